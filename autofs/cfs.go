@@ -174,7 +174,7 @@ func cfsList() error {
 	}
 
 	for _, v := range mps {
-		if v.Type == "fuse" || v.Type == "fuse.cubefs" {
+		if v.Type == "fuse" || v.Type == "fuse.cubefs" || v.Type == kernelFsType {
 			fmt.Printf("%s on %s type %s (%s)\n", v.Device, v.Path, v.Type, strings.Join(v.Opts, ","))
 		}
 	}
@@ -190,6 +190,15 @@ func cfsMount(mountPoint, options string) error {
 
 	c := cfsParse(mountPoint, options)
 
+	mounted, err := kernelMount(c)
+	if err != nil {
+		log.Println("kernelMountErr, err: ", err.Error())
+		return err
+	}
+	if mounted {
+		return nil
+	}
+
 	co := c.ConvertToCliOptions()
 
 	log.Println("cfsMount commands: ", getCfsClientPath(), strings.Join(co, " "))
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"k8s.io/mount-utils"
+)
+
+const (
+	kernelFsType    = "cubefs"
+	procFilesystems = "/proc/filesystems"
+)
+
+// client modes of mounting
+const (
+	ClientModeAuto   = "auto"   // prefer the kernel client, fall back to FUSE
+	ClientModeKernel = "kernel" // the kernel client only
+	ClientModeFuse   = "fuse"   // the FUSE client only
+)
+
+// options not supported by the kernel client, mounted by FUSE if any of them is set
+var kernelUnsupportedOptions = []string{
+	"subdir", "authenticate", "ticketHost", "clientKey", "certFile", "enableHTTPS",
+	"bcacheDir", "ebsEndpoint", "ebsServerPath",
+}
+
+// hasFilesystem reports whether the file system type is in the content of /proc/filesystems.
+func hasFilesystem(content, fsType string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[len(fields)-1] == fsType {
+			return true
+		}
+	}
+	return false
+}
+
+func kernelFsRegistered() bool {
+	content, err := os.ReadFile(procFilesystems)
+	if err != nil {
+		log.Println("[warn] read filesystems failed, err: ", err)
+		return false
+	}
+	return hasFilesystem(string(content), kernelFsType)
+}
+
+// kernelModuleAvailable reports whether the kernel client is available, it tries to load the
+// module if the file system is not registered yet.
+func kernelModuleAvailable() bool {
+	if kernelFsRegistered() {
+		return true
+	}
+	modprobe, err := exec.LookPath("modprobe")
+	if err != nil {
+		return false
+	}
+	if out, err := exec.Command(modprobe, "-q", kernelFsType).CombinedOutput(); err != nil {
+		log.Printf("[info] kernel module %s not loaded, err: %v, output: %s", kernelFsType, err, out)
+		return false
+	}
+	return kernelFsRegistered()
+}
+
+// optionFields returns the fields of options by the json names.
+func (c *cfsOption) optionFields() map[string]reflect.Value {
+	tp := reflect.TypeOf(*c)
+	el := reflect.ValueOf(c).Elem()
+	fields := make(map[string]reflect.Value, tp.NumField())
+	for i := 0; i < tp.NumField(); i++ {
+		if js := tp.Field(i).Tag.Get("json"); js != "" {
+			fields[js] = el.Field(i)
+		}
+	}
+	return fields
+}
+
+// mergeConfigFile fills the options not set with the config file of the FUSE client, so that
+// the kernel client is mounted by the same config file.
+func (c *cfsOption) mergeConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	values := make(map[string]interface{})
+	if err = json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	fields := c.optionFields()
+	for key, value := range values {
+		field, ok := fields[key]
+		if !ok || field.String() != "" {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			field.SetString(v)
+		case bool:
+			if v {
+				field.SetString("1")
+			}
+		default:
+			field.SetString(fmt.Sprint(v))
+		}
+	}
+	return nil
+}
+
+// kernelUnsupported returns the reason why the options can not be mounted by the kernel client.
+func (c *cfsOption) kernelUnsupported() string {
+	if c.MasterAddr == "" || c.VolName == "" || c.Owner == "" {
+		return "masterAddr, volName and owner are required"
+	}
+	if c.VolType != "" && c.VolType != "0" {
+		return "cold volume is not supported"
+	}
+	fields := c.optionFields()
+	for _, name := range kernelUnsupportedOptions {
+		if fields[name].String() != "" {
+			return fmt.Sprintf("option %s is not supported", name)
+		}
+	}
+	return ""
+}
+
+func isTrue(v string) bool {
+	b, err := strconv.ParseBool(v)
+	return err == nil && b
+}
+
+// kernelMountArgs returns the source and the options to mount the kernel client, in the form of
+// mount -t cubefs -o owner=xxx //master1,master2/volume /mnt/cubefs
+func (c *cfsOption) kernelMountArgs() (source string, options []string) {
+	source = "//" + c.MasterAddr + "/" + c.VolName
+	options = append(options, "owner="+c.Owner)
+	for _, opt := range []struct {
+		name  string
+		value string
+	}{
+		{"dentry_cache_valid_ms", c.LookupValid},
+		{"attr_cache_valid_ms", c.AttrValid},
+	} {
+		// the valid durations of FUSE client are in seconds
+		if sec, err := strconv.ParseInt(opt.value, 10, 64); err == nil && sec > 0 {
+			options = append(options, fmt.Sprintf("%s=%d", opt.name, sec*1000))
+		}
+	}
+	if isTrue(c.Rdonly) {
+		options = append(options, "ro")
+	}
+	return
+}
+
+// kernelMount mounts by the kernel client if it is available, and reports whether it is mounted.
+// The error is returned only in the kernel mode, otherwise it falls back to FUSE.
+func kernelMount(c *cfsOption) (bool, error) {
+	mode := getClientMode()
+	if mode == ClientModeFuse {
+		return false, nil
+	}
+	fallback := func(format string, args ...interface{}) (bool, error) {
+		err := fmt.Errorf(format, args...)
+		if mode == ClientModeKernel {
+			return false, err
+		}
+		log.Println("[info] kernelMount fall back to FUSE: ", err)
+		return false, nil
+	}
+
+	if !kernelModuleAvailable() {
+		return fallback("kernel module %s is not available", kernelFsType)
+	}
+	kc := *c
+	if kc.C != "" {
+		if err := kc.mergeConfigFile(kc.C); err != nil {
+			return fallback("load config file failed: %v", err)
+		}
+	}
+	if reason := kc.kernelUnsupported(); reason != "" {
+		return fallback("%s", reason)
+	}
+
+	source, options := kc.kernelMountArgs()
+	log.Println("kernelMount: ", source, kc.MountPoint, strings.Join(options, ","))
+	if err := mount.New("").Mount(source, kc.MountPoint, kernelFsType, options); err != nil {
+		return fallback("mount failed: %v", err)
+	}
+	log.Println("[info] kernelMount mounted")
+	return true, nil
+}
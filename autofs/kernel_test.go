@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHasFilesystem(t *testing.T) {
+	content := "nodev\tsysfs\nnodev\tproc\n\text4\nnodev\tfuse\nnodev\tcubefs\n"
+	if !hasFilesystem(content, "cubefs") {
+		t.Error("cubefs should be registered")
+	}
+	if hasFilesystem(content, "cubefs2") || hasFilesystem("", "cubefs") {
+		t.Error("cubefs2 should not be registered")
+	}
+}
+
+func TestKernelMountArgs(t *testing.T) {
+	c := cfsParse("tmp/mp", "volName=ltptest,owner=ltptest,masterAddr=10.0.0.1:17010,lookupValid=5,attrValid=30,rdonly")
+	// the masters are separated by comma in the config file
+	c.MasterAddr = "10.0.0.1:17010,10.0.0.2:17010"
+	if reason := c.kernelUnsupported(); reason != "" {
+		t.Error("kernel should be supported, reason: ", reason)
+	}
+
+	source, options := c.kernelMountArgs()
+	if source != "//10.0.0.1:17010,10.0.0.2:17010/ltptest" {
+		t.Error("sourceErr, source: ", source)
+	}
+	if strings.Join(options, ",") != "owner=ltptest,dentry_cache_valid_ms=5000,attr_cache_valid_ms=30000,ro" {
+		t.Error("optionsErr, options: ", options)
+	}
+}
+
+func TestKernelUnsupported(t *testing.T) {
+	c := cfsParse("tmp/mp", "volName=ltptest,masterAddr=10.0.0.1:17010")
+	if c.kernelUnsupported() == "" {
+		t.Error("owner is required")
+	}
+
+	c = cfsParse("tmp/mp", "subdir=sc1ch,volName=ltptest,owner=ltptest,masterAddr=10.0.0.1:17010")
+	if c.kernelUnsupported() == "" {
+		t.Error("subdir is not supported")
+	}
+
+	c = cfsParse("tmp/mp", "volName=ltptest,owner=ltptest,masterAddr=10.0.0.1:17010,volType=1")
+	if c.kernelUnsupported() == "" {
+		t.Error("cold volume is not supported")
+	}
+}
+
+func TestMergeConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "client.json")
+	conf := `{"masterAddr": "10.0.0.1:17010", "volName": "ltptest", "owner": "ltptest", "lookupValid": 5, "rdonly": true, "unknown": "x"}`
+	if err := os.WriteFile(path, []byte(conf), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := cfsParse("tmp/mp", "c="+path+",volName=other")
+	if err := c.mergeConfigFile(c.C); err != nil {
+		t.Fatal(err)
+	}
+	if c.MasterAddr != "10.0.0.1:17010" || c.VolName != "other" || c.Owner != "ltptest" || c.LookupValid != "5" || c.Rdonly != "1" {
+		t.Error("mergeErr, c: ", c)
+	}
+
+	if err := c.mergeConfigFile(path + ".none"); err == nil {
+		t.Error("config file not exists")
+	}
+}
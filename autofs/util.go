@@ -5,6 +5,7 @@ import "os"
 const (
 	EnvLogFile       = "CFSAUTO_LOG_FILE"
 	EnvCfsClientPath = "CFS_CLIENT_PATH"
+	EnvClientMode    = "CFSAUTO_CLIENT_MODE"
 )
 
 const LogFile = "/var/log/cfsauto.log"
@@ -22,3 +23,8 @@ func getEnv(key, dft string) (val string) {
 func getCfsClientPath() string {
 	return getEnv(EnvCfsClientPath, "/etc/cfs/cfs-client")
 }
+
+// getClientMode env, auto, kernel or fuse
+func getClientMode() string {
+	return getEnv(EnvClientMode, ClientModeAuto)
+}
@@ -125,6 +125,8 @@ type ClusterController interface {
 	GetConfig(ctx context.Context, key string) (string, error)
 	// ChangeChooseAlg change alloc algorithm
 	ChangeChooseAlg(alg AlgChoose) error
+	// ReportUsage reports usage of tenants to cluster manager of the cluster
+	ReportUsage(ctx context.Context, clusterID proto.ClusterID, args *cmapi.ReportUsageArgs) error
 }
 
 // ClusterConfig cluster config
@@ -139,6 +141,12 @@ type ClusterConfig struct {
 	ServiceReloadSecs int          `json:"service_reload_secs"`
 	CMClientConfig    cmapi.Config `json:"clustermgr_client_config"`
 
+	// LocationSecrets are the cluster keys to sign the locations, the first one signs
+	// and all of them verify. The locations of the region magic crc are still valid
+	// if LocationCrcCompatible, to migrate the locations put before.
+	LocationSecrets       []string `json:"location_secrets"`
+	LocationCrcCompatible bool     `json:"location_crc_compatible"`
+
 	ServicePunishThreshold      uint32 `json:"service_punish_threshold"`
 	ServicePunishValidIntervalS int    `json:"service_punish_valid_interval_s"`
 
@@ -502,3 +510,12 @@ func (c *clusterControllerImpl) GetConfig(ctx context.Context, key string) (ret
 	}
 	return
 }
+
+func (c *clusterControllerImpl) ReportUsage(ctx context.Context, clusterID proto.ClusterID, args *cmapi.ReportUsageArgs) error {
+	allClusters := c.clusters.Load().(clusterMap)
+	cluster, ok := allClusters[clusterID]
+	if !ok {
+		return ErrNoSuchCluster
+	}
+	return cluster.client.ReportUsage(ctx, args)
+}
@@ -20,6 +20,7 @@ import (
 	"net/http"
 	"strconv"
 	"sync"
+	"sync/atomic"
 
 	"github.com/cubefs/cubefs/blobstore/access/controller"
 	"github.com/cubefs/cubefs/blobstore/access/stream"
@@ -35,6 +36,7 @@ import (
 	"github.com/cubefs/cubefs/blobstore/common/trace"
 	"github.com/cubefs/cubefs/blobstore/common/uptoken"
 	"github.com/cubefs/cubefs/blobstore/util/closer"
+	"github.com/cubefs/cubefs/blobstore/util/defaulter"
 	"github.com/cubefs/cubefs/blobstore/util/errors"
 	"github.com/cubefs/cubefs/blobstore/util/log"
 )
@@ -48,6 +50,8 @@ const (
 	limitNameSign   = "sign"
 )
 
+const defaultDeleteBatchConcurrency = 32
+
 func initWithRegionMagic(regionMagic string) {
 	if regionMagic == "" {
 		log.Warn("no region magic setting, using default secret keys for checksum")
@@ -74,6 +78,11 @@ type Config struct {
 	ServiceRegister consul.Config       `json:"service_register"`
 	Stream          stream.StreamConfig `json:"stream"`
 	Limit           stream.LimitConfig  `json:"limit"`
+
+	// DeleteBatchConcurrency max concurrent deletions of one /deletebatch request
+	DeleteBatchConcurrency int `json:"delete_batch_concurrency"`
+	// UsageReportIntervalS interval of reporting usage of tenants to cluster manager
+	UsageReportIntervalS int `json:"usage_report_interval_s"`
 }
 
 // Service rpc service
@@ -81,6 +90,7 @@ type Service struct {
 	config        Config
 	streamHandler stream.StreamHandler
 	limiter       stream.Limiter
+	usage         *usageReporter
 	closer        closer.Closer
 }
 
@@ -88,6 +98,7 @@ type Service struct {
 func New(cfg Config) *Service {
 	// add region magic checksum to the secret keys
 	initWithRegionMagic(cfg.Stream.ClusterConfig.RegionMagic)
+	stream.LocationInitSigning(&cfg.Stream.ClusterConfig)
 
 	cl := closer.New()
 	h, err := stream.NewStreamHandler(&cfg.Stream, cl.Done())
@@ -95,10 +106,17 @@ func New(cfg Config) *Service {
 		log.Fatalf("new stream handler failed, err: %+v", err)
 	}
 
+	var usage *usageReporter
+	if admin, ok := h.Admin().(*stream.StreamAdmin); ok {
+		defaulter.LessOrEqual(&cfg.UsageReportIntervalS, defaultUsageReportIntervalS)
+		usage = newUsageReporter(admin.Controller, cfg.UsageReportIntervalS, cl.Done())
+	}
+
 	return &Service{
 		config:        cfg,
 		streamHandler: h,
 		limiter:       stream.NewLimiter(cfg.Limit),
+		usage:         usage,
 		closer:        cl,
 	}
 }
@@ -200,7 +218,7 @@ func (s *Service) Limit(c *rpc.Context) {
 		name = limitNamePutAt
 	case "/get":
 		name = limitNameGet
-	case "/delete":
+	case "/delete", "/deletebatch":
 		name = limitNameDelete
 	case "/sign":
 		name = limitNameSign
@@ -232,7 +250,7 @@ func (s *Service) Put(c *rpc.Context) {
 	span := trace.SpanFromContextSafe(ctx)
 
 	span.Debugf("accept /put request args:%+v", args)
-	if !args.IsValid() {
+	if !args.IsValid() || !validTenant(args.Tenant) {
 		c.RespondError(errcode.ErrIllegalArguments)
 		return
 	}
@@ -244,7 +262,13 @@ func (s *Service) Put(c *rpc.Context) {
 		hasherMap[alg] = alg.ToHasher()
 	}
 
-	rc := s.limiter.Reader(ctx, c.Request.Body)
+	if !validContentLength(c.Request, args.Size) {
+		span.Infof("content length %d mismatch size %d", c.Request.ContentLength, args.Size)
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+	body := stream.NewPutBodyReader(c.Request.Body, s.config.Stream.PutBodyConfig)
+	rc := s.limiter.Reader(ctx, body)
 	loc, err := s.streamHandler.Put(ctx, rc, args.Size, hasherMap)
 	if err != nil {
 		span.Error("stream put failed", errors.Detail(err))
@@ -263,11 +287,13 @@ func (s *Service) Put(c *rpc.Context) {
 		return
 	}
 
+	s.usage.Put(args.Tenant, loc)
+
 	c.RespondJSON(access.PutResp{
 		Location:   *loc,
 		HashSumMap: hashSumMap,
 	})
-	span.Infof("done /put request location:%+v hash:%+v", loc, hashSumMap.All())
+	span.Infof("done /put request location:%+v hash:%+v body crc32:%d", loc, hashSumMap.All(), body.Crc32())
 }
 
 // PutAt put one blob
@@ -308,7 +334,13 @@ func (s *Service) PutAt(c *rpc.Context) {
 		hasherMap[alg] = alg.ToHasher()
 	}
 
-	rc := s.limiter.Reader(ctx, c.Request.Body)
+	if !validContentLength(c.Request, args.Size) {
+		span.Infof("content length %d mismatch size %d", c.Request.ContentLength, args.Size)
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+	body := stream.NewPutBodyReader(c.Request.Body, s.config.Stream.PutBodyConfig)
+	rc := s.limiter.Reader(ctx, body)
 	err := s.streamHandler.PutAt(ctx, rc, args.ClusterID, args.Vid, args.BlobID, args.Size, hasherMap)
 	if err != nil {
 		span.Error("stream putat failed", errors.Detail(err))
@@ -322,7 +354,7 @@ func (s *Service) PutAt(c *rpc.Context) {
 	}
 
 	c.RespondJSON(access.PutAtResp{HashSumMap: hashSumMap})
-	span.Infof("done /putat request hash:%+v", hashSumMap.All())
+	span.Infof("done /putat request hash:%+v body crc32:%d", hashSumMap.All(), body.Crc32())
 }
 
 // Alloc alloc one location
@@ -337,7 +369,7 @@ func (s *Service) Alloc(c *rpc.Context) {
 	span := trace.SpanFromContextSafe(ctx)
 
 	span.Debugf("accept /alloc request args:%+v", args)
-	if !args.IsValid() {
+	if !args.IsValid() || !validTenant(args.Tenant) {
 		c.RespondError(errcode.ErrIllegalArguments)
 		return
 	}
@@ -355,6 +387,10 @@ func (s *Service) Alloc(c *rpc.Context) {
 		return
 	}
 
+	if args.AssignClusterID == 0 {
+		s.usage.Put(args.Tenant, location)
+	}
+
 	resp := access.AllocResp{
 		Location: *location,
 		Tokens:   stream.StreamGenTokens(location),
@@ -442,7 +478,7 @@ func (s *Service) Delete(c *rpc.Context) {
 		c.RespondJSON(resp)
 	}()
 
-	if !args.IsValid() {
+	if !args.IsValid() || !validTenant(args.Tenant) {
 		err = errcode.ErrIllegalArguments
 		return
 	}
@@ -464,7 +500,9 @@ func (s *Service) Delete(c *rpc.Context) {
 		if err := s.streamHandler.Delete(ctx, &loc); err != nil {
 			span.Error("stream delete failed", errors.Detail(err))
 			resp.FailedLocations = []access.Location{loc}
+			return
 		}
+		s.usage.Delete(args.Tenant, &loc)
 		return
 	}
 
@@ -486,8 +524,10 @@ func (s *Service) Delete(c *rpc.Context) {
 	var wg sync.WaitGroup
 	failedCh := make(chan proto.ClusterID, 1)
 	done := make(chan struct{})
+	failedClusters := make(map[proto.ClusterID]bool)
 	go func() {
 		for id := range failedCh {
+			failedClusters[id] = true
 			if resp.FailedLocations == nil {
 				resp.FailedLocations = make([]access.Location, 0, len(args.Locations))
 			}
@@ -518,6 +558,84 @@ func (s *Service) Delete(c *rpc.Context) {
 	wg.Wait()
 	close(failedCh)
 	<-done
+
+	for idx := range args.Locations {
+		if !failedClusters[args.Locations[idx].ClusterID] {
+			s.usage.Delete(args.Tenant, &args.Locations[idx])
+		}
+	}
+}
+
+// DeleteBatch delete locations one by one with bounded concurrency,
+// responds result of every location instead of failing the whole batch.
+func (s *Service) DeleteBatch(c *rpc.Context) {
+	args := new(access.DeleteBatchArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+
+	if !args.IsValid() || !validTenant(args.Tenant) {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+	span.Debugf("accept /deletebatch request args: locations %d", len(args.Locations))
+
+	concurrency := s.config.DeleteBatchConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultDeleteBatchConcurrency
+	}
+
+	resp := access.DeleteBatchResp{Results: make([]access.DeleteBatchResult, len(args.Locations))}
+	var (
+		wg     sync.WaitGroup
+		failed int32
+	)
+	tokens := make(chan struct{}, concurrency)
+	for idx := range args.Locations {
+		loc := &args.Locations[idx]
+		result := &resp.Results[idx]
+		if !stream.LocationCrcVerify(loc) {
+			span.Infof("invalid crc %+v", loc)
+			result.Code = errcode.ErrIllegalArguments.StatusCode()
+			result.Error = errcode.ErrIllegalArguments.Error()
+			atomic.AddInt32(&failed, 1)
+			continue
+		}
+		if loc.Size == 0 {
+			result.Code = http.StatusOK
+			continue
+		}
+
+		tokens <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+			if err := s.streamHandler.Delete(ctx, loc); err != nil {
+				span.Error("stream delete failed", loc.ClusterID, errors.Detail(err))
+				httpErr := rpc.Error2HTTPError(httpError(err))
+				result.Code = httpErr.StatusCode()
+				result.Error = httpErr.Error()
+				atomic.AddInt32(&failed, 1)
+				return
+			}
+			s.usage.Delete(args.Tenant, loc)
+			result.Code = http.StatusOK
+		}()
+	}
+	wg.Wait()
+
+	if failed > 0 {
+		span.Errorf("failed locations N %d of %d", failed, len(args.Locations))
+	}
+	c.RespondJSON(resp)
+	span.Infof("done /deletebatch request locations %d failed %d", len(args.Locations), failed)
 }
 
 // DeleteBlob delete one blob
@@ -598,6 +716,17 @@ func (s *Service) Sign(c *rpc.Context) {
 	span.Infof("done /sign request crc %d -> %d, resp:%+v", crcOld, loc.Crc, loc)
 }
 
+// validContentLength returns false if the declared content length is not the size of put,
+// the body of unknown length is checked after reading the size.
+// validTenant returns the tenant is empty or valid.
+func validTenant(tenant string) bool {
+	return tenant == "" || clustermgr.IsValidTenant(tenant)
+}
+
+func validContentLength(req *http.Request, size int64) bool {
+	return req.ContentLength < 0 || req.ContentLength == size
+}
+
 func httpError(err error) error {
 	if e, ok := err.(rpc.HTTPError); ok {
 		return e
@@ -282,6 +282,13 @@ func TestAccessServicePut(t *testing.T) {
 			require.NoError(t, err)
 			require.Equal(t, uint64(1024), resp.Location.Size)
 		}
+		{
+			args.Body = bytes.NewReader(make([]byte, 1025))
+			req, _ := http.NewRequest(method, url(1024, args.Hashes), args.Body)
+			resp := &access.PutResp{}
+			err := cli.DoWith(ctx, req, resp, rpc.WithCrcEncode())
+			assertErrorCode(t, 400, err)
+		}
 	}
 }
 
@@ -445,6 +452,53 @@ func TestAccessServiceDelete(t *testing.T) {
 	}
 }
 
+func TestAccessServiceDeleteBatch(t *testing.T) {
+	host := runMockService(newService())
+	cli := newClient()
+
+	url := fmt.Sprintf("%s/deletebatch", host)
+	{
+		err := cli.PostWith(ctx, url, nil, access.DeleteBatchArgs{})
+		assertErrorCode(t, 400, err)
+		err = cli.PostWith(ctx, url, nil, access.DeleteBatchArgs{
+			Locations: make([]access.Location, access.MaxDeleteBatchLocations+1),
+		})
+		assertErrorCode(t, 400, err)
+	}
+	{
+		locs := make([]access.Location, access.MaxDeleteLocations*2)
+		for idx := range locs {
+			loc := location.Copy()
+			loc.Size = 1024
+			loc.ClusterID = proto.ClusterID(idx % 11)
+			stream.LocationCrcFill(&loc)
+			locs[idx] = loc
+		}
+		// invalid crc
+		locs[1].Crc++
+		// empty location
+		locs[2] = access.Location{}
+		stream.LocationCrcFill(&locs[2])
+
+		var resp access.DeleteBatchResp
+		err := cli.PostWith(ctx, url, &resp, access.DeleteBatchArgs{Locations: locs})
+		require.NoError(t, err)
+		require.Equal(t, len(locs), len(resp.Results))
+		for idx, result := range resp.Results {
+			switch {
+			case idx == 1:
+				require.Equal(t, 400, result.Code)
+			case locs[idx].ClusterID >= 10:
+				require.Equal(t, 500, result.Code)
+				require.NotEmpty(t, result.Error)
+			default:
+				require.Equal(t, 200, result.Code)
+			}
+		}
+		require.Equal(t, len(locs)/11+1, len(resp.FailedIndexes()))
+	}
+}
+
 func TestAccessServiceDeleteBlob(t *testing.T) {
 	host := runMockService(newService())
 	cli := newClient()
@@ -92,6 +92,10 @@ func NewHandler(service *Service) *rpc.Router {
 	// request  body:  json
 	// response body:  json
 	rpc.POST("/delete", service.Delete, rpc.OptArgsBody())
+	// POST /deletebatch
+	// request  body:  json
+	// response body:  json
+	rpc.POST("/deletebatch", service.DeleteBatch, rpc.OptArgsBody())
 	// DELETE /deleteblob
 	rpc.DELETE("/deleteblob", service.DeleteBlob, rpc.OptArgsQuery())
 
@@ -23,6 +23,10 @@ const (
 	defaultAllocRetryIntervalMS   int = 100
 	defaultEncoderConcurrency     int = 1000
 	defaultMinReadShardsX         int = 1
+	defaultBlobCacheMaxSize       int = 1 << 16
+	defaultWriteDegradedReloadS   int = 30
+	defaultAdaptivePutWaitMS      int = 200
+	defaultMaxPutBuffers          int = 4
 
 	// client timeout ms
 	defaultTimeoutClusterMgr int64 = 1000 * 3
@@ -139,6 +139,20 @@ func (mr *MockClusterControllerMockRecorder) Region() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Region", reflect.TypeOf((*MockClusterController)(nil).Region))
 }
 
+// ReportUsage mocks base method.
+func (m *MockClusterController) ReportUsage(arg0 context.Context, arg1 proto.ClusterID, arg2 *clustermgr.ReportUsageArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReportUsage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReportUsage indicates an expected call of ReportUsage.
+func (mr *MockClusterControllerMockRecorder) ReportUsage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportUsage", reflect.TypeOf((*MockClusterController)(nil).ReportUsage), arg0, arg1, arg2)
+}
+
 // MockServiceController is a mock of ServiceController interface.
 type MockServiceController struct {
 	ctrl     *gomock.Controller
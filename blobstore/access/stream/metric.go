@@ -40,11 +40,22 @@ var downloadMetric = prometheus.NewCounterVec(
 	[]string{"cluster", "way", "reason"},
 )
 
+var adaptivePutMetric = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "blobstore",
+		Subsystem: "access",
+		Name:      "adaptive_put",
+		Help:      "put returned with adaptive quorum and its background completion",
+	},
+	[]string{"cluster", "result"},
+)
+
 var SteamReportDownload = reportDownload
 
 func init() {
 	prometheus.MustRegister(unhealthMetric)
 	prometheus.MustRegister(downloadMetric)
+	prometheus.MustRegister(adaptivePutMetric)
 }
 
 func reportUnhealth(cid proto.ClusterID, action, module, host, reason string) {
@@ -54,3 +65,7 @@ func reportUnhealth(cid proto.ClusterID, action, module, host, reason string) {
 func reportDownload(cid proto.ClusterID, way, reason string) {
 	downloadMetric.WithLabelValues(cid.ToString(), way, reason).Inc()
 }
+
+func reportAdaptivePut(cid proto.ClusterID, result string) {
+	adaptivePutMetric.WithLabelValues(cid.ToString(), result).Inc()
+}
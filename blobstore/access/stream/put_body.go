@@ -0,0 +1,93 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"errors"
+	"hash"
+	"hash/crc32"
+	"io"
+	"time"
+)
+
+// ErrPutBodyTooSlow the body of put is dribbled slower than the min rate
+var ErrPutBodyTooSlow = errors.New("put body is read too slowly")
+
+// PutBodyConfig protects access from the uploaders holding connections with dribbling bodies
+type PutBodyConfig struct {
+	// the reading of body fails if the average rate is slower than MinRateKBps
+	// after GraceS seconds spent on reading, 0 means no limit
+	MinRateKBps int `json:"min_rate_kbps"`
+	GraceS      int `json:"grace_s"`
+}
+
+// PutBodyReader reads the request body of put, the crc32 of the body is computed
+// while reading, and the time waiting for the uploader is accounted to check the
+// min rate, so it should wrap the request body directly.
+type PutBodyReader struct {
+	underlying io.Reader
+	crc        hash.Hash32
+	minRate    float64 // bytes per second
+	grace      time.Duration
+	read       int64
+	duration   time.Duration
+}
+
+var _ io.Reader = &PutBodyReader{}
+
+// NewPutBodyReader returns reader of the put request body
+func NewPutBodyReader(r io.Reader, cfg PutBodyConfig) *PutBodyReader {
+	return &PutBodyReader{
+		underlying: r,
+		crc:        crc32.NewIEEE(),
+		minRate:    float64(cfg.MinRateKBps) * 1024,
+		grace:      time.Duration(cfg.GraceS) * time.Second,
+	}
+}
+
+func (r *PutBodyReader) Read(p []byte) (n int, err error) {
+	st := time.Now()
+	n, err = r.underlying.Read(p)
+	r.duration += time.Since(st)
+	r.read += int64(n)
+	r.crc.Write(p[:n])
+
+	if err == nil && r.minRate > 0 && r.duration > r.grace &&
+		float64(r.read) < r.minRate*r.duration.Seconds() {
+		return n, ErrPutBodyTooSlow
+	}
+	return
+}
+
+// Crc32 returns the crc32 of the body read so far
+func (r *PutBodyReader) Crc32() uint32 {
+	return r.crc.Sum32()
+}
+
+// ReadSize returns the size of the body read so far
+func (r *PutBodyReader) ReadSize() int64 {
+	return r.read
+}
+
+// hasTrailingBody returns true if there is still data in body after the declared size was read,
+// the put of buggy or malicious uploader fails rather than truncates the body silently.
+func hasTrailingBody(rc io.Reader) bool {
+	if rc == nil {
+		return false
+	}
+	var b [1]byte
+	n, _ := io.ReadFull(rc, b[:])
+	return n > 0
+}
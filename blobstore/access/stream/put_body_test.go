@@ -0,0 +1,74 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"bytes"
+	"hash/crc32"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+)
+
+type dribbleReader struct {
+	data  []byte
+	delay time.Duration
+}
+
+func (r *dribbleReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(r.delay)
+	p[0] = r.data[0]
+	r.data = r.data[1:]
+	return 1, nil
+}
+
+func TestAccessStreamPutBodyReader(t *testing.T) {
+	data := make([]byte, 1<<10)
+	{
+		body := NewPutBodyReader(bytes.NewReader(data), PutBodyConfig{MinRateKBps: 1})
+		_, err := io.ReadAll(body)
+		require.NoError(t, err)
+		require.Equal(t, crc32.ChecksumIEEE(data), body.Crc32())
+		require.Equal(t, int64(len(data)), body.ReadSize())
+	}
+	{
+		body := NewPutBodyReader(&dribbleReader{data: data, delay: 10 * time.Millisecond}, PutBodyConfig{MinRateKBps: 1})
+		_, err := io.ReadAll(body)
+		require.ErrorIs(t, err, ErrPutBodyTooSlow)
+	}
+	{
+		body := NewPutBodyReader(&dribbleReader{data: data[:10], delay: 10 * time.Millisecond}, PutBodyConfig{})
+		_, err := io.ReadAll(body)
+		require.NoError(t, err)
+	}
+
+	require.False(t, hasTrailingBody(nil))
+	require.False(t, hasTrailingBody(bytes.NewReader(nil)))
+	require.True(t, hasTrailingBody(bytes.NewReader(data)))
+}
+
+func TestAccessStreamPutTrailingBody(t *testing.T) {
+	ctx := ctxWithName("TestAccessStreamPutTrailingBody")
+	size := 1 << 10
+	_, err := streamer.Put(ctx(), newReader(size+1), int64(size), nil)
+	require.ErrorIs(t, err, errcode.ErrAccessReadRequestBody)
+}
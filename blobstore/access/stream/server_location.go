@@ -15,15 +15,20 @@
 package stream
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
 	"hash/crc32"
 	"sync"
 	"time"
 
+	"github.com/cubefs/cubefs/blobstore/access/controller"
 	"github.com/cubefs/cubefs/blobstore/api/access"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/common/uptoken"
 	"github.com/cubefs/cubefs/blobstore/util/bytespool"
+	"github.com/cubefs/cubefs/blobstore/util/log"
 )
 
 const (
@@ -54,6 +59,12 @@ var (
 	}
 	_initTokenSecret sync.Once
 
+	// locationSigningKeys sign the locations with the first key always,
+	// and verify with all the keys, so that you can change the key as tokenSecretKeys.
+	locationSigningKeys   [][]byte
+	locationCrcCompatible bool
+	_initLocationSigning  sync.Once
+
 	LocationCrcCalculate = calcCrc
 	LocationCrcFill      = fillCrc
 	LocationCrcVerify    = verifyCrc
@@ -68,6 +79,33 @@ func initLocationSecret(b []byte) {
 	})
 }
 
+// LocationInitSigningKeys initializes the cluster keys to sign the locations once.
+//
+// The crc of the magic key is linear, anyone holds a location is able to fill the crc of
+// a forged one. The crc of the locations is the truncated hmac-sha256 of the cluster key
+// if any, which is not forged without the key. The locations of the crc are still valid
+// if compatible, to read and delete the locations put before the keys.
+func LocationInitSigningKeys(keys []string, compatible bool) {
+	_initLocationSigning.Do(func() {
+		for _, key := range keys {
+			if key != "" {
+				locationSigningKeys = append(locationSigningKeys, []byte(key))
+			}
+		}
+		locationCrcCompatible = compatible
+	})
+}
+
+// LocationInitSigning initializes the signing keys of the locations by the cluster config.
+func LocationInitSigning(cfg *controller.ClusterConfig) {
+	if len(cfg.LocationSecrets) == 0 {
+		log.Warn("no location secrets setting, the locations are verified by crc only")
+	} else if cfg.LocationCrcCompatible {
+		log.Warn("the locations of crc are still valid, disable location_crc_compatible after migrated")
+	}
+	LocationInitSigningKeys(cfg.LocationSecrets, cfg.LocationCrcCompatible)
+}
+
 // TokenInitSecret initializate token's secret keys once.
 func TokenInitSecret(b []byte) {
 	_initTokenSecret.Do(func() {
@@ -103,8 +141,33 @@ func calcCrc(loc *access.Location) (uint32, error) {
 	return crcWriter.Sum32(), nil
 }
 
+// calcSignature returns the first 4 bytes of the hmac-sha256 of the location with the key.
+func calcSignature(loc *access.Location, key []byte) (uint32, error) {
+	buf := bytespool.Alloc(1024)
+	defer bytespool.Free(buf)
+
+	n := loc.Encode2(buf)
+	if n < 4 {
+		return 0, fmt.Errorf("no enough bytes(%d) fill into buf", n)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	if _, err := mac.Write(buf[4:n]); err != nil {
+		return 0, fmt.Errorf("fill signature %s", err.Error())
+	}
+	return binary.BigEndian.Uint32(mac.Sum(nil)), nil
+}
+
 func fillCrc(loc *access.Location) error {
-	crc, err := calcCrc(loc)
+	var (
+		crc uint32
+		err error
+	)
+	if len(locationSigningKeys) > 0 {
+		crc, err = calcSignature(loc, locationSigningKeys[0])
+	} else {
+		crc, err = calcCrc(loc)
+	}
 	if err != nil {
 		return err
 	}
@@ -113,6 +176,14 @@ func fillCrc(loc *access.Location) error {
 }
 
 func verifyCrc(loc *access.Location) bool {
+	for _, key := range locationSigningKeys {
+		if crc, err := calcSignature(loc, key); err == nil && loc.Crc == crc {
+			return true
+		}
+	}
+	if len(locationSigningKeys) > 0 && !locationCrcCompatible {
+		return false
+	}
 	crc, err := calcCrc(loc)
 	if err != nil {
 		return false
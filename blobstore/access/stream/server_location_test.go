@@ -102,6 +102,61 @@ func TestAccessServiceLocationSecret(t *testing.T) {
 	}
 }
 
+func TestAccessServiceLocationSigningKeys(t *testing.T) {
+	defer func() {
+		locationSigningKeys, locationCrcCompatible = nil, false
+	}()
+	loc := &access.Location{
+		ClusterID: 1,
+		CodeMode:  1,
+		Size:      1023,
+		BlobSize:  1024,
+		Blobs:     []access.SliceInfo{{MinBid: 11, Vid: 199, Count: 1}},
+	}
+	require.NoError(t, fillCrc(loc))
+	legacy := loc.Crc
+
+	locationSigningKeys = [][]byte{[]byte("key-new"), []byte("key-old")}
+	{
+		require.False(t, verifyCrc(loc))
+		locationCrcCompatible = true
+		require.True(t, verifyCrc(loc))
+		locationCrcCompatible = false
+	}
+	{
+		require.NoError(t, fillCrc(loc))
+		require.NotEqual(t, legacy, loc.Crc)
+		require.True(t, verifyCrc(loc))
+
+		crc, err := calcSignature(loc, []byte("key-new"))
+		require.NoError(t, err)
+		require.Equal(t, crc, loc.Crc)
+	}
+	{
+		// the signature of the rotated key
+		crc, err := calcSignature(loc, []byte("key-old"))
+		require.NoError(t, err)
+		forged := loc.Copy()
+		forged.Crc = crc
+		require.True(t, verifyCrc(&forged))
+	}
+	{
+		// the forged location of the linear crc is invalid
+		forged := loc.Copy()
+		forged.Blobs[0].MinBid = 12
+		require.False(t, verifyCrc(&forged))
+		crc, err := calcSignature(&forged, []byte("key-other"))
+		require.NoError(t, err)
+		forged.Crc = crc
+		require.False(t, verifyCrc(&forged))
+	}
+	{
+		loc1, loc2 := loc.Copy(), loc.Copy()
+		require.NoError(t, signCrc(loc, []access.Location{loc1, loc2}))
+		require.True(t, verifyCrc(loc))
+	}
+}
+
 func TestAccessServiceTokenSecret(t *testing.T) {
 	keys := tokenSecretKeys
 	defer func() {
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync/atomic"
 
 	"github.com/afex/hystrix-go/hystrix"
 
@@ -123,11 +124,31 @@ type StreamConfig struct {
 	MinReadShardsX             int    `json:"min_read_shards_x"`
 	ShardCrcDisabled           bool   `json:"shard_crc_disabled"`
 
+	// read small blobs through proxy blob cache
+	BlobCacheEnable  bool `json:"blob_cache_enable"`
+	BlobCacheMaxSize int  `json:"blob_cache_max_size"`
+
 	MemPoolSizeClasses map[int]int `json:"mem_pool_size_classes"`
 
 	// CodeModesPutQuorums
 	// just for one AZ is down, cant write quorum in all AZs
 	CodeModesPutQuorums map[codemode.CodeMode]int `json:"code_mode_put_quorums"`
+	// CodeModesDegradedPutQuorums
+	// put quorums when any IDC is write degraded for planned maintenance,
+	// shards in write degraded IDC are not written and repaired later
+	CodeModesDegradedPutQuorums map[codemode.CodeMode]int `json:"code_mode_degraded_put_quorums"`
+	WriteDegradedReloadS        int                       `json:"write_degraded_reload_s"`
+	// CodeModesAdaptivePutQuorums
+	// put succeeds with the adaptive quorum if the put quorum is still not reached
+	// in AdaptivePutWaitMS after the adaptive quorum is reached, such as data and half
+	// of parity shards, the remaining shards are written in background and repaired if failed
+	CodeModesAdaptivePutQuorums map[codemode.CodeMode]int `json:"code_mode_adaptive_put_quorums"`
+	AdaptivePutWaitMS           int                       `json:"adaptive_put_wait_ms"`
+
+	// MaxPutBuffers the max ec buffers of blobs held by one put, reading of the body
+	// is blocked until the written blobs release their buffers
+	MaxPutBuffers int           `json:"max_put_buffers"`
+	PutBodyConfig PutBodyConfig `json:"put_body_config"`
 
 	ClusterConfig  controller.ClusterConfig `json:"cluster_config"`
 	BlobnodeConfig blobnode.Config          `json:"blobnode_config"`
@@ -170,6 +191,9 @@ type Handler struct {
 	discardVidChan chan discardVid
 	stopCh         <-chan struct{}
 
+	// write degraded idcs, map[string]struct{}
+	writeDegradedIDC atomic.Value
+
 	StreamConfig
 }
 
@@ -192,6 +216,18 @@ func confCheck(cfg *StreamConfig) error {
 			return errors.Newf("invalid put quorum(%d) in codemode(%d): %+v", quorum, mode, tactic)
 		}
 	}
+	for mode, quorum := range cfg.CodeModesDegradedPutQuorums {
+		tactic := mode.Tactic()
+		if quorum < tactic.N+tactic.L+1 || quorum > mode.GetShardNum() {
+			return errors.Newf("invalid degraded put quorum(%d) in codemode(%d): %+v", quorum, mode, tactic)
+		}
+	}
+	for mode, quorum := range cfg.CodeModesAdaptivePutQuorums {
+		tactic := mode.Tactic()
+		if quorum < tactic.N+tactic.L+1 || quorum > mode.GetShardNum() {
+			return errors.Newf("invalid adaptive put quorum(%d) in codemode(%d): %+v", quorum, mode, tactic)
+		}
+	}
 
 	defaulter.Equal(&cfg.MaxBlobSize, defaultMaxBlobSize)
 	defaulter.LessOrEqual(&cfg.DiskPunishIntervalS, defaultDiskPunishIntervalS)
@@ -203,6 +239,10 @@ func confCheck(cfg *StreamConfig) error {
 	}
 	defaulter.LessOrEqual(&cfg.EncoderConcurrency, defaultEncoderConcurrency)
 	defaulter.LessOrEqual(&cfg.MinReadShardsX, defaultMinReadShardsX)
+	defaulter.LessOrEqual(&cfg.BlobCacheMaxSize, defaultBlobCacheMaxSize)
+	defaulter.LessOrEqual(&cfg.WriteDegradedReloadS, defaultWriteDegradedReloadS)
+	defaulter.LessOrEqual(&cfg.AdaptivePutWaitMS, defaultAdaptivePutWaitMS)
+	defaulter.LessOrEqual(&cfg.MaxPutBuffers, defaultMaxPutBuffers)
 
 	defaulter.LessOrEqual(&cfg.ClusterConfig.CMClientConfig.Config.ClientTimeoutMs, defaultTimeoutClusterMgr)
 	defaulter.LessOrEqual(&cfg.BlobnodeConfig.ClientTimeoutMs, defaultTimeoutBlobnode)
@@ -302,6 +342,7 @@ func NewStreamHandler(cfg *StreamConfig, stopCh <-chan struct{}) (h StreamHandle
 	handler.discardVidChan = make(chan discardVid, 8)
 	handler.stopCh = stopCh
 	handler.loopDiscardVids()
+	handler.loopReloadWriteDegradedIDC()
 	return handler, nil
 }
 
@@ -421,9 +462,36 @@ func (h *Handler) clearGarbage(ctx context.Context, location *access.Location) e
 	}
 
 	span.Infof("send delete message(%+v)", logMsg)
+	if h.BlobCacheEnable {
+		h.eraseBlobCache(ctx, serviceController, deleteArgs.Blobs)
+	}
 	return nil
 }
 
+// eraseBlobCache invalidates the deleted blobs in the blob cache of all proxies in background,
+// proxy which missed it keeps the deleted blobs readable until blob_expiration_seconds at most.
+func (h *Handler) eraseBlobCache(ctx context.Context, serviceController controller.ServiceController, blobs []proxy.BlobDelete) {
+	span := trace.SpanFromContextSafe(ctx)
+	hosts, err := serviceController.GetServiceHosts(ctx, serviceProxy)
+	if err != nil {
+		span.Warn("get proxy hosts to erase blob cache", err)
+		return
+	}
+
+	for _, host := range hosts {
+		bgSpan, bgCtx := trace.StartSpanFromContextWithTraceID(context.Background(), "erase_proxy_blob", span.TraceID())
+		go func(host string) {
+			defer bgSpan.Finish()
+			for _, blob := range blobs {
+				args := proxy.CacheBlobArgs{Vid: blob.Vid, Bid: blob.Bid}
+				if err := h.proxyClient.Erase(bgCtx, host, args.BlobKey()); err != nil {
+					bgSpan.Warnf("erase blob cache %s on %s: %s", args.BlobKey(), host, err.Error())
+				}
+			}
+		}(host)
+	}
+}
+
 // getVolume get volume info
 func (h *Handler) getVolume(ctx context.Context, clusterID proto.ClusterID, vid proto.Vid, isCache bool) (*controller.VolumePhy, error) {
 	volumeGetter, err := h.clusterController.GetVolumeGetter(clusterID)
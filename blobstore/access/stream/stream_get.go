@@ -152,7 +152,12 @@ func (h *Handler) Get(ctx context.Context, w io.Writer, location access.Location
 				span.Debugf("read data shard only %s readsize:%d blobsize:%d shardsize:%d",
 					blob.ID(), blob.ReadSize, blob.BlobSize, blob.ShardSize)
 
-				err := h.getDataShardOnly(ctx, getTime, w, serviceController, blob)
+				var err error
+				if h.BlobCacheEnable && blob.ReadSize <= uint64(h.BlobCacheMaxSize) {
+					err = h.getDataShardWithCache(ctx, getTime, w, serviceController, blob)
+				} else {
+					err = h.getDataShardOnly(ctx, getTime, w, serviceController, blob)
+				}
 				if err != errNeedReconstructRead {
 					if err != nil {
 						span.Error("read data shard only", err)
@@ -191,7 +196,7 @@ func (h *Handler) Get(ctx context.Context, w io.Writer, location access.Location
 						}
 
 						// do not use local shards
-						sortedVuids = genSortedVuidByIDC(ctx, serviceController, h.IDC, blobVolume.Units[:tactic.N+tactic.M])
+						sortedVuids = genSortedVuidByIDC(ctx, serviceController, h.IDC, tactic, blobVolume.Units[:tactic.N+tactic.M], h.isWriteDegradedIDC)
 						span.Debugf("to read %s with read-shard-x:%d active-shard-n:%d of data-n:%d party-n:%d",
 							blob.ID(), h.MinReadShardsX, len(sortedVuids), tactic.N, tactic.M)
 						if len(sortedVuids) < tactic.N {
@@ -775,12 +780,15 @@ func genLocationBlobs(location *access.Location, readSize uint64, offset uint64)
 }
 
 func genSortedVuidByIDC(ctx context.Context,
-	serviceController controller.ServiceController, idc string, vuidPhys []controller.Unit,
+	serviceController controller.ServiceController, idc string, tactic codemode.Tactic,
+	vuidPhys []controller.Unit, degradedIDC func(idc string) bool,
 ) []sortedVuid {
 	span := trace.SpanFromContextSafe(ctx)
 
 	vuids := make([]sortedVuid, 0, len(vuidPhys))
 	sortMap := make(map[int][]sortedVuid)
+	costs := make([]int, len(vuidPhys))
+	badIdx := make([]int, 0)
 
 	for idx, phy := range vuidPhys {
 		var hostIDC *controller.HostIDC
@@ -793,10 +801,13 @@ func genSortedVuidByIDC(ctx context.Context,
 			return nil
 		}); err != nil {
 			span.Warnf("no host of disk(%d %d) %s", phy.Vuid, phy.DiskID, err.Error())
+			badIdx = append(badIdx, idx)
 			continue
 		}
 
-		dis := distance(idc, hostIDC.IDC, hostIDC.Punished)
+		// read from write degraded idc as punished
+		dis := distance(idc, hostIDC.IDC, hostIDC.Punished || degradedIDC(hostIDC.IDC))
+		costs[idx] = distanceCosts[dis]
 		if _, ok := sortMap[dis]; !ok {
 			sortMap[dis] = make([]sortedVuid, 0, 8)
 		}
@@ -825,9 +836,30 @@ func genSortedVuidByIDC(ctx context.Context,
 		}
 	}
 
-	return vuids
+	// read the minimal-cost survival shards firstly, and the others by distance
+	survival, err := ec.GetSurvivalShards(tactic, badIdx, costs)
+	if err != nil {
+		return vuids
+	}
+	first := make(map[int]int, len(survival))
+	for i, idx := range survival {
+		first[idx] = i
+	}
+	sorted := make([]sortedVuid, len(survival), len(vuids))
+	for _, vuid := range vuids {
+		if i, ok := first[vuid.index]; ok {
+			sorted[i] = vuid
+		} else {
+			sorted = append(sorted, vuid)
+		}
+	}
+	return sorted
 }
 
+// distanceCosts is the read cost weight of the distance, the cross idc and the punished
+// shards are read at last
+var distanceCosts = [...]int{1, 10, 100, 1000}
+
 func distance(idc1, idc2 string, punished bool) int {
 	if punished {
 		if idc1 == idc2 {
@@ -0,0 +1,94 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/access/controller"
+	"github.com/cubefs/cubefs/blobstore/api/proxy"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+// getDataShardWithCache reads small blob range through the blob cache of proxy,
+// fills the cache with data read from blobnodes if missed.
+func (h *Handler) getDataShardWithCache(ctx context.Context, getTime *timeReadWrite,
+	w io.Writer, serviceController controller.ServiceController, blob blobGetArgs,
+) error {
+	span := trace.SpanFromContextSafe(ctx)
+	if blob.ReadSize == 0 {
+		return nil
+	}
+
+	args := &proxy.CacheBlobArgs{
+		Vid:    blob.Vid,
+		Bid:    blob.Bid,
+		Offset: blob.Offset,
+		Size:   blob.ReadSize,
+	}
+	host, err := serviceController.GetServiceHost(ctx, serviceProxy)
+	if err != nil {
+		span.Warn("get proxy host for blob cache", err)
+		return h.getDataShardOnly(ctx, getTime, w, serviceController, blob)
+	}
+
+	startRead := time.Now()
+	data, err := h.proxyClient.GetCacheBlob(ctx, host, args)
+	getTime.IncR(time.Since(startRead))
+	if err == nil {
+		reportDownload(blob.Cid, "Cache", "hit")
+		startWrite := time.Now()
+		_, err = w.Write(data)
+		getTime.IncW(time.Since(startWrite))
+		if err != nil {
+			return errors.Info(err, "write to response")
+		}
+		return nil
+	}
+	if rpc.DetectStatusCode(err) != errcode.CodeBlobCacheMiss {
+		span.Warnf("get blob cache %s from %s: %s", blob.ID(), host, err.Error())
+	}
+	reportDownload(blob.Cid, "Cache", "miss")
+
+	buffer := bytes.NewBuffer(make([]byte, 0, blob.ReadSize))
+	if err = h.getDataShardOnly(ctx, getTime, buffer, serviceController, blob); err != nil {
+		return err
+	}
+	data = buffer.Bytes()
+
+	// new child span to fill the cache in background, we should finish it here.
+	spanFill, ctxFill := trace.StartSpanFromContextWithTraceID(
+		context.Background(), "PutCacheBlob", span.TraceID())
+	go func() {
+		defer spanFill.Finish()
+		if err := h.proxyClient.PutCacheBlob(ctxFill, host, args, data); err != nil {
+			spanFill.Warnf("put blob cache %s to %s: %s", blob.ID(), host, err.Error())
+		}
+	}()
+
+	startWrite := time.Now()
+	_, err = w.Write(data)
+	getTime.IncW(time.Since(startWrite))
+	if err != nil {
+		return errors.Info(err, "write to response")
+	}
+	return nil
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/cubefs/cubefs/blobstore/api/access"
+	"github.com/cubefs/cubefs/blobstore/api/proxy"
 	"github.com/cubefs/cubefs/blobstore/common/codemode"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 )
@@ -197,6 +198,50 @@ func TestAccessStreamGetOffset(t *testing.T) {
 	dataShards.clean()
 }
 
+func TestAccessStreamGetCache(t *testing.T) {
+	ctx := ctxWithName("TestAccessStreamGetCache")
+	streamer.BlobCacheEnable = true
+	streamer.BlobCacheMaxSize = 1 << 10
+	defer func() {
+		streamer.BlobCacheEnable = false
+		streamer.BlobCacheMaxSize = 0
+	}()
+
+	dataShards.clean()
+	data := make([]byte, 1<<10)
+	rand.Read(data)
+	loc, err := streamer.Put(ctx(), bytes.NewReader(data), int64(len(data)), nil)
+	require.NoError(t, err)
+
+	// miss and fill the cache
+	buff := bytes.NewBuffer(nil)
+	transfer, err := streamer.Get(ctx(), buff, *loc, 100, 10)
+	require.NoError(t, err)
+	require.NoError(t, transfer())
+	require.True(t, dataEqual(data[10:110], buff.Bytes()))
+
+	// hit the cache without any shard
+	require.Eventually(t, func() bool {
+		args := proxy.CacheBlobArgs{Vid: loc.Blobs[0].Vid, Bid: loc.Blobs[0].MinBid, Offset: 10, Size: 100}
+		_, ok := blobCache.Load(args.Key())
+		return ok
+	}, time.Second, 10*time.Millisecond)
+	dataShards.clean()
+	buff.Reset()
+	transfer, err = streamer.Get(ctx(), buff, *loc, 100, 10)
+	require.NoError(t, err)
+	require.NoError(t, transfer())
+	require.True(t, dataEqual(data[10:110], buff.Bytes()))
+
+	// invalidated on all proxies after deleted
+	require.NoError(t, streamer.Delete(ctx(), loc))
+	require.Eventually(t, func() bool {
+		args := proxy.CacheBlobArgs{Vid: loc.Blobs[0].Vid, Bid: loc.Blobs[0].MinBid, Offset: 10, Size: 100}
+		_, ok := blobCache.Load(args.Key())
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+}
+
 func TestAccessStreamGetShardTimeout(t *testing.T) {
 	ctx := ctxWithName("TestAccessStreamGetShardTimeout")
 	dataShards.clean()
@@ -354,6 +399,44 @@ func TestAccessStreamGetLocalIDC(t *testing.T) {
 	}()
 }
 
+func TestAccessStreamGetWriteDegradedIDC(t *testing.T) {
+	ctx := ctxWithName("TestAccessStreamGetWriteDegradedIDC")
+	dataShards.clean()
+	vuidController.Unbreak(1005)
+	defer func() {
+		vuidController.Break(1005)
+		dataShards.clean()
+	}()
+
+	size := 1 << 22
+	buff := make([]byte, size)
+	rand.Read(buff)
+	loc, err := streamer.Put(ctx(), bytes.NewReader(buff), int64(size), nil)
+	require.NoError(t, err)
+
+	streamer.writeDegradedIDC.Store(map[string]struct{}{idc: {}})
+	defer streamer.writeDegradedIDC.Store(map[string]struct{}{})
+	require.True(t, streamer.isWriteDegradedIDC(idc))
+	require.False(t, streamer.isWriteDegradedIDC(idcOther))
+
+	// no delay when blocking local idc all shards, cos local idc is write degraded
+	for _, id := range idcID {
+		vuidController.Block(proto.Vuid(id))
+	}
+	defer func() {
+		for _, id := range idcID {
+			vuidController.Unblock(proto.Vuid(id))
+		}
+	}()
+	startTime := time.Now()
+	transfer, _ := streamer.Get(ctx(), bytes.NewBuffer(nil), *loc, uint64(size), 0)
+	err = transfer()
+	require.NoError(t, err)
+
+	duration := time.Since(startTime)
+	require.GreaterOrEqual(t, vuidController.duration, duration, "greater duration: ", duration)
+}
+
 func TestAccessStreamGetAligned(t *testing.T) {
 	ctx := ctxWithName("TestAccessStreamGetAligned")
 	defer func() {
@@ -16,11 +16,13 @@ package stream
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"github.com/cubefs/cubefs/blobstore/api/proxy"
 	"github.com/cubefs/cubefs/blobstore/common/memcache"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
 	"github.com/cubefs/cubefs/blobstore/common/trace"
 	"github.com/cubefs/cubefs/blobstore/util/errors"
 )
@@ -119,3 +121,57 @@ func (h *Handler) tryDiscardVidOnAllocator(cid proto.ClusterID, vid proto.Vid, a
 		span.Warnf("post on %s discard vids %+v : %v", host, args, err)
 	}
 }
+
+// loopReloadWriteDegradedIDC reloads write degraded idcs from cluster manager
+func (h *Handler) loopReloadWriteDegradedIDC() {
+	h.reloadWriteDegradedIDC()
+	go func() {
+		ticker := time.NewTicker(time.Second * time.Duration(h.WriteDegradedReloadS))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-h.stopCh:
+				return
+			case <-ticker.C:
+				h.reloadWriteDegradedIDC()
+			}
+		}
+	}()
+}
+
+func (h *Handler) reloadWriteDegradedIDC() {
+	span, ctx := trace.StartSpanFromContext(context.Background(), "")
+
+	value, err := h.clusterController.GetConfig(ctx, proto.WriteDegradedIDCConfigKey)
+	if err != nil {
+		if rpc.DetectStatusCode(err) != http.StatusNotFound {
+			span.Warnf("get write degraded idc failed: %v", err)
+			return
+		}
+		value = ""
+	}
+
+	idcs := make(map[string]struct{})
+	for _, idc := range proto.ParseIDCs(value) {
+		idcs[idc] = struct{}{}
+	}
+	if len(idcs) > 0 {
+		span.Infof("write degraded idc: %v", value)
+	}
+	h.writeDegradedIDC.Store(idcs)
+}
+
+func (h *Handler) isWriteDegradedIDC(idc string) bool {
+	idcs, ok := h.writeDegradedIDC.Load().(map[string]struct{})
+	if !ok {
+		return false
+	}
+	_, degraded := idcs[idc]
+	return degraded
+}
+
+func (h *Handler) hasWriteDegradedIDC() bool {
+	idcs, ok := h.writeDegradedIDC.Load().(map[string]struct{})
+	return ok && len(idcs) > 0
+}
@@ -27,6 +27,7 @@ import (
 	"math"
 	"math/rand"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -71,6 +72,7 @@ var (
 	memPool     *resourcepool.MemPool
 	encoder     map[codemode.CodeMode]ec.Encoder
 	proxyClient proxy.Client
+	blobCache   sync.Map
 
 	allCodeModes CodeModePairs
 
@@ -398,6 +400,28 @@ func initMockData() {
 			}
 			return dataAllocs, nil
 		})
+	allocCli.EXPECT().GetCacheBlob(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(ctx context.Context, host string, args *proxy.CacheBlobArgs) ([]byte, error) {
+			if val, ok := blobCache.Load(args.Key()); ok {
+				return val.([]byte), nil
+			}
+			return nil, errcode.ErrBlobCacheMiss
+		})
+	allocCli.EXPECT().PutCacheBlob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(ctx context.Context, host string, args *proxy.CacheBlobArgs, data []byte) error {
+			blobCache.Store(args.Key(), data)
+			return nil
+		})
+	allocCli.EXPECT().Erase(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(ctx context.Context, host string, key string) error {
+			blobCache.Range(func(k, _ interface{}) bool {
+				if strings.HasPrefix(k.(string), key+"_") {
+					blobCache.Delete(k)
+				}
+				return true
+			})
+			return nil
+		})
 	proxyClient = allocCli
 }
 
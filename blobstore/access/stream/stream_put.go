@@ -26,8 +26,10 @@ import (
 
 	"github.com/afex/hystrix-go/hystrix"
 
+	"github.com/cubefs/cubefs/blobstore/access/controller"
 	"github.com/cubefs/cubefs/blobstore/api/access"
 	"github.com/cubefs/cubefs/blobstore/api/blobnode"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
 	"github.com/cubefs/cubefs/blobstore/common/ec"
 	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
@@ -102,10 +104,13 @@ func (h *Handler) Put(ctx context.Context,
 		span.AppendRPCTrackLog([]string{putTime.String()})
 	}()
 
-	// concurrent buffer in per request
-	const concurrence = 4
+	// concurrent buffer in per request, caps the memory held by one uploader
+	concurrence := h.MaxPutBuffers
+	if concurrence <= 0 {
+		concurrence = defaultMaxPutBuffers
+	}
 	ready := make(chan struct{}, concurrence)
-	for range [concurrence]struct{}{} {
+	for i := 0; i < concurrence; i++ {
 		ready <- struct{}{}
 	}
 
@@ -164,6 +169,12 @@ func (h *Handler) Put(ctx context.Context,
 		}
 	}
 
+	// the body must be exactly the declared size
+	if hasTrailingBody(rc) {
+		span.Infof("request body is longer than size:%d", size)
+		return nil, errcode.ErrAccessReadRequestBody
+	}
+
 	uploadSucc = true
 	return location, nil
 }
@@ -193,6 +204,35 @@ type shardPutStatus struct {
 // writeToBlobnodes write shards to blobnodes.
 // takeover ec buffer release by callback.
 // return if had quorum successful shards, then wait all shards in background.
+// defaultDegradedPutQuorum returns the put quorum of the data and parity shards if the shards
+// in write degraded idc are skipped, which is at least N+L+1 to tolerate one more failed shard.
+// It's not ok to skip them if the quorum can't be reached without them.
+func defaultDegradedPutQuorum(tactic codemode.Tactic, skipped int) (quorum int, ok bool) {
+	quorum = tactic.N + tactic.M - skipped
+	if quorum < tactic.N+tactic.L+1 {
+		return 0, false
+	}
+	return quorum, true
+}
+
+// countWriteDegradedShards returns the number of the units on the disks in write degraded idc.
+func (h *Handler) countWriteDegradedShards(ctx context.Context,
+	serviceController controller.ServiceController, units []controller.Unit,
+) (count int) {
+	span := trace.SpanFromContextSafe(ctx)
+	for _, unit := range units {
+		hostInfo, err := serviceController.GetDiskHost(ctx, unit.DiskID)
+		if err != nil {
+			span.Warnf("get disk(%d) host failed: %v", unit.DiskID, err)
+			continue
+		}
+		if h.isWriteDegradedIDC(hostInfo.IDC) {
+			count++
+		}
+	}
+	return
+}
+
 func (h *Handler) writeToBlobnodes(ctx context.Context,
 	blob blobIdent, shards [][]byte, callback func(),
 ) (err error) {
@@ -223,6 +263,24 @@ func (h *Handler) writeToBlobnodes(ctx context.Context,
 	if num, ok := h.CodeModesPutQuorums[volume.CodeMode]; ok && num <= tactic.N+tactic.M {
 		putQuorum = uint32(num)
 	}
+	// shards in write degraded idc are skipped only if the degraded put quorum can be reached
+	skipDegraded := false
+	if h.hasWriteDegradedIDC() {
+		if num, ok := h.CodeModesDegradedPutQuorums[volume.CodeMode]; ok && num <= tactic.N+tactic.M {
+			putQuorum = uint32(num)
+			skipDegraded = true
+		} else if num, ok := defaultDegradedPutQuorum(tactic,
+			h.countWriteDegradedShards(ctx, serviceController, volume.Units[:tactic.N+tactic.M])); ok {
+			if uint32(num) < putQuorum {
+				putQuorum = uint32(num)
+			}
+			skipDegraded = true
+		}
+	}
+	adaptiveQuorum := putQuorum
+	if num, ok := h.CodeModesAdaptivePutQuorums[volume.CodeMode]; ok && uint32(num) < putQuorum {
+		adaptiveQuorum = uint32(num)
+	}
 
 	// writtenNum ONLY apply on data and partiy shards
 	// TODO: count N and M in each AZ,
@@ -273,6 +331,12 @@ func (h *Handler) writeToBlobnodes(ctx context.Context,
 					diskID, hostInfo.Host, unit.Vuid, index, hostInfo.IDC)
 				return
 			}
+			// write degraded idc, ignore and repair it later
+			if skipDegraded && h.isWriteDegradedIDC(hostInfo.IDC) {
+				span.Debugf("ignore disk(%d %s) uvid(%d) ecidx(%02d) in write degraded idc(%s)",
+					diskID, hostInfo.Host, unit.Vuid, index, hostInfo.IDC)
+				return
+			}
 			host := hostInfo.Host
 
 			var (
@@ -373,9 +437,25 @@ func (h *Handler) writeToBlobnodes(ctx context.Context,
 	}
 
 	received := make(map[int]shardPutStatus, len(volume.Units))
+	// waiting stragglers at most AdaptivePutWaitMS after adaptive quorum is reached
+	var adaptiveC <-chan time.Time
+	adaptive := false
+WAIT:
 	for len(received) < len(volume.Units) && atomic.LoadUint32(&writtenNum) < putQuorum {
-		st := <-statusCh
-		received[st.index] = st
+		select {
+		case st := <-statusCh:
+			received[st.index] = st
+			if adaptiveC == nil && adaptiveQuorum < putQuorum && atomic.LoadUint32(&writtenNum) >= adaptiveQuorum {
+				timer := time.NewTimer(time.Duration(h.AdaptivePutWaitMS) * time.Millisecond)
+				defer timer.Stop()
+				adaptiveC = timer.C
+			}
+		case <-adaptiveC:
+			if atomic.LoadUint32(&writtenNum) < putQuorum {
+				adaptive = true
+			}
+			break WAIT
+		}
 	}
 
 	writeDone := make(chan struct{}, 1)
@@ -400,6 +480,13 @@ func (h *Handler) writeToBlobnodes(ctx context.Context,
 		if len(badIdxes) > 0 {
 			h.sendRepairMsgBg(ctx, blob, badIdxes)
 		}
+		if adaptive {
+			if len(badIdxes) > 0 {
+				reportAdaptivePut(clusterID, "repair")
+			} else {
+				reportAdaptivePut(clusterID, "completed")
+			}
+		}
 	}(writeDone)
 
 	// return if had quorum successful shards
@@ -407,6 +494,14 @@ func (h *Handler) writeToBlobnodes(ctx context.Context,
 		writeDone <- struct{}{}
 		return
 	}
+	// return if had adaptive quorum successful shards, remaining shards are tracked in background
+	if adaptive {
+		span.Warnf("adaptive quorum write (%d < %d) of %s, remaining shards in background",
+			atomic.LoadUint32(&writtenNum), putQuorum, blob.String())
+		reportAdaptivePut(clusterID, "adaptive")
+		writeDone <- struct{}{}
+		return
+	}
 
 	// It tolerate one az was down when we have 3 or more azs.
 	// But MUST make sure others azs data is all completed,
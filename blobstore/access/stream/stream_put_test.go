@@ -28,6 +28,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/cubefs/cubefs/blobstore/api/access"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
 	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 )
@@ -259,6 +260,45 @@ func TestAccessStreamPutShardTimeout(t *testing.T) {
 	}
 }
 
+func TestAccessStreamPutAdaptiveQuorum(t *testing.T) {
+	ctx := ctxWithName("TestAccessStreamPutAdaptiveQuorum")
+	dataShards.clean()
+	vuidController.Unbreak(1005)
+	vuidController.Block(1001)
+	vuidController.Block(1002)
+	streamer.CodeModesAdaptivePutQuorums = map[codemode.CodeMode]int{codemode.EC6P6: 10}
+	streamer.AdaptivePutWaitMS = 50
+	defer func() {
+		vuidController.Unblock(1001)
+		vuidController.Unblock(1002)
+		vuidController.Break(1005)
+		streamer.CodeModesAdaptivePutQuorums = nil
+		streamer.AdaptivePutWaitMS = defaultAdaptivePutWaitMS
+		dataShards.clean()
+	}()
+
+	size := 1 << 22
+	buff := make([]byte, size)
+	rand.Read(buff)
+
+	// response after waiting stragglers if had adaptive quorum shards
+	startTime := time.Now()
+	loc, err := streamer.Put(ctx(), bytes.NewReader(buff), int64(size), nil)
+	require.NoError(t, err)
+	duration := time.Since(startTime)
+	require.GreaterOrEqual(t, vuidController.duration*2, duration, "greater duration: ", duration)
+
+	transfer, err := streamer.Get(ctx(), bytes.NewBuffer(nil), *loc, uint64(size), 0)
+	require.NoError(t, err)
+	require.NoError(t, transfer())
+
+	// fail if adaptive quorum is not reached
+	vuidController.Block(1003)
+	_, err = streamer.Put(ctx(), bytes.NewReader(buff), int64(size), nil)
+	require.Error(t, err)
+	vuidController.Unblock(1003)
+}
+
 func TestAccessStreamPutQuorum(t *testing.T) {
 	ctx := ctxWithName("TestAccessStreamPutQuorum")
 	defer func() {
@@ -313,6 +353,73 @@ func TestAccessStreamPutQuorum(t *testing.T) {
 	}
 }
 
+func TestAccessStreamPutDefaultDegradedQuorum(t *testing.T) {
+	for _, cs := range []struct {
+		mode    codemode.CodeMode
+		skipped int
+		quorum  int
+		ok      bool
+	}{
+		{codemode.EC6P6, 0, 12, true},
+		{codemode.EC6P6, 4, 8, true}, // one of three az
+		{codemode.EC6P6, 5, 7, true},
+		{codemode.EC6P6, 6, 0, false},
+		{codemode.EC12P9, 7, 14, true},
+		{codemode.EC6P10L2, 8, 8, false}, // one of two az
+		{codemode.EC6P10L2, 7, 9, true},
+	} {
+		quorum, ok := defaultDegradedPutQuorum(cs.mode.Tactic(), cs.skipped)
+		require.Equal(t, cs.ok, ok, cs.mode.String(), cs.skipped)
+		if ok {
+			require.Equal(t, cs.quorum, quorum, cs.mode.String(), cs.skipped)
+		}
+	}
+}
+
+func TestAccessStreamPutWriteDegradedIDC(t *testing.T) {
+	ctx := ctxWithName("TestAccessStreamPutWriteDegradedIDC")
+	dataShards.clean()
+	vuidController.Unbreak(1005)
+	streamer.writeDegradedIDC.Store(map[string]struct{}{idcOther: {}})
+	defer func() {
+		streamer.writeDegradedIDC.Store(map[string]struct{}{})
+		streamer.CodeModesDegradedPutQuorums = nil
+		vuidController.Break(1005)
+		dataShards.clean()
+	}()
+
+	size := 1 << 20
+	buff := make([]byte, size)
+	rand.Read(buff)
+	written := func(bid proto.BlobID, ids []int) (n int) {
+		for _, id := range ids {
+			if len(dataShards.get(proto.Vuid(id), bid)) > 0 {
+				n++
+			}
+		}
+		return
+	}
+
+	// default config, half of the shards are in the write degraded idc of the mock,
+	// they are not skipped because the put quorum can't be reached without them
+	loc, err := streamer.Put(ctx(), bytes.NewReader(buff), int64(size), nil)
+	require.NoError(t, err)
+	bid := loc.Blobs[0].MinBid
+	require.Eventually(t, func() bool { return written(bid, idcOtherID) == len(idcOtherID) },
+		time.Second, 10*time.Millisecond)
+	require.Equal(t, len(idcID), written(bid, idcID))
+
+	// skipped with the configured degraded put quorum
+	streamer.CodeModesDegradedPutQuorums = map[codemode.CodeMode]int{codemode.EC6P6: 6}
+	dataShards.clean()
+	loc, err = streamer.Put(ctx(), bytes.NewReader(buff), int64(size), nil)
+	require.NoError(t, err)
+	bid = loc.Blobs[0].MinBid
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, 0, written(bid, idcOtherID))
+	require.Equal(t, len(idcID), written(bid, idcID))
+}
+
 func BenchmarkAccessStreamPut(b *testing.B) {
 	ctx := ctxWithName("BenchmarkAccessStreamPut")()
 	vuidController.Unbreak(1005)
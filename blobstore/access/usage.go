@@ -0,0 +1,160 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package access
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/access/controller"
+	"github.com/cubefs/cubefs/blobstore/api/access"
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+)
+
+const defaultUsageReportIntervalS = 60
+
+type usageKey struct {
+	clusterID proto.ClusterID
+	tenant    string
+	codeMode  codemode.CodeMode
+}
+
+// usageReporter accumulates the usage changed by puts and deletes of tenants,
+// and reports it to cluster manager of every cluster periodically.
+// The usage not reported is merged into the next report.
+type usageReporter struct {
+	controller controller.ClusterController
+
+	mu     sync.Mutex
+	deltas map[usageKey]*clustermgr.UsageDelta
+}
+
+func newUsageReporter(cc controller.ClusterController, intervalS int, stopCh <-chan struct{}) *usageReporter {
+	r := &usageReporter{
+		controller: cc,
+		deltas:     make(map[usageKey]*clustermgr.UsageDelta),
+	}
+	go func() {
+		ticker := time.NewTicker(time.Duration(intervalS) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.report()
+			case <-stopCh:
+				r.report()
+				return
+			}
+		}
+	}()
+	return r
+}
+
+func locationBlobs(loc *access.Location) (blobs int64) {
+	for _, blob := range loc.Blobs {
+		blobs += int64(blob.Count)
+	}
+	return
+}
+
+func (r *usageReporter) add(tenant string, loc *access.Location, deleted bool) {
+	if r == nil || loc.Size == 0 {
+		return
+	}
+	if tenant == "" {
+		tenant = clustermgr.DefaultTenant
+	}
+	key := usageKey{clusterID: loc.ClusterID, tenant: tenant, codeMode: loc.CodeMode}
+
+	r.mu.Lock()
+	delta, ok := r.deltas[key]
+	if !ok {
+		delta = &clustermgr.UsageDelta{Tenant: tenant, CodeMode: loc.CodeMode}
+		r.deltas[key] = delta
+	}
+	if deleted {
+		delta.DeleteBytes += int64(loc.Size)
+		delta.DeleteBlobs += locationBlobs(loc)
+	} else {
+		delta.PutBytes += int64(loc.Size)
+		delta.PutBlobs += locationBlobs(loc)
+	}
+	r.mu.Unlock()
+}
+
+// Put accounts the location put by the tenant.
+func (r *usageReporter) Put(tenant string, loc *access.Location) {
+	r.add(tenant, loc, false)
+}
+
+// Delete accounts the location deleted by the tenant.
+func (r *usageReporter) Delete(tenant string, loc *access.Location) {
+	r.add(tenant, loc, true)
+}
+
+func (r *usageReporter) report() {
+	r.mu.Lock()
+	deltas := r.deltas
+	r.deltas = make(map[usageKey]*clustermgr.UsageDelta)
+	r.mu.Unlock()
+	if len(deltas) == 0 {
+		return
+	}
+
+	clusters := make(map[proto.ClusterID][]clustermgr.UsageDelta)
+	for key, delta := range deltas {
+		clusters[key.clusterID] = append(clusters[key.clusterID], *delta)
+	}
+
+	span, ctx := trace.StartSpanFromContext(context.Background(), "")
+	for clusterID, clusterDeltas := range clusters {
+		var err error
+		for len(clusterDeltas) > 0 && err == nil {
+			n := len(clusterDeltas)
+			if n > clustermgr.MaxUsageReportCount {
+				n = clustermgr.MaxUsageReportCount
+			}
+			if err = r.controller.ReportUsage(ctx, clusterID,
+				&clustermgr.ReportUsageArgs{Deltas: clusterDeltas[:n]}); err == nil {
+				clusterDeltas = clusterDeltas[n:]
+			}
+		}
+		if err != nil {
+			span.Warnf("report usage to cluster %d failed, deltas %d, err: %v", clusterID, len(clusterDeltas), err)
+			r.merge(clusterID, clusterDeltas)
+		}
+	}
+}
+
+func (r *usageReporter) merge(clusterID proto.ClusterID, deltas []clustermgr.UsageDelta) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range deltas {
+		key := usageKey{clusterID: clusterID, tenant: d.Tenant, codeMode: d.CodeMode}
+		delta, ok := r.deltas[key]
+		if !ok {
+			delta = &clustermgr.UsageDelta{Tenant: d.Tenant, CodeMode: d.CodeMode}
+			r.deltas[key] = delta
+		}
+		delta.PutBytes += d.PutBytes
+		delta.PutBlobs += d.PutBlobs
+		delta.DeleteBytes += d.DeleteBytes
+		delta.DeleteBlobs += d.DeleteBlobs
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package access
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/access/controller"
+	"github.com/cubefs/cubefs/blobstore/api/access"
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+type usageController struct {
+	controller.ClusterController
+	err     error
+	reports map[proto.ClusterID][]clustermgr.UsageDelta
+}
+
+func (c *usageController) ReportUsage(ctx context.Context, clusterID proto.ClusterID, args *clustermgr.ReportUsageArgs) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.reports[clusterID] = append(c.reports[clusterID], args.Deltas...)
+	return nil
+}
+
+func TestAccessUsageReporter(t *testing.T) {
+	cc := &usageController{reports: make(map[proto.ClusterID][]clustermgr.UsageDelta)}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	r := newUsageReporter(cc, 3600, stopCh)
+
+	loc := func(cid proto.ClusterID, mode codemode.CodeMode, size uint64, blobs uint32) *access.Location {
+		return &access.Location{
+			ClusterID: cid, CodeMode: mode, Size: size,
+			Blobs: []access.SliceInfo{{MinBid: 1, Vid: 1, Count: blobs}},
+		}
+	}
+	r.Put("t1", loc(1, codemode.EC6P6, 100, 1))
+	r.Put("t1", loc(1, codemode.EC6P6, 200, 2))
+	r.Delete("t1", loc(1, codemode.EC6P6, 100, 1))
+	r.Put("", loc(2, codemode.EC15P12, 300, 3))
+	r.Put("t1", loc(2, codemode.EC15P12, 0, 0))
+
+	// merged back if failed
+	cc.err = errors.New("report failed")
+	r.report()
+	cc.err = nil
+	r.Put("t1", loc(1, codemode.EC6P6, 50, 1))
+	r.report()
+
+	require.Equal(t, []clustermgr.UsageDelta{
+		{Tenant: "t1", CodeMode: codemode.EC6P6, PutBytes: 350, PutBlobs: 4, DeleteBytes: 100, DeleteBlobs: 1},
+	}, cc.reports[1])
+	require.Equal(t, []clustermgr.UsageDelta{
+		{Tenant: clustermgr.DefaultTenant, CodeMode: codemode.EC15P12, PutBytes: 300, PutBlobs: 3},
+	}, cc.reports[2])
+
+	// usage of tenants in code modes are reported separately
+	for i := 0; i < clustermgr.MaxUsageReportCount+1; i++ {
+		r.Put("t1", loc(3, codemode.CodeMode(i%3+1), 1, 1))
+		r.Put("t2", loc(3, codemode.CodeMode(i%3+1), 1, 1))
+	}
+	r.report()
+	require.Equal(t, 6, len(cc.reports[3]))
+	sort.Slice(cc.reports[3], func(i, j int) bool {
+		if cc.reports[3][i].Tenant != cc.reports[3][j].Tenant {
+			return cc.reports[3][i].Tenant < cc.reports[3][j].Tenant
+		}
+		return cc.reports[3][i].CodeMode < cc.reports[3][j].CodeMode
+	})
+	require.Equal(t, int64(334), cc.reports[3][0].PutBytes)
+
+	var nilReporter *usageReporter
+	nilReporter.Put("t1", loc(1, codemode.EC6P6, 100, 1))
+}
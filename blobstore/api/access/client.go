@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"runtime"
 	"sort"
 	"sync/atomic"
@@ -218,6 +219,9 @@ type API interface {
 	// Delete all blobs in these locations.
 	// return failed locations which have yet been deleted if error is not nil.
 	Delete(ctx context.Context, args *DeleteArgs) (failedLocations []Location, err error)
+	// DeleteBatch deletes locations with bounded concurrency in access,
+	// return result of every location in the same order of args.
+	DeleteBatch(ctx context.Context, args *DeleteBatchArgs) (resp *DeleteBatchResp, err error)
 }
 
 var _ API = (*client)(nil)
@@ -407,6 +411,9 @@ func (c *client) putObject(ctx context.Context, args *PutArgs) (location Locatio
 	rpcClient := c.rpcClient.Load().(rpc.Client)
 
 	urlStr := fmt.Sprintf("/put?size=%d&hashes=%d", args.Size, args.Hashes)
+	if args.Tenant != "" {
+		urlStr += "&tenant=" + url.QueryEscape(args.Tenant)
+	}
 	req, err := http.NewRequest(http.MethodPut, urlStr, args.Body)
 	if err != nil {
 		return
@@ -544,7 +551,7 @@ func (c *client) putParts(ctx context.Context, args *PutArgs) (Location, HashSum
 			}
 		}
 		if len(locations) > 0 {
-			if _, err := c.Delete(newCtx, &DeleteArgs{Locations: locations}); err != nil {
+			if _, err := c.Delete(newCtx, &DeleteArgs{Locations: locations, Tenant: args.Tenant}); err != nil {
 				span.Warnf("clean location '%+v' failed %s", locations, err.Error())
 			}
 		}
@@ -552,7 +559,7 @@ func (c *client) putParts(ctx context.Context, args *PutArgs) (Location, HashSum
 
 	// alloc
 	allocResp := &AllocResp{}
-	if err := rpcClient.PostWith(ctx, "/alloc", allocResp, AllocArgs{Size: uint64(args.Size)}); err != nil {
+	if err := rpcClient.PostWith(ctx, "/alloc", allocResp, AllocArgs{Size: uint64(args.Size), Tenant: args.Tenant}); err != nil {
 		return allocResp.Location, nil, err
 	}
 	loc = allocResp.Location
@@ -772,7 +779,7 @@ func (c *client) Delete(ctx context.Context, args *DeleteArgs) ([]Location, erro
 		// access response 2xx even if there has failed locations
 		deleteResp := &DeleteResp{}
 		if err := rpcClient.PostWith(ctx, "/delete", deleteResp,
-			DeleteArgs{Locations: locations}); err != nil && rpc.DetectStatusCode(err) != http.StatusIMUsed {
+			DeleteArgs{Locations: locations, Tenant: args.Tenant}); err != nil && rpc.DetectStatusCode(err) != http.StatusIMUsed {
 			return err
 		}
 		if len(deleteResp.FailedLocations) > 0 {
@@ -786,6 +793,23 @@ func (c *client) Delete(ctx context.Context, args *DeleteArgs) ([]Location, erro
 	return nil, nil
 }
 
+func (c *client) DeleteBatch(ctx context.Context, args *DeleteBatchArgs) (*DeleteBatchResp, error) {
+	if !args.IsValid() {
+		return nil, errcode.ErrIllegalArguments
+	}
+	rpcClient := c.rpcClient.Load().(rpc.Client)
+
+	ctx = withReqidContext(ctx)
+	resp := &DeleteBatchResp{}
+	if err := rpcClient.PostWith(ctx, "/deletebatch", resp, args); err != nil {
+		return nil, err
+	}
+	if len(resp.Results) != len(args.Locations) {
+		return nil, errcode.ErrUnexpected
+	}
+	return resp, nil
+}
+
 func shouldRetry(code int, err error) bool {
 	if err != nil {
 		if httpErr, ok := err.(rpc.HTTPError); ok {
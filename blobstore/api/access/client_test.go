@@ -111,6 +111,7 @@ func init() {
 	handler.Handle(http.MethodPut, "/putat", handlePutAt, rpc.OptArgsQuery())
 	handler.Handle(http.MethodPost, "/get", handleGet, rpc.OptArgsBody())
 	handler.Handle(http.MethodPost, "/delete", handleDelete, rpc.OptArgsBody())
+	handler.Handle(http.MethodPost, "/deletebatch", handleDeleteBatch, rpc.OptArgsBody())
 	handler.Handle(http.MethodPost, "/sign", handleSign, rpc.OptArgsBody())
 	handler.Router.NotFound = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -345,6 +346,31 @@ func handleDelete(c *rpc.Context) {
 	c.RespondJSON(access.DeleteResp{})
 }
 
+func handleDeleteBatch(c *rpc.Context) {
+	args := new(access.DeleteBatchArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+
+	if !args.IsValid() {
+		c.RespondStatus(http.StatusBadRequest)
+		return
+	}
+	resp := access.DeleteBatchResp{Results: make([]access.DeleteBatchResult, len(args.Locations))}
+	for idx := range args.Locations {
+		switch {
+		case !verifyCrc(&args.Locations[idx]):
+			resp.Results[idx].Code = http.StatusBadRequest
+		case idx%2 == 1:
+			resp.Results[idx].Code = http.StatusInternalServerError
+		default:
+			resp.Results[idx].Code = http.StatusOK
+		}
+	}
+	c.RespondJSON(resp)
+}
+
 func handleSign(c *rpc.Context) {
 	args := new(access.SignArgs)
 	if err := c.ParseArgs(args); err != nil {
@@ -825,6 +851,29 @@ func TestAccessClientDelete(t *testing.T) {
 	}
 }
 
+func TestAccessClientDeleteBatch(t *testing.T) {
+	{
+		_, err := client.DeleteBatch(randCtx(), nil)
+		require.ErrorIs(t, errcode.ErrIllegalArguments, err)
+		_, err = client.DeleteBatch(randCtx(), &access.DeleteBatchArgs{})
+		require.ErrorIs(t, errcode.ErrIllegalArguments, err)
+		_, err = client.DeleteBatch(randCtx(), &access.DeleteBatchArgs{
+			Locations: make([]access.Location, access.MaxDeleteBatchLocations+1),
+		})
+		require.ErrorIs(t, errcode.ErrIllegalArguments, err)
+	}
+	{
+		loc := access.Location{Size: 100, Blobs: make([]access.SliceInfo, 0)}
+		fillCrc(&loc)
+		args := &access.DeleteBatchArgs{Locations: []access.Location{loc, loc, {Size: 100}, loc}}
+		resp, err := client.DeleteBatch(randCtx(), args)
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 4)
+		require.Equal(t, http.StatusBadRequest, resp.Results[2].Code)
+		require.Equal(t, []int{1, 2, 3}, resp.FailedIndexes())
+	}
+}
+
 func TestAccessClientRequestBody(t *testing.T) {
 	cfg := access.Config{}
 	cfg.MaxSizePutOnce = 1 << 20
@@ -25,6 +25,7 @@ import (
 	"hash"
 	"hash/crc32"
 	"io"
+	"net/http"
 
 	"github.com/cubefs/cubefs/blobstore/common/codemode"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
@@ -50,6 +51,8 @@ const (
 	MaxLocationBlobs int = 4
 	// MaxDeleteLocations max locations of delete request
 	MaxDeleteLocations int = 1024
+	// MaxDeleteBatchLocations max locations of delete batch request
+	MaxDeleteBatchLocations int = 8192
 	// MaxBlobSize max blob size for allocation
 	MaxBlobSize uint32 = 1 << 25 // 32MB
 )
@@ -487,7 +490,10 @@ func DecodeLocationFromBase64(s string) (Location, error) {
 type PutArgs struct {
 	Size   int64         `json:"size"`
 	Hashes HashAlgorithm `json:"hashes,omitempty"`
-	Body   io.Reader     `json:"-"`
+	// Tenant is the tenant whom the usage of the object is accounted to,
+	// it is the default tenant if empty.
+	Tenant string    `json:"tenant,omitempty"`
+	Body   io.Reader `json:"-"`
 
 	// GetBody defines an optional func to return a new copy of Body.
 	// It is used for client requests when a redirect requires reading
@@ -544,6 +550,9 @@ type AllocArgs struct {
 	BlobSize        uint32            `json:"blob_size"`
 	AssignClusterID proto.ClusterID   `json:"assign_cluster_id"`
 	CodeMode        codemode.CodeMode `json:"code_mode"`
+	// Tenant is accounted the usage of the location if AssignClusterID is not set,
+	// the locations allocated for the rest parts of a failed put are not accounted.
+	Tenant string `json:"tenant,omitempty"`
 }
 
 // IsValid is valid alloc args
@@ -587,6 +596,7 @@ func (args *GetArgs) IsValid() bool {
 // DeleteArgs for service /delete
 type DeleteArgs struct {
 	Locations []Location `json:"locations"`
+	Tenant    string     `json:"tenant,omitempty"`
 }
 
 // IsValid is valid delete args
@@ -602,6 +612,44 @@ type DeleteResp struct {
 	FailedLocations []Location `json:"failed_locations,omitempty"`
 }
 
+// DeleteBatchArgs for service /deletebatch
+type DeleteBatchArgs struct {
+	Locations []Location `json:"locations"`
+	Tenant    string     `json:"tenant,omitempty"`
+}
+
+// IsValid is valid delete batch args
+func (args *DeleteBatchArgs) IsValid() bool {
+	if args == nil {
+		return false
+	}
+	return len(args.Locations) > 0 && len(args.Locations) <= MaxDeleteBatchLocations
+}
+
+// DeleteBatchResult result of one location in delete batch,
+// Code is http status code, 200 means deleted.
+type DeleteBatchResult struct {
+	Code  int    `json:"code"`
+	Error string `json:"error,omitempty"`
+}
+
+// DeleteBatchResp delete batch response,
+// Results are in the same order as the locations of args.
+type DeleteBatchResp struct {
+	Results []DeleteBatchResult `json:"results"`
+}
+
+// FailedIndexes returns indexes of the locations failed to delete
+func (resp *DeleteBatchResp) FailedIndexes() []int {
+	var idxes []int
+	for idx, result := range resp.Results {
+		if result.Code != http.StatusOK {
+			idxes = append(idxes, idx)
+		}
+	}
+	return idxes
+}
+
 // DeleteBlobArgs for service /deleteblob
 type DeleteBlobArgs struct {
 	ClusterID proto.ClusterID `json:"clusterid"`
@@ -555,6 +555,20 @@ func TestDeleteArgs(t *testing.T) {
 	require.False(t, args.IsValid())
 }
 
+func TestDeleteBatchArgs(t *testing.T) {
+	args := access.DeleteBatchArgs{}
+	require.False(t, args.IsValid())
+	require.False(t, (*access.DeleteBatchArgs)(nil).IsValid())
+	args.Locations = make([]access.Location, access.MaxDeleteBatchLocations)
+	require.True(t, args.IsValid())
+	args.Locations = make([]access.Location, access.MaxDeleteBatchLocations+1)
+	require.False(t, args.IsValid())
+
+	resp := access.DeleteBatchResp{Results: []access.DeleteBatchResult{{Code: 200}, {Code: 400}, {Code: 500}}}
+	require.Equal(t, []int{1, 2}, resp.FailedIndexes())
+	require.Nil(t, (&access.DeleteBatchResp{}).FailedIndexes())
+}
+
 func TestDeleteBlobArgs(t *testing.T) {
 	args := access.DeleteBlobArgs{}
 	require.False(t, args.IsValid())
@@ -79,12 +79,24 @@ func (c *client) DiskInfo(ctx context.Context, host string, args *DiskStatArgs)
 	return
 }
 
+func (c *client) DiskLoad(ctx context.Context, host string, args *DiskStatArgs) (dl *DiskLoad, err error) {
+	if !IsValidDiskID(args.DiskID) {
+		return nil, errors.ErrInvalidDiskId
+	}
+
+	urlStr := fmt.Sprintf("%v/disk/load/diskid/%v", host, args.DiskID)
+	dl = new(DiskLoad)
+	err = c.GetWith(ctx, urlStr, dl)
+	return
+}
+
 type StorageAPI interface {
 	String(ctx context.Context, host string) string
 	IsOnline(ctx context.Context, host string) bool
 	Close(ctx context.Context, host string) error
 	Stat(ctx context.Context, host string) (infos []*DiskInfo, err error)
 	DiskInfo(ctx context.Context, host string, args *DiskStatArgs) (di *DiskInfo, err error)
+	DiskLoad(ctx context.Context, host string, args *DiskStatArgs) (dl *DiskLoad, err error)
 
 	// chunks
 	CreateChunk(ctx context.Context, host string, args *CreateChunkArgs) (err error)
@@ -98,6 +110,7 @@ type StorageAPI interface {
 	GetShard(ctx context.Context, host string, args *GetShardArgs) (body io.ReadCloser, shardCrc uint32, err error)
 	RangeGetShard(ctx context.Context, host string, args *RangeGetShardArgs) (body io.ReadCloser, shardCrc uint32, err error)
 	PutShard(ctx context.Context, host string, args *PutShardArgs) (crc uint32, err error)
+	PushShards(ctx context.Context, host string, args *PushShardsArgs) (pushed []proto.BlobID, err error)
 	StatShard(ctx context.Context, host string, args *StatShardArgs) (si *ShardInfo, err error)
 	MarkDeleteShard(ctx context.Context, host string, args *DeleteShardArgs) (err error)
 	DeleteShard(ctx context.Context, host string, args *DeleteShardArgs) (err error)
@@ -56,6 +56,10 @@ func TestNewBlobNodeClient(t *testing.T) {
 	require.NoError(t, err)
 	span.Infof("disk info: %v\n", diskInfo)
 
+	diskLoad, err := cli.DiskLoad(ctx, mockServer.URL, diskStatArgs)
+	require.NoError(t, err)
+	span.Infof("disk load: %v\n", diskLoad)
+
 	creteChunkArgs := &CreateChunkArgs{
 		DiskID: diskid,
 		Vuid:   20001,
@@ -28,6 +28,7 @@ type DiskHeartBeatInfo struct {
 	MaxChunkCnt  int64        `json:"max_chunk_cnt"`  // note: maintained by clustermgr
 	FreeChunkCnt int64        `json:"free_chunk_cnt"` // note: maintained by clustermgr
 	UsedChunkCnt int64        `json:"used_chunk_cnt"` // current number of chunks on the disk
+	ChunkUsed    int64        `json:"chunk_used"`     // allocated space of all chunks, zero if not reconciled yet
 }
 
 type DiskInfo struct {
@@ -45,6 +46,18 @@ type DiskInfo struct {
 	DiskHeartBeatInfo
 }
 
+// DiskLoad current IO load of disk, used to select the least loaded shards to read
+type DiskLoad struct {
+	DiskID     proto.DiskID `json:"diskid"`
+	ReadCount  int32        `json:"read_count"`  // current read IO count
+	WriteCount int32        `json:"write_count"` // current write IO count
+}
+
+// Load returns total IO count of disk
+func (l *DiskLoad) Load() int32 {
+	return l.ReadCount + l.WriteCount
+}
+
 type ChunkInfo struct {
 	Id         ChunkId      `json:"id"`
 	Vuid       proto.Vuid   `json:"vuid"`
@@ -197,6 +197,39 @@ func (c *client) RangeGetShard(ctx context.Context, host string, args *RangeGetS
 	return resp.Body, shardCrc, nil
 }
 
+type PushShardsArgs struct {
+	DiskID proto.DiskID        `json:"diskid"`
+	Vuid   proto.Vuid          `json:"vuid"`
+	Bids   []proto.BlobID      `json:"bids"`
+	Dest   proto.VunitLocation `json:"dest"`
+	Type   IOType              `json:"iotype,omitempty"`
+}
+
+type PushShardsRet struct {
+	Pushed []proto.BlobID `json:"pushed"`
+}
+
+// PushShards makes the blobnode of the vuid push the shards to the destination directly, every
+// shard is verified by the crc returned by the destination. The shards not pushed, such as the
+// missing or damaged ones, are not in pushed and are left to the caller.
+func (c *client) PushShards(ctx context.Context, host string, args *PushShardsArgs) (pushed []proto.BlobID, err error) {
+	if !IsValidDiskID(args.DiskID) || !IsValidDiskID(args.Dest.DiskID) {
+		err = bloberr.ErrInvalidDiskId
+		return
+	}
+	if !args.Type.IsValid() {
+		err = bloberr.ErrInvalidParam
+		return
+	}
+
+	ret := &PushShardsRet{}
+	err = c.PostWith(ctx, host+"/shard/push", ret, args)
+	if err == nil {
+		pushed = ret.Pushed
+	}
+	return
+}
+
 type DeleteShardArgs struct {
 	DiskID proto.DiskID `json:"diskid"`
 	Vuid   proto.Vuid   `json:"vuid"`
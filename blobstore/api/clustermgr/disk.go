@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	"github.com/cubefs/cubefs/blobstore/api/blobnode"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
 )
@@ -91,6 +92,19 @@ type SpaceStatInfo struct {
 	TotalBlobNode  int64          `json:"total_blob_node"`
 	TotalDisk      int64          `json:"total_disk"`
 	DisksStatInfos []DiskStatInfo `json:"disk_stat_infos"`
+	AZRedundancies []AZRedundancy `json:"az_redundancies,omitempty"`
+}
+
+// AZRedundancy is the redundancy of the volumes can be built by the code mode on the azs
+type AZRedundancy struct {
+	CodeMode    codemode.CodeMode `json:"code_mode"`
+	Buildable   bool              `json:"buildable"`
+	Asymmetric  bool              `json:"asymmetric"`    // the shards of azs are relaxed from the even layout
+	ShardsPerAZ map[string]int    `json:"shards_per_az"` // idc to the shards of a volume
+	// the shards can be lost of a volume, and those after the az of the most shards is down,
+	// -1 if the data is not readable with any az down
+	ToleratedShards       int `json:"tolerated_shards"`
+	ToleratedShardsAZDown int `json:"tolerated_shards_az_down"`
 }
 
 type DiskAccessArgs struct {
@@ -27,6 +27,15 @@ const (
 	getserviceUrl  = "/service/get"
 	heartbeatUrl   = "/service/heartbeat"
 	ListServiceUrl = "/service/list"
+	eventsUrl      = "/service/events"
+)
+
+const (
+	ServiceEventRegister   = "register"
+	ServiceEventUnregister = "unregister"
+
+	// UnregisterReasonProbe is the reason of service node unregistered by clustermgr after health probing failed
+	UnregisterReasonProbe = "health probe failed"
 )
 
 type ServiceNode struct {
@@ -46,16 +55,37 @@ type GetServiceArgs struct {
 }
 
 type UnregisterArgs struct {
+	Name   string `json:"name"`
+	Host   string `json:"host"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type HeartbeatArgs struct {
 	Name string `json:"name"`
 	Host string `json:"host"`
 }
 
-type HeartbeatArgs UnregisterArgs
-
 type ServiceInfo struct {
 	Nodes []ServiceNode `json:"nodes"`
 }
 
+type ListServiceEventsArgs struct {
+	Name string `json:"name"`
+}
+
+// ServiceEvent is a register or unregister event of service node, Time is in unix seconds.
+type ServiceEvent struct {
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Host   string `json:"host"`
+	Reason string `json:"reason,omitempty"`
+	Time   int64  `json:"time"`
+}
+
+type ServiceEvents struct {
+	Events []ServiceEvent `json:"events"`
+}
+
 // Register service node to cm
 // tickInterval: unit of second
 // HeartbeatInterval = heartbeatTicks * tickInterval
@@ -109,3 +139,10 @@ func (c *Client) ListService(ctx context.Context) (info ServiceInfo, err error)
 	err = c.GetWith(ctx, ListServiceUrl, &info)
 	return
 }
+
+// ListServiceEvents returns the recent register and unregister events of service nodes,
+// events of all services are returned if args.Name is empty
+func (c *Client) ListServiceEvents(ctx context.Context, args ListServiceEventsArgs) (ret ServiceEvents, err error) {
+	err = c.GetWith(ctx, eventsUrl+"?name="+args.Name, &ret)
+	return
+}
@@ -0,0 +1,173 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+)
+
+const (
+	// DefaultTenant is the tenant of the usage without tenant
+	DefaultTenant = "default"
+	// UsageDateLayout is the layout of the date of daily usage
+	UsageDateLayout = "2006-01-02"
+
+	MaxUsageTenantLength = 64
+	MaxUsageReportCount  = 1000
+	MaxUsageListDays     = 366
+)
+
+// UsageDelta is the usage changed by the puts and deletes of a tenant in a code mode.
+type UsageDelta struct {
+	Tenant      string            `json:"tenant"`
+	CodeMode    codemode.CodeMode `json:"code_mode"`
+	PutBytes    int64             `json:"put_bytes"`
+	PutBlobs    int64             `json:"put_blobs"`
+	DeleteBytes int64             `json:"delete_bytes"`
+	DeleteBlobs int64             `json:"delete_blobs"`
+}
+
+type ReportUsageArgs struct {
+	Deltas []UsageDelta `json:"deltas"`
+}
+
+// Usage is the usage of a tenant in a code mode. LogicalBytes and Blobs are the stored ones,
+// and PhysicalBytes is LogicalBytes times the overhead of the code mode. The daily usage has
+// the date, and the put and delete bytes of the day, the current usage has no date.
+type Usage struct {
+	Tenant        string            `json:"tenant"`
+	CodeMode      codemode.CodeMode `json:"code_mode"`
+	Date          string            `json:"date,omitempty"`
+	LogicalBytes  int64             `json:"logical_bytes"`
+	PhysicalBytes int64             `json:"physical_bytes"`
+	Blobs         int64             `json:"blobs"`
+	PutBytes      int64             `json:"put_bytes"`
+	DeleteBytes   int64             `json:"delete_bytes"`
+}
+
+// ListUsageArgs lists the current usage if the dates are empty, or the daily usage from StartDate
+// to EndDate inclusively. The usage of all tenants or code modes are listed if they are empty.
+type ListUsageArgs struct {
+	Tenant    string            `json:"tenant"`
+	CodeMode  codemode.CodeMode `json:"code_mode"`
+	StartDate string            `json:"start_date"`
+	EndDate   string            `json:"end_date"`
+}
+
+type ListUsageRet struct {
+	Usages []Usage `json:"usages"`
+}
+
+// IsValidTenant returns whether the tenant is valid, which is made up of letters,
+// digits, '-', '_' and '.'.
+func IsValidTenant(tenant string) bool {
+	if tenant == "" || len(tenant) > MaxUsageTenantLength {
+		return false
+	}
+	for _, c := range tenant {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// PhysicalUsage returns the physical bytes of the logical bytes in the code mode.
+func PhysicalUsage(mode codemode.CodeMode, logical int64) int64 {
+	if !mode.IsValid() {
+		return logical
+	}
+	tactic := mode.Tactic()
+	total := int64(tactic.N + tactic.M + tactic.L)
+	n := int64(tactic.N)
+	return logical/n*total + logical%n*total/n
+}
+
+// ParseUsageDates parses the dates of the args, both of them are zero if the dates are empty.
+func (args *ListUsageArgs) ParseUsageDates() (start, end time.Time, err error) {
+	if args.StartDate == "" && args.EndDate == "" {
+		return
+	}
+	if start, err = time.Parse(UsageDateLayout, args.StartDate); err != nil {
+		return
+	}
+	if end, err = time.Parse(UsageDateLayout, args.EndDate); err != nil {
+		return
+	}
+	if end.Before(start) || end.Sub(start) >= MaxUsageListDays*24*time.Hour {
+		err = fmt.Errorf("invalid date range from %s to %s", args.StartDate, args.EndDate)
+	}
+	return
+}
+
+func (args *ListUsageArgs) query() string {
+	values := url.Values{}
+	if args.Tenant != "" {
+		values.Set("tenant", args.Tenant)
+	}
+	if args.CodeMode > 0 {
+		values.Set("code_mode", strconv.Itoa(int(args.CodeMode)))
+	}
+	if args.StartDate != "" {
+		values.Set("start_date", args.StartDate)
+	}
+	if args.EndDate != "" {
+		values.Set("end_date", args.EndDate)
+	}
+	return values.Encode()
+}
+
+// WriteUsageCSV writes the usages in csv with a header line.
+func WriteUsageCSV(w io.Writer, usages []Usage) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{
+		"date", "tenant", "code_mode", "logical_bytes", "physical_bytes",
+		"blobs", "put_bytes", "delete_bytes",
+	}); err != nil {
+		return err
+	}
+	for _, u := range usages {
+		if err := cw.Write([]string{
+			u.Date, u.Tenant, u.CodeMode.String(),
+			strconv.FormatInt(u.LogicalBytes, 10), strconv.FormatInt(u.PhysicalBytes, 10),
+			strconv.FormatInt(u.Blobs, 10), strconv.FormatInt(u.PutBytes, 10),
+			strconv.FormatInt(u.DeleteBytes, 10),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReportUsage reports the usage changed since the last report
+func (c *Client) ReportUsage(ctx context.Context, args *ReportUsageArgs) (err error) {
+	err = c.PostWith(ctx, "/usage/report", nil, args)
+	return
+}
+
+// ListUsage lists the current or daily usage
+func (c *Client) ListUsage(ctx context.Context, args *ListUsageArgs) (ret ListUsageRet, err error) {
+	err = c.GetWith(ctx, "/usage/list?"+args.query(), &ret)
+	return
+}
@@ -0,0 +1,78 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+)
+
+func TestUsagePhysical(t *testing.T) {
+	require.Equal(t, int64(0), PhysicalUsage(codemode.EC6P6, 0))
+	require.Equal(t, int64(12), PhysicalUsage(codemode.EC6P6, 6))
+	require.Equal(t, int64(2700), PhysicalUsage(codemode.EC15P12, 1500))
+	require.Equal(t, int64(1600), PhysicalUsage(codemode.EC12P4, 1200))
+	require.Equal(t, int64(1), PhysicalUsage(codemode.EC12P4, 1))
+	// large logical bytes without overflow
+	require.Equal(t, int64(18<<50), PhysicalUsage(codemode.EC6P10L2, 6<<50))
+	require.Equal(t, int64(100), PhysicalUsage(codemode.CodeMode(0), 100))
+}
+
+func TestUsageTenant(t *testing.T) {
+	for _, tenant := range []string{"a", "tenant-1", "Tenant_1.a", strings.Repeat("a", MaxUsageTenantLength)} {
+		require.True(t, IsValidTenant(tenant), tenant)
+	}
+	for _, tenant := range []string{"", "a/b", "a b", "租户", strings.Repeat("a", MaxUsageTenantLength+1)} {
+		require.False(t, IsValidTenant(tenant), tenant)
+	}
+}
+
+func TestUsageListArgs(t *testing.T) {
+	args := &ListUsageArgs{}
+	start, end, err := args.ParseUsageDates()
+	require.NoError(t, err)
+	require.True(t, start.IsZero() && end.IsZero())
+	require.Equal(t, "", args.query())
+
+	args = &ListUsageArgs{Tenant: "t1", CodeMode: codemode.EC6P6, StartDate: "2023-05-01", EndDate: "2023-05-03"}
+	start, end, err = args.ParseUsageDates()
+	require.NoError(t, err)
+	require.Equal(t, 2, int(end.Sub(start).Hours()/24))
+	require.Equal(t, "code_mode=2&end_date=2023-05-03&start_date=2023-05-01&tenant=t1", args.query())
+
+	for _, args := range []*ListUsageArgs{
+		{StartDate: "2023-05-01"},
+		{StartDate: "2023-05-03", EndDate: "2023-05-01"},
+		{StartDate: "2023-05-01", EndDate: "2024-05-01"},
+		{StartDate: "2023/05/01", EndDate: "2023/05/02"},
+	} {
+		_, _, err = args.ParseUsageDates()
+		require.Error(t, err)
+	}
+}
+
+func TestUsageCSV(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, WriteUsageCSV(buf, []Usage{
+		{Tenant: "t1", CodeMode: codemode.EC6P6, Date: "2023-05-01", LogicalBytes: 600, PhysicalBytes: 1200, Blobs: 1, PutBytes: 1200, DeleteBytes: 600},
+	}))
+	require.Equal(t, "date,tenant,code_mode,logical_bytes,physical_bytes,blobs,put_bytes,delete_bytes\n"+
+		"2023-05-01,t1,EC6P6,600,1200,1,1200,600\n", buf.String())
+}
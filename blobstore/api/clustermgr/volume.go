@@ -223,6 +223,39 @@ func (c *Client) ListVolumeUnit(ctx context.Context, args *ListVolumeUnitArgs) (
 	return ret.VolumeUnitInfos, err
 }
 
+// UnitDivergence is a chunk hosted by the blobnode which diverges from the volume unit recorded
+type UnitDivergence struct {
+	Vuid   proto.Vuid   `json:"vuid"`
+	DiskID proto.DiskID `json:"disk_id"`
+	Host   string       `json:"host"`
+	// the vuid and disk of the volume unit recorded, zero if the volume not exist
+	UnitVuid   proto.Vuid   `json:"unit_vuid"`
+	UnitDiskID proto.DiskID `json:"unit_disk_id"`
+	Corrected  bool         `json:"corrected"`
+	Error      string       `json:"error,omitempty"`
+}
+
+// UnitReconcileReport is the result of the last round comparing the volume units with the
+// chunks hosted by the blobnodes, the divergences are seen in two consecutive rounds
+type UnitReconcileReport struct {
+	StartTime  int64                   `json:"start_time"`
+	EndTime    int64                   `json:"end_time"`
+	Disks      int                     `json:"disks"`
+	Chunks     int                     `json:"chunks"`
+	Units      int                     `json:"units"`
+	DiskErrors map[proto.DiskID]string `json:"disk_errors"`
+	// the chunks not of the volume unit recorded on the disk, e.g. left by the migrations
+	Orphans []UnitDivergence `json:"orphans"`
+	// the volume units recorded on the disk without the chunk hosted
+	Missing []UnitDivergence `json:"missing"`
+}
+
+func (c *Client) GetUnitReconcileReport(ctx context.Context) (ret *UnitReconcileReport, err error) {
+	ret = &UnitReconcileReport{}
+	err = c.GetWith(ctx, "/volume/unit/reconcile/report", ret)
+	return
+}
+
 type ReportChunkArgs struct {
 	ChunkInfos []blobnode.ChunkInfo `json:"chunk_infos"`
 }
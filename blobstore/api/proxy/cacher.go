@@ -19,6 +19,7 @@ import (
 	"crypto/sha1"
 	"encoding/binary"
 	"encoding/hex"
+	"fmt"
 	"hash/crc32"
 	"strings"
 
@@ -55,10 +56,32 @@ type CacheDiskArgs struct {
 	Flush  bool         `json:"flush,omitempty"`
 }
 
+// CacheBlobArgs blob range arguments.
+type CacheBlobArgs struct {
+	Vid    proto.Vid    `json:"vid"`
+	Bid    proto.BlobID `json:"bid"`
+	Offset uint64       `json:"offset"`
+	Size   uint64       `json:"size"`
+}
+
+// BlobKey returns key prefix of all cached ranges of the blob.
+func (args *CacheBlobArgs) BlobKey() string {
+	return fmt.Sprintf("blob-%d_%d", args.Vid, args.Bid)
+}
+
+// Key returns key of the cached range.
+func (args *CacheBlobArgs) Key() string {
+	return fmt.Sprintf("%s_%d_%d", args.BlobKey(), args.Offset, args.Size)
+}
+
 // Cacher interface of proxy cache.
 type Cacher interface {
 	GetCacheVolume(ctx context.Context, host string, args *CacheVolumeArgs) (*VersionVolume, error)
 	GetCacheDisk(ctx context.Context, host string, args *CacheDiskArgs) (*blobnode.DiskInfo, error)
+	// GetCacheBlob returns data of the blob range, errors.ErrBlobCacheMiss if not cached.
+	GetCacheBlob(ctx context.Context, host string, args *CacheBlobArgs) ([]byte, error)
+	// PutCacheBlob fills data of the blob range into cache.
+	PutCacheBlob(ctx context.Context, host string, args *CacheBlobArgs, data []byte) error
 	// Erase cache in proxy memory and diskv.
 	// Volume key is "volume-{vid}", and disk key is "disk-{disk_id}".
 	// Notice: Erase all if key is "ALL"!
@@ -67,12 +90,21 @@ type Cacher interface {
 
 // DiskvPathTransform transform key to multi-level path.
 // eg: key(with '{namespace}-{id}') --> ~/hash(key)[0:2]/hash(key)[2:4]/key
+//
+// Blob key(with 'blob-{vid}_{bid}_{offset}_{size}') is hashed by 'blob-{vid}_{bid}',
+// so all cached ranges of one blob are in the same directory.
 func DiskvPathTransform(key string) []string {
 	paths := strings.SplitN(key, "-", 2)
 	if len(paths) < 2 {
 		return []string{}
 	}
 
+	if paths[0] == "blob" {
+		if parts := strings.SplitN(key, "_", 3); len(parts) == 3 {
+			key = parts[0] + "_" + parts[1]
+		}
+	}
+
 	sha := sha1.New()
 	sha.Write([]byte(key))
 	h := hex.EncodeToString(sha.Sum(nil))
@@ -15,8 +15,11 @@
 package proxy
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"net/http"
 
 	"github.com/cubefs/cubefs/blobstore/api/blobnode"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
@@ -73,6 +76,33 @@ func (c *client) GetCacheDisk(ctx context.Context, host string, args *CacheDiskA
 	return
 }
 
+func (c *client) GetCacheBlob(ctx context.Context, host string, args *CacheBlobArgs) ([]byte, error) {
+	url := fmt.Sprintf("%s/cache/blob/%d/%d?offset=%d&size=%d", host, args.Vid, args.Bid, args.Offset, args.Size)
+	resp, err := c.Get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, rpc.ParseResponseErr(resp)
+	}
+	data := make([]byte, args.Size)
+	if _, err = io.ReadFull(resp.Body, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (c *client) PutCacheBlob(ctx context.Context, host string, args *CacheBlobArgs, data []byte) error {
+	url := fmt.Sprintf("%s/cache/blob/%d/%d?offset=%d&size=%d", host, args.Vid, args.Bid, args.Offset, args.Size)
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(data))
+	return c.DoWith(ctx, req, nil)
+}
+
 func (c *client) Erase(ctx context.Context, host string, key string) error {
 	resp, err := c.Delete(ctx, fmt.Sprintf("%s/cache/erase/%s", host, key))
 	if err != nil {
@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
 	_ "github.com/cubefs/cubefs/blobstore/testing/nolog"
@@ -235,4 +236,40 @@ func TestCacher_DiskvPathTransform(t *testing.T) {
 	} {
 		require.Equal(t, cs.paths, DiskvPathTransform(cs.key))
 	}
+
+	args := &CacheBlobArgs{Vid: 1, Bid: 2, Offset: 0, Size: 10}
+	paths := DiskvPathTransform(args.Key())
+	require.Equal(t, DiskvPathTransform(args.BlobKey()), paths)
+	args.Offset = 10
+	require.Equal(t, paths, DiskvPathTransform(args.Key()))
+	args.Bid = 3
+	require.NotEqual(t, paths, DiskvPathTransform(args.Key()))
+}
+
+func TestClient_CacheBlob(t *testing.T) {
+	cli := New(&Config{})
+	data := []byte("hot blob")
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPut {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if req.URL.Query().Get("offset") != "0" {
+			w.WriteHeader(errcode.CodeBlobCacheMiss)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
+	}))
+	defer mockServer.Close()
+
+	args := &CacheBlobArgs{Vid: 1, Bid: 2, Size: uint64(len(data))}
+	require.NoError(t, cli.PutCacheBlob(context.Background(), mockServer.URL, args, data))
+	ret, err := cli.GetCacheBlob(context.Background(), mockServer.URL, args)
+	require.NoError(t, err)
+	require.Equal(t, data, ret)
+
+	args.Offset = 1
+	_, err = cli.GetCacheBlob(context.Background(), mockServer.URL, args)
+	require.Equal(t, errcode.CodeBlobCacheMiss, rpc.DetectStatusCode(err))
 }
@@ -0,0 +1,108 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+// AddCampaignArgs campaign of the disks to add, the disks of the idc are added if Disks is empty,
+// such as an AZ evacuation.
+type AddCampaignArgs struct {
+	ID            string         `json:"id"`
+	TaskType      proto.TaskType `json:"task_type"`
+	Disks         []proto.DiskID `json:"disks"`
+	IDC           string         `json:"idc"`
+	BandwidthMBPS int            `json:"bandwidth_mbps"`
+}
+
+func (args *AddCampaignArgs) Valid() bool {
+	if args.ID == "" || args.BandwidthMBPS < 0 || (len(args.Disks) == 0 && args.IDC == "") {
+		return false
+	}
+	return args.TaskType == proto.TaskTypeDiskDrop || args.TaskType == proto.TaskTypeDiskRepair
+}
+
+// CampaignArgs campaign to operate.
+type CampaignArgs struct {
+	ID string `json:"id"`
+}
+
+// SetCampaignBudgetArgs bandwidth budget of campaign, 0 means unlimited.
+type SetCampaignBudgetArgs struct {
+	ID            string `json:"id"`
+	BandwidthMBPS int    `json:"bandwidth_mbps"`
+}
+
+// CampaignStat campaign with the live stats.
+type CampaignStat struct {
+	proto.MigrateCampaign
+	CurrentMBPS float64 `json:"current_mbps"` // bandwidth of the tasks reported in the last minutes
+	Throttled   bool    `json:"throttled"`    // tasks are not acquired as it's over the budget
+	// estimated seconds to complete by the migrating rate, -1 means unknown
+	ETASec int64  `json:"eta_sec"`
+	ETA    string `json:"eta,omitempty"` // estimated time of completion
+}
+
+// ListCampaignsRet campaigns of migration.
+type ListCampaignsRet struct {
+	Campaigns []*CampaignStat `json:"campaigns"`
+}
+
+func (c *client) AddCampaign(ctx context.Context, args *AddCampaignArgs) (err error) {
+	if args == nil || !args.Valid() {
+		return errcode.ErrIllegalArguments
+	}
+	return c.request(func(host string) error {
+		return c.PostWith(ctx, host+PathCampaignAdd, nil, args)
+	})
+}
+
+func (c *client) PauseCampaign(ctx context.Context, args *CampaignArgs) (err error) {
+	return c.request(func(host string) error {
+		return c.PostWith(ctx, host+PathCampaignPause, nil, args)
+	})
+}
+
+func (c *client) ResumeCampaign(ctx context.Context, args *CampaignArgs) (err error) {
+	return c.request(func(host string) error {
+		return c.PostWith(ctx, host+PathCampaignResume, nil, args)
+	})
+}
+
+func (c *client) SetCampaignBudget(ctx context.Context, args *SetCampaignBudgetArgs) (err error) {
+	if args == nil || args.BandwidthMBPS < 0 {
+		return errcode.ErrIllegalArguments
+	}
+	return c.request(func(host string) error {
+		return c.PostWith(ctx, host+PathCampaignBudget, nil, args)
+	})
+}
+
+func (c *client) DeleteCampaign(ctx context.Context, args *CampaignArgs) (err error) {
+	return c.request(func(host string) error {
+		return c.PostWith(ctx, host+PathCampaignDelete, nil, args)
+	})
+}
+
+func (c *client) ListCampaigns(ctx context.Context) (ret *ListCampaignsRet, err error) {
+	err = c.request(func(host string) error {
+		return c.GetWith(ctx, host+PathCampaignList, &ret)
+	})
+	return
+}
@@ -38,8 +38,17 @@ const (
 	PathTaskRenewal          = "/task/renewal"
 	PathInspectComplete      = "/inspect/complete"
 	PathInspectAcquire       = "/inspect/acquire"
+	PathInspectHistory       = "/inspect/history"
+	PathInspectTrend         = "/inspect/trend"
 	PathManualMigrateTaskAdd = "/manual/migrate/task/add"
 
+	PathCampaignAdd    = "/campaign/add"
+	PathCampaignPause  = "/campaign/pause"
+	PathCampaignResume = "/campaign/resume"
+	PathCampaignBudget = "/campaign/budget"
+	PathCampaignDelete = "/campaign/delete"
+	PathCampaignList   = "/campaign/list"
+
 	PathTaskDetail    = "/task/detail"
 	PathTaskDetailURI = PathTaskDetail + "/:type/:id" // "/task/detail/:type/:id"
 	PathUpdateVolume  = "/update/vol"
@@ -71,6 +80,8 @@ type ISchedulerStatus interface {
 	DiskMigratingStats(ctx context.Context, args *DiskMigratingStatsArgs) (ret *DiskMigratingStats, err error)
 	Stats(ctx context.Context, host string) (ret TasksStat, err error)
 	LeaderStats(ctx context.Context) (ret TasksStat, err error)
+	VolumeInspectHistory(ctx context.Context, args *VolumeInspectHistoryArgs) (ret *VolumeInspectHistoryRet, err error)
+	VolumeInspectTrend(ctx context.Context, args *VolumeInspectTrendArgs) (ret *VolumeInspectTrendRet, err error)
 }
 
 // IManualMigrator add manual migrate task.
@@ -78,6 +89,16 @@ type IManualMigrator interface {
 	AddManualMigrateTask(ctx context.Context, args *AddManualMigrateArgs) (err error)
 }
 
+// ICampaignManager campaigns of migration.
+type ICampaignManager interface {
+	AddCampaign(ctx context.Context, args *AddCampaignArgs) (err error)
+	PauseCampaign(ctx context.Context, args *CampaignArgs) (err error)
+	ResumeCampaign(ctx context.Context, args *CampaignArgs) (err error)
+	SetCampaignBudget(ctx context.Context, args *SetCampaignBudgetArgs) (err error)
+	DeleteCampaign(ctx context.Context, args *CampaignArgs) (err error)
+	ListCampaigns(ctx context.Context) (ret *ListCampaignsRet, err error)
+}
+
 // IVolumeUpdater volume updater.
 type IVolumeUpdater interface {
 	UpdateVolume(ctx context.Context, host string, vid proto.Vid) (err error)
@@ -90,6 +111,7 @@ type IScheduler interface {
 	ISchedulerStatus
 	IManualMigrator
 	IVolumeUpdater
+	ICampaignManager
 }
 
 // Config scheduler config.
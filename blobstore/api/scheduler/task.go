@@ -242,6 +242,64 @@ func (c *client) DiskMigratingStats(ctx context.Context, args *DiskMigratingStat
 	return
 }
 
+// VolumeInspectHistoryArgs volume inspect history args.
+type VolumeInspectHistoryArgs struct {
+	Vid proto.Vid `json:"vid"`
+}
+
+// VolumeInspectHistoryRet records of volume inspection, in order of inspect time.
+type VolumeInspectHistoryRet struct {
+	Records []*proto.VolumeInspectRecord `json:"records"`
+}
+
+func (c *client) VolumeInspectHistory(ctx context.Context, args *VolumeInspectHistoryArgs) (ret *VolumeInspectHistoryRet, err error) {
+	if args == nil || args.Vid == proto.InvalidVid {
+		err = errcode.ErrIllegalArguments
+		return
+	}
+	err = c.request(func(host string) error {
+		return c.GetWith(ctx, host+PathInspectHistory+fmt.Sprintf("?vid=%d", args.Vid), &ret)
+	})
+	return
+}
+
+// VolumeInspectTrendArgs volume inspect trend args, trend of the latest days.
+type VolumeInspectTrendArgs struct {
+	Days int `json:"days"`
+}
+
+// VolumeInspectTrendPoint bad and repaired shards found by volume inspection in a day.
+type VolumeInspectTrendPoint struct {
+	Date         string `json:"date"` // 2006-01-02
+	BadShardCnt  int    `json:"bad_shard_cnt"`
+	RecurringCnt int    `json:"recurring_cnt"`
+	RepairedCnt  int    `json:"repaired_cnt"`
+	BadDiskCnt   int    `json:"bad_disk_cnt"`
+}
+
+// VolumeInspectTrend trend of volume inspection of the disks in the same model and batch.
+type VolumeInspectTrend struct {
+	Model  string                     `json:"model"`
+	Batch  string                     `json:"batch"`
+	Points []*VolumeInspectTrendPoint `json:"points"`
+}
+
+// VolumeInspectTrendRet volume inspect trends.
+type VolumeInspectTrendRet struct {
+	Trends []*VolumeInspectTrend `json:"trends"`
+}
+
+func (c *client) VolumeInspectTrend(ctx context.Context, args *VolumeInspectTrendArgs) (ret *VolumeInspectTrendRet, err error) {
+	if args == nil || args.Days < 0 {
+		err = errcode.ErrIllegalArguments
+		return
+	}
+	err = c.request(func(host string) error {
+		return c.GetWith(ctx, host+PathInspectTrend+fmt.Sprintf("?days=%d", args.Days), &ret)
+	})
+	return
+}
+
 func (c *client) selectHost() ([]string, error) {
 	hosts := c.selector.GetRandomN(c.hostRetry)
 	if len(hosts) == 0 {
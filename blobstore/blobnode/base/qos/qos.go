@@ -36,6 +36,7 @@ type Qos interface {
 	Reader(context.Context, bnapi.IOType, io.Reader) io.Reader
 	Allow(rwType IOTypeRW) bool
 	Release(rwType IOTypeRW)
+	IOCount(rwType IOTypeRW) int32
 	ResetQosLimit(Config)
 	GetConfig() Config
 	Close()
@@ -171,6 +172,11 @@ func (qos *IoQueueQos) Release(rwType IOTypeRW) {
 	atomic.AddInt32(&qos.ioCnt[rwType], -1)
 }
 
+// IOCount returns current IO count of the disk, it is the load of disk
+func (qos *IoQueueQos) IOCount(rwType IOTypeRW) int32 {
+	return atomic.LoadInt32(&qos.ioCnt[rwType])
+}
+
 // TryAcquireIO is just simply counts, and determines if you can operate IO. return true means you can operate IO.
 // 1.If the total IO is less than the queue depth, high-priority IO can be added to the queue; otherwise, they are discarded all
 // 2.If the low-priority IO less than half of the queue depth, it can be added to the queue; otherwise, they are discarded some
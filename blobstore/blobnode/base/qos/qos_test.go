@@ -256,6 +256,8 @@ func TestQosTryAcquire(t *testing.T) {
 
 		require.Equal(t, int32(8), q.ioCnt[IOTypeRead])
 		require.Equal(t, int32(8), q.ioCnt[IOTypeWrite])
+		require.Equal(t, int32(8), q.IOCount(IOTypeRead))
+		require.Equal(t, int32(8), q.IOCount(IOTypeWrite))
 		require.Equal(t, int32(8), q.readDiscard.currentCnt)
 		require.Equal(t, int32(4), q.writeDiscard[0].currentCnt)
 		require.Equal(t, int32(4), q.writeDiscard[1].currentCnt)
@@ -35,6 +35,8 @@ type IBlobNode interface {
 	ListShards(ctx context.Context, location proto.VunitLocation) (shards []*ShardInfo, err error)
 	GetShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID, ioType api.IOType) (body io.ReadCloser, crc32 uint32, err error)
 	PutShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID, size int64, body io.Reader, ioType api.IOType) (err error)
+	PushShards(ctx context.Context, src, dst proto.VunitLocation, bids []proto.BlobID, ioType api.IOType) (pushed []proto.BlobID, err error)
+	DiskLoad(ctx context.Context, location proto.VunitLocation) (load int32, err error)
 }
 
 // BlobNodeClient blobnode client
@@ -160,3 +162,29 @@ func (c *BlobNodeClient) PutShard(ctx context.Context, location proto.VunitLocat
 	}
 	return
 }
+
+// PushShards makes the blobnode of the source push the shards to the destination directly,
+// returns the shards pushed
+func (c *BlobNodeClient) PushShards(ctx context.Context, src, dst proto.VunitLocation, bids []proto.BlobID, ioType api.IOType) (pushed []proto.BlobID, err error) {
+	ctx = trace.NewContextFromContext(ctx)
+	span := trace.SpanFromContext(ctx).WithOperation("PushShards")
+	pushed, err = c.cli.PushShards(ctx, src.Host, &api.PushShardsArgs{DiskID: src.DiskID, Vuid: src.Vuid, Bids: bids, Dest: dst, Type: ioType})
+	if err != nil {
+		span.Warnf("PushShards failed: src[%+v], dst[%+v], code[%d], err[%+v]", src, dst, rpc.DetectStatusCode(err), err)
+		return
+	}
+	span.Debugf("PushShards success: src[%+v], dst[%+v], pushed[%d/%d]", src, dst, len(pushed), len(bids))
+	return
+}
+
+// DiskLoad returns current IO load of the disk where the vunit located
+func (c *BlobNodeClient) DiskLoad(ctx context.Context, location proto.VunitLocation) (load int32, err error) {
+	ctx = trace.NewContextFromContext(ctx)
+	span := trace.SpanFromContext(ctx).WithOperation("DiskLoad")
+	dl, err := c.cli.DiskLoad(ctx, location.Host, &api.DiskStatArgs{DiskID: location.DiskID})
+	if err != nil {
+		span.Warnf("DiskLoad failed: location[%+v], code[%d], err[%+v]", location, rpc.DetectStatusCode(err), err)
+		return
+	}
+	return dl.Load(), nil
+}
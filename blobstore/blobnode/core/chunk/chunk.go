@@ -288,8 +288,10 @@ func (cs *chunk) Write(ctx context.Context, b *core.Shard) (err error) {
 		return err
 	}
 
-	// update stats
-	atomic.AddUint64(&cs.fileInfo.Used, uint64(core.Alignphysize(int64(b.Size))))
+	// update stats, inline shard takes no space of data file
+	if !b.Inline {
+		atomic.AddUint64(&cs.fileInfo.Used, uint64(core.PhySpace(int64(b.Size))))
+	}
 	atomic.StoreUint32(&cs.dirty, 1)
 
 	return nil
@@ -606,7 +608,7 @@ func (cs *chunk) Delete(ctx context.Context, bid proto.BlobID) (err error) {
 	}
 
 	// update stats
-	atomic.AddUint64(&cs.fileInfo.Used, -uint64(core.Alignphysize(n)))
+	atomic.AddUint64(&cs.fileInfo.Used, -uint64(n))
 	atomic.StoreUint32(&cs.dirty, 1)
 
 	return nil
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	bnapi "github.com/cubefs/cubefs/blobstore/api/blobnode"
@@ -320,9 +321,9 @@ func (cs *chunk) NeedCompact(ctx context.Context) bool {
 	size, phySize := stat.FileSize, stat.PhySize
 
 	// file size is too large
-	if size >= cs.conf.CompactTriggerThreshold {
+	if threshold := cs.compactTriggerThreshold(); size >= threshold {
 		span.Debugf("phySize:%v/fsize:%v, threshold:%v",
-			phySize, size, cs.conf.CompactTriggerThreshold)
+			phySize, size, threshold)
 		return true
 	}
 
@@ -336,6 +337,26 @@ func (cs *chunk) NeedCompact(ctx context.Context) bool {
 	return false
 }
 
+// compactTriggerThreshold scales the threshold of file size in proportion to the chunk size for the
+// chunks larger than the default, otherwise the large chunks are compacted again and again. The
+// scaled threshold leaves room for the writes before the file reaches the max chunk file size.
+func (cs *chunk) compactTriggerThreshold() int64 {
+	threshold := cs.conf.CompactTriggerThreshold
+	total := int64(atomic.LoadUint64(&cs.fileInfo.Total))
+	if total <= core.DefaultChunkSize {
+		return threshold
+	}
+	// multiply before dividing in float, the product of sizes may overflow int64
+	scaled := float64(threshold) * float64(total) / float64(core.DefaultChunkSize)
+	if scaled > DefaultMaxChunkFileSize/2 {
+		scaled = DefaultMaxChunkFileSize / 2
+	}
+	if int64(scaled) > threshold {
+		return int64(scaled)
+	}
+	return threshold
+}
+
 func (cs *chunk) compactCheck(ctx context.Context, ncs *chunk) (err error) {
 	span := trace.SpanFromContextSafe(ctx)
 
@@ -663,3 +663,28 @@ func TestChunkStorage_CompactCheck(t *testing.T) {
 	runtime.GC()
 	runtime.GC()
 }
+
+func TestChunk_CompactTriggerThreshold(t *testing.T) {
+	cs := &chunk{conf: &core.Config{}}
+	cs.fileInfo.Total = uint64(core.DefaultChunkSize)
+
+	// the default chunk size keeps the threshold
+	cs.conf.CompactTriggerThreshold = core.DefaultCompactTriggerThreshold
+	require.Equal(t, core.DefaultCompactTriggerThreshold, cs.compactTriggerThreshold())
+
+	// scaled in proportion to the chunk size, and limited to half of the max chunk file size
+	cs.fileInfo.Total = uint64(core.DefaultChunkSize * 3)
+	require.Equal(t, int64(3<<40), cs.compactTriggerThreshold())
+	cs.fileInfo.Total = uint64(core.DefaultChunkSize * 8)
+	require.Equal(t, int64(DefaultMaxChunkFileSize/2), cs.compactTriggerThreshold())
+	cs.fileInfo.Total = uint64(core.DefaultChunkSize * 3)
+	cs.conf.CompactTriggerThreshold = 1 << 30
+	require.Equal(t, int64(3<<30), cs.compactTriggerThreshold())
+
+	// threshold less than the default chunk size is scaled too
+	cs.conf.CompactTriggerThreshold = 1 << 20
+	cs.fileInfo.Total = uint64(core.DefaultChunkSize * 4)
+	require.Equal(t, int64(4<<20), cs.compactTriggerThreshold())
+	cs.fileInfo.Total = uint64(core.DefaultChunkSize*3) / 2
+	require.Equal(t, int64(3<<19), cs.compactTriggerThreshold())
+}
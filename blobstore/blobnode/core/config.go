@@ -36,6 +36,7 @@ const (
 	DefaultChunkCompactIntervalSec      = int64(10 * 60)        // 10 min
 	DefaultChunkCleanIntervalSec        = int64(60)             // 1 min
 	DefaultDiskUsageIntervalSec         = int64(60)             // 1 min
+	DefaultChunkReconcileIntervalSec    = int64(5 * 60)         // 5 min
 	DefaultDiskCleanTrashIntervalSec    = int64(60)             // 1 min
 	DefaultDiskTrashProtectionM         = int64(1440)           // 1 days
 	DefaultCompactBatchSize             = 1024                  // 1024 counts
@@ -67,6 +68,7 @@ type RuntimeConfig struct {
 	ChunkGcCreateTimeProtectionM int64   `json:"chunk_gc_create_time_protection_M"` // protect
 	ChunkGcModifyTimeProtectionM int64   `json:"chunk_gc_modify_time_protection_M"` // protect
 	DiskUsageIntervalSec         int64   `json:"disk_usage_interval_S"`             // loop
+	ChunkReconcileIntervalSec    int64   `json:"chunk_reconcile_interval_S"`        // loop
 	DiskCleanTrashIntervalSec    int64   `json:"disk_clean_trash_interval_S"`       // loop
 	DiskTrashProtectionM         int64   `json:"disk_trash_protection_M"`           // protect
 	CompactMinSizeThreshold      int64   `json:"compact_min_size_threshold"`
@@ -131,6 +133,7 @@ func InitConfig(conf *Config) error {
 	defaulter.LessOrEqual(&conf.ChunkGcCreateTimeProtectionM, DefaultChunkGcCreateTimeProtectionM)
 	defaulter.LessOrEqual(&conf.ChunkGcModifyTimeProtectionM, DefaultChunkGcModifyTimeProtectionM)
 	defaulter.LessOrEqual(&conf.DiskUsageIntervalSec, DefaultDiskUsageIntervalSec)
+	defaulter.LessOrEqual(&conf.ChunkReconcileIntervalSec, DefaultChunkReconcileIntervalSec)
 	defaulter.LessOrEqual(&conf.CompactTriggerThreshold, DefaultCompactTriggerThreshold)
 	defaulter.LessOrEqual(&conf.CompactMinSizeThreshold, DefaultCompactMinSizeThreshold)
 	defaulter.LessOrEqual(&conf.CompactEmptyRateThreshold, DefaultCompactEmptyRateThreshold)
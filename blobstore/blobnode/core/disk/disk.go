@@ -76,7 +76,8 @@ type DiskStorage struct {
 	ChunkLimitPerKey limit.Limiter
 
 	// stats
-	stats atomic.Value // *core.DiskStats
+	stats     atomic.Value // *core.DiskStats
+	chunkUsed int64        // allocated blocks of all chunks, reconciled periodically
 
 	// DataQos (include io visualization function)
 	dataQos qos.Qos
@@ -184,6 +185,7 @@ func (ds *DiskStorage) DiskInfo() (info bnapi.DiskInfo) {
 	// stats
 	info.Used = stats.Used
 	info.UsedChunkCnt = int64(len(ds.Chunks))
+	info.ChunkUsed = stats.ChunkUsed
 	// for chunk space
 	info.Free = stats.Free - stats.Reserved
 	if info.Free < 0 {
@@ -530,6 +532,7 @@ func newDiskStorage(ctx context.Context, conf core.Config) (ds *DiskStorage, err
 	ds.loopAttach(ds.loopCleanChunk)
 	ds.loopAttach(ds.loopCompactFile)
 	ds.loopAttach(ds.loopDiskUsage)
+	ds.loopAttach(ds.loopReconcileChunkSpace)
 	ds.loopAttach(ds.loopCleanTrash)
 	ds.loopAttach(ds.loopMetricReport)
 
@@ -1070,12 +1073,62 @@ func (ds *DiskStorage) fillDiskUsage(ctx context.Context) (err error) {
 	stats.Used = int64(rootInfo.Total - rootInfo.Free)
 	stats.Free = int64(rootInfo.Free)
 	stats.TotalDiskSize = int64(rootInfo.Total)
+	stats.ChunkUsed = atomic.LoadInt64(&ds.chunkUsed)
 
 	ds.stats.Store(stats)
 
 	return nil
 }
 
+// loopReconcileChunkSpace resets space of chunks by the allocated blocks of chunk files.
+// Space of chunks is updated by estimated size of shards when writing and deleting,
+// hole punching and inline shards make it drift from the actual space of filesystem.
+func (ds *DiskStorage) loopReconcileChunkSpace() {
+	span, ctx := trace.StartSpanFromContextWithTraceID(context.Background(), "", "ReconcileChunkSpace"+ds.Conf.Path)
+
+	span.Infof("loop reconcile chunk space start")
+
+	timer := initTimer(ds.Conf.ChunkReconcileIntervalSec)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ds.closeCh:
+			span.Infof("loop reconcile chunk space done")
+			return
+		case <-timer.C:
+			ds.reconcileChunkSpace(ctx)
+			resetTimer(ds.Conf.ChunkReconcileIntervalSec, timer)
+		}
+	}
+}
+
+func (ds *DiskStorage) reconcileChunkSpace(ctx context.Context) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	ds.Lock.RLock()
+	chunks := make([]core.ChunkAPI, 0, len(ds.Chunks))
+	for _, cs := range ds.Chunks {
+		chunks = append(chunks, cs)
+	}
+	ds.Lock.RUnlock()
+
+	var used int64
+	for _, cs := range chunks {
+		before := cs.ChunkInfo(ctx).Used
+		if err := cs.RefreshFstat(ctx); err != nil {
+			span.Errorf("Failed refresh fstat, vuid:%v err:%v", cs.Vuid(), err)
+			continue
+		}
+		after := cs.ChunkInfo(ctx).Used
+		if before != after {
+			span.Debugf("reconcile chunk space, vuid:%v used:%d -> %d", cs.Vuid(), before, after)
+		}
+		used += int64(after)
+	}
+	atomic.StoreInt64(&ds.chunkUsed, used)
+}
+
 func (ds *DiskStorage) WalkChunksWithLock(ctx context.Context, walkFn func(cs core.ChunkAPI) error) (err error) {
 	ds.Lock.RLock()
 	defer ds.Lock.RUnlock()
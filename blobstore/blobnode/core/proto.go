@@ -58,6 +58,7 @@ type DiskStats struct {
 	Free          int64 `json:"free"`            // actual remaining physical space on the disk
 	Reserved      int64 `json:"reserved"`        // reserve space on the disk
 	TotalDiskSize int64 `json:"total_disk_size"` // total actual disk size
+	ChunkUsed     int64 `json:"chunk_used"`      // allocated blocks of all chunk files, reconciled by fstat
 }
 
 type StorageStat struct {
@@ -99,7 +100,7 @@ type Storage interface {
 	ReadShardMeta(ctx context.Context, bid proto.BlobID) (sm *ShardMeta, err error)
 	NewRangeReader(ctx context.Context, b *Shard, from, to int64) (rc io.Reader, err error)
 	MarkDelete(ctx context.Context, bid proto.BlobID) (err error)
-	Delete(ctx context.Context, bid proto.BlobID) (n int64, err error)
+	Delete(ctx context.Context, bid proto.BlobID) (n int64, err error) // n is the released space of data file
 	ScanMeta(ctx context.Context, startBid proto.BlobID, limit int,
 		fn func(bid proto.BlobID, sm *ShardMeta) error) (err error)
 	SyncData(ctx context.Context) (err error)
@@ -140,6 +141,7 @@ type ChunkAPI interface {
 	CommitCompact(ctx context.Context, ncs ChunkAPI) (err error)
 	StopCompact(ctx context.Context, ncs ChunkAPI) (err error)
 	NeedCompact(ctx context.Context) bool
+	RefreshFstat(ctx context.Context) (err error)
 	IsDirty() bool
 	IsClosed() bool
 	AllowModify() (err error)
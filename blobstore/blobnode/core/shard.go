@@ -69,6 +69,9 @@ import (
 // | padding  (0 bytes) |
 // ----------------------
 
+// PageSize shards are aligned by page in data file
+const PageSize = 4 * 1024 // 4k
+
 const (
 	// shard header size
 	_shardHeaderSize = 4 + 4 + 8 + 8 + 4 + 4 // 32 (crc + magic + bid + vuid + size + reserved)
@@ -366,6 +369,12 @@ func Alignphysize(shardSize int64) int64 {
 	return _shardHeaderSize + int64(bodysize) + _shardFooterSize
 }
 
+// PhySpace returns the allocated space of a shard in data file, shards are
+// written and punched in pages, so the space is aligned by page.
+func PhySpace(shardSize int64) int64 {
+	return AlignSize(Alignphysize(shardSize), PageSize)
+}
+
 func GetShardHeaderSize() int64 {
 	return _shardHeaderSize
 }
@@ -145,3 +145,12 @@ func TestShardMeta_Marshal(t *testing.T) {
 	require.Equal(t, int(1), int(sm1.Flag))
 	require.Equal(t, sm.Buffer, sm1.Buffer)
 }
+
+func TestShardPhySpace(t *testing.T) {
+	for _, size := range []int64{0, 1, 4 * 1024, 64*1024 + 1, 1 << 20} {
+		space := PhySpace(size)
+		require.True(t, space >= Alignphysize(size))
+		require.Equal(t, int64(0), space%PageSize)
+		require.True(t, space-Alignphysize(size) < PageSize)
+	}
+}
@@ -72,7 +72,7 @@ const (
 )
 
 const (
-	_pageSize = 4 * 1024 // 4k
+	_pageSize = core.PageSize
 )
 
 const (
@@ -467,8 +467,7 @@ func (cd *datafile) Delete(ctx context.Context, shard *core.Shard) (err error) {
 	}
 
 	// punch hole
-	discardSize = core.Alignphysize(int64(shard.Size))
-	discardSize = core.AlignSize(discardSize, _pageSize)
+	discardSize = core.PhySpace(int64(shard.Size))
 	err = cd.ef.Discard(shard.Offset, discardSize)
 
 	return err
@@ -159,9 +159,9 @@ func (stg *storage) Delete(ctx context.Context, bid proto.BlobID) (n int64, err
 		return n, err
 	}
 
-	// data inline , skip
+	// data inline , skip, no space in data file
 	if shardMeta.Inline {
-		return int64(shardMeta.Size), nil
+		return 0, nil
 	}
 
 	shard := &core.Shard{
@@ -180,7 +180,7 @@ func (stg *storage) Delete(ctx context.Context, bid proto.BlobID) (n int64, err
 		return n, err
 	}
 
-	return int64(shardMeta.Size), nil
+	return core.PhySpace(int64(shardMeta.Size)), nil
 }
 
 func (stg *storage) ScanMeta(ctx context.Context, startBid proto.BlobID, limit int,
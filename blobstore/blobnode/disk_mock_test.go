@@ -921,6 +921,20 @@ func (mr *MockChunkAPIMockRecorder) ReadShardMeta(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadShardMeta", reflect.TypeOf((*MockChunkAPI)(nil).ReadShardMeta), arg0, arg1)
 }
 
+// RefreshFstat mocks base method.
+func (m *MockChunkAPI) RefreshFstat(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshFstat", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshFstat indicates an expected call of RefreshFstat.
+func (mr *MockChunkAPIMockRecorder) RefreshFstat(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshFstat", reflect.TypeOf((*MockChunkAPI)(nil).RefreshFstat), arg0)
+}
+
 // SetDirty mocks base method.
 func (m *MockChunkAPI) SetDirty(arg0 bool) {
 	m.ctrl.T.Helper()
@@ -27,11 +27,22 @@ var (
 		},
 		[]string{"cluster_id", "idc", "host", "node", "api", "io_type"},
 	)
+
+	reconstructRemainingMetric = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "blobstore",
+			Subsystem: "blobnode",
+			Name:      "shard_reconstruct_remaining_bytes",
+			Help:      "blobnode remaining bytes of shards in reconstructing",
+		},
+		[]string{"task_type"},
+	)
 )
 
 func init() {
 	prometheus.MustRegister(diskHealthMetric)
 	prometheus.MustRegister(networkMetric)
+	prometheus.MustRegister(reconstructRemainingMetric)
 }
 
 // when find the lost disk, set value 1
@@ -66,3 +77,29 @@ func (s *Service) reportPutTraffic(ioType bnapi.IOType, size int64) {
 	networkMetric.WithLabelValues(node.ClusterID.ToString(), node.IDC, node.Host, node.NodeID.ToString(),
 		"put", ioType.String()).Add(float64(size))
 }
+
+// reconstructProgress reports the remaining bytes of one reconstruction to the gauge,
+// the gauge sums the remaining bytes of all running reconstructions of the task type.
+type reconstructProgress struct {
+	gauge       prometheus.Gauge
+	done, total int
+}
+
+func newReconstructProgress(taskType string) *reconstructProgress {
+	return &reconstructProgress{gauge: reconstructRemainingMetric.WithLabelValues(taskType)}
+}
+
+func (p *reconstructProgress) report(done, total int) {
+	if p.total == 0 {
+		p.total = total
+		p.gauge.Add(float64(total))
+	}
+	p.gauge.Sub(float64(done - p.done))
+	p.done = done
+}
+
+// finish removes the remaining bytes from the gauge, whether the reconstruction succeeded or not.
+func (p *reconstructProgress) finish() {
+	p.gauge.Sub(float64(p.total - p.done))
+	p.done = p.total
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
 	"github.com/cubefs/cubefs/blobstore/util/defaulter"
+	"github.com/cubefs/cubefs/blobstore/util/graceful"
 	"github.com/cubefs/cubefs/blobstore/util/log"
 )
 
@@ -40,7 +41,7 @@ func init() {
 		SetUp:      setUp,
 		TearDown:   tearDown,
 	}
-	cmd.RegisterModule(mod)
+	cmd.RegisterExclusiveGracefulModule(mod)
 }
 
 func initConfig(args []string) (cfg *cmd.Config, err error) {
@@ -54,9 +55,12 @@ func initConfig(args []string) (cfg *cmd.Config, err error) {
 		conf.FlockFilename = "./blobnode.flock"
 	}
 
-	if _, err = fileutil.TryLockFile(conf.FlockFilename); err != nil {
-		log.Errorf("Failed to flock, err: %v", err)
-		return nil, err
+	// the flock is held by the master process, which forks the slave process serving the disks
+	if !graceful.IsSlave() {
+		if _, err = fileutil.TryLockFile(conf.FlockFilename); err != nil {
+			log.Errorf("Failed to flock, err: %v", err)
+			return nil, err
+		}
 	}
 
 	initPromeConf()
@@ -124,6 +128,7 @@ func NewHandler(service *Service) *rpc.Router {
 	r.Handle(http.MethodGet, "/config/get", service.ConfigGet, rpc.OptArgsQuery())
 
 	r.Handle(http.MethodGet, "/disk/stat/diskid/:diskid", service.DiskStat, rpc.OptArgsURI())
+	r.Handle(http.MethodGet, "/disk/load/diskid/:diskid", service.DiskLoad, rpc.OptArgsURI())
 	r.Handle(http.MethodPost, "/disk/probe", service.DiskProbe, rpc.OptArgsBody())
 
 	r.Handle(http.MethodPost, "/chunk/inspect/diskid/:diskid/vuid/:vuid", service.ChunkInspect, rpc.OptArgsURI())
@@ -141,6 +146,7 @@ func NewHandler(service *Service) *rpc.Router {
 	r.Handle(http.MethodPost, "/shard/markdelete/diskid/:diskid/vuid/:vuid/bid/:bid", service.ShardMarkdelete, rpc.OptArgsURI())
 	r.Handle(http.MethodPost, "/shard/delete/diskid/:diskid/vuid/:vuid/bid/:bid", service.ShardDelete, rpc.OptArgsURI())
 	r.Handle(http.MethodPost, "/shard/put/diskid/:diskid/vuid/:vuid/bid/:bid/size/:size", service.ShardPut, rpc.OptArgsURI(), rpc.OptArgsQuery())
+	r.Handle(http.MethodPost, "/shard/push", service.ShardPush, rpc.OptArgsBody())
 
 	r.Handle(http.MethodPost, "/shard/repair", service.WorkerService.ShardRepair, rpc.OptArgsBody())
 	r.Handle(http.MethodGet, "/worker/stats", service.WorkerService.WorkerStats)
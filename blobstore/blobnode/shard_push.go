@@ -0,0 +1,126 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package blobnode
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	bnapi "github.com/cubefs/cubefs/blobstore/api/blobnode"
+	"github.com/cubefs/cubefs/blobstore/blobnode/base/limitio"
+	"github.com/cubefs/cubefs/blobstore/blobnode/core"
+	bloberr "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+)
+
+/*
+ *  method:         POST
+ *  url:            /shard/push
+ *  request body:   json.Marshal(bnapi.PushShardsArgs)
+ *  response body:  json.Marshal(bnapi.PushShardsRet)
+ *
+ *  The shards of the vuid are pushed to the destination directly, so that the migration of
+ *  the healthy shards does not relay the data through the worker.
+ */
+func (s *Service) ShardPush(c *rpc.Context) {
+	args := new(bnapi.PushShardsArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+
+	if !bnapi.IsValidDiskID(args.DiskID) || !bnapi.IsValidDiskID(args.Dest.DiskID) {
+		c.RespondError(bloberr.ErrInvalidDiskId)
+		return
+	}
+	if !args.Type.IsValid() {
+		c.RespondError(bloberr.ErrInvalidParam)
+		return
+	}
+
+	convertIoType(&args.Type)
+	ctx = bnapi.SetIoType(ctx, args.Type)
+	ctx = limitio.SetLimitTrack(ctx)
+
+	s.lock.RLock()
+	ds, exist := s.Disks[args.DiskID]
+	s.lock.RUnlock()
+	if !exist {
+		c.RespondError(bloberr.ErrNoSuchDisk)
+		return
+	}
+	if !ds.IsWritable() {
+		c.RespondError(bloberr.ErrDiskBroken)
+		return
+	}
+	cs, exist := ds.GetChunkStorage(args.Vuid)
+	if !exist {
+		c.RespondError(bloberr.ErrNoSuchVuid)
+		return
+	}
+
+	ret := &bnapi.PushShardsRet{Pushed: make([]proto.BlobID, 0, len(args.Bids))}
+	for _, bid := range args.Bids {
+		if err := s.pushShard(ctx, cs, bid, args.Dest, args.Type); err != nil {
+			span.Warnf("push shard failed: vuid[%d], bid[%d], dest[%+v], err[%v]", args.Vuid, bid, args.Dest, err)
+			continue
+		}
+		ret.Pushed = append(ret.Pushed, bid)
+	}
+	span.Infof("push shards: vuid[%d], dest[%+v], pushed[%d/%d]", args.Vuid, args.Dest, len(ret.Pushed), len(args.Bids))
+	c.RespondJSON(ret)
+}
+
+// pushShard streams the normal shard to the destination, the crc of the shard written by the
+// destination must be the same as the one of the source.
+func (s *Service) pushShard(ctx context.Context, cs core.ChunkAPI, bid proto.BlobID,
+	dest proto.VunitLocation, ioType bnapi.IOType,
+) error {
+	sm, err := cs.ReadShardMeta(ctx, bid)
+	if err != nil {
+		return handlerBidNotFoundErr(err)
+	}
+	if sm.Flag != bnapi.ShardStatusNormal {
+		return bloberr.ErrShardMarkDeleted
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		shard := core.NewShardReader(bid, cs.Vuid(), 0, 0, pw)
+		_, err := cs.Read(ctx, shard)
+		if err != nil && isShardErr(err) {
+			s.inspectMgr.reportBadShard(cs, bid, err)
+		}
+		pw.CloseWithError(err)
+	}()
+
+	crc, err := s.shardPusher.PutShard(ctx, dest.Host, &bnapi.PutShardArgs{
+		DiskID: dest.DiskID, Vuid: dest.Vuid, Bid: bid, Size: int64(sm.Size), Type: ioType, Body: pr,
+	})
+	pr.CloseWithError(err)
+	if err != nil {
+		return err
+	}
+	if crc != sm.Crc {
+		return fmt.Errorf("mismatched crc of destination %d, source %d", crc, sm.Crc)
+	}
+	return nil
+}
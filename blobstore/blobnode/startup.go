@@ -414,6 +414,7 @@ func NewService(conf Config) (svr *Service, err error) {
 		span.Errorf("Failed to new worker service, err: %v", err)
 		return
 	}
+	svr.shardPusher = bnapi.New(&conf.WorkerConfig.BlobNode)
 
 	// background loop goroutines
 	go svr.loopHeartbeatToClusterMgr()
@@ -23,6 +23,7 @@ import (
 
 	bnapi "github.com/cubefs/cubefs/blobstore/api/blobnode"
 	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/blobnode/base/qos"
 	"github.com/cubefs/cubefs/blobstore/blobnode/core"
 	bloberr "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
@@ -43,6 +44,8 @@ type Service struct {
 
 	// client handler
 	ClusterMgrClient *cmapi.Client
+	// puts the shards pushed to the other blobnodes
+	shardPusher bnapi.StorageAPI
 
 	Conf       *Config
 	inspectMgr *DataInspectMgr
@@ -217,6 +220,44 @@ func (s *Service) DiskStat(c *rpc.Context) {
 	c.RespondJSON(&info)
 }
 
+/*
+ *  method:         GET
+ *  url:            /disk/load/diskid/{diskid}
+ *  response body:  json.Marshal(DiskLoad)
+ */
+func (s *Service) DiskLoad(c *rpc.Context) {
+	args := new(bnapi.DiskStatArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+
+	span.Debugf("diskload args: %v", args)
+	if !bnapi.IsValidDiskID(args.DiskID) {
+		c.RespondError(bloberr.ErrInvalidDiskId)
+		return
+	}
+
+	s.lock.RLock()
+	ds, exist := s.Disks[args.DiskID]
+	s.lock.RUnlock()
+	if !exist {
+		span.Errorf("diskID %d not exist", args.DiskID)
+		c.RespondError(bloberr.ErrNoSuchDisk)
+		return
+	}
+
+	ioQos := ds.GetIoQos()
+	c.RespondJSON(&bnapi.DiskLoad{
+		DiskID:     args.DiskID,
+		ReadCount:  ioQos.IOCount(qos.IOTypeRead),
+		WriteCount: ioQos.IOCount(qos.IOTypeWrite),
+	})
+}
+
 func (s *Service) copyDiskStorages(ctx context.Context) []core.DiskAPI {
 	disks := make([]core.DiskAPI, 0)
 	s.lock.RLock()
@@ -16,11 +16,17 @@ package blobnode
 
 import (
 	"context"
+	"hash/crc32"
+	"io"
+	"sync"
 
+	bnapi "github.com/cubefs/cubefs/blobstore/api/blobnode"
 	"github.com/cubefs/cubefs/blobstore/api/scheduler"
 	"github.com/cubefs/cubefs/blobstore/blobnode/base"
 	"github.com/cubefs/cubefs/blobstore/blobnode/base/workutils"
 	"github.com/cubefs/cubefs/blobstore/blobnode/client"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/ec"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/common/trace"
 	"github.com/cubefs/cubefs/blobstore/util/limit"
@@ -32,14 +38,19 @@ type InspectTaskMgr struct {
 	taskLimit limit.Limiter
 	bidGetter client.IBlobNode
 	reporter  scheduler.IInspector
+	// ratio of shard bytes to verify parity, 0 means not to verify
+	parityCoverage float64
 }
 
 // NewInspectTaskMgr returns inspect task manager
-func NewInspectTaskMgr(concurrency int, bidGetter client.IBlobNode, reporter scheduler.IInspector) *InspectTaskMgr {
+func NewInspectTaskMgr(concurrency int, parityCoverage float64, bidGetter client.IBlobNode,
+	reporter scheduler.IInspector,
+) *InspectTaskMgr {
 	return &InspectTaskMgr{
-		taskLimit: count.New(concurrency),
-		bidGetter: bidGetter,
-		reporter:  reporter,
+		taskLimit:      count.New(concurrency),
+		bidGetter:      bidGetter,
+		reporter:       reporter,
+		parityCoverage: parityCoverage,
 	}
 }
 
@@ -116,7 +127,19 @@ func (mgr *InspectTaskMgr) doInspect(ctx context.Context, task *proto.VolumeInsp
 			continue
 		}
 
-		if existStatus.ExistCnt() == 0 || existStatus.ExistCnt() == mode.GetShardNum() {
+		if existStatus.ExistCnt() == mode.GetShardNum() {
+			if mgr.parityCoverage > 0 {
+				ok, err := mgr.verifyParity(ctx, mode, replicas, bid.Bid)
+				if err != nil {
+					span.Errorf("verify parity failed: vid[%d], bid[%d], err[%+v]", replicas[0].Vuid.Vid(), bid.Bid, err)
+				} else if !ok {
+					span.Warnf("parity of blob mismatched: vid[%d], bid[%d]", replicas[0].Vuid.Vid(), bid.Bid)
+				}
+			}
+			continue
+		}
+
+		if existStatus.ExistCnt() == 0 {
 			continue
 		}
 
@@ -130,3 +153,52 @@ func (mgr *InspectTaskMgr) doInspect(ctx context.Context, task *proto.VolumeInsp
 	ret.MissedShards = allBlobMissed
 	return ret
 }
+
+// verifyParity verifies parity with data of the blob whose shards all exist,
+// only on the sampled byte ranges of shards as the cheap screen of scrubbing.
+func (mgr *InspectTaskMgr) verifyParity(ctx context.Context, mode codemode.CodeMode,
+	replicas []proto.VunitLocation, bid proto.BlobID,
+) (bool, error) {
+	shards := make([][]byte, len(replicas))
+	errs := make([]error, len(replicas))
+	wg := sync.WaitGroup{}
+	for idx := range replicas {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			shards[idx], errs[idx] = mgr.getShard(ctx, replicas[idx], bid)
+		}(idx)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return false, err
+		}
+	}
+	// nothing to verify of empty blob
+	if len(shards[0]) == 0 {
+		return true, nil
+	}
+
+	encoder, err := workutils.GetEncoder(mode)
+	if err != nil {
+		return false, err
+	}
+	return ec.VerifySampled(encoder, shards, ec.SampleConfig{Coverage: mgr.parityCoverage})
+}
+
+func (mgr *InspectTaskMgr) getShard(ctx context.Context, replica proto.VunitLocation, bid proto.BlobID) ([]byte, error) {
+	body, crc, err := mgr.bidGetter.GetShard(ctx, replica, bid, bnapi.BackgroundIO)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(data) != crc {
+		return nil, errCrcNotMatch
+	}
+	return data, nil
+}
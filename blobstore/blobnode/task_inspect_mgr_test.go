@@ -15,11 +15,13 @@
 package blobnode
 
 import (
+	"bytes"
 	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	bnapi "github.com/cubefs/cubefs/blobstore/api/blobnode"
 	"github.com/cubefs/cubefs/blobstore/api/scheduler"
 	"github.com/cubefs/cubefs/blobstore/common/codemode"
 	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
@@ -43,7 +45,7 @@ func testTaskInspectMgrDo(t *testing.T, mode codemode.CodeMode) {
 	bids := []proto.BlobID{1, 2, 3, 4, 5, 6, 7}
 	sizes := []int64{10, 1024, 1024, 1024, 1024, 1024, 1024}
 	getter := NewMockGetterWithBids(replicas, mode, bids, sizes)
-	mgr := NewInspectTaskMgr(1, getter, newMockReporter(t))
+	mgr := NewInspectTaskMgr(1, 0, getter, newMockReporter(t))
 	task := proto.VolumeInspectTask{
 		TaskID:   "InspectTask_XXX",
 		Mode:     mode,
@@ -128,13 +130,50 @@ func verifyInspectResult(t *testing.T, expect, FailShards []*proto.MissedShard)
 	}
 }
 
+func TestTaskInspectMgrVerifyParity(t *testing.T) {
+	testWithAllMode(t, testTaskInspectMgrVerifyParity)
+}
+
+func testTaskInspectMgrVerifyParity(t *testing.T, mode codemode.CodeMode) {
+	replicas := genMockVol(1, mode)
+	bids := []proto.BlobID{1, 2, 3}
+	sizes := []int64{10, 1024, 16 << 10}
+	getter := NewMockGetterWithBids(replicas, mode, bids, sizes)
+	mgr := NewInspectTaskMgr(1, 0.5, getter, newMockReporter(t))
+	for _, bid := range bids {
+		ok, err := mgr.verifyParity(context.Background(), mode, replicas, bid)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	// stale parity is found by the full verification
+	parity := replicas[mode.Tactic().N]
+	data := genMockBytes('x', sizes[1])
+	require.NoError(t, getter.PutShard(context.Background(), parity, 2, sizes[1], bytes.NewReader(data), bnapi.BackgroundIO))
+	mgr.parityCoverage = 1
+	ok, err := mgr.verifyParity(context.Background(), mode, replicas, 2)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// parity mismatched is not reported as missed shards
+	task := proto.VolumeInspectTask{TaskID: "InspectTask_XXX", Mode: mode, Replicas: replicas}
+	ret := mgr.doInspect(context.Background(), &task)
+	require.NoError(t, ret.Err())
+	require.Equal(t, 0, len(ret.MissedShards))
+
+	getter.setFail(parity.Vuid, errors.New("fake error"))
+	_, err = mgr.verifyParity(context.Background(), mode, replicas, 1)
+	require.Error(t, err)
+	getter.setWell(parity.Vuid)
+}
+
 func TestTaskInspectMgrAddTask(t *testing.T) {
 	mode := codemode.EC6P10L2
 	replicas := genMockVol(1, mode)
 	bids := []proto.BlobID{1, 2, 3, 4, 5, 6, 7}
 	sizes := []int64{10, 1024, 1024, 1024, 1024, 1024, 1024}
 	getter := NewMockGetterWithBids(replicas, mode, bids, sizes)
-	mgr := NewInspectTaskMgr(1, getter, newMockReporter(t))
+	mgr := NewInspectTaskMgr(1, 0, getter, newMockReporter(t))
 	task := proto.VolumeInspectTask{
 		TaskID:   "InspectTask_XXX",
 		Mode:     mode,
@@ -156,7 +195,7 @@ func TestTaskInspectMgrAddTask2(t *testing.T) {
 	bids := []proto.BlobID{1, 2, 3}
 	sizes := []int64{1024, 1024, 1024}
 	getter := NewMockGetterWithBids(replicas, mode, bids, sizes)
-	mgr := NewInspectTaskMgr(1, getter, newMockReporter(t))
+	mgr := NewInspectTaskMgr(1, 0, getter, newMockReporter(t))
 	task := proto.VolumeInspectTask{
 		TaskID:   "InspectTask_XXX",
 		Mode:     mode,
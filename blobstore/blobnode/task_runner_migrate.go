@@ -19,6 +19,7 @@ import (
 	"errors"
 	"sync"
 
+	"github.com/cubefs/cubefs/blobstore/api/blobnode"
 	"github.com/cubefs/cubefs/blobstore/api/scheduler"
 	"github.com/cubefs/cubefs/blobstore/blobnode/base/workutils"
 	"github.com/cubefs/cubefs/blobstore/blobnode/client"
@@ -102,11 +103,24 @@ func (w *MigrateWorker) GenTasklets(ctx context.Context) ([]Tasklet, *WorkError)
 	return BidsSplit(ctx, migBids, workutils.TaskBufPool.GetMigrateBufSize())
 }
 
+// canDirectPush returns true if the shards can be pushed by the source to the destination directly,
+// the source of the disk repair task is broken
+func (w *MigrateWorker) canDirectPush() bool {
+	return w.t.TaskType != proto.TaskTypeDiskRepair && w.canDirectDownload()
+}
+
 // ExecTasklet execute migrate tasklet
 func (w *MigrateWorker) ExecTasklet(ctx context.Context, tasklet Tasklet) *WorkError {
+	bids := tasklet.bids
+	if w.canDirectPush() {
+		if bids = w.pushShards(ctx, bids); len(bids) == 0 {
+			return nil
+		}
+	}
+
 	replicas := w.t.Sources
 	mode := w.t.CodeMode
-	shardRecover := NewShardRecover(replicas, mode, tasklet.bids, w.bolbNodeCli, w.downloadShardConcurrency, w.t.TaskType)
+	shardRecover := NewShardRecover(replicas, mode, bids, w.bolbNodeCli, w.downloadShardConcurrency, w.t.TaskType)
 	defer shardRecover.ReleaseBuf()
 
 	return MigrateBids(ctx,
@@ -114,10 +128,50 @@ func (w *MigrateWorker) ExecTasklet(ctx context.Context, tasklet Tasklet) *WorkE
 		w.t.SourceVuid.Index(),
 		w.t.Destination,
 		w.canDirectDownload(),
-		tasklet.bids,
+		bids,
 		w.bolbNodeCli)
 }
 
+// pushShards makes the source push the shards to the destination, which halves the hops of the
+// data through the worker. It returns the shards not pushed, such as the missing or damaged ones,
+// which are migrated through the worker.
+func (w *MigrateWorker) pushShards(ctx context.Context, bids []*ShardInfoSimple) []*ShardInfoSimple {
+	span := trace.SpanFromContextSafe(ctx)
+	var src *proto.VunitLocation
+	for idx := range w.t.Sources {
+		if w.t.Sources[idx].Vuid == w.t.SourceVuid {
+			src = &w.t.Sources[idx]
+			break
+		}
+	}
+	if src == nil {
+		return bids
+	}
+
+	ids := make([]proto.BlobID, 0, len(bids))
+	for _, bid := range bids {
+		ids = append(ids, bid.Bid)
+	}
+	pushed, err := w.bolbNodeCli.PushShards(ctx, *src, w.t.Destination, ids, blobnode.BackgroundIO)
+	if err != nil {
+		span.Warnf("push shards failed and migrate through worker: src[%+v], dst[%+v], err[%v]", *src, w.t.Destination, err)
+		return bids
+	}
+
+	done := make(map[proto.BlobID]struct{}, len(pushed))
+	for _, bid := range pushed {
+		done[bid] = struct{}{}
+	}
+	left := make([]*ShardInfoSimple, 0, len(bids))
+	for _, bid := range bids {
+		if _, ok := done[bid.Bid]; !ok {
+			left = append(left, bid)
+		}
+	}
+	span.Infof("push shards: src[%+v], dst[%+v], pushed[%d], left[%d]", *src, w.t.Destination, len(pushed), len(left))
+	return left
+}
+
 // Check checks migrate task execute result
 func (w *MigrateWorker) Check(ctx context.Context) *WorkError {
 	return CheckVunit(ctx, w.benchmarkBids, w.t.Destination, w.bolbNodeCli)
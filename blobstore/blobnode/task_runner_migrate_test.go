@@ -234,6 +234,66 @@ func TestMigrateExecTasklet(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestMigrateExecTaskletPush(t *testing.T) {
+	mode := codemode.EC6P6
+	replicas := genMockVol(100, mode)
+	badi := 3
+	dest := replicas[badi]
+	dest.Vuid, _ = proto.NewVuid(100, uint8(badi), 2)
+	dest.DiskID = 2
+	balanceTask := &proto.MigrateTask{
+		TaskID:      "mock_balance_task_id",
+		TaskType:    proto.TaskTypeBalance,
+		CodeMode:    mode,
+		Sources:     replicas,
+		Destination: dest,
+		SourceVuid:  replicas[badi].Vuid,
+	}
+	bids := []proto.BlobID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	sizes := []int64{1024, 2048, 0, 512, 23, 65, 12, 50, 100, 2047}
+
+	workutils.TaskBufPool = workutils.NewBufPool(&workutils.BufConfig{
+		MigrateBufSize:     2 * 1024,
+		MigrateBufCapacity: 100,
+		RepairBufSize:      1,
+		RepairBufCapacity:  1,
+	})
+	getter := NewMockGetterWithBids(replicas, mode, bids, sizes)
+	getter.vunits[dest.Vuid] = newMockVunit(dest.Vuid, api.ChunkStatusNormal)
+	crcMap := make(map[proto.BlobID]uint32)
+	for _, bid := range bids {
+		crcMap[bid] = getter.getShardCrc32(replicas[badi].Vuid, bid)
+	}
+	// the shard missing in the source is migrated through the worker
+	getter.Delete(context.Background(), replicas[badi].Vuid, bids[1])
+
+	w := NewMigrateWorker(MigrateTaskEx{taskInfo: balanceTask, blobNodeCli: getter, downloadShardConcurrency: 1})
+	tasklets, werr := w.GenTasklets(context.Background())
+	require.Nil(t, werr)
+	for _, tasklet := range tasklets {
+		require.Nil(t, w.ExecTasklet(context.Background(), tasklet))
+	}
+	require.Equal(t, len(bids)-1, getter.getPushed())
+	require.Nil(t, w.Check(context.Background()))
+	for _, bid := range bids {
+		_, crc, err := getter.GetShard(context.Background(), dest, bid, api.BackgroundIO)
+		require.NoError(t, err)
+		require.Equal(t, crcMap[bid], crc)
+	}
+
+	// the source of the disk repair task is broken, the shards are not pushed
+	repairTask := *balanceTask
+	repairTask.TaskType = proto.TaskTypeDiskRepair
+	getter.Delete(context.Background(), dest.Vuid, bids[0])
+	w = NewMigrateWorker(MigrateTaskEx{taskInfo: &repairTask, blobNodeCli: getter, downloadShardConcurrency: 1})
+	tasklets, werr = w.GenTasklets(context.Background())
+	require.Nil(t, werr)
+	for _, tasklet := range tasklets {
+		require.Nil(t, w.ExecTasklet(context.Background(), tasklet))
+	}
+	require.Equal(t, len(bids)-1, getter.getPushed())
+}
+
 func TestMigrateCheck(t *testing.T) {
 	mode := codemode.EC16P20L2
 	replicas := genMockVol(100, codemode.CodeMode(mode))
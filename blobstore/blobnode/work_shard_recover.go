@@ -19,7 +19,9 @@ import (
 	"errors"
 	"hash/crc32"
 	"io"
+	"math"
 	"math/rand"
+	"sort"
 	"sync"
 	"unsafe"
 
@@ -27,6 +29,7 @@ import (
 	"github.com/cubefs/cubefs/blobstore/blobnode/base/workutils"
 	"github.com/cubefs/cubefs/blobstore/blobnode/client"
 	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/ec"
 	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
@@ -103,30 +106,41 @@ func (locs Vunits) IntactGlobalSet(mode codemode.CodeMode, bad []uint8) Vunits {
 	return locs.Subset(idxes)
 }
 
-func (stripe *repairStripe) genDownloadPlans() []downloadPlan {
-	badi := stripe.badIdxes
-	n := stripe.n
-	var downloadPlans []downloadPlan
-	var wellReplications Vunits
-
-	stripeReplicas := make([]proto.VunitLocation, len(stripe.replicas))
-	copy(stripeReplicas, stripe.replicas)
-	rand.Shuffle(len(stripeReplicas), func(i, j int) {
-		stripeReplicas[i], stripeReplicas[j] = stripeReplicas[j], stripeReplicas[i]
-	})
-
+func (stripe *repairStripe) wellReplicas() Vunits {
 	badMap := make(map[uint8]struct{})
-	for _, bad := range badi {
+	for _, bad := range stripe.badIdxes {
 		badMap[bad] = struct{}{}
 	}
 
-	for _, replica := range stripeReplicas {
-		replicaIdx := replica.Vuid.Index()
-		if _, ok := badMap[replicaIdx]; ok {
+	var wellReplications Vunits
+	for _, replica := range stripe.replicas {
+		if _, ok := badMap[replica.Vuid.Index()]; ok {
 			continue
 		}
 		wellReplications = append(wellReplications, replica)
 	}
+	return wellReplications
+}
+
+// genDownloadPlans gen download plans which read the least loaded well replicas first,
+// replica without load is regarded as the most loaded one
+func (stripe *repairStripe) genDownloadPlans(loads map[proto.Vuid]int32) []downloadPlan {
+	n := stripe.n
+	var downloadPlans []downloadPlan
+
+	wellReplications := stripe.wellReplicas()
+	rand.Shuffle(len(wellReplications), func(i, j int) {
+		wellReplications[i], wellReplications[j] = wellReplications[j], wellReplications[i]
+	})
+	replicaLoad := func(vuid proto.Vuid) int32 {
+		if load, ok := loads[vuid]; ok {
+			return load
+		}
+		return math.MaxInt32
+	}
+	sort.SliceStable(wellReplications, func(i, j int) bool {
+		return replicaLoad(wellReplications[i].Vuid) < replicaLoad(wellReplications[j].Vuid)
+	})
 
 	planCnt := len(wellReplications) - int(n) + 1
 	for i := 0; i < planCnt; i++ {
@@ -572,7 +586,8 @@ func (r *ShardRecover) repairStripe(ctx context.Context, repairBids []proto.Blob
 	// step1:gen download plans for repair
 	span := trace.SpanFromContextSafe(ctx)
 
-	downloadPlans := stripe.genDownloadPlans()
+	loads := r.getReplicasLoad(ctx, stripe.wellReplicas())
+	downloadPlans := stripe.genDownloadPlans(loads)
 	span.Infof("start repairStripe: downloadPlans len[%d], len(repairBids)[%d]", len(downloadPlans), len(repairBids))
 	failBids := repairBids
 	// step2:download data according download plans and repair data
@@ -590,6 +605,30 @@ func (r *ShardRecover) repairStripe(ctx context.Context, repairBids []proto.Blob
 	return err
 }
 
+// getReplicasLoad returns disk load of replicas, which are failed to get load are not included
+func (r *ShardRecover) getReplicasLoad(ctx context.Context, replicas Vunits) map[proto.Vuid]int32 {
+	span := trace.SpanFromContextSafe(ctx)
+	loads := make(map[proto.Vuid]int32, len(replicas))
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for _, replica := range replicas {
+		wg.Add(1)
+		go func(rep proto.VunitLocation) {
+			defer wg.Done()
+			load, err := r.shardGetter.DiskLoad(ctx, rep)
+			if err != nil {
+				span.Warnf("get disk load failed: replica[%+v], err[%+v]", rep, err)
+				return
+			}
+			mu.Lock()
+			loads[rep.Vuid] = load
+			mu.Unlock()
+		}(replica)
+	}
+	wg.Wait()
+	return loads
+}
+
 func (r *ShardRecover) download(ctx context.Context, repairBids []proto.BlobID, replicas Vunits) {
 	wg := sync.WaitGroup{}
 	tp := taskpool.New(len(replicas), len(replicas))
@@ -748,9 +787,20 @@ func (r *ShardRecover) repair(ctx context.Context, repairBids []proto.BlobID, st
 			continue
 		}
 
-		err = encoder.Reconstruct(blobShards, recoverIdxOfStripe)
+		// reconstruct huge shards chunk by chunk, stop it if the task is canceled or timeout
+		progress := newReconstructProgress(string(r.taskType))
+		progressEncoder := ec.WithProgress(encoder, 0, func(done, total int) error {
+			span.Debugf("reconstruct progress: bid[%d], done[%d/%d]", bid, done, total)
+			progress.report(done, total)
+			return ctx.Err()
+		})
+		err = progressEncoder.Reconstruct(blobShards, recoverIdxOfStripe)
+		progress.finish()
 		if err != nil {
 			span.Errorf("reconstruct shard failed: err[%+v],codemode:%v", err, r.codeMode.Tactic())
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 			return errBidCanNotRecover
 		}
 
@@ -20,6 +20,8 @@ import (
 	"hash/crc32"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/require"
 
 	"github.com/cubefs/cubefs/blobstore/blobnode/base/workutils"
@@ -180,6 +182,38 @@ func testRepairByGlobalStripe(t *testing.T, mode codemode.CodeMode) {
 	}
 	testCheckData(t, repair, getter, badi)
 	repair.ReleaseBuf()
+	// nothing remains in the gauge after the reconstructions finished
+	require.Zero(t, gaugeValue(t, reconstructRemainingMetric.WithLabelValues(string(repair.taskType))))
+}
+
+func gaugeValue(t *testing.T, gauge prometheus.Gauge) float64 {
+	m := &dto.Metric{}
+	require.NoError(t, gauge.Write(m))
+	return m.GetGauge().GetValue()
+}
+
+func TestReconstructProgress(t *testing.T) {
+	gauge := reconstructRemainingMetric.WithLabelValues("test_reconstruct_progress")
+	p1 := newReconstructProgress("test_reconstruct_progress")
+	p2 := newReconstructProgress("test_reconstruct_progress")
+	p1.report(10, 100)
+	require.Equal(t, float64(90), gaugeValue(t, gauge))
+	p2.report(50, 200)
+	require.Equal(t, float64(240), gaugeValue(t, gauge))
+	p1.report(100, 100)
+	require.Equal(t, float64(150), gaugeValue(t, gauge))
+	p1.finish()
+	require.Equal(t, float64(150), gaugeValue(t, gauge))
+
+	// the remaining bytes of a failed reconstruction are removed
+	p2.report(60, 200)
+	require.Equal(t, float64(140), gaugeValue(t, gauge))
+	p2.finish()
+	require.Zero(t, gaugeValue(t, gauge))
+
+	// never reported
+	newReconstructProgress("test_reconstruct_progress").finish()
+	require.Zero(t, gaugeValue(t, gauge))
 }
 
 func TestRecoverLocalReplicaShards(t *testing.T) {
@@ -403,6 +437,46 @@ func TestDownload(t *testing.T) {
 	}
 }
 
+func TestGenDownloadPlansByLoad(t *testing.T) {
+	ctx := context.Background()
+	repair, _, getter, replicas := InitMockRepair(codemode.EC6P6)
+	stripe := repairStripe{
+		replicas: replicas,
+		n:        codemode.EC6P6.T().N,
+		m:        codemode.EC6P6.T().M,
+		badIdxes: []uint8{0, 1},
+	}
+
+	// the larger index, the less loaded, and replica 2 failed to get load
+	for _, replica := range replicas {
+		getter.setLoad(replica.Vuid, int32(100-replica.Vuid.Index()))
+	}
+	getter.setFail(replicas[2].Vuid, errors.New("fake error"))
+	loads := repair.getReplicasLoad(ctx, stripe.wellReplicas())
+	require.Equal(t, 9, len(loads))
+	require.NotContains(t, loads, replicas[2].Vuid)
+
+	plans := stripe.genDownloadPlans(loads)
+	require.Equal(t, 5, len(plans))
+	for i, plan := range plans {
+		require.Equal(t, stripe.n, len(plan.downloadReplicas))
+		for j, replica := range plan.downloadReplicas[:stripe.n-1] {
+			require.Equal(t, uint8(11-j), replica.Vuid.Index())
+		}
+		require.Equal(t, uint8(11-(stripe.n-1)-i), plan.downloadReplicas[stripe.n-1].Vuid.Index())
+	}
+	require.Equal(t, uint8(2), plans[4].downloadReplicas[stripe.n-1].Vuid.Index())
+
+	// without load, every plan has the well replicas only
+	plans = stripe.genDownloadPlans(nil)
+	require.Equal(t, 5, len(plans))
+	for _, plan := range plans {
+		for _, replica := range plan.downloadReplicas {
+			require.NotContains(t, stripe.badIdxes, replica.Vuid.Index())
+		}
+	}
+}
+
 func TestDirect(t *testing.T) {
 	ctx := context.Background()
 	repair, _, getter, _ := InitMockRepair(codemode.EC6P6)
@@ -71,8 +71,10 @@ type MockGetter struct {
 	mu       sync.Mutex
 	vunits   map[proto.Vuid]*mockVunit
 	failVuid map[proto.Vuid]error
+	loads    map[proto.Vuid]int32
 	bids     []proto.BlobID
 	sizes    []int64
+	pushed   int
 }
 
 func NewMockGetter(replicas []proto.VunitLocation, mode codemode.CodeMode) *MockGetter {
@@ -87,6 +89,7 @@ func NewMockGetterWithBids(replicas []proto.VunitLocation, mode codemode.CodeMod
 		sizes:    sizes,
 		vunits:   make(map[proto.Vuid]*mockVunit),
 		failVuid: make(map[proto.Vuid]error),
+		loads:    make(map[proto.Vuid]int32),
 	}
 	for _, replica := range replicas {
 		vunit := newMockVunit(replica.Vuid, api.ChunkStatusReadOnly)
@@ -173,6 +176,12 @@ func (getter *MockGetter) setWell(vuid proto.Vuid) {
 	delete(getter.failVuid, vuid)
 }
 
+func (getter *MockGetter) setLoad(vuid proto.Vuid, load int32) {
+	getter.mu.Lock()
+	defer getter.mu.Unlock()
+	getter.loads[vuid] = load
+}
+
 func (getter *MockGetter) setVunitStatus(vuid proto.Vuid, status api.ChunkStatus) {
 	getter.mu.Lock()
 	defer getter.mu.Unlock()
@@ -194,6 +203,33 @@ func (getter *MockGetter) PutShard(ctx context.Context, location proto.VunitLoca
 	return
 }
 
+func (getter *MockGetter) PushShards(ctx context.Context, src, dst proto.VunitLocation, bids []proto.BlobID, ioType api.IOType) (pushed []proto.BlobID, err error) {
+	getter.mu.Lock()
+	defer getter.mu.Unlock()
+	for _, vuid := range []proto.Vuid{src.Vuid, dst.Vuid} {
+		if err, ok := getter.failVuid[vuid]; ok {
+			return nil, err
+		}
+	}
+	for _, bid := range bids {
+		reader, _, err := getter.vunits[src.Vuid].getShard(bid)
+		if err != nil {
+			continue
+		}
+		data, _ := io.ReadAll(reader)
+		getter.vunits[dst.Vuid].putShard(bid, data)
+		pushed = append(pushed, bid)
+	}
+	getter.pushed += len(pushed)
+	return
+}
+
+func (getter *MockGetter) getPushed() int {
+	getter.mu.Lock()
+	defer getter.mu.Unlock()
+	return getter.pushed
+}
+
 func (getter *MockGetter) GetShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID, ioType api.IOType) (body io.ReadCloser, crc32 uint32, err error) {
 	getter.mu.Lock()
 	defer getter.mu.Unlock()
@@ -251,6 +287,15 @@ func (getter *MockGetter) StatChunk(ctx context.Context, location proto.VunitLoc
 	return
 }
 
+func (getter *MockGetter) DiskLoad(ctx context.Context, location proto.VunitLocation) (load int32, err error) {
+	getter.mu.Lock()
+	defer getter.mu.Unlock()
+	if err, ok := getter.failVuid[location.Vuid]; ok {
+		return 0, err
+	}
+	return getter.loads[location.Vuid], nil
+}
+
 func (getter *MockGetter) getSizes() []int64 {
 	getter.mu.Lock()
 	defer getter.mu.Unlock()
@@ -50,6 +50,9 @@ type WorkerConfigMeter struct {
 	ShardRepairConcurrency int `json:"shard_repair_concurrency"`
 	// volume inspect concurrency
 	InspectConcurrency int `json:"inspect_concurrency"`
+	// ratio of shard bytes to verify parity of blobs in volume inspect, in (0, 1],
+	// 1 means full verification and 0 means not to verify
+	InspectParityCoverage float64 `json:"inspect_parity_coverage"`
 
 	// batch download concurrency of single tasklet
 	DownloadShardConcurrency int `json:"download_shard_concurrency"`
@@ -143,7 +146,7 @@ func NewWorkerService(cfg *WorkerConfig, service cmapi.APIService, clusterID pro
 	renewalConfig.ClientTimeoutMs = 1000 * proto.RenewalTimeoutS
 	renewalCli := scheduler.New(&renewalConfig, service, clusterID)
 	taskRunnerMgr := NewTaskRunnerMgr(idc, cfg.WorkerConfigMeter, NewMigrateWorker, renewalCli, schedulerCli)
-	inspectTaskMgr := NewInspectTaskMgr(cfg.InspectConcurrency, blobNodeCli, schedulerCli)
+	inspectTaskMgr := NewInspectTaskMgr(cfg.InspectConcurrency, cfg.InspectParityCoverage, blobNodeCli, schedulerCli)
 
 	shardRepairLimit := count.New(cfg.ShardRepairConcurrency)
 	shardRepairer := NewShardRepairer(blobNodeCli)
@@ -64,6 +64,14 @@ func (m *mBlobNodeCli) PutShard(ctx context.Context, location proto.VunitLocatio
 	return
 }
 
+func (m *mBlobNodeCli) PushShards(ctx context.Context, src, dst proto.VunitLocation, bids []proto.BlobID, ioType bnapi.IOType) ([]proto.BlobID, error) {
+	return nil, nil
+}
+
+func (m *mBlobNodeCli) DiskLoad(ctx context.Context, location proto.VunitLocation) (int32, error) {
+	return 0, nil
+}
+
 type mockScheCli struct {
 	*mocks.MockIScheduler
 
@@ -144,7 +152,7 @@ func newMockWorkService(t *testing.T) (*Service, *mockScheCli) {
 		blobNodeCli:      blobnodeCli,
 
 		taskRunnerMgr:  NewTaskRunnerMgr("z0", getDefaultConfig().WorkerConfigMeter, NewMockMigrateWorker, schedulerCli, schedulerCli),
-		inspectTaskMgr: NewInspectTaskMgr(1, blobnodeCli, schedulerCli),
+		inspectTaskMgr: NewInspectTaskMgr(1, 0, blobnodeCli, schedulerCli),
 	}
 	return &Service{WorkerService: workSvr}, schedulerCli
 }
@@ -0,0 +1,219 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/desertbit/grumble"
+
+	"github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/cli/common"
+	"github.com/cubefs/cubefs/blobstore/cli/common/fmt"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+const (
+	_campaignID = "campaign_id"
+	_disks      = "disks"
+	_idc        = "idc"
+	_bandwidth  = "bandwidth_mbps"
+)
+
+func addCmdCampaign(cmd *grumble.Command) {
+	campaignCommand := &grumble.Command{
+		Name:     "campaign",
+		Help:     "migrate campaign tools",
+		LongHelp: "campaign groups the migrate tasks of disks, such as a disk drop or an AZ evacuation",
+	}
+	cmd.AddCommand(campaignCommand)
+
+	campaignCommand.AddCommand(&grumble.Command{
+		Name: "add",
+		Help: "add campaign of disks or idc",
+		Run:  cmdAddCampaign,
+		Flags: func(f *grumble.Flags) {
+			clusterFlags(f)
+			f.StringL(_taskType, "", "task_type, one of disk_drop and disk_repair")
+			f.StringL(_disks, "", "disk ids separated by comma")
+			f.StringL(_idc, "", "all disks of the idc if disks is empty")
+			f.IntL(_bandwidth, 0, "bandwidth budget in MB/s, 0 means unlimited")
+		},
+		Args: func(a *grumble.Args) {
+			a.String(_campaignID, "campaign id")
+		},
+	})
+	campaignCommand.AddCommand(&grumble.Command{
+		Name:  "list",
+		Help:  "list campaigns with progress and eta",
+		Run:   cmdListCampaigns,
+		Flags: clusterFlags,
+	})
+	campaignCommand.AddCommand(&grumble.Command{
+		Name:  "pause",
+		Help:  "pause campaign",
+		Run:   cmdPauseCampaign,
+		Flags: clusterFlags,
+		Args: func(a *grumble.Args) {
+			a.String(_campaignID, "campaign id")
+		},
+	})
+	campaignCommand.AddCommand(&grumble.Command{
+		Name:  "resume",
+		Help:  "resume campaign",
+		Run:   cmdResumeCampaign,
+		Flags: clusterFlags,
+		Args: func(a *grumble.Args) {
+			a.String(_campaignID, "campaign id")
+		},
+	})
+	campaignCommand.AddCommand(&grumble.Command{
+		Name:  "budget",
+		Help:  "set bandwidth budget of campaign",
+		Run:   cmdSetCampaignBudget,
+		Flags: clusterFlags,
+		Args: func(a *grumble.Args) {
+			a.String(_campaignID, "campaign id")
+			a.Int(_bandwidth, "bandwidth budget in MB/s, 0 means unlimited")
+		},
+	})
+	campaignCommand.AddCommand(&grumble.Command{
+		Name:  "delete",
+		Help:  "delete campaign",
+		Run:   cmdDeleteCampaign,
+		Flags: clusterFlags,
+		Args: func(a *grumble.Args) {
+			a.String(_campaignID, "campaign id")
+		},
+	})
+}
+
+func newSchedulerClient(c *grumble.Context) scheduler.IScheduler {
+	clusterID := getClusterID(c.Flags)
+	return scheduler.New(&scheduler.Config{}, newClusterMgrClient(clusterID), clusterID)
+}
+
+func parseDisks(s string) (disks []proto.DiskID, err error) {
+	for _, field := range strings.Split(s, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		diskID, err := strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			return nil, errcode.ErrIllegalDiskID
+		}
+		disks = append(disks, proto.DiskID(diskID))
+	}
+	return
+}
+
+func cmdAddCampaign(c *grumble.Context) error {
+	disks, err := parseDisks(c.Flags.String(_disks))
+	if err != nil {
+		return err
+	}
+	args := &scheduler.AddCampaignArgs{
+		ID:            c.Args.String(_campaignID),
+		TaskType:      proto.TaskType(c.Flags.String(_taskType)),
+		Disks:         disks,
+		IDC:           c.Flags.String(_idc),
+		BandwidthMBPS: c.Flags.Int(_bandwidth),
+	}
+	if !args.Valid() {
+		return errcode.ErrIllegalArguments
+	}
+	target := fmt.Sprintf("disks %v", disks)
+	if len(disks) == 0 {
+		target = fmt.Sprintf("all disks of idc %s", args.IDC)
+	}
+	if !common.Confirm(fmt.Sprintf("add %s campaign %s of %s ?", args.TaskType, args.ID, target)) {
+		return nil
+	}
+	if err = newSchedulerClient(c).AddCampaign(common.CmdContext(), args); err != nil {
+		return err
+	}
+	fmt.Println("add campaign successfully")
+	return nil
+}
+
+func cmdListCampaigns(c *grumble.Context) error {
+	ret, err := newSchedulerClient(c).ListCampaigns(common.CmdContext())
+	if err != nil {
+		return err
+	}
+	for _, campaign := range ret.Campaigns {
+		curr := 100
+		if campaign.TotalTasksCnt > 0 && campaign.MigratedTasksCnt < campaign.TotalTasksCnt {
+			curr = campaign.MigratedTasksCnt * 100 / campaign.TotalTasksCnt
+		}
+		eta := "unknown"
+		if campaign.ETASec >= 0 {
+			eta = campaign.ETA
+		}
+		fmt.Printf("%s %s disks:%d paused:%v budget:%dMB/s current:%.2fMB/s throttled:%v eta:%s\n",
+			campaign.ID, campaign.TaskType, len(campaign.Disks), campaign.Paused,
+			campaign.BandwidthMBPS, campaign.CurrentMBPS, campaign.Throttled, eta)
+		fmt.Printf("[%s] MigratedTasksCnt: %d/TotalTasksCnt: %d\n", common.LineBar(curr, 50),
+			campaign.MigratedTasksCnt, campaign.TotalTasksCnt)
+	}
+	return nil
+}
+
+func cmdPauseCampaign(c *grumble.Context) error {
+	id := c.Args.String(_campaignID)
+	if !common.Confirm(fmt.Sprintf("pause campaign %s ?", id)) {
+		return nil
+	}
+	if err := newSchedulerClient(c).PauseCampaign(common.CmdContext(), &scheduler.CampaignArgs{ID: id}); err != nil {
+		return err
+	}
+	fmt.Println("pause campaign successfully")
+	return nil
+}
+
+func cmdResumeCampaign(c *grumble.Context) error {
+	id := c.Args.String(_campaignID)
+	if err := newSchedulerClient(c).ResumeCampaign(common.CmdContext(), &scheduler.CampaignArgs{ID: id}); err != nil {
+		return err
+	}
+	fmt.Println("resume campaign successfully")
+	return nil
+}
+
+func cmdSetCampaignBudget(c *grumble.Context) error {
+	args := &scheduler.SetCampaignBudgetArgs{
+		ID:            c.Args.String(_campaignID),
+		BandwidthMBPS: c.Args.Int(_bandwidth),
+	}
+	if err := newSchedulerClient(c).SetCampaignBudget(common.CmdContext(), args); err != nil {
+		return err
+	}
+	fmt.Println("set campaign budget successfully")
+	return nil
+}
+
+func cmdDeleteCampaign(c *grumble.Context) error {
+	id := c.Args.String(_campaignID)
+	if !common.Confirm(fmt.Sprintf("delete campaign %s ?", id)) {
+		return nil
+	}
+	if err := newSchedulerClient(c).DeleteCampaign(common.CmdContext(), &scheduler.CampaignArgs{ID: id}); err != nil {
+		return err
+	}
+	fmt.Println("delete campaign successfully")
+	return nil
+}
@@ -84,6 +84,7 @@ func Register(app *grumble.App) {
 	addCmdMigrateTask(schedulerCommand)
 	addCmdVolumeInspectCheckpointTask(schedulerCommand)
 	addCmdKafkaConsumer(schedulerCommand)
+	addCmdCampaign(schedulerCommand)
 }
 
 func leaderStat(c *grumble.Context) error {
@@ -73,6 +73,13 @@ func (s *Service) ConfigSet(c *rpc.Context) {
 		c.RespondError(apierrors.ErrIllegalArguments)
 		return
 	}
+	if args.Key == proto.WriteDegradedIDCConfigKey {
+		if err := s.checkWriteDegradedIDC(args.Value); err != nil {
+			span.Warnf("invalid write degraded idc: %s, error: %v", args.Value, err)
+			c.RespondError(errors.Info(apierrors.ErrIllegalArguments).Detail(err))
+			return
+		}
+	}
 
 	data, err := json.Marshal(args)
 	if err != nil {
@@ -119,3 +126,22 @@ func (s *Service) ConfigDelete(c *rpc.Context) {
 		return
 	}
 }
+
+// checkWriteDegradedIDC checks the idcs are in this cluster, and one idc at least is not degraded
+func (s *Service) checkWriteDegradedIDC(value string) error {
+	all := make(map[string]bool, len(s.IDC))
+	for _, idc := range s.IDC {
+		all[idc] = true
+	}
+	idcs := proto.ParseIDCs(value)
+	for _, idc := range idcs {
+		if !all[idc] {
+			return errors.Newf("idc %s not in cluster", idc)
+		}
+		delete(all, idc)
+	}
+	if len(all) == 0 {
+		return errors.New("all idcs can not be write degraded")
+	}
+	return nil
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -45,6 +46,14 @@ func TestConfig(t *testing.T) {
 		// failed case
 		err = testClusterClient.SetConfig(ctx, proto.CodeModeConfigKey, string(b))
 		require.Error(t, err)
+
+		// write degraded idc
+		err = testClusterClient.SetConfig(ctx, proto.WriteDegradedIDCConfigKey, testServiceCfg.IDC[0])
+		require.NoError(t, err)
+		err = testClusterClient.SetConfig(ctx, proto.WriteDegradedIDCConfigKey, "not-exist-idc")
+		require.Error(t, err)
+		err = testClusterClient.SetConfig(ctx, proto.WriteDegradedIDCConfigKey, strings.Join(testServiceCfg.IDC, ","))
+		require.Error(t, err)
 	}
 
 	// test get clusterMgr config
@@ -51,6 +51,8 @@ type allocatorConfig struct {
 	tg       topoInfoGetter
 	diffRack bool
 	diffHost bool
+	// relax the shards of azs if any az is short of the even shards
+	asymmetricAZ bool
 }
 
 func newAllocator(cfg allocatorConfig) *allocator {
@@ -68,8 +70,9 @@ type allocator struct {
 }
 
 type allocRet struct {
-	Idc   string
-	Disks []proto.DiskID
+	Idc     string
+	Disks   []proto.DiskID
+	Indexes []int // the shard indexes of the disks
 }
 
 // Alloc alloc disk id
@@ -98,8 +101,20 @@ func (a *allocator) Alloc(ctx context.Context, diskType proto.DiskType, mode cod
 
 	idcAllocators := diskSetAllocator.alloc(ctx, len(idcIndexes[0]))
 	if len(idcAllocators) < len(idcIndexes) {
-		span.Errorf("need %d idcAllocators, but got %d", len(idcIndexes), len(idcAllocators))
-		return nil, ErrNoEnoughSpace
+		if !a.cfg.asymmetricAZ {
+			span.Errorf("need %d idcAllocators, but got %d", len(idcIndexes), len(idcAllocators))
+			return nil, ErrNoEnoughSpace
+		}
+		idcAllocators, idcIndexes = diskSetAllocator.allocAsymmetric(mode.Tactic())
+		if idcAllocators == nil {
+			span.Errorf("alloc asymmetric shards of code mode %s failed, not enough space in azs", mode.String())
+			return nil, ErrNoEnoughSpace
+		}
+		span.Warnf("alloc asymmetric shards of code mode %s, layout: %v", mode.String(), idcIndexes)
+	} else {
+		rand.Shuffle(len(idcIndexes), func(i, j int) {
+			idcIndexes[i], idcIndexes[j] = idcIndexes[j], idcIndexes[i]
+		})
 	}
 
 	for i := range idcIndexes {
@@ -111,8 +126,9 @@ func (a *allocator) Alloc(ctx context.Context, diskType proto.DiskType, mode cod
 		}
 
 		ret = append(ret, allocRet{
-			Idc:   idcAllocators[i].idc,
-			Disks: _disks,
+			Idc:     idcAllocators[i].idc,
+			Disks:   _disks,
+			Indexes: idcIndexes[i],
 		})
 	}
 	// update diskset and nodeset free chunk
@@ -265,6 +281,24 @@ func (d *diskSetAllocator) alloc(ctx context.Context, count int) (ret []*idcAllo
 	return
 }
 
+// allocAsymmetric returns all the idc allocators and the shard indexes of them, which are
+// relaxed from the even layout of the code mode, nil if the shards are not satisfied.
+func (d *diskSetAllocator) allocAsymmetric(tactic codemode.Tactic) ([]*idcAllocator, [][]int) {
+	idcAllocators := make([]*idcAllocator, 0, len(d.idcAllocators))
+	for _, idcAllocator := range d.idcAllocators {
+		idcAllocators = append(idcAllocators, idcAllocator)
+	}
+	capacities := make([]int, len(idcAllocators))
+	for i := range idcAllocators {
+		capacities[i] = idcAllocators[i].capacity()
+	}
+	counts, ok := asymmetricShardCounts(tactic, capacities)
+	if !ok {
+		return nil, nil
+	}
+	return idcAllocators, asymmetricLayout(tactic, counts)
+}
+
 // idcAllocator represent an idc allocator
 type idcAllocator struct {
 	idc string
@@ -693,3 +693,81 @@ func TestAllocCost(t *testing.T) {
 	wg.Wait()
 	t.Log("op cost:", time.Since(start)/time.Duration(totalTimes))
 }
+
+func TestAsymmetricShardCounts(t *testing.T) {
+	tactic := codemode.EC6P6.Tactic()
+	for _, cs := range []struct {
+		capacities []int
+		counts     []int
+		ok         bool
+	}{
+		{[]int{10, 10, 10}, []int{4, 4, 4}, true},
+		{[]int{10, 10, 2}, []int{5, 5, 2}, true},
+		{[]int{10, 10, 0}, []int{6, 6, 0}, true},
+		{[]int{10, 5, 0}, nil, false},
+		{[]int{10, 10}, nil, false},
+	} {
+		counts, ok := asymmetricShardCounts(tactic, cs.capacities)
+		require.Equal(t, cs.ok, ok)
+		require.Equal(t, cs.counts, counts)
+	}
+	_, ok := asymmetricShardCounts(codemode.EC6P3L3.Tactic(), []int{10, 10, 2})
+	require.False(t, ok)
+
+	layout := asymmetricLayout(tactic, []int{5, 5, 2})
+	require.Equal(t, [][]int{{0, 3, 6, 8, 10}, {1, 4, 7, 9, 11}, {2, 5}}, layout)
+}
+
+func TestAllocAsymmetricAZ(t *testing.T) {
+	testDiskMgr, closeTestDiskMgr := initTestDiskMgr(t)
+	defer closeTestDiskMgr()
+	// disk never expire
+	testDiskMgr.HeartbeatExpireIntervalS = 6000
+	testDiskMgr.HostAware = true
+
+	_, ctx := trace.StartSpanFromContext(context.Background(), "alloc-asymmetric-az")
+	// the third az has only two hosts
+	initTestDiskMgrNodes(t, testDiskMgr, 1, 10, testIdcs[0], testIdcs[1])
+	initTestDiskMgrDisks(t, testDiskMgr, 1, 10, true, testIdcs[0], testIdcs[1])
+	initTestDiskMgrNodes(t, testDiskMgr, 11, 12, testIdcs[2])
+	initTestDiskMgrDisks(t, testDiskMgr, 11, 12, true, testIdcs[2])
+
+	testDiskMgr.refresh(ctx)
+	blobNodeAllocator := testDiskMgr.allocators[proto.NodeRoleBlobNode].Load().(*allocator)
+	_, err := blobNodeAllocator.Alloc(ctx, proto.DiskTypeHDD, codemode.EC6P6)
+	require.ErrorIs(t, err, ErrNoEnoughSpace)
+
+	testDiskMgr.AsymmetricAZ = true
+	testDiskMgr.refresh(ctx)
+	blobNodeAllocator = testDiskMgr.allocators[proto.NodeRoleBlobNode].Load().(*allocator)
+	ret, err := blobNodeAllocator.Alloc(ctx, proto.DiskTypeHDD, codemode.EC6P6)
+	require.NoError(t, err)
+	require.Len(t, ret, 3)
+	indexes := make(map[int]bool)
+	for _, r := range ret {
+		expected := 5
+		if r.Idc == testIdcs[2] {
+			expected = 2
+		}
+		require.Len(t, r.Disks, expected)
+		require.Len(t, r.Indexes, expected)
+		for _, idx := range r.Indexes {
+			indexes[idx] = true
+		}
+	}
+	require.Len(t, indexes, codemode.EC6P6.GetShardNum())
+
+	spaceStatInfo := testDiskMgr.Stat(ctx)
+	require.Len(t, spaceStatInfo.AZRedundancies, 2)
+	for _, redundancy := range spaceStatInfo.AZRedundancies {
+		if redundancy.CodeMode == codemode.EC15P12 {
+			require.False(t, redundancy.Buildable)
+			continue
+		}
+		require.True(t, redundancy.Buildable)
+		require.True(t, redundancy.Asymmetric)
+		require.Equal(t, map[string]int{testIdcs[0]: 5, testIdcs[1]: 5, testIdcs[2]: 2}, redundancy.ShardsPerAZ)
+		require.Equal(t, 6, redundancy.ToleratedShards)
+		require.Equal(t, 1, redundancy.ToleratedShardsAZDown)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package diskmgr
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+)
+
+// capacity returns the most shards of a volume can be allocated in the idc, one shard per host
+// if host aware.
+func (s *idcAllocator) capacity() int {
+	free := atomic.LoadInt64(&s.freeChunk)
+	if s.diffHost {
+		hosts := int64(0)
+		for _, stg := range s.blobNodeStorages {
+			if atomic.LoadInt64(&stg.freeChunk) > 0 {
+				hosts++
+			}
+		}
+		if hosts < free {
+			free = hosts
+		}
+	}
+	if free > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int(free)
+}
+
+// asymmetricShardCounts returns the shards of each az of the volume, which are relaxed from the
+// even layout of the code mode within the capacities of the azs. The shards of an az are no more
+// than M, so that the data is still readable if any az is down. The local stripes of LRC are
+// bound to the azs, so the code modes of LRC are not relaxed.
+func asymmetricShardCounts(tactic codemode.Tactic, capacities []int) ([]int, bool) {
+	if len(capacities) != tactic.AZCount || tactic.AZCount < 2 || tactic.L != 0 {
+		return nil, false
+	}
+	counts := make([]int, tactic.AZCount)
+	limits := make([]int, tactic.AZCount)
+	even := (tactic.N + tactic.M) / tactic.AZCount
+	left := tactic.N + tactic.M
+	for i, capacity := range capacities {
+		limits[i] = capacity
+		if limits[i] > tactic.M {
+			limits[i] = tactic.M
+		}
+		counts[i] = even
+		if counts[i] > limits[i] {
+			counts[i] = limits[i]
+		}
+		left -= counts[i]
+	}
+	// move the shards to the az of the fewest shards with room, to keep the most redundancy
+	for ; left > 0; left-- {
+		chosen := -1
+		for i := range counts {
+			if counts[i] < limits[i] && (chosen < 0 || counts[i] < counts[chosen]) {
+				chosen = i
+			}
+		}
+		if chosen < 0 {
+			return nil, false
+		}
+		counts[chosen]++
+	}
+	return counts, true
+}
+
+// asymmetricLayout deals the data shards and then the parity shards round robin to the azs
+// with room, so that the data shards are spread over the azs as even as possible.
+func asymmetricLayout(tactic codemode.Tactic, counts []int) [][]int {
+	layout := make([][]int, len(counts))
+	for i := range layout {
+		layout[i] = make([]int, 0, counts[i])
+	}
+	az := 0
+	for idx := 0; idx < tactic.N+tactic.M; idx++ {
+		for len(layout[az]) == counts[az] {
+			az = (az + 1) % len(counts)
+		}
+		layout[az] = append(layout[az], idx)
+		az = (az + 1) % len(counts)
+	}
+	return layout
+}
+
+// azRedundancy reports the shards of each az of a volume can be built by the code mode, and
+// the redundancy achieved by them.
+func azRedundancy(mode codemode.CodeMode, idcs []string, idcAllocators map[string]*idcAllocator, asymmetric bool) clustermgr.AZRedundancy {
+	tactic := mode.Tactic()
+	ret := clustermgr.AZRedundancy{CodeMode: mode, ToleratedShards: tactic.M}
+	if len(idcs) != tactic.AZCount || len(idcAllocators) == 0 {
+		return ret
+	}
+	capacities := make([]int, len(idcs))
+	for i, idc := range idcs {
+		if stg, ok := idcAllocators[idc]; ok {
+			capacities[i] = stg.capacity()
+		}
+	}
+
+	layout := tactic.GetECLayoutByAZ()
+	counts := make([]int, len(layout))
+	for i := range layout {
+		counts[i] = len(layout[i])
+		if counts[i] > capacities[i] {
+			counts = nil
+			break
+		}
+	}
+	if counts == nil {
+		ok := false
+		if asymmetric {
+			counts, ok = asymmetricShardCounts(tactic, capacities)
+		}
+		if !ok {
+			return ret
+		}
+		ret.Asymmetric = true
+	}
+
+	ret.Buildable = true
+	ret.ShardsPerAZ = make(map[string]int, len(idcs))
+	// the global shards of the az of the most, the local shards of LRC are even
+	most := (tactic.N + tactic.M) / tactic.AZCount
+	for i, idc := range idcs {
+		ret.ShardsPerAZ[idc] = counts[i]
+		if ret.Asymmetric && counts[i] > most {
+			most = counts[i]
+		}
+	}
+	ret.ToleratedShardsAZDown = -1
+	if tactic.AZCount >= 2 && most <= tactic.M {
+		ret.ToleratedShardsAZDown = tactic.M - most
+	}
+	return ret
+}
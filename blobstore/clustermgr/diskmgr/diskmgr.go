@@ -18,7 +18,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"math/rand"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -105,6 +104,9 @@ type AllocPolicy struct {
 	DiskType proto.DiskType
 	CodeMode codemode.CodeMode
 	Vuids    []proto.Vuid
+	// size of the chunks to create, the chunk size of the disk type if not set,
+	// the units of an existing volume keep the size of the volume
+	ChunkSize int64
 
 	Excludes   []proto.DiskID
 	DiskSetID  proto.DiskSetID
@@ -130,6 +132,10 @@ type DiskMgrConfig struct {
 	IDC                      []string            `json:"-"`
 	CodeModes                []codemode.CodeMode `json:"-"`
 	ChunkSize                int64               `json:"-"`
+	// chunk size of the disk types, overrides ChunkSize
+	ChunkSizes map[proto.DiskType]int64 `json:"-"`
+	// relax the shards of azs of the EC volumes if any az is short of the even shards
+	AsymmetricAZ bool `json:"asymmetric_az"`
 
 	CopySetConfigs map[proto.NodeRole]map[proto.DiskType]CopySetConfig `json:"copy_set_configs"`
 }
@@ -473,7 +479,11 @@ func (d *DiskMgr) AllocChunks(ctx context.Context, policy AllocPolicy) ([]proto.
 		retVuids        = make([]proto.Vuid, len(policy.Vuids))
 		retryTimes      = policy.RetryTimes
 		allocLock       sync.Mutex
+		chunkSize       = policy.ChunkSize
 	)
+	if chunkSize == 0 {
+		chunkSize = d.getChunkSize(policy.DiskType)
+	}
 
 	// repair
 	if len(policy.Excludes) > 0 {
@@ -494,25 +504,18 @@ func (d *DiskMgr) AllocChunks(ctx context.Context, policy AllocPolicy) ([]proto.
 			idcVuidIndexMap[policy.Idc][vuid] = idx
 		}
 	} else {
-		tactic := policy.CodeMode.Tactic()
-		idcIndexes := tactic.GetECLayoutByAZ()
-		rand.Seed(time.Now().UnixNano())
-		rand.Shuffle(len(idcIndexes), func(i, j int) {
-			idcIndexes[i], idcIndexes[j] = idcIndexes[j], idcIndexes[i]
-		})
-		span.Debugf("idcIndexes is %#v", idcIndexes)
-
 		ret, err := allocator.Alloc(ctx, policy.DiskType, policy.CodeMode)
 		if err != nil {
 			span.Errorf("create volume alloc first time failed, err: %s", err.Error())
 			return nil, nil, err
 		}
 
-		for idcIdx, r := range ret {
+		for _, r := range ret {
 			idc := r.Idc
+			span.Debugf("idc %s indexes is %v", idc, r.Indexes)
 			idcDiskMap[idc] = r.Disks
 			idcVuidIndexMap[idc] = make(map[proto.Vuid]int)
-			for _, vuidIdx := range idcIndexes[idcIdx] {
+			for _, vuidIdx := range r.Indexes {
 				vuid := policy.Vuids[vuidIdx]
 				idcVuidMap[idc] = append(idcVuidMap[idc], vuid)
 				idcVuidIndexMap[idc][vuid] = vuidIdx
@@ -567,7 +570,7 @@ func (d *DiskMgr) AllocChunks(ctx context.Context, policy AllocPolicy) ([]proto.
 				defer wg.Done()
 
 				blobNodeErr := d.blobNodeClient.CreateChunk(ctx, host,
-					&blobnode.CreateChunkArgs{DiskID: disks[idx], Vuid: vuids[idx], ChunkSize: d.ChunkSize})
+					&blobnode.CreateChunkArgs{DiskID: disks[idx], Vuid: vuids[idx], ChunkSize: chunkSize})
 				if blobNodeErr != nil {
 					vuidPrefix := vuids[idx].VuidPrefix()
 					newVuid := proto.EncodeVuid(vuidPrefix, vuids[idx].Epoch()+1)
@@ -857,6 +860,7 @@ func (d *DiskMgr) addDisk(ctx context.Context, info *blobnode.DiskInfo) error {
 		return nil
 	}
 	// alloc diskSetID and compatible case: update follower first
+	diskType := proto.DiskTypeHDD
 	if node, ok := d.allNodes[info.NodeID]; ok {
 		if node.info.Status == proto.NodeStatusDropped {
 			span.Warnf("node is dropped, disk info: %v", info)
@@ -867,10 +871,11 @@ func (d *DiskMgr) addDisk(ctx context.Context, info *blobnode.DiskInfo) error {
 			return nil
 		}
 		info.DiskSetID = d.topoMgrs[node.info.Role].AllocDiskSetID(ctx, info, node.info, d.CopySetConfigs[node.info.Role][node.info.DiskType])
+		diskType = node.info.DiskType
 	}
 
 	// calculate free and max chunk count
-	info.MaxChunkCnt = info.Size / d.ChunkSize
+	info.MaxChunkCnt = info.Size / d.getChunkSize(diskType)
 	info.FreeChunkCnt = info.MaxChunkCnt - info.UsedChunkCnt
 	err := d.diskTbl.AddDisk(diskInfoToDiskInfoRecord(info))
 	if err != nil {
@@ -965,17 +970,19 @@ func (d *DiskMgr) heartBeatDiskInfo(ctx context.Context, infos []*blobnode.DiskH
 			span.Warnf("disk not found in all disk, diskID: %d", info.DiskID)
 			continue
 		}
+		chunkSize := d.getChunkSize(d.getDiskType(diskInfo))
 		// memory modify disk heartbeat info, dump into db timely
 		diskInfo.lock.Lock()
 		diskInfo.info.Free = info.Free
 		diskInfo.info.Size = info.Size
 		diskInfo.info.Used = info.Used
 		diskInfo.info.UsedChunkCnt = info.UsedChunkCnt
+		diskInfo.info.ChunkUsed = info.ChunkUsed
 		// calculate free and max chunk count
-		diskInfo.info.MaxChunkCnt = info.Size / d.ChunkSize
+		diskInfo.info.MaxChunkCnt = info.Size / chunkSize
 		// use the minimum value as free chunk count
 		diskInfo.info.FreeChunkCnt = diskInfo.info.MaxChunkCnt - diskInfo.info.UsedChunkCnt
-		freeChunkCnt := info.Free / d.ChunkSize
+		freeChunkCnt := (info.Free - chunkUnallocatedSpace(info, chunkSize)) / chunkSize
 		if freeChunkCnt < diskInfo.info.FreeChunkCnt {
 			span.Debugf("use minimum free chunk count, disk id[%d], free chunk[%d]", diskInfo.diskID, freeChunkCnt)
 			diskInfo.info.FreeChunkCnt = freeChunkCnt
@@ -1153,6 +1160,27 @@ func (d *DiskMgr) getNode(nodeID proto.NodeID) (node *nodeItem, exist bool) {
 	return
 }
 
+// chunkUnallocatedSpace returns the space which the existing chunks on the disk can still grow into,
+// the free space of filesystem is not all available for new chunks. It's zero if the allocated space
+// of chunks is not reported by the blobnode.
+func chunkUnallocatedSpace(info *blobnode.DiskHeartBeatInfo, chunkSize int64) int64 {
+	if info.ChunkUsed <= 0 {
+		return 0
+	}
+	if unallocated := info.UsedChunkCnt*chunkSize - info.ChunkUsed; unallocated > 0 {
+		return unallocated
+	}
+	return 0
+}
+
+// getChunkSize returns the size of the chunks created on the disks of the disk type
+func (d *DiskMgr) getChunkSize(diskType proto.DiskType) int64 {
+	if size, ok := d.ChunkSizes[diskType]; ok && size > 0 {
+		return size
+	}
+	return d.ChunkSize
+}
+
 func (d *DiskMgr) getDiskType(disk *diskItem) proto.DiskType {
 	n, _ := d.getNode(disk.info.NodeID)
 	if n == nil {
@@ -226,6 +226,72 @@ func TestDiskMgr_Heartbeat(t *testing.T) {
 	require.Equal(t, 2, len(disks))
 }
 
+func TestDiskMgr_ChunkSizeOfDiskType(t *testing.T) {
+	testDiskMgr, closeTestDiskMgr := initTestDiskMgr(t)
+	defer closeTestDiskMgr()
+	initTestDiskMgrNodes(t, testDiskMgr, 1, 1, testIdcs[0])
+	initTestDiskMgrDisks(t, testDiskMgr, 1, 10, false, testIdcs[0])
+	_, ctx := trace.StartSpanFromContext(context.Background(), "")
+
+	chunkSize := testDiskMgr.ChunkSize * 4
+	testDiskMgr.ChunkSizes = map[proto.DiskType]int64{proto.DiskTypeHDD: chunkSize}
+	require.Equal(t, chunkSize, testDiskMgr.getChunkSize(proto.DiskTypeHDD))
+	require.Equal(t, testDiskMgr.ChunkSize, testDiskMgr.getChunkSize(proto.DiskTypeSSD))
+
+	heartbeatInfos := make([]*blobnode.DiskHeartBeatInfo, 0)
+	for i := 1; i <= 10; i++ {
+		diskInfo, err := testDiskMgr.GetDiskInfo(ctx, proto.DiskID(i))
+		require.NoError(t, err)
+		heartbeatInfos = append(heartbeatInfos, &diskInfo.DiskHeartBeatInfo)
+	}
+	err := testDiskMgr.heartBeatDiskInfo(ctx, heartbeatInfos)
+	require.NoError(t, err)
+
+	for i := 1; i <= 10; i++ {
+		diskInfo, err := testDiskMgr.GetDiskInfo(ctx, proto.DiskID(i))
+		require.NoError(t, err)
+		require.Equal(t, diskInfo.Size/chunkSize, diskInfo.MaxChunkCnt)
+		require.LessOrEqual(t, diskInfo.FreeChunkCnt, diskInfo.Free/chunkSize)
+	}
+}
+
+func TestDiskMgr_HeartbeatChunkUsed(t *testing.T) {
+	testDiskMgr, closeTestDiskMgr := initTestDiskMgr(t)
+	defer closeTestDiskMgr()
+	initTestDiskMgrNodes(t, testDiskMgr, 1, 1, testIdcs[0])
+	initTestDiskMgrDisks(t, testDiskMgr, 1, 1, false, testIdcs[0])
+	_, ctx := trace.StartSpanFromContext(context.Background(), "")
+
+	chunkSize := testDiskMgr.ChunkSize
+	// 50 chunks allocated 20 chunks of space, and other files used 10 chunks of space
+	heartbeat := &blobnode.DiskHeartBeatInfo{
+		DiskID:       proto.DiskID(1),
+		Size:         100 * chunkSize,
+		Used:         30 * chunkSize,
+		Free:         70 * chunkSize,
+		UsedChunkCnt: 50,
+	}
+	require.NoError(t, testDiskMgr.heartBeatDiskInfo(ctx, []*blobnode.DiskHeartBeatInfo{heartbeat}))
+	diskInfo, err := testDiskMgr.GetDiskInfo(ctx, proto.DiskID(1))
+	require.NoError(t, err)
+	require.Equal(t, int64(50), diskInfo.FreeChunkCnt)
+
+	// the existing chunks can still grow into 30 chunks of the free space
+	heartbeat.ChunkUsed = 20 * chunkSize
+	require.NoError(t, testDiskMgr.heartBeatDiskInfo(ctx, []*blobnode.DiskHeartBeatInfo{heartbeat}))
+	diskInfo, err = testDiskMgr.GetDiskInfo(ctx, proto.DiskID(1))
+	require.NoError(t, err)
+	require.Equal(t, 20*chunkSize, diskInfo.ChunkUsed)
+	require.Equal(t, int64(40), diskInfo.FreeChunkCnt)
+
+	// the chunks larger than the chunk size don't count
+	heartbeat.ChunkUsed = 60 * chunkSize
+	require.NoError(t, testDiskMgr.heartBeatDiskInfo(ctx, []*blobnode.DiskHeartBeatInfo{heartbeat}))
+	diskInfo, err = testDiskMgr.GetDiskInfo(ctx, proto.DiskID(1))
+	require.NoError(t, err)
+	require.Equal(t, int64(50), diskInfo.FreeChunkCnt)
+}
+
 func TestDiskMgr_ListDisks(t *testing.T) {
 	testDiskMgr, closeTestDiskMgr := initTestDiskMgr(t)
 	defer closeTestDiskMgr()
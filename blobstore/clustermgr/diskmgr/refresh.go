@@ -85,7 +85,7 @@ func (d *DiskMgr) refresh(ctx context.Context) {
 				for _, diskSet := range nodeSet.GetDiskSets() {
 					disks := diskSet.GetDisks()
 					// ecDiskSet[diskType] = append(ecDiskSet[diskType], disks...)
-					idcAllocators, diskSetFreeChunk := d.generateDiskSetStorage(ctx, disks, spaceStatInfo, diskStatInfo)
+					idcAllocators, diskSetFreeChunk := d.generateDiskSetStorage(ctx, disks, d.getChunkSize(diskType), spaceStatInfo, diskStatInfo)
 					diskSetAllocator := newDiskSetAllocator(diskSet.ID(), diskSetFreeChunk, idcAllocators)
 					diskSetAllocators[diskType][diskSet.ID()] = diskSetAllocator
 					nodeSetAllocator.addDiskSet(diskSetAllocator)
@@ -128,7 +128,7 @@ func (d *DiskMgr) refresh(ctx context.Context) {
 					diskStatInfo[d.IDC[i]] = &clustermgr.DiskStatInfo{IDC: d.IDC[i]}
 				}
 
-				ecIdcAllocators, ecFreeChunk := d.generateDiskSetStorage(ctx, ecDiskSet[diskType], ecSpaceStateInfo, diskStatInfo)
+				ecIdcAllocators, ecFreeChunk := d.generateDiskSetStorage(ctx, ecDiskSet[diskType], d.getChunkSize(diskType), ecSpaceStateInfo, diskStatInfo)
 
 				// initial ec allocator
 				diskSetAllocator := newDiskSetAllocator(ecDiskSetID, ecFreeChunk, ecIdcAllocators)
@@ -142,6 +142,12 @@ func (d *DiskMgr) refresh(ctx context.Context) {
 				for idc := range diskStatInfo {
 					ecSpaceStateInfo.DisksStatInfos = append(ecSpaceStateInfo.DisksStatInfos, *diskStatInfo[idc])
 				}
+				for _, mode := range d.CodeModes {
+					if !mode.T().IsReplicateMode() {
+						ecSpaceStateInfo.AZRedundancies = append(ecSpaceStateInfo.AZRedundancies,
+							azRedundancy(mode, d.IDC, ecIdcAllocators, d.AsymmetricAZ))
+					}
+				}
 				// no copy set register, set space info and disk stat info by ec statistic
 				if len(nodeSetsMap) == 0 {
 					spaceStatInfos[nodeRole][diskType] = ecSpaceStateInfo
@@ -150,6 +156,7 @@ func (d *DiskMgr) refresh(ctx context.Context) {
 
 				// TODO: calculate writable space by replicate code mode and ec code mode ratio
 				spaceStatInfos[nodeRole][diskType].WritableSpace = ecSpaceStateInfo.WritableSpace
+				spaceStatInfos[nodeRole][diskType].AZRedundancies = ecSpaceStateInfo.AZRedundancies
 			}
 		}
 
@@ -158,19 +165,20 @@ func (d *DiskMgr) refresh(ctx context.Context) {
 		}
 
 		d.allocators[nodeRole].Store(newAllocator(allocatorConfig{
-			nodeSets: nodeSetAllocators,
-			diskSets: diskSetAllocators,
-			dg:       d,
-			tg:       d.topoMgrs[nodeRole],
-			diffHost: d.HostAware,
-			diffRack: d.RackAware,
+			nodeSets:     nodeSetAllocators,
+			diskSets:     diskSetAllocators,
+			dg:           d,
+			tg:           d.topoMgrs[nodeRole],
+			diffHost:     d.HostAware,
+			diffRack:     d.RackAware,
+			asymmetricAZ: d.AsymmetricAZ,
 		}))
 	}
 
 	d.spaceStatInfo.Store(spaceStatInfos)
 }
 
-func (d *DiskMgr) generateDiskSetStorage(ctx context.Context, disks []*diskItem, spaceStatInfo *clustermgr.SpaceStatInfo,
+func (d *DiskMgr) generateDiskSetStorage(ctx context.Context, disks []*diskItem, chunkSize int64, spaceStatInfo *clustermgr.SpaceStatInfo,
 	diskStatInfosM map[string]*clustermgr.DiskStatInfo,
 ) (ret map[string]*idcAllocator, freeChunk int64) {
 	span := trace.SpanFromContextSafe(ctx)
@@ -306,13 +314,13 @@ func (d *DiskMgr) generateDiskSetStorage(ctx context.Context, disks []*diskItem,
 			}
 			freeChunk += idcFreeChunks[d.IDC[i]]
 		}
-		spaceStatInfo.WritableSpace += d.calculateWritable(idcBlobNodeStgs)
+		spaceStatInfo.WritableSpace += d.calculateWritable(idcBlobNodeStgs, chunkSize)
 	}
 
 	return
 }
 
-func (d *DiskMgr) calculateWritable(idcBlobNodeStgs map[string][]*blobNodeAllocator) int64 {
+func (d *DiskMgr) calculateWritable(idcBlobNodeStgs map[string][]*blobNodeAllocator, chunkSize int64) int64 {
 	// writable space statistic
 	codeMode, suCount := d.getMaxSuCount()
 	idcSuCount := suCount / len(d.IDC)
@@ -323,7 +331,7 @@ func (d *DiskMgr) calculateWritable(idcBlobNodeStgs map[string][]*blobNodeAlloca
 			lefts := make(maxHeap, 0)
 			n := int64(0)
 			for _, v := range stgs {
-				count := v.free / d.ChunkSize
+				count := v.free / chunkSize
 				if count > 0 {
 					lefts = append(lefts, count)
 				}
@@ -356,7 +364,7 @@ func (d *DiskMgr) calculateWritable(idcBlobNodeStgs map[string][]*blobNodeAlloca
 				minimumStripeCount = n
 			}
 		}
-		return minimumStripeCount * int64(codeMode.Tactic().N) * d.ChunkSize
+		return minimumStripeCount * int64(codeMode.Tactic().N) * chunkSize
 	}
 
 	if len(idcBlobNodeStgs) > 0 {
@@ -364,13 +372,13 @@ func (d *DiskMgr) calculateWritable(idcBlobNodeStgs map[string][]*blobNodeAlloca
 		for idc := range idcBlobNodeStgs {
 			idcChunkNum := int64(0)
 			for i := range idcBlobNodeStgs[idc] {
-				idcChunkNum += idcBlobNodeStgs[idc][i].free / d.ChunkSize
+				idcChunkNum += idcBlobNodeStgs[idc][i].free / chunkSize
 			}
 			if idcChunkNum < minimumChunkNum {
 				minimumChunkNum = idcChunkNum
 			}
 		}
-		return minimumChunkNum / int64(idcSuCount) * int64(codeMode.Tactic().N) * d.ChunkSize
+		return minimumChunkNum / int64(idcSuCount) * int64(codeMode.Tactic().N) * chunkSize
 	}
 
 	return 0
@@ -19,7 +19,7 @@ func TestWritableSpace(t *testing.T) {
 			idcBlobNodeStgs[testDiskMgr.IDC[i]] = append(idcBlobNodeStgs[testDiskMgr.IDC[i]], &blobNodeAllocator{free: 100 * testDiskMgr.ChunkSize})
 		}
 	}
-	testDiskMgr.calculateWritable(idcBlobNodeStgs)
+	testDiskMgr.calculateWritable(idcBlobNodeStgs, testDiskMgr.ChunkSize)
 	t.Log("writable space: ", spaceInfo.WritableSpace)
 }
 
@@ -0,0 +1,72 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"encoding/json"
+
+	"github.com/cubefs/cubefs/blobstore/clustermgr/persistence/raftdb"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/ec"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+// ecFingerprintKey is the key of the fingerprints of the code modes persisted in the raft db
+var ecFingerprintKey = []byte("#ec_fingerprints")
+
+// checkECFingerprints compares the fingerprints of the encoders of the code modes with the ones
+// persisted by the previous start, the encoding coefficients changed by an upgrade would make
+// the stripes written before unrecoverable. The fingerprints of the new code modes are persisted.
+func checkECFingerprints(raftDB *raftdb.RaftDB, modes []codemode.CodeMode) error {
+	fingerprints := make(map[string]string)
+	raw, err := raftDB.Get(ecFingerprintKey)
+	if err != nil {
+		return errors.Info(err, "get ec fingerprints failed")
+	}
+	if len(raw) > 0 {
+		if err = json.Unmarshal(raw, &fingerprints); err != nil {
+			return errors.Info(err, "unmarshal ec fingerprints failed")
+		}
+	}
+
+	changed := false
+	for _, mode := range modes {
+		encoder, err := ec.NewEncoder(ec.Config{CodeMode: mode.Tactic()})
+		if err != nil {
+			return errors.Info(err, "new encoder failed", mode.String())
+		}
+		fingerprint := encoder.Fingerprint()
+		if fingerprint == "" {
+			return errors.Newf("empty ec fingerprint of code mode %s", mode.String())
+		}
+		if persisted, ok := fingerprints[mode.String()]; ok {
+			if persisted != fingerprint {
+				return errors.Newf("ec fingerprint of code mode %s changed from %s to %s",
+					mode.String(), persisted, fingerprint)
+			}
+			continue
+		}
+		fingerprints[mode.String()] = fingerprint
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	if raw, err = json.Marshal(fingerprints); err != nil {
+		return errors.Info(err, "marshal ec fingerprints failed")
+	}
+	return raftDB.Put(ecFingerprintKey, raw)
+}
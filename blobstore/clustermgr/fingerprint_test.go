@@ -0,0 +1,54 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/clustermgr/persistence/raftdb"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+)
+
+func TestCheckECFingerprints(t *testing.T) {
+	tmpRaftDBPath, err := os.MkdirTemp("", "raftdb")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpRaftDBPath)
+	raftDB, err := raftdb.OpenRaftDB(tmpRaftDBPath)
+	require.NoError(t, err)
+	defer raftDB.Close()
+
+	modes := []codemode.CodeMode{codemode.EC6P6, codemode.EC6P10L2}
+	require.NoError(t, checkECFingerprints(raftDB, modes))
+	require.NoError(t, checkECFingerprints(raftDB, modes))
+	// the new code mode is persisted
+	require.NoError(t, checkECFingerprints(raftDB, append(modes, codemode.EC12P4)))
+	raw, err := raftDB.Get(ecFingerprintKey)
+	require.NoError(t, err)
+	fingerprints := make(map[string]string)
+	require.NoError(t, json.Unmarshal(raw, &fingerprints))
+	require.Len(t, fingerprints, 3)
+
+	// the coefficients changed
+	fingerprints[codemode.EC6P6.String()] = "changed"
+	raw, err = json.Marshal(fingerprints)
+	require.NoError(t, err)
+	require.NoError(t, raftDB.Put(ecFingerprintKey, raw))
+	require.Error(t, checkECFingerprints(raftDB, modes))
+	require.NoError(t, checkECFingerprints(raftDB, []codemode.CodeMode{codemode.EC12P4}))
+}
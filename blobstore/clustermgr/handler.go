@@ -80,6 +80,9 @@ func NewHandler(service *Service) *rpc.Router {
 
 	rpc.GET("/service/list", service.ServiceList)
 
+	rpc.RegisterArgsParser(&clustermgr.ListServiceEventsArgs{}, "json")
+	rpc.GET("/service/events", service.ServiceEvents, rpc.OptArgsQuery())
+
 	//==================volume==========================
 	rpc.RegisterArgsParser(&clustermgr.GetVolumeArgs{}, "json")
 	rpc.RegisterArgsParser(&clustermgr.ListVolumeArgs{}, "json")
@@ -109,6 +112,8 @@ func NewHandler(service *Service) *rpc.Router {
 
 	rpc.GET("/volume/unit/list", service.VolumeUnitList, rpc.OptArgsQuery())
 
+	rpc.GET("/volume/unit/reconcile/report", service.VolumeUnitReconcileReport)
+
 	rpc.GET("/volume/allocated/list", service.VolumeAllocatedList, rpc.OptArgsQuery())
 
 	rpc.POST("/admin/update/volume/unit", service.AdminUpdateVolumeUnit, rpc.OptArgsBody())
@@ -152,5 +157,14 @@ func NewHandler(service *Service) *rpc.Router {
 
 	rpc.GET("/kv/list", service.KvList, rpc.OptArgsQuery())
 
+	//==================usage==========================
+	rpc.RegisterArgsParser(&clustermgr.ListUsageArgs{}, "json")
+
+	rpc.POST("/usage/report", service.UsageReport, rpc.OptArgsBody())
+
+	rpc.GET("/usage/list", service.UsageList, rpc.OptArgsQuery())
+
+	rpc.GET("/usage/export", service.UsageExport, rpc.OptArgsQuery())
+
 	return rpc.DefaultRouter
 }
@@ -41,6 +41,15 @@ var (
 		},
 		[]string{"region", "cluster", "is_leader", "item"},
 	)
+	serviceProbeUnregisterMetric = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "blobstore",
+			Subsystem: "clusterMgr",
+			Name:      "service_probe_unregister",
+			Help:      "service node unregistered after health probe failed",
+		},
+		[]string{"region", "cluster", "service"},
+	)
 	VolInconsistencyMetric = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "blobstore",
@@ -56,6 +65,7 @@ func init() {
 	prometheus.MustRegister(raftStatMetric)
 	prometheus.MustRegister(diskHeartbeatChangeMetric)
 	prometheus.MustRegister(VolInconsistencyMetric)
+	prometheus.MustRegister(serviceProbeUnregisterMetric)
 }
 
 func (s *Service) report(ctx context.Context) {
@@ -73,6 +83,10 @@ func (s *Service) reportHeartbeatChange(num float64) {
 	diskHeartbeatChangeMetric.WithLabelValues(s.Region, s.ClusterID.ToString()).Set(num)
 }
 
+func (s *Service) reportServiceProbeUnregister(name string) {
+	serviceProbeUnregisterMetric.WithLabelValues(s.Region, s.ClusterID.ToString(), name).Inc()
+}
+
 func (s *Service) reportInConsistentVols(vids []proto.Vid) {
 	VolInconsistencyMetric.Reset()
 	isLeader := strconv.FormatBool(s.raftNode.IsLeader())
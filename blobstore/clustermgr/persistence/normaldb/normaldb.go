@@ -23,6 +23,7 @@ var (
 	configCF           = "config"
 	diskDropCF         = "disk_drop"
 	serviceCF          = "service"
+	usageCF            = "usage"
 	diskStatusIndexCF  = "disk-status"
 	diskHostIndexCF    = "disk-host"
 	diskIDCIndexCF     = "disk-idc"
@@ -35,6 +36,7 @@ var (
 		diskDropCF,
 		configCF,
 		serviceCF,
+		usageCF,
 		diskStatusIndexCF,
 		diskHostIndexCF,
 		diskIDCIndexCF,
@@ -0,0 +1,61 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package normaldb
+
+import (
+	"github.com/cubefs/cubefs/blobstore/common/kvstore"
+)
+
+type UsageTable struct {
+	tbl kvstore.KVTable
+}
+
+func OpenUsageTable(db *NormalDB) *UsageTable {
+	return &UsageTable{db.Table(usageCF)}
+}
+
+func (u *UsageTable) Get(key string) ([]byte, error) {
+	return u.tbl.Get([]byte(key))
+}
+
+func (u *UsageTable) Put(key string, data []byte) error {
+	return u.tbl.Put(kvstore.KV{Key: []byte(key), Value: data})
+}
+
+// RangePrefix ranges the values of keys with the prefix from the start key in order
+func (u *UsageTable) RangePrefix(prefix, start string, f func(key string, val []byte) bool) error {
+	iter := u.tbl.NewIterator(nil)
+	defer func() {
+		iter.Close()
+	}()
+	if start < prefix {
+		start = prefix
+	}
+	keyPrefix := []byte(prefix)
+	for iter.Seek([]byte(start)); iter.ValidForPrefix(keyPrefix); iter.Next() {
+		if err := iter.Err(); err != nil {
+			return err
+		}
+		key := string(iter.Key().Data())
+		val := make([]byte, iter.Value().Size())
+		copy(val, iter.Value().Data())
+		iter.Key().Free()
+		iter.Value().Free()
+		if !f(key, val) {
+			break
+		}
+	}
+	return nil
+}
@@ -15,6 +15,7 @@
 package clustermgr
 
 import (
+	"context"
 	"encoding/json"
 	"net"
 	"strings"
@@ -190,3 +191,51 @@ func (s *Service) ServiceList(c *rpc.Context) {
 	}
 	c.RespondJSON(info)
 }
+
+func (s *Service) ServiceEvents(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.ListServiceEventsArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept ServiceEvents request, args: %v", args)
+
+	if err := s.raftNode.ReadIndex(ctx); err != nil {
+		span.Errorf("read index error: %v", err)
+		c.RespondError(apierrors.ErrRaftReadIndex)
+		return
+	}
+	c.RespondJSON(s.ServiceMgr.ListEvents(args.Name))
+}
+
+// probeServices unregisters the service nodes which failed in health probing, so that
+// the consumers stop receiving the dead endpoints.
+func (s *Service) probeServices(ctx context.Context) {
+	span := trace.SpanFromContextSafe(ctx)
+	deads := s.ServiceMgr.Probe(ctx)
+	for _, node := range deads {
+		if !s.ServiceMgr.IsRegistered(node.Name, node.Host) {
+			continue
+		}
+		args := &clustermgr.UnregisterArgs{Name: node.Name, Host: node.Host, Reason: clustermgr.UnregisterReasonProbe}
+		data, err := json.Marshal(args)
+		if err != nil {
+			span.Errorf("json marshal unregister args failed, err: %v", err)
+			continue
+		}
+		span.Warnf("unregister dead service node: %+v", node)
+		err = s.raftNode.Propose(ctx,
+			base.EncodeProposeInfo(
+				s.ServiceMgr.GetModuleName(),
+				servicemgr.OpUnregister,
+				data,
+				base.ProposeContext{ReqID: span.TraceID()}))
+		if err != nil {
+			span.Errorf("unregister dead service node %+v raft propose failed, err: %v", node, err)
+			continue
+		}
+		s.reportServiceProbeUnregister(node.Name)
+	}
+}
@@ -80,7 +80,7 @@ func (s *ServiceMgr) Apply(ctx context.Context, opTypes []int32, datas [][]byte,
 					errs[idx] = errors.Info(err, OpUnregister, datas[idx]).Detail(err)
 					return
 				}
-				errs[idx] = s.handleUnregister(taskCtx, arg.Name, arg.Host)
+				errs[idx] = s.handleUnregister(taskCtx, arg.Name, arg.Host, arg.Reason)
 			})
 
 		case OpHeartbeat:
@@ -159,5 +159,6 @@ func (s *ServiceMgr) Flush(ctx context.Context) error {
 }
 
 func (s *ServiceMgr) NotifyLeaderChange(ctx context.Context, leader uint64, host string) {
-	// Do nothing.
+	// probe failures counted by the old leader are not reliable any more
+	s.resetProbeFailures()
 }
@@ -0,0 +1,178 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package servicemgr
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+)
+
+const (
+	defaultProbeIntervalS        = 30
+	defaultProbeTimeoutMs        = 3000
+	defaultProbeFailureThreshold = 3
+	defaultProbePath             = "/"
+	defaultProbeConcurrency      = 20
+	defaultMaxEvents             = 1000
+)
+
+// ServiceMgrConfig defines service manager configuration
+type ServiceMgrConfig struct {
+	// active health probing of registered service nodes, a node is unregistered by the
+	// leader after failing ProbeFailureThreshold probes in a row
+	EnableProbe           bool   `json:"enable_probe"`
+	ProbeIntervalS        int    `json:"probe_interval_s"`
+	ProbeTimeoutMs        int    `json:"probe_timeout_ms"`
+	ProbeFailureThreshold int    `json:"probe_failure_threshold"`
+	ProbePath             string `json:"probe_path"`
+	// max number of register/unregister events kept in memory
+	MaxEvents int `json:"max_events"`
+}
+
+func (c *ServiceMgrConfig) checkAndFix() {
+	if c.ProbeIntervalS <= 0 {
+		c.ProbeIntervalS = defaultProbeIntervalS
+	}
+	if c.ProbeTimeoutMs <= 0 {
+		c.ProbeTimeoutMs = defaultProbeTimeoutMs
+	}
+	if c.ProbeFailureThreshold <= 0 {
+		c.ProbeFailureThreshold = defaultProbeFailureThreshold
+	}
+	if c.ProbePath == "" {
+		c.ProbePath = defaultProbePath
+	}
+	if c.MaxEvents <= 0 {
+		c.MaxEvents = defaultMaxEvents
+	}
+}
+
+type probeFunc func(ctx context.Context, host string) error
+
+func newHTTPProbe(cfg ServiceMgrConfig) probeFunc {
+	cli := &http.Client{Timeout: time.Duration(cfg.ProbeTimeoutMs) * time.Millisecond}
+	return func(ctx context.Context, host string) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, host+cfg.ProbePath, nil)
+		if err != nil {
+			return err
+		}
+		// any response means that the service is alive
+		resp, err := cli.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+}
+
+func (s *ServiceMgr) ProbeEnabled() bool {
+	return s.cfg.EnableProbe
+}
+
+func (s *ServiceMgr) ProbeInterval() time.Duration {
+	return time.Duration(s.cfg.ProbeIntervalS) * time.Second
+}
+
+// Probe probes all registered service nodes concurrently, and returns the nodes which have
+// failed ProbeFailureThreshold probes in a row. The failure counts are kept in the memory of
+// current node, so Probe should only be called by the leader.
+func (s *ServiceMgr) Probe(ctx context.Context) (deads []clustermgr.ServiceNode) {
+	span := trace.SpanFromContextSafe(ctx)
+	info, _ := s.ListServiceInfo()
+
+	wg := sync.WaitGroup{}
+	limit := make(chan struct{}, defaultProbeConcurrency)
+	errs := make([]error, len(info.Nodes))
+	for i := range info.Nodes {
+		idx := i
+		wg.Add(1)
+		limit <- struct{}{}
+		go func() {
+			defer func() {
+				<-limit
+				wg.Done()
+			}()
+			errs[idx] = s.probe(ctx, info.Nodes[idx].Host)
+		}()
+	}
+	wg.Wait()
+
+	s.probeLock.Lock()
+	defer s.probeLock.Unlock()
+	failures := make(map[nodeName]int)
+	for i, node := range info.Nodes {
+		if errs[i] == nil {
+			continue
+		}
+		key := nodeName{node.Name, node.Host}
+		failures[key] = s.probeFailures[key] + 1
+		span.Warnf("probe service[%s] host[%s] failed %d times: %v", node.Name, node.Host, failures[key], errs[i])
+		if failures[key] >= s.cfg.ProbeFailureThreshold {
+			deads = append(deads, node)
+		}
+	}
+	// nodes probed successfully or unregistered are dropped
+	s.probeFailures = failures
+	return
+}
+
+// ProbeFailures returns the continuous probe failures of the service node.
+func (s *ServiceMgr) ProbeFailures(name, host string) int {
+	s.probeLock.Lock()
+	defer s.probeLock.Unlock()
+	return s.probeFailures[nodeName{name, host}]
+}
+
+func (s *ServiceMgr) resetProbeFailures() {
+	s.probeLock.Lock()
+	s.probeFailures = make(map[nodeName]int)
+	s.probeLock.Unlock()
+}
+
+func (s *ServiceMgr) addEvent(typ, name, host, reason string) {
+	s.eventLock.Lock()
+	defer s.eventLock.Unlock()
+	s.events = append(s.events, clustermgr.ServiceEvent{
+		Type:   typ,
+		Name:   name,
+		Host:   host,
+		Reason: reason,
+		Time:   time.Now().Unix(),
+	})
+	if len(s.events) > s.cfg.MaxEvents {
+		s.events = s.events[len(s.events)-s.cfg.MaxEvents:]
+	}
+}
+
+// ListEvents returns the recent register/unregister events from the oldest to the newest,
+// all of the events are returned if name is empty. The events are applied from raft log and
+// kept in memory only, so they are lost after restart.
+func (s *ServiceMgr) ListEvents(name string) (ret clustermgr.ServiceEvents) {
+	s.eventLock.RLock()
+	defer s.eventLock.RUnlock()
+	ret.Events = make([]clustermgr.ServiceEvent, 0)
+	for _, event := range s.events {
+		if name == "" || event.Name == name {
+			ret.Events = append(ret.Events, event)
+		}
+	}
+	return
+}
@@ -71,14 +71,26 @@ type ServiceMgr struct {
 	cache      sync.Map
 	dirty      atomic.Value
 	taskPool   *base.TaskDistribution
+	cfg        ServiceMgrConfig
+
+	probe         probeFunc
+	probeLock     sync.Mutex
+	probeFailures map[nodeName]int
+
+	eventLock sync.RWMutex
+	events    []clustermgr.ServiceEvent
 }
 
-func NewServiceMgr(t *normaldb.ServiceTable) *ServiceMgr {
+func NewServiceMgr(t *normaldb.ServiceTable, cfg ServiceMgrConfig) *ServiceMgr {
 	_, ctx := trace.StartSpanFromContext(context.Background(), "NewServiceMgr")
+	cfg.checkAndFix()
 	mgr := &ServiceMgr{
-		moduleName: moduleName,
-		tbl:        t,
-		taskPool:   base.NewTaskDistribution(defaultApplyConcurrency, 1),
+		moduleName:    moduleName,
+		tbl:           t,
+		taskPool:      base.NewTaskDistribution(defaultApplyConcurrency, 1),
+		cfg:           cfg,
+		probe:         newHTTPProbe(cfg),
+		probeFailures: make(map[nodeName]int),
 	}
 	mgr.dirty.Store(&sync.Map{})
 	if err := mgr.LoadData(ctx); err != nil {
@@ -150,10 +162,11 @@ func (s *ServiceMgr) handleRegister(ctx context.Context, arg clustermgr.Register
 	sv.nodes[info.Host] = info
 	s.dirty.Load().(*sync.Map).Store(key, DbPut)
 	sv.Unlock()
+	s.addEvent(clustermgr.ServiceEventRegister, arg.Name, arg.Host, "")
 	return nil
 }
 
-func (s *ServiceMgr) handleUnregister(ctx context.Context, sname, host string) error {
+func (s *ServiceMgr) handleUnregister(ctx context.Context, sname, host, reason string) error {
 	key := nodeName{sname, host}
 	val, hit := s.cache.Load(sname)
 	if !hit {
@@ -161,9 +174,13 @@ func (s *ServiceMgr) handleUnregister(ctx context.Context, sname, host string) e
 	}
 	sv := val.(*service)
 	sv.Lock()
-	defer sv.Unlock()
+	_, hit = sv.nodes[host]
 	delete(sv.nodes, host)
 	s.dirty.Load().(*sync.Map).Store(key, DbDelete)
+	sv.Unlock()
+	if hit {
+		s.addEvent(clustermgr.ServiceEventUnregister, sname, host, reason)
+	}
 	return nil
 }
 
@@ -17,6 +17,7 @@ package servicemgr
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"math/rand"
 	"os"
 	"strconv"
@@ -61,7 +62,7 @@ func TestServiceMgr(t *testing.T) {
 		require.NoError(t, err)
 	}
 
-	serviceMgr := NewServiceMgr(serviceTbl)
+	serviceMgr := NewServiceMgr(serviceTbl, ServiceMgrConfig{})
 	serviceMgr.SetModuleName("")
 	serviceMgr.GetModuleName()
 	serviceMgr.NotifyLeaderChange(context.Background(), 0, "")
@@ -78,7 +79,7 @@ func TestServiceMgr_Apply(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 	serviceTbl := normaldb.OpenServiceTable(db)
-	serviceMgr := NewServiceMgr(serviceTbl)
+	serviceMgr := NewServiceMgr(serviceTbl, ServiceMgrConfig{})
 
 	serviceName := "testService"
 	hostPrefix := "testHost-"
@@ -135,3 +136,67 @@ func TestServiceMgr_Apply(t *testing.T) {
 	err = serviceMgr.Flush(ctx)
 	require.NoError(t, err)
 }
+
+func TestServiceMgrProbe(t *testing.T) {
+	tmpDBPath := "/tmp/tmpservicenormaldb" + strconv.Itoa(rand.Intn(1000000000))
+	defer os.RemoveAll(tmpDBPath)
+
+	db, err := normaldb.OpenNormalDB(tmpDBPath)
+	require.NoError(t, err)
+	defer db.Close()
+	serviceMgr := NewServiceMgr(normaldb.OpenServiceTable(db), ServiceMgrConfig{ProbeFailureThreshold: 2, MaxEvents: 4})
+
+	serviceName := "testService"
+	deadHost := "http://127.0.0.1:9501"
+	serviceMgr.probe = func(ctx context.Context, host string) error {
+		if host == deadHost {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	span, ctx := trace.StartSpanFromContext(context.Background(), "")
+	proposeCtx := []base.ProposeContext{{ReqID: span.TraceID()}}
+	for _, host := range []string{"http://127.0.0.1:9500", deadHost} {
+		data, err := json.Marshal(&clustermgr.RegisterArgs{
+			ServiceNode: clustermgr.ServiceNode{ClusterID: 1, Name: serviceName, Host: host},
+			Timeout:     30,
+		})
+		require.NoError(t, err)
+		require.NoError(t, serviceMgr.Apply(ctx, []int32{OpRegister}, [][]byte{data}, proposeCtx))
+	}
+
+	require.Empty(t, serviceMgr.Probe(ctx))
+	require.Equal(t, 1, serviceMgr.ProbeFailures(serviceName, deadHost))
+	deads := serviceMgr.Probe(ctx)
+	require.Equal(t, 1, len(deads))
+	require.Equal(t, deadHost, deads[0].Host)
+
+	// failures counted by the old leader are dropped
+	serviceMgr.NotifyLeaderChange(ctx, 1, "")
+	require.Equal(t, 0, serviceMgr.ProbeFailures(serviceName, deadHost))
+
+	data, err := json.Marshal(&clustermgr.UnregisterArgs{Name: serviceName, Host: deadHost, Reason: clustermgr.UnregisterReasonProbe})
+	require.NoError(t, err)
+	require.NoError(t, serviceMgr.Apply(ctx, []int32{OpUnregister}, [][]byte{data}, proposeCtx))
+	require.False(t, serviceMgr.IsRegistered(serviceName, deadHost))
+	require.Equal(t, 1, len(serviceMgr.GetServiceInfo(serviceName).Nodes))
+
+	events := serviceMgr.ListEvents("").Events
+	require.Equal(t, 3, len(events))
+	require.Equal(t, clustermgr.ServiceEventUnregister, events[2].Type)
+	require.Equal(t, deadHost, events[2].Host)
+	require.Equal(t, clustermgr.UnregisterReasonProbe, events[2].Reason)
+	require.Empty(t, serviceMgr.ListEvents("otherService").Events)
+
+	// unregister of absent node has no event, and only the newest events are kept
+	require.NoError(t, serviceMgr.Apply(ctx, []int32{OpUnregister}, [][]byte{data}, proposeCtx))
+	require.Equal(t, 3, len(serviceMgr.ListEvents(serviceName).Events))
+	for i := 0; i < 2; i++ {
+		serviceMgr.addEvent(clustermgr.ServiceEventRegister, serviceName, deadHost, "")
+	}
+	events = serviceMgr.ListEvents(serviceName).Events
+	require.Equal(t, 4, len(events))
+	require.Equal(t, clustermgr.ServiceEventRegister, events[0].Type)
+	require.Equal(t, clustermgr.ServiceEventUnregister, events[1].Type)
+}
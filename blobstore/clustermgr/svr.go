@@ -41,6 +41,7 @@ import (
 	"github.com/cubefs/cubefs/blobstore/clustermgr/persistence/volumedb"
 	"github.com/cubefs/cubefs/blobstore/clustermgr/scopemgr"
 	"github.com/cubefs/cubefs/blobstore/clustermgr/servicemgr"
+	"github.com/cubefs/cubefs/blobstore/clustermgr/usagemgr"
 	"github.com/cubefs/cubefs/blobstore/clustermgr/volumemgr"
 	"github.com/cubefs/cubefs/blobstore/cmd"
 	"github.com/cubefs/cubefs/blobstore/common/codemode"
@@ -71,6 +72,7 @@ const (
 	BidScopeName             = "bid"
 	MaxBidCount              = 100000
 	DefaultChunkSize         = 17179869184
+	MaxChunkSize             = 1 << 40 // 1 TiB, the max chunk size of blob node
 	DefaultVolumeReserveSize = 10485760
 )
 
@@ -93,33 +95,42 @@ var (
 )
 
 type Config struct {
-	Region                   string                    `json:"region"`
-	IDC                      []string                  `json:"idc"`
-	UnavailableIDC           string                    `json:"unavailable_idc"`
-	ClusterID                proto.ClusterID           `json:"cluster_id"`
-	Readonly                 bool                      `json:"readonly"`
-	VolumeMgrConfig          volumemgr.VolumeMgrConfig `json:"volume_mgr_config"`
-	DBPath                   string                    `json:"db_path"`
-	DBCacheSize              uint64                    `json:"db_cache_size"`
-	NormalDBPath             string                    `json:"normal_db_path"`
-	KvDBPath                 string                    `json:"kv_db_path"`
-	CodeModePolicies         []codemode.Policy         `json:"code_mode_policies"`
-	ClusterCfg               map[string]interface{}    `json:"cluster_config"`
-	RaftConfig               RaftConfig                `json:"raft_config"`
-	DiskMgrConfig            diskmgr.DiskMgrConfig     `json:"disk_mgr_config"`
-	ClusterReportIntervalS   int                       `json:"cluster_report_interval_s"`
-	ConsulAgentAddr          string                    `json:"consul_agent_addr"`
-	ConsulToken              string                    `json:"consul_token"`
-	ConsulTokenFile          string                    `json:"consul_token_file"`
-	HeartbeatNotifyIntervalS int                       `json:"heartbeat_notify_interval_s"`
-	MaxHeartbeatNotifyNum    int                       `json:"max_heartbeat_notify_num"`
-	ChunkSize                uint64                    `json:"chunk_size"`
-	MetricReportIntervalM    int                       `json:"metric_report_interval_m"`
-	ConsistentCheckIntervalM int                       `json:"consistent_check_interval_m"`
+	Region                   string                      `json:"region"`
+	IDC                      []string                    `json:"idc"`
+	UnavailableIDC           string                      `json:"unavailable_idc"`
+	ClusterID                proto.ClusterID             `json:"cluster_id"`
+	Readonly                 bool                        `json:"readonly"`
+	VolumeMgrConfig          volumemgr.VolumeMgrConfig   `json:"volume_mgr_config"`
+	DBPath                   string                      `json:"db_path"`
+	DBCacheSize              uint64                      `json:"db_cache_size"`
+	NormalDBPath             string                      `json:"normal_db_path"`
+	KvDBPath                 string                      `json:"kv_db_path"`
+	CodeModePolicies         []codemode.Policy           `json:"code_mode_policies"`
+	ClusterCfg               map[string]interface{}      `json:"cluster_config"`
+	RaftConfig               RaftConfig                  `json:"raft_config"`
+	DiskMgrConfig            diskmgr.DiskMgrConfig       `json:"disk_mgr_config"`
+	ServiceMgrConfig         servicemgr.ServiceMgrConfig `json:"service_mgr_config"`
+	ClusterReportIntervalS   int                         `json:"cluster_report_interval_s"`
+	ConsulAgentAddr          string                      `json:"consul_agent_addr"`
+	ConsulToken              string                      `json:"consul_token"`
+	ConsulTokenFile          string                      `json:"consul_token_file"`
+	HeartbeatNotifyIntervalS int                         `json:"heartbeat_notify_interval_s"`
+	MaxHeartbeatNotifyNum    int                         `json:"max_heartbeat_notify_num"`
+	ChunkSize                uint64                      `json:"chunk_size"`
+	DiskTypeChunkSizes       []DiskTypeChunkSize         `json:"disk_type_chunk_sizes"`
+	MetricReportIntervalM    int                         `json:"metric_report_interval_m"`
+	ConsistentCheckIntervalM int                         `json:"consistent_check_interval_m"`
 
 	cmd.Config
 }
 
+// DiskTypeChunkSize is the size of the chunks created on the disks of the disk type, such as
+// the larger chunks for the large disks. The chunks and volumes already created keep their size.
+type DiskTypeChunkSize struct {
+	DiskType  proto.DiskType `json:"disk_type"`
+	ChunkSize uint64         `json:"chunk_size"`
+}
+
 type RaftConfig struct {
 	RaftDBPath       string              `json:"raft_db_path"`
 	SnapshotPatchNum int                 `json:"snapshot_patch_num"`
@@ -136,6 +147,7 @@ type Service struct {
 	DiskMgr   *diskmgr.DiskMgr
 	VolumeMgr *volumemgr.VolumeMgr
 	KvMgr     *kvmgr.KvMgr
+	UsageMgr  *usagemgr.UsageMgr
 
 	dbs map[string]base.SnapshotDB
 	// status indicate service's current state, like normal/snapshot
@@ -200,6 +212,9 @@ func New(cfg *Config) (*Service, error) {
 	if err != nil {
 		log.Fatalf("open raft database failed, err: %v", err)
 	}
+	if err = checkECFingerprints(raftDB, cfg.DiskMgrConfig.CodeModes); err != nil {
+		log.Fatalf("check ec fingerprints failed, err: %v", errors.Detail(err))
+	}
 	kvDB, err := kvdb.Open(cfg.KvDBPath, kvstore.WithCatchSize(cfg.DBCacheSize))
 	if err != nil {
 		log.Fatal("open kv database failed,err:%v", err)
@@ -249,7 +264,12 @@ func New(cfg *Config) (*Service, error) {
 		log.Fatalf("new configMg failed, error: %v", err)
 	}
 
-	serviceMgr := servicemgr.NewServiceMgr(normaldb.OpenServiceTable(normalDB))
+	usageMgr, err := usagemgr.NewUsageMgr(normalDB)
+	if err != nil {
+		log.Fatalf("new usageMgr failed, err: %v", err)
+	}
+
+	serviceMgr := servicemgr.NewServiceMgr(normaldb.OpenServiceTable(normalDB), cfg.ServiceMgrConfig)
 
 	volumeMgr, err := volumemgr.NewVolumeMgr(cfg.VolumeMgrConfig, diskMgr, scopeMgr, configMgr, volumeDB)
 	if err != nil {
@@ -262,6 +282,7 @@ func New(cfg *Config) (*Service, error) {
 	service.DiskMgr = diskMgr
 	service.ServiceMgr = serviceMgr
 	service.ScopeMgr = scopeMgr
+	service.UsageMgr = usageMgr
 
 	// raft server initial
 	applyIndex := uint64(0)
@@ -307,6 +328,7 @@ func New(cfg *Config) (*Service, error) {
 	scopeMgr.SetRaftServer(raftServer)
 	volumeMgr.SetRaftServer(raftServer)
 	configMgr.SetRaftServer(raftServer)
+	usageMgr.SetRaftServer(raftServer)
 
 	// wait for raft start
 	service.waitForRaftStart()
@@ -402,9 +424,27 @@ func (c *Config) checkAndFix() (err error) {
 	if c.ClusterCfg[proto.VolumeReserveSizeKey] == nil {
 		c.ClusterCfg[proto.VolumeReserveSizeKey] = DefaultVolumeReserveSize
 	}
-	c.VolumeMgrConfig.ChunkSize = c.ChunkSize
+	if c.ChunkSize > MaxChunkSize {
+		return errors.New("chunk size is larger than max chunk size")
+	}
 	c.DiskMgrConfig.ChunkSize = int64(c.ChunkSize)
-	c.ClusterCfg[proto.VolumeChunkSizeKey] = c.ChunkSize
+	c.DiskMgrConfig.ChunkSizes = make(map[proto.DiskType]int64, len(c.DiskTypeChunkSizes))
+	for _, size := range c.DiskTypeChunkSizes {
+		if !size.DiskType.IsValid() || size.ChunkSize == 0 || size.ChunkSize > MaxChunkSize {
+			return fmt.Errorf("invalid chunk size %d of disk type %s", size.ChunkSize, size.DiskType)
+		}
+		if _, ok := c.DiskMgrConfig.ChunkSizes[size.DiskType]; ok {
+			return fmt.Errorf("chunk size of disk type %s repeat", size.DiskType)
+		}
+		c.DiskMgrConfig.ChunkSizes[size.DiskType] = int64(size.ChunkSize)
+	}
+	// volumes are created on the hdd disks
+	volumeChunkSize := c.ChunkSize
+	if size, ok := c.DiskMgrConfig.ChunkSizes[proto.DiskTypeHDD]; ok {
+		volumeChunkSize = uint64(size)
+	}
+	c.VolumeMgrConfig.ChunkSize = volumeChunkSize
+	c.ClusterCfg[proto.VolumeChunkSizeKey] = volumeChunkSize
 	c.ClusterCfg[proto.CodeModeConfigKey] = c.CodeModePolicies
 
 	if len(c.CodeModePolicies) == 0 {
@@ -571,6 +611,9 @@ func (s *Service) loop() {
 	checkTicker := time.NewTicker(time.Duration(s.ConsistentCheckIntervalM) * time.Minute)
 	defer checkTicker.Stop()
 
+	serviceProbeTicker := time.NewTicker(s.ServiceMgr.ProbeInterval())
+	defer serviceProbeTicker.Stop()
+
 	for {
 		select {
 		case <-reportTicker.C:
@@ -675,6 +718,12 @@ func (s *Service) loop() {
 				}
 			}()
 
+		case <-serviceProbeTicker.C:
+			if !s.ServiceMgr.ProbeEnabled() || !s.raftNode.IsLeader() {
+				continue
+			}
+			s.probeServices(ctx)
+
 		case <-s.closeCh:
 			return
 		}
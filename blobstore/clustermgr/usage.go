@@ -0,0 +1,108 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	apierrors "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+func (s *Service) UsageReport(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.ReportUsageArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept UsageReport request, deltas: %d", len(args.Deltas))
+
+	if len(args.Deltas) == 0 || len(args.Deltas) > clustermgr.MaxUsageReportCount {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+	for _, delta := range args.Deltas {
+		if !clustermgr.IsValidTenant(delta.Tenant) || !delta.CodeMode.IsValid() ||
+			delta.PutBytes < 0 || delta.PutBlobs < 0 || delta.DeleteBytes < 0 || delta.DeleteBlobs < 0 {
+			span.Warnf("invalid usage delta: %+v", delta)
+			c.RespondError(apierrors.ErrIllegalArguments)
+			return
+		}
+	}
+
+	if err := s.UsageMgr.Report(ctx, args.Deltas, time.Now()); err != nil {
+		span.Errorf("report usage failed, err: %v", errors.Detail(err))
+		c.RespondError(apierrors.ErrRaftPropose)
+	}
+}
+
+func (s *Service) UsageList(c *rpc.Context) {
+	usages, err := s.listUsage(c)
+	if err != nil {
+		c.RespondError(err)
+		return
+	}
+	c.RespondJSON(&clustermgr.ListUsageRet{Usages: usages})
+}
+
+// UsageExport exports the usage listed in csv for billing
+func (s *Service) UsageExport(c *rpc.Context) {
+	usages, err := s.listUsage(c)
+	if err != nil {
+		c.RespondError(err)
+		return
+	}
+	buf := new(bytes.Buffer)
+	if err = clustermgr.WriteUsageCSV(buf, usages); err != nil {
+		c.RespondError(err)
+		return
+	}
+	c.RespondWith(http.StatusOK, "text/csv", buf.Bytes())
+}
+
+func (s *Service) listUsage(c *rpc.Context) ([]clustermgr.Usage, error) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.ListUsageArgs)
+	if err := c.ParseArgs(args); err != nil {
+		return nil, err
+	}
+	span.Debugf("accept list usage request, args: %+v", args)
+
+	if args.CodeMode != 0 && !args.CodeMode.IsValid() {
+		return nil, apierrors.ErrIllegalArguments
+	}
+	if _, _, err := args.ParseUsageDates(); err != nil {
+		span.Warnf("invalid usage dates: %v", err)
+		return nil, apierrors.ErrIllegalArguments
+	}
+	if err := s.raftNode.ReadIndex(ctx); err != nil {
+		span.Errorf("list usage read index error: %v", err)
+		return nil, apierrors.ErrRaftReadIndex
+	}
+	usages, err := s.UsageMgr.List(ctx, args)
+	if err != nil {
+		span.Errorf("list usage failed, err: %v", errors.Detail(err))
+		return nil, apierrors.ErrCMUnexpect
+	}
+	return usages, nil
+}
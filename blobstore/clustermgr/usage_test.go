@@ -0,0 +1,72 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+)
+
+func TestUsage(t *testing.T) {
+	testService, clean := initTestService(t)
+	defer clean()
+	testClusterClient := initTestClusterClient(testService)
+	ctx := newCtx()
+
+	{
+		err := testClusterClient.ReportUsage(ctx, &clustermgr.ReportUsageArgs{Deltas: []clustermgr.UsageDelta{
+			{Tenant: "tenant1", CodeMode: codemode.EC6P6, PutBytes: 6000, PutBlobs: 2},
+			{Tenant: "tenant1", CodeMode: codemode.EC6P6, DeleteBytes: 600, DeleteBlobs: 1},
+			{Tenant: "tenant2", CodeMode: codemode.EC15P12, PutBytes: 1500, PutBlobs: 1},
+		}})
+		require.NoError(t, err)
+
+		err = testClusterClient.ReportUsage(ctx, &clustermgr.ReportUsageArgs{})
+		require.Error(t, err)
+		err = testClusterClient.ReportUsage(ctx, &clustermgr.ReportUsageArgs{Deltas: []clustermgr.UsageDelta{
+			{Tenant: "tenant/1", CodeMode: codemode.EC6P6, PutBytes: 1},
+		}})
+		require.Error(t, err)
+		err = testClusterClient.ReportUsage(ctx, &clustermgr.ReportUsageArgs{Deltas: []clustermgr.UsageDelta{
+			{Tenant: "tenant1", CodeMode: codemode.EC6P6, PutBytes: -1},
+		}})
+		require.Error(t, err)
+	}
+	{
+		ret, err := testClusterClient.ListUsage(ctx, &clustermgr.ListUsageArgs{})
+		require.NoError(t, err)
+		require.Equal(t, []clustermgr.Usage{
+			{Tenant: "tenant1", CodeMode: codemode.EC6P6, LogicalBytes: 5400, PhysicalBytes: 10800, Blobs: 1, PutBytes: 6000, DeleteBytes: 600},
+			{Tenant: "tenant2", CodeMode: codemode.EC15P12, LogicalBytes: 1500, PhysicalBytes: 2700, Blobs: 1, PutBytes: 1500},
+		}, ret.Usages)
+
+		today := time.Now().UTC().Format(clustermgr.UsageDateLayout)
+		ret, err = testClusterClient.ListUsage(ctx, &clustermgr.ListUsageArgs{Tenant: "tenant2", StartDate: today, EndDate: today})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(ret.Usages))
+		require.Equal(t, today, ret.Usages[0].Date)
+		require.Equal(t, int64(2700), ret.Usages[0].PhysicalBytes)
+
+		_, err = testClusterClient.ListUsage(ctx, &clustermgr.ListUsageArgs{StartDate: today})
+		require.Error(t, err)
+		_, err = testClusterClient.ListUsage(ctx, &clustermgr.ListUsageArgs{StartDate: "2023-01-01", EndDate: "2024-12-31"})
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package usagemgr
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/clustermgr/base"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+const (
+	OperTypeUpdateUsage = iota + 1
+)
+
+const (
+	module = "usageMgr"
+)
+
+// updateUsageCtx carries the new current and daily usage
+type updateUsageCtx struct {
+	Usages []clustermgr.Usage `json:"usages"`
+}
+
+func (u *UsageMgr) LoadData(ctx context.Context) error {
+	current := make(map[string]*clustermgr.Usage)
+	err := u.tbl.RangePrefix(currentKeyPrefix, "", func(key string, val []byte) bool {
+		usage := &clustermgr.Usage{}
+		if err := json.Unmarshal(val, usage); err != nil {
+			trace.SpanFromContextSafe(ctx).Panicf("invalid usage of %s: %v", key, err)
+		}
+		current[key] = usage
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	u.lock.Lock()
+	u.current = current
+	u.lock.Unlock()
+	return nil
+}
+
+func (u *UsageMgr) GetModuleName() string {
+	return module
+}
+
+func (u *UsageMgr) SetModuleName(module string) {
+	// nothing to do
+}
+
+func (u *UsageMgr) Apply(ctx context.Context, operTypes []int32, datas [][]byte, contexts []base.ProposeContext) error {
+	span := trace.SpanFromContextSafe(ctx)
+	for i := range operTypes {
+		_, taskCtx := trace.StartSpanFromContextWithTraceID(ctx, "", contexts[i].ReqID)
+		switch operTypes[i] {
+		case OperTypeUpdateUsage:
+			args := &updateUsageCtx{}
+			err := json.Unmarshal(datas[i], args)
+			if err != nil {
+				span.Errorf("json unmarshal failed, err: %v, operation type: %d, data: %v", err, operTypes[i], datas[i])
+				return errors.Info(err, "json unmarshal failed").Detail(err)
+			}
+			if err = u.applyUpdate(taskCtx, args); err != nil {
+				return errors.Info(err, "apply update usage failed").Detail(err)
+			}
+		default:
+		}
+	}
+	return nil
+}
+
+// nothing to do
+func (u *UsageMgr) Flush(ctx context.Context) error {
+	return nil
+}
+
+// nothing to do
+func (u *UsageMgr) NotifyLeaderChange(ctx context.Context, leader uint64, host string) {
+	// Do nothing.
+}
@@ -0,0 +1,261 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package usagemgr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/clustermgr/base"
+	"github.com/cubefs/cubefs/blobstore/clustermgr/persistence/normaldb"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/kvstore"
+	"github.com/cubefs/cubefs/blobstore/common/raftserver"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+)
+
+const (
+	currentKeyPrefix = "c/"
+	dailyKeyPrefix   = "d/"
+)
+
+type UsageMgrAPI interface {
+	Report(ctx context.Context, deltas []clustermgr.UsageDelta, now time.Time) error
+	List(ctx context.Context, args *clustermgr.ListUsageArgs) ([]clustermgr.Usage, error)
+}
+
+// UsageMgr accounts the usage of tenants in code modes. The current usage of every tenant and
+// code mode is kept in memory, and the daily usage records the usage at the end of the day and
+// the bytes put and deleted in the day. The reports are applied by the leader to the usage and
+// proposed as the new usage, so that the apply is idempotent.
+type UsageMgr struct {
+	current    map[string]*clustermgr.Usage
+	raftServer raftserver.RaftServer
+
+	tbl        *normaldb.UsageTable
+	lock       sync.RWMutex
+	reportLock sync.Mutex
+}
+
+func NewUsageMgr(db *normaldb.NormalDB) (*UsageMgr, error) {
+	_, ctx := trace.StartSpanFromContext(context.Background(), "NewUsageMgr")
+	u := &UsageMgr{tbl: normaldb.OpenUsageTable(db)}
+	if err := u.LoadData(ctx); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (u *UsageMgr) SetRaftServer(raftServer raftserver.RaftServer) {
+	u.raftServer = raftServer
+}
+
+func usageKey(tenant string, mode codemode.CodeMode) string {
+	return fmt.Sprintf("%s/%03d/", tenant, mode)
+}
+
+func currentKey(tenant string, mode codemode.CodeMode) string {
+	return currentKeyPrefix + usageKey(tenant, mode)
+}
+
+func dailyKey(tenant string, mode codemode.CodeMode, date string) string {
+	return dailyKeyPrefix + usageKey(tenant, mode) + date
+}
+
+func (u *UsageMgr) getCurrent(tenant string, mode codemode.CodeMode) clustermgr.Usage {
+	u.lock.RLock()
+	defer u.lock.RUnlock()
+	if usage, ok := u.current[currentKey(tenant, mode)]; ok {
+		return *usage
+	}
+	return clustermgr.Usage{Tenant: tenant, CodeMode: mode}
+}
+
+func (u *UsageMgr) getDaily(tenant string, mode codemode.CodeMode, date string) (usage clustermgr.Usage, err error) {
+	data, err := u.tbl.Get(dailyKey(tenant, mode, date))
+	if err == kvstore.ErrNotFound {
+		return clustermgr.Usage{Tenant: tenant, CodeMode: mode, Date: date}, nil
+	}
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data, &usage)
+	return
+}
+
+// Report adds the deltas to the current usage and the daily usage of the date of now.
+func (u *UsageMgr) Report(ctx context.Context, deltas []clustermgr.UsageDelta, now time.Time) error {
+	span := trace.SpanFromContextSafe(ctx)
+	date := now.UTC().Format(clustermgr.UsageDateLayout)
+
+	u.reportLock.Lock()
+	defer u.reportLock.Unlock()
+
+	merged := make(map[string]*clustermgr.UsageDelta)
+	var keys []string
+	for i := range deltas {
+		key := usageKey(deltas[i].Tenant, deltas[i].CodeMode)
+		delta, ok := merged[key]
+		if !ok {
+			delta = &clustermgr.UsageDelta{Tenant: deltas[i].Tenant, CodeMode: deltas[i].CodeMode}
+			merged[key] = delta
+			keys = append(keys, key)
+		}
+		delta.PutBytes += deltas[i].PutBytes
+		delta.PutBlobs += deltas[i].PutBlobs
+		delta.DeleteBytes += deltas[i].DeleteBytes
+		delta.DeleteBlobs += deltas[i].DeleteBlobs
+	}
+
+	args := &updateUsageCtx{Usages: make([]clustermgr.Usage, 0, 2*len(keys))}
+	for _, key := range keys {
+		delta := merged[key]
+		current := u.getCurrent(delta.Tenant, delta.CodeMode)
+		current.LogicalBytes += delta.PutBytes - delta.DeleteBytes
+		current.PhysicalBytes = clustermgr.PhysicalUsage(delta.CodeMode, current.LogicalBytes)
+		current.Blobs += delta.PutBlobs - delta.DeleteBlobs
+		current.PutBytes += delta.PutBytes
+		current.DeleteBytes += delta.DeleteBytes
+
+		daily, err := u.getDaily(delta.Tenant, delta.CodeMode, date)
+		if err != nil {
+			return err
+		}
+		daily.LogicalBytes = current.LogicalBytes
+		daily.PhysicalBytes = current.PhysicalBytes
+		daily.Blobs = current.Blobs
+		daily.PutBytes += delta.PutBytes
+		daily.DeleteBytes += delta.DeleteBytes
+		args.Usages = append(args.Usages, current, daily)
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	return u.raftServer.Propose(ctx, base.EncodeProposeInfo(u.GetModuleName(), OperTypeUpdateUsage, data, base.ProposeContext{ReqID: span.TraceID()}))
+}
+
+func (u *UsageMgr) matchedCurrent(tenant string, mode codemode.CodeMode) []clustermgr.Usage {
+	u.lock.RLock()
+	defer u.lock.RUnlock()
+	usages := make([]clustermgr.Usage, 0, len(u.current))
+	for _, usage := range u.current {
+		if (tenant == "" || usage.Tenant == tenant) && (mode == 0 || usage.CodeMode == mode) {
+			usages = append(usages, *usage)
+		}
+	}
+	return usages
+}
+
+// List lists the current usage, or the daily usage of every day in the dates. The days without
+// any report have the usage of the last reported day and no bytes put and deleted.
+func (u *UsageMgr) List(ctx context.Context, args *clustermgr.ListUsageArgs) ([]clustermgr.Usage, error) {
+	start, end, err := args.ParseUsageDates()
+	if err != nil {
+		return nil, err
+	}
+	current := u.matchedCurrent(args.Tenant, args.CodeMode)
+	if start.IsZero() {
+		sort.Slice(current, func(i, j int) bool {
+			if current[i].Tenant != current[j].Tenant {
+				return current[i].Tenant < current[j].Tenant
+			}
+			return current[i].CodeMode < current[j].CodeMode
+		})
+		return current, nil
+	}
+
+	if today := time.Now().UTC().Truncate(24 * time.Hour); end.After(today) {
+		end = today
+	}
+	endDate := end.Format(clustermgr.UsageDateLayout)
+	var usages []clustermgr.Usage
+	for _, cur := range current {
+		var daily []clustermgr.Usage
+		prefix := dailyKeyPrefix + usageKey(cur.Tenant, cur.CodeMode)
+		err = u.tbl.RangePrefix(prefix, "", func(key string, val []byte) bool {
+			var usage clustermgr.Usage
+			if err = json.Unmarshal(val, &usage); err != nil {
+				return false
+			}
+			if usage.Date > endDate {
+				return false
+			}
+			daily = append(daily, usage)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var last *clustermgr.Usage
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			date := day.Format(clustermgr.UsageDateLayout)
+			for len(daily) > 0 && daily[0].Date <= date {
+				last = &daily[0]
+				daily = daily[1:]
+			}
+			if last == nil {
+				continue
+			}
+			usage := *last
+			if usage.Date != date {
+				usage.Date = date
+				usage.PutBytes = 0
+				usage.DeleteBytes = 0
+			}
+			usages = append(usages, usage)
+		}
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Date != usages[j].Date {
+			return usages[i].Date < usages[j].Date
+		}
+		if usages[i].Tenant != usages[j].Tenant {
+			return usages[i].Tenant < usages[j].Tenant
+		}
+		return usages[i].CodeMode < usages[j].CodeMode
+	})
+	return usages, nil
+}
+
+func (u *UsageMgr) applyUpdate(ctx context.Context, args *updateUsageCtx) error {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	for i := range args.Usages {
+		usage := args.Usages[i]
+		key := currentKey(usage.Tenant, usage.CodeMode)
+		if usage.Date != "" {
+			key = dailyKey(usage.Tenant, usage.CodeMode, usage.Date)
+		}
+		data, err := json.Marshal(usage)
+		if err != nil {
+			return err
+		}
+		if err = u.tbl.Put(key, data); err != nil {
+			return err
+		}
+		if usage.Date == "" {
+			u.current[key] = &usage
+		}
+	}
+	return nil
+}
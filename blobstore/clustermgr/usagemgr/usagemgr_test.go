@@ -0,0 +1,100 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package usagemgr
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/clustermgr/base"
+	"github.com/cubefs/cubefs/blobstore/clustermgr/persistence/normaldb"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
+)
+
+func TestUsageMgr(t *testing.T) {
+	tmpDBPath := "/tmp/tmpnormaldb" + strconv.Itoa(rand.Intn(10000000000))
+	defer os.RemoveAll(tmpDBPath)
+
+	db, err := normaldb.OpenNormalDB(tmpDBPath)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, ctx := trace.StartSpanFromContext(context.Background(), "")
+	usageMgr, err := NewUsageMgr(db)
+	require.NoError(t, err)
+
+	// apply the proposal at once like raft
+	mockRaftServer := mocks.NewMockRaftServer(ctrl)
+	mockRaftServer.EXPECT().Propose(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(ctx context.Context, data []byte) error {
+		proposeInfo := base.DecodeProposeInfo(data)
+		return usageMgr.Apply(ctx, []int32{proposeInfo.OperType}, [][]byte{proposeInfo.Data}, []base.ProposeContext{proposeInfo.Context})
+	})
+	usageMgr.SetRaftServer(mockRaftServer)
+
+	day1 := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	day3 := day1.AddDate(0, 0, 2)
+	require.NoError(t, usageMgr.Report(ctx, []clustermgr.UsageDelta{
+		{Tenant: "t1", CodeMode: codemode.EC6P6, PutBytes: 600, PutBlobs: 1},
+		{Tenant: "t1", CodeMode: codemode.EC6P6, PutBytes: 600, PutBlobs: 1},
+		{Tenant: "t2", CodeMode: codemode.EC12P4, PutBytes: 1200, PutBlobs: 1},
+	}, day1))
+	require.NoError(t, usageMgr.Report(ctx, []clustermgr.UsageDelta{
+		{Tenant: "t1", CodeMode: codemode.EC6P6, DeleteBytes: 600, DeleteBlobs: 1},
+	}, day1))
+	require.NoError(t, usageMgr.Report(ctx, []clustermgr.UsageDelta{
+		{Tenant: "t1", CodeMode: codemode.EC6P6, PutBytes: 1800, PutBlobs: 1},
+	}, day3))
+
+	current, err := usageMgr.List(ctx, &clustermgr.ListUsageArgs{})
+	require.NoError(t, err)
+	require.Equal(t, []clustermgr.Usage{
+		{Tenant: "t1", CodeMode: codemode.EC6P6, LogicalBytes: 2400, PhysicalBytes: 4800, Blobs: 2, PutBytes: 3000, DeleteBytes: 600},
+		{Tenant: "t2", CodeMode: codemode.EC12P4, LogicalBytes: 1200, PhysicalBytes: 1600, Blobs: 1, PutBytes: 1200},
+	}, current)
+
+	daily, err := usageMgr.List(ctx, &clustermgr.ListUsageArgs{Tenant: "t1", StartDate: "2023-04-30", EndDate: "2023-05-03"})
+	require.NoError(t, err)
+	require.Equal(t, []clustermgr.Usage{
+		{Tenant: "t1", CodeMode: codemode.EC6P6, Date: "2023-05-01", LogicalBytes: 600, PhysicalBytes: 1200, Blobs: 1, PutBytes: 1200, DeleteBytes: 600},
+		{Tenant: "t1", CodeMode: codemode.EC6P6, Date: "2023-05-02", LogicalBytes: 600, PhysicalBytes: 1200, Blobs: 1},
+		{Tenant: "t1", CodeMode: codemode.EC6P6, Date: "2023-05-03", LogicalBytes: 2400, PhysicalBytes: 4800, Blobs: 2, PutBytes: 1800},
+	}, daily)
+
+	daily, err = usageMgr.List(ctx, &clustermgr.ListUsageArgs{CodeMode: codemode.EC12P4, StartDate: "2023-05-02", EndDate: "2023-05-02"})
+	require.NoError(t, err)
+	require.Equal(t, []clustermgr.Usage{
+		{Tenant: "t2", CodeMode: codemode.EC12P4, Date: "2023-05-02", LogicalBytes: 1200, PhysicalBytes: 1600, Blobs: 1},
+	}, daily)
+
+	// reload from the table
+	usageMgr2, err := NewUsageMgr(db)
+	require.NoError(t, err)
+	current2, err := usageMgr2.List(ctx, &clustermgr.ListUsageArgs{})
+	require.NoError(t, err)
+	require.Equal(t, current, current2)
+}
@@ -300,6 +300,15 @@ func (s *Service) VolumeUnitList(c *rpc.Context) {
 	c.RespondJSON(&clustermgr.ListVolumeUnitInfos{VolumeUnitInfos: vuInfos})
 }
 
+// VolumeUnitReconcileReport returns the report of the unit reconciliation, which runs on the leader
+func (s *Service) VolumeUnitReconcileReport(c *rpc.Context) {
+	if !s.raftNode.IsLeader() {
+		s.forwardToLeader(c.Writer, c.Request)
+		return
+	}
+	c.RespondJSON(s.VolumeMgr.GetUnitReconcileReport())
+}
+
 // direct use blobnode client release chunk
 func (s *Service) VolumeUnitRelease(c *rpc.Context) {
 	ctx := c.Request.Context()
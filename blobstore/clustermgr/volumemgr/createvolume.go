@@ -239,11 +239,17 @@ func (v *VolumeMgr) allocChunkForAllUnits(ctx context.Context, vol *CreateVolume
 	span.Debugf("start alloc chunk for all units,volume is %d", vol.Vid)
 
 	idcCnt := vol.VolInfo.CodeMode.Tactic().AZCount
+	degradedIDC := v.getWriteDegradedIDC(ctx)
 	availableIDC := make([]string, 0)
 	for i := range v.IDC {
 		if v.IDC[i] == v.UnavailableIDC {
 			continue
 		}
+		// volume units should not be allocated in write degraded idc
+		if degradedIDC[v.IDC[i]] {
+			span.Warnf("idc %s is write degraded", v.IDC[i])
+			continue
+		}
 		availableIDC = append(availableIDC, v.IDC[i])
 	}
 	if len(availableIDC) != idcCnt {
@@ -260,6 +266,7 @@ func (v *VolumeMgr) allocChunkForAllUnits(ctx context.Context, vol *CreateVolume
 		DiskType:   proto.DiskTypeHDD,
 		CodeMode:   vol.VolInfo.CodeMode,
 		Vuids:      vuids,
+		ChunkSize:  int64(vol.VuInfos[0].Total),
 		RetryTimes: IncreaseEpochInterval,
 	}
 
@@ -279,3 +286,16 @@ func (v *VolumeMgr) allocChunkForAllUnits(ctx context.Context, vol *CreateVolume
 	}
 	return
 }
+
+// getWriteDegradedIDC returns idcs which are write degraded by config
+func (v *VolumeMgr) getWriteDegradedIDC(ctx context.Context) map[string]bool {
+	ret := make(map[string]bool)
+	value, err := v.configMgr.Get(ctx, proto.WriteDegradedIDCConfigKey)
+	if err != nil {
+		return ret
+	}
+	for _, idc := range proto.ParseIDCs(value) {
+		ret[idc] = true
+	}
+	return ret
+}
@@ -78,6 +78,21 @@ func TestVolumeMgr_CreateVolume(t *testing.T) {
 		require.Error(t, err)
 	}
 
+	// failed case, idc write degraded
+	{
+		configMgr := mockVolumeMgr.configMgr
+		mockConfigMgr := mock.NewMockConfigMgrAPI(ctr)
+		mockConfigMgr.EXPECT().Get(gomock.Any(), proto.WriteDegradedIDCConfigKey).AnyTimes().Return("z1", nil)
+		mockVolumeMgr.configMgr = mockConfigMgr
+		require.True(t, mockVolumeMgr.getWriteDegradedIDC(ctx)["z1"])
+
+		mockScopeMgr.EXPECT().Alloc(gomock.Any(), gomock.Any(), gomock.Any()).Return(uint64(42), uint64(42), nil)
+		mockRaftServer.EXPECT().Propose(gomock.Any(), gomock.Any()).MaxTimes(1).Return(nil)
+		err := mockVolumeMgr.createVolume(ctx, 1)
+		require.Error(t, err)
+		mockVolumeMgr.configMgr = configMgr
+	}
+
 	vols := generateVolume(codemode.EC15P12, 1, 31)
 	// failed case apply create volume
 	{
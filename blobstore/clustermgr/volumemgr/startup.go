@@ -56,6 +56,10 @@ type VolumeMgrConfig struct {
 	AllocatableSize uint64 `json:"allocatable_size"`
 	// the number of volume partitions that can be allocated
 	ShardNum int `json:"shard_num"`
+	// interval of comparing the volume units with the chunks hosted by the blobnodes, disabled if 0
+	UnitReconcileIntervalS int `json:"unit_reconcile_interval_s"`
+	// release the orphan chunks left by the migrations and relocate the units to the disks hosting them
+	UnitReconcileAutoCorrect bool `json:"unit_reconcile_auto_correct"`
 
 	// the volume in Proxy which free size small than FreezeThreshold treat filled
 	FreezeThreshold  uint64            `json:"-"`
@@ -191,6 +195,7 @@ func (v *VolumeMgr) SetRaftServer(raftServer raftserver.RaftServer) {
 func (v *VolumeMgr) Start() {
 	go v.taskLoop()
 	go v.loop()
+	go v.unitReconcileLoop()
 }
 
 func (v *VolumeMgr) loadVolume(ctx context.Context) error {
@@ -0,0 +1,286 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumemgr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/api/blobnode"
+	cm "github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/clustermgr/base"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+)
+
+const defaultUnitReconcileListDiskCount = 200
+
+// unitView is the volume unit recorded
+type unitView struct {
+	vuid       proto.Vuid
+	nextEpoch  uint32
+	diskID     proto.DiskID
+	compacting bool
+}
+
+// unitReconciler compares the volume units with the chunks hosted by the blobnodes in the
+// background. A divergence seen in a round may race with the migration of the unit, so only
+// the ones seen in two consecutive rounds are reported and corrected.
+type unitReconciler struct {
+	lock     sync.RWMutex
+	report   *cm.UnitReconcileReport
+	suspects map[string]struct{} // the divergences of the last round
+}
+
+func newUnitReconcileReport() *cm.UnitReconcileReport {
+	return &cm.UnitReconcileReport{
+		DiskErrors: make(map[proto.DiskID]string),
+		Orphans:    make([]cm.UnitDivergence, 0),
+		Missing:    make([]cm.UnitDivergence, 0),
+	}
+}
+
+// GetUnitReconcileReport returns the report of the last round of the unit reconciliation.
+func (v *VolumeMgr) GetUnitReconcileReport() *cm.UnitReconcileReport {
+	v.reconciler.lock.RLock()
+	defer v.reconciler.lock.RUnlock()
+	if v.reconciler.report == nil {
+		return newUnitReconcileReport()
+	}
+	return v.reconciler.report
+}
+
+// only leader node reconciles the volume units
+func (v *VolumeMgr) unitReconcileLoop() {
+	if v.UnitReconcileIntervalS <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(v.UnitReconcileIntervalS) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !v.raftServer.IsLeader() {
+				v.reconciler.lock.Lock()
+				v.reconciler.suspects = nil
+				v.reconciler.lock.Unlock()
+				continue
+			}
+			_, ctx := trace.StartSpanFromContext(context.Background(), "unit-reconcile")
+			v.reconcileUnits(ctx)
+		case <-v.closeLoopChan:
+			return
+		}
+	}
+}
+
+func (v *VolumeMgr) getUnitView(vuidPrefix proto.VuidPrefix) (unitView, bool) {
+	vol := v.all.getVol(vuidPrefix.Vid())
+	if vol == nil {
+		return unitView{}, false
+	}
+	vol.lock.RLock()
+	defer vol.lock.RUnlock()
+	index := vuidPrefix.Index()
+	if int(index) >= len(vol.vUnits) || vol.vUnits[index].vuidPrefix != vuidPrefix {
+		return unitView{}, false
+	}
+	unit := vol.vUnits[index]
+	return unitView{
+		vuid:       proto.EncodeVuid(unit.vuidPrefix, unit.epoch),
+		nextEpoch:  unit.nextEpoch,
+		diskID:     unit.vuInfo.DiskID,
+		compacting: unit.vuInfo.Compacting,
+	}, true
+}
+
+// compareDiskUnits compares the chunks hosted by the disk with the volume units recorded on it.
+// The chunk allocated for the migration of the unit not finished yet is not an orphan.
+func compareDiskUnits(diskID proto.DiskID, host string, chunks []*blobnode.ChunkInfo, units []proto.VuidPrefix,
+	lookup func(proto.VuidPrefix) (unitView, bool),
+) (orphans, missing []cm.UnitDivergence) {
+	hosted := make(map[proto.Vuid]struct{}, len(chunks))
+	for _, chunk := range chunks {
+		if chunk.Status == blobnode.ChunkStatusRelease {
+			continue
+		}
+		hosted[chunk.Vuid] = struct{}{}
+		unit, ok := lookup(chunk.Vuid.VuidPrefix())
+		if ok {
+			if unit.vuid == chunk.Vuid && unit.diskID == diskID {
+				continue
+			}
+			if epoch := chunk.Vuid.Epoch(); epoch > unit.vuid.Epoch() && epoch <= unit.nextEpoch {
+				continue
+			}
+		}
+		orphans = append(orphans, cm.UnitDivergence{
+			Vuid: chunk.Vuid, DiskID: diskID, Host: host, UnitVuid: unit.vuid, UnitDiskID: unit.diskID,
+		})
+	}
+	for _, vuidPrefix := range units {
+		unit, ok := lookup(vuidPrefix)
+		// the unit migrated to the other disk
+		if !ok || unit.diskID != diskID {
+			continue
+		}
+		if _, ok := hosted[unit.vuid]; !ok {
+			missing = append(missing, cm.UnitDivergence{
+				Vuid: unit.vuid, DiskID: diskID, Host: host, UnitVuid: unit.vuid, UnitDiskID: diskID,
+			})
+		}
+	}
+	return
+}
+
+func divergenceKey(kind string, d *cm.UnitDivergence) string {
+	return fmt.Sprintf("%s-%d-%d", kind, d.DiskID, d.Vuid)
+}
+
+// reconcileUnits runs a round of comparing the volume units with the chunks of the normal disks.
+func (v *VolumeMgr) reconcileUnits(ctx context.Context) {
+	span := trace.SpanFromContextSafe(ctx)
+	report := newUnitReconcileReport()
+	report.StartTime = time.Now().Unix()
+
+	var orphans, missing []cm.UnitDivergence
+	// the disks hosting the chunks of the vuids, to relocate the missing units
+	hostedBy := make(map[proto.Vuid]*blobnode.DiskInfo)
+	opt := &cm.ListOptionArgs{Status: proto.DiskStatusNormal, Count: defaultUnitReconcileListDiskCount}
+	for {
+		ret, err := v.diskMgr.ListDiskInfo(ctx, opt)
+		if err != nil {
+			span.Errorf("list disks failed: %v", err)
+			return
+		}
+		if ret == nil || len(ret.Disks) == 0 {
+			break
+		}
+		for _, disk := range ret.Disks {
+			if !v.raftServer.IsLeader() {
+				span.Warn("not leader, stop reconciling units")
+				return
+			}
+			chunks, err := v.blobNodeClient.ListChunks(ctx, disk.Host, &blobnode.ListChunkArgs{DiskID: disk.DiskID})
+			if err != nil {
+				report.DiskErrors[disk.DiskID] = err.Error()
+				continue
+			}
+			units, err := v.volumeTbl.ListVolumeUnit(disk.DiskID)
+			if err != nil {
+				report.DiskErrors[disk.DiskID] = err.Error()
+				continue
+			}
+			report.Disks++
+			report.Chunks += len(chunks)
+			report.Units += len(units)
+			for _, chunk := range chunks {
+				if chunk.Status != blobnode.ChunkStatusRelease {
+					hostedBy[chunk.Vuid] = disk
+				}
+			}
+			o, m := compareDiskUnits(disk.DiskID, disk.Host, chunks, units, v.getUnitView)
+			orphans = append(orphans, o...)
+			missing = append(missing, m...)
+		}
+		opt.Marker = ret.Marker
+	}
+
+	v.reconciler.lock.Lock()
+	last := v.reconciler.suspects
+	v.reconciler.lock.Unlock()
+	suspects := make(map[string]struct{}, len(orphans)+len(missing))
+	for i := range orphans {
+		key := divergenceKey("orphan", &orphans[i])
+		suspects[key] = struct{}{}
+		if _, ok := last[key]; !ok {
+			continue
+		}
+		if v.UnitReconcileAutoCorrect {
+			v.releaseOrphanChunk(ctx, &orphans[i])
+		}
+		report.Orphans = append(report.Orphans, orphans[i])
+	}
+	for i := range missing {
+		key := divergenceKey("missing", &missing[i])
+		suspects[key] = struct{}{}
+		if _, ok := last[key]; !ok {
+			continue
+		}
+		if disk, ok := hostedBy[missing[i].Vuid]; ok && v.UnitReconcileAutoCorrect {
+			v.relocateMissingUnit(ctx, &missing[i], disk)
+		}
+		report.Missing = append(report.Missing, missing[i])
+	}
+	report.EndTime = time.Now().Unix()
+	span.Infof("reconcile units done, disks: %d, chunks: %d, units: %d, orphans: %d, missing: %d, disk errors: %d",
+		report.Disks, report.Chunks, report.Units, len(report.Orphans), len(report.Missing), len(report.DiskErrors))
+
+	v.reconciler.lock.Lock()
+	v.reconciler.suspects = suspects
+	v.reconciler.report = report
+	v.reconciler.lock.Unlock()
+}
+
+// releaseOrphanChunk releases the chunk of the epoch older than the volume unit, which is left
+// by the migration of the unit. The other orphans are reported only.
+func (v *VolumeMgr) releaseOrphanChunk(ctx context.Context, d *cm.UnitDivergence) {
+	span := trace.SpanFromContextSafe(ctx)
+	unit, ok := v.getUnitView(d.Vuid.VuidPrefix())
+	if !ok || d.Vuid.Epoch() >= unit.vuid.Epoch() {
+		return
+	}
+	args := &blobnode.ChangeChunkStatusArgs{DiskID: d.DiskID, Vuid: d.Vuid}
+	err := v.blobNodeClient.SetChunkReadonly(ctx, d.Host, args)
+	if err == nil {
+		err = v.blobNodeClient.ReleaseChunk(ctx, d.Host, args)
+	}
+	if err != nil {
+		span.Errorf("release orphan chunk failed, disk_id: %d, vuid: %d, err: %v", d.DiskID, d.Vuid, err)
+		d.Error = err.Error()
+		return
+	}
+	span.Warnf("release orphan chunk, disk_id: %d, vuid: %d, unit vuid: %d", d.DiskID, d.Vuid, unit.vuid)
+	d.Corrected = true
+}
+
+// relocateMissingUnit updates the disk of the volume unit to the disk hosting its chunk.
+func (v *VolumeMgr) relocateMissingUnit(ctx context.Context, d *cm.UnitDivergence, disk *blobnode.DiskInfo) {
+	span := trace.SpanFromContextSafe(ctx)
+	unit, ok := v.getUnitView(d.Vuid.VuidPrefix())
+	if !ok || unit.vuid != d.Vuid || unit.diskID != d.DiskID {
+		return
+	}
+	args := &cm.AdminUpdateUnitArgs{VolumeUnitInfo: cm.VolumeUnitInfo{
+		Vuid:       unit.vuid,
+		DiskID:     disk.DiskID,
+		Compacting: unit.compacting,
+	}}
+	data, err := json.Marshal(args)
+	if err == nil {
+		proposeInfo := base.EncodeProposeInfo(v.GetModuleName(), OperTypeAdminUpdateVolumeUnit, data, base.ProposeContext{ReqID: span.TraceID()})
+		err = v.raftServer.Propose(ctx, proposeInfo)
+	}
+	if err != nil {
+		span.Errorf("relocate missing unit failed, vuid: %d, disk_id: %d, new disk_id: %d, err: %v", d.Vuid, d.DiskID, disk.DiskID, err)
+		d.Error = err.Error()
+		return
+	}
+	span.Warnf("relocate missing unit, vuid: %d, disk_id: %d, new disk_id: %d", d.Vuid, d.DiskID, disk.DiskID)
+	d.Corrected = true
+}
@@ -0,0 +1,134 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumemgr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/blobnode"
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
+)
+
+func TestCompareDiskUnits(t *testing.T) {
+	prefix := func(vid proto.Vid, index uint8) proto.VuidPrefix {
+		return proto.EncodeVuidPrefix(vid, index)
+	}
+	units := map[proto.VuidPrefix]unitView{
+		prefix(1, 0): {vuid: proto.EncodeVuid(prefix(1, 0), 2), nextEpoch: 2, diskID: 1},
+		prefix(2, 0): {vuid: proto.EncodeVuid(prefix(2, 0), 1), nextEpoch: 2, diskID: 2},
+		prefix(3, 0): {vuid: proto.EncodeVuid(prefix(3, 0), 1), nextEpoch: 1, diskID: 1},
+		prefix(4, 0): {vuid: proto.EncodeVuid(prefix(4, 0), 1), nextEpoch: 1, diskID: 2},
+	}
+	lookup := func(vuidPrefix proto.VuidPrefix) (unitView, bool) {
+		unit, ok := units[vuidPrefix]
+		return unit, ok
+	}
+	chunks := []*blobnode.ChunkInfo{
+		{Vuid: proto.EncodeVuid(prefix(1, 0), 2)},                                      // matched
+		{Vuid: proto.EncodeVuid(prefix(1, 0), 1)},                                      // stale epoch
+		{Vuid: proto.EncodeVuid(prefix(2, 0), 2)},                                      // migrating
+		{Vuid: proto.EncodeVuid(prefix(4, 0), 1)},                                      // unit on the other disk
+		{Vuid: proto.EncodeVuid(prefix(5, 0), 1)},                                      // volume not exist
+		{Vuid: proto.EncodeVuid(prefix(3, 0), 1), Status: blobnode.ChunkStatusRelease}, // released
+	}
+	orphans, missing := compareDiskUnits(1, "127.0.0.1", chunks, []proto.VuidPrefix{prefix(1, 0), prefix(3, 0), prefix(4, 0)}, lookup)
+
+	require.Equal(t, 3, len(orphans))
+	require.Equal(t, proto.EncodeVuid(prefix(1, 0), 1), orphans[0].Vuid)
+	require.Equal(t, proto.EncodeVuid(prefix(1, 0), 2), orphans[0].UnitVuid)
+	require.Equal(t, proto.EncodeVuid(prefix(4, 0), 1), orphans[1].Vuid)
+	require.Equal(t, proto.DiskID(2), orphans[1].UnitDiskID)
+	require.Equal(t, proto.EncodeVuid(prefix(5, 0), 1), orphans[2].Vuid)
+	require.Equal(t, proto.Vuid(0), orphans[2].UnitVuid)
+
+	require.Equal(t, 1, len(missing))
+	require.Equal(t, proto.EncodeVuid(prefix(3, 0), 1), missing[0].Vuid)
+	require.Equal(t, proto.DiskID(1), missing[0].DiskID)
+}
+
+func TestVolumeMgr_ReconcileUnits(t *testing.T) {
+	mockVolumeMgr, clean := initMockVolumeMgr(t)
+	defer clean()
+	mockVolumeMgr.UnitReconcileAutoCorrect = true
+
+	_, ctx := trace.StartSpanFromContext(context.Background(), "")
+	ctr := gomock.NewController(t)
+	mockRaftServer := mocks.NewMockRaftServer(ctr)
+	mockRaftServer.EXPECT().IsLeader().AnyTimes().Return(true)
+	mockVolumeMgr.raftServer = mockRaftServer
+	mockDiskMgr := NewMockDiskMgrAPI(ctr)
+	mockVolumeMgr.diskMgr = mockDiskMgr
+	mockBlobNode := mocks.NewMockStorageAPI(ctr)
+	mockVolumeMgr.blobNodeClient = mockBlobNode
+
+	disks := []*blobnode.DiskInfo{
+		{DiskHeartBeatInfo: blobnode.DiskHeartBeatInfo{DiskID: 1}, Host: "127.0.0.1"},
+		{DiskHeartBeatInfo: blobnode.DiskHeartBeatInfo{DiskID: 2}, Host: "127.0.0.2"},
+	}
+	mockDiskMgr.EXPECT().ListDiskInfo(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, opt *clustermgr.ListOptionArgs) (*clustermgr.ListDiskRet, error) {
+			if opt.Marker != proto.InvalidDiskID {
+				return &clustermgr.ListDiskRet{Marker: proto.InvalidDiskID}, nil
+			}
+			return &clustermgr.ListDiskRet{Disks: disks, Marker: 2}, nil
+		})
+	// the unit of index 0 is on disk 1, and of index 1 on disk 2, the chunk of the unit
+	// of vid 1 index 1 is hosted by disk 1 rather than disk 2
+	listChunks := func(_ context.Context, _ string, args *blobnode.ListChunkArgs) ([]*blobnode.ChunkInfo, error) {
+		var chunks []*blobnode.ChunkInfo
+		for i := 0; i < volumeCount; i++ {
+			vid := proto.Vid(i)
+			if vid == 1 {
+				continue
+			}
+			index := uint8(args.DiskID - 1)
+			chunks = append(chunks, &blobnode.ChunkInfo{Vuid: proto.EncodeVuid(proto.EncodeVuidPrefix(vid, index), 1)})
+		}
+		if args.DiskID == 1 {
+			chunks = append(chunks,
+				&blobnode.ChunkInfo{Vuid: proto.EncodeVuid(proto.EncodeVuidPrefix(1, 0), 1)},
+				&blobnode.ChunkInfo{Vuid: proto.EncodeVuid(proto.EncodeVuidPrefix(1, 1), 1)})
+		}
+		return chunks, nil
+	}
+	mockBlobNode.EXPECT().ListChunks(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(listChunks)
+
+	// the divergences seen once are not reported
+	mockVolumeMgr.reconcileUnits(ctx)
+	report := mockVolumeMgr.GetUnitReconcileReport()
+	require.Equal(t, 2, report.Disks)
+	require.Equal(t, 0, len(report.Orphans))
+	require.Equal(t, 0, len(report.Missing))
+
+	// the missing unit is relocated to the disk hosting its chunk
+	mockRaftServer.EXPECT().Propose(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+	mockVolumeMgr.reconcileUnits(ctx)
+	report = mockVolumeMgr.GetUnitReconcileReport()
+	require.Equal(t, 1, len(report.Orphans))
+	require.Equal(t, proto.EncodeVuid(proto.EncodeVuidPrefix(1, 1), 1), report.Orphans[0].Vuid)
+	require.Equal(t, proto.DiskID(2), report.Orphans[0].UnitDiskID)
+	require.False(t, report.Orphans[0].Corrected)
+	require.Equal(t, 1, len(report.Missing))
+	require.Equal(t, proto.EncodeVuid(proto.EncodeVuidPrefix(1, 1), 1), report.Missing[0].Vuid)
+	require.Equal(t, proto.DiskID(2), report.Missing[0].DiskID)
+	require.True(t, report.Missing[0].Corrected)
+}
@@ -143,6 +143,7 @@ type VolumeMgr struct {
 	lastFlushTime  time.Time
 	pendingEntries sync.Map
 	codeMode       map[codemode.CodeMode]codeModeConf
+	reconciler     unitReconciler
 
 	VolumeMgrConfig
 }
@@ -752,6 +753,11 @@ func (v *VolumeMgr) loop() {
 			}
 
 			span_, ctx_ := trace.StartSpanFromContext(context.Background(), "")
+			// every volume has units in all idc, so do not create new volume when any idc is write degraded
+			if degradedIDC := v.getWriteDegradedIDC(ctx_); len(degradedIDC) > 0 {
+				span_.Warnf("skip create volume, write degraded idc: %v", degradedIDC)
+				continue
+			}
 			span_.Infof("leader node start create volume")
 
 			allocatableVolCounts := v.allocator.StatAllocatable()
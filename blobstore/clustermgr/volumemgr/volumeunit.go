@@ -97,6 +97,8 @@ func (v *VolumeMgr) AllocVolumeUnit(ctx context.Context, vuid proto.Vuid) (*cmap
 	targetDiskID := proto.DiskID(0)
 	vol.lock.RLock()
 	targetDiskID = vol.vUnits[vuid.Index()].vuInfo.DiskID
+	// the new chunk keeps the size of the unit, though the chunk size of the disk type is changed
+	chunkSize := int64(vol.vUnits[vuid.Index()].vuInfo.Total)
 	for _, vu := range vol.vUnits {
 		excludes = append(excludes, vu.vuInfo.DiskID)
 	}
@@ -111,6 +113,7 @@ func (v *VolumeMgr) AllocVolumeUnit(ctx context.Context, vuid proto.Vuid) (*cmap
 		DiskType:   proto.DiskTypeHDD,
 		CodeMode:   vol.volInfoBase.CodeMode,
 		Vuids:      []proto.Vuid{newVuid.(proto.Vuid)},
+		ChunkSize:  chunkSize,
 		Idc:        diskInfo.Idc,
 		Excludes:   excludes,
 		RetryTimes: 0,
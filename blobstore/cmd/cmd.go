@@ -67,6 +67,7 @@ type Module struct {
 	SetUp      func() (*rpc.Router, []rpc.ProgressHandler)
 	TearDown   func()
 	graceful   bool
+	exclusive  bool
 }
 
 var mod *Module
@@ -81,6 +82,14 @@ func RegisterGracefulModule(m *Module) {
 	mod.graceful = true
 }
 
+// RegisterExclusiveGracefulModule registers the graceful module owning exclusive resources such as disks,
+// the old process drains and exits before the new one starts in reloading.
+func RegisterExclusiveGracefulModule(m *Module) {
+	mod = m
+	mod.graceful = true
+	mod.exclusive = true
+}
+
 func newLogWriter(cfg *LogConfig) io.Writer {
 	maxsize := cfg.MaxSize
 	if maxsize == 0 {
@@ -166,6 +175,9 @@ func Main(args []string) {
 		graceful.Run(&graceful.Config{
 			Entry:           programEntry,
 			ListenAddresses: []string{cfg.BindAddr},
+			Exclusive:       mod.exclusive,
+			// wait for shutting down the http server and tearing down the module
+			StopTimeout: 2 * time.Duration(cfg.ShutdownTimeoutS) * time.Second,
 		})
 		return
 	}
@@ -0,0 +1,49 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+// Allocator allocates the buffers of the shards reconstructed, such as resourcepool.MemPool.
+type Allocator interface {
+	Alloc(size int) ([]byte, error)
+}
+
+// preallocShards allocates the buffers of the missing shards of the first limit shards by the
+// allocator, or of the required shards if required is not nil, so that the engines reconstruct the
+// shards in them rather than allocating. The shards of the capacity enough are not allocated, and
+// the engines allocate the shards if the allocator fails.
+func (c *Config) preallocShards(shards [][]byte, limit int, required []bool) {
+	if c.Allocator == nil {
+		return
+	}
+	size := 0
+	for _, shard := range shards {
+		if len(shard) > size {
+			size = len(shard)
+		}
+	}
+	if size == 0 {
+		return
+	}
+	for i := 0; i < limit && i < len(shards); i++ {
+		if len(shards[i]) != 0 || cap(shards[i]) >= size || (required != nil && !required[i]) {
+			continue
+		}
+		buf, err := c.Allocator.Alloc(size)
+		if err != nil || cap(buf) < size {
+			continue
+		}
+		shards[i] = buf[:0]
+	}
+}
@@ -79,6 +79,10 @@ func newBuffer(dataSize, from, to int, tactic codemode.Tactic, pool *resourcepoo
 	if shardSize < tactic.MinShardSize {
 		shardSize = tactic.MinShardSize
 	}
+	// the GF(2^16) engine of the wide stripes codes the shards in blocks of 64 bytes
+	if tactic.N+tactic.M+tactic.L > maxGF8Shards {
+		shardSize = (shardSize + shardAlignSize - 1) &^ (shardAlignSize - 1)
+	}
 
 	ecDataSize := shardSize * tactic.N
 	ecSize := shardSize * (tactic.N + tactic.M + tactic.L)
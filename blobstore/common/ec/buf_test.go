@@ -203,3 +203,15 @@ func TestBufferDataPadding(t *testing.T) {
 			buf[buffer.DataSize:buffer.ShardSize*cm.N])
 	}
 }
+
+func TestBufferWideStripe(t *testing.T) {
+	tactic := codemode.Tactic{N: 240, M: 80, AZCount: 1, MinShardSize: 1}
+	for _, size := range []int{1, 1000, 240 * 64, 240*64 + 1} {
+		sizes, err := ec.GetBufferSizes(size, tactic)
+		require.NoError(t, err)
+		// the shards of more than 256 are aligned to 64 bytes
+		require.Zero(t, sizes.ShardSize%64)
+		require.GreaterOrEqual(t, sizes.ShardSize*tactic.N, size)
+		require.Less(t, sizes.ShardSize*tactic.N-size, tactic.N*64)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+)
+
+// ShardChecksumSize size of crc32c trailer at the end of every shard
+//
+// - - - - - - - - - - - - - - - - - - -
+// |        payload         | crc32c(4) |
+// - - - - - - - - - - - - - - - - - - -
+const ShardChecksumSize = crc32.Size
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ShardPayload returns payload of the shard with checksum trailer (No-Copy)
+func ShardPayload(shard []byte) []byte {
+	if len(shard) < ShardChecksumSize {
+		return shard[:0]
+	}
+	return shard[:len(shard)-ShardChecksumSize]
+}
+
+// PutShardChecksum calculates checksum of the payload and writes it into the trailer
+func PutShardChecksum(shard []byte) {
+	payload := ShardPayload(shard)
+	binary.BigEndian.PutUint32(shard[len(payload):], crc32.Checksum(payload, castagnoliTable))
+}
+
+// CheckShardChecksum returns true if payload of the shard matches its trailer
+func CheckShardChecksum(shard []byte) bool {
+	if len(shard) < ShardChecksumSize {
+		return false
+	}
+	payload := ShardPayload(shard)
+	return binary.BigEndian.Uint32(shard[len(payload):]) == crc32.Checksum(payload, castagnoliTable)
+}
+
+// SplitWithChecksum splits data into shards, every shard has a checksum trailer space
+func SplitWithChecksum(e Encoder, data []byte) ([][]byte, error) {
+	shards, err := e.Split(data)
+	if err != nil {
+		return nil, err
+	}
+	size := shardSize(shards)
+	buf := make([]byte, (size+ShardChecksumSize)*len(shards))
+	for idx := range shards {
+		shard := buf[: size+ShardChecksumSize : size+ShardChecksumSize]
+		buf = buf[size+ShardChecksumSize:]
+		copy(shard, shards[idx])
+		shards[idx] = shard
+	}
+	return shards, nil
+}
+
+// EncodeWithChecksum encodes payloads of shards, then writes checksum trailer of all shards
+func EncodeWithChecksum(e Encoder, shards [][]byte) error {
+	if err := e.Encode(payloads(shards)); err != nil {
+		return err
+	}
+	for _, shard := range shards {
+		PutShardChecksum(shard)
+	}
+	return nil
+}
+
+// VerifyWithChecksum returns indexes of shards mismatched with trailer,
+// and verifies parity shards with data shards if all trailers are correct.
+func VerifyWithChecksum(e Encoder, shards [][]byte) (badIdx []int, ok bool, err error) {
+	badIdx = badChecksumShards(shards)
+	if len(badIdx) > 0 {
+		return badIdx, false, nil
+	}
+	ok, err = e.Verify(payloads(shards))
+	return
+}
+
+// ReconstructWithChecksum reconstructs all missing shards and shards mismatched with trailer,
+// the trailer of reconstructed shards will be rewritten.
+func ReconstructWithChecksum(e Encoder, shards [][]byte, badIdx []int) error {
+	size := 0
+	bads := make(map[int]struct{}, len(badIdx))
+	for _, idx := range badIdx {
+		bads[idx] = struct{}{}
+	}
+	for _, idx := range badChecksumShards(shards) {
+		bads[idx] = struct{}{}
+	}
+	for idx, shard := range shards {
+		if len(shard) == 0 {
+			bads[idx] = struct{}{}
+		}
+	}
+	for idx, shard := range shards {
+		if _, ok := bads[idx]; !ok {
+			size = len(shard)
+			break
+		}
+	}
+	if size < ShardChecksumSize {
+		return ErrInvalidShards
+	}
+
+	allBad := make([]int, 0, len(bads))
+	for idx := range bads {
+		allBad = append(allBad, idx)
+	}
+	pls := payloads(shards)
+	if err := e.Reconstruct(pls, allBad); err != nil {
+		return err
+	}
+	for _, idx := range allBad {
+		pl := pls[idx]
+		if cap(pl) >= size {
+			shards[idx] = pl[:size]
+		} else {
+			shards[idx] = make([]byte, size)
+			copy(shards[idx], pl)
+		}
+		PutShardChecksum(shards[idx])
+	}
+	return nil
+}
+
+func payloads(shards [][]byte) [][]byte {
+	pls := make([][]byte, len(shards))
+	for idx, shard := range shards {
+		pls[idx] = ShardPayload(shard)
+	}
+	return pls
+}
+
+func badChecksumShards(shards [][]byte) (badIdx []int) {
+	for idx, shard := range shards {
+		if len(shard) > 0 && !CheckShardChecksum(shard) {
+			badIdx = append(badIdx, idx)
+		}
+	}
+	return
+}
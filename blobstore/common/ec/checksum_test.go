@@ -0,0 +1,82 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+)
+
+func TestShardChecksum(t *testing.T) {
+	shard := make([]byte, 1024+ShardChecksumSize)
+	rand.Read(shard)
+	require.False(t, CheckShardChecksum(shard))
+	PutShardChecksum(shard)
+	require.True(t, CheckShardChecksum(shard))
+	require.Equal(t, 1024, len(ShardPayload(shard)))
+
+	shard[0]++
+	require.False(t, CheckShardChecksum(shard))
+	require.False(t, CheckShardChecksum(shard[:ShardChecksumSize-1]))
+	require.Equal(t, 0, len(ShardPayload(shard[:1])))
+}
+
+func TestEncoderWithChecksum(t *testing.T) {
+	for _, mode := range []codemode.CodeMode{codemode.EC6P6, codemode.EC6P10L2} {
+		encoder, err := NewEncoder(Config{CodeMode: mode.Tactic(), EnableVerify: true})
+		require.NoError(t, err)
+
+		data := make([]byte, 1<<16+1)
+		rand.Read(data)
+		shards, err := SplitWithChecksum(encoder, data)
+		require.NoError(t, err)
+		require.NoError(t, EncodeWithChecksum(encoder, shards))
+		for _, shard := range shards {
+			require.True(t, CheckShardChecksum(shard))
+		}
+		badIdx, ok, err := VerifyWithChecksum(encoder, shards)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, 0, len(badIdx))
+
+		origin := copyShards(shards)
+		// silent corruption and missing shard
+		shards[1][10]++
+		shards[mode.Tactic().N] = nil
+		badIdx, ok, err = VerifyWithChecksum(encoder, shards)
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Equal(t, []int{1}, badIdx)
+
+		require.NoError(t, ReconstructWithChecksum(encoder, shards, nil))
+		for idx := range shards {
+			require.True(t, bytes.Equal(origin[idx], shards[idx]))
+		}
+
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, encoder.Join(buf, payloads(shards), len(data)))
+		require.Equal(t, data, buf.Bytes())
+
+		for idx := range shards {
+			shards[idx] = nil
+		}
+		require.ErrorIs(t, ReconstructWithChecksum(encoder, shards, nil), ErrInvalidShards)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"sync"
+
+	"github.com/cubefs/cubefs/blobstore/util/limit"
+)
+
+// derivedEncoders caches the encoders of the same config but the goroutines of coding a stripe,
+// which share the concurrency pool.
+type derivedEncoders struct {
+	sync.Mutex
+	encoders map[int]Encoder // by the max goroutines
+}
+
+func newDerivedEncoders() *derivedEncoders {
+	return &derivedEncoders{encoders: make(map[int]Encoder)}
+}
+
+func (d *derivedEncoders) get(cfg Config, pool limit.Limiter, n int) (Encoder, error) {
+	if n < 0 {
+		n = 0
+	}
+	d.Lock()
+	defer d.Unlock()
+	if e, ok := d.encoders[n]; ok {
+		return e, nil
+	}
+	cfg.MaxGoroutines = n
+	e, err := newEncoder(cfg, pool, d)
+	if err != nil {
+		return nil, err
+	}
+	d.encoders[n] = e
+	return e, nil
+}
+
+func (e *encoder) WithConcurrency(n int) (Encoder, error) {
+	return e.derived.get(e.Config, e.pool, n)
+}
+
+func (e *lrcEncoder) WithConcurrency(n int) (Encoder, error) {
+	return e.derived.get(e.Config, e.pool, n)
+}
+
+// WithConcurrency reports the progress of the encoder derived too.
+func (e *progressEncoder) WithConcurrency(n int) (Encoder, error) {
+	derived, err := e.Encoder.WithConcurrency(n)
+	if err != nil {
+		return nil, err
+	}
+	return &progressEncoder{Encoder: derived, chunkSize: e.chunkSize, fn: e.fn}, nil
+}
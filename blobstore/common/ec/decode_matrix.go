@@ -0,0 +1,148 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"sync"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+// DecodeMatrix is the coefficients of decoding the missing shards from the survivors in GF(2^8),
+// the shard Missing[r] is the sum of Rows[r][c] times the shard Survivors[c] over all c.
+type DecodeMatrix struct {
+	Missing   []int    // the missing shards in the order of index
+	Survivors []int    // the shards decoded from in the order of index
+	Rows      [][]byte // len(Missing) rows by len(Survivors) columns
+
+	// the engine coding the missing shards as the parity of the survivors by the rows
+	engineOnce sync.Once
+	engine     reedsolomon.Encoder
+	engineErr  error
+}
+
+// PartialReconstruct adds the products of the survivors in shards to the missing shards in
+// shards, see PartialReconstructTo.
+func (m *DecodeMatrix) PartialReconstruct(shards [][]byte) error {
+	dst := make([][]byte, len(m.Missing))
+	for r, i := range m.Missing {
+		if i >= len(shards) {
+			return ErrInvalidShards
+		}
+		dst[r] = shards[i]
+	}
+	return m.PartialReconstructTo(shards, dst)
+}
+
+// PartialReconstructTo XORs the products of the coefficients and the survivors present in
+// shards into dst, dst[r] accumulates the partial sum of the shard Missing[r]. The survivors
+// held elsewhere are empty in shards, so the partial sums of the azs are accumulated into the
+// same buffers, or into the buffers of their own to be XORed together, without the stripe.
+// The buffers of dst are zeroed by the caller before the first call. All the missing shards
+// are accumulated in one pass over every survivor, so the partial sums of the failures of a
+// stripe are computed and transferred together.
+func (m *DecodeMatrix) PartialReconstructTo(shards, dst [][]byte) error {
+	if len(dst) != len(m.Missing) {
+		return ErrInvalidShards
+	}
+	engine, err := m.partialEngine()
+	if err != nil {
+		return err
+	}
+	size := -1
+	for _, d := range dst {
+		if size >= 0 && len(d) != size {
+			return reedsolomon.ErrShardSize
+		}
+		size = len(d)
+	}
+	for c, s := range m.Survivors {
+		if s >= len(shards) || len(shards[s]) == 0 {
+			continue
+		}
+		if len(shards[s]) != size {
+			return reedsolomon.ErrShardSize
+		}
+		if err = engine.EncodeIdx(shards[s], c, dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// partialEngine returns the engine whose parity rows are the rows of the matrix, so that the
+// survivor of column c is added to all the missing shards by the kernels of EncodeIdx.
+func (m *DecodeMatrix) partialEngine() (reedsolomon.Encoder, error) {
+	m.engineOnce.Do(func() {
+		if len(m.Missing) == 0 || len(m.Survivors) == 0 || len(m.Rows) != len(m.Missing) ||
+			len(m.Survivors)+len(m.Missing) > maxGF8Shards {
+			m.engineErr = ErrInvalidShards
+			return
+		}
+		for _, row := range m.Rows {
+			if len(row) != len(m.Survivors) {
+				m.engineErr = ErrInvalidShards
+				return
+			}
+		}
+		m.engine, m.engineErr = reedsolomon.New(len(m.Survivors), len(m.Missing),
+			reedsolomon.WithInversionCache(false), reedsolomon.WithCustomMatrix(m.Rows))
+	})
+	return m.engine, m.engineErr
+}
+
+// decodeMatrix returns the decode matrix of the bad shards out of total, the survivors are the
+// first dataShards of the candidates not bad.
+func decodeMatrix(parity [][]byte, dataShards, candidates, total int, badIdx []int) (*DecodeMatrix, error) {
+	missing, err := sortedBadIdx(badIdx, total)
+	if err != nil {
+		return nil, err
+	}
+	survivors, inverted, err := invertSurvivors(parity, dataShards, candidates, missing)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([][]byte, len(missing))
+	for r, i := range missing {
+		rows[r] = append([]byte(nil), decodeRow(parity, inverted, dataShards, i)...)
+	}
+	return &DecodeMatrix{Missing: missing, Survivors: survivors, Rows: rows}, nil
+}
+
+func (e *encoder) GetDecodeMatrix(badIdx []int) (*DecodeMatrix, error) {
+	tactic := e.CodeMode
+	if e.EnableGF16 || tactic.N+tactic.M > maxGF8Shards {
+		return nil, reedsolomon.ErrNotSupported
+	}
+	parity, err := parityMatrix(e.engine, tactic.N, tactic.M)
+	if err != nil {
+		return nil, err
+	}
+	return decodeMatrix(parity, tactic.N, tactic.N+tactic.M, tactic.N+tactic.M, badIdx)
+}
+
+// GetDecodeMatrix decodes the local parity shards from the survivors of the global stripe too,
+// they are linear in the data shards through the global parity shards in the az.
+func (e *lrcEncoder) GetDecodeMatrix(badIdx []int) (*DecodeMatrix, error) {
+	tactic := e.CodeMode
+	if e.EnableGF16 || tactic.N+tactic.M+tactic.L > maxGF8Shards {
+		return nil, reedsolomon.ErrNotSupported
+	}
+	parity, err := encodedMatrix(e.Encode, tactic.N, tactic.M+tactic.L)
+	if err != nil {
+		return nil, err
+	}
+	return decodeMatrix(parity, tactic.N, tactic.N+tactic.M, tactic.N+tactic.M+tactic.L, badIdx)
+}
@@ -0,0 +1,252 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"sort"
+
+	"github.com/klauspost/reedsolomon"
+
+	"github.com/cubefs/cubefs/blobstore/util/limit"
+)
+
+// Decoder reconstructs the stripes of a fixed failure pattern, it is safe for concurrent use.
+type Decoder interface {
+	// reconstruct the bad shards prepared in place, the other shards should be present
+	Reconstruct(shards [][]byte) error
+}
+
+// decoder codes the bad shards as the parity of the survivors by the decode matrix, which is
+// inverted once for all the stripes of the failure pattern. The leopard engine is not coded by
+// the matrix, so the bad shards are reconstructed by the engine.
+type decoder struct {
+	pool      limit.Limiter
+	total     int
+	badIdx    []int
+	dataOnly  bool                // the bad parity shards are not reconstructed
+	survivors []int               // the shards decoded from, nil if reconstructed by the engine
+	engine    reedsolomon.Encoder // the decode engine, or the engine to reconstruct
+}
+
+// sortedBadIdx returns the sorted and deduplicated bad idx of the shards.
+func sortedBadIdx(badIdx []int, total int) ([]int, error) {
+	sorted := make([]int, 0, len(badIdx))
+	seen := make(map[int]bool, len(badIdx))
+	for _, i := range badIdx {
+		if i < 0 || i >= total {
+			return nil, ErrInvalidShards
+		}
+		if !seen[i] {
+			seen[i] = true
+			sorted = append(sorted, i)
+		}
+	}
+	sort.Ints(sorted)
+	return sorted, nil
+}
+
+func newDecoder(pool limit.Limiter, engine reedsolomon.Encoder, dataShards, parityShards int,
+	badIdx []int, dataOnly bool, opts ...reedsolomon.Option) (*decoder, error) {
+	total := dataShards + parityShards
+	badIdx, err := sortedBadIdx(badIdx, total)
+	if err != nil {
+		return nil, err
+	}
+	if len(badIdx) > parityShards {
+		return nil, reedsolomon.ErrTooFewShards
+	}
+	d := &decoder{pool: pool, total: total, badIdx: badIdx, dataOnly: dataOnly, engine: engine}
+	if _, ok := engine.(*gf16Engine); ok || len(badIdx) == 0 {
+		return d, nil
+	}
+
+	parity, err := parityMatrix(engine, dataShards, parityShards)
+	if err != nil {
+		return nil, err
+	}
+	survivors, inverted, err := invertSurvivors(parity, dataShards, total, badIdx)
+	if err != nil {
+		return nil, err
+	}
+	// the survivors are out of all the bad shards, but only the bad data shards are decoded
+	if dataOnly {
+		if badIdx = badIdx[:sort.SearchInts(badIdx, dataShards)]; len(badIdx) == 0 {
+			d.badIdx = nil
+			return d, nil
+		}
+	}
+
+	rows := make([][]byte, len(badIdx))
+	for r, i := range badIdx {
+		rows[r] = decodeRow(parity, inverted, dataShards, i)
+	}
+	d.engine, err = newEngine(dataShards, len(badIdx), false,
+		append(opts, reedsolomon.WithCustomMatrix(rows))...)
+	if err != nil {
+		return nil, err
+	}
+	d.badIdx = badIdx
+	d.survivors = survivors
+	return d, nil
+}
+
+// parityMatrix returns the coefficients of the parity shards over the data shards, which are
+// the parity shards of the unit vectors of the data shards.
+// invertSurvivors returns the first dataShards shards of the candidates not bad, and the inverse
+// of the matrix of the survivors over the data shards, which decodes the data shards from them.
+// The rows of parity are the coefficients of the parity shards over the data shards.
+func invertSurvivors(parity [][]byte, dataShards, candidates int, badIdx []int) ([]int, [][]byte, error) {
+	bad := make(map[int]bool, len(badIdx))
+	for _, i := range badIdx {
+		bad[i] = true
+	}
+	survivors := make([]int, 0, dataShards)
+	sub := make([][]byte, 0, dataShards)
+	for i := 0; i < candidates && len(survivors) < dataShards; i++ {
+		if bad[i] {
+			continue
+		}
+		survivors = append(survivors, i)
+		if i < dataShards {
+			row := make([]byte, dataShards)
+			row[i] = 1
+			sub = append(sub, row)
+		} else {
+			sub = append(sub, parity[i-dataShards])
+		}
+	}
+	if len(survivors) < dataShards {
+		return nil, nil, reedsolomon.ErrTooFewShards
+	}
+	inverted, err := gfInvertMatrix(sub)
+	if err != nil {
+		return nil, nil, err
+	}
+	return survivors, inverted, nil
+}
+
+// decodeRow returns the coefficients of the shard i over the survivors of the inverted matrix,
+// the data shards are the inverted matrix times the survivors, and the parity shards are the
+// parity matrix times the data shards.
+func decodeRow(parity, inverted [][]byte, dataShards, i int) []byte {
+	if i < dataShards {
+		return inverted[i]
+	}
+	return gfMulMatrixRow(parity[i-dataShards], inverted)
+}
+
+func parityMatrix(engine reedsolomon.Encoder, dataShards, parityShards int) ([][]byte, error) {
+	return encodedMatrix(engine.Encode, dataShards, parityShards)
+}
+
+// encodedMatrix returns the coefficients of the parity shards coded by the encode function.
+func encodedMatrix(encode func(shards [][]byte) error, dataShards, parityShards int) ([][]byte, error) {
+	shards := make([][]byte, dataShards+parityShards)
+	for i := range shards {
+		shards[i] = make([]byte, 1)
+	}
+	parity := make([][]byte, parityShards)
+	for p := range parity {
+		parity[p] = make([]byte, dataShards)
+	}
+	for d := 0; d < dataShards; d++ {
+		for i := range shards {
+			shards[i][0] = 0
+		}
+		shards[d][0] = 1
+		if err := encode(shards); err != nil {
+			return nil, err
+		}
+		for p := range parity {
+			parity[p][d] = shards[dataShards+p][0]
+		}
+	}
+	return parity, nil
+}
+
+func (d *decoder) Reconstruct(shards [][]byte) error {
+	d.pool.Acquire()
+	defer d.pool.Release()
+	return d.reconstruct(shards)
+}
+
+func (d *decoder) reconstruct(shards [][]byte) error {
+	if len(shards) != d.total {
+		return ErrInvalidShards
+	}
+	if len(d.badIdx) == 0 {
+		return nil
+	}
+	if d.survivors == nil {
+		initBadShards(shards, d.badIdx)
+		if d.dataOnly {
+			return d.engine.ReconstructData(shards)
+		}
+		return d.engine.Reconstruct(shards)
+	}
+
+	size := len(shards[d.survivors[0]])
+	work := make([][]byte, 0, len(d.survivors)+len(d.badIdx))
+	for _, i := range d.survivors {
+		if len(shards[i]) == 0 {
+			return reedsolomon.ErrTooFewShards
+		}
+		if len(shards[i]) != size {
+			return reedsolomon.ErrShardSize
+		}
+		work = append(work, shards[i])
+	}
+	for _, i := range d.badIdx {
+		if cap(shards[i]) < size {
+			shards[i] = make([]byte, size)
+		}
+		shards[i] = shards[i][:size]
+		work = append(work, shards[i])
+	}
+	return d.engine.Encode(work)
+}
+
+// lrcDecoder reconstructs the bad global shards by the decoder of the global stripe, and then
+// encodes the local parity shards of the azs which are bad.
+type lrcDecoder struct {
+	*lrcEncoder
+	global   *decoder
+	localIdx map[int][]int // az index to the bad local parity shards
+}
+
+func (d *lrcDecoder) Reconstruct(shards [][]byte) error {
+	if len(shards) != d.CodeMode.N+d.CodeMode.M+d.CodeMode.L {
+		return ErrInvalidShards
+	}
+	d.pool.Acquire()
+	defer d.pool.Release()
+
+	if err := d.global.reconstruct(shards[:d.CodeMode.N+d.CodeMode.M]); err != nil {
+		return err
+	}
+	for idx, localIdx := range d.localIdx {
+		size := shardSize(shards)
+		for _, i := range localIdx {
+			if cap(shards[i]) < size {
+				shards[i] = make([]byte, size)
+			}
+			shards[i] = shards[i][:size]
+		}
+		if err := d.localEngine.Encode(d.GetShardsInIdc(shards, idx)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"crypto/rand"
+	mrand "math/rand"
+	"testing"
+
+	"github.com/klauspost/reedsolomon"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+)
+
+func TestGaloisInvertMatrix(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		require.Equal(t, byte(1), gfMul(byte(a), gfInv(byte(a))))
+	}
+	matrix := [][]byte{{1, 2, 3}, {4, 5, 6}, {7, 8, 10}}
+	inverted, err := gfInvertMatrix(matrix)
+	require.NoError(t, err)
+	for i := range matrix {
+		row := gfMulMatrixRow(matrix[i], inverted)
+		for j := range row {
+			if i == j {
+				require.Equal(t, byte(1), row[j])
+			} else {
+				require.Equal(t, byte(0), row[j])
+			}
+		}
+	}
+	_, err = gfInvertMatrix([][]byte{{1, 2}, {1, 2}})
+	require.ErrorIs(t, err, errSingularMatrix)
+}
+
+func TestEncoderPrepareReconstruct(t *testing.T) {
+	for _, cm := range codemode.GetECCodeModes() {
+		testEncoderPrepareReconstruct(t, Config{CodeMode: cm.Tactic()})
+	}
+	testEncoderPrepareReconstruct(t, Config{CodeMode: codemode.EC6P6.Tactic(), EnableGF16: true})
+}
+
+func testEncoderPrepareReconstruct(t *testing.T, cfg Config) {
+	tactic := cfg.CodeMode
+	encoder, err := NewEncoder(cfg)
+	require.NoError(t, err)
+
+	_, err = encoder.PrepareReconstruct([]int{tactic.N + tactic.M + tactic.L})
+	require.ErrorIs(t, err, ErrInvalidShards)
+	badIdx := make([]int, tactic.M+1)
+	for i := range badIdx {
+		badIdx[i] = i
+	}
+	_, err = encoder.PrepareReconstruct(badIdx)
+	require.ErrorIs(t, err, reedsolomon.ErrTooFewShards)
+
+	// the data, parity and local parity shards are bad
+	badIdx = []int{tactic.N + tactic.M - 1, mrand.Intn(tactic.N), tactic.N - 1}
+	if len(badIdx) > tactic.M {
+		badIdx = badIdx[:tactic.M]
+	}
+	if tactic.L > 0 {
+		badIdx = append(badIdx, tactic.N+tactic.M+mrand.Intn(tactic.L))
+	}
+	decoder, err := encoder.PrepareReconstruct(badIdx)
+	require.NoError(t, err)
+	require.ErrorIs(t, decoder.Reconstruct(make([][]byte, 1)), ErrInvalidShards)
+
+	for _, size := range []int{1 << 8, (1 << 14) + 17} {
+		data := make([]byte, size*tactic.N)
+		rand.Read(data)
+		shards, err := encoder.Split(data)
+		require.NoError(t, err)
+		require.NoError(t, encoder.Encode(shards))
+		origin := copyShards(shards)
+
+		for _, i := range badIdx {
+			shards[i] = nil
+		}
+		require.NoError(t, decoder.Reconstruct(shards))
+		require.Equal(t, origin, shards, "tactic: %+v, bad: %v", tactic, badIdx)
+	}
+}
+
+func BenchmarkEncoderPrepareReconstruct(b *testing.B) {
+	tactic := codemode.EC12P4.Tactic()
+	encoder, err := NewEncoder(Config{CodeMode: tactic})
+	require.NoError(b, err)
+	data := make([]byte, (1<<16)*tactic.N)
+	rand.Read(data)
+	shards, err := encoder.Split(data)
+	require.NoError(b, err)
+	require.NoError(b, encoder.Encode(shards))
+	badIdx := []int{0, 3, tactic.N + 1}
+
+	b.Run("reconstruct", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			require.NoError(b, encoder.Reconstruct(shards, badIdx))
+		}
+	})
+	b.Run("prepared", func(b *testing.B) {
+		decoder, err := encoder.PrepareReconstruct(badIdx)
+		require.NoError(b, err)
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			require.NoError(b, decoder.Reconstruct(shards))
+		}
+	})
+}
@@ -0,0 +1,225 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package ectest provides the conformance suite of the ec package, which checks the shards coded
+// on the architecture against the golden vectors, such as the big-endian and the architectures
+// without the unaligned memory access, and the generic kernels forced by the noasm build tag:
+//
+//	go test -tags noasm ./blobstore/common/ec/...
+package ectest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/ec"
+)
+
+// the size of the shards of the probe stripe, which is odd so that the tail of the shards is
+// coded out of the simd blocks, the wide stripes are aligned to 64 bytes
+const (
+	probeShardSize     = 1013
+	probeWideShardSize = 1024
+)
+
+// Vector is the golden digest of the shards of the probe stripe coded by the config.
+type Vector struct {
+	Name      string
+	Config    ec.Config
+	ShardSize int
+	Digest    string // the hex sha256 of all the shards encoded
+}
+
+// the hex sha256 of the shards of the probe stripe, which never change unless the coding changes
+var goldenDigests = map[string]string{
+	"EC15P12":       "36b13c5ae48a7b84b53ae02c64efb58b20795f4339a08d3ae13f51959cf909ff",
+	"EC6P6":         "25e835a1bc483459b445c2994cbded80b67398af969d2688a450bdbdeeaa76fd",
+	"EC16P20L2":     "0927485a07856ba5f1bb231c00a6855dc2c6bf4d245db9c6583a211631f4106c",
+	"EC6P10L2":      "e011c400817e8ba3be91fc186d595b865143e946b2a19e7ac8ccebec5ce18106",
+	"EC6P3L3":       "5d7b1644383b1c08795b1e78182886075fe064f3121ec899c47a221f788fdfda",
+	"EC6P6Align0":   "25e835a1bc483459b445c2994cbded80b67398af969d2688a450bdbdeeaa76fd",
+	"EC6P6Align512": "25e835a1bc483459b445c2994cbded80b67398af969d2688a450bdbdeeaa76fd",
+	"EC4P4L2":       "1bde1d438a699f5422d124f60a6dffaa5b4d410a9076fc0df62c1842abb54bec",
+	"EC12P4":        "1a79e0a7a5ed729628b36e49530bdb395eb15a8e85d0d70af186cf91b0935018",
+	"EC16P4":        "a86bc1fd61296d1f805a5fb288230b6157ca7ccf665576a2ea370adaad190ff0",
+	"EC3P3":         "5f2cf3e63c04e036bae7ab48bf00b4d2bc4623425f3e88ee926a7a6a894565f5",
+	"EC10P4":        "a6f5b2bbad0743eb590d0145dc358ed71efb9744f83a6e50a0cc5ffcb765a903",
+	"EC6P3":         "612a3c6f8fcffdf48692616f4cdcb07c4aa805f245a7d68617458c8b03dd01b2",
+	"EC12P9":        "83cbe906b86d78cf15e7eeb4a29553184fe096ebe6c70b575e2946f22c1ded82",
+	"EC6P6L9":       "97dc924d26d4eb09f141a6cb2ff0be5ed8c89ab40d6022cbf2b65fd3c9af5290",
+	"EC6P8L10":      "c016d54609df4e3080cdd1cd59ae5a1b2706a3f49a4eb460133de539368d93a4",
+	"EC6P6-GF16":    "65e689e754bac28324ad3a7330ecd46e3831b5211291abd69f8aadce86fd23b9",
+	"EC6P10L2-GF16": "8f037b355495ab0b23dfc289f6eb68142b41979ad22c260918d95dcbf672f2c1",
+	"EC12P4-Cauchy": "344a354a4db73327abf1b8d46801d4c9e2e8491968c8b7be19f88a7dfbee0c37",
+	"EC240P80-GF16": "1faa4b20c6ff97a878eafa4357b88e82e7e04b84085f161146177a52aa8eee38",
+}
+
+// cauchyMatrix returns the parity rows of the cauchy matrix of ISA-L, 1/((N+i)^j) in GF(2^8).
+func cauchyMatrix(dataShards, parityShards int) [][]byte {
+	inv := func(a byte) byte {
+		for b := 1; b < 256; b++ {
+			if gfMul(a, byte(b)) == 1 {
+				return byte(b)
+			}
+		}
+		return 0
+	}
+	matrix := make([][]byte, parityShards)
+	for i := range matrix {
+		matrix[i] = make([]byte, dataShards)
+		for j := range matrix[i] {
+			matrix[i][j] = inv(byte((dataShards + i) ^ j))
+		}
+	}
+	return matrix
+}
+
+// gfMul multiplies in GF(2^8) of the polynomial x^8 + x^4 + x^3 + x^2 + 1 bit by bit.
+func gfMul(a, b byte) (p byte) {
+	for ; b > 0; b >>= 1 {
+		if b&1 != 0 {
+			p ^= a
+		}
+		carry := a & 0x80
+		if a <<= 1; carry != 0 {
+			a ^= 0x1d
+		}
+	}
+	return
+}
+
+// Vectors returns the golden vectors of all the code modes of EC and LRC, and of the engines of
+// GF(2^16), the wide stripe and the custom encoding matrix.
+func Vectors() []Vector {
+	vectors := make([]Vector, 0)
+	add := func(name string, cfg ec.Config, shardSize int) {
+		vectors = append(vectors, Vector{Name: name, Config: cfg, ShardSize: shardSize, Digest: goldenDigests[name]})
+	}
+	for _, mode := range codemode.GetECCodeModes() {
+		add(mode.String(), ec.Config{CodeMode: mode.Tactic()}, probeShardSize)
+	}
+	for _, mode := range []codemode.CodeMode{codemode.EC6P6, codemode.EC6P10L2} {
+		add(mode.String()+"-GF16", ec.Config{CodeMode: mode.Tactic(), EnableGF16: true}, probeShardSize)
+	}
+	add("EC12P4-Cauchy", ec.Config{CodeMode: codemode.EC12P4.Tactic(), EncodingMatrix: cauchyMatrix(12, 4)}, probeShardSize)
+	add("EC240P80-GF16", ec.Config{
+		CodeMode: codemode.Tactic{N: 240, M: 80, AZCount: 1, PutQuorum: 300, MinShardSize: 1},
+	}, probeWideShardSize)
+	return vectors
+}
+
+// probeShards returns the shards of the probe stripe with the data shards filled, which are cut
+// from a buffer at the odd offsets, so that the shards are not aligned in memory.
+func probeShards(tactic codemode.Tactic, shardSize int) [][]byte {
+	total := tactic.N + tactic.M + tactic.L
+	buf := make([]byte, 1+total*(shardSize+1))
+	shards := make([][]byte, total)
+	for i := range shards {
+		off := 1 + i*(shardSize+1)
+		shards[i] = buf[off : off+shardSize : off+shardSize]
+		if i < tactic.N {
+			for j := range shards[i] {
+				shards[i][j] = byte(i*131 + j*7 + j>>8 + 1)
+			}
+		}
+	}
+	return shards
+}
+
+func digest(shards [][]byte) string {
+	h := sha256.New()
+	for _, shard := range shards {
+		h.Write(shard)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func copyShards(shards [][]byte) [][]byte {
+	dst := make([][]byte, len(shards))
+	for i := range shards {
+		dst[i] = append([]byte{}, shards[i]...)
+	}
+	return dst
+}
+
+// Check codes the probe stripe of the vector, and returns the error if the shards differ from the
+// golden vector, or they are not verified, reconstructed and encoded one by one as expected.
+func Check(v Vector) error {
+	encoder, err := ec.NewEncoder(v.Config)
+	if err != nil {
+		return err
+	}
+	defer encoder.Release()
+	tactic := v.Config.CodeMode
+
+	shards := probeShards(tactic, v.ShardSize)
+	if err = encoder.Encode(shards); err != nil {
+		return err
+	}
+	if got := digest(shards); got != v.Digest {
+		return fmt.Errorf("digest %s, expected %s", got, v.Digest)
+	}
+	if ok, err := encoder.Verify(shards); err != nil || !ok {
+		return fmt.Errorf("verify %v, %v", ok, err)
+	}
+
+	// the data shards of the most missing
+	badIdx := make([]int, 0, tactic.M)
+	for i := 0; i < tactic.M && i < tactic.N; i++ {
+		badIdx = append(badIdx, i)
+	}
+	work := copyShards(shards)
+	for _, i := range badIdx {
+		work[i] = work[i][:0]
+	}
+	if err = encoder.Reconstruct(work, badIdx); err != nil {
+		return fmt.Errorf("reconstruct %v: %v", badIdx, err)
+	}
+	if got := digest(work); got != v.Digest {
+		return fmt.Errorf("reconstruct %v digest %s, expected %s", badIdx, got, v.Digest)
+	}
+
+	if v.Config.EnableGF16 || tactic.N+tactic.M+tactic.L > 256 {
+		return nil
+	}
+	parity := make([][]byte, tactic.M+tactic.L)
+	for i := range parity {
+		parity[i] = make([]byte, v.ShardSize)
+	}
+	for i := tactic.N - 1; i >= 0; i-- {
+		if err = encoder.EncodeIdx(shards[i], i, parity); err != nil {
+			return fmt.Errorf("encode idx %d: %v", i, err)
+		}
+	}
+	if got := digest(append(shards[:tactic.N:tactic.N], parity...)); got != v.Digest {
+		return fmt.Errorf("encode idx digest %s, expected %s", got, v.Digest)
+	}
+	return nil
+}
+
+// Conformance checks all the golden vectors.
+func Conformance(t *testing.T) {
+	t.Logf("generic kernels %v", ec.GenericKernels())
+	for _, v := range Vectors() {
+		v := v
+		t.Run(v.Name, func(t *testing.T) {
+			if err := Check(v); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
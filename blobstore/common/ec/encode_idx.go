@@ -0,0 +1,64 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"github.com/klauspost/reedsolomon"
+)
+
+func (e *encoder) EncodeIdx(dataShard []byte, idx int, parity [][]byte) error {
+	if len(parity) > 0 {
+		dataShard = e.padShortShard(dataShard, len(parity[0]))
+	}
+	e.pool.Acquire()
+	defer e.pool.Release()
+	// the leopard engine of GF(2^16) returns reedsolomon.ErrNotSupported
+	return e.engine.EncodeIdx(dataShard, idx, parity)
+}
+
+func (e *lrcEncoder) EncodeIdx(dataShard []byte, idx int, parity [][]byte) error {
+	engine, err := e.directEngine()
+	if err != nil {
+		return err
+	}
+	if len(parity) > 0 {
+		dataShard = e.padShortShard(dataShard, len(parity[0]))
+	}
+	e.pool.Acquire()
+	defer e.pool.Release()
+	return engine.EncodeIdx(dataShard, idx, parity)
+}
+
+// directEngine returns the engine coding all the parity shards of LRC over the data shards
+// directly. The local parity shards are linear in the data shards through the global parity
+// shards in the az, so the coefficients are got by encoding the unit vectors of the data shards.
+func (e *lrcEncoder) directEngine() (reedsolomon.Encoder, error) {
+	e.directOnce.Do(func() {
+		tactic := e.CodeMode
+		if e.EnableGF16 || tactic.N+tactic.M+tactic.L > maxGF8Shards {
+			e.directErr = reedsolomon.ErrNotSupported
+			return
+		}
+		rows, err := encodedMatrix(e.Encode, tactic.N, tactic.M+tactic.L)
+		if err != nil {
+			e.directErr = err
+			return
+		}
+		opts := append(e.engineOptions(), reedsolomon.WithInversionCache(false),
+			reedsolomon.WithCustomMatrix(rows))
+		e.direct, e.directErr = newEngine(tactic.N, tactic.M+tactic.L, false, opts...)
+	})
+	return e.direct, e.directErr
+}
@@ -35,18 +35,44 @@ var (
 	ErrInvalidCodeMode = errors.New("invalid code mode")
 	ErrVerify          = errors.New("shards verify failed")
 	ErrInvalidShards   = errors.New("invalid shards")
+	ErrInvalidAzLayout = errors.New("invalid az layout")
+	ErrInvalidMatrix   = errors.New("invalid encoding matrix")
 )
 
 // Encoder normal ec encoder, implements all these functions
 type Encoder interface {
 	// encode source data into shards, whatever normal ec or LRC
 	Encode(shards [][]byte) error
+	// add the data shard of idx to the parity shards, which are zeroed by the caller before the
+	// first call, so the data shards are encoded one by one as they arrive without buffering the
+	// stripe. The parity shards are the global and then the local parity shards of LRC, every data
+	// shard must be added once, and it is not supported in GF(2^16)
+	EncodeIdx(dataShard []byte, idx int, parity [][]byte) error
+	// update the range of offset and length of the data shards to newData, and the range of the
+	// parity shards in place, whatever normal ec or LRC. The data shards not changed are nil in
+	// newData, so a small overwrite touches only the range of the parity shards rather than
+	// encoding the whole stripe. It is not supported in GF(2^16)
+	UpdateRange(shards, newData [][]byte, offset, length int) error
 	// reconstruct all missing shards, you should assign the missing or bad idx in shards
 	Reconstruct(shards [][]byte, badIdx []int) error
 	// only reconstruct data shards, you should assign the missing or bad idx in shards
 	ReconstructData(shards [][]byte, badIdx []int) error
+	// only reconstruct the shards required, the length of required must be equal to shards,
+	// the required shards are regarded as bad, and the other missing shards should be empty
+	ReconstructSome(shards [][]byte, required []bool) error
+	// prepare the decoder of the bad idx, which reconstructs the stripes of the same failure
+	// pattern without inverting the matrix of every stripe
+	PrepareReconstruct(badIdx []int) (Decoder, error)
+	// get the coefficients of decoding the bad idx from the survivors, the bad shards of LRC are
+	// decoded from the global stripe. So the survivors can be multiplied by the coefficients on the
+	// nodes holding them in the partial repair. It is not supported in GF(2^16)
+	GetDecodeMatrix(badIdx []int) (*DecodeMatrix, error)
 	// split source data into adapted shards size
 	Split(data []byte) ([][]byte, error)
+	// split source data into the shards of dst provided by the caller, such as from the pool,
+	// without allocation. The shards are resliced to the size of Split, so the capacity of every
+	// shard must be enough, and the data shards are copied with the padding zeroed
+	SplitTo(data []byte, dst [][]byte) error
 	// get data shards(No-Copy)
 	GetDataShards(shards [][]byte) [][]byte
 	// get parity shards(No-Copy)
@@ -59,6 +85,24 @@ type Encoder interface {
 	Join(dst io.Writer, shards [][]byte, outSize int) error
 	// verify parity shards with data shards
 	Verify(shards [][]byte) (bool, error)
+	// verify like Verify, but return the mismatched parity shards in the order of index with the
+	// byte offset of the first difference of each, so that the corrupted chunks can be located.
+	// It returns nil if all the parity shards match
+	VerifyDetailed(shards [][]byte) ([]ParityMismatch, error)
+	// validate shards layout in azs, which survives if one az was down
+	ValidateAzLayout(azLayout [][]int) error
+	// release the pooled scratch buffers of verification
+	Release()
+	// get the coefficients of the parity shards over the data shards of the global stripe,
+	// M rows by N columns, nil if coded in GF(2^16)
+	EncodingMatrix() [][]byte
+	// get the fingerprint of the coding, which is changed if any coefficient of the parity
+	// or local parity shards is changed, stable across the versions and the CPU kernels
+	Fingerprint() string
+	// get the encoder of the same config coding a stripe with at most n goroutines, the default
+	// of the engine if n is 0, such as 1 for the background repairs while the foreground writes
+	// use all the CPUs. It shares the concurrency pool, and is created once for the same n
+	WithConcurrency(n int) (Encoder, error)
 }
 
 // Config ec encoder config
@@ -66,12 +110,48 @@ type Config struct {
 	CodeMode     codemode.Tactic
 	EnableVerify bool
 	Concurrency  int
+	// code in GF(2^16) by the leopard engine, which is faster for the wide stripes,
+	// it is always enabled for more than 256 shards
+	EnableGF16 bool
+	// the GFNI kernels are used on the CPUs supporting them, disable them for testing
+	DisableGFNI bool
+	// the M rows by N columns of the parity coefficients of the global stripe, keeps the
+	// shards compatible with the writers of other libraries, such as ISA-L. It is validated
+	// that any N of the shards can decode the data, and is not supported in GF(2^16)
+	EncodingMatrix [][]byte
+	// the most inverted matrices of the failure patterns cached for reconstruction, the least
+	// recently used are evicted. All of them are cached without limit if it is 0
+	InversionCacheLimit int
+	// disable the cache of the inverted matrices, the matrix is inverted at every reconstruction
+	DisableInversionCache bool
+	// split the data without the zero padding of the last data shard, which saves the padding of
+	// the small blobs. The short last data shard is padded in coding, and the reconstructed one is
+	// of the size of the others with the padding, which is cut off by Join of the data size
+	ShortLastShard bool
+	// allocates the buffers of the missing shards in Reconstruct, ReconstructData and ReconstructSome,
+	// such as from the memory pool, which are returned in the shards and released by the caller. The
+	// missing shards of the capacity enough are reused, and the buffers are allocated if it is nil
+	Allocator Allocator
+	// the most goroutines of coding a stripe, the engine decides by the shard size and the CPUs if
+	// it is 0. The encoders of the other goroutines are got by WithConcurrency
+	MaxGoroutines int
+}
+
+// engineOptions returns the options of the engines of the config.
+func (cfg *Config) engineOptions() []reedsolomon.Option {
+	opts := engineOptions(cfg.DisableGFNI)
+	if cfg.MaxGoroutines > 0 {
+		opts = append(opts, reedsolomon.WithMaxGoroutines(cfg.MaxGoroutines))
+	}
+	return opts
 }
 
 type encoder struct {
 	Config
-	pool   limit.Limiter // concurrency pool
-	engine reedsolomon.Encoder
+	pool    limit.Limiter // concurrency pool
+	engine  reedsolomon.Encoder
+	scratch *scratchBuffers
+	derived *derivedEncoders
 }
 
 // NewEncoder return an encoder which support normal EC or LRC
@@ -79,20 +159,51 @@ func NewEncoder(cfg Config) (Encoder, error) {
 	if !cfg.CodeMode.IsValid() {
 		return nil, ErrInvalidCodeMode
 	}
+	// catch the misconfigured code mode at startup, rather than at the first reconstruction
+	if err := validateAzLayout(cfg.CodeMode, cfg.CodeMode.GetECLayoutByAZ()); err != nil {
+		return nil, err
+	}
 	if cfg.Concurrency <= 0 {
 		cfg.Concurrency = defaultConcurrency
 	}
+	if cfg.MaxGoroutines < 0 {
+		cfg.MaxGoroutines = 0
+	}
+	derived := newDerivedEncoders()
+	return derived.get(cfg, count.NewBlockingCount(cfg.Concurrency), cfg.MaxGoroutines)
+}
 
-	engine, err := reedsolomon.New(cfg.CodeMode.N, cfg.CodeMode.M)
+// newEncoder returns the encoder of the config validated, which codes in the concurrency pool.
+func newEncoder(cfg Config, pool limit.Limiter, derived *derivedEncoders) (Encoder, error) {
+	opts := cfg.engineOptions()
+	if cfg.EncodingMatrix != nil {
+		if cfg.EnableGF16 || cfg.CodeMode.N+cfg.CodeMode.M > maxGF8Shards {
+			return nil, ErrInvalidMatrix
+		}
+		if err := validateEncodingMatrix(cfg.EncodingMatrix, cfg.CodeMode.N, cfg.CodeMode.M); err != nil {
+			return nil, err
+		}
+		opts = append(opts, reedsolomon.WithCustomMatrix(cfg.EncodingMatrix))
+	}
+	// the inverted matrices are cached by the decoders of the cached engine
+	opts = append(opts, reedsolomon.WithInversionCache(false))
+	engine, err := newEngine(cfg.CodeMode.N, cfg.CodeMode.M, cfg.EnableGF16, opts...)
 	if err != nil {
 		return nil, err
 	}
-	pool := count.NewBlockingCount(cfg.Concurrency)
+	if _, ok := engine.(*gf16Engine); !ok && !cfg.DisableInversionCache {
+		engine = newCachedEngine(engine, cfg.CodeMode.N, cfg.CodeMode.M, cfg.InversionCacheLimit,
+			cfg.engineOptions()...)
+	}
 
 	if cfg.CodeMode.L != 0 {
 		localN := (cfg.CodeMode.N + cfg.CodeMode.M) / cfg.CodeMode.AZCount
 		localM := cfg.CodeMode.L / cfg.CodeMode.AZCount
-		localEngine, err := reedsolomon.New(localN, localM)
+		localOpts := cfg.engineOptions()
+		if cfg.DisableInversionCache {
+			localOpts = append(localOpts, reedsolomon.WithInversionCache(false))
+		}
+		localEngine, err := newEngine(localN, localM, cfg.EnableGF16, localOpts...)
 		if err != nil {
 			return nil, err
 		}
@@ -101,17 +212,25 @@ func NewEncoder(cfg Config) (Encoder, error) {
 			pool:        pool,
 			engine:      engine,
 			localEngine: localEngine,
+			scratch:     newScratchBuffers(),
+			derived:     derived,
 		}, nil
 	}
 
 	return &encoder{
-		Config: cfg,
-		pool:   pool,
-		engine: engine,
+		Config:  cfg,
+		pool:    pool,
+		engine:  engine,
+		scratch: newScratchBuffers(),
+		derived: derived,
 	}, nil
 }
 
 func (e *encoder) Encode(shards [][]byte) error {
+	if work, padded := e.padShortShards(shards); work != nil {
+		defer restoreShortShards(shards, work, padded)
+		shards = work
+	}
 	e.pool.Acquire()
 	defer e.pool.Release()
 
@@ -119,7 +238,7 @@ func (e *encoder) Encode(shards [][]byte) error {
 		return err
 	}
 	if e.EnableVerify {
-		ok, err := e.engine.Verify(shards)
+		ok, err := e.scratch.verify(e.engine, e.CodeMode.N, shards)
 		if err != nil {
 			return err
 		}
@@ -131,13 +250,36 @@ func (e *encoder) Encode(shards [][]byte) error {
 }
 
 func (e *encoder) Verify(shards [][]byte) (bool, error) {
+	if work, padded := e.padShortShards(shards); work != nil {
+		defer restoreShortShards(shards, work, padded)
+		shards = work
+	}
+	e.pool.Acquire()
+	defer e.pool.Release()
+	return e.scratch.verify(e.engine, e.CodeMode.N, shards)
+}
+
+func (e *encoder) VerifyDetailed(shards [][]byte) ([]ParityMismatch, error) {
+	if work, padded := e.padShortShards(shards); work != nil {
+		defer restoreShortShards(shards, work, padded)
+		shards = work
+	}
 	e.pool.Acquire()
 	defer e.pool.Release()
-	return e.engine.Verify(shards)
+	return e.scratch.verifyDetailed(e.engine, e.CodeMode.N, shards)
+}
+
+func (e *encoder) Release() {
+	e.scratch.release()
 }
 
 func (e *encoder) Reconstruct(shards [][]byte, badIdx []int) error {
 	initBadShards(shards, badIdx)
+	e.preallocShards(shards, len(shards), nil)
+	if work, padded := e.padShortShards(shards); work != nil {
+		defer restoreShortShards(shards, work, padded)
+		shards = work
+	}
 	e.pool.Acquire()
 	defer e.pool.Release()
 	return e.engine.Reconstruct(shards)
@@ -145,13 +287,45 @@ func (e *encoder) Reconstruct(shards [][]byte, badIdx []int) error {
 
 func (e *encoder) ReconstructData(shards [][]byte, badIdx []int) error {
 	initBadShards(shards, badIdx)
+	e.preallocShards(shards, e.CodeMode.N, nil)
+	if work, padded := e.padShortShards(shards); work != nil {
+		defer restoreShortShards(shards, work, padded)
+		shards = work
+	}
 	e.pool.Acquire()
 	defer e.pool.Release()
 	return e.engine.ReconstructData(shards)
 }
 
+func (e *encoder) ReconstructSome(shards [][]byte, required []bool) error {
+	if len(required) != len(shards) {
+		return ErrInvalidShards
+	}
+	initBadShards(shards, requiredIdx(required))
+	e.preallocShards(shards, len(shards), required)
+	if work, padded := e.padShortShards(shards); work != nil {
+		defer restoreShortShards(shards, work, padded)
+		shards = work
+	}
+	e.pool.Acquire()
+	defer e.pool.Release()
+	return reconstructSome(e.engine, e.CodeMode.N, shards, required)
+}
+
+func (e *encoder) PrepareReconstruct(badIdx []int) (Decoder, error) {
+	d, err := newDecoder(e.pool, e.engine, e.CodeMode.N, e.CodeMode.M, badIdx, false, e.engineOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	return e.shortShardDecoder(d, badIdx), nil
+}
+
 func (e *encoder) Split(data []byte) ([][]byte, error) {
-	return e.engine.Split(data)
+	shards, err := e.engine.Split(data)
+	if err != nil || !e.ShortLastShard {
+		return shards, err
+	}
+	return trimLastShard(shards, e.CodeMode.N, len(data)), nil
 }
 
 func (e *encoder) GetDataShards(shards [][]byte) [][]byte {
@@ -187,6 +361,45 @@ func initBadShards(shards [][]byte, badIdx []int) {
 	}
 }
 
+func requiredIdx(required []bool) []int {
+	idx := make([]int, 0, len(required))
+	for i, r := range required {
+		if r {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// reconstructSome reconstructs the required shards of a stripe in place, and leaves the others
+// missing. The parity shards are coded from all the data shards, so if any of them is required,
+// the missing data shards not required are reconstructed in the temporary buffers.
+func reconstructSome(engine reedsolomon.Encoder, dataShards int, shards [][]byte, required []bool) error {
+	parityRequired := false
+	for _, r := range required[dataShards:] {
+		parityRequired = parityRequired || r
+	}
+	if !parityRequired {
+		return engine.ReconstructSome(shards, required)
+	}
+
+	work := make([][]byte, len(shards))
+	for i, shard := range shards {
+		if len(shard) != 0 || required[i] {
+			work[i] = shard
+		}
+	}
+	if err := engine.Reconstruct(work); err != nil {
+		return err
+	}
+	for i := range shards {
+		if required[i] {
+			shards[i] = work[i]
+		}
+	}
+	return nil
+}
+
 func shardSize(shards [][]byte) int {
 	for _, shard := range shards {
 		if len(shard) != 0 {
@@ -17,14 +17,18 @@ package ec
 import (
 	"bytes"
 	"crypto/rand"
+	"errors"
 	mrand "math/rand"
 	"reflect"
+	"sync"
 	"testing"
 
+	"github.com/klauspost/reedsolomon"
 	"github.com/stretchr/testify/require"
 
 	"github.com/cubefs/cubefs/blobstore/common/codemode"
 	"github.com/cubefs/cubefs/blobstore/util/bytespool"
+	"github.com/cubefs/cubefs/blobstore/util/limit"
 )
 
 var srcData = []byte("Hello world")
@@ -305,3 +309,845 @@ func testLrcReconstruct(t *testing.T, cm codemode.CodeMode) {
 		}
 	}
 }
+
+func TestEncoderReconstructSome(t *testing.T) {
+	for _, cm := range codemode.GetECCodeModes() {
+		testEncoderReconstructSome(t, cm)
+	}
+}
+
+func testEncoderReconstructSome(t *testing.T, cm codemode.CodeMode) {
+	tactic := cm.Tactic()
+	encoder, err := NewEncoder(Config{CodeMode: tactic})
+	require.NoError(t, err)
+
+	data := make([]byte, (1<<12)+mrand.Intn(1<<12))
+	rand.Read(data)
+	shards, err := encoder.Split(data)
+	require.NoError(t, err)
+	require.NoError(t, encoder.Encode(shards))
+	origin := copyShards(shards)
+
+	require.ErrorIs(t, encoder.ReconstructSome(shards, make([]bool, len(shards)-1)), ErrInvalidShards)
+
+	// the other missing shards are left missing
+	for idx := 0; idx < len(shards); idx++ {
+		shards = copyShards(origin)
+		missing := (idx + 1) % len(shards)
+		shards[missing] = nil
+		required := make([]bool, len(shards))
+		required[idx] = true
+		bytespool.Zero(shards[idx])
+
+		require.NoError(t, encoder.ReconstructSome(shards, required))
+		require.Equal(t, origin[idx], shards[idx], "idx: %d", idx)
+		require.Nil(t, shards[missing])
+	}
+
+	// the bad data shard in a stripe of too many missing shards
+	shards = copyShards(origin)
+	required := make([]bool, len(shards))
+	required[0] = true
+	for idx := 1; idx <= tactic.M+tactic.L; idx++ {
+		shards[idx] = nil
+	}
+	require.Error(t, encoder.ReconstructSome(shards, required))
+
+	// use local ec reconstruct
+	for azIdx := 0; azIdx < tactic.AZCount && tactic.L > 0; azIdx++ {
+		locals, _, _ := tactic.LocalStripeInAZ(azIdx)
+		localShards := make([][]byte, len(locals))
+		for localIdx, idx := range locals {
+			localShards[localIdx] = append([]byte{}, origin[idx]...)
+		}
+		required := make([]bool, len(locals))
+		required[0] = true
+		bytespool.Zero(localShards[0])
+		require.NoError(t, encoder.ReconstructSome(localShards, required))
+		require.Equal(t, origin[locals[0]], localShards[0])
+	}
+}
+
+func TestEncoderValidateAzLayout(t *testing.T) {
+	for _, mode := range codemode.GetECCodeModes() {
+		encoder, err := NewEncoder(Config{CodeMode: mode.Tactic()})
+		require.NoError(t, err)
+		require.NoError(t, encoder.ValidateAzLayout(mode.T().GetECLayoutByAZ()), mode.String())
+	}
+
+	{
+		encoder, err := NewEncoder(Config{CodeMode: codemode.EC6P6.Tactic()})
+		require.NoError(t, err)
+		for _, azLayout := range [][][]int{
+			{{0, 1, 6, 7}, {2, 3, 8, 9}},                 // az count
+			{{0, 1, 6, 7}, {2, 3, 8, 9}, {4, 5, 10, 12}}, // out of range
+			{{0, 1, 6, 7}, {2, 3, 8, 9}, {4, 5, 10, 10}}, // placed twice
+			{{0, 1, 6, 7}, {2, 3, 8, 9}, {4, 5, 10}},     // not covered
+			{{0, 1, 2, 3, 4, 5, 6}, {7, 8, 9}, {10, 11}}, // az down
+		} {
+			require.ErrorIs(t, encoder.ValidateAzLayout(azLayout), ErrInvalidAzLayout, azLayout)
+		}
+	}
+	{
+		encoder, err := NewEncoder(Config{CodeMode: codemode.EC6P10L2.Tactic()})
+		require.NoError(t, err)
+		// local parity shards are swapped
+		azLayout := codemode.EC6P10L2.T().GetECLayoutByAZ()
+		azLayout[0][8], azLayout[1][8] = azLayout[1][8], azLayout[0][8]
+		require.ErrorIs(t, encoder.ValidateAzLayout(azLayout), ErrInvalidAzLayout)
+		// data shard moves to the other az
+		azLayout = codemode.EC6P10L2.T().GetECLayoutByAZ()
+		azLayout[1] = append(azLayout[1], azLayout[0][0])
+		azLayout[0] = azLayout[0][1:]
+		require.ErrorIs(t, encoder.ValidateAzLayout(azLayout), ErrInvalidAzLayout)
+	}
+}
+
+func TestEncoderVerifyScratch(t *testing.T) {
+	for _, cm := range []codemode.CodeMode{codemode.EC6P6, codemode.EC6P10L2} {
+		encoder, err := NewEncoder(Config{CodeMode: cm.Tactic()})
+		require.NoError(t, err)
+		shards, err := encoder.Split(srcData)
+		require.NoError(t, err)
+		require.NoError(t, encoder.Encode(shards))
+
+		for range [3]struct{}{} {
+			ok, err := encoder.Verify(shards)
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+		if len(encoder.GetLocalShards(shards)) > 0 {
+			ok, err := encoder.Verify(encoder.GetShardsInIdc(shards, 0))
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+
+		// broken parity is detected with the pooled scratch
+		parity := encoder.GetParityShards(shards)
+		parity[0][0]++
+		ok, err := encoder.Verify(shards)
+		require.NoError(t, err)
+		require.False(t, ok)
+		parity[0][0]--
+
+		// the data shards are not kept in the pooled scratch
+		encoder.Release()
+		ok, err = encoder.Verify(shards)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		// invalid shards are verified by the engine
+		invalidShards := copyShards(shards)
+		invalidShards[1] = invalidShards[1][:1]
+		_, err = encoder.Verify(invalidShards)
+		require.Error(t, err)
+	}
+}
+
+func TestEncoderVerifyDetailed(t *testing.T) {
+	for _, cm := range []codemode.CodeMode{codemode.EC6P6, codemode.EC6P10L2} {
+		tactic := cm.Tactic()
+		encoder, err := NewEncoder(Config{CodeMode: tactic})
+		require.NoError(t, err)
+		data := make([]byte, 1024*tactic.N)
+		rand.Read(data)
+		shards, err := encoder.Split(data)
+		require.NoError(t, err)
+		require.NoError(t, encoder.Encode(shards))
+
+		mismatches, err := encoder.VerifyDetailed(shards)
+		require.NoError(t, err)
+		require.Nil(t, mismatches)
+
+		parity := encoder.GetParityShards(shards)
+		parity[3][7]++
+		parity[1][100]++
+		mismatches, err = encoder.VerifyDetailed(shards)
+		require.NoError(t, err)
+		require.Equal(t, []ParityMismatch{{Index: tactic.N + 1, Offset: 100}, {Index: tactic.N + 3, Offset: 7}}, mismatches)
+		parity[3][7]--
+		parity[1][100]--
+
+		// the corrupted data shard mismatches all the parity shards at the same offset
+		shards[0][9]++
+		mismatches, err = encoder.VerifyDetailed(shards)
+		require.NoError(t, err)
+		require.Equal(t, tactic.M, len(mismatches))
+		for i, m := range mismatches {
+			require.Equal(t, ParityMismatch{Index: tactic.N + i, Offset: 9}, m)
+		}
+		shards[0][9]--
+
+		if tactic.L > 0 {
+			locals := encoder.GetLocalShards(shards)
+			locals[1][50]++
+			mismatches, err = encoder.VerifyDetailed(shards)
+			require.NoError(t, err)
+			require.Equal(t, []ParityMismatch{{Index: tactic.N + tactic.M + 1, Offset: 50}}, mismatches)
+
+			// the indexes of the local stripe are of the shards verified
+			azShards := encoder.GetShardsInIdc(shards, 1)
+			mismatches, err = encoder.VerifyDetailed(azShards)
+			require.NoError(t, err)
+			require.Equal(t, []ParityMismatch{{Index: len(azShards) - 1, Offset: 50}}, mismatches)
+			locals[1][50]--
+		}
+
+		invalidShards := copyShards(shards)
+		invalidShards[1] = invalidShards[1][:1]
+		_, err = encoder.VerifyDetailed(invalidShards)
+		require.Error(t, err)
+	}
+}
+
+func BenchmarkEncoderVerify(b *testing.B) {
+	encoder, err := NewEncoder(Config{CodeMode: codemode.EC6P6.Tactic()})
+	require.NoError(b, err)
+	data := make([]byte, 1<<20)
+	rand.Read(data)
+	shards, err := encoder.Split(data)
+	require.NoError(b, err)
+	require.NoError(b, encoder.Encode(shards))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		encoder.Verify(shards)
+	}
+}
+
+// cauchyMatrix returns the parity rows of the cauchy matrix generated by gf_gen_cauchy1_matrix of ISA-L.
+func cauchyMatrix(dataShards, parityShards int) [][]byte {
+	matrix := make([][]byte, parityShards)
+	for i := range matrix {
+		matrix[i] = make([]byte, dataShards)
+		for j := range matrix[i] {
+			matrix[i][j] = gfInv(byte((dataShards + i) ^ j))
+		}
+	}
+	return matrix
+}
+
+func TestEncoderWithConcurrency(t *testing.T) {
+	for _, cm := range []codemode.CodeMode{codemode.EC6P6, codemode.EC6P10L2} {
+		tactic := cm.Tactic()
+		parallel, err := NewEncoder(Config{CodeMode: tactic})
+		require.NoError(t, err)
+		same, err := parallel.WithConcurrency(0)
+		require.NoError(t, err)
+		require.True(t, same == parallel)
+
+		serial, err := parallel.WithConcurrency(1)
+		require.NoError(t, err)
+		require.False(t, serial == parallel)
+		again, err := serial.WithConcurrency(1)
+		require.NoError(t, err)
+		require.True(t, again == serial)
+		back, err := serial.WithConcurrency(-1)
+		require.NoError(t, err)
+		require.True(t, back == parallel)
+
+		// the shards of the serial encoder are the same as the parallel one
+		data := make([]byte, 1<<20)
+		rand.Read(data)
+		shards, err := parallel.Split(data)
+		require.NoError(t, err)
+		require.NoError(t, parallel.Encode(shards))
+		serialShards, err := serial.Split(data)
+		require.NoError(t, err)
+		require.NoError(t, serial.Encode(serialShards))
+		require.Equal(t, shards, serialShards)
+
+		badIdx := []int{0, tactic.N}
+		for _, i := range badIdx {
+			serialShards[i] = nil
+		}
+		require.NoError(t, serial.Reconstruct(serialShards, badIdx))
+		require.Equal(t, shards, serialShards)
+
+		// the concurrency pool is shared
+		pool := func(e Encoder) limit.Limiter {
+			if lrc, ok := e.(*lrcEncoder); ok {
+				return lrc.pool
+			}
+			return e.(*encoder).pool
+		}
+		require.True(t, pool(serial) == pool(same))
+	}
+
+	parallel, err := NewEncoder(Config{CodeMode: codemode.EC6P6.Tactic()})
+	require.NoError(t, err)
+	progress, err := WithProgress(parallel, 0, func(done, total int) error { return nil }).WithConcurrency(1)
+	require.NoError(t, err)
+	_, ok := progress.(*progressEncoder)
+	require.True(t, ok)
+}
+
+func TestEncoderGetDecodeMatrix(t *testing.T) {
+	for _, cm := range []codemode.CodeMode{codemode.EC6P6, codemode.EC6P10L2} {
+		tactic := cm.Tactic()
+		encoder, err := NewEncoder(Config{CodeMode: tactic})
+		require.NoError(t, err)
+		data := make([]byte, 1024*tactic.N)
+		rand.Read(data)
+		shards, err := encoder.Split(data)
+		require.NoError(t, err)
+		require.NoError(t, encoder.Encode(shards))
+
+		badIdxes := [][]int{{0}, {tactic.N}, {tactic.M - 1, 1, 0, tactic.N + 1}}
+		if tactic.L > 0 {
+			badIdxes = append(badIdxes, []int{2, tactic.N + tactic.M, tactic.N + tactic.M + 1})
+		}
+		for _, badIdx := range badIdxes {
+			matrix, err := encoder.GetDecodeMatrix(badIdx)
+			require.NoError(t, err)
+			require.Equal(t, tactic.N, len(matrix.Survivors))
+			require.Equal(t, len(matrix.Missing), len(matrix.Rows))
+			for r, i := range matrix.Missing {
+				require.Contains(t, badIdx, i)
+				require.NotContains(t, matrix.Survivors, i)
+				// the survivors multiplied by the coefficients are summed up to the missing shard
+				decoded := make([]byte, len(shards[i]))
+				for c, survivor := range matrix.Survivors {
+					for k, b := range shards[survivor] {
+						decoded[k] ^= gfMul(matrix.Rows[r][c], b)
+					}
+				}
+				require.Equal(t, shards[i], decoded)
+			}
+		}
+
+		// the bad shards are more than the global parity shards
+		tooMany := make([]int, tactic.M+1)
+		for i := range tooMany {
+			tooMany[i] = i
+		}
+		_, err = encoder.GetDecodeMatrix(tooMany)
+		require.ErrorIs(t, err, reedsolomon.ErrTooFewShards)
+		_, err = encoder.GetDecodeMatrix([]int{tactic.N + tactic.M + tactic.L})
+		require.ErrorIs(t, err, ErrInvalidShards)
+	}
+
+	encoder, err := NewEncoder(Config{CodeMode: codemode.EC6P6.Tactic(), EnableGF16: true})
+	require.NoError(t, err)
+	_, err = encoder.GetDecodeMatrix([]int{0})
+	require.ErrorIs(t, err, reedsolomon.ErrNotSupported)
+}
+
+func TestDecodeMatrixPartialReconstruct(t *testing.T) {
+	for _, cm := range []codemode.CodeMode{codemode.EC6P6, codemode.EC6P10L2} {
+		tactic := cm.Tactic()
+		encoder, err := NewEncoder(Config{CodeMode: tactic})
+		require.NoError(t, err)
+		data := make([]byte, 1024*tactic.N)
+		rand.Read(data)
+		shards, err := encoder.Split(data)
+		require.NoError(t, err)
+		require.NoError(t, encoder.Encode(shards))
+
+		// the failures of the stripe up to the global parity shards
+		allParity := make([]int, tactic.M)
+		for i := range allParity {
+			allParity[i] = i * (tactic.N + tactic.M) / tactic.M
+		}
+		badIdxes := [][]int{{1}, {1, tactic.N}, allParity}
+		if tactic.L > 0 {
+			badIdxes = append(badIdxes, []int{0, tactic.N + tactic.M, tactic.N + tactic.M + 1})
+		}
+		for _, badIdx := range badIdxes {
+			matrix, err := encoder.GetDecodeMatrix(badIdx)
+			require.NoError(t, err)
+
+			// the survivors are split into two azs, whose partial sums of all the missing shards
+			// are computed in parallel and combined
+			azs := [2][][]byte{make([][]byte, len(shards)), make([][]byte, len(shards))}
+			for c, s := range matrix.Survivors {
+				azs[c%2][s] = shards[s]
+			}
+			partials := [2][][]byte{}
+			var wg sync.WaitGroup
+			for a := range azs {
+				partials[a] = make([][]byte, len(matrix.Missing))
+				for r := range partials[a] {
+					partials[a][r] = make([]byte, len(shards[0]))
+				}
+				wg.Add(1)
+				go func(a int) {
+					defer wg.Done()
+					require.NoError(t, matrix.PartialReconstructTo(azs[a], partials[a]))
+				}(a)
+			}
+			wg.Wait()
+			for r, i := range matrix.Missing {
+				for k, b := range partials[1][r] {
+					partials[0][r][k] ^= b
+				}
+				require.Equal(t, shards[i], partials[0][r])
+			}
+
+			// accumulated into the missing shards of the stripe in place
+			stripe := make([][]byte, len(shards))
+			for _, s := range matrix.Survivors {
+				stripe[s] = shards[s]
+			}
+			for _, i := range matrix.Missing {
+				stripe[i] = make([]byte, len(shards[i]))
+			}
+			require.NoError(t, matrix.PartialReconstruct(stripe))
+			for _, i := range matrix.Missing {
+				require.Equal(t, shards[i], stripe[i])
+			}
+
+			combined := partials[0]
+			require.ErrorIs(t, matrix.PartialReconstructTo(shards, combined[:len(combined)-1]), ErrInvalidShards)
+			combined[0] = combined[0][1:]
+			require.ErrorIs(t, matrix.PartialReconstructTo(shards, combined), reedsolomon.ErrShardSize)
+		}
+	}
+
+	// the matrix got elsewhere is validated
+	matrix := &DecodeMatrix{Missing: []int{0}, Survivors: []int{1, 2}, Rows: [][]byte{{1}}}
+	require.ErrorIs(t, matrix.PartialReconstructTo(nil, [][]byte{make([]byte, 8)}), ErrInvalidShards)
+}
+
+func TestEncoderEncodingMatrix(t *testing.T) {
+	tactic := codemode.EC6P3.Tactic()
+	matrix := cauchyMatrix(tactic.N, tactic.M)
+	require.NoError(t, validateEncodingMatrix(matrix, tactic.N, tactic.M))
+
+	encoder, err := NewEncoder(Config{CodeMode: tactic, EncodingMatrix: matrix})
+	require.NoError(t, err)
+	data := make([]byte, 1<<10*tactic.N+1)
+	rand.Read(data)
+	shards, err := encoder.Split(data)
+	require.NoError(t, err)
+	require.NoError(t, encoder.Encode(shards))
+
+	// the parity shards are the matrix times the data shards
+	for i, row := range matrix {
+		expected := make([]byte, len(shards[0]))
+		for j, c := range row {
+			for b := range expected {
+				expected[b] ^= gfMul(c, shards[j][b])
+			}
+		}
+		require.Equal(t, expected, shards[tactic.N+i])
+	}
+
+	origin := copyShards(shards)
+	badIdx := []int{0, 4, tactic.N + 1}
+	for _, i := range badIdx {
+		shards[i] = nil
+	}
+	require.NoError(t, encoder.Reconstruct(shards, badIdx))
+	require.Equal(t, origin, shards)
+	decoder, err := encoder.PrepareReconstruct(badIdx)
+	require.NoError(t, err)
+	for _, i := range badIdx {
+		shards[i] = nil
+	}
+	require.NoError(t, decoder.Reconstruct(shards))
+	require.Equal(t, origin, shards)
+
+	// the same as the stream encoder
+	stream, err := NewStreamEncoder(StreamConfig{CodeMode: tactic, EncodingMatrix: matrix})
+	require.NoError(t, err)
+	ok, err := stream.Verify(shardReaders(shards))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// the default encoder is not compatible with the matrix
+	encoder, err = NewEncoder(Config{CodeMode: tactic})
+	require.NoError(t, err)
+	ok, err = encoder.Verify(shards)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestEncoderInvalidEncodingMatrix(t *testing.T) {
+	tactic := codemode.EC6P3.Tactic()
+	for _, cfg := range []Config{
+		{CodeMode: tactic, EncodingMatrix: cauchyMatrix(tactic.N, tactic.M-1)},
+		{CodeMode: tactic, EncodingMatrix: cauchyMatrix(tactic.N-1, tactic.M)},
+		{CodeMode: tactic, EncodingMatrix: cauchyMatrix(tactic.N, tactic.M), EnableGF16: true},
+		{CodeMode: codemode.EC15P12.Tactic(), EncodingMatrix: cauchyMatrix(15, 12)},
+	} {
+		_, err := NewEncoder(cfg)
+		require.ErrorIs(t, err, ErrInvalidMatrix)
+	}
+
+	// a zero coefficient
+	matrix := cauchyMatrix(tactic.N, tactic.M)
+	matrix[1][2] = 0
+	err := validateEncodingMatrix(matrix, tactic.N, tactic.M)
+	require.ErrorIs(t, err, ErrInvalidMatrix)
+	// the same columns make the singular 2x2 submatrices
+	matrix = cauchyMatrix(tactic.N, tactic.M)
+	for i := range matrix {
+		matrix[i][3] = matrix[i][1]
+	}
+	_, err = NewStreamEncoder(StreamConfig{CodeMode: tactic, EncodingMatrix: matrix})
+	require.ErrorIs(t, err, ErrInvalidMatrix)
+}
+
+func TestEncoderFingerprint(t *testing.T) {
+	tactic := codemode.EC6P3.Tactic()
+	encoder, err := NewEncoder(Config{CodeMode: tactic})
+	require.NoError(t, err)
+	matrix := encoder.EncodingMatrix()
+	require.Len(t, matrix, tactic.M)
+	fingerprint := encoder.Fingerprint()
+	require.Len(t, fingerprint, 64)
+
+	// the same coding of the other encoders and kernels
+	other, err := NewEncoder(Config{CodeMode: tactic, DisableGFNI: true, Concurrency: 1})
+	require.NoError(t, err)
+	require.Equal(t, fingerprint, other.Fingerprint())
+	other, err = NewEncoder(Config{CodeMode: tactic, EncodingMatrix: matrix})
+	require.NoError(t, err)
+	require.Equal(t, matrix, other.EncodingMatrix())
+	require.Equal(t, fingerprint, other.Fingerprint())
+
+	// the other coefficients
+	other, err = NewEncoder(Config{CodeMode: tactic, EncodingMatrix: cauchyMatrix(tactic.N, tactic.M)})
+	require.NoError(t, err)
+	require.Equal(t, cauchyMatrix(tactic.N, tactic.M), other.EncodingMatrix())
+	require.NotEqual(t, fingerprint, other.Fingerprint())
+	other, err = NewEncoder(Config{CodeMode: tactic, EnableGF16: true})
+	require.NoError(t, err)
+	require.Nil(t, other.EncodingMatrix())
+	require.NotEqual(t, fingerprint, other.Fingerprint())
+
+	// the same fingerprint of the code modes of the same stripe only
+	fingerprints := make(map[string]codemode.CodeMode)
+	for _, cm := range codemode.GetECCodeModes() {
+		encoder, err := NewEncoder(Config{CodeMode: cm.Tactic()})
+		require.NoError(t, err)
+		fingerprint := encoder.Fingerprint()
+		require.NotEmpty(t, fingerprint)
+		if mode, ok := fingerprints[fingerprint]; ok {
+			a, b := mode.Tactic(), cm.Tactic()
+			require.Equal(t, []int{a.N, a.M, a.L, a.AZCount}, []int{b.N, b.M, b.L, b.AZCount},
+				"fingerprint of %s and %s", mode.String(), cm.String())
+		}
+		fingerprints[fingerprint] = cm
+	}
+}
+
+func TestEncoderEncodeIdx(t *testing.T) {
+	for _, cfg := range []Config{
+		{CodeMode: codemode.EC6P6.Tactic()},
+		{CodeMode: codemode.EC6P3.Tactic(), EncodingMatrix: cauchyMatrix(6, 3)},
+		{CodeMode: codemode.EC6P10L2.Tactic()},
+		{CodeMode: codemode.EC16P20L2.Tactic(), EnableVerify: true},
+	} {
+		tactic := cfg.CodeMode
+		encoder, err := NewEncoder(cfg)
+		require.NoError(t, err)
+		for _, size := range []int{1, 1 << 10, 1<<16 + 3} {
+			shards := make([][]byte, tactic.N+tactic.M+tactic.L)
+			for i := range shards {
+				shards[i] = make([]byte, size)
+				if i < tactic.N {
+					rand.Read(shards[i])
+				}
+			}
+			require.NoError(t, encoder.Encode(shards))
+
+			// the data shards arrive in any order
+			parity := make([][]byte, tactic.M+tactic.L)
+			for i := range parity {
+				parity[i] = make([]byte, size)
+			}
+			for _, idx := range mrand.Perm(tactic.N) {
+				require.NoError(t, encoder.EncodeIdx(shards[idx], idx, parity))
+			}
+			require.Equal(t, shards[tactic.N:], parity, "%+v size %d", tactic, size)
+		}
+
+		parity := make([][]byte, tactic.M+tactic.L)
+		for i := range parity {
+			parity[i] = make([]byte, 8)
+		}
+		require.Error(t, encoder.EncodeIdx(make([]byte, 8), tactic.N, parity))
+		require.Error(t, encoder.EncodeIdx(make([]byte, 4), 0, parity))
+		require.Error(t, encoder.EncodeIdx(make([]byte, 8), 0, parity[1:]))
+	}
+
+	encoder, err := NewEncoder(Config{CodeMode: codemode.EC6P6.Tactic(), EnableGF16: true})
+	require.NoError(t, err)
+	parity := make([][]byte, 6)
+	for i := range parity {
+		parity[i] = make([]byte, 64)
+	}
+	require.Error(t, encoder.EncodeIdx(make([]byte, 64), 0, parity))
+}
+
+func TestEncoderUpdateRange(t *testing.T) {
+	for _, cfg := range []Config{
+		{CodeMode: codemode.EC6P6.Tactic()},
+		{CodeMode: codemode.EC6P3.Tactic(), EncodingMatrix: cauchyMatrix(6, 3), EnableVerify: true},
+		{CodeMode: codemode.EC6P10L2.Tactic(), EnableVerify: true},
+	} {
+		tactic := cfg.CodeMode
+		encoder, err := NewEncoder(cfg)
+		require.NoError(t, err)
+		size := 1<<16 + 3
+		shards := make([][]byte, tactic.N+tactic.M+tactic.L)
+		for i := range shards {
+			shards[i] = make([]byte, size)
+			if i < tactic.N {
+				rand.Read(shards[i])
+			}
+		}
+		require.NoError(t, encoder.Encode(shards))
+
+		for _, r := range [][2]int{{0, 1}, {100, 4096}, {size - 7, 7}, {0, size}} {
+			offset, length := r[0], r[1]
+			newData := make([][]byte, tactic.N)
+			for _, idx := range mrand.Perm(tactic.N)[:2] {
+				newData[idx] = make([]byte, length)
+				rand.Read(newData[idx])
+			}
+			require.NoError(t, encoder.UpdateRange(shards, newData, offset, length))
+
+			expected := copyShards(shards)
+			for i := tactic.N; i < len(expected); i++ {
+				expected[i] = make([]byte, size)
+			}
+			require.NoError(t, encoder.Encode(expected))
+			require.Equal(t, expected, shards, "%+v range %d+%d", tactic, offset, length)
+			for idx, data := range newData {
+				if data != nil {
+					require.Equal(t, data, shards[idx][offset:offset+length])
+				}
+			}
+		}
+
+		newData := make([][]byte, tactic.N)
+		newData[0] = make([]byte, 8)
+		require.Error(t, encoder.UpdateRange(shards, newData, size-4, 8))
+		require.Error(t, encoder.UpdateRange(shards, newData, 0, 4))
+		require.Error(t, encoder.UpdateRange(shards, newData[1:], 0, 8))
+		require.Error(t, encoder.UpdateRange(shards[1:], newData, 0, 8))
+	}
+
+	encoder, err := NewEncoder(Config{CodeMode: codemode.EC6P6.Tactic(), EnableGF16: true})
+	require.NoError(t, err)
+	shards := make([][]byte, 12)
+	for i := range shards {
+		shards[i] = make([]byte, 64)
+	}
+	require.Error(t, encoder.UpdateRange(shards, [][]byte{make([]byte, 64), nil, nil, nil, nil, nil}, 0, 64))
+}
+
+func TestEncoderShortLastShard(t *testing.T) {
+	for _, cfg := range []Config{
+		{CodeMode: codemode.EC6P6.Tactic()},
+		{CodeMode: codemode.EC6P6.Tactic(), EnableGF16: true},
+		{CodeMode: codemode.EC6P10L2.Tactic(), EnableVerify: true},
+	} {
+		tactic := cfg.CodeMode
+		padEncoder, err := NewEncoder(cfg)
+		require.NoError(t, err)
+		cfg.ShortLastShard = true
+		encoder, err := NewEncoder(cfg)
+		require.NoError(t, err)
+
+		for _, size := range []int{10, 1000, 1<<16 + 3} {
+			data := make([]byte, size)
+			rand.Read(data)
+			padShards, err := padEncoder.Split(append([]byte{}, data...))
+			require.NoError(t, err)
+			require.NoError(t, padEncoder.Encode(padShards))
+
+			shards, err := encoder.Split(append([]byte{}, data...))
+			require.NoError(t, err)
+			shardSize := len(shards[0])
+			lastSize := size - (tactic.N-1)*shardSize
+			if lastSize <= 0 {
+				lastSize = shardSize
+			}
+			require.Equal(t, lastSize, len(shards[tactic.N-1]), "%+v size %d", tactic, size)
+			// the spare capacity of the data is not the data
+			spare := make([]byte, size, 2*size+(tactic.N+tactic.M+tactic.L)*shardSize)
+			copy(spare, data)
+			spareShards, err := encoder.Split(spare)
+			require.NoError(t, err)
+			require.Equal(t, lastSize, len(spareShards[tactic.N-1]), "%+v size %d", tactic, size)
+			require.NoError(t, encoder.Encode(shards))
+			require.Equal(t, padShards[tactic.N:], shards[tactic.N:])
+			ok, err := encoder.Verify(shards)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			joined := bytes.NewBuffer(nil)
+			require.NoError(t, encoder.Join(joined, shards, size))
+			require.Equal(t, data, joined.Bytes())
+
+			// the short last shard survives
+			work := copyShards(shards)
+			require.NoError(t, encoder.Reconstruct(work, []int{0, tactic.N}))
+			require.Equal(t, shards, work)
+			work = copyShards(shards)
+			require.NoError(t, encoder.ReconstructData(work, []int{0}))
+			require.Equal(t, shards[:tactic.N], work[:tactic.N])
+			decoder, err := encoder.PrepareReconstruct([]int{1, tactic.N + 1})
+			require.NoError(t, err)
+			work = copyShards(shards)
+			require.NoError(t, decoder.Reconstruct(work))
+			require.Equal(t, shards, work)
+
+			// the short last shard is reconstructed with the padding
+			work = copyShards(shards)
+			require.NoError(t, encoder.Reconstruct(work, []int{tactic.N - 1}))
+			require.Equal(t, padShards[tactic.N-1], work[tactic.N-1])
+			joined.Reset()
+			require.NoError(t, encoder.Join(joined, work, size))
+			require.Equal(t, data, joined.Bytes())
+			work = copyShards(shards)
+			required := make([]bool, len(work))
+			required[tactic.N-1] = true
+			require.NoError(t, encoder.ReconstructSome(work, required))
+			require.Equal(t, padShards[tactic.N-1], work[tactic.N-1])
+
+			if cfg.EnableGF16 {
+				continue
+			}
+			parity := make([][]byte, tactic.M+tactic.L)
+			for i := range parity {
+				parity[i] = make([]byte, shardSize)
+			}
+			for _, idx := range mrand.Perm(tactic.N) {
+				require.NoError(t, encoder.EncodeIdx(shards[idx], idx, parity))
+			}
+			require.Equal(t, shards[tactic.N:], parity)
+		}
+	}
+}
+
+func TestEncoderSplitTo(t *testing.T) {
+	for _, cfg := range []Config{
+		{CodeMode: codemode.EC6P6.Tactic()},
+		{CodeMode: codemode.EC6P10L2.Tactic()},
+		{CodeMode: codemode.Tactic{N: 240, M: 80, AZCount: 1, PutQuorum: 300, MinShardSize: 1}},
+		{CodeMode: codemode.EC6P6.Tactic(), ShortLastShard: true},
+	} {
+		tactic := cfg.CodeMode
+		encoder, err := NewEncoder(cfg)
+		require.NoError(t, err)
+		total := tactic.N + tactic.M + tactic.L
+
+		for _, size := range []int{1, 1000, 1<<16 + 3} {
+			data := make([]byte, size)
+			rand.Read(data)
+			expected, err := encoder.Split(append([]byte{}, data...))
+			require.NoError(t, err)
+
+			// the dirty buffers of the pool
+			dst := make([][]byte, total)
+			for i := range dst {
+				dst[i] = make([]byte, len(expected[0])+100)
+				rand.Read(dst[i])
+			}
+			require.NoError(t, encoder.SplitTo(data, dst))
+			require.Equal(t, expected[:tactic.N], dst[:tactic.N], "%+v size %d", tactic, size)
+			for i := tactic.N; i < total; i++ {
+				require.Len(t, dst[i], len(expected[i]))
+			}
+			require.NoError(t, encoder.Encode(dst))
+			require.NoError(t, encoder.Encode(expected))
+			require.Equal(t, expected, dst)
+
+			allocs := testing.AllocsPerRun(10, func() {
+				for i := range dst {
+					dst[i] = dst[i][:cap(dst[i])]
+				}
+				if err := encoder.SplitTo(data, dst); err != nil {
+					panic(err)
+				}
+			})
+			require.Zero(t, allocs)
+		}
+
+		dst := make([][]byte, total)
+		require.ErrorIs(t, encoder.SplitTo(nil, dst), ErrShortData)
+		require.ErrorIs(t, encoder.SplitTo(make([]byte, 10), dst[1:]), ErrInvalidShards)
+		require.ErrorIs(t, encoder.SplitTo(make([]byte, 10*tactic.N), dst), reedsolomon.ErrShardSize)
+	}
+}
+
+type testAllocator struct {
+	bufs [][]byte
+	err  error
+}
+
+func (a *testAllocator) Alloc(size int) ([]byte, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	buf := make([]byte, size, size+10)
+	a.bufs = append(a.bufs, buf)
+	return buf, nil
+}
+
+func (a *testAllocator) owns(shard []byte) bool {
+	for _, buf := range a.bufs {
+		if &buf[:1][0] == &shard[:1][0] {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEncoderAllocator(t *testing.T) {
+	for _, mode := range []codemode.CodeMode{codemode.EC6P6, codemode.EC6P10L2} {
+		tactic := mode.Tactic()
+		allocator := &testAllocator{}
+		encoder, err := NewEncoder(Config{CodeMode: tactic, Allocator: allocator})
+		require.NoError(t, err)
+
+		data := make([]byte, 1<<16+3)
+		rand.Read(data)
+		shards, err := encoder.Split(data)
+		require.NoError(t, err)
+		require.NoError(t, encoder.Encode(shards))
+		expected := copyShards(shards)
+
+		// the missing shards are allocated, the shards of the capacity enough are reused
+		work := copyShards(shards)
+		work[0], work[tactic.N] = nil, nil
+		reused := work[1]
+		require.NoError(t, encoder.Reconstruct(work, []int{0, 1, tactic.N}))
+		require.Equal(t, expected, work)
+		require.Len(t, allocator.bufs, 2)
+		require.True(t, allocator.owns(work[0]))
+		require.True(t, allocator.owns(work[tactic.N]))
+		require.Equal(t, &reused[0], &work[1][0])
+
+		allocator.bufs = nil
+		work = copyShards(shards)
+		work[0], work[tactic.N] = nil, nil
+		require.NoError(t, encoder.ReconstructData(work, []int{0, tactic.N}))
+		require.Equal(t, expected[0], work[0])
+		require.Len(t, allocator.bufs, 1)
+		require.True(t, allocator.owns(work[0]))
+
+		allocator.bufs = nil
+		work = copyShards(shards)
+		work[0], work[1] = nil, nil
+		required := make([]bool, len(work))
+		required[1] = true
+		require.NoError(t, encoder.ReconstructSome(work, required))
+		require.Equal(t, expected[1], work[1])
+		require.Len(t, allocator.bufs, 1)
+		require.True(t, allocator.owns(work[1]))
+
+		// allocated by the engine if the allocator fails
+		allocator.bufs, allocator.err = nil, errors.New("no buffer")
+		work = copyShards(shards)
+		work[0] = nil
+		require.NoError(t, encoder.Reconstruct(work, []int{0}))
+		require.Equal(t, expected, work)
+	}
+}
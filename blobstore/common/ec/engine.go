@@ -0,0 +1,153 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"github.com/klauspost/cpuid/v2"
+	"github.com/klauspost/reedsolomon"
+)
+
+const (
+	// shards not larger than it are coded in the calling goroutine without fan-out
+	smallShardSize = 1 << 10
+	// the parallel coding splits shards at the multiples of it for the simd kernels
+	shardAlignSize = 64
+	// the engine of more shards than it is not bytewise
+	maxGF8Shards = 256
+)
+
+// engine codes the shards of irregular size in two parts. The engine of reedsolomon rounds
+// the split size of its goroutines to 64 bytes, so the last goroutine gets an odd remainder
+// which falls back to the slow byte loop, and the small shards of small blobs pay for the
+// goroutines more than the coding. The coding is bytewise independent, so the aligned body
+// of shards is coded by the parallel engine and the unaligned tail by the serial engine,
+// the small shards are coded by the serial engine entirely.
+type engine struct {
+	reedsolomon.Encoder // the parallel engine
+	serial              reedsolomon.Encoder
+}
+
+// GFNISupported reports whether the CPU supports the GFNI kernels of GF(2^8), which are about
+// twice as fast as the AVX2 kernels, such as Intel Ice Lake and newer.
+func GFNISupported() bool {
+	return !genericKernels && cpuid.CPU.Supports(cpuid.AVX512F, cpuid.AVX512DQ, cpuid.GFNI)
+}
+
+// GenericKernels reports whether the engines are built with the generic Go kernels only by the
+// noasm build tag.
+func GenericKernels() bool {
+	return genericKernels
+}
+
+// engineOptions returns the options of the engines, the kernels are dispatched by the CPU
+// features unless they are disabled.
+func engineOptions(disableGFNI bool) []reedsolomon.Option {
+	if genericKernels {
+		return []reedsolomon.Option{
+			reedsolomon.WithSSE2(false), reedsolomon.WithSSSE3(false), reedsolomon.WithAVX2(false),
+			reedsolomon.WithAVX512(false), reedsolomon.WithGFNI(false),
+		}
+	}
+	if disableGFNI {
+		return []reedsolomon.Option{reedsolomon.WithGFNI(false)}
+	}
+	return nil
+}
+
+func newEngine(dataShards, parityShards int, gf16 bool, opts ...reedsolomon.Option) (reedsolomon.Encoder, error) {
+	if gf16 || dataShards+parityShards > maxGF8Shards {
+		return newGF16Engine(dataShards, parityShards, opts...)
+	}
+	parallel, err := reedsolomon.New(dataShards, parityShards, opts...)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := reedsolomon.New(dataShards, parityShards, append(opts, reedsolomon.WithMaxGoroutines(1))...)
+	if err != nil {
+		return nil, err
+	}
+	return &engine{Encoder: parallel, serial: serial}, nil
+}
+
+// regularSize returns the size of shards if all of them are in the same size,
+// the engine returns the errors of irregular shards.
+func regularSize(shards [][]byte) (int, bool) {
+	size := shardSize(shards)
+	if size == 0 {
+		return 0, false
+	}
+	for _, shard := range shards {
+		if len(shard) != size {
+			return 0, false
+		}
+	}
+	return size, true
+}
+
+func subShards(shards [][]byte, start, end int) [][]byte {
+	sub := make([][]byte, len(shards))
+	for i := range shards {
+		sub[i] = shards[i][start:end:end]
+	}
+	return sub
+}
+
+func (e *engine) Encode(shards [][]byte) error {
+	size, ok := regularSize(shards)
+	if !ok {
+		return e.Encoder.Encode(shards)
+	}
+	if size <= smallShardSize {
+		return e.serial.Encode(shards)
+	}
+	aligned := size &^ (shardAlignSize - 1)
+	if aligned == size {
+		return e.Encoder.Encode(shards)
+	}
+	if err := e.Encoder.Encode(subShards(shards, 0, aligned)); err != nil {
+		return err
+	}
+	return e.serial.Encode(subShards(shards, aligned, size))
+}
+
+func (e *engine) Verify(shards [][]byte) (bool, error) {
+	if size := shardSize(shards); size > 0 && size <= smallShardSize {
+		return e.serial.Verify(shards)
+	}
+	return e.Encoder.Verify(shards)
+}
+
+// Reconstruct allocates the missing shards, so only the small shards are reconstructed
+// by the serial engine.
+func (e *engine) Reconstruct(shards [][]byte) error {
+	if size := shardSize(shards); size > 0 && size <= smallShardSize {
+		return e.serial.Reconstruct(shards)
+	}
+	return e.Encoder.Reconstruct(shards)
+}
+
+func (e *engine) ReconstructData(shards [][]byte) error {
+	if size := shardSize(shards); size > 0 && size <= smallShardSize {
+		return e.serial.ReconstructData(shards)
+	}
+	return e.Encoder.ReconstructData(shards)
+}
+
+func (e *engine) ReconstructSome(shards [][]byte, required []bool) error {
+	if size := shardSize(shards); size > 0 && size <= smallShardSize {
+		return e.serial.ReconstructSome(shards, required)
+	}
+	return e.Encoder.ReconstructSome(shards, required)
+}
@@ -0,0 +1,252 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"fmt"
+	mrand "math/rand"
+	"testing"
+
+	"github.com/klauspost/reedsolomon"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+)
+
+// irregular shard sizes around the small size and the split boundaries of simd and goroutines
+func irregularShardSizes(r *mrand.Rand) []int {
+	sizes := []int{1, 7, 31, 33, 63, 65, 127, 129, smallShardSize - 1, smallShardSize, smallShardSize + 1}
+	for _, align := range []int{32, 64, 1 << 10, 1 << 12, 1 << 16} {
+		for _, delta := range []int{-1, 1, 17} {
+			sizes = append(sizes, 3*align+delta)
+		}
+	}
+	for i := 0; i < 20; i++ {
+		sizes = append(sizes, 1+r.Intn(1<<18))
+	}
+	return sizes
+}
+
+func randShards(r *mrand.Rand, total, size int) [][]byte {
+	shards := make([][]byte, total)
+	for i := range shards {
+		shards[i] = make([]byte, size)
+		r.Read(shards[i])
+	}
+	return shards
+}
+
+func TestEngineIrregularShardSize(t *testing.T) {
+	r := mrand.New(mrand.NewSource(1))
+	for _, mode := range []codemode.CodeMode{codemode.EC6P6, codemode.EC15P12, codemode.EC12P4} {
+		tactic := mode.Tactic()
+		n, m := tactic.N, tactic.M
+		e, err := newEngine(n, m, false)
+		require.NoError(t, err)
+		ref, err := reedsolomon.New(n, m)
+		require.NoError(t, err)
+
+		for _, size := range irregularShardSizes(r) {
+			shards := randShards(r, n+m, size)
+			expected := copyShards(shards)
+			require.NoError(t, e.Encode(shards))
+			require.NoError(t, ref.Encode(expected))
+			require.Equal(t, expected, shards, "mode %s size %d", mode, size)
+
+			ok, err := e.Verify(shards)
+			require.NoError(t, err)
+			require.True(t, ok)
+			shards[n+r.Intn(m)][r.Intn(size)]++
+			ok, err = e.Verify(shards)
+			require.NoError(t, err)
+			require.False(t, ok, "mode %s size %d", mode, size)
+
+			shards = copyShards(expected)
+			for _, idx := range r.Perm(n + m)[:m] {
+				shards[idx] = shards[idx][:0]
+			}
+			require.NoError(t, e.Reconstruct(shards))
+			require.Equal(t, expected, shards, "mode %s size %d", mode, size)
+
+			shards = copyShards(expected)
+			for _, idx := range r.Perm(n + m)[:m] {
+				shards[idx] = nil
+			}
+			require.NoError(t, e.ReconstructData(shards))
+			require.Equal(t, expected[:n], shards[:n], "mode %s size %d", mode, size)
+		}
+	}
+}
+
+func TestEngineInvalidShards(t *testing.T) {
+	e, err := newEngine(6, 3, false)
+	require.NoError(t, err)
+
+	shards := make([][]byte, 9)
+	require.ErrorIs(t, e.Encode(shards), reedsolomon.ErrShardNoData)
+	for i := range shards {
+		shards[i] = make([]byte, 100)
+	}
+	shards[8] = make([]byte, 99)
+	require.ErrorIs(t, e.Encode(shards), reedsolomon.ErrShardSize)
+	require.ErrorIs(t, e.Encode(shards[:8]), reedsolomon.ErrTooFewShards)
+}
+
+func TestEncoderIrregularShardSize(t *testing.T) {
+	r := mrand.New(mrand.NewSource(2))
+	for _, mode := range []codemode.CodeMode{codemode.EC6P6, codemode.EC6P10L2} {
+		tactic := mode.Tactic()
+		encoder, err := NewEncoder(Config{CodeMode: tactic, EnableVerify: true})
+		require.NoError(t, err)
+		for _, size := range irregularShardSizes(r) {
+			shards := randShards(r, tactic.N+tactic.M+tactic.L, size)
+			require.NoError(t, encoder.Encode(shards))
+			ok, err := encoder.Verify(shards)
+			require.NoError(t, err)
+			require.True(t, ok, "mode %s size %d", mode, size)
+		}
+	}
+}
+
+func TestEngineDisableGFNI(t *testing.T) {
+	t.Logf("GFNI supported: %v", GFNISupported())
+	r := mrand.New(mrand.NewSource(5))
+	n, m := 12, 4
+	e, err := newEngine(n, m, false)
+	require.NoError(t, err)
+	disabled, err := newEngine(n, m, false, engineOptions(true)...)
+	require.NoError(t, err)
+
+	for _, size := range []int{smallShardSize + 1, 64 << 10, 1<<20 + 7} {
+		shards := randShards(r, n+m, size)
+		expected := copyShards(shards)
+		require.NoError(t, e.Encode(shards))
+		require.NoError(t, disabled.Encode(expected))
+		require.Equal(t, expected, shards, "size %d", size)
+
+		for _, idx := range r.Perm(n + m)[:m] {
+			shards[idx] = nil
+		}
+		require.NoError(t, disabled.Reconstruct(shards))
+		require.Equal(t, expected, shards, "size %d", size)
+	}
+}
+
+func TestGF16EngineIrregularShardSize(t *testing.T) {
+	r := mrand.New(mrand.NewSource(3))
+	n, m := 12, 4
+	e, err := newEngine(n, m, true)
+	require.NoError(t, err)
+
+	for _, size := range irregularShardSizes(r) {
+		shards := randShards(r, n+m, size)
+		require.NoError(t, e.Encode(shards))
+		expected := copyShards(shards)
+
+		ok, err := e.Verify(shards)
+		require.NoError(t, err)
+		require.True(t, ok, "size %d", size)
+		// corrupt the tail or the aligned body
+		shards[n+r.Intn(m)][r.Intn(size)]++
+		ok, err = e.Verify(shards)
+		require.NoError(t, err)
+		require.False(t, ok, "size %d", size)
+
+		shards = copyShards(expected)
+		for _, idx := range r.Perm(n + m)[:m] {
+			shards[idx] = shards[idx][:0]
+		}
+		require.NoError(t, e.Reconstruct(shards))
+		require.Equal(t, expected, shards, "size %d", size)
+
+		shards = copyShards(expected)
+		missing := r.Perm(n + m)[:m]
+		for _, idx := range missing {
+			shards[idx] = nil
+		}
+		require.NoError(t, e.ReconstructData(shards))
+		require.Equal(t, expected[:n], shards[:n], "size %d", size)
+		for _, idx := range missing {
+			if idx >= n {
+				require.Len(t, shards[idx], 0)
+			}
+		}
+	}
+}
+
+func TestGF16EngineWideStripe(t *testing.T) {
+	r := mrand.New(mrand.NewSource(4))
+	n, m := 240, 80
+	e, err := newEngine(n, m, false)
+	require.NoError(t, err)
+
+	// the tail of more than 256 shards can not be coded
+	require.ErrorIs(t, e.Encode(randShards(r, n+m, 100)), reedsolomon.ErrShardSize)
+
+	shards := randShards(r, n+m, 3*shardAlignSize)
+	require.NoError(t, e.Encode(shards))
+	expected := copyShards(shards)
+	for _, idx := range r.Perm(n + m)[:m] {
+		shards[idx] = nil
+	}
+	require.NoError(t, e.Reconstruct(shards))
+	require.Equal(t, expected, shards)
+}
+
+func TestEncoderGF16(t *testing.T) {
+	r := mrand.New(mrand.NewSource(5))
+	for _, mode := range []codemode.CodeMode{codemode.EC6P6, codemode.EC6P10L2} {
+		tactic := mode.Tactic()
+		encoder, err := NewEncoder(Config{CodeMode: tactic, EnableVerify: true, EnableGF16: true})
+		require.NoError(t, err)
+		for _, size := range []int{1, 100, 1 << 10, 100 << 10} {
+			buf, err := NewBuffer(size, tactic, nil)
+			require.NoError(t, err)
+			data := make([]byte, buf.ECDataSize)
+			r.Read(data[:size])
+
+			shards, err := encoder.Split(data)
+			require.NoError(t, err)
+			require.Len(t, shards[0], buf.ShardSize)
+			require.NoError(t, encoder.Encode(shards))
+			expected := copyShards(shards)
+
+			shards[0], shards[tactic.N] = nil, nil
+			require.NoError(t, encoder.Reconstruct(shards, []int{0, tactic.N}))
+			require.Equal(t, expected, shards, "mode %s size %d", mode, size)
+		}
+	}
+}
+
+func BenchmarkEngineSmallShards(b *testing.B) {
+	for _, size := range []int{100, 1000} {
+		tactic := codemode.EC6P6.Tactic()
+		shards := randShards(mrand.New(mrand.NewSource(0)), tactic.N+tactic.M, size)
+		b.Run(fmt.Sprintf("parallel-%d", size), func(b *testing.B) {
+			e, _ := reedsolomon.New(tactic.N, tactic.M)
+			b.SetBytes(int64(size * tactic.N))
+			for i := 0; i < b.N; i++ {
+				e.Encode(shards)
+			}
+		})
+		b.Run(fmt.Sprintf("engine-%d", size), func(b *testing.B) {
+			e, _ := newEngine(tactic.N, tactic.M, false)
+			b.SetBytes(int64(size * tactic.N))
+			for i := 0; i < b.N; i++ {
+				e.Encode(shards)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/klauspost/reedsolomon"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+)
+
+// the size of the shards of the probe stripe, aligned to the blocks of the leopard engine
+const fingerprintShardSize = shardAlignSize
+
+// encodingMatrix returns the parity matrix of the engine, nil if coded in GF(2^16).
+func encodingMatrix(engine reedsolomon.Encoder, dataShards, parityShards int) [][]byte {
+	if _, ok := engine.(*gf16Engine); ok {
+		return nil
+	}
+	matrix, err := parityMatrix(engine, dataShards, parityShards)
+	if err != nil {
+		return nil
+	}
+	return matrix
+}
+
+// fingerprint returns the sha256 of the code mode and the shards of a probe stripe encoded,
+// which covers all the coefficients of the global and local parity shards of any engine.
+func fingerprint(tactic codemode.Tactic, encode func(shards [][]byte) error) string {
+	shards := make([][]byte, tactic.N+tactic.M+tactic.L)
+	for i := range shards {
+		shards[i] = make([]byte, fingerprintShardSize)
+		if i < tactic.N {
+			for j := range shards[i] {
+				shards[i][j] = byte(i*131 + j*7 + 1)
+			}
+		}
+	}
+	if err := encode(shards); err != nil {
+		return ""
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%d+%d+%d/%d", tactic.N, tactic.M, tactic.L, tactic.AZCount)
+	for _, shard := range shards {
+		h.Write(shard)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (e *encoder) EncodingMatrix() [][]byte {
+	return encodingMatrix(e.engine, e.CodeMode.N, e.CodeMode.M)
+}
+
+func (e *encoder) Fingerprint() string {
+	return fingerprint(e.CodeMode, e.Encode)
+}
+
+func (e *lrcEncoder) EncodingMatrix() [][]byte {
+	return encodingMatrix(e.engine, e.CodeMode.N, e.CodeMode.M)
+}
+
+func (e *lrcEncoder) Fingerprint() string {
+	return fingerprint(e.CodeMode, e.Encode)
+}
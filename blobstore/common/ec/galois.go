@@ -0,0 +1,190 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"errors"
+	"fmt"
+)
+
+// the generating polynomial x^8 + x^4 + x^3 + x^2 + 1 of GF(2^8), the same as the engine
+const gfPolynomial = 0x11d
+
+// the square submatrices validated of the encoding matrix at most, which are 1820 of 12+4
+// and 293930 of 12+9, the wider stripes are not validated in a reasonable time
+const maxValidatedSubmatrices = 1 << 20
+
+var errSingularMatrix = errors.New("matrix is singular")
+
+var gfExp, gfLog = gfTables()
+
+func gfTables() (exp [510]byte, log [256]byte) {
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i], exp[i+255] = byte(x), byte(x)
+		log[x] = byte(i)
+		if x <<= 1; x&0x100 != 0 {
+			x ^= gfPolynomial
+		}
+	}
+	return
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// gfInv returns the multiplicative inverse of a, which is not zero.
+func gfInv(a byte) byte {
+	return gfExp[255-int(gfLog[a])]
+}
+
+// gfMulMatrixRow returns the row times the square matrix.
+func gfMulMatrixRow(row []byte, matrix [][]byte) []byte {
+	out := make([]byte, len(matrix[0]))
+	for i, c := range row {
+		if c == 0 {
+			continue
+		}
+		for j, v := range matrix[i] {
+			out[j] ^= gfMul(c, v)
+		}
+	}
+	return out
+}
+
+// gfInvertMatrix returns the inverse of the square matrix by the Gauss-Jordan elimination,
+// the matrix is not modified.
+func gfInvertMatrix(matrix [][]byte) ([][]byte, error) {
+	n := len(matrix)
+	work := make([][]byte, n)
+	for i := range work {
+		work[i] = make([]byte, 2*n)
+		copy(work[i], matrix[i])
+		work[i][n+i] = 1
+	}
+	for col := 0; col < n; col++ {
+		pivot := col
+		for pivot < n && work[pivot][col] == 0 {
+			pivot++
+		}
+		if pivot == n {
+			return nil, errSingularMatrix
+		}
+		work[col], work[pivot] = work[pivot], work[col]
+		if c := work[col][col]; c != 1 {
+			inv := gfInv(c)
+			for j := range work[col] {
+				work[col][j] = gfMul(work[col][j], inv)
+			}
+		}
+		for i := 0; i < n; i++ {
+			if c := work[i][col]; i != col && c != 0 {
+				for j := range work[i] {
+					work[i][j] ^= gfMul(c, work[col][j])
+				}
+			}
+		}
+	}
+	inverted := make([][]byte, n)
+	for i := range inverted {
+		inverted[i] = work[i][n:]
+	}
+	return inverted, nil
+}
+
+// binomial returns n choose k, or the limit if it is over the limit.
+func binomial(n, k, limit int) int {
+	c := 1
+	for i := 1; i <= k; i++ {
+		c = c * (n - k + i) / i
+		if c > limit {
+			return limit
+		}
+	}
+	return c
+}
+
+// forEachCombination calls fn with the sorted k of n indexes in the lexicographical order
+// until fn returns false, the indexes are reused across the calls.
+func forEachCombination(n, k int, fn func(idx []int) bool) bool {
+	idx := make([]int, k)
+	for i := range idx {
+		idx[i] = i
+	}
+	for {
+		if !fn(idx) {
+			return false
+		}
+		i := k - 1
+		for i >= 0 && idx[i] == n-k+i {
+			i--
+		}
+		if i < 0 {
+			return true
+		}
+		idx[i]++
+		for j := i + 1; j < k; j++ {
+			idx[j] = idx[j-1] + 1
+		}
+	}
+}
+
+// validateEncodingMatrix checks that the matrix is of the parity rows by the data columns, and
+// all the square submatrices of it are invertible, which is the same as any data shards of the
+// stripe are able to decode the others. There are C(N+M, M) of the submatrices in total.
+func validateEncodingMatrix(matrix [][]byte, dataShards, parityShards int) error {
+	if len(matrix) != parityShards {
+		return fmt.Errorf("%w: %d rows of %d parity shards", ErrInvalidMatrix, len(matrix), parityShards)
+	}
+	for _, row := range matrix {
+		if len(row) != dataShards {
+			return fmt.Errorf("%w: %d columns of %d data shards", ErrInvalidMatrix, len(row), dataShards)
+		}
+	}
+	if binomial(dataShards+parityShards, parityShards, maxValidatedSubmatrices+1) > maxValidatedSubmatrices {
+		return fmt.Errorf("%w: too many submatrices of %d+%d to validate", ErrInvalidMatrix, dataShards, parityShards)
+	}
+
+	var singular []int
+	for k := 1; k <= parityShards && k <= dataShards; k++ {
+		sub := make([][]byte, k)
+		for i := range sub {
+			sub[i] = make([]byte, k)
+		}
+		forEachCombination(parityShards, k, func(rows []int) bool {
+			return forEachCombination(dataShards, k, func(cols []int) bool {
+				for i, r := range rows {
+					for j, c := range cols {
+						sub[i][j] = matrix[r][c]
+					}
+				}
+				if _, err := gfInvertMatrix(sub); err != nil {
+					singular = append(append([]int{}, rows...), cols...)
+					return false
+				}
+				return true
+			})
+		})
+		if singular != nil {
+			return fmt.Errorf("%w: singular submatrix of rows %v and columns %v",
+				ErrInvalidMatrix, singular[:k], singular[k:])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"github.com/klauspost/reedsolomon"
+)
+
+// gf16Engine codes the shards in GF(2^16) by the leopard engine, which supports up to 65536
+// shards and is faster than GF(2^8) for the wide stripes. The leopard engine codes the shards
+// in blocks of 64 bytes, so the unaligned tail of shards is coded in GF(2^8) by the serial
+// engine, which is not available for more than 256 shards, the shards of them must be aligned.
+type gf16Engine struct {
+	reedsolomon.Encoder // the leopard engine
+	tail                reedsolomon.Encoder
+	dataShards          int
+}
+
+func newGF16Engine(dataShards, parityShards int, opts ...reedsolomon.Option) (reedsolomon.Encoder, error) {
+	leopard, err := reedsolomon.New(dataShards, parityShards, append(opts, reedsolomon.WithLeopardGF16(true))...)
+	if err != nil {
+		return nil, err
+	}
+	e := &gf16Engine{Encoder: leopard, dataShards: dataShards}
+	if dataShards+parityShards <= maxGF8Shards {
+		if e.tail, err = reedsolomon.New(dataShards, parityShards, append(opts, reedsolomon.WithMaxGoroutines(1))...); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// split returns the aligned size of the shards, and whether the tail is coded by the serial engine.
+func (e *gf16Engine) split(size int) (int, bool, error) {
+	aligned := size &^ (shardAlignSize - 1)
+	if aligned == size {
+		return aligned, false, nil
+	}
+	if e.tail == nil {
+		return 0, false, reedsolomon.ErrShardSize
+	}
+	return aligned, true, nil
+}
+
+func (e *gf16Engine) Encode(shards [][]byte) error {
+	size, ok := regularSize(shards)
+	if !ok {
+		return e.Encoder.Encode(shards)
+	}
+	aligned, hasTail, err := e.split(size)
+	if err != nil {
+		return err
+	}
+	if !hasTail {
+		return e.Encoder.Encode(shards)
+	}
+	if aligned > 0 {
+		if err = e.Encoder.Encode(subShards(shards, 0, aligned)); err != nil {
+			return err
+		}
+	}
+	return e.tail.Encode(subShards(shards, aligned, size))
+}
+
+func (e *gf16Engine) Verify(shards [][]byte) (bool, error) {
+	size, ok := regularSize(shards)
+	if !ok {
+		return e.Encoder.Verify(shards)
+	}
+	aligned, hasTail, err := e.split(size)
+	if err != nil {
+		return false, err
+	}
+	if !hasTail {
+		return e.Encoder.Verify(shards)
+	}
+	if aligned > 0 {
+		if ok, err = e.Encoder.Verify(subShards(shards, 0, aligned)); err != nil || !ok {
+			return ok, err
+		}
+	}
+	return e.tail.Verify(subShards(shards, aligned, size))
+}
+
+func (e *gf16Engine) Reconstruct(shards [][]byte) error {
+	return e.reconstruct(shards, false)
+}
+
+func (e *gf16Engine) ReconstructData(shards [][]byte) error {
+	return e.reconstruct(shards, true)
+}
+
+// ReconstructSome reconstructs all the missing data shards, the leopard engine does not
+// reconstruct some of them.
+func (e *gf16Engine) ReconstructSome(shards [][]byte, required []bool) error {
+	return e.reconstruct(shards, true)
+}
+
+func reconstructWith(engine reedsolomon.Encoder, shards [][]byte, dataOnly bool) error {
+	if dataOnly {
+		return engine.ReconstructData(shards)
+	}
+	return engine.Reconstruct(shards)
+}
+
+// reconstruct allocates the missing shards to be reconstructed, and reconstructs the aligned
+// body and the tail of them in place.
+func (e *gf16Engine) reconstruct(shards [][]byte, dataOnly bool) error {
+	size := shardSize(shards)
+	if size == 0 {
+		return reconstructWith(e.Encoder, shards, dataOnly)
+	}
+	aligned, hasTail, err := e.split(size)
+	if err != nil {
+		return err
+	}
+	if !hasTail {
+		return reconstructWith(e.Encoder, shards, dataOnly)
+	}
+
+	end := len(shards)
+	if dataOnly {
+		end = e.dataShards
+	}
+
+	body, tail := make([][]byte, len(shards)), make([][]byte, len(shards))
+	missing := make([]int, 0, len(shards))
+	for i, shard := range shards {
+		if len(shard) == 0 {
+			if i >= end {
+				continue
+			}
+			if cap(shard) < size {
+				shard = make([]byte, size)
+			}
+			shards[i] = shard[:0]
+			missing = append(missing, i)
+			shard = shard[:size]
+			body[i], tail[i] = shard[:0:aligned], shard[aligned:aligned:size]
+			continue
+		}
+		if len(shard) != size {
+			return reedsolomon.ErrShardSize
+		}
+		body[i], tail[i] = shard[:aligned:aligned], shard[aligned:size:size]
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if aligned > 0 {
+		if err = reconstructWith(e.Encoder, body, dataOnly); err != nil {
+			return err
+		}
+	}
+	if err = reconstructWith(e.tail, tail, dataOnly); err != nil {
+		return err
+	}
+	for _, i := range missing {
+		shards[i] = shards[i][:size]
+	}
+	return nil
+}
+
+// Split splits the data into the shards of the same size as the serial engine, the leopard
+// engine rounds the size of shards up to 64 bytes.
+func (e *gf16Engine) Split(data []byte) ([][]byte, error) {
+	if e.tail != nil {
+		return e.tail.Split(data)
+	}
+	return e.Encoder.Split(data)
+}
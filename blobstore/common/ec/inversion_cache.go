@@ -0,0 +1,196 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+// the shards of the decoder cache, the decoders of a shard are locked together
+const decoderCacheShards = 32
+
+// decoderCache caches the decoders of the failure patterns in the shards locked respectively,
+// the lookup of a shard is read locked, so the parallel reconstructions of the same pattern
+// do not serialize. The recency of the decoders is recorded in milliseconds without lock, the
+// least recently used of a shard is evicted approximately if the shard is full.
+type decoderCache struct {
+	limit  int // the most decoders of a shard, 0 without limit
+	shards []*decoderShard
+}
+
+type decoderShard struct {
+	sync.RWMutex
+	decoders map[string]*decoderEntry
+}
+
+type decoderEntry struct {
+	*decoder
+	used int64 // the unix milliseconds of the last use
+}
+
+// newDecoderCache returns the cache of no more than limit decoders, 0 without limit.
+func newDecoderCache(limit int) *decoderCache {
+	n := decoderCacheShards
+	if limit > 0 && limit < n {
+		n = limit
+	}
+	c := &decoderCache{limit: limit / n, shards: make([]*decoderShard, n)}
+	for i := range c.shards {
+		c.shards[i] = &decoderShard{decoders: make(map[string]*decoderEntry)}
+	}
+	return c
+}
+
+func (c *decoderCache) shard(key []byte) *decoderShard {
+	// fnv-1a without the allocation of hash.Hash
+	h := uint32(2166136261)
+	for _, b := range key {
+		h ^= uint32(b)
+		h *= 16777619
+	}
+	return c.shards[h%uint32(len(c.shards))]
+}
+
+func (c *decoderCache) get(key []byte) *decoder {
+	s := c.shard(key)
+	s.RLock()
+	e := s.decoders[string(key)]
+	s.RUnlock()
+	if e == nil {
+		return nil
+	}
+	// the shared entry is written at most once a millisecond
+	if now := time.Now().UnixNano() / 1e6; atomic.LoadInt64(&e.used) != now {
+		atomic.StoreInt64(&e.used, now)
+	}
+	return e.decoder
+}
+
+// add returns the decoder of the key added by the other reconstruction if any.
+func (c *decoderCache) add(key []byte, d *decoder) *decoder {
+	s := c.shard(key)
+	s.Lock()
+	defer s.Unlock()
+	if e, ok := s.decoders[string(key)]; ok {
+		return e.decoder
+	}
+	if c.limit > 0 && len(s.decoders) >= c.limit {
+		var (
+			oldest string
+			used   int64 = -1
+		)
+		for k, e := range s.decoders {
+			if u := atomic.LoadInt64(&e.used); used < 0 || u < used {
+				oldest, used = k, u
+			}
+		}
+		delete(s.decoders, oldest)
+	}
+	s.decoders[string(key)] = &decoderEntry{decoder: d, used: time.Now().UnixNano() / 1e6}
+	return d
+}
+
+func (c *decoderCache) len() (n int) {
+	for _, s := range c.shards {
+		s.RLock()
+		n += len(s.decoders)
+		s.RUnlock()
+	}
+	return
+}
+
+// cachedEngine reconstructs the shards by the decoders of the failure patterns cached. The
+// inversion tree of the engine caches the inverted matrices of all the failure patterns
+// without limit under a single lock, it grows to GBs under the random failures of the wide
+// stripes and serializes the parallel reconstructions, so the engine is created without it.
+type cachedEngine struct {
+	reedsolomon.Encoder // the engine without the inversion tree
+	dataShards          int
+	parityShards        int
+	opts                []reedsolomon.Option
+	decoders            *decoderCache
+}
+
+func newCachedEngine(engine reedsolomon.Encoder, dataShards, parityShards, limit int,
+	opts ...reedsolomon.Option) reedsolomon.Encoder {
+	return &cachedEngine{
+		Encoder:      engine,
+		dataShards:   dataShards,
+		parityShards: parityShards,
+		opts:         opts,
+		decoders:     newDecoderCache(limit),
+	}
+}
+
+func (e *cachedEngine) Reconstruct(shards [][]byte) error {
+	return e.reconstruct(shards, false)
+}
+
+func (e *cachedEngine) ReconstructData(shards [][]byte) error {
+	return e.reconstruct(shards, true)
+}
+
+func (e *cachedEngine) reconstruct(shards [][]byte, dataOnly bool) error {
+	badIdx := make([]int, 0, e.parityShards)
+	size := 0
+	for i := range shards {
+		switch {
+		case len(shards[i]) == 0:
+			badIdx = append(badIdx, i)
+		case size == 0:
+			size = len(shards[i])
+		case len(shards[i]) != size:
+			return reedsolomon.ErrShardSize
+		}
+	}
+	// the engine returns the errors of the shards, or nothing to reconstruct
+	if len(shards) != e.dataShards+e.parityShards || len(badIdx) == 0 || len(badIdx) > e.parityShards {
+		if dataOnly {
+			return e.Encoder.ReconstructData(shards)
+		}
+		return e.Encoder.Reconstruct(shards)
+	}
+
+	d, err := e.decoder(badIdx, dataOnly)
+	if err != nil {
+		return err
+	}
+	return d.reconstruct(shards)
+}
+
+// decoder returns the decoder of the failure pattern, the bad idx are sorted.
+func (e *cachedEngine) decoder(badIdx []int, dataOnly bool) (*decoder, error) {
+	// the engine of GF(2^8) is not more than 256 shards
+	key := make([]byte, 1, len(badIdx)+1)
+	if dataOnly {
+		key[0] = 1
+	}
+	for _, i := range badIdx {
+		key = append(key, byte(i))
+	}
+	if d := e.decoders.get(key); d != nil {
+		return d, nil
+	}
+
+	d, err := newDecoder(nil, e.Encoder, e.dataShards, e.parityShards, badIdx, dataOnly, e.opts...)
+	if err != nil {
+		return nil, err
+	}
+	return e.decoders.add(key, d), nil
+}
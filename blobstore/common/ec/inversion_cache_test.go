@@ -0,0 +1,183 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"crypto/rand"
+	mrand "math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+)
+
+func TestEncoderInversionCache(t *testing.T) {
+	for _, cm := range []codemode.CodeMode{codemode.EC6P6, codemode.EC12P4, codemode.EC6P10L2} {
+		testEncoderInversionCache(t, Config{CodeMode: cm.Tactic(), InversionCacheLimit: 4})
+		testEncoderInversionCache(t, Config{CodeMode: cm.Tactic()})
+		testEncoderInversionCache(t, Config{CodeMode: cm.Tactic(), DisableInversionCache: true})
+	}
+	testEncoderInversionCache(t, Config{CodeMode: codemode.EC6P6.Tactic(), EnableGF16: true, InversionCacheLimit: 4})
+	testEncoderInversionCache(t, Config{CodeMode: codemode.EC6P6.Tactic(), EncodingMatrix: cauchyMatrix(6, 6), InversionCacheLimit: 4})
+}
+
+func testEncoderInversionCache(t *testing.T, cfg Config) {
+	tactic := cfg.CodeMode
+	enc, err := NewEncoder(cfg)
+	require.NoError(t, err)
+
+	for _, size := range []int{1 << 8, (1 << 14) + 17} {
+		data := make([]byte, size*tactic.N)
+		rand.Read(data)
+		shards, err := enc.Split(data)
+		require.NoError(t, err)
+		require.NoError(t, enc.Encode(shards))
+		origin := copyShards(shards)
+
+		for round := 0; round < 32; round++ {
+			badIdx := mrand.Perm(tactic.N + tactic.M)[:1+mrand.Intn(tactic.M)]
+			shards = copyShards(origin)
+			require.NoError(t, enc.Reconstruct(shards, badIdx))
+			require.Equal(t, origin, shards, "tactic: %+v, bad: %v", tactic, badIdx)
+
+			shards = copyShards(origin)
+			require.NoError(t, enc.ReconstructData(shards, badIdx))
+			for _, i := range badIdx {
+				if i >= tactic.N {
+					require.Empty(t, shards[i])
+					shards[i] = origin[i]
+				}
+			}
+			require.Equal(t, origin, shards, "tactic: %+v, bad: %v", tactic, badIdx)
+		}
+
+		badIdx := mrand.Perm(tactic.N + tactic.M)[:tactic.M+1]
+		require.Error(t, enc.Reconstruct(copyShards(origin), badIdx))
+	}
+
+	var engine interface{}
+	switch e := enc.(type) {
+	case *encoder:
+		engine = e.engine
+	case *lrcEncoder:
+		engine = e.engine
+	}
+	cached, ok := engine.(*cachedEngine)
+	require.Equal(t, !cfg.DisableInversionCache && !cfg.EnableGF16, ok)
+	if ok && cfg.InversionCacheLimit > 0 {
+		require.LessOrEqual(t, cached.decoders.len(), cfg.InversionCacheLimit)
+	}
+}
+
+func TestDecoderCache(t *testing.T) {
+	key := func(i int) []byte { return []byte{0, byte(i), byte(i >> 8)} }
+	d := &decoder{}
+
+	c := newDecoderCache(0)
+	for i := 0; i < 1000; i++ {
+		require.Nil(t, c.get(key(i)))
+		require.Equal(t, d, c.add(key(i), d))
+	}
+	require.Equal(t, 1000, c.len())
+	// the decoder added by the other reconstruction
+	require.Equal(t, d, c.add(key(1), &decoder{}))
+
+	for _, limit := range []int{1, 4, 100} {
+		c = newDecoderCache(limit)
+		for i := 0; i < 1000; i++ {
+			c.add(key(i), d)
+			require.Equal(t, d, c.get(key(i)))
+			require.LessOrEqual(t, c.len(), limit)
+		}
+	}
+
+	// the least recently used is evicted
+	c = newDecoderCache(2)
+	c.shards = c.shards[:1]
+	c.limit = 2
+	c.add(key(1), d)
+	c.add(key(2), d)
+	c.shards[0].decoders[string(key(2))].used = 0
+	c.add(key(3), d)
+	require.NotNil(t, c.get(key(1)))
+	require.Nil(t, c.get(key(2)))
+	require.NotNil(t, c.get(key(3)))
+}
+
+func TestEncoderInversionCacheParallel(t *testing.T) {
+	tactic := codemode.EC12P4.Tactic()
+	enc, err := NewEncoder(Config{CodeMode: tactic, InversionCacheLimit: 8, Concurrency: 16})
+	require.NoError(t, err)
+	data := make([]byte, (1<<12)*tactic.N)
+	rand.Read(data)
+	origin, err := enc.Split(data)
+	require.NoError(t, err)
+	require.NoError(t, enc.Encode(origin))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for round := 0; round < 64; round++ {
+				badIdx := mrand.Perm(tactic.N + tactic.M)[:1+mrand.Intn(tactic.M)]
+				shards := copyShards(origin)
+				require.NoError(t, enc.Reconstruct(shards, badIdx))
+				require.Equal(t, origin, shards)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkEncoderReconstructParallel(b *testing.B) {
+	tactic := codemode.EC12P4.Tactic()
+	data := make([]byte, (1<<14)*tactic.N)
+	rand.Read(data)
+	badIdx := []int{0, 3, tactic.N + 1}
+
+	for _, cfg := range []Config{
+		{CodeMode: tactic},
+		{CodeMode: tactic, InversionCacheLimit: 64},
+		{CodeMode: tactic, DisableInversionCache: true},
+	} {
+		name := "default"
+		if cfg.InversionCacheLimit > 0 {
+			name = "limit"
+		} else if cfg.DisableInversionCache {
+			name = "disable"
+		}
+		cfg.Concurrency = 1 << 10
+		b.Run(name, func(b *testing.B) {
+			enc, err := NewEncoder(cfg)
+			require.NoError(b, err)
+			origin, err := enc.Split(data)
+			require.NoError(b, err)
+			require.NoError(b, enc.Encode(origin))
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				shards := copyShards(origin)
+				for pb.Next() {
+					if err := enc.Reconstruct(shards, badIdx); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
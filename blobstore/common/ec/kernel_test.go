@@ -0,0 +1,67 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"fmt"
+	mrand "math/rand"
+	"testing"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+)
+
+// BenchmarkGaloisKernels codes the shards whose body goes through the simd kernels of the
+// cpu, such as NEON on arm64 and AVX2 on amd64, with and without the unaligned tail coded
+// by the table loop, run it with GOARCH=arm64 on the arm hosts to compare the kernels.
+func BenchmarkGaloisKernels(b *testing.B) {
+	tactic := codemode.EC6P6.Tactic()
+	for _, size := range []int{1 << 16, 1<<16 + 31, 1 << 20, 1<<20 + 31} {
+		shards := randShards(mrand.New(mrand.NewSource(0)), tactic.N+tactic.M, size)
+		e, _ := newEngine(tactic.N, tactic.M, false)
+		b.Run(fmt.Sprintf("encode-%d", size), func(b *testing.B) {
+			b.SetBytes(int64(size * tactic.N))
+			for i := 0; i < b.N; i++ {
+				e.Encode(shards)
+			}
+		})
+	}
+}
+
+// BenchmarkReconstructKernels compares reconstruction with encoding of the same shards, both
+// of them are coded by the same kernels, the GFNI kernels if the cpu supports and not disabled.
+func BenchmarkReconstructKernels(b *testing.B) {
+	tactic := codemode.EC6P6.Tactic()
+	size := 1 << 20
+	for _, disableGFNI := range []bool{false, true} {
+		e, _ := newEngine(tactic.N, tactic.M, false, engineOptions(disableGFNI)...)
+		shards := randShards(mrand.New(mrand.NewSource(0)), tactic.N+tactic.M, size)
+		e.Encode(shards)
+		b.Run(fmt.Sprintf("encode-gfni-%v", !disableGFNI), func(b *testing.B) {
+			b.SetBytes(int64(size * tactic.N))
+			for i := 0; i < b.N; i++ {
+				e.Encode(shards)
+			}
+		})
+		b.Run(fmt.Sprintf("reconstruct-gfni-%v", !disableGFNI), func(b *testing.B) {
+			b.SetBytes(int64(size * tactic.N))
+			for i := 0; i < b.N; i++ {
+				for j := 0; j < tactic.M; j++ {
+					shards[j] = shards[j][:0]
+				}
+				e.Reconstruct(shards)
+			}
+		})
+	}
+}
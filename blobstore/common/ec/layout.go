@@ -0,0 +1,89 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"fmt"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+)
+
+// validateAzLayout checks the az layout of shards against the tactic:
+//   - coverage, every shard of the stripe is placed in some az
+//   - disjointness, none of the shards is placed twice
+//   - local parity membership (LRC), the shards in az idx are exactly the local stripe
+//     idx whose local parity is computed by the encoder
+//   - survival, the global shards in any one az are no more than M, so the data
+//     is recoverable if the whole az is down
+func validateAzLayout(tactic codemode.Tactic, azLayout [][]int) error {
+	if len(azLayout) != tactic.AZCount {
+		return fmt.Errorf("%w: az count %d, expected %d", ErrInvalidAzLayout, len(azLayout), tactic.AZCount)
+	}
+
+	total, global := tactic.N+tactic.M+tactic.L, tactic.N+tactic.M
+	placed := make(map[int]int, total)
+	for azIdx, shards := range azLayout {
+		globalInAz := 0
+		for _, idx := range shards {
+			if idx < 0 || idx >= total {
+				return fmt.Errorf("%w: shard %d in az %d out of range [0, %d)", ErrInvalidAzLayout, idx, azIdx, total)
+			}
+			if prev, ok := placed[idx]; ok {
+				return fmt.Errorf("%w: shard %d placed in both az %d and az %d", ErrInvalidAzLayout, idx, prev, azIdx)
+			}
+			placed[idx] = azIdx
+			if idx < global {
+				globalInAz++
+			}
+		}
+		if tactic.AZCount > 1 && globalInAz > tactic.M {
+			return fmt.Errorf("%w: %d global shards in az %d, unrecoverable if the az is down with %d parity shards",
+				ErrInvalidAzLayout, globalInAz, azIdx, tactic.M)
+		}
+	}
+	if len(placed) != total {
+		for idx := 0; idx < total; idx++ {
+			if _, ok := placed[idx]; !ok {
+				return fmt.Errorf("%w: shard %d not placed in any az", ErrInvalidAzLayout, idx)
+			}
+		}
+	}
+
+	if tactic.L == 0 {
+		return nil
+	}
+	for azIdx, shards := range azLayout {
+		local, n, m := tactic.LocalStripeInAZ(azIdx)
+		if len(shards) != n+m {
+			return fmt.Errorf("%w: %d shards in az %d, expected local stripe of %d shards",
+				ErrInvalidAzLayout, len(shards), azIdx, n+m)
+		}
+		for _, idx := range local {
+			if placed[idx] != azIdx {
+				return fmt.Errorf("%w: shard %d of local stripe %d placed in az %d",
+					ErrInvalidAzLayout, idx, azIdx, placed[idx])
+			}
+		}
+	}
+	return nil
+}
+
+func (e *encoder) ValidateAzLayout(azLayout [][]int) error {
+	return validateAzLayout(e.CodeMode, azLayout)
+}
+
+func (e *lrcEncoder) ValidateAzLayout(azLayout [][]int) error {
+	return validateAzLayout(e.CodeMode, azLayout)
+}
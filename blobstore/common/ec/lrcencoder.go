@@ -17,6 +17,8 @@ package ec
 import (
 	"context"
 	"io"
+	"sort"
+	"sync"
 
 	"github.com/klauspost/reedsolomon"
 
@@ -30,12 +32,24 @@ type lrcEncoder struct {
 	pool        limit.Limiter // concurrency pool
 	engine      reedsolomon.Encoder
 	localEngine reedsolomon.Encoder
+	scratch     *scratchBuffers
+	derived     *derivedEncoders
+
+	// the engine coding the global and local parity shards over the data shards directly,
+	// created at the first EncodeIdx or UpdateRange
+	directOnce sync.Once
+	direct     reedsolomon.Encoder
+	directErr  error
 }
 
 func (e *lrcEncoder) Encode(shards [][]byte) error {
 	if len(shards) != (e.CodeMode.N + e.CodeMode.M + e.CodeMode.L) {
 		return ErrInvalidShards
 	}
+	if work, padded := e.padShortShards(shards); work != nil {
+		defer restoreShortShards(shards, work, padded)
+		shards = work
+	}
 	e.pool.Acquire()
 	defer e.pool.Release()
 	fillFullShards(shards)
@@ -45,7 +59,7 @@ func (e *lrcEncoder) Encode(shards [][]byte) error {
 		return errors.Info(err, "lrcEncoder.Encode global failed")
 	}
 	if e.EnableVerify {
-		ok, err := e.engine.Verify(shards[:e.CodeMode.N+e.CodeMode.M])
+		ok, err := e.scratch.verify(e.engine, e.CodeMode.N, shards[:e.CodeMode.N+e.CodeMode.M])
 		if err != nil {
 			return errors.Info(err, "lrcEncoder.Encode global verify failed")
 		}
@@ -63,7 +77,7 @@ func (e *lrcEncoder) Encode(shards [][]byte) error {
 				return errors.Info(err, "lrcEncoder.Encode local failed")
 			}
 			if e.EnableVerify {
-				ok, err := e.localEngine.Verify(localShards)
+				ok, err := e.scratch.verify(e.localEngine, e.localN(), localShards)
 				if err != nil {
 					return errors.Info(err, "lrcEncoder.Encode local verify failed")
 				}
@@ -87,18 +101,22 @@ type verifyError struct {
 }
 
 func (e *lrcEncoder) Verify(shards [][]byte) (bool, error) {
+	if work, padded := e.padShortShards(shards); work != nil {
+		defer restoreShortShards(shards, work, padded)
+		shards = work
+	}
 	e.pool.Acquire()
 	defer e.pool.Release()
 
 	if len(shards) == (e.CodeMode.N+e.CodeMode.M+e.CodeMode.L)/e.CodeMode.AZCount {
-		ok, err := e.localEngine.Verify(shards)
+		ok, err := e.scratch.verify(e.localEngine, e.localN(), shards)
 		if err != nil {
 			err = errors.Info(err, "lrcEncoder.Verify local shards failed")
 		}
 		return ok, err
 	}
 
-	ok, err := e.engine.Verify(shards[:e.CodeMode.N+e.CodeMode.M])
+	ok, err := e.scratch.verify(e.engine, e.CodeMode.N, shards[:e.CodeMode.N+e.CodeMode.M])
 	if !ok || err != nil {
 		if err != nil {
 			err = errors.Info(err, "lrcEncoder.Verify global shards failed")
@@ -110,7 +128,7 @@ func (e *lrcEncoder) Verify(shards [][]byte) (bool, error) {
 	for i := 0; i < e.CodeMode.AZCount; i++ {
 		localShards := e.GetShardsInIdc(shards, i)
 		tasks = append(tasks, func() error {
-			ok, err := e.localEngine.Verify(localShards)
+			ok, err := e.scratch.verify(e.localEngine, e.localN(), localShards)
 			if !ok || err != nil {
 				if err != nil {
 					err = errors.Info(err, "lrcEncoder.Verify local shards failed")
@@ -130,7 +148,77 @@ func (e *lrcEncoder) Verify(shards [][]byte) (bool, error) {
 	return true, nil
 }
 
+// VerifyDetailed verifies the global stripe and the local stripes of all azs, the local parity
+// of the az of a mismatched global shard is not verified, which is encoded with the bad one.
+func (e *lrcEncoder) VerifyDetailed(shards [][]byte) ([]ParityMismatch, error) {
+	if work, padded := e.padShortShards(shards); work != nil {
+		defer restoreShortShards(shards, work, padded)
+		shards = work
+	}
+	e.pool.Acquire()
+	defer e.pool.Release()
+
+	if len(shards) == (e.CodeMode.N+e.CodeMode.M+e.CodeMode.L)/e.CodeMode.AZCount {
+		mismatches, err := e.scratch.verifyDetailed(e.localEngine, e.localN(), shards)
+		if err != nil {
+			err = errors.Info(err, "lrcEncoder.VerifyDetailed local shards failed")
+		}
+		return mismatches, err
+	}
+
+	mismatches, err := e.scratch.verifyDetailed(e.engine, e.CodeMode.N, shards[:e.CodeMode.N+e.CodeMode.M])
+	if err != nil {
+		return nil, errors.Info(err, "lrcEncoder.VerifyDetailed global shards failed")
+	}
+	bad := make(map[int]bool, len(mismatches))
+	for _, m := range mismatches {
+		bad[m.Index] = true
+	}
+
+	localMismatches := make([][]ParityMismatch, e.CodeMode.AZCount)
+	tasks := make([]func() error, 0, e.CodeMode.AZCount)
+	for i := 0; i < e.CodeMode.AZCount; i++ {
+		idx := i
+		locals, _, _ := e.CodeMode.LocalStripeInAZ(idx)
+		skip := false
+		for _, globalIdx := range locals[:e.localN()] {
+			skip = skip || bad[globalIdx]
+		}
+		if skip {
+			continue
+		}
+		localShards := e.GetShardsInIdc(shards, idx)
+		tasks = append(tasks, func() error {
+			ms, err := e.scratch.verifyDetailed(e.localEngine, e.localN(), localShards)
+			if err != nil {
+				return errors.Info(err, "lrcEncoder.VerifyDetailed local shards failed")
+			}
+			for j := range ms {
+				ms[j].Index = locals[ms[j].Index]
+			}
+			localMismatches[idx] = ms
+			return nil
+		})
+	}
+	if err = task.Run(context.Background(), tasks...); err != nil {
+		return nil, err
+	}
+	for _, ms := range localMismatches {
+		mismatches = append(mismatches, ms...)
+	}
+	sort.Slice(mismatches, func(i, j int) bool { return mismatches[i].Index < mismatches[j].Index })
+	return mismatches, nil
+}
+
 func (e *lrcEncoder) Reconstruct(shards [][]byte, badIdx []int) error {
+	if e.ShortLastShard {
+		initBadShards(shards, badIdx)
+	}
+	e.preallocShards(shards, len(shards), nil)
+	if work, padded := e.padShortShards(shards); work != nil {
+		defer restoreShortShards(shards, work, padded)
+		shards = work
+	}
 	fillFullShards(shards)
 
 	globalBadIdx := make([]int, 0)
@@ -186,6 +274,14 @@ func (e *lrcEncoder) Reconstruct(shards [][]byte, badIdx []int) error {
 }
 
 func (e *lrcEncoder) ReconstructData(shards [][]byte, badIdx []int) error {
+	if e.ShortLastShard {
+		initBadShards(shards, badIdx)
+	}
+	e.preallocShards(shards, e.CodeMode.N, nil)
+	if work, padded := e.padShortShards(shards); work != nil {
+		defer restoreShortShards(shards, work, padded)
+		shards = work
+	}
 	fillFullShards(shards[:e.CodeMode.N+e.CodeMode.M])
 	globalBadIdx := make([]int, 0)
 	for _, i := range badIdx {
@@ -200,7 +296,110 @@ func (e *lrcEncoder) ReconstructData(shards [][]byte, badIdx []int) error {
 	return e.engine.ReconstructData(shards)
 }
 
+func (e *lrcEncoder) ReconstructSome(shards [][]byte, required []bool) error {
+	if len(required) != len(shards) {
+		return ErrInvalidShards
+	}
+	initBadShards(shards, requiredIdx(required))
+	e.preallocShards(shards, len(shards), required)
+	if work, padded := e.padShortShards(shards); work != nil {
+		defer restoreShortShards(shards, work, padded)
+		shards = work
+	}
+	e.pool.Acquire()
+	defer e.pool.Release()
+
+	n, m, l, azCount := e.CodeMode.N, e.CodeMode.M, e.CodeMode.L, e.CodeMode.AZCount
+	// use local ec reconstruct, saving network bandwidth
+	if len(shards) == (n+m+l)/azCount {
+		if err := reconstructSome(e.localEngine, e.localN(), shards, required); err != nil {
+			return errors.Info(err, "lrcEncoder.ReconstructSome local ec reconstruct failed")
+		}
+		return nil
+	}
+	if len(shards) != n+m+l {
+		return ErrInvalidShards
+	}
+
+	// the local parity shards are coded from the global shards in the az, which are required
+	// by the global ec if they are missing
+	work := make([][]byte, len(shards))
+	copy(work, shards)
+	globalRequired := make([]bool, n+m)
+	copy(globalRequired, required)
+	localRequired := make(map[int][]bool)
+	for i := n + m; i < len(shards); i++ {
+		if !required[i] {
+			continue
+		}
+		idcIdx := (i - n - m) * azCount / l
+		locals, _, _ := e.CodeMode.LocalStripeInAZ(idcIdx)
+		if _, ok := localRequired[idcIdx]; !ok {
+			localRequired[idcIdx] = make([]bool, len(locals))
+		}
+		for localIdx, globalIdx := range locals {
+			if globalIdx == i {
+				localRequired[idcIdx][localIdx] = true
+			}
+			if globalIdx < n+m && len(shards[globalIdx]) == 0 {
+				globalRequired[globalIdx] = true
+			}
+		}
+	}
+	for i := 0; i < n+m; i++ {
+		if globalRequired[i] && !required[i] {
+			work[i] = nil
+		}
+	}
+
+	if err := reconstructSome(e.engine, n, work[:n+m], globalRequired); err != nil {
+		return errors.Info(err, "lrcEncoder.ReconstructSome global ec reconstruct failed")
+	}
+	for idx, localReq := range localRequired {
+		locals, _, _ := e.CodeMode.LocalStripeInAZ(idx)
+		localShards := e.GetShardsInIdc(work, idx)
+		if err := reconstructSome(e.localEngine, e.localN(), localShards, localReq); err != nil {
+			return errors.Info(err, "lrcEncoder.ReconstructSome local ec reconstruct after global ec failed")
+		}
+		for localIdx, globalIdx := range locals {
+			work[globalIdx] = localShards[localIdx]
+		}
+	}
+	for i := range shards {
+		if required[i] {
+			shards[i] = work[i]
+		}
+	}
+	return nil
+}
+
+// PrepareReconstruct prepares the decoder of the whole stripe, the local stripe in an az is
+// reconstructed by Reconstruct.
+func (e *lrcEncoder) PrepareReconstruct(badIdx []int) (Decoder, error) {
+	n, m, l, azCount := e.CodeMode.N, e.CodeMode.M, e.CodeMode.L, e.CodeMode.AZCount
+	badIdx, err := sortedBadIdx(badIdx, n+m+l)
+	if err != nil {
+		return nil, err
+	}
+	globalBadIdx := make([]int, 0, len(badIdx))
+	localIdx := make(map[int][]int)
+	for _, i := range badIdx {
+		if i < n+m {
+			globalBadIdx = append(globalBadIdx, i)
+			continue
+		}
+		idcIdx := (i - n - m) * azCount / l
+		localIdx[idcIdx] = append(localIdx[idcIdx], i)
+	}
+	global, err := newDecoder(e.pool, e.engine, n, m, globalBadIdx, false, e.engineOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	return e.shortShardDecoder(&lrcDecoder{lrcEncoder: e, global: global, localIdx: localIdx}, badIdx), nil
+}
+
 func (e *lrcEncoder) Split(data []byte) ([][]byte, error) {
+	dataLen := len(data)
 	shards, err := e.engine.Split(data)
 	if err != nil {
 		return nil, err
@@ -218,6 +417,9 @@ func (e *lrcEncoder) Split(data []byte) ([][]byte, error) {
 			shards = append(shards, make([]byte, shardLen))
 		}
 	}
+	if e.ShortLastShard {
+		shards = trimLastShard(shards, e.CodeMode.N, dataLen)
+	}
 	return shards, nil
 }
 
@@ -245,3 +447,12 @@ func (e *lrcEncoder) GetShardsInIdc(shards [][]byte, idx int) [][]byte {
 func (e *lrcEncoder) Join(dst io.Writer, shards [][]byte, outSize int) error {
 	return e.engine.Join(dst, shards[:(e.CodeMode.N+e.CodeMode.M)], outSize)
 }
+
+func (e *lrcEncoder) Release() {
+	e.scratch.release()
+}
+
+// localN returns the number of data shards of the local stripe in an az
+func (e *lrcEncoder) localN() int {
+	return (e.CodeMode.N + e.CodeMode.M) / e.CodeMode.AZCount
+}
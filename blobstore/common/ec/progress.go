@@ -0,0 +1,175 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import "sort"
+
+const defaultProgressChunkSize = 1 << 20
+
+// ProgressFunc is called after every chunk of shards is done,
+// done and total are the bytes of one shard.
+// Returns an error, such as deadline exceeded, to stop the remaining chunks.
+type ProgressFunc func(done, total int) error
+
+// progressEncoder encodes, reconstructs and verifies shards chunk by chunk,
+// every chunk is the same range of all shards, which is independent of other ranges.
+type progressEncoder struct {
+	Encoder
+	chunkSize int
+	fn        ProgressFunc
+}
+
+// WithProgress returns an encoder which calls fn after every chunkSize bytes of shards are done,
+// so that long-running works of huge shards can be reported and canceled.
+func WithProgress(e Encoder, chunkSize int, fn ProgressFunc) Encoder {
+	if chunkSize <= 0 {
+		chunkSize = defaultProgressChunkSize
+	}
+	return &progressEncoder{Encoder: e, chunkSize: chunkSize, fn: fn}
+}
+
+func (e *progressEncoder) Encode(shards [][]byte) error {
+	fillFullShards(shards)
+	return e.doChunks(shards, shardSize(shards), nil, func(chunks [][]byte) error {
+		return e.Encoder.Encode(chunks)
+	})
+}
+
+func (e *progressEncoder) Verify(shards [][]byte) (bool, error) {
+	ok := true
+	err := e.doChunks(shards, shardSize(shards), nil, func(chunks [][]byte) (err error) {
+		if ok, err = e.Encoder.Verify(chunks); err == nil && !ok {
+			return ErrVerify
+		}
+		return
+	})
+	if err == ErrVerify {
+		return false, nil
+	}
+	return ok, err
+}
+
+// VerifyDetailed verifies chunk by chunk, the offset of a parity shard is the first difference
+// of all the chunks.
+func (e *progressEncoder) VerifyDetailed(shards [][]byte) ([]ParityMismatch, error) {
+	var mismatches []ParityMismatch
+	found := make(map[int]bool)
+	off := 0
+	err := e.doChunks(shards, shardSize(shards), nil, func(chunks [][]byte) error {
+		ms, err := e.Encoder.VerifyDetailed(chunks)
+		if err != nil {
+			return err
+		}
+		for _, m := range ms {
+			if !found[m.Index] {
+				found[m.Index] = true
+				mismatches = append(mismatches, ParityMismatch{Index: m.Index, Offset: off + m.Offset})
+			}
+		}
+		off += shardSize(chunks)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(mismatches, func(i, j int) bool { return mismatches[i].Index < mismatches[j].Index })
+	return mismatches, nil
+}
+
+func (e *progressEncoder) Reconstruct(shards [][]byte, badIdx []int) error {
+	missing := e.allocMissingShards(shards, badIdx, len(shards))
+	return e.doChunks(shards, shardSize(shards), missing, func(chunks [][]byte) error {
+		return e.Encoder.Reconstruct(chunks, badIdx)
+	})
+}
+
+func (e *progressEncoder) ReconstructData(shards [][]byte, badIdx []int) error {
+	missing := e.allocMissingShards(shards, badIdx, len(e.GetDataShards(shards)))
+	return e.doChunks(shards, shardSize(shards), missing, func(chunks [][]byte) error {
+		return e.Encoder.ReconstructData(chunks, badIdx)
+	})
+}
+
+func (e *progressEncoder) ReconstructSome(shards [][]byte, required []bool) error {
+	if len(required) != len(shards) {
+		return ErrInvalidShards
+	}
+	initBadShards(shards, requiredIdx(required))
+	size := shardSize(shards)
+	missing := make(map[int]bool)
+	for i, r := range required {
+		if !r {
+			continue
+		}
+		missing[i] = true
+		if cap(shards[i]) >= size {
+			shards[i] = shards[i][:size]
+		} else {
+			shards[i] = make([]byte, size)
+		}
+	}
+	return e.doChunks(shards, size, missing, func(chunks [][]byte) error {
+		return e.Encoder.ReconstructSome(chunks, required)
+	})
+}
+
+// allocMissingShards makes the bad and empty shards before the limit index full size,
+// chunks of them are reconstructed in place.
+func (e *progressEncoder) allocMissingShards(shards [][]byte, badIdx []int, limit int) map[int]bool {
+	initBadShards(shards, badIdx)
+	size := shardSize(shards)
+	missing := make(map[int]bool)
+	for i := 0; i < limit; i++ {
+		if len(shards[i]) != 0 {
+			continue
+		}
+		missing[i] = true
+		if cap(shards[i]) >= size {
+			shards[i] = shards[i][:size]
+		} else {
+			shards[i] = make([]byte, size)
+		}
+	}
+	return missing
+}
+
+func (e *progressEncoder) doChunks(shards [][]byte, size int, missing map[int]bool, fn func(chunks [][]byte) error) error {
+	chunks := make([][]byte, len(shards))
+	for off := 0; off < size; off += e.chunkSize {
+		end := off + e.chunkSize
+		if end > size {
+			end = size
+		}
+		for i, shard := range shards {
+			switch {
+			case missing[i]:
+				chunks[i] = shard[off:off:end]
+			case len(shard) == 0:
+				chunks[i] = nil
+			default:
+				chunks[i] = shard[off:end]
+			}
+		}
+		if err := fn(chunks); err != nil {
+			return err
+		}
+		if e.fn != nil {
+			if err := e.fn(end, size); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
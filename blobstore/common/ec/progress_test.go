@@ -0,0 +1,113 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+)
+
+func TestEncoderWithProgress(t *testing.T) {
+	for _, mode := range []codemode.CodeMode{codemode.EC6P6, codemode.EC6P10L2} {
+		encoder, err := NewEncoder(Config{CodeMode: mode.Tactic(), EnableVerify: true})
+		require.NoError(t, err)
+
+		var dones []int
+		pe := WithProgress(encoder, 1000, func(done, total int) error {
+			require.Equal(t, 4096, total)
+			dones = append(dones, done)
+			return nil
+		})
+
+		data := make([]byte, 4096*mode.Tactic().N)
+		rand.Read(data)
+		shards, err := pe.Split(data)
+		require.NoError(t, err)
+		require.NoError(t, pe.Encode(shards))
+		require.Equal(t, []int{1000, 2000, 3000, 4000, 4096}, dones)
+
+		expected := copyShards(shards)
+		ok, err := pe.Verify(shards)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		// reconstruct all shards
+		dones = dones[:0]
+		badIdx := []int{0, mode.Tactic().N}
+		shards[0] = shards[0][:0]
+		shards[mode.Tactic().N] = nil
+		require.NoError(t, pe.Reconstruct(shards, badIdx))
+		require.Equal(t, expected, shards)
+		require.Equal(t, 5, len(dones))
+
+		// reconstruct data shards only
+		shards[1] = nil
+		shards[mode.Tactic().N+1] = nil
+		require.NoError(t, pe.ReconstructData(shards, []int{1, mode.Tactic().N + 1}))
+		require.Equal(t, expected[1], shards[1])
+		require.Equal(t, 0, len(shards[mode.Tactic().N+1]))
+
+		// reconstruct the required shards only
+		shards = copyShards(expected)
+		shards[1] = nil
+		shards[2][0]++
+		required := make([]bool, len(shards))
+		required[2], required[mode.Tactic().N] = true, true
+		require.NoError(t, pe.ReconstructSome(shards, required))
+		require.Equal(t, expected[2], shards[2])
+		require.Equal(t, expected[mode.Tactic().N], shards[mode.Tactic().N])
+		require.Nil(t, shards[1])
+
+		shards = copyShards(expected)
+		shards[2][4000]++
+		ok, err = pe.Verify(shards)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		// the offsets are of the whole shards
+		shards = copyShards(expected)
+		shards[mode.Tactic().N+1][2500]++
+		shards[mode.Tactic().N+1][3500]++
+		shards[mode.Tactic().N][10]++
+		mismatches, err := pe.VerifyDetailed(shards)
+		require.NoError(t, err)
+		require.Equal(t, []ParityMismatch{
+			{Index: mode.Tactic().N, Offset: 10},
+			{Index: mode.Tactic().N + 1, Offset: 2500},
+		}, mismatches)
+	}
+}
+
+func TestEncoderWithProgressCancel(t *testing.T) {
+	encoder, err := NewEncoder(Config{CodeMode: codemode.EC6P6.Tactic()})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	called := 0
+	pe := WithProgress(encoder, 1024, func(done, total int) error {
+		called++
+		cancel()
+		return ctx.Err()
+	})
+	shards, err := pe.Split(make([]byte, 6*4096))
+	require.NoError(t, err)
+	require.ErrorIs(t, pe.Encode(shards), context.Canceled)
+	require.Equal(t, 1, called)
+}
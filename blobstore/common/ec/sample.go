@@ -0,0 +1,95 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const defaultSampleRangeSize = 4 << 10
+
+// SampleConfig config of sampled verification
+type SampleConfig struct {
+	// RangeSize bytes of every sampled range, default is 4KB
+	RangeSize int
+	// Coverage ratio of shard bytes to verify in (0, 1), 0 or not less than 1 means full verification
+	Coverage float64
+	// Rand random source of ranges, global source is used if nil.
+	// It is not safe for concurrent use.
+	Rand *rand.Rand
+}
+
+// SampleRanges returns the sorted offsets of ranges to verify in shards of size
+func (c SampleConfig) SampleRanges(size int) []int {
+	rangeSize := c.RangeSize
+	if rangeSize <= 0 {
+		rangeSize = defaultSampleRangeSize
+	}
+	total := (size + rangeSize - 1) / rangeSize
+	count := total
+	if c.Coverage > 0 && c.Coverage < 1 {
+		count = int(math.Ceil(c.Coverage * float64(total)))
+	}
+
+	var perm []int
+	if c.Rand != nil {
+		perm = c.Rand.Perm(total)
+	} else {
+		perm = rand.Perm(total)
+	}
+	offsets := perm[:count]
+	sort.Ints(offsets)
+	for i := range offsets {
+		offsets[i] *= rangeSize
+	}
+	return offsets
+}
+
+// VerifySampled verifies parity with data only on sampled byte ranges of all shards,
+// every byte offset of shards is an independent stripe of Reed-Solomon, so mismatched
+// ranges can be detected without multiplying the full shards.
+// It is a cheap screen for scrubbers, stale parity outside the sampled ranges is not found.
+func VerifySampled(e Encoder, shards [][]byte, conf SampleConfig) (bool, error) {
+	size := shardSize(shards)
+	for _, shard := range shards {
+		if size == 0 || len(shard) != size {
+			return false, ErrInvalidShards
+		}
+	}
+	if conf.Coverage <= 0 || conf.Coverage >= 1 {
+		return e.Verify(shards)
+	}
+
+	rangeSize := conf.RangeSize
+	if rangeSize <= 0 {
+		rangeSize = defaultSampleRangeSize
+	}
+	chunks := make([][]byte, len(shards))
+	for _, off := range conf.SampleRanges(size) {
+		end := off + rangeSize
+		if end > size {
+			end = size
+		}
+		for i, shard := range shards {
+			chunks[i] = shard[off:end]
+		}
+		if ok, err := e.Verify(chunks); err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	crand "crypto/rand"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+)
+
+func TestSampleRanges(t *testing.T) {
+	conf := SampleConfig{RangeSize: 100, Coverage: 0.3, Rand: rand.New(rand.NewSource(1))}
+	offsets := conf.SampleRanges(1001)
+	require.Equal(t, 4, len(offsets))
+	for i, off := range offsets {
+		require.Equal(t, 0, off%100)
+		require.LessOrEqual(t, off, 1000)
+		if i > 0 {
+			require.Less(t, offsets[i-1], off)
+		}
+	}
+
+	conf.Coverage = 0
+	require.Equal(t, 11, len(conf.SampleRanges(1001)))
+	conf.RangeSize = 0
+	require.Equal(t, []int{0, defaultSampleRangeSize}, conf.SampleRanges(defaultSampleRangeSize+1))
+}
+
+func TestVerifySampled(t *testing.T) {
+	for _, mode := range []codemode.CodeMode{codemode.EC6P6, codemode.EC6P10L2} {
+		encoder, err := NewEncoder(Config{CodeMode: mode.Tactic()})
+		require.NoError(t, err)
+
+		data := make([]byte, 1<<20)
+		crand.Read(data)
+		shards, err := encoder.Split(data)
+		require.NoError(t, err)
+		require.NoError(t, encoder.Encode(shards))
+		size := len(shards[0])
+
+		conf := SampleConfig{RangeSize: 1 << 10, Coverage: 0.1, Rand: rand.New(rand.NewSource(1))}
+		ok, err := VerifySampled(encoder, shards, conf)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		// stale parity in every range is detected with any coverage
+		parity := encoder.GetParityShards(shards)[0]
+		for off := 0; off < size; off += 1 << 10 {
+			parity[off] ^= 0xff
+		}
+		ok, _ = VerifySampled(encoder, shards, conf)
+		require.False(t, ok)
+		for off := 0; off < size; off += 1 << 10 {
+			parity[off] ^= 0xff
+		}
+
+		// stale parity in one range is detected only if the range is sampled
+		parity[size-1] ^= 0xff
+		conf.Coverage = 0.01
+		for seed := int64(0); seed < 10; seed++ {
+			conf.Rand = rand.New(rand.NewSource(seed))
+			offsets := conf.SampleRanges(size)
+			sampled := offsets[len(offsets)-1]+conf.RangeSize >= size
+
+			conf.Rand = rand.New(rand.NewSource(seed))
+			ok, err = VerifySampled(encoder, shards, conf)
+			require.NoError(t, err)
+			require.Equal(t, !sampled, ok)
+		}
+
+		// full verification
+		conf.Coverage = 1
+		ok, err = VerifySampled(encoder, shards, conf)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		shards[0] = shards[0][:0]
+		_, err = VerifySampled(encoder, shards, conf)
+		require.ErrorIs(t, err, ErrInvalidShards)
+	}
+}
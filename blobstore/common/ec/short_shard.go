@@ -0,0 +1,114 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+// trimLastShard returns the shards split of dataLen bytes with the zero padding of the last data
+// shard trimmed. The last data shard is still padded if it has no data, so that it is not
+// regarded as missing, or if the data shards are aligned by the engine.
+func trimLastShard(shards [][]byte, dataShards, dataLen int) [][]byte {
+	if len(shards) < dataShards || len(shards[0]) == 0 {
+		return shards
+	}
+	if size := dataLen - (dataShards-1)*len(shards[0]); size > 0 && size < len(shards[dataShards-1]) {
+		shards[dataShards-1] = shards[dataShards-1][:size]
+	}
+	return shards
+}
+
+// shortDataShards returns the number of the data shards which may be short of the shards, the
+// shards of the local stripe of LRC are coded over the global shards in the az.
+func (c *Config) shortDataShards(total int) int {
+	if c.CodeMode.L != 0 && total == (c.CodeMode.N+c.CodeMode.M+c.CodeMode.L)/c.CodeMode.AZCount {
+		return (c.CodeMode.N + c.CodeMode.M) / c.CodeMode.AZCount
+	}
+	return c.CodeMode.N
+}
+
+// padShortShards returns the copy of the shards with the short data shards padded with zeros to
+// the size of the others, which are coded by the engine of the equal shards. It returns nil if
+// the short last shard is not enabled or none of the data shards is short. The shards missing
+// are not padded, mark the short ones bad before padding to reconstruct them.
+func (c *Config) padShortShards(shards [][]byte) (work [][]byte, padded []bool) {
+	if !c.ShortLastShard {
+		return nil, nil
+	}
+	size := 0
+	for _, shard := range shards {
+		if len(shard) > size {
+			size = len(shard)
+		}
+	}
+	dataShards := c.shortDataShards(len(shards))
+	for i := 0; i < dataShards && i < len(shards); i++ {
+		if len(shards[i]) == 0 || len(shards[i]) == size {
+			continue
+		}
+		if work == nil {
+			work = make([][]byte, len(shards))
+			copy(work, shards)
+			padded = make([]bool, len(shards))
+		}
+		work[i] = make([]byte, size)
+		copy(work[i], shards[i])
+		padded[i] = true
+	}
+	return work, padded
+}
+
+// restoreShortShards moves the shards coded in the copy back, the short shards are kept.
+func restoreShortShards(shards, work [][]byte, padded []bool) {
+	for i := range shards {
+		if !padded[i] {
+			shards[i] = work[i]
+		}
+	}
+}
+
+// padShortShard returns the data shard padded to size if the short last shard is enabled.
+func (c *Config) padShortShard(dataShard []byte, size int) []byte {
+	if !c.ShortLastShard || len(dataShard) == 0 || len(dataShard) >= size {
+		return dataShard
+	}
+	padded := make([]byte, size)
+	copy(padded, dataShard)
+	return padded
+}
+
+// shortShardDecoder reconstructs the stripes of the short last data shard by the decoder.
+type shortShardDecoder struct {
+	Decoder
+	config *Config
+	badIdx []int
+}
+
+func (d *shortShardDecoder) Reconstruct(shards [][]byte) error {
+	if len(shards) != d.config.CodeMode.N+d.config.CodeMode.M+d.config.CodeMode.L {
+		return ErrInvalidShards
+	}
+	initBadShards(shards, d.badIdx)
+	if work, padded := d.config.padShortShards(shards); work != nil {
+		defer restoreShortShards(shards, work, padded)
+		shards = work
+	}
+	return d.Decoder.Reconstruct(shards)
+}
+
+// shortShardDecoder returns the decoder of the short last data shard if it is enabled.
+func (c *Config) shortShardDecoder(d Decoder, badIdx []int) Decoder {
+	if !c.ShortLastShard {
+		return d
+	}
+	return &shortShardDecoder{Decoder: d, config: c, badIdx: badIdx}
+}
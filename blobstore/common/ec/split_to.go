@@ -0,0 +1,74 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"github.com/klauspost/reedsolomon"
+)
+
+// splitShardSize returns the size of the shards of dataLen bytes split by the engine, the leopard
+// engine without the serial engine of the tail aligns the shards to 64 bytes.
+func splitShardSize(engine reedsolomon.Encoder, dataShards, dataLen int) int {
+	size := (dataLen + dataShards - 1) / dataShards
+	if e, ok := engine.(*gf16Engine); ok && e.tail == nil {
+		size = (size + shardAlignSize - 1) &^ (shardAlignSize - 1)
+	}
+	return size
+}
+
+// splitTo copies the data to the data shards of dst and reslices all the shards of dst to the size
+// of the shards split by the engine, the padding of the data shards is zeroed. The parity and
+// local shards are not zeroed, they are overwritten by Encode.
+func (c *Config) splitTo(engine reedsolomon.Encoder, data []byte, dst [][]byte) error {
+	if len(data) == 0 {
+		return ErrShortData
+	}
+	tactic := c.CodeMode
+	if len(dst) != tactic.N+tactic.M+tactic.L {
+		return ErrInvalidShards
+	}
+	size := splitShardSize(engine, tactic.N, len(data))
+	for i := range dst {
+		if cap(dst[i]) < size {
+			return reedsolomon.ErrShardSize
+		}
+	}
+
+	dataLen := len(data)
+	for i := range dst {
+		shard := dst[i][:size]
+		if i < tactic.N {
+			n := copy(shard, data)
+			data = data[n:]
+			padding := shard[n:]
+			for j := range padding {
+				padding[j] = 0
+			}
+		}
+		dst[i] = shard
+	}
+	if c.ShortLastShard {
+		trimLastShard(dst, tactic.N, dataLen)
+	}
+	return nil
+}
+
+func (e *encoder) SplitTo(data []byte, dst [][]byte) error {
+	return e.splitTo(e.engine, data, dst)
+}
+
+func (e *lrcEncoder) SplitTo(data []byte, dst [][]byte) error {
+	return e.splitTo(e.engine, data, dst)
+}
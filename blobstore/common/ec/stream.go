@@ -0,0 +1,115 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"io"
+
+	"github.com/klauspost/reedsolomon"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/util/limit"
+	"github.com/cubefs/cubefs/blobstore/util/limit/count"
+)
+
+const defaultStreamBlockSize = 4 << 20
+
+// StreamEncoder codes the global stripe of N data shards and M parity shards in streams,
+// reading and writing a block of every shard at a time, so that the shards of a large blob
+// need not to be in memory. The local shards of LRC are not coded by it.
+type StreamEncoder interface {
+	// encode the N data readers into the M parity writers, the data readers must supply
+	// the same number of bytes
+	Encode(data []io.Reader, parity []io.Writer) error
+	// verify the parity shards with the data shards, shards are N data readers followed
+	// by M parity readers
+	Verify(shards []io.Reader) (bool, error)
+	// reconstruct the missing shards, you should set nil in valid for the missing or bad
+	// shards, and the writers in fill for the shards to be reconstructed
+	Reconstruct(valid []io.Reader, fill []io.Writer) error
+}
+
+// StreamConfig stream encoder config
+type StreamConfig struct {
+	CodeMode    codemode.Tactic
+	BlockSize   int // bytes of each shard coded at a time, default is 4MB
+	Concurrency int
+	DisableGFNI bool // disable the GFNI kernels for testing
+	// the parity coefficients of the global stripe, the same as the EncodingMatrix of Config
+	EncodingMatrix [][]byte
+}
+
+type streamEncoder struct {
+	StreamConfig
+	pool   limit.Limiter // concurrency pool
+	engine reedsolomon.StreamEncoder
+}
+
+// NewStreamEncoder return a stream encoder of the global stripe of the code mode
+func NewStreamEncoder(cfg StreamConfig) (StreamEncoder, error) {
+	if !cfg.CodeMode.IsValid() {
+		return nil, ErrInvalidCodeMode
+	}
+	if cfg.BlockSize <= 0 {
+		cfg.BlockSize = defaultStreamBlockSize
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defaultConcurrency
+	}
+
+	opts := append(engineOptions(cfg.DisableGFNI), reedsolomon.WithStreamBlockSize(cfg.BlockSize))
+	if cfg.EncodingMatrix != nil {
+		if err := validateEncodingMatrix(cfg.EncodingMatrix, cfg.CodeMode.N, cfg.CodeMode.M); err != nil {
+			return nil, err
+		}
+		opts = append(opts, reedsolomon.WithCustomMatrix(cfg.EncodingMatrix))
+	}
+	engine, err := reedsolomon.NewStream(cfg.CodeMode.N, cfg.CodeMode.M, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &streamEncoder{
+		StreamConfig: cfg,
+		pool:         count.NewBlockingCount(cfg.Concurrency),
+		engine:       engine,
+	}, nil
+}
+
+func (e *streamEncoder) Encode(data []io.Reader, parity []io.Writer) error {
+	if len(data) != e.CodeMode.N || len(parity) != e.CodeMode.M {
+		return ErrInvalidShards
+	}
+	e.pool.Acquire()
+	defer e.pool.Release()
+	return e.engine.Encode(data, parity)
+}
+
+func (e *streamEncoder) Verify(shards []io.Reader) (bool, error) {
+	if len(shards) != e.CodeMode.N+e.CodeMode.M {
+		return false, ErrInvalidShards
+	}
+	e.pool.Acquire()
+	defer e.pool.Release()
+	return e.engine.Verify(shards)
+}
+
+func (e *streamEncoder) Reconstruct(valid []io.Reader, fill []io.Writer) error {
+	if len(valid) != e.CodeMode.N+e.CodeMode.M || len(fill) != len(valid) {
+		return ErrInvalidShards
+	}
+	e.pool.Acquire()
+	defer e.pool.Release()
+	return e.engine.Reconstruct(valid, fill)
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+)
+
+func shardReaders(shards [][]byte) []io.Reader {
+	readers := make([]io.Reader, len(shards))
+	for i := range shards {
+		if shards[i] != nil {
+			readers[i] = bytes.NewReader(shards[i])
+		}
+	}
+	return readers
+}
+
+func TestStreamEncoderNew(t *testing.T) {
+	_, err := NewStreamEncoder(StreamConfig{CodeMode: codemode.Tactic{}})
+	require.ErrorIs(t, err, ErrInvalidCodeMode)
+	_, err = NewStreamEncoder(StreamConfig{CodeMode: codemode.EC6P6.Tactic()})
+	require.NoError(t, err)
+}
+
+func TestStreamEncoder(t *testing.T) {
+	tactic := codemode.EC6P6.Tactic()
+	stream, err := NewStreamEncoder(StreamConfig{CodeMode: tactic, BlockSize: 16 << 10})
+	require.NoError(t, err)
+
+	// the shards span several blocks and end with a partial block
+	shardSize := 100<<10 + 7
+	shards := make([][]byte, tactic.N+tactic.M)
+	for i := 0; i < tactic.N; i++ {
+		shards[i] = make([]byte, shardSize)
+		_, err = rand.Read(shards[i])
+		require.NoError(t, err)
+	}
+
+	parity := make([]*bytes.Buffer, tactic.M)
+	writers := make([]io.Writer, tactic.M)
+	for i := range parity {
+		parity[i] = new(bytes.Buffer)
+		writers[i] = parity[i]
+	}
+	require.NoError(t, stream.Encode(shardReaders(shards[:tactic.N]), writers))
+	for i := range parity {
+		shards[tactic.N+i] = parity[i].Bytes()
+	}
+
+	// the same as the in-memory encoder
+	encoder, err := NewEncoder(Config{CodeMode: tactic})
+	require.NoError(t, err)
+	ok, err := encoder.Verify(copyShards(shards))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = stream.Verify(shardReaders(shards))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// reconstruct a data shard and a parity shard
+	bad := []int{1, tactic.N + 2}
+	valid := copyShards(shards)
+	fill := make([]io.Writer, len(shards))
+	filled := make(map[int]*bytes.Buffer)
+	for _, idx := range bad {
+		valid[idx] = nil
+		filled[idx] = new(bytes.Buffer)
+		fill[idx] = filled[idx]
+	}
+	require.NoError(t, stream.Reconstruct(shardReaders(valid), fill))
+	for _, idx := range bad {
+		require.Equal(t, shards[idx], filled[idx].Bytes())
+	}
+
+	// corrupted parity
+	shards[tactic.N][0] ^= 0xff
+	ok, err = stream.Verify(shardReaders(shards))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// mismatched number of shards
+	require.ErrorIs(t, stream.Encode(shardReaders(shards[:tactic.N-1]), writers), ErrInvalidShards)
+	_, err = stream.Verify(shardReaders(shards[:tactic.N]))
+	require.ErrorIs(t, err, ErrInvalidShards)
+	require.ErrorIs(t, stream.Reconstruct(shardReaders(valid), fill[:tactic.N]), ErrInvalidShards)
+}
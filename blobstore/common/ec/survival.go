@@ -0,0 +1,172 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+)
+
+// GetSurvivalShards returns the minimal-cost read set to get the data of a stripe, which is
+// the indexes of N surviving global shards in ascending order of cost, excluding the missing
+// shards in badIdx. costs is the read cost weight of every global shard, such as 1 for the
+// local az and 10 for the cross az, nil means the same cost.
+//
+// The global stripe is maximum distance separable, so any N of its shards are invertible and
+// the N cheapest shards are the minimal-cost read set. The data shards come first of the same
+// cost, the data is read directly without decoding if none of them is missing. The local parity
+// shards of LRC are never in the read set, ReconstructData decodes the data by the global stripe.
+func GetSurvivalShards(tactic codemode.Tactic, badIdx []int, costs []int) ([]int, error) {
+	n, global := tactic.N, tactic.N+tactic.M
+	if costs != nil && len(costs) < global {
+		return nil, fmt.Errorf("%w: %d costs of %d global shards", ErrInvalidShards, len(costs), global)
+	}
+	bad := make(map[int]struct{}, len(badIdx))
+	for _, idx := range badIdx {
+		bad[idx] = struct{}{}
+	}
+
+	survival := make([]int, 0, global)
+	for idx := 0; idx < global; idx++ {
+		if _, ok := bad[idx]; !ok {
+			survival = append(survival, idx)
+		}
+	}
+	if len(survival) < n {
+		return nil, fmt.Errorf("%w: %d surviving shards, at least %d", ErrInvalidShards, len(survival), n)
+	}
+	if costs != nil {
+		// the data shards are ahead of the parity shards, keep them first of the same cost
+		sort.SliceStable(survival, func(i, j int) bool {
+			return costs[survival[i]] < costs[survival[j]]
+		})
+	}
+	return survival[:n], nil
+}
+
+// GetRepairShards returns the read set to reconstruct the missing shards in badIdx, and whether
+// it is of a local stripe. The missing shards of LRC in the local stripe of an az, no more than
+// its local parity shards, are reconstructed by the surviving shards of the local stripe in the
+// order of the layout, which are read in the az without the cross az traffic. Otherwise it falls
+// back to the global read set of GetSurvivalShards, the missing local parity shards are encoded
+// again by the global shards of the az after the global stripe is reconstructed. The local read
+// sets of LRC are cached by the missing shards, the global read set depends on the costs.
+func GetRepairShards(tactic codemode.Tactic, badIdx []int, costs []int) (shards []int, local bool, err error) {
+	if tactic.L != 0 {
+		if badIdx, err = sortedBadIdx(badIdx, tactic.N+tactic.M+tactic.L); err != nil {
+			return nil, false, err
+		}
+		key := repairCacheKey(tactic, badIdx)
+		stripe, ok := repairShardsCache.get(key)
+		if !ok {
+			stripe = localRepairShards(tactic, badIdx)
+			repairShardsCache.add(key, stripe)
+		}
+		if stripe != nil {
+			return append([]int{}, stripe...), true, nil
+		}
+	}
+
+	globalBad := make([]int, 0, len(badIdx))
+	for _, idx := range badIdx {
+		if idx < tactic.N+tactic.M {
+			globalBad = append(globalBad, idx)
+		}
+	}
+	shards, err = GetSurvivalShards(tactic, globalBad, costs)
+	return shards, false, err
+}
+
+// localRepairShards returns the surviving shards of the local stripe to read, or nil if the
+// missing shards are not repairable in a local stripe.
+func localRepairShards(tactic codemode.Tactic, badIdx []int) []int {
+	stripe, n := localRepairStripe(tactic, badIdx)
+	if stripe == nil {
+		return nil
+	}
+	bad := make(map[int]struct{}, len(badIdx))
+	for _, idx := range badIdx {
+		bad[idx] = struct{}{}
+	}
+	shards := make([]int, 0, n)
+	for _, idx := range stripe {
+		if _, ok := bad[idx]; !ok && len(shards) < n {
+			shards = append(shards, idx)
+		}
+	}
+	return shards
+}
+
+// the most failure patterns of the local read sets cached
+const repairCacheLimit = 4096
+
+var repairShardsCache = &repairCache{sets: make(map[string][]int)}
+
+// repairCache caches the local read sets of LRC keyed on the code mode and the sorted missing
+// shards, nil if they are not repairable in a local stripe. The failure patterns repaired are
+// few, so the cache is simply cleared if it is full.
+type repairCache struct {
+	sync.RWMutex
+	sets map[string][]int
+}
+
+func repairCacheKey(tactic codemode.Tactic, badIdx []int) string {
+	key := make([]byte, 0, 2*(4+len(badIdx)))
+	for _, v := range append([]int{tactic.N, tactic.M, tactic.L, tactic.AZCount}, badIdx...) {
+		key = append(key, byte(v>>8), byte(v))
+	}
+	return string(key)
+}
+
+func (c *repairCache) get(key string) ([]int, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	set, ok := c.sets[key]
+	return set, ok
+}
+
+func (c *repairCache) add(key string, set []int) {
+	c.Lock()
+	defer c.Unlock()
+	if len(c.sets) >= repairCacheLimit {
+		c.sets = make(map[string][]int)
+	}
+	c.sets[key] = set
+}
+
+// localRepairStripe returns the local stripe of the az holding all the missing shards and the
+// count of its shards to read, or nil if the missing shards are not repairable in a local stripe.
+func localRepairStripe(tactic codemode.Tactic, badIdx []int) ([]int, int) {
+	if tactic.L == 0 || len(badIdx) == 0 {
+		return nil, 0
+	}
+	stripe, n, m := tactic.LocalStripe(badIdx[0])
+	if stripe == nil || len(badIdx) > m {
+		return nil, 0
+	}
+	in := make(map[int]struct{}, len(stripe))
+	for _, idx := range stripe {
+		in[idx] = struct{}{}
+	}
+	for _, idx := range badIdx {
+		if _, ok := in[idx]; !ok {
+			return nil, 0
+		}
+	}
+	return stripe, n
+}
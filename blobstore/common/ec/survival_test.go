@@ -0,0 +1,130 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+)
+
+func TestGetSurvivalShards(t *testing.T) {
+	tactic := codemode.EC6P6.Tactic()
+
+	shards, err := GetSurvivalShards(tactic, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2, 3, 4, 5}, shards)
+
+	shards, err = GetSurvivalShards(tactic, []int{1, 4}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 2, 3, 5, 6, 7}, shards)
+
+	// d1 d2 d3 p1 p2 p3 in the local az, and d4 d5 d6 p4 p5 p6 in the cross az
+	costs := []int{1, 1, 1, 10, 10, 10, 1, 1, 1, 10, 10, 10}
+	shards, err = GetSurvivalShards(tactic, []int{1}, costs)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 2, 6, 7, 8, 3}, shards)
+
+	// the data shards first of the same cost
+	shards, err = GetSurvivalShards(tactic, []int{9}, make([]int, 12))
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2, 3, 4, 5}, shards)
+
+	// the local parity shards of LRC are never read
+	lrc := codemode.EC6P10L2.Tactic()
+	shards, err = GetSurvivalShards(lrc, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []int{10, 11, 12, 13, 14, 15}, shards)
+
+	_, err = GetSurvivalShards(tactic, []int{0, 1, 2, 3, 4, 5, 6}, nil)
+	require.ErrorIs(t, err, ErrInvalidShards)
+	_, err = GetSurvivalShards(tactic, nil, []int{1, 1})
+	require.ErrorIs(t, err, ErrInvalidShards)
+}
+
+func TestGetRepairShards(t *testing.T) {
+	// az0: d1 d2 d3 p1..p5 l1, az1: d4 d5 d6 p6..p10 l2
+	lrc := codemode.EC6P10L2.Tactic()
+	stripe, _, _ := lrc.LocalStripeInAZ(0)
+	require.Equal(t, []int{0, 1, 2, 6, 7, 8, 9, 10, 16}, stripe)
+
+	// a missing shard is repaired in its local stripe
+	shards, local, err := GetRepairShards(lrc, []int{1}, nil)
+	require.NoError(t, err)
+	require.True(t, local)
+	require.Equal(t, []int{0, 2, 6, 7, 8, 9, 10, 16}, shards)
+
+	// so is the local parity
+	shards, local, err = GetRepairShards(lrc, []int{17}, nil)
+	require.NoError(t, err)
+	require.True(t, local)
+	require.Equal(t, []int{3, 4, 5, 11, 12, 13, 14, 15}, shards)
+
+	// more missing shards than the local parity in an az fall back to the global stripe
+	shards, local, err = GetRepairShards(lrc, []int{0, 1, 16}, nil)
+	require.NoError(t, err)
+	require.False(t, local)
+	require.Equal(t, []int{2, 3, 4, 5, 6, 7}, shards)
+
+	// so do the missing shards across the azs
+	shards, local, err = GetRepairShards(lrc, []int{0, 3}, nil)
+	require.NoError(t, err)
+	require.False(t, local)
+	require.Equal(t, []int{1, 2, 4, 5, 6, 7}, shards)
+
+	// no local stripe of RS
+	shards, local, err = GetRepairShards(codemode.EC6P6.Tactic(), []int{1}, nil)
+	require.NoError(t, err)
+	require.False(t, local)
+	require.Equal(t, []int{0, 2, 3, 4, 5, 6}, shards)
+
+	_, _, err = GetRepairShards(lrc, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, nil)
+	require.ErrorIs(t, err, ErrInvalidShards)
+	_, _, err = GetRepairShards(lrc, []int{18}, nil)
+	require.ErrorIs(t, err, ErrInvalidShards)
+}
+
+func TestGetRepairShardsCache(t *testing.T) {
+	lrc := codemode.EC6P10L2.Tactic()
+	key := repairCacheKey(lrc, []int{1})
+	shards, local, err := GetRepairShards(lrc, []int{1, 1}, nil)
+	require.NoError(t, err)
+	require.True(t, local)
+	cached, ok := repairShardsCache.get(key)
+	require.True(t, ok)
+	require.Equal(t, shards, cached)
+
+	// the read set returned is not the cached one
+	shards[0] = 100
+	again, _, err := GetRepairShards(lrc, []int{1}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 2, 6, 7, 8, 9, 10, 16}, again)
+
+	// the patterns not repairable locally are cached too
+	_, local, err = GetRepairShards(lrc, []int{3, 0}, nil)
+	require.NoError(t, err)
+	require.False(t, local)
+	cached, ok = repairShardsCache.get(repairCacheKey(lrc, []int{0, 3}))
+	require.True(t, ok)
+	require.Nil(t, cached)
+
+	c := &repairCache{sets: make(map[string][]int)}
+	for i := 0; i < repairCacheLimit+1; i++ {
+		c.add(repairCacheKey(lrc, []int{i}), nil)
+	}
+	require.Equal(t, 1, len(c.sets))
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"github.com/klauspost/reedsolomon"
+)
+
+func (e *encoder) UpdateRange(shards, newData [][]byte, offset, length int) error {
+	e.pool.Acquire()
+	defer e.pool.Release()
+	return updateRange(e.engine, e.scratch, e.CodeMode.N, e.EnableVerify, shards, newData, offset, length)
+}
+
+func (e *lrcEncoder) UpdateRange(shards, newData [][]byte, offset, length int) error {
+	engine, err := e.directEngine()
+	if err != nil {
+		return err
+	}
+	e.pool.Acquire()
+	defer e.pool.Release()
+	return updateRange(engine, e.scratch, e.CodeMode.N, e.EnableVerify, shards, newData, offset, length)
+}
+
+// updateRange updates the range of the data shards and the parity shards coded by the engine,
+// the parity shards of the range are updated by the delta of the data, and verified if verify.
+func updateRange(engine reedsolomon.Encoder, scratch *scratchBuffers, dataShards int, verify bool,
+	shards, newData [][]byte, offset, length int,
+) error {
+	if len(newData) != dataShards || offset < 0 || length <= 0 {
+		return ErrInvalidShards
+	}
+	end := offset + length
+	for _, shard := range shards {
+		if len(shard) < end {
+			return ErrInvalidShards
+		}
+	}
+	for _, data := range newData {
+		if data != nil && len(data) != length {
+			return ErrInvalidShards
+		}
+	}
+
+	sub := subShards(shards, offset, end)
+	// the old data of the range is xored with the new data as the delta by the engine
+	if err := engine.Update(sub, newData); err != nil {
+		return err
+	}
+	for i, data := range newData {
+		if data != nil {
+			copy(sub[i], data)
+		}
+	}
+
+	if verify {
+		ok, err := scratch.verify(engine, dataShards, sub)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrVerify
+		}
+	}
+	return nil
+}
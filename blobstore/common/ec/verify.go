@@ -0,0 +1,149 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+// ParityMismatch is a parity shard mismatched with the parity encoded from the data shards.
+type ParityMismatch struct {
+	Index  int // the index of the shard in the shards verified
+	Offset int // the byte offset of the first difference
+}
+
+// firstDifference returns the offset of the first different byte of a and b of the same size,
+// -1 if they are equal.
+func firstDifference(a, b []byte) int {
+	if bytes.Equal(a, b) {
+		return -1
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return len(a)
+}
+
+type scratchKey struct {
+	shards int
+	parity int
+	size   int
+}
+
+// scratchBuffers pools the scratch shards of verification keyed by (shards, parity, shard size),
+// the parity of scratch shards is encoded and compared with the parity to verify. The engine
+// allocates the outputs in every verification, which makes heavy GC in continuous verifiers.
+type scratchBuffers struct {
+	mu    sync.Mutex
+	pools map[scratchKey]*sync.Pool
+}
+
+func newScratchBuffers() *scratchBuffers {
+	return &scratchBuffers{pools: make(map[scratchKey]*sync.Pool)}
+}
+
+func (s *scratchBuffers) getPool(key scratchKey) *sync.Pool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pool, ok := s.pools[key]
+	if !ok {
+		pool = &sync.Pool{New: func() interface{} {
+			buf := make([]byte, key.parity*key.size)
+			shards := make([][]byte, key.shards)
+			for i := key.shards - key.parity; i < key.shards; i++ {
+				shards[i], buf = buf[:key.size:key.size], buf[key.size:]
+			}
+			return &shards
+		}}
+		s.pools[key] = pool
+	}
+	return pool
+}
+
+// release drops all the pooled scratch shards, the later verification allocates them again
+func (s *scratchBuffers) release() {
+	s.mu.Lock()
+	s.pools = make(map[scratchKey]*sync.Pool)
+	s.mu.Unlock()
+}
+
+// verify verifies the parity shards with data shards like engine.Verify, but the parity is
+// encoded into the pooled scratch shards. The shards of invalid size are verified by engine
+// to return the same errors.
+func (s *scratchBuffers) verify(engine reedsolomon.Encoder, dataNum int, shards [][]byte) (bool, error) {
+	if !scratchable(dataNum, shards) {
+		return engine.Verify(shards)
+	}
+	mismatches, err := s.compare(engine, dataNum, shards, true)
+	return err == nil && len(mismatches) == 0, err
+}
+
+// verifyDetailed is verify but returns all the parity shards mismatched, the indexes are of shards.
+func (s *scratchBuffers) verifyDetailed(engine reedsolomon.Encoder, dataNum int, shards [][]byte) ([]ParityMismatch, error) {
+	if !scratchable(dataNum, shards) {
+		if ok, err := engine.Verify(shards); err != nil || ok {
+			return nil, err
+		}
+		return nil, ErrInvalidShards
+	}
+	return s.compare(engine, dataNum, shards, false)
+}
+
+func scratchable(dataNum int, shards [][]byte) bool {
+	size := shardSize(shards)
+	if len(shards) <= dataNum || size == 0 {
+		return false
+	}
+	for _, shard := range shards {
+		if len(shard) != size {
+			return false
+		}
+	}
+	return true
+}
+
+// compare encodes the parity into the scratch shards and compares it with the parity shards,
+// it stops at the first mismatched if first.
+func (s *scratchBuffers) compare(engine reedsolomon.Encoder, dataNum int, shards [][]byte, first bool) ([]ParityMismatch, error) {
+	key := scratchKey{shards: len(shards), parity: len(shards) - dataNum, size: shardSize(shards)}
+	pool := s.getPool(key)
+	scratch := pool.Get().(*[][]byte)
+	defer func() {
+		for i := 0; i < dataNum; i++ {
+			(*scratch)[i] = nil
+		}
+		pool.Put(scratch)
+	}()
+
+	copy(*scratch, shards[:dataNum])
+	if err := engine.Encode(*scratch); err != nil {
+		return nil, err
+	}
+	var mismatches []ParityMismatch
+	for i := dataNum; i < len(shards); i++ {
+		if off := firstDifference((*scratch)[i], shards[i]); off >= 0 {
+			mismatches = append(mismatches, ParityMismatch{Index: i, Offset: off})
+			if first {
+				break
+			}
+		}
+	}
+	return mismatches, nil
+}
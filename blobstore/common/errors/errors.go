@@ -98,6 +98,7 @@ var errCodeMap = map[int]string{
 	CodeNoAvaliableVolume: "this codemode has no avaliable volume",
 	CodeAllocBidFromCm:    "alloc bid from clustermgr error",
 	CodeClusterIDNotMatch: "clusterId not match",
+	CodeBlobCacheMiss:     "blob not in cache",
 
 	// blobnode
 	CodeInvalidParam:   "blobnode: invalid params",
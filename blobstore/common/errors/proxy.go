@@ -18,10 +18,12 @@ const (
 	CodeNoAvaliableVolume = 801
 	CodeAllocBidFromCm    = 802
 	CodeClusterIDNotMatch = 803
+	CodeBlobCacheMiss     = 804
 )
 
 var (
 	ErrNoAvaliableVolume = Error(CodeNoAvaliableVolume)
 	ErrAllocBidFromCm    = Error(CodeAllocBidFromCm)
 	ErrClusterIDNotMatch = Error(CodeClusterIDNotMatch)
+	ErrBlobCacheMiss     = Error(CodeBlobCacheMiss)
 )
@@ -232,6 +232,21 @@ func IsSysConfigKey(key string) bool {
 	}
 }
 
+// WriteDegradedIDCConfigKey config key of write-degraded idcs separated by comma,
+// it's set for planned network maintenance of the idcs.
+const WriteDegradedIDCConfigKey = "write_degraded_idc"
+
+// ParseIDCs returns non-empty idcs of the value separated by comma.
+func ParseIDCs(value string) []string {
+	idcs := make([]string, 0)
+	for _, idc := range strings.Split(value, ",") {
+		if idc = strings.TrimSpace(idc); idc != "" {
+			idcs = append(idcs, idc)
+		}
+	}
+	return idcs
+}
+
 type TaskSwitch string
 
 const (
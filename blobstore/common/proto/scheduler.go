@@ -189,6 +189,41 @@ func (inspect *VolumeInspectRet) Err() error {
 	return errors.New(inspect.InspectErrStr)
 }
 
+// InspectShard is a shard found bad or repaired by volume inspection
+type InspectShard struct {
+	Vuid   Vuid   `json:"vuid"`
+	Bid    BlobID `json:"bid"`
+	DiskID DiskID `json:"disk_id"`
+	Host   string `json:"host"`
+	// bad in the last record of the volume too, the repair didn't take effect or it's corrupted again
+	Recurring bool `json:"recurring,omitempty"`
+}
+
+// VolumeInspectRecord is the result of an inspection of volume, it's recorded only if the
+// bad shards are different from the last record of the volume.
+type VolumeInspectRecord struct {
+	Vid         Vid   `json:"vid"`
+	InspectTime int64 `json:"inspect_time"` // unix seconds
+	// bad shards found in this inspection
+	BadShards []*InspectShard `json:"bad_shards"`
+	// bad shards of the last record which are good in this inspection
+	RepairedShards []*InspectShard `json:"repaired_shards"`
+}
+
+// MigrateCampaign groups the migrate tasks of the source disks, such as the disks of a disk drop
+// or an AZ evacuation, which share a bandwidth budget and are paused and resumed together.
+type MigrateCampaign struct {
+	ID            string   `json:"id"`
+	TaskType      TaskType `json:"task_type"`
+	Disks         []DiskID `json:"disks"`
+	BandwidthMBPS int      `json:"bandwidth_mbps"` // overall budget of the tasks, 0 means unlimited
+	Paused        bool     `json:"paused"`
+	// used chunks of the disks when it's added, one task for each chunk
+	TotalTasksCnt    int   `json:"total_tasks_cnt"`
+	MigratedTasksCnt int   `json:"migrated_tasks_cnt"`
+	Ctime            int64 `json:"ctime"` // unix seconds
+}
+
 type ShardRepairTask struct {
 	Bid      BlobID            `json:"bid"`
 	CodeMode codemode.CodeMode `json:"code_mode"`
@@ -15,11 +15,21 @@
 package proxy
 
 import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
 	"github.com/cubefs/cubefs/blobstore/api/proxy"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
 	"github.com/cubefs/cubefs/blobstore/common/trace"
 )
 
+// blobKeyPrefix prefix of the erasing key of blob cache, see proxy.CacheBlobArgs.BlobKey.
+const blobKeyPrefix = "blob-"
+
 // GetCacheVolume returns volume in cacher.
 func (s *Service) GetCacheVolume(c *rpc.Context) {
 	args := new(proxy.CacheVolumeArgs)
@@ -64,6 +74,10 @@ func (s *Service) GetCacheDisk(c *rpc.Context) {
 func (s *Service) EraseCache(c *rpc.Context) {
 	ctx := c.Request.Context()
 	key := c.Param.ByName("key")
+	if strings.HasPrefix(key, blobKeyPrefix) {
+		s.eraseCacheBlob(c, key)
+		return
+	}
 	if err := s.cacher.Erase(ctx, key); err != nil {
 		span := trace.SpanFromContextSafe(ctx)
 		span.Errorf("erase key:%s error:%s", key, err.Error())
@@ -72,3 +86,79 @@ func (s *Service) EraseCache(c *rpc.Context) {
 	}
 	c.Respond()
 }
+
+// eraseCacheBlob removes all cached ranges of the blob with key "blob-{vid}_{bid}".
+func (s *Service) eraseCacheBlob(c *rpc.Context, key string) {
+	var (
+		vid proto.Vid
+		bid proto.BlobID
+	)
+	if _, err := fmt.Sscanf(key, blobKeyPrefix+"%d_%d", &vid, &bid); err != nil {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+	if s.blobCacher == nil {
+		c.Respond()
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := s.blobCacher.EraseBlob(ctx, vid, bid); err != nil {
+		span := trace.SpanFromContextSafe(ctx)
+		span.Errorf("erase blob key:%s error:%s", key, err.Error())
+		c.RespondError(err)
+		return
+	}
+	c.Respond()
+}
+
+// GetCacheBlob returns data of blob range in cacher.
+func (s *Service) GetCacheBlob(c *rpc.Context) {
+	args := new(proxy.CacheBlobArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	if s.blobCacher == nil {
+		c.RespondError(errcode.ErrBlobCacheMiss)
+		return
+	}
+
+	data, err := s.blobCacher.GetBlob(c.Request.Context(), args)
+	if err != nil {
+		c.RespondError(err)
+		return
+	}
+	c.RespondWith(http.StatusOK, rpc.MIMEStream, data)
+}
+
+// PutCacheBlob fills data of blob range into cacher.
+func (s *Service) PutCacheBlob(c *rpc.Context) {
+	args := new(proxy.CacheBlobArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	if s.blobCacher == nil {
+		c.Respond()
+		return
+	}
+	if c.Request.ContentLength != int64(args.Size) {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+
+	ctx := c.Request.Context()
+	data := make([]byte, args.Size)
+	if _, err := io.ReadFull(c.Request.Body, data); err != nil {
+		c.RespondError(err)
+		return
+	}
+	if err := s.blobCacher.PutBlob(ctx, args, data); err != nil {
+		span := trace.SpanFromContextSafe(ctx)
+		span.Warnf("put blob args:%+v error:%s", args, err.Error())
+		c.RespondError(err)
+		return
+	}
+	c.Respond()
+}
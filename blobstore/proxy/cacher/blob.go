@@ -0,0 +1,312 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cacher
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/peterbourgon/diskv/v3"
+
+	"github.com/cubefs/cubefs/blobstore/api/proxy"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/memcache"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/defaulter"
+)
+
+const (
+	_defaultBlobCapacity    = 1 << 16
+	_defaultBlobMaxSize     = 1 << 16
+	_defaultBlobExpirationS = 300
+
+	blobExpiryHeaderSize = 8
+	maxRangesPerBlob     = 16
+)
+
+var errBlobTooLarge = errors.New("blob range too large to cache")
+
+// ConfigBlobCache is setting of blob cache, it caches data of hot small blobs
+// keyed by (vid, bid, range) in memory and an optional diskv tier on SSD.
+// Access fills the cache after reading from blobnodes when it missed.
+type ConfigBlobCache struct {
+	BlobCacheEnable   bool   `json:"blob_cache_enable"`
+	BlobDiskvBasePath string `json:"blob_diskv_base_path"` // disable diskv tier if empty
+	BlobDiskvTempDir  string `json:"blob_diskv_temp_dir"`
+
+	BlobCapacity    int `json:"blob_capacity"` // max blobs in memory
+	BlobMaxSize     int `json:"blob_max_size"` // max bytes of one cached range
+	BlobExpirationS int `json:"blob_expiration_seconds"`
+}
+
+// BlobCacher read-through cache of blob data.
+type BlobCacher interface {
+	GetBlob(ctx context.Context, args *proxy.CacheBlobArgs) ([]byte, error)
+	PutBlob(ctx context.Context, args *proxy.CacheBlobArgs, data []byte) error
+	// EraseBlob removes all cached ranges of the blob.
+	EraseBlob(ctx context.Context, vid proto.Vid, bid proto.BlobID) error
+	Close()
+}
+
+type expiryBlob struct {
+	data     []byte
+	expiryAt int64 // seconds
+}
+
+func (b *expiryBlob) Expired() bool {
+	return b.expiryAt > 0 && time.Now().Unix() >= b.expiryAt
+}
+
+func encodeBlob(b *expiryBlob) []byte {
+	data := make([]byte, blobExpiryHeaderSize+len(b.data))
+	binary.BigEndian.PutUint64(data, uint64(b.expiryAt))
+	copy(data[blobExpiryHeaderSize:], b.data)
+	return data
+}
+
+func decodeBlob(data []byte) (valueExpired, error) {
+	if len(data) < blobExpiryHeaderSize {
+		return nil, errors.New("invalid blob data")
+	}
+	return &expiryBlob{
+		data:     data[blobExpiryHeaderSize:],
+		expiryAt: int64(binary.BigEndian.Uint64(data)),
+	}, nil
+}
+
+// blobRanges all cached ranges of one blob in memory.
+type blobRanges struct {
+	sync.RWMutex
+	ranges map[string]*expiryBlob
+}
+
+type blobCacher struct {
+	config    ConfigBlobCache
+	clusterID proto.ClusterID
+
+	blobCache *memcache.MemCache
+	blobDiskv *diskv.Diskv
+	closeCh   chan struct{}
+}
+
+// NewBlobCacher returns a BlobCacher, returns nil if blob cache is disabled.
+func NewBlobCacher(clusterID proto.ClusterID, config ConfigBlobCache) (BlobCacher, error) {
+	if !config.BlobCacheEnable {
+		return nil, nil
+	}
+	defaulter.LessOrEqual(&config.BlobCapacity, _defaultBlobCapacity)
+	defaulter.LessOrEqual(&config.BlobMaxSize, _defaultBlobMaxSize)
+	defaulter.LessOrEqual(&config.BlobExpirationS, _defaultBlobExpirationS)
+
+	bc, err := memcache.NewMemCache(config.BlobCapacity)
+	if err != nil {
+		return nil, err
+	}
+	c := &blobCacher{
+		config:    config,
+		clusterID: clusterID,
+		blobCache: bc,
+		closeCh:   make(chan struct{}),
+	}
+	if config.BlobDiskvBasePath != "" {
+		c.blobDiskv = diskv.New(diskv.Options{
+			BasePath:  config.BlobDiskvBasePath,
+			TempDir:   config.BlobDiskvTempDir,
+			Transform: proxy.DiskvPathTransform,
+		})
+	}
+	go c.cleanLoop()
+	return c, nil
+}
+
+func (c *blobCacher) GetBlob(ctx context.Context, args *proxy.CacheBlobArgs) ([]byte, error) {
+	span := trace.SpanFromContextSafe(ctx)
+	blobKey, key := args.BlobKey(), args.Key()
+
+	if val := c.blobCache.Get(blobKey); val != nil {
+		ranges := val.(*blobRanges)
+		ranges.RLock()
+		b, ok := ranges.ranges[key]
+		ranges.RUnlock()
+		if ok {
+			if !b.Expired() {
+				c.blobReport("memcache", "hit")
+				return b.data, nil
+			}
+			c.blobReport("memcache", "expired")
+		}
+	}
+	c.blobReport("memcache", "miss")
+
+	if c.blobDiskv == nil {
+		return nil, errcode.ErrBlobCacheMiss
+	}
+	data, err := c.blobDiskv.Read(key)
+	if err != nil {
+		c.blobReport("diskv", "miss")
+		return nil, errcode.ErrBlobCacheMiss
+	}
+	val, err := decodeBlob(data)
+	if err != nil {
+		c.blobReport("diskv", "error")
+		span.Warnf("decode blob key:%s %s", key, err.Error())
+		return nil, errcode.ErrBlobCacheMiss
+	}
+	b := val.(*expiryBlob)
+	if b.Expired() || uint64(len(b.data)) != args.Size {
+		c.blobReport("diskv", "expired")
+		c.blobDiskv.Erase(key)
+		return nil, errcode.ErrBlobCacheMiss
+	}
+	c.blobReport("diskv", "hit")
+	c.setMemory(blobKey, key, b)
+	return b.data, nil
+}
+
+func (c *blobCacher) PutBlob(ctx context.Context, args *proxy.CacheBlobArgs, data []byte) error {
+	if args.Size != uint64(len(data)) {
+		return errcode.ErrIllegalArguments
+	}
+	if len(data) > c.config.BlobMaxSize {
+		return errBlobTooLarge
+	}
+
+	span := trace.SpanFromContextSafe(ctx)
+	expire := c.config.BlobExpirationS
+	// random expiration to avoid hot blobs expiring at the same time.
+	expiration := rand.Intn(expire/2+1) + expire
+	b := &expiryBlob{
+		data:     data,
+		expiryAt: time.Now().Add(time.Second * time.Duration(expiration)).Unix(),
+	}
+	c.setMemory(args.BlobKey(), args.Key(), b)
+
+	if c.blobDiskv != nil {
+		if err := c.blobDiskv.Write(args.Key(), encodeBlob(b)); err != nil {
+			span.Warnf("write blob diskv key:%s error:%s", args.Key(), err.Error())
+		}
+	}
+	return nil
+}
+
+func (c *blobCacher) EraseBlob(ctx context.Context, vid proto.Vid, bid proto.BlobID) error {
+	args := &proxy.CacheBlobArgs{Vid: vid, Bid: bid}
+	blobKey := args.BlobKey()
+	c.blobCache.Remove(blobKey)
+	if c.blobDiskv == nil {
+		return nil
+	}
+
+	pathKey := c.blobDiskv.AdvancedTransform(blobKey)
+	dir := filepath.Join(c.blobDiskv.BasePath, filepath.Join(pathKey.Path...))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), blobKey+"_") {
+			if err = c.blobDiskv.Erase(entry.Name()); err != nil {
+				trace.SpanFromContextSafe(ctx).Warnf("erase blob diskv key:%s error:%s", entry.Name(), err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+func (c *blobCacher) setMemory(blobKey, key string, b *expiryBlob) {
+	var ranges *blobRanges
+	if val := c.blobCache.Get(blobKey); val != nil {
+		ranges = val.(*blobRanges)
+	} else {
+		ranges = &blobRanges{ranges: make(map[string]*expiryBlob)}
+		c.blobCache.Set(blobKey, ranges)
+	}
+	ranges.Lock()
+	if len(ranges.ranges) >= maxRangesPerBlob {
+		ranges.ranges = make(map[string]*expiryBlob)
+	}
+	ranges.ranges[key] = b
+	ranges.Unlock()
+}
+
+func (c *blobCacher) Close() {
+	close(c.closeCh)
+}
+
+// cleanLoop removes expired blobs in memory and diskv, which will never be read again.
+func (c *blobCacher) cleanLoop() {
+	interval := time.Second * time.Duration(c.config.BlobExpirationS)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.closeCh:
+			return
+		case <-ticker.C:
+		}
+		c.cleanMemory()
+		if c.blobDiskv != nil {
+			c.cleanDiskv(time.Now().Add(-2 * interval))
+		}
+	}
+}
+
+// cleanMemory removes expired ranges, and the blobs without any range,
+// without changing the recently used order of blobs.
+func (c *blobCacher) cleanMemory() {
+	for _, blobKey := range c.blobCache.Keys() {
+		val, ok := c.blobCache.Peek(blobKey)
+		if !ok {
+			continue
+		}
+		ranges := val.(*blobRanges)
+		ranges.Lock()
+		for key, b := range ranges.ranges {
+			if b.Expired() {
+				delete(ranges.ranges, key)
+				c.blobReport("memcache", "clean")
+			}
+		}
+		empty := len(ranges.ranges) == 0
+		ranges.Unlock()
+		if empty {
+			c.blobCache.Remove(blobKey)
+		}
+	}
+}
+
+func (c *blobCacher) cleanDiskv(expired time.Time) {
+	filepath.Walk(c.blobDiskv.BasePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasPrefix(info.Name(), "blob-") {
+			return nil
+		}
+		if info.ModTime().Before(expired) {
+			c.blobDiskv.Erase(info.Name())
+			c.blobReport("diskv", "clean")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,133 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cacher
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/proxy"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+)
+
+func newBlobCacher(t *testing.T, basePath string) BlobCacher {
+	c, err := NewBlobCacher(1, ConfigBlobCache{
+		BlobCacheEnable:   true,
+		BlobDiskvBasePath: basePath,
+		BlobMaxSize:       1 << 10,
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func TestProxyBlobCacherDisabled(t *testing.T) {
+	c, err := NewBlobCacher(1, ConfigBlobCache{})
+	require.NoError(t, err)
+	require.Nil(t, c)
+}
+
+func TestProxyBlobCacherGetPut(t *testing.T) {
+	ctx := context.Background()
+	basePath := path.Join(os.TempDir(), "proxy-cacher", fmt.Sprintf("blob-%d", rand.Intn(10000)+10000))
+	defer os.RemoveAll(basePath)
+	c := newBlobCacher(t, basePath)
+	defer c.Close()
+
+	data := []byte("hot small blob")
+	args := &proxy.CacheBlobArgs{Vid: 1, Bid: 10, Offset: 0, Size: uint64(len(data))}
+	_, err := c.GetBlob(ctx, args)
+	require.ErrorIs(t, err, errcode.ErrBlobCacheMiss)
+
+	require.ErrorIs(t, c.PutBlob(ctx, args, data[1:]), errcode.ErrIllegalArguments)
+	require.Error(t, c.PutBlob(ctx, &proxy.CacheBlobArgs{Vid: 1, Bid: 10, Size: 2 << 10}, make([]byte, 2<<10)))
+
+	require.NoError(t, c.PutBlob(ctx, args, data))
+	val, err := c.GetBlob(ctx, args)
+	require.NoError(t, err)
+	require.Equal(t, data, val)
+
+	other := &proxy.CacheBlobArgs{Vid: 1, Bid: 10, Offset: 1, Size: 4}
+	require.NoError(t, c.PutBlob(ctx, other, data[1:5]))
+
+	// hits on diskv after memory purged
+	c.(*blobCacher).blobCache.Purge()
+	val, err = c.GetBlob(ctx, other)
+	require.NoError(t, err)
+	require.Equal(t, data[1:5], val)
+
+	// erase all ranges of the blob
+	require.NoError(t, c.EraseBlob(ctx, 1, 10))
+	_, err = c.GetBlob(ctx, args)
+	require.ErrorIs(t, err, errcode.ErrBlobCacheMiss)
+	_, err = c.GetBlob(ctx, other)
+	require.ErrorIs(t, err, errcode.ErrBlobCacheMiss)
+	require.NoError(t, c.EraseBlob(ctx, 1, 11))
+}
+
+func TestProxyBlobCacherExpired(t *testing.T) {
+	ctx := context.Background()
+	c := newBlobCacher(t, "")
+	defer c.Close()
+
+	data := []byte("expired blob")
+	args := &proxy.CacheBlobArgs{Vid: 2, Bid: 20, Size: uint64(len(data))}
+	require.NoError(t, c.PutBlob(ctx, args, data))
+
+	bc := c.(*blobCacher)
+	ranges := bc.blobCache.Get(args.BlobKey()).(*blobRanges)
+	ranges.ranges[args.Key()].expiryAt = 1
+	_, err := c.GetBlob(ctx, args)
+	require.ErrorIs(t, err, errcode.ErrBlobCacheMiss)
+
+	val, err := decodeBlob(encodeBlob(&expiryBlob{data: data, expiryAt: 1}))
+	require.NoError(t, err)
+	require.True(t, val.Expired())
+	require.Equal(t, data, val.(*expiryBlob).data)
+	_, err = decodeBlob(nil)
+	require.Error(t, err)
+}
+
+func TestProxyBlobCacherCleanMemory(t *testing.T) {
+	ctx := context.Background()
+	c := newBlobCacher(t, "")
+	defer c.Close()
+	bc := c.(*blobCacher)
+
+	data := []byte("blob")
+	expired := &proxy.CacheBlobArgs{Vid: 3, Bid: 30, Size: uint64(len(data))}
+	require.NoError(t, c.PutBlob(ctx, expired, data))
+	alive := &proxy.CacheBlobArgs{Vid: 3, Bid: 31, Size: uint64(len(data))}
+	require.NoError(t, c.PutBlob(ctx, alive, data))
+	partial := &proxy.CacheBlobArgs{Vid: 3, Bid: 32, Size: uint64(len(data))}
+	require.NoError(t, c.PutBlob(ctx, partial, data))
+	partialAlive := &proxy.CacheBlobArgs{Vid: 3, Bid: 32, Offset: 1, Size: uint64(len(data))}
+	require.NoError(t, c.PutBlob(ctx, partialAlive, data))
+
+	bc.blobCache.Get(expired.BlobKey()).(*blobRanges).ranges[expired.Key()].expiryAt = 1
+	bc.blobCache.Get(partial.BlobKey()).(*blobRanges).ranges[partial.Key()].expiryAt = 1
+	bc.cleanMemory()
+
+	require.Nil(t, bc.blobCache.Get(expired.BlobKey()))
+	require.Equal(t, 1, len(bc.blobCache.Get(alive.BlobKey()).(*blobRanges).ranges))
+	ranges := bc.blobCache.Get(partial.BlobKey()).(*blobRanges).ranges
+	require.Equal(t, 1, len(ranges))
+	require.NotNil(t, ranges[partialAlive.Key()])
+}
@@ -43,3 +43,7 @@ func (c *cacher) volumeReport(name, action string) {
 func (c *cacher) diskReport(name, action string) {
 	c.metricReport("disk", name, action)
 }
+
+func (c *blobCacher) blobReport(name, action string) {
+	cacheMetric.WithLabelValues(c.clusterID.ToString(), "blob", name, action).Inc()
+}
@@ -77,5 +77,13 @@ func (s *Service) SendDeleteMessage(c *rpc.Context) {
 		return
 	}
 
+	if s.blobCacher != nil {
+		for _, blob := range args.Blobs {
+			if err = s.blobCacher.EraseBlob(ctx, blob.Vid, blob.Bid); err != nil {
+				span.Warnf("erase blob cache vid:%d bid:%d error:%s", blob.Vid, blob.Bid, err.Error())
+			}
+		}
+	}
+
 	c.Respond()
 }
@@ -40,6 +40,7 @@ type Producer interface {
 type BlobDeleteConfig struct {
 	Topic        string            `json:"topic"`
 	MsgSenderCfg kafka.ProducerCfg `json:"msg_sender_cfg"`
+	Failover     FailoverConfig    `json:"failover"`
 }
 
 // blobDeleteMgr is blob delete manager
@@ -50,7 +51,7 @@ type blobDeleteMgr struct {
 
 // NewBlobDeleteMgr returns blob delete manager to handle delete message
 func NewBlobDeleteMgr(cfg BlobDeleteConfig) (*blobDeleteMgr, error) {
-	delMsgSender, err := kafka.NewProducer(&cfg.MsgSenderCfg)
+	delMsgSender, err := newMsgSender(cfg.MsgSenderCfg, cfg.Failover)
 	if err != nil {
 		return nil, err
 	}
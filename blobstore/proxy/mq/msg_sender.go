@@ -0,0 +1,149 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package mq
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/common/kafka"
+	"github.com/cubefs/cubefs/blobstore/util/log"
+)
+
+const defaultFailbackIntervalS = 60
+
+// ClusterConfig is the config of one kafka cluster
+type ClusterConfig struct {
+	Name         string            `json:"name"`
+	MsgSenderCfg kafka.ProducerCfg `json:"msg_sender"`
+}
+
+// FailoverConfig is the config of multiple kafka clusters, the messages of a topic are sent to
+// the first available cluster of its route, which fails over to the next one on error
+type FailoverConfig struct {
+	// primary first, then the standby clusters
+	Clusters []ClusterConfig `json:"clusters"`
+	// topic => names of the clusters in failover order, all clusters if not set
+	Routes map[string][]string `json:"routes"`
+	// a failed cluster is skipped until the interval passed
+	FailbackIntervalS int `json:"failback_interval_s"`
+}
+
+type producerCluster struct {
+	name     string
+	producer kafka.MsgProducer
+	failedAt int64 // unix nano, 0 if available
+}
+
+type failoverProducer struct {
+	clusters         []*producerCluster
+	routes           map[string][]*producerCluster
+	failbackInterval time.Duration
+}
+
+// newMsgSender returns the producer of the single cluster if no multiple clusters configured
+func newMsgSender(cfg kafka.ProducerCfg, failover FailoverConfig) (Producer, error) {
+	if len(failover.Clusters) == 0 {
+		return kafka.NewProducer(&cfg)
+	}
+	return NewFailoverProducer(failover, func(cfg *kafka.ProducerCfg) (kafka.MsgProducer, error) {
+		return kafka.NewProducer(cfg)
+	})
+}
+
+// NewFailoverProducer returns a producer sending messages to multiple kafka clusters
+func NewFailoverProducer(cfg FailoverConfig,
+	newProducer func(cfg *kafka.ProducerCfg) (kafka.MsgProducer, error),
+) (Producer, error) {
+	if len(cfg.Clusters) == 0 {
+		return nil, fmt.Errorf("no kafka cluster")
+	}
+	if cfg.FailbackIntervalS <= 0 {
+		cfg.FailbackIntervalS = defaultFailbackIntervalS
+	}
+	p := &failoverProducer{
+		routes:           make(map[string][]*producerCluster, len(cfg.Routes)),
+		failbackInterval: time.Duration(cfg.FailbackIntervalS) * time.Second,
+	}
+	byName := make(map[string]*producerCluster, len(cfg.Clusters))
+	for i := range cfg.Clusters {
+		c := cfg.Clusters[i]
+		if _, ok := byName[c.Name]; ok {
+			return nil, fmt.Errorf("duplicated kafka cluster[%s]", c.Name)
+		}
+		producer, err := newProducer(&c.MsgSenderCfg)
+		if err != nil {
+			return nil, fmt.Errorf("new producer of kafka cluster[%s]: %w", c.Name, err)
+		}
+		cluster := &producerCluster{name: c.Name, producer: producer}
+		byName[c.Name] = cluster
+		p.clusters = append(p.clusters, cluster)
+	}
+	for topic, names := range cfg.Routes {
+		for _, name := range names {
+			cluster, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown kafka cluster[%s] of topic[%s]", name, topic)
+			}
+			p.routes[topic] = append(p.routes[topic], cluster)
+		}
+	}
+	return p, nil
+}
+
+func (p *failoverProducer) SendMessage(topic string, msg []byte) error {
+	return p.send(topic, func(producer kafka.MsgProducer) error {
+		return producer.SendMessage(topic, msg)
+	})
+}
+
+func (p *failoverProducer) SendMessages(topic string, msgs [][]byte) error {
+	return p.send(topic, func(producer kafka.MsgProducer) error {
+		return producer.SendMessages(topic, msgs)
+	})
+}
+
+// send tries the available clusters of the topic in order, and the failed ones at last
+func (p *failoverProducer) send(topic string, fn func(producer kafka.MsgProducer) error) (err error) {
+	clusters, ok := p.routes[topic]
+	if !ok {
+		clusters = p.clusters
+	}
+	now := time.Now().UnixNano()
+	ordered := make([]*producerCluster, 0, len(clusters))
+	failed := make([]*producerCluster, 0)
+	for _, c := range clusters {
+		if failedAt := atomic.LoadInt64(&c.failedAt); failedAt > 0 && now-failedAt < int64(p.failbackInterval) {
+			failed = append(failed, c)
+			continue
+		}
+		ordered = append(ordered, c)
+	}
+	ordered = append(ordered, failed...)
+
+	for _, c := range ordered {
+		if err = fn(c.producer); err == nil {
+			if atomic.SwapInt64(&c.failedAt, 0) > 0 {
+				log.Infof("kafka cluster[%s] recovered, topic[%s]", c.name, topic)
+			}
+			return nil
+		}
+		if atomic.SwapInt64(&c.failedAt, time.Now().UnixNano()) == 0 {
+			log.Warnf("kafka cluster[%s] failed over, topic[%s], err: %s", c.name, topic, err)
+		}
+	}
+	return err
+}
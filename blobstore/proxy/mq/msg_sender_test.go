@@ -0,0 +1,104 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package mq
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/kafka"
+)
+
+type fakeProducer struct {
+	fail  bool
+	count map[string]int
+}
+
+func (p *fakeProducer) SendMessage(topic string, msg []byte) error {
+	return p.SendMessages(topic, [][]byte{msg})
+}
+
+func (p *fakeProducer) SendMessages(topic string, msgs [][]byte) error {
+	if p.fail {
+		return ErrSendMessage
+	}
+	p.count[topic] += len(msgs)
+	return nil
+}
+
+func newFakeProducers(names ...string) (map[string]*fakeProducer, func(cfg *kafka.ProducerCfg) (kafka.MsgProducer, error)) {
+	producers := make(map[string]*fakeProducer)
+	for _, name := range names {
+		producers[name] = &fakeProducer{count: make(map[string]int)}
+	}
+	return producers, func(cfg *kafka.ProducerCfg) (kafka.MsgProducer, error) {
+		return producers[cfg.Topic], nil
+	}
+}
+
+func TestFailoverProducer(t *testing.T) {
+	producers, newProducer := newFakeProducers("primary", "standby")
+	cfg := FailoverConfig{
+		Clusters: []ClusterConfig{
+			{Name: "primary", MsgSenderCfg: kafka.ProducerCfg{Topic: "primary"}},
+			{Name: "standby", MsgSenderCfg: kafka.ProducerCfg{Topic: "standby"}},
+		},
+		Routes: map[string][]string{"repair": {"standby"}},
+	}
+	producer, err := NewFailoverProducer(cfg, newProducer)
+	require.NoError(t, err)
+	fp := producer.(*failoverProducer)
+
+	require.NoError(t, producer.SendMessage("delete", nil))
+	require.NoError(t, producer.SendMessage("repair", nil))
+	require.Equal(t, 1, producers["primary"].count["delete"])
+	require.Equal(t, 1, producers["standby"].count["repair"])
+
+	// fail over to the standby, and skip the failed primary
+	producers["primary"].fail = true
+	require.NoError(t, producer.SendMessages("delete", [][]byte{nil, nil}))
+	require.Equal(t, 2, producers["standby"].count["delete"])
+	require.True(t, fp.clusters[0].failedAt > 0)
+	producers["primary"].fail = false
+	require.NoError(t, producer.SendMessage("delete", nil))
+	require.Equal(t, 3, producers["standby"].count["delete"])
+
+	// fail back to the primary after the interval
+	fp.clusters[0].failedAt = time.Now().Add(-fp.failbackInterval).UnixNano()
+	require.NoError(t, producer.SendMessage("delete", nil))
+	require.Equal(t, 2, producers["primary"].count["delete"])
+	require.Equal(t, int64(0), fp.clusters[0].failedAt)
+
+	// the failed clusters are tried at last
+	producers["standby"].fail = true
+	require.ErrorIs(t, producer.SendMessage("repair", nil), ErrSendMessage)
+	require.True(t, fp.clusters[1].failedAt > 0)
+	producers["standby"].fail = false
+	require.NoError(t, producer.SendMessage("repair", nil))
+	require.Equal(t, 2, producers["standby"].count["repair"])
+
+	// illegal config
+	_, err = NewFailoverProducer(FailoverConfig{}, newProducer)
+	require.Error(t, err)
+	cfg.Clusters = append(cfg.Clusters, ClusterConfig{Name: "primary"})
+	_, err = NewFailoverProducer(cfg, newProducer)
+	require.Error(t, err)
+	cfg.Clusters = cfg.Clusters[:2]
+	cfg.Routes["delete"] = []string{"unknown"}
+	_, err = NewFailoverProducer(cfg, newProducer)
+	require.Error(t, err)
+}
@@ -36,11 +36,12 @@ type ShardRepairConfig struct {
 	Topic         string            `json:"topic"`
 	PriorityTopic string            `json:"priority_topic"`
 	MsgSenderCfg  kafka.ProducerCfg `json:"msg_sender_cfg"`
+	Failover      FailoverConfig    `json:"failover"`
 }
 
 // NewShardRepairMgr returns shard repair manager
 func NewShardRepairMgr(cfg ShardRepairConfig) (*shardRepairMgr, error) {
-	shardRepairMsgSender, err := kafka.NewProducer(&cfg.MsgSenderCfg)
+	shardRepairMsgSender, err := newMsgSender(cfg.MsgSenderCfg, cfg.Failover)
 	if err != nil {
 		return nil, err
 	}
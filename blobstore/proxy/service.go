@@ -50,6 +50,15 @@ var (
 	// ErrIllegalTopic illegal topic
 	ErrIllegalTopic = errors.New("illegal topic")
 	ErrIllegalKafka = errors.New("illegal kafka version")
+	// ErrIllegalKafkaCluster illegal kafka cluster
+	ErrIllegalKafkaCluster = errors.New("illegal kafka cluster")
+)
+
+// message types to route to the kafka clusters
+const (
+	MsgTypeBlobDelete          = "blob_delete"
+	MsgTypeShardRepair         = "shard_repair"
+	MsgTypeShardRepairPriority = "shard_repair_priority"
 )
 
 // MQConfig is mq config
@@ -59,6 +68,59 @@ type MQConfig struct {
 	ShardRepairPriorityTopic string            `json:"shard_repair_priority_topic"`
 	MsgSender                kafka.ProducerCfg `json:"msg_sender"`
 	Version                  string            `json:"version"`
+
+	// multiple kafka clusters in failover order, msg_sender is ignored if set
+	Clusters []mq.ClusterConfig `json:"clusters"`
+	// message type => names of the clusters in failover order, all clusters if not set
+	Routes            map[string][]string `json:"routes"`
+	FailbackIntervalS int                 `json:"failback_interval_s"`
+}
+
+func (c *MQConfig) topic(msgType string) string {
+	switch msgType {
+	case MsgTypeBlobDelete:
+		return c.BlobDeleteTopic
+	case MsgTypeShardRepair:
+		return c.ShardRepairTopic
+	case MsgTypeShardRepairPriority:
+		return c.ShardRepairPriorityTopic
+	}
+	return ""
+}
+
+func (c *MQConfig) failoverCfg() mq.FailoverConfig {
+	cfg := mq.FailoverConfig{
+		Clusters:          c.Clusters,
+		Routes:            make(map[string][]string, len(c.Routes)),
+		FailbackIntervalS: c.FailbackIntervalS,
+	}
+	for msgType, names := range c.Routes {
+		cfg.Routes[c.topic(msgType)] = names
+	}
+	return cfg
+}
+
+func (c *MQConfig) checkClusters() error {
+	names := make(map[string]struct{}, len(c.Clusters))
+	for i := range c.Clusters {
+		cluster := &c.Clusters[i]
+		if _, ok := names[cluster.Name]; ok || cluster.Name == "" || len(cluster.MsgSenderCfg.BrokerList) == 0 {
+			return ErrIllegalKafkaCluster
+		}
+		names[cluster.Name] = struct{}{}
+		defaulter.LessOrEqual(&cluster.MsgSenderCfg.TimeoutMs, defaultTimeoutMS)
+	}
+	for msgType, routes := range c.Routes {
+		if c.topic(msgType) == "" || len(c.Clusters) == 0 {
+			return ErrIllegalKafkaCluster
+		}
+		for _, name := range routes {
+			if _, ok := names[name]; !ok {
+				return ErrIllegalKafkaCluster
+			}
+		}
+	}
+	return nil
 }
 
 type Config struct {
@@ -67,6 +129,7 @@ type Config struct {
 	alloc.BlobConfig
 	alloc.VolConfig
 	cacher.ConfigCache
+	cacher.ConfigBlobCache
 
 	HeartbeatIntervalS uint32            `json:"heartbeat_interval_s"` // proxy heartbeat interval to ClusterManager
 	HeartbeatTicks     uint32            `json:"heartbeat_ticks"`
@@ -79,6 +142,7 @@ func (c *Config) blobDeleteCfg() mq.BlobDeleteConfig {
 	return mq.BlobDeleteConfig{
 		Topic:        c.MQ.BlobDeleteTopic,
 		MsgSenderCfg: c.MQ.MsgSender,
+		Failover:     c.MQ.failoverCfg(),
 	}
 }
 
@@ -87,6 +151,7 @@ func (c *Config) shardRepairCfg() mq.ShardRepairConfig {
 		Topic:         c.MQ.ShardRepairTopic,
 		PriorityTopic: c.MQ.ShardRepairPriorityTopic,
 		MsgSenderCfg:  c.MQ.MsgSender,
+		Failover:      c.MQ.failoverCfg(),
 	}
 }
 
@@ -99,7 +164,8 @@ type Service struct {
 	// allocator
 	volumeMgr alloc.VolumeMgr
 	// cacher
-	cacher cacher.Cacher
+	cacher     cacher.Cacher
+	blobCacher cacher.BlobCacher // nil if blob cache is disabled
 }
 
 func init() {
@@ -128,6 +194,9 @@ func setUp() (*rpc.Router, []rpc.ProgressHandler) {
 
 func tearDown() {
 	service.volumeMgr.Close()
+	if service.blobCacher != nil {
+		service.blobCacher.Close()
+	}
 }
 
 func New(cfg Config, cmcli clustermgr.APIProxy) *Service {
@@ -151,6 +220,10 @@ func New(cfg Config, cmcli clustermgr.APIProxy) *Service {
 		log.Fatalf("fail to new volumeMgr, error: %s", err.Error())
 	}
 
+	blobCacher, err := cacher.NewBlobCacher(cfg.ClusterID, cfg.ConfigBlobCache)
+	if err != nil {
+		log.Fatalf("fail to new blob cacher, error: %s", err.Error())
+	}
 	cacher, err := cacher.New(cfg.ClusterID, cfg.ConfigCache, cmcli)
 	if err != nil {
 		log.Fatalf("fail to new cacher, error: %s", err.Error())
@@ -172,6 +245,7 @@ func New(cfg Config, cmcli clustermgr.APIProxy) *Service {
 		Config:         cfg,
 		volumeMgr:      volumeMgr,
 		cacher:         cacher,
+		blobCacher:     blobCacher,
 		shardRepairMgr: shardRepairMgr,
 		blobDeleteMgr:  blobDeleteMgr,
 	}
@@ -182,6 +256,7 @@ func NewHandler(service *Service) *rpc.Router {
 	rpc.RegisterArgsParser(&proxy.ListVolsArgs{}, "json")
 	rpc.RegisterArgsParser(&proxy.CacheVolumeArgs{}, "json")
 	rpc.RegisterArgsParser(&proxy.CacheDiskArgs{}, "json")
+	rpc.RegisterArgsParser(&proxy.CacheBlobArgs{}, "json")
 	rpc.RegisterArgsParser(&proxy.DiscardVolsArgs{}, "json")
 
 	// POST /volume/alloc
@@ -212,6 +287,13 @@ func NewHandler(service *Service) *rpc.Router {
 	router.Handle(http.MethodGet, "/cache/disk/:disk_id", service.GetCacheDisk, rpc.OptArgsURI(), rpc.OptArgsQuery())
 	router.Handle(http.MethodDelete, "/cache/erase/:key", service.EraseCache, rpc.OptArgsURI())
 
+	// GET /cache/blob/{vid}/{bid}?offset={offset}&size={size}
+	// response body: blob data
+	router.Handle(http.MethodGet, "/cache/blob/:vid/:bid", service.GetCacheBlob, rpc.OptArgsURI(), rpc.OptArgsQuery())
+	// PUT /cache/blob/{vid}/{bid}?offset={offset}&size={size}
+	// request body: blob data
+	router.Handle(http.MethodPut, "/cache/blob/:vid/:bid", service.PutCacheBlob, rpc.OptArgsURI(), rpc.OptArgsQuery())
+
 	return router
 }
 
@@ -229,6 +311,9 @@ func (c *Config) checkAndFix() (err error) {
 	defaulter.Equal(&c.ExpiresTicks, defaultExpiresTicks)
 	defaulter.LessOrEqual(&c.Clustermgr.Config.ClientTimeoutMs, defaultTimeoutMS)
 	defaulter.LessOrEqual(&c.MQ.MsgSender.TimeoutMs, defaultTimeoutMS)
+	if err = c.MQ.checkClusters(); err != nil {
+		return err
+	}
 	if c.MQ.Version != "" {
 		kafkaVersion, err := sarama.ParseKafkaVersion(c.MQ.Version)
 		if err != nil {
@@ -16,6 +16,7 @@ package proxy
 
 import (
 	"context"
+	"net/http"
 	"net/http/httptest"
 	"sync"
 	"testing"
@@ -33,7 +34,9 @@ import (
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
 	"github.com/cubefs/cubefs/blobstore/proxy/allocator"
+	"github.com/cubefs/cubefs/blobstore/proxy/cacher"
 	"github.com/cubefs/cubefs/blobstore/proxy/mock"
+	"github.com/cubefs/cubefs/blobstore/proxy/mq"
 	_ "github.com/cubefs/cubefs/blobstore/testing/nolog"
 	"github.com/cubefs/cubefs/blobstore/util/errors"
 )
@@ -407,6 +410,48 @@ func TestService_CacherErase(t *testing.T) {
 	}
 }
 
+func TestService_CacherBlob(t *testing.T) {
+	svr := newMockService(t)
+	server := httptest.NewServer(NewHandler(svr))
+	defer server.Close()
+	url := server.URL
+	cli := proxy.New(&proxy.Config{})
+	data := []byte("blob")
+	args := &proxy.CacheBlobArgs{Vid: 1, Bid: 1, Size: uint64(len(data))}
+
+	// disabled
+	_, err := cli.GetCacheBlob(ctx, url, args)
+	require.Equal(t, errcode.CodeBlobCacheMiss, rpc.DetectStatusCode(err))
+	require.NoError(t, cli.PutCacheBlob(ctx, url, args, data))
+	require.NoError(t, cli.Erase(ctx, url, args.BlobKey()))
+
+	svr.blobCacher, err = cacher.NewBlobCacher(1, cacher.ConfigBlobCache{BlobCacheEnable: true})
+	require.NoError(t, err)
+	defer svr.blobCacher.Close()
+	_, err = cli.GetCacheBlob(ctx, url, args)
+	require.Equal(t, errcode.CodeBlobCacheMiss, rpc.DetectStatusCode(err))
+	require.NoError(t, cli.PutCacheBlob(ctx, url, args, data))
+	val, err := cli.GetCacheBlob(ctx, url, args)
+	require.NoError(t, err)
+	require.Equal(t, data, val)
+
+	// invalidated on delete
+	err = newClient().PostWith(ctx, url+"/deletemsg", nil, proxy.DeleteArgs{
+		ClusterID: 1,
+		Blobs:     []proxy.BlobDelete{{Bid: 1, Vid: 1}},
+	})
+	require.NoError(t, err)
+	_, err = cli.GetCacheBlob(ctx, url, args)
+	require.Equal(t, errcode.CodeBlobCacheMiss, rpc.DetectStatusCode(err))
+
+	// invalidated by the erasing broadcast from access
+	require.NoError(t, cli.PutCacheBlob(ctx, url, args, data))
+	require.NoError(t, cli.Erase(ctx, url, args.BlobKey()))
+	_, err = cli.GetCacheBlob(ctx, url, args)
+	require.Equal(t, errcode.CodeBlobCacheMiss, rpc.DetectStatusCode(err))
+	require.Equal(t, http.StatusBadRequest, rpc.DetectStatusCode(cli.Erase(ctx, url, "blob-1")))
+}
+
 func TestConfigFix(t *testing.T) {
 	testCases := []struct {
 		cfg *Config
@@ -418,6 +463,37 @@ func TestConfigFix(t *testing.T) {
 		{cfg: &Config{MQ: MQConfig{BlobDeleteTopic: "test", ShardRepairTopic: "test", ShardRepairPriorityTopic: "test3"}}, err: ErrIllegalTopic},
 		{cfg: &Config{MQ: MQConfig{BlobDeleteTopic: "test", ShardRepairTopic: "test1", ShardRepairPriorityTopic: "test"}}, err: ErrIllegalTopic},
 		{cfg: &Config{MQ: MQConfig{BlobDeleteTopic: "test", ShardRepairTopic: "test1", ShardRepairPriorityTopic: "test3"}}, err: nil},
+		{cfg: &Config{MQ: MQConfig{
+			BlobDeleteTopic: "test", ShardRepairTopic: "test1", ShardRepairPriorityTopic: "test3",
+			Clusters: []mq.ClusterConfig{{Name: "primary"}},
+		}}, err: ErrIllegalKafkaCluster},
+		{cfg: &Config{MQ: MQConfig{
+			BlobDeleteTopic: "test", ShardRepairTopic: "test1", ShardRepairPriorityTopic: "test3",
+			Clusters: []mq.ClusterConfig{
+				{Name: "primary", MsgSenderCfg: kafka.ProducerCfg{BrokerList: []string{"127.0.0.1:9092"}}},
+				{Name: "primary", MsgSenderCfg: kafka.ProducerCfg{BrokerList: []string{"127.0.0.1:9093"}}},
+			},
+		}}, err: ErrIllegalKafkaCluster},
+		{cfg: &Config{MQ: MQConfig{
+			BlobDeleteTopic: "test", ShardRepairTopic: "test1", ShardRepairPriorityTopic: "test3",
+			Routes: map[string][]string{MsgTypeBlobDelete: {"primary"}},
+		}}, err: ErrIllegalKafkaCluster},
+		{cfg: &Config{MQ: MQConfig{
+			BlobDeleteTopic: "test", ShardRepairTopic: "test1", ShardRepairPriorityTopic: "test3",
+			Clusters: []mq.ClusterConfig{
+				{Name: "primary", MsgSenderCfg: kafka.ProducerCfg{BrokerList: []string{"127.0.0.1:9092"}}},
+				{Name: "standby", MsgSenderCfg: kafka.ProducerCfg{BrokerList: []string{"127.0.0.1:9093"}}},
+			},
+			Routes: map[string][]string{"unknown": {"primary"}},
+		}}, err: ErrIllegalKafkaCluster},
+		{cfg: &Config{MQ: MQConfig{
+			BlobDeleteTopic: "test", ShardRepairTopic: "test1", ShardRepairPriorityTopic: "test3",
+			Clusters: []mq.ClusterConfig{
+				{Name: "primary", MsgSenderCfg: kafka.ProducerCfg{BrokerList: []string{"127.0.0.1:9092"}}},
+				{Name: "standby", MsgSenderCfg: kafka.ProducerCfg{BrokerList: []string{"127.0.0.1:9093"}}},
+			},
+			Routes: map[string][]string{MsgTypeBlobDelete: {"primary", "standby"}, MsgTypeShardRepair: {"standby"}},
+		}}, err: nil},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,419 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	api "github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/common/counter"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/scheduler/client"
+	"github.com/cubefs/cubefs/blobstore/util/closer"
+	"github.com/cubefs/cubefs/blobstore/util/log"
+)
+
+const (
+	campaignFlushInterval = time.Minute
+	campaignCounterWindow = int64(counter.SLOT-1) * 60 // seconds of the full minutes in the counter
+)
+
+var (
+	errCampaignExist        = rpc.NewError(http.StatusConflict, "campaign_exist", errors.New("campaign already exists"))
+	errCampaignNotFound     = rpc.NewError(http.StatusNotFound, "campaign_not_found", errors.New("campaign not found"))
+	errCampaignDiskConflict = rpc.NewError(http.StatusConflict, "campaign_disk_conflict", errors.New("disk is in another campaign"))
+	errCampaignNoDisks      = rpc.NewError(http.StatusBadRequest, "campaign_no_disks", errors.New("no disks in the campaign"))
+)
+
+// campaign groups the migrate tasks of the disks, such as a disk drop or an AZ evacuation.
+type campaign struct {
+	proto.MigrateCampaign
+	traffic  counter.Counter // bytes migrated per minute
+	finished counter.Counter // tasks finished per minute
+	dirty    bool
+}
+
+// campaignMgr controls the migrate tasks by campaigns on the leader, the tasks of paused campaign
+// or over the bandwidth budget are not acquired, and the progress is persisted in clustermgr.
+type campaignMgr struct {
+	mu        sync.RWMutex
+	campaigns map[string]*campaign
+	// campaign of the source disk by task type
+	disks map[proto.TaskType]map[proto.DiskID]*campaign
+
+	clusterMgrCli client.ClusterMgrAPI
+	startTime     time.Time
+	closer.Closer
+}
+
+func newCampaignMgr(clusterMgrCli client.ClusterMgrAPI) *campaignMgr {
+	return &campaignMgr{
+		campaigns:     make(map[string]*campaign),
+		disks:         make(map[proto.TaskType]map[proto.DiskID]*campaign),
+		clusterMgrCli: clusterMgrCli,
+		startTime:     time.Now(),
+		Closer:        closer.New(),
+	}
+}
+
+// Load loads campaigns from clustermgr.
+func (mgr *campaignMgr) Load() error {
+	campaigns, err := mgr.clusterMgrCli.ListAllMigrateCampaigns(context.Background())
+	if err != nil {
+		return err
+	}
+	mgr.mu.Lock()
+	for _, mc := range campaigns {
+		mgr.addLocked(&campaign{MigrateCampaign: *mc})
+	}
+	mgr.mu.Unlock()
+	log.Infof("load campaigns: count[%d]", len(campaigns))
+	return nil
+}
+
+// Run persists the progress of campaigns periodically.
+func (mgr *campaignMgr) Run() {
+	go func() {
+		ticker := time.NewTicker(campaignFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				mgr.flush()
+			case <-mgr.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the campaign manager and persists the progress.
+func (mgr *campaignMgr) Close() {
+	mgr.Closer.Close()
+	mgr.flush()
+}
+
+func (mgr *campaignMgr) flush() {
+	span, ctx := trace.StartSpanFromContext(context.Background(), "campaign_flush")
+
+	var dirty []proto.MigrateCampaign
+	mgr.mu.Lock()
+	for _, c := range mgr.campaigns {
+		if c.dirty {
+			dirty = append(dirty, c.MigrateCampaign)
+			c.dirty = false
+		}
+	}
+	mgr.mu.Unlock()
+
+	for i := range dirty {
+		if err := mgr.clusterMgrCli.SetMigrateCampaign(ctx, &dirty[i]); err != nil {
+			span.Errorf("flush campaign failed: id[%s], err[%+v]", dirty[i].ID, err)
+			mgr.mu.Lock()
+			if c, ok := mgr.campaigns[dirty[i].ID]; ok {
+				c.dirty = true
+			}
+			mgr.mu.Unlock()
+		}
+	}
+}
+
+func (mgr *campaignMgr) addLocked(c *campaign) {
+	mgr.campaigns[c.ID] = c
+	disks, ok := mgr.disks[c.TaskType]
+	if !ok {
+		disks = make(map[proto.DiskID]*campaign)
+		mgr.disks[c.TaskType] = disks
+	}
+	for _, diskID := range c.Disks {
+		disks[diskID] = c
+	}
+}
+
+// resolveDisks returns the disks of the campaign and the total tasks of them.
+func (mgr *campaignMgr) resolveDisks(ctx context.Context, args *api.AddCampaignArgs) ([]proto.DiskID, int, error) {
+	var infos []*client.DiskInfoSimple
+	if len(args.Disks) > 0 {
+		seen := make(map[proto.DiskID]struct{}, len(args.Disks))
+		for _, diskID := range args.Disks {
+			if _, ok := seen[diskID]; ok {
+				continue
+			}
+			seen[diskID] = struct{}{}
+			info, err := mgr.clusterMgrCli.GetDiskInfo(ctx, diskID)
+			if err != nil {
+				return nil, 0, err
+			}
+			infos = append(infos, info)
+		}
+	} else {
+		disks, err := mgr.clusterMgrCli.ListClusterDisks(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, disk := range disks {
+			if disk.Idc == args.IDC {
+				infos = append(infos, disk)
+			}
+		}
+	}
+	if len(infos) == 0 {
+		return nil, 0, errCampaignNoDisks
+	}
+
+	disks := make([]proto.DiskID, 0, len(infos))
+	total := 0
+	for _, info := range infos {
+		disks = append(disks, info.DiskID)
+		total += int(info.UsedChunkCnt)
+	}
+	sort.Slice(disks, func(i, j int) bool { return disks[i] < disks[j] })
+	return disks, total, nil
+}
+
+// AddCampaign adds a campaign of the disks or the disks of the idc.
+func (mgr *campaignMgr) AddCampaign(ctx context.Context, args *api.AddCampaignArgs) error {
+	mgr.mu.RLock()
+	_, ok := mgr.campaigns[args.ID]
+	mgr.mu.RUnlock()
+	if ok {
+		return errCampaignExist
+	}
+
+	disks, total, err := mgr.resolveDisks(ctx, args)
+	if err != nil {
+		return err
+	}
+	c := &campaign{MigrateCampaign: proto.MigrateCampaign{
+		ID:            args.ID,
+		TaskType:      args.TaskType,
+		Disks:         disks,
+		BandwidthMBPS: args.BandwidthMBPS,
+		TotalTasksCnt: total,
+		Ctime:         time.Now().Unix(),
+	}}
+
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	if _, ok = mgr.campaigns[args.ID]; ok {
+		return errCampaignExist
+	}
+	for _, diskID := range disks {
+		if _, ok = mgr.disks[args.TaskType][diskID]; ok {
+			return errCampaignDiskConflict
+		}
+	}
+	if err = mgr.clusterMgrCli.SetMigrateCampaign(ctx, &c.MigrateCampaign); err != nil {
+		return err
+	}
+	mgr.addLocked(c)
+	return nil
+}
+
+// update updates the campaign and persists it.
+func (mgr *campaignMgr) update(ctx context.Context, id string, fn func(c *campaign)) error {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	c, ok := mgr.campaigns[id]
+	if !ok {
+		return errCampaignNotFound
+	}
+	mc := c.MigrateCampaign
+	fn(c)
+	if err := mgr.clusterMgrCli.SetMigrateCampaign(ctx, &c.MigrateCampaign); err != nil {
+		c.MigrateCampaign = mc
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// PauseCampaign pauses the tasks of campaign, the running tasks are stopped at the next renewal.
+func (mgr *campaignMgr) PauseCampaign(ctx context.Context, id string) error {
+	return mgr.update(ctx, id, func(c *campaign) { c.Paused = true })
+}
+
+// ResumeCampaign resumes the tasks of campaign.
+func (mgr *campaignMgr) ResumeCampaign(ctx context.Context, id string) error {
+	return mgr.update(ctx, id, func(c *campaign) { c.Paused = false })
+}
+
+// SetCampaignBudget sets the bandwidth budget of campaign, 0 means unlimited.
+func (mgr *campaignMgr) SetCampaignBudget(ctx context.Context, id string, mbps int) error {
+	return mgr.update(ctx, id, func(c *campaign) { c.BandwidthMBPS = mbps })
+}
+
+// DeleteCampaign deletes the campaign, the tasks of the disks are not controlled any more.
+func (mgr *campaignMgr) DeleteCampaign(ctx context.Context, id string) error {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	c, ok := mgr.campaigns[id]
+	if !ok {
+		return errCampaignNotFound
+	}
+	if err := mgr.clusterMgrCli.DeleteMigrateCampaign(ctx, id); err != nil {
+		return err
+	}
+	delete(mgr.campaigns, id)
+	for _, diskID := range c.Disks {
+		delete(mgr.disks[c.TaskType], diskID)
+	}
+	return nil
+}
+
+// ListCampaigns returns the campaigns with the live stats.
+func (mgr *campaignMgr) ListCampaigns() []*api.CampaignStat {
+	now := time.Now()
+	mgr.mu.RLock()
+	stats := make([]*api.CampaignStat, 0, len(mgr.campaigns))
+	for _, c := range mgr.campaigns {
+		stats = append(stats, mgr.statLocked(c, now))
+	}
+	mgr.mu.RUnlock()
+	sort.Slice(stats, func(i, j int) bool { return stats[i].ID < stats[j].ID })
+	return stats
+}
+
+func (mgr *campaignMgr) statLocked(c *campaign, now time.Time) *api.CampaignStat {
+	mbps := mgr.bandwidth(c, now)
+	stat := &api.CampaignStat{
+		MigrateCampaign: c.MigrateCampaign,
+		CurrentMBPS:     mbps,
+		Throttled:       c.Paused || (c.BandwidthMBPS > 0 && mbps >= float64(c.BandwidthMBPS)),
+		ETASec:          mgr.eta(c, now),
+	}
+	stat.Disks = append([]proto.DiskID(nil), c.Disks...)
+	if stat.ETASec > 0 {
+		stat.ETA = now.Add(time.Duration(stat.ETASec) * time.Second).Format(time.RFC3339)
+	}
+	return stat
+}
+
+// bandwidth returns MB per second migrated in the last minute and the current minute.
+func (mgr *campaignMgr) bandwidth(c *campaign, now time.Time) float64 {
+	counts := c.traffic.Show()
+	bytes := counts[counter.SLOT-1] + counts[counter.SLOT-2]
+	seconds := 60 + now.Unix()%60
+	return float64(bytes) / float64(seconds) / (1 << 20)
+}
+
+// eta returns the estimated seconds to complete the campaign by the rate of finished tasks
+// in the recent minutes, -1 means unknown.
+func (mgr *campaignMgr) eta(c *campaign, now time.Time) int64 {
+	remaining := c.TotalTasksCnt - c.MigratedTasksCnt
+	if remaining <= 0 {
+		return 0
+	}
+	if c.Paused {
+		return -1
+	}
+
+	finished := 0
+	for _, cnt := range c.finished.Show() {
+		finished += cnt
+	}
+	window := campaignCounterWindow + now.Unix()%60
+	since := mgr.startTime.Unix()
+	if c.Ctime > since {
+		since = c.Ctime
+	}
+	if elapsed := now.Unix() - since; elapsed < window {
+		window = elapsed
+	}
+	// too few seconds to be a stable rate
+	if window < 60 {
+		window = 60
+	}
+	if finished == 0 {
+		return -1
+	}
+	return int64(remaining) * window / int64(finished)
+}
+
+// parseTaskDiskID returns the source disk of the migrate task id generated by client.GenMigrateTaskID.
+func parseTaskDiskID(taskType proto.TaskType, taskID string) (proto.DiskID, bool) {
+	fields := strings.Split(strings.TrimPrefix(taskID, client.GenMigrateTaskPrefix(taskType)), "-")
+	diskID, err := strconv.ParseUint(fields[0], 10, 32)
+	if err != nil {
+		return proto.InvalidDiskID, false
+	}
+	return proto.DiskID(diskID), true
+}
+
+func (mgr *campaignMgr) campaignOfDisk(taskType proto.TaskType, diskID proto.DiskID) *campaign {
+	return mgr.disks[taskType][diskID]
+}
+
+func (mgr *campaignMgr) campaignOfTask(taskType proto.TaskType, taskID string) *campaign {
+	diskID, ok := parseTaskDiskID(taskType, taskID)
+	if !ok {
+		return nil
+	}
+	return mgr.campaignOfDisk(taskType, diskID)
+}
+
+// AllowAcquire returns false if the campaign of the task is paused or over the bandwidth budget.
+func (mgr *campaignMgr) AllowAcquire(task *proto.MigrateTask) bool {
+	mgr.mu.RLock()
+	defer mgr.mu.RUnlock()
+	c := mgr.campaignOfDisk(task.TaskType, task.SourceDiskID)
+	if c == nil {
+		return true
+	}
+	if c.Paused {
+		return false
+	}
+	return c.BandwidthMBPS == 0 || mgr.bandwidth(c, time.Now()) < float64(c.BandwidthMBPS)
+}
+
+// TaskPaused returns true if the campaign of the task is paused.
+func (mgr *campaignMgr) TaskPaused(taskType proto.TaskType, taskID string) bool {
+	mgr.mu.RLock()
+	defer mgr.mu.RUnlock()
+	c := mgr.campaignOfTask(taskType, taskID)
+	return c != nil && c.Paused
+}
+
+// ReportTask counts the migrated bytes of the task.
+func (mgr *campaignMgr) ReportTask(taskType proto.TaskType, taskID string, increaseBytes int) {
+	if increaseBytes <= 0 {
+		return
+	}
+	mgr.mu.RLock()
+	defer mgr.mu.RUnlock()
+	if c := mgr.campaignOfTask(taskType, taskID); c != nil {
+		c.traffic.AddN(increaseBytes)
+	}
+}
+
+// CompleteTask counts the finished task.
+func (mgr *campaignMgr) CompleteTask(taskType proto.TaskType, taskID string) {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	if c := mgr.campaignOfTask(taskType, taskID); c != nil {
+		c.MigratedTasksCnt++
+		c.finished.Add()
+		c.dirty = true
+	}
+}
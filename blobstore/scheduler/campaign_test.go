@@ -0,0 +1,108 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/scheduler/client"
+)
+
+func newCampaignTaskID(taskType proto.TaskType, diskID proto.DiskID) string {
+	return client.GenMigrateTaskID(taskType, diskID, proto.Vid(1))
+}
+
+func TestParseTaskDiskID(t *testing.T) {
+	diskID, ok := parseTaskDiskID(proto.TaskTypeDiskDrop, newCampaignTaskID(proto.TaskTypeDiskDrop, 12))
+	require.True(t, ok)
+	require.Equal(t, proto.DiskID(12), diskID)
+
+	_, ok = parseTaskDiskID(proto.TaskTypeDiskDrop, "disk_drop-xx-1-xid")
+	require.False(t, ok)
+	_, ok = parseTaskDiskID(proto.TaskTypeDiskDrop, newCampaignTaskID(proto.TaskTypeDiskRepair, 12))
+	require.False(t, ok)
+}
+
+func TestCampaignMgr(t *testing.T) {
+	ctx := context.Background()
+	ctr := gomock.NewController(t)
+	clusterMgrCli := NewMockClusterMgrAPI(ctr)
+	clusterMgrCli.EXPECT().ListAllMigrateCampaigns(any).Return([]*proto.MigrateCampaign{{
+		ID: "loaded", TaskType: proto.TaskTypeDiskRepair, Disks: []proto.DiskID{3}, Paused: true,
+	}}, nil)
+	clusterMgrCli.EXPECT().ListClusterDisks(any).Return([]*client.DiskInfoSimple{testDisk1, testDisk2}, nil)
+	clusterMgrCli.EXPECT().GetDiskInfo(any, any).Times(2).Return(testDisk1, nil)
+	clusterMgrCli.EXPECT().SetMigrateCampaign(any, any).AnyTimes().Return(nil)
+	clusterMgrCli.EXPECT().DeleteMigrateCampaign(any, any).Return(nil)
+
+	mgr := newCampaignMgr(clusterMgrCli)
+	require.NoError(t, mgr.Load())
+	require.True(t, mgr.TaskPaused(proto.TaskTypeDiskRepair, newCampaignTaskID(proto.TaskTypeDiskRepair, 3)))
+	require.False(t, mgr.TaskPaused(proto.TaskTypeDiskDrop, newCampaignTaskID(proto.TaskTypeDiskDrop, 3)))
+	require.False(t, mgr.AllowAcquire(&proto.MigrateTask{TaskType: proto.TaskTypeDiskRepair, SourceDiskID: 3}))
+	require.NoError(t, mgr.ResumeCampaign(ctx, "loaded"))
+	require.True(t, mgr.AllowAcquire(&proto.MigrateTask{TaskType: proto.TaskTypeDiskRepair, SourceDiskID: 3}))
+
+	// evacuate the idc
+	args := &api.AddCampaignArgs{ID: "evacuate", TaskType: proto.TaskTypeDiskDrop, IDC: "z0"}
+	require.NoError(t, mgr.AddCampaign(ctx, args))
+	require.ErrorIs(t, mgr.AddCampaign(ctx, args), errCampaignExist)
+	args = &api.AddCampaignArgs{ID: "drop", TaskType: proto.TaskTypeDiskDrop, Disks: []proto.DiskID{1}}
+	require.ErrorIs(t, mgr.AddCampaign(ctx, args), errCampaignDiskConflict)
+	require.ErrorIs(t, mgr.PauseCampaign(ctx, "drop"), errCampaignNotFound)
+
+	// over the bandwidth budget
+	task := &proto.MigrateTask{TaskType: proto.TaskTypeDiskDrop, SourceDiskID: 2}
+	taskID := newCampaignTaskID(proto.TaskTypeDiskDrop, 2)
+	require.True(t, mgr.AllowAcquire(task))
+	require.NoError(t, mgr.SetCampaignBudget(ctx, "evacuate", 1))
+	mgr.ReportTask(proto.TaskTypeDiskDrop, taskID, 200<<20)
+	require.False(t, mgr.AllowAcquire(task))
+	require.NoError(t, mgr.SetCampaignBudget(ctx, "evacuate", 0))
+	require.True(t, mgr.AllowAcquire(task))
+
+	// progress and eta
+	mgr.startTime = time.Now().Add(-time.Hour)
+	for i := 0; i < 10; i++ {
+		mgr.CompleteTask(proto.TaskTypeDiskDrop, taskID)
+	}
+	stats := mgr.ListCampaigns()
+	require.Equal(t, 2, len(stats))
+	stat := stats[0]
+	require.Equal(t, "evacuate", stat.ID)
+	require.Equal(t, []proto.DiskID{1, 2}, stat.Disks)
+	require.Equal(t, int(testDisk1.UsedChunkCnt+testDisk2.UsedChunkCnt), stat.TotalTasksCnt)
+	require.Equal(t, 10, stat.MigratedTasksCnt)
+	require.True(t, stat.CurrentMBPS > 0)
+	require.True(t, stat.ETASec >= 0)
+	require.Equal(t, int64(0), stats[1].ETASec)
+
+	require.NoError(t, mgr.PauseCampaign(ctx, "evacuate"))
+	require.True(t, mgr.TaskPaused(proto.TaskTypeDiskDrop, taskID))
+	require.Equal(t, int64(-1), mgr.ListCampaigns()[0].ETASec)
+
+	mgr.flush()
+	require.NoError(t, mgr.DeleteCampaign(ctx, "evacuate"))
+	require.False(t, mgr.TaskPaused(proto.TaskTypeDiskDrop, taskID))
+	require.NoError(t, mgr.AddCampaign(ctx, args))
+	mgr.Close()
+}
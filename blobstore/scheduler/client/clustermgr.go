@@ -79,6 +79,12 @@ type ClusterMgrTaskAPI interface {
 	ListMigratingDisks(ctx context.Context, taskType proto.TaskType) (disks []*MigratingDiskMeta, err error)
 	GetVolumeInspectCheckPoint(ctx context.Context) (ck *proto.VolumeInspectCheckPoint, err error)
 	SetVolumeInspectCheckPoint(ctx context.Context, startVid proto.Vid) (err error)
+	AddVolumeInspectRecord(ctx context.Context, record *proto.VolumeInspectRecord) (err error)
+	DeleteVolumeInspectRecord(ctx context.Context, record *proto.VolumeInspectRecord) (err error)
+	ListAllVolumeInspectRecords(ctx context.Context) (records []*proto.VolumeInspectRecord, err error)
+	SetMigrateCampaign(ctx context.Context, campaign *proto.MigrateCampaign) (err error)
+	DeleteMigrateCampaign(ctx context.Context, id string) (err error)
+	ListAllMigrateCampaigns(ctx context.Context) (campaigns []*proto.MigrateCampaign, err error)
 	GetConsumeOffset(taskType proto.TaskType, topic string, partition int32) (offset int64, err error)
 	SetConsumeOffset(taskType proto.TaskType, topic string, partition int32, offset int64) (err error)
 }
@@ -117,6 +123,13 @@ type ClusterMgrAPI interface {
 //  for example:
 //		volume_inspect-checkpoint
 //
+// volume inspect record key
+//  - - - - - - - - - - - - - - - - - - - - - - - - - - -
+//  | {task_type} | _inspectRecord | vid | inspect_time |
+//  - - - - - - - - - - - - - - - - - - - - - - - - - - -
+//  for example:
+//		volume_inspect-record-1024-1672502400
+//
 // kafka consume offset key
 //  - - - - - - - - - - - - - - - - - - - - - - - - - - - -
 //  | {task_type} | _consumeOffset | {topic} | {partition} |
@@ -129,7 +142,9 @@ const (
 	_delimiter           = "-"
 	_migratingDiskPrefix = "migrating"
 	_checkPoint          = "checkpoint"
+	_inspectRecord       = "record"
 	_consumeOffset       = "consume_offset"
+	_migrateCampaign     = "migrate_campaign"
 )
 
 var (
@@ -184,6 +199,22 @@ func genVolumeInspectCheckpointKey() string {
 	return proto.TaskTypeVolumeInspect.String() + _delimiter + _checkPoint
 }
 
+func genVolumeInspectRecordKey(record *proto.VolumeInspectRecord) string {
+	return fmt.Sprintf("%s%d%s%d", genVolumeInspectRecordPrefix(), record.Vid, _delimiter, record.InspectTime)
+}
+
+func genVolumeInspectRecordPrefix() string {
+	return proto.TaskTypeVolumeInspect.String() + _delimiter + _inspectRecord + _delimiter
+}
+
+func genMigrateCampaignKey(id string) string {
+	return genMigrateCampaignPrefix() + id
+}
+
+func genMigrateCampaignPrefix() string {
+	return _migrateCampaign + _delimiter
+}
+
 func genConsumerOffsetKey(taskType proto.TaskType, topic string, partition int32) string {
 	return fmt.Sprintf("%s%s%s%s%s%s%d", taskType, _delimiter, _consumeOffset, _delimiter, topic, _delimiter, partition)
 }
@@ -968,6 +999,100 @@ func (c *clustermgrClient) SetVolumeInspectCheckPoint(ctx context.Context, start
 	return c.client.SetKV(ctx, genVolumeInspectCheckpointKey(), checkPointBytes)
 }
 
+// AddVolumeInspectRecord adds record of volume inspection
+func (c *clustermgrClient) AddVolumeInspectRecord(ctx context.Context, record *proto.VolumeInspectRecord) (err error) {
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return c.client.SetKV(ctx, genVolumeInspectRecordKey(record), recordBytes)
+}
+
+// DeleteVolumeInspectRecord deletes record of volume inspection
+func (c *clustermgrClient) DeleteVolumeInspectRecord(ctx context.Context, record *proto.VolumeInspectRecord) (err error) {
+	return c.client.DeleteKV(ctx, genVolumeInspectRecordKey(record))
+}
+
+// ListAllVolumeInspectRecords returns all records of volume inspection
+func (c *clustermgrClient) ListAllVolumeInspectRecords(ctx context.Context) (records []*proto.VolumeInspectRecord, err error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	marker := defaultListTaskMarker
+	for {
+		args := &cmapi.ListKvOpts{
+			Prefix: genVolumeInspectRecordPrefix(),
+			Count:  defaultListTaskNum,
+			Marker: marker,
+		}
+		ret, err := c.client.ListKV(ctx, args)
+		if err != nil {
+			span.Errorf("list volume inspect records failed: err[%+v]", err)
+			return nil, err
+		}
+
+		for _, v := range ret.Kvs {
+			var record *proto.VolumeInspectRecord
+			if err = json.Unmarshal(v.Value, &record); err != nil {
+				span.Errorf("unmarshal volume inspect record failed: key[%s], err[%+v]", v.Key, err)
+				return nil, err
+			}
+			records = append(records, record)
+		}
+		marker = ret.Marker
+		if marker == defaultListTaskMarker {
+			break
+		}
+	}
+	return
+}
+
+// SetMigrateCampaign adds or updates the campaign of migration
+func (c *clustermgrClient) SetMigrateCampaign(ctx context.Context, campaign *proto.MigrateCampaign) (err error) {
+	campaignBytes, err := json.Marshal(campaign)
+	if err != nil {
+		return err
+	}
+	return c.client.SetKV(ctx, genMigrateCampaignKey(campaign.ID), campaignBytes)
+}
+
+// DeleteMigrateCampaign deletes the campaign of migration
+func (c *clustermgrClient) DeleteMigrateCampaign(ctx context.Context, id string) (err error) {
+	return c.client.DeleteKV(ctx, genMigrateCampaignKey(id))
+}
+
+// ListAllMigrateCampaigns returns all campaigns of migration
+func (c *clustermgrClient) ListAllMigrateCampaigns(ctx context.Context) (campaigns []*proto.MigrateCampaign, err error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	marker := defaultListTaskMarker
+	for {
+		args := &cmapi.ListKvOpts{
+			Prefix: genMigrateCampaignPrefix(),
+			Count:  defaultListTaskNum,
+			Marker: marker,
+		}
+		ret, err := c.client.ListKV(ctx, args)
+		if err != nil {
+			span.Errorf("list migrate campaigns failed: err[%+v]", err)
+			return nil, err
+		}
+
+		for _, v := range ret.Kvs {
+			var campaign *proto.MigrateCampaign
+			if err = json.Unmarshal(v.Value, &campaign); err != nil {
+				span.Errorf("unmarshal migrate campaign failed: key[%s], err[%+v]", v.Key, err)
+				return nil, err
+			}
+			campaigns = append(campaigns, campaign)
+		}
+		marker = ret.Marker
+		if marker == defaultListTaskMarker {
+			break
+		}
+	}
+	return
+}
+
 func (c *clustermgrClient) GetConsumeOffset(taskType proto.TaskType, topic string, partition int32) (offset int64, err error) {
 	ret, err := c.client.GetKV(context.Background(), genConsumerOffsetKey(taskType, topic, partition))
 	if err != nil {
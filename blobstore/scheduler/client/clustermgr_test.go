@@ -466,6 +466,54 @@ func TestClustermgrClient(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, checkpoint.StartVid, checkpoint2.StartVid)
 	}
+	{
+		// add and delete volume inspect record
+		record := &proto.VolumeInspectRecord{Vid: 100, InspectTime: 1672502400}
+		cli.client.(*MockClusterManager).EXPECT().SetKV(any, "volume_inspect-record-100-1672502400", any).Return(nil)
+		require.NoError(t, cli.AddVolumeInspectRecord(ctx, record))
+		cli.client.(*MockClusterManager).EXPECT().DeleteKV(any, "volume_inspect-record-100-1672502400").Return(nil)
+		require.NoError(t, cli.DeleteVolumeInspectRecord(ctx, record))
+
+		// list volume inspect records
+		recordBytes, _ := json.Marshal(record)
+		cli.client.(*MockClusterManager).EXPECT().ListKV(any, any).Return(cmapi.ListKvRet{
+			Kvs:    []*cmapi.KeyValue{{Key: "volume_inspect-record-100-1672502400", Value: recordBytes}},
+			Marker: "volume_inspect-record-100-1672502400",
+		}, nil)
+		cli.client.(*MockClusterManager).EXPECT().ListKV(any, any).Return(cmapi.ListKvRet{}, nil)
+		records, err := cli.ListAllVolumeInspectRecords(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(records))
+		require.Equal(t, record.Vid, records[0].Vid)
+
+		cli.client.(*MockClusterManager).EXPECT().ListKV(any, any).Return(cmapi.ListKvRet{}, errMock)
+		_, err = cli.ListAllVolumeInspectRecords(ctx)
+		require.Error(t, err)
+	}
+	{
+		// set and delete migrate campaign
+		campaign := &proto.MigrateCampaign{ID: "az1", TaskType: proto.TaskTypeDiskDrop, Disks: []proto.DiskID{1, 2}}
+		cli.client.(*MockClusterManager).EXPECT().SetKV(any, "migrate_campaign-az1", any).Return(nil)
+		require.NoError(t, cli.SetMigrateCampaign(ctx, campaign))
+		cli.client.(*MockClusterManager).EXPECT().DeleteKV(any, "migrate_campaign-az1").Return(nil)
+		require.NoError(t, cli.DeleteMigrateCampaign(ctx, campaign.ID))
+
+		// list migrate campaigns
+		campaignBytes, _ := json.Marshal(campaign)
+		cli.client.(*MockClusterManager).EXPECT().ListKV(any, any).Return(cmapi.ListKvRet{
+			Kvs:    []*cmapi.KeyValue{{Key: "migrate_campaign-az1", Value: campaignBytes}},
+			Marker: "migrate_campaign-az1",
+		}, nil)
+		cli.client.(*MockClusterManager).EXPECT().ListKV(any, any).Return(cmapi.ListKvRet{}, nil)
+		campaigns, err := cli.ListAllMigrateCampaigns(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(campaigns))
+		require.Equal(t, campaign.Disks, campaigns[0].Disks)
+
+		cli.client.(*MockClusterManager).EXPECT().ListKV(any, any).Return(cmapi.ListKvRet{}, errMock)
+		_, err = cli.ListAllMigrateCampaigns(ctx)
+		require.Error(t, err)
+	}
 	{
 		// set consume offset
 		topic := "test"
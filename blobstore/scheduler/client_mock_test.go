@@ -65,6 +65,20 @@ func (mr *MockClusterMgrAPIMockRecorder) AddMigratingDisk(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMigratingDisk", reflect.TypeOf((*MockClusterMgrAPI)(nil).AddMigratingDisk), arg0, arg1)
 }
 
+// AddVolumeInspectRecord mocks base method.
+func (m *MockClusterMgrAPI) AddVolumeInspectRecord(arg0 context.Context, arg1 *proto.VolumeInspectRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddVolumeInspectRecord", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddVolumeInspectRecord indicates an expected call of AddVolumeInspectRecord.
+func (mr *MockClusterMgrAPIMockRecorder) AddVolumeInspectRecord(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddVolumeInspectRecord", reflect.TypeOf((*MockClusterMgrAPI)(nil).AddVolumeInspectRecord), arg0, arg1)
+}
+
 // AllocVolumeUnit mocks base method.
 func (m *MockClusterMgrAPI) AllocVolumeUnit(arg0 context.Context, arg1 proto.Vuid) (*client.AllocVunitInfo, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +94,20 @@ func (mr *MockClusterMgrAPIMockRecorder) AllocVolumeUnit(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllocVolumeUnit", reflect.TypeOf((*MockClusterMgrAPI)(nil).AllocVolumeUnit), arg0, arg1)
 }
 
+// DeleteMigrateCampaign mocks base method.
+func (m *MockClusterMgrAPI) DeleteMigrateCampaign(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMigrateCampaign", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMigrateCampaign indicates an expected call of DeleteMigrateCampaign.
+func (mr *MockClusterMgrAPIMockRecorder) DeleteMigrateCampaign(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMigrateCampaign", reflect.TypeOf((*MockClusterMgrAPI)(nil).DeleteMigrateCampaign), arg0, arg1)
+}
+
 // DeleteMigrateTask mocks base method.
 func (m *MockClusterMgrAPI) DeleteMigrateTask(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -108,6 +136,20 @@ func (mr *MockClusterMgrAPIMockRecorder) DeleteMigratingDisk(arg0, arg1, arg2 in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMigratingDisk", reflect.TypeOf((*MockClusterMgrAPI)(nil).DeleteMigratingDisk), arg0, arg1, arg2)
 }
 
+// DeleteVolumeInspectRecord mocks base method.
+func (m *MockClusterMgrAPI) DeleteVolumeInspectRecord(arg0 context.Context, arg1 *proto.VolumeInspectRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVolumeInspectRecord", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVolumeInspectRecord indicates an expected call of DeleteVolumeInspectRecord.
+func (mr *MockClusterMgrAPIMockRecorder) DeleteVolumeInspectRecord(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVolumeInspectRecord", reflect.TypeOf((*MockClusterMgrAPI)(nil).DeleteVolumeInspectRecord), arg0, arg1)
+}
+
 // GetConfig mocks base method.
 func (m *MockClusterMgrAPI) GetConfig(arg0 context.Context, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -228,6 +270,21 @@ func (mr *MockClusterMgrAPIMockRecorder) GetVolumeInspectCheckPoint(arg0 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVolumeInspectCheckPoint", reflect.TypeOf((*MockClusterMgrAPI)(nil).GetVolumeInspectCheckPoint), arg0)
 }
 
+// ListAllMigrateCampaigns mocks base method.
+func (m *MockClusterMgrAPI) ListAllMigrateCampaigns(arg0 context.Context) ([]*proto.MigrateCampaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAllMigrateCampaigns", arg0)
+	ret0, _ := ret[0].([]*proto.MigrateCampaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAllMigrateCampaigns indicates an expected call of ListAllMigrateCampaigns.
+func (mr *MockClusterMgrAPIMockRecorder) ListAllMigrateCampaigns(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllMigrateCampaigns", reflect.TypeOf((*MockClusterMgrAPI)(nil).ListAllMigrateCampaigns), arg0)
+}
+
 // ListAllMigrateTasks mocks base method.
 func (m *MockClusterMgrAPI) ListAllMigrateTasks(arg0 context.Context, arg1 proto.TaskType) ([]*proto.MigrateTask, error) {
 	m.ctrl.T.Helper()
@@ -258,6 +315,21 @@ func (mr *MockClusterMgrAPIMockRecorder) ListAllMigrateTasksByDiskID(arg0, arg1,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllMigrateTasksByDiskID", reflect.TypeOf((*MockClusterMgrAPI)(nil).ListAllMigrateTasksByDiskID), arg0, arg1, arg2)
 }
 
+// ListAllVolumeInspectRecords mocks base method.
+func (m *MockClusterMgrAPI) ListAllVolumeInspectRecords(arg0 context.Context) ([]*proto.VolumeInspectRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAllVolumeInspectRecords", arg0)
+	ret0, _ := ret[0].([]*proto.VolumeInspectRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAllVolumeInspectRecords indicates an expected call of ListAllVolumeInspectRecords.
+func (mr *MockClusterMgrAPIMockRecorder) ListAllVolumeInspectRecords(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllVolumeInspectRecords", reflect.TypeOf((*MockClusterMgrAPI)(nil).ListAllVolumeInspectRecords), arg0)
+}
+
 // ListBrokenDisks mocks base method.
 func (m *MockClusterMgrAPI) ListBrokenDisks(arg0 context.Context) ([]*client.DiskInfoSimple, error) {
 	m.ctrl.T.Helper()
@@ -492,6 +564,20 @@ func (mr *MockClusterMgrAPIMockRecorder) SetDiskRepairing(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDiskRepairing", reflect.TypeOf((*MockClusterMgrAPI)(nil).SetDiskRepairing), arg0, arg1)
 }
 
+// SetMigrateCampaign mocks base method.
+func (m *MockClusterMgrAPI) SetMigrateCampaign(arg0 context.Context, arg1 *proto.MigrateCampaign) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMigrateCampaign", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMigrateCampaign indicates an expected call of SetMigrateCampaign.
+func (mr *MockClusterMgrAPIMockRecorder) SetMigrateCampaign(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMigrateCampaign", reflect.TypeOf((*MockClusterMgrAPI)(nil).SetMigrateCampaign), arg0, arg1)
+}
+
 // SetVolumeInspectCheckPoint mocks base method.
 func (m *MockClusterMgrAPI) SetVolumeInspectCheckPoint(arg0 context.Context, arg1 proto.Vid) error {
 	m.ctrl.T.Helper()
@@ -48,6 +48,8 @@ const (
 	defaultDeleteHourRangeTo      = 24
 	defaultMessagePunishThreshold = 3
 	defaultMessagePunishTimeM     = 4
+	defaultRepairedTTLM           = 60
+	defaultRepairedClockSkewS     = 60
 	defaultSlowDownTimeS          = 3
 	defaultDeleteLogChunkSize     = uint(29)
 	defaultDeleteDelayH           = int64(72)
@@ -271,6 +273,8 @@ func (c *Config) fixShardRepairConfig() {
 	defaulter.LessOrEqual(&c.ShardRepair.OrphanShardLog.ChunkBits, defaultDeleteLogChunkSize)
 	defaulter.LessOrEqual(&c.ShardRepair.MessagePunishThreshold, defaultMessagePunishThreshold)
 	defaulter.LessOrEqual(&c.ShardRepair.MessagePunishTimeM, defaultMessagePunishTimeM)
+	defaulter.LessOrEqual(&c.ShardRepair.RepairedTTLM, defaultRepairedTTLM)
+	defaulter.LessOrEqual(&c.ShardRepair.RepairedClockSkewS, defaultRepairedClockSkewS)
 	c.ShardRepair.Kafka.FailMsgSenderTimeoutMs = c.Kafka.FailMsgSenderTimeoutMs
 	c.ShardRepair.Kafka.BrokerList = c.Kafka.BrokerList
 	c.ShardRepair.Kafka.TopicNormals = c.Kafka.Topics.ShardRepair
@@ -673,6 +673,34 @@ func (mr *MockVolumeInspectorMockRecorder) Enabled() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockVolumeInspector)(nil).Enabled))
 }
 
+// GetInspectHistory mocks base method.
+func (m *MockVolumeInspector) GetInspectHistory(arg0 proto.Vid) []*proto.VolumeInspectRecord {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInspectHistory", arg0)
+	ret0, _ := ret[0].([]*proto.VolumeInspectRecord)
+	return ret0
+}
+
+// GetInspectHistory indicates an expected call of GetInspectHistory.
+func (mr *MockVolumeInspectorMockRecorder) GetInspectHistory(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInspectHistory", reflect.TypeOf((*MockVolumeInspector)(nil).GetInspectHistory), arg0)
+}
+
+// GetInspectTrend mocks base method.
+func (m *MockVolumeInspector) GetInspectTrend(arg0 int) []*scheduler.VolumeInspectTrend {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInspectTrend", arg0)
+	ret0, _ := ret[0].([]*scheduler.VolumeInspectTrend)
+	return ret0
+}
+
+// GetInspectTrend indicates an expected call of GetInspectTrend.
+func (mr *MockVolumeInspectorMockRecorder) GetInspectTrend(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInspectTrend", reflect.TypeOf((*MockVolumeInspector)(nil).GetInspectTrend), arg0)
+}
+
 // GetTaskStats mocks base method.
 func (m *MockVolumeInspector) GetTaskStats() ([20]int, [20]int) {
 	m.ctrl.T.Helper()
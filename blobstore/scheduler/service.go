@@ -43,6 +43,7 @@ type Service struct {
 	diskRepairMgr IDisKMigrator
 	manualMigMgr  IManualMigrator
 	inspectMgr    IVolumeInspector
+	campaignMgr   *campaignMgr
 
 	shardRepairMgr  ITaskRunner
 	blobDeleteMgr   ITaskRunner
@@ -95,10 +96,20 @@ func (svr *Service) HTTPTaskAcquire(c *rpc.Context) {
 		shuffledMigrators[i], shuffledMigrators[j] = shuffledMigrators[j], shuffledMigrators[i]
 	})
 	for _, acquire := range migrators {
-		if migrateTask, err := acquire.AcquireTask(ctx, args.IDC); err == nil {
-			c.RespondJSON(migrateTask)
-			return
+		migrateTask, err := acquire.AcquireTask(ctx, args.IDC)
+		if err != nil {
+			continue
+		}
+		// give the task back if its campaign is paused or over the bandwidth budget
+		if !svr.campaignMgr.AllowAcquire(&migrateTask) {
+			_ = acquire.CancelTask(ctx, &api.OperateTaskArgs{
+				IDC: args.IDC, TaskID: migrateTask.TaskID, TaskType: migrateTask.TaskType,
+				Src: migrateTask.Sources, Dest: migrateTask.Destination, Reason: "campaign throttled",
+			})
+			continue
 		}
+		c.RespondJSON(migrateTask)
+		return
 	}
 	c.RespondError(errcode.ErrNothingTodo)
 }
@@ -171,7 +182,12 @@ func (svr *Service) HTTPTaskComplete(c *rpc.Context) {
 		c.RespondError(err)
 		return
 	}
-	c.RespondError(completer.CompleteTask(ctx, args))
+	if err = completer.CompleteTask(ctx, args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	svr.campaignMgr.CompleteTask(args.TaskType, args.TaskID)
+	c.Respond()
 }
 
 // HTTPInspectAcquire acquire inspect task
@@ -199,6 +215,34 @@ func (svr *Service) HTTPInspectComplete(c *rpc.Context) {
 	c.Respond()
 }
 
+// HTTPInspectHistory returns records of the inspection of volume
+func (svr *Service) HTTPInspectHistory(c *rpc.Context) {
+	args := new(api.VolumeInspectHistoryArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	if args.Vid == proto.InvalidVid {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+	c.RespondJSON(&api.VolumeInspectHistoryRet{Records: svr.inspectMgr.GetInspectHistory(args.Vid)})
+}
+
+// HTTPInspectTrend returns trends of the inspection per disk model and batch
+func (svr *Service) HTTPInspectTrend(c *rpc.Context) {
+	args := new(api.VolumeInspectTrendArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	if args.Days < 0 {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+	c.RespondJSON(&api.VolumeInspectTrendRet{Trends: svr.inspectMgr.GetInspectTrend(args.Days)})
+}
+
 // HTTPTaskRenewal renewal task
 func (svr *Service) HTTPTaskRenewal(c *rpc.Context) {
 	args := new(api.TaskRenewalArgs)
@@ -222,6 +266,10 @@ func (svr *Service) HTTPTaskRenewal(c *rpc.Context) {
 				errors[id] = errcode.ErrIllegalArguments.Error()
 				continue
 			}
+			if svr.campaignMgr.TaskPaused(typ, id) {
+				errors[id] = proto.ErrTaskPaused.Error()
+				continue
+			}
 			if err := renewaler.RenewalTask(ctx, args.IDC, id); err != nil {
 				errors[id] = err.Error()
 			}
@@ -251,6 +299,7 @@ func (svr *Service) HTTPTaskReport(c *rpc.Context) {
 		return
 	}
 	reporter.ReportWorkerTaskStats(args)
+	svr.campaignMgr.ReportTask(args.TaskType, args.TaskID, args.IncreaseDataSizeByte)
 	c.Respond()
 }
 
@@ -392,6 +441,69 @@ func (svr *Service) HTTPManualMigrateTaskAdd(c *rpc.Context) {
 	c.RespondError(rpc.Error2HTTPError(err))
 }
 
+// HTTPCampaignAdd adds campaign of migration
+func (svr *Service) HTTPCampaignAdd(c *rpc.Context) {
+	args := new(api.AddCampaignArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	if !args.Valid() {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+	c.RespondError(rpc.Error2HTTPError(svr.campaignMgr.AddCampaign(c.Request.Context(), args)))
+}
+
+// HTTPCampaignPause pauses campaign
+func (svr *Service) HTTPCampaignPause(c *rpc.Context) {
+	args := new(api.CampaignArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	c.RespondError(rpc.Error2HTTPError(svr.campaignMgr.PauseCampaign(c.Request.Context(), args.ID)))
+}
+
+// HTTPCampaignResume resumes campaign
+func (svr *Service) HTTPCampaignResume(c *rpc.Context) {
+	args := new(api.CampaignArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	c.RespondError(rpc.Error2HTTPError(svr.campaignMgr.ResumeCampaign(c.Request.Context(), args.ID)))
+}
+
+// HTTPCampaignBudget sets bandwidth budget of campaign
+func (svr *Service) HTTPCampaignBudget(c *rpc.Context) {
+	args := new(api.SetCampaignBudgetArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	if args.BandwidthMBPS < 0 {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+	c.RespondError(rpc.Error2HTTPError(svr.campaignMgr.SetCampaignBudget(c.Request.Context(), args.ID, args.BandwidthMBPS)))
+}
+
+// HTTPCampaignDelete deletes campaign
+func (svr *Service) HTTPCampaignDelete(c *rpc.Context) {
+	args := new(api.CampaignArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	c.RespondError(rpc.Error2HTTPError(svr.campaignMgr.DeleteCampaign(c.Request.Context(), args.ID)))
+}
+
+// HTTPCampaignList returns campaigns with progress and eta
+func (svr *Service) HTTPCampaignList(c *rpc.Context) {
+	c.RespondJSON(&api.ListCampaignsRet{Campaigns: svr.campaignMgr.ListCampaigns()})
+}
+
 // HTTPUpdateVolume updates volume cache
 func (svr *Service) HTTPUpdateVolume(c *rpc.Context) {
 	args := new(api.UpdateVolumeArgs)
@@ -118,6 +118,10 @@ func newMockService(t *testing.T) *Service {
 	// complete inspect task
 	inspectorMgr.EXPECT().CompleteInspect(any, any).Return()
 
+	// inspect history and trend
+	inspectorMgr.EXPECT().GetInspectHistory(any).Return([]*proto.VolumeInspectRecord{{Vid: 1}})
+	inspectorMgr.EXPECT().GetInspectTrend(any).Return([]*api.VolumeInspectTrend{{Model: "m1", Batch: "b1"}})
+
 	// volume update
 	clusterTopology.EXPECT().UpdateVolume(any).Return(&client.VolumeInfoSimple{}, nil)
 	clusterTopology.EXPECT().UpdateVolume(any).Return(nil, errMock)
@@ -157,6 +161,11 @@ func newMockService(t *testing.T) *Service {
 	diskRepairMgr.EXPECT().DiskProgress(any, any).Return(&api.DiskMigratingStats{TotalTasksCnt: int(testDisk1.UsedChunkCnt), MigratedTasksCnt: 1}, nil)
 	diskDropMgr.EXPECT().DiskProgress(any, any).Return(&api.DiskMigratingStats{TotalTasksCnt: int(testDisk1.UsedChunkCnt), MigratedTasksCnt: 1}, nil)
 
+	// campaign
+	clusterMgrCli.EXPECT().GetDiskInfo(any, any).AnyTimes().Return(testDisk1, nil)
+	clusterMgrCli.EXPECT().SetMigrateCampaign(any, any).AnyTimes().Return(nil)
+	clusterMgrCli.EXPECT().DeleteMigrateCampaign(any, any).AnyTimes().Return(nil)
+
 	service := &Service{
 		ClusterID:     1,
 		leader:        true,
@@ -166,6 +175,7 @@ func newMockService(t *testing.T) *Service {
 		manualMigMgr:  manualMgr,
 		diskRepairMgr: diskRepairMgr,
 		inspectMgr:    inspectorMgr,
+		campaignMgr:   newCampaignMgr(clusterMgrCli),
 
 		shardRepairMgr:  shardRepairMgr,
 		blobDeleteMgr:   blobDeleteMgr,
@@ -251,6 +261,18 @@ func TestServiceAPI(t *testing.T) {
 	// complete inspect task
 	require.NoError(t, cli.CompleteInspectTask(ctx, &proto.VolumeInspectRet{}))
 
+	// inspect history and trend
+	_, err = cli.VolumeInspectHistory(ctx, &api.VolumeInspectHistoryArgs{})
+	require.Error(t, err)
+	history, err := cli.VolumeInspectHistory(ctx, &api.VolumeInspectHistoryArgs{Vid: 1})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(history.Records))
+	_, err = cli.VolumeInspectTrend(ctx, &api.VolumeInspectTrendArgs{Days: -1})
+	require.Error(t, err)
+	trend, err := cli.VolumeInspectTrend(ctx, &api.VolumeInspectTrendArgs{Days: 7})
+	require.NoError(t, err)
+	require.Equal(t, "m1", trend.Trends[0].Model)
+
 	// volume update
 	require.NoError(t, cli.UpdateVolume(ctx, schedulerServer.URL, proto.Vid(1)))
 	require.Error(t, cli.UpdateVolume(ctx, schedulerServer.URL, proto.Vid(1)))
@@ -289,6 +311,36 @@ func TestServiceAPI(t *testing.T) {
 		require.Equal(t, 1, stats.MigratedTasksCnt)
 	}
 
+	// campaign
+	{
+		require.Error(t, cli.AddCampaign(ctx, &api.AddCampaignArgs{ID: "c1", TaskType: proto.TaskTypeBalance, Disks: []proto.DiskID{diskID}}))
+		require.NoError(t, cli.AddCampaign(ctx, &api.AddCampaignArgs{ID: "c1", TaskType: proto.TaskTypeDiskDrop, Disks: []proto.DiskID{diskID}}))
+		err = cli.AddCampaign(ctx, &api.AddCampaignArgs{ID: "c1", TaskType: proto.TaskTypeDiskDrop, Disks: []proto.DiskID{diskID}})
+		require.Equal(t, errCampaignExist.StatusCode(), rpc.DetectStatusCode(err))
+		require.NoError(t, cli.SetCampaignBudget(ctx, &api.SetCampaignBudgetArgs{ID: "c1", BandwidthMBPS: 100}))
+		require.NoError(t, cli.PauseCampaign(ctx, &api.CampaignArgs{ID: "c1"}))
+
+		renewal, err := cli.RenewalTask(ctx, &api.TaskRenewalArgs{
+			IDC: idc,
+			IDs: map[proto.TaskType][]string{proto.TaskTypeDiskDrop: {client.GenMigrateTaskID(proto.TaskTypeDiskDrop, diskID, volumeID)}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(renewal.Errors[proto.TaskTypeDiskDrop]))
+
+		ret, err := cli.ListCampaigns(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(ret.Campaigns))
+		require.True(t, ret.Campaigns[0].Paused)
+		require.Equal(t, 100, ret.Campaigns[0].BandwidthMBPS)
+		require.Equal(t, int(testDisk1.UsedChunkCnt), ret.Campaigns[0].TotalTasksCnt)
+		require.Equal(t, int64(-1), ret.Campaigns[0].ETASec)
+
+		require.NoError(t, cli.ResumeCampaign(ctx, &api.CampaignArgs{ID: "c1"}))
+		require.NoError(t, cli.DeleteCampaign(ctx, &api.CampaignArgs{ID: "c1"}))
+		err = cli.DeleteCampaign(ctx, &api.CampaignArgs{ID: "c1"})
+		require.Equal(t, errCampaignNotFound.StatusCode(), rpc.DetectStatusCode(err))
+	}
+
 	// reclaim failed
 	err = cli.ReclaimTask(ctx, &api.OperateTaskArgs{
 		IDC: idc, TaskType: proto.TaskTypeDiskRepair,
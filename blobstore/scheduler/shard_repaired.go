@@ -0,0 +1,146 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+// repairFingerprint identifies the same repair of shards, indexes are sorted.
+func repairFingerprint(msg *proto.ShardRepairMsg) string {
+	badIdx := make([]int, len(msg.BadIdx))
+	for i, idx := range msg.BadIdx {
+		badIdx[i] = int(idx)
+	}
+	sort.Ints(badIdx)
+	return fmt.Sprintf("%d:%d:%v", msg.Vid, msg.Bid, badIdx)
+}
+
+// repairedRecord is the completed repair and the kafka message which triggered it.
+type repairedRecord struct {
+	StartAt   int64  `json:"start_at"` // unix nano of the local time
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	Offset    int64  `json:"offset"`
+}
+
+// repairedRecords records completed repairs by fingerprint, a message produced before
+// the start of the same completed repair is done already. A message in the same topic
+// partition is compared by the offset, which is produced before the triggering message.
+// Otherwise it's compared by the timestamp of the producer, which must be earlier than
+// the local start time by the clock skew at least.
+// Records are persisted to the file if path is not empty, so that completed work
+// is recognized across restarts, and expired after ttl.
+type repairedRecords struct {
+	sync.Mutex
+	path      string
+	ttl       time.Duration
+	clockSkew time.Duration
+	records   map[string]repairedRecord // fingerprint -> record
+}
+
+func newRepairedRecords(path string, ttl, clockSkew time.Duration) (*repairedRecords, error) {
+	r := &repairedRecords{path: path, ttl: ttl, clockSkew: clockSkew, records: make(map[string]repairedRecord)}
+	if path == "" {
+		return r, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return r, nil
+		}
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &r.records); err != nil {
+		return nil, err
+	}
+	r.expire(time.Now())
+	return r, nil
+}
+
+// Done records the repair completed, which started at the time after consuming the message.
+func (r *repairedRecords) Done(fingerprint string, msg *sarama.ConsumerMessage, startAt time.Time) {
+	r.Lock()
+	r.records[fingerprint] = repairedRecord{
+		StartAt:   startAt.UnixNano(),
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+	}
+	r.Unlock()
+}
+
+// IsDone returns true if the message is produced before a completed repair.
+func (r *repairedRecords) IsDone(fingerprint string, msg *sarama.ConsumerMessage) bool {
+	r.Lock()
+	record, ok := r.records[fingerprint]
+	r.Unlock()
+	if !ok {
+		return false
+	}
+	// the position is unknown without the topic
+	if record.Topic != "" && msg.Topic == record.Topic && msg.Partition == record.Partition {
+		return msg.Offset <= record.Offset
+	}
+	if msg.Timestamp.IsZero() {
+		return false
+	}
+	return msg.Timestamp.Add(r.clockSkew).UnixNano() < record.StartAt
+}
+
+// Save removes expired records and persists the others.
+func (r *repairedRecords) Save() error {
+	r.Lock()
+	r.expire(time.Now())
+	if r.path == "" {
+		r.Unlock()
+		return nil
+	}
+	data, err := json.Marshal(r.records)
+	r.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmpPath := r.path + ".tmp"
+	if err = os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, r.path)
+}
+
+func (r *repairedRecords) expire(now time.Time) {
+	deadline := now.Add(-r.ttl).UnixNano()
+	for fingerprint, record := range r.records {
+		if record.StartAt < deadline {
+			delete(r.records, fingerprint)
+		}
+	}
+}
+
+func (r *repairedRecords) Len() int {
+	r.Lock()
+	defer r.Unlock()
+	return len(r.records)
+}
@@ -36,6 +36,7 @@ import (
 	"github.com/cubefs/cubefs/blobstore/scheduler/base"
 	"github.com/cubefs/cubefs/blobstore/scheduler/client"
 	"github.com/cubefs/cubefs/blobstore/util/closer"
+	"github.com/cubefs/cubefs/blobstore/util/log"
 	"github.com/cubefs/cubefs/blobstore/util/selector"
 	"github.com/cubefs/cubefs/blobstore/util/taskpool"
 )
@@ -49,6 +50,7 @@ const (
 	ShardRepairStatusUnexpect
 	ShardRepairStatusOrphan
 	ShardRepairStatusUndo
+	ShardRepairStatusRepaired
 )
 
 // shard repair name
@@ -71,6 +73,14 @@ type ShardRepairConfig struct {
 
 	TaskPoolSize   int              `json:"task_pool_size"`
 	OrphanShardLog recordlog.Config `json:"orphan_shard_log"`
+
+	// completed repairs are recorded for a while to skip duplicate messages,
+	// and persisted to the file if the path is not empty.
+	RepairedRecordPath string `json:"repaired_record_path"`
+	RepairedTTLM       int    `json:"repaired_ttl_m"`
+	// max clock skew between the producers of messages and the scheduler,
+	// messages in other kafka partitions are compared by the producer timestamp
+	RepairedClockSkewS int `json:"repaired_clock_skew_s"`
 }
 
 func (cfg *ShardRepairConfig) topics() []string {
@@ -114,6 +124,7 @@ type ShardRepairMgr struct {
 	errStatsDistribution    *base.ErrorStats
 
 	group             singleflight.Group
+	repaired          *repairedRecords
 	orphanShardLogger recordlog.Encoder
 
 	cfg *ShardRepairConfig
@@ -146,6 +157,12 @@ func NewShardRepairMgr(
 		return nil, err
 	}
 
+	repaired, err := newRepairedRecords(cfg.RepairedRecordPath, time.Duration(cfg.RepairedTTLM)*time.Minute,
+		time.Duration(cfg.RepairedClockSkewS)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
 	return &ShardRepairMgr{
 		blobnodeCli:      blobnodeCli,
 		taskPool:         taskpool.New(cfg.TaskPoolSize, cfg.TaskPoolSize),
@@ -158,6 +175,7 @@ func NewShardRepairMgr(
 		punishTime:          time.Duration(cfg.MessagePunishTimeM) * time.Minute,
 
 		orphanShardLogger: orphanShardsLog,
+		repaired:          repaired,
 
 		repairSuccessCounter:    base.NewCounter(cfg.ClusterID, ShardRepair, base.KindSuccess),
 		repairFailedCounter:     base.NewCounter(cfg.ClusterID, ShardRepair, base.KindFailed),
@@ -177,11 +195,32 @@ func (mgr *ShardRepairMgr) Enabled() bool {
 
 func (mgr *ShardRepairMgr) Run() {
 	go mgr.runTask()
+	go mgr.runSaveRepaired()
 }
 
 func (mgr *ShardRepairMgr) Close() {
 	mgr.Closer.Close()
 	mgr.stopConsumer()
+	if err := mgr.repaired.Save(); err != nil {
+		log.Errorf("save repaired records failed: err[%+v]", err)
+	}
+}
+
+func (mgr *ShardRepairMgr) runSaveRepaired() {
+	t := time.NewTicker(time.Minute)
+	span := trace.SpanFromContextSafe(context.Background())
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			if err := mgr.repaired.Save(); err != nil {
+				span.Errorf("save repaired records failed: err[%+v]", err)
+			}
+		case <-mgr.Done():
+			return
+		}
+	}
 }
 
 func (mgr *ShardRepairMgr) runTask() {
@@ -243,6 +282,8 @@ type shardRepairRet struct {
 	status    shardRepairStatus
 	repairMsg *proto.ShardRepairMsg
 	err       error
+	// start time of the repair, zero if the repair is coalesced into another one
+	startAt time.Time
 }
 
 // GetTaskStats returns task stats
@@ -289,7 +330,16 @@ func (mgr *ShardRepairMgr) handleOneMsg(ctx context.Context, msg *sarama.Consume
 	}
 
 	_, ctx = trace.StartSpanFromContextWithTraceID(ctx, "ShardRepairConsume", repairMsg.ReqId)
-	return mgr.consume(ctx, repairMsg, consumerPause)
+	fingerprint := repairFingerprint(repairMsg)
+	if mgr.repaired.IsDone(fingerprint, msg) {
+		ret.status = ShardRepairStatusRepaired
+		return
+	}
+	ret = mgr.consume(ctx, repairMsg, consumerPause)
+	if ret.status == ShardRepairStatusDone && !ret.startAt.IsZero() {
+		mgr.repaired.Done(fingerprint, msg, ret.startAt)
+	}
+	return
 }
 
 func (mgr *ShardRepairMgr) recordOneResult(ctx context.Context, r shardRepairRet) {
@@ -317,6 +367,8 @@ func (mgr *ShardRepairMgr) recordOneResult(ctx context.Context, r shardRepairRet
 		span.Warnf("unexpected result: msg[%+v], err[%+v]", r.repairMsg, r.err)
 	case ShardRepairStatusUndo:
 		span.Warnf("repair message unconsume: msg[%+v]", r.repairMsg)
+	case ShardRepairStatusRepaired:
+		span.Infof("skip repaired message: vid[%d], bid[%d], bad_idx[%v]", r.repairMsg.Vid, r.repairMsg.Bid, r.repairMsg.BadIdx)
 	default:
 		// do nothing
 	}
@@ -338,10 +390,13 @@ func (mgr *ShardRepairMgr) consume(ctx context.Context, repairMsg *proto.ShardRe
 			return shardRepairRet{status: ShardRepairStatusUndo}
 		}
 	}
-	jobKey := fmt.Sprintf("%d:%d:%s", repairMsg.Vid, repairMsg.Bid, repairMsg.BadIdx)
-	_, err, _ := mgr.group.Do(jobKey, func() (ret interface{}, e error) {
-		e = mgr.repairWithCheckVolConsistency(ctx, repairMsg)
-		return
+	// identical repairs are coalesced
+	jobKey := repairFingerprint(repairMsg)
+	var startAt time.Time
+	_, err, _ := mgr.group.Do(jobKey, func() (interface{}, error) {
+		// only the repair which is not coalesced records the start time
+		startAt = time.Now()
+		return nil, mgr.repairWithCheckVolConsistency(ctx, repairMsg)
 	})
 
 	if isOrphanShard(err) {
@@ -352,7 +407,7 @@ func (mgr *ShardRepairMgr) consume(ctx context.Context, repairMsg *proto.ShardRe
 		return shardRepairRet{status: ShardRepairStatusFailed, err: err}
 	}
 
-	return shardRepairRet{status: ShardRepairStatusDone}
+	return shardRepairRet{status: ShardRepairStatusDone, startAt: startAt}
 }
 
 func (mgr *ShardRepairMgr) repairWithCheckVolConsistency(ctx context.Context, repairMsg *proto.ShardRepairMsg) error {
@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -74,6 +75,7 @@ func newShardRepairMgr(t *testing.T) *ShardRepairMgr {
 		kafkaConsumerClient:     kafkaClient,
 		punishTime:              time.Duration(defaultMessagePunishTimeM) * time.Minute,
 		orphanShardLogger:       orphanShardLog,
+		repaired:                &repairedRecords{ttl: time.Hour, clockSkew: time.Minute, records: make(map[string]repairedRecord)},
 		taskSwitch:              taskSwitch,
 		taskPool:                taskpool.New(10, 10),
 		repairSuccessCounter:    base.NewCounter(1, ShardRepair, base.KindSuccess),
@@ -339,3 +341,75 @@ func TestTryRepair(t *testing.T) {
 		require.True(t, doneVolume.EqualWith(newVolume))
 	}
 }
+
+func TestShardRepairDuplicate(t *testing.T) {
+	ctx := context.Background()
+	mgr := newShardRepairMgr(t)
+	require.Equal(t, repairFingerprint(&proto.ShardRepairMsg{Vid: 1, Bid: 1, BadIdx: []uint8{1, 0}}),
+		repairFingerprint(&proto.ShardRepairMsg{Vid: 1, Bid: 1, BadIdx: []uint8{0, 1}}))
+	require.NotEqual(t, repairFingerprint(&proto.ShardRepairMsg{Vid: 1, Bid: 1, BadIdx: []uint8{0}}),
+		repairFingerprint(&proto.ShardRepairMsg{Vid: 1, Bid: 1, BadIdx: []uint8{0, 1}}))
+
+	produced := time.Now()
+	msg := &proto.ShardRepairMsg{Bid: 1, Vid: 1, ReqId: "123456", BadIdx: []uint8{0, 1}}
+	msgByte, _ := json.Marshal(msg)
+	kafkaMsg := &sarama.ConsumerMessage{Value: msgByte, Topic: "repair", Partition: 1, Offset: 10, Timestamp: produced}
+	ret := mgr.handleOneMsg(ctx, kafkaMsg, closer.New())
+	require.Equal(t, ShardRepairStatusDone, ret.status)
+
+	// produced before the completed repair in the same partition, whatever the timestamp is
+	msg.BadIdx = []uint8{1, 0}
+	msgByte, _ = json.Marshal(msg)
+	kafkaMsg = &sarama.ConsumerMessage{Value: msgByte, Topic: "repair", Partition: 1, Offset: 5, Timestamp: time.Now()}
+	ret = mgr.handleOneMsg(ctx, kafkaMsg, closer.New())
+	require.Equal(t, ShardRepairStatusRepaired, ret.status)
+
+	// produced after the completed repair in the same partition
+	kafkaMsg.Offset = 11
+	kafkaMsg.Timestamp = produced.Add(-time.Hour)
+	ret = mgr.handleOneMsg(ctx, kafkaMsg, closer.New())
+	require.Equal(t, ShardRepairStatusDone, ret.status)
+
+	// other partitions are compared by the timestamp with the clock skew
+	kafkaMsg = &sarama.ConsumerMessage{Value: msgByte, Topic: "repair", Partition: 2, Offset: 5, Timestamp: time.Now().Add(-2 * time.Minute)}
+	ret = mgr.handleOneMsg(ctx, kafkaMsg, closer.New())
+	require.Equal(t, ShardRepairStatusRepaired, ret.status)
+	kafkaMsg.Timestamp = time.Now().Add(-time.Second)
+	ret = mgr.handleOneMsg(ctx, kafkaMsg, closer.New())
+	require.Equal(t, ShardRepairStatusDone, ret.status)
+}
+
+func TestRepairedRecords(t *testing.T) {
+	testDir, err := os.MkdirTemp(os.TempDir(), "repaired_records")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+	path := filepath.Join(testDir, "repaired")
+
+	records, err := newRepairedRecords(path, time.Hour, time.Minute)
+	require.NoError(t, err)
+	now := time.Now()
+	newMsg := func(partition int32, offset int64, timestamp time.Time) *sarama.ConsumerMessage {
+		return &sarama.ConsumerMessage{Topic: "repair", Partition: partition, Offset: offset, Timestamp: timestamp}
+	}
+	records.Done("1:1:[0]", newMsg(0, 100, now), now)
+	records.Done("1:2:[0]", newMsg(0, 101, now), now.Add(-2*time.Hour))
+	require.True(t, records.IsDone("1:1:[0]", newMsg(0, 100, now)))
+	require.True(t, records.IsDone("1:1:[0]", newMsg(0, 99, now.Add(time.Hour))))
+	require.False(t, records.IsDone("1:1:[0]", newMsg(0, 101, now.Add(-time.Hour))))
+	require.True(t, records.IsDone("1:1:[0]", newMsg(1, 1, now.Add(-2*time.Minute))))
+	require.False(t, records.IsDone("1:1:[0]", newMsg(1, 1, now.Add(-time.Second))))
+	require.False(t, records.IsDone("1:1:[0]", newMsg(1, 1, time.Time{})))
+	require.False(t, records.IsDone("1:3:[0]", newMsg(0, 1, now.Add(-time.Hour))))
+	require.NoError(t, records.Save())
+	require.Equal(t, 1, records.Len())
+
+	// reload after restart
+	records, err = newRepairedRecords(path, time.Hour, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 1, records.Len())
+	require.True(t, records.IsDone("1:1:[0]", newMsg(0, 99, now)))
+
+	require.NoError(t, os.WriteFile(path, []byte("invalid"), 0o644))
+	_, err = newRepairedRecords(path, time.Hour, time.Minute)
+	require.Error(t, err)
+}
@@ -190,12 +190,14 @@ func NewService(conf *Config) (svr *Service, err error) {
 		return nil, err
 	}
 	inspectMgr := NewVolumeInspectMgr(clusterMgrCli, mqProxy, inspectorTaskSwitch, &conf.VolumeInspect)
+	campaignMgr := newCampaignMgr(clusterMgrCli)
 
 	svr.balanceMgr = balanceMgr
 	svr.diskDropMgr = diskDropMgr
 	svr.manualMigMgr = manualMigMgr
 	svr.diskRepairMgr = diskRepairMgr
 	svr.inspectMgr = inspectMgr
+	svr.campaignMgr = campaignMgr
 
 	err = svr.waitAndLoad()
 	if err != nil {
@@ -228,6 +230,9 @@ func (svr *Service) load() (err error) {
 	if err = svr.manualMigMgr.Load(); err != nil {
 		return
 	}
+	if err = svr.campaignMgr.Load(); err != nil {
+		return
+	}
 
 	return
 }
@@ -252,6 +257,7 @@ func (svr *Service) Run() {
 	svr.diskDropMgr.Run()
 	svr.manualMigMgr.Run()
 	svr.inspectMgr.Run()
+	svr.campaignMgr.Run()
 }
 
 // RunTask run shard repair and blob delete tasks
@@ -351,6 +357,7 @@ func (svr *Service) Close() {
 	svr.diskDropMgr.Close()
 	svr.manualMigMgr.Close()
 	svr.inspectMgr.Close()
+	svr.campaignMgr.Close()
 }
 
 // NewHandler returns app server handler
@@ -358,6 +365,8 @@ func NewHandler(service *Service) *rpc.Router {
 	rpc.RegisterArgsParser(&api.AcquireArgs{}, "json")
 	rpc.RegisterArgsParser(&api.DiskMigratingStatsArgs{}, "json")
 	rpc.RegisterArgsParser(&api.MigrateTaskDetailArgs{}, "json")
+	rpc.RegisterArgsParser(&api.VolumeInspectHistoryArgs{}, "json")
+	rpc.RegisterArgsParser(&api.VolumeInspectTrendArgs{}, "json")
 
 	// rpc http svr interface
 	rpc.GET(api.PathTaskAcquire, service.HTTPTaskAcquire, rpc.OptArgsQuery())
@@ -368,6 +377,8 @@ func NewHandler(service *Service) *rpc.Router {
 
 	rpc.GET(api.PathInspectAcquire, service.HTTPInspectAcquire)
 	rpc.POST(api.PathInspectComplete, service.HTTPInspectComplete, rpc.OptArgsBody())
+	rpc.GET(api.PathInspectHistory, service.HTTPInspectHistory, rpc.OptArgsQuery())
+	rpc.GET(api.PathInspectTrend, service.HTTPInspectTrend, rpc.OptArgsQuery())
 
 	rpc.POST(api.PathTaskReport, service.HTTPTaskReport, rpc.OptArgsBody())
 	rpc.POST(api.PathTaskRenewal, service.HTTPTaskRenewal, rpc.OptArgsBody())
@@ -379,5 +390,12 @@ func NewHandler(service *Service) *rpc.Router {
 
 	rpc.POST(api.PathUpdateVolume, service.HTTPUpdateVolume, rpc.OptArgsBody())
 
+	rpc.POST(api.PathCampaignAdd, service.HTTPCampaignAdd, rpc.OptArgsBody())
+	rpc.POST(api.PathCampaignPause, service.HTTPCampaignPause, rpc.OptArgsBody())
+	rpc.POST(api.PathCampaignResume, service.HTTPCampaignResume, rpc.OptArgsBody())
+	rpc.POST(api.PathCampaignBudget, service.HTTPCampaignBudget, rpc.OptArgsBody())
+	rpc.POST(api.PathCampaignDelete, service.HTTPCampaignDelete, rpc.OptArgsBody())
+	rpc.GET(api.PathCampaignList, service.HTTPCampaignList)
+
 	return rpc.DefaultRouter
 }
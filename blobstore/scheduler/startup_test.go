@@ -126,6 +126,7 @@ func newMockServiceWithOpts(ctr *gomock.Controller, isLeader bool) *Service {
 	diskRepairMgr.EXPECT().Load().AnyTimes().Return(nil)
 	diskDropMgr.EXPECT().Load().AnyTimes().Return(nil)
 	manualMgr.EXPECT().Load().AnyTimes().Return(nil)
+	clusterMgrCli.EXPECT().ListAllMigrateCampaigns(any).AnyTimes().Return(nil, nil)
 
 	blobDeleteMgr.EXPECT().GetErrorStats().AnyTimes().Return([]string{}, uint64(0))
 	blobDeleteMgr.EXPECT().GetTaskStats().AnyTimes().Return([counter.SLOT]int{}, [counter.SLOT]int{})
@@ -169,6 +170,7 @@ func newMockServiceWithOpts(ctr *gomock.Controller, isLeader bool) *Service {
 		clusterTopology: clusterTopology,
 		volumeUpdater:   volumeUpdater,
 		clusterMgrCli:   clusterMgrCli,
+		campaignMgr:     newCampaignMgr(clusterMgrCli),
 	}
 	return service
 }
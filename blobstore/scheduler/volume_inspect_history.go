@@ -0,0 +1,258 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	api "github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/scheduler/client"
+	"github.com/cubefs/cubefs/blobstore/util/retry"
+)
+
+const (
+	defaultInspectTrendDays = 30
+	inspectTrendDateLayout  = "2006-01-02"
+	unknownDiskModel        = "unknown"
+)
+
+// DiskBatch is a batch of disks in the same model, such as the disks purchased together,
+// the hosts are the blobnode hosts of the batch, such as http://127.0.0.1:8889
+type DiskBatch struct {
+	Model string   `json:"model"`
+	Batch string   `json:"batch"`
+	Hosts []string `json:"hosts"`
+}
+
+type inspectShardKey struct {
+	vuid proto.Vuid
+	bid  proto.BlobID
+}
+
+// volumeInspectHistory keeps the records of volume inspection in memory and persists them in
+// clustermgr. A record of volume is added only if the bad shards are different from the last
+// record, so the corruption and repair of shards can be traced per volume, and the trends of
+// them are stat per disk model and batch to find the failing batches early.
+type volumeInspectHistory struct {
+	sync.RWMutex
+	loaded  bool
+	records map[proto.Vid][]*proto.VolumeInspectRecord
+
+	cntPerVol     int
+	hostBatches   map[string]*DiskBatch
+	clusterMgrCli client.ClusterMgrAPI
+}
+
+func newVolumeInspectHistory(clusterMgrCli client.ClusterMgrAPI, cntPerVol int, batches []DiskBatch) *volumeInspectHistory {
+	hostBatches := make(map[string]*DiskBatch)
+	for i := range batches {
+		for _, host := range batches[i].Hosts {
+			hostBatches[host] = &batches[i]
+		}
+	}
+	return &volumeInspectHistory{
+		records:       make(map[proto.Vid][]*proto.VolumeInspectRecord),
+		cntPerVol:     cntPerVol,
+		hostBatches:   hostBatches,
+		clusterMgrCli: clusterMgrCli,
+	}
+}
+
+// load loads the records persisted in clustermgr, it should be called with lock
+func (h *volumeInspectHistory) load(ctx context.Context) error {
+	records, err := h.clusterMgrCli.ListAllVolumeInspectRecords(ctx)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		h.records[record.Vid] = append(h.records[record.Vid], record)
+	}
+	for _, volRecords := range h.records {
+		sort.Slice(volRecords, func(i, j int) bool {
+			return volRecords[i].InspectTime < volRecords[j].InspectTime
+		})
+	}
+	h.loaded = true
+	return nil
+}
+
+// record adds record of the inspection of volume if the bad shards are changed
+func (h *volumeInspectHistory) record(ctx context.Context, vid proto.Vid, replicas []proto.VunitLocation,
+	missedShards []*proto.MissedShard, inspectTime time.Time,
+) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	h.Lock()
+	defer h.Unlock()
+
+	if !h.loaded {
+		if err := h.load(ctx); err != nil {
+			span.Errorf("load volume inspect records failed: err[%+v]", err)
+			return
+		}
+	}
+
+	lastBads := make(map[inspectShardKey]*proto.InspectShard)
+	if volRecords := h.records[vid]; len(volRecords) > 0 {
+		for _, shard := range volRecords[len(volRecords)-1].BadShards {
+			lastBads[inspectShardKey{vuid: shard.Vuid, bid: shard.Bid}] = shard
+		}
+	}
+
+	record := &proto.VolumeInspectRecord{Vid: vid, InspectTime: inspectTime.Unix()}
+	bads := make(map[inspectShardKey]struct{})
+	changed := false
+	for _, missed := range missedShards {
+		key := inspectShardKey{vuid: missed.Vuid, bid: missed.Bid}
+		if _, ok := bads[key]; ok {
+			continue
+		}
+		bads[key] = struct{}{}
+
+		shard := &proto.InspectShard{Vuid: missed.Vuid, Bid: missed.Bid}
+		if idx := int(missed.Vuid.Index()); idx < len(replicas) {
+			shard.DiskID = replicas[idx].DiskID
+			shard.Host = replicas[idx].Host
+		}
+		if _, ok := lastBads[key]; ok {
+			shard.Recurring = true
+		} else {
+			changed = true
+		}
+		record.BadShards = append(record.BadShards, shard)
+	}
+	for key, shard := range lastBads {
+		if _, ok := bads[key]; !ok {
+			record.RepairedShards = append(record.RepairedShards, &proto.InspectShard{
+				Vuid: shard.Vuid, Bid: shard.Bid, DiskID: shard.DiskID, Host: shard.Host,
+			})
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	err := retry.Timed(3, 200).On(func() error {
+		return h.clusterMgrCli.AddVolumeInspectRecord(ctx, record)
+	})
+	if err != nil {
+		span.Errorf("add volume inspect record failed: vid[%d], err[%+v]", vid, err)
+		return
+	}
+	span.Infof("add volume inspect record: vid[%d], bad shards[%d], repaired shards[%d]",
+		vid, len(record.BadShards), len(record.RepairedShards))
+
+	volRecords := append(h.records[vid], record)
+	for len(volRecords) > h.cntPerVol {
+		if err = h.clusterMgrCli.DeleteVolumeInspectRecord(ctx, volRecords[0]); err != nil {
+			span.Warnf("delete volume inspect record failed: vid[%d], inspect time[%d], err[%+v]",
+				vid, volRecords[0].InspectTime, err)
+			break
+		}
+		volRecords = volRecords[1:]
+	}
+	h.records[vid] = volRecords
+}
+
+// history returns the records of volume in order of inspect time
+func (h *volumeInspectHistory) history(vid proto.Vid) []*proto.VolumeInspectRecord {
+	h.RLock()
+	defer h.RUnlock()
+	return append([]*proto.VolumeInspectRecord{}, h.records[vid]...)
+}
+
+// trend returns the bad and repaired shards per day of the disks in each model and batch,
+// the disks on the hosts not in any batch are stat in the unknown model.
+func (h *volumeInspectHistory) trend(days int, now time.Time) []*api.VolumeInspectTrend {
+	type batchKey struct {
+		model string
+		batch string
+	}
+	type dayPoint struct {
+		point *api.VolumeInspectTrendPoint
+		disks map[proto.DiskID]struct{}
+	}
+
+	since := now.AddDate(0, 0, -days).Unix()
+	batchPoints := make(map[batchKey]map[string]*dayPoint)
+	getPoint := func(shard *proto.InspectShard, date string) *dayPoint {
+		key := batchKey{model: unknownDiskModel}
+		if batch, ok := h.hostBatches[shard.Host]; ok {
+			key = batchKey{model: batch.Model, batch: batch.Batch}
+		}
+		points, ok := batchPoints[key]
+		if !ok {
+			points = make(map[string]*dayPoint)
+			batchPoints[key] = points
+		}
+		p, ok := points[date]
+		if !ok {
+			p = &dayPoint{
+				point: &api.VolumeInspectTrendPoint{Date: date},
+				disks: make(map[proto.DiskID]struct{}),
+			}
+			points[date] = p
+		}
+		return p
+	}
+
+	h.RLock()
+	for _, volRecords := range h.records {
+		for _, record := range volRecords {
+			if record.InspectTime < since {
+				continue
+			}
+			date := time.Unix(record.InspectTime, 0).Format(inspectTrendDateLayout)
+			for _, shard := range record.BadShards {
+				p := getPoint(shard, date)
+				p.point.BadShardCnt++
+				if shard.Recurring {
+					p.point.RecurringCnt++
+				}
+				p.disks[shard.DiskID] = struct{}{}
+			}
+			for _, shard := range record.RepairedShards {
+				getPoint(shard, date).point.RepairedCnt++
+			}
+		}
+	}
+	h.RUnlock()
+
+	trends := make([]*api.VolumeInspectTrend, 0, len(batchPoints))
+	for key, points := range batchPoints {
+		trend := &api.VolumeInspectTrend{Model: key.model, Batch: key.batch}
+		for _, p := range points {
+			p.point.BadDiskCnt = len(p.disks)
+			trend.Points = append(trend.Points, p.point)
+		}
+		sort.Slice(trend.Points, func(i, j int) bool {
+			return trend.Points[i].Date < trend.Points[j].Date
+		})
+		trends = append(trends, trend)
+	}
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].Model != trends[j].Model {
+			return trends[i].Model < trends[j].Model
+		}
+		return trends[i].Batch < trends[j].Batch
+	})
+	return trends
+}
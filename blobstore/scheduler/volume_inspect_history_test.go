@@ -0,0 +1,157 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/scheduler/client"
+)
+
+func TestVolumeInspectHistory(t *testing.T) {
+	ctx := context.Background()
+	ctr := gomock.NewController(t)
+	clusterMgr := NewMockClusterMgrAPI(ctr)
+
+	vid := proto.Vid(100012)
+	volume := MockGenVolInfo(vid, codemode.EC6P6, proto.VolumeStatusIdle)
+	replicas := volume.VunitLocations
+	missed := func(bids ...proto.BlobID) []*proto.MissedShard {
+		var shards []*proto.MissedShard
+		for _, bid := range bids {
+			shards = append(shards, &proto.MissedShard{Vuid: replicas[1].Vuid, Bid: bid})
+		}
+		return shards
+	}
+	batches := []DiskBatch{{Model: "m1", Batch: "b1", Hosts: []string{replicas[1].Host}}}
+	history := newVolumeInspectHistory(clusterMgr, 2, batches)
+	now := time.Now()
+
+	// load failed and nothing recorded
+	clusterMgr.EXPECT().ListAllVolumeInspectRecords(any).Return(nil, errMock)
+	history.record(ctx, vid, replicas, missed(1), now)
+	require.Equal(t, 0, len(history.history(vid)))
+
+	// clean volume is not recorded
+	old := &proto.VolumeInspectRecord{Vid: 1, InspectTime: now.AddDate(0, 0, -100).Unix()}
+	clusterMgr.EXPECT().ListAllVolumeInspectRecords(any).Return([]*proto.VolumeInspectRecord{old}, nil)
+	history.record(ctx, vid, replicas, nil, now)
+	require.Equal(t, 0, len(history.history(vid)))
+	require.Equal(t, 1, len(history.history(1)))
+
+	// new bad shards
+	clusterMgr.EXPECT().AddVolumeInspectRecord(any, any).Return(nil)
+	history.record(ctx, vid, replicas, missed(1, 2), now.Add(-time.Hour))
+	records := history.history(vid)
+	require.Equal(t, 1, len(records))
+	require.Equal(t, 2, len(records[0].BadShards))
+	require.Equal(t, replicas[1].DiskID, records[0].BadShards[0].DiskID)
+	require.False(t, records[0].BadShards[0].Recurring)
+
+	// same bad shards are not recorded again
+	history.record(ctx, vid, replicas, missed(2, 1), now)
+	require.Equal(t, 1, len(history.history(vid)))
+
+	// add failed
+	clusterMgr.EXPECT().AddVolumeInspectRecord(any, any).Times(3).Return(errMock)
+	history.record(ctx, vid, replicas, missed(1), now)
+	require.Equal(t, 1, len(history.history(vid)))
+
+	// shard 1 is recurring and shard 2 is repaired
+	clusterMgr.EXPECT().AddVolumeInspectRecord(any, any).Return(nil)
+	history.record(ctx, vid, replicas, missed(1), now)
+	records = history.history(vid)
+	require.Equal(t, 2, len(records))
+	require.Equal(t, 1, len(records[1].BadShards))
+	require.True(t, records[1].BadShards[0].Recurring)
+	require.Equal(t, 1, len(records[1].RepairedShards))
+	require.Equal(t, proto.BlobID(2), records[1].RepairedShards[0].Bid)
+
+	// all repaired and the oldest record is deleted
+	clusterMgr.EXPECT().AddVolumeInspectRecord(any, any).Return(nil)
+	clusterMgr.EXPECT().DeleteVolumeInspectRecord(any, any).Return(nil)
+	history.record(ctx, vid, replicas, nil, now)
+	records = history.history(vid)
+	require.Equal(t, 2, len(records))
+	require.Equal(t, 0, len(records[1].BadShards))
+	require.Equal(t, 1, len(records[1].RepairedShards))
+
+	// trends
+	trends := history.trend(defaultInspectTrendDays, now)
+	require.Equal(t, 1, len(trends))
+	require.Equal(t, "m1", trends[0].Model)
+	require.Equal(t, "b1", trends[0].Batch)
+	var badCnt, recurringCnt, repairedCnt int
+	for _, point := range trends[0].Points {
+		badCnt += point.BadShardCnt
+		recurringCnt += point.RecurringCnt
+		repairedCnt += point.RepairedCnt
+		require.LessOrEqual(t, point.BadDiskCnt, 1)
+	}
+	require.Equal(t, 1, badCnt)
+	require.Equal(t, 1, recurringCnt)
+	require.Equal(t, 2, repairedCnt)
+
+	// hosts not in any batch
+	history = newVolumeInspectHistory(clusterMgr, 2, nil)
+	history.loaded = true
+	clusterMgr.EXPECT().AddVolumeInspectRecord(any, any).Return(nil)
+	history.record(ctx, vid, replicas, missed(1), now)
+	trends = history.trend(defaultInspectTrendDays, now)
+	require.Equal(t, 1, len(trends))
+	require.Equal(t, unknownDiskModel, trends[0].Model)
+	require.Equal(t, 1, trends[0].Points[0].BadDiskCnt)
+	require.Equal(t, 0, len(history.trend(1, now.AddDate(0, 0, 2))))
+}
+
+func TestInspectorFinishWithHistory(t *testing.T) {
+	ctx := context.Background()
+	mgr := newInspector(t)
+	mgr.cfg.InspectBatch = 1
+	mgr.cfg.ListVolStep = 1
+	mgr.history = newVolumeInspectHistory(mgr.clusterMgrCli, 10, nil)
+	require.Nil(t, newInspector(t).GetInspectHistory(100012))
+	require.Nil(t, newInspector(t).GetInspectTrend(0))
+
+	volume := MockGenVolInfo(100012, codemode.EC6P6, proto.VolumeStatusIdle)
+	mgr.clusterMgrCli.(*MockClusterMgrAPI).EXPECT().GetVolumeInspectCheckPoint(any).AnyTimes().Return(&proto.VolumeInspectCheckPoint{}, nil)
+	mgr.clusterMgrCli.(*MockClusterMgrAPI).EXPECT().ListVolume(any, any, any).Return([]*client.VolumeInfoSimple{volume}, proto.Vid(0), nil)
+	mgr.prepare(ctx)
+	require.Equal(t, 1, len(mgr.tasks))
+
+	for _, task := range mgr.tasks {
+		task.ret = &proto.VolumeInspectRet{MissedShards: genMockFailShards(100012, []proto.BlobID{3, 4})}
+	}
+	mgr.clusterMgrCli.(*MockClusterMgrAPI).EXPECT().GetVolumeInfo(any, any).Return(volume, nil)
+	mgr.repairShardSender.(*MockMqProxyAPI).EXPECT().SendShardRepairMsg(any, any, any, any).AnyTimes().Return(nil)
+	mgr.clusterMgrCli.(*MockClusterMgrAPI).EXPECT().ListAllVolumeInspectRecords(any).Return(nil, nil)
+	mgr.clusterMgrCli.(*MockClusterMgrAPI).EXPECT().AddVolumeInspectRecord(any, any).Return(nil)
+	mgr.clusterMgrCli.(*MockClusterMgrAPI).EXPECT().SetVolumeInspectCheckPoint(any, any).Return(nil)
+	mgr.finish(ctx)
+
+	records := mgr.GetInspectHistory(100012)
+	require.Equal(t, 1, len(records))
+	require.Equal(t, 2, len(records[0].BadShards))
+	trends := mgr.GetInspectTrend(0)
+	require.Equal(t, 1, len(trends))
+	require.Equal(t, unknownDiskModel, trends[0].Model)
+}
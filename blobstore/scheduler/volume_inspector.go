@@ -22,6 +22,7 @@ import (
 	"sync"
 	"time"
 
+	api "github.com/cubefs/cubefs/blobstore/api/scheduler"
 	"github.com/cubefs/cubefs/blobstore/common/counter"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/common/taskswitch"
@@ -38,6 +39,8 @@ type IVolumeInspector interface {
 	AcquireInspect(ctx context.Context) (*proto.VolumeInspectTask, error)
 	CompleteInspect(ctx context.Context, ret *proto.VolumeInspectRet)
 	GetTaskStats() (finished, timeout [counter.SLOT]int)
+	GetInspectHistory(vid proto.Vid) []*proto.VolumeInspectRecord
+	GetInspectTrend(days int) []*api.VolumeInspectTrend
 	Enabled() bool
 	Run()
 	closer.Closer
@@ -156,6 +159,11 @@ type VolumeInspectMgrCfg struct {
 
 	// timeout of inspect
 	TimeoutMs int `json:"timeout_ms"`
+
+	// records of inspection kept per volume, the inspection is not recorded if 0
+	HistoryCntPerVol int `json:"history_cnt_per_vol"`
+	// batches of disks to stat the inspection trends per disk model and batch
+	DiskBatches []DiskBatch `json:"disk_batches"`
 }
 
 // VolumeInspectMgr inspect task manager
@@ -183,6 +191,8 @@ type VolumeInspectMgr struct {
 	completeTaskCounter counter.Counter
 	timeoutCounter      counter.Counter
 
+	history *volumeInspectHistory
+
 	cfg *VolumeInspectMgrCfg
 }
 
@@ -192,6 +202,10 @@ func NewVolumeInspectMgr(
 	repairShardSender client.ProxyAPI,
 	taskSwitch taskswitch.ISwitcher, cfg *VolumeInspectMgrCfg,
 ) *VolumeInspectMgr {
+	var history *volumeInspectHistory
+	if cfg.HistoryCntPerVol > 0 {
+		history = newVolumeInspectHistory(clusterMgrCli, cfg.HistoryCntPerVol, cfg.DiskBatches)
+	}
 	return &VolumeInspectMgr{
 		Closer:            closer.New(),
 		tasks:             make(map[string]*inspectTaskInfo),
@@ -201,6 +215,7 @@ func NewVolumeInspectMgr(
 		clusterMgrCli:     clusterMgrCli,
 		repairShardSender: repairShardSender,
 		sendDeduplicator:  newBadShardDeduplicator(defaultDuplicateCnt),
+		history:           history,
 		cfg:               cfg,
 	}
 }
@@ -415,7 +430,11 @@ func (mgr *VolumeInspectMgr) finish(ctx context.Context) {
 
 	// collect missed bids
 	var missedShards [][]*proto.MissedShard
+	inspected := make(map[proto.Vid]*inspectTaskInfo)
 	for _, task := range mgr.tasks {
+		if mgr.history != nil && task.completed() && task.ret.Err() == nil && len(task.t.Replicas) > 0 {
+			inspected[task.t.Replicas[0].Vuid.Vid()] = task
+		}
 		if task.hasMissedShard() {
 			missedShards = append(missedShards, task.ret.MissedShards)
 			continue
@@ -443,11 +462,13 @@ func (mgr *VolumeInspectMgr) finish(ctx context.Context) {
 		volInfo, err := mgr.clusterMgrCli.GetVolumeInfo(ctx, vid)
 		if err != nil {
 			span.Errorf("get volume info failed: err[%+v]", err)
+			delete(inspected, vid)
 			continue
 		}
 
 		if volInfo.IsActive() {
 			span.Infof("volume is active and will skip: vid[%d]", volInfo.Vid)
+			delete(inspected, vid)
 			continue
 		}
 
@@ -465,6 +486,12 @@ func (mgr *VolumeInspectMgr) finish(ctx context.Context) {
 		}
 	}
 
+	// record the inspection results
+	inspectTime := time.Now()
+	for vid, task := range inspected {
+		mgr.history.record(ctx, vid, task.t.Replicas, task.ret.MissedShards, inspectTime)
+	}
+
 	err := retry.Timed(3, 200).On(func() error {
 		return mgr.clusterMgrCli.SetVolumeInspectCheckPoint(ctx, mgr.nextVid)
 	})
@@ -554,6 +581,25 @@ func (mgr *VolumeInspectMgr) GetTaskStats() (finished, timeout [counter.SLOT]int
 	return
 }
 
+// GetInspectHistory returns records of the inspection of volume
+func (mgr *VolumeInspectMgr) GetInspectHistory(vid proto.Vid) []*proto.VolumeInspectRecord {
+	if mgr.history == nil {
+		return nil
+	}
+	return mgr.history.history(vid)
+}
+
+// GetInspectTrend returns trends of the inspection per disk model and batch in the latest days
+func (mgr *VolumeInspectMgr) GetInspectTrend(days int) []*api.VolumeInspectTrend {
+	if mgr.history == nil {
+		return nil
+	}
+	if days <= 0 {
+		days = defaultInspectTrendDays
+	}
+	return mgr.history.trend(days, time.Now())
+}
+
 func sortBads(bads []uint8) {
 	sort.Slice(bads, func(i, j int) bool {
 		return bads[i] < bads[j]
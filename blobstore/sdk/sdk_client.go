@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
+	"sync/atomic"
 
 	"github.com/cubefs/cubefs/blobstore/access/stream"
 	acapi "github.com/cubefs/cubefs/blobstore/api/access"
@@ -30,6 +32,8 @@ const (
 	defaultRetryDelayMs    uint32 = 10
 	defaultPartConcurrence int    = 4
 
+	defaultDeleteBatchConcurrency = 32
+
 	limitNameGet    = "get"
 	limitNamePut    = "put"
 	limitNameDelete = "delete"
@@ -93,6 +97,7 @@ func New(conf *Config) (acapi.API, error) {
 	fixConfig(conf)
 	// add region magic checksum to the secret keys
 	initWithRegionMagic(conf.StreamConfig.ClusterConfig.RegionMagic)
+	stream.LocationInitSigning(&conf.StreamConfig.ClusterConfig)
 
 	cl := closer.New()
 	h, err := stream.NewStreamHandler(&conf.StreamConfig, cl.Done())
@@ -170,6 +175,25 @@ func (s *sdkHandler) Delete(ctx context.Context, args *acapi.DeleteArgs) (failed
 	return nil, nil
 }
 
+// DeleteBatch deletes locations one by one with bounded concurrency,
+// returns result of every location instead of failing the whole batch.
+func (s *sdkHandler) DeleteBatch(ctx context.Context, args *acapi.DeleteBatchArgs) (*acapi.DeleteBatchResp, error) {
+	if !args.IsValid() {
+		return nil, errcode.ErrIllegalArguments
+	}
+
+	ctx = acapi.ClientWithReqidContext(ctx)
+	name := limitNameDelete
+	if err := s.limiter.Acquire(name); err != nil {
+		span := trace.SpanFromContextSafe(ctx)
+		span.Debugf("access concurrent limited %s, err:%+v", name, err)
+		return nil, errcode.ErrAccessLimited
+	}
+	defer s.limiter.Release(name)
+
+	return s.doDeleteBatch(ctx, args), nil
+}
+
 func (s *sdkHandler) Put(ctx context.Context, args *acapi.PutArgs) (lc acapi.Location, hm acapi.HashSumMap, err error) {
 	if args == nil {
 		return acapi.Location{}, nil, errcode.ErrIllegalArguments
@@ -417,6 +441,61 @@ func (s *sdkHandler) doDelete(ctx context.Context, args *acapi.DeleteArgs) (resp
 	return
 }
 
+func (s *sdkHandler) doDeleteBatch(ctx context.Context, args *acapi.DeleteBatchArgs) *acapi.DeleteBatchResp {
+	span := trace.SpanFromContextSafe(ctx)
+	span.Debugf("accept sdk delete batch request args: locations %d", len(args.Locations))
+
+	resp := &acapi.DeleteBatchResp{Results: make([]acapi.DeleteBatchResult, len(args.Locations))}
+	var (
+		wg     sync.WaitGroup
+		failed int32
+	)
+	tokens := make(chan struct{}, defaultDeleteBatchConcurrency)
+	for idx := range args.Locations {
+		loc := &args.Locations[idx]
+		result := &resp.Results[idx]
+		if !stream.LocationCrcVerify(loc) {
+			span.Infof("invalid crc %+v", loc)
+			result.Code = errcode.ErrIllegalArguments.StatusCode()
+			result.Error = errcode.ErrIllegalArguments.Error()
+			atomic.AddInt32(&failed, 1)
+			continue
+		}
+		if loc.Size == 0 {
+			result.Code = http.StatusOK
+			continue
+		}
+
+		tokens <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+			err := retry.Timed(s.conf.MaxRetry, s.conf.RetryDelayMs).On(func() error {
+				return s.handler.Delete(ctx, loc)
+			})
+			if err != nil {
+				span.Error("stream delete failed", loc.ClusterID, errors.Detail(err))
+				httpErr := rpc.Error2HTTPError(httpError(err))
+				result.Code = httpErr.StatusCode()
+				result.Error = httpErr.Error()
+				atomic.AddInt32(&failed, 1)
+				return
+			}
+			result.Code = http.StatusOK
+		}()
+	}
+	wg.Wait()
+
+	if failed > 0 {
+		span.Errorf("failed locations N %d of %d", failed, len(args.Locations))
+	}
+	span.Infof("done sdk delete batch request locations %d failed %d", len(args.Locations), failed)
+	return resp
+}
+
 func (s *sdkHandler) doPutObject(ctx context.Context, args *acapi.PutArgs) (acapi.Location, acapi.HashSumMap, error) {
 	span := trace.SpanFromContextSafe(ctx)
 	var err error
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"net/http"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -111,6 +112,41 @@ func TestSdkHandler_Delete(t *testing.T) {
 	require.Equal(t, args.Locations, ret)
 }
 
+func TestSdkHandler_DeleteBatch(t *testing.T) {
+	any := gomock.Any()
+	ctx := context.Background()
+	hd := newSdkHandler()
+	_, err := hd.DeleteBatch(ctx, nil)
+	require.ErrorIs(t, err, errcode.ErrIllegalArguments)
+	_, err = hd.DeleteBatch(ctx, &acapi.DeleteBatchArgs{})
+	require.ErrorIs(t, err, errcode.ErrIllegalArguments)
+
+	loc := acapi.Location{ClusterID: 1, Size: 1, Blobs: []acapi.SliceInfo{{Vid: 9}}}
+	loc.Crc, _ = stream.LocationCrcCalculate(&loc)
+	failed := loc
+	failed.ClusterID = 2
+	failed.Crc, _ = stream.LocationCrcCalculate(&failed)
+	empty := acapi.Location{ClusterID: 1}
+	empty.Crc, _ = stream.LocationCrcCalculate(&empty)
+	args := &acapi.DeleteBatchArgs{Locations: []acapi.Location{loc, {Size: 1}, empty, failed}}
+
+	hd.handler.(*mocks.MockStreamHandler).EXPECT().Delete(any, any).DoAndReturn(
+		func(_ context.Context, loc *acapi.Location) error {
+			if loc.ClusterID == 2 {
+				return errcode.ErrUnexpected
+			}
+			return nil
+		}).Times(1 + 3) // retry the failed
+	resp, err := hd.DeleteBatch(ctx, args)
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 4)
+	require.Equal(t, http.StatusOK, resp.Results[0].Code)
+	require.Equal(t, errcode.ErrIllegalArguments.StatusCode(), resp.Results[1].Code)
+	require.Equal(t, http.StatusOK, resp.Results[2].Code)
+	require.Equal(t, errcode.ErrUnexpected.StatusCode(), resp.Results[3].Code)
+	require.Equal(t, []int{1, 3}, resp.FailedIndexes())
+}
+
 func TestSdkHandler_Get(t *testing.T) {
 	any := gomock.Any()
 	errMock := errors.New("fake error")
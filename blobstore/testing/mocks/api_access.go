@@ -51,6 +51,21 @@ func (mr *MockAccessAPIMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAccessAPI)(nil).Delete), arg0, arg1)
 }
 
+// DeleteBatch mocks base method.
+func (m *MockAccessAPI) DeleteBatch(arg0 context.Context, arg1 *access.DeleteBatchArgs) (*access.DeleteBatchResp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBatch", arg0, arg1)
+	ret0, _ := ret[0].(*access.DeleteBatchResp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteBatch indicates an expected call of DeleteBatch.
+func (mr *MockAccessAPIMockRecorder) DeleteBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBatch", reflect.TypeOf((*MockAccessAPI)(nil).DeleteBatch), arg0, arg1)
+}
+
 // Get mocks base method.
 func (m *MockAccessAPI) Get(arg0 context.Context, arg1 *access.GetArgs) (io.ReadCloser, error) {
 	m.ctrl.T.Helper()
@@ -94,6 +94,21 @@ func (mr *MockStorageAPIMockRecorder) DiskInfo(arg0, arg1, arg2 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiskInfo", reflect.TypeOf((*MockStorageAPI)(nil).DiskInfo), arg0, arg1, arg2)
 }
 
+// DiskLoad mocks base method.
+func (m *MockStorageAPI) DiskLoad(arg0 context.Context, arg1 string, arg2 *blobnode.DiskStatArgs) (*blobnode.DiskLoad, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiskLoad", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*blobnode.DiskLoad)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiskLoad indicates an expected call of DiskLoad.
+func (mr *MockStorageAPIMockRecorder) DiskLoad(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiskLoad", reflect.TypeOf((*MockStorageAPI)(nil).DiskLoad), arg0, arg1, arg2)
+}
+
 // GetShard mocks base method.
 func (m *MockStorageAPI) GetShard(arg0 context.Context, arg1 string, arg2 *blobnode.GetShardArgs) (io.ReadCloser, uint32, error) {
 	m.ctrl.T.Helper()
@@ -184,6 +199,21 @@ func (mr *MockStorageAPIMockRecorder) PutShard(arg0, arg1, arg2 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutShard", reflect.TypeOf((*MockStorageAPI)(nil).PutShard), arg0, arg1, arg2)
 }
 
+// PushShards mocks base method.
+func (m *MockStorageAPI) PushShards(arg0 context.Context, arg1 string, arg2 *blobnode.PushShardsArgs) ([]proto.BlobID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PushShards", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]proto.BlobID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PushShards indicates an expected call of PushShards.
+func (mr *MockStorageAPIMockRecorder) PushShards(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushShards", reflect.TypeOf((*MockStorageAPI)(nil).PushShards), arg0, arg1, arg2)
+}
+
 // RangeGetShard mocks base method.
 func (m *MockStorageAPI) RangeGetShard(arg0 context.Context, arg1 string, arg2 *blobnode.RangeGetShardArgs) (io.ReadCloser, uint32, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +50,21 @@ func (mr *MockProxyClientMockRecorder) Erase(arg0, arg1, arg2 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Erase", reflect.TypeOf((*MockProxyClient)(nil).Erase), arg0, arg1, arg2)
 }
 
+// GetCacheBlob mocks base method.
+func (m *MockProxyClient) GetCacheBlob(arg0 context.Context, arg1 string, arg2 *proxy.CacheBlobArgs) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCacheBlob", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCacheBlob indicates an expected call of GetCacheBlob.
+func (mr *MockProxyClientMockRecorder) GetCacheBlob(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCacheBlob", reflect.TypeOf((*MockProxyClient)(nil).GetCacheBlob), arg0, arg1, arg2)
+}
+
 // GetCacheDisk mocks base method.
 func (m *MockProxyClient) GetCacheDisk(arg0 context.Context, arg1 string, arg2 *proxy.CacheDiskArgs) (*blobnode.DiskInfo, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +110,20 @@ func (mr *MockProxyClientMockRecorder) ListVolumes(arg0, arg1, arg2 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVolumes", reflect.TypeOf((*MockProxyClient)(nil).ListVolumes), arg0, arg1, arg2)
 }
 
+// PutCacheBlob mocks base method.
+func (m *MockProxyClient) PutCacheBlob(arg0 context.Context, arg1 string, arg2 *proxy.CacheBlobArgs, arg3 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutCacheBlob", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutCacheBlob indicates an expected call of PutCacheBlob.
+func (mr *MockProxyClientMockRecorder) PutCacheBlob(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutCacheBlob", reflect.TypeOf((*MockProxyClient)(nil).PutCacheBlob), arg0, arg1, arg2, arg3)
+}
+
 // SendDeleteMsg mocks base method.
 func (m *MockProxyClient) SendDeleteMsg(arg0 context.Context, arg1 string, arg2 *proxy.DeleteArgs) error {
 	m.ctrl.T.Helper()
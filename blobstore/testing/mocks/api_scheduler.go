@@ -66,6 +66,20 @@ func (mr *MockISchedulerMockRecorder) AcquireTask(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireTask", reflect.TypeOf((*MockIScheduler)(nil).AcquireTask), arg0, arg1)
 }
 
+// AddCampaign mocks base method.
+func (m *MockIScheduler) AddCampaign(arg0 context.Context, arg1 *scheduler.AddCampaignArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCampaign", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddCampaign indicates an expected call of AddCampaign.
+func (mr *MockISchedulerMockRecorder) AddCampaign(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCampaign", reflect.TypeOf((*MockIScheduler)(nil).AddCampaign), arg0, arg1)
+}
+
 // AddManualMigrateTask mocks base method.
 func (m *MockIScheduler) AddManualMigrateTask(arg0 context.Context, arg1 *scheduler.AddManualMigrateArgs) error {
 	m.ctrl.T.Helper()
@@ -122,6 +136,20 @@ func (mr *MockISchedulerMockRecorder) CompleteTask(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTask", reflect.TypeOf((*MockIScheduler)(nil).CompleteTask), arg0, arg1)
 }
 
+// DeleteCampaign mocks base method.
+func (m *MockIScheduler) DeleteCampaign(arg0 context.Context, arg1 *scheduler.CampaignArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCampaign", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCampaign indicates an expected call of DeleteCampaign.
+func (mr *MockISchedulerMockRecorder) DeleteCampaign(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCampaign", reflect.TypeOf((*MockIScheduler)(nil).DeleteCampaign), arg0, arg1)
+}
+
 // DetailMigrateTask mocks base method.
 func (m *MockIScheduler) DetailMigrateTask(arg0 context.Context, arg1 *scheduler.MigrateTaskDetailArgs) (scheduler.MigrateTaskDetail, error) {
 	m.ctrl.T.Helper()
@@ -167,6 +195,35 @@ func (mr *MockISchedulerMockRecorder) LeaderStats(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeaderStats", reflect.TypeOf((*MockIScheduler)(nil).LeaderStats), arg0)
 }
 
+// ListCampaigns mocks base method.
+func (m *MockIScheduler) ListCampaigns(arg0 context.Context) (*scheduler.ListCampaignsRet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCampaigns", arg0)
+	ret0, _ := ret[0].(*scheduler.ListCampaignsRet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCampaigns indicates an expected call of ListCampaigns.
+func (mr *MockISchedulerMockRecorder) ListCampaigns(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCampaigns", reflect.TypeOf((*MockIScheduler)(nil).ListCampaigns), arg0)
+}
+
+// PauseCampaign mocks base method.
+func (m *MockIScheduler) PauseCampaign(arg0 context.Context, arg1 *scheduler.CampaignArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PauseCampaign", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PauseCampaign indicates an expected call of PauseCampaign.
+func (mr *MockISchedulerMockRecorder) PauseCampaign(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseCampaign", reflect.TypeOf((*MockIScheduler)(nil).PauseCampaign), arg0, arg1)
+}
+
 // ReclaimTask mocks base method.
 func (m *MockIScheduler) ReclaimTask(arg0 context.Context, arg1 *scheduler.OperateTaskArgs) error {
 	m.ctrl.T.Helper()
@@ -210,6 +267,34 @@ func (mr *MockISchedulerMockRecorder) ReportTask(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportTask", reflect.TypeOf((*MockIScheduler)(nil).ReportTask), arg0, arg1)
 }
 
+// ResumeCampaign mocks base method.
+func (m *MockIScheduler) ResumeCampaign(arg0 context.Context, arg1 *scheduler.CampaignArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeCampaign", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResumeCampaign indicates an expected call of ResumeCampaign.
+func (mr *MockISchedulerMockRecorder) ResumeCampaign(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeCampaign", reflect.TypeOf((*MockIScheduler)(nil).ResumeCampaign), arg0, arg1)
+}
+
+// SetCampaignBudget mocks base method.
+func (m *MockIScheduler) SetCampaignBudget(arg0 context.Context, arg1 *scheduler.SetCampaignBudgetArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCampaignBudget", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCampaignBudget indicates an expected call of SetCampaignBudget.
+func (mr *MockISchedulerMockRecorder) SetCampaignBudget(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCampaignBudget", reflect.TypeOf((*MockIScheduler)(nil).SetCampaignBudget), arg0, arg1)
+}
+
 // Stats mocks base method.
 func (m *MockIScheduler) Stats(arg0 context.Context, arg1 string) (scheduler.TasksStat, error) {
 	m.ctrl.T.Helper()
@@ -238,3 +323,33 @@ func (mr *MockISchedulerMockRecorder) UpdateVolume(arg0, arg1, arg2 interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVolume", reflect.TypeOf((*MockIScheduler)(nil).UpdateVolume), arg0, arg1, arg2)
 }
+
+// VolumeInspectHistory mocks base method.
+func (m *MockIScheduler) VolumeInspectHistory(arg0 context.Context, arg1 *scheduler.VolumeInspectHistoryArgs) (*scheduler.VolumeInspectHistoryRet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VolumeInspectHistory", arg0, arg1)
+	ret0, _ := ret[0].(*scheduler.VolumeInspectHistoryRet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VolumeInspectHistory indicates an expected call of VolumeInspectHistory.
+func (mr *MockISchedulerMockRecorder) VolumeInspectHistory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VolumeInspectHistory", reflect.TypeOf((*MockIScheduler)(nil).VolumeInspectHistory), arg0, arg1)
+}
+
+// VolumeInspectTrend mocks base method.
+func (m *MockIScheduler) VolumeInspectTrend(arg0 context.Context, arg1 *scheduler.VolumeInspectTrendArgs) (*scheduler.VolumeInspectTrendRet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VolumeInspectTrend", arg0, arg1)
+	ret0, _ := ret[0].(*scheduler.VolumeInspectTrendRet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VolumeInspectTrend indicates an expected call of VolumeInspectTrend.
+func (mr *MockISchedulerMockRecorder) VolumeInspectTrend(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VolumeInspectTrend", reflect.TypeOf((*MockIScheduler)(nil).VolumeInspectTrend), arg0, arg1)
+}
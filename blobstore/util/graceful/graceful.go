@@ -18,6 +18,7 @@ import (
 	"net"
 	"os"
 	"syscall"
+	"time"
 
 	"github.com/cubefs/cubefs/blobstore/util/log"
 )
@@ -37,8 +38,12 @@ import (
 const (
 	forkEnv  = "GRACEFUL-FORK"
 	fdNumEnv = "GRACEFUL-FD-NUM"
+	// the pipes of ready and start follow the listen fds if set
+	handshakeEnv = "GRACEFUL-HANDSHAKE"
 
 	listenFdStart = 3
+
+	defaultStopTimeout = time.Minute
 )
 
 var receiveSigs = []os.Signal{syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGKILL, syscall.SIGUSR2}
@@ -59,12 +64,25 @@ type State struct {
 type Config struct {
 	Entry           programEntry
 	ListenAddresses []string
+	// Exclusive is for the program owning exclusive resources such as disks, the old process
+	// is stopped after the new one reports ready in reloading, and the new one runs the entry
+	// after the old one exits, the connections are queued in the listen sockets held by master process in the
+	// meantime. The old process keeps running if the new one fails to get ready.
+	Exclusive bool
+	// StopTimeout is the max time waiting for the new process to get ready and the old process
+	// to exit in exclusive reloading, the old process is killed after it, default is 1 minute.
+	StopTimeout time.Duration
+}
+
+// IsSlave returns true in the process forked by master process
+func IsSlave() bool {
+	return os.Getenv(forkEnv) == "1"
 }
 
 func Run(config *Config) {
 	var process process
 	checkParams(config)
-	if fork := os.Getenv(forkEnv); fork == "1" {
+	if IsSlave() {
 		process = newProcSlave(config)
 	} else {
 		process = newProcMaster(config)
@@ -76,4 +94,7 @@ func checkParams(config *Config) {
 	if len(config.ListenAddresses) < 1 {
 		log.Fatal("invalid params ListenAddresses")
 	}
+	if config.StopTimeout <= 0 {
+		config.StopTimeout = defaultStopTimeout
+	}
 }
@@ -0,0 +1,178 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graceful
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// the slave process exits before getting ready if set
+const testCrashEnv = "GRACEFUL-TEST-CRASH"
+
+// drainListener tracks the connections accepted, which are served before the process exits.
+// The http server is not shut down, which drops the requests read in shutting down.
+type drainListener struct {
+	net.Listener
+	wg sync.WaitGroup
+}
+
+type drainConn struct {
+	net.Conn
+	once sync.Once
+	done func()
+}
+
+func (l *drainListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	l.wg.Add(1)
+	return &drainConn{Conn: c, done: l.wg.Done}, nil
+}
+
+func (c *drainConn) Close() error {
+	c.once.Do(c.done)
+	return c.Conn.Close()
+}
+
+// TestMain runs the test binary forked by master process as the slave process,
+// which replies its pid until closed.
+func TestMain(m *testing.M) {
+	if !IsSlave() {
+		os.Exit(m.Run())
+	}
+	if os.Getenv(testCrashEnv) == "1" {
+		os.Exit(1)
+	}
+	Run(&Config{
+		Entry: func(state *State) {
+			server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, os.Getpid())
+			})}
+			l := &drainListener{Listener: state.ListenerFds[0]}
+			go server.Serve(l)
+			<-state.CloseCh
+			l.Close()
+			l.wg.Wait()
+		},
+		ListenAddresses: []string{"slave"},
+	})
+	os.Exit(0)
+}
+
+func TestExclusiveRestart(t *testing.T) {
+	m := newProcMaster(&Config{
+		ListenAddresses: []string{"127.0.0.1:0"},
+		Exclusive:       true,
+		StopTimeout:     10 * time.Second,
+	}).(*procMaster)
+	l, err := net.FileListener(m.extraFiles[0])
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	l.Close()
+
+	client := &http.Client{Timeout: 10 * time.Second, Transport: &http.Transport{DisableKeepAlives: true}}
+	getPid := func() (int, error) {
+		resp, err := client.Get("http://" + addr)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return 0, err
+		}
+		return strconv.Atoi(string(data))
+	}
+
+	m.lock.Lock()
+	m.fork()
+	m.lock.Unlock()
+	defer func() {
+		m.lock.Lock()
+		m.stopping = true
+		slaves := append(m.slaveProcs[:0:0], m.slaveProcs...)
+		for _, cmd := range slaves {
+			cmd.Process.Kill()
+		}
+		m.lock.Unlock()
+		m.waitSlavesExit(slaves, 10*time.Second)
+	}()
+	oldPid, err := getPid()
+	require.NoError(t, err)
+
+	// the requests in restarting are queued in the listen socket rather than refused
+	var (
+		wg       sync.WaitGroup
+		stop     = make(chan struct{})
+		requests int64
+		failures int64
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if _, err := getPid(); err != nil {
+				t.Log("request failed in restarting:", err)
+				atomic.AddInt64(&failures, 1)
+			}
+			atomic.AddInt64(&requests, 1)
+		}
+	}()
+
+	m.lock.Lock()
+	olds := append(m.slaveProcs[:0:0], m.slaveProcs...)
+	m.lock.Unlock()
+	require.NoError(t, m.restart())
+	require.False(t, m.anyRunning(olds))
+	newPid, err := getPid()
+	require.NoError(t, err)
+	require.NotEqual(t, oldPid, newPid)
+	close(stop)
+	wg.Wait()
+	require.Greater(t, atomic.LoadInt64(&requests), int64(0))
+	require.Zero(t, atomic.LoadInt64(&failures))
+
+	// the old slave keeps serving if the new one fails to get ready
+	os.Setenv(testCrashEnv, "1")
+	err = m.restart()
+	os.Unsetenv(testCrashEnv)
+	require.Error(t, err)
+	pid, err := getPid()
+	require.NoError(t, err)
+	require.Equal(t, newPid, pid)
+	require.Eventually(t, func() bool {
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		return len(m.slaveProcs) == 1
+	}, 10*time.Second, 100*time.Millisecond)
+}
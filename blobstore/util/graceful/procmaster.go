@@ -22,6 +22,7 @@ import (
 	"strconv"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/cubefs/cubefs/blobstore/util/errors"
 	"github.com/cubefs/cubefs/blobstore/util/log"
@@ -32,6 +33,9 @@ type procMaster struct {
 	programEntry    programEntry
 	extraFiles      []*os.File
 	listenAddresses []string
+	exclusive       bool
+	stopTimeout     time.Duration
+	stopping        bool
 	lock            sync.Mutex
 }
 
@@ -65,6 +69,8 @@ func newProcMaster(config *Config) process {
 		programEntry:    config.Entry,
 		extraFiles:      extraFiles,
 		listenAddresses: config.ListenAddresses,
+		exclusive:       config.Exclusive,
+		stopTimeout:     config.StopTimeout,
 	}
 }
 
@@ -77,6 +83,12 @@ func (m *procMaster) run() {
 	for sig := range sigCh {
 		log.Info("master process rececive signal: ", sig)
 		if sig == syscall.SIGUSR2 {
+			if m.exclusive {
+				if err := m.restart(); err != nil {
+					log.Error("restart slave process failed: ", errors.Detail(err))
+				}
+				continue
+			}
 			m.fork()
 		} else if sig == syscall.SIGCHLD {
 			// this signal occur every restarting, ignore it
@@ -84,6 +96,7 @@ func (m *procMaster) run() {
 		}
 		// send signal to children process
 		m.lock.Lock()
+		m.stopping = sig != syscall.SIGUSR2
 		log.Info(m.slaveProcs)
 		for i := range m.slaveProcs {
 			if i != len(m.slaveProcs)-1 || sig != syscall.SIGUSR2 {
@@ -103,20 +116,30 @@ func (m *procMaster) run() {
 }
 
 func (m *procMaster) fork() {
+	if _, err := m.forkSlave(); err != nil {
+		log.Fatal("fork new process failed：", errors.Detail(err))
+	}
+}
+
+// forkSlave starts the slave process with the listen fds, and the pipes of the handshake
+// in exclusive restarting if any, the caller holds the lock if the slaves are running.
+func (m *procMaster) forkSlave(handshakeFiles ...*os.File) (*exec.Cmd, error) {
 	log.Info("fork new process")
 	cmd := exec.Command(os.Args[0])
 	cmd.Args = os.Args
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	cmd.ExtraFiles = m.extraFiles
+	cmd.ExtraFiles = append(append([]*os.File{}, m.extraFiles...), handshakeFiles...)
 	e := os.Environ()
 	e = append(e, forkEnv+"=1")
 	e = append(e, fdNumEnv+"="+strconv.Itoa(len(m.extraFiles)))
+	if len(handshakeFiles) > 0 {
+		e = append(e, handshakeEnv+"=1")
+	}
 	cmd.Env = e
-	err := cmd.Start()
-	if err != nil {
-		log.Fatal("fork new process failed：", errors.Detail(err))
+	if err := cmd.Start(); err != nil {
+		return nil, err
 	}
 	m.slaveProcs = append(m.slaveProcs, cmd)
 	log.Info("fork new process success")
@@ -129,7 +152,7 @@ func (m *procMaster) fork() {
 				break
 			}
 		}
-		if len(m.slaveProcs) == 0 {
+		if len(m.slaveProcs) == 0 && !m.stopping {
 			log.Fatal("all slave process exit")
 		}
 		m.lock.Unlock()
@@ -138,4 +161,93 @@ func (m *procMaster) fork() {
 		}
 		log.Info("wait for children process exit success")
 	}()
+	return cmd, nil
+}
+
+// restart forks the new slave process with the listen fds, and stops the old ones after the new
+// one reports ready, then the new one starts after the old ones exit, which drain the in-flight
+// requests and release the exclusive resources in exiting. The old ones keep serving if the new
+// one fails to get ready.
+func (m *procMaster) restart() (err error) {
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		return
+	}
+	defer readyReader.Close()
+	startReader, startWriter, err := os.Pipe()
+	if err != nil {
+		readyWriter.Close()
+		return
+	}
+	defer startWriter.Close()
+
+	m.lock.Lock()
+	olds := append([]*exec.Cmd{}, m.slaveProcs...)
+	cmd, err := m.forkSlave(readyWriter, startReader)
+	m.lock.Unlock()
+	// the ends of the child are closed, so that the exit of the child is read as EOF
+	readyWriter.Close()
+	startReader.Close()
+	if err != nil {
+		return
+	}
+
+	if err = waitReady(readyReader, m.stopTimeout); err != nil {
+		log.Error("new slave process not ready, keep the old one: ", err)
+		if killErr := cmd.Process.Kill(); killErr != nil {
+			log.Error("unexpected err when killing process: ", killErr.Error())
+		}
+		return
+	}
+
+	for _, old := range olds {
+		if err := old.Process.Signal(syscall.SIGUSR2); err != nil {
+			log.Error("unexpected err when sending signal: ", err.Error())
+		}
+	}
+	if !m.waitSlavesExit(olds, m.stopTimeout) {
+		log.Warn("old slave process not exit in ", m.stopTimeout, ", kill it")
+		for _, old := range olds {
+			if err := old.Process.Kill(); err != nil {
+				log.Error("unexpected err when killing process: ", err.Error())
+			}
+		}
+		m.waitSlavesExit(olds, m.stopTimeout)
+	}
+
+	_, err = startWriter.Write([]byte{1})
+	return
+}
+
+// waitReady waits for the new slave process reporting ready by the pipe
+func waitReady(readyReader *os.File, timeout time.Duration) error {
+	if err := readyReader.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+	_, err := readyReader.Read(make([]byte, 1))
+	return err
+}
+
+func (m *procMaster) waitSlavesExit(cmds []*exec.Cmd, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if !m.anyRunning(cmds) {
+			return true
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return false
+}
+
+func (m *procMaster) anyRunning(cmds []*exec.Cmd) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, cmd := range cmds {
+		for _, proc := range m.slaveProcs {
+			if cmd.Process.Pid == proc.Process.Pid {
+				return true
+			}
+		}
+	}
+	return false
 }
@@ -31,6 +31,9 @@ type procSlave struct {
 	programEntry programEntry
 	state        *State
 	once         sync.Once
+	// the pipes of the handshake with master process in exclusive restarting
+	readyWriter *os.File
+	startReader *os.File
 }
 
 // get the listener fd by extra files
@@ -58,11 +61,33 @@ func newProcSlave(config *Config) process {
 	if err != nil {
 		log.Fatal("find master process failed, err: ", err)
 	}
-	return &procSlave{
+	slave := &procSlave{
 		masterProc:   masterProc,
 		programEntry: config.Entry,
 		state:        state,
 	}
+	if os.Getenv(handshakeEnv) == "1" {
+		slave.readyWriter = os.NewFile(uintptr(listenFdStart+fdNum), "ready")
+		slave.startReader = os.NewFile(uintptr(listenFdStart+fdNum+1), "start")
+	}
+	return slave
+}
+
+// waitStart reports ready to master process in exclusive restarting, and waits for the old
+// slave process exiting, which releases the exclusive resources.
+func (s *procSlave) waitStart() {
+	if s.readyWriter == nil {
+		return
+	}
+	if _, err := s.readyWriter.Write([]byte{1}); err != nil {
+		log.Fatal("report ready to master process failed, err: ", err)
+	}
+	s.readyWriter.Close()
+	if _, err := s.startReader.Read(make([]byte, 1)); err != nil {
+		log.Fatal("wait for starting by master process failed, err: ", err)
+	}
+	s.startReader.Close()
+	log.Info("old slave process exited, start to run")
 }
 
 // run the program entry, watching signal and syncing the master process status
@@ -90,5 +115,6 @@ func (s *procSlave) run() {
 			time.Sleep(2 * time.Second)
 		}
 	}()
+	s.waitStart()
 	s.programEntry(s.state)
 }
@@ -300,3 +300,27 @@ func unmarshalInodes(resp *http.Response) (rstMap map[uint64]*Inode, err error)
 		}
 	}
 }
+
+// ExportPartition streams the export of the namespace of the meta partition at the snapshot version
+// verSeq to w, or the current version if verSeq is 0. The export may be large so the request is sent
+// without timeout.
+func (mc *MetaHttpClient) ExportPartition(pid, verSeq uint64, w io.Writer) (err error) {
+	defer func() {
+		if err != nil {
+			log.LogErrorf("action[ExportPartition],pid:%v,err:%v", pid, err)
+		}
+	}()
+	reqURL := fmt.Sprintf("http://%v%v?pid=%v&verSeq=%v", mc.host, "/exportPartition", pid, verSeq)
+	log.LogDebugf("reqURL=%v", reqURL)
+	resp, err := (&http.Client{}).Get(reqURL)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status(%v) msg(%v)", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	_, err = io.Copy(w, resp.Body)
+	return
+}
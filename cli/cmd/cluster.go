@@ -51,6 +51,9 @@ func newClusterCmd(client *master.MasterClient) *cobra.Command {
 		newClusterEnableAutoDecommissionDiskCmd(client),
 		newClusterQueryDecommissionFailedDiskCmd(client),
 		newClusterSetDecommissionDiskLimitCmd(client),
+		newClusterSimulatePlacementCmd(client),
+		newClusterDiffCmd(client),
+		newClusterRolloutCmd(client),
 	)
 	return clusterCmd
 }
@@ -74,6 +77,8 @@ const (
 	cmdEnableAutoDecommissionDiskShort     = "enable auto decommission disk"
 	cmdQueryDecommissionFailedDiskShort    = "query auto or manual decommission failed disk"
 	cmdSetDecommissionDiskLimit            = "set decommission disk limit"
+	cmdSimulatePlacementShort              = "Simulate the effect of removing or adding nodes on partitions"
+	cmdClusterDiffShort                    = "Diff the configuration, volume settings and user policies with another cluster"
 )
 
 func newClusterInfoCmd(client *master.MasterClient) *cobra.Command {
@@ -593,3 +598,40 @@ func newClusterQueryDecommissionFailedDisk(client *master.MasterClient) *cobra.C
 	}
 	return cmd
 }
+
+func newClusterSimulatePlacementCmd(client *master.MasterClient) *cobra.Command {
+	var (
+		optRemoveNodes  []string
+		optRemoveZones  []string
+		optAddDataNodes int
+		optAddMetaNodes int
+		optZone         string
+	)
+	cmd := &cobra.Command{
+		Use:   CliOpSimulatePlacement,
+		Short: cmdSimulatePlacementShort,
+		Long: `Simulate the effect of removing nodes or zones and adding nodes on the partitions,
+and show how many partitions would go below the quorum. The cluster is not changed.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				err    error
+				result *proto.PlacementSimulation
+			)
+			defer func() {
+				errout(err)
+			}()
+			if result, err = client.AdminAPI().SimulatePlacement(optRemoveNodes, optRemoveZones,
+				optAddDataNodes, optAddMetaNodes, optZone); err != nil {
+				return
+			}
+			stdout("%v", formatPlacementSimulation(result))
+		},
+	}
+	cmd.Flags().StringSliceVar(&optRemoveNodes, "remove-nodes", nil, "Addresses of data or meta nodes to remove")
+	cmd.Flags().StringSliceVar(&optRemoveZones, "remove-zones", nil, "Zones to remove")
+	cmd.Flags().IntVar(&optAddDataNodes, "add-datanodes", 0, "Number of data nodes to add")
+	cmd.Flags().IntVar(&optAddMetaNodes, "add-metanodes", 0, "Number of meta nodes to add")
+	cmd.Flags().StringVar(&optZone, CliFlagZoneName, "", "Zone of the added nodes, empty means any zone")
+	return cmd
+}
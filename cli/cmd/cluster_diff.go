@@ -0,0 +1,266 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/master"
+	"github.com/spf13/cobra"
+)
+
+const (
+	diffMissing = "-"
+	diffExists  = "exists"
+)
+
+// the fields changed by the workload rather than configured, which are not compared
+var (
+	clusterDiffVolatileFields = []string{
+		"Name", "CreateTime", "LeaderAddr", "Applied", "MaxDataPartitionID", "MaxMetaNodeID",
+		"MaxMetaPartitionID", "DataNodeStatInfo", "MetaNodeStatInfo", "VolStatInfo", "BadPartitionIDs",
+		"BadMetaPartitionIDs", "MasterNodes", "MetaNodes", "DataNodes",
+	}
+	volDiffVolatileFields = []string{
+		"ID", "Name", "InodeCount", "DentryCount", "MaxMetaPartitionID", "Status", "RwDpCnt", "MpCnt",
+		"DpCnt", "NeedToLowerReplica", "CreateTime", "LatestVer", "DeleteExecTime",
+	}
+	userDiffVolatileFields = []string{"user_id", "access_key", "secret_key", "create_time", "EMPTY"}
+)
+
+type settingDiff struct {
+	Key    string
+	Local  string
+	Target string
+}
+
+// clusterSettings is the settings of a cluster compared, by the names of the volumes and users.
+type clusterSettings struct {
+	cluster map[string]string
+	vols    map[string]map[string]string
+	users   map[string]map[string]string
+}
+
+func newClusterDiffCmd(client *master.MasterClient) *cobra.Command {
+	var optTarget string
+	cmd := &cobra.Command{
+		Use:   CliOpDiff,
+		Short: cmdClusterDiffShort,
+		Long: `Compare the cluster configuration, the settings of the volumes and the policies of the users
+with the target cluster, such as the primary and DR clusters, and print the discrepancies.
+The statistics changed by the workload and the keys of the users are not compared.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				err            error
+				local, target  *clusterSettings
+				targetAddrList []string
+			)
+			defer func() {
+				errout(err)
+			}()
+			for _, addr := range strings.Split(optTarget, ",") {
+				if addr = strings.TrimSpace(addr); addr != "" {
+					targetAddrList = append(targetAddrList, addr)
+				}
+			}
+			if len(targetAddrList) == 0 {
+				err = fmt.Errorf("the master addresses of the target cluster are required by --%v", CliFlagTarget)
+				return
+			}
+			if local, err = loadClusterSettings(client); err != nil {
+				err = fmt.Errorf("load settings of local cluster failed: %v", err)
+				return
+			}
+			if target, err = loadClusterSettings(master.NewMasterClient(targetAddrList, false)); err != nil {
+				err = fmt.Errorf("load settings of target cluster failed: %v", err)
+				return
+			}
+			diffs := diffClusterSettings(local, target)
+			stdout("[Cluster Diff] local(%v) target(%v)\n", strings.Join(client.Nodes(), ","), strings.Join(targetAddrList, ","))
+			stdout("%v", formatSettingDiffs(diffs))
+		},
+	}
+	cmd.Flags().StringVar(&optTarget, CliFlagTarget, "", "Master addresses of the target cluster, separated by comma")
+	return cmd
+}
+
+func loadClusterSettings(client *master.MasterClient) (s *clusterSettings, err error) {
+	s = &clusterSettings{
+		vols:  make(map[string]map[string]string),
+		users: make(map[string]map[string]string),
+	}
+
+	cv, err := client.AdminAPI().GetCluster()
+	if err != nil {
+		return
+	}
+	if s.cluster, err = settingsOf(cv, clusterDiffVolatileFields...); err != nil {
+		return
+	}
+	paras, err := client.AdminAPI().GetClusterParas()
+	if err != nil {
+		return
+	}
+	for k, v := range paras {
+		s.cluster[k] = v
+	}
+
+	vols, err := client.AdminAPI().ListVols("")
+	if err != nil {
+		return
+	}
+	for _, vol := range vols {
+		var view *proto.SimpleVolView
+		if view, err = client.AdminAPI().GetVolumeSimpleInfo(vol.Name); err != nil {
+			return nil, fmt.Errorf("get volume %v: %v", vol.Name, err)
+		}
+		if s.vols[vol.Name], err = settingsOf(view, volDiffVolatileFields...); err != nil {
+			return
+		}
+	}
+
+	users, err := client.UserAPI().ListUsers("")
+	if err != nil {
+		return
+	}
+	for _, user := range users {
+		user.Policy = sortedUserPolicy(user.Policy)
+		if s.users[user.UserID], err = settingsOf(user, userDiffVolatileFields...); err != nil {
+			return
+		}
+	}
+	return s, nil
+}
+
+// sortedUserPolicy returns the policy of the volumes and actions sorted, which are in any order.
+func sortedUserPolicy(policy *proto.UserPolicy) *proto.UserPolicy {
+	if policy == nil {
+		return nil
+	}
+	sorted := &proto.UserPolicy{
+		OwnVols:        append([]string{}, policy.OwnVols...),
+		AuthorizedVols: make(map[string][]string, len(policy.AuthorizedVols)),
+	}
+	sort.Strings(sorted.OwnVols)
+	for vol, actions := range policy.AuthorizedVols {
+		actions = append([]string{}, actions...)
+		sort.Strings(actions)
+		sorted.AuthorizedVols[vol] = actions
+	}
+	return sorted
+}
+
+// settingsOf returns the json fields of v as the settings, except the volatile fields.
+func settingsOf(v interface{}, volatile ...string) (map[string]string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]json.RawMessage)
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for _, field := range volatile {
+		delete(fields, field)
+	}
+	settings := make(map[string]string, len(fields))
+	for k, raw := range fields {
+		var str string
+		if json.Unmarshal(raw, &str) == nil {
+			settings[k] = str
+		} else {
+			settings[k] = string(raw)
+		}
+	}
+	return settings, nil
+}
+
+// diffSettings returns the settings different in the clusters, the missing ones are "-".
+func diffSettings(prefix string, local, target map[string]string) []settingDiff {
+	keys := make([]string, 0, len(local)+len(target))
+	for k := range local {
+		keys = append(keys, k)
+	}
+	for k := range target {
+		if _, ok := local[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	diffs := make([]settingDiff, 0)
+	for _, k := range keys {
+		l, lok := local[k]
+		t, tok := target[k]
+		if lok && tok && l == t {
+			continue
+		}
+		if !lok {
+			l = diffMissing
+		}
+		if !tok {
+			t = diffMissing
+		}
+		diffs = append(diffs, settingDiff{Key: prefix + k, Local: l, Target: t})
+	}
+	return diffs
+}
+
+// diffObjects returns the differences of the volumes or users by the names, the ones missing
+// in a cluster are reported without the settings.
+func diffObjects(kind string, local, target map[string]map[string]string) []settingDiff {
+	localNames := make(map[string]string, len(local))
+	shared := make([]string, 0, len(local))
+	for name := range local {
+		localNames["("+name+")"] = diffExists
+		if _, ok := target[name]; ok {
+			shared = append(shared, name)
+		}
+	}
+	targetNames := make(map[string]string, len(target))
+	for name := range target {
+		targetNames["("+name+")"] = diffExists
+	}
+	sort.Strings(shared)
+
+	diffs := diffSettings(kind, localNames, targetNames)
+	for _, name := range shared {
+		diffs = append(diffs, diffSettings(fmt.Sprintf("%v(%v).", kind, name), local[name], target[name])...)
+	}
+	return diffs
+}
+
+func diffClusterSettings(local, target *clusterSettings) []settingDiff {
+	diffs := diffSettings("cluster.", local.cluster, target.cluster)
+	diffs = append(diffs, diffObjects("vol", local.vols, target.vols)...)
+	diffs = append(diffs, diffObjects("user", local.users, target.users)...)
+	return diffs
+}
+
+func formatSettingDiffs(diffs []settingDiff) string {
+	if len(diffs) == 0 {
+		return "No difference\n"
+	}
+	rows := table{arow("KEY", "LOCAL", "TARGET")}
+	for _, diff := range diffs {
+		rows = rows.append(arow(diff.Key, diff.Local, diff.Target))
+	}
+	return alignTable(rows...) + fmt.Sprintf("Total %v differences\n", len(diffs))
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCliClusterDiffSettings(t *testing.T) {
+	settings, err := settingsOf(&proto.ClusterView{Name: "c1", DisableAutoAlloc: true, MetaNodeThreshold: 0.75},
+		clusterDiffVolatileFields...)
+	require.NoError(t, err)
+	require.NotContains(t, settings, "Name")
+	require.Equal(t, "true", settings["DisableAutoAlloc"])
+	require.Equal(t, "0.75", settings["MetaNodeThreshold"])
+
+	user := &proto.UserInfo{UserID: "u1", AccessKey: "ak", Description: "desc"}
+	user.Policy = sortedUserPolicy(&proto.UserPolicy{
+		OwnVols:        []string{"v2", "v1"},
+		AuthorizedVols: map[string][]string{"v3": {"perm:builtin:Writable", "perm:builtin:ReadOnly"}},
+	})
+	settings, err = settingsOf(user, userDiffVolatileFields...)
+	require.NoError(t, err)
+	require.NotContains(t, settings, "access_key")
+	require.Equal(t, "desc", settings["description"])
+	require.Equal(t, `{"own_vols":["v1","v2"],"authorized_vols":{"v3":["perm:builtin:ReadOnly","perm:builtin:Writable"]}}`,
+		settings["policy"])
+}
+
+func TestCliClusterDiff(t *testing.T) {
+	local := &clusterSettings{
+		cluster: map[string]string{"a": "1", "b": "2"},
+		vols: map[string]map[string]string{
+			"v1": {"Capacity": "10", "Owner": "u1"},
+			"v2": {"Capacity": "10"},
+		},
+		users: map[string]map[string]string{"u1": {"policy": "p"}},
+	}
+	target := &clusterSettings{
+		cluster: map[string]string{"a": "1", "c": "3"},
+		vols: map[string]map[string]string{
+			"v1": {"Capacity": "20", "Owner": "u1"},
+			"v3": {"Capacity": "10"},
+		},
+		users: map[string]map[string]string{"u1": {"policy": "p"}},
+	}
+	require.Equal(t, []settingDiff{
+		{Key: "cluster.b", Local: "2", Target: diffMissing},
+		{Key: "cluster.c", Local: diffMissing, Target: "3"},
+		{Key: "vol(v2)", Local: diffExists, Target: diffMissing},
+		{Key: "vol(v3)", Local: diffMissing, Target: diffExists},
+		{Key: "vol(v1).Capacity", Local: "10", Target: "20"},
+	}, diffClusterSettings(local, target))
+
+	require.Empty(t, diffClusterSettings(local, local))
+	t.Log("\n" + formatSettingDiffs(diffClusterSettings(local, target)))
+}
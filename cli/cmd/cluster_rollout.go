@@ -0,0 +1,198 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/master"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdClusterRolloutShort         = "Roll out a cluster setting to the canary nodes first"
+	cmdClusterRolloutStartShort    = "Start to roll out the value of a setting to the canary nodes"
+	cmdClusterRolloutStatShort     = "Show the rollout in progress and the recent rollouts"
+	cmdClusterRolloutPromoteShort  = "Promote the rollout in progress to all the nodes"
+	cmdClusterRolloutRollbackShort = "Roll back the rollout in progress"
+)
+
+func newClusterRolloutCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   CliOpRollout,
+		Short: cmdClusterRolloutShort,
+	}
+	cmd.AddCommand(
+		newClusterRolloutStartCmd(client),
+		newClusterRolloutStatCmd(client),
+		newClusterRolloutPromoteCmd(client),
+		newClusterRolloutRollbackCmd(client),
+	)
+	return cmd
+}
+
+func newClusterRolloutStartCmd(client *master.MasterClient) *cobra.Command {
+	var (
+		optCanaryNodes     []string
+		optCanaryLabel     string
+		optCanaryPercent   int
+		optObserveSec      int64
+		optMaxErrRateDelta float64
+	)
+	cmd := &cobra.Command{
+		Use:   CliOpStart + " [KEY] [VALUE]",
+		Short: cmdClusterRolloutStartShort,
+		Long: `Start to roll out the value of a setting to the canary nodes, which are the nodes given,
+or the nodes of the label, otherwise a percent of the nodes chosen randomly. The value is
+promoted to all the nodes after the observation, or rolled back once the heartbeat error rate
+of the canary nodes exceeds the other nodes by the delta. The settings can be rolled out:
+  diskQosEnable, fileStatsEnable, qosIopsReadLimit, qosIopsWriteLimit,
+  qosFlowReadLimit (MB), qosFlowWriteLimit (MB)`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				err     error
+				rollout *proto.ConfigRollout
+			)
+			defer func() {
+				errout(err)
+			}()
+			if rollout, err = client.AdminAPI().StartConfigRollout(args[0], args[1], optCanaryNodes, optCanaryLabel,
+				optCanaryPercent, optObserveSec, optMaxErrRateDelta); err != nil {
+				return
+			}
+			stdout("%v", formatConfigRollout(rollout))
+		},
+	}
+	cmd.Flags().StringSliceVar(&optCanaryNodes, "canary-nodes", nil, "Addresses of the canary nodes")
+	cmd.Flags().StringVar(&optCanaryLabel, "canary-label", "", "Label of the canary nodes")
+	cmd.Flags().IntVar(&optCanaryPercent, "canary-percent", 10, "Percent of the nodes chosen as canary randomly")
+	cmd.Flags().Int64Var(&optObserveSec, "observe-sec", 600, "Seconds to observe the canary nodes before promoted")
+	cmd.Flags().Float64Var(&optMaxErrRateDelta, "max-err-rate-delta", 0.1,
+		"Rolled back if the error rate of the canary nodes exceeds the other nodes by it")
+	return cmd
+}
+
+func newClusterRolloutStatCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   CliOpStatus,
+		Short: cmdClusterRolloutStatShort,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				err  error
+				view *proto.ConfigRolloutView
+			)
+			defer func() {
+				errout(err)
+			}()
+			if view, err = client.AdminAPI().GetConfigRollout(); err != nil {
+				return
+			}
+			stdout("%v", formatConfigRolloutView(view))
+		},
+	}
+	return cmd
+}
+
+func newClusterRolloutPromoteCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   CliOpPromote,
+		Short: cmdClusterRolloutPromoteShort,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				err     error
+				rollout *proto.ConfigRollout
+			)
+			defer func() {
+				errout(err)
+			}()
+			if rollout, err = client.AdminAPI().PromoteConfigRollout(); err != nil {
+				return
+			}
+			stdout("%v", formatConfigRollout(rollout))
+		},
+	}
+	return cmd
+}
+
+func newClusterRolloutRollbackCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   CliOpRollback,
+		Short: cmdClusterRolloutRollbackShort,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				err     error
+				rollout *proto.ConfigRollout
+			)
+			defer func() {
+				errout(err)
+			}()
+			if rollout, err = client.AdminAPI().RollbackConfigRollout(); err != nil {
+				return
+			}
+			stdout("%v", formatConfigRollout(rollout))
+		},
+	}
+	return cmd
+}
+
+func formatErrRate(errors, samples int) string {
+	if samples == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.3f (%v/%v)", float64(errors)/float64(samples), errors, samples)
+}
+
+func formatConfigRollout(r *proto.ConfigRollout) string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("  ID               : %v\n", r.ID))
+	sb.WriteString(fmt.Sprintf("  Key              : %v\n", r.Key))
+	sb.WriteString(fmt.Sprintf("  Value            : %v -> %v\n", r.OldValue, r.Value))
+	sb.WriteString(fmt.Sprintf("  Status           : %v\n", r.Status))
+	sb.WriteString(fmt.Sprintf("  Node type        : %v\n", r.NodeType))
+	sb.WriteString(fmt.Sprintf("  Canary nodes     : [%v]\n", strings.Join(r.CanaryNodes, ", ")))
+	sb.WriteString(fmt.Sprintf("  Canary err rate  : %v\n", formatErrRate(r.CanaryErrors, r.CanarySamples)))
+	sb.WriteString(fmt.Sprintf("  Others err rate  : %v\n", formatErrRate(r.ControlErrors, r.ControlSamples)))
+	sb.WriteString(fmt.Sprintf("  Max delta        : %v\n", r.MaxErrRateDelta))
+	sb.WriteString(fmt.Sprintf("  Observe          : %vs\n", r.ObserveSec))
+	sb.WriteString(fmt.Sprintf("  Start time       : %v\n", formatTime(r.StartTime)))
+	if r.EndTime > 0 {
+		sb.WriteString(fmt.Sprintf("  End time         : %v\n", formatTime(r.EndTime)))
+		sb.WriteString(fmt.Sprintf("  Reason           : %v\n", r.Reason))
+	}
+	return sb.String()
+}
+
+func formatConfigRolloutView(view *proto.ConfigRolloutView) string {
+	sb := strings.Builder{}
+	sb.WriteString("Current:\n")
+	if view.Current == nil {
+		sb.WriteString("  none\n")
+	} else {
+		sb.WriteString(formatConfigRollout(view.Current))
+	}
+	sb.WriteString("\nHistory:\n")
+	sb.WriteString(fmt.Sprintf("  %-6v    %-20v    %-24v    %-10v    %-20v    %v\n", "ID", "KEY", "VALUE", "STATUS", "END TIME", "REASON"))
+	for _, r := range view.History {
+		sb.WriteString(fmt.Sprintf("  %-6v    %-20v    %-24v    %-10v    %-20v    %v\n", r.ID, r.Key, r.OldValue+" -> "+r.Value,
+			r.Status, formatTime(r.EndTime), r.Reason))
+	}
+	return sb.String()
+}
@@ -30,6 +30,8 @@ const (
 	CliOpQueryProgress               = "query-progress"
 	CliOpAbortDecommission           = "abort-decommission"
 	CliOpMigrate                     = "migrate"
+	CliOpSetWeight                   = "set-weight"
+	CliOpSetLabels                   = "set-labels"
 	CliOpDownloadZip                 = "load"
 	CliOpMetaCompatibility           = "meta"
 	CliOpFreeze                      = "freeze"
@@ -49,7 +51,15 @@ const (
 	CliOpEnableAutoDecommission      = "enable-auto-decommission"
 	CliOpQueryDecommissionFailedDisk = "query-decommission-failed-disk"
 	CliOpSetDecommissionDiskLimit    = "set-decommission-disk-limit"
+	CliOpSimulatePlacement           = "simulate"
+	CliOpRollout                     = "rollout"
+	CliOpPromote                     = "promote"
+	CliOpRollback                    = "rollback"
+	CliOpDiff                        = "diff"
 	CliOpResetRestoreStatus          = "reset-restore-status"
+	CliOpStart                       = "start"
+	CliOpStop                        = "stop"
+	CliOpReplace                     = "replace"
 
 	CliOpSetDecommissionLimit    = "set-decommission-limit"
 	CliOpQueryDecommissionStatus = "query-decommission-status"
@@ -130,6 +140,10 @@ const (
 	CliFlagForceInode              = "forceInode"
 	CliFlagEnableQuota             = "enableQuota"
 	CliFlagDeleteLockTime          = "delete-lock-time"
+	CliFlagCaseInsensitive         = "case-insensitive"
+	CliFlagAffinityLabels          = "affinity-labels"
+	CliFlagAntiAffinityLabels      = "anti-affinity-labels"
+	CliFlagFsyncPolicy             = "fsync-policy"
 	CliFlagClientIDKey             = "clientIDKey"
 	CliFlagMarkDiskBrokenThreshold = "markBrokenDiskThreshold"
 	CliFlagForce                   = "force"
@@ -137,6 +151,7 @@ const (
 	CliFlagAutoDpMetaRepair        = "autoDpMetaRepair"
 	CliFlagDpRepairTimeout         = "dpRepairTimeout"
 	CliFlagDpTimeout               = "dpTimeout"
+	CliFlagTarget                  = "target"
 
 	// CliFlagSetDataPartitionCount	= "count" use dp-count instead
 
@@ -15,7 +15,9 @@
 package cmd
 
 import (
+	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/cubefs/cubefs/sdk/master"
@@ -25,6 +27,8 @@ import (
 const (
 	cmdDataNodeShort            = "Manage data nodes"
 	cmdDataNodeMigrateInfoShort = "Migrate partitions from a data node to the other node"
+	cmdDataNodeSetWeightShort   = "Set the weight of partition allocation of a data node"
+	cmdDataNodeSetLabelsShort   = "Set the labels of a data node"
 	dpMigrateMax                = 50
 )
 
@@ -38,7 +42,10 @@ func newDataNodeCmd(client *master.MasterClient) *cobra.Command {
 		newDataNodeInfoCmd(client),
 		newDataNodeDecommissionCmd(client),
 		newDataNodeMigrateCmd(client),
+		newDataNodeSetWeightCmd(client),
+		newDataNodeSetLabelsCmd(client),
 		newDataNodeQueryDecommissionedDisk(client),
+		newDataNodeDiskCmd(client),
 	)
 	return cmd
 }
@@ -194,3 +201,64 @@ func newDataNodeQueryDecommissionedDisk(client *master.MasterClient) *cobra.Comm
 	}
 	return cmd
 }
+
+func newDataNodeSetWeightCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   CliOpSetWeight + " [{HOST}:{PORT}] [WEIGHT]",
+		Short: cmdDataNodeSetWeightShort,
+		Long: `Set the weight of partition allocation of the data node, which is used by the "Weighted" node selector.
+A node with weight 2 is allocated about twice the partitions of a node with weight 1 and the same capacity,
+the weight 0 resets it to the value in master config, or 1 if not configured.`,
+		Args: cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			var weight float64
+			if weight, err = strconv.ParseFloat(args[1], 64); err != nil || weight < 0 {
+				err = fmt.Errorf("invalid weight %v, should be a non-negative number", args[1])
+				return
+			}
+			if err = client.NodeAPI().SetDataNodeAllocWeight(args[0], weight); err != nil {
+				return
+			}
+			stdout("Set alloc weight of data node %v to %v successfully\n", args[0], weight)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validDataNodes(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	return cmd
+}
+
+func newDataNodeSetLabelsCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   CliOpSetLabels + " [{HOST}:{PORT}] [LABELS]",
+		Short: cmdDataNodeSetLabelsShort,
+		Long: `Set the labels of the data node separated by comma, such as "ssd,tenantA", the labels are matched with
+the affinity and anti-affinity labels of volumes to allocate partitions, an empty string clears the labels.`,
+		Args: cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			labels := parseLabels(args[1])
+			if err = client.NodeAPI().SetDataNodeLabels(args[0], labels); err != nil {
+				return
+			}
+			stdout("Set labels of data node %v to %v successfully\n", args[0], labels)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validDataNodes(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,206 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/master"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdDataNodeDiskShort    = "Manage the disks of a data node"
+	cmdDiskReplaceShort     = "Replace a disk of a data node step by step"
+	diskReplaceSteps        = 5
+	diskReplaceStatusOk     = "Success"
+	defaultDiskReplaceWait  = 10 * time.Second
+	defaultDiskReplaceLimit = 24 * time.Hour
+)
+
+func newDataNodeDiskCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   CliResourceDisk,
+		Short: cmdDataNodeDiskShort,
+	}
+	cmd.AddCommand(
+		newDiskReplaceCmd(client),
+	)
+	return cmd
+}
+
+type diskReplacer struct {
+	client   *master.MasterClient
+	addr     string
+	disk     string
+	interval time.Duration
+	timeout  time.Duration
+	yes      bool
+	dps      []uint64
+}
+
+func newDiskReplaceCmd(client *master.MasterClient) *cobra.Command {
+	r := &diskReplacer{client: client}
+	cmd := &cobra.Command{
+		Use:   CliOpReplace + " [DATA NODE ADDR] [DISK]",
+		Short: cmdDiskReplaceShort,
+		Long: `Replace a disk of a data node, which runs the following steps in order and stops at the first failure:
+  1. decommission the disk so that no partition is allocated on it
+  2. wait until all the data partitions on the disk are migrated
+  3. verify the replica count of the migrated data partitions
+  4. wait for the new disk to be installed and reported writable by the data node
+  5. recommission the disk
+The command can be run again to resume from the step where it stopped.`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			r.addr, r.disk = args[0], args[1]
+			err = r.run()
+		},
+	}
+	cmd.Flags().DurationVar(&r.interval, "interval", defaultDiskReplaceWait, "Interval to poll the progress")
+	cmd.Flags().DurationVar(&r.timeout, "timeout", defaultDiskReplaceLimit, "Timeout of each waiting step")
+	cmd.Flags().BoolVarP(&r.yes, "yes", "y", false, "Do not prompt to confirm the new disk is installed")
+	return cmd
+}
+
+func (r *diskReplacer) run() (err error) {
+	steps := []struct {
+		name string
+		do   func() error
+	}{
+		{"decommission disk", r.decommission},
+		{"wait for migration", r.waitMigration},
+		{"verify replicas", r.verifyReplicas},
+		{"accept new disk", r.acceptNewDisk},
+		{"recommission disk", r.recommission},
+	}
+	for i, step := range steps {
+		stdout("[%v/%v] %v %v:%v\n", i+1, diskReplaceSteps, step.name, r.addr, r.disk)
+		if err = step.do(); err != nil {
+			return fmt.Errorf("step [%v/%v] %v failed: %v", i+1, diskReplaceSteps, step.name, err)
+		}
+		stdout("[%v/%v] %v done\n", i+1, diskReplaceSteps, step.name)
+	}
+	stdout("Disk %v:%v is replaced\n", r.addr, r.disk)
+	return
+}
+
+func (r *diskReplacer) decommission() (err error) {
+	view, err := r.client.ClientAPI().GetDiskDataPartitions(r.addr, r.disk)
+	if err != nil {
+		return
+	}
+	for _, dp := range view.DataPartitions {
+		r.dps = append(r.dps, dp.PartitionID)
+	}
+	stdout("      %v data partitions on the disk\n", len(r.dps))
+	// resume the decommission task that is already submitted
+	if progress, e := r.client.AdminAPI().QueryDecommissionDiskProgress(r.addr, r.disk); e == nil {
+		stdout("      decommission is already submitted, status %v\n", progress.StatusMessage)
+		return
+	}
+	return r.client.AdminAPI().DecommissionDisk(r.addr, r.disk)
+}
+
+func (r *diskReplacer) waitMigration() (err error) {
+	deadline := time.Now().Add(r.timeout)
+	for {
+		var progress *proto.DecommissionProgress
+		if progress, err = r.client.AdminAPI().QueryDecommissionDiskProgress(r.addr, r.disk); err != nil {
+			return
+		}
+		stdout("      status %v progress %v\n", progress.StatusMessage, progress.Progress)
+		switch progress.StatusMessage {
+		case diskReplaceStatusOk:
+			return nil
+		case "Failed", "Paused", "DecommissionCancel":
+			return fmt.Errorf("decommission is %v:\n%v", progress.StatusMessage, formatDecommissionProgress(progress))
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout after %v", r.timeout)
+		}
+		time.Sleep(r.interval)
+	}
+}
+
+func (r *diskReplacer) verifyReplicas() (err error) {
+	partitions := make([]*proto.DataPartitionInfo, 0, len(r.dps))
+	for _, id := range r.dps {
+		var dp *proto.DataPartitionInfo
+		if dp, err = r.client.AdminAPI().GetDataPartitionById(id); err != nil {
+			return fmt.Errorf("get data partition %v: %v", id, err)
+		}
+		partitions = append(partitions, dp)
+	}
+	if err = verifyReplacedDiskReplicas(r.addr, r.disk, partitions); err != nil {
+		return
+	}
+	stdout("      %v data partitions have the full replicas\n", len(partitions))
+	return
+}
+
+// verifyReplacedDiskReplicas checks that the data partitions migrated from the disk have the full
+// replicas and none of them is left on the disk.
+func verifyReplacedDiskReplicas(addr, disk string, partitions []*proto.DataPartitionInfo) error {
+	for _, dp := range partitions {
+		if len(dp.Hosts) < int(dp.ReplicaNum) || len(dp.Replicas) < int(dp.ReplicaNum) {
+			return fmt.Errorf("data partition %v has %v hosts and %v replicas, expect %v",
+				dp.PartitionID, len(dp.Hosts), len(dp.Replicas), dp.ReplicaNum)
+		}
+		for _, replica := range dp.Replicas {
+			if replica.Addr == addr && replica.DiskPath == disk {
+				return fmt.Errorf("data partition %v still has a replica on the disk", dp.PartitionID)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *diskReplacer) acceptNewDisk() (err error) {
+	if !r.yes {
+		stdout("      Replace the disk and mount it at %v on %v, restart the data node if needed.\n", r.disk, r.addr)
+		stdout("      Is the new disk installed (yes/no)[no]:")
+		var userConfirm string
+		_, _ = fmt.Scanln(&userConfirm)
+		if userConfirm != "yes" {
+			return fmt.Errorf("abort by user, run the command again to resume")
+		}
+	}
+	deadline := time.Now().Add(r.timeout)
+	for {
+		var info *proto.DiskInfo
+		if info, err = r.client.AdminAPI().DiskDetail(r.addr, r.disk); err != nil {
+			return
+		}
+		stdout("      status %v total %v errors %v\n", info.Status, formatSize(info.Total), len(info.DiskErrPartitionList))
+		if info.Status == proto.DiskStatusMap[proto.ReadWrite] && len(info.DiskErrPartitionList) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout after %v", r.timeout)
+		}
+		time.Sleep(r.interval)
+	}
+}
+
+func (r *diskReplacer) recommission() (err error) {
+	return r.client.AdminAPI().RecommissionDisk(r.addr, r.disk)
+}
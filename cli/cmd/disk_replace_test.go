@@ -0,0 +1,47 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyReplacedDiskReplicas(t *testing.T) {
+	addr, disk := "192.168.0.1:17310", "/data0"
+	newDp := func(id uint64, replicas ...*proto.DataReplica) *proto.DataPartitionInfo {
+		dp := &proto.DataPartitionInfo{PartitionID: id, ReplicaNum: 3, Replicas: replicas}
+		for _, replica := range replicas {
+			dp.Hosts = append(dp.Hosts, replica.Addr)
+		}
+		return dp
+	}
+	replica := func(addr, disk string) *proto.DataReplica {
+		return &proto.DataReplica{Addr: addr, DiskPath: disk}
+	}
+
+	require.NoError(t, verifyReplacedDiskReplicas(addr, disk, nil))
+	require.NoError(t, verifyReplacedDiskReplicas(addr, disk, []*proto.DataPartitionInfo{
+		newDp(1, replica("192.168.0.2:17310", "/data0"), replica("192.168.0.3:17310", "/data0"), replica(addr, "/data1")),
+	}))
+	require.Error(t, verifyReplacedDiskReplicas(addr, disk, []*proto.DataPartitionInfo{
+		newDp(2, replica("192.168.0.2:17310", "/data0"), replica("192.168.0.3:17310", "/data0")),
+	}))
+	require.Error(t, verifyReplacedDiskReplicas(addr, disk, []*proto.DataPartitionInfo{
+		newDp(3, replica("192.168.0.2:17310", "/data0"), replica("192.168.0.3:17310", "/data0"), replica(addr, disk)),
+	}))
+}
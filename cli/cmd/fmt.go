@@ -178,6 +178,22 @@ func formatSimpleVolView(svv *proto.SimpleVolView) string {
 	sb.WriteString(fmt.Sprintf("  DpRepairBlockSize               : %v\n", strutil.FormatSize(svv.DpRepairBlockSize)))
 	sb.WriteString(fmt.Sprintf("  EnableAutoDpMetaRepair          : %v\n", svv.EnableAutoDpMetaRepair))
 	sb.WriteString(fmt.Sprintf("  Quota                           : %v\n", formatEnabledDisabled(svv.EnableQuota)))
+	sb.WriteString(fmt.Sprintf("  CaseInsensitive                 : %v\n", svv.CaseInsensitive))
+	if len(svv.PreviousNames) > 0 {
+		sb.WriteString(fmt.Sprintf("  PreviousNames                   : %v\n", strings.Join(svv.PreviousNames, ",")))
+	}
+	if svv.CloneSource != "" {
+		sb.WriteString(fmt.Sprintf("  CloneSource                     : %v (snapshot version %v)\n", svv.CloneSource, svv.CloneVerSeq))
+	}
+	if len(svv.AffinityLabels) > 0 {
+		sb.WriteString(fmt.Sprintf("  AffinityLabels                  : %v\n", strings.Join(svv.AffinityLabels, ",")))
+	}
+	if len(svv.AntiAffinityLabels) > 0 {
+		sb.WriteString(fmt.Sprintf("  AntiAffinityLabels              : %v\n", strings.Join(svv.AntiAffinityLabels, ",")))
+	}
+	if svv.FsyncPolicy != "" {
+		sb.WriteString(fmt.Sprintf("  FsyncPolicy                     : %v\n", svv.FsyncPolicy))
+	}
 	if svv.Forbidden && svv.Status == 1 {
 		sb.WriteString(fmt.Sprintf("  DeleteDelayTime                 : %v\n", time.Until(svv.DeleteExecTime)))
 	}
@@ -242,6 +258,16 @@ func formatUidInfoTableRow(uidInfo *proto.UidSpaceInfo) string {
 		uidInfo.Uid, time.Unix(uidInfo.CTime, 0).Format(time.RFC1123), uidInfo.Enabled, uidInfo.Limited, uidInfo.LimitSize, uidInfo.UsedSize)
 }
 
+var (
+	volumeAuditPattern     = "%-25v    %-12v    %-16v    %-21v    %-10v    %v"
+	volumeAuditTableHeader = fmt.Sprintf(volumeAuditPattern, "TIME", "OP", "PRINCIPAL", "CLIENT", "RESULT", "DETAIL")
+)
+
+func formatVolAuditTableRow(record *proto.VolAuditRecord) string {
+	return fmt.Sprintf(volumeAuditPattern,
+		time.Unix(0, record.Time).Format(time.RFC3339), record.Op, record.Principal, record.Client, record.Result, record.Detail)
+}
+
 func formatVerInfoTableRow(verInfo *proto.VolVersionInfo) string {
 	return fmt.Sprintf(volumeVersionPattern,
 		verInfo.Ver, time.UnixMicro(int64(verInfo.Ver)).Local().Format(time.RFC1123), verInfo.Status, "")
@@ -776,6 +802,8 @@ func formatDataNodeDetail(dn *proto.DataNodeInfo, rowTable bool) string {
 	sb.WriteString(fmt.Sprintf("  Total               : %v\n", formatSize(dn.Total)))
 	sb.WriteString(fmt.Sprintf("  Zone                : %v\n", dn.ZoneName))
 	sb.WriteString(fmt.Sprintf("  Rdonly              : %v\n", dn.RdOnly))
+	sb.WriteString(fmt.Sprintf("  Alloc weight        : %v\n", dn.AllocWeight))
+	sb.WriteString(fmt.Sprintf("  Labels              : %v\n", strings.Join(dn.Labels, ",")))
 	sb.WriteString(fmt.Sprintf("  IsActive            : %v\n", formatNodeStatus(dn.IsActive)))
 	sb.WriteString(fmt.Sprintf("  ToBeOffline         : %v\n", formatNodeOfflineStatus(dn.ToBeOffline)))
 	sb.WriteString(fmt.Sprintf("  Report time         : %v\n", formatTimeToString(dn.ReportTime)))
@@ -813,6 +841,8 @@ func formatMetaNodeDetail(mn *proto.MetaNodeInfo, rowTable bool) string {
 	sb.WriteString(fmt.Sprintf("  Allocated           : %v\n", formatSize(mn.Used)))
 	sb.WriteString(fmt.Sprintf("  Total               : %v\n", formatSize(mn.Total)))
 	sb.WriteString(fmt.Sprintf("  Zone                : %v\n", mn.ZoneName))
+	sb.WriteString(fmt.Sprintf("  Alloc weight        : %v\n", mn.AllocWeight))
+	sb.WriteString(fmt.Sprintf("  Labels              : %v\n", strings.Join(mn.Labels, ",")))
 	sb.WriteString(fmt.Sprintf("  IsActive            : %v\n", formatNodeStatus(mn.IsActive)))
 	sb.WriteString(fmt.Sprintf("  Report time         : %v\n", formatTimeToString(mn.ReportTime)))
 	sb.WriteString(fmt.Sprintf("  Partition count     : %v\n", mn.MetaPartitionCount))
@@ -1043,3 +1073,56 @@ func replicaInHost(hosts []string, replica string) bool {
 func formatDecommissionTokenStatus(status *proto.DecommissionTokenStatus) string {
 	return fmt.Sprintf("Nodeset %v: %v/%v", status.NodesetID, status.CurTokenNum, status.MaxTokenNum)
 }
+
+var topItemTableRowPattern = "%-4v    %-24v    %-24v    %-10v    %-14v    %-12v"
+
+func formatTopItemTableHeader(kind string) string {
+	return fmt.Sprintf(topItemTableRowPattern, "RANK", kind, "VOLUME", "QPS", "BANDWIDTH", "LATENCY(us)")
+}
+
+func formatTopItemTableRow(rank int, item *proto.TopItem) string {
+	return fmt.Sprintf(topItemTableRowPattern, rank, item.Name, item.VolName, item.Qps,
+		formatSize(item.Bandwidth)+"/s", item.AvgLatencyUs)
+}
+
+func formatPlacementImpact(kind string, impact *proto.PlacementImpact) string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("%v partitions:\n", kind))
+	sb.WriteString(fmt.Sprintf("  Total               : %v\n", impact.Total))
+	sb.WriteString(fmt.Sprintf("  Degraded            : %v\n", impact.Degraded))
+	sb.WriteString(fmt.Sprintf("  BelowQuorum         : %v\n", impact.BelowQuorum))
+	sb.WriteString(fmt.Sprintf("  AllReplicasLost     : %v\n", impact.AllReplicasLost))
+	sb.WriteString(fmt.Sprintf("  LostReplicas        : %v\n", impact.LostReplicas))
+	sb.WriteString(fmt.Sprintf("  UnplaceableReplicas : %v\n", impact.UnplaceableReplicas))
+	if impact.RequiredSpace > 0 || impact.AvailableSpace > 0 {
+		sb.WriteString(fmt.Sprintf("  RequiredSpace       : %v\n", formatSize(impact.RequiredSpace)))
+		sb.WriteString(fmt.Sprintf("  AvailableSpace      : %v\n", formatSize(impact.AvailableSpace)))
+	}
+	if len(impact.BelowQuorumPartitions) > 0 {
+		sb.WriteString(fmt.Sprintf("  %-12v    %-24v    %-8v    %-40v    %v\n", "ID", "VOLUME", "REPLICAS", "ALIVE", "LOST"))
+		for _, partition := range impact.BelowQuorumPartitions {
+			sb.WriteString(fmt.Sprintf("  %-12v    %-24v    %-8v    %-40v    %v\n", partition.PartitionID, partition.VolName,
+				partition.ReplicaNum, "["+strings.Join(partition.AliveHosts, ", ")+"]", "["+strings.Join(partition.LostHosts, ", ")+"]"))
+		}
+		if len(impact.BelowQuorumPartitions) < impact.BelowQuorum {
+			sb.WriteString(fmt.Sprintf("  ... %v more\n", impact.BelowQuorum-len(impact.BelowQuorumPartitions)))
+		}
+	}
+	return sb.String()
+}
+
+func formatPlacementSimulation(result *proto.PlacementSimulation) string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("Removed data nodes : %v\n", result.RemovedDataNodes))
+	sb.WriteString(fmt.Sprintf("Removed meta nodes : %v\n", result.RemovedMetaNodes))
+	sb.WriteString(fmt.Sprintf("Added data nodes   : %v\n", result.AddedDataNodes))
+	sb.WriteString(fmt.Sprintf("Added meta nodes   : %v\n", result.AddedMetaNodes))
+	if result.AddedZone != "" {
+		sb.WriteString(fmt.Sprintf("Zone of added nodes: %v\n", result.AddedZone))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(formatPlacementImpact("Data", result.DataPartitions))
+	sb.WriteString("\n")
+	sb.WriteString(formatPlacementImpact("Meta", result.MetaPartitions))
+	return sb.String()
+}
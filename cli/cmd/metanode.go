@@ -15,7 +15,9 @@
 package cmd
 
 import (
+	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/cubefs/cubefs/proto"
@@ -39,6 +41,8 @@ func newMetaNodeCmd(client *master.MasterClient) *cobra.Command {
 		newMetaNodeInfoCmd(client),
 		newMetaNodeDecommissionCmd(client),
 		newMetaNodeMigrateCmd(client),
+		newMetaNodeSetWeightCmd(client),
+		newMetaNodeSetLabelsCmd(client),
 	)
 	return cmd
 }
@@ -48,6 +52,8 @@ const (
 	cmdMetaNodeInfoShort             = "Show information of meta nodes"
 	cmdMetaNodeDecommissionInfoShort = "Decommission partitions in a meta node to other nodes"
 	cmdMetaNodeMigrateInfoShort      = "Migrate partitions from a meta node to the other node"
+	cmdMetaNodeSetWeightShort        = "Set the weight of partition allocation of a meta node"
+	cmdMetaNodeSetLabelsShort        = "Set the labels of a meta node"
 )
 
 func newMetaNodeListCmd(client *master.MasterClient) *cobra.Command {
@@ -192,3 +198,64 @@ func newMetaNodeMigrateCmd(client *master.MasterClient) *cobra.Command {
 	cmd.Flags().StringVar(&clientIDKey, CliFlagClientIDKey, client.ClientIDKey(), CliUsageClientIDKey)
 	return cmd
 }
+
+func newMetaNodeSetWeightCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   CliOpSetWeight + " [{HOST}:{PORT}] [WEIGHT]",
+		Short: cmdMetaNodeSetWeightShort,
+		Long: `Set the weight of partition allocation of the meta node, which is used by the "Weighted" node selector.
+A node with weight 2 is allocated about twice the partitions of a node with weight 1 and the same capacity,
+the weight 0 resets it to the value in master config, or 1 if not configured.`,
+		Args: cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			var weight float64
+			if weight, err = strconv.ParseFloat(args[1], 64); err != nil || weight < 0 {
+				err = fmt.Errorf("invalid weight %v, should be a non-negative number", args[1])
+				return
+			}
+			if err = client.NodeAPI().SetMetaNodeAllocWeight(args[0], weight); err != nil {
+				return
+			}
+			stdout("Set alloc weight of meta node %v to %v successfully\n", args[0], weight)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validMetaNodes(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	return cmd
+}
+
+func newMetaNodeSetLabelsCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   CliOpSetLabels + " [{HOST}:{PORT}] [LABELS]",
+		Short: cmdMetaNodeSetLabelsShort,
+		Long: `Set the labels of the meta node separated by comma, such as "ssd,tenantA", the labels are matched with
+the affinity and anti-affinity labels of volumes to allocate partitions, an empty string clears the labels.`,
+		Args: cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			labels := parseLabels(args[1])
+			if err = client.NodeAPI().SetMetaNodeLabels(args[0], labels); err != nil {
+				return
+			}
+			stdout("Set labels of meta node %v to %v successfully\n", args[0], labels)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validMetaNodes(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	return cmd
+}
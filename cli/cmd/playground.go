@@ -0,0 +1,591 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// The playground runs a mini cluster on the local host, every node listens on its own
+// loopback address, since the raft ports of the peers are the same as the local ones.
+const (
+	cmdPlaygroundUse        = "playground [COMMAND]"
+	cmdPlaygroundShort      = "Run a mini cluster on the local host for development and test"
+	cmdPlaygroundStartShort = "Start the playground cluster"
+	cmdPlaygroundStopShort  = "Stop the playground cluster"
+	cmdPlaygroundStatShort  = "Show the nodes of the playground cluster"
+
+	playgroundModeProcess = "process"
+	playgroundModeDocker  = "docker"
+
+	playgroundStateFile      = "playground.json"
+	playgroundComposeFile    = "docker-compose.yml"
+	playgroundComposeProject = "cubefs-playground"
+	playgroundClusterName    = "playground"
+	playgroundMasterAddr     = "127.0.0.1:17010"
+	playgroundObjectNodeAddr = "127.0.0.1:17410"
+	playgroundMaxNodes       = 9
+	playgroundStartTimeout   = 30 * time.Second
+	playgroundStopTimeout    = 10 * time.Second
+
+	defaultPlaygroundDir   = "/tmp/cubefs-playground"
+	defaultPlaygroundImage = "ghcr.io/cubefs/cbfs-base:1.0-golang-1.16.12"
+	// meta partitions are always of 3 replicas
+	defaultPlaygroundMetaNodes = 3
+	defaultPlaygroundDataNodes = 1
+)
+
+type playgroundNode struct {
+	Name   string                 `json:"name"`
+	Role   string                 `json:"role"`
+	Addr   string                 `json:"addr"`
+	Pid    int                    `json:"pid,omitempty"`
+	Config map[string]interface{} `json:"-"`
+}
+
+type playgroundState struct {
+	Mode           string            `json:"mode"`
+	BinDir         string            `json:"binDir"`
+	Image          string            `json:"image,omitempty"`
+	BlobstoreImage string            `json:"blobstoreImage,omitempty"`
+	Nodes          []*playgroundNode `json:"nodes"`
+}
+
+func newPlaygroundCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   cmdPlaygroundUse,
+		Short: cmdPlaygroundShort,
+	}
+	cmd.AddCommand(
+		newPlaygroundStartCmd(),
+		newPlaygroundStopCmd(),
+		newPlaygroundStatCmd(),
+	)
+	return cmd
+}
+
+func newPlaygroundStartCmd() *cobra.Command {
+	var (
+		optMode           string
+		optDir            string
+		optBinDir         string
+		optImage          string
+		optBlobstoreImage string
+		optMetaNodes      int
+		optDataNodes      int
+		optClean          bool
+	)
+	cmd := &cobra.Command{
+		Use:   CliOpStart,
+		Short: cmdPlaygroundStartShort,
+		Long: `Start a mini cluster of 1 master, metanodes, datanodes and 1 objectnode on the local host.
+In process mode the nodes are the processes of cfs-server, in docker mode they are the containers
+of docker-compose with the host network. The data is kept in the directory across restarts.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			if optMode != playgroundModeProcess && optMode != playgroundModeDocker {
+				err = fmt.Errorf("unknown mode %v, must be %v or %v", optMode, playgroundModeProcess, playgroundModeDocker)
+				return
+			}
+			if optMetaNodes < 1 || optMetaNodes > playgroundMaxNodes || optDataNodes < 1 || optDataNodes > playgroundMaxNodes {
+				err = fmt.Errorf("count of metanodes and datanodes must be in [1, %v]", playgroundMaxNodes)
+				return
+			}
+			if optBlobstoreImage != "" && optMode != playgroundModeDocker {
+				err = fmt.Errorf("blobstore is only supported in %v mode", playgroundModeDocker)
+				return
+			}
+			if optDir, err = filepath.Abs(optDir); err != nil {
+				return
+			}
+			if optBinDir == "" {
+				optBinDir = defaultPlaygroundBinDir()
+			}
+			if optBinDir, err = filepath.Abs(optBinDir); err != nil {
+				return
+			}
+			if _, err = os.Stat(path.Join(optBinDir, "cfs-server")); err != nil {
+				err = fmt.Errorf("cfs-server is not found in %v, specify it by --bin", optBinDir)
+				return
+			}
+			if state, _ := loadPlaygroundState(optDir); state != nil {
+				err = fmt.Errorf("playground in %v is running, stop it first", optDir)
+				return
+			}
+			if optClean {
+				if err = cleanPlaygroundData(optDir); err != nil {
+					return
+				}
+			}
+
+			state := &playgroundState{
+				Mode:           optMode,
+				BinDir:         optBinDir,
+				Nodes:          playgroundNodes(optDir, optMetaNodes, optDataNodes),
+				BlobstoreImage: optBlobstoreImage,
+			}
+			if optMode == playgroundModeDocker {
+				state.Image = optImage
+			}
+			if err = writePlaygroundConfigs(optDir, state); err != nil {
+				return
+			}
+			if optMode == playgroundModeProcess {
+				err = startPlaygroundProcesses(optDir, state)
+			} else {
+				err = startPlaygroundContainers(optDir, state)
+			}
+			if err != nil {
+				return
+			}
+			if err = savePlaygroundState(optDir, state); err != nil {
+				return
+			}
+
+			stdout("playground is started in %v mode, data and logs are in %v\n", optMode, optDir)
+			stdout("%v\n", formatPlaygroundNodes(state))
+			stdout("\nnext steps:\n")
+			stdout("  cfs-cli config set --addr %v\n", playgroundMasterAddr)
+			stdout("  cfs-cli user create <user>\n")
+			stdout("  cfs-cli volume create <volume> <user> --replica-num %v\n", minInt(optDataNodes, 3))
+			stdout("  object gateway: http://%v\n", playgroundObjectNodeAddr)
+		},
+	}
+	cmd.Flags().StringVar(&optMode, "mode", playgroundModeProcess, "Run the nodes as processes or docker containers [process|docker]")
+	cmd.Flags().StringVar(&optDir, "dir", defaultPlaygroundDir, "Directory of the configs, data and logs of the nodes")
+	cmd.Flags().StringVar(&optBinDir, "bin", "", "Directory of cfs-server, default is the directory of cfs-cli")
+	cmd.Flags().StringVar(&optImage, "image", defaultPlaygroundImage, "Image of the containers in docker mode")
+	cmd.Flags().StringVar(&optBlobstoreImage, "blobstore-image", "", "Start the all-in-one blobstore of the image in docker mode, built by blobstore/run_docker.sh")
+	cmd.Flags().IntVar(&optMetaNodes, "metanodes", defaultPlaygroundMetaNodes, "Count of metanodes")
+	cmd.Flags().IntVar(&optDataNodes, "datanodes", defaultPlaygroundDataNodes, "Count of datanodes")
+	cmd.Flags().BoolVar(&optClean, "clean", false, "Remove the data of the previous run before starting")
+	return cmd
+}
+
+func newPlaygroundStopCmd() *cobra.Command {
+	var (
+		optDir   string
+		optPurge bool
+	)
+	cmd := &cobra.Command{
+		Use:   CliOpStop,
+		Short: cmdPlaygroundStopShort,
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				state *playgroundState
+				err   error
+			)
+			defer func() {
+				errout(err)
+			}()
+			if optDir, err = filepath.Abs(optDir); err != nil {
+				return
+			}
+			if state, err = loadPlaygroundState(optDir); err != nil {
+				return
+			}
+			if state.Mode == playgroundModeDocker {
+				err = runPlaygroundCompose(optDir, "down")
+			} else {
+				stopPlaygroundProcesses(state)
+			}
+			if err != nil {
+				return
+			}
+			if err = os.Remove(path.Join(optDir, playgroundStateFile)); err != nil {
+				return
+			}
+			if optPurge {
+				if err = os.RemoveAll(optDir); err != nil {
+					return
+				}
+				stdout("playground is stopped and %v is removed\n", optDir)
+				return
+			}
+			stdout("playground is stopped, data is kept in %v\n", optDir)
+		},
+	}
+	cmd.Flags().StringVar(&optDir, "dir", defaultPlaygroundDir, "Directory of the configs, data and logs of the nodes")
+	cmd.Flags().BoolVar(&optPurge, "purge", false, "Remove the directory of the playground after stopping")
+	return cmd
+}
+
+func newPlaygroundStatCmd() *cobra.Command {
+	var optDir string
+	cmd := &cobra.Command{
+		Use:   CliOpStatus,
+		Short: cmdPlaygroundStatShort,
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				state *playgroundState
+				err   error
+			)
+			defer func() {
+				errout(err)
+			}()
+			if optDir, err = filepath.Abs(optDir); err != nil {
+				return
+			}
+			if state, err = loadPlaygroundState(optDir); err != nil {
+				return
+			}
+			stdout("playground is running in %v mode, data and logs are in %v\n", state.Mode, optDir)
+			stdout("%v\n", formatPlaygroundNodes(state))
+			if state.Mode == playgroundModeDocker {
+				err = runPlaygroundCompose(optDir, "ps")
+			}
+		},
+	}
+	cmd.Flags().StringVar(&optDir, "dir", defaultPlaygroundDir, "Directory of the configs, data and logs of the nodes")
+	return cmd
+}
+
+func defaultPlaygroundBinDir() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return "."
+	}
+	return filepath.Dir(exe)
+}
+
+// playgroundNodes returns the nodes with their configs, node i of a role listens on 127.0.0.(i+1)
+func playgroundNodes(dir string, metaNodes, dataNodes int) []*playgroundNode {
+	masterAddrs := []string{playgroundMasterAddr}
+	nodeDir := func(name, sub string) string {
+		return path.Join(dir, name, sub)
+	}
+
+	nodes := []*playgroundNode{{
+		Name: "master",
+		Role: RoleMaster,
+		Addr: playgroundMasterAddr,
+		Config: map[string]interface{}{
+			"role":                RoleMaster,
+			"clusterName":         playgroundClusterName,
+			"id":                  "1",
+			"ip":                  "127.0.0.1",
+			"listen":              "17010",
+			"prof":                "17020",
+			"peers":               "1:" + playgroundMasterAddr,
+			"retainLogs":          "20000",
+			"logLevel":            "info",
+			"logDir":              nodeDir("master", "log"),
+			"walDir":              nodeDir("master", "data/wal"),
+			"storeDir":            nodeDir("master", "data/store"),
+			"metaNodeReservedMem": "67108864",
+		},
+	}}
+	for i := 0; i < metaNodes; i++ {
+		name := fmt.Sprintf("metanode%d", i+1)
+		ip := fmt.Sprintf("127.0.0.%d", i+1)
+		nodes = append(nodes, &playgroundNode{
+			Name: name,
+			Role: "metanode",
+			Addr: ip + ":17210",
+			Config: map[string]interface{}{
+				"role":              "metanode",
+				"localIP":           ip,
+				"bindIp":            true,
+				"listen":            "17210",
+				"prof":              fmt.Sprintf("%d", 17220+i),
+				"raftHeartbeatPort": "17230",
+				"raftReplicaPort":   "17240",
+				"logLevel":          "info",
+				"logDir":            nodeDir(name, "log"),
+				"metadataDir":       nodeDir(name, "data/meta"),
+				"raftDir":           nodeDir(name, "data/raft"),
+				"totalMem":          "536870912",
+				"retainLogs":        "100",
+				"masterAddr":        masterAddrs,
+			},
+		})
+	}
+	for i := 0; i < dataNodes; i++ {
+		name := fmt.Sprintf("datanode%d", i+1)
+		ip := fmt.Sprintf("127.0.0.%d", i+1)
+		nodes = append(nodes, &playgroundNode{
+			Name: name,
+			Role: "datanode",
+			Addr: ip + ":17310",
+			Config: map[string]interface{}{
+				"role":          "datanode",
+				"localIP":       ip,
+				"bindIp":        true,
+				"listen":        "17310",
+				"prof":          fmt.Sprintf("%d", 17320+i),
+				"raftHeartbeat": "17330",
+				"raftReplica":   "17340",
+				"logLevel":      "info",
+				"logDir":        nodeDir(name, "log"),
+				"raftDir":       nodeDir(name, "data/raft"),
+				"disks":         []string{nodeDir(name, "data/disk") + ":5368709120"},
+				"masterAddr":    masterAddrs,
+			},
+		})
+	}
+	nodes = append(nodes, &playgroundNode{
+		Name: "objectnode",
+		Role: "objectnode",
+		Addr: playgroundObjectNodeAddr,
+		Config: map[string]interface{}{
+			"role":       "objectnode",
+			"listen":     "17410",
+			"logLevel":   "info",
+			"logDir":     nodeDir("objectnode", "log"),
+			"masterAddr": masterAddrs,
+		},
+	})
+	return nodes
+}
+
+func writePlaygroundConfigs(dir string, state *playgroundState) (err error) {
+	for _, node := range state.Nodes {
+		for _, key := range []string{"logDir", "walDir", "storeDir", "metadataDir", "raftDir"} {
+			if d, ok := node.Config[key].(string); ok {
+				if err = os.MkdirAll(d, 0o755); err != nil {
+					return
+				}
+			}
+		}
+		if disks, ok := node.Config["disks"].([]string); ok {
+			for _, disk := range disks {
+				if err = os.MkdirAll(strings.Split(disk, ":")[0], 0o755); err != nil {
+					return
+				}
+			}
+		}
+		var data []byte
+		if data, err = json.MarshalIndent(node.Config, "", "  "); err != nil {
+			return
+		}
+		if err = os.WriteFile(playgroundNodeConfig(dir, node), data, 0o644); err != nil {
+			return
+		}
+	}
+	if state.Mode == playgroundModeDocker {
+		err = os.WriteFile(path.Join(dir, playgroundComposeFile), []byte(renderPlaygroundCompose(dir, state)), 0o644)
+	}
+	return
+}
+
+func playgroundNodeConfig(dir string, node *playgroundNode) string {
+	return path.Join(dir, node.Name, node.Role+".json")
+}
+
+// cleanPlaygroundData removes the data and logs of the nodes, the other files in the directory are kept
+func cleanPlaygroundData(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if err = os.RemoveAll(path.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startPlaygroundProcesses(dir string, state *playgroundState) (err error) {
+	binary := path.Join(state.BinDir, "cfs-server")
+	defer func() {
+		if err != nil {
+			stopPlaygroundProcesses(state)
+		}
+	}()
+	for _, node := range state.Nodes {
+		var stdFile *os.File
+		if stdFile, err = os.OpenFile(path.Join(dir, node.Name, "log", "std.log"),
+			os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644); err != nil {
+			return
+		}
+		c := exec.Command(binary, "-f", "-c", playgroundNodeConfig(dir, node))
+		c.Stdout, c.Stderr = stdFile, stdFile
+		// detach from the terminal so that the nodes keep running after the cli exits
+		c.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+		err = c.Start()
+		stdFile.Close()
+		if err != nil {
+			return fmt.Errorf("start %v failed: %v", node.Name, err)
+		}
+		node.Pid = c.Process.Pid
+		c.Process.Release()
+
+		if node.Role == RoleMaster {
+			if err = waitPlaygroundAddr(node.Addr, playgroundStartTimeout); err != nil {
+				return fmt.Errorf("master is not ready, see logs in %v: %v", path.Join(dir, node.Name, "log"), err)
+			}
+		}
+	}
+	return
+}
+
+func stopPlaygroundProcesses(state *playgroundState) {
+	for i := len(state.Nodes) - 1; i >= 0; i-- {
+		node := state.Nodes[i]
+		if node.Pid <= 0 || !isProcessAlive(node.Pid) {
+			continue
+		}
+		if err := syscall.Kill(node.Pid, syscall.SIGTERM); err != nil {
+			stdout("stop %v(pid %v) failed: %v\n", node.Name, node.Pid, err)
+			continue
+		}
+		deadline := time.Now().Add(playgroundStopTimeout)
+		for isProcessAlive(node.Pid) && time.Now().Before(deadline) {
+			time.Sleep(100 * time.Millisecond)
+		}
+		if isProcessAlive(node.Pid) {
+			syscall.Kill(node.Pid, syscall.SIGKILL)
+		}
+	}
+}
+
+func isProcessAlive(pid int) bool {
+	return syscall.Kill(pid, 0) == nil
+}
+
+func waitPlaygroundAddr(addr string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// renderPlaygroundCompose returns the docker-compose file, the containers share the host network
+// and the directory of the playground is mounted at the same path so the configs are the same.
+func renderPlaygroundCompose(dir string, state *playgroundState) string {
+	var sb strings.Builder
+	sb.WriteString("version: '2.1'\n\nservices:\n")
+	for _, node := range state.Nodes {
+		fmt.Fprintf(&sb, "    %v:\n", node.Name)
+		fmt.Fprintf(&sb, "        image: %v\n", state.Image)
+		sb.WriteString("        network_mode: host\n")
+		sb.WriteString("        privileged: true\n")
+		sb.WriteString("        restart: on-failure\n")
+		sb.WriteString("        volumes:\n")
+		fmt.Fprintf(&sb, "            - %v:/cfs/bin:ro\n", state.BinDir)
+		fmt.Fprintf(&sb, "            - %v:%v\n", dir, dir)
+		fmt.Fprintf(&sb, "        command: /cfs/bin/cfs-server -f -c %v\n", playgroundNodeConfig(dir, node))
+		if node.Role != RoleMaster {
+			sb.WriteString("        depends_on:\n            - master\n")
+		}
+		sb.WriteString("\n")
+	}
+	if state.BlobstoreImage != "" {
+		sb.WriteString("    blobstore:\n")
+		fmt.Fprintf(&sb, "        image: %v\n", state.BlobstoreImage)
+		sb.WriteString("        network_mode: host\n")
+		sb.WriteString("        restart: on-failure\n")
+	}
+	return sb.String()
+}
+
+func startPlaygroundContainers(dir string, state *playgroundState) (err error) {
+	if err = runPlaygroundCompose(dir, "up", "-d"); err != nil {
+		return
+	}
+	if err = waitPlaygroundAddr(playgroundMasterAddr, playgroundStartTimeout); err != nil {
+		runPlaygroundCompose(dir, "down")
+		return fmt.Errorf("master is not ready, see logs in %v: %v", path.Join(dir, "master", "log"), err)
+	}
+	return
+}
+
+// runPlaygroundCompose runs docker-compose, or the compose plugin of docker if it's not installed
+func runPlaygroundCompose(dir string, args ...string) error {
+	args = append([]string{"-f", path.Join(dir, playgroundComposeFile), "-p", playgroundComposeProject}, args...)
+	name := "docker-compose"
+	if _, err := exec.LookPath(name); err != nil {
+		name = "docker"
+		args = append([]string{"compose"}, args...)
+	}
+	c := exec.Command(name, args...)
+	c.Stdout, c.Stderr = os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("run %v %v failed: %v", name, strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+func savePlaygroundState(dir string, state *playgroundState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path.Join(dir, playgroundStateFile), data, 0o644)
+}
+
+func loadPlaygroundState(dir string) (*playgroundState, error) {
+	data, err := os.ReadFile(path.Join(dir, playgroundStateFile))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no playground is running in %v", dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := &playgroundState{}
+	if err = json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("load %v failed: %v", playgroundStateFile, err)
+	}
+	return state, nil
+}
+
+func formatPlaygroundNodes(state *playgroundState) string {
+	rows := table{arow("NAME", "ROLE", "ADDRESS", "PID", "ALIVE")}
+	for _, node := range state.Nodes {
+		pid, alive := "-", "-"
+		if state.Mode == playgroundModeProcess {
+			pid = fmt.Sprintf("%d", node.Pid)
+			alive = fmt.Sprintf("%v", isProcessAlive(node.Pid))
+		}
+		rows = append(rows, arow(node.Name, node.Role, node.Addr, pid, alive))
+	}
+	return alignTable(rows...)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
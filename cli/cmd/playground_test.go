@@ -0,0 +1,89 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaygroundNodes(t *testing.T) {
+	dir := t.TempDir()
+	nodes := playgroundNodes(dir, 3, 2)
+	require.Len(t, nodes, 1+3+2+1)
+
+	addrs := make(map[string]bool)
+	profs := make(map[string]bool)
+	for _, node := range nodes {
+		require.False(t, addrs[node.Addr], node.Addr)
+		addrs[node.Addr] = true
+		prof, ok := node.Config["prof"].(string)
+		if ok {
+			require.False(t, profs[prof], prof)
+			profs[prof] = true
+		}
+		require.Equal(t, node.Role, node.Config["role"])
+		if node.Role != RoleMaster {
+			require.Equal(t, []string{playgroundMasterAddr}, node.Config["masterAddr"])
+		}
+	}
+	require.Equal(t, "127.0.0.3", nodes[3].Config["localIP"])
+
+	state := &playgroundState{Mode: playgroundModeDocker, BinDir: "/cfs/build/bin", Image: "image", Nodes: nodes}
+	require.NoError(t, writePlaygroundConfigs(dir, state))
+	data, err := os.ReadFile(playgroundNodeConfig(dir, nodes[4]))
+	require.NoError(t, err)
+	config := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(data, &config))
+	require.Equal(t, "datanode", config["role"])
+	_, err = os.Stat(path.Join(dir, "datanode1", "data", "disk"))
+	require.NoError(t, err)
+
+	compose, err := os.ReadFile(path.Join(dir, playgroundComposeFile))
+	require.NoError(t, err)
+	require.Equal(t, len(nodes), strings.Count(string(compose), "network_mode: host"))
+	require.Contains(t, string(compose), "/cfs/bin/cfs-server -f -c "+playgroundNodeConfig(dir, nodes[0]))
+	require.NotContains(t, string(compose), "blobstore:")
+
+	state.BlobstoreImage = "blobstore:latest"
+	require.Contains(t, renderPlaygroundCompose(dir, state), "image: blobstore:latest")
+}
+
+func TestPlaygroundState(t *testing.T) {
+	dir := t.TempDir()
+	_, err := loadPlaygroundState(dir)
+	require.Error(t, err)
+
+	state := &playgroundState{Mode: playgroundModeProcess, Nodes: playgroundNodes(dir, 1, 1)}
+	state.Nodes[0].Pid = os.Getpid()
+	require.NoError(t, savePlaygroundState(dir, state))
+	loaded, err := loadPlaygroundState(dir)
+	require.NoError(t, err)
+	require.Equal(t, playgroundModeProcess, loaded.Mode)
+	require.Len(t, loaded.Nodes, 4)
+	require.True(t, isProcessAlive(loaded.Nodes[0].Pid))
+
+	require.NoError(t, os.MkdirAll(path.Join(dir, "master", "log"), 0o755))
+	require.NoError(t, cleanPlaygroundData(dir))
+	_, err = os.Stat(path.Join(dir, "master"))
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Stat(path.Join(dir, playgroundStateFile))
+	require.NoError(t, err)
+}
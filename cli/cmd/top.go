@@ -0,0 +1,91 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/master"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdTopUse   = "top"
+	cmdTopShort = "Show a refreshing view of the hottest volumes, partitions and clients"
+
+	clearScreen = "\033[H\033[2J"
+)
+
+func newTopCmd(client *master.MasterClient) *cobra.Command {
+	var (
+		optSortBy     string
+		optLimit      int
+		optInterval   time.Duration
+		optIterations int
+	)
+	cmd := &cobra.Command{
+		Use:   cmdTopUse,
+		Short: cmdTopShort,
+		Long: `Rank volumes, data partitions and clients by qps, bandwidth or latency.
+Partition statistics are reported by datanode heartbeats, client statistics
+are only available for volumes with qos enabled.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			if optInterval < time.Second {
+				err = fmt.Errorf("refresh interval must be at least 1s")
+				return
+			}
+			for i := 0; optIterations <= 0 || i < optIterations; i++ {
+				if i > 0 {
+					time.Sleep(optInterval)
+				}
+				var view *proto.TopView
+				if view, err = client.AdminAPI().GetTopView(optSortBy, optLimit); err != nil {
+					return
+				}
+				if optIterations != 1 {
+					stdout(clearScreen)
+				}
+				stdout("%v", formatTopView(view))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&optSortBy, "sort-by", proto.TopSortByQps,
+		fmt.Sprintf("Sort by [%v|%v|%v]", proto.TopSortByQps, proto.TopSortByBandwidth, proto.TopSortByLatency))
+	cmd.Flags().IntVarP(&optLimit, "limit", "l", 10, "Number of entries shown for each resource")
+	cmd.Flags().DurationVarP(&optInterval, "interval", "i", 5*time.Second, "Refresh interval")
+	cmd.Flags().IntVarP(&optIterations, "iterations", "n", 0, "Number of refreshes before exit, 0 means forever")
+	return cmd
+}
+
+func formatTopView(view *proto.TopView) string {
+	sb := fmt.Sprintf("Report time: %v    Sort by: %v\n", formatTime(view.ReportTime), view.SortBy)
+	section := func(title, kind string, items []*proto.TopItem) {
+		sb += fmt.Sprintf("\n%v:\n%v\n", title, formatTopItemTableHeader(kind))
+		for i, item := range items {
+			sb += formatTopItemTableRow(i+1, item) + "\n"
+		}
+	}
+	section("Volumes", "NAME", view.Volumes)
+	section("Data partitions", "PARTITION", view.Partitions)
+	section("Clients", "CLIENT", view.Clients)
+	return sb
+}
@@ -16,6 +16,7 @@ package cmd
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/cubefs/cubefs/proto"
@@ -41,6 +42,8 @@ func newUserCmd(client *master.MasterClient) *cobra.Command {
 		newUserPermCmd(client),
 		newUserUpdateCmd(client),
 		newUserDeleteCmd(client),
+		newUserQuotaCmd(client),
+		newUserUsageCmd(client),
 	)
 	return cmd
 }
@@ -381,6 +384,76 @@ func newUserPermCmd(client *master.MasterClient) *cobra.Command {
 	return cmd
 }
 
+const (
+	cmdUserQuotaUse   = "quota [USER ID] [CAPACITY QUOTA (GB)]"
+	cmdUserQuotaShort = "Set capacity quota shared by the volumes and buckets of user, 0 means unlimited"
+)
+
+func newUserQuotaCmd(client *master.MasterClient) *cobra.Command {
+	var clientIDKey string
+	cmd := &cobra.Command{
+		Use:   cmdUserQuotaUse,
+		Short: cmdUserQuotaShort,
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			var userInfo *proto.UserInfo
+			defer func() {
+				errout(err)
+			}()
+			param := &proto.UserQuotaParam{UserID: args[0]}
+			if param.CapacityQuota, err = strconv.ParseUint(args[1], 10, 64); err != nil {
+				err = fmt.Errorf("Invalid capacity quota: %v\n", args[1])
+				return
+			}
+			if userInfo, err = client.UserAPI().SetQuota(param, clientIDKey); err != nil {
+				return
+			}
+			printUserInfo(userInfo)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validUsers(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	cmd.Flags().StringVar(&clientIDKey, CliFlagClientIDKey, client.ClientIDKey(), CliUsageClientIDKey)
+	return cmd
+}
+
+const (
+	cmdUserUsageUse   = "usage [USER ID]"
+	cmdUserUsageShort = "Show total consumption of user across the volumes and buckets"
+)
+
+func newUserUsageCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   cmdUserUsageUse,
+		Short: cmdUserUsageShort,
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			var usage *proto.UserUsage
+			defer func() {
+				errout(err)
+			}()
+			if usage, err = client.UserAPI().GetUsage(args[0]); err != nil {
+				err = fmt.Errorf("Get user usage failed: %v\n", err)
+				return
+			}
+			printUserUsage(usage)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validUsers(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	return cmd
+}
+
 const (
 	cmdUserListShort = "List cluster users"
 )
@@ -417,6 +490,7 @@ func printUserInfo(userInfo *proto.UserInfo) {
 	stdout("  Secret Key : %v\n", userInfo.SecretKey)
 	stdout("  Type       : %v\n", userInfo.UserType)
 	stdout("  Create Time: %v\n", userInfo.CreateTime)
+	stdout("  Quota      : %v\n", formatCapacityQuota(userInfo.CapacityQuota))
 	if userInfo.Policy == nil {
 		return
 	}
@@ -429,3 +503,23 @@ func printUserInfo(userInfo *proto.UserInfo) {
 		stdout("%-20v    %-12v\n", vol, strings.Join(perms, ","))
 	}
 }
+
+func formatCapacityQuota(quota uint64) string {
+	if quota == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%v GB", quota)
+}
+
+func printUserUsage(usage *proto.UserUsage) {
+	stdout("[Summary]\n")
+	stdout("  User ID    : %v\n", usage.UserID)
+	stdout("  Quota      : %v\n", formatCapacityQuota(usage.CapacityQuota))
+	stdout("  Used       : %v\n", formatSize(usage.UsedSize))
+	stdout("  Over Quota : %v\n", usage.OverQuota)
+	stdout("[Volumes]\n")
+	stdout("%-20v    %-12v    %-12v\n", "VOLUME", "CAPACITY", "USED")
+	for _, vol := range usage.Vols {
+		stdout("%-20v    %-12v    %-12v\n", vol.Name, fmt.Sprintf("%v GB", vol.Capacity), formatSize(vol.UsedSize))
+	}
+}
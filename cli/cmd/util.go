@@ -110,3 +110,14 @@ func alignColumnIndent(indent string, rows ...[]interface{}) string {
 func alignColumnIndex(index int, rows ...[]interface{}) string {
 	return alignColumnIndent(util.Any2String(index), rows...)
 }
+
+// parseLabels splits the labels separated by comma, the empty labels are removed
+func parseLabels(raw string) []string {
+	labels := make([]string, 0)
+	for _, label := range strings.Split(raw, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
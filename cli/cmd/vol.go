@@ -23,6 +23,7 @@ import (
 
 	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/sdk/master"
+	"github.com/cubefs/cubefs/sdk/meta"
 	"github.com/cubefs/cubefs/util"
 	"github.com/cubefs/cubefs/util/strutil"
 	"github.com/spf13/cobra"
@@ -48,13 +49,21 @@ func newVolCmd(client *master.MasterClient) *cobra.Command {
 		newVolUpdateCmd(client),
 		newVolInfoCmd(client),
 		newVolDeleteCmd(client),
+		newVolRestoreCmd(client),
 		newVolTransferCmd(client),
+		newVolRenameCmd(client),
+		newVolCloneCmd(client),
 		newVolAddDPCmd(client),
 		newVolAddMPCmd(client),
 		newVolSetForbiddenCmd(client),
 		newVolSetAuditLogCmd(client),
 		newVolSetTrashIntervalCmd(client),
+		newVolRestoreTrashCmd(client),
 		newVolSetDpRepairBlockSize(client),
+		newVolSnapshotCmd(client),
+		newVolAuditCmd(client),
+		newVolExportCmd(client),
+		newVolImportCmd(client),
 	)
 	return cmd
 }
@@ -139,6 +148,10 @@ func newVolCreateCmd(client *master.MasterClient) *cobra.Command {
 	var optTxConflictRetryNum int64
 	var optTxConflictRetryInterval int64
 	var optDeleteLockTime int64
+	var optCaseInsensitive bool
+	var optAffinityLabels string
+	var optAntiAffinityLabels string
+	var optFsyncPolicy string
 	var clientIDKey string
 	var optYes bool
 	cmd := &cobra.Command{
@@ -171,6 +184,8 @@ func newVolCreateCmd(client *master.MasterClient) *cobra.Command {
 			if optDeleteLockTime < 0 {
 				optDeleteLockTime = 0
 			}
+			affinityLabels := parseLabels(optAffinityLabels)
+			antiAffinityLabels := parseLabels(optAntiAffinityLabels)
 
 			// ask user for confirm
 			if !optYes {
@@ -189,7 +204,11 @@ func newVolCreateCmd(client *master.MasterClient) *cobra.Command {
 				stdout("  volType                  : %v\n", optVolType)
 				stdout("  followerRead             : %v\n", followerRead)
 				stdout("  readOnlyWhenFull         : %v\n", dpReadOnlyWhenVolFull)
+				stdout("  caseInsensitive          : %v\n", optCaseInsensitive)
 				stdout("  zoneName                 : %v\n", optZoneName)
+				stdout("  affinityLabels           : %v\n", strings.Join(affinityLabels, ","))
+				stdout("  antiAffinityLabels       : %v\n", strings.Join(antiAffinityLabels, ","))
+				stdout("  fsyncPolicy              : %v\n", optFsyncPolicy)
 				stdout("  cacheRuleKey             : %v\n", optCacheRuleKey)
 				stdout("  ebsBlkSize               : %v byte\n", optEbsBlkSize)
 				stdout("  cacheCapacity            : %v G\n", optCacheCap)
@@ -218,7 +237,8 @@ func newVolCreateCmd(client *master.MasterClient) *cobra.Command {
 				optZoneName, optCacheRuleKey, optEbsBlkSize, optCacheCap,
 				optCacheAction, optCacheThreshold, optCacheTTL, optCacheHighWater,
 				optCacheLowWater, optCacheLRUInterval, dpReadOnlyWhenVolFull,
-				optTxMask, optTxTimeout, optTxConflictRetryNum, optTxConflictRetryInterval, optEnableQuota, clientIDKey)
+				optTxMask, optTxTimeout, optTxConflictRetryNum, optTxConflictRetryInterval, optEnableQuota, clientIDKey,
+				optCaseInsensitive, affinityLabels, antiAffinityLabels, optFsyncPolicy)
 			if err != nil {
 				err = fmt.Errorf("Create volume failed case:\n%v\n", err)
 				return
@@ -256,6 +276,10 @@ func newVolCreateCmd(client *master.MasterClient) *cobra.Command {
 	cmd.Flags().Int64Var(&optTxConflictRetryInterval, CliTxConflictRetryInterval, 0, "Specify retry interval[Unit: ms] for transaction conflict [10-1000]")
 	cmd.Flags().StringVar(&optEnableQuota, CliFlagEnableQuota, "false", "Enable quota (default false)")
 	cmd.Flags().Int64Var(&optDeleteLockTime, CliFlagDeleteLockTime, 0, "Specify delete lock time[Unit: hour] for volume")
+	cmd.Flags().BoolVar(&optCaseInsensitive, CliFlagCaseInsensitive, false, "Lookup dentry ignoring case but preserve the case of name, can't be changed after creation")
+	cmd.Flags().StringVar(&optAffinityLabels, CliFlagAffinityLabels, "", "Allocate partitions only on the nodes with all of the labels, separated by comma")
+	cmd.Flags().StringVar(&optAntiAffinityLabels, CliFlagAntiAffinityLabels, "", "Never allocate partitions on the nodes with any of the labels, separated by comma")
+	cmd.Flags().StringVar(&optFsyncPolicy, CliFlagFsyncPolicy, proto.DefaultFsyncPolicy, "When the extents are fsynced on the data nodes: [always|interval|never]")
 
 	return cmd
 }
@@ -293,6 +317,9 @@ func newVolUpdateCmd(client *master.MasterClient) *cobra.Command {
 	var optDeleteLockTime int64
 	var optEnableQuota string
 	var optEnableDpAutoMetaRepair string
+	var optAffinityLabels string
+	var optAntiAffinityLabels string
+	var optFsyncPolicy string
 	confirmString := strings.Builder{}
 	var vv *proto.SimpleVolView
 	cmd := &cobra.Command{
@@ -601,6 +628,26 @@ func newVolUpdateCmd(client *master.MasterClient) *cobra.Command {
 			} else {
 				confirmString.WriteString(fmt.Sprintf("  EnableAutoDpMetaRepair : %v", vv.EnableAutoDpMetaRepair))
 			}
+			// an empty value clears the labels, so check whether the flag is set
+			if cmd.Flags().Changed(CliFlagAffinityLabels) {
+				isChange = true
+				labels := parseLabels(optAffinityLabels)
+				confirmString.WriteString(fmt.Sprintf("\n  AffinityLabels      : %v -> %v",
+					strings.Join(vv.AffinityLabels, ","), strings.Join(labels, ",")))
+				vv.AffinityLabels = labels
+			}
+			if cmd.Flags().Changed(CliFlagAntiAffinityLabels) {
+				isChange = true
+				labels := parseLabels(optAntiAffinityLabels)
+				confirmString.WriteString(fmt.Sprintf("\n  AntiAffinityLabels  : %v -> %v",
+					strings.Join(vv.AntiAffinityLabels, ","), strings.Join(labels, ",")))
+				vv.AntiAffinityLabels = labels
+			}
+			if optFsyncPolicy != "" {
+				isChange = true
+				confirmString.WriteString(fmt.Sprintf("\n  FsyncPolicy         : %v -> %v", vv.FsyncPolicy, optFsyncPolicy))
+				vv.FsyncPolicy = optFsyncPolicy
+			}
 
 			if err != nil {
 				return
@@ -661,6 +708,9 @@ func newVolUpdateCmd(client *master.MasterClient) *cobra.Command {
 	cmd.Flags().Int64Var(&optDeleteLockTime, CliFlagDeleteLockTime, -1, "Specify delete lock time[Unit: hour] for volume")
 	cmd.Flags().StringVar(&clientIDKey, CliFlagClientIDKey, client.ClientIDKey(), CliUsageClientIDKey)
 	cmd.Flags().StringVar(&optEnableDpAutoMetaRepair, CliFlagAutoDpMetaRepair, "", "Enable or disable dp auto meta repair")
+	cmd.Flags().StringVar(&optAffinityLabels, CliFlagAffinityLabels, "", "Allocate partitions only on the nodes with all of the labels, separated by comma, empty to clear")
+	cmd.Flags().StringVar(&optAntiAffinityLabels, CliFlagAntiAffinityLabels, "", "Never allocate partitions on the nodes with any of the labels, separated by comma, empty to clear")
+	cmd.Flags().StringVar(&optFsyncPolicy, CliFlagFsyncPolicy, "", "When the extents are fsynced on the data nodes: [always|interval|never]")
 
 	return cmd
 }
@@ -819,6 +869,64 @@ func newVolDeleteCmd(client *master.MasterClient) *cobra.Command {
 	return cmd
 }
 
+const (
+	cmdVolRestoreUse   = "restore [VOLUME NAME]"
+	cmdVolRestoreShort = "Restore a deleted volume in the retention window"
+)
+
+func newVolRestoreCmd(client *master.MasterClient) *cobra.Command {
+	var optYes bool
+	cmd := &cobra.Command{
+		Use:   cmdVolRestoreUse,
+		Short: cmdVolRestoreShort,
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			volumeName := args[0]
+			defer func() {
+				errout(err)
+			}()
+
+			var svv *proto.SimpleVolView
+			if svv, err = client.AdminAPI().GetVolumeSimpleInfo(volumeName); err != nil {
+				err = fmt.Errorf("Restore volume failed:\n%v\n", err)
+				return
+			}
+			if svv.Status != proto.VolStatusMarkDelete || !svv.Forbidden {
+				err = fmt.Errorf("Volume [%v] is not deleted with delay deletion\n", volumeName)
+				return
+			}
+			window := time.Until(svv.DeleteExecTime)
+			if window <= 0 {
+				err = fmt.Errorf("Retention window of volume [%v] expired at %v\n", volumeName, svv.DeleteExecTime.Format(time.RFC3339))
+				return
+			}
+			if !optYes {
+				stdout("Restore volume [%v], which will be deleted in %v (yes/no)[no]:", volumeName, window.Truncate(time.Second))
+				var userConfirm string
+				_, _ = fmt.Scanln(&userConfirm)
+				if userConfirm != "yes" {
+					err = fmt.Errorf("Abort by user.\n")
+					return
+				}
+			}
+			if err = client.AdminAPI().UnDeleteVolume(volumeName, util.CalcAuthKey(svv.Owner), false); err != nil {
+				err = fmt.Errorf("Restore volume failed:\n%v\n", err)
+				return
+			}
+			stdout("Volume has been restored successfully.\n")
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validVols(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	cmd.Flags().BoolVarP(&optYes, "yes", "y", false, "Answer yes for all questions")
+	return cmd
+}
+
 const (
 	cmdVolTransferUse   = "transfer [VOLUME NAME] [USER ID]"
 	cmdVolTransferShort = "Transfer volume to another user. (Change owner of volume)"
@@ -884,6 +992,58 @@ func newVolTransferCmd(client *master.MasterClient) *cobra.Command {
 	return cmd
 }
 
+const (
+	cmdVolRenameUse   = "rename [VOLUME NAME] [NEW NAME]"
+	cmdVolRenameShort = "Rename a volume, the previous name can still be used to access it"
+)
+
+func newVolRenameCmd(client *master.MasterClient) *cobra.Command {
+	var optYes bool
+	cmd := &cobra.Command{
+		Use:   cmdVolRenameUse,
+		Short: cmdVolRenameShort,
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			volume := args[0]
+			newName := args[1]
+			defer func() {
+				errout(err)
+			}()
+
+			// ask user for confirm
+			if !optYes {
+				stdout("Rename volume [%v] to [%v] (yes/no)[no]:", volume, newName)
+				var confirm string
+				_, _ = fmt.Scanln(&confirm)
+				if confirm != "yes" {
+					err = fmt.Errorf("Abort by user.\n")
+					return
+				}
+			}
+
+			var svv *proto.SimpleVolView
+			if svv, err = client.AdminAPI().GetVolumeSimpleInfo(volume); err != nil {
+				err = fmt.Errorf("Rename volume failed:\n%v\n", err)
+				return
+			}
+			if err = client.AdminAPI().RenameVolume(volume, newName, util.CalcAuthKey(svv.Owner)); err != nil {
+				err = fmt.Errorf("Rename volume failed:\n%v\n", err)
+				return
+			}
+			stdout("Volume has been renamed successfully.\n")
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validVols(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	cmd.Flags().BoolVarP(&optYes, "yes", "y", false, "Answer yes for all questions")
+	return cmd
+}
+
 const (
 	cmdVolAddDPCmdUse   = "add-dp [VOLUME] [NUMBER]"
 	cmdVolAddDPCmdShort = "Create and add more data partition to a volume"
@@ -1154,3 +1314,87 @@ func newVolSetTrashIntervalCmd(client *master.MasterClient) *cobra.Command {
 	}
 	return cmd
 }
+
+var (
+	cmdVolRestoreTrashUse   = "restore-trash [VOLUME] [TRASH PATH]"
+	cmdVolRestoreTrashShort = "Restore the deleted file or directory in trash to its original path"
+)
+
+func newVolRestoreTrashCmd(client *master.MasterClient) *cobra.Command {
+	var optSubDir string
+	cmd := &cobra.Command{
+		Use:   cmdVolRestoreTrashUse,
+		Short: cmdVolRestoreTrashShort,
+		Long: `Restore the deleted file or directory in trash to its original path.
+The trash path is relative to the .Trash folder of the mounted subdir, such as Current/a/b/file.
+The missing parent directories are created, and it fails if the original path is taken.`,
+		Args: cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			name := args[0]
+			defer func() {
+				if err != nil {
+					errout(err)
+				}
+			}()
+
+			proto.InitBufferPool(32768)
+			var mw *meta.MetaWrapper
+			if mw, err = meta.NewMetaWrapper(&meta.MetaConfig{
+				Volume:  name,
+				Masters: client.Nodes(),
+			}); err != nil {
+				err = fmt.Errorf("NewMetaWrapper failed: %v", err)
+				return
+			}
+			defer mw.Close()
+
+			var originalPath string
+			if originalPath, err = meta.NewTrashRestorer(mw, optSubDir).Restore(args[1]); err != nil {
+				err = fmt.Errorf("Restore %v failed: %v", args[1], err)
+				return
+			}
+			stdout("Restore %v to %v of volume %v successfully\n", args[1], originalPath, name)
+		},
+	}
+	cmd.Flags().StringVar(&optSubDir, "subdir", "/", "Subdir mounted by the clients with the trash")
+	return cmd
+}
+
+var (
+	cmdVolAuditUse   = "audit [VOLUME]"
+	cmdVolAuditShort = "Show audit records of the admin operations on the volume"
+)
+
+func newVolAuditCmd(client *master.MasterClient) *cobra.Command {
+	var (
+		optStartTime int64
+		optEndTime   int64
+		optLimit     int
+	)
+	cmd := &cobra.Command{
+		Use:   cmdVolAuditUse,
+		Short: cmdVolAuditShort,
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				records []*proto.VolAuditRecord
+				err     error
+			)
+			defer func() {
+				errout(err)
+			}()
+			if records, err = client.AdminAPI().GetVolAuditTrail(args[0], optStartTime, optEndTime, optLimit); err != nil {
+				return
+			}
+			stdout("%v\n", volumeAuditTableHeader)
+			for _, record := range records {
+				stdout("%v\n", formatVolAuditTableRow(record))
+			}
+		},
+	}
+	cmd.Flags().Int64Var(&optStartTime, "start-time", 0, "Specify start time of records in unix seconds")
+	cmd.Flags().Int64Var(&optEndTime, "end-time", 0, "Specify end time of records in unix seconds, 0 means now")
+	cmd.Flags().IntVar(&optLimit, "limit", 100, "Specify max number of newest records to show")
+	return cmd
+}
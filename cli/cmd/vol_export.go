@@ -0,0 +1,428 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"time"
+
+	"github.com/cubefs/cubefs/cli/api"
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/master"
+	"github.com/cubefs/cubefs/sdk/meta"
+	"github.com/cubefs/cubefs/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdVolExportUse   = "export [VOLUME] [FILE]"
+	cmdVolExportShort = "Export the metadata of the volume to a file"
+	cmdVolImportUse   = "import [VOLUME] [FILE]"
+	cmdVolImportShort = "Import the metadata exported from a volume into an empty volume"
+	cmdVolCloneUse    = "clone [VOLUME] [NEW VOLUME]"
+	cmdVolCloneShort  = "Clone a volume by copying the metadata of a snapshot, the data is shared copy-on-write"
+
+	cmdVolExportDefaultProfPort = "17220"
+	cmdVolExportProgressPeriod  = 5 * time.Second
+)
+
+func newVolExportCmd(client *master.MasterClient) *cobra.Command {
+	var optProfPort string
+	cmd := &cobra.Command{
+		Use:   cmdVolExportUse,
+		Short: cmdVolExportShort,
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			volName, fileName := args[0], args[1]
+			var views []*proto.MetaPartitionView
+			if views, err = client.ClientAPI().GetMetaPartitions(volName); err != nil {
+				err = fmt.Errorf("Get meta partitions failed:\n%v\n", err)
+				return
+			}
+
+			var file *os.File
+			if file, err = os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644); err != nil {
+				return
+			}
+			defer func() {
+				if closeErr := file.Close(); err == nil {
+					err = closeErr
+				}
+				if err != nil {
+					os.Remove(fileName)
+				}
+			}()
+			w := bufio.NewWriter(file)
+
+			start := time.Now()
+			var total *proto.MetaExportTrailer
+			if total, err = exportVolMeta(views, 0, optProfPort, w); err != nil {
+				return
+			}
+			if err = w.Flush(); err != nil {
+				return
+			}
+			stdout("Volume %v has been exported: partitions: %v, inodes: %v, dentries: %v, xattrs: %v, cost: %v\n",
+				volName, len(views), total.Inodes, total.Dentries, total.XAttrs, time.Since(start).Truncate(time.Second))
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validVols(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	cmd.Flags().StringVar(&optProfPort, "prof-port", cmdVolExportDefaultProfPort, "Prof port of meta nodes")
+	return cmd
+}
+
+// exportVolMeta exports the meta partitions of the volume at the snapshot version verSeq one by one.
+func exportVolMeta(views []*proto.MetaPartitionView, verSeq uint64, profPort string, w io.Writer) (total *proto.MetaExportTrailer, err error) {
+	total = new(proto.MetaExportTrailer)
+	for _, view := range views {
+		var count *proto.MetaExportTrailer
+		if count, err = exportMetaPartition(view, verSeq, profPort, w); err != nil {
+			err = fmt.Errorf("Export meta partition %v failed:\n%v\n", view.PartitionID, err)
+			return
+		}
+		total.Inodes += count.Inodes
+		total.Dentries += count.Dentries
+		total.XAttrs += count.XAttrs
+		stdout("Exported meta partition %v: inodes: %v, dentries: %v, xattrs: %v\n",
+			view.PartitionID, count.Inodes, count.Dentries, count.XAttrs)
+	}
+	return
+}
+
+// exportMetaPartition exports the partition from its leader, and reads the export while writing
+// it to check that the export is complete.
+func exportMetaPartition(view *proto.MetaPartitionView, verSeq uint64, profPort string, w io.Writer) (count *proto.MetaExportTrailer, err error) {
+	if view.LeaderAddr == "" {
+		return nil, fmt.Errorf("no leader")
+	}
+	host, _, err := net.SplitHostPort(view.LeaderAddr)
+	if err != nil {
+		return
+	}
+	pr, pw := io.Pipe()
+	checked := make(chan error, 1)
+	go func() {
+		reader := proto.NewMetaExportReader(pr)
+		for {
+			record, err := reader.Next()
+			if err != nil {
+				if err == io.EOF && count == nil {
+					err = fmt.Errorf("empty export")
+				} else if err == io.EOF {
+					err = nil
+				}
+				pr.CloseWithError(err)
+				checked <- err
+				return
+			}
+			if record.Type == proto.MetaExportRecordTrailer {
+				count = record.Trailer
+			}
+		}
+	}()
+	err = api.NewMetaHttpClient(net.JoinHostPort(host, profPort), false).ExportPartition(view.PartitionID, verSeq, io.MultiWriter(w, pw))
+	pw.CloseWithError(err)
+	if checkErr := <-checked; err == nil {
+		err = checkErr
+	}
+	return
+}
+
+func newVolCloneCmd(client *master.MasterClient) *cobra.Command {
+	var (
+		optOwner    string
+		optProfPort string
+		optTempDir  string
+	)
+	cmd := &cobra.Command{
+		Use:   cmdVolCloneUse,
+		Short: cmdVolCloneShort,
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			volName, newName := args[0], args[1]
+			var svv *proto.SimpleVolView
+			if svv, err = client.AdminAPI().GetVolumeSimpleInfo(volName); err != nil {
+				err = fmt.Errorf("Clone volume failed:\n%v\n", err)
+				return
+			}
+			var views []*proto.MetaPartitionView
+			if views, err = client.ClientAPI().GetMetaPartitions(volName); err != nil {
+				err = fmt.Errorf("Get meta partitions failed:\n%v\n", err)
+				return
+			}
+			var clone *proto.SimpleVolView
+			if clone, err = client.AdminAPI().CloneVolume(volName, newName, optOwner, util.CalcAuthKey(svv.Owner)); err != nil {
+				err = fmt.Errorf("Clone volume failed:\n%v\n", err)
+				return
+			}
+			stdout("Volume %v has been created with the snapshot version %v of volume %v\n", newName, clone.CloneVerSeq, volName)
+			defer func() {
+				if err != nil {
+					err = fmt.Errorf("%v\nDelete volume %v and clone again\n", err, newName)
+				}
+			}()
+
+			var file *os.File
+			if file, err = os.CreateTemp(optTempDir, newName+"-*.export"); err != nil {
+				return
+			}
+			defer func() {
+				file.Close()
+				os.Remove(file.Name())
+			}()
+			w := bufio.NewWriter(file)
+			start := time.Now()
+			// the oldest version 0 is read by the max seq, the same as the snapshot read of client
+			verSeq := clone.CloneVerSeq
+			if verSeq == 0 {
+				verSeq = math.MaxUint64
+			}
+			if _, err = exportVolMeta(views, verSeq, optProfPort, w); err != nil {
+				return
+			}
+			if err = w.Flush(); err != nil {
+				return
+			}
+
+			var im *metaImporter
+			if im, err = importVolMeta(client, newName, file.Name()); err != nil {
+				return
+			}
+			stdout("Volume %v has been cloned to %v: inodes: %v, dentries: %v, xattrs: %v, cost: %v\n",
+				volName, newName, im.count.Inodes, im.count.Dentries, im.count.XAttrs, time.Since(start).Truncate(time.Second))
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validVols(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	cmd.Flags().StringVar(&optOwner, CliFlagOnwer, "", "Owner of the new volume, the same as the source if empty")
+	cmd.Flags().StringVar(&optProfPort, "prof-port", cmdVolExportDefaultProfPort, "Prof port of meta nodes")
+	cmd.Flags().StringVar(&optTempDir, "temp-dir", "", "Directory of the temporary export file, the default temporary directory if empty")
+	return cmd
+}
+
+func newVolImportCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   cmdVolImportUse,
+		Short: cmdVolImportShort,
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			volName, fileName := args[0], args[1]
+			var im *metaImporter
+			if im, err = importVolMeta(client, volName, fileName); err != nil {
+				return
+			}
+			stdout("Metadata has been imported into volume %v: inodes: %v, dentries: %v, xattrs: %v, cost: %v\n",
+				volName, im.count.Inodes, im.count.Dentries, im.count.XAttrs, time.Since(im.start).Truncate(time.Second))
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validVols(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	return cmd
+}
+
+// importVolMeta imports the metadata exported into the empty volume.
+func importVolMeta(client *master.MasterClient, volName, fileName string) (im *metaImporter, err error) {
+	var mw *meta.MetaWrapper
+	if mw, err = meta.NewMetaWrapper(&meta.MetaConfig{
+		Volume:  volName,
+		Masters: client.Nodes(),
+	}); err != nil {
+		err = fmt.Errorf("NewMetaWrapper failed: %v", err)
+		return
+	}
+	defer mw.Close()
+
+	var children []proto.Dentry
+	if children, err = mw.ReadDirLimit_ll(proto.RootIno, "", 1); err != nil {
+		return
+	}
+	if len(children) > 0 {
+		err = fmt.Errorf("volume %v is not empty", volName)
+		return
+	}
+
+	im = &metaImporter{
+		mw:       mw,
+		inodes:   map[uint64]uint64{proto.RootIno: proto.RootIno},
+		linked:   make(map[uint64]bool),
+		start:    time.Now(),
+		reported: time.Now(),
+	}
+	// dentries may refer to the inodes of later partitions, so all inodes are
+	// created at the first pass and linked into the namespace at the second.
+	stdout("Importing inodes ...\n")
+	if err = im.importFile(fileName, im.importInode); err != nil {
+		err = fmt.Errorf("Import inodes failed:\n%v\n", err)
+		return
+	}
+	stdout("Importing dentries ...\n")
+	if err = im.importFile(fileName, im.importDentry); err != nil {
+		err = fmt.Errorf("Import dentries failed:\n%v\n", err)
+		return
+	}
+	return
+}
+
+// metaImporter recreates the exported namespace in a volume, the inodes get the new ids of
+// the volume and keep the extent keys, so the data is referred to rather than copied.
+type metaImporter struct {
+	mw     *meta.MetaWrapper
+	inodes map[uint64]uint64 // exported inode id to imported inode id
+	linked map[uint64]bool   // imported inodes which have been linked by a dentry
+
+	count    proto.MetaExportTrailer
+	start    time.Time
+	reported time.Time
+}
+
+func (im *metaImporter) importFile(fileName string, fn func(record *proto.MetaExportRecord) error) (err error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	reader := proto.NewMetaExportReader(bufio.NewReader(file))
+	for {
+		var record *proto.MetaExportRecord
+		if record, err = reader.Next(); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return
+		}
+		if err = fn(record); err != nil {
+			return
+		}
+		if time.Since(im.reported) > cmdVolExportProgressPeriod {
+			im.reported = time.Now()
+			stdout("Progress: inodes: %v, dentries: %v, xattrs: %v, cost: %v\n",
+				im.count.Inodes, im.count.Dentries, im.count.XAttrs, time.Since(im.start).Truncate(time.Second))
+		}
+	}
+}
+
+func (im *metaImporter) importInode(record *proto.MetaExportRecord) (err error) {
+	switch record.Type {
+	case proto.MetaExportRecordInode:
+		ino := record.Inode
+		// the unlinked files are being deleted or still open in the exported volume
+		if ino.NLink == 0 && !proto.IsDir(ino.Type) {
+			return nil
+		}
+		newIno := proto.RootIno
+		if ino.Inode != proto.RootIno {
+			var info *proto.InodeInfo
+			if info, err = im.mw.InodeCreate_ll(0, ino.Type, ino.Uid, ino.Gid, ino.LinkTarget, nil, ""); err != nil {
+				return fmt.Errorf("create inode of %v: %v", ino.Inode, err)
+			}
+			newIno = info.Inode
+			im.inodes[ino.Inode] = newIno
+		}
+		if len(ino.Extents) > 0 {
+			if err = im.mw.AppendExtentKeys(newIno, ino.Extents); err != nil {
+				return fmt.Errorf("append extent keys of %v: %v", ino.Inode, err)
+			}
+		}
+		if len(ino.ObjExtents) > 0 {
+			if err = im.mw.AppendObjExtentKeys(newIno, ino.ObjExtents); err != nil {
+				return fmt.Errorf("append obj extent keys of %v: %v", ino.Inode, err)
+			}
+		}
+		if proto.IsRegular(ino.Type) {
+			if err = im.mw.Truncate(newIno, ino.Size, ""); err != nil {
+				return fmt.Errorf("truncate %v: %v", ino.Inode, err)
+			}
+		}
+		valid := proto.AttrModifyTime | proto.AttrAccessTime
+		if ino.Inode == proto.RootIno {
+			valid |= proto.AttrMode | proto.AttrUid | proto.AttrGid
+		}
+		if err = im.mw.Setattr(newIno, valid, ino.Type, ino.Uid, ino.Gid, ino.AccessTime, ino.ModifyTime); err != nil {
+			return fmt.Errorf("set attr of %v: %v", ino.Inode, err)
+		}
+		im.count.Inodes++
+	case proto.MetaExportRecordXAttr:
+		newIno, ok := im.inodes[record.XAttr.Inode]
+		if !ok {
+			return nil
+		}
+		attrs := make(map[string]string, len(record.XAttr.Attrs))
+		for k, v := range record.XAttr.Attrs {
+			attrs[k] = string(v)
+		}
+		if err = im.mw.BatchSetXAttr_ll(newIno, attrs); err != nil {
+			return fmt.Errorf("set xattrs of %v: %v", record.XAttr.Inode, err)
+		}
+		im.count.XAttrs++
+	}
+	return nil
+}
+
+func (im *metaImporter) importDentry(record *proto.MetaExportRecord) (err error) {
+	if record.Type != proto.MetaExportRecordDentry {
+		return nil
+	}
+	den := record.Dentry
+	parentIno, ok := im.inodes[den.ParentId]
+	if !ok {
+		return fmt.Errorf("parent inode %v of dentry %v not found", den.ParentId, den.Name)
+	}
+	newIno, ok := im.inodes[den.Inode]
+	if !ok {
+		return fmt.Errorf("inode %v of dentry %v not found", den.Inode, den.Name)
+	}
+	// the other dentries of hard links increase the nlink of the inode
+	if im.linked[newIno] {
+		_, err = im.mw.Link(parentIno, den.Name, newIno, "")
+	} else {
+		err = im.mw.DentryCreate_ll(parentIno, den.Name, newIno, den.Type, "")
+	}
+	if err != nil {
+		return fmt.Errorf("create dentry %v in %v: %v", den.Name, den.ParentId, err)
+	}
+	im.linked[newIno] = true
+	im.count.Dentries++
+	return nil
+}
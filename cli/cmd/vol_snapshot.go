@@ -0,0 +1,498 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"hash/crc32"
+	"math/rand"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/data/stream"
+	"github.com/cubefs/cubefs/sdk/master"
+	"github.com/cubefs/cubefs/sdk/meta"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdVolSnapshotUse          = "snapshot [COMMAND]"
+	cmdVolSnapshotShort        = "Manage snapshots of a volume"
+	cmdVolSnapshotCreateUse    = "create [VOLUME]"
+	cmdVolSnapshotCreateShort  = "Create a snapshot of the volume"
+	cmdVolSnapshotListUse      = "list [VOLUME]"
+	cmdVolSnapshotListShort    = "List snapshots of the volume"
+	cmdVolSnapshotRestoreUse   = "restore [VOLUME] [SNAPSHOT VER] [SRC PATH] [DST PATH]"
+	cmdVolSnapshotRestoreShort = "Restore the path in the snapshot to a new path of the volume, and verify the restored files"
+
+	cmdVolSnapshotDefaultSamples = 100
+	cmdVolSnapshotCopyBufSize    = 1 << 20
+	cmdVolSnapshotProgressPeriod = 5 * time.Second
+)
+
+func newVolSnapshotCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   cmdVolSnapshotUse,
+		Short: cmdVolSnapshotShort,
+		Args:  cobra.MinimumNArgs(0),
+	}
+	cmd.AddCommand(
+		newVolSnapshotCreateCmd(client),
+		newVolSnapshotListCmd(client),
+		newVolSnapshotRestoreCmd(client),
+	)
+	return cmd
+}
+
+func newVolSnapshotCreateCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   cmdVolSnapshotCreateUse,
+		Short: cmdVolSnapshotCreateShort,
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			var ver *proto.VolVersionInfo
+			if ver, err = client.AdminAPI().CreateVersion(args[0]); err != nil {
+				err = fmt.Errorf("Create snapshot failed:\n%v\n", err)
+				return
+			}
+			stdout("Snapshot has been created, ver: %v\n", ver.Ver)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validVols(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	return cmd
+}
+
+func newVolSnapshotListCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     cmdVolSnapshotListUse,
+		Short:   cmdVolSnapshotListShort,
+		Aliases: []string{"ls"},
+		Args:    cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			var verList *proto.VolVersionInfoList
+			if verList, err = client.AdminAPI().GetVerList(args[0]); err != nil {
+				err = fmt.Errorf("List snapshots failed:\n%v\n", err)
+				return
+			}
+			stdout("%v\n", snapshotTableHeader)
+			for _, ver := range verList.VerList {
+				stdout("%v\n", formatSnapshotTableRow(ver))
+			}
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validVols(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	return cmd
+}
+
+func newVolSnapshotRestoreCmd(client *master.MasterClient) *cobra.Command {
+	var (
+		optSamples    int
+		optSkipVerify bool
+	)
+	cmd := &cobra.Command{
+		Use:   cmdVolSnapshotRestoreUse,
+		Short: cmdVolSnapshotRestoreShort,
+		Args:  cobra.MinimumNArgs(4),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			volName, srcPath, dstPath := args[0], args[2], args[3]
+			var verSeq uint64
+			if verSeq, err = strconv.ParseUint(args[1], 10, 64); err != nil {
+				err = fmt.Errorf("invalid snapshot ver %v: %v", args[1], err)
+				return
+			}
+
+			var r *snapshotRestorer
+			if r, err = newSnapshotRestorer(client, volName, verSeq); err != nil {
+				err = fmt.Errorf("Restore snapshot failed:\n%v\n", err)
+				return
+			}
+			defer r.close()
+
+			stdout("Restoring %v of snapshot %v to %v ...\n", srcPath, verSeq, dstPath)
+			if err = r.restore(srcPath, dstPath); err != nil {
+				err = fmt.Errorf("Restore snapshot failed:\n%v\n", err)
+				return
+			}
+			stdout("Restored dirs: %v, files: %v, bytes: %v, cost: %v\n",
+				r.dirs, r.files, formatSize(r.bytes), time.Since(r.start).Truncate(time.Second))
+			if optSkipVerify {
+				return
+			}
+
+			stdout("Verifying ...\n")
+			if err = r.verify(optSamples); err != nil {
+				err = fmt.Errorf("Verify restored files failed:\n%v\n", err)
+				return
+			}
+			stdout("Snapshot has been restored and verified successfully.\n")
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validVols(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	cmd.Flags().IntVar(&optSamples, "samples", cmdVolSnapshotDefaultSamples, "Number of restored files sampled to compare checksums")
+	cmd.Flags().BoolVar(&optSkipVerify, "skip-verify", false, "Skip verifying restored files")
+	return cmd
+}
+
+var snapshotTableRowPattern = "%-20v    %-20v    %-10v    %-20v"
+
+var snapshotTableHeader = fmt.Sprintf(snapshotTableRowPattern, "VER", "CREATE TIME", "STATUS", "DELETE TIME")
+
+func formatSnapshotTableRow(ver *proto.VolVersionInfo) string {
+	delTime := ""
+	if ver.DelTime > 0 {
+		delTime = formatTime(ver.DelTime)
+	}
+	return fmt.Sprintf(snapshotTableRowPattern, ver.Ver, formatTimeToString(time.UnixMicro(int64(ver.Ver))),
+		formatSnapshotStatus(ver.Status), delTime)
+}
+
+func formatSnapshotStatus(status uint8) string {
+	switch status {
+	case proto.VersionNormal:
+		return "normal"
+	case proto.VersionDeleted:
+		return "deleted"
+	case proto.VersionDeleting:
+		return "deleting"
+	case proto.VersionDeleteAbnormal:
+		return "abnormal"
+	case proto.VersionPrepare:
+		return "prepare"
+	}
+	return fmt.Sprintf("unknown(%v)", status)
+}
+
+type restoredFile struct {
+	path   string
+	srcIno uint64
+	dstIno uint64
+	size   uint64
+}
+
+// snapshotRestorer copies a path of the snapshot into the volume, the snapshot is
+// read by meta and data clients with the snapshot ver as the read seq.
+type snapshotRestorer struct {
+	srcMw *meta.MetaWrapper
+	srcEc *stream.ExtentClient
+	dstMw *meta.MetaWrapper
+	dstEc *stream.ExtentClient
+
+	srcRootIno uint64
+	dstRootIno uint64
+	restored   []restoredFile
+
+	dirs     uint64
+	files    uint64
+	bytes    uint64
+	start    time.Time
+	reported time.Time
+}
+
+func newSnapshotRestorer(client *master.MasterClient, volName string, verSeq uint64) (r *snapshotRestorer, err error) {
+	var svv *proto.SimpleVolView
+	if svv, err = client.AdminAPI().GetVolumeSimpleInfo(volName); err != nil {
+		return
+	}
+	if !proto.IsHot(svv.VolType) {
+		return nil, fmt.Errorf("volume %v is not a hot volume", volName)
+	}
+	var verList *proto.VolVersionInfoList
+	if verList, err = client.AdminAPI().GetVerList(volName); err != nil {
+		return
+	}
+	found := false
+	for _, ver := range verList.VerList {
+		if ver.Ver == verSeq && ver.Status == proto.VersionNormal {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("snapshot %v of volume %v not found or not normal", verSeq, volName)
+	}
+
+	r = &snapshotRestorer{start: time.Now(), reported: time.Now()}
+	if r.srcMw, r.srcEc, err = newSnapshotClients(client, volName, verSeq); err != nil {
+		return nil, err
+	}
+	if r.dstMw, r.dstEc, err = newSnapshotClients(client, volName, 0); err != nil {
+		r.close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func newSnapshotClients(client *master.MasterClient, volName string, verSeq uint64) (mw *meta.MetaWrapper, ec *stream.ExtentClient, err error) {
+	if mw, err = meta.NewMetaWrapper(&meta.MetaConfig{
+		Volume:     volName,
+		Masters:    client.Nodes(),
+		VerReadSeq: verSeq,
+	}); err != nil {
+		return nil, nil, fmt.Errorf("NewMetaWrapper failed: %v", err)
+	}
+	if ec, err = stream.NewExtentClient(&stream.ExtentConfig{
+		Volume:            volName,
+		Masters:           client.Nodes(),
+		VerReadSeq:        verSeq,
+		OnAppendExtentKey: mw.AppendExtentKey,
+		OnSplitExtentKey:  mw.SplitExtentKey,
+		OnGetExtents:      mw.GetExtents,
+		OnTruncate:        mw.Truncate,
+		DisableMetaCache:  true,
+	}); err != nil {
+		mw.Close()
+		return nil, nil, fmt.Errorf("NewExtentClient failed: %v", err)
+	}
+	return
+}
+
+func (r *snapshotRestorer) close() {
+	if r.srcEc != nil {
+		r.srcEc.Close()
+		r.srcMw.Close()
+	}
+	if r.dstEc != nil {
+		r.dstEc.Close()
+		r.dstMw.Close()
+	}
+}
+
+// restore restores srcPath of the snapshot to dstPath, whose parent must exist and itself must not.
+func (r *snapshotRestorer) restore(srcPath, dstPath string) (err error) {
+	srcPath, dstPath = path.Clean("/"+srcPath), path.Clean("/"+dstPath)
+	if r.srcRootIno, err = r.srcMw.LookupPath(srcPath); err != nil {
+		return fmt.Errorf("lookup %v in snapshot: %v", srcPath, err)
+	}
+	var parentIno uint64
+	if parentIno, err = r.dstMw.LookupPath(path.Dir(dstPath)); err != nil {
+		return fmt.Errorf("lookup parent of %v: %v", dstPath, err)
+	}
+	if _, err = r.dstMw.LookupPath(dstPath); err == nil {
+		return fmt.Errorf("%v already exists", dstPath)
+	}
+
+	var info *proto.InodeInfo
+	if info, err = r.srcMw.InodeGet_ll(r.srcRootIno); err != nil {
+		return fmt.Errorf("get inode of %v in snapshot: %v", srcPath, err)
+	}
+	if !proto.IsDir(info.Mode) {
+		return r.restoreEntry(info, parentIno, path.Base(dstPath), dstPath)
+	}
+	var dstInfo *proto.InodeInfo
+	if dstInfo, err = r.dstMw.Create_ll(parentIno, path.Base(dstPath), info.Mode, info.Uid, info.Gid, nil, dstPath, false); err != nil {
+		return fmt.Errorf("create %v: %v", dstPath, err)
+	}
+	r.dirs++
+	r.dstRootIno = dstInfo.Inode
+	return r.restoreDir(r.srcRootIno, dstInfo.Inode, dstPath)
+}
+
+func (r *snapshotRestorer) restoreDir(srcIno, dstIno uint64, dstPath string) error {
+	children, err := r.srcMw.ReadDir_ll(srcIno)
+	if err != nil {
+		return fmt.Errorf("read dir %v in snapshot: %v", dstPath, err)
+	}
+	for _, child := range children {
+		info, err := r.srcMw.InodeGet_ll(child.Inode)
+		if err != nil {
+			return fmt.Errorf("get inode of %v in snapshot: %v", path.Join(dstPath, child.Name), err)
+		}
+		if err = r.restoreEntry(info, dstIno, child.Name, path.Join(dstPath, child.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *snapshotRestorer) restoreEntry(info *proto.InodeInfo, parentIno uint64, name, fullPath string) error {
+	var target []byte
+	if proto.IsSymlink(info.Mode) {
+		target = info.Target
+	}
+	dstInfo, err := r.dstMw.Create_ll(parentIno, name, info.Mode, info.Uid, info.Gid, target, fullPath, false)
+	if err != nil {
+		return fmt.Errorf("create %v: %v", fullPath, err)
+	}
+	switch {
+	case proto.IsDir(info.Mode):
+		r.dirs++
+		if err = r.restoreDir(info.Inode, dstInfo.Inode, fullPath); err != nil {
+			return err
+		}
+	case proto.IsRegular(info.Mode):
+		if err = r.copyFile(info.Inode, dstInfo.Inode, info.Size); err != nil {
+			return fmt.Errorf("copy %v: %v", fullPath, err)
+		}
+		r.files++
+		r.bytes += info.Size
+		r.restored = append(r.restored, restoredFile{path: fullPath, srcIno: info.Inode, dstIno: dstInfo.Inode, size: info.Size})
+	default:
+		r.files++
+	}
+	if time.Since(r.reported) > cmdVolSnapshotProgressPeriod {
+		r.reported = time.Now()
+		stdout("Progress: dirs: %v, files: %v, bytes: %v, cost: %v\n",
+			r.dirs, r.files, formatSize(r.bytes), time.Since(r.start).Truncate(time.Second))
+	}
+	return nil
+}
+
+func (r *snapshotRestorer) copyFile(srcIno, dstIno, size uint64) (err error) {
+	if err = r.srcEc.OpenStream(srcIno); err != nil {
+		return
+	}
+	defer r.srcEc.CloseStream(srcIno)
+	if err = r.dstEc.OpenStream(dstIno); err != nil {
+		return
+	}
+	defer r.dstEc.CloseStream(dstIno)
+
+	buf := make([]byte, cmdVolSnapshotCopyBufSize)
+	for offset := uint64(0); offset < size; {
+		n := size - offset
+		if n > uint64(len(buf)) {
+			n = uint64(len(buf))
+		}
+		var read int
+		if read, err = r.srcEc.Read(srcIno, buf, int(offset), int(n)); err != nil {
+			return
+		}
+		if read == 0 {
+			return fmt.Errorf("unexpected end of file at offset %v, size %v", offset, size)
+		}
+		if _, err = r.dstEc.Write(dstIno, int(offset), buf[:read], 0, nil); err != nil {
+			return
+		}
+		offset += uint64(read)
+	}
+	return r.dstEc.Flush(dstIno)
+}
+
+// verify compares the inode counts of the snapshot and the restored path,
+// and the checksums of sampled restored files.
+func (r *snapshotRestorer) verify(samples int) (err error) {
+	if r.dstRootIno != 0 {
+		var dirs, files uint64
+		if dirs, files, err = countTree(r.dstMw, r.dstRootIno); err != nil {
+			return
+		}
+		var srcDirs, srcFiles uint64
+		if srcDirs, srcFiles, err = countTree(r.srcMw, r.srcRootIno); err != nil {
+			return
+		}
+		if dirs != srcDirs || files != srcFiles {
+			return fmt.Errorf("inode count mismatch: snapshot dirs %v files %v, restored dirs %v files %v",
+				srcDirs, srcFiles, dirs, files)
+		}
+		stdout("Inode count matched: dirs: %v, files: %v\n", dirs, files)
+	}
+
+	sampled := r.restored
+	if samples >= 0 && len(sampled) > samples {
+		sampled = make([]restoredFile, len(r.restored))
+		copy(sampled, r.restored)
+		rand.Shuffle(len(sampled), func(i, j int) { sampled[i], sampled[j] = sampled[j], sampled[i] })
+		sampled = sampled[:samples]
+	}
+	for _, file := range sampled {
+		var srcSum, dstSum uint32
+		if srcSum, err = fileChecksum(r.srcEc, file.srcIno, file.size); err != nil {
+			return fmt.Errorf("checksum %v in snapshot: %v", file.path, err)
+		}
+		if dstSum, err = fileChecksum(r.dstEc, file.dstIno, file.size); err != nil {
+			return fmt.Errorf("checksum %v: %v", file.path, err)
+		}
+		if srcSum != dstSum {
+			return fmt.Errorf("checksum mismatch of %v: snapshot %v, restored %v", file.path, srcSum, dstSum)
+		}
+	}
+	stdout("Checksum matched: %v sampled files\n", len(sampled))
+	return nil
+}
+
+// countTree returns the number of dirs, excluding the root, and other inodes under the root.
+func countTree(mw *meta.MetaWrapper, root uint64) (dirs, files uint64, err error) {
+	children, err := mw.ReadDir_ll(root)
+	if err != nil {
+		return
+	}
+	for _, child := range children {
+		if !proto.IsDir(child.Type) {
+			files++
+			continue
+		}
+		dirs++
+		var subDirs, subFiles uint64
+		if subDirs, subFiles, err = countTree(mw, child.Inode); err != nil {
+			return
+		}
+		dirs += subDirs
+		files += subFiles
+	}
+	return
+}
+
+func fileChecksum(ec *stream.ExtentClient, ino, size uint64) (sum uint32, err error) {
+	if err = ec.OpenStream(ino); err != nil {
+		return
+	}
+	defer ec.CloseStream(ino)
+	buf := make([]byte, cmdVolSnapshotCopyBufSize)
+	for offset := uint64(0); offset < size; {
+		n := size - offset
+		if n > uint64(len(buf)) {
+			n = uint64(len(buf))
+		}
+		var read int
+		if read, err = ec.Read(ino, buf, int(offset), int(n)); err != nil {
+			return
+		}
+		if read == 0 {
+			return 0, fmt.Errorf("unexpected end of file at offset %v, size %v", offset, size)
+		}
+		sum = crc32.Update(sum, crc32.IEEETable, buf[:read])
+		offset += uint64(read)
+	}
+	return
+}
@@ -43,6 +43,20 @@ const (
 	DefaultReaddirLimit = 1024
 )
 
+const (
+	// the expiration duration of the negative dentry is bounded to avoid hiding files created by others for long
+	MaxNegativeDentryExpiration = 60 * time.Second
+	MaxNegativeDentryCache      = 1000000
+)
+
+const (
+	// the stat ahead starts after continuous lookups in the order of readdir
+	StatAheadMinHits    = 2
+	MaxStatAheadWindow  = 4096
+	MaxStatAheadDirs    = 1024
+	MaxStatAheadEntries = 1 << 16
+)
+
 const (
 	DeleteExtentsTimeout = 600 * time.Second
 )
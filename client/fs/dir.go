@@ -166,6 +166,7 @@ func (d *Dir) Create(ctx context.Context, req *fuse.CreateRequest, resp *fuse.Cr
 
 	info, err := d.super.mw.Create_ll(d.info.Inode, req.Name, proto.Mode(req.Mode.Perm()), req.Uid, req.Gid, nil,
 		fullPath, false)
+	d.super.nc.Delete(d.info.Inode, req.Name)
 	if err != nil {
 		log.LogErrorf("Create: parent(%v) req(%v) err(%v)", d.info.Inode, req, err)
 		return nil, nil, ParseError(err)
@@ -201,6 +202,8 @@ func (d *Dir) Forget() {
 	}()
 
 	d.super.ic.Delete(ino)
+	d.super.nc.DeleteDir(ino)
+	d.super.sa.Forget(ino)
 
 	d.super.fslock.Lock()
 	delete(d.super.nodeCache, ino)
@@ -225,6 +228,7 @@ func (d *Dir) Mkdir(ctx context.Context, req *fuse.MkdirRequest) (fs.Node, error
 	log.LogDebugf("TRACE Mkdir:enter")
 	info, err := d.super.mw.Create_ll(d.info.Inode, req.Name, proto.Mode(os.ModeDir|req.Mode.Perm()), req.Uid,
 		req.Gid, nil, fullPath, false)
+	d.super.nc.Delete(d.info.Inode, req.Name)
 	if err != nil {
 		log.LogErrorf("Mkdir: parent(%v) req(%v) err(%v)", d.info.Inode, req, err)
 		return nil, ParseError(err)
@@ -305,6 +309,13 @@ func (d *Dir) Lookup(ctx context.Context, req *fuse.LookupRequest, resp *fuse.Lo
 	log.LogDebugf("TRACE Lookup: parent(%v) req(%v)", d.info.Inode, req)
 	log.LogDebugf("TRACE Lookup: parent(%v) path(%v) d.super.bcacheDir(%v)", d.info.Inode, d.getCwd(), d.super.bcacheDir)
 
+	if d.super.nc.Has(d.info.Inode, req.Name) {
+		exporter.NewCounter("lookupNegativeDcacheHit").AddWithLabels(1, map[string]string{exporter.Vol: d.super.volname})
+		err = syscall.ENOENT
+		return nil, ParseError(err)
+	}
+	ncacheGen := d.super.nc.Gen()
+
 	if d.needDentrycache() {
 		dcachev2 = true
 	}
@@ -320,6 +331,8 @@ func (d *Dir) Lookup(ctx context.Context, req *fuse.LookupRequest, resp *fuse.Lo
 			if err != nil {
 				if err != syscall.ENOENT {
 					log.LogErrorf("Lookup: parent(%v) name(%v) err(%v)", d.info.Inode, req.Name, err)
+				} else {
+					d.super.nc.Put(d.info.Inode, req.Name, ncacheGen)
 				}
 				return nil, ParseError(err)
 			}
@@ -341,6 +354,8 @@ func (d *Dir) Lookup(ctx context.Context, req *fuse.LookupRequest, resp *fuse.Lo
 			if err != nil {
 				if err != syscall.ENOENT {
 					log.LogErrorf("Lookup: parent(%v) name(%v) err(%v)", d.info.Inode, req.Name, err)
+				} else {
+					d.super.nc.Put(d.info.Inode, req.Name, ncacheGen)
 				}
 				return nil, ParseError(err)
 			}
@@ -350,6 +365,7 @@ func (d *Dir) Lookup(ctx context.Context, req *fuse.LookupRequest, resp *fuse.Lo
 		ino = cino
 	}
 
+	d.super.sa.Lookup(d.info.Inode, req.Name)
 	info, err := d.super.InodeGet(ino)
 	if err != nil {
 		log.LogErrorf("Lookup: parent(%v) name(%v) ino(%v) err(%v)", d.info.Inode, req.Name, ino, err)
@@ -483,9 +499,14 @@ func (d *Dir) ReadDir(ctx context.Context, req *fuse.ReadRequest, resp *fuse.Rea
 		}
 	}
 
-	infos := d.super.mw.BatchInodeGet(inodes)
-	for _, info := range infos {
-		d.super.ic.Put(info)
+	// the attributes are fetched ahead of the lookups if stat ahead is enabled
+	if d.super.sa.Enabled() {
+		d.super.sa.Record(d.info.Inode, children, req.Offset != 0)
+	} else {
+		infos := d.super.mw.BatchInodeGet(inodes)
+		for _, info := range infos {
+			d.super.ic.Put(info)
+		}
 	}
 
 	d.dcache = dcache
@@ -562,9 +583,13 @@ func (d *Dir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
 		}
 	}
 
-	infos := d.super.mw.BatchInodeGet(inodes)
-	for _, info := range infos {
-		d.super.ic.Put(info)
+	if d.super.sa.Enabled() {
+		d.super.sa.Record(d.info.Inode, children, false)
+	} else {
+		infos := d.super.mw.BatchInodeGet(inodes)
+		for _, info := range infos {
+			d.super.ic.Put(info)
+		}
 	}
 	d.dcache = dcache
 	elapsed := time.Since(start)
@@ -629,6 +654,7 @@ func (d *Dir) Rename(ctx context.Context, req *fuse.RenameRequest, newDir fs.Nod
 		}
 	}
 	err = d.super.mw.Rename_ll(d.info.Inode, req.OldName, dstDir.info.Inode, req.NewName, srcPath, dstPath, true)
+	d.super.nc.Delete(dstDir.info.Inode, req.NewName)
 	if err != nil {
 		log.LogErrorf("Rename: parent(%v) req(%v) err(%v)", d.info.Inode, req, err)
 		return ParseError(err)
@@ -693,6 +719,7 @@ func (d *Dir) Mknod(ctx context.Context, req *fuse.MknodRequest) (fs.Node, error
 	fullPath := path.Join(d.getCwd(), req.Name)
 	info, err := d.super.mw.Create_ll(d.info.Inode, req.Name, proto.Mode(req.Mode), req.Uid, req.Gid,
 		nil, fullPath, false)
+	d.super.nc.Delete(d.info.Inode, req.Name)
 	if err != nil {
 		log.LogErrorf("Mknod: parent(%v) req(%v) err(%v)", d.info.Inode, req, err)
 		return nil, ParseError(err)
@@ -725,6 +752,7 @@ func (d *Dir) Symlink(ctx context.Context, req *fuse.SymlinkRequest) (fs.Node, e
 	fullPath := path.Join(d.getCwd(), req.NewName)
 	info, err := d.super.mw.Create_ll(parentIno, req.NewName, proto.Mode(os.ModeSymlink|os.ModePerm), req.Uid,
 		req.Gid, []byte(req.Target), fullPath, false)
+	d.super.nc.Delete(parentIno, req.NewName)
 	if err != nil {
 		log.LogErrorf("Symlink: parent(%v) NewName(%v) err(%v)", parentIno, req.NewName, err)
 		return nil, ParseError(err)
@@ -768,6 +796,7 @@ func (d *Dir) Link(ctx context.Context, req *fuse.LinkRequest, old fs.Node) (fs.
 	}()
 	fullPath := path.Join(d.getCwd(), req.NewName)
 	info, err := d.super.mw.Link(d.info.Inode, req.NewName, oldInode.Inode, fullPath)
+	d.super.nc.Delete(d.info.Inode, req.NewName)
 	if err != nil {
 		log.LogErrorf("Link: parent(%v) name(%v) ino(%v) err(%v)", d.info.Inode, req.NewName, oldInode.Inode, err)
 		return nil, ParseError(err)
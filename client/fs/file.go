@@ -44,6 +44,7 @@ type File struct {
 	sync.RWMutex
 	fReader *blobstore.Reader
 	fWriter *blobstore.Writer
+	advice  int32 // the access pattern hinted by XAttrKeyFadvise
 }
 
 // Functions that File needs to implement
@@ -233,7 +234,8 @@ func (f *File) Open(ctx context.Context, req *fuse.OpenRequest, resp *fuse.OpenR
 
 	f.super.ec.RefreshExtentsCache(ino)
 
-	if f.super.keepCache && resp != nil {
+	advice := f.getAdvice()
+	if f.super.keepCache && resp != nil && advice < FadviseNoReuse {
 		resp.Flags |= fuse.OpenKeepCache
 	}
 	if proto.IsCold(f.super.volType) {
@@ -273,6 +275,9 @@ func (f *File) Open(ctx context.Context, req *fuse.OpenRequest, resp *fuse.OpenR
 		}
 		log.LogDebugf("TRACE file open,ino(%v)  req.Flags(%v) reader(%v)  writer(%v)", ino, req.Flags, f.fReader, f.fWriter)
 	}
+	if advice != FadviseNormal {
+		f.applyAdvice(advice)
+	}
 
 	elapsed := time.Since(start)
 	log.LogDebugf("TRACE Open: ino(%v) req(%v) resp(%v) (%v)ns", ino, req, resp, elapsed.Nanoseconds())
@@ -642,6 +647,10 @@ func (f *File) Getxattr(ctx context.Context, req *fuse.GetxattrRequest, resp *fu
 		stat.EndStat("Getxattr", err, bgTime, 1)
 	}()
 
+	if req.Name == XAttrKeyFadvise {
+		resp.Xattr = []byte(fadviseName(f.getAdvice()))
+		return nil
+	}
 	if !f.super.enableXattr {
 		return fuse.ENOSYS
 	}
@@ -701,6 +710,10 @@ func (f *File) Setxattr(ctx context.Context, req *fuse.SetxattrRequest) error {
 		stat.EndStat("Setxattr", err, bgTime, 1)
 	}()
 
+	if req.Name == XAttrKeyFadvise {
+		err = f.setAdvice(string(req.Xattr))
+		return err
+	}
 	if !f.super.enableXattr {
 		return fuse.ENOSYS
 	}
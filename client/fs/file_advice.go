@@ -0,0 +1,85 @@
+// Copyright 2018 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package fs
+
+import (
+	"strings"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/cubefs/cubefs/depends/bazil.org/fuse"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// FUSE does not forward posix_fadvise, so the access pattern of a file is hinted by the virtual
+// xattr below, which is kept in the client and never persisted to the metanode.
+const XAttrKeyFadvise = "user.cubefs.fadvise"
+
+const (
+	// the default behavior, the reads fill the block cache with the whole blocks or extents
+	FadviseNormal int32 = iota
+	FadviseSequential
+	// the reads do not fill the block cache
+	FadviseRandom
+	// the reads do not fill the block cache, and the page cache is not kept across the opens
+	FadviseNoReuse
+	// same as noreuse, and the cached blocks of the file are evicted from the block cache
+	FadviseDontNeed
+)
+
+var fadviseNames = []string{"normal", "sequential", "random", "noreuse", "dontneed"}
+
+func parseFadvise(value string) (int32, bool) {
+	value = strings.ToLower(strings.TrimSpace(strings.TrimRight(value, "\x00")))
+	for i, name := range fadviseNames {
+		if name == value {
+			return int32(i), true
+		}
+	}
+	return 0, false
+}
+
+func fadviseName(advice int32) string {
+	if advice < 0 || int(advice) >= len(fadviseNames) {
+		return fadviseNames[FadviseNormal]
+	}
+	return fadviseNames[advice]
+}
+
+func (f *File) getAdvice() int32 {
+	return atomic.LoadInt32(&f.advice)
+}
+
+func (f *File) setAdvice(value string) error {
+	advice, ok := parseFadvise(value)
+	if !ok {
+		return fuse.Errno(syscall.EINVAL)
+	}
+	atomic.StoreInt32(&f.advice, advice)
+	f.applyAdvice(advice)
+	if advice == FadviseDontNeed {
+		f.super.ec.EvictCache(f.info.Inode)
+		f.fReader.EvictCache()
+	}
+	log.LogDebugf("TRACE Fadvise: ino(%v) advice(%v)", f.info.Inode, fadviseName(advice))
+	return nil
+}
+
+// applyAdvice applies the advice to the opened stream and reader of the file.
+func (f *File) applyAdvice(advice int32) {
+	fill := advice < FadviseRandom
+	f.super.ec.SetCacheFill(f.info.Inode, fill)
+	f.fReader.SetCacheFill(fill)
+}
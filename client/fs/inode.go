@@ -20,6 +20,7 @@ import (
 	"github.com/cubefs/cubefs/depends/bazil.org/fuse"
 
 	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/exporter"
 	"github.com/cubefs/cubefs/util/log"
 )
 
@@ -27,6 +28,25 @@ const (
 	LogTimeFormat = "20060102150405000"
 )
 
+// statAheadFetch gets the attributes of the inodes missed in the inode cache by the bulk stat
+func (s *Super) statAheadFetch(inodes []uint64) {
+	misses := make([]uint64, 0, len(inodes))
+	for _, ino := range inodes {
+		if s.ic.Get(ino) == nil {
+			misses = append(misses, ino)
+		}
+	}
+	if len(misses) == 0 {
+		return
+	}
+	infos := s.mw.BatchInodeGet(misses)
+	for _, info := range infos {
+		s.ic.Put(info)
+	}
+	exporter.NewCounter("statAheadPrefetch").AddWithLabels(int64(len(infos)), map[string]string{exporter.Vol: s.volname})
+	log.LogDebugf("statAheadFetch: prefetch inodes(%v) got(%v)", len(misses), len(infos))
+}
+
 func (s *Super) InodeGet(ino uint64) (info *proto.InodeInfo, err error) {
 	info = s.ic.Get(ino)
 
@@ -50,6 +70,10 @@ func (s *Super) InodeGet(ino uint64) (info *proto.InodeInfo, err error) {
 	if isFind {
 		s, ok := node.(*Dir)
 		if ok {
+			// the directory is changed by others, names created there must be looked up again
+			if !s.info.ModifyTime.Equal(info.ModifyTime) {
+				s.super.nc.DeleteDir(ino)
+			}
 			s.info = info
 		} else {
 			node.(*File).info = info
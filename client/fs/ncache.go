@@ -0,0 +1,150 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package fs
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type negativeDentry struct {
+	parent     uint64
+	name       string
+	expiration time.Time
+}
+
+// NegativeDentryCache caches the names which are known not to exist in a directory,
+// so that repeated lookups of nonexistent paths do not go to the meta nodes.
+type NegativeDentryCache struct {
+	sync.Mutex
+	dirs        map[uint64]map[string]*list.Element
+	lruList     *list.List
+	expiration  time.Duration
+	maxElements int
+	// gen is increased by every invalidation, a lookup started before
+	// an invalidation must not put its stale result into the cache.
+	gen uint64
+}
+
+// NewNegativeDentryCache returns a new negative dentry cache.
+func NewNegativeDentryCache(exp time.Duration, maxElements int) *NegativeDentryCache {
+	if exp > MaxNegativeDentryExpiration {
+		exp = MaxNegativeDentryExpiration
+	}
+	return &NegativeDentryCache{
+		dirs:        make(map[uint64]map[string]*list.Element),
+		lruList:     list.New(),
+		expiration:  exp,
+		maxElements: maxElements,
+	}
+}
+
+// Gen returns the current generation, which should be taken before looking up meta nodes.
+func (nc *NegativeDentryCache) Gen() uint64 {
+	if nc == nil {
+		return 0
+	}
+	nc.Lock()
+	defer nc.Unlock()
+	return nc.gen
+}
+
+// Put records that the name does not exist in the parent directory,
+// it is ignored if any invalidation happened after gen.
+func (nc *NegativeDentryCache) Put(parent uint64, name string, gen uint64) {
+	if nc == nil {
+		return
+	}
+	nc.Lock()
+	defer nc.Unlock()
+	if gen != nc.gen {
+		return
+	}
+	entries, ok := nc.dirs[parent]
+	if !ok {
+		entries = make(map[string]*list.Element)
+		nc.dirs[parent] = entries
+	}
+	if element, ok := entries[name]; ok {
+		element.Value.(*negativeDentry).expiration = time.Now().Add(nc.expiration)
+		nc.lruList.MoveToFront(element)
+		return
+	}
+	for nc.lruList.Len() >= nc.maxElements && nc.lruList.Len() > 0 {
+		nc.remove(nc.lruList.Back())
+	}
+	entries[name] = nc.lruList.PushFront(&negativeDentry{
+		parent:     parent,
+		name:       name,
+		expiration: time.Now().Add(nc.expiration),
+	})
+}
+
+// Has returns true if the name is known not to exist in the parent directory.
+func (nc *NegativeDentryCache) Has(parent uint64, name string) bool {
+	if nc == nil {
+		return false
+	}
+	nc.Lock()
+	defer nc.Unlock()
+	element, ok := nc.dirs[parent][name]
+	if !ok {
+		return false
+	}
+	if element.Value.(*negativeDentry).expiration.Before(time.Now()) {
+		nc.remove(element)
+		return false
+	}
+	return true
+}
+
+// Delete invalidates the name in the parent directory, it is called when the name is created locally.
+func (nc *NegativeDentryCache) Delete(parent uint64, name string) {
+	if nc == nil {
+		return
+	}
+	nc.Lock()
+	defer nc.Unlock()
+	nc.gen++
+	if element, ok := nc.dirs[parent][name]; ok {
+		nc.remove(element)
+	}
+}
+
+// DeleteDir invalidates all names in the parent directory, it is called when
+// the directory is changed by others or forgotten by the kernel.
+func (nc *NegativeDentryCache) DeleteDir(parent uint64) {
+	if nc == nil {
+		return
+	}
+	nc.Lock()
+	defer nc.Unlock()
+	nc.gen++
+	for _, element := range nc.dirs[parent] {
+		nc.lruList.Remove(element)
+	}
+	delete(nc.dirs, parent)
+}
+
+// The caller should grab the lock of the cache.
+func (nc *NegativeDentryCache) remove(element *list.Element) {
+	dentry := nc.lruList.Remove(element).(*negativeDentry)
+	entries := nc.dirs[dentry.parent]
+	delete(entries, dentry.name)
+	if len(entries) == 0 {
+		delete(nc.dirs, dentry.parent)
+	}
+}
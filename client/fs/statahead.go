@@ -0,0 +1,191 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package fs
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+type statAheadDir struct {
+	ino     uint64
+	inodes  []uint64
+	index   map[string]int
+	last    int // index of the last looked up dentry
+	hits    int // number of continuous lookups in the order of readdir
+	fetched int // dentries before it are prefetched or being prefetched
+	element *list.Element
+}
+
+// StatAhead prefetches the inode attributes of the upcoming dentries of a directory, when the
+// dentries are looked up in the order of readdir, e.g. du, find and rsync. The readdir only records
+// the dentries, and the attributes are fetched in batches by the bulk stat ahead of the lookups.
+type StatAhead struct {
+	sync.Mutex
+	dirs    map[uint64]*statAheadDir
+	lruList *list.List
+	window  int
+	// fetch gets the attributes of inodes and puts them into the inode cache
+	fetch func(inodes []uint64)
+}
+
+// NewStatAhead returns a new stat ahead engine which prefetches window dentries ahead of lookups.
+func NewStatAhead(window int, fetch func(inodes []uint64)) *StatAhead {
+	if window > MaxStatAheadWindow {
+		window = MaxStatAheadWindow
+	}
+	return &StatAhead{
+		dirs:    make(map[uint64]*statAheadDir),
+		lruList: list.New(),
+		window:  window,
+		fetch:   fetch,
+	}
+}
+
+// Record records the dentries of a directory returned by readdir, the dentries are appended
+// to the previous ones if the readdir continues from an offset.
+func (sa *StatAhead) Record(parent uint64, children []proto.Dentry, continued bool) {
+	if sa == nil || len(children) == 0 {
+		return
+	}
+	sa.Lock()
+	defer sa.Unlock()
+	dir, ok := sa.dirs[parent]
+	if !ok || !continued {
+		if ok {
+			sa.lruList.Remove(dir.element)
+		}
+		dir = &statAheadDir{ino: parent, index: make(map[string]int), last: -1}
+		dir.element = sa.lruList.PushFront(dir)
+		sa.dirs[parent] = dir
+		if sa.lruList.Len() > MaxStatAheadDirs {
+			oldest := sa.lruList.Back().Value.(*statAheadDir)
+			sa.lruList.Remove(oldest.element)
+			delete(sa.dirs, oldest.ino)
+		}
+	} else {
+		sa.lruList.MoveToFront(dir.element)
+	}
+	for _, child := range children {
+		if child.Name == "." || child.Name == ".." {
+			continue
+		}
+		if _, ok = dir.index[child.Name]; ok {
+			continue
+		}
+		dir.index[child.Name] = len(dir.inodes)
+		dir.inodes = append(dir.inodes, child.Inode)
+	}
+	if len(dir.inodes) > MaxStatAheadEntries {
+		dir.trim()
+	}
+	// the dentries are not looked up, e.g. ls without stat
+	if len(dir.inodes) > MaxStatAheadEntries {
+		sa.lruList.Remove(dir.element)
+		delete(sa.dirs, parent)
+	}
+}
+
+// trim drops the dentries which have been passed by the lookups
+func (dir *statAheadDir) trim() {
+	drop := dir.last + 1
+	if drop > dir.fetched {
+		drop = dir.fetched
+	}
+	if drop <= 0 {
+		return
+	}
+	dir.inodes = dir.inodes[drop:]
+	for name, idx := range dir.index {
+		if idx < drop {
+			delete(dir.index, name)
+		} else {
+			dir.index[name] = idx - drop
+		}
+	}
+	dir.last -= drop
+	dir.fetched -= drop
+}
+
+// Lookup tells the engine that the name of parent is looked up, and prefetches the upcoming
+// dentries asynchronously if the lookups follow the order of readdir.
+func (sa *StatAhead) Lookup(parent uint64, name string) {
+	if sa == nil {
+		return
+	}
+	sa.Lock()
+	dir, ok := sa.dirs[parent]
+	if !ok {
+		sa.Unlock()
+		return
+	}
+	idx, ok := dir.index[name]
+	if !ok {
+		sa.Unlock()
+		return
+	}
+	if idx == dir.last+1 {
+		dir.hits++
+	} else {
+		dir.hits = 0
+	}
+	dir.last = idx
+	var inodes []uint64
+	// prefetch the next window when the lookups pass the half of the prefetched one
+	if dir.hits >= StatAheadMinHits && dir.fetched < len(dir.inodes) && dir.fetched-idx < sa.window/2 {
+		begin := dir.fetched
+		if begin <= idx {
+			begin = idx + 1
+		}
+		end := idx + 1 + sa.window
+		if end > len(dir.inodes) {
+			end = len(dir.inodes)
+		}
+		if begin < end {
+			inodes = append(inodes, dir.inodes[begin:end]...)
+			dir.fetched = end
+		}
+	}
+	if idx == len(dir.inodes)-1 {
+		// the walk of directory is done
+		sa.lruList.Remove(dir.element)
+		delete(sa.dirs, parent)
+	}
+	sa.Unlock()
+
+	if len(inodes) > 0 {
+		go sa.fetch(inodes)
+	}
+}
+
+// Forget drops the recorded dentries of a directory
+func (sa *StatAhead) Forget(parent uint64) {
+	if sa == nil {
+		return
+	}
+	sa.Lock()
+	if dir, ok := sa.dirs[parent]; ok {
+		sa.lruList.Remove(dir.element)
+		delete(sa.dirs, parent)
+	}
+	sa.Unlock()
+}
+
+// Enabled returns whether the stat ahead is enabled
+func (sa *StatAhead) Enabled() bool {
+	return sa != nil
+}
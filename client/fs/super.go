@@ -39,6 +39,7 @@ import (
 	"github.com/cubefs/cubefs/util/auditlog"
 	"github.com/cubefs/cubefs/util/errors"
 	"github.com/cubefs/cubefs/util/log"
+	"github.com/cubefs/cubefs/util/retry"
 	"github.com/cubefs/cubefs/util/ump"
 )
 
@@ -52,6 +53,8 @@ type Super struct {
 	owner       string
 	ic          *InodeCache
 	dc          *Dcache
+	nc          *NegativeDentryCache
+	sa          *StatAhead
 	mw          *meta.MetaWrapper
 	ec          *stream.ExtentClient
 	orphan      *OrphanInodeList
@@ -103,9 +106,39 @@ const (
 	DefaultTaskPoolSize         = 30
 )
 
+// retryPolicy returns the policy def overridden by the retry options of the mount, nil if none.
+func retryPolicy(def retry.Policy, opt *proto.MountOptions) (*retry.Policy, error) {
+	o := retry.Policy{
+		MaxAttempts:  int(opt.RetryMaxAttempts),
+		BaseInterval: time.Duration(opt.RetryBaseIntervalMs) * time.Millisecond,
+		MaxInterval:  time.Duration(opt.RetryMaxIntervalMs) * time.Millisecond,
+		Jitter:       float64(opt.RetryJitterPercent) / 100,
+	}
+	var err error
+	if o.Retryable, err = retry.ParseClass(opt.RetryErrorClasses); err != nil {
+		return nil, err
+	}
+	if o == (retry.Policy{}) {
+		return nil, nil
+	}
+	p := def.Override(o)
+	if err = p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
 // NewSuper returns a new Super.
 func NewSuper(opt *proto.MountOptions) (s *Super, err error) {
 	s = new(Super)
+	metaRetry, err := retryPolicy(meta.DefaultSendRetryPolicy, opt)
+	if err != nil {
+		return nil, errors.Trace(err, "invalid retry options")
+	}
+	dataRetry, err := retryPolicy(stream.DefaultSendRetryPolicy, opt)
+	if err != nil {
+		return nil, errors.Trace(err, "invalid retry options")
+	}
 	masters := strings.Split(opt.Master, meta.HostsSeparator)
 	metaConfig := &meta.MetaConfig{
 		Volume:          opt.Volname,
@@ -120,6 +153,8 @@ func NewSuper(opt *proto.MountOptions) (s *Super, err error) {
 		SubDir:                     opt.SubDir,
 		TrashRebuildGoroutineLimit: int(opt.TrashRebuildGoroutineLimit),
 		TrashTraverseLimit:         int(opt.TrashDeleteExpiredDirGoroutineLimit),
+		MetaDegradeProbeInterval:   time.Duration(opt.MetaDegradeProbeInterval) * time.Second,
+		RetryPolicy:                metaRetry,
 	}
 	s.mw, err = meta.NewMetaWrapper(metaConfig)
 	if err != nil {
@@ -157,6 +192,12 @@ func NewSuper(opt *proto.MountOptions) (s *Super, err error) {
 		s.ic = NewInodeCache(inodeExpiration, DefaultMaxInodeCache)
 		s.dc = NewDcache(inodeExpiration, DefaultMaxInodeCache)
 	}
+	if opt.NegativeDentryTTL > 0 {
+		s.nc = NewNegativeDentryCache(time.Duration(opt.NegativeDentryTTL)*time.Second, MaxNegativeDentryCache)
+	}
+	if opt.StatAheadWindow > 0 {
+		s.sa = NewStatAhead(int(opt.StatAheadWindow), s.statAheadFetch)
+	}
 	s.orphan = NewOrphanInodeList()
 	s.nodeCache = make(map[uint64]fs.Node)
 	s.disableDcache = opt.DisableDcache
@@ -222,6 +263,8 @@ func NewSuper(opt *proto.MountOptions) (s *Super, err error) {
 		NearRead:          opt.NearRead,
 		ReadRate:          opt.ReadRate,
 		WriteRate:         opt.WriteRate,
+		MaxUploadMBps:     opt.MaxUploadMBps,
+		MaxDownloadMBps:   opt.MaxDownloadMBps,
 		VolumeType:        opt.VolType,
 		BcacheEnable:      opt.EnableBcache,
 		BcacheDir:         opt.BcacheDir,
@@ -238,6 +281,7 @@ func NewSuper(opt *proto.MountOptions) (s *Super, err error) {
 
 		DisableMetaCache:             DisableMetaCache,
 		MinWriteAbleDataPartitionCnt: opt.MinWriteAbleDataPartitionCnt,
+		RetryPolicy:                  dataRetry,
 	}
 
 	s.ec, err = stream.NewExtentClient(extentConfig)
@@ -398,6 +442,26 @@ func (s *Super) SetRate(w http.ResponseWriter, r *http.Request) {
 			w.Write([]byte(fmt.Sprintf("Set write rate to %v successfully\n", msg)))
 		}
 	}
+
+	if mbps := r.FormValue("upload"); mbps != "" {
+		val, err := strconv.Atoi(mbps)
+		if err != nil {
+			w.Write([]byte("Set upload bandwidth failed\n"))
+		} else {
+			msg := s.ec.SetUploadBandwidth(val)
+			w.Write([]byte(fmt.Sprintf("Set upload bandwidth to %v successfully\n", msg)))
+		}
+	}
+
+	if mbps := r.FormValue("download"); mbps != "" {
+		val, err := strconv.Atoi(mbps)
+		if err != nil {
+			w.Write([]byte("Set download bandwidth failed\n"))
+		} else {
+			msg := s.ec.SetDownloadBandwidth(val)
+			w.Write([]byte(fmt.Sprintf("Set download bandwidth to %v successfully\n", msg)))
+		}
+	}
 }
 
 func (s *Super) umpKey(act string) string {
@@ -522,6 +586,25 @@ func (s *Super) QueryTrash(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *Super) RestoreTrash(w http.ResponseWriter, r *http.Request) {
+	var err error
+	if err = r.ParseForm(); err != nil {
+		replyFail(w, r, err.Error())
+		return
+	}
+	trashPath := r.FormValue("path")
+	if trashPath == "" {
+		replyFail(w, r, "path cannot be empty")
+		return
+	}
+	originalPath, err := s.mw.RestoreFromTrash(trashPath)
+	if err != nil {
+		replyFail(w, r, fmt.Sprintf("restore %v failed: %v", trashPath, err))
+		return
+	}
+	replySucc(w, r, fmt.Sprintf("restore %v to %v successfully\n", trashPath, originalPath))
+}
+
 func (s *Super) EnableAuditLog(w http.ResponseWriter, r *http.Request) {
 	var err error
 	if err = r.ParseForm(); err != nil {
@@ -94,6 +94,7 @@ const (
 	ControlCommandFreeOSMemory = "/debug/freeosmemory"
 	ControlCommandSuspend      = "/suspend"
 	ControlCommandResume       = "/resume"
+	ControlCommandHoldFuseFd   = "/holdfd"
 	Role                       = "Client"
 
 	DefaultIP            = "127.0.0.1"
@@ -269,6 +270,57 @@ func doSuspend(uds string, port string) (*os.File, error) {
 	return fud, nil
 }
 
+// sendFuseFdToHolder sends the FUSE fd to the holder listening on the unix domain socket,
+// the kernel doesn't abort the FUSE connection while the holder keeps the fd open, so that
+// the client restarted after crash resumes the mount with the fd received from the holder.
+func sendFuseFdToHolder(fsConn *fuse.Conn, sockaddr string) (err error) {
+	var (
+		addr   *net.UnixAddr
+		conn   *net.UnixConn
+		socket *os.File
+	)
+
+	fud := fsConn.GetFuseDevFile()
+	if fud == nil {
+		return fmt.Errorf("fuse dev not exist")
+	}
+	if addr, err = net.ResolveUnixAddr("unix", sockaddr); err != nil {
+		return fmt.Errorf("failed to create unix addr: %v", err)
+	}
+	if conn, err = net.DialUnix("unix", nil, addr); err != nil {
+		return fmt.Errorf("failed to connect unix socket: %v", err)
+	}
+	defer conn.Close()
+	if socket, err = conn.File(); err != nil {
+		return fmt.Errorf("failed to get socket file: %v", err)
+	}
+	defer socket.Close()
+
+	if err = util.SendFd(socket, fud.Name(), fud.Fd()); err != nil {
+		return fmt.Errorf("failed to send fuse dev file: %v", err)
+	}
+	return nil
+}
+
+func holdFuseFdHandler(fsConn *fuse.Conn) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sockaddr := r.FormValue("sock")
+		if sockaddr == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Need parameter 'sock' for IPC"))
+			return
+		}
+		if err := sendFuseFdToHolder(fsConn, sockaddr); err != nil {
+			log.LogErrorf("holdFuseFd: sock(%v) err(%v)", sockaddr, err)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		log.LogInfof("holdFuseFd: fuse fd is held by sock(%v)", sockaddr)
+		w.Write([]byte(fmt.Sprintf("fuse fd is held by %s", sockaddr)))
+	}
+}
+
 func getLastExitInfo(outputFile *os.File) string {
 	scanner := bufio.NewScanner(outputFile)
 	lastCubeFsLine := ""
@@ -527,6 +579,7 @@ func main() {
 	}
 	defer fsConn.Close()
 	defer super.Close()
+	http.HandleFunc(ControlCommandHoldFuseFd, holdFuseFdHandler(fsConn))
 
 	syslog.Printf("enable bcache %v", opt.EnableBcache)
 
@@ -562,6 +615,10 @@ func main() {
 			fsConn.SetFuseDevFile(fud)
 		}
 	}
+	if opt.AutoUpgrade {
+		go loopAutoUpgrade(opt)
+	}
+
 	// if last exit info no empty, export it
 	if exitInfo != "" {
 		syslog.Printf("LastExitInfo: %v", exitInfo)
@@ -716,6 +773,7 @@ func mount(opt *proto.MountOptions) (fsConn *fuse.Conn, super *cfs.Super, err er
 	http.HandleFunc(auditlog.SetAuditLogBufSizeReqPath, auditlog.ResetWriterBuffSize)
 	http.HandleFunc(meta.DisableTrash, super.DisableTrash)
 	http.HandleFunc(meta.QueryTrash, super.QueryTrash)
+	http.HandleFunc(meta.RestoreTrash, super.RestoreTrash)
 
 	statusCh := make(chan error)
 	pprofAddr := ":" + opt.Profport
@@ -918,6 +976,23 @@ func parseMountOption(cfg *config.Config) (*proto.MountOptions, error) {
 	opt.MinWriteAbleDataPartitionCnt = int(GlobalMountOptions[proto.MinWriteAbleDataPartitionCnt].GetInt64())
 	opt.FileSystemName = GlobalMountOptions[proto.FileSystemName].GetString()
 	opt.DisableMountSubtype = GlobalMountOptions[proto.DisableMountSubtype].GetBool()
+	opt.AutoUpgrade = GlobalMountOptions[proto.AutoUpgrade].GetBool()
+	opt.AutoUpgradePubKey = GlobalMountOptions[proto.AutoUpgradePubKey].GetString()
+	opt.NegativeDentryTTL = GlobalMountOptions[proto.NegativeDentryTTL].GetInt64()
+	opt.MaxUploadMBps = GlobalMountOptions[proto.MaxUploadMBps].GetInt64()
+	opt.MaxDownloadMBps = GlobalMountOptions[proto.MaxDownloadMBps].GetInt64()
+	opt.MetaDegradeProbeInterval = GlobalMountOptions[proto.MetaDegradeProbeInterval].GetInt64()
+	opt.StatAheadWindow = GlobalMountOptions[proto.StatAheadWindow].GetInt64()
+	opt.RetryMaxAttempts = GlobalMountOptions[proto.RetryMaxAttempts].GetInt64()
+	opt.RetryBaseIntervalMs = GlobalMountOptions[proto.RetryBaseIntervalMs].GetInt64()
+	opt.RetryMaxIntervalMs = GlobalMountOptions[proto.RetryMaxIntervalMs].GetInt64()
+	opt.RetryJitterPercent = GlobalMountOptions[proto.RetryJitterPercent].GetInt64()
+	opt.RetryErrorClasses = GlobalMountOptions[proto.RetryErrorClasses].GetString()
+	if stateFile := GlobalMountOptions[proto.SessionStateFile].GetString(); stateFile != "" {
+		if opt.SessionStateFile, err = filepath.Abs(stateFile); err != nil {
+			return nil, errors.Trace(err, "invalide session state file (%v) ", stateFile)
+		}
+	}
 
 	if opt.MountPoint == "" || opt.Volname == "" || opt.Owner == "" || opt.Master == "" {
 		return nil, errors.New(fmt.Sprintf("invalid config file: lack of mandatory fields, mountPoint(%v), volName(%v), owner(%v), masterAddr(%v)", opt.MountPoint, opt.Volname, opt.Owner, opt.Master))
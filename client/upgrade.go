@@ -0,0 +1,176 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/master"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	AutoUpgradeCheckInterval    = 10 * time.Minute
+	AutoUpgradeDownloadTimeout  = 10 * time.Minute
+	AutoUpgradeBinaryNameFormat = "%s.%s" // current executable path, version
+)
+
+// loopAutoUpgrade checks the client version advertised by master periodically,
+// then downloads and verifies the binary, and starts it to take over the FUSE
+// connection by fd passing (the same way of restoring with -r option),
+// the current client exits after the new client receives the FUSE fd.
+func loopAutoUpgrade(opt *proto.MountOptions) {
+	pubKey, err := base64.StdEncoding.DecodeString(opt.AutoUpgradePubKey)
+	if err != nil || len(pubKey) != ed25519.PublicKeySize {
+		log.LogErrorf("loopAutoUpgrade: invalid public key(%v), auto upgrade is disabled: err(%v)", opt.AutoUpgradePubKey, err)
+		return
+	}
+	if opt.Profport == "" {
+		log.LogErrorf("loopAutoUpgrade: http port is required by fuse handover, auto upgrade is disabled")
+		return
+	}
+
+	mc := master.NewMasterClientFromString(opt.Master, false)
+	ticker := time.NewTicker(AutoUpgradeCheckInterval)
+	defer ticker.Stop()
+
+	// a version failed to upgrade will not be tried again until master advertises another one
+	var lastTried string
+	for range ticker.C {
+		info, err := mc.AdminAPI().GetClientUpgrade()
+		if err != nil {
+			log.LogWarnf("loopAutoUpgrade: get client upgrade from master failed: err(%v)", err)
+			continue
+		}
+		if info.Version == "" || info.Version == proto.Version || info.Version == lastTried {
+			continue
+		}
+		lastTried = info.Version
+
+		log.LogInfof("loopAutoUpgrade: start to upgrade from version(%v) to version(%v) url(%v)",
+			proto.Version, info.Version, info.URL)
+		binPath, err := downloadClient(info, ed25519.PublicKey(pubKey))
+		if err != nil {
+			log.LogErrorf("loopAutoUpgrade: download client version(%v) failed: err(%v)", info.Version, err)
+			continue
+		}
+		if err = startUpgradedClient(binPath, opt.Profport); err != nil {
+			log.LogErrorf("loopAutoUpgrade: start client(%v) failed: err(%v)", binPath, err)
+			continue
+		}
+		log.LogInfof("loopAutoUpgrade: client(%v) started, waiting for fuse handover", binPath)
+	}
+}
+
+// downloadClient downloads the client binary next to the current executable,
+// and verifies its sha256 checksum and ed25519 signature.
+func downloadClient(info *proto.ClientUpgradeInfo, pubKey ed25519.PublicKey) (binPath string, err error) {
+	checksum, err := hex.DecodeString(info.Checksum)
+	if err != nil {
+		return
+	}
+	signature, err := base64.StdEncoding.DecodeString(info.Signature)
+	if err != nil {
+		return
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+	binPath = fmt.Sprintf(AutoUpgradeBinaryNameFormat, exe, info.Version)
+	tmpPath := binPath + ".tmp"
+
+	client := &http.Client{Timeout: AutoUpgradeDownloadTimeout}
+	resp, err := client.Get(info.URL)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("download status(%v)", resp.Status)
+		return
+	}
+
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o755)
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmpPath)
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(file, hash), resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return
+	}
+
+	digest := hash.Sum(nil)
+	if !bytes.Equal(digest, checksum) {
+		err = fmt.Errorf("checksum mismatch, expect(%v) actual(%x)", info.Checksum, digest)
+		return
+	}
+	if !ed25519.Verify(pubKey, digest, signature) {
+		err = fmt.Errorf("signature verification failed")
+		return
+	}
+	err = os.Rename(tmpPath, binPath)
+	return
+}
+
+// startUpgradedClient starts the new client with the same arguments in restore mode,
+// it tells this client to suspend by the http port and receives the FUSE fd.
+func startUpgradedClient(binPath, port string) error {
+	args := upgradeArgs(os.Args[1:], port)
+	cmd := exec.Command(binPath, args...)
+	cmd.Env = os.Environ()
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// the new client runs by itself after this client exits
+	return cmd.Process.Release()
+}
+
+// upgradeArgs replaces the restore options of the arguments, and runs in foreground
+// since the current process is already a daemon.
+func upgradeArgs(args []string, port string) []string {
+	newArgs := []string{"-f", "-r", "-p", port}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-f" || arg == "-r":
+		case arg == "-p" || arg == "-s":
+			i++
+		case strings.HasPrefix(arg, "-p=") || strings.HasPrefix(arg, "-s="):
+		default:
+			newArgs = append(newArgs, arg)
+		}
+	}
+	return newArgs
+}
@@ -0,0 +1,129 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func newUpgradeInfoForTest(t *testing.T, version string, binary []byte) (*proto.ClientUpgradeInfo, ed25519.PublicKey) {
+	pubKey, priKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	digest := sha256.Sum256(binary)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(binary)
+	}))
+	t.Cleanup(server.Close)
+	info := &proto.ClientUpgradeInfo{
+		Version:   version,
+		URL:       server.URL,
+		Checksum:  hex.EncodeToString(digest[:]),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(priKey, digest[:])),
+	}
+	return info, pubKey
+}
+
+func requireNoUpgradeBinary(t *testing.T, version string) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	binPath := fmt.Sprintf(AutoUpgradeBinaryNameFormat, exe, version)
+	for _, name := range []string{binPath, binPath + ".tmp"} {
+		_, err = os.Stat(name)
+		require.True(t, os.IsNotExist(err), name)
+	}
+}
+
+func TestDownloadClient(t *testing.T) {
+	binary := []byte("client binary")
+	info, pubKey := newUpgradeInfoForTest(t, "test-download", binary)
+	binPath, err := downloadClient(info, pubKey)
+	require.NoError(t, err)
+	defer os.Remove(binPath)
+	data, err := os.ReadFile(binPath)
+	require.NoError(t, err)
+	require.Equal(t, binary, data)
+	st, err := os.Stat(binPath)
+	require.NoError(t, err)
+	require.NotZero(t, st.Mode()&0o100)
+}
+
+func TestDownloadClientBadChecksum(t *testing.T) {
+	info, pubKey := newUpgradeInfoForTest(t, "test-bad-checksum", []byte("client binary"))
+	digest := sha256.Sum256([]byte("another binary"))
+	info.Checksum = hex.EncodeToString(digest[:])
+	_, err := downloadClient(info, pubKey)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "checksum mismatch")
+	requireNoUpgradeBinary(t, info.Version)
+}
+
+func TestDownloadClientBadSignature(t *testing.T) {
+	info, _ := newUpgradeInfoForTest(t, "test-bad-signature", []byte("client binary"))
+	// signed by another key
+	otherPubKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	_, err = downloadClient(info, otherPubKey)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "signature verification failed")
+	requireNoUpgradeBinary(t, info.Version)
+
+	// tampered signature
+	info, pubKey := newUpgradeInfoForTest(t, "test-bad-signature", []byte("client binary"))
+	signature, err := base64.StdEncoding.DecodeString(info.Signature)
+	require.NoError(t, err)
+	signature[0] ^= 0xff
+	info.Signature = base64.StdEncoding.EncodeToString(signature)
+	_, err = downloadClient(info, pubKey)
+	require.Error(t, err)
+	requireNoUpgradeBinary(t, info.Version)
+}
+
+func TestUpgradeArgs(t *testing.T) {
+	for _, cs := range []struct {
+		args   []string
+		expect []string
+	}{
+		{
+			args:   []string{"-c", "/etc/cfs/client.json"},
+			expect: []string{"-f", "-r", "-p", "17410", "-c", "/etc/cfs/client.json"},
+		},
+		{
+			// the restore options of the last restore are replaced
+			args:   []string{"-f", "-r", "-p", "17400", "-s", "/tmp/fuse.sock", "-c", "/etc/cfs/client.json", "-n"},
+			expect: []string{"-f", "-r", "-p", "17410", "-c", "/etc/cfs/client.json", "-n"},
+		},
+		{
+			args:   []string{"-p=17400", "-s=/tmp/fuse.sock", "-c=/etc/cfs/client.json"},
+			expect: []string{"-f", "-r", "-p", "17410", "-c=/etc/cfs/client.json"},
+		},
+		{
+			args:   nil,
+			expect: []string{"-f", "-r", "-p", "17410"},
+		},
+	} {
+		require.Equal(t, cs.expect, upgradeArgs(cs.args, "17410"))
+	}
+}
@@ -728,7 +728,7 @@ func (dp *DataPartition) streamRepairExtent(remoteExtentInfo *storage.ExtentInfo
 	doWork := func(wType int, currFixOffset uint64, dstOffset uint64, request repl.PacketInterface) (err error) {
 		log.LogDebugf("streamRepairExtent. currFixOffset %v dstOffset %v, request %v", currFixOffset, dstOffset, request)
 		var conn net.Conn
-		conn, err = dp.getRepairConn(remoteExtentInfo.Source)
+		conn, err = dp.getCompressedRepairConn(remoteExtentInfo.Source)
 		if err != nil {
 			return errors.Trace(err, "streamRepairExtent get conn from host(%v) error", remoteExtentInfo.Source)
 		}
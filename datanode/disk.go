@@ -87,6 +87,11 @@ type Disk struct {
 	limitFactor map[uint32]*rate.Limiter
 	limitRead   *ioLimiter
 	limitWrite  *ioLimiter
+	latency     diskLatency
+	// weights of this disk override weights of datanode if positive, accessed atomically
+	writeTinyWeight   int32
+	writeNormalWeight int32
+	qosConfigLock     sync.Mutex
 
 	// diskPartition info
 	diskPartition               *disk.PartitionStat
@@ -142,6 +147,14 @@ func NewDisk(path string, reservedSpace, diskRdonlySpace uint64, maxErrCnt int,
 	d.limitFactor[proto.IopsWriteType] = rate.NewLimiter(rate.Limit(proto.QosDefaultDiskMaxIoLimit), defaultIOLimitBurst)
 	d.limitRead = newIOLimiter(space.dataNode.diskReadFlow, space.dataNode.diskReadIocc)
 	d.limitWrite = newIOLimiter(space.dataNode.diskWriteFlow, space.dataNode.diskWriteIocc)
+	if err = d.loadQosConfig(); err != nil {
+		log.LogErrorf("action[NewDisk]: failed to load disk qos config, err %v", err)
+		// NOTE: continue execution with the weights of datanode
+		err = nil
+	}
+	d.limitWrite.ResetWeight(d.writeWeights())
+	d.limitRead.observe = d.latency.observe
+	d.limitWrite.observe = d.latency.observe
 
 	err = d.initDecommissionStatus()
 	if err != nil {
@@ -215,13 +228,15 @@ func (d *Disk) updateQosLimiter() {
 	for i := proto.IopsReadType; i < proto.FlowWriteType; i++ {
 		log.LogInfof("action[updateQosLimiter] type %v limit %v", proto.QosTypeString(i), d.limitFactor[i].Limit())
 	}
-	log.LogInfof("action[updateQosLimiter] read(iocc:%d iops:%d flow:%d) write(iocc:%d iops:%d flow:%d)",
+	tinyWeight, normalWeight := d.writeWeights()
+	log.LogInfof("action[updateQosLimiter] read(iocc:%d iops:%d flow:%d) write(iocc:%d iops:%d flow:%d weight tiny:%d normal:%d)",
 		d.dataNode.diskReadIocc, d.dataNode.diskReadIops, d.dataNode.diskReadFlow,
-		d.dataNode.diskWriteIocc, d.dataNode.diskWriteIops, d.dataNode.diskWriteFlow)
+		d.dataNode.diskWriteIocc, d.dataNode.diskWriteIops, d.dataNode.diskWriteFlow, tinyWeight, normalWeight)
 	d.limitRead.ResetIO(d.dataNode.diskReadIocc)
 	d.limitRead.ResetFlow(d.dataNode.diskReadFlow)
 	d.limitWrite.ResetIO(d.dataNode.diskWriteIocc)
 	d.limitWrite.ResetFlow(d.dataNode.diskWriteFlow)
+	d.limitWrite.ResetWeight(tinyWeight, normalWeight)
 }
 
 func (d *Disk) allocCheckLimit(factorType uint32, used uint32) error {
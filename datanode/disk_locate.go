@@ -0,0 +1,151 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// The locate LED and SMART of the disks are operated by the external tools, such as ledctl of
+// ledmon and smartctl of smartmontools, so that datacenter techs can find the failed disk.
+// The commands are configurable for the different enclosures, such as sg_ses.
+const (
+	diskDevicePlaceholder = "{dev}"
+
+	DefaultDiskLocateCmd    = "ledctl locate={dev}"
+	DefaultDiskLocateOffCmd = "ledctl locate_off={dev}"
+	DefaultDiskSmartCmd     = "smartctl -A {dev}"
+
+	diskCmdTimeout = 30 * time.Second
+)
+
+// DiskSmartInfo is the output of SMART command of the disk
+type DiskSmartInfo struct {
+	Disk   string `json:"disk"`
+	Device string `json:"device"`
+	Output string `json:"output"`
+}
+
+// buildDiskCmd returns the args of command with the device filled, the command is not run by
+// shell, so the device can't inject anything.
+func buildDiskCmd(cmd, device string) ([]string, error) {
+	if !strings.Contains(cmd, diskDevicePlaceholder) {
+		return nil, fmt.Errorf("command(%v) has no device placeholder %v", cmd, diskDevicePlaceholder)
+	}
+	args := strings.Fields(cmd)
+	for i := range args {
+		args[i] = strings.ReplaceAll(args[i], diskDevicePlaceholder, device)
+	}
+	return args, nil
+}
+
+func runDiskCmd(cmd, device string) (output string, err error) {
+	args, err := buildDiskCmd(cmd, device)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), diskCmdTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput()
+	output = string(out)
+	if err != nil {
+		err = fmt.Errorf("run %v failed: %v, output: %v", args, err, output)
+	}
+	return
+}
+
+func (s *DataNode) getDiskDevice(diskPath string) (device string, err error) {
+	disk, err := s.space.GetDisk(diskPath)
+	if err != nil {
+		return "", fmt.Errorf("disk %v is not found", diskPath)
+	}
+	partition := disk.GetDiskPartition()
+	if partition == nil || partition.Device == "" {
+		return "", fmt.Errorf("device of disk %v is unknown", diskPath)
+	}
+	return partition.Device, nil
+}
+
+func (s *DataNode) locateDisk(w http.ResponseWriter, r *http.Request) {
+	const (
+		paramDisk = "disk"
+		paramOn   = "on"
+	)
+	if err := r.ParseForm(); err != nil {
+		err = fmt.Errorf("parse form fail: %v", err)
+		s.buildFailureResp(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	on := true
+	if raw := r.FormValue(paramOn); raw != "" {
+		var err error
+		if on, err = strconv.ParseBool(raw); err != nil {
+			err = fmt.Errorf("parse param %v fail: %v", paramOn, err)
+			s.buildFailureResp(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	diskPath := r.FormValue(paramDisk)
+	device, err := s.getDiskDevice(diskPath)
+	if err != nil {
+		s.buildFailureResp(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cmd := s.diskLocateCmd
+	if !on {
+		cmd = s.diskLocateOffCmd
+	}
+	if _, err = runDiskCmd(cmd, device); err != nil {
+		log.LogErrorf("[locateDisk] disk(%v) device(%v) on(%v) err(%v)", diskPath, device, on, err)
+		s.buildFailureResp(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	log.LogWarnf("[locateDisk] disk(%v) device(%v) locate LED on(%v)", diskPath, device, on)
+	s.buildSuccessResp(w, "success")
+}
+
+func (s *DataNode) getDiskSmart(w http.ResponseWriter, r *http.Request) {
+	const (
+		paramDisk = "disk"
+	)
+	if err := r.ParseForm(); err != nil {
+		err = fmt.Errorf("parse form fail: %v", err)
+		s.buildFailureResp(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	diskPath := r.FormValue(paramDisk)
+	device, err := s.getDiskDevice(diskPath)
+	if err != nil {
+		s.buildFailureResp(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// smartctl exits with non-zero bits if the disk is failing, the output is still returned
+	output, err := runDiskCmd(s.diskSmartCmd, device)
+	if err != nil && output == "" {
+		log.LogErrorf("[getDiskSmart] disk(%v) device(%v) err(%v)", diskPath, device, err)
+		s.buildFailureResp(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.buildSuccessResp(w, &DiskSmartInfo{Disk: diskPath, Device: device, Output: output})
+}
@@ -0,0 +1,31 @@
+package datanode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildDiskCmd(t *testing.T) {
+	args, err := buildDiskCmd(DefaultDiskLocateCmd, "/dev/sdb")
+	require.NoError(t, err)
+	require.Equal(t, []string{"ledctl", "locate=/dev/sdb"}, args)
+
+	args, err = buildDiskCmd("sg_ses --dev-slot-num=3 --set=ident  {dev}", "/dev/sg2")
+	require.NoError(t, err)
+	require.Equal(t, []string{"sg_ses", "--dev-slot-num=3", "--set=ident", "/dev/sg2"}, args)
+
+	// device with shell meta characters is passed as one arg
+	args, err = buildDiskCmd(DefaultDiskSmartCmd, "/dev/sdb;reboot")
+	require.NoError(t, err)
+	require.Equal(t, []string{"smartctl", "-A", "/dev/sdb;reboot"}, args)
+
+	_, err = buildDiskCmd("smartctl -A", "/dev/sdb")
+	require.Error(t, err)
+
+	output, err := runDiskCmd("echo {dev}", "/dev/sdb")
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sdb\n", output)
+	_, err = runDiskCmd("false {dev}", "/dev/sdb")
+	require.Error(t, err)
+}
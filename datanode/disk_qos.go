@@ -0,0 +1,119 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"sync/atomic"
+
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	DiskQosConfigFileName     = "diskQosConfig"
+	TempDiskQosConfigFileName = ".diskQosConfig"
+)
+
+// diskQosConfig is the qos config set on one disk, which is persisted in the root of the disk,
+// the weights of this disk override weights of datanode if positive.
+type diskQosConfig struct {
+	WriteTinyWeight   int `json:"writeTinyWeight"`
+	WriteNormalWeight int `json:"writeNormalWeight"`
+}
+
+func (d *Disk) getQosConfig() diskQosConfig {
+	return diskQosConfig{
+		WriteTinyWeight:   int(atomic.LoadInt32(&d.writeTinyWeight)),
+		WriteNormalWeight: int(atomic.LoadInt32(&d.writeNormalWeight)),
+	}
+}
+
+func (d *Disk) storeQosConfig(cfg diskQosConfig) {
+	atomic.StoreInt32(&d.writeTinyWeight, int32(cfg.WriteTinyWeight))
+	atomic.StoreInt32(&d.writeNormalWeight, int32(cfg.WriteNormalWeight))
+}
+
+// loadQosConfig loads the qos config of the disk, it's empty if never set.
+func (d *Disk) loadQosConfig() (err error) {
+	data, err := os.ReadFile(path.Join(d.Path, DiskQosConfigFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	cfg := diskQosConfig{}
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return
+	}
+	d.storeQosConfig(cfg)
+	log.LogInfof("action[loadQosConfig] disk(%v) qos config %s", d.Path, data)
+	return
+}
+
+// updateQosConfig updates and persists the qos config of the disk, then the write weights take
+// effect, the config in memory is not changed if failed to persist.
+func (d *Disk) updateQosConfig(update func(cfg *diskQosConfig)) (err error) {
+	d.qosConfigLock.Lock()
+	defer d.qosConfigLock.Unlock()
+	cfg := d.getQosConfig()
+	update(&cfg)
+	if err = d.persistQosConfig(cfg); err != nil {
+		log.LogErrorf("action[updateQosConfig] disk(%v) persist qos config failed: %v", d.Path, err)
+		return
+	}
+	d.storeQosConfig(cfg)
+	d.limitWrite.ResetWeight(d.writeWeights())
+	return
+}
+
+func (d *Disk) persistQosConfig(cfg diskQosConfig) (err error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return
+	}
+	fileName := path.Join(d.Path, TempDiskQosConfigFileName)
+	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o666)
+	if err != nil {
+		return
+	}
+	defer func() {
+		file.Close()
+		os.Remove(fileName)
+	}()
+	if _, err = file.Write(data); err != nil {
+		return
+	}
+	// sync before rename, or a torn config file may be renamed after power loss
+	if err = file.Sync(); err != nil {
+		return
+	}
+	return os.Rename(fileName, path.Join(d.Path, DiskQosConfigFileName))
+}
+
+// writeWeights returns weights of scheduling write io between tiny extents and normal extents.
+func (d *Disk) writeWeights() (tinyWeight, normalWeight int) {
+	tinyWeight, normalWeight = d.dataNode.diskWriteTinyWeight, d.dataNode.diskWriteNormalWeight
+	cfg := d.getQosConfig()
+	if cfg.WriteTinyWeight > 0 {
+		tinyWeight = cfg.WriteTinyWeight
+	}
+	if cfg.WriteNormalWeight > 0 {
+		normalWeight = cfg.WriteNormalWeight
+	}
+	return
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"os"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newDiskForQosTest(t *testing.T, diskPath string, dn *DataNode) *Disk {
+	d := &Disk{Path: diskPath, dataNode: dn}
+	d.limitWrite = newIOLimiter(-1, 2)
+	require.NoError(t, d.loadQosConfig())
+	d.limitWrite.ResetWeight(d.writeWeights())
+	return d
+}
+
+func TestDiskQosConfig(t *testing.T) {
+	diskPath := t.TempDir()
+	dn := &DataNode{diskWriteTinyWeight: 2, diskWriteNormalWeight: 5}
+
+	d := newDiskForQosTest(t, diskPath, dn)
+	defer d.limitWrite.Close()
+	tinyWeight, normalWeight := d.writeWeights()
+	require.Equal(t, 2, tinyWeight)
+	require.Equal(t, 5, normalWeight)
+
+	require.NoError(t, d.updateQosConfig(func(cfg *diskQosConfig) { cfg.WriteTinyWeight = 3 }))
+	st := d.limitWrite.Status()
+	require.Equal(t, 3, st.TinyWeight)
+	require.Equal(t, 5, st.NormalWeight)
+	_, err := os.Stat(path.Join(diskPath, TempDiskQosConfigFileName))
+	require.True(t, os.IsNotExist(err))
+
+	// the weights of the disk are loaded after restart
+	restarted := newDiskForQosTest(t, diskPath, dn)
+	defer restarted.limitWrite.Close()
+	require.Equal(t, diskQosConfig{WriteTinyWeight: 3}, restarted.getQosConfig())
+	st = restarted.limitWrite.Status()
+	require.Equal(t, 3, st.TinyWeight)
+	require.Equal(t, 5, st.NormalWeight)
+
+	// reset to the weights of datanode
+	require.NoError(t, restarted.updateQosConfig(func(cfg *diskQosConfig) { cfg.WriteTinyWeight = 0 }))
+	st = restarted.limitWrite.Status()
+	require.Equal(t, 2, st.TinyWeight)
+
+	// the config in memory is not changed if failed to persist
+	broken := &Disk{Path: path.Join(diskPath, "notExist"), dataNode: dn, limitWrite: newIOLimiter(-1, 2)}
+	defer broken.limitWrite.Close()
+	require.Error(t, broken.updateQosConfig(func(cfg *diskQosConfig) { cfg.WriteTinyWeight = 4 }))
+	require.Equal(t, diskQosConfig{}, broken.getQosConfig())
+}
+
+func TestDiskQosConfigConcurrent(t *testing.T) {
+	dn := &DataNode{diskWriteTinyWeight: 1, diskWriteNormalWeight: 1}
+	d := newDiskForQosTest(t, t.TempDir(), dn)
+	defer d.limitWrite.Close()
+
+	var wg sync.WaitGroup
+	for ii := 1; ii <= 8; ii++ {
+		weight := ii
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, d.updateQosConfig(func(cfg *diskQosConfig) { cfg.WriteTinyWeight = weight }))
+		}()
+		go func() {
+			defer wg.Done()
+			d.limitWrite.ResetIO(weight)
+		}()
+		go func() {
+			defer wg.Done()
+			d.writeWeights()
+			d.limitWrite.RunTiny(0, func() {})
+		}()
+	}
+	wg.Wait()
+
+	// the last weight persisted takes effect and is kept by resetting io concurrency
+	tinyWeight, _ := d.writeWeights()
+	require.Equal(t, tinyWeight, d.limitWrite.Status().TinyWeight)
+	restarted := newDiskForQosTest(t, d.Path, dn)
+	defer restarted.limitWrite.Close()
+	require.Equal(t, d.getQosConfig(), restarted.getQosConfig())
+}
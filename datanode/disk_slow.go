@@ -0,0 +1,270 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/repl"
+	"github.com/cubefs/cubefs/util/exporter"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	MetricSlowDisk = "slowDisk"
+
+	defaultSlowDiskCheckInterval = time.Minute
+	defaultSlowDiskLatencyRatio  = 3
+	defaultSlowDiskMinLatencyMs  = 50
+	defaultSlowDiskRounds        = 3
+	// the disks with less io in a round are not compared
+	slowDiskMinSamples = 100
+	// the median of fewer disks is meaningless
+	slowDiskMinDisks = 3
+)
+
+// diskLatency records the elapsed time of io on a disk in the current round of slow disk detection.
+type diskLatency struct {
+	sum   int64 // nanoseconds
+	count int64
+
+	slow       int32
+	slowRounds int // continuous rounds of outlier
+	fastRounds int // continuous rounds of non-outlier since slow
+	lastAvg    int64
+}
+
+func (l *diskLatency) observe(elapsed time.Duration) {
+	atomic.AddInt64(&l.sum, int64(elapsed))
+	atomic.AddInt64(&l.count, 1)
+}
+
+// reset returns the average latency and io count of the round, and starts a new round
+func (l *diskLatency) reset() (avg time.Duration, count int64) {
+	sum := atomic.SwapInt64(&l.sum, 0)
+	count = atomic.SwapInt64(&l.count, 0)
+	if count > 0 {
+		avg = time.Duration(sum / count)
+	}
+	return
+}
+
+func (l *diskLatency) isSlow() bool {
+	return atomic.LoadInt32(&l.slow) == 1
+}
+
+// update updates the status with the result of a round, the disk becomes slow after continuous
+// rounds of outlier and becomes normal after the same rounds of non-outlier.
+func (l *diskLatency) update(outlier bool, rounds int) (changed bool) {
+	if outlier {
+		l.slowRounds++
+		l.fastRounds = 0
+	} else {
+		l.slowRounds = 0
+		l.fastRounds++
+	}
+	if !l.isSlow() && l.slowRounds >= rounds {
+		atomic.StoreInt32(&l.slow, 1)
+		return true
+	}
+	if l.isSlow() && l.fastRounds >= rounds {
+		atomic.StoreInt32(&l.slow, 0)
+		return true
+	}
+	return false
+}
+
+// IsSlow returns true if the disk is a persistent latency outlier of the node
+func (d *Disk) IsSlow() bool {
+	return d.latency.isSlow()
+}
+
+// AvgLatency returns the average io latency of the disk in the last round of slow disk detection
+func (d *Disk) AvgLatency() time.Duration {
+	return time.Duration(atomic.LoadInt64(&d.latency.lastAvg))
+}
+
+func (d *Disk) leaderPartitions() (dps []*DataPartition) {
+	d.RLock()
+	defer d.RUnlock()
+	for _, dp := range d.partitionMap {
+		if _, ok := dp.IsRaftLeader(); ok {
+			dps = append(dps, dp)
+		}
+	}
+	return
+}
+
+// transferLeaders asks the followers of the partitions led on the slow disk to be the raft leader,
+// so that the slow disk is deprioritized for the leader, which serves all the writes of partition.
+func (d *Disk) transferLeaders() {
+	for _, dp := range d.leaderPartitions() {
+		for _, addr := range dp.getReplicaCopy() {
+			if addr == d.dataNode.localServerAddr {
+				continue
+			}
+			if err := dp.askToBeLeader(addr); err != nil {
+				log.LogWarnf("[transferLeaders] disk(%v) dp(%v) ask %v to be leader failed: %v", d.Path, dp.partitionID, addr, err)
+				continue
+			}
+			log.LogInfof("[transferLeaders] disk(%v) dp(%v) ask %v to be leader", d.Path, dp.partitionID, addr)
+			break
+		}
+	}
+}
+
+func (dp *DataPartition) askToBeLeader(target string) (err error) {
+	p := new(repl.Packet)
+	p.Opcode = proto.OpDataPartitionTryToLeader
+	p.PartitionID = dp.partitionID
+	p.Magic = proto.ProtoMagic
+	p.ReqID = proto.GenerateRequestID()
+
+	conn, err := gConnPool.GetConnect(target)
+	if err != nil {
+		return
+	}
+	defer func() {
+		gConnPool.PutConnect(conn, err != nil)
+	}()
+	if err = p.WriteToConn(conn); err != nil {
+		return
+	}
+	if err = p.ReadFromConnWithVer(conn, proto.ReadDeadlineTime); err != nil {
+		return
+	}
+	if p.ResultCode != proto.OpOk {
+		err = fmt.Errorf("result code %v: %v", p.ResultCode, string(p.Data))
+	}
+	return
+}
+
+// slowDiskDetector compares the average io latency of every disk with the median of the node,
+// the disk is a outlier if its latency is more than ratio times of median and minLatency.
+type slowDiskDetector struct {
+	ratio      float64
+	minLatency time.Duration
+	rounds     int
+	metric     *exporter.GaugeVec
+}
+
+func newSlowDiskDetector(ratio float64, minLatencyMs int, rounds int) *slowDiskDetector {
+	if ratio <= 1 {
+		ratio = defaultSlowDiskLatencyRatio
+	}
+	if minLatencyMs <= 0 {
+		minLatencyMs = defaultSlowDiskMinLatencyMs
+	}
+	if rounds <= 0 {
+		rounds = defaultSlowDiskRounds
+	}
+	return &slowDiskDetector{
+		ratio:      ratio,
+		minLatency: time.Duration(minLatencyMs) * time.Millisecond,
+		rounds:     rounds,
+		metric:     exporter.NewGaugeVec(MetricSlowDisk, "", []string{exporter.Disk}),
+	}
+}
+
+func medianLatency(latencies []time.Duration) time.Duration {
+	sorted := append([]time.Duration{}, latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// outliers returns the indexes of latencies which are outliers of the median
+func (sd *slowDiskDetector) outliers(latencies []time.Duration) (idxes []int) {
+	if len(latencies) < slowDiskMinDisks {
+		return
+	}
+	threshold := time.Duration(float64(medianLatency(latencies)) * sd.ratio)
+	if threshold < sd.minLatency {
+		threshold = sd.minLatency
+	}
+	for i, latency := range latencies {
+		if latency > threshold {
+			idxes = append(idxes, i)
+		}
+	}
+	return
+}
+
+func (sd *slowDiskDetector) detect(disks []*Disk) {
+	var (
+		sampled   []*Disk
+		latencies []time.Duration
+	)
+	for _, d := range disks {
+		avg, count := d.latency.reset()
+		atomic.StoreInt64(&d.latency.lastAvg, int64(avg))
+		if count < slowDiskMinSamples || d.Status == proto.Unavailable {
+			continue
+		}
+		sampled = append(sampled, d)
+		latencies = append(latencies, avg)
+	}
+
+	outliers := make(map[*Disk]bool)
+	for _, idx := range sd.outliers(latencies) {
+		outliers[sampled[idx]] = true
+	}
+	for _, d := range sampled {
+		if d.latency.update(outliers[d], sd.rounds) {
+			var msg string
+			if d.IsSlow() {
+				msg = fmt.Sprintf("disk path %v on %v is slow, average latency %v, median of node %v",
+					d.Path, LocalIP, d.AvgLatency(), medianLatency(latencies))
+			} else {
+				msg = fmt.Sprintf("disk path %v on %v recovers from slow, average latency %v", d.Path, LocalIP, d.AvgLatency())
+			}
+			log.LogWarn(msg)
+			exporter.Warning(msg)
+		}
+		if d.IsSlow() {
+			// transfer the leaders in every round, the partitions may elect it again
+			d.transferLeaders()
+		}
+	}
+	for _, d := range disks {
+		var slow float64
+		if d.IsSlow() {
+			slow = 1
+		}
+		sd.metric.SetWithLabelValues(slow, d.Path)
+	}
+}
+
+func (manager *SpaceManager) startSlowDiskDetector() {
+	ticker := time.NewTicker(defaultSlowDiskCheckInterval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				manager.slowDiskDetector.detect(manager.GetDisks())
+			case <-manager.stopC:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
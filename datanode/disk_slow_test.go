@@ -0,0 +1,73 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlowDiskOutliers(t *testing.T) {
+	ms := time.Millisecond
+	require.Equal(t, 2*ms, medianLatency([]time.Duration{3 * ms, 1 * ms, 2 * ms}))
+	require.Equal(t, 3*ms, medianLatency([]time.Duration{4 * ms, 1 * ms, 2 * ms, 5 * ms}))
+
+	sd := newSlowDiskDetector(0, 0, 0)
+	require.Equal(t, float64(defaultSlowDiskLatencyRatio), sd.ratio)
+	require.Equal(t, defaultSlowDiskRounds, sd.rounds)
+
+	// too few disks to compare
+	require.Empty(t, sd.outliers([]time.Duration{ms, time.Second}))
+	// under the min latency
+	require.Empty(t, sd.outliers([]time.Duration{ms, ms, 40 * ms}))
+	require.Equal(t, []int{2}, sd.outliers([]time.Duration{10 * ms, 12 * ms, 200 * ms, 11 * ms}))
+	require.Empty(t, sd.outliers([]time.Duration{100 * ms, 120 * ms, 200 * ms, 110 * ms}))
+}
+
+func TestDiskLatencyUpdate(t *testing.T) {
+	l := &diskLatency{}
+	for i := 0; i < 10; i++ {
+		l.observe(time.Duration(i+1) * time.Millisecond)
+	}
+	avg, count := l.reset()
+	require.Equal(t, int64(10), count)
+	require.Equal(t, 5500*time.Microsecond, avg)
+	avg, count = l.reset()
+	require.Zero(t, count)
+	require.Zero(t, avg)
+
+	require.False(t, l.update(true, 2))
+	require.False(t, l.update(false, 2))
+	require.False(t, l.update(true, 2))
+	require.True(t, l.update(true, 2))
+	require.True(t, l.isSlow())
+	require.False(t, l.update(true, 2))
+	require.False(t, l.update(false, 2))
+	require.True(t, l.update(false, 2))
+	require.False(t, l.isSlow())
+}
+
+func TestLimitIOObserve(t *testing.T) {
+	l := &diskLatency{}
+	limiter := newIOLimiter(-1, 2)
+	defer limiter.Close()
+	limiter.observe = l.observe
+	limiter.Run(0, func() { time.Sleep(time.Millisecond) })
+	require.True(t, limiter.TryRun(0, func() {}))
+	_, count := l.reset()
+	require.Equal(t, int64(2), count)
+}
@@ -27,10 +27,19 @@ import (
 
 const minusOne = ^uint32(0)
 
+const (
+	defaultTinyWeight   = 1
+	defaultNormalWeight = 1
+)
+
 type ioLimiter struct {
 	limit int
 	flow  *rate.Limiter
 	io    atomic.Value
+	// resetLock serializes swapping the io queue, so the settings of the old queue are not lost
+	resetLock sync.Mutex
+	// observe is called with the elapsed time of every io task if not nil
+	observe func(time.Duration)
 }
 
 type LimiterStatus struct {
@@ -41,6 +50,10 @@ type LimiterStatus struct {
 	IOQueue       int
 	IORunning     int
 	IOWaiting     int
+
+	IOTinyWaiting int
+	TinyWeight    int
+	NormalWeight  int
 }
 
 // flow rate limiter's burst is double limit.
@@ -51,7 +64,7 @@ func newIOLimiter(flowLimit, ioConcurrency int) *ioLimiter {
 		flow = rate.NewLimiter(rate.Limit(flowLimit), 2*flowLimit)
 	}
 	l := &ioLimiter{limit: flowLimit, flow: flow}
-	l.io.Store(newIOQueue(ioConcurrency))
+	l.io.Store(newIOQueue(ioConcurrency, defaultTinyWeight, defaultNormalWeight))
 	return l
 }
 
@@ -59,6 +72,17 @@ func (l *ioLimiter) getIO() *ioQueue {
 	return l.io.Load().(*ioQueue)
 }
 
+func (l *ioLimiter) timed(taskFn func()) func() {
+	if l.observe == nil {
+		return taskFn
+	}
+	return func() {
+		start := time.Now()
+		taskFn()
+		l.observe(time.Since(start))
+	}
+}
+
 func (l *ioLimiter) ResetFlow(flowLimit int) {
 	l.limit = flowLimit
 	if flowLimit <= 0 {
@@ -71,21 +95,60 @@ func (l *ioLimiter) ResetFlow(flowLimit int) {
 }
 
 func (l *ioLimiter) ResetIO(ioConcurrency int) {
-	q := l.io.Swap(newIOQueue(ioConcurrency)).(*ioQueue)
+	l.resetLock.Lock()
+	defer l.resetLock.Unlock()
+	old := l.getIO()
+	q := l.io.Swap(newIOQueue(ioConcurrency, old.tinyWeight, old.normalWeight)).(*ioQueue)
+	q.Close()
+}
+
+// ResetWeight resets the weights of scheduling between tiny extent and normal extent io.
+func (l *ioLimiter) ResetWeight(tinyWeight, normalWeight int) {
+	if tinyWeight <= 0 {
+		tinyWeight = defaultTinyWeight
+	}
+	if normalWeight <= 0 {
+		normalWeight = defaultNormalWeight
+	}
+	l.resetLock.Lock()
+	defer l.resetLock.Unlock()
+	old := l.getIO()
+	if old.tinyWeight == tinyWeight && old.normalWeight == normalWeight {
+		return
+	}
+	q := l.io.Swap(newIOQueue(old.concurrency, tinyWeight, normalWeight)).(*ioQueue)
 	q.Close()
 }
 
 func (l *ioLimiter) Run(size int, taskFn func()) {
+	l.run(size, false, taskFn)
+}
+
+// RunTiny runs io of tiny extent, which is queued apart from normal extent io.
+func (l *ioLimiter) RunTiny(size int, taskFn func()) {
+	l.run(size, true, taskFn)
+}
+
+func (l *ioLimiter) run(size int, tiny bool, taskFn func()) {
 	if size > 0 {
 		if err := l.flow.WaitN(context.Background(), size); err != nil {
 			log.LogWarnf("action[limitio] run wait flow with %d %s", size, err.Error())
 		}
 	}
-	l.getIO().Run(taskFn)
+	l.getIO().run(tiny, l.timed(taskFn))
 }
 
 func (l *ioLimiter) TryRun(size int, taskFn func()) bool {
-	if ok := l.getIO().TryRun(taskFn); !ok {
+	return l.tryRun(size, false, taskFn)
+}
+
+// TryRunTiny tries to run io of tiny extent, which is queued apart from normal extent io.
+func (l *ioLimiter) TryRunTiny(size int, taskFn func()) bool {
+	return l.tryRun(size, true, taskFn)
+}
+
+func (l *ioLimiter) tryRun(size int, tiny bool, taskFn func()) bool {
+	if ok := l.getIO().tryRun(tiny, l.timed(taskFn)); !ok {
 		return false
 	}
 	if size > 0 {
@@ -116,7 +179,10 @@ func (l *ioLimiter) Status() (st LimiterStatus) {
 }
 
 func (l *ioLimiter) Close() {
-	q := l.io.Swap(newIOQueue(0)).(*ioQueue)
+	l.resetLock.Lock()
+	defer l.resetLock.Unlock()
+	old := l.getIO()
+	q := l.io.Swap(newIOQueue(0, old.tinyWeight, old.normalWeight)).(*ioQueue)
 	q.Close()
 }
 
@@ -125,36 +191,60 @@ type task struct {
 	done chan struct{}
 }
 
+// ioQueue runs io tasks with limited concurrency, tasks of tiny extents
+// and normal extents are queued apart and scheduled by weighted round robin,
+// so that storms of tiny extent appending can not starve normal extent io.
 type ioQueue struct {
-	wg          sync.WaitGroup
-	once        sync.Once
-	running     uint32
-	concurrency int
-	stopCh      chan struct{}
-	queue       chan *task
+	wg           sync.WaitGroup
+	once         sync.Once
+	running      uint32
+	turn         uint64
+	concurrency  int
+	tinyWeight   int
+	normalWeight int
+	stopCh       chan struct{}
+	queue        chan *task
+	tinyQueue    chan *task
 }
 
-func newIOQueue(concurrency int) *ioQueue {
-	q := &ioQueue{concurrency: concurrency}
+func newIOQueue(concurrency, tinyWeight, normalWeight int) *ioQueue {
+	if tinyWeight <= 0 {
+		tinyWeight = defaultTinyWeight
+	}
+	if normalWeight <= 0 {
+		normalWeight = defaultNormalWeight
+	}
+	q := &ioQueue{concurrency: concurrency, tinyWeight: tinyWeight, normalWeight: normalWeight}
 	if q.concurrency <= 0 {
 		return q
 	}
 
 	q.stopCh = make(chan struct{})
 	q.queue = make(chan *task, 8*concurrency)
+	q.tinyQueue = make(chan *task, 8*concurrency)
 	q.wg.Add(concurrency)
 	for ii := 0; ii < concurrency; ii++ {
 		go func() {
 			defer q.wg.Done()
 			for {
+				first, second := q.queue, q.tinyQueue
+				if q.tinyTurn() {
+					first, second = second, first
+				}
+				select {
+				case task := <-first:
+					q.runTask(task)
+					continue
+				default:
+				}
+
 				select {
 				case <-q.stopCh:
 					return
-				case task := <-q.queue:
-					atomic.AddUint32(&q.running, 1)
-					task.fn()
-					atomic.AddUint32(&q.running, minusOne)
-					close(task.done)
+				case task := <-first:
+					q.runTask(task)
+				case task := <-second:
+					q.runTask(task)
 				}
 			}
 		}()
@@ -162,7 +252,31 @@ func newIOQueue(concurrency int) *ioQueue {
 	return q
 }
 
+// tinyTurn returns true if tiny extent io takes precedence in this turn.
+func (q *ioQueue) tinyTurn() bool {
+	turn := atomic.AddUint64(&q.turn, 1)
+	return turn%uint64(q.tinyWeight+q.normalWeight) < uint64(q.tinyWeight)
+}
+
+func (q *ioQueue) runTask(task *task) {
+	atomic.AddUint32(&q.running, 1)
+	task.fn()
+	atomic.AddUint32(&q.running, minusOne)
+	close(task.done)
+}
+
+func (q *ioQueue) getQueue(tiny bool) chan *task {
+	if tiny {
+		return q.tinyQueue
+	}
+	return q.queue
+}
+
 func (q *ioQueue) Run(taskFn func()) {
+	q.run(false, taskFn)
+}
+
+func (q *ioQueue) run(tiny bool, taskFn func()) {
 	if q.concurrency <= 0 {
 		taskFn()
 		return
@@ -179,12 +293,16 @@ func (q *ioQueue) Run(taskFn func()) {
 	select {
 	case <-q.stopCh:
 		taskFn()
-	case q.queue <- task:
+	case q.getQueue(tiny) <- task:
 		<-task.done
 	}
 }
 
 func (q *ioQueue) TryRun(taskFn func()) bool {
+	return q.tryRun(false, taskFn)
+}
+
+func (q *ioQueue) tryRun(tiny bool, taskFn func()) bool {
 	if q.concurrency <= 0 {
 		taskFn()
 		return true
@@ -202,7 +320,7 @@ func (q *ioQueue) TryRun(taskFn func()) bool {
 	case <-q.stopCh:
 		taskFn()
 		return true
-	case q.queue <- task:
+	case q.getQueue(tiny) <- task:
 		<-task.done
 		return true
 	default:
@@ -215,6 +333,9 @@ func (q *ioQueue) Status() (st LimiterStatus) {
 	st.IOQueue = cap(q.queue)
 	st.IORunning = int(atomic.LoadUint32(&q.running))
 	st.IOWaiting = len(q.queue)
+	st.IOTinyWaiting = len(q.tinyQueue)
+	st.TinyWeight = q.tinyWeight
+	st.NormalWeight = q.normalWeight
 	return
 }
 
@@ -237,6 +358,10 @@ func (q *ioQueue) Close() {
 				task.fn()
 				close(task.done)
 				waitTimer.Reset(time.Minute)
+			case task := <-q.tinyQueue:
+				task.fn()
+				close(task.done)
+				waitTimer.Reset(time.Minute)
 			case <-waitTimer.C:
 				return
 			}
@@ -15,6 +15,7 @@
 package datanode
 
 import (
+	"sync"
 	"testing"
 	"time"
 
@@ -130,3 +131,52 @@ func TestLimitIOConcurrency(t *testing.T) {
 	close(done)
 	l.Close()
 }
+
+func TestLimitIOWeight(t *testing.T) {
+	l := newIOLimiter(-1, 1)
+	l.ResetWeight(1, 3)
+	st := l.Status()
+	require.Equal(t, 1, st.TinyWeight)
+	require.Equal(t, 3, st.NormalWeight)
+
+	done := make(chan struct{})
+	go l.Run(0, func() { <-done })
+	time.Sleep(100 * time.Millisecond)
+
+	var mu sync.Mutex
+	var order []bool
+	var wg sync.WaitGroup
+	for ii := 0; ii < st.IOQueue; ii++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			l.RunTiny(0, func() { mu.Lock(); order = append(order, true); mu.Unlock() })
+		}()
+		go func() {
+			defer wg.Done()
+			l.Run(0, func() { mu.Lock(); order = append(order, false); mu.Unlock() })
+		}()
+	}
+	require.Eventually(t, func() bool {
+		st := l.Status()
+		return st.IOWaiting == st.IOQueue && st.IOTinyWaiting == st.IOQueue
+	}, time.Second, 10*time.Millisecond)
+	close(done)
+	wg.Wait()
+
+	tiny := 0
+	for _, isTiny := range order[:8] {
+		if isTiny {
+			tiny++
+		}
+	}
+	require.Equal(t, 2, tiny)
+	require.Equal(t, 2*st.IOQueue, len(order))
+
+	l.ResetWeight(0, 0)
+	st = l.Status()
+	require.Equal(t, defaultTinyWeight, st.TinyWeight)
+	require.Equal(t, defaultNormalWeight, st.NormalWeight)
+	require.True(t, l.TryRunTiny(1, func() {}))
+	l.Close()
+}
@@ -74,6 +74,7 @@ type DataPartitionMetadata struct {
 	VerList                 []*proto.VolVersionInfo
 	ApplyID                 uint64
 	DiskErrCnt              uint64
+	WriteEpoch              uint64
 }
 
 func (md *DataPartitionMetadata) Validate() (err error) {
@@ -134,6 +135,10 @@ type DataPartition struct {
 	isLoadingDataPartition        int32
 	persistMetaMutex              sync.RWMutex
 
+	applyJournalLock    sync.Mutex
+	applyJournalSeq     uint64 // sequence of the last record in apply journal
+	applyJournalRecords int    // records in apply journal, rewrite it if zero or full
+
 	// snapshot
 	// verSeqPrepare              uint64
 	// verSeqCommitStatus         int8
@@ -144,6 +149,34 @@ type DataPartition struct {
 	recoverErrCnt              uint64 // donot reset, if reach max err cnt, delete this dp
 
 	diskErrCnt uint64 // number of disk io errors while reading or writing
+	writeEpoch uint64 // issued by master on the changes of the hosts, fences the writes of the stale clients
+
+	opStat *PartitionOpStat // io counters reported to master by heartbeat
+	opLog  *partitionOpLog  // sampled write history for forensics, nil if disabled
+}
+
+// OpStat returns the io statistics collected since the last heartbeat and resets them.
+func (dp *DataPartition) OpStat() proto.PartitionOpStat {
+	if dp.opStat == nil {
+		return proto.PartitionOpStat{}
+	}
+	return dp.opStat.Reset()
+}
+
+func (dp *DataPartition) recordOp(p *repl.Packet, size uint64, cost time.Duration) {
+	if dp.opStat == nil {
+		return
+	}
+	switch p.Opcode {
+	case proto.OpStreamRead, proto.OpRead, proto.OpStreamFollowerRead, proto.OpBackupRead:
+		dp.opStat.AddRead(size, cost)
+	case proto.OpWrite, proto.OpSyncWrite, proto.OpBackupWrite,
+		proto.OpRandomWrite, proto.OpSyncRandomWrite,
+		proto.OpRandomWriteAppend, proto.OpSyncRandomWriteAppend,
+		proto.OpTryWriteAppend, proto.OpSyncTryWriteAppend,
+		proto.OpRandomWriteVer, proto.OpSyncRandomWriteVer:
+		dp.opStat.AddWrite(size, cost)
+	}
 }
 
 func (dp *DataPartition) IsForbidden() bool {
@@ -166,6 +199,33 @@ func (dp *DataPartition) SetRepairBlockSize(size uint64) {
 	dp.config.DpRepairBlockSize = size
 }
 
+func (dp *DataPartition) GetWriteEpoch() uint64 {
+	return atomic.LoadUint64(&dp.writeEpoch)
+}
+
+// UpdateWriteEpoch advances the write epoch, an older epoch is ignored.
+func (dp *DataPartition) UpdateWriteEpoch(epoch uint64) (updated bool) {
+	for {
+		old := atomic.LoadUint64(&dp.writeEpoch)
+		if epoch <= old {
+			return false
+		}
+		if atomic.CompareAndSwapUint64(&dp.writeEpoch, old, epoch) {
+			return true
+		}
+	}
+}
+
+// checkWriteEpoch rejects the write with an epoch older than the partition, which comes from a
+// client writing by the hosts before the last change. The write without an epoch is allowed
+// for the compatibility with the old clients.
+func (dp *DataPartition) checkWriteEpoch(epoch uint64, hasEpoch bool) (err error) {
+	if current := dp.GetWriteEpoch(); hasEpoch && epoch < current {
+		return fmt.Errorf("%w: dp(%v) epoch(%v) write epoch(%v)", ErrStaleWriteEpoch, dp.partitionID, current, epoch)
+	}
+	return
+}
+
 func CreateDataPartition(dpCfg *dataPartitionCfg, disk *Disk, request *proto.CreateDataPartitionRequest) (dp *DataPartition, err error) {
 	if dp, err = newDataPartition(dpCfg, disk, true); err != nil {
 		return
@@ -188,6 +248,7 @@ func CreateDataPartition(dpCfg *dataPartitionCfg, disk *Disk, request *proto.Cre
 	go dp.StartRaftLoggingSchedule()
 	dp.DataPartitionCreateType = request.CreateType
 	dp.replicaNum = request.ReplicaNum
+	dp.writeEpoch = request.WriteEpoch
 	err = dp.PersistMetadata()
 	disk.AddSize(uint64(dp.Size()))
 	return
@@ -273,6 +334,8 @@ func LoadDataPartition(partitionDir string, disk *Disk) (dp *DataPartition, err
 	}
 	dp.stopRecover = meta.StopRecover
 	dp.metaAppliedID = meta.ApplyID
+	// fence the stale writes since loaded, rather than since the next heartbeat of master
+	dp.writeEpoch = meta.WriteEpoch
 	dp.computeUsage()
 	dp.ForceSetDataPartitionToLoading()
 	disk.space.AttachPartition(dp)
@@ -366,6 +429,10 @@ func newDataPartition(dpCfg *dataPartitionCfg, disk *Disk, isCreate bool) (dp *D
 		verSeq:                  dpCfg.VerSeq,
 		DataPartitionCreateType: dpCfg.CreateType,
 		volVersionInfoList:      &proto.VolVersionInfoList{},
+		opStat:                  newPartitionOpStat(),
+	}
+	if disk.dataNode != nil {
+		partition.opLog = newPartitionOpLog(disk.dataNode.opLogSampleRate, disk.dataNode.opLogCapacity)
 	}
 	atomic.StoreUint64(&partition.recoverErrCnt, 0)
 	log.LogInfof("action[newDataPartition] dp %v replica num %v", partitionID, dpCfg.ReplicaNum)
@@ -386,6 +453,9 @@ func newDataPartition(dpCfg *dataPartitionCfg, disk *Disk, isCreate bool) (dp *D
 			return
 		}
 	}
+	if !isCreate {
+		partition.loadOpLog()
+	}
 	disk.AttachDataPartition(partition)
 	dp = partition
 	go partition.statusUpdateScheduler()
@@ -646,6 +716,9 @@ func (dp *DataPartition) Stop() {
 		// Close the store and raftstore.
 		dp.stopRaft()
 		dp.extentStore.Close()
+		if err := dp.persistOpLog(); err != nil {
+			log.LogWarnf("action[Stop]: dp(%v) persist op log failed: %v", dp.partitionID, err)
+		}
 		err := dp.storeAppliedID(atomic.LoadUint64(&dp.appliedID))
 		if err != nil {
 			log.LogErrorf("action[Stop]: failed to store applied index")
@@ -748,6 +821,7 @@ func (dp *DataPartition) PersistMetadata() (err error) {
 		VerList:                 dp.volVersionInfoList.VerList,
 		ApplyID:                 dp.appliedID,
 		DiskErrCnt:              atomic.LoadUint64(&dp.diskErrCnt),
+		WriteEpoch:              atomic.LoadUint64(&dp.writeEpoch),
 	}
 
 	if metaData, err = json.Marshal(md); err != nil {
@@ -757,6 +831,10 @@ func (dp *DataPartition) PersistMetadata() (err error) {
 	if _, err = metadataFile.Write(metaData); err != nil {
 		return
 	}
+	// sync before rename, or a torn metadata file may be renamed after power loss
+	if err = metadataFile.Sync(); err != nil {
+		return
+	}
 	dp.metaAppliedID = dp.appliedID
 	log.LogInfof("PersistMetadata DataPartition(%v) data(%v)", dp.partitionID, string(metaData))
 	err = os.Rename(fileName, path.Join(dp.Path(), DataPartitionMetadataFileName))
@@ -771,6 +849,9 @@ func (dp *DataPartition) statusUpdateScheduler() {
 		select {
 		case <-ticker.C:
 			dp.statusUpdate()
+			if err := dp.persistOpLog(); err != nil {
+				log.LogWarnf("[statusUpdateScheduler] dp(%v) persist op log failed: %v", dp.partitionID, err)
+			}
 			// only repair tiny extent
 			if !dp.isNormalType() {
 				dp.LaunchRepair(proto.TinyExtentType)
@@ -1218,6 +1299,19 @@ func (dp *DataPartition) getRepairConn(target string) (net.Conn, error) {
 	return dp.dataNode.getRepairConnFunc(target)
 }
 
+// getCompressedRepairConn gets a repair connection on which the source compresses the data
+// of the replies if it is in another zone.
+func (dp *DataPartition) getCompressedRepairConn(target string) (conn net.Conn, err error) {
+	if conn, err = dp.getRepairConn(target); err != nil {
+		return
+	}
+	if _, err = repl.NegotiateCompression(conn, target); err != nil {
+		dp.putRepairConn(conn, true)
+		return dp.getRepairConn(target)
+	}
+	return
+}
+
 func (dp *DataPartition) enableSmux() bool {
 	if dp.dataNode == nil {
 		return false
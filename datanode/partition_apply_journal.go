@@ -0,0 +1,194 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path"
+
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// The apply index file is the applied id in text by default. With enableApplyJournal,
+// it's a journal of fixed size records, a record is appended and synced every time the
+// applied id is stored, and the journal is compacted to one record by rename when it's full.
+// Both formats are loaded, so the flag can be turned off before downgrading the datanode.
+//
+//	+-----------+---------+-------------+-----------+
+//	| magic(4)  | seq(8)  | applyID(8)  | crc32(4)  |
+//	+-----------+---------+-------------+-----------+
+//
+// A torn write after power loss leaves a partial or mismatched record at the tail,
+// which is detected on restart and rolled back to the last valid record.
+const (
+	applyJournalMagic      uint32 = 0x4150504a // "APPJ"
+	applyJournalRecordSize        = 24
+	maxApplyJournalRecords        = 4096
+)
+
+type applyJournalRecord struct {
+	seq     uint64
+	applyID uint64
+}
+
+func encodeApplyJournalRecord(rec applyJournalRecord) []byte {
+	data := make([]byte, applyJournalRecordSize)
+	binary.BigEndian.PutUint32(data[0:4], applyJournalMagic)
+	binary.BigEndian.PutUint64(data[4:12], rec.seq)
+	binary.BigEndian.PutUint64(data[12:20], rec.applyID)
+	binary.BigEndian.PutUint32(data[20:24], crc32.ChecksumIEEE(data[:20]))
+	return data
+}
+
+func isApplyJournal(data []byte) bool {
+	return len(data) >= 4 && binary.BigEndian.Uint32(data[0:4]) == applyJournalMagic
+}
+
+// decodeApplyJournal returns the last valid record and the count of valid records,
+// records after the first invalid one are ignored.
+func decodeApplyJournal(data []byte) (last applyJournalRecord, count int, err error) {
+	for off := 0; off+applyJournalRecordSize <= len(data); off += applyJournalRecordSize {
+		rec := data[off : off+applyJournalRecordSize]
+		if binary.BigEndian.Uint32(rec[0:4]) != applyJournalMagic ||
+			binary.BigEndian.Uint32(rec[20:24]) != crc32.ChecksumIEEE(rec[:20]) {
+			break
+		}
+		seq := binary.BigEndian.Uint64(rec[4:12])
+		if count > 0 && seq != last.seq+1 {
+			break
+		}
+		last = applyJournalRecord{seq: seq, applyID: binary.BigEndian.Uint64(rec[12:20])}
+		count++
+	}
+	if count == 0 {
+		err = fmt.Errorf("no valid record in apply journal of %d bytes", len(data))
+	}
+	return
+}
+
+func (dp *DataPartition) isApplyJournalEnabled() bool {
+	return dp.dataNode != nil && dp.dataNode.enableApplyJournal
+}
+
+func (dp *DataPartition) storeAppliedID(applyIndex uint64) (err error) {
+	dp.applyJournalLock.Lock()
+	defer dp.applyJournalLock.Unlock()
+
+	if !dp.isApplyJournalEnabled() {
+		// rewrite the journal if it's enabled later
+		dp.applyJournalRecords = 0
+		return dp.storeAppliedIDInText(applyIndex)
+	}
+
+	rec := encodeApplyJournalRecord(applyJournalRecord{seq: dp.applyJournalSeq + 1, applyID: applyIndex})
+	if dp.applyJournalRecords <= 0 || dp.applyJournalRecords >= maxApplyJournalRecords {
+		if err = dp.compactApplyJournal(rec); err != nil {
+			return
+		}
+		dp.applyJournalRecords = 1
+	} else {
+		if err = dp.appendApplyJournal(rec); err != nil {
+			// rewrite the journal next time, the tail may be torn
+			dp.applyJournalRecords = 0
+			return
+		}
+		dp.applyJournalRecords++
+	}
+	dp.applyJournalSeq++
+	return
+}
+
+func (dp *DataPartition) storeAppliedIDInText(applyIndex uint64) (err error) {
+	filename := path.Join(dp.Path(), TempApplyIndexFile)
+	fp, err := os.OpenFile(filename, os.O_RDWR|os.O_APPEND|os.O_TRUNC|os.O_CREATE, 0o755)
+	if err != nil {
+		return
+	}
+	defer func() {
+		fp.Close()
+		os.Remove(filename)
+	}()
+	if _, err = fp.WriteString(fmt.Sprintf("%d", applyIndex)); err != nil {
+		return
+	}
+	fp.Sync()
+	err = os.Rename(filename, path.Join(dp.Path(), ApplyIndexFile))
+	return
+}
+
+func (dp *DataPartition) appendApplyJournal(rec []byte) (err error) {
+	fp, err := os.OpenFile(path.Join(dp.Path(), ApplyIndexFile), os.O_WRONLY|os.O_APPEND, 0o755)
+	if err != nil {
+		return
+	}
+	defer fp.Close()
+	if _, err = fp.Write(rec); err != nil {
+		return
+	}
+	return fp.Sync()
+}
+
+func (dp *DataPartition) compactApplyJournal(rec []byte) (err error) {
+	filename := path.Join(dp.Path(), TempApplyIndexFile)
+	fp, err := os.OpenFile(filename, os.O_RDWR|os.O_APPEND|os.O_TRUNC|os.O_CREATE, 0o755)
+	if err != nil {
+		return
+	}
+	defer func() {
+		fp.Close()
+		os.Remove(filename)
+	}()
+	if _, err = fp.Write(rec); err != nil {
+		return
+	}
+	if err = fp.Sync(); err != nil {
+		return
+	}
+	err = os.Rename(filename, path.Join(dp.Path(), ApplyIndexFile))
+	return
+}
+
+// loadApplyJournal loads the applied id from the journal, the torn tail is truncated.
+// The apply index file in text is also supported, it's rewritten on next store.
+func (dp *DataPartition) loadApplyJournal(filename string, data []byte) (err error) {
+	if !isApplyJournal(data) {
+		if _, err = fmt.Sscanf(string(data), "%d", &dp.appliedID); err != nil {
+			return errors.NewErrorf("[loadApplyID] ReadApplyID: %s", err.Error())
+		}
+		dp.applyJournalSeq = 0
+		dp.applyJournalRecords = 0
+		return
+	}
+
+	last, count, err := decodeApplyJournal(data)
+	if err != nil {
+		return errors.NewErrorf("[loadApplyID] dp(%v) %s", dp.partitionID, err.Error())
+	}
+	if valid := count * applyJournalRecordSize; valid != len(data) {
+		log.LogWarnf("[loadApplyID] dp(%v) torn apply journal detected, size(%v) valid(%v), roll back to seq(%v) applyID(%v)",
+			dp.partitionID, len(data), valid, last.seq, last.applyID)
+		if err = os.Truncate(filename, int64(valid)); err != nil {
+			return errors.NewErrorf("[loadApplyID] dp(%v) truncate torn apply journal: %s", dp.partitionID, err.Error())
+		}
+	}
+	dp.appliedID = last.applyID
+	dp.applyJournalSeq = last.seq
+	dp.applyJournalRecords = count
+	return
+}
@@ -0,0 +1,89 @@
+package datanode
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyJournal(t *testing.T) {
+	dir := t.TempDir()
+	filename := path.Join(dir, ApplyIndexFile)
+
+	load := func() *DataPartition {
+		data, err := os.ReadFile(filename)
+		require.NoError(t, err)
+		loaded := &DataPartition{path: dir, partitionID: 1, dataNode: &DataNode{enableApplyJournal: true}}
+		require.NoError(t, loaded.loadApplyJournal(filename, data))
+		return loaded
+	}
+
+	// legacy apply index in text
+	require.NoError(t, os.WriteFile(filename, []byte("100"), 0o755))
+	dp := load()
+	require.Equal(t, uint64(100), dp.appliedID)
+	require.Equal(t, 0, dp.applyJournalRecords)
+
+	for id := uint64(101); id <= 110; id++ {
+		require.NoError(t, dp.storeAppliedID(id))
+	}
+	loaded := load()
+	require.Equal(t, uint64(110), loaded.appliedID)
+	require.Equal(t, dp.applyJournalSeq, loaded.applyJournalSeq)
+	require.Equal(t, 10, loaded.applyJournalRecords)
+
+	// torn write of the last record
+	info, err := os.Stat(filename)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(filename, info.Size()-3))
+	loaded = load()
+	require.Equal(t, uint64(109), loaded.appliedID)
+	info, err = os.Stat(filename)
+	require.NoError(t, err)
+	require.Equal(t, int64(9*applyJournalRecordSize), info.Size())
+
+	// corrupted record rolls back to the previous one
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	data[len(data)-applyJournalRecordSize+15] ^= 0xff
+	require.NoError(t, os.WriteFile(filename, data, 0o755))
+	loaded = load()
+	require.Equal(t, uint64(108), loaded.appliedID)
+	require.Equal(t, 8, loaded.applyJournalRecords)
+
+	// continue to append after rolling back
+	require.NoError(t, loaded.storeAppliedID(111))
+	require.Equal(t, uint64(111), load().appliedID)
+
+	// compact when full
+	loaded.applyJournalRecords = maxApplyJournalRecords
+	require.NoError(t, loaded.storeAppliedID(112))
+	info, err = os.Stat(filename)
+	require.NoError(t, err)
+	require.Equal(t, int64(applyJournalRecordSize), info.Size())
+	require.Equal(t, uint64(112), load().appliedID)
+
+	// no valid record
+	require.NoError(t, os.WriteFile(filename, data[:applyJournalRecordSize-1], 0o755))
+	data, err = os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Error(t, dp.loadApplyJournal(filename, data))
+
+	// the text format is stored if the journal is disabled, e.g. before downgrading
+	loaded.dataNode.enableApplyJournal = false
+	require.NoError(t, loaded.storeAppliedID(113))
+	data, err = os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, "113", string(data))
+	require.Equal(t, uint64(113), load().appliedID)
+
+	// the journal is rewritten after enabled again
+	loaded.dataNode.enableApplyJournal = true
+	require.NoError(t, loaded.storeAppliedID(114))
+	info, err = os.Stat(filename)
+	require.NoError(t, err)
+	require.Equal(t, int64(applyJournalRecordSize), info.Size())
+	require.Equal(t, uint64(114), load().appliedID)
+}
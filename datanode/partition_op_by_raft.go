@@ -281,7 +281,11 @@ func (dp *DataPartition) ApplyRandomWrite(command []byte, raftApplyID uint64) (r
 			syncWrite = true
 		}
 
-		dp.disk.limitWrite.Run(int(opItem.size), func() {
+		run := dp.disk.limitWrite.Run
+		if storage.IsTinyExtent(uint64(opItem.extentID)) {
+			run = dp.disk.limitWrite.RunTiny
+		}
+		run(int(opItem.size), func() {
 			param := &storage.WriteParam{
 				ExtentID:      uint64(opItem.extentID),
 				Offset:        int64(opItem.offset),
@@ -0,0 +1,218 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/repl"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	OpLogFile     = "OPLOG"
+	TempOpLogFile = ".oplog"
+
+	DefaultOpLogSampleRate = 100
+	DefaultOpLogCapacity   = 1024
+)
+
+// OpLogRecord is a sampled modification of the data partition, the op log keeps the
+// recent records so that the write history can be reconstructed after data corruption.
+type OpLogRecord struct {
+	Time     int64  `json:"time"`
+	Op       string `json:"op"`
+	ExtentID uint64 `json:"extentId"`
+	Offset   int64  `json:"offset"`
+	Size     uint32 `json:"size"`
+	Crc      uint32 `json:"crc"`
+	ReqID    int64  `json:"reqId"`
+	Client   string `json:"client"`
+	Result   string `json:"result"`
+}
+
+// partitionOpLog is a ring buffer of sampled op records, one of every sampleRate ops is
+// recorded, and the oldest record is overwritten when it's full.
+type partitionOpLog struct {
+	sync.Mutex
+	sampleRate uint64
+	records    []OpLogRecord
+	next       int // position of the next record
+	full       bool
+	dirty      bool // records changed since last persist
+	opCount    uint64
+}
+
+func newPartitionOpLog(sampleRate int64, capacity int) *partitionOpLog {
+	if sampleRate <= 0 || capacity <= 0 {
+		return nil
+	}
+	return &partitionOpLog{
+		sampleRate: uint64(sampleRate),
+		records:    make([]OpLogRecord, capacity),
+	}
+}
+
+func isOpLogOp(opcode uint8) bool {
+	switch opcode {
+	case proto.OpWrite, proto.OpSyncWrite, proto.OpBackupWrite,
+		proto.OpRandomWrite, proto.OpSyncRandomWrite,
+		proto.OpRandomWriteAppend, proto.OpSyncRandomWriteAppend,
+		proto.OpTryWriteAppend, proto.OpSyncTryWriteAppend,
+		proto.OpRandomWriteVer, proto.OpSyncRandomWriteVer,
+		proto.OpMarkDelete, proto.OpSplitMarkDelete:
+		return true
+	}
+	return false
+}
+
+func (l *partitionOpLog) sample() bool {
+	return atomic.AddUint64(&l.opCount, 1)%l.sampleRate == 0
+}
+
+func (l *partitionOpLog) add(rec OpLogRecord) {
+	l.Lock()
+	defer l.Unlock()
+	l.records[l.next] = rec
+	l.next++
+	if l.next == len(l.records) {
+		l.next = 0
+		l.full = true
+	}
+	l.dirty = true
+}
+
+// list returns the records from the oldest to the newest.
+func (l *partitionOpLog) list() []OpLogRecord {
+	l.Lock()
+	defer l.Unlock()
+	if !l.full {
+		return append([]OpLogRecord{}, l.records[:l.next]...)
+	}
+	records := make([]OpLogRecord, 0, len(l.records))
+	records = append(records, l.records[l.next:]...)
+	return append(records, l.records[:l.next]...)
+}
+
+// restore fills the ring buffer with the persisted records, the oldest ones are
+// dropped if the capacity is smaller than before.
+func (l *partitionOpLog) restore(records []OpLogRecord) {
+	if len(records) > len(l.records) {
+		records = records[len(records)-len(l.records):]
+	}
+	l.Lock()
+	defer l.Unlock()
+	copy(l.records, records)
+	l.next = len(records) % len(l.records)
+	l.full = len(records) == len(l.records)
+	l.dirty = false
+}
+
+func (dp *DataPartition) recordOpLog(p *repl.Packet, size uint32, client string) {
+	if dp.opLog == nil || !isOpLogOp(p.Opcode) || !dp.opLog.sample() {
+		return
+	}
+	result := "ok"
+	if p.IsErrPacket() {
+		result = p.GetResultMsg()
+	}
+	dp.opLog.add(OpLogRecord{
+		Time:     time.Now().UnixNano(),
+		Op:       p.GetOpMsg(),
+		ExtentID: p.ExtentID,
+		Offset:   p.ExtentOffset,
+		Size:     size,
+		Crc:      p.CRC,
+		ReqID:    p.ReqID,
+		Client:   client,
+		Result:   result,
+	})
+}
+
+// OpLog returns the sampled op records of the partition from the oldest to the newest.
+func (dp *DataPartition) OpLog() []OpLogRecord {
+	if dp.opLog == nil {
+		return nil
+	}
+	return dp.opLog.list()
+}
+
+// persistOpLog writes the op log to disk if it has changed since last persist.
+func (dp *DataPartition) persistOpLog() (err error) {
+	if dp.opLog == nil {
+		return
+	}
+	dp.opLog.Lock()
+	dirty := dp.opLog.dirty
+	dp.opLog.dirty = false
+	dp.opLog.Unlock()
+	if !dirty {
+		return
+	}
+	defer func() {
+		if err != nil {
+			dp.opLog.Lock()
+			dp.opLog.dirty = true
+			dp.opLog.Unlock()
+		}
+	}()
+
+	data, err := json.Marshal(dp.opLog.list())
+	if err != nil {
+		return
+	}
+	filename := path.Join(dp.Path(), TempOpLogFile)
+	fp, err := os.OpenFile(filename, os.O_RDWR|os.O_TRUNC|os.O_CREATE, 0o755)
+	if err != nil {
+		return
+	}
+	defer func() {
+		fp.Close()
+		os.Remove(filename)
+	}()
+	if _, err = fp.Write(data); err != nil {
+		return
+	}
+	if err = fp.Sync(); err != nil {
+		return
+	}
+	return os.Rename(filename, path.Join(dp.Path(), OpLogFile))
+}
+
+// loadOpLog restores the op log persisted before restart, a broken op log is ignored.
+func (dp *DataPartition) loadOpLog() {
+	if dp.opLog == nil {
+		return
+	}
+	data, err := os.ReadFile(path.Join(dp.Path(), OpLogFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.LogWarnf("[loadOpLog] dp(%v) read op log failed: %v", dp.partitionID, err)
+		}
+		return
+	}
+	records := make([]OpLogRecord, 0)
+	if err = json.Unmarshal(data, &records); err != nil {
+		log.LogWarnf("[loadOpLog] dp(%v) unmarshal op log failed: %v", dp.partitionID, err)
+		return
+	}
+	dp.opLog.restore(records)
+}
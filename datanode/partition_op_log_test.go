@@ -0,0 +1,62 @@
+package datanode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/repl"
+)
+
+func TestPartitionOpLog(t *testing.T) {
+	dir := t.TempDir()
+	dp := &DataPartition{path: dir, partitionID: 1, opLog: newPartitionOpLog(2, 4)}
+	require.Empty(t, dp.OpLog())
+
+	for i := 1; i <= 12; i++ {
+		p := repl.NewPacket()
+		p.Opcode = proto.OpWrite
+		p.ResultCode = proto.OpOk
+		p.ExtentID = uint64(i)
+		p.ExtentOffset = int64(i * 100)
+		p.CRC = uint32(i)
+		dp.recordOpLog(p, uint32(i), "127.0.0.1:1234")
+
+		// read is not recorded
+		p.Opcode = proto.OpStreamRead
+		dp.recordOpLog(p, uint32(i), "127.0.0.1:1234")
+	}
+
+	// one of every two writes is sampled, and only the newest four are kept
+	records := dp.OpLog()
+	require.Equal(t, 4, len(records))
+	for i, rec := range records {
+		id := uint64(6 + 2*i)
+		require.Equal(t, id, rec.ExtentID)
+		require.Equal(t, int64(id*100), rec.Offset)
+		require.Equal(t, uint32(id), rec.Size)
+		require.Equal(t, uint32(id), rec.Crc)
+		require.Equal(t, "127.0.0.1:1234", rec.Client)
+		require.Equal(t, "ok", rec.Result)
+	}
+
+	require.NoError(t, dp.persistOpLog())
+	require.False(t, dp.opLog.dirty)
+
+	loaded := &DataPartition{path: dir, partitionID: 1, opLog: newPartitionOpLog(2, 4)}
+	loaded.loadOpLog()
+	require.Equal(t, records, loaded.OpLog())
+
+	// the oldest records are dropped if the capacity shrinks
+	loaded = &DataPartition{path: dir, partitionID: 1, opLog: newPartitionOpLog(2, 3)}
+	loaded.loadOpLog()
+	require.Equal(t, records[1:], loaded.OpLog())
+
+	// op log is disabled
+	require.Nil(t, newPartitionOpLog(-1, 4))
+	disabled := &DataPartition{path: dir, partitionID: 1}
+	disabled.recordOpLog(repl.NewPacket(), 0, "")
+	require.Nil(t, disabled.OpLog())
+	require.NoError(t, disabled.persistOpLog())
+}
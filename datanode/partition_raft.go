@@ -469,24 +469,6 @@ func (dp *DataPartition) removeRaftNode(req *proto.RemoveDataPartitionRaftMember
 	return
 }
 
-func (dp *DataPartition) storeAppliedID(applyIndex uint64) (err error) {
-	filename := path.Join(dp.Path(), TempApplyIndexFile)
-	fp, err := os.OpenFile(filename, os.O_RDWR|os.O_APPEND|os.O_TRUNC|os.O_CREATE, 0o755)
-	if err != nil {
-		return
-	}
-	defer func() {
-		fp.Close()
-		os.Remove(filename)
-	}()
-	if _, err = fp.WriteString(fmt.Sprintf("%d", applyIndex)); err != nil {
-		return
-	}
-	fp.Sync()
-	err = os.Rename(filename, path.Join(dp.Path(), ApplyIndexFile))
-	return
-}
-
 // LoadAppliedID loads the applied IDs to the memory.
 func (dp *DataPartition) LoadAppliedID() (err error) {
 	begin := time.Now()
@@ -506,8 +488,7 @@ func (dp *DataPartition) LoadAppliedID() (err error) {
 		err = errors.NewErrorf("[loadApplyIndex]: ApplyIndex is empty")
 		return
 	}
-	if _, err = fmt.Sscanf(string(data), "%d", &dp.appliedID); err != nil {
-		err = errors.NewErrorf("[loadApplyID] ReadApplyID: %s", err.Error())
+	if err = dp.loadApplyJournal(filename, data); err != nil {
 		return
 	}
 	dp.extentStore.ApplyId = dp.appliedID
@@ -0,0 +1,349 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/repl"
+	"github.com/cubefs/cubefs/storage"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	// the extents modified within it are not dangling, they may be written
+	// after the extent keys are collected from the meta partitions
+	defaultVerifyDanglingGraceSec = 3600
+	// the default prof port of the meta nodes to collect the extent keys
+	defaultVerifyMetaProfPort = "17220"
+	verifyCollectTimeout      = 5 * time.Minute
+	verifyReclaimBatchCount   = 512
+
+	verifyRepairRetryCount       = 8
+	verifyRepairRetryInterval    = 100 * time.Millisecond
+	verifyRepairMaxRetryInterval = 5 * time.Second
+)
+
+// ExtentVerifyReport is the result of cross-checking the normal extents of the partition
+// against the extent keys of the meta partitions and the peer replicas.
+type ExtentVerifyReport struct {
+	PartitionID       uint64              `json:"partitionID"`
+	MetaPartitions    int                 `json:"metaPartitions"`
+	LocalExtents      int                 `json:"localExtents"`
+	ReferencedExtents int                 `json:"referencedExtents"`
+	Dangling          []uint64            `json:"dangling"`    // local extents not referenced, leak the space
+	Missing           []uint64            `json:"missing"`     // referenced extents not on this replica
+	Lost              []uint64            `json:"lost"`        // referenced extents on none of the replicas
+	PeerMissing       map[string][]uint64 `json:"peerMissing"` // referenced extents not on the peer
+	PeerErrors        map[string]string   `json:"peerErrors"`
+	Reclaimed         []uint64            `json:"reclaimed"`
+	Repaired          []uint64            `json:"repaired"`
+
+	// the peer extents of the max size to repair the missing extents of this replica
+	repairSources map[uint64]*storage.ExtentInfo
+}
+
+// compareExtents compares the local and peer extents with the referenced extents, the local
+// extents modified after deadline are not dangling. The extents missing on all the peers are
+// lost only if all the peers are compared.
+func compareExtents(partitionID uint64, local []*storage.ExtentInfo, referenced []uint64,
+	peers map[string][]*storage.ExtentInfo, peerErrors map[string]string, deadline int64,
+) *ExtentVerifyReport {
+	report := &ExtentVerifyReport{
+		PartitionID:   partitionID,
+		LocalExtents:  len(local),
+		Dangling:      make([]uint64, 0),
+		Missing:       make([]uint64, 0),
+		Lost:          make([]uint64, 0),
+		PeerMissing:   make(map[string][]uint64, len(peers)),
+		PeerErrors:    peerErrors,
+		Reclaimed:     make([]uint64, 0),
+		Repaired:      make([]uint64, 0),
+		repairSources: make(map[uint64]*storage.ExtentInfo),
+	}
+
+	refs := make(map[uint64]struct{}, len(referenced))
+	for _, extentID := range referenced {
+		if !storage.IsTinyExtent(extentID) {
+			refs[extentID] = struct{}{}
+		}
+	}
+	report.ReferencedExtents = len(refs)
+
+	locals := make(map[uint64]struct{}, len(local))
+	for _, ei := range local {
+		locals[ei.FileID] = struct{}{}
+		if _, ok := refs[ei.FileID]; !ok && ei.ModifyTime < deadline {
+			report.Dangling = append(report.Dangling, ei.FileID)
+		}
+	}
+
+	peerExtents := make(map[string]map[uint64]*storage.ExtentInfo, len(peers))
+	for addr, extents := range peers {
+		m := make(map[uint64]*storage.ExtentInfo, len(extents))
+		for _, ei := range extents {
+			if !ei.IsDeleted {
+				m[ei.FileID] = ei
+			}
+		}
+		peerExtents[addr] = m
+		report.PeerMissing[addr] = make([]uint64, 0)
+	}
+
+	for extentID := range refs {
+		for addr, m := range peerExtents {
+			ei, ok := m[extentID]
+			if !ok {
+				report.PeerMissing[addr] = append(report.PeerMissing[addr], extentID)
+				continue
+			}
+			if source, has := report.repairSources[extentID]; !has || ei.TotalSize() > source.TotalSize() {
+				report.repairSources[extentID] = &storage.ExtentInfo{
+					Source: addr, FileID: extentID, Size: ei.Size, SnapshotDataOff: ei.SnapshotDataOff,
+				}
+			}
+		}
+		if _, ok := locals[extentID]; ok {
+			continue
+		}
+		report.Missing = append(report.Missing, extentID)
+		if _, ok := report.repairSources[extentID]; !ok && len(peerErrors) == 0 {
+			report.Lost = append(report.Lost, extentID)
+		}
+	}
+
+	for _, ids := range [][]uint64{report.Dangling, report.Missing, report.Lost} {
+		sortExtentIDs(ids)
+	}
+	for _, ids := range report.PeerMissing {
+		sortExtentIDs(ids)
+	}
+	return report
+}
+
+func sortExtentIDs(ids []uint64) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+}
+
+// verifyExtents cross-checks the normal extents of the partition against the extents referenced
+// by the extent keys of all the meta partitions of the volume and the peer replicas. The tiny extents
+// are shared by the files and are not checked. The dangling extents are mark deleted through the
+// leader on all the replicas if reclaim, and the missing extents are created and repaired from the
+// peer of the max size if repair.
+func (dp *DataPartition) verifyExtents(metaProfPort string, graceSec int64, reclaim, repair bool) (report *ExtentVerifyReport, err error) {
+	views, err := MasterClient.ClientAPI().GetMetaPartitions(dp.volumeID)
+	if err != nil {
+		err = errors.Trace(err, "verifyExtents get meta partitions of vol(%v)", dp.volumeID)
+		return
+	}
+	// the dangling extents are not reliable if any meta partition is not collected
+	referenced, err := collectReferencedExtents(views, metaProfPort, dp.partitionID)
+	if err != nil {
+		return
+	}
+
+	local, _, err := dp.getLocalExtentInfo(proto.NormalExtentType, nil)
+	if err != nil {
+		return
+	}
+
+	peers := make(map[string][]*storage.ExtentInfo)
+	peerErrors := make(map[string]string)
+	for _, addr := range dp.getReplicaCopy() {
+		if addr == dp.dataNode.localServerAddr {
+			continue
+		}
+		extents, opErr := dp.getRemoteExtentInfo(proto.NormalExtentType, nil, addr)
+		if opErr != nil {
+			log.LogWarnf("action[verifyExtents] dp(%v) get extents of replica(%v) err(%v)", dp.partitionID, addr, opErr)
+			peerErrors[addr] = opErr.Error()
+			continue
+		}
+		peers[addr] = extents
+	}
+
+	report = compareExtents(dp.partitionID, local, referenced, peers, peerErrors, time.Now().Unix()-graceSec)
+	report.MetaPartitions = len(views)
+	log.LogWarnf("action[verifyExtents] dp(%v) local(%v) referenced(%v) dangling(%v) missing(%v) lost(%v)",
+		dp.partitionID, report.LocalExtents, report.ReferencedExtents, len(report.Dangling), len(report.Missing), len(report.Lost))
+
+	if reclaim {
+		if err = checkReclaimDangling(report); err != nil {
+			return
+		}
+		report.Reclaimed = dp.reclaimDanglingExtents(report.Dangling)
+	}
+	if repair {
+		report.Repaired = dp.repairMissingExtents(report.Missing, report.repairSources)
+	}
+	return
+}
+
+// collectReferencedExtents collects the extents of the data partition referenced by the meta partitions
+// from their leaders, it fails if any meta partition is not collected.
+func collectReferencedExtents(views []*proto.MetaPartitionView, metaProfPort string, partitionID uint64) (referenced []uint64, err error) {
+	referenced = make([]uint64, 0)
+	client := &http.Client{Timeout: verifyCollectTimeout}
+	for _, view := range views {
+		var extents []uint64
+		if extents, err = getReferencedExtents(client, view, metaProfPort, partitionID); err != nil {
+			log.LogErrorf("action[collectReferencedExtents] dp(%v) mp(%v) leader(%v) err(%v)",
+				partitionID, view.PartitionID, view.LeaderAddr, err)
+			return nil, errors.Trace(err, "collect referenced extents from mp(%v) leader(%v)", view.PartitionID, view.LeaderAddr)
+		}
+		referenced = append(referenced, extents...)
+	}
+	return
+}
+
+func getReferencedExtents(client *http.Client, view *proto.MetaPartitionView, metaProfPort string, partitionID uint64) (extents []uint64, err error) {
+	if view.LeaderAddr == "" {
+		return nil, fmt.Errorf("no leader")
+	}
+	host, _, err := net.SplitHostPort(view.LeaderAddr)
+	if err != nil {
+		return
+	}
+	reqURL := fmt.Sprintf("http://%v/getExtentIdsByDp?pid=%v&dpId=%v", net.JoinHostPort(host, metaProfPort), view.PartitionID, partitionID)
+	resp, err := client.Get(reqURL)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	reply := struct {
+		Code int      `json:"code"`
+		Msg  string   `json:"msg"`
+		Data []uint64 `json:"data"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return nil, fmt.Errorf("status(%v) decode reply: %v", resp.StatusCode, err)
+	}
+	if reply.Code != http.StatusOK {
+		return nil, fmt.Errorf("code(%v) msg(%v)", reply.Code, reply.Msg)
+	}
+	return reply.Data, nil
+}
+
+// checkReclaimDangling refuses to reclaim if no extent is referenced, which is more likely
+// caused by the wrong meta partitions than an empty partition.
+func checkReclaimDangling(report *ExtentVerifyReport) error {
+	if report.MetaPartitions == 0 || report.ReferencedExtents == 0 {
+		return fmt.Errorf("refuse to reclaim dangling extents of dp(%v) without referenced extents from %v meta partitions",
+			report.PartitionID, report.MetaPartitions)
+	}
+	return nil
+}
+
+// reclaimDanglingExtents mark deletes the dangling extents through the leader of the partition
+// in batches, which forwards the deletion to the followers.
+func (dp *DataPartition) reclaimDanglingExtents(extents []uint64) (reclaimed []uint64) {
+	reclaimed = make([]uint64, 0, len(extents))
+	hosts := dp.getReplicaCopy()
+	if len(hosts) == 0 {
+		log.LogErrorf("action[reclaimDanglingExtents] dp(%v) no replica", dp.partitionID)
+		return
+	}
+	for start := 0; start < len(extents); start += verifyReclaimBatchCount {
+		end := start + verifyReclaimBatchCount
+		if end > len(extents) {
+			end = len(extents)
+		}
+		batch := extents[start:end]
+		if err := dp.batchDeleteExtents(hosts, batch); err != nil {
+			log.LogErrorf("action[reclaimDanglingExtents] dp(%v) mark delete extents(%v) through leader(%v) err(%v)",
+				dp.partitionID, batch, hosts[0], err)
+			return
+		}
+		log.LogWarnf("action[reclaimDanglingExtents] dp(%v) mark delete dangling extents(%v)", dp.partitionID, batch)
+		reclaimed = append(reclaimed, batch...)
+	}
+	return
+}
+
+func (dp *DataPartition) batchDeleteExtents(hosts []string, extents []uint64) (err error) {
+	exts := make([]*proto.DelExtentParam, 0, len(extents))
+	for _, extentID := range extents {
+		exts = append(exts, &proto.DelExtentParam{ExtentKey: &proto.ExtentKey{PartitionId: dp.partitionID, ExtentId: extentID}})
+	}
+	p, err := repl.NewPacketToBatchDeleteExtent(dp.partitionID, exts, hosts)
+	if err != nil {
+		return
+	}
+	conn, err := gConnPool.GetConnect(hosts[0])
+	if err != nil {
+		return
+	}
+	defer func() {
+		gConnPool.PutConnect(conn, err != nil)
+	}()
+	if err = p.WriteToConn(conn); err != nil {
+		return
+	}
+	if err = p.ReadFromConnWithVer(conn, proto.BatchDeleteExtentReadDeadLineTime); err != nil {
+		return
+	}
+	if p.ResultCode != proto.OpOk {
+		err = fmt.Errorf("%v response: %v", p.GetUniqueLogId(), p.GetResultMsg())
+	}
+	return
+}
+
+func (dp *DataPartition) repairMissingExtents(extents []uint64, sources map[uint64]*storage.ExtentInfo) (repaired []uint64) {
+	repaired = make([]uint64, 0, len(extents))
+	store := dp.ExtentStore()
+	for _, extentID := range extents {
+		source, ok := sources[extentID]
+		if !ok || store.IsDeletedNormalExtent(extentID) {
+			continue
+		}
+		dp.disk.allocCheckLimit(proto.IopsWriteType, 1)
+		if err := store.Create(extentID); err != nil {
+			log.LogWarnf("action[repairMissingExtents] dp(%v) create extent(%v) err(%v)", dp.partitionID, extentID, err)
+			continue
+		}
+		if err := dp.repairMissingExtent(source); err != nil {
+			err = errors.Trace(err, "repairMissingExtents %v from %v", dp.applyRepairKey(int(extentID)), source.Source)
+			log.LogWarnf("action[repairMissingExtents] err(%v).", err)
+			continue
+		}
+		repaired = append(repaired, extentID)
+	}
+	return
+}
+
+// repairMissingExtent retries with backoff if the source has no token to read for repair.
+func (dp *DataPartition) repairMissingExtent(source *storage.ExtentInfo) (err error) {
+	backoff := verifyRepairRetryInterval
+	for retry := 0; ; retry++ {
+		err = dp.streamRepairExtent(source, repl.NewTinyExtentRepairReadPacket, repl.NewExtentRepairReadPacket, repl.NewNormalExtentWithHoleRepairReadPacket, repl.NewPacketEx)
+		if err == nil || retry >= verifyRepairRetryCount ||
+			!strings.Contains(err.Error(), storage.NoDiskReadRepairExtentTokenError.Error()) {
+			return
+		}
+		log.LogDebugf("action[repairMissingExtent] retry dp(%v) extent(%v) after %v.", dp.partitionID, source.FileID, backoff)
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > verifyRepairMaxRetryInterval {
+			backoff = verifyRepairMaxRetryInterval
+		}
+	}
+}
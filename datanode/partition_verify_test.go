@@ -0,0 +1,126 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/storage"
+)
+
+func TestCompareExtents(t *testing.T) {
+	const deadline = 1000
+	extent := func(id, size uint64, modTime int64) *storage.ExtentInfo {
+		return &storage.ExtentInfo{FileID: id, Size: size, ModifyTime: modTime}
+	}
+	local := []*storage.ExtentInfo{
+		extent(1025, 100, 10), extent(1026, 100, 10), extent(1027, 100, deadline+1), extent(1028, 100, 10),
+	}
+	peers := map[string][]*storage.ExtentInfo{
+		"peer1": {extent(1025, 100, 10), extent(1029, 50, 10), extent(1030, 10, 10)},
+		"peer2": {extent(1025, 100, 10), extent(1029, 80, 10), {FileID: 1031, IsDeleted: true}},
+	}
+	// the tiny extents are not checked
+	referenced := []uint64{1, 1025, 1028, 1029, 1031, 1032}
+
+	report := compareExtents(1, local, referenced, peers, map[string]string{}, deadline)
+	require.Equal(t, 4, report.LocalExtents)
+	require.Equal(t, 5, report.ReferencedExtents)
+	// the extent modified after the deadline is not dangling
+	require.Equal(t, []uint64{1026}, report.Dangling)
+	require.Equal(t, []uint64{1029, 1031, 1032}, report.Missing)
+	require.Equal(t, []uint64{1031, 1032}, report.Lost)
+	require.Equal(t, []uint64{1028, 1031, 1032}, report.PeerMissing["peer1"])
+	require.Equal(t, []uint64{1028, 1031, 1032}, report.PeerMissing["peer2"])
+	// repaired from the peer of the max size
+	require.Equal(t, "peer2", report.repairSources[1029].Source)
+	require.Equal(t, uint64(80), report.repairSources[1029].Size)
+
+	// not lost if any peer is not compared
+	report = compareExtents(1, local, referenced, peers, map[string]string{"peer3": "timeout"}, deadline)
+	require.Equal(t, []uint64{1029, 1031, 1032}, report.Missing)
+	require.Empty(t, report.Lost)
+}
+
+func TestCollectReferencedExtents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/getExtentIdsByDp", r.URL.Path)
+		require.Equal(t, "10", r.FormValue("dpId"))
+		reply := map[string]interface{}{"code": http.StatusOK}
+		switch r.FormValue("pid") {
+		case "1":
+			reply["data"] = []uint64{1025, 1026}
+		case "2":
+			reply["data"] = []uint64{1, 1027}
+		default:
+			reply["code"] = http.StatusServiceUnavailable
+			reply["msg"] = "not leader"
+		}
+		data, _ := json.Marshal(reply)
+		w.Write(data)
+	}))
+	defer server.Close()
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	leader := net.JoinHostPort(host, "17210")
+	views := []*proto.MetaPartitionView{{PartitionID: 1, LeaderAddr: leader}, {PartitionID: 2, LeaderAddr: leader}}
+
+	referenced, err := collectReferencedExtents(views, port, 10)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1025, 1026, 1, 1027}, referenced)
+
+	// fails if any meta partition is not collected
+	_, err = collectReferencedExtents(append(views, &proto.MetaPartitionView{PartitionID: 3, LeaderAddr: leader}), port, 10)
+	require.ErrorContains(t, err, "not leader")
+	_, err = collectReferencedExtents(append(views, &proto.MetaPartitionView{PartitionID: 4}), port, 10)
+	require.ErrorContains(t, err, "no leader")
+	unused, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	_, unusedPort, _ := net.SplitHostPort(unused.Addr().String())
+	unused.Close()
+	_, err = collectReferencedExtents(views, unusedPort, 10)
+	require.Error(t, err)
+
+	referenced, err = collectReferencedExtents(nil, port, 10)
+	require.NoError(t, err)
+	require.Empty(t, referenced)
+}
+
+func TestCheckReclaimDangling(t *testing.T) {
+	local := []*storage.ExtentInfo{{FileID: 1025}, {FileID: 1026}}
+	// nothing referenced, e.g. the extents of the wrong partition are collected
+	report := compareExtents(1, local, []uint64{}, nil, map[string]string{}, 1000)
+	report.MetaPartitions = 2
+	require.Equal(t, []uint64{1025, 1026}, report.Dangling)
+	require.Error(t, checkReclaimDangling(report))
+
+	// only the tiny extents are referenced
+	report = compareExtents(1, local, []uint64{1, 2}, nil, map[string]string{}, 1000)
+	report.MetaPartitions = 2
+	require.Error(t, checkReclaimDangling(report))
+
+	report = compareExtents(1, local, []uint64{1025}, nil, map[string]string{}, 1000)
+	require.Error(t, checkReclaimDangling(report))
+	report.MetaPartitions = 2
+	require.NoError(t, checkReclaimDangling(report))
+	require.Equal(t, []uint64{1026}, report.Dangling)
+}
@@ -53,6 +53,7 @@ var (
 	ErrNoSpaceToCreatePartition    = errors.New("No disk space to create a data partition")
 	ErrNewSpaceManagerFailed       = errors.New("Creater new space manager failed")
 	ErrGetMasterDatanodeInfoFailed = errors.New("Failed to get datanode info from master")
+	ErrStaleWriteEpoch             = errors.New("Stale write epoch of data partition")
 
 	LocalIP   string
 	gConnPool = util.NewConnectPool()
@@ -110,6 +111,13 @@ const (
 	ConfigKeySmuxMaxBuffer     = "smuxMaxBuffer"      // int
 	ConfigKeySmuxTotalStream   = "sumxTotalStream"    // int
 
+	// compress the data of replication and repair between zones
+	ConfigKeyEnableReplCompression = "enableReplCompression" // bool
+
+	// store the applied index of data partitions in the journal with checksums,
+	// which can't be loaded by the datanodes before it
+	ConfigKeyEnableApplyJournal = "enableApplyJournal" // bool
+
 	// rate limit control enable
 	ConfigDiskQosEnable = "diskQosEnable" // bool
 	ConfigDiskReadIocc  = "diskReadIocc"  // int
@@ -119,6 +127,10 @@ const (
 	ConfigDiskWriteIops = "diskWriteIops" // int
 	ConfigDiskWriteFlow = "diskWriteFlow" // int
 
+	// weights of scheduling write io between tiny extents and normal extents
+	ConfigDiskWriteTinyWeight   = "diskWriteTinyWeight"   // int
+	ConfigDiskWriteNormalWeight = "diskWriteNormalWeight" // int
+
 	// load/stop dp limit
 	ConfigDiskCurrentLoadDpLimit = "diskCurrentLoadDpLimit"
 	ConfigDiskCurrentStopDpLimit = "diskCurrentStopDpLimit"
@@ -127,8 +139,24 @@ const (
 
 	ConfigServiceIDKey = "serviceIDKey"
 
+	// slow disk detection by latency outlier of disks
+	ConfigEnableSlowDiskDetect = "enableSlowDiskDetect" // bool
+	ConfigSlowDiskLatencyRatio = "slowDiskLatencyRatio" // float
+	ConfigSlowDiskMinLatencyMs = "slowDiskMinLatencyMs" // int
+	ConfigSlowDiskRounds       = "slowDiskRounds"       // int
+
 	// disk status becomes unavailable if disk error partition count reaches this value
 	ConfigKeyDiskUnavailablePartitionErrorCount = "diskUnavailablePartitionErrorCount"
+
+	// op log of data partition, one of every opLogSampleRate write ops is recorded,
+	// minus value turns off the op log
+	ConfigKeyOpLogSampleRate = "opLogSampleRate" // int
+	ConfigKeyOpLogCapacity   = "opLogCapacity"   // int
+
+	// commands to blink the locate LED and fetch SMART of the disk, {dev} is replaced by the device
+	ConfigKeyDiskLocateCmd    = "diskLocateCmd"    // string
+	ConfigKeyDiskLocateOffCmd = "diskLocateOffCmd" // string
+	ConfigKeyDiskSmartCmd     = "diskSmartCmd"     // string
 )
 
 const cpuSampleDuration = 1 * time.Second
@@ -163,6 +191,10 @@ type DataNode struct {
 
 	getRepairConnFunc func(target string) (net.Conn, error)
 	putRepairConnFunc func(conn net.Conn, forceClose bool)
+	// compress the data of replication and repair between zones
+	enableReplCompression bool
+	// store the applied index in the journal instead of text
+	enableApplyJournal bool
 
 	metrics        *DataNodeMetrics
 	metricsDegrade int64
@@ -179,6 +211,8 @@ type DataNode struct {
 	diskWriteIocc           int
 	diskWriteIops           int
 	diskWriteFlow           int
+	diskWriteTinyWeight     int
+	diskWriteNormalWeight   int
 	dpMaxRepairErrCnt       uint64
 	clusterUuid             string
 	clusterUuidEnable       bool
@@ -189,6 +223,13 @@ type DataNode struct {
 
 	diskUnavailablePartitionErrorCount uint64 // disk status becomes unavailable when disk error partition count reaches this value
 	started                            int32
+
+	opLogSampleRate int64 // one of every opLogSampleRate write ops is recorded in op log, minus value disables it
+	opLogCapacity   int   // max records of op log per partition
+
+	diskLocateCmd    string
+	diskLocateOffCmd string
+	diskSmartCmd     string
 }
 
 type verOp2Phase struct {
@@ -374,8 +415,32 @@ func (s *DataNode) parseConfig(cfg *config.Config) (err error) {
 	}
 	s.metricsDegrade = cfg.GetInt64(CfgMetricsDegrade)
 
+	s.enableReplCompression = cfg.GetBool(ConfigKeyEnableReplCompression)
+	repl.SetCompression(s.enableReplCompression, s.zoneName)
+	s.enableApplyJournal = cfg.GetBool(ConfigKeyEnableApplyJournal)
+
 	s.serviceIDKey = cfg.GetString(ConfigServiceIDKey)
 
+	s.opLogSampleRate = cfg.GetInt64(ConfigKeyOpLogSampleRate)
+	if s.opLogSampleRate == 0 {
+		s.opLogSampleRate = DefaultOpLogSampleRate
+	}
+	s.opLogCapacity = cfg.GetInt(ConfigKeyOpLogCapacity)
+	if s.opLogCapacity <= 0 {
+		s.opLogCapacity = DefaultOpLogCapacity
+	}
+	log.LogDebugf("action[parseConfig] load opLogSampleRate(%v) opLogCapacity(%v)", s.opLogSampleRate, s.opLogCapacity)
+
+	if s.diskLocateCmd = cfg.GetString(ConfigKeyDiskLocateCmd); s.diskLocateCmd == "" {
+		s.diskLocateCmd = DefaultDiskLocateCmd
+	}
+	if s.diskLocateOffCmd = cfg.GetString(ConfigKeyDiskLocateOffCmd); s.diskLocateOffCmd == "" {
+		s.diskLocateOffCmd = DefaultDiskLocateOffCmd
+	}
+	if s.diskSmartCmd = cfg.GetString(ConfigKeyDiskSmartCmd); s.diskSmartCmd == "" {
+		s.diskSmartCmd = DefaultDiskSmartCmd
+	}
+
 	diskUnavailablePartitionErrorCount := cfg.GetInt64(ConfigKeyDiskUnavailablePartitionErrorCount)
 	if diskUnavailablePartitionErrorCount <= 0 || diskUnavailablePartitionErrorCount > 100 {
 		diskUnavailablePartitionErrorCount = DefaultDiskUnavailablePartitionErrorCount
@@ -388,6 +453,8 @@ func (s *DataNode) parseConfig(cfg *config.Config) (err error) {
 	log.LogDebugf("action[parseConfig] load masterAddrs(%v).", MasterClient.Nodes())
 	log.LogDebugf("action[parseConfig] load port(%v).", s.port)
 	log.LogDebugf("action[parseConfig] load zoneName(%v).", s.zoneName)
+	log.LogDebugf("action[parseConfig] load enableReplCompression(%v).", s.enableReplCompression)
+	log.LogDebugf("action[parseConfig] load enableApplyJournal(%v).", s.enableApplyJournal)
 	return
 }
 
@@ -400,8 +467,11 @@ func (s *DataNode) initQosLimit(cfg *config.Config) {
 	dn.diskWriteIocc = cfg.GetInt(ConfigDiskWriteIocc)
 	dn.diskWriteIops = cfg.GetInt(ConfigDiskWriteIops)
 	dn.diskWriteFlow = cfg.GetInt(ConfigDiskWriteFlow)
-	log.LogWarnf("action[initQosLimit] set qos [%v], read(iocc:%d iops:%d flow:%d) write(iocc:%d iops:%d flow:%d)",
-		dn.diskQosEnable, dn.diskReadIocc, dn.diskReadIops, dn.diskReadFlow, dn.diskWriteIocc, dn.diskWriteIops, dn.diskWriteFlow)
+	dn.diskWriteTinyWeight = cfg.GetInt(ConfigDiskWriteTinyWeight)
+	dn.diskWriteNormalWeight = cfg.GetInt(ConfigDiskWriteNormalWeight)
+	log.LogWarnf("action[initQosLimit] set qos [%v], read(iocc:%d iops:%d flow:%d) write(iocc:%d iops:%d flow:%d weight tiny:%d normal:%d)",
+		dn.diskQosEnable, dn.diskReadIocc, dn.diskReadIops, dn.diskReadFlow, dn.diskWriteIocc, dn.diskWriteIops, dn.diskWriteFlow,
+		dn.diskWriteTinyWeight, dn.diskWriteNormalWeight)
 }
 
 func (s *DataNode) updateQosLimit() {
@@ -428,6 +498,10 @@ func (s *DataNode) newSpaceManager(cfg *config.Config) (err error) {
 	s.space.SetCurrentLoadDpLimit(loadLimit)
 	s.space.SetCurrentStopDpLimit(stopLimit)
 
+	if cfg.GetBoolWithDefault(ConfigEnableSlowDiskDetect, true) {
+		s.space.slowDiskDetector = newSlowDiskDetector(cfg.GetFloat(ConfigSlowDiskLatencyRatio),
+			cfg.GetInt(ConfigSlowDiskMinLatencyMs), cfg.GetInt(ConfigSlowDiskRounds))
+	}
 	return
 }
 
@@ -496,6 +570,9 @@ func (s *DataNode) startSpaceManager(cfg *config.Config) (err error) {
 	wg.Wait()
 	// start async sample
 	s.space.StartDiskSample()
+	if s.space.slowDiskDetector != nil {
+		s.space.startSlowDiskDetector()
+	}
 	s.updateQosLimit() // load from config
 	s.markAllDiskLoaded()
 	return nil
@@ -695,6 +772,10 @@ func (s *DataNode) registerHandler() {
 	http.HandleFunc("/markDataPartitionBroken", s.markDataPartitionBroken)
 	http.HandleFunc("/markDiskBroken", s.markDiskBroken)
 	http.HandleFunc("/getAllExtent", s.getAllExtent)
+	http.HandleFunc("/verifyExtents", s.verifyExtents)
+	http.HandleFunc("/getOpLog", s.getOpLog)
+	http.HandleFunc("/locateDisk", s.locateDisk)
+	http.HandleFunc("/getDiskSmart", s.getDiskSmart)
 }
 
 func (s *DataNode) startTCPService() (err error) {
@@ -51,6 +51,8 @@ func (s *DataNode) getDiskAPI(w http.ResponseWriter, r *http.Request) {
 			DiskRdoSize  uint64 `json:"diskRdoSize"`
 			Partitions   int    `json:"partitions"`
 			Decommission bool   `json:"decommission"`
+			Slow         bool   `json:"slow"`
+			AvgLatencyUs int64  `json:"avgLatencyUs"`
 		}{
 			Path:         diskItem.Path,
 			Total:        diskItem.Total,
@@ -63,6 +65,8 @@ func (s *DataNode) getDiskAPI(w http.ResponseWriter, r *http.Request) {
 			DiskRdoSize:  diskItem.DiskRdonlySpace,
 			Partitions:   diskItem.PartitionCount(),
 			Decommission: diskItem.GetDecommissionStatus(),
+			Slow:         diskItem.IsSlow(),
+			AvgLatencyUs: diskItem.AvgLatency().Microseconds(),
 		}
 		disks = append(disks, disk)
 	}
@@ -316,14 +320,49 @@ func (s *DataNode) setDiskQos(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// weights of write io scheduling can be set on one disk
+	if path := r.FormValue("disk"); path != "" {
+		d, err := s.space.GetDisk(path)
+		if err != nil {
+			s.buildFailureResp(w, http.StatusNotFound, err.Error())
+			return
+		}
+		tinyWeight, err, hasTiny := parser(ConfigDiskWriteTinyWeight)
+		if err != nil {
+			s.buildFailureResp(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		normalWeight, err, hasNormal := parser(ConfigDiskWriteNormalWeight)
+		if err != nil {
+			s.buildFailureResp(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		err = d.updateQosConfig(func(cfg *diskQosConfig) {
+			if hasTiny {
+				cfg.WriteTinyWeight = tinyWeight
+			}
+			if hasNormal {
+				cfg.WriteNormalWeight = normalWeight
+			}
+		})
+		if err != nil {
+			s.buildFailureResp(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.buildSuccessResp(w, "success")
+		return
+	}
+
 	updated := false
 	for key, pVal := range map[string]*int{
-		ConfigDiskReadIocc:  &s.diskReadIocc,
-		ConfigDiskReadIops:  &s.diskReadIops,
-		ConfigDiskReadFlow:  &s.diskReadFlow,
-		ConfigDiskWriteIocc: &s.diskWriteIocc,
-		ConfigDiskWriteIops: &s.diskWriteIops,
-		ConfigDiskWriteFlow: &s.diskWriteFlow,
+		ConfigDiskReadIocc:          &s.diskReadIocc,
+		ConfigDiskReadIops:          &s.diskReadIops,
+		ConfigDiskReadFlow:          &s.diskReadFlow,
+		ConfigDiskWriteIocc:         &s.diskWriteIocc,
+		ConfigDiskWriteIops:         &s.diskWriteIops,
+		ConfigDiskWriteFlow:         &s.diskWriteFlow,
+		ConfigDiskWriteTinyWeight:   &s.diskWriteTinyWeight,
+		ConfigDiskWriteNormalWeight: &s.diskWriteNormalWeight,
 	} {
 		val, err, has := parser(key)
 		if err != nil {
@@ -499,6 +538,43 @@ func (s *DataNode) getAllExtent(w http.ResponseWriter, r *http.Request) {
 	s.buildSuccessResp(w, gcExtents)
 }
 
+// verifyExtents cross-checks the extents of the partition against the extents referenced by the
+// extent keys of all the meta partitions of the volume, which are collected from the leaders of them
+// on the prof port of meta nodes.
+func (s *DataNode) verifyExtents(w http.ResponseWriter, r *http.Request) {
+	var (
+		pid          common.Uint
+		graceSec     common.Int
+		reclaim      common.Bool
+		repair       common.Bool
+		metaProfPort common.String
+	)
+	graceSec.V = defaultVerifyDanglingGraceSec
+	metaProfPort.V = defaultVerifyMetaProfPort
+	if err := parseArgs(r, pid.ID(), graceSec.Key("graceSec").OmitEmpty(),
+		reclaim.Key("reclaim").OmitEmpty(), repair.Key("repair").OmitEmpty(),
+		metaProfPort.Key("metaProfPort").OmitEmpty()); err != nil {
+		s.buildFailureResp(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if graceSec.V < 0 {
+		s.buildFailureResp(w, http.StatusBadRequest, "graceSec should not be negative")
+		return
+	}
+	partition := s.space.Partition(pid.V)
+	if partition == nil {
+		s.buildFailureResp(w, http.StatusNotFound, "partition not exist")
+		return
+	}
+
+	report, err := partition.verifyExtents(metaProfPort.V, graceSec.V, reclaim.V, repair.V)
+	if err != nil {
+		s.buildFailureResp(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.buildSuccessResp(w, report)
+}
+
 func (s *DataNode) setDiskBadAPI(w http.ResponseWriter, r *http.Request) {
 	var (
 		err      error
@@ -790,3 +866,17 @@ func (s *DataNode) loadDataPartition(w http.ResponseWriter, r *http.Request) {
 		s.buildSuccessResp(w, "success")
 	}
 }
+
+func (s *DataNode) getOpLog(w http.ResponseWriter, r *http.Request) {
+	var pid common.Uint
+	if err := parseArgs(r, pid.ID()); err != nil {
+		s.buildFailureResp(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	partition := s.space.Partition(pid.V)
+	if partition == nil {
+		s.buildFailureResp(w, http.StatusNotFound, "partition not exist")
+		return
+	}
+	s.buildSuccessResp(w, partition.OpLog())
+}
@@ -59,6 +59,7 @@ type SpaceManager struct {
 	allDisksLoaded     bool
 	dataNodeIDs        map[string]uint64
 	dataNodeIDsMutex   sync.RWMutex
+	slowDiskDetector   *slowDiskDetector
 }
 
 const diskSampleDuration = 1 * time.Second
@@ -603,6 +604,7 @@ func (s *DataNode) buildHeartBeatResponse(response *proto.DataNodeHeartbeatRespo
 			DecommissionRepairProgress: partition.decommissionRepairProgress,
 			LocalPeers:                 partition.config.Peers,
 			TriggerDiskError:           atomic.LoadUint64(&partition.diskErrCnt) > 0,
+			OpStat:                     partition.OpStat(),
 		}
 		log.LogDebugf("action[Heartbeats] dpid(%v), status(%v) total(%v) used(%v) leader(%v) isLeader(%v) TriggerDiskError(%v).",
 			vr.PartitionID, vr.PartitionStatus, vr.Total, vr.Used, leaderAddr, vr.IsLeader, vr.TriggerDiskError)
@@ -18,6 +18,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/cubefs/cubefs/proto"
 )
 
 // Stats defines various metrics that will be collected during the execution.
@@ -93,3 +95,54 @@ func (s *Stats) updateMetricLackPartitionsInDisk(lackPartitionsInDisk uint64) {
 
 	s.LackPartitionsInDisk = lackPartitionsInDisk
 }
+
+// PartitionOpStat accumulates the io counters of a data partition between two heartbeats.
+type PartitionOpStat struct {
+	readOps    uint64
+	writeOps   uint64
+	readBytes  uint64
+	writeBytes uint64
+	latencyNs  uint64
+	lastReset  int64
+	sync.Mutex
+}
+
+func newPartitionOpStat() *PartitionOpStat {
+	return &PartitionOpStat{lastReset: time.Now().Unix()}
+}
+
+// AddRead records a finished read operation.
+func (s *PartitionOpStat) AddRead(size uint64, cost time.Duration) {
+	atomic.AddUint64(&s.readOps, 1)
+	atomic.AddUint64(&s.readBytes, size)
+	atomic.AddUint64(&s.latencyNs, uint64(cost.Nanoseconds()))
+}
+
+// AddWrite records a finished write operation.
+func (s *PartitionOpStat) AddWrite(size uint64, cost time.Duration) {
+	atomic.AddUint64(&s.writeOps, 1)
+	atomic.AddUint64(&s.writeBytes, size)
+	atomic.AddUint64(&s.latencyNs, uint64(cost.Nanoseconds()))
+}
+
+// Reset returns the counters collected since the last reset and clears them.
+func (s *PartitionOpStat) Reset() (stat proto.PartitionOpStat) {
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now().Unix()
+	stat.ReadOps = atomic.SwapUint64(&s.readOps, 0)
+	stat.WriteOps = atomic.SwapUint64(&s.writeOps, 0)
+	stat.ReadBytes = atomic.SwapUint64(&s.readBytes, 0)
+	stat.WriteBytes = atomic.SwapUint64(&s.writeBytes, 0)
+	latencyNs := atomic.SwapUint64(&s.latencyNs, 0)
+	if ops := stat.ReadOps + stat.WriteOps; ops > 0 {
+		stat.AvgLatencyUs = latencyNs / ops / uint64(time.Microsecond)
+	}
+	stat.Interval = now - s.lastReset
+	if stat.Interval <= 0 {
+		stat.Interval = 1
+	}
+	s.lastReset = now
+	return
+}
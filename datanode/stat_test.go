@@ -2,6 +2,7 @@ package datanode
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -46,3 +47,22 @@ func TestStats(t *testing.T) {
 	s.updateMetricLackPartitionsInDisk(lackPartitionsInDisk)
 	require.Equal(t, lackPartitionsInDisk, s.LackPartitionsInDisk, "updateMetricLackPartitionsInDisk() error")
 }
+
+func TestPartitionOpStat(t *testing.T) {
+	s := newPartitionOpStat()
+	s.AddRead(4096, 2*time.Millisecond)
+	s.AddWrite(1024, 4*time.Millisecond)
+	s.AddWrite(1024, 6*time.Millisecond)
+
+	stat := s.Reset()
+	require.Equal(t, uint64(1), stat.ReadOps)
+	require.Equal(t, uint64(2), stat.WriteOps)
+	require.Equal(t, uint64(4096), stat.ReadBytes)
+	require.Equal(t, uint64(2048), stat.WriteBytes)
+	require.Equal(t, uint64(4000), stat.AvgLatencyUs)
+	require.True(t, stat.Interval >= 1)
+
+	stat = s.Reset()
+	require.Equal(t, uint64(0), stat.ReadOps+stat.WriteOps)
+	require.Equal(t, uint64(0), stat.AvgLatencyUs)
+}
@@ -128,6 +128,12 @@ func (s *DataNode) OperatePacket(p *repl.Packet, c net.Conn) (err error) {
 		if !shallDegrade {
 			tpObject.SetWithLabels(err, tpLabels)
 		}
+		if partition, ok := p.Object.(*DataPartition); ok && err == nil {
+			partition.recordOp(p, uint64(sz), time.Duration(time.Now().UnixNano()-start))
+		}
+		if partition, ok := p.Object.(*DataPartition); ok {
+			partition.recordOpLog(p, sz, c.RemoteAddr().String())
+		}
 	}()
 	switch p.Opcode {
 	case proto.OpCreateExtent:
@@ -501,6 +507,38 @@ func (s *DataNode) checkVolumeDpRepairBlockSize(dpRepairBlockSize map[string]uin
 	})
 }
 
+func (s *DataNode) checkVolumeFsyncPolicy(fsyncPolicy map[string]string) {
+	s.space.RangePartitions(func(partition *DataPartition) bool {
+		policy, ok := fsyncPolicy[partition.volumeID]
+		if !ok {
+			policy = proto.DefaultFsyncPolicy
+		}
+		store := partition.ExtentStore()
+		if store.FsyncPolicy() != policy {
+			log.LogInfof("[checkVolumeFsyncPolicy] volume(%v) dp(%v) fsync policy(%v) -> (%v)",
+				partition.volumeID, partition.partitionID, store.FsyncPolicy(), policy)
+			store.SetFsyncPolicy(policy)
+		}
+		return true
+	})
+}
+
+func (s *DataNode) checkDpWriteEpochs(writeEpochs map[uint64]uint64) {
+	for id, epoch := range writeEpochs {
+		partition := s.space.Partition(id)
+		if partition == nil {
+			continue
+		}
+		if !partition.UpdateWriteEpoch(epoch) {
+			continue
+		}
+		log.LogInfof("[checkDpWriteEpochs] dp(%v) write epoch updated to %v", id, epoch)
+		if err := partition.PersistMetadata(); err != nil {
+			log.LogErrorf("[checkDpWriteEpochs] dp(%v) persist write epoch %v failed: %v", id, epoch, err)
+		}
+	}
+}
+
 func (s *DataNode) checkDecommissionDisks(decommissionDisks []string) {
 	decommissionDiskSet := util.NewSet()
 	for _, disk := range decommissionDisks {
@@ -560,6 +598,8 @@ func (s *DataNode) handleHeartbeatPacket(p *repl.Packet) {
 			s.diskQosEnableFromMaster = request.EnableDiskQos
 
 			s.checkVolumeDpRepairBlockSize(request.VolDpRepairBlockSize)
+			s.checkVolumeFsyncPolicy(request.VolFsyncPolicy)
+			s.checkDpWriteEpochs(request.DpWriteEpochs)
 
 			var needUpdate bool
 			for _, pair := range []struct {
@@ -829,7 +869,7 @@ func (s *DataNode) handleWritePacket(p *repl.Packet) {
 		partition.disk.allocCheckLimit(proto.FlowWriteType, uint32(p.Size))
 		partition.disk.allocCheckLimit(proto.IopsWriteType, 1)
 
-		if writable := partition.disk.limitWrite.TryRun(int(p.Size), func() {
+		if writable := partition.disk.limitWrite.TryRunTiny(int(p.Size), func() {
 			param := &storage.WriteParam{
 				ExtentID:      p.ExtentID,
 				Offset:        p.ExtentOffset,
@@ -15,7 +15,9 @@
 package datanode
 
 import (
+	"encoding/json"
 	"os"
+	"path"
 	"testing"
 
 	"github.com/cubefs/cubefs/proto"
@@ -109,3 +111,41 @@ func TestSkipAppendWrite(t *testing.T) {
 	t.Logf("handle write packet, result code(%v)", p.ResultCode)
 	require.EqualValues(t, proto.OpArgMismatchErr, p.ResultCode)
 }
+
+func TestCheckWriteEpoch(t *testing.T) {
+	dp := &DataPartition{partitionID: 1}
+	require.NoError(t, dp.checkWriteEpoch(0, true))
+	require.NoError(t, dp.checkWriteEpoch(1, true))
+
+	require.True(t, dp.UpdateWriteEpoch(1))
+	// a client with the view before the first change of hosts
+	require.ErrorIs(t, dp.checkWriteEpoch(0, true), ErrStaleWriteEpoch)
+
+	require.True(t, dp.UpdateWriteEpoch(3))
+	require.False(t, dp.UpdateWriteEpoch(2))
+	require.False(t, dp.UpdateWriteEpoch(3))
+	require.EqualValues(t, 3, dp.GetWriteEpoch())
+
+	require.ErrorIs(t, dp.checkWriteEpoch(2, true), ErrStaleWriteEpoch)
+	require.NoError(t, dp.checkWriteEpoch(3, true))
+	require.NoError(t, dp.checkWriteEpoch(4, true))
+	// the old clients do not carry the epoch
+	require.NoError(t, dp.checkWriteEpoch(0, false))
+}
+
+func TestPersistWriteEpoch(t *testing.T) {
+	dp := &DataPartition{
+		partitionID:        1,
+		path:               t.TempDir(),
+		config:             &dataPartitionCfg{VolName: "vol", PartitionID: 1, PartitionSize: 1},
+		volVersionInfoList: &proto.VolVersionInfoList{},
+	}
+	require.True(t, dp.UpdateWriteEpoch(2))
+	require.NoError(t, dp.PersistMetadata())
+
+	data, err := os.ReadFile(path.Join(dp.Path(), DataPartitionMetadataFileName))
+	require.NoError(t, err)
+	meta := &DataPartitionMetadata{}
+	require.NoError(t, json.Unmarshal(data, meta))
+	require.EqualValues(t, 2, meta.WriteEpoch)
+}
@@ -99,6 +99,10 @@ func (s *DataNode) checkPartition(p *repl.Packet) (err error) {
 	}
 	p.Object = dp
 	if p.IsNormalWriteOperation() || p.IsCreateExtentOperation() {
+		if err = dp.checkWriteEpoch(p.WriteEpoch, p.HasWriteEpoch); err != nil {
+			log.LogWarnf("[checkPartition] reject packet(%v) err(%v)", p.GetUniqueLogId(), err)
+			return
+		}
 		if dp.Available() <= 0 {
 			log.LogErrorf("[checkPartition] dp(%v) disk no space available(%v) can write(%v)", dp.partitionID, dp.Available(), dp.disk.CanWrite())
 			err = storage.NoSpaceError
@@ -407,6 +407,7 @@ type Server struct {
 	freeNode   []fuse.NodeID
 	freeHandle []fuse.HandleID
 	nodeGen    uint64
+	session    *sessionState
 
 	// Allocated to ensure worker goroutines finish before Serve returns
 	wg sync.WaitGroup
@@ -717,7 +718,12 @@ func (s *Server) TryRestore(fs FS) error {
 		return nil
 	}
 
-	err := s.LoadFuseContext(fs, sockaddr)
+	var err error
+	if s.session != nil && s.session.loaded {
+		err = s.LoadSessionContext(fs)
+	} else {
+		err = s.LoadFuseContext(fs, sockaddr)
+	}
 	if err != nil {
 		return err
 	}
@@ -782,20 +788,9 @@ func (s *Server) LoadFuseContext(fs FS, sockaddr string) error {
 		}
 
 		if cnVersion == ContextNodeVersionV1 {
-			cn := ContextNodeFromBytes(data)
-			sn := &serveNode{inode: cn.Inode, generation: cn.Generation, refs: cn.Refs}
-			if sn.node, err = fs.Node(cn.Inode, cn.ParentIno, cn.Mode); err != nil {
-				err = fmt.Errorf("LoadFuseContext: failed to get fs.Node of %v: %v\n", sn.inode, err)
-				return err
+			if err = s.restoreNode(fs, ContextNodeFromBytes(data)); err != nil {
+				return fmt.Errorf("LoadFuseContext: %v\n", err)
 			}
-
-			for uint64(len(s.node)) < cn.NodeID {
-				freeNodeID := fuse.NodeID(len(s.node))
-				s.freeNode = append(s.freeNode, freeNodeID)
-				s.node = append(s.node, nil)
-			}
-			s.node = append(s.node, sn)
-			s.nodeRef[sn.node] = fuse.NodeID(cn.NodeID)
 		} else {
 			err = fmt.Errorf("LoadFuseContext: unrecognize nodes file version %v\n", cnVersion)
 			return err
@@ -806,7 +801,6 @@ func (s *Server) LoadFuseContext(fs FS, sockaddr string) error {
 		var (
 			data  []byte = make([]byte, unsafe.Sizeof(ContextHandle{}))
 			rsize int
-			hdl   Handle
 		)
 		rsize, err = handleListFile.Read(data)
 		if rsize == 0 || err == io.EOF {
@@ -815,31 +809,9 @@ func (s *Server) LoadFuseContext(fs FS, sockaddr string) error {
 		}
 
 		if chVersion == ContextHandleVersionV1 {
-			ch := ContextHandleFromBytes(data)
-			if ch.NodeID > uint64(len(s.node)) {
-				err = fmt.Errorf("LoadFuseContext: invalid handle(%v) len of s.node %v\n",
-					ch, len(s.node))
-				return err
+			if err = s.restoreHandle(ContextHandleFromBytes(data)); err != nil {
+				return fmt.Errorf("LoadFuseContext: %v\n", err)
 			}
-
-			sn := s.node[ch.NodeID]
-			if node, ok := sn.node.(NodeOpener); ok {
-				// create streamers for cubefs
-				if hdl, err = node.Open(context.TODO(), nil, nil); err != nil {
-					err = fmt.Errorf("LoadFuseContext: failed to open handle %v: %v\n", sn.inode, err)
-					return err
-				}
-			} else {
-				hdl = sn.node
-			}
-
-			sh := &serveHandle{handle: hdl, nodeID: fuse.NodeID(ch.NodeID)}
-			for uint64(len(s.handle)) < ch.HandleID {
-				freeHandleID := fuse.HandleID(len(s.handle))
-				s.freeHandle = append(s.freeHandle, freeHandleID)
-				s.handle = append(s.handle, nil)
-			}
-			s.handle = append(s.handle, sh)
 		} else {
 			err = fmt.Errorf("LoadFuseContext: unrecognize handles file version %v\n", chVersion)
 			return err
@@ -849,6 +821,69 @@ func (s *Server) LoadFuseContext(fs FS, sockaddr string) error {
 	return err
 }
 
+// LoadSessionContext restores the nodes and handles journaled by the session state, in the
+// order of the ids.
+func (s *Server) LoadSessionContext(fs FS) error {
+	for _, cn := range s.session.sortedNodes() {
+		if err := s.restoreNode(fs, cn); err != nil {
+			return fmt.Errorf("LoadSessionContext: %v", err)
+		}
+	}
+	for _, ch := range s.session.sortedHandles() {
+		if err := s.restoreHandle(ch); err != nil {
+			return fmt.Errorf("LoadSessionContext: %v", err)
+		}
+	}
+	return nil
+}
+
+// restoreNode restores the node of the id, the nodes are restored in the order of the ids.
+func (s *Server) restoreNode(fs FS, cn *ContextNode) (err error) {
+	sn := &serveNode{inode: cn.Inode, generation: cn.Generation, refs: cn.Refs}
+	if sn.node, err = fs.Node(cn.Inode, cn.ParentIno, cn.Mode); err != nil {
+		return fmt.Errorf("failed to get fs.Node of %v: %v", sn.inode, err)
+	}
+
+	for uint64(len(s.node)) < cn.NodeID {
+		freeNodeID := fuse.NodeID(len(s.node))
+		s.freeNode = append(s.freeNode, freeNodeID)
+		s.node = append(s.node, nil)
+	}
+	s.node = append(s.node, sn)
+	s.nodeRef[sn.node] = fuse.NodeID(cn.NodeID)
+	if cn.Generation > s.nodeGen {
+		s.nodeGen = cn.Generation
+	}
+	return nil
+}
+
+// restoreHandle opens the handle of the id again, the handles are restored in the order of the ids.
+func (s *Server) restoreHandle(ch *ContextHandle) (err error) {
+	if ch.NodeID >= uint64(len(s.node)) || s.node[ch.NodeID] == nil {
+		return fmt.Errorf("invalid handle(%v) len of s.node %v", ch, len(s.node))
+	}
+
+	var hdl Handle
+	sn := s.node[ch.NodeID]
+	if node, ok := sn.node.(NodeOpener); ok {
+		// create streamers for cubefs
+		if hdl, err = node.Open(context.TODO(), nil, nil); err != nil {
+			return fmt.Errorf("failed to open handle %v: %v", sn.inode, err)
+		}
+	} else {
+		hdl = sn.node
+	}
+
+	sh := &serveHandle{handle: hdl, nodeID: fuse.NodeID(ch.NodeID)}
+	for uint64(len(s.handle)) < ch.HandleID {
+		freeHandleID := fuse.HandleID(len(s.handle))
+		s.freeHandle = append(s.freeHandle, freeHandleID)
+		s.handle = append(s.handle, nil)
+	}
+	s.handle = append(s.handle, sh)
+	return nil
+}
+
 func (s *Server) LoadFuseDevFd(sockaddr string) (err error) {
 	var (
 		addr   *net.UnixAddr
@@ -917,20 +952,36 @@ func (s *Server) Serve(fs FS, opt *proto.MountOptions) error {
 	})
 	s.handle = append(s.handle, nil)
 
+	if opt != nil && opt.SessionStateFile != "" {
+		stat, _ := fs.State()
+		if s.session, err = openSessionState(opt.SessionStateFile, stat == FSStatRestore); err != nil {
+			return err
+		}
+	}
+
 	if err = s.TryRestore(fs); err != nil {
 		return fmt.Errorf("restore fail: %v", err)
 	}
 
 	for {
 		if s.TrySuspend(fs) {
+			s.flushSession()
 			break
 		}
 
 		req, err := s.conn.ReadRequest()
 		if err != nil {
 			if err == io.EOF {
+				// unmounted, nothing to resume
+				s.meta.Lock()
+				if s.session != nil {
+					s.session.close(true)
+					s.session = nil
+				}
+				s.meta.Unlock()
 				break
 			}
+			s.flushSession()
 			return err
 		}
 
@@ -1005,13 +1056,31 @@ type serveHandle struct {
 // without needing NodeRef.
 type NodeRef struct{}
 
-func (c *Server) saveNode(inode uint64, node Node) (id fuse.NodeID, gen uint64) {
+// flushSession writes the records of the session state buffered, before the server exits.
+func (s *Server) flushSession() {
+	s.meta.Lock()
+	session := s.session
+	s.meta.Unlock()
+	session.flush()
+}
+
+func (c *Server) saveNode(attr *fuse.Attr, node Node) (id fuse.NodeID, gen uint64) {
+	var seq uint64
 	c.meta.Lock()
-	defer c.meta.Unlock()
+	session := c.session
+	defer func() {
+		c.meta.Unlock()
+		// journaled out of the lock, before the node is replied to the kernel
+		session.sync(seq)
+	}()
 
+	inode := attr.Inode
 	if id, ok := c.nodeRef[node]; ok {
 		sn := c.node[id]
 		sn.refs++
+		if session != nil {
+			seq = session.refNode(uint64(id), sn.refs)
+		}
 		return id, sn.generation
 	}
 
@@ -1027,6 +1096,9 @@ func (c *Server) saveNode(inode uint64, node Node) (id fuse.NodeID, gen uint64)
 	}
 	sn.generation = c.nodeGen
 	c.nodeRef[node] = id
+	if session != nil {
+		seq = session.saveNode(&ContextNode{inode, attr.ParentIno, sn.generation, sn.refs, uint64(id), uint32(attr.Mode), 0})
+	}
 	return id, sn.generation
 }
 
@@ -1041,7 +1113,13 @@ func (c *Server) saveHandle(handle Handle, nodeID fuse.NodeID) (id fuse.HandleID
 		id = fuse.HandleID(len(c.handle))
 		c.handle = append(c.handle, shandle)
 	}
+	var seq uint64
+	session := c.session
+	if session != nil {
+		seq = session.saveHandle(&ContextHandle{uint64(id), uint64(nodeID)})
+	}
 	c.meta.Unlock()
+	session.sync(seq)
 	return
 }
 
@@ -1082,8 +1160,14 @@ func (c *Server) dropNode(id fuse.NodeID, n uint64) (forget bool) {
 		c.node[id] = nil
 		delete(c.nodeRef, snode.node)
 		c.freeNode = append(c.freeNode, id)
+		if c.session != nil {
+			c.session.dropNode(uint64(id))
+		}
 		return true
 	}
+	if c.session != nil {
+		c.session.refNode(uint64(id), snode.refs)
+	}
 	return false
 }
 
@@ -1091,6 +1175,9 @@ func (c *Server) dropHandle(id fuse.HandleID) {
 	c.meta.Lock()
 	c.handle[id] = nil
 	c.freeHandle = append(c.freeHandle, id)
+	if c.session != nil {
+		c.session.dropHandle(uint64(id))
+	}
 	c.meta.Unlock()
 }
 
@@ -2050,7 +2137,7 @@ func (c *Server) saveLookup(ctx context.Context, s *fuse.LookupResponse, snode *
 		s.Attr.Inode = c.dynamicInode(snode.inode, elem)
 	}
 
-	s.Node, s.Generation = c.saveNode(s.Attr.Inode, n2)
+	s.Node, s.Generation = c.saveNode(&s.Attr, n2)
 	return nil
 }
 
@@ -0,0 +1,335 @@
+// Session state of the FUSE service loop, for servers that wish to resume
+// the mount after the process crashed.
+
+package fs
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"unsafe"
+)
+
+const (
+	SessionStateVersionV1 uint32 = 1
+	SessionStateVersion   uint32 = SessionStateVersionV1
+)
+
+const (
+	sessionOpNode       uint8 = iota + 1 // the node saved or its refs changed
+	sessionOpDropNode                    // the node forgotten
+	sessionOpHandle                      // the handle opened
+	sessionOpDropHandle                  // the handle released
+)
+
+// the records are of the same size, the payload of the handles is padded
+var sessionRecordSize = 1 + int(unsafe.Sizeof(ContextNode{}))
+
+// the journal is compacted if the records are more than 4 times of the live ones
+var sessionCompactMinRecords = 1 << 16
+
+// sessionState journals the nodes and handles of the server to the local file as they
+// change, so that the server restarted after the process crashed loads the same node
+// and handle ids known by the kernel, and opens the handles again. The records are not
+// synced, they survive the crash of the process but not of the host, which loses the
+// mount anyway.
+//
+// The records are appended to the buffer under the lock of the server, and written out
+// of it by sync before the nodes and handles are replied to the kernel, the records
+// appended concurrently are written together. The drops are not waited for, a drop lost
+// by the crash only leaks the node or handle in the restarted process.
+type sessionState struct {
+	path    string
+	records int
+	loaded  bool
+	nodes   map[uint64]*ContextNode   // by node id, guarded by the lock of the server
+	handles map[uint64]*ContextHandle // by handle id, guarded by the lock of the server
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	file     *os.File
+	buf      []byte // records appended but not written
+	appended uint64 // seq of the last record appended
+	written  uint64 // seq of the last record written
+	writing  bool
+}
+
+// openSessionState opens the journal of the path, the nodes and handles of the last
+// process are loaded if load, otherwise the journal is truncated.
+func openSessionState(path string, load bool) (ss *sessionState, err error) {
+	ss = &sessionState{
+		path:    path,
+		nodes:   make(map[uint64]*ContextNode),
+		handles: make(map[uint64]*ContextHandle),
+	}
+	ss.cond = sync.NewCond(&ss.mu)
+	if load {
+		if err = ss.load(); err != nil {
+			return nil, err
+		}
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if err = ss.compactLocked(); err != nil {
+		return nil, err
+	}
+	return ss, nil
+}
+
+func (ss *sessionState) load() error {
+	file, err := os.Open(ss.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("load session state: %v", err)
+	}
+	defer file.Close()
+
+	version, err := ReadVersion(file)
+	if err != nil {
+		return fmt.Errorf("load session state: failed to read version: %v", err)
+	}
+	if version != SessionStateVersionV1 {
+		return fmt.Errorf("load session state: unrecognize version %v", version)
+	}
+
+	data := make([]byte, sessionRecordSize)
+	for {
+		// the last record torn by the crash is not replied to the kernel
+		if _, err = io.ReadFull(file, data); err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("load session state: %v", err)
+		}
+		switch payload := data[1:]; data[0] {
+		case sessionOpNode:
+			cn := ContextNodeFromBytes(payload)
+			ss.nodes[cn.NodeID] = cn
+		case sessionOpDropNode:
+			delete(ss.nodes, ContextNodeFromBytes(payload).NodeID)
+		case sessionOpHandle:
+			ch := ContextHandleFromBytes(payload)
+			ss.handles[ch.HandleID] = ch
+		case sessionOpDropHandle:
+			delete(ss.handles, ContextHandleFromBytes(payload).HandleID)
+		default:
+			return fmt.Errorf("load session state: unrecognize record %v", data[0])
+		}
+	}
+	ss.loaded = true
+	return nil
+}
+
+// compactLocked rewrites the journal with the live nodes and handles, and appends to it.
+// The records buffered are dropped, which are included by the live ones.
+func (ss *sessionState) compactLocked() (err error) {
+	for ss.writing {
+		ss.cond.Wait()
+	}
+	tmpPath := ss.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("compact session state: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if err = WriteVersion(tmp, SessionStateVersion); err != nil {
+		return fmt.Errorf("compact session state: %v", err)
+	}
+	records := 0
+	for _, cn := range ss.sortedNodes() {
+		if err = writeSessionRecord(tmp, sessionOpNode, ContextNodeToBytes(cn)); err != nil {
+			return fmt.Errorf("compact session state: %v", err)
+		}
+		records++
+	}
+	for _, ch := range ss.sortedHandles() {
+		if err = writeSessionRecord(tmp, sessionOpHandle, ContextHandleToBytes(ch)); err != nil {
+			return fmt.Errorf("compact session state: %v", err)
+		}
+		records++
+	}
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("compact session state: %v", err)
+	}
+	if err = os.Rename(tmpPath, ss.path); err != nil {
+		return fmt.Errorf("compact session state: %v", err)
+	}
+
+	if ss.file != nil {
+		ss.file.Close()
+	}
+	ss.file = tmp
+	ss.records = records
+	ss.buf = nil
+	ss.written = ss.appended
+	return nil
+}
+
+func appendSessionRecord(buf []byte, op uint8, payload []byte) []byte {
+	data := make([]byte, sessionRecordSize)
+	data[0] = op
+	copy(data[1:], payload)
+	return append(buf, data...)
+}
+
+func writeSessionRecord(w io.Writer, op uint8, payload []byte) error {
+	_, err := w.Write(appendSessionRecord(nil, op, payload))
+	return err
+}
+
+func (ss *sessionState) sortedNodes() []*ContextNode {
+	nodes := make([]*ContextNode, 0, len(ss.nodes))
+	for _, cn := range ss.nodes {
+		nodes = append(nodes, cn)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].NodeID < nodes[j].NodeID })
+	return nodes
+}
+
+func (ss *sessionState) sortedHandles() []*ContextHandle {
+	handles := make([]*ContextHandle, 0, len(ss.handles))
+	for _, ch := range ss.handles {
+		handles = append(handles, ch)
+	}
+	sort.Slice(handles, func(i, j int) bool { return handles[i].HandleID < handles[j].HandleID })
+	return handles
+}
+
+// append buffers the record, and returns the seq of it to sync.
+func (ss *sessionState) append(op uint8, payload []byte) (seq uint64) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.file == nil {
+		return 0
+	}
+	ss.buf = appendSessionRecord(ss.buf, op, payload)
+	ss.appended++
+	ss.records++
+	if live := len(ss.nodes) + len(ss.handles); ss.records > sessionCompactMinRecords && ss.records > 4*live {
+		if err := ss.compactLocked(); err != nil {
+			ss.disableLocked(err)
+			return 0
+		}
+	}
+	return ss.appended
+}
+
+// sync writes the records appended up to seq to the journal. It's called out of the lock
+// of the server, one of the callers writes the records of all.
+func (ss *sessionState) sync(seq uint64) {
+	if ss == nil {
+		return
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	for ss.file != nil && ss.written < seq {
+		if ss.writing {
+			ss.cond.Wait()
+			continue
+		}
+		ss.writeLocked()
+	}
+}
+
+// flush writes all of the records buffered.
+func (ss *sessionState) flush() {
+	if ss == nil {
+		return
+	}
+	ss.mu.Lock()
+	seq := ss.appended
+	ss.mu.Unlock()
+	ss.sync(seq)
+}
+
+// writeLocked writes the records buffered, the lock is released while writing.
+func (ss *sessionState) writeLocked() {
+	buf, seq, file := ss.buf, ss.appended, ss.file
+	ss.buf = nil
+	ss.writing = true
+	ss.mu.Unlock()
+	_, err := file.Write(buf)
+	ss.mu.Lock()
+	ss.writing = false
+	ss.cond.Broadcast()
+	if err != nil {
+		ss.disableLocked(err)
+		return
+	}
+	ss.written = seq
+}
+
+// disableLocked removes the journal, so that the stale nodes and handles are never loaded.
+func (ss *sessionState) disableLocked(err error) {
+	log.Printf("fuse: session state %v is disabled: %v", ss.path, err)
+	ss.closeLocked(true)
+}
+
+func (ss *sessionState) saveNode(cn *ContextNode) uint64 {
+	ss.nodes[cn.NodeID] = cn
+	return ss.append(sessionOpNode, ContextNodeToBytes(cn))
+}
+
+// refNode records the refs of the node saved, the root is not saved.
+func (ss *sessionState) refNode(nodeID, refs uint64) uint64 {
+	if cn, ok := ss.nodes[nodeID]; ok {
+		cn.Refs = refs
+		return ss.append(sessionOpNode, ContextNodeToBytes(cn))
+	}
+	return 0
+}
+
+func (ss *sessionState) dropNode(nodeID uint64) {
+	if _, ok := ss.nodes[nodeID]; ok {
+		delete(ss.nodes, nodeID)
+		ss.append(sessionOpDropNode, ContextNodeToBytes(&ContextNode{NodeID: nodeID}))
+	}
+}
+
+func (ss *sessionState) saveHandle(ch *ContextHandle) uint64 {
+	ss.handles[ch.HandleID] = ch
+	return ss.append(sessionOpHandle, ContextHandleToBytes(ch))
+}
+
+func (ss *sessionState) dropHandle(handleID uint64) {
+	if _, ok := ss.handles[handleID]; ok {
+		delete(ss.handles, handleID)
+		ss.append(sessionOpDropHandle, ContextHandleToBytes(&ContextHandle{HandleID: handleID}))
+	}
+}
+
+// close closes the journal, which is removed if the mount is not to resume, otherwise
+// the records buffered are written.
+func (ss *sessionState) close(remove bool) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.closeLocked(remove)
+}
+
+func (ss *sessionState) closeLocked(remove bool) {
+	for ss.writing {
+		ss.cond.Wait()
+	}
+	if !remove && ss.file != nil && len(ss.buf) > 0 {
+		ss.writeLocked()
+	}
+	if ss.file != nil {
+		ss.file.Close()
+		ss.file = nil
+	}
+	ss.cond.Broadcast()
+	if remove {
+		os.Remove(ss.path)
+	}
+}
@@ -0,0 +1,164 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func openTestSessionState(t *testing.T, path string, load bool) *sessionState {
+	t.Helper()
+	ss, err := openSessionState(path, load)
+	if err != nil {
+		t.Fatalf("open session state: %v", err)
+	}
+	return ss
+}
+
+func sessionStateFileSize(t *testing.T, path string) int64 {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat session state: %v", err)
+	}
+	return info.Size()
+}
+
+func TestSessionStateLoadTornRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session")
+	ss := openTestSessionState(t, path, false)
+	header := sessionStateFileSize(t, path)
+	ss.saveNode(&ContextNode{Inode: 10, Refs: 1, NodeID: 2})
+	seq := ss.saveHandle(&ContextHandle{HandleID: 1, NodeID: 2})
+	ss.sync(seq)
+	if size := sessionStateFileSize(t, path); size != header+2*int64(sessionRecordSize) {
+		t.Fatalf("records not written by sync: size %v", size)
+	}
+
+	// the process crashed while writing the next record
+	torn := appendSessionRecord(nil, sessionOpNode, ContextNodeToBytes(&ContextNode{Inode: 11, Refs: 1, NodeID: 3}))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.Write(torn[:len(torn)/2]); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	loaded := openTestSessionState(t, path, true)
+	defer loaded.close(true)
+	if !loaded.loaded {
+		t.Fatal("session state not loaded")
+	}
+	if want := map[uint64]*ContextNode{2: {Inode: 10, Refs: 1, NodeID: 2}}; !reflect.DeepEqual(loaded.nodes, want) {
+		t.Fatalf("nodes %v, want %v", loaded.nodes, want)
+	}
+	if want := map[uint64]*ContextHandle{1: {HandleID: 1, NodeID: 2}}; !reflect.DeepEqual(loaded.handles, want) {
+		t.Fatalf("handles %v, want %v", loaded.handles, want)
+	}
+	// the torn record is dropped by the compaction on open
+	if size := sessionStateFileSize(t, path); size != header+2*int64(sessionRecordSize) {
+		t.Fatalf("torn record not dropped: size %v", size)
+	}
+}
+
+func TestSessionStateCompact(t *testing.T) {
+	minRecords := sessionCompactMinRecords
+	sessionCompactMinRecords = 8
+	defer func() { sessionCompactMinRecords = minRecords }()
+
+	path := filepath.Join(t.TempDir(), "session")
+	ss := openTestSessionState(t, path, false)
+	header := sessionStateFileSize(t, path)
+	ss.saveNode(&ContextNode{Inode: 10, Refs: 1, NodeID: 2})
+	ss.saveHandle(&ContextHandle{HandleID: 1, NodeID: 2})
+	var seq uint64
+	for refs := uint64(2); refs <= 100; refs++ {
+		seq = ss.refNode(2, refs)
+	}
+	ss.sync(seq)
+	if ss.records > sessionCompactMinRecords+1 {
+		t.Fatalf("journal not compacted: %v records", ss.records)
+	}
+	if size := sessionStateFileSize(t, path); size != header+int64(ss.records*sessionRecordSize) {
+		t.Fatalf("size %v of %v records", size, ss.records)
+	}
+	ss.close(false)
+
+	loaded := openTestSessionState(t, path, true)
+	defer loaded.close(true)
+	if want := map[uint64]*ContextNode{2: {Inode: 10, Refs: 100, NodeID: 2}}; !reflect.DeepEqual(loaded.nodes, want) {
+		t.Fatalf("nodes %v, want %v", loaded.nodes, want)
+	}
+	if want := map[uint64]*ContextHandle{1: {HandleID: 1, NodeID: 2}}; !reflect.DeepEqual(loaded.handles, want) {
+		t.Fatalf("handles %v, want %v", loaded.handles, want)
+	}
+	if size := sessionStateFileSize(t, path); size != header+2*int64(sessionRecordSize) {
+		t.Fatalf("journal not compacted on open: size %v", size)
+	}
+}
+
+func TestSessionStateDrop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session")
+	ss := openTestSessionState(t, path, false)
+	ss.saveNode(&ContextNode{Inode: 10, Refs: 1, NodeID: 2})
+	ss.saveNode(&ContextNode{Inode: 11, Refs: 1, NodeID: 3})
+	ss.saveHandle(&ContextHandle{HandleID: 1, NodeID: 2})
+	ss.saveHandle(&ContextHandle{HandleID: 2, NodeID: 3})
+	ss.dropHandle(2)
+	ss.dropNode(3)
+	// the drops are not waited for, but written on close
+	ss.close(false)
+
+	loaded := openTestSessionState(t, path, true)
+	if want := map[uint64]*ContextNode{2: {Inode: 10, Refs: 1, NodeID: 2}}; !reflect.DeepEqual(loaded.nodes, want) {
+		t.Fatalf("nodes %v, want %v", loaded.nodes, want)
+	}
+	if want := map[uint64]*ContextHandle{1: {HandleID: 1, NodeID: 2}}; !reflect.DeepEqual(loaded.handles, want) {
+		t.Fatalf("handles %v, want %v", loaded.handles, want)
+	}
+
+	// the journal is removed if the mount is not to resume
+	loaded.close(true)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("session state not removed: %v", err)
+	}
+	if loaded = openTestSessionState(t, path, true); loaded.loaded {
+		t.Fatal("removed session state loaded")
+	}
+	loaded.close(true)
+}
+
+func TestSessionStateConcurrentSync(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session")
+	ss := openTestSessionState(t, path, false)
+	var (
+		meta sync.Mutex // the lock of the server
+		wg   sync.WaitGroup
+	)
+	for ii := 0; ii < 64; ii++ {
+		id := uint64(ii + 2)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			meta.Lock()
+			seq := ss.saveNode(&ContextNode{Inode: id, Refs: 1, NodeID: id})
+			meta.Unlock()
+			ss.sync(seq)
+		}()
+	}
+	wg.Wait()
+	if ss.written != ss.appended || len(ss.buf) != 0 {
+		t.Fatalf("written %v of %v records", ss.written, ss.appended)
+	}
+	ss.close(false)
+
+	loaded := openTestSessionState(t, path, true)
+	defer loaded.close(true)
+	if len(loaded.nodes) != 64 {
+		t.Fatalf("loaded %v nodes, want 64", len(loaded.nodes))
+	}
+}
@@ -34,6 +34,7 @@ var (
 	optDynamicUDS   = flag.Bool("n", false, "use dynamic UDS file name")
 	optSuspend      = flag.Bool("s", false, "suspend fuse")
 	optResume       = flag.Bool("r", false, "resume fuse")
+	optHold         = flag.Bool("k", false, "keep fuse fd to restart fuse client after crash")
 	optDump         = flag.String("d", "", "dump nodes/handles list files, <nodes list>,<handles list>")
 	optVersion      = flag.Bool("v", false, "show version")
 )
@@ -204,6 +205,72 @@ func doSuspend(port string) error {
 	return nil
 }
 
+func SendHoldRequest(port string, udsListener net.Listener) (err error) {
+	var (
+		req  *http.Request
+		resp *http.Response
+		data []byte
+	)
+	udsFilePath := udsListener.Addr().String()
+
+	url := fmt.Sprintf("http://%s:%s/holdfd?sock=%s", DefaultIP, port, udsFilePath)
+	if req, err = http.NewRequest("POST", url, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get new request: %v\n", err)
+		return err
+	}
+	req.Header.Set("Content-Type", "application/text")
+
+	client := http.DefaultClient
+	if resp, err = client.Do(req); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to post request: %v\n", err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if data, err = io.ReadAll(resp.Body); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read response: %v\n", err)
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		fmt.Printf("\n==> %s\n==> Status: %s\n\n", string(data), resp.Status)
+		return fmt.Errorf(resp.Status)
+	}
+	fmt.Printf("\n==> %s\n==> Could restart cfs-client after crash with -r -s %s option.\n\n",
+		string(data), udsFilePath)
+	return nil
+}
+
+// doHold keeps the fuse fd of the running client until killed, the kernel doesn't abort
+// the fuse connection while the fd is open. The fd is sent to every client restarted to
+// resume the mount, with the nodes and handles journaled by sessionStateFile.
+func doHold(port string) error {
+	udsListener, err := createUDS()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "doHold: failed to create UDS: %v\n", err)
+		return err
+	}
+	defer destroyUDS(udsListener)
+
+	if err = SendHoldRequest(port, udsListener); err != nil {
+		return err
+	}
+
+	fud, err := RecvFuseFdFromOldClient(udsListener)
+	if err != nil {
+		return err
+	}
+	defer fud.Close()
+
+	for {
+		if err = SendFuseFdToNewClient(udsListener, fud); err != nil {
+			if opErr, ok := err.(*net.OpError); ok && opErr.Op == "accept" {
+				return err
+			}
+		}
+	}
+}
+
 func SendResumeRequest(port string) (err error) {
 	var (
 		req  *http.Request
@@ -356,7 +423,7 @@ func main() {
 	}
 
 	if *optDump == "" {
-		if *optFuseHttpPort == "" || (!*optSuspend && !*optResume) {
+		if *optFuseHttpPort == "" || (!*optSuspend && !*optResume && !*optHold) {
 			flag.Usage()
 			os.Exit(-1)
 		}
@@ -372,6 +439,9 @@ func main() {
 	} else if *optResume {
 		fmt.Printf("Do Resume ...\n")
 		err = doResume(*optFuseHttpPort)
+	} else if *optHold {
+		fmt.Printf("Do Hold ...\n")
+		err = doHold(*optFuseHttpPort)
 	}
 
 	if err != nil {
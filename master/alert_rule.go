@@ -0,0 +1,369 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// metrics which can be used by alert rules, all of them are collected by master.
+const (
+	alertMetricZoneDataUsedRatio = "zone_data_used_ratio"
+	alertMetricZoneMetaUsedRatio = "zone_meta_used_ratio"
+	alertMetricDpUnavailable     = "dp_unavailable"
+	alertMetricMpUnavailable     = "mp_unavailable"
+	alertMetricDataNodeInactive  = "datanode_inactive"
+	alertMetricMetaNodeInactive  = "metanode_inactive"
+)
+
+const (
+	alertStatusFiring   = "firing"
+	alertStatusResolved = "resolved"
+
+	alertTargetMailPrefix = "mailto:"
+
+	defaultIntervalToCheckAlertRules = 60 // in terms of seconds
+	alertWebhookTimeout              = 10 * time.Second
+)
+
+// alertRule fires a notification to the target when the metric of an object
+// breaches the threshold continuously for DurationSec.
+type alertRule struct {
+	Name        string  `json:"name"`
+	Metric      string  `json:"metric"`
+	Comparator  string  `json:"comparator"` // one of >, >=, <, <=, ==, !=
+	Threshold   float64 `json:"threshold"`
+	DurationSec int64   `json:"durationSec"`
+	// Target is a webhook url, or mail addresses separated by comma with prefix "mailto:".
+	Target string `json:"target"`
+}
+
+func (rule *alertRule) validate() error {
+	if rule.Name == "" {
+		return fmt.Errorf("alert rule name is empty")
+	}
+	switch rule.Metric {
+	case alertMetricZoneDataUsedRatio, alertMetricZoneMetaUsedRatio, alertMetricDpUnavailable,
+		alertMetricMpUnavailable, alertMetricDataNodeInactive, alertMetricMetaNodeInactive:
+	default:
+		return fmt.Errorf("alert rule[%v] unknown metric[%v]", rule.Name, rule.Metric)
+	}
+	if _, err := compareAlertValue(rule.Comparator, 0, 0); err != nil {
+		return fmt.Errorf("alert rule[%v] %v", rule.Name, err)
+	}
+	if rule.DurationSec < 0 {
+		return fmt.Errorf("alert rule[%v] durationSec can't be less than 0", rule.Name)
+	}
+	if !strings.HasPrefix(rule.Target, alertTargetMailPrefix) &&
+		!strings.HasPrefix(rule.Target, "http://") && !strings.HasPrefix(rule.Target, "https://") {
+		return fmt.Errorf("alert rule[%v] invalid target[%v]", rule.Name, rule.Target)
+	}
+	return nil
+}
+
+func compareAlertValue(comparator string, value, threshold float64) (bool, error) {
+	switch comparator {
+	case ">":
+		return value > threshold, nil
+	case ">=":
+		return value >= threshold, nil
+	case "<":
+		return value < threshold, nil
+	case "<=":
+		return value <= threshold, nil
+	case "==":
+		return value == threshold, nil
+	case "!=":
+		return value != threshold, nil
+	}
+	return false, fmt.Errorf("unknown comparator[%v]", comparator)
+}
+
+// alertSmtpConfig is the mail server used by mail targets.
+type alertSmtpConfig struct {
+	Addr     string `json:"addr"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+	From     string `json:"from"`
+}
+
+// alertMessage is the body posted to webhooks.
+type alertMessage struct {
+	Cluster    string  `json:"cluster"`
+	Rule       string  `json:"rule"`
+	Metric     string  `json:"metric"`
+	Object     string  `json:"object"`
+	Value      float64 `json:"value"`
+	Comparator string  `json:"comparator"`
+	Threshold  float64 `json:"threshold"`
+	Status     string  `json:"status"`
+	Since      int64   `json:"since"`
+	Time       int64   `json:"time"`
+}
+
+func (msg *alertMessage) String() string {
+	return fmt.Sprintf("[%v] cluster[%v] rule[%v] object[%v] %v[%v] %v %v since %v",
+		strings.ToUpper(msg.Status), msg.Cluster, msg.Rule, msg.Object, msg.Metric, msg.Value,
+		msg.Comparator, msg.Threshold, time.Unix(msg.Since, 0).Format(proto.TimeFormat))
+}
+
+type alertState struct {
+	since time.Time
+	fired bool
+}
+
+// alertStateKey is the object checked by the rule, the rule name may contain any character,
+// so that they are not joined as the key.
+type alertStateKey struct {
+	rule   string
+	object string
+}
+
+type alertManager struct {
+	sync.Mutex
+	cluster string
+	rules   []*alertRule
+	smtp    *alertSmtpConfig
+	states  map[alertStateKey]*alertState
+	notify  func(target string, msg *alertMessage) error
+}
+
+func newAlertManager(cluster string, rules []*alertRule, smtp *alertSmtpConfig) *alertManager {
+	am := &alertManager{
+		cluster: cluster,
+		rules:   rules,
+		smtp:    smtp,
+		states:  make(map[alertStateKey]*alertState),
+	}
+	am.notify = am.send
+	return am
+}
+
+// alertNotification is a message to send to the target of the rule.
+type alertNotification struct {
+	target string
+	msg    *alertMessage
+}
+
+// evaluate checks all rules with the metric values (metric -> object -> value) collected at now.
+// The notifications are sent after the states are updated and unlocked, so that a slow target
+// doesn't block the others.
+func (am *alertManager) evaluate(now time.Time, values map[string]map[string]float64) {
+	for _, n := range am.check(now, values) {
+		if err := am.notify(n.target, n.msg); err != nil {
+			log.LogErrorf("action[alertManager] notify target[%v] rule[%v] object[%v] err[%v]",
+				n.target, n.msg.Rule, n.msg.Object, err)
+		}
+	}
+}
+
+// check updates the states of the rules and returns the notifications of the rules fired or resolved.
+func (am *alertManager) check(now time.Time, values map[string]map[string]float64) (notifications []alertNotification) {
+	am.Lock()
+	defer am.Unlock()
+
+	for _, rule := range am.rules {
+		breached := make(map[alertStateKey]bool)
+		for object, value := range values[rule.Metric] {
+			if ok, _ := compareAlertValue(rule.Comparator, value, rule.Threshold); !ok {
+				continue
+			}
+			key := alertStateKey{rule: rule.Name, object: object}
+			breached[key] = true
+			state, ok := am.states[key]
+			if !ok {
+				state = &alertState{since: now}
+				am.states[key] = state
+			}
+			if state.fired || now.Sub(state.since) < time.Duration(rule.DurationSec)*time.Second {
+				continue
+			}
+			state.fired = true
+			notifications = append(notifications, am.fire(rule, object, value, alertStatusFiring, state.since, now))
+		}
+
+		for key, state := range am.states {
+			if key.rule != rule.Name || breached[key] {
+				continue
+			}
+			delete(am.states, key)
+			if state.fired {
+				notifications = append(notifications,
+					am.fire(rule, key.object, values[rule.Metric][key.object], alertStatusResolved, state.since, now))
+			}
+		}
+	}
+	return
+}
+
+func (am *alertManager) fire(rule *alertRule, object string, value float64, status string, since, now time.Time) alertNotification {
+	msg := &alertMessage{
+		Cluster:    am.cluster,
+		Rule:       rule.Name,
+		Metric:     rule.Metric,
+		Object:     object,
+		Value:      value,
+		Comparator: rule.Comparator,
+		Threshold:  rule.Threshold,
+		Status:     status,
+		Since:      since.Unix(),
+		Time:       now.Unix(),
+	}
+	log.LogWarnf("action[alertManager] %v", msg)
+	return alertNotification{target: rule.Target, msg: msg}
+}
+
+func (am *alertManager) send(target string, msg *alertMessage) error {
+	if strings.HasPrefix(target, alertTargetMailPrefix) {
+		return am.sendMail(strings.Split(strings.TrimPrefix(target, alertTargetMailPrefix), commaSplit), msg)
+	}
+	return sendAlertWebhook(target, msg)
+}
+
+func (am *alertManager) sendMail(to []string, msg *alertMessage) error {
+	if am.smtp == nil || am.smtp.Addr == "" {
+		return fmt.Errorf("smtp server is not configured")
+	}
+	var auth smtp.Auth
+	if am.smtp.User != "" {
+		host := strings.Split(am.smtp.Addr, colonSplit)[0]
+		auth = smtp.PlainAuth("", am.smtp.User, am.smtp.Password, host)
+	}
+	content := msg.String()
+	body := fmt.Sprintf("From: %v\r\nTo: %v\r\nSubject: %v\r\n\r\n%v\r\n",
+		am.smtp.From, strings.Join(to, commaSplit), content, content)
+	return smtp.SendMail(am.smtp.Addr, auth, am.smtp.From, to, []byte(body))
+}
+
+func sendAlertWebhook(url string, msg *alertMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: alertWebhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook response status[%v]", resp.Status)
+	}
+	return nil
+}
+
+// parseAlertRules parses the raw config value of alert rules, which is a json array.
+func parseAlertRules(raw interface{}) (rules []*alertRule, err error) {
+	if raw == nil {
+		return
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(data, &rules); err != nil {
+		return
+	}
+	names := make(map[string]bool)
+	for _, rule := range rules {
+		if err = rule.validate(); err != nil {
+			return nil, err
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("duplicate alert rule[%v]", rule.Name)
+		}
+		names[rule.Name] = true
+	}
+	return
+}
+
+func parseAlertSmtpConfig(raw interface{}) (cfg *alertSmtpConfig, err error) {
+	if raw == nil {
+		return
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return
+	}
+	cfg = new(alertSmtpConfig)
+	err = json.Unmarshal(data, cfg)
+	return
+}
+
+// collectAlertValues collects the values of metrics used by alert rules.
+func (c *Cluster) collectAlertValues() (values map[string]map[string]float64) {
+	values = map[string]map[string]float64{
+		alertMetricZoneDataUsedRatio: make(map[string]float64),
+		alertMetricZoneMetaUsedRatio: make(map[string]float64),
+		alertMetricDpUnavailable:     make(map[string]float64),
+		alertMetricMpUnavailable:     make(map[string]float64),
+		alertMetricDataNodeInactive:  make(map[string]float64),
+		alertMetricMetaNodeInactive:  make(map[string]float64),
+	}
+	for zoneName, zoneStat := range c.zoneStatInfos {
+		values[alertMetricZoneDataUsedRatio][zoneName] = zoneStat.DataNodeStat.UsedRatio
+		values[alertMetricZoneMetaUsedRatio][zoneName] = zoneStat.MetaNodeStat.UsedRatio
+	}
+	for _, vol := range c.allVols() {
+		for _, dp := range vol.dataPartitions.clonePartitions() {
+			if dp.Status == proto.Unavailable {
+				values[alertMetricDpUnavailable][fmt.Sprintf("%v/dp_%v", vol.Name, dp.PartitionID)] = 1
+			}
+		}
+		for _, mp := range vol.cloneMetaPartitionMap() {
+			if mp.Status == proto.Unavailable {
+				values[alertMetricMpUnavailable][fmt.Sprintf("%v/mp_%v", vol.Name, mp.PartitionID)] = 1
+			}
+		}
+	}
+	c.dataNodes.Range(func(addr, node interface{}) bool {
+		if !node.(*DataNode).isActive {
+			values[alertMetricDataNodeInactive][addr.(string)] = 1
+		}
+		return true
+	})
+	c.metaNodes.Range(func(addr, node interface{}) bool {
+		if !node.(*MetaNode).IsActive {
+			values[alertMetricMetaNodeInactive][addr.(string)] = 1
+		}
+		return true
+	})
+	return
+}
+
+func (c *Cluster) scheduleToCheckAlertRules() {
+	if len(c.cfg.alertRules) == 0 {
+		return
+	}
+	c.alertMgr = newAlertManager(c.Name, c.cfg.alertRules, c.cfg.alertSmtp)
+	go func() {
+		for {
+			if c.partition != nil && c.partition.IsRaftLeader() {
+				c.alertMgr.evaluate(time.Now(), c.collectAlertValues())
+			}
+			time.Sleep(time.Second * defaultIntervalToCheckAlertRules)
+		}
+	}()
+}
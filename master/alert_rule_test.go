@@ -0,0 +1,125 @@
+package master
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAlertRules(t *testing.T) {
+	var raw interface{}
+	err := json.Unmarshal([]byte(`[{"name":"zone_full","metric":"zone_data_used_ratio","comparator":">",
+		"threshold":0.9,"durationSec":60,"target":"http://127.0.0.1:8080/alert"}]`), &raw)
+	require.NoError(t, err)
+	rules, err := parseAlertRules(raw)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(rules))
+	require.Equal(t, 0.9, rules[0].Threshold)
+
+	rules, err = parseAlertRules(nil)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(rules))
+
+	invalids := []string{
+		`[{"name":"a","metric":"unknown","comparator":">","target":"mailto:a@b.com"}]`,
+		`[{"name":"a","metric":"dp_unavailable","comparator":"~","target":"mailto:a@b.com"}]`,
+		`[{"name":"a","metric":"dp_unavailable","comparator":">","target":"ftp://a"}]`,
+		`[{"name":"a","metric":"dp_unavailable","comparator":">","durationSec":-1,"target":"mailto:a@b.com"}]`,
+		`[{"name":"a","metric":"dp_unavailable","comparator":">","target":"mailto:a@b.com"},
+		  {"name":"a","metric":"mp_unavailable","comparator":">","target":"mailto:a@b.com"}]`,
+	}
+	for _, invalid := range invalids {
+		require.NoError(t, json.Unmarshal([]byte(invalid), &raw))
+		_, err = parseAlertRules(raw)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestAlertManagerEvaluate(t *testing.T) {
+	rules := []*alertRule{
+		{Name: "zone_full", Metric: alertMetricZoneDataUsedRatio, Comparator: ">", Threshold: 0.9, Target: "http://a"},
+		{Name: "dp_down", Metric: alertMetricDpUnavailable, Comparator: ">=", Threshold: 1, DurationSec: 300, Target: "http://b"},
+	}
+	am := newAlertManager("test", rules, nil)
+	var msgs []*alertMessage
+	am.notify = func(target string, msg *alertMessage) error {
+		// the notifications are sent without the lock held
+		unlocked := make(chan struct{})
+		go func() {
+			am.Lock()
+			am.Unlock()
+			close(unlocked)
+		}()
+		select {
+		case <-unlocked:
+		case <-time.After(time.Second):
+			t.Error("notify with the lock of alert manager held")
+		}
+		msgs = append(msgs, msg)
+		return nil
+	}
+
+	now := time.Now()
+	am.evaluate(now, map[string]map[string]float64{
+		alertMetricZoneDataUsedRatio: {"zone1": 0.95, "zone2": 0.5},
+		alertMetricDpUnavailable:     {"vol/dp_1": 1},
+	})
+	require.Equal(t, 1, len(msgs))
+	require.Equal(t, "zone1", msgs[0].Object)
+	require.Equal(t, alertStatusFiring, msgs[0].Status)
+
+	// fire once during breach, dp fires after duration
+	msgs = nil
+	am.evaluate(now.Add(5*time.Minute), map[string]map[string]float64{
+		alertMetricZoneDataUsedRatio: {"zone1": 0.96},
+		alertMetricDpUnavailable:     {"vol/dp_1": 1},
+	})
+	require.Equal(t, 1, len(msgs))
+	require.Equal(t, "vol/dp_1", msgs[0].Object)
+
+	// resolved
+	msgs = nil
+	am.evaluate(now.Add(6*time.Minute), map[string]map[string]float64{
+		alertMetricZoneDataUsedRatio: {"zone1": 0.8},
+	})
+	require.Equal(t, 2, len(msgs))
+	for _, msg := range msgs {
+		require.Equal(t, alertStatusResolved, msg.Status)
+	}
+	require.Equal(t, 0, len(am.states))
+}
+
+func TestAlertManagerRuleNamesWithSlash(t *testing.T) {
+	rules := []*alertRule{
+		{Name: "zone", Metric: alertMetricZoneDataUsedRatio, Comparator: ">", Threshold: 0.9, Target: "http://a"},
+		{Name: "zone/used", Metric: alertMetricZoneDataUsedRatio, Comparator: ">", Threshold: 0.5, Target: "http://b"},
+	}
+	am := newAlertManager("test", rules, nil)
+	var msgs []*alertMessage
+	am.notify = func(target string, msg *alertMessage) error {
+		msgs = append(msgs, msg)
+		return nil
+	}
+
+	now := time.Now()
+	values := map[string]map[string]float64{alertMetricZoneDataUsedRatio: {"zone1": 0.6}}
+	am.evaluate(now, values)
+	require.Equal(t, 1, len(msgs))
+	require.Equal(t, "zone/used", msgs[0].Rule)
+	require.Equal(t, "zone1", msgs[0].Object)
+	require.Equal(t, alertStatusFiring, msgs[0].Status)
+
+	// the state of one rule is not resolved by another whose name is the prefix
+	msgs = nil
+	am.evaluate(now.Add(time.Minute), values)
+	require.Equal(t, 0, len(msgs))
+	require.Equal(t, 1, len(am.states))
+
+	am.evaluate(now.Add(2*time.Minute), map[string]map[string]float64{alertMetricZoneDataUsedRatio: {"zone1": 0.4}})
+	require.Equal(t, 1, len(msgs))
+	require.Equal(t, "zone/used", msgs[0].Rule)
+	require.Equal(t, "zone1", msgs[0].Object)
+	require.Equal(t, alertStatusResolved, msgs[0].Status)
+}
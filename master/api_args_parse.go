@@ -16,6 +16,10 @@ package master
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -315,6 +319,39 @@ func parseRequestToDeleteVol(r *http.Request) (name, authKey string, status, for
 	return
 }
 
+func parseRequestToRenameVol(r *http.Request) (name, newName, authKey string, err error) {
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+	if name, err = extractName(r); err != nil {
+		return
+	}
+	if newName, err = extractNewName(r); err != nil {
+		return
+	}
+	authKey, err = extractAuthKey(r)
+	return
+}
+
+func parseRequestToCloneVol(r *http.Request) (name, newName, owner, authKey string, err error) {
+	if name, newName, authKey, err = parseRequestToRenameVol(r); err != nil {
+		return
+	}
+	owner = r.FormValue(volOwnerKey)
+	return
+}
+
+func extractNewName(r *http.Request) (name string, err error) {
+	if name = r.FormValue(newNameKey); name == "" {
+		err = keyNotFound(newNameKey)
+		return
+	}
+	if !volNameRegexp.MatchString(name) {
+		return "", errors.New("newName can only be number and letters")
+	}
+	return
+}
+
 func extractUintWithDefault(r *http.Request, key string, def int) (val int, err error) {
 	var str string
 	if str = r.FormValue(key); str == "" {
@@ -362,6 +399,17 @@ func extractStrWithDefault(r *http.Request, key string, def string) (val string)
 	return val
 }
 
+// extractLabelsWithDefault returns the default labels if the key is absent, and an empty value clears the labels
+func extractLabelsWithDefault(r *http.Request, key string, def []string) (labels []string, err error) {
+	if _, ok := r.Form[key]; !ok {
+		return def, nil
+	}
+	if labels, err = parseLabels(r.FormValue(key)); err != nil {
+		err = fmt.Errorf("args [%s] is not legal: %s", key, err.Error())
+	}
+	return
+}
+
 func extractBoolWithDefault(r *http.Request, key string, def bool) (val bool, err error) {
 	var str string
 	if str = r.FormValue(key); str == "" {
@@ -398,6 +446,9 @@ type updateVolReq struct {
 	enableQuota             bool
 	crossZone               bool
 	enableAutoDpMetaRepair  bool
+	affinityLabels          []string
+	antiAffinityLabels      []string
+	fsyncPolicy             string
 }
 
 func parseColdVolUpdateArgs(r *http.Request, vol *Vol) (args *coldVolArgs, err error) {
@@ -537,6 +588,22 @@ func parseVolUpdateReq(r *http.Request, vol *Vol, req *updateVolReq) (err error)
 		return
 	}
 
+	if req.affinityLabels, err = extractLabelsWithDefault(r, affinityLabelsKey, vol.affinityLabels); err != nil {
+		return
+	}
+
+	if req.antiAffinityLabels, err = extractLabelsWithDefault(r, antiAffinityLabelsKey, vol.antiAffinityLabels); err != nil {
+		return
+	}
+
+	if err = checkLabelConstraints(req.affinityLabels, req.antiAffinityLabels); err != nil {
+		return
+	}
+
+	if req.fsyncPolicy, err = extractFsyncPolicy(r, vol.fsyncPolicy); err != nil {
+		return
+	}
+
 	req.dpSelectorName = r.FormValue(dpSelectorNameKey)
 	req.dpSelectorParm = r.FormValue(dpSelectorParmKey)
 
@@ -580,6 +647,146 @@ func parseBoolFieldToUpdateVol(r *http.Request, vol *Vol) (followerRead, authent
 	return
 }
 
+func parseRequestToGetTopView(r *http.Request) (sortBy string, limit int, err error) {
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+	sortBy = r.FormValue(sortByKey)
+	switch sortBy {
+	case "":
+		sortBy = proto.TopSortByQps
+	case proto.TopSortByQps, proto.TopSortByBandwidth, proto.TopSortByLatency:
+	default:
+		err = fmt.Errorf("args [%s] is not legal, must be one of [%s, %s, %s]",
+			sortByKey, proto.TopSortByQps, proto.TopSortByBandwidth, proto.TopSortByLatency)
+		return
+	}
+	limit, err = extractUint(r, Limit)
+	return
+}
+
+func parseRequestToSetClientUpgrade(r *http.Request) (info *proto.ClientUpgradeInfo, err error) {
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+	info = &proto.ClientUpgradeInfo{
+		Version:   r.FormValue(clientVersion),
+		URL:       r.FormValue(clientUpgradeURLKey),
+		Checksum:  r.FormValue(clientUpgradeChecksumKey),
+		Signature: r.FormValue(clientUpgradeSignatureKey),
+	}
+	// disable auto upgrade
+	if info.Version == "" {
+		return &proto.ClientUpgradeInfo{}, nil
+	}
+	if info.URL == "" {
+		err = keyNotFound(clientUpgradeURLKey)
+		return
+	}
+	if checksum, e := hex.DecodeString(info.Checksum); e != nil || len(checksum) != sha256.Size {
+		err = fmt.Errorf("args [%s] is not a hex encoded sha256", clientUpgradeChecksumKey)
+		return
+	}
+	if signature, e := base64.StdEncoding.DecodeString(info.Signature); e != nil || len(signature) != ed25519.SignatureSize {
+		err = fmt.Errorf("args [%s] is not a base64 encoded ed25519 signature", clientUpgradeSignatureKey)
+		return
+	}
+	return
+}
+
+func parseRequestToSimulatePlacement(r *http.Request) (removeNodes, removeZones []string, addDataNodes,
+	addMetaNodes int, zoneName string, err error,
+) {
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+	splitList := func(key string) (list []string) {
+		for _, item := range strings.Split(r.FormValue(key), commaSplit) {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return
+	}
+	removeNodes = splitList(removeNodesKey)
+	removeZones = splitList(removeZonesKey)
+	if addDataNodes, err = extractUint(r, addDataNodesKey); err != nil {
+		return
+	}
+	if addMetaNodes, err = extractUint(r, addMetaNodesKey); err != nil {
+		return
+	}
+	zoneName = extractStr(r, zoneNameKey)
+	return
+}
+
+type configRolloutArgs struct {
+	key             string
+	value           string
+	canaryNodes     []string
+	canaryLabel     string
+	canaryPercent   int
+	observeSec      int64
+	maxErrRateDelta float64
+}
+
+func parseRequestToStartConfigRollout(r *http.Request) (args *configRolloutArgs, err error) {
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+	args = &configRolloutArgs{maxErrRateDelta: defaultConfigRolloutMaxErrRateDelta}
+	if args.key = extractStr(r, configKeyKey); args.key == "" {
+		return nil, keyNotFound(configKeyKey)
+	}
+	if args.value = extractStr(r, configValueKey); args.value == "" {
+		return nil, keyNotFound(configValueKey)
+	}
+	for _, addr := range strings.Split(r.FormValue(canaryNodesKey), commaSplit) {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			args.canaryNodes = append(args.canaryNodes, addr)
+		}
+	}
+	args.canaryLabel = extractStr(r, canaryLabelKey)
+	if args.canaryPercent, err = extractUintWithDefault(r, canaryPercentKey, defaultConfigRolloutCanaryPercent); err != nil {
+		return nil, err
+	}
+	if args.observeSec, err = extractInt64WithDefault(r, observeSecKey, defaultConfigRolloutObserveSec); err != nil {
+		return nil, err
+	}
+	if value := r.FormValue(maxErrRateDeltaKey); value != "" {
+		if args.maxErrRateDelta, err = strconv.ParseFloat(value, 64); err != nil || args.maxErrRateDelta < 0 {
+			return nil, fmt.Errorf("parse [%s] is not valid rate [%v], err %v", maxErrRateDeltaKey, value, err)
+		}
+	}
+	return
+}
+
+func parseRequestToSetFeatureFlag(r *http.Request) (feature, mode string, err error) {
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+	if feature = extractStr(r, featureKey); feature == "" {
+		return "", "", keyNotFound(featureKey)
+	}
+	if mode = extractStr(r, featureModeKey); mode == "" {
+		return "", "", keyNotFound(featureModeKey)
+	}
+	return
+}
+
+func parseRequestToSetVolFeatureFlag(r *http.Request) (name, feature string, enable bool, err error) {
+	if name, err = parseAndExtractName(r); err != nil {
+		return
+	}
+	if feature = extractStr(r, featureKey); feature == "" {
+		return "", "", false, keyNotFound(featureKey)
+	}
+	if enable, err = extractBoolWithDefault(r, enableKey, true); err != nil {
+		return
+	}
+	return
+}
+
 func parseRequestToSetApiQpsLimit(r *http.Request) (name string, limit uint32, timeout uint32, err error) {
 	if err = r.ParseForm(); err != nil {
 		return
@@ -668,6 +875,10 @@ type createVolReq struct {
 	volType                              int
 	enablePosixAcl                       bool
 	DpReadOnlyWhenVolFull                bool
+	caseInsensitive                      bool
+	affinityLabels                       []string
+	antiAffinityLabels                   []string
+	fsyncPolicy                          string
 	enableTransaction                    proto.TxOpMask
 	enableQuota                          bool
 	txTimeout                            int64
@@ -676,7 +887,9 @@ type createVolReq struct {
 	qosLimitArgs                         *qosArgs
 	clientReqPeriod, clientHitTriggerCnt uint32
 	// cold vol args
-	coldArgs coldVolArgs
+	coldArgs    coldVolArgs
+	cloneSource string
+	cloneVerSeq uint64
 }
 
 func checkCacheAction(action int) error {
@@ -725,6 +938,18 @@ func parseColdArgs(r *http.Request) (args coldVolArgs, err error) {
 	return
 }
 
+// extractFsyncPolicy returns the default policy if the key is absent
+func extractFsyncPolicy(r *http.Request, def string) (policy string, err error) {
+	if policy = r.FormValue(fsyncPolicyKey); policy == "" {
+		return def, nil
+	}
+	if !proto.IsValidFsyncPolicy(policy) {
+		err = fmt.Errorf("args [%s] is not legal: %s, should be %s, %s or %s", fsyncPolicyKey, policy,
+			proto.FsyncPolicyAlways, proto.FsyncPolicyInterval, proto.FsyncPolicyNever)
+	}
+	return
+}
+
 func parseRequestToCreateVol(r *http.Request, req *createVolReq) (err error) {
 	if err = r.ParseForm(); err != nil {
 		return
@@ -817,6 +1042,26 @@ func parseRequestToCreateVol(r *http.Request, req *createVolReq) (err error) {
 		return
 	}
 
+	if req.caseInsensitive, err = extractBoolWithDefault(r, caseInsensitiveKey, false); err != nil {
+		return
+	}
+
+	if req.affinityLabels, err = extractLabelsWithDefault(r, affinityLabelsKey, nil); err != nil {
+		return
+	}
+
+	if req.antiAffinityLabels, err = extractLabelsWithDefault(r, antiAffinityLabelsKey, nil); err != nil {
+		return
+	}
+
+	if err = checkLabelConstraints(req.affinityLabels, req.antiAffinityLabels); err != nil {
+		return
+	}
+
+	if req.fsyncPolicy, err = extractFsyncPolicy(r, proto.DefaultFsyncPolicy); err != nil {
+		return
+	}
+
 	var txMask proto.TxOpMask
 	if txMask, err = parseTxMask(r, proto.TxOpMaskOff); err != nil {
 		return
@@ -2113,7 +2113,9 @@ func (m *Server) markDeleteVol(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if enableDirectDeleteVol {
-		if err = m.cluster.markDeleteVol(name, authKey, false, true); err != nil {
+		err = m.cluster.markDeleteVol(name, authKey, false, true)
+		m.recordVolAudit(r, name, volAuditOpDelete, "", err)
+		if err != nil {
 			sendErrReply(w, r, newErrHTTPReply(err))
 			return
 		}
@@ -2162,7 +2164,9 @@ func (m *Server) markDeleteVol(w http.ResponseWriter, r *http.Request) {
 			}
 		}()
 
-		if err = m.cluster.markDeleteVol(name, authKey, false, true); err != nil {
+		err = m.cluster.markDeleteVol(name, authKey, false, true)
+		m.recordVolAudit(r, name, volAuditOpDelete, fmt.Sprintf("delay delete at[%v]", vol.DeleteExecTime.Format(time.RFC3339)), err)
+		if err != nil {
 			sendErrReply(w, r, newErrHTTPReply(err))
 			return
 		}
@@ -2175,24 +2179,23 @@ func (m *Server) markDeleteVol(w http.ResponseWriter, r *http.Request) {
 		sendOkReply(w, r, newSuccessHTTPReply(msg))
 		return
 	}
-	var index int
-	var value *delayDeleteVolInfo
-	if len(m.cluster.delayDeleteVolsInfo) == 0 {
-		msg := fmt.Sprintf("vol[%v] was not previously deleted or already deleted", name)
-		err = errors.New(msg)
+	// the volume can be restored only in the retention window, the data is reclaimed after that
+	if vol.Status != proto.VolStatusMarkDelete || !vol.Forbidden || time.Until(vol.DeleteExecTime) <= 0 {
+		err = fmt.Errorf("vol[%v] was not previously deleted or the retention window expired", name)
 		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotDelete, Msg: err.Error()})
 		return
 	}
+	found := false
 	m.cluster.deleteVolMutex.RLock()
-	for index, value = range m.cluster.delayDeleteVolsInfo {
+	for _, value := range m.cluster.delayDeleteVolsInfo {
 		if value.volName == name {
+			found = true
 			break
 		}
 	}
 	m.cluster.deleteVolMutex.RUnlock()
-	if index == len(m.cluster.delayDeleteVolsInfo)-1 && value.volName != name {
-		msg := fmt.Sprintf("vol[%v] was not previously deleted or already deleted", name)
-		err = errors.New(msg)
+	if !found {
+		err = fmt.Errorf("vol[%v] was not previously deleted or already deleted", name)
 		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotDelete, Msg: err.Error()})
 		return
 	}
@@ -2213,12 +2216,19 @@ func (m *Server) markDeleteVol(w http.ResponseWriter, r *http.Request) {
 			vol.user = oldUser
 		}
 	}()
-	if err = m.cluster.markDeleteVol(name, authKey, false, false); err != nil {
+	err = m.cluster.markDeleteVol(name, authKey, false, false)
+	m.recordVolAudit(r, name, volAuditOpUndelete, "", err)
+	if err != nil {
 		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
 	m.cluster.deleteVolMutex.Lock()
-	m.cluster.delayDeleteVolsInfo = append(m.cluster.delayDeleteVolsInfo[:index], m.cluster.delayDeleteVolsInfo[index+1:]...)
+	for index, value := range m.cluster.delayDeleteVolsInfo {
+		if value.volName == name {
+			m.cluster.delayDeleteVolsInfo = append(m.cluster.delayDeleteVolsInfo[:index], m.cluster.delayDeleteVolsInfo[index+1:]...)
+			break
+		}
+	}
 	m.cluster.deleteVolMutex.Unlock()
 	msg = fmt.Sprintf("undelete vol: unforbid vol[%v] successfully,from[%v]", name, r.RemoteAddr)
 	log.LogWarn(msg)
@@ -2333,9 +2343,16 @@ func (m *Server) updateVol(w http.ResponseWriter, r *http.Request) {
 	newArgs.dpReplicaNum = uint8(req.replicaNum)
 	newArgs.dpReadOnlyWhenVolFull = req.dpReadOnlyWhenVolFull
 	newArgs.enableAutoDpMetaRepair = req.enableAutoDpMetaRepair
+	newArgs.affinityLabels = req.affinityLabels
+	newArgs.antiAffinityLabels = req.antiAffinityLabels
+	newArgs.fsyncPolicy = req.fsyncPolicy
 
 	log.LogWarnf("[updateVolOut] name [%s], z1 [%s], z2[%s] replicaNum[%v]", req.name, req.zoneName, vol.Name, req.replicaNum)
-	if err = m.cluster.updateVol(req.name, req.authKey, newArgs); err != nil {
+	oldCapacity := vol.Capacity
+	err = m.cluster.updateVol(req.name, req.authKey, newArgs)
+	m.recordVolAudit(r, req.name, volAuditOpUpdate, fmt.Sprintf("capacity[%v->%v] replicaNum[%v] enableQuota[%v]",
+		oldCapacity, newArgs.capacity, newArgs.dpReplicaNum, newArgs.enableQuota), err)
+	if err != nil {
 		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
@@ -2383,7 +2400,10 @@ func (m *Server) volExpand(w http.ResponseWriter, r *http.Request) {
 	newArgs := getVolVarargs(vol)
 	newArgs.capacity = uint64(capacity)
 
-	if err = m.cluster.updateVol(name, authKey, newArgs); err != nil {
+	oldCapacity := vol.Capacity
+	err = m.cluster.updateVol(name, authKey, newArgs)
+	m.recordVolAudit(r, name, volAuditOpExpand, fmt.Sprintf("capacity[%v->%v]", oldCapacity, capacity), err)
+	if err != nil {
 		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
@@ -2425,7 +2445,10 @@ func (m *Server) volShrink(w http.ResponseWriter, r *http.Request) {
 	newArgs := getVolVarargs(vol)
 	newArgs.capacity = uint64(capacity)
 
-	if err = m.cluster.updateVol(name, authKey, newArgs); err != nil {
+	oldCapacity := vol.Capacity
+	err = m.cluster.updateVol(name, authKey, newArgs)
+	m.recordVolAudit(r, name, volAuditOpShrink, fmt.Sprintf("capacity[%v->%v]", oldCapacity, capacity), err)
+	if err != nil {
 		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
@@ -2559,7 +2582,9 @@ func (m *Server) createVol(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var vol *Vol
-	if vol, err = m.cluster.createVol(req); err != nil {
+	vol, err = m.cluster.createVol(req)
+	m.recordVolAudit(r, req.name, volAuditOpCreate, fmt.Sprintf("owner[%v] capacity[%v] volType[%v]", req.owner, req.capacity, req.volType), err)
+	if err != nil {
 		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
@@ -2573,6 +2598,97 @@ func (m *Server) createVol(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(msg))
 }
 
+func (m *Server) renameVol(w http.ResponseWriter, r *http.Request) {
+	var (
+		name    string
+		newName string
+		authKey string
+		err     error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminRenameVol))
+	defer func() {
+		doStatAndMetric(proto.AdminRenameVol, metric, err, map[string]string{exporter.Vol: name})
+	}()
+
+	if name, newName, authKey, err = parseRequestToRenameVol(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	// the vol is renamed if returned, even if the partitions are failed to persist
+	vol, renameErr := m.cluster.renameVol(name, newName, authKey)
+	if vol == nil {
+		err = renameErr
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+
+	if err = m.user.renameVolPolicy(name, newName); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+
+	if err = renameErr; err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+
+	msg := fmt.Sprintf("rename vol[%v] to [%v] successfully, from[%v]", name, newName, r.RemoteAddr)
+	log.LogWarn(msg)
+	sendOkReply(w, r, newSuccessHTTPReply(msg))
+}
+
+func (m *Server) cloneVol(w http.ResponseWriter, r *http.Request) {
+	var (
+		name    string
+		newName string
+		owner   string
+		authKey string
+		req     *createVolReq
+		vol     *Vol
+		err     error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminCloneVol))
+	defer func() {
+		doStatAndMetric(proto.AdminCloneVol, metric, err, map[string]string{exporter.Vol: name})
+	}()
+
+	if name, newName, owner, authKey, err = parseRequestToCloneVol(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	if req, err = m.cluster.cloneVol(name, newName, owner, authKey); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+
+	if err = m.checkCreateReq(req); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	if vol, err = m.cluster.createVol(req); err != nil {
+		log.LogWarnf("action[cloneVol] create vol[%v] failed, snapshot version[%v] of vol[%v] can be deleted",
+			newName, req.cloneVerSeq, name)
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+
+	if err = m.associateVolWithUser(req.owner, req.name); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+
+	m.cluster.updateSharedPartitions(vol)
+	vol.dataPartitions.updateResponseCache(true, 0, vol)
+	vol.dataPartitions.updateCompressCache(true, 0, vol)
+
+	log.LogWarnf("action[cloneVol] clone vol[%v] from [%v] at snapshot version[%v] successfully, from[%v]",
+		newName, name, req.cloneVerSeq, r.RemoteAddr)
+	sendOkReply(w, r, newSuccessHTTPReply(newSimpleView(vol)))
+}
+
 func (m *Server) qosUpload(w http.ResponseWriter, r *http.Request) {
 	var (
 		err   error
@@ -2681,6 +2797,7 @@ func newSimpleView(vol *Vol) (view *proto.SimpleVolView) {
 		FollowerRead:            vol.FollowerRead,
 		EnablePosixAcl:          vol.enablePosixAcl,
 		EnableQuota:             vol.enableQuota,
+		CaseInsensitive:         vol.caseInsensitive,
 		EnableTransactionV1:     proto.GetMaskString(vol.enableTransaction),
 		EnableTransaction:       "off",
 		TxTimeout:               vol.txTimeout,
@@ -2719,6 +2836,12 @@ func newSimpleView(vol *Vol) (view *proto.SimpleVolView) {
 		DeleteExecTime:          vol.DeleteExecTime,
 		DpRepairBlockSize:       vol.dpRepairBlockSize,
 		EnableAutoDpMetaRepair:  vol.EnableAutoMetaRepair.Load(),
+		PreviousNames:           vol.previousNames,
+		CloneSource:             vol.cloneSource,
+		CloneVerSeq:             vol.cloneVerSeq,
+		AffinityLabels:          vol.affinityLabels,
+		AntiAffinityLabels:      vol.antiAffinityLabels,
+		FsyncPolicy:             vol.fsyncPolicy,
 	}
 
 	vol.uidSpaceManager.rwMutex.RLock()
@@ -2838,6 +2961,8 @@ func (m *Server) getDataNode(w http.ResponseWriter, r *http.Request) {
 		PersistenceDataPartitions: dataNode.PersistenceDataPartitions,
 		BadDisks:                  dataNode.BadDisks,
 		RdOnly:                    dataNode.RdOnly,
+		AllocWeight:               dataNode.GetAllocWeight(),
+		Labels:                    dataNode.Labels,
 		CanAllocPartition:         dataNode.canAlloc() && dataNode.canAllocDp(),
 		MaxDpCntLimit:             dataNode.GetPartitionLimitCnt(),
 		CpuUtil:                   dataNode.CpuUtil.Load(),
@@ -3432,6 +3557,88 @@ func (m *Server) setNodeRdOnly(addr string, nodeType uint32, rdOnly bool) (err e
 	return
 }
 
+func (m *Server) setNodeAllocWeight(addr string, nodeType uint32, weight float64) (err error) {
+	if nodeType == TypeDataPartition {
+		m.cluster.dnMutex.Lock()
+		defer m.cluster.dnMutex.Unlock()
+		value, ok := m.cluster.dataNodes.Load(addr)
+		if !ok {
+			return fmt.Errorf("[setNodeAllocWeight] data node %s is not exist", addr)
+		}
+
+		dataNode := value.(*DataNode)
+		oldWeight := dataNode.AllocWeight
+		dataNode.AllocWeight = weight
+
+		if err = m.cluster.syncUpdateDataNode(dataNode); err != nil {
+			dataNode.AllocWeight = oldWeight
+			return fmt.Errorf("[setNodeAllocWeight] syncUpdateDataNode err(%s)", err.Error())
+		}
+
+		return
+	}
+
+	m.cluster.mnMutex.Lock()
+	defer m.cluster.mnMutex.Unlock()
+
+	value, ok := m.cluster.metaNodes.Load(addr)
+	if !ok {
+		return fmt.Errorf("[setNodeAllocWeight] meta node %s is not exist", addr)
+	}
+
+	metaNode := value.(*MetaNode)
+	oldWeight := metaNode.AllocWeight
+	metaNode.AllocWeight = weight
+
+	if err = m.cluster.syncUpdateMetaNode(metaNode); err != nil {
+		metaNode.AllocWeight = oldWeight
+		return fmt.Errorf("[setNodeAllocWeight] syncUpdateMetaNode err(%s)", err.Error())
+	}
+
+	return
+}
+
+func (m *Server) setNodeLabels(addr string, nodeType uint32, labels []string) (err error) {
+	if nodeType == TypeDataPartition {
+		m.cluster.dnMutex.Lock()
+		defer m.cluster.dnMutex.Unlock()
+		value, ok := m.cluster.dataNodes.Load(addr)
+		if !ok {
+			return fmt.Errorf("[setNodeLabels] data node %s is not exist", addr)
+		}
+
+		dataNode := value.(*DataNode)
+		oldLabels := dataNode.Labels
+		dataNode.Labels = labels
+
+		if err = m.cluster.syncUpdateDataNode(dataNode); err != nil {
+			dataNode.Labels = oldLabels
+			return fmt.Errorf("[setNodeLabels] syncUpdateDataNode err(%s)", err.Error())
+		}
+
+		return
+	}
+
+	m.cluster.mnMutex.Lock()
+	defer m.cluster.mnMutex.Unlock()
+
+	value, ok := m.cluster.metaNodes.Load(addr)
+	if !ok {
+		return fmt.Errorf("[setNodeLabels] meta node %s is not exist", addr)
+	}
+
+	metaNode := value.(*MetaNode)
+	oldLabels := metaNode.Labels
+	metaNode.Labels = labels
+
+	if err = m.cluster.syncUpdateMetaNode(metaNode); err != nil {
+		metaNode.Labels = oldLabels
+		return fmt.Errorf("[setNodeLabels] syncUpdateMetaNode err(%s)", err.Error())
+	}
+
+	return
+}
+
 func (m *Server) updateNodesetCapcity(zoneName string, nodesetId uint64, capcity uint64) (err error) {
 	var ns *nodeSet
 	var ok bool
@@ -3582,6 +3789,58 @@ func parseSetNodeRdOnlyParam(r *http.Request) (addr string, nodeType uint32, rdO
 	return
 }
 
+func parseSetNodeAllocWeightParam(r *http.Request) (addr string, nodeType uint32, weight float64, err error) {
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+
+	if addr = r.FormValue(addrKey); addr == "" {
+		err = fmt.Errorf("parseSetNodeAllocWeightParam %s is empty", addrKey)
+		return
+	}
+
+	if nodeType, err = parseNodeType(r); err != nil {
+		return
+	}
+
+	val := r.FormValue(allocWeightKey)
+	if val == "" {
+		err = fmt.Errorf("parseSetNodeAllocWeightParam %s is empty", allocWeightKey)
+		return
+	}
+
+	// 0 means to reset the weight to the config or default value
+	if weight, err = strconv.ParseFloat(val, 64); err != nil || weight < 0 {
+		err = fmt.Errorf("parseSetNodeAllocWeightParam %s is not a non-negative number %s", allocWeightKey, val)
+		return
+	}
+
+	return
+}
+
+func parseSetNodeLabelsParam(r *http.Request) (addr string, nodeType uint32, labels []string, err error) {
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+
+	if addr = r.FormValue(addrKey); addr == "" {
+		err = fmt.Errorf("parseSetNodeLabelsParam %s is empty", addrKey)
+		return
+	}
+
+	if nodeType, err = parseNodeType(r); err != nil {
+		return
+	}
+
+	// empty labels means to clear the labels of the node
+	if labels, err = parseLabels(r.FormValue(labelsKey)); err != nil {
+		err = fmt.Errorf("parseSetNodeLabelsParam %s", err.Error())
+		return
+	}
+
+	return
+}
+
 func parseSetDpRdOnlyParam(r *http.Request) (dpId uint64, rdOnly bool, err error) {
 	if err = r.ParseForm(); err != nil {
 		return
@@ -3657,6 +3916,64 @@ func (m *Server) setNodeRdOnlyHandler(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("[setNodeRdOnlyHandler] set node %s to rdOnly(%v) success", addr, rdOnly)))
 }
 
+func (m *Server) setNodeAllocWeightHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		addr     string
+		nodeType uint32
+		weight   float64
+		err      error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminSetNodeAllocWeight))
+	defer func() {
+		doStatAndMetric(proto.AdminSetNodeAllocWeight, metric, err, nil)
+	}()
+
+	addr, nodeType, weight, err = parseSetNodeAllocWeightParam(r)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	log.LogInfof("[setNodeAllocWeightHandler] set node %s alloc weight(%v)", addr, weight)
+
+	if err = m.setNodeAllocWeight(addr, nodeType, weight); err != nil {
+		log.LogErrorf("[setNodeAllocWeightHandler] set node %s alloc weight %v, err (%s)", addr, weight, err.Error())
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("[setNodeAllocWeightHandler] set node %s alloc weight(%v) success", addr, weight)))
+}
+
+func (m *Server) setNodeLabelsHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		addr     string
+		nodeType uint32
+		labels   []string
+		err      error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminSetNodeLabels))
+	defer func() {
+		doStatAndMetric(proto.AdminSetNodeLabels, metric, err, nil)
+	}()
+
+	addr, nodeType, labels, err = parseSetNodeLabelsParam(r)
+	if err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	log.LogInfof("[setNodeLabelsHandler] set node %s labels(%v)", addr, labels)
+
+	if err = m.setNodeLabels(addr, nodeType, labels); err != nil {
+		log.LogErrorf("[setNodeLabelsHandler] set node %s labels %v, err (%s)", addr, labels, err.Error())
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("[setNodeLabelsHandler] set node %s labels(%v) success", addr, labels)))
+}
+
 func (m *Server) setDpRdOnlyHandler(w http.ResponseWriter, r *http.Request) {
 	var (
 		dpId   uint64
@@ -4497,6 +4814,8 @@ func (m *Server) getMetaNode(w http.ResponseWriter, r *http.Request) {
 		PersistenceMetaPartitions: metaNode.PersistenceMetaPartitions,
 		CanAllowPartition:         metaNode.IsWriteAble() && metaNode.PartitionCntLimited(),
 		MaxMpCntLimit:             metaNode.GetPartitionLimitCnt(),
+		AllocWeight:               metaNode.GetAllocWeight(),
+		Labels:                    metaNode.Labels,
 		CpuUtil:                   metaNode.CpuUtil.Load(),
 	}
 	sendOkReply(w, r, newSuccessHTTPReply(metaNodeInfo))
@@ -6041,7 +6360,14 @@ func (m *Server) CreateQuota(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if quotaId, err = vol.quotaManager.createQuota(req); err != nil {
+	quotaId, err = vol.quotaManager.createQuota(req)
+	paths := make([]string, 0, len(req.PathInfos))
+	for _, pathInfo := range req.PathInfos {
+		paths = append(paths, pathInfo.FullPath)
+	}
+	m.recordVolAudit(r, req.VolName, volAuditOpQuotaCreate, fmt.Sprintf("quotaId[%v] paths%v maxFiles[%v] maxBytes[%v]",
+		quotaId, paths, req.MaxFiles, req.MaxBytes), err)
+	if err != nil {
 		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
@@ -6071,7 +6397,10 @@ func (m *Server) UpdateQuota(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = vol.quotaManager.updateQuota(req); err != nil {
+	err = vol.quotaManager.updateQuota(req)
+	m.recordVolAudit(r, req.VolName, volAuditOpQuotaUpdate, fmt.Sprintf("quotaId[%v] maxFiles[%v] maxBytes[%v]",
+		req.QuotaId, req.MaxFiles, req.MaxBytes), err)
+	if err != nil {
 		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
@@ -6104,7 +6433,9 @@ func (m *Server) DeleteQuota(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = vol.quotaManager.deleteQuota(quotaId); err != nil {
+	err = vol.quotaManager.deleteQuota(quotaId)
+	m.recordVolAudit(r, name, volAuditOpQuotaDelete, fmt.Sprintf("quotaId[%v]", quotaId), err)
+	if err != nil {
 		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
@@ -6333,6 +6664,250 @@ func (m *Server) getDiscardDpHandler(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(DiscardDpInfos))
 }
 
+func (m *Server) getTopView(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		sortBy string
+		limit  int
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminGetTopView))
+	defer func() {
+		doStatAndMetric(proto.AdminGetTopView, metric, err, nil)
+	}()
+
+	if sortBy, limit, err = parseRequestToGetTopView(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.getTopView(sortBy, limit)))
+}
+
+// Simulate the effect of removing nodes or zones and adding nodes on the partitions, the cluster is not changed.
+func (m *Server) simulatePlacement(w http.ResponseWriter, r *http.Request) {
+	var (
+		err          error
+		removeNodes  []string
+		removeZones  []string
+		addDataNodes int
+		addMetaNodes int
+		zoneName     string
+		result       *proto.PlacementSimulation
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminSimulatePlacement))
+	defer func() {
+		doStatAndMetric(proto.AdminSimulatePlacement, metric, err, nil)
+	}()
+
+	if removeNodes, removeZones, addDataNodes, addMetaNodes, zoneName, err = parseRequestToSimulatePlacement(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if result, err = m.cluster.simulatePlacement(removeNodes, removeZones, addDataNodes, addMetaNodes, zoneName); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(result))
+}
+
+// Start to roll out the value of a cluster setting to the canary nodes, it is promoted to all the nodes
+// after the observation, or rolled back once the canary nodes regress.
+func (m *Server) startConfigRollout(w http.ResponseWriter, r *http.Request) {
+	var (
+		err     error
+		args    *configRolloutArgs
+		rollout *proto.ConfigRollout
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminStartConfigRollout))
+	defer func() {
+		doStatAndMetric(proto.AdminStartConfigRollout, metric, err, nil)
+	}()
+
+	if args, err = parseRequestToStartConfigRollout(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if rollout, err = m.cluster.startConfigRollout(args.key, args.value, args.canaryNodes, args.canaryLabel,
+		args.canaryPercent, args.observeSec, args.maxErrRateDelta); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(rollout))
+}
+
+func (m *Server) getConfigRollout(w http.ResponseWriter, r *http.Request) {
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminGetConfigRollout))
+	defer func() {
+		doStatAndMetric(proto.AdminGetConfigRollout, metric, nil, nil)
+	}()
+
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.configRollout.view()))
+}
+
+// Promote the rollout in progress to all the nodes without waiting for the observation.
+func (m *Server) promoteConfigRollout(w http.ResponseWriter, r *http.Request) {
+	var (
+		err     error
+		rollout *proto.ConfigRollout
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminPromoteConfigRollout))
+	defer func() {
+		doStatAndMetric(proto.AdminPromoteConfigRollout, metric, err, nil)
+	}()
+
+	if rollout, err = m.cluster.promoteConfigRollout("promoted manually"); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(rollout))
+}
+
+// Roll back the rollout in progress, the canary nodes get the value of the cluster again.
+func (m *Server) rollbackConfigRollout(w http.ResponseWriter, r *http.Request) {
+	var (
+		err     error
+		rollout *proto.ConfigRollout
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminRollbackConfigRollout))
+	defer func() {
+		doStatAndMetric(proto.AdminRollbackConfigRollout, metric, err, nil)
+	}()
+
+	if rollout, err = m.cluster.rollbackConfigRollout("rolled back manually"); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(rollout))
+}
+
+// Set the mode of a feature flag, which is off, optIn for the volumes opted in only, or on for all the volumes.
+func (m *Server) setFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	var (
+		err     error
+		feature string
+		mode    string
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminSetFeatureFlag))
+	defer func() {
+		doStatAndMetric(proto.AdminSetFeatureFlag, metric, err, nil)
+	}()
+
+	if feature, mode, err = parseRequestToSetFeatureFlag(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = checkFeatureFlag(feature); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = checkFeatureFlagMode(mode); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.setFeatureFlag(feature, mode); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("set feature flag [%v] to [%v] successfully", feature, mode)))
+}
+
+func (m *Server) getFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminGetFeatureFlags))
+	defer func() {
+		doStatAndMetric(proto.AdminGetFeatureFlags, metric, nil, nil)
+	}()
+
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.getFeatureFlags()))
+}
+
+// Opt the volume in or out of a feature flag, which takes effect for the volume once the flag is optIn.
+func (m *Server) setVolFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	var (
+		err     error
+		name    string
+		feature string
+		enable  bool
+		vol     *Vol
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminVolSetFeatureFlag))
+	defer func() {
+		doStatAndMetric(proto.AdminVolSetFeatureFlag, metric, err, map[string]string{exporter.Vol: name})
+	}()
+
+	if name, feature, enable, err = parseRequestToSetVolFeatureFlag(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = checkFeatureFlag(feature); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if vol, err = m.cluster.getVol(name); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.setVolFeatureOptIn(vol, feature, enable); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.getVolFeatureFlags(vol)))
+}
+
+// Get the feature flags enabled for the volume, which are queried by the components serving it.
+func (m *Server) getVolFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	var (
+		err  error
+		name string
+		vol  *Vol
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.ClientVolFeatureFlags))
+	defer func() {
+		doStatAndMetric(proto.ClientVolFeatureFlags, metric, err, map[string]string{exporter.Vol: name})
+	}()
+
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if vol, err = m.cluster.getVol(name); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(proto.ErrVolNotExists))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.getVolFeatureFlags(vol)))
+}
+
+// Set the client version which clients with auto upgrade enabled will switch to.
+// An empty version disables auto upgrade.
+func (m *Server) setClientUpgrade(w http.ResponseWriter, r *http.Request) {
+	var (
+		err  error
+		info *proto.ClientUpgradeInfo
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminSetClientUpgrade))
+	defer func() {
+		doStatAndMetric(proto.AdminSetClientUpgrade, metric, err, nil)
+	}()
+
+	if info, err = parseRequestToSetClientUpgrade(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.setClientUpgrade(info); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("set client upgrade version to [%v] successfully", info.Version)))
+}
+
+func (m *Server) getClientUpgrade(w http.ResponseWriter, r *http.Request) {
+	var err error
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminGetClientUpgrade))
+	defer func() {
+		doStatAndMetric(proto.AdminGetClientUpgrade, metric, err, nil)
+	}()
+
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.getClientUpgrade()))
+}
+
 func (m *Server) queryBadDisks(w http.ResponseWriter, r *http.Request) {
 	var (
 		err   error
@@ -16,13 +16,17 @@ package master
 
 import (
 	"bytes"
+	"crypto/ed25519"
 	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/pprof"
+	"net/url"
 	"os"
 	"strings"
 	"sync"
@@ -498,11 +502,114 @@ func TestMarkDeleteVol(t *testing.T) {
 	}
 }
 
+func TestMarkDeleteVolRetention(t *testing.T) {
+	name := "retentionVol"
+	createVol(map[string]interface{}{nameKey: name}, t)
+	vol, err := server.cluster.getVol(name)
+	require.NoError(t, err)
+	deleteURL := fmt.Sprintf("%v%v?name=%v&authKey=%v", hostAddr, proto.AdminDeleteVol, name, buildAuthKey(testOwner))
+	restoreURL := fmt.Sprintf("%v&%v=false", deleteURL, deleteVolKey)
+	isDelayDeleted := func() bool {
+		server.cluster.deleteVolMutex.RLock()
+		defer server.cluster.deleteVolMutex.RUnlock()
+		for _, info := range server.cluster.delayDeleteVolsInfo {
+			if info.volName == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	// mark delete in the retention window
+	process(deleteURL, t)
+	require.Equal(t, proto.VolStatusMarkDelete, vol.Status)
+	require.True(t, vol.Forbidden)
+	require.True(t, time.Until(vol.DeleteExecTime) > time.Duration(server.config.volDelayDeleteTimeHour-1)*time.Hour)
+	require.True(t, isDelayDeleted())
+	reply := processNoCheck(deleteURL, t)
+	require.EqualValues(t, proto.ErrCodeVolHasDeleted, reply.Code)
+	server.cluster.checkDelayDeleteVols()
+	require.True(t, isDelayDeleted())
+
+	// restore in the retention window
+	process(restoreURL, t)
+	require.Equal(t, proto.VolStatusNormal, vol.Status)
+	require.False(t, vol.Forbidden)
+	require.True(t, vol.DeleteExecTime.IsZero())
+	require.False(t, isDelayDeleted())
+	reply = processNoCheck(restoreURL, t)
+	require.EqualValues(t, proto.ErrCodeVolNotDelete, reply.Code)
+	userInfo, err := server.user.getUserInfo(testOwner)
+	require.NoError(t, err)
+	require.True(t, contains(userInfo.Policy.OwnVols, name))
+
+	// purge after the retention window expired
+	process(deleteURL, t)
+	expired := time.Now().Add(-time.Second)
+	server.cluster.deleteVolMutex.Lock()
+	vol.DeleteExecTime = expired
+	for _, info := range server.cluster.delayDeleteVolsInfo {
+		if info.volName == name {
+			info.execTime = expired
+		}
+	}
+	server.cluster.deleteVolMutex.Unlock()
+	reply = processNoCheck(restoreURL, t)
+	require.EqualValues(t, proto.ErrCodeVolNotDelete, reply.Code)
+	require.Equal(t, proto.VolStatusMarkDelete, vol.Status)
+
+	server.cluster.checkDelayDeleteVols()
+	require.False(t, isDelayDeleted())
+	require.Eventually(t, func() bool {
+		userInfo, err := server.user.getUserInfo(testOwner)
+		return err == nil && !contains(userInfo.Policy.OwnVols, name)
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
 func TestSetVolCapacity(t *testing.T) {
 	setVolCapacity(600, proto.AdminVolExpand, t)
 	setVolCapacity(300, proto.AdminVolShrink, t)
 }
 
+func TestVolAuditTrail(t *testing.T) {
+	name := "auditVol"
+	createVol(map[string]interface{}{nameKey: name}, t)
+
+	req := map[string]interface{}{
+		nameKey:        name,
+		volCapacityKey: 400,
+		volAuthKey:     buildAuthKey(testOwner),
+	}
+	processWithFatalV2(proto.AdminVolExpand, true, req, t)
+	req[volAuthKey] = buildAuthKey("invalid")
+	processWithFatalV2(proto.AdminVolShrink, false, req, t)
+
+	reply := processWithFatalV2(proto.AdminVolAuditTrail, true, map[string]interface{}{nameKey: name}, t)
+	records := make([]*proto.VolAuditRecord, 0)
+	require.NoError(t, json.Unmarshal(reply.Data, &records))
+	require.Equal(t, 3, len(records))
+	require.Equal(t, volAuditOpCreate, records[0].Op)
+	require.Equal(t, volAuditOpExpand, records[1].Op)
+	require.Equal(t, "capacity[300->400]", records[1].Detail)
+	require.Equal(t, volAuditResultOk, records[1].Result)
+	require.Equal(t, volAuditOpShrink, records[2].Op)
+	require.NotEqual(t, volAuditResultOk, records[2].Result)
+	for _, record := range records {
+		require.Equal(t, name, record.Vol)
+		require.Equal(t, volAuditAnonymous, record.Principal)
+	}
+
+	// the newest records are returned with limit
+	reply = processWithFatalV2(proto.AdminVolAuditTrail, true, map[string]interface{}{nameKey: name, Limit: 1}, t)
+	require.NoError(t, json.Unmarshal(reply.Data, &records))
+	require.Equal(t, 1, len(records))
+	require.Equal(t, volAuditOpShrink, records[0].Op)
+
+	reply = processWithFatalV2(proto.AdminVolAuditTrail, true, map[string]interface{}{nameKey: name, endTimeKey: 1}, t)
+	require.NoError(t, json.Unmarshal(reply.Data, &records))
+	require.Empty(t, records)
+}
+
 func TestPreloadDp(t *testing.T) {
 	volName := "preloadVol"
 	req := map[string]interface{}{}
@@ -1318,6 +1425,69 @@ func TestListUsersOfVol(t *testing.T) {
 	process(reqURL, t)
 }
 
+func TestUserCapacityQuota(t *testing.T) {
+	userID := "quota_user"
+	if _, err := server.user.createKey(&proto.UserCreateParam{ID: userID, Type: proto.UserTypeNormal}); err != nil {
+		t.Error(err)
+		return
+	}
+	defer server.user.deleteKey(userID)
+	if _, err := server.user.addOwnVol(userID, commonVolName); err != nil {
+		t.Error(err)
+		return
+	}
+	defer server.user.removeOwnVol(userID, commonVolName)
+	vol, err := server.cluster.getVol(commonVolName)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	quota := vol.totalUsedSpace()/util.GB + 1
+	reqURL := fmt.Sprintf("%v%v", hostAddr, proto.UserSetQuota)
+	data, err := json.Marshal(&proto.UserQuotaParam{UserID: userID, CapacityQuota: quota})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	post(reqURL, data, t)
+	userInfo, err := server.user.getUserInfo(userID)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if userInfo.CapacityQuota != quota {
+		t.Errorf("expect capacity quota %v, but is %v", quota, userInfo.CapacityQuota)
+		return
+	}
+	reqURL = fmt.Sprintf("%v%v?user=%v", hostAddr, proto.UserGetUsage, userID)
+	process(reqURL, t)
+	usage := server.cluster.getUserUsage(userInfo)
+	if usage.OverQuota || len(usage.Vols) != 1 || usage.Vols[0].Name != commonVolName {
+		t.Errorf("unexpected usage %+v", usage)
+		return
+	}
+
+	// exceed the quota by the used space of partitions
+	userInfo.CapacityQuota = 1
+	defer func() {
+		userInfo.CapacityQuota = 0
+		server.cluster.checkUserCapacityQuota()
+	}()
+	for _, dp := range vol.dataPartitions.clonePartitions() {
+		dp.used = util.GB
+	}
+	server.cluster.checkUserCapacityQuota()
+	if !vol.IsReadOnlyForUserQuota() {
+		t.Errorf("expect vol %v readonly for user quota", commonVolName)
+		return
+	}
+	if err = server.cluster.checkOwnerCapacityQuota(userID); err != proto.ErrUserCapacityQuotaExceeded {
+		t.Errorf("expect err ErrUserCapacityQuotaExceeded, but err is %v", err)
+		return
+	}
+}
+
 func TestListNodeSets(t *testing.T) {
 	reqURL := fmt.Sprintf("%v%v", hostAddr, proto.GetAllNodeSets)
 	process(reqURL, t)
@@ -1655,6 +1825,52 @@ func TestSetEnableAutoDpMetaRepair(t *testing.T) {
 	require.EqualValues(t, oldVal, server.cluster.getEnableAutoDpMetaRepair())
 }
 
+func TestSetClientUpgrade(t *testing.T) {
+	_, priKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("client binary"))
+	info := proto.ClientUpgradeInfo{
+		Version:   "3.3.1",
+		URL:       "http://127.0.0.1/cfs-client?v=3.3.1",
+		Checksum:  hex.EncodeToString(digest[:]),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(priKey, digest[:])),
+	}
+	setURL := func(info proto.ClientUpgradeInfo) string {
+		return fmt.Sprintf("%v%v?%v=%v&%v=%v&%v=%v&%v=%v", hostAddr, proto.AdminSetClientUpgrade,
+			clientVersion, url.QueryEscape(info.Version), clientUpgradeURLKey, url.QueryEscape(info.URL),
+			clientUpgradeChecksumKey, url.QueryEscape(info.Checksum), clientUpgradeSignatureKey, url.QueryEscape(info.Signature))
+	}
+	getClientUpgrade := func() (got proto.ClientUpgradeInfo) {
+		reply := process(fmt.Sprintf("%v%v", hostAddr, proto.AdminGetClientUpgrade), t)
+		data, err := json.Marshal(reply.Data)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &got))
+		return
+	}
+	defer process(setURL(proto.ClientUpgradeInfo{}), t)
+
+	process(setURL(info), t)
+	require.Equal(t, info, getClientUpgrade())
+	require.Equal(t, info, newClusterValue(server.cluster).ClientUpgrade)
+
+	// the invalid args are rejected, and the version set is kept
+	badChecksum := info
+	badChecksum.Version, badChecksum.Checksum = "3.3.2", "abc"
+	badSignature := info
+	badSignature.Version, badSignature.Signature = "3.3.2", base64.StdEncoding.EncodeToString([]byte("sig"))
+	noURL := info
+	noURL.Version, noURL.URL = "3.3.2", ""
+	for _, bad := range []proto.ClientUpgradeInfo{badChecksum, badSignature, noURL} {
+		reply := processNoCheck(setURL(bad), t)
+		require.EqualValues(t, proto.ErrCodeParamError, reply.Code)
+	}
+	require.Equal(t, info, getClientUpgrade())
+
+	// the empty version disables auto upgrade
+	process(setURL(proto.ClientUpgradeInfo{Version: "", URL: info.URL}), t)
+	require.Equal(t, proto.ClientUpgradeInfo{}, getClientUpgrade())
+}
+
 func TestSetDpTimeout(t *testing.T) {
 	reqUrl := fmt.Sprintf("%v%v", hostAddr, proto.AdminSetNodeInfo)
 	oldVal := server.cluster.getDataPartitionTimeoutSec()
@@ -1750,3 +1966,27 @@ func TestUpdateVolAutoDpMetaRepair(t *testing.T) {
 	process(unsetUrl, t)
 	require.EqualValues(t, oldVal, vol.EnableAutoMetaRepair.Load())
 }
+
+func TestVolFsyncPolicy(t *testing.T) {
+	name := "fsyncPolicyVol"
+	createVol(map[string]interface{}{nameKey: name, fsyncPolicyKey: proto.FsyncPolicyAlways}, t)
+	vol, err := server.cluster.getVol(name)
+	require.NoError(t, err)
+	defer func() {
+		reqURL := fmt.Sprintf("%v%v?name=%v&authKey=%v", hostAddr, proto.AdminDeleteVol, name, buildAuthKey(testOwner))
+		process(reqURL, t)
+	}()
+	require.EqualValues(t, proto.FsyncPolicyAlways, vol.fsyncPolicy)
+	require.EqualValues(t, proto.FsyncPolicyAlways, getSimpleVol(name, true, t).FsyncPolicy)
+
+	reqUrl := fmt.Sprintf("%v%v?%v=%v&%v=%v", hostAddr, proto.AdminUpdateVol, nameKey, vol.Name, volAuthKey, buildAuthKey(testOwner))
+	// the policy is kept if absent, and the illegal one is rejected
+	process(reqUrl, t)
+	require.EqualValues(t, proto.FsyncPolicyAlways, vol.fsyncPolicy)
+	reply := processNoCheck(fmt.Sprintf("%v&%v=%v", reqUrl, fsyncPolicyKey, "sometimes"), t)
+	require.NotEqualValues(t, 0, reply.Code)
+	require.EqualValues(t, proto.FsyncPolicyAlways, vol.fsyncPolicy)
+
+	process(fmt.Sprintf("%v&%v=%v", reqUrl, fsyncPolicyKey, proto.FsyncPolicyNever), t)
+	require.EqualValues(t, proto.FsyncPolicyNever, vol.fsyncPolicy)
+}
@@ -261,11 +261,14 @@ func (m *Server) transferUserVol(w http.ResponseWriter, r *http.Request) {
 		sendErrReply(w, r, newErrHTTPReply(proto.ErrHaveNoPolicy))
 		return
 	}
+	owner := vol.Owner
+	defer func() {
+		m.recordVolAudit(r, volName, volAuditOpTransfer, fmt.Sprintf("owner[%v->%v] force[%v]", owner, param.UserDst, param.Force), err)
+	}()
 	if userInfo, err = m.user.transferVol(&param); err != nil {
 		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
-	owner := vol.Owner
 	vol.Owner = userInfo.UserID
 	if err = m.cluster.syncUpdateVol(vol); err != nil {
 		vol.Owner = owner
@@ -317,6 +320,55 @@ func (m *Server) getUsersOfVol(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(users))
 }
 
+func (m *Server) setUserQuota(w http.ResponseWriter, r *http.Request) {
+	var (
+		userInfo *proto.UserInfo
+		bytes    []byte
+		err      error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.UserSetQuota))
+	defer func() {
+		doStatAndMetric(proto.UserSetQuota, metric, err, nil)
+	}()
+
+	if bytes, err = io.ReadAll(r.Body); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	param := proto.UserQuotaParam{}
+	if err = json.Unmarshal(bytes, &param); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if userInfo, err = m.user.setCapacityQuota(&param); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(userInfo))
+}
+
+func (m *Server) getUserUsage(w http.ResponseWriter, r *http.Request) {
+	var (
+		userID   string
+		userInfo *proto.UserInfo
+		err      error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.UserGetUsage))
+	defer func() {
+		doStatAndMetric(proto.UserGetUsage, metric, err, nil)
+	}()
+
+	if userID, err = parseUser(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if userInfo, err = m.user.getUserInfo(userID); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.getUserUsage(userInfo)))
+}
+
 func parseUser(r *http.Request) (userID string, err error) {
 	if err = r.ParseForm(); err != nil {
 		return
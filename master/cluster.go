@@ -47,6 +47,7 @@ type Cluster struct {
 	Name                         string
 	CreateTime                   int64
 	vols                         map[string]*Vol
+	volAliases                   map[string]string // previous name -> current name of renamed vols
 	delayDeleteVolsInfo          []*delayDeleteVolInfo
 	stopc                        chan bool
 	dataNodes                    sync.Map
@@ -81,6 +82,7 @@ type Cluster struct {
 	lastZoneIdxForNode           int
 	zoneIdxMux                   sync.Mutex //
 	followerReadManager          *followerReadManager
+	configRollout                *configRolloutManager
 	diskQosEnable                bool
 	QosAcceptLimit               *rate.Limiter
 	apiLimiter                   *ApiLimiter
@@ -98,6 +100,7 @@ type Cluster struct {
 	maxInodeNotEqualMP           *sync.Map
 	dentryCountNotEqualMP        *sync.Map
 	ac                           *authSDK.AuthClient
+	user                         *User
 	authenticate                 bool
 	lcNodes                      sync.Map
 	lcMgr                        *lifecycleManager
@@ -106,6 +109,10 @@ type Cluster struct {
 	S3ApiQosQuota                *sync.Map // (api,uid,limtType) -> limitQuota
 	MarkDiskBrokenThreshold      atomicutil.Float64
 	EnableAutoDpMetaRepair       atomicutil.Bool
+	alertMgr                     *alertManager
+	clientUpgrade                atomic.Value // *proto.ClientUpgradeInfo
+	featureFlags                 atomic.Value // map[string]string, the modes of the feature flags
+	featureFlagMutex             sync.Mutex
 }
 
 type delayDeleteVolInfo struct {
@@ -335,6 +342,7 @@ func newCluster(name string, leaderInfo *LeaderInfo, fsm *MetadataFsm, partition
 	c.Name = name
 	c.leaderInfo = leaderInfo
 	c.vols = make(map[string]*Vol)
+	c.volAliases = make(map[string]string)
 	c.delayDeleteVolsInfo = make([]*delayDeleteVolInfo, 0)
 	c.stopc = make(chan bool)
 	c.cfg = cfg
@@ -352,6 +360,7 @@ func newCluster(name string, leaderInfo *LeaderInfo, fsm *MetadataFsm, partition
 	c.FaultDomain = cfg.faultDomain
 	c.zoneStatInfos = make(map[string]*proto.ZoneStat)
 	c.followerReadManager = newFollowerReadManager(c)
+	c.configRollout = newConfigRolloutManager()
 	c.fsm = fsm
 	c.partition = partition
 	c.idAlloc = newIDAllocator(c.fsm.store, c.partition)
@@ -398,6 +407,7 @@ func (c *Cluster) scheduleTask() {
 	c.scheduleToSnapshotDelVerScan()
 	c.scheduleToBadDisk()
 	c.scheduleToCheckVolUid()
+	c.scheduleToCheckAlertRules()
 }
 
 func (c *Cluster) masterAddr() (addr string) {
@@ -485,39 +495,7 @@ func (c *Cluster) scheduleToCheckDelayDeleteVols() {
 		for {
 			select {
 			case <-ticker.C:
-				if len(c.delayDeleteVolsInfo) == 0 {
-					continue
-				}
-				c.deleteVolMutex.Lock()
-				for index := 0; index < len(c.delayDeleteVolsInfo); index++ {
-					currentDeleteVol := c.delayDeleteVolsInfo[index]
-					log.LogDebugf("action[scheduleToCheckDelayDeleteVols] currentDeleteVol[%v]", currentDeleteVol)
-					if time.Until(currentDeleteVol.execTime) > 0 {
-						continue
-					}
-					go func() {
-						if err := currentDeleteVol.user.deleteVolPolicy(currentDeleteVol.volName); err != nil {
-							msg := fmt.Sprintf("delete vol[%v] failed: err:[%v]", currentDeleteVol.volName, err)
-							log.LogError(msg)
-							return
-						}
-						msg := fmt.Sprintf("delete vol[%v] successfully", currentDeleteVol.volName)
-						log.LogWarn(msg)
-					}()
-					if len(c.delayDeleteVolsInfo) == 1 {
-						c.delayDeleteVolsInfo = make([]*delayDeleteVolInfo, 0)
-						continue
-					}
-					if index == 0 {
-						c.delayDeleteVolsInfo = c.delayDeleteVolsInfo[index+1:]
-					} else if index == len(c.delayDeleteVolsInfo)-1 {
-						c.delayDeleteVolsInfo = c.delayDeleteVolsInfo[:index]
-					} else {
-						c.delayDeleteVolsInfo = append(c.delayDeleteVolsInfo[:index], c.delayDeleteVolsInfo[index+1:]...)
-					}
-				}
-				c.deleteVolMutex.Unlock()
-
+				c.checkDelayDeleteVols()
 			case <-c.stopc:
 				ticker.Stop()
 				return
@@ -526,6 +504,33 @@ func (c *Cluster) scheduleToCheckDelayDeleteVols() {
 	}()
 }
 
+// checkDelayDeleteVols purges the vols marked delete whose retention window expired.
+func (c *Cluster) checkDelayDeleteVols() {
+	c.deleteVolMutex.Lock()
+	defer c.deleteVolMutex.Unlock()
+	if len(c.delayDeleteVolsInfo) == 0 {
+		return
+	}
+	remained := make([]*delayDeleteVolInfo, 0, len(c.delayDeleteVolsInfo))
+	for _, currentDeleteVol := range c.delayDeleteVolsInfo {
+		log.LogDebugf("action[checkDelayDeleteVols] currentDeleteVol[%v]", currentDeleteVol)
+		if time.Until(currentDeleteVol.execTime) > 0 {
+			remained = append(remained, currentDeleteVol)
+			continue
+		}
+		go func(currentDeleteVol *delayDeleteVolInfo) {
+			if err := currentDeleteVol.user.deleteVolPolicy(currentDeleteVol.volName); err != nil {
+				msg := fmt.Sprintf("delete vol[%v] failed: err:[%v]", currentDeleteVol.volName, err)
+				log.LogError(msg)
+				return
+			}
+			msg := fmt.Sprintf("delete vol[%v] successfully", currentDeleteVol.volName)
+			log.LogWarn(msg)
+		}(currentDeleteVol)
+	}
+	c.delayDeleteVolsInfo = remained
+}
+
 func (c *Cluster) scheduleToCheckDataPartitions() {
 	go func() {
 		for {
@@ -641,10 +646,12 @@ func (c *Cluster) checkDataPartitions() {
 		}
 	}()
 
+	c.checkUserCapacityQuota()
 	vols := c.allVols()
 	for _, vol := range vols {
 		readWrites := vol.checkDataPartitions(c)
 		vol.dataPartitions.setReadWriteDataPartitions(readWrites, c.Name)
+		c.updateSharedPartitions(vol)
 		if c.metaReady {
 			vol.dataPartitions.updateResponseCache(true, 0, vol)
 			vol.dataPartitions.updateCompressCache(true, 0, vol)
@@ -709,6 +716,7 @@ func (c *Cluster) scheduleToCheckHeartbeat() {
 			if c.partition != nil && c.partition.IsRaftLeader() {
 				c.checkLeaderAddr()
 				c.checkDataNodeHeartbeat()
+				c.checkConfigRollout()
 				// update load factor
 				setOverSoldFactor(c.cfg.ClusterLoadFactor)
 			}
@@ -742,11 +750,14 @@ func (c *Cluster) checkLeaderAddr() {
 
 func (c *Cluster) checkDataNodeHeartbeat() {
 	tasks := make([]*proto.AdminTask, 0)
+	writeEpochs := c.getDataPartitionWriteEpochs()
 	c.dataNodes.Range(func(addr, dataNode interface{}) bool {
 		node := dataNode.(*DataNode)
 		node.checkLiveness()
 		task := node.createHeartbeatTask(c.masterAddr(), c.diskQosEnable)
 		hbReq := task.Request.(*proto.HeartBeatRequest)
+		hbReq.DpWriteEpochs = writeEpochs[node.Addr]
+		c.configRollout.applyCanary(DataNodeType, node.Addr, hbReq)
 		c.volMutex.RLock()
 		defer c.volMutex.RUnlock()
 		for _, vol := range c.vols {
@@ -756,6 +767,9 @@ func (c *Cluster) checkDataNodeHeartbeat() {
 			if vol.dpRepairBlockSize != proto.DefaultDpRepairBlockSize {
 				hbReq.VolDpRepairBlockSize[vol.Name] = vol.dpRepairBlockSize
 			}
+			if vol.fsyncPolicy != proto.DefaultFsyncPolicy {
+				hbReq.VolFsyncPolicy[vol.Name] = vol.fsyncPolicy
+			}
 		}
 		tasks = append(tasks, task)
 		return true
@@ -773,6 +787,7 @@ func (c *Cluster) checkMetaNodeHeartbeat() {
 		node.checkHeartbeat()
 		task := node.createHeartbeatTask(c.masterAddr(), c.fileStatsEnable)
 		hbReq := task.Request.(*proto.HeartBeatRequest)
+		c.configRollout.applyCanary(MetaNodeType, node.Addr, hbReq)
 
 		for _, vol := range c.vols {
 			if vol.FollowerRead {
@@ -1007,6 +1022,7 @@ func (c *Cluster) addMetaNode(nodeAddr, zoneName string, nodesetId uint64) (id u
 
 	metaNode = newMetaNode(nodeAddr, zoneName, c.Name)
 	metaNode.MpCntLimit = newLimitCounter(&c.cfg.MaxMpCntLimit, defaultMaxMpCntLimit)
+	metaNode.configAllocWeight = c.cfg.nodeAllocWeights[nodeAddr]
 	zone, err := c.t.getZone(zoneName)
 	if err != nil {
 		zone = c.t.putZoneIfAbsent(newZone(zoneName))
@@ -1071,6 +1087,7 @@ func (c *Cluster) addDataNode(nodeAddr, zoneName string, nodesetId uint64) (id u
 
 	dataNode = newDataNode(nodeAddr, zoneName, c.Name)
 	dataNode.DpCntLimit = newLimitCounter(&c.cfg.MaxDpCntLimit, defaultMaxDpCntLimit)
+	dataNode.configAllocWeight = c.cfg.nodeAllocWeights[nodeAddr]
 	zone, err := c.t.getZone(zoneName)
 	if err != nil {
 		zone = c.t.putZoneIfAbsent(newZone(zoneName))
@@ -1269,6 +1286,9 @@ func (c *Cluster) putVol(vol *Vol) {
 	defer c.volMutex.Unlock()
 	if _, ok := c.vols[vol.Name]; !ok {
 		c.vols[vol.Name] = vol
+		for _, name := range vol.previousNames {
+			c.volAliases[name] = vol.Name
+		}
 	}
 }
 
@@ -1328,6 +1348,12 @@ func (c *Cluster) getVol(volName string) (vol *Vol, err error) {
 	defer c.volMutex.RUnlock()
 	vol, ok := c.vols[volName]
 	if !ok {
+		// resolve the previous name of renamed vol
+		if name, renamed := c.volAliases[volName]; renamed {
+			if vol, ok = c.vols[name]; ok {
+				return
+			}
+		}
 		err = proto.ErrVolNotExists
 	}
 	return
@@ -1336,6 +1362,11 @@ func (c *Cluster) getVol(volName string) (vol *Vol, err error) {
 func (c *Cluster) deleteVol(name string) {
 	c.volMutex.Lock()
 	defer c.volMutex.Unlock()
+	if vol, ok := c.vols[name]; ok {
+		for _, previousName := range vol.previousNames {
+			delete(c.volAliases, previousName)
+		}
+	}
 	delete(c.vols, name)
 }
 
@@ -1394,6 +1425,11 @@ func (c *Cluster) markDeleteVol(name, authKey string, force bool, isNotCancel bo
 		return proto.ErrVolAuthKeyNotMatch
 	}
 
+	// the clones read the data of the vol
+	if clones := c.getVolClones(vol); len(clones) > 0 {
+		return fmt.Errorf("vol %v is the clone source of %v vols, delete the clones first", vol.Name, len(clones))
+	}
+
 	vol.Status = proto.VolStatusMarkDelete
 	if err = c.syncUpdateVol(vol); err != nil {
 		vol.Status = proto.VolStatusNormal
@@ -1531,7 +1567,8 @@ func (c *Cluster) createDataPartition(volName string, preload *DataPartitionPreL
 		}
 	} else {
 		zoneNum := c.decideZoneNum(vol) // zoneNum scope [1,3]
-		if targetHosts, targetPeers, err = c.getHostFromNormalZone(TypeDataPartition, nil, nil, nil,
+		excludeNodeSets, excludeHosts := c.getLabelExcludes(vol, TypeDataPartition, int(dpReplicaNum), zoneNum)
+		if targetHosts, targetPeers, err = c.getHostFromNormalZone(TypeDataPartition, nil, excludeNodeSets, excludeHosts,
 			int(dpReplicaNum), zoneNum, zoneName); err != nil {
 			goto errHandler
 		}
@@ -1921,6 +1958,23 @@ func (c *Cluster) getAllDataPartitionIDByDatanode(addr string) (partitionIDs []u
 	return
 }
 
+// getDataPartitionWriteEpochs returns the write epochs of the data partitions grouped by the hosts.
+func (c *Cluster) getDataPartitionWriteEpochs() (epochs map[string]map[uint64]uint64) {
+	epochs = make(map[string]map[uint64]uint64)
+	for _, vol := range c.allVols() {
+		for _, dp := range vol.dataPartitions.clonePartitions() {
+			epoch := atomic.LoadUint64(&dp.WriteEpoch)
+			for _, host := range dp.Hosts {
+				if epochs[host] == nil {
+					epochs[host] = make(map[uint64]uint64)
+				}
+				epochs[host][dp.PartitionID] = epoch
+			}
+		}
+	}
+	return
+}
+
 func (c *Cluster) getAllMetaPartitionIDByMetaNode(addr string) (partitionIDs []uint64) {
 	partitionIDs = make([]uint64, 0)
 	safeVols := c.allVols()
@@ -2247,6 +2301,7 @@ func (c *Cluster) migrateDataPartition(srcAddr, targetAddr string, dp *DataParti
 		replica         *DataReplica
 		ns              *nodeSet
 		excludeNodeSets []uint64
+		excludeHosts    []string
 		zones           []string
 	)
 	log.LogDebugf("[migrateDataPartition] src %v target %v raftForce %v", srcAddr, targetAddr, raftForce)
@@ -2295,9 +2350,11 @@ func (c *Cluster) migrateDataPartition(srcAddr, targetAddr string, dp *DataParti
 		goto errHandler
 	}
 
+	// the nodes mismatching the label constraints of the vol are not the targets
+	excludeHosts = append(c.getLabelExcludedHosts(dp.VolName, TypeDataPartition), dp.Hosts...)
 	if targetAddr != "" {
 		targetHosts = []string{targetAddr}
-	} else if targetHosts, _, err = ns.getAvailDataNodeHosts(dp.Hosts, 1); err != nil {
+	} else if targetHosts, _, err = ns.getAvailDataNodeHosts(excludeHosts, 1); err != nil {
 		if _, ok := c.vols[dp.VolName]; !ok {
 			log.LogWarnf("clusterID[%v] partitionID:%v  on node:%v offline failed,PersistenceHosts:[%v]",
 				c.Name, dp.PartitionID, srcAddr, dp.Hosts)
@@ -2310,7 +2367,7 @@ func (c *Cluster) migrateDataPartition(srcAddr, targetAddr string, dp *DataParti
 		}
 		// select data nodes from the other node set in same zone
 		excludeNodeSets = append(excludeNodeSets, ns.ID)
-		if targetHosts, _, err = zone.getAvailNodeHosts(TypeDataPartition, excludeNodeSets, dp.Hosts, 1); err != nil {
+		if targetHosts, _, err = zone.getAvailNodeHosts(TypeDataPartition, excludeNodeSets, excludeHosts, 1); err != nil {
 			// select data nodes from the other zone
 			zones = dp.getLiveZones(srcAddr)
 			var excludeZone []string
@@ -2319,7 +2376,7 @@ func (c *Cluster) migrateDataPartition(srcAddr, targetAddr string, dp *DataParti
 			} else {
 				excludeZone = append(excludeZone, zones[0])
 			}
-			if targetHosts, _, err = c.getHostFromNormalZone(TypeDataPartition, excludeZone, excludeNodeSets, dp.Hosts, 1, 1, ""); err != nil {
+			if targetHosts, _, err = c.getHostFromNormalZone(TypeDataPartition, excludeZone, excludeNodeSets, excludeHosts, 1, 1, ""); err != nil {
 				goto errHandler
 			}
 		}
@@ -3191,6 +3248,9 @@ func (c *Cluster) createVol(req *createVolReq) (vol *Vol, err error) {
 		log.LogWarn("the cluster is frozen")
 		return nil, fmt.Errorf("the cluster is frozen, can not create volume")
 	}
+	if err = c.checkOwnerCapacityQuota(req.owner); err != nil {
+		return
+	}
 
 	var readWriteDataPartitions int
 
@@ -3303,6 +3363,10 @@ func (c *Cluster) doCreateVol(req *createVolReq) (vol *Vol, err error) {
 		Description:             req.description,
 		EnablePosixAcl:          req.enablePosixAcl,
 		EnableQuota:             req.enableQuota,
+		CaseInsensitive:         req.caseInsensitive,
+		AffinityLabels:          req.affinityLabels,
+		AntiAffinityLabels:      req.antiAffinityLabels,
+		FsyncPolicy:             req.fsyncPolicy,
 		EnableTransaction:       req.enableTransaction,
 		TxTimeout:               req.txTimeout,
 		TxConflictRetryNum:      req.txConflictRetryNum,
@@ -3327,6 +3391,8 @@ func (c *Cluster) doCreateVol(req *createVolReq) (vol *Vol, err error) {
 
 		DpReadOnlyWhenVolFull: req.DpReadOnlyWhenVolFull,
 		EnableAutoMetaRepair:  false,
+		CloneSource:           req.cloneSource,
+		CloneVerSeq:           req.cloneVerSeq,
 	}
 
 	log.LogInfof("[doCreateVol] volView, %v", vv)
@@ -3367,7 +3433,217 @@ errHandler:
 	return
 }
 
+// renameVol renames the vol, the previous name is kept as an alias of the vol,
+// so that partitions on nodes and mounted clients referring to it still work.
+func (c *Cluster) renameVol(name, newName, authKey string) (vol *Vol, err error) {
+	if vol, err = c.getVol(name); err != nil || vol.Name != name {
+		log.LogErrorf("action[renameVol] vol[%v] not found", name)
+		return nil, proto.ErrVolNotExists
+	}
+	if vol.status() == proto.VolStatusMarkDelete || vol.Forbidden {
+		log.LogErrorf("action[renameVol] vol[%v] is deleted or forbidden", name)
+		return nil, fmt.Errorf("vol[%v] is deleted or forbidden", name)
+	}
+	if name == newName {
+		return nil, fmt.Errorf("new name is the same as vol[%v]", name)
+	}
+
+	vol.volLock.Lock()
+	defer vol.volLock.Unlock()
+	if !matchKey(vol.Owner, authKey) {
+		return nil, proto.ErrVolAuthKeyNotMatch
+	}
+
+	// name of new vols can't be taken while renaming
+	c.createVolMutex.Lock()
+	defer c.createVolMutex.Unlock()
+	if other, e := c.getVol(newName); e == nil && other != vol {
+		return nil, proto.ErrDuplicateVol
+	}
+
+	oldPreviousNames := vol.previousNames
+	previousNames := make([]string, 0, len(oldPreviousNames)+1)
+	for _, previousName := range oldPreviousNames {
+		if previousName != newName {
+			previousNames = append(previousNames, previousName)
+		}
+	}
+	previousNames = append(previousNames, name)
+	vol.Name = newName
+	vol.previousNames = previousNames
+	if err = c.syncUpdateVol(vol); err != nil {
+		log.LogErrorf("action[renameVol] vol[%v] to [%v] err[%v]", name, newName, err)
+		vol.Name = name
+		vol.previousNames = oldPreviousNames
+		return nil, proto.ErrPersistenceByRaft
+	}
+
+	c.volMutex.Lock()
+	delete(c.vols, name)
+	c.vols[newName] = vol
+	delete(c.volAliases, newName)
+	for _, previousName := range previousNames {
+		c.volAliases[previousName] = newName
+	}
+	c.volMutex.Unlock()
+	c.volStatInfo.Delete(name)
+	if lcConf := c.GetBucketLifecycle(name); lcConf != nil {
+		c.SetBucketLifecycle(&proto.LcConfiguration{VolName: newName, Rules: lcConf.Rules})
+		c.DelBucketLifecycle(name)
+	}
+
+	err = c.syncRenameVolPartitions(vol, newName)
+	vol.updateViewCache(c)
+
+	log.LogWarnf("action[renameVol] vol[%v] is renamed to [%v], previous names %v", name, newName, previousNames)
+	return
+}
+
+// syncRenameVolPartitions persists the new vol name of the partitions and quotas of the renamed vol.
+// They are still renamed in memory if failed, and renamed again on loading by the previous names of vol.
+func (c *Cluster) syncRenameVolPartitions(vol *Vol, newName string) (err error) {
+	vol.dataPartitions.Lock()
+	vol.dataPartitions.volName = newName
+	vol.dataPartitions.Unlock()
+	for _, dp := range vol.dataPartitions.clonePartitions() {
+		dp.Lock()
+		dp.VolName = newName
+		if e := c.syncUpdateDataPartition(dp); e != nil {
+			log.LogErrorf("action[syncRenameVolPartitions] vol[%v] dp[%v] err[%v]", newName, dp.PartitionID, e)
+			err = proto.ErrPersistenceByRaft
+		}
+		dp.Unlock()
+	}
+	for _, mp := range vol.cloneMetaPartitionMap() {
+		mp.Lock()
+		mp.volName = newName
+		if e := c.syncUpdateMetaPartition(mp); e != nil {
+			log.LogErrorf("action[syncRenameVolPartitions] vol[%v] mp[%v] err[%v]", newName, mp.PartitionID, e)
+			err = proto.ErrPersistenceByRaft
+		}
+		mp.Unlock()
+	}
+	if e := vol.quotaManager.renameVol(newName); e != nil {
+		err = proto.ErrPersistenceByRaft
+	}
+	return
+}
+
 // Update the upper bound of the inode ids in a meta partition.
+// cloneVol creates the snapshot version of the source vol, and returns the request to create a new vol
+// with the configuration of the source. The clone shares the data of the snapshot copy-on-write, the
+// metadata of the snapshot is copied into it by the client, see the volume clone of cli.
+func (c *Cluster) cloneVol(name, newName, owner, authKey string) (req *createVolReq, err error) {
+	var src *Vol
+	if src, err = c.getVol(name); err != nil {
+		log.LogErrorf("action[cloneVol] vol[%v] not found", name)
+		return nil, proto.ErrVolNotExists
+	}
+	if src.status() == proto.VolStatusMarkDelete {
+		return nil, proto.ErrVolNotExists
+	}
+	if !matchKey(src.Owner, authKey) {
+		return nil, proto.ErrVolAuthKeyNotMatch
+	}
+	if !proto.IsHot(src.VolType) {
+		return nil, fmt.Errorf("vol[%v] is not a hot vol, only hot vol can be cloned", name)
+	}
+	if _, err = c.getVol(newName); err == nil {
+		return nil, proto.ErrDuplicateVol
+	}
+	if owner == "" {
+		owner = src.Owner
+	}
+
+	src.volLock.RLock()
+	req = &createVolReq{
+		name:                    newName,
+		owner:                   owner,
+		dpSize:                  int(src.dataPartitionSize / util.GB),
+		mpCount:                 defaultInitMetaPartitionCount,
+		dpCount:                 defaultInitDataPartitionCnt,
+		dpReplicaNum:            src.dpReplicaNum,
+		capacity:                int(src.Capacity),
+		deleteLockTime:          src.DeleteLockTime,
+		followerRead:            src.FollowerRead,
+		authenticate:            src.authenticate,
+		crossZone:               src.crossZone,
+		normalZonesFirst:        src.defaultPriority,
+		domainId:                src.domainId,
+		zoneName:                src.zoneName,
+		description:             src.description,
+		volType:                 src.VolType,
+		enablePosixAcl:          src.enablePosixAcl,
+		DpReadOnlyWhenVolFull:   src.DpReadOnlyWhenVolFull,
+		caseInsensitive:         src.caseInsensitive,
+		affinityLabels:          src.affinityLabels,
+		antiAffinityLabels:      src.antiAffinityLabels,
+		fsyncPolicy:             src.fsyncPolicy,
+		enableTransaction:       src.enableTransaction,
+		enableQuota:             src.enableQuota,
+		txTimeout:               src.txTimeout,
+		txConflictRetryNum:      src.txConflictRetryNum,
+		txConflictRetryInterval: src.txConflictRetryInterval,
+		qosLimitArgs: &qosArgs{
+			qosEnable: src.qosManager.qosEnable,
+			iopsRVal:  src.qosManager.getQosLimit(proto.IopsReadType),
+			iopsWVal:  src.qosManager.getQosLimit(proto.IopsWriteType),
+			flowRVal:  src.qosManager.getQosLimit(proto.FlowReadType),
+			flowWVal:  src.qosManager.getQosLimit(proto.FlowWriteType),
+		},
+		clientReqPeriod:     src.qosManager.ClientReqPeriod,
+		clientHitTriggerCnt: src.qosManager.ClientHitTriggerCnt,
+		cloneSource:         src.Name,
+	}
+	src.volLock.RUnlock()
+
+	// the snapshot is kept until the clone is deleted, the extents of it are never deleted by the source
+	var verInfo *proto.VolVersionInfo
+	if verInfo, err = src.VersionMgr.createVer2PhaseTask(c, uint64(time.Now().UnixMicro()), proto.CreateVersion, false); err != nil {
+		log.LogErrorf("action[cloneVol] vol[%v] create snapshot err[%v]", name, err)
+		return nil, err
+	}
+	if verInfo == nil {
+		return nil, fmt.Errorf("vol[%v] failed to create snapshot", name)
+	}
+	req.cloneVerSeq = verInfo.Ver
+	log.LogWarnf("action[cloneVol] vol[%v] is cloned to [%v] at snapshot version[%v]", name, newName, verInfo.Ver)
+	return
+}
+
+// getVolClones returns the vols cloned from the vol.
+func (c *Cluster) getVolClones(src *Vol) (clones []*Vol) {
+	for _, vol := range c.allVols() {
+		if vol.cloneSource == "" {
+			continue
+		}
+		// the source may have been renamed after cloned
+		if source, err := c.getVol(vol.cloneSource); err == nil && source == src {
+			clones = append(clones, vol)
+		}
+	}
+	return
+}
+
+// updateSharedPartitions shares the data partitions of the clone source with the clone read only,
+// the files of the clone read the data of the snapshot from them until rewritten.
+func (c *Cluster) updateSharedPartitions(vol *Vol) {
+	if vol.cloneSource == "" {
+		return
+	}
+	src, err := c.getVol(vol.cloneSource)
+	if err != nil {
+		log.LogErrorf("action[updateSharedPartitions] vol[%v] clone source[%v] not found", vol.Name, vol.cloneSource)
+		return
+	}
+	dpResps := src.dataPartitions.getDataPartitionsView(0)
+	for _, dpResp := range dpResps {
+		dpResp.Status = proto.ReadOnly
+		dpResp.IsShared = true
+	}
+	vol.dataPartitions.setSharedPartitions(dpResps)
+}
+
 func (c *Cluster) updateInodeIDRange(volName string, start uint64) (err error) {
 	var (
 		maxPartitionID uint64
@@ -3818,6 +4094,25 @@ func (c *Cluster) getMarkDiskBrokenThreshold() (v float64) {
 	return
 }
 
+func (c *Cluster) getClientUpgrade() (info *proto.ClientUpgradeInfo) {
+	if val := c.clientUpgrade.Load(); val != nil {
+		return val.(*proto.ClientUpgradeInfo)
+	}
+	return &proto.ClientUpgradeInfo{}
+}
+
+func (c *Cluster) setClientUpgrade(info *proto.ClientUpgradeInfo) (err error) {
+	oldVal := c.getClientUpgrade()
+	c.clientUpgrade.Store(info)
+	if err = c.syncPutCluster(); err != nil {
+		log.LogErrorf("[setClientUpgrade] failed to set client upgrade, err(%v)", err)
+		c.clientUpgrade.Store(oldVal)
+		err = proto.ErrPersistenceByRaft
+		return
+	}
+	return
+}
+
 func (c *Cluster) setEnableAutoDpMetaRepair(val bool) (err error) {
 	oldVal := c.EnableAutoDpMetaRepair.Load()
 	c.EnableAutoDpMetaRepair.Store(val)
@@ -3901,6 +4196,7 @@ func (c *Cluster) clearVols() {
 		}
 	}()
 	c.vols = make(map[string]*Vol)
+	c.volAliases = make(map[string]string)
 }
 
 func (c *Cluster) clearTopology() {
@@ -49,6 +49,9 @@ const (
 	disableAutoCreate                   = "disableAutoCreate"
 	cfgMonitorPushAddr                  = "monitorPushAddr"
 	intervalToScanS3Expiration          = "intervalToScanS3Expiration"
+	cfgAlertRules                       = "alertRules"
+	cfgAlertSmtp                        = "alertSmtp"
+	cfgNodeAllocWeights                 = "nodeAllocWeights"
 
 	cfgVolForceDeletion           = "volForceDeletion"
 	cfgVolDeletionDentryThreshold = "volDeletionDentryThreshold"
@@ -148,6 +151,11 @@ type clusterConfig struct {
 	volForceDeletion           bool   // when delete a volume, ignore it's dentry count or not
 	volDeletionDentryThreshold uint64 // in case of volForceDeletion is set to false, define the dentry count threshold to allow volume deletion
 	volDelayDeleteTimeHour     int64
+
+	alertRules []*alertRule
+	alertSmtp  *alertSmtpConfig
+
+	nodeAllocWeights map[string]float64 // addr -> weight of partition allocation, used by the weighted node selector
 }
 
 func newClusterConfig() (cfg *clusterConfig) {
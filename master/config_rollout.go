@@ -0,0 +1,556 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	defaultConfigRolloutObserveSec      = 600
+	defaultConfigRolloutCanaryPercent   = 10
+	defaultConfigRolloutMaxErrRateDelta = 0.1
+	// the heartbeat rounds of the canary nodes sampled at least before rolling back
+	configRolloutMinRounds   = 3
+	configRolloutHistorySize = 16
+)
+
+// rolloutSetting is a cluster setting carried by the heartbeats of the nodes of the type, which
+// is sent to the canary nodes by apply, and to all the nodes once promoted.
+type rolloutSetting struct {
+	nodeType NodeType
+	parse    func(value string) (interface{}, error)
+	current  func(c *Cluster) string
+	apply    func(req *proto.HeartBeatRequest, value interface{})
+	promote  func(c *Cluster, value interface{}) error
+}
+
+var rolloutSettings = map[string]*rolloutSetting{
+	"diskQosEnable": {
+		nodeType: DataNodeType,
+		parse:    parseRolloutBool,
+		current:  func(c *Cluster) string { return strconv.FormatBool(c.diskQosEnable) },
+		apply:    func(req *proto.HeartBeatRequest, value interface{}) { req.EnableDiskQos = value.(bool) },
+		promote: func(c *Cluster, value interface{}) error {
+			oldValue := c.diskQosEnable
+			c.diskQosEnable = value.(bool)
+			if err := c.syncPutCluster(); err != nil {
+				c.diskQosEnable = oldValue
+				return proto.ErrPersistenceByRaft
+			}
+			return nil
+		},
+	},
+	"fileStatsEnable": {
+		nodeType: MetaNodeType,
+		parse:    parseRolloutBool,
+		current:  func(c *Cluster) string { return strconv.FormatBool(c.fileStatsEnable) },
+		apply:    func(req *proto.HeartBeatRequest, value interface{}) { req.FileStatsEnable = value.(bool) },
+		promote: func(c *Cluster, value interface{}) error {
+			oldValue := c.fileStatsEnable
+			c.fileStatsEnable = value.(bool)
+			if err := c.syncPutCluster(); err != nil {
+				c.fileStatsEnable = oldValue
+				return proto.ErrPersistenceByRaft
+			}
+			return nil
+		},
+	},
+	"qosIopsReadLimit": qosLimitRolloutSetting(MinIoLimit, 1,
+		func(q *qosArgs) *uint64 { return &q.iopsRVal },
+		func(req *proto.HeartBeatRequest) *uint64 { return &req.QosIopsReadLimit },
+		func(dataNode *DataNode) uint64 { return dataNode.QosIopsRLimit }),
+	"qosIopsWriteLimit": qosLimitRolloutSetting(MinIoLimit, 1,
+		func(q *qosArgs) *uint64 { return &q.iopsWVal },
+		func(req *proto.HeartBeatRequest) *uint64 { return &req.QosIopsWriteLimit },
+		func(dataNode *DataNode) uint64 { return dataNode.QosIopsWLimit }),
+	"qosFlowReadLimit": qosLimitRolloutSetting(MinFlowLimit, util.MB,
+		func(q *qosArgs) *uint64 { return &q.flowRVal },
+		func(req *proto.HeartBeatRequest) *uint64 { return &req.QosFlowReadLimit },
+		func(dataNode *DataNode) uint64 { return dataNode.QosFlowRLimit }),
+	"qosFlowWriteLimit": qosLimitRolloutSetting(MinFlowLimit, util.MB,
+		func(q *qosArgs) *uint64 { return &q.flowWVal },
+		func(req *proto.HeartBeatRequest) *uint64 { return &req.QosFlowWriteLimit },
+		func(dataNode *DataNode) uint64 { return dataNode.QosFlowWLimit }),
+}
+
+func parseRolloutBool(value string) (interface{}, error) {
+	return strconv.ParseBool(value)
+}
+
+// qosLimitRolloutSetting returns the setting of a disk qos limit of the datanodes, the value is
+// in the unit, such as MB of the flow limits, and promoted to the datanodes of all the zones.
+func qosLimitRolloutSetting(min uint64, unit int, arg func(q *qosArgs) *uint64,
+	field func(req *proto.HeartBeatRequest) *uint64, limit func(dataNode *DataNode) uint64,
+) *rolloutSetting {
+	return &rolloutSetting{
+		nodeType: DataNodeType,
+		parse: func(value string) (interface{}, error) {
+			v, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			if v*uint64(unit) < min {
+				return nil, fmt.Errorf("limit %v should not be less than %v", v, min/uint64(unit))
+			}
+			return v * uint64(unit), nil
+		},
+		current: func(c *Cluster) string {
+			limits := make(map[uint64]struct{})
+			c.dataNodes.Range(func(key, value interface{}) bool {
+				limits[limit(value.(*DataNode))/uint64(unit)] = struct{}{}
+				return true
+			})
+			values := make([]string, 0, len(limits))
+			for v := range limits {
+				values = append(values, strconv.FormatUint(v, 10))
+			}
+			sort.Strings(values)
+			return strings.Join(values, commaSplit)
+		},
+		apply: func(req *proto.HeartBeatRequest, value interface{}) { *field(req) = value.(uint64) },
+		promote: func(c *Cluster, value interface{}) error {
+			qosParam := &qosArgs{}
+			*arg(qosParam) = value.(uint64)
+			for _, zone := range c.t.getAllZones() {
+				if err := zone.updateDataNodeQosLimit(c, qosParam); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func rolloutSettingKeys() []string {
+	keys := make([]string, 0, len(rolloutSettings))
+	for key := range rolloutSettings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func nodeTypeName(nodeType NodeType) string {
+	if nodeType == DataNodeType {
+		return "dataNode"
+	}
+	return "metaNode"
+}
+
+// configRolloutManager keeps the rollout in progress in the memory of the leader, which is persisted
+// with the cluster value, so that the new leader continues the rollout after loading the cluster value.
+type configRolloutManager struct {
+	sync.RWMutex
+	opMutex sync.Mutex // serializes the promotions and rollbacks
+	nextID  uint64
+	current *proto.ConfigRollout
+	setting *rolloutSetting
+	value   interface{}
+	canary  map[string]struct{}
+	failed  map[string]bool // the nodes of which the last heartbeat failed
+	history []*proto.ConfigRollout
+}
+
+// configRolloutValue is the rollouts persisted with the cluster value.
+type configRolloutValue struct {
+	NextID  uint64
+	Current *proto.ConfigRollout
+	History []*proto.ConfigRollout
+}
+
+func newConfigRolloutManager() *configRolloutManager {
+	return &configRolloutManager{}
+}
+
+func (m *configRolloutManager) start(r *proto.ConfigRollout, setting *rolloutSetting, value interface{}) error {
+	m.Lock()
+	defer m.Unlock()
+	if m.current != nil {
+		return fmt.Errorf("rollout[%v] of config[%v] is in progress", m.current.ID, m.current.Key)
+	}
+	m.nextID++
+	r.ID = m.nextID
+	r.Status = proto.ConfigRolloutCanary
+	r.StartTime = time.Now().Unix()
+	m.setCurrentLocked(r, setting, value)
+	return nil
+}
+
+func (m *configRolloutManager) setCurrentLocked(r *proto.ConfigRollout, setting *rolloutSetting, value interface{}) {
+	m.current, m.setting, m.value = r, setting, value
+	m.canary = make(map[string]struct{}, len(r.CanaryNodes))
+	for _, addr := range r.CanaryNodes {
+		m.canary[addr] = struct{}{}
+	}
+	m.failed = make(map[string]bool)
+}
+
+// cancel drops the rollout started, such as failed to persist it.
+func (m *configRolloutManager) cancel(id uint64) {
+	m.Lock()
+	defer m.Unlock()
+	if m.current == nil || m.current.ID != id {
+		return
+	}
+	m.nextID--
+	m.current, m.setting, m.value, m.canary, m.failed = nil, nil, nil, nil, nil
+}
+
+func (m *configRolloutManager) persistValue() configRolloutValue {
+	m.RLock()
+	defer m.RUnlock()
+	v := configRolloutValue{NextID: m.nextID, History: make([]*proto.ConfigRollout, 0, len(m.history))}
+	if m.current != nil {
+		v.Current = copyConfigRollout(m.current)
+	}
+	for _, r := range m.history {
+		v.History = append(v.History, copyConfigRollout(r))
+	}
+	return v
+}
+
+// load restores the rollouts persisted, the rollout in progress is continued with the samples
+// persisted, or rolled back if its value can't be parsed any more.
+func (m *configRolloutManager) load(v configRolloutValue) {
+	m.Lock()
+	defer m.Unlock()
+	m.nextID, m.history = v.NextID, v.History
+	m.current, m.setting, m.value, m.canary, m.failed = nil, nil, nil, nil, nil
+	r := v.Current
+	if r == nil {
+		return
+	}
+	setting, ok := rolloutSettings[r.Key]
+	if !ok {
+		m.finishLocked(r, proto.ConfigRolloutRolledBack, fmt.Sprintf("config[%v] can't be rolled out", r.Key))
+		return
+	}
+	value, err := setting.parse(r.Value)
+	if err != nil {
+		m.finishLocked(r, proto.ConfigRolloutRolledBack, fmt.Sprintf("value[%v] is invalid: %v", r.Value, err))
+		return
+	}
+	m.setCurrentLocked(r, setting, value)
+	log.LogWarnf("action[configRollout] rollout[%v] of config[%v] to [%v] is continued", r.ID, r.Key, r.Value)
+}
+
+// applyCanary sets the new value to the heartbeat of the canary node.
+func (m *configRolloutManager) applyCanary(nodeType NodeType, addr string, req *proto.HeartBeatRequest) {
+	m.RLock()
+	defer m.RUnlock()
+	if m.current == nil || m.setting.nodeType != nodeType {
+		return
+	}
+	if _, ok := m.canary[addr]; ok {
+		m.setting.apply(req, m.value)
+	}
+}
+
+func (m *configRolloutManager) recordHeartbeat(nodeType NodeType, addr string, succeeded bool) {
+	m.Lock()
+	defer m.Unlock()
+	if m.current == nil || m.setting.nodeType != nodeType {
+		return
+	}
+	m.failed[addr] = !succeeded
+}
+
+// inProgress returns the type of the nodes of the rollout in progress.
+func (m *configRolloutManager) inProgress() (nodeType NodeType, ok bool) {
+	m.RLock()
+	defer m.RUnlock()
+	if m.current == nil {
+		return
+	}
+	return m.setting.nodeType, true
+}
+
+// sample counts the canary and other nodes, the inactive nodes and the nodes of which the last
+// heartbeat failed are errors.
+func (m *configRolloutManager) sample(nodes []Node) {
+	m.Lock()
+	defer m.Unlock()
+	if m.current == nil {
+		return
+	}
+	for _, node := range nodes {
+		bad := !node.IsActiveNode() || m.failed[node.GetAddr()]
+		if _, ok := m.canary[node.GetAddr()]; ok {
+			m.current.CanarySamples++
+			if bad {
+				m.current.CanaryErrors++
+			}
+			continue
+		}
+		m.current.ControlSamples++
+		if bad {
+			m.current.ControlErrors++
+		}
+	}
+}
+
+func errRate(errors, samples int) float64 {
+	if samples == 0 {
+		return 0
+	}
+	return float64(errors) / float64(samples)
+}
+
+// decideConfigRollout returns the status the rollout goes to, empty to keep observing. It is
+// rolled back once the error rate of the canary nodes exceeds the other nodes by the delta after
+// the canary nodes are sampled for some rounds, and promoted after the observation.
+func decideConfigRollout(r *proto.ConfigRollout, now int64) (status, reason string) {
+	canaryRate := errRate(r.CanaryErrors, r.CanarySamples)
+	controlRate := errRate(r.ControlErrors, r.ControlSamples)
+	if r.CanarySamples >= configRolloutMinRounds*len(r.CanaryNodes) && canaryRate > controlRate+r.MaxErrRateDelta {
+		return proto.ConfigRolloutRolledBack, fmt.Sprintf("error rate of the canary nodes %.3f regressed over %.3f of the other nodes",
+			canaryRate, controlRate)
+	}
+	if now-r.StartTime >= r.ObserveSec {
+		return proto.ConfigRolloutPromoted, fmt.Sprintf("error rate of the canary nodes %.3f, %.3f of the other nodes",
+			canaryRate, controlRate)
+	}
+	return "", ""
+}
+
+func (m *configRolloutManager) decide(now int64) (status, reason string) {
+	m.RLock()
+	defer m.RUnlock()
+	if m.current == nil {
+		return
+	}
+	return decideConfigRollout(m.current, now)
+}
+
+func (m *configRolloutManager) promoting() (setting *rolloutSetting, value interface{}, err error) {
+	m.RLock()
+	defer m.RUnlock()
+	if m.current == nil {
+		return nil, nil, fmt.Errorf("no config rollout in progress")
+	}
+	return m.setting, m.value, nil
+}
+
+// finish ends the rollout in progress with the status, and keeps it in the history.
+func (m *configRolloutManager) finish(status, reason string) (r *proto.ConfigRollout, err error) {
+	m.Lock()
+	defer m.Unlock()
+	if m.current == nil {
+		return nil, fmt.Errorf("no config rollout in progress")
+	}
+	r = m.current
+	m.finishLocked(r, status, reason)
+	m.current, m.setting, m.value, m.canary, m.failed = nil, nil, nil, nil, nil
+	return copyConfigRollout(r), nil
+}
+
+func (m *configRolloutManager) finishLocked(r *proto.ConfigRollout, status, reason string) {
+	r.Status, r.Reason, r.EndTime = status, reason, time.Now().Unix()
+	m.history = append(m.history, r)
+	if len(m.history) > configRolloutHistorySize {
+		m.history = m.history[len(m.history)-configRolloutHistorySize:]
+	}
+}
+
+func copyConfigRollout(r *proto.ConfigRollout) *proto.ConfigRollout {
+	cp := *r
+	cp.CanaryNodes = append([]string{}, r.CanaryNodes...)
+	return &cp
+}
+
+func (m *configRolloutManager) view() *proto.ConfigRolloutView {
+	m.RLock()
+	defer m.RUnlock()
+	view := &proto.ConfigRolloutView{History: make([]*proto.ConfigRollout, 0, len(m.history))}
+	if m.current != nil {
+		view.Current = copyConfigRollout(m.current)
+	}
+	for i := len(m.history) - 1; i >= 0; i-- {
+		view.History = append(view.History, copyConfigRollout(m.history[i]))
+	}
+	return view
+}
+
+// selectCanaryNodes returns the canary nodes, which are the nodes of the addresses if any, or the
+// nodes of the label if any, otherwise the percent of the nodes chosen randomly. Some nodes are
+// left out of the canary nodes to compare with.
+func selectCanaryNodes(nodes []Node, addrs []string, label string, percent int) ([]string, error) {
+	canary := make([]string, 0)
+	switch {
+	case len(addrs) > 0:
+		for _, addr := range addrs {
+			found := false
+			for _, node := range nodes {
+				found = found || node.GetAddr() == addr
+			}
+			if !found {
+				return nil, fmt.Errorf("node[%v] not found", addr)
+			}
+			if !contains(canary, addr) {
+				canary = append(canary, addr)
+			}
+		}
+	case label != "":
+		for _, node := range nodes {
+			if contains(node.GetLabels(), label) {
+				canary = append(canary, node.GetAddr())
+			}
+		}
+		if len(canary) == 0 {
+			return nil, fmt.Errorf("no nodes of label[%v]", label)
+		}
+	default:
+		if percent <= 0 || percent >= 100 {
+			return nil, fmt.Errorf("canary percent %v should be between 0 and 100", percent)
+		}
+		n := (len(nodes)*percent + 99) / 100
+		for _, i := range rand.Perm(len(nodes))[:n] {
+			canary = append(canary, nodes[i].GetAddr())
+		}
+	}
+	if len(canary) >= len(nodes) {
+		return nil, fmt.Errorf("no nodes left out of the %v canary nodes to compare with", len(canary))
+	}
+	sort.Strings(canary)
+	return canary, nil
+}
+
+func (c *Cluster) rolloutNodes(nodeType NodeType) (nodes []Node) {
+	allNodes := &c.dataNodes
+	if nodeType == MetaNodeType {
+		allNodes = &c.metaNodes
+	}
+	allNodes.Range(func(key, value interface{}) bool {
+		nodes = append(nodes, asNodeWrap(value, nodeType))
+		return true
+	})
+	return
+}
+
+// startConfigRollout starts to roll out the value of the config to the canary nodes.
+func (c *Cluster) startConfigRollout(key, value string, canaryNodes []string, canaryLabel string, canaryPercent int,
+	observeSec int64, maxErrRateDelta float64,
+) (r *proto.ConfigRollout, err error) {
+	setting, ok := rolloutSettings[key]
+	if !ok {
+		return nil, fmt.Errorf("config[%v] can't be rolled out, only %v", key, rolloutSettingKeys())
+	}
+	v, err := setting.parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("value[%v] of config[%v] is invalid: %v", value, key, err)
+	}
+	canary, err := selectCanaryNodes(c.rolloutNodes(setting.nodeType), canaryNodes, canaryLabel, canaryPercent)
+	if err != nil {
+		return nil, err
+	}
+
+	r = &proto.ConfigRollout{
+		Key:             key,
+		Value:           value,
+		OldValue:        setting.current(c),
+		NodeType:        nodeTypeName(setting.nodeType),
+		CanaryNodes:     canary,
+		ObserveSec:      observeSec,
+		MaxErrRateDelta: maxErrRateDelta,
+	}
+	c.configRollout.opMutex.Lock()
+	defer c.configRollout.opMutex.Unlock()
+	if err = c.configRollout.start(r, setting, v); err != nil {
+		return nil, err
+	}
+	if err = c.syncPutCluster(); err != nil {
+		log.LogErrorf("action[startConfigRollout] persist rollout[%v] failed: %v", r.ID, err)
+		c.configRollout.cancel(r.ID)
+		return nil, proto.ErrPersistenceByRaft
+	}
+	log.LogWarnf("action[startConfigRollout] rollout[%v] of config[%v] from [%v] to [%v] on canary nodes %v",
+		r.ID, key, r.OldValue, value, canary)
+	return copyConfigRollout(r), nil
+}
+
+// promoteConfigRollout applies the value of the rollout to all the nodes. The rollout stays on the
+// canary nodes if failed to apply.
+func (c *Cluster) promoteConfigRollout(reason string) (r *proto.ConfigRollout, err error) {
+	c.configRollout.opMutex.Lock()
+	defer c.configRollout.opMutex.Unlock()
+	setting, value, err := c.configRollout.promoting()
+	if err != nil {
+		return
+	}
+	if err = setting.promote(c, value); err != nil {
+		log.LogErrorf("action[promoteConfigRollout] promote failed: %v", err)
+		return
+	}
+	if r, err = c.configRollout.finish(proto.ConfigRolloutPromoted, reason); err != nil {
+		return
+	}
+	log.LogWarnf("action[promoteConfigRollout] rollout[%v] of config[%v] to [%v] is promoted: %v", r.ID, r.Key, r.Value, reason)
+	c.persistConfigRollout()
+	return
+}
+
+// persistConfigRollout persists the rollouts, the new leader continues the rollout persisted last
+// if failed, and the promotion or rollback of it is done again.
+func (c *Cluster) persistConfigRollout() {
+	if err := c.syncPutCluster(); err != nil {
+		log.LogErrorf("action[persistConfigRollout] persist config rollouts failed: %v", err)
+	}
+}
+
+// rollbackConfigRollout stops the rollout, the canary nodes get the value of the cluster at the
+// next heartbeat.
+func (c *Cluster) rollbackConfigRollout(reason string) (r *proto.ConfigRollout, err error) {
+	c.configRollout.opMutex.Lock()
+	defer c.configRollout.opMutex.Unlock()
+	if r, err = c.configRollout.finish(proto.ConfigRolloutRolledBack, reason); err != nil {
+		return
+	}
+	msg := fmt.Sprintf("action[rollbackConfigRollout] clusterID[%v] rollout[%v] of config[%v] to [%v] is rolled back: %v",
+		c.Name, r.ID, r.Key, r.Value, reason)
+	log.LogWarn(msg)
+	Warn(c.Name, msg)
+	c.persistConfigRollout()
+	return
+}
+
+// checkConfigRollout samples the nodes of the rollout in progress every heartbeat, and promotes
+// or rolls back it by the error rates.
+func (c *Cluster) checkConfigRollout() {
+	nodeType, ok := c.configRollout.inProgress()
+	if !ok {
+		return
+	}
+	c.configRollout.sample(c.rolloutNodes(nodeType))
+	switch status, reason := c.configRollout.decide(time.Now().Unix()); status {
+	case proto.ConfigRolloutPromoted:
+		c.promoteConfigRollout(reason)
+	case proto.ConfigRolloutRolledBack:
+		c.rollbackConfigRollout(reason)
+	default:
+		// persist the samples for the new leader to decide
+		c.persistConfigRollout()
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func newRolloutNodes() []Node {
+	return []Node{
+		&DataNode{Addr: "a1", isActive: true, Labels: []string{"canary"}},
+		&DataNode{Addr: "a2", isActive: true},
+		&DataNode{Addr: "a3", isActive: true, Labels: []string{"canary"}},
+		&DataNode{Addr: "a4", isActive: true},
+		&DataNode{Addr: "a5", isActive: false},
+	}
+}
+
+func TestConfigRolloutSelectCanaryNodes(t *testing.T) {
+	nodes := newRolloutNodes()
+
+	canary, err := selectCanaryNodes(nodes, []string{"a2", "a1", "a2"}, "canary", 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a1", "a2"}, canary)
+	_, err = selectCanaryNodes(nodes, []string{"a6"}, "", 10)
+	require.Error(t, err)
+
+	canary, err = selectCanaryNodes(nodes, nil, "canary", 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a1", "a3"}, canary)
+	_, err = selectCanaryNodes(nodes, nil, "none", 10)
+	require.Error(t, err)
+
+	// at least one node
+	canary, err = selectCanaryNodes(nodes, nil, "", 10)
+	require.NoError(t, err)
+	require.Len(t, canary, 1)
+	canary, err = selectCanaryNodes(nodes, nil, "", 50)
+	require.NoError(t, err)
+	require.Len(t, canary, 3)
+	_, err = selectCanaryNodes(nodes, nil, "", 100)
+	require.Error(t, err)
+
+	// no nodes left to compare with
+	_, err = selectCanaryNodes(nodes, []string{"a1", "a2", "a3", "a4", "a5"}, "", 10)
+	require.Error(t, err)
+}
+
+func TestConfigRolloutDecide(t *testing.T) {
+	r := &proto.ConfigRollout{CanaryNodes: []string{"a1"}, ObserveSec: 60, MaxErrRateDelta: 0.1, StartTime: 100}
+	status, _ := decideConfigRollout(r, 120)
+	require.Equal(t, "", status)
+
+	// regressed but not sampled enough
+	r.CanarySamples, r.CanaryErrors, r.ControlSamples = 2, 2, 8
+	status, _ = decideConfigRollout(r, 120)
+	require.Equal(t, "", status)
+	r.CanarySamples, r.CanaryErrors = 3, 3
+	status, _ = decideConfigRollout(r, 120)
+	require.Equal(t, proto.ConfigRolloutRolledBack, status)
+
+	// the other nodes fail as well
+	r.ControlErrors = 8
+	status, _ = decideConfigRollout(r, 120)
+	require.Equal(t, "", status)
+	status, _ = decideConfigRollout(r, 160)
+	require.Equal(t, proto.ConfigRolloutPromoted, status)
+}
+
+func TestConfigRolloutManager(t *testing.T) {
+	m := newConfigRolloutManager()
+	setting := rolloutSettings["qosIopsReadLimit"]
+	value, err := setting.parse("50")
+	require.Error(t, err)
+	value, err = setting.parse("1000")
+	require.NoError(t, err)
+
+	r := &proto.ConfigRollout{Key: "qosIopsReadLimit", Value: "1000", CanaryNodes: []string{"a1"}, ObserveSec: 60, MaxErrRateDelta: 0.1}
+	require.NoError(t, m.start(r, setting, value))
+	require.Error(t, m.start(&proto.ConfigRollout{}, setting, value))
+
+	req := &proto.HeartBeatRequest{}
+	m.applyCanary(MetaNodeType, "a1", req)
+	m.applyCanary(DataNodeType, "a2", req)
+	require.Equal(t, uint64(0), req.QosIopsReadLimit)
+	m.applyCanary(DataNodeType, "a1", req)
+	require.Equal(t, uint64(1000), req.QosIopsReadLimit)
+
+	m.recordHeartbeat(DataNodeType, "a1", false)
+	m.sample(newRolloutNodes())
+	view := m.view()
+	require.Equal(t, 1, view.Current.CanarySamples)
+	require.Equal(t, 1, view.Current.CanaryErrors)
+	require.Equal(t, 4, view.Current.ControlSamples)
+	// a5 is inactive
+	require.Equal(t, 1, view.Current.ControlErrors)
+
+	// the new leader continues the rollout persisted with the samples
+	persisted := m.persistValue()
+	m.load(configRolloutValue{})
+	require.Nil(t, m.view().Current)
+	loaded := newConfigRolloutManager()
+	loaded.load(persisted)
+	require.Equal(t, view, loaded.view())
+	req = &proto.HeartBeatRequest{}
+	loaded.applyCanary(DataNodeType, "a1", req)
+	require.Equal(t, uint64(1000), req.QosIopsReadLimit)
+	require.Error(t, loaded.start(&proto.ConfigRollout{}, setting, value))
+	m = loaded
+
+	_, err = m.finish(proto.ConfigRolloutRolledBack, "rolled back manually")
+	require.NoError(t, err)
+	view = m.view()
+	require.Nil(t, view.Current)
+	require.Len(t, view.History, 1)
+	require.Equal(t, proto.ConfigRolloutRolledBack, view.History[0].Status)
+	_, err = m.finish(proto.ConfigRolloutPromoted, "")
+	require.Error(t, err)
+
+	// the heartbeats are untouched after finished
+	req = &proto.HeartBeatRequest{}
+	m.applyCanary(DataNodeType, "a1", req)
+	require.Equal(t, uint64(0), req.QosIopsReadLimit)
+}
+
+func TestConfigRolloutManagerLoad(t *testing.T) {
+	m := newConfigRolloutManager()
+	setting := rolloutSettings["diskQosEnable"]
+	r := &proto.ConfigRollout{Key: "diskQosEnable", Value: "true", CanaryNodes: []string{"a1"}, ObserveSec: 60}
+	require.NoError(t, m.start(r, setting, true))
+	persisted := m.persistValue()
+	_, err := m.finish(proto.ConfigRolloutPromoted, "")
+	require.NoError(t, err)
+
+	// the ids go on after loaded
+	m.load(m.persistValue())
+	r = &proto.ConfigRollout{Key: "diskQosEnable", Value: "false", CanaryNodes: []string{"a1"}, ObserveSec: 60}
+	require.NoError(t, m.start(r, setting, false))
+	require.Equal(t, uint64(2), r.ID)
+	m.cancel(r.ID)
+	require.Nil(t, m.view().Current)
+	require.Equal(t, uint64(1), m.persistValue().NextID)
+
+	// the rollout of which the value can't be parsed is rolled back on loading
+	persisted.Current.Value = "invalid"
+	m.load(persisted)
+	view := m.view()
+	require.Nil(t, view.Current)
+	require.Len(t, view.History, 1)
+	require.Equal(t, uint64(1), view.History[0].ID)
+	require.Equal(t, proto.ConfigRolloutRolledBack, view.History[0].Status)
+}
@@ -60,8 +60,12 @@ const (
 	cacheLowWaterKey           = "cacheLowWater"
 	cacheLRUIntervalKey        = "cacheLRUInterval"
 	clientVersion              = "version"
+	clientUpgradeURLKey        = "url"
+	clientUpgradeChecksumKey   = "checksum"
+	clientUpgradeSignatureKey  = "signature"
 	domainIdKey                = "domainId"
 	volOwnerKey                = "owner"
+	newNameKey                 = "newName"
 	volAuthKey                 = "authKey"
 	replicaNumKey              = "replicaNum"
 	followerReadKey            = "followerRead"
@@ -90,6 +94,11 @@ const (
 	nodeTypeKey                = "nodeType"
 	ratio                      = "ratio"
 	rdOnlyKey                  = "rdOnly"
+	allocWeightKey             = "allocWeight"
+	labelsKey                  = "labels"
+	affinityLabelsKey          = "affinityLabels"
+	antiAffinityLabelsKey      = "antiAffinityLabels"
+	fsyncPolicyKey             = "fsyncPolicy"
 	srcAddrKey                 = "srcAddr"
 	targetAddrKey              = "targetAddr"
 	forceKey                   = "force"
@@ -113,9 +122,24 @@ const (
 	decommissionLimit          = "decommissionLimit"
 	DiskDisableKey             = "diskDisable"
 	Limit                      = "limit"
+	sortByKey                  = "sortBy"
+	removeNodesKey             = "removeNodes"
+	removeZonesKey             = "removeZones"
+	addDataNodesKey            = "addDataNodes"
+	addMetaNodesKey            = "addMetaNodes"
+	configKeyKey               = "key"
+	configValueKey             = "value"
+	canaryNodesKey             = "canaryNodes"
+	canaryLabelKey             = "canaryLabel"
+	canaryPercentKey           = "canaryPercent"
+	observeSecKey              = "observeSec"
+	maxErrRateDeltaKey         = "maxErrRateDelta"
+	featureKey                 = "feature"
+	featureModeKey             = "mode"
 	TimeOut                    = "timeout"
 	CountByMeta                = "countByMeta"
 	dpReadOnlyWhenVolFull      = "dpReadOnlyWhenVolFull"
+	caseInsensitiveKey         = "caseInsensitive"
 	PeriodicKey                = "periodic"
 	IPKey                      = "ip"
 	OperateKey                 = "op"
@@ -141,6 +165,8 @@ const (
 	decommissionTypeKey        = "decommissionType"
 	autoDpMetaRepairKey        = "autoDpMetaRepair"
 	dpTimeoutKey               = "dpTimeout"
+	startTimeKey               = "startTime"
+	endTimeKey                 = "endTime"
 )
 
 const (
@@ -298,6 +324,8 @@ const (
 
 	opSyncS3QosSet    uint32 = 0x60
 	opSyncS3QosDelete uint32 = 0x61
+
+	opSyncPutVolAudit uint32 = 0x62
 )
 
 const (
@@ -351,6 +379,7 @@ const (
 	lcNodePrefix     = keySeparator + lcNodeAcronym + keySeparator
 	lcConfPrefix     = keySeparator + lcConfigurationAcronym + keySeparator
 	S3QoSPrefix      = keySeparator + S3QoS + keySeparator
+	volAuditPrefix   = keySeparator + "volaudit" + keySeparator
 )
 
 // selector enum
@@ -57,6 +57,9 @@ type DataNode struct {
 	AllDisks                  []string            // TODO: remove me when merge to github master
 	ToBeOffline               bool
 	RdOnly                    bool
+	AllocWeight               float64  // weight of partition allocation set by admin, 0 means unset
+	configAllocWeight         float64  // weight of partition allocation in master config
+	Labels                    []string // labels set by admin, matched with the label constraints of volumes
 	MigrateLock               sync.RWMutex
 	QosIopsRLimit             uint64
 	QosIopsWLimit             uint64
@@ -245,6 +248,16 @@ func (dataNode *DataNode) PartitionCntLimited() bool {
 	return limited
 }
 
+// GetAllocWeight returns the weight of partition allocation used by the weighted node selector.
+func (dataNode *DataNode) GetAllocWeight() float64 {
+	return getAllocWeight(dataNode.AllocWeight, dataNode.configAllocWeight)
+}
+
+// GetLabels returns the labels of the node matched with the label constraints of volumes.
+func (dataNode *DataNode) GetLabels() []string {
+	return dataNode.Labels
+}
+
 func (dataNode *DataNode) GetStorageInfo() string {
 	return fmt.Sprintf("data node(%v) cannot alloc dp, total space(%v) avaliable space(%v) used space(%v), offline(%v), avaliable disk cnt(%v), dp count(%v), over sold(%v))",
 		dataNode.GetAddr(), dataNode.GetTotal(), dataNode.GetTotal()-dataNode.GetUsed(), dataNode.GetUsed(),
@@ -301,6 +314,7 @@ func (dataNode *DataNode) createHeartbeatTask(masterAddr string, enableDiskQos b
 		CurrTime:             time.Now().Unix(),
 		MasterAddr:           masterAddr,
 		VolDpRepairBlockSize: make(map[string]uint64),
+		VolFsyncPolicy:       make(map[string]string),
 	}
 	request.EnableDiskQos = enableDiskQos
 	request.QosIopsReadLimit = dataNode.QosIopsRLimit
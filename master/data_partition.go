@@ -80,8 +80,13 @@ type DataPartition struct {
 	RepairBlockSize                uint64
 	DecommissionType               uint32
 	RestoreReplica                 uint32
+	WriteEpoch                     uint64 // bumped whenever the hosts change, the writes with an older epoch are fenced
 }
 
+// initialWriteEpoch is the write epoch of a new partition, the clients without the epoch in view
+// carry 0, then their writes are fenced since the first change of hosts.
+const initialWriteEpoch = 1
+
 type DataPartitionPreLoad struct {
 	PreloadCacheTTL      uint64
 	preloadCacheCapacity int
@@ -122,6 +127,7 @@ func newDataPartition(ID uint64, replicaNum uint8, volName string, volID uint64,
 	partition.LeaderReportTime = now
 	partition.RepairBlockSize = util.DefaultDataPartitionSize
 	partition.RestoreReplica = RestoreReplicaMetaStop
+	partition.WriteEpoch = initialWriteEpoch
 	return
 }
 
@@ -285,10 +291,12 @@ func (partition *DataPartition) createTaskToCreateDataPartition(addr string, dat
 		partition.RUnlock()
 	}
 
-	task = proto.NewAdminTask(proto.OpCreateDataPartition, addr, newCreateDataPartitionRequest(
+	request := newCreateDataPartitionRequest(
 		partition.VolName, partition.PartitionID, int(partition.ReplicaNum),
 		peers, int(dataPartitionSize), leaderSize, hosts, createType,
-		partitionType, decommissionedDisks, partition.VerSeq))
+		partitionType, decommissionedDisks, partition.VerSeq)
+	request.WriteEpoch = atomic.LoadUint64(&partition.WriteEpoch)
+	task = proto.NewAdminTask(proto.OpCreateDataPartition, addr, request)
 	partition.resetTaskID(task)
 	return
 }
@@ -441,6 +449,7 @@ func (partition *DataPartition) convertToDataPartitionResponse() (dpr *proto.Dat
 	dpr.LeaderAddr = partition.getLeaderAddr()
 	dpr.IsRecover = partition.isRecover
 	dpr.IsDiscard = partition.IsDiscard
+	dpr.WriteEpoch = atomic.LoadUint64(&partition.WriteEpoch)
 
 	return
 }
@@ -699,14 +708,18 @@ func (partition *DataPartition) update(action, volName string, newPeers []proto.
 	copy(oldPeers, partition.Peers)
 	partition.Hosts = newHosts
 	partition.Peers = newPeers
+	// the clients holding the old hosts may still append to a replica out of the new hosts,
+	// bump the write epoch so that the datanodes reject their writes
+	atomic.AddUint64(&partition.WriteEpoch, 1)
 	if err = c.syncUpdateDataPartition(partition); err != nil {
 		partition.Hosts = orgHosts
 		partition.Peers = oldPeers
+		atomic.AddUint64(&partition.WriteEpoch, ^uint64(0))
 		return errors.Trace(err, "action[%v] update partition[%v] vol[%v] failed", action, partition.PartitionID, volName)
 	}
 	msg := fmt.Sprintf("action[%v] success,vol[%v] partitionID:%v "+
-		"oldHosts:%v newHosts:%v,oldPees[%v],newPeers[%v]",
-		action, volName, partition.PartitionID, orgHosts, partition.Hosts, oldPeers, partition.Peers)
+		"oldHosts:%v newHosts:%v,oldPees[%v],newPeers[%v],writeEpoch[%v]",
+		action, volName, partition.PartitionID, orgHosts, partition.Hosts, oldPeers, partition.Peers, atomic.LoadUint64(&partition.WriteEpoch))
 	log.LogWarnf(msg)
 	return
 }
@@ -741,6 +754,7 @@ func (partition *DataPartition) updateMetric(vr *proto.DataPartitionReport, data
 	replica.DecommissionRepairProgress = vr.DecommissionRepairProgress
 	replica.LocalPeers = vr.LocalPeers
 	replica.TriggerDiskError = vr.TriggerDiskError
+	replica.opStat = vr.OpStat
 	if replica.DiskPath != vr.DiskPath && vr.DiskPath != "" {
 		oldDiskPath := replica.DiskPath
 		replica.DiskPath = vr.DiskPath
@@ -1714,7 +1728,8 @@ func (partition *DataPartition) TryAcquireDecommissionToken(c *Cluster) bool {
 			result = true
 			return true
 		}
-		excludeHosts := partition.Hosts
+		// the nodes mismatching the label constraints of the vol are not the targets
+		excludeHosts := append(c.getLabelExcludedHosts(partition.VolName, TypeDataPartition), partition.Hosts...)
 		// if dp rollback success, DecommissionSrcAddr is not contained in dp.hosts, so we must prevent
 		// to create new replica on DecommissionSrcAddr, eg 3 replica dp recover failed, but dp hosts do
 		// not contain DecommissionSrcAddr when completing rolling back
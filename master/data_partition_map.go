@@ -39,6 +39,7 @@ type DataPartitionMap struct {
 	lastAutoCreateTime     time.Time
 	volName                string
 	readMutex              sync.RWMutex
+	sharedPartitions       []*proto.DataPartitionResponse // partitions of the clone source shared with the clone
 }
 
 func newDataPartitionMap(volName string) (dpMap *DataPartitionMap) {
@@ -163,6 +164,7 @@ func (dpMap *DataPartitionMap) updateResponseCache(needsUpdate bool, minPartitio
 			return
 		}
 		dpResps := dpMap.getDataPartitionsView(minPartitionID)
+		dpResps = append(dpResps, dpMap.getSharedPartitionsView(minPartitionID)...)
 		if len(dpResps) == 0 && proto.IsHot(vol.VolType) {
 			log.LogError(fmt.Sprintf("action[updateDpResponseCache],volName[%v] minPartitionID:%v,err:%v",
 				dpMap.volName, minPartitionID, proto.ErrNoAvailDataPartition))
@@ -170,7 +172,7 @@ func (dpMap *DataPartitionMap) updateResponseCache(needsUpdate bool, minPartitio
 		}
 		cv := proto.NewDataPartitionsView()
 		cv.DataPartitions = dpResps
-		if vol.IsReadOnlyForVolFull() || vol.Forbidden {
+		if vol.IsReadOnlyForVolFull() || vol.IsReadOnlyForUserQuota() || vol.Forbidden {
 			cv.VolReadOnly = true
 		}
 		reply := newSuccessHTTPReply(cv)
@@ -362,3 +364,20 @@ func inStingList(target string, strArray []string) bool {
 	}
 	return false
 }
+
+func (dpMap *DataPartitionMap) setSharedPartitions(dpResps []*proto.DataPartitionResponse) {
+	dpMap.Lock()
+	defer dpMap.Unlock()
+	dpMap.sharedPartitions = dpResps
+}
+
+func (dpMap *DataPartitionMap) getSharedPartitionsView(minPartitionID uint64) (dpResps []*proto.DataPartitionResponse) {
+	dpMap.RLock()
+	defer dpMap.RUnlock()
+	for _, dpResp := range dpMap.sharedPartitions {
+		if dpResp.PartitionID > minPartitionID {
+			dpResps = append(dpResps, dpResp)
+		}
+	}
+	return
+}
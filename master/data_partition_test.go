@@ -27,6 +27,22 @@ func TestDataPartition(t *testing.T) {
 	// decommissionDataPartition(partition, t)
 }
 
+func TestDataPartitionInitialWriteEpoch(t *testing.T) {
+	dp := newDataPartition(1, 3, "vol", 1, proto.PartitionTypeNormal, 0)
+	if dp.WriteEpoch != initialWriteEpoch {
+		t.Errorf("new partition write epoch %v, expect %v", dp.WriteEpoch, initialWriteEpoch)
+	}
+	dpv := newDataPartitionValue(dp)
+	dpv.WriteEpoch = 0
+	if restored := dpv.Restore(server.cluster); restored.WriteEpoch != initialWriteEpoch {
+		t.Errorf("restored partition write epoch %v, expect %v", restored.WriteEpoch, initialWriteEpoch)
+	}
+	dpv.WriteEpoch = 5
+	if restored := dpv.Restore(server.cluster); restored.WriteEpoch != 5 {
+		t.Errorf("restored partition write epoch %v, expect 5", restored.WriteEpoch)
+	}
+}
+
 func createDataPartition(vol *Vol, count int, t *testing.T) {
 	oldCount := len(vol.dataPartitions.partitions)
 	reqURL := fmt.Sprintf("%v%v?count=%v&name=%v&type=extent",
@@ -25,6 +25,7 @@ import (
 type DataReplica struct {
 	proto.DataReplica
 	dataNode *DataNode
+	opStat   proto.PartitionOpStat // io statistics reported by the last heartbeat
 	// loc      uint8
 }
 
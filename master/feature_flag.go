@@ -0,0 +1,166 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+func checkFeatureFlag(name string) error {
+	if !proto.IsFeatureFlagName(name) {
+		return fmt.Errorf("feature flag[%v] is unknown, only %v are supported", name, proto.FeatureFlagNames)
+	}
+	return nil
+}
+
+func checkFeatureFlagMode(mode string) error {
+	switch mode {
+	case proto.FeatureFlagOff, proto.FeatureFlagOptIn, proto.FeatureFlagOn:
+		return nil
+	}
+	return fmt.Errorf("feature flag mode[%v] is invalid, only %v, %v and %v are supported",
+		mode, proto.FeatureFlagOff, proto.FeatureFlagOptIn, proto.FeatureFlagOn)
+}
+
+// featureEnabled returns true if the feature of the mode is enabled for the volume, which is
+// enabled for all the volumes if on, or for the volume opted in if optIn.
+func featureEnabled(mode string, optIn bool) bool {
+	return mode == proto.FeatureFlagOn || (mode == proto.FeatureFlagOptIn && optIn)
+}
+
+// getFeatureFlagModes returns the modes of the feature flags set, which is never modified
+// but replaced as a whole.
+func (c *Cluster) getFeatureFlagModes() map[string]string {
+	if val := c.featureFlags.Load(); val != nil {
+		return val.(map[string]string)
+	}
+	return nil
+}
+
+func (c *Cluster) getFeatureFlagMode(name string) string {
+	if mode, ok := c.getFeatureFlagModes()[name]; ok {
+		return mode
+	}
+	return proto.FeatureFlagOff
+}
+
+func (c *Cluster) setFeatureFlag(name, mode string) (err error) {
+	if err = checkFeatureFlag(name); err != nil {
+		return
+	}
+	if err = checkFeatureFlagMode(mode); err != nil {
+		return
+	}
+	c.featureFlagMutex.Lock()
+	defer c.featureFlagMutex.Unlock()
+
+	oldModes := c.getFeatureFlagModes()
+	modes := make(map[string]string, len(oldModes)+1)
+	for n, m := range oldModes {
+		modes[n] = m
+	}
+	modes[name] = mode
+	c.featureFlags.Store(modes)
+	if err = c.syncPutCluster(); err != nil {
+		log.LogErrorf("[setFeatureFlag] failed to set feature flag[%v] to [%v], err(%v)", name, mode, err)
+		c.featureFlags.Store(oldModes)
+		err = proto.ErrPersistenceByRaft
+		return
+	}
+	log.LogWarnf("[setFeatureFlag] set feature flag[%v] from [%v] to [%v]", name, oldModes[name], mode)
+	return
+}
+
+// getFeatureFlags returns all the feature flags known, with the volumes opted in.
+func (c *Cluster) getFeatureFlags() (flags []*proto.FeatureFlag) {
+	byName := make(map[string]*proto.FeatureFlag, len(proto.FeatureFlagNames))
+	for _, name := range proto.FeatureFlagNames {
+		flag := &proto.FeatureFlag{Name: name, Mode: c.getFeatureFlagMode(name), Volumes: make([]string, 0)}
+		byName[name] = flag
+		flags = append(flags, flag)
+	}
+	for _, vol := range c.allVols() {
+		for _, name := range vol.getFeatureOptIns() {
+			if flag, ok := byName[name]; ok {
+				flag.Volumes = append(flag.Volumes, vol.Name)
+			}
+		}
+	}
+	for _, flag := range flags {
+		sort.Strings(flag.Volumes)
+	}
+	return
+}
+
+// getVolFeatureFlags returns the feature flags the volume opts in and the features enabled for it.
+func (c *Cluster) getVolFeatureFlags(vol *Vol) *proto.VolFeatureFlags {
+	optIns := vol.getFeatureOptIns()
+	flags := &proto.VolFeatureFlags{
+		VolName: vol.Name,
+		OptIn:   make([]string, 0, len(optIns)),
+		Enabled: make(map[string]bool, len(proto.FeatureFlagNames)),
+	}
+	flags.OptIn = append(flags.OptIn, optIns...)
+	for _, name := range proto.FeatureFlagNames {
+		flags.Enabled[name] = featureEnabled(c.getFeatureFlagMode(name), contains(optIns, name))
+	}
+	return flags
+}
+
+// setVolFeatureOptIn opts the volume in or out of the feature flag, which takes effect once the
+// mode of the flag is optIn.
+func (c *Cluster) setVolFeatureOptIn(vol *Vol, name string, optIn bool) (err error) {
+	if err = checkFeatureFlag(name); err != nil {
+		return
+	}
+	c.featureFlagMutex.Lock()
+	defer c.featureFlagMutex.Unlock()
+
+	oldOptIns := vol.getFeatureOptIns()
+	if contains(oldOptIns, name) == optIn {
+		return
+	}
+	optIns := make([]string, 0, len(oldOptIns)+1)
+	for _, n := range oldOptIns {
+		if n != name {
+			optIns = append(optIns, n)
+		}
+	}
+	if optIn {
+		optIns = append(optIns, name)
+		sort.Strings(optIns)
+	}
+	vol.featureOptIns.Store(optIns)
+	if err = c.syncUpdateVol(vol); err != nil {
+		log.LogErrorf("[setVolFeatureOptIn] vol[%v] failed to set opt-in of feature flag[%v] to [%v], err(%v)",
+			vol.Name, name, optIn, err)
+		vol.featureOptIns.Store(oldOptIns)
+		err = proto.ErrPersistenceByRaft
+		return
+	}
+	log.LogWarnf("[setVolFeatureOptIn] vol[%v] set opt-in of feature flag[%v] to [%v]", vol.Name, name, optIn)
+	return
+}
+
+func (vol *Vol) getFeatureOptIns() []string {
+	if val := vol.featureOptIns.Load(); val != nil {
+		return val.([]string)
+	}
+	return nil
+}
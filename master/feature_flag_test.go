@@ -0,0 +1,77 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatureEnabled(t *testing.T) {
+	assert.False(t, featureEnabled(proto.FeatureFlagOff, true))
+	assert.False(t, featureEnabled(proto.FeatureFlagOptIn, false))
+	assert.True(t, featureEnabled(proto.FeatureFlagOptIn, true))
+	assert.True(t, featureEnabled(proto.FeatureFlagOn, false))
+	assert.False(t, featureEnabled("", true))
+
+	assert.NoError(t, checkFeatureFlag(proto.FeatureInlineData))
+	assert.Error(t, checkFeatureFlag("unknown"))
+	assert.NoError(t, checkFeatureFlagMode(proto.FeatureFlagOptIn))
+	assert.Error(t, checkFeatureFlagMode("enabled"))
+}
+
+func TestVolFeatureFlags(t *testing.T) {
+	setFlag := func(mode string) {
+		process(fmt.Sprintf("%v%v?feature=%v&mode=%v", hostAddr, proto.AdminSetFeatureFlag, proto.FeatureInlineData, mode), t)
+	}
+	optIn := func(enable bool) {
+		process(fmt.Sprintf("%v%v?name=%v&feature=%v&enable=%v", hostAddr, proto.AdminVolSetFeatureFlag,
+			commonVolName, proto.FeatureInlineData, enable), t)
+	}
+	defer setFlag(proto.FeatureFlagOff)
+	defer optIn(false)
+
+	vol, err := server.cluster.getVol(commonVolName)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// off by default, whatever the volume opts in
+	optIn(true)
+	assert.Equal(t, []string{proto.FeatureInlineData}, vol.getFeatureOptIns())
+	assert.False(t, server.cluster.getVolFeatureFlags(vol).IsEnabled(proto.FeatureInlineData))
+
+	setFlag(proto.FeatureFlagOptIn)
+	assert.True(t, server.cluster.getVolFeatureFlags(vol).IsEnabled(proto.FeatureInlineData))
+	assert.False(t, server.cluster.getVolFeatureFlags(vol).IsEnabled(proto.FeatureAtomicRename))
+	for _, flag := range server.cluster.getFeatureFlags() {
+		if flag.Name == proto.FeatureInlineData {
+			assert.Equal(t, proto.FeatureFlagOptIn, flag.Mode)
+			assert.Equal(t, []string{commonVolName}, flag.Volumes)
+		}
+	}
+
+	optIn(false)
+	assert.False(t, server.cluster.getVolFeatureFlags(vol).IsEnabled(proto.FeatureInlineData))
+	setFlag(proto.FeatureFlagOn)
+	assert.True(t, server.cluster.getVolFeatureFlags(vol).IsEnabled(proto.FeatureInlineData))
+
+	reply := processNoCheck(fmt.Sprintf("%v%v?feature=unknown&mode=on", hostAddr, proto.AdminSetFeatureFlag), t)
+	assert.NotEqualValues(t, proto.ErrCodeSuccess, reply.Code)
+	reply = processNoCheck(fmt.Sprintf("%v%v?feature=%v&mode=enabled", hostAddr, proto.AdminSetFeatureFlag, proto.FeatureInlineData), t)
+	assert.NotEqualValues(t, proto.ErrCodeSuccess, reply.Code)
+}
@@ -150,11 +150,13 @@ func (m *Server) registerAPIMiddleware(route *mux.Router) {
 // AuthenticationUri2MsgTypeMap define the mapping from authentication uri to message type
 var AuthenticationUri2MsgTypeMap = map[string]proto.MsgType{
 	// Master API cluster management
-	proto.AdminClusterFreeze: proto.MsgMasterClusterFreezeReq,
-	proto.AddRaftNode:        proto.MsgMasterAddRaftNodeReq,
-	proto.RemoveRaftNode:     proto.MsgMasterRemoveRaftNodeReq,
-	proto.AdminSetNodeInfo:   proto.MsgMasterSetNodeInfoReq,
-	proto.AdminSetNodeRdOnly: proto.MsgMasterSetNodeRdOnlyReq,
+	proto.AdminClusterFreeze:      proto.MsgMasterClusterFreezeReq,
+	proto.AddRaftNode:             proto.MsgMasterAddRaftNodeReq,
+	proto.RemoveRaftNode:          proto.MsgMasterRemoveRaftNodeReq,
+	proto.AdminSetNodeInfo:        proto.MsgMasterSetNodeInfoReq,
+	proto.AdminSetNodeRdOnly:      proto.MsgMasterSetNodeRdOnlyReq,
+	proto.AdminSetNodeAllocWeight: proto.MsgMasterSetNodeAllocWeightReq,
+	proto.AdminSetNodeLabels:      proto.MsgMasterSetNodeLabelsReq,
 
 	// Master API volume management
 	proto.AdminCreateVol: proto.MsgMasterCreateVolReq,
@@ -211,6 +213,7 @@ var AuthenticationUri2MsgTypeMap = map[string]proto.MsgType{
 	proto.UserRemovePolicy:    proto.MsgMasterUserRemovePolicyReq,
 	proto.UserDeleteVolPolicy: proto.MsgMasterUserDeleteVolPolicyReq,
 	proto.UserTransferVol:     proto.MsgMasterUserTransferVolReq,
+	proto.UserSetQuota:        proto.MsgMasterUserSetQuotaReq,
 
 	// Master API zone management
 	proto.UpdateZone: proto.MsgMasterUpdateZoneReq,
@@ -284,6 +287,12 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet).
 		Path(proto.AdminGetMonitorPushAddr).
 		HandlerFunc(m.getMonitorPushAddr)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminSetClientUpgrade).
+		HandlerFunc(m.setClientUpgrade)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminGetClientUpgrade).
+		HandlerFunc(m.getClientUpgrade)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminClusterFreeze).
 		HandlerFunc(m.setupAutoAllocation)
@@ -384,6 +393,12 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminVolExpand).
 		HandlerFunc(m.volExpand)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminRenameVol).
+		HandlerFunc(m.renameVol)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminCloneVol).
+		HandlerFunc(m.cloneVol)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.ClientVol).
 		HandlerFunc(m.getVol)
@@ -408,6 +423,9 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminVolEnableAuditLog).
 		HandlerFunc(m.setEnableAuditLogForVolume)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminVolAuditTrail).
+		HandlerFunc(m.getVolAuditTrail)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminVolSetDpRepairBlockSize).
 		HandlerFunc(m.setVolDpRepairBlockSize)
@@ -710,6 +728,12 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminSetNodeRdOnly).
 		HandlerFunc(m.setNodeRdOnlyHandler)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminSetNodeAllocWeight).
+		HandlerFunc(m.setNodeAllocWeightHandler)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminSetNodeLabels).
+		HandlerFunc(m.setNodeLabelsHandler)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminSetDpRdOnly).
 		HandlerFunc(m.setDpRdOnlyHandler)
@@ -719,6 +743,36 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminGetDiscardDp).
 		HandlerFunc(m.getDiscardDpHandler)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminGetTopView).
+		HandlerFunc(m.getTopView)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminSimulatePlacement).
+		HandlerFunc(m.simulatePlacement)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminStartConfigRollout).
+		HandlerFunc(m.startConfigRollout)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminGetConfigRollout).
+		HandlerFunc(m.getConfigRollout)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminPromoteConfigRollout).
+		HandlerFunc(m.promoteConfigRollout)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminRollbackConfigRollout).
+		HandlerFunc(m.rollbackConfigRollout)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminSetFeatureFlag).
+		HandlerFunc(m.setFeatureFlag)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminGetFeatureFlags).
+		HandlerFunc(m.getFeatureFlags)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminVolSetFeatureFlag).
+		HandlerFunc(m.setVolFeatureFlag)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.ClientVolFeatureFlags).
+		HandlerFunc(m.getVolFeatureFlags)
 
 	// user management APIs
 	router.NewRoute().Methods(http.MethodPost).
@@ -754,6 +808,12 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet).
 		Path(proto.UsersOfVol).
 		HandlerFunc(m.getUsersOfVol)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.UserSetQuota).
+		HandlerFunc(m.setUserQuota)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.UserGetUsage).
+		HandlerFunc(m.getUserUsage)
 
 	// zone management APIs
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
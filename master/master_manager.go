@@ -61,6 +61,8 @@ func (m *Server) handleLeaderChange(leader uint64) {
 	} else {
 		Warn(m.clusterName, fmt.Sprintf("clusterID[%v] leader is changed to %v",
 			m.clusterName, m.leaderInfo.addr))
+		// the new leader continues the rollout persisted
+		m.cluster.configRollout.load(configRolloutValue{})
 		m.clearMetadata()
 		m.metaReady = false
 		m.cluster.metaReady = false
@@ -279,3 +279,30 @@ func (mqMgr *MasterQuotaManager) HasQuota() bool {
 	defer mqMgr.RUnlock()
 	return len(mqMgr.IdQuotaInfoMap) > 0
 }
+
+// renameVol sets and persists the new vol name of all quotas of the renamed vol.
+func (mqMgr *MasterQuotaManager) renameVol(newName string) (err error) {
+	mqMgr.Lock()
+	defer mqMgr.Unlock()
+	for _, quotaInfo := range mqMgr.IdQuotaInfoMap {
+		quotaInfo.VolName = newName
+	}
+	for _, quotaInfo := range mqMgr.IdQuotaInfoMap {
+		var value []byte
+		if value, err = json.Marshal(quotaInfo); err != nil {
+			log.LogErrorf("rename quota [%v] marsha1 fail [%v].", quotaInfo, err)
+			return
+		}
+
+		metadata := new(RaftCmd)
+		metadata.Op = opSyncSetQuota
+		metadata.K = quotaPrefix + strconv.FormatUint(mqMgr.vol.ID, 10) + keySeparator + strconv.FormatUint(uint64(quotaInfo.QuotaId), 10)
+		metadata.V = value
+
+		if err = mqMgr.c.submit(metadata); err != nil {
+			log.LogErrorf("rename quota [%v] submit fail [%v].", quotaInfo, err)
+			return
+		}
+	}
+	return
+}
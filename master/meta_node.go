@@ -47,6 +47,9 @@ type MetaNode struct {
 	ToBeOffline               bool
 	PersistenceMetaPartitions []uint64
 	RdOnly                    bool
+	AllocWeight               float64  // weight of partition allocation set by admin, 0 means unset
+	configAllocWeight         float64  // weight of partition allocation in master config
+	Labels                    []string // labels set by admin, matched with the label constraints of volumes
 	MigrateLock               sync.RWMutex
 	MpCntLimit                LimitCounter       `json:"-"` // max count of meta partition in a meta node
 	CpuUtil                   atomicutil.Float64 `json:"-"`
@@ -70,6 +73,16 @@ func (metaNode *MetaNode) clean() {
 	metaNode.Sender.exitCh <- struct{}{}
 }
 
+// GetAllocWeight returns the weight of partition allocation used by the weighted node selector.
+func (metaNode *MetaNode) GetAllocWeight() float64 {
+	return getAllocWeight(metaNode.AllocWeight, metaNode.configAllocWeight)
+}
+
+// GetLabels returns the labels of the node matched with the label constraints of volumes.
+func (metaNode *MetaNode) GetLabels() []string {
+	return metaNode.Labels
+}
+
 func (metaNode *MetaNode) GetStorageInfo() string {
 	return fmt.Sprintf("meta node(%v) cannot alloc dp, total space(%v) avaliable space(%v) used space(%v), offline(%v),  mp count(%v)",
 		metaNode.GetAddr(), metaNode.GetTotal(), metaNode.GetTotal()-metaNode.GetUsed(), metaNode.GetUsed(),
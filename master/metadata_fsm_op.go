@@ -66,6 +66,9 @@ type clusterValue struct {
 	MarkDiskBrokenThreshold     float64
 	EnableAutoDpMetaRepair      bool
 	DataPartitionTimeoutSec     int64
+	ClientUpgrade               bsProto.ClientUpgradeInfo
+	FeatureFlags                map[string]string
+	ConfigRollouts              configRolloutValue
 }
 
 func newClusterValue(c *Cluster) (cv *clusterValue) {
@@ -101,6 +104,9 @@ func newClusterValue(c *Cluster) (cv *clusterValue) {
 		MarkDiskBrokenThreshold:     c.getMarkDiskBrokenThreshold(),
 		EnableAutoDpMetaRepair:      c.getEnableAutoDpMetaRepair(),
 		DataPartitionTimeoutSec:     c.getDataPartitionTimeoutSec(),
+		ClientUpgrade:               *c.getClientUpgrade(),
+		FeatureFlags:                c.getFeatureFlagModes(),
+		ConfigRollouts:              c.configRollout.persistValue(),
 	}
 	return cv
 }
@@ -168,6 +174,7 @@ type dataPartitionValue struct {
 	DecommissionNeedRollbackTimes  uint32
 	DecommissionType               uint32
 	RestoreReplica                 uint32
+	WriteEpoch                     uint64
 }
 
 func (dpv *dataPartitionValue) Restore(c *Cluster) (dp *DataPartition) {
@@ -200,6 +207,12 @@ func (dpv *dataPartitionValue) Restore(c *Cluster) (dp *DataPartition) {
 	dp.DecommissionErrorMessage = dpv.DecommissionErrorMessage
 	dp.DecommissionType = dpv.DecommissionType
 	dp.RestoreReplica = dpv.RestoreReplica
+	dp.WriteEpoch = dpv.WriteEpoch
+	// the partitions persisted before the write epoch was issued, a client may still carry
+	// the epoch 0 from the view before the first change of hosts
+	if dp.WriteEpoch < initialWriteEpoch {
+		dp.WriteEpoch = initialWriteEpoch
+	}
 	// to ensure progress of checkReplicaMeta can be run again, the status of RestoreReplicaMeta can not be
 	// set to RestoreReplicaMetaStop otherwise for checkReplicaMeta cannot be executed.
 	if dp.RestoreReplica == RestoreReplicaMetaRunning {
@@ -251,6 +264,7 @@ func newDataPartitionValue(dp *DataPartition) (dpv *dataPartitionValue) {
 		DecommissionNeedRollbackTimes:  dp.DecommissionNeedRollbackTimes,
 		DecommissionType:               dp.DecommissionType,
 		RestoreReplica:                 atomic.LoadUint32(&dp.RestoreReplica),
+		WriteEpoch:                     atomic.LoadUint64(&dp.WriteEpoch),
 	}
 	for _, replica := range dp.Replicas {
 		rv := &replicaValue{Addr: replica.Addr, DiskPath: replica.DiskPath}
@@ -300,8 +314,14 @@ type volValue struct {
 	CacheLRUInterval int
 	CacheRule        string
 
-	EnablePosixAcl bool
-	EnableQuota    bool
+	EnablePosixAcl  bool
+	EnableQuota     bool
+	CaseInsensitive bool
+
+	AffinityLabels     []string
+	AntiAffinityLabels []string
+	FeatureOptIns      []string
+	FsyncPolicy        string
 
 	EnableTransaction       bsProto.TxOpMask
 	TxTimeout               int64
@@ -320,6 +340,10 @@ type volValue struct {
 	Forbidden            bool
 	DpRepairBlockSize    uint64
 	EnableAutoMetaRepair bool
+
+	PreviousNames []string
+	CloneSource   string
+	CloneVerSeq   uint64
 }
 
 func (v *volValue) Bytes() (raw []byte, err error) {
@@ -352,11 +376,16 @@ func newVolValue(vol *Vol) (vv *volValue) {
 		DefaultPriority:         vol.defaultPriority,
 		EnablePosixAcl:          vol.enablePosixAcl,
 		EnableQuota:             vol.enableQuota,
+		CaseInsensitive:         vol.caseInsensitive,
 		EnableTransaction:       vol.enableTransaction,
 		TxTimeout:               vol.txTimeout,
 		TxConflictRetryNum:      vol.txConflictRetryNum,
 		TxConflictRetryInterval: vol.txConflictRetryInterval,
 		TxOpLimit:               vol.txOpLimit,
+		AffinityLabels:          vol.affinityLabels,
+		AntiAffinityLabels:      vol.antiAffinityLabels,
+		FeatureOptIns:           vol.getFeatureOptIns(),
+		FsyncPolicy:             vol.fsyncPolicy,
 
 		VolType:             vol.VolType,
 		EbsBlkSize:          vol.EbsBlkSize,
@@ -389,6 +418,9 @@ func newVolValue(vol *Vol) (vv *volValue) {
 		User:                  vol.user,
 		DpRepairBlockSize:     vol.dpRepairBlockSize,
 		EnableAutoMetaRepair:  vol.EnableAutoMetaRepair.Load(),
+		PreviousNames:         vol.previousNames,
+		CloneSource:           vol.cloneSource,
+		CloneVerSeq:           vol.cloneVerSeq,
 	}
 
 	return
@@ -408,6 +440,8 @@ type dataNodeValue struct {
 	Addr                     string
 	ZoneName                 string
 	RdOnly                   bool
+	AllocWeight              float64
+	Labels                   []string
 	DecommissionedDisks      []string
 	DecommissionStatus       uint32
 	DecommissionDstAddr      string
@@ -426,6 +460,8 @@ func newDataNodeValue(dataNode *DataNode) *dataNodeValue {
 		Addr:                     dataNode.Addr,
 		ZoneName:                 dataNode.ZoneName,
 		RdOnly:                   dataNode.RdOnly,
+		AllocWeight:              dataNode.AllocWeight,
+		Labels:                   dataNode.Labels,
 		DecommissionedDisks:      dataNode.getDecommissionedDisks(),
 		DecommissionStatus:       atomic.LoadUint32(&dataNode.DecommissionStatus),
 		DecommissionDstAddr:      dataNode.DecommissionDstAddr,
@@ -439,20 +475,24 @@ func newDataNodeValue(dataNode *DataNode) *dataNodeValue {
 }
 
 type metaNodeValue struct {
-	ID        uint64
-	NodeSetID uint64
-	Addr      string
-	ZoneName  string
-	RdOnly    bool
+	ID          uint64
+	NodeSetID   uint64
+	Addr        string
+	ZoneName    string
+	RdOnly      bool
+	AllocWeight float64
+	Labels      []string
 }
 
 func newMetaNodeValue(metaNode *MetaNode) *metaNodeValue {
 	return &metaNodeValue{
-		ID:        metaNode.ID,
-		NodeSetID: metaNode.NodeSetID,
-		Addr:      metaNode.Addr,
-		ZoneName:  metaNode.ZoneName,
-		RdOnly:    metaNode.RdOnly,
+		ID:          metaNode.ID,
+		NodeSetID:   metaNode.NodeSetID,
+		Addr:        metaNode.Addr,
+		ZoneName:    metaNode.ZoneName,
+		RdOnly:      metaNode.RdOnly,
+		AllocWeight: metaNode.AllocWeight,
+		Labels:      metaNode.Labels,
 	}
 }
 
@@ -1214,6 +1254,11 @@ func (c *Cluster) loadClusterValue() (err error) {
 		c.updateMarkDiskBrokenThreshold(cv.MarkDiskBrokenThreshold)
 		c.updateEnableAutoDpMetaRepair(cv.EnableAutoDpMetaRepair)
 		c.updateDataPartitionTimeoutSec(cv.DataPartitionTimeoutSec)
+		c.clientUpgrade.Store(&cv.ClientUpgrade)
+		if cv.FeatureFlags != nil {
+			c.featureFlags.Store(cv.FeatureFlags)
+		}
+		c.configRollout.load(cv.ConfigRollouts)
 	}
 	return
 }
@@ -1427,6 +1472,9 @@ func (c *Cluster) loadDataNodes() (err error) {
 		dataNode.ID = dnv.ID
 		dataNode.NodeSetID = dnv.NodeSetID
 		dataNode.RdOnly = dnv.RdOnly
+		dataNode.AllocWeight = dnv.AllocWeight
+		dataNode.Labels = dnv.Labels
+		dataNode.configAllocWeight = c.cfg.nodeAllocWeights[dnv.Addr]
 		for _, disk := range dnv.DecommissionedDisks {
 			dataNode.addDecommissionedDisk(disk)
 		}
@@ -1477,6 +1525,9 @@ func (c *Cluster) loadMetaNodes() (err error) {
 		metaNode.ID = mnv.ID
 		metaNode.NodeSetID = mnv.NodeSetID
 		metaNode.RdOnly = mnv.RdOnly
+		metaNode.AllocWeight = mnv.AllocWeight
+		metaNode.Labels = mnv.Labels
+		metaNode.configAllocWeight = c.cfg.nodeAllocWeights[mnv.Addr]
 
 		oldmn, ok := c.metaNodes.Load(metaNode.Addr)
 		if ok {
@@ -1620,6 +1671,8 @@ func (c *Cluster) loadDataPartitions() (err error) {
 		}
 
 		dp := dpv.Restore(c)
+		// the volume may be renamed
+		dp.VolName = vol.Name
 		if dp.IsDiscard {
 			log.LogWarnf("[loadDataPartitions] dp(%v) is discard, decommission status(%v)", dp.PartitionID, dp.GetDecommissionStatus())
 		}
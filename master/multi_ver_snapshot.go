@@ -579,6 +579,15 @@ func (verMgr *VolVersionManager) initVer2PhaseTask(verSeq uint64, op uint8) (ver
 }
 
 func (verMgr *VolVersionManager) createVer2PhaseTask(cluster *Cluster, verSeq uint64, op uint8, force bool) (verRsp *proto.VolVersionInfo, err error) {
+	if op == proto.DeleteVersion {
+		for _, clone := range cluster.getVolClones(verMgr.vol) {
+			if clone.cloneVerSeq == verSeq {
+				err = fmt.Errorf("version %v is shared with the clone %v", verSeq, clone.Name)
+				log.LogErrorf("vol %v createVer2PhaseTask. %v", verMgr.vol.Name, err)
+				return
+			}
+		}
+	}
 	if err = verMgr.startWork(); err != nil {
 		return
 	}
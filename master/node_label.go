@@ -0,0 +1,134 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// labelRegexp is the pattern of node labels, such as ssd, gpu-rack and tenantA
+var labelRegexp = regexp.MustCompile("^[a-zA-Z0-9_.-]+$")
+
+// parseLabels parses the labels separated by comma, the empty and duplicated labels are removed.
+func parseLabels(raw string) (labels []string, err error) {
+	for _, label := range strings.Split(raw, commaSplit) {
+		label = strings.TrimSpace(label)
+		if label == "" || contains(labels, label) {
+			continue
+		}
+		if !labelRegexp.MatchString(label) {
+			return nil, fmt.Errorf("label[%v] is invalid, only letters, digits, '_', '-' and '.' are allowed", label)
+		}
+		labels = append(labels, label)
+	}
+	return
+}
+
+func checkLabelConstraints(affinityLabels, antiAffinityLabels []string) error {
+	for _, label := range affinityLabels {
+		if contains(antiAffinityLabels, label) {
+			return fmt.Errorf("label[%v] can't be both affinity and anti-affinity", label)
+		}
+	}
+	return nil
+}
+
+// matchLabels returns true if the node has all of the affinity labels and none of the anti-affinity labels.
+func matchLabels(nodeLabels, affinityLabels, antiAffinityLabels []string) bool {
+	for _, label := range affinityLabels {
+		if !contains(nodeLabels, label) {
+			return false
+		}
+	}
+	for _, label := range antiAffinityLabels {
+		if contains(nodeLabels, label) {
+			return false
+		}
+	}
+	return true
+}
+
+func (vol *Vol) hasLabelConstraints() bool {
+	return len(vol.affinityLabels) > 0 || len(vol.antiAffinityLabels) > 0
+}
+
+func (vol *Vol) matchNode(node Node) bool {
+	return matchLabels(node.GetLabels(), vol.affinityLabels, vol.antiAffinityLabels)
+}
+
+func toNodeType(partitionType uint32) NodeType {
+	if partitionType == TypeDataPartition {
+		return DataNodeType
+	}
+	return MetaNodeType
+}
+
+// getLabelExcludes returns the nodes mismatching the label constraints of the vol, and the nodesets
+// without enough writable nodes matching the constraints to place the replicas in a zone, so that
+// the nodeset selectors skip them instead of failing in the node selectors.
+func (c *Cluster) getLabelExcludes(vol *Vol, partitionType uint32, replicaNum, zoneNum int) (excludeNodeSets []uint64, excludeHosts []string) {
+	if !vol.hasLabelConstraints() {
+		return
+	}
+
+	nodeType := toNodeType(partitionType)
+	// the most replicas placed in a zone
+	need := replicaNum - zoneNum + 1
+	if need < 1 {
+		need = 1
+	}
+	for _, zone := range c.t.getAllZones() {
+		for _, ns := range zone.getAllNodeSet() {
+			matched := 0
+			ns.getNodes(nodeType).Range(func(key, value interface{}) bool {
+				node := asNodeWrap(value, nodeType)
+				if !vol.matchNode(node) {
+					excludeHosts = append(excludeHosts, node.GetAddr())
+				} else if canAllocPartition(node) {
+					matched++
+				}
+				return true
+			})
+			if matched < need {
+				excludeNodeSets = append(excludeNodeSets, ns.ID)
+			}
+		}
+	}
+	return
+}
+
+// getLabelExcludedHosts returns the nodes mismatching the label constraints of the vol,
+// they are excluded from the targets of partition decommission.
+func (c *Cluster) getLabelExcludedHosts(volName string, partitionType uint32) (excludeHosts []string) {
+	vol, err := c.getVol(volName)
+	if err != nil || !vol.hasLabelConstraints() {
+		return
+	}
+
+	nodes := &c.dataNodes
+	if partitionType == TypeMetaPartition {
+		nodes = &c.metaNodes
+	}
+	nodeType := toNodeType(partitionType)
+	nodes.Range(func(key, value interface{}) bool {
+		if node := asNodeWrap(value, nodeType); !vol.matchNode(node) {
+			excludeHosts = append(excludeHosts, node.GetAddr())
+		}
+		return true
+	})
+	return
+}
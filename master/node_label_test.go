@@ -0,0 +1,109 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLabels(t *testing.T) {
+	labels, err := parseLabels(" ssd,gpu-rack,,ssd,tenant_A.1 ")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ssd", "gpu-rack", "tenant_A.1"}, labels)
+
+	labels, err = parseLabels("")
+	assert.NoError(t, err)
+	assert.Empty(t, labels)
+
+	_, err = parseLabels("ssd,rack 1")
+	assert.Error(t, err)
+
+	assert.NoError(t, checkLabelConstraints([]string{"ssd"}, []string{"hdd"}))
+	assert.Error(t, checkLabelConstraints([]string{"ssd", "gpu"}, []string{"gpu"}))
+}
+
+func TestMatchLabels(t *testing.T) {
+	nodeLabels := []string{"ssd", "tenantA"}
+	assert.True(t, matchLabels(nodeLabels, nil, nil))
+	assert.True(t, matchLabels(nodeLabels, []string{"ssd"}, []string{"gpu"}))
+	assert.True(t, matchLabels(nodeLabels, []string{"ssd", "tenantA"}, nil))
+	assert.False(t, matchLabels(nodeLabels, []string{"ssd", "gpu"}, nil))
+	assert.False(t, matchLabels(nodeLabels, nil, []string{"tenantA"}))
+	assert.False(t, matchLabels(nil, []string{"ssd"}, nil))
+	assert.True(t, matchLabels(nil, nil, []string{"ssd"}))
+}
+
+func TestVolLabelConstraints(t *testing.T) {
+	setLabels := func(addr string, nodeType uint32, labels string) {
+		reqURL := fmt.Sprintf("%v%v?addr=%v&nodeType=%v&labels=%v", hostAddr, proto.AdminSetNodeLabels, addr, nodeType, labels)
+		process(reqURL, t)
+	}
+	setLabels(mds1Addr, TypeDataPartition, "ssd,tenantA")
+	setLabels(mms1Addr, TypeMetaPartition, "ssd")
+	defer func() {
+		setLabels(mds1Addr, TypeDataPartition, "")
+		setLabels(mms1Addr, TypeMetaPartition, "")
+	}()
+
+	dataNode, err := server.cluster.dataNode(mds1Addr)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"ssd", "tenantA"}, dataNode.Labels)
+
+	vol, err := server.cluster.getVol(commonVolName)
+	if !assert.NoError(t, err) {
+		return
+	}
+	updateURL := fmt.Sprintf("%v%v?name=%v&authKey=%v", hostAddr, proto.AdminUpdateVol, commonVolName, buildAuthKey(testOwner))
+	defer process(fmt.Sprintf("%v&%v=&%v=", updateURL, affinityLabelsKey, antiAffinityLabelsKey), t)
+
+	// the same label can't be both affinity and anti-affinity
+	reply := processNoCheck(fmt.Sprintf("%v&%v=ssd&%v=ssd", updateURL, affinityLabelsKey, antiAffinityLabelsKey), t)
+	assert.NotEqualValues(t, proto.ErrCodeSuccess, reply.Code)
+
+	process(fmt.Sprintf("%v&%v=ssd", updateURL, antiAffinityLabelsKey), t)
+	assert.Equal(t, []string{"ssd"}, vol.antiAffinityLabels)
+	assert.Empty(t, vol.affinityLabels)
+	view := getSimpleVol(commonVolName, true, t)
+	assert.Equal(t, []string{"ssd"}, view.AntiAffinityLabels)
+
+	// the labels are kept if not set in the update
+	process(fmt.Sprintf("%v&%v=%v", updateURL, volCapacityKey, vol.Capacity), t)
+	assert.Equal(t, []string{"ssd"}, vol.antiAffinityLabels)
+
+	_, excludeHosts := server.cluster.getLabelExcludes(vol, TypeDataPartition, 3, 1)
+	assert.Equal(t, []string{mds1Addr}, excludeHosts)
+	_, excludeHosts = server.cluster.getLabelExcludes(vol, TypeMetaPartition, 3, 1)
+	assert.Equal(t, []string{mms1Addr}, excludeHosts)
+	assert.Equal(t, []string{mds1Addr}, server.cluster.getLabelExcludedHosts(commonVolName, TypeDataPartition))
+
+	// only the node with the affinity labels is allowed, no nodeset has enough nodes for 3 replicas
+	process(fmt.Sprintf("%v&%v=tenantA&%v=", updateURL, affinityLabelsKey, antiAffinityLabelsKey), t)
+	excludeNodeSets, excludeHosts := server.cluster.getLabelExcludes(vol, TypeDataPartition, 3, 1)
+	assert.NotContains(t, excludeHosts, mds1Addr)
+	assert.Contains(t, excludeHosts, mds2Addr)
+	assert.Contains(t, excludeNodeSets, dataNode.NodeSetID)
+
+	// no constraints
+	process(fmt.Sprintf("%v&%v=", updateURL, affinityLabelsKey), t)
+	excludeNodeSets, excludeHosts = server.cluster.getLabelExcludes(vol, TypeDataPartition, 3, 1)
+	assert.Empty(t, excludeNodeSets)
+	assert.Empty(t, excludeHosts)
+}
@@ -15,6 +15,7 @@
 package master
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"math/rand"
@@ -35,6 +36,8 @@ const AvailableSpaceFirstNodeSelectorName = "AvailableSpaceFirst"
 
 const StrawNodeSelectorName = "Straw"
 
+const WeightedNodeSelectorName = "Weighted"
+
 const DefaultNodeSelectorName = CarryWeightNodeSelectorName
 
 func (ns *nodeSet) getNodes(nodeType NodeType) *sync.Map {
@@ -73,6 +76,8 @@ type Node interface {
 	GetUsed() uint64
 	GetAvailableSpace() uint64
 	GetStorageInfo() string
+	GetAllocWeight() float64
+	GetLabels() []string
 }
 
 // SortedWeightedNodes defines an array sorted by carry
@@ -90,6 +95,37 @@ func (nodes SortedWeightedNodes) Swap(i, j int) {
 	nodes[i], nodes[j] = nodes[j], nodes[i]
 }
 
+// getAllocWeight prefers the weight set by admin to the weight in config, and the default weight is 1.
+func getAllocWeight(weight, configWeight float64) float64 {
+	if weight > 0 {
+		return weight
+	}
+	if configWeight > 0 {
+		return configWeight
+	}
+	return 1
+}
+
+// parseNodeAllocWeights parses the config like {"192.168.0.1:17310": 2.5}
+func parseNodeAllocWeights(raw interface{}) (weights map[string]float64, err error) {
+	if raw == nil {
+		return
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(data, &weights); err != nil {
+		return
+	}
+	for addr, weight := range weights {
+		if weight <= 0 {
+			return nil, fmt.Errorf("alloc weight[%v] of node[%v] should be greater than 0", weight, addr)
+		}
+	}
+	return
+}
+
 func canAllocPartition(node Node) bool {
 	return node.IsWriteAble() && node.PartitionCntLimited()
 }
@@ -430,11 +466,15 @@ func (s *StrawNodeSelector) getWeight(node Node) float64 {
 }
 
 func (s *StrawNodeSelector) selectOneNode(nodes []Node) (index int, maxNode Node) {
+	return s.selectOneNodeByWeight(nodes, s.getWeight)
+}
+
+func (s *StrawNodeSelector) selectOneNodeByWeight(nodes []Node, getWeight func(node Node) float64) (index int, maxNode Node) {
 	maxStraw := float64(0)
 	index = -1
 	for i, node := range nodes {
 		straw := float64(s.rand.Intn(StrawNodeSelectorRandMax))
-		straw = math.Log(straw/float64(StrawNodeSelectorRandMax)) / s.getWeight(node)
+		straw = math.Log(straw/float64(StrawNodeSelectorRandMax)) / getWeight(node)
 		if index == -1 || straw > maxStraw {
 			maxStraw = straw
 			maxNode = node
@@ -493,6 +533,85 @@ func NewStrawNodeSelector(nodeType NodeType) *StrawNodeSelector {
 	}
 }
 
+// WeightedNodeSelector allocates partitions in nodesets mixing servers of very different disk counts and sizes.
+// The weight of a node is the mean of its share of available space and its share of available disks
+// among the candidates, multiplied by the alloc weight of the node, then straw2 is used to select nodes.
+type WeightedNodeSelector struct {
+	StrawNodeSelector
+}
+
+func (s *WeightedNodeSelector) GetName() string {
+	return WeightedNodeSelectorName
+}
+
+func (s *WeightedNodeSelector) getPerfWeight(node Node) float64 {
+	if dataNode, ok := node.(*DataNode); ok {
+		if cnt := dataNode.availableDiskCount(); cnt > 0 {
+			return float64(cnt)
+		}
+	}
+	return 1
+}
+
+func (s *WeightedNodeSelector) getWeights(nodes []Node) map[string]float64 {
+	totalSpace, totalPerf := float64(0), float64(0)
+	for _, node := range nodes {
+		totalSpace += float64(node.GetAvailableSpace())
+		totalPerf += s.getPerfWeight(node)
+	}
+	weights := make(map[string]float64, len(nodes))
+	for _, node := range nodes {
+		share := s.getPerfWeight(node) / totalPerf
+		if totalSpace > 0 {
+			share = (share + float64(node.GetAvailableSpace())/totalSpace) / 2
+		}
+		weights[node.GetAddr()] = share * node.GetAllocWeight()
+	}
+	return weights
+}
+
+func (s *WeightedNodeSelector) Select(ns *nodeSet, excludeHosts []string, replicaNum int) (newHosts []string, peers []proto.Peer, err error) {
+	nodes := make([]Node, 0)
+	ns.getNodes(s.nodeType).Range(func(key, value interface{}) bool {
+		node := asNodeWrap(value, s.nodeType)
+		if !contains(excludeHosts, node.GetAddr()) && canAllocPartition(node) {
+			nodes = append(nodes, node)
+		}
+		return true
+	})
+	if len(nodes) < replicaNum {
+		err = fmt.Errorf("action[%vNodeSelector::Select] no enough writable hosts,replicaNum:%v  MatchNodeCount:%v  ",
+			s.GetName(), replicaNum, len(nodes))
+		return
+	}
+	weights := s.getWeights(nodes)
+	getWeight := func(node Node) float64 {
+		return weights[node.GetAddr()]
+	}
+	orderHosts := make([]string, 0, replicaNum)
+	for len(orderHosts) < replicaNum {
+		index, node := s.selectOneNodeByWeight(nodes, getWeight)
+		nodes[0], nodes[index] = node, nodes[0]
+		nodes = nodes[1:]
+		orderHosts = append(orderHosts, node.GetAddr())
+		node.SelectNodeForWrite()
+		peers = append(peers, proto.Peer{ID: node.GetID(), Addr: node.GetAddr()})
+	}
+	log.LogInfof("action[%vNodeSelector::Select] peers[%v]", s.GetName(), peers)
+	// reshuffle for primary-backup replication
+	if newHosts, err = reshuffleHosts(orderHosts); err != nil {
+		err = fmt.Errorf("action[%vNodeSelector::Select] err:%v  orderHosts is nil", s.GetName(), err.Error())
+		return
+	}
+	return
+}
+
+func NewWeightedNodeSelector(nodeType NodeType) *WeightedNodeSelector {
+	return &WeightedNodeSelector{
+		StrawNodeSelector: *NewStrawNodeSelector(nodeType),
+	}
+}
+
 func NewNodeSelector(name string, nodeType NodeType) NodeSelector {
 	switch name {
 	case RoundRobinNodeSelectorName:
@@ -503,6 +622,8 @@ func NewNodeSelector(name string, nodeType NodeType) NodeSelector {
 		return NewAvailableSpaceFirstNodeSelector(nodeType)
 	case StrawNodeSelectorName:
 		return NewStrawNodeSelector(nodeType)
+	case WeightedNodeSelectorName:
+		return NewWeightedNodeSelector(nodeType)
 	default:
 		return NewCarryWeightNodeSelector(nodeType)
 	}
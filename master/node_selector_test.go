@@ -537,3 +537,57 @@ func TestBenchStrawNodeSelector(t *testing.T) {
 	selector = NewStrawNodeSelector(MetaNodeType)
 	metaNodeSelectorBench(t, selector)
 }
+
+func TestWeightedNodeSelector(t *testing.T) {
+	nset := prepareDataNodesForBench(3, 100*util.GB, 0)
+	nodes := make([]Node, 0)
+	for i := 0; i < 3; i++ {
+		val, _ := nset.dataNodes.Load(fmt.Sprintf("Datanode: %v", i))
+		nodes = append(nodes, val.(*DataNode))
+	}
+	// node 0 has more disks, node 2 is weighted by admin
+	nodes[0].(*DataNode).AllDisks = []string{"/cfs/disk1", "/cfs/disk2", "/cfs/disk3", "/cfs/disk4"}
+	nodes[2].(*DataNode).AllocWeight = 2
+	nodes[1].(*DataNode).configAllocWeight = 3
+	nodes[1].(*DataNode).AllocWeight = 1
+
+	selector := NewWeightedNodeSelector(DataNodeType)
+	weights := selector.getWeights(nodes)
+	expected := []float64{0.5, 0.25, 0.5}
+	for i, node := range nodes {
+		if math.Abs(weights[node.GetAddr()]-expected[i]) > 1e-9 {
+			t.Errorf("unexpected weight of node %v: %v, expected %v", i, weights[node.GetAddr()], expected[i])
+		}
+	}
+
+	times := make(map[uint64]int)
+	for i := 0; i < 1000; i++ {
+		_, peers, err := selector.Select(nset, nil, 1)
+		if err != nil {
+			t.Errorf("%v failed to select %v", selector.GetName(), err)
+			return
+		}
+		times[peers[0].ID]++
+	}
+	printNodeSelectTimes(t, times)
+	if times[1] >= times[0] || times[1] >= times[2] {
+		t.Errorf("%v failed to select nodes by weight", selector.GetName())
+	}
+
+	if _, _, err := selector.Select(nset, nil, 4); err == nil {
+		t.Errorf("%v should fail without enough nodes", selector.GetName())
+	}
+}
+
+func TestParseNodeAllocWeights(t *testing.T) {
+	weights, err := parseNodeAllocWeights(map[string]interface{}{"192.168.0.1:17310": 2.5})
+	if err != nil || weights["192.168.0.1:17310"] != 2.5 {
+		t.Errorf("failed to parse alloc weights %v err %v", weights, err)
+	}
+	if _, err = parseNodeAllocWeights(map[string]interface{}{"192.168.0.1:17310": 0}); err == nil {
+		t.Errorf("alloc weight 0 should be invalid")
+	}
+	if weights, err = parseNodeAllocWeights(nil); err != nil || weights != nil {
+		t.Errorf("failed to parse empty alloc weights %v err %v", weights, err)
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+// max partitions below quorum listed in the result of simulation
+const maxSimulatedBelowQuorumPartitions = 100
+
+type simNode struct {
+	addr      string
+	zone      string
+	active    bool
+	total     uint64
+	available uint64
+}
+
+type simPartition struct {
+	id         uint64
+	volName    string
+	zones      []string // zones of volume, empty means any zone
+	replicaNum uint8
+	hosts      []string
+	used       uint64
+}
+
+// placementSimulator computes the state of partitions after removing and adding nodes of a type,
+// the candidates of a partition are the alive nodes in the zones of its volume except its hosts.
+type placementSimulator struct {
+	nodes     map[string]*simNode
+	removed   map[string]bool
+	added     int
+	addedZone string // empty means the added nodes fit any zone
+
+	aliveInZone    map[string]int
+	available      uint64
+	avgTotal       uint64
+	estimatedSpace bool
+}
+
+func newPlacementSimulator(nodes []*simNode, removed map[string]bool, added int, addedZone string, estimatedSpace bool) *placementSimulator {
+	s := &placementSimulator{
+		nodes:          make(map[string]*simNode, len(nodes)),
+		removed:        removed,
+		added:          added,
+		addedZone:      addedZone,
+		aliveInZone:    make(map[string]int),
+		estimatedSpace: estimatedSpace,
+	}
+	var total uint64
+	for _, node := range nodes {
+		s.nodes[node.addr] = node
+		total += node.total
+		if s.alive(node.addr) {
+			s.aliveInZone[node.zone]++
+			s.available += node.available
+		}
+	}
+	if len(nodes) > 0 {
+		s.avgTotal = total / uint64(len(nodes))
+	}
+	return s
+}
+
+func (s *placementSimulator) alive(addr string) bool {
+	node, ok := s.nodes[addr]
+	return ok && node.active && !s.removed[addr]
+}
+
+func zoneAllowed(zones []string, zone string) bool {
+	if len(zones) == 0 {
+		return true
+	}
+	for _, z := range zones {
+		if z == zone {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *placementSimulator) candidates(dp *simPartition, aliveHosts []string) (count int) {
+	if len(dp.zones) == 0 {
+		for _, n := range s.aliveInZone {
+			count += n
+		}
+	} else {
+		for _, zone := range dp.zones {
+			count += s.aliveInZone[zone]
+		}
+	}
+	for _, addr := range aliveHosts {
+		if zoneAllowed(dp.zones, s.nodes[addr].zone) {
+			count--
+		}
+	}
+	if s.addedZone == "" || zoneAllowed(dp.zones, s.addedZone) {
+		count += s.added
+	}
+	return
+}
+
+func (s *placementSimulator) simulate(partitions []*simPartition) (impact *proto.PlacementImpact) {
+	impact = &proto.PlacementImpact{Total: len(partitions)}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].id < partitions[j].id })
+	for _, dp := range partitions {
+		var aliveHosts, lostHosts []string
+		for _, addr := range dp.hosts {
+			if s.alive(addr) {
+				aliveHosts = append(aliveHosts, addr)
+			} else {
+				lostHosts = append(lostHosts, addr)
+			}
+		}
+		lost := int(dp.replicaNum) - len(aliveHosts)
+		if lost <= 0 {
+			continue
+		}
+		impact.LostReplicas += lost
+		if s.estimatedSpace {
+			impact.RequiredSpace += uint64(lost) * dp.used
+		}
+		if candidates := s.candidates(dp, aliveHosts); candidates < lost {
+			if candidates < 0 {
+				candidates = 0
+			}
+			impact.UnplaceableReplicas += lost - candidates
+		}
+		if len(aliveHosts) >= int(dp.replicaNum)/2+1 {
+			impact.Degraded++
+			continue
+		}
+		impact.BelowQuorum++
+		if len(aliveHosts) == 0 {
+			impact.AllReplicasLost++
+		}
+		if len(impact.BelowQuorumPartitions) < maxSimulatedBelowQuorumPartitions {
+			impact.BelowQuorumPartitions = append(impact.BelowQuorumPartitions, &proto.PlacementPartition{
+				PartitionID: dp.id,
+				VolName:     dp.volName,
+				ReplicaNum:  dp.replicaNum,
+				AliveHosts:  aliveHosts,
+				LostHosts:   lostHosts,
+			})
+		}
+	}
+	if s.estimatedSpace {
+		impact.AvailableSpace = s.available + uint64(s.added)*s.avgTotal
+	}
+	return
+}
+
+func volZones(vol *Vol) []string {
+	if vol.zoneName == "" {
+		return nil
+	}
+	return strings.Split(vol.zoneName, commaSplit)
+}
+
+// simulatePlacement simulates the effect of removing the nodes and zones and adding nodes on the
+// placement and availability of partitions, so that the maintenance plans can be validated.
+func (c *Cluster) simulatePlacement(removeNodes, removeZones []string, addDataNodes, addMetaNodes int,
+	addedZone string,
+) (result *proto.PlacementSimulation, err error) {
+	for _, zone := range removeZones {
+		if _, err = c.t.getZone(zone); err != nil {
+			return
+		}
+	}
+	if addedZone != "" {
+		if _, err = c.t.getZone(addedZone); err != nil {
+			return
+		}
+	}
+	result = &proto.PlacementSimulation{
+		AddedDataNodes: addDataNodes,
+		AddedMetaNodes: addMetaNodes,
+		AddedZone:      addedZone,
+	}
+	removing := func(addr, zone string) bool {
+		for _, a := range removeNodes {
+			if a == addr {
+				return true
+			}
+		}
+		for _, z := range removeZones {
+			if z == zone {
+				return true
+			}
+		}
+		return false
+	}
+
+	var dataNodes, metaNodes []*simNode
+	removedData, removedMeta := make(map[string]bool), make(map[string]bool)
+	c.dataNodes.Range(func(key, value interface{}) bool {
+		dataNode := value.(*DataNode)
+		dataNodes = append(dataNodes, &simNode{
+			addr:      dataNode.Addr,
+			zone:      dataNode.ZoneName,
+			active:    dataNode.isActive,
+			total:     dataNode.Total,
+			available: dataNode.AvailableSpace,
+		})
+		if removing(dataNode.Addr, dataNode.ZoneName) {
+			removedData[dataNode.Addr] = true
+			result.RemovedDataNodes = append(result.RemovedDataNodes, dataNode.Addr)
+		}
+		return true
+	})
+	c.metaNodes.Range(func(key, value interface{}) bool {
+		metaNode := value.(*MetaNode)
+		metaNodes = append(metaNodes, &simNode{
+			addr:   metaNode.Addr,
+			zone:   metaNode.ZoneName,
+			active: metaNode.IsActive,
+		})
+		if removing(metaNode.Addr, metaNode.ZoneName) {
+			removedMeta[metaNode.Addr] = true
+			result.RemovedMetaNodes = append(result.RemovedMetaNodes, metaNode.Addr)
+		}
+		return true
+	})
+	for _, addr := range removeNodes {
+		if !removedData[addr] && !removedMeta[addr] {
+			return nil, fmt.Errorf("node[%v] is not found", addr)
+		}
+	}
+	sort.Strings(result.RemovedDataNodes)
+	sort.Strings(result.RemovedMetaNodes)
+
+	var dps, mps []*simPartition
+	for _, vol := range c.allVols() {
+		zones := volZones(vol)
+		for _, dp := range vol.dataPartitions.clonePartitions() {
+			dp.RLock()
+			dps = append(dps, &simPartition{
+				id:         dp.PartitionID,
+				volName:    dp.VolName,
+				zones:      zones,
+				replicaNum: dp.ReplicaNum,
+				hosts:      append([]string{}, dp.Hosts...),
+				used:       dp.used,
+			})
+			dp.RUnlock()
+		}
+		for _, mp := range vol.cloneMetaPartitionMap() {
+			mp.RLock()
+			mps = append(mps, &simPartition{
+				id:         mp.PartitionID,
+				volName:    mp.volName,
+				zones:      zones,
+				replicaNum: mp.ReplicaNum,
+				hosts:      append([]string{}, mp.Hosts...),
+			})
+			mp.RUnlock()
+		}
+	}
+	result.DataPartitions = newPlacementSimulator(dataNodes, removedData, addDataNodes, addedZone, true).simulate(dps)
+	result.MetaPartitions = newPlacementSimulator(metaNodes, removedMeta, addMetaNodes, addedZone, false).simulate(mps)
+	return
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newSimNodes() []*simNode {
+	return []*simNode{
+		{addr: "a1", zone: "z1", active: true, total: 100, available: 50},
+		{addr: "a2", zone: "z1", active: true, total: 100, available: 50},
+		{addr: "b1", zone: "z2", active: true, total: 100, available: 50},
+		{addr: "b2", zone: "z2", active: false, total: 100, available: 50},
+		{addr: "c1", zone: "z3", active: true, total: 100, available: 50},
+	}
+}
+
+func newSimPartitions() []*simPartition {
+	return []*simPartition{
+		{id: 3, volName: "vol", replicaNum: 3, hosts: []string{"a1", "b1", "c1"}, used: 10},
+		{id: 1, volName: "vol", replicaNum: 3, hosts: []string{"a1", "a2", "b1"}, used: 10},
+		{id: 2, volName: "vol", zones: []string{"z1", "z2"}, replicaNum: 3, hosts: []string{"a1", "a2", "b2"}, used: 10},
+	}
+}
+
+func TestPlacementSimulateBaseline(t *testing.T) {
+	s := newPlacementSimulator(newSimNodes(), map[string]bool{}, 0, "", true)
+	impact := s.simulate(newSimPartitions())
+	require.Equal(t, 3, impact.Total)
+	// b2 is inactive
+	require.Equal(t, 1, impact.Degraded)
+	require.Equal(t, 0, impact.BelowQuorum)
+	require.Equal(t, 1, impact.LostReplicas)
+	// b1 is the only candidate of partition 2
+	require.Equal(t, 0, impact.UnplaceableReplicas)
+	require.Equal(t, uint64(10), impact.RequiredSpace)
+	require.Equal(t, uint64(200), impact.AvailableSpace)
+}
+
+func TestPlacementSimulateRemoveZone(t *testing.T) {
+	removed := map[string]bool{"a1": true, "a2": true}
+	s := newPlacementSimulator(newSimNodes(), removed, 0, "", true)
+	impact := s.simulate(newSimPartitions())
+	require.Equal(t, 1, impact.Degraded)
+	require.Equal(t, 2, impact.BelowQuorum)
+	require.Equal(t, 1, impact.AllReplicasLost)
+	require.Equal(t, 6, impact.LostReplicas)
+	// partition 1 has candidate c1, partition 2 has candidate b1 in its zones, partition 3 has none
+	require.Equal(t, 4, impact.UnplaceableReplicas)
+	require.Len(t, impact.BelowQuorumPartitions, 2)
+	require.Equal(t, uint64(1), impact.BelowQuorumPartitions[0].PartitionID)
+	require.Equal(t, []string{"b1"}, impact.BelowQuorumPartitions[0].AliveHosts)
+	require.Equal(t, []string{"a1", "a2"}, impact.BelowQuorumPartitions[0].LostHosts)
+	require.Empty(t, impact.BelowQuorumPartitions[1].AliveHosts)
+
+	// the added nodes out of the zones of volume don't help partition 2
+	s = newPlacementSimulator(newSimNodes(), removed, 2, "z3", true)
+	impact = s.simulate(newSimPartitions())
+	require.Equal(t, 2, impact.UnplaceableReplicas)
+	require.Equal(t, uint64(100+2*100), impact.AvailableSpace)
+
+	s = newPlacementSimulator(newSimNodes(), removed, 2, "", false)
+	impact = s.simulate(newSimPartitions())
+	require.Equal(t, 0, impact.UnplaceableReplicas)
+	require.Zero(t, impact.RequiredSpace)
+	require.Zero(t, impact.AvailableSpace)
+}
@@ -362,6 +362,16 @@ func (m *Server) checkConfig(cfg *config.Config) (err error) {
 
 	m.config.MonitorPushAddr = cfg.GetString(cfgMonitorPushAddr)
 
+	if m.config.alertRules, err = parseAlertRules(cfg.GetValue(cfgAlertRules)); err != nil {
+		return fmt.Errorf("%v,err:%v", proto.ErrInvalidCfg, err.Error())
+	}
+	if m.config.alertSmtp, err = parseAlertSmtpConfig(cfg.GetValue(cfgAlertSmtp)); err != nil {
+		return fmt.Errorf("%v,err:%v", proto.ErrInvalidCfg, err.Error())
+	}
+	if m.config.nodeAllocWeights, err = parseNodeAllocWeights(cfg.GetValue(cfgNodeAllocWeights)); err != nil {
+		return fmt.Errorf("%v,err:%v", proto.ErrInvalidCfg, err.Error())
+	}
+
 	m.config.volForceDeletion = cfg.GetBoolWithDefault(cfgVolForceDeletion, true)
 
 	threshold := cfg.GetInt64WithDefault(cfgVolDeletionDentryThreshold, 0)
@@ -430,5 +440,6 @@ func (m *Server) initCluster() {
 func (m *Server) initUser() {
 	log.LogInfo("action[initUser] begin")
 	m.user = newUser(m.fsm, m.partition)
+	m.cluster.user = m.user
 	log.LogInfo("action[initUser] end")
 }
@@ -0,0 +1,127 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+const defaultTopViewLimit = 10
+
+// topItem returns the load of a data partition. Writes are replicated to every
+// replica, so the busiest replica (usually the leader) stands for the whole partition.
+func (partition *DataPartition) topItem() (item *proto.TopItem) {
+	partition.RLock()
+	defer partition.RUnlock()
+
+	item = &proto.TopItem{
+		Name:    fmt.Sprintf("%v", partition.PartitionID),
+		VolName: partition.VolName,
+	}
+	for _, replica := range partition.Replicas {
+		stat := replica.opStat
+		if qps := stat.Qps(); qps > item.Qps || (qps == item.Qps && stat.Bandwidth() > item.Bandwidth) {
+			item.Qps = qps
+			item.Bandwidth = stat.Bandwidth()
+			item.AvgLatencyUs = stat.AvgLatencyUs
+		}
+	}
+	return
+}
+
+// clientTopItems returns the load of the clients which reported their flow info by qos upload.
+func (vol *Vol) clientTopItems() (items []*proto.TopItem) {
+	vol.qosManager.RLock()
+	defer vol.qosManager.RUnlock()
+
+	period := uint64(vol.qosManager.ClientReqPeriod)
+	if period == 0 {
+		period = 1
+	}
+	used := func(info *ClientInfoMgr, factorType uint32) uint64 {
+		if limit, ok := info.Cli.FactorMap[factorType]; ok && limit != nil {
+			return limit.Used
+		}
+		return 0
+	}
+	for _, info := range vol.qosManager.cliInfoMgrMap {
+		if info.Cli == nil {
+			continue
+		}
+		items = append(items, &proto.TopItem{
+			Name:      fmt.Sprintf("%v(%v)", info.Host, info.ID),
+			VolName:   vol.Name,
+			Qps:       (used(info, proto.IopsReadType) + used(info, proto.IopsWriteType)) / period,
+			Bandwidth: (used(info, proto.FlowReadType) + used(info, proto.FlowWriteType)) / period,
+		})
+	}
+	return
+}
+
+func sortTopItems(items []*proto.TopItem, sortBy string, limit int) []*proto.TopItem {
+	sort.SliceStable(items, func(i, j int) bool {
+		switch sortBy {
+		case proto.TopSortByBandwidth:
+			return items[i].Bandwidth > items[j].Bandwidth
+		case proto.TopSortByLatency:
+			return items[i].AvgLatencyUs > items[j].AvgLatencyUs
+		default:
+			return items[i].Qps > items[j].Qps
+		}
+	})
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+// getTopView ranks volumes, data partitions and clients by their load reported to master.
+func (c *Cluster) getTopView(sortBy string, limit int) (view *proto.TopView) {
+	if limit <= 0 {
+		limit = defaultTopViewLimit
+	}
+	view = &proto.TopView{
+		SortBy:     sortBy,
+		ReportTime: time.Now().Unix(),
+	}
+	var volumes, partitions, clients []*proto.TopItem
+	for _, vol := range c.allVols() {
+		volItem := &proto.TopItem{Name: vol.Name, VolName: vol.Name}
+		var latencySum, busyPartitions uint64
+		for _, dp := range vol.dataPartitions.clonePartitions() {
+			item := dp.topItem()
+			volItem.Qps += item.Qps
+			volItem.Bandwidth += item.Bandwidth
+			if item.Qps > 0 {
+				latencySum += item.AvgLatencyUs * item.Qps
+				busyPartitions += item.Qps
+			}
+			partitions = append(partitions, item)
+		}
+		if busyPartitions > 0 {
+			volItem.AvgLatencyUs = latencySum / busyPartitions
+		}
+		volumes = append(volumes, volItem)
+		clients = append(clients, vol.clientTopItems()...)
+	}
+	view.Volumes = sortTopItems(volumes, sortBy, limit)
+	view.Partitions = sortTopItems(partitions, sortBy, limit)
+	view.Clients = sortTopItems(clients, sortBy, limit)
+	return
+}
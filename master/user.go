@@ -410,6 +410,59 @@ func (u *User) deleteVolPolicy(volName string) (err error) {
 	return
 }
 
+// renameVolPolicy moves the policies and the users index of the vol to the new name.
+func (u *User) renameVolPolicy(volName, newVolName string) (err error) {
+	var (
+		volUser  *proto.VolUser
+		userInfo *proto.UserInfo
+		userIDs  []string
+	)
+	if userIDs, err = u.getUsersOfVol(volName); err != nil {
+		if err == proto.ErrHaveNoPolicy {
+			return nil
+		}
+		return
+	}
+	for _, userID := range userIDs {
+		if userInfo, err = u.getUserInfo(userID); err != nil {
+			if err == proto.ErrUserNotExists {
+				log.LogWarnf("action[renameVolPolicy], userID: %v does not exist", userID)
+				continue
+			}
+			return
+		}
+		userInfo.Mu.Lock()
+		userInfo.Policy.RenameVol(volName, newVolName)
+		if err = u.syncUpdateUserInfo(userInfo); err != nil {
+			err = proto.ErrPersistenceByRaft
+			userInfo.Mu.Unlock()
+			return
+		}
+		userInfo.Mu.Unlock()
+	}
+	// move volName index
+	u.volUserMutex.Lock()
+	defer u.volUserMutex.Unlock()
+	if value, exist := u.volUser.Load(volName); exist {
+		volUser = value.(*proto.VolUser)
+	} else {
+		return nil
+	}
+	volUser.Mu.Lock()
+	defer volUser.Mu.Unlock()
+	newVolUser := &proto.VolUser{Vol: newVolName, UserIDs: volUser.UserIDs}
+	if err = u.syncAddVolUser(newVolUser); err != nil {
+		return proto.ErrPersistenceByRaft
+	}
+	if err = u.syncDeleteVolUser(volUser); err != nil {
+		return proto.ErrPersistenceByRaft
+	}
+	u.volUser.Store(newVolName, newVolUser)
+	u.volUser.Delete(volName)
+	log.LogInfof("action[renameVolPolicy], volName: %v, newVolName: %v", volName, newVolName)
+	return
+}
+
 func (u *User) transferVol(params *proto.UserTransferVolParam) (targetUserInfo *proto.UserInfo, err error) {
 	var userInfo *proto.UserInfo
 	userInfo, err = u.getUserInfo(params.UserSrc)
@@ -436,6 +489,22 @@ func (u *User) transferVol(params *proto.UserTransferVolParam) (targetUserInfo *
 	return
 }
 
+// setCapacityQuota sets the capacity quota shared by the volumes and buckets owned by the user.
+func (u *User) setCapacityQuota(params *proto.UserQuotaParam) (userInfo *proto.UserInfo, err error) {
+	if userInfo, err = u.getUserInfo(params.UserID); err != nil {
+		return
+	}
+	userInfo.Mu.Lock()
+	defer userInfo.Mu.Unlock()
+	userInfo.CapacityQuota = params.CapacityQuota
+	if err = u.syncUpdateUserInfo(userInfo); err != nil {
+		err = proto.ErrPersistenceByRaft
+		return
+	}
+	log.LogInfof("action[setCapacityQuota], userID: %v, capacityQuota: %vGB", params.UserID, params.CapacityQuota)
+	return
+}
+
 func (u *User) getAllUserInfo(keywords string) (users []*proto.UserInfo) {
 	users = make([]*proto.UserInfo, 0)
 	u.userStore.Range(func(key, value interface{}) bool {
@@ -0,0 +1,82 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// getUserUsage returns the total consumption of the user across the owned volumes, the buckets
+// of the object storage are the volumes either, so the quota is shared by both protocols.
+func (c *Cluster) getUserUsage(userInfo *proto.UserInfo) *proto.UserUsage {
+	userInfo.Mu.RLock()
+	usage := &proto.UserUsage{UserID: userInfo.UserID, CapacityQuota: userInfo.CapacityQuota}
+	ownVols := append([]string{}, userInfo.Policy.OwnVols...)
+	userInfo.Mu.RUnlock()
+
+	usage.Vols = make([]*proto.UserVolUsage, 0, len(ownVols))
+	for _, volName := range ownVols {
+		vol, err := c.getVol(volName)
+		if err != nil {
+			continue
+		}
+		used := vol.totalUsedSpace()
+		usage.UsedSize += used
+		usage.Vols = append(usage.Vols, &proto.UserVolUsage{Name: volName, Capacity: vol.capacity(), UsedSize: used})
+	}
+	usage.OverQuota = usage.CapacityQuota > 0 && usage.UsedSize >= usage.CapacityQuota*util.GB
+	return usage
+}
+
+// checkUserCapacityQuota marks all the volumes of the user readonly if the capacity quota is
+// exceeded, and writable again once the usage is under the quota.
+func (c *Cluster) checkUserCapacityQuota() {
+	if c.user == nil {
+		return
+	}
+	c.user.userStore.Range(func(key, value interface{}) bool {
+		usage := c.getUserUsage(value.(*proto.UserInfo))
+		for _, volUsage := range usage.Vols {
+			vol, err := c.getVol(volUsage.Name)
+			if err != nil {
+				continue
+			}
+			if vol.IsReadOnlyForUserQuota() != usage.OverQuota {
+				log.LogWarnf("action[checkUserCapacityQuota] user[%v] vol[%v] readonly[%v], used[%v] quota[%vGB]",
+					usage.UserID, vol.Name, usage.OverQuota, usage.UsedSize, usage.CapacityQuota)
+				vol.SetReadOnlyForUserQuota(usage.OverQuota)
+			}
+		}
+		return true
+	})
+}
+
+// checkOwnerCapacityQuota returns an error if the owner exceeds the capacity quota, no more
+// volumes or buckets can be created by the owner.
+func (c *Cluster) checkOwnerCapacityQuota(owner string) error {
+	if c.user == nil {
+		return nil
+	}
+	value, ok := c.user.userStore.Load(owner)
+	if !ok {
+		return nil
+	}
+	if c.getUserUsage(value.(*proto.UserInfo)).OverQuota {
+		return proto.ErrUserCapacityQuotaExceeded
+	}
+	return nil
+}
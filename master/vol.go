@@ -53,6 +53,9 @@ type VolVarargs struct {
 	trashInterval           int64
 	crossZone               bool
 	enableAutoDpMetaRepair  bool
+	affinityLabels          []string
+	antiAffinityLabels      []string
+	fsyncPolicy             string
 }
 
 // Vol represents a set of meta partitionMap and data partitionMap
@@ -89,12 +92,17 @@ type Vol struct {
 	domainOn                bool
 	defaultPriority         bool // old default zone first
 	enablePosixAcl          bool
+	caseInsensitive         bool // lookup dentry ignoring case, it can only be set on creation
 	enableTransaction       proto.TxOpMask
 	txTimeout               int64
 	txConflictRetryNum      int64
 	txConflictRetryInterval int64
 	txOpLimit               int
+	affinityLabels          []string // partitions are only allocated on the nodes with all of the labels
+	antiAffinityLabels      []string // partitions are never allocated on the nodes with any of the labels
+	fsyncPolicy             string   // when the extents are fsynced on the data nodes
 	zoneName                string
+	featureOptIns           atomic.Value              // []string, the feature flags opted in
 	MetaPartitions          map[uint64]*MetaPartition `graphql:"-"`
 	dataPartitions          *DataPartitionMap
 	mpsCache                []byte
@@ -110,6 +118,7 @@ type Vol struct {
 	qosManager              *QosCtrlManager
 	DpReadOnlyWhenVolFull   bool // only if this switch is on, all dp becomes readonly when vol is full
 	ReadOnlyForVolFull      bool // only if the switch DpReadOnlyWhenVolFull is on, mark vol is readonly when is full
+	readOnlyForUserQuota    bool // all the volumes of the owner are readonly when the capacity quota of owner is exceeded
 	aclMgr                  AclManager
 	uidSpaceManager         *UidSpaceManager
 	volLock                 sync.RWMutex
@@ -126,6 +135,9 @@ type Vol struct {
 	user                    *User
 	dpRepairBlockSize       uint64
 	EnableAutoMetaRepair    atomicutil.Bool
+	previousNames           []string // names before renamed, partitions on nodes may still refer to them
+	cloneSource             string   // the volume which is cloned from
+	cloneVerSeq             uint64   // the snapshot version of the clone source which the clone shares data with
 }
 
 func newVol(vv volValue) (vol *Vol) {
@@ -154,6 +166,17 @@ func newVol(vv volValue) (vol *Vol) {
 	vol.defaultPriority = vv.DefaultPriority
 	vol.domainId = vv.DomainId
 	vol.enablePosixAcl = vv.EnablePosixAcl
+	vol.caseInsensitive = vv.CaseInsensitive
+	vol.affinityLabels = vv.AffinityLabels
+	vol.antiAffinityLabels = vv.AntiAffinityLabels
+	vol.featureOptIns.Store(vv.FeatureOptIns)
+	vol.fsyncPolicy = vv.FsyncPolicy
+	if vol.fsyncPolicy == "" {
+		vol.fsyncPolicy = proto.DefaultFsyncPolicy
+	}
+	vol.previousNames = vv.PreviousNames
+	vol.cloneSource = vv.CloneSource
+	vol.cloneVerSeq = vv.CloneVerSeq
 	vol.enableQuota = vv.EnableQuota
 	vol.enableTransaction = vv.EnableTransaction
 	vol.txTimeout = vv.TxTimeout
@@ -861,6 +884,15 @@ func (vol *Vol) status() uint8 {
 	return vol.Status
 }
 
+func (vol *Vol) isPreviousName(name string) bool {
+	for _, previousName := range vol.previousNames {
+		if previousName == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (vol *Vol) capacity() uint64 {
 	vol.volLock.RLock()
 	defer vol.volLock.RUnlock()
@@ -886,6 +918,18 @@ func (vol *Vol) IsReadOnlyForVolFull() bool {
 	return vol.ReadOnlyForVolFull
 }
 
+func (vol *Vol) SetReadOnlyForUserQuota(exceeded bool) {
+	vol.volLock.Lock()
+	defer vol.volLock.Unlock()
+	vol.readOnlyForUserQuota = exceeded
+}
+
+func (vol *Vol) IsReadOnlyForUserQuota() bool {
+	vol.volLock.RLock()
+	defer vol.volLock.RUnlock()
+	return vol.readOnlyForUserQuota
+}
+
 func (vol *Vol) autoDeleteDp(c *Cluster) {
 	if vol.dataPartitions == nil {
 		return
@@ -929,7 +973,7 @@ func (vol *Vol) checkAutoDataPartitionCreation(c *Cluster) {
 
 	vol.setStatus(proto.VolStatusNormal)
 	log.LogInfof("action[autoCreateDataPartitions] vol[%v] before autoCreateDataPartitions", vol.Name)
-	if !c.DisableAutoAllocate && !vol.Forbidden {
+	if !c.DisableAutoAllocate && !vol.Forbidden && !vol.IsReadOnlyForUserQuota() {
 		vol.autoCreateDataPartitions(c)
 	}
 }
@@ -1484,7 +1528,8 @@ func (vol *Vol) doCreateMetaPartition(c *Cluster, start, end uint64) (mp *MetaPa
 	} else {
 		var excludeZone []string
 		zoneNum := c.decideZoneNum(vol)
-		if hosts, peers, err = c.getHostFromNormalZone(TypeMetaPartition, excludeZone, nil, nil, int(vol.mpReplicaNum), zoneNum, vol.zoneName); err != nil {
+		excludeNodeSets, excludeHosts := c.getLabelExcludes(vol, TypeMetaPartition, int(vol.mpReplicaNum), zoneNum)
+		if hosts, peers, err = c.getHostFromNormalZone(TypeMetaPartition, excludeZone, excludeNodeSets, excludeHosts, int(vol.mpReplicaNum), zoneNum, vol.zoneName); err != nil {
 			log.LogErrorf("action[doCreateMetaPartition] getHostFromNormalZone err[%v]", err)
 			return nil, errors.NewError(err)
 		}
@@ -1563,6 +1608,9 @@ func setVolFromArgs(args *VolVarargs, vol *Vol) {
 	vol.txOpLimit = args.txOpLimit
 	vol.dpReplicaNum = args.dpReplicaNum
 	vol.crossZone = args.crossZone
+	vol.affinityLabels = args.affinityLabels
+	vol.antiAffinityLabels = args.antiAffinityLabels
+	vol.fsyncPolicy = args.fsyncPolicy
 
 	if proto.IsCold(vol.VolType) {
 		coldArgs := args.coldArgs
@@ -1619,6 +1667,9 @@ func getVolVarargs(vol *Vol) *VolVarargs {
 		coldArgs:                args,
 		dpReadOnlyWhenVolFull:   vol.DpReadOnlyWhenVolFull,
 		enableAutoDpMetaRepair:  vol.EnableAutoMetaRepair.Load(),
+		affinityLabels:          vol.affinityLabels,
+		antiAffinityLabels:      vol.antiAffinityLabels,
+		fsyncPolicy:             vol.fsyncPolicy,
 	}
 }
 
@@ -1653,9 +1704,10 @@ func (vol *Vol) loadQuotaManager(c *Cluster) (err error) {
 			return err
 		}
 		log.LogDebugf("loadQuotaManager info [%v]", quotaInfo)
-		if vol.Name != quotaInfo.VolName {
+		if vol.Name != quotaInfo.VolName && !vol.isPreviousName(quotaInfo.VolName) {
 			panic(fmt.Sprintf("vol name do not match vol name [%v], quotaInfo vol name [%v]", vol.Name, quotaInfo.VolName))
 		}
+		quotaInfo.VolName = vol.Name
 		vol.quotaManager.IdQuotaInfoMap[quotaInfo.QuotaId] = quotaInfo
 	}
 
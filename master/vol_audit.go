@@ -0,0 +1,166 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/exporter"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// audit ops of the namespace-changing admin operations of volumes
+const (
+	volAuditOpCreate      = "create"
+	volAuditOpDelete      = "delete"
+	volAuditOpUndelete    = "undelete"
+	volAuditOpUpdate      = "update"
+	volAuditOpExpand      = "expand"
+	volAuditOpShrink      = "shrink"
+	volAuditOpTransfer    = "transfer"
+	volAuditOpQuotaCreate = "quotaCreate"
+	volAuditOpQuotaUpdate = "quotaUpdate"
+	volAuditOpQuotaDelete = "quotaDelete"
+
+	volAuditResultOk       = "ok"
+	volAuditAnonymous      = "anonymous"
+	defaultVolAuditListCnt = 100
+)
+
+func volAuditKeyPrefix(volName string) string {
+	return volAuditPrefix + volName + keySeparator
+}
+
+// the time is fixed width so that the records of a volume are in time order in the store
+func volAuditKey(volName string, ts int64) string {
+	return fmt.Sprintf("%v%020d", volAuditKeyPrefix(volName), ts)
+}
+
+// getAuditPrincipal returns the principal who sends the request, which is the client id
+// authenticated by the authnode, or the user id of the request.
+func getAuditPrincipal(r *http.Request) string {
+	if clientIDKey := r.FormValue(ClientIDKey); clientIDKey != "" {
+		if clientID, _, err := proto.ExtractIDAndAuthKey(clientIDKey); err == nil {
+			return clientID
+		}
+	}
+	if userID := r.Header.Get(string(proto.UserKey)); userID != "" {
+		return userID
+	}
+	return volAuditAnonymous
+}
+
+func (c *Cluster) syncPutVolAudit(record *proto.VolAuditRecord) (err error) {
+	metadata := new(RaftCmd)
+	metadata.Op = opSyncPutVolAudit
+	metadata.K = volAuditKey(record.Vol, record.Time)
+	if metadata.V, err = json.Marshal(record); err != nil {
+		return
+	}
+	return c.submit(metadata)
+}
+
+// recordVolAudit persists the audit record of the admin operation on the volume, the
+// operation itself is not failed if the record can't be persisted.
+func (m *Server) recordVolAudit(r *http.Request, volName, op, detail string, opErr error) {
+	record := &proto.VolAuditRecord{
+		Time:      time.Now().UnixNano(),
+		Vol:       volName,
+		Op:        op,
+		Principal: getAuditPrincipal(r),
+		Client:    r.RemoteAddr,
+		Detail:    detail,
+		Result:    volAuditResultOk,
+	}
+	if opErr != nil {
+		record.Result = opErr.Error()
+	}
+	if err := m.cluster.syncPutVolAudit(record); err != nil {
+		log.LogErrorf("action[recordVolAudit] vol[%v] op[%v] principal[%v] persist failed, err[%v]",
+			volName, op, record.Principal, err)
+		return
+	}
+	log.LogInfof("action[recordVolAudit] vol[%v] op[%v] principal[%v] client[%v] detail[%v] result[%v]",
+		volName, op, record.Principal, record.Client, detail, record.Result)
+}
+
+// getVolAuditRecords returns the newest limit records of the volume in [start, end] of unix seconds
+// from the oldest to the newest, end of 0 means no upper bound. The records of deleted volumes are kept.
+func (c *Cluster) getVolAuditRecords(volName string, start, end int64, limit int) (records []*proto.VolAuditRecord, err error) {
+	result, err := c.fsm.store.SeekForPrefix([]byte(volAuditKeyPrefix(volName)))
+	if err != nil {
+		err = fmt.Errorf("action[getVolAuditRecords] seek vol[%v] failed, err[%v]", volName, err)
+		return
+	}
+	records = make([]*proto.VolAuditRecord, 0, len(result))
+	for _, value := range result {
+		record := &proto.VolAuditRecord{}
+		if err = json.Unmarshal(value, record); err != nil {
+			err = fmt.Errorf("action[getVolAuditRecords] unmarshal vol[%v] failed, err[%v]", volName, err)
+			return
+		}
+		if ts := record.Time / int64(time.Second); ts < start || (end > 0 && ts > end) {
+			continue
+		}
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Time < records[j].Time })
+	if limit > 0 && len(records) > limit {
+		records = records[len(records)-limit:]
+	}
+	return
+}
+
+func (m *Server) getVolAuditTrail(w http.ResponseWriter, r *http.Request) {
+	var (
+		name       string
+		start, end int64
+		limit      int
+		records    []*proto.VolAuditRecord
+		err        error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminVolAuditTrail))
+	defer func() {
+		doStatAndMetric(proto.AdminVolAuditTrail, metric, err, map[string]string{exporter.Vol: name})
+	}()
+
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if start, err = extractInt64WithDefault(r, startTimeKey, 0); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if end, err = extractInt64WithDefault(r, endTimeKey, 0); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if limit, err = extractUintWithDefault(r, Limit, defaultVolAuditListCnt); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	if records, err = m.cluster.getVolAuditRecords(name, start, end, limit); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(records))
+}
@@ -447,3 +447,79 @@ func TestConcurrentReadWriteDataPartitionMap(t *testing.T) {
 		vol.updateViewCache(server.cluster)
 	}
 }
+
+func TestRenameVol(t *testing.T) {
+	name := "renameVol"
+	newName := "renamedVol"
+	createVol(map[string]interface{}{nameKey: name}, t)
+
+	req := map[string]interface{}{
+		nameKey:    name,
+		newNameKey: newName,
+		volAuthKey: buildAuthKey(testOwner),
+	}
+	processWithFatalV2(proto.AdminRenameVol, true, req, t)
+
+	view := getSimpleVol(newName, true, t)
+	require.Equal(t, newName, view.Name)
+	require.Equal(t, []string{name}, view.PreviousNames)
+	// the previous name is still resolved
+	vol, err := server.cluster.getVol(name)
+	require.NoError(t, err)
+	require.Equal(t, newName, vol.Name)
+	for _, dp := range vol.dataPartitions.clonePartitions() {
+		require.Equal(t, newName, dp.VolName)
+	}
+	for _, mp := range vol.cloneMetaPartitionMap() {
+		require.Equal(t, newName, mp.volName)
+	}
+	userInfo, err := server.user.getUserInfo(testOwner)
+	require.NoError(t, err)
+	require.True(t, userInfo.Policy.IsOwn(newName))
+	require.False(t, userInfo.Policy.IsOwn(name))
+
+	// name of the renamed vol can't be taken
+	createReq := map[string]interface{}{nameKey: name, volTypeKey: proto.VolumeTypeHot, volOwnerKey: testOwner,
+		zoneNameKey: testZone2, volCapacityKey: 300, replicaNumKey: 3}
+	processWithFatalV2(proto.AdminCreateVol, false, createReq, t)
+}
+
+func TestCloneVolSharedPartitions(t *testing.T) {
+	srcName := "cloneSrcVol"
+	cloneName := "clonedVol"
+	createVol(map[string]interface{}{nameKey: srcName}, t)
+	createVol(map[string]interface{}{nameKey: cloneName}, t)
+	src, err := server.cluster.getVol(srcName)
+	require.NoError(t, err)
+	clone, err := server.cluster.getVol(cloneName)
+	require.NoError(t, err)
+	// the clone api creates the snapshot on meta nodes, which is not supported by the mock meta nodes
+	clone.cloneSource = srcName
+	clone.cloneVerSeq = 1
+	defer func() {
+		clone.cloneSource = ""
+	}()
+	require.Equal(t, []*Vol{clone}, server.cluster.getVolClones(src))
+
+	server.cluster.updateSharedPartitions(clone)
+	shared := clone.dataPartitions.getSharedPartitionsView(0)
+	require.Len(t, shared, len(src.dataPartitions.clonePartitions()))
+	for _, dpResp := range shared {
+		require.True(t, dpResp.IsShared)
+		require.Equal(t, int8(proto.ReadOnly), dpResp.Status)
+		_, err = src.getDataPartitionByID(dpResp.PartitionID)
+		require.NoError(t, err)
+	}
+	body, err := clone.dataPartitions.updateResponseCache(true, 0, clone)
+	require.NoError(t, err)
+	reply := struct{ Data *proto.DataPartitionsView }{}
+	require.NoError(t, json.Unmarshal(body, &reply))
+	require.Len(t, reply.Data.DataPartitions, len(clone.dataPartitions.clonePartitions())+len(shared))
+
+	// the source and the snapshot shared with the clone are not deleted
+	require.Error(t, server.cluster.markDeleteVol(srcName, buildAuthKey(testOwner), false, true))
+	require.Equal(t, proto.VolStatusNormal, src.Status)
+	_, err = src.VersionMgr.createVer2PhaseTask(server.cluster, clone.cloneVerSeq, proto.DeleteVersion, false)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), cloneName)
+}
@@ -23,6 +23,8 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"sort"
+	"time"
 
 	"github.com/cubefs/cubefs/cmd/common"
 	"github.com/cubefs/cubefs/proto"
@@ -58,10 +60,13 @@ func (m *MetaNode) registerAPIHandler() (err error) {
 	http.HandleFunc("/getPartitions", m.getPartitionsHandler)
 	http.HandleFunc("/getPartitionById", m.getPartitionByIDHandler)
 	http.HandleFunc("/getLeaderPartitions", m.getLeaderPartitionsHandler)
+	http.HandleFunc("/freezePartition", m.freezePartitionHandler)
+	http.HandleFunc("/thawPartition", m.thawPartitionHandler)
 	http.HandleFunc("/getInode", m.getInodeHandler)
 	http.HandleFunc("/getSplitKey", m.getSplitKeyHandler)
 	http.HandleFunc("/getExtentsByInode", m.getExtentsByInodeHandler)
 	http.HandleFunc("/getEbsExtentsByInode", m.getEbsExtentsByInodeHandler)
+	http.HandleFunc("/getExtentIdsByDp", m.getExtentIdsByDpHandler)
 	// get all inodes of the partitionID
 	http.HandleFunc("/getAllInodes", m.getAllInodesHandler)
 	// get dentry information
@@ -75,8 +80,15 @@ func (m *MetaNode) registerAPIHandler() (err error) {
 	http.HandleFunc("/genClusterVersionFile", m.genClusterVersionFileHandler)
 	http.HandleFunc("/getInodeSnapshot", m.getInodeSnapshotHandler)
 	http.HandleFunc("/getDentrySnapshot", m.getDentrySnapshotHandler)
+	// export the namespace of the partition
+	http.HandleFunc("/exportPartition", m.exportPartitionHandler)
+	// verify the audit chain of the privileged operations
+	http.HandleFunc("/getAuditChain", m.getAuditChainHandler)
 	// get tx information
 	http.HandleFunc("/getTx", m.getTxHandler)
+	// per-volume qos of meta requests
+	http.HandleFunc("/getVolQos", m.getVolQosHandler)
+	http.HandleFunc("/setVolQos", m.setVolQosHandler)
 	return
 }
 
@@ -148,11 +160,76 @@ func (m *MetaNode) getPartitionByIDHandler(w http.ResponseWriter, r *http.Reques
 	msg["peers"] = conf.Peers
 	msg["nodeId"] = conf.NodeId
 	msg["cursor"] = conf.Cursor
+	msg["frozen"] = mp.IsFrozen()
 	resp.Data = msg
 	resp.Code = http.StatusOK
 	resp.Msg = http.StatusText(http.StatusOK)
 }
 
+func (m *MetaNode) freezePartitionHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[freezePartitionHandler] response %s", err)
+		}
+	}()
+	var pid, timeout common.Uint
+	if err := parseArgs(r, pid.PID(), timeout.Key("timeout").OmitEmpty()); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	mp, err := m.metadataManager.GetPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	if _, ok := mp.IsLeader(); !ok {
+		resp.Msg = ErrNotALeader.Error()
+		return
+	}
+	applyID, err := mp.Freeze(time.Duration(timeout.V) * time.Second)
+	if err != nil {
+		resp.Code = http.StatusInternalServerError
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Data = map[string]interface{}{
+		"partition_id": pid.V,
+		"apply_id":     applyID,
+	}
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+}
+
+func (m *MetaNode) thawPartitionHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[thawPartitionHandler] response %s", err)
+		}
+	}()
+	var pid common.Uint
+	if err := parseArgs(r, pid.PID()); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	mp, err := m.metadataManager.GetPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	if !mp.Thaw() {
+		resp.Msg = "meta partition is not frozen"
+		return
+	}
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+}
+
 func (m *MetaNode) getLeaderPartitionsHandler(w http.ResponseWriter, r *http.Request) {
 	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
 	mps := m.metadataManager.GetLeaderPartitions()
@@ -230,6 +307,48 @@ func (m *MetaNode) getAllInodesHandler(w http.ResponseWriter, r *http.Request) {
 	mp.GetInodeTree().Ascend(f)
 }
 
+func (m *MetaNode) exportPartitionHandler(w http.ResponseWriter, r *http.Request) {
+	var pid, verSeq common.Uint
+	if err := parseArgs(r, pid.PID(), verSeq.Key("verSeq").OmitEmpty()); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	mp, err := m.metadataManager.GetPartition(pid.V)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	// the stream without trailer is taken as truncated by the readers
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	if err = mp.ExportMeta(w, verSeq.V); err != nil {
+		log.LogErrorf("[exportPartitionHandler] export partition(%v) err(%v)", pid.V, err)
+	}
+}
+
+func (m *MetaNode) getAuditChainHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getAuditChainHandler] response %s", err)
+		}
+	}()
+	var pid common.Uint
+	if err := parseArgs(r, pid.PID()); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	mp, err := m.metadataManager.GetPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Data = mp.VerifyAuditChain()
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+}
+
 func (m *MetaNode) getSplitKeyHandler(w http.ResponseWriter, r *http.Request) {
 	log.LogDebugf("getSplitKeyHandler")
 	resp := NewAPIResponse(http.StatusBadRequest, "")
@@ -438,6 +557,79 @@ func (m *MetaNode) getExtentsByInodeHandler(w http.ResponseWriter,
 	}
 }
 
+// getExtentIdsByDpHandler returns the ids of the extents of the data partition referenced by the
+// inodes and their snapshot versions of the meta partition, it's served by the leader only so the
+// extent keys appended before are all collected.
+func (m *MetaNode) getExtentIdsByDpHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getExtentIdsByDpHandler] response %s", err)
+		}
+	}()
+	var pid, dpID common.Uint
+	if err := parseArgs(r, pid.PID(), dpID.Key("dpId")); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	mp, err := m.metadataManager.GetPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	partition, ok := mp.(*metaPartition)
+	if !ok {
+		resp.Code = http.StatusInternalServerError
+		resp.Msg = fmt.Sprintf("mp(%v) is not supported", pid.V)
+		return
+	}
+	if leader, ok := mp.IsLeader(); !ok {
+		resp.Code = http.StatusServiceUnavailable
+		resp.Msg = fmt.Sprintf("mp(%v) is not leader, leader(%v)", pid.V, leader)
+		return
+	}
+
+	resp.Code = http.StatusOK
+	resp.Data = partition.referencedExtentIDs(dpID.V)
+}
+
+// referencedExtentIDs returns the sorted ids of the extents of the data partition referenced by
+// the inodes and their snapshot versions.
+func (mp *metaPartition) referencedExtentIDs(partitionID uint64) []uint64 {
+	extentIDs := make(map[uint64]struct{})
+	collect := func(ino *Inode) {
+		if ino.Extents == nil {
+			return
+		}
+		ino.Extents.Range(func(_ int, ek proto.ExtentKey) bool {
+			if ek.PartitionId == partitionID {
+				extentIDs[ek.ExtentId] = struct{}{}
+			}
+			return true
+		})
+	}
+	mp.GetInodeTree().Ascend(func(i BtreeItem) bool {
+		ino := i.(*Inode)
+		ino.RLock()
+		collect(ino)
+		ino.RangeMultiVer(func(_ int, v *Inode) bool {
+			collect(v)
+			return true
+		})
+		ino.RUnlock()
+		return true
+	})
+
+	ids := make([]uint64, 0, len(extentIDs))
+	for id := range extentIDs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
 func (m *MetaNode) getDentryHandler(w http.ResponseWriter, r *http.Request) {
 	resp := NewAPIResponse(http.StatusBadRequest, "")
 	defer func() {
@@ -802,3 +994,62 @@ func (m *MetaNode) getSnapshotHandler(w http.ResponseWriter, r *http.Request, fi
 		return
 	}
 }
+
+func (m *MetaNode) getVolQosHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getVolQosHandler] response %s", err)
+		}
+	}()
+	manager, ok := m.metadataManager.(*metadataManager)
+	if !ok {
+		resp.Code = http.StatusInternalServerError
+		resp.Msg = "metadata manager is not ready"
+		return
+	}
+	resp.Data = manager.volQos.getConfig()
+}
+
+// setVolQosHandler sets the qos limit of the volume, or the default limit of volumes if vol is empty.
+// The limit is not persisted, and is reset to the config after restart.
+func (m *MetaNode) setVolQosHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[setVolQosHandler] response %s", err)
+		}
+	}()
+	var (
+		vol                common.String
+		concurrency, queue common.Int
+		queueTimeoutMs     common.Int
+	)
+	if err := parseArgs(r, vol.Key("vol").OmitEmpty(),
+		concurrency.Key("maxConcurrency").OmitEmpty(),
+		queue.Key("maxQueue").OmitEmpty(),
+		queueTimeoutMs.Key("queueTimeoutMs").OmitEmpty()); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	manager, ok := m.metadataManager.(*metadataManager)
+	if !ok {
+		resp.Code = http.StatusInternalServerError
+		resp.Msg = "metadata manager is not ready"
+		return
+	}
+	limit := VolQosLimit{
+		MaxConcurrency: int(concurrency.V),
+		MaxQueue:       int(queue.V),
+		QueueTimeoutMs: queueTimeoutMs.V,
+	}
+	if err := manager.volQos.setLimit(vol.V, limit); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	log.LogInfof("[setVolQosHandler] set vol(%v) qos limit %+v", vol.V, limit)
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+}
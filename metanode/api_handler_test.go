@@ -10,6 +10,7 @@ import (
 	"path"
 	"testing"
 
+	"github.com/cubefs/cubefs/proto"
 	"github.com/stretchr/testify/require"
 )
 
@@ -159,3 +160,35 @@ func TestWithWrongMetaPartitionID(t *testing.T) {
 	data := httpReqHandle(url, t)
 	require.Contains(t, string(data), "unknown meta partition")
 }
+
+func TestGetExtentIdsByDp(t *testing.T) {
+	testPath := "/tmp/testMetaNodeApiHandler/"
+	os.RemoveAll(testPath)
+	defer os.RemoveAll(testPath)
+
+	mp := createMetaPartition(testPath, t)
+	require.NotNil(t, mp)
+
+	file := NewInode(2, proto.Mode(os.ModePerm))
+	file.Extents = NewSortedExtentsFromEks([]proto.ExtentKey{
+		{FileOffset: 0, PartitionId: 1, ExtentId: 1026, Size: 100},
+		{FileOffset: 100, PartitionId: 2, ExtentId: 1025, Size: 100},
+		{FileOffset: 200, PartitionId: 1, ExtentId: 1025, Size: 100},
+	})
+	// the extents of the snapshot versions are referenced too
+	version := NewInode(2, proto.Mode(os.ModePerm))
+	version.Extents = NewSortedExtentsFromEks([]proto.ExtentKey{{FileOffset: 0, PartitionId: 1, ExtentId: 1027, Size: 100}})
+	file.multiSnap = NewMultiSnap(1)
+	file.multiSnap.multiVersions = append(file.multiSnap.multiVersions, version)
+	mp.inodeTree.ReplaceOrInsert(file, true)
+
+	require.Equal(t, []uint64{1025, 1026, 1027}, mp.referencedExtentIDs(1))
+	require.Equal(t, []uint64{1025}, mp.referencedExtentIDs(2))
+	require.Empty(t, mp.referencedExtentIDs(3))
+
+	// only the leader serves the extents
+	url := fmt.Sprintf("http://127.0.0.1:%v%v?pid=%v&dpId=%v",
+		PROF_PORT, "/getExtentIdsByDp", METAPARTITION_ID, 1)
+	data := httpReqHandle(url, t)
+	require.Contains(t, string(data), "is not leader")
+}
@@ -0,0 +1,186 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"sync"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/errors"
+)
+
+const (
+	auditChainKeepEntries = 65536
+	auditQuotaMaxInodes   = 16 // the inodes of a quota batch recorded in the detail
+
+	auditOpChown       = "chown"
+	auditOpChmod       = "chmod"
+	auditOpSetQuota    = "setQuota"
+	auditOpDeleteQuota = "deleteQuota"
+)
+
+// auditChainEntry is a privileged operation applied by the partition, it is chained to the
+// previous entry by the hash, so any entry modified or removed breaks the chain.
+type auditChainEntry struct {
+	ApplyID  uint64 `json:"apply_id"`
+	Op       string `json:"op"`
+	Inode    uint64 `json:"ino"`
+	Detail   string `json:"detail"`
+	PrevHash string `json:"prev_hash"`
+	Hash     string `json:"hash"`
+}
+
+func (e *auditChainEntry) sum() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d|%s|%d|%s|%s", e.ApplyID, e.Op, e.Inode, e.Detail, e.PrevHash)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// auditChainReport is the result of verifying the audit chain of the partition.
+type auditChainReport struct {
+	Verified bool               `json:"verified"`
+	BrokenAt int                `json:"broken_at"` // position of the first bad entry, -1 if verified
+	Entries  []*auditChainEntry `json:"entries"`
+}
+
+// auditChain records the privileged operations in the raft apply order, it is deterministic
+// over the replicas and is replicated by the snapshot of the partition. The oldest entries are
+// evicted over auditChainKeepEntries, the chain is verified from the first entry retained.
+type auditChain struct {
+	sync.Mutex
+	entries []*auditChainEntry
+}
+
+func newAuditChain() *auditChain {
+	return &auditChain{entries: make([]*auditChainEntry, 0)}
+}
+
+func (chain *auditChain) append(applyID uint64, op string, ino uint64, detail string) {
+	chain.Lock()
+	defer chain.Unlock()
+	entry := &auditChainEntry{ApplyID: applyID, Op: op, Inode: ino, Detail: detail}
+	if n := len(chain.entries); n > 0 {
+		entry.PrevHash = chain.entries[n-1].Hash
+	}
+	entry.Hash = entry.sum()
+	chain.entries = append(chain.entries, entry)
+	if len(chain.entries) > auditChainKeepEntries {
+		chain.entries = chain.entries[len(chain.entries)-auditChainKeepEntries:]
+	}
+}
+
+func (chain *auditChain) len() int {
+	chain.Lock()
+	defer chain.Unlock()
+	return len(chain.entries)
+}
+
+func (chain *auditChain) clone() *auditChain {
+	chain.Lock()
+	defer chain.Unlock()
+	entries := make([]*auditChainEntry, len(chain.entries))
+	for i, entry := range chain.entries {
+		e := *entry
+		entries[i] = &e
+	}
+	return &auditChain{entries: entries}
+}
+
+func (chain *auditChain) verify() *auditChainReport {
+	cloned := chain.clone()
+	report := &auditChainReport{Verified: true, BrokenAt: -1, Entries: cloned.entries}
+	for i, entry := range cloned.entries {
+		if (i > 0 && entry.PrevHash != cloned.entries[i-1].Hash) || entry.Hash != entry.sum() {
+			report.Verified = false
+			report.BrokenAt = i
+			break
+		}
+	}
+	return report
+}
+
+func (chain *auditChain) Marshal() (buf []byte, crc uint32, err error) {
+	chain.Lock()
+	buf, err = json.Marshal(chain.entries)
+	chain.Unlock()
+	if err != nil {
+		return
+	}
+	crc = crc32.ChecksumIEEE(buf)
+	return
+}
+
+func (chain *auditChain) UnMarshal(data []byte) (err error) {
+	entries := make([]*auditChainEntry, 0)
+	if err = json.Unmarshal(data, &entries); err != nil {
+		return errors.NewErrorf("auditChain unmarshal: %v", err)
+	}
+	chain.Lock()
+	chain.entries = entries
+	chain.Unlock()
+	return
+}
+
+// auditSetAttr records the chown of any inode and the chmod of the root inode or the
+// directories owned by root, it is called before the attributes are set.
+func (mp *metaPartition) auditSetAttr(applyID uint64, req *SetattrRequest) {
+	item := mp.inodeTree.Get(NewInode(req.Inode, 0))
+	if item == nil {
+		return
+	}
+	ino := item.(*Inode)
+	ino.RLock()
+	uid, gid, mode := ino.Uid, ino.Gid, ino.Type
+	ino.RUnlock()
+
+	if req.Valid&(proto.AttrUid|proto.AttrGid) != 0 {
+		newUid, newGid := uid, gid
+		if req.Valid&proto.AttrUid != 0 {
+			newUid = req.Uid
+		}
+		if req.Valid&proto.AttrGid != 0 {
+			newGid = req.Gid
+		}
+		if newUid != uid || newGid != gid {
+			mp.auditChain.append(applyID, auditOpChown, req.Inode,
+				fmt.Sprintf("uid %d->%d gid %d->%d", uid, newUid, gid, newGid))
+		}
+	}
+	if req.Valid&proto.AttrMode != 0 && req.Mode != mode && proto.IsDir(mode) &&
+		(req.Inode == proto.RootIno || uid == 0) {
+		mp.auditChain.append(applyID, auditOpChmod, req.Inode, fmt.Sprintf("mode %o->%o", mode, req.Mode))
+	}
+}
+
+func (mp *metaPartition) auditQuota(applyID uint64, op string, quotaID uint32, inodes []uint64) {
+	if len(inodes) == 0 {
+		return
+	}
+	detail := fmt.Sprintf("quota %d inodes %d %v", quotaID, len(inodes), inodes)
+	if len(inodes) > auditQuotaMaxInodes {
+		detail = fmt.Sprintf("quota %d inodes %d %v...", quotaID, len(inodes), inodes[:auditQuotaMaxInodes])
+	}
+	mp.auditChain.append(applyID, op, inodes[0], detail)
+}
+
+// VerifyAuditChain returns the audit chain of the partition and whether it is not tampered.
+func (mp *metaPartition) VerifyAuditChain() *auditChainReport {
+	return mp.auditChain.verify()
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+func TestAuditChainVerify(t *testing.T) {
+	chain := newAuditChain()
+	report := chain.verify()
+	require.True(t, report.Verified)
+	require.Equal(t, -1, report.BrokenAt)
+
+	for i := uint64(1); i <= 5; i++ {
+		chain.append(i, auditOpChown, i, "uid 0->1000 gid 0->1000")
+	}
+	report = chain.verify()
+	require.True(t, report.Verified)
+	require.Len(t, report.Entries, 5)
+	require.Empty(t, report.Entries[0].PrevHash)
+	require.Equal(t, report.Entries[3].Hash, report.Entries[4].PrevHash)
+
+	data, crc, err := chain.Marshal()
+	require.NoError(t, err)
+	require.NotZero(t, crc)
+	loaded := newAuditChain()
+	require.NoError(t, loaded.UnMarshal(data))
+	require.Equal(t, report, loaded.verify())
+
+	// the entry modified breaks the chain at it
+	loaded.entries[2].Detail = "uid 0->0 gid 0->0"
+	report = loaded.verify()
+	require.False(t, report.Verified)
+	require.Equal(t, 2, report.BrokenAt)
+
+	// the entry removed breaks the chain at the next one
+	chain.entries = append(chain.entries[:1], chain.entries[2:]...)
+	report = chain.verify()
+	require.False(t, report.Verified)
+	require.Equal(t, 1, report.BrokenAt)
+}
+
+func TestAuditChainEvict(t *testing.T) {
+	chain := newAuditChain()
+	for i := uint64(1); i <= auditChainKeepEntries+10; i++ {
+		chain.append(i, auditOpSetQuota, i, "quota 1 inodes 1 [1]")
+	}
+	report := chain.verify()
+	require.True(t, report.Verified)
+	require.Len(t, report.Entries, auditChainKeepEntries)
+	require.Equal(t, uint64(11), report.Entries[0].ApplyID)
+}
+
+func TestAuditChainPartition(t *testing.T) {
+	rootDir, err := os.MkdirTemp("", "audit_chain_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(rootDir)
+	mpC := &MetaPartitionConfig{
+		PartitionId:   1,
+		VolName:       "test_vol",
+		End:           100,
+		PartitionType: 1,
+		RootDir:       rootDir,
+	}
+	metaM := &metadataManager{
+		nodeId:     1,
+		zoneName:   "test",
+		partitions: make(map[uint64]MetaPartition),
+		metaNode:   &MetaNode{},
+	}
+	mp := NewMetaPartition(mpC, metaM).(*metaPartition)
+	mp.uidManager = NewUidMgr(mpC.VolName, mpC.PartitionId)
+	mp.mqMgr = NewQuotaManager(mpC.VolName, mpC.PartitionId)
+	mp.inodeTree.ReplaceOrInsert(NewInode(proto.RootIno, proto.Mode(os.ModeDir|0o755)), true)
+	mp.inodeTree.ReplaceOrInsert(NewInode(2, proto.Mode(0o644)), true)
+
+	// chmod of the root dir and chown of the file are recorded, the chmod of the file is not
+	mp.auditSetAttr(10, &SetattrRequest{Inode: proto.RootIno, Mode: proto.Mode(os.ModeDir | 0o777), Valid: proto.AttrMode})
+	mp.auditSetAttr(11, &SetattrRequest{Inode: 2, Mode: proto.Mode(0o600), Valid: proto.AttrMode})
+	mp.auditSetAttr(12, &SetattrRequest{Inode: 2, Uid: 1000, Valid: proto.AttrUid})
+	mp.auditSetAttr(13, &SetattrRequest{Inode: 3, Uid: 1000, Valid: proto.AttrUid})
+	mp.auditQuota(14, auditOpSetQuota, 1, []uint64{proto.RootIno, 2})
+	report := mp.VerifyAuditChain()
+	require.True(t, report.Verified)
+	require.Len(t, report.Entries, 3)
+	require.Equal(t, auditOpChmod, report.Entries[0].Op)
+	require.Equal(t, auditOpChown, report.Entries[1].Op)
+	require.Equal(t, "uid 0->1000 gid 0->0", report.Entries[1].Detail)
+	require.Equal(t, auditOpSetQuota, report.Entries[2].Op)
+
+	msg := &storeMsg{
+		command:        opFSMStoreTick,
+		applyIndex:     14,
+		inodeTree:      mp.inodeTree,
+		dentryTree:     mp.dentryTree,
+		extendTree:     mp.extendTree,
+		multipartTree:  mp.multipartTree,
+		txTree:         mp.txProcessor.txManager.txTree,
+		txRbInodeTree:  mp.txProcessor.txResource.txRbInodeTree,
+		txRbDentryTree: mp.txProcessor.txResource.txRbDentryTree,
+		uniqChecker:    mp.uniqChecker,
+		auditChain:     mp.auditChain.clone(),
+	}
+	require.NoError(t, mp.store(msg))
+
+	loaded := NewMetaPartition(mpC, metaM).(*metaPartition)
+	loaded.uidManager = NewUidMgr(mpC.VolName, mpC.PartitionId)
+	loaded.mqMgr = NewQuotaManager(mpC.VolName, mpC.PartitionId)
+	require.NoError(t, loaded.LoadSnapshot(path.Join(rootDir, snapshotDir)))
+	require.Equal(t, report, loaded.VerifyAuditChain())
+}
@@ -140,6 +140,15 @@ func (b *BTree) AscendGreaterOrEqual(pivot BtreeItem, iterator func(i BtreeItem)
 	b.RUnlock()
 }
 
+// Compact rebuilds the sparse subtrees after pivot into dense nodes, visiting at most limit leaves.
+// It returns the item to resume from, nil if the whole tree is visited, and the bytes freed.
+func (b *BTree) Compact(pivot BtreeItem, limit int) (next BtreeItem, freed int) {
+	b.Lock()
+	next, freed = b.tree.Compact(pivot, limit)
+	b.Unlock()
+	return
+}
+
 // GetTree returns the snapshot of a btree.
 func (b *BTree) GetTree() *BTree {
 	b.Lock()
@@ -0,0 +1,164 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/cubefs/cubefs/util/exporter"
+	"github.com/cubefs/cubefs/util/log"
+	"golang.org/x/time/rate"
+)
+
+const (
+	MetricBTreeDefragReclaimedBytes = "btree_defrag_reclaimed_bytes"
+
+	defaultDefragIntervalSec  = 3600
+	defaultDefragLeavesPerSec = 10000
+	// leaves visited under the write lock of the tree at a time
+	defragStepLeaves = 256
+)
+
+// BTreeDefragConfig configures the background defragmentation of the inode, dentry, extend and
+// multipart trees. After heavy churn of creates and deletes the tree nodes are left half empty,
+// the defragmentation rebuilds the sparse subtrees into dense nodes and returns the memory to the OS.
+type BTreeDefragConfig struct {
+	Enable       bool `json:"enable"`
+	IntervalSec  int  `json:"intervalSec"`  // interval between the rounds, default is 3600
+	LeavesPerSec int  `json:"leavesPerSec"` // leaf nodes visited per second, default is 10000
+}
+
+func parseBTreeDefragConfig(raw interface{}) (conf BTreeDefragConfig, err error) {
+	if raw == nil {
+		return
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(data, &conf); err != nil {
+		return
+	}
+	if conf.IntervalSec < 0 || conf.LeavesPerSec < 0 {
+		return conf, fmt.Errorf("invalid btree defrag %+v, intervalSec and leavesPerSec should not be negative", conf)
+	}
+	if conf.IntervalSec == 0 {
+		conf.IntervalSec = defaultDefragIntervalSec
+	}
+	if conf.LeavesPerSec == 0 {
+		conf.LeavesPerSec = defaultDefragLeavesPerSec
+	}
+	return
+}
+
+type btreeDefrag struct {
+	conf      BTreeDefragConfig
+	limiter   *rate.Limiter
+	reclaimed *exporter.Counter
+}
+
+func newBTreeDefrag(conf BTreeDefragConfig) *btreeDefrag {
+	burst := conf.LeavesPerSec
+	if burst < defragStepLeaves {
+		burst = defragStepLeaves
+	}
+	return &btreeDefrag{
+		conf:      conf,
+		limiter:   rate.NewLimiter(rate.Limit(conf.LeavesPerSec), burst),
+		reclaimed: exporter.NewCounter(MetricBTreeDefragReclaimedBytes),
+	}
+}
+
+// defragTree compacts the tree step by step, so that the write lock is held shortly each time.
+func (d *btreeDefrag) defragTree(ctx context.Context, tree *BTree) (freed int, err error) {
+	var pivot BtreeItem
+	for {
+		if err = d.limiter.WaitN(ctx, defragStepLeaves); err != nil {
+			return
+		}
+		next, n := tree.Compact(pivot, defragStepLeaves)
+		if freed += n; next == nil {
+			return
+		}
+		pivot = next
+	}
+}
+
+func (d *btreeDefrag) defragPartition(ctx context.Context, mp *metaPartition) (freed int, err error) {
+	for _, tree := range []*BTree{mp.inodeTree, mp.dentryTree, mp.extendTree, mp.multipartTree} {
+		var n int
+		n, err = d.defragTree(ctx, tree)
+		if freed += n; err != nil {
+			return
+		}
+	}
+	if freed > 0 {
+		d.reclaimed.AddWithLabels(int64(freed), map[string]string{exporter.Vol: mp.GetVolName()})
+	}
+	return
+}
+
+// startBTreeDefrag defragments the trees of all the partitions periodically, and returns the
+// freed memory to the OS at the end of each round.
+func (m *metadataManager) startBTreeDefrag() {
+	if m.btreeDefrag == nil || !m.btreeDefrag.conf.Enable {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-m.stopC
+		cancel()
+	}()
+	go func() {
+		ticker := time.NewTicker(time.Duration(m.btreeDefrag.conf.IntervalSec) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.defragPartitions(ctx)
+			}
+		}
+	}()
+}
+
+func (m *metadataManager) defragPartitions(ctx context.Context) {
+	partitions := make([]*metaPartition, 0)
+	m.Range(true, func(_ uint64, p MetaPartition) bool {
+		if mp, ok := p.(*metaPartition); ok {
+			partitions = append(partitions, mp)
+		}
+		return true
+	})
+
+	start, total := time.Now(), 0
+	for _, mp := range partitions {
+		freed, err := m.btreeDefrag.defragPartition(ctx, mp)
+		total += freed
+		if err != nil {
+			log.LogWarnf("[defragPartitions] stop at mp(%v) err(%v)", mp.config.PartitionId, err)
+			break
+		}
+	}
+	if total > 0 {
+		debug.FreeOSMemory()
+	}
+	log.LogInfof("[defragPartitions] %v partitions, reclaimed %v bytes, cost %v", len(partitions), total, time.Since(start))
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBTreeDefragConfig(t *testing.T) {
+	conf, err := parseBTreeDefragConfig(map[string]interface{}{"enable": true})
+	require.NoError(t, err)
+	require.Equal(t, BTreeDefragConfig{
+		Enable:       true,
+		IntervalSec:  defaultDefragIntervalSec,
+		LeavesPerSec: defaultDefragLeavesPerSec,
+	}, conf)
+
+	conf, err = parseBTreeDefragConfig(map[string]interface{}{"enable": true, "intervalSec": 60, "leavesPerSec": 100})
+	require.NoError(t, err)
+	require.Equal(t, BTreeDefragConfig{Enable: true, IntervalSec: 60, LeavesPerSec: 100}, conf)
+
+	_, err = parseBTreeDefragConfig(map[string]interface{}{"leavesPerSec": -1})
+	require.Error(t, err)
+
+	conf, err = parseBTreeDefragConfig(nil)
+	require.NoError(t, err)
+	require.False(t, conf.Enable)
+}
+
+func TestBTreeDefrag(t *testing.T) {
+	tree := NewBtree()
+	for i := 0; i < 100000; i++ {
+		tree.ReplaceOrInsert(&testItem{data: i}, true)
+	}
+	for i := 0; i < 100000; i++ {
+		if i%4 != 0 {
+			tree.Delete(&testItem{data: i})
+		}
+	}
+
+	d := newBTreeDefrag(BTreeDefragConfig{Enable: true, LeavesPerSec: 1 << 20})
+	freed, err := d.defragTree(context.Background(), tree)
+	require.NoError(t, err)
+	require.Greater(t, freed, 0)
+	require.Equal(t, 25000, tree.Len())
+	expect := 0
+	tree.Ascend(func(i BtreeItem) bool {
+		require.Equal(t, expect, i.(*testItem).data)
+		expect += 4
+		return true
+	})
+
+	freed, err = d.defragTree(context.Background(), tree)
+	require.NoError(t, err)
+	require.Equal(t, 0, freed)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = d.defragTree(ctx, tree)
+	require.Error(t, err)
+}
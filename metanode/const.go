@@ -189,6 +189,11 @@ const (
 	opFSMStoreTickV1  = 72
 
 	opFSMVerListSnapShot = 73
+
+	// audit chain snapshot
+	opFSMAuditChainSnap = 75
+
+	opFSMExtentsAddIfSize = 76
 )
 
 var (
@@ -222,6 +227,9 @@ const (
 	cfgRetainLogs                = "retainLogs"                // string, raft RetainLogs
 	cfgRaftSyncSnapFormatVersion = "raftSyncSnapFormatVersion" // int, format version of snapshot that raft leader sent to follower
 	cfgServiceIDKey              = "serviceIDKey"
+	cfgVolQos                    = "volQos"      // object, see VolQosConfig
+	cfgLinkLimit                 = "linkLimit"   // object, see LinkLimitConfig
+	cfgBTreeDefrag               = "btreeDefrag" // object, see BTreeDefragConfig
 
 	metaNodeDeleteBatchCountKey = "batchCount"
 	configNameResolveInterval   = "nameResolveInterval" // int
@@ -17,6 +17,7 @@ package metanode
 import (
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/util/log"
@@ -30,6 +31,7 @@ type DataPartition struct {
 	PartitionType string
 	Hosts         []string
 	IsDiscard     bool
+	IsShared      bool // shared with the source of the cloned volume, the extents are never deleted by the clone
 }
 
 // GetAllAddrs returns all addresses of the data partition.
@@ -51,6 +53,7 @@ type Vol struct {
 	sync.RWMutex
 	dataPartitionView map[uint64]*DataPartition
 	volDeleteLockTime int64
+	caseInsensitive   int32 // 1 if lookup dentry ignoring case, but preserve the case of name
 }
 
 // NewVol returns a new volume instance.
@@ -75,6 +78,20 @@ func (v *Vol) UpdatePartitions(partitions *DataPartitionsView) {
 	}
 }
 
+// isCaseInsensitive returns whether to lookup dentry ignoring case, it's updated by the volume view
+// while looking up dentries.
+func (v *Vol) isCaseInsensitive() bool {
+	return atomic.LoadInt32(&v.caseInsensitive) == 1
+}
+
+func (v *Vol) setCaseInsensitive(caseInsensitive bool) {
+	var val int32
+	if caseInsensitive {
+		val = 1
+	}
+	atomic.StoreInt32(&v.caseInsensitive, val)
+}
+
 func (v *Vol) replaceOrInsert(partition *DataPartition) {
 	v.Lock()
 	defer v.Unlock()
@@ -0,0 +1,211 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+type dentryFoldKey struct {
+	parentID uint64
+	name     string // lower case of the dentry name
+}
+
+func newDentryFoldKey(parentID uint64, name string) dentryFoldKey {
+	return dentryFoldKey{parentID: parentID, name: strings.ToLower(name)}
+}
+
+// dentryFoldIndex indexes the names of dentries by the lower case name under the parent,
+// it's only built for volumes with case insensitive lookup mode. It's derived from the
+// dentry tree and updated in the fsm, so all replicas have the same index.
+type dentryFoldIndex struct {
+	sync.RWMutex
+	enabled bool
+	names   map[dentryFoldKey][]string // names of the dentries, usually only one
+}
+
+func (idx *dentryFoldIndex) isEnabled() bool {
+	idx.RLock()
+	defer idx.RUnlock()
+	return idx.enabled
+}
+
+// enable builds the index from the dentry tree on the first time it's enabled.
+func (idx *dentryFoldIndex) enable(tree *BTree) {
+	idx.Lock()
+	defer idx.Unlock()
+	if idx.enabled {
+		return
+	}
+	idx.enabled = true
+	idx.rebuildLocked(tree)
+}
+
+// rebuild rebuilds the index from the dentry tree, e.g. the tree is replaced by the applied snapshot.
+func (idx *dentryFoldIndex) rebuild(tree *BTree) {
+	idx.Lock()
+	defer idx.Unlock()
+	if idx.enabled {
+		idx.rebuildLocked(tree)
+	}
+}
+
+func (idx *dentryFoldIndex) rebuildLocked(tree *BTree) {
+	idx.names = make(map[dentryFoldKey][]string)
+	tree.Ascend(func(i BtreeItem) bool {
+		d := i.(*Dentry)
+		idx.addLocked(d.ParentId, d.Name)
+		return true
+	})
+}
+
+func (idx *dentryFoldIndex) add(parentID uint64, name string) {
+	idx.Lock()
+	defer idx.Unlock()
+	if idx.enabled {
+		idx.addLocked(parentID, name)
+	}
+}
+
+func (idx *dentryFoldIndex) addLocked(parentID uint64, name string) {
+	key := newDentryFoldKey(parentID, name)
+	for _, n := range idx.names[key] {
+		if n == name {
+			return
+		}
+	}
+	idx.names[key] = append(idx.names[key], name)
+}
+
+func (idx *dentryFoldIndex) remove(parentID uint64, name string) {
+	idx.Lock()
+	defer idx.Unlock()
+	if !idx.enabled {
+		return
+	}
+	key := newDentryFoldKey(parentID, name)
+	names := idx.names[key]
+	for i, n := range names {
+		if n != name {
+			continue
+		}
+		if len(names) == 1 {
+			delete(idx.names, key)
+			return
+		}
+		idx.names[key] = append(names[:i:i], names[i+1:]...)
+		return
+	}
+}
+
+// get returns the names of dentries equal to name ignoring case under the parent.
+func (idx *dentryFoldIndex) get(parentID uint64, name string) []string {
+	idx.RLock()
+	defer idx.RUnlock()
+	if !idx.enabled {
+		return nil
+	}
+	names := idx.names[newDentryFoldKey(parentID, name)]
+	return append([]string(nil), names...)
+}
+
+// setCaseInsensitive sets the case insensitive lookup mode of the volume,
+// which can only be set on creation, so it's never disabled after enabled.
+func (mp *metaPartition) setCaseInsensitive(caseInsensitive bool) {
+	// build the index before looking up ignoring case
+	if caseInsensitive && !mp.dentryFold.isEnabled() {
+		log.LogInfof("action[setCaseInsensitive] mp[%v] build dentry fold index", mp.config.PartitionId)
+		mp.dentryFold.enable(mp.dentryTree)
+	}
+	mp.vol.setCaseInsensitive(caseInsensitive)
+}
+
+// getDentryIgnoreCase returns the dentry whose name equals to name ignoring case
+// under the parent, it's used by volumes with case insensitive lookup mode.
+func (mp *metaPartition) getDentryIgnoreCase(parentID uint64, name string, verSeq uint64) (den *Dentry, status uint8) {
+	for _, n := range mp.dentryFold.get(parentID, name) {
+		item := mp.dentryTree.Get(&Dentry{ParentId: parentID, Name: n})
+		if item == nil {
+			continue
+		}
+		if d := mp.getDentryByVerSeq(item.(*Dentry), verSeq); d != nil {
+			return d, proto.OpOk
+		}
+	}
+	return nil, proto.OpNotExistErr
+}
+
+// resolveDentryName returns the name of the existing dentry equal to name ignoring case,
+// it returns name itself if the dentry of name exists or case insensitive mode is disabled.
+func (mp *metaPartition) resolveDentryName(parentID uint64, name string) string {
+	if !mp.dentryFold.isEnabled() || mp.dentryTree.Has(&Dentry{ParentId: parentID, Name: name}) {
+		return name
+	}
+	for _, n := range mp.dentryFold.get(parentID, name) {
+		if mp.dentryTree.Has(&Dentry{ParentId: parentID, Name: n}) {
+			return n
+		}
+	}
+	return name
+}
+
+// checkDentryIgnoreCase checks whether another dentry equal to the name of dentry
+// ignoring case exists, the same inode is allowed for renaming to another case.
+func (mp *metaPartition) checkDentryIgnoreCase(dentry *Dentry) uint8 {
+	for _, n := range mp.dentryFold.get(dentry.ParentId, dentry.Name) {
+		if n == dentry.Name {
+			continue
+		}
+		item := mp.dentryTree.Get(&Dentry{ParentId: dentry.ParentId, Name: n})
+		if item == nil {
+			continue
+		}
+		if d := item.(*Dentry); !d.isDeleted() && d.Inode != dentry.Inode {
+			log.LogWarnf("action[checkDentryIgnoreCase] mp[%v] dentry %v exists ignoring case of [%v]",
+				mp.config.PartitionId, d, dentry)
+			return proto.OpExistErr
+		}
+	}
+	return proto.OpOk
+}
+
+// removeDentryFold removes the name from the fold index if the dentry is removed from the tree.
+func (mp *metaPartition) removeDentryFold(parentID uint64, name string) {
+	if mp.dentryFold.isEnabled() && !mp.dentryTree.Has(&Dentry{ParentId: parentID, Name: name}) {
+		mp.dentryFold.remove(parentID, name)
+	}
+}
+
+// getTxDentryInfoIgnoreCase returns the dentry info of the transaction whose name
+// equals to name ignoring case, the dentry name is resolved by the leader then.
+func (mp *metaPartition) getTxDentryInfoIgnoreCase(txInfo *proto.TransactionInfo, parentID uint64, name string) (*proto.TxDentryInfo, bool) {
+	txDI := proto.NewTxDentryInfo("", parentID, name, 0)
+	if txDenInfo, ok := txInfo.TxDentryInfos[txDI.GetKey()]; ok {
+		return txDenInfo, true
+	}
+	if !mp.dentryFold.isEnabled() {
+		return nil, false
+	}
+	for _, txDenInfo := range txInfo.TxDentryInfos {
+		if txDenInfo.ParentId == parentID && strings.EqualFold(txDenInfo.Name, name) {
+			return txDenInfo, true
+		}
+	}
+	return nil, false
+}
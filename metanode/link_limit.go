@@ -0,0 +1,129 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/exporter"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	MetricInodeLinksRejected      = "inode_links_rejected"
+	MetricInodeLinksHighWatermark = "inode_links_high_watermark"
+
+	defaultLinkWarnRatio = 0.8
+)
+
+var (
+	ErrTooManyLinks     = errors.New("too many links of inode")
+	ErrCrossVolumeLinks = errors.New("link inode of another volume")
+)
+
+// LinkLimitConfig limits the link count of inodes on the meta node, 0 means unlimited.
+// The inodes crossing the high watermark of WarnRatio*MaxLinks are counted and alarmed,
+// so that the applications creating millions of hard links are found before the cap.
+type LinkLimitConfig struct {
+	MaxLinks  uint32  `json:"maxLinks"`
+	WarnRatio float64 `json:"warnRatio"` // default is 0.8
+}
+
+func parseLinkLimitConfig(raw interface{}) (conf LinkLimitConfig, err error) {
+	if raw == nil {
+		return
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(data, &conf); err != nil {
+		return
+	}
+	if conf.WarnRatio < 0 || conf.WarnRatio > 1 {
+		return conf, fmt.Errorf("invalid link limit %+v, warnRatio should be in [0, 1]", conf)
+	}
+	if conf.WarnRatio == 0 {
+		conf.WarnRatio = defaultLinkWarnRatio
+	}
+	return
+}
+
+func (c LinkLimitConfig) highWatermark() uint32 {
+	return uint32(float64(c.MaxLinks) * c.WarnRatio)
+}
+
+type linkLimit struct {
+	conf          LinkLimitConfig
+	rejected      *exporter.Counter
+	highWatermark *exporter.Counter
+}
+
+func newLinkLimit(conf LinkLimitConfig) *linkLimit {
+	return &linkLimit{
+		conf:          conf,
+		rejected:      exporter.NewCounter(MetricInodeLinksRejected),
+		highWatermark: exporter.NewCounter(MetricInodeLinksHighWatermark),
+	}
+}
+
+// check denies the link of inode reaching the max link count. The links of rename are not
+// limited, the old dentry of them is removed right after the new one is created.
+func (l *linkLimit) check(vol string, ino *Inode, isRename bool) error {
+	if l == nil || l.conf.MaxLinks == 0 || isRename {
+		return nil
+	}
+	if ino.GetNLink() >= l.conf.MaxLinks {
+		l.rejected.AddWithLabels(1, map[string]string{exporter.Vol: vol})
+		return ErrTooManyLinks
+	}
+	return nil
+}
+
+// observe alarms the inode whose link count crosses the high watermark
+func (l *linkLimit) observe(vol string, ino uint64, nlink uint32) {
+	if l == nil || l.conf.MaxLinks == 0 || nlink != l.conf.highWatermark() {
+		return
+	}
+	l.highWatermark.AddWithLabels(1, map[string]string{exporter.Vol: vol})
+	msg := fmt.Sprintf("vol(%v) ino(%v) link count %v reaches high watermark, max %v", vol, ino, nlink, l.conf.MaxLinks)
+	log.LogWarn(msg)
+	exporter.Warning(msg)
+}
+
+func (mp *metaPartition) getLinkLimit() *linkLimit {
+	if mp.manager == nil {
+		return nil
+	}
+	return mp.manager.linkLimit
+}
+
+// checkLinkInode denies the link of inode from another volume, and the link of inode reaching
+// the max link count. The missing inode is left to the fsm.
+func (mp *metaPartition) checkLinkInode(vol string, ino *Inode, isRename bool) (status uint8, err error) {
+	if vol != "" && vol != mp.GetVolName() {
+		return proto.OpCrossVolumeErr, ErrCrossVolumeLinks
+	}
+	if ino == nil {
+		return proto.OpOk, nil
+	}
+	if err = mp.getLinkLimit().check(mp.GetVolName(), ino, isRename); err != nil {
+		return proto.OpTooManyLinksErr, err
+	}
+	return proto.OpOk, nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+func TestParseLinkLimitConfig(t *testing.T) {
+	conf, err := parseLinkLimitConfig(map[string]interface{}{"maxLinks": 1000})
+	require.NoError(t, err)
+	require.Equal(t, LinkLimitConfig{MaxLinks: 1000, WarnRatio: defaultLinkWarnRatio}, conf)
+	require.Equal(t, uint32(800), conf.highWatermark())
+
+	conf, err = parseLinkLimitConfig(map[string]interface{}{"maxLinks": 1000, "warnRatio": 0.5})
+	require.NoError(t, err)
+	require.Equal(t, uint32(500), conf.highWatermark())
+
+	_, err = parseLinkLimitConfig(map[string]interface{}{"maxLinks": 1000, "warnRatio": 1.5})
+	require.Error(t, err)
+
+	conf, err = parseLinkLimitConfig(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), conf.MaxLinks)
+}
+
+func TestLinkLimit(t *testing.T) {
+	ino := NewInode(100, proto.Mode(0o644))
+	ino.NLink = 3
+
+	var nilLimit *linkLimit
+	require.NoError(t, nilLimit.check("vol", ino, false))
+	nilLimit.observe("vol", ino.Inode, 3)
+
+	require.NoError(t, newLinkLimit(LinkLimitConfig{}).check("vol", ino, false))
+
+	l := newLinkLimit(LinkLimitConfig{MaxLinks: 3, WarnRatio: defaultLinkWarnRatio})
+	require.ErrorIs(t, l.check("vol", ino, false), ErrTooManyLinks)
+	require.NoError(t, l.check("vol", ino, true))
+	ino.NLink = 2
+	require.NoError(t, l.check("vol", ino, false))
+	l.observe("vol", ino.Inode, 2)
+}
+
+func TestCheckLinkInode(t *testing.T) {
+	mp := &metaPartition{config: &MetaPartitionConfig{VolName: "vol"}}
+	ino := NewInode(100, proto.Mode(0o644))
+
+	status, err := mp.checkLinkInode("vol2", ino, false)
+	require.Equal(t, proto.OpCrossVolumeErr, status)
+	require.ErrorIs(t, err, ErrCrossVolumeLinks)
+
+	status, err = mp.checkLinkInode("vol", nil, false)
+	require.Equal(t, proto.OpOk, status)
+	require.NoError(t, err)
+
+	mp.manager = &metadataManager{linkLimit: newLinkLimit(LinkLimitConfig{MaxLinks: 1})}
+	status, err = mp.checkLinkInode("", ino, false)
+	require.Equal(t, proto.OpTooManyLinksErr, status)
+	require.ErrorIs(t, err, ErrTooManyLinks)
+	status, _ = mp.checkLinkInode("vol", ino, true)
+	require.Equal(t, proto.OpOk, status)
+}
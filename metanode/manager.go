@@ -61,10 +61,13 @@ type MetadataManager interface {
 
 // MetadataManagerConfig defines the configures in the metadata manager.
 type MetadataManagerConfig struct {
-	NodeID    uint64
-	RootDir   string
-	ZoneName  string
-	RaftStore raftstore.RaftStore
+	NodeID      uint64
+	RootDir     string
+	ZoneName    string
+	RaftStore   raftstore.RaftStore
+	VolQos      VolQosConfig
+	LinkLimit   LinkLimitConfig
+	BTreeDefrag BTreeDefragConfig
 }
 
 type verOp2Phase struct {
@@ -93,6 +96,10 @@ type metadataManager struct {
 	stopC                chan struct{}
 	volUpdating          *sync.Map // map[string]*verOp2Phase
 	verUpdateChan        chan string
+	volQos               *volQos
+	linkLimit            *linkLimit
+	btreeDefrag          *btreeDefrag
+	xattrInherit         *xattrInheritRouter
 }
 
 func (m *metadataManager) GetAllVolumes() (volumes *util.Set) {
@@ -202,6 +209,16 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remo
 		}
 	}()
 
+	if vol := labels[exporter.Vol]; vol != "" {
+		var release func()
+		if release, err = m.volQos.acquire(vol); err != nil {
+			p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+			m.respondToClient(conn, p)
+			return
+		}
+		defer release()
+	}
+
 	switch p.Opcode {
 	case proto.OpMetaCreateInode:
 		err = m.opCreateInode(conn, p, remoteAddr)
@@ -284,6 +301,8 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remo
 		err = m.opMetaSetXAttr(conn, p, remoteAddr)
 	case proto.OpMetaBatchSetXAttr:
 		err = m.opMetaBatchSetXAttr(conn, p, remoteAddr)
+	case proto.OpMetaInheritXAttr:
+		err = m.opMetaInheritXAttr(conn, p, remoteAddr)
 	case proto.OpMetaGetXAttr:
 		err = m.opMetaGetXAttr(conn, p, remoteAddr)
 	case proto.OpMetaGetAllXAttr:
@@ -447,6 +466,7 @@ func (m *metadataManager) onStart() (err error) {
 	m.startCpuSample()
 	m.startSnapshotVersionPromote()
 	m.startUpdateVolumes()
+	m.startBTreeDefrag()
 	return
 }
 
@@ -771,6 +791,10 @@ func NewMetadataManager(conf MetadataManagerConfig, metaNode *MetaNode) Metadata
 		metaNode:             metaNode,
 		maxQuotaGoroutineNum: defaultMaxQuotaGoroutine,
 		volUpdating:          new(sync.Map),
+		volQos:               newVolQos(conf.VolQos),
+		linkLimit:            newLinkLimit(conf.LinkLimit),
+		btreeDefrag:          newBTreeDefrag(conf.BTreeDefrag),
+		xattrInherit:         newXAttrInheritRouter(),
 	}
 }
 
@@ -47,6 +47,7 @@ func (m *metadataManager) IsForbiddenOp(mp MetaPartition, reqOp uint8) bool {
 		proto.OpMetaUpdateXAttr,
 		proto.OpMetaSetXAttr,
 		proto.OpMetaBatchSetXAttr,
+		proto.OpMetaInheritXAttr,
 		proto.OpMetaRemoveXAttr,
 		// extent
 		proto.OpMetaTruncate,
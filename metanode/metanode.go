@@ -73,6 +73,9 @@ type MetaNode struct {
 	clusterUuid               string
 	clusterUuidEnable         bool
 	serviceIDKey              string
+	volQosConfig              VolQosConfig
+	linkLimitConfig           LinkLimitConfig
+	btreeDefragConfig         BTreeDefragConfig
 
 	control common.Control
 }
@@ -303,6 +306,21 @@ func (m *MetaNode) parseConfig(cfg *config.Config) (err error) {
 	log.LogInfof("[parseConfig] load raftReplicatePort[%v].", m.raftReplicatePort)
 	log.LogInfof("[parseConfig] load zoneName[%v].", m.zoneName)
 
+	if m.volQosConfig, err = parseVolQosConfig(cfg.GetValue(cfgVolQos)); err != nil {
+		return fmt.Errorf("parse %v fail err %v", cfgVolQos, err)
+	}
+	log.LogInfof("[parseConfig] load volQos[%+v].", m.volQosConfig)
+
+	if m.linkLimitConfig, err = parseLinkLimitConfig(cfg.GetValue(cfgLinkLimit)); err != nil {
+		return fmt.Errorf("parse %v fail err %v", cfgLinkLimit, err)
+	}
+	log.LogInfof("[parseConfig] load linkLimit[%+v].", m.linkLimitConfig)
+
+	if m.btreeDefragConfig, err = parseBTreeDefragConfig(cfg.GetValue(cfgBTreeDefrag)); err != nil {
+		return fmt.Errorf("parse %v fail err %v", cfgBTreeDefrag, err)
+	}
+	log.LogInfof("[parseConfig] load btreeDefrag[%+v].", m.btreeDefragConfig)
+
 	if err = m.parseSmuxConfig(cfg); err != nil {
 		return fmt.Errorf("parseSmuxConfig fail err %v", err)
 	} else {
@@ -419,10 +437,13 @@ func (m *MetaNode) newMetaManager() (err error) {
 
 	// load metadataManager
 	conf := MetadataManagerConfig{
-		NodeID:    m.nodeId,
-		RootDir:   m.metadataDir,
-		RaftStore: m.raftStore,
-		ZoneName:  m.zoneName,
+		NodeID:      m.nodeId,
+		RootDir:     m.metadataDir,
+		RaftStore:   m.raftStore,
+		ZoneName:    m.zoneName,
+		VolQos:      m.volQosConfig,
+		LinkLimit:   m.linkLimitConfig,
+		BTreeDefrag: m.btreeDefragConfig,
 	}
 	m.metadataManager = NewMetadataManager(conf, m)
 	return
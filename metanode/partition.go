@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"math/rand"
 	"os"
@@ -151,6 +152,7 @@ type OpInode interface {
 type OpExtend interface {
 	SetXAttr(req *proto.SetXAttrRequest, p *Packet) (err error)
 	BatchSetXAttr(req *proto.BatchSetXAttrRequest, p *Packet) (err error)
+	InheritXAttr(req *proto.InheritXAttrRequest, p *Packet) (err error)
 	GetXAttr(req *proto.GetXAttrRequest, p *Packet) (err error)
 	GetAllXAttr(req *proto.GetAllXAttrRequest, p *Packet) (err error)
 	BatchGetXAttr(req *proto.BatchGetXAttrRequest, p *Packet) (err error)
@@ -279,6 +281,11 @@ type MetaPartition interface {
 	IsEnableAuditLog() bool
 	SetEnableAuditLog(status bool)
 	UpdateVolumeView(dataView *proto.DataPartitionsView, volumeView *proto.SimpleVolView)
+	Freeze(timeout time.Duration) (applyID uint64, err error)
+	Thaw() bool
+	IsFrozen() bool
+	ExportMeta(w io.Writer, verSeq uint64) error
+	VerifyAuditChain() *auditChainReport
 }
 
 type UidManager struct {
@@ -485,6 +492,7 @@ type metaPartition struct {
 	applyID                 uint64                // Inode/Dentry max applyID, this index will be update after restoring from the dumped data.
 	storedApplyId           uint64                // update after store snapshot to disk
 	dentryTree              *BTree                // btree for dentries
+	dentryFold              dentryFoldIndex       // dentry names ignoring case, only for case insensitive volumes
 	inodeTree               *BTree                // btree for inodes
 	extendTree              *BTree                // btree for inode extend (XAttr) management
 	multipartTree           *BTree                // collection for multipart management
@@ -501,6 +509,8 @@ type metaPartition struct {
 	manager                 *metadataManager
 	isLoadingMetaPartition  bool
 	ebsClient               *blobstore.BlobStoreClient
+	ebsClientLock           sync.Mutex
+	ebsConfig               *access.Config // creates the ebs client of hot volume on the first use
 	volType                 int
 	isFollowerRead          bool
 	uidManager              *UidManager
@@ -509,11 +519,35 @@ type metaPartition struct {
 	mqMgr                   *MetaQuotaManager
 	nonIdempotent           sync.Mutex
 	uniqChecker             *uniqChecker
+	auditChain              *auditChain
 	verSeq                  uint64
 	multiVersionList        *proto.VolVersionInfoList
 	verUpdateChan           chan []byte
 	enableAuditLog          bool
 	recycleInodeDelFileFlag atomicutil.Flag
+	freezer                 partitionFreezer // quiesces mutations for consistent backup
+}
+
+// getEbsClient returns the ebs client, which is created on the first use for hot volume, whose inodes
+// may also have obj extents of the objects of cold storage class written to ebs by object node.
+func (mp *metaPartition) getEbsClient() (ebsClient *blobstore.BlobStoreClient, err error) {
+	mp.ebsClientLock.Lock()
+	defer mp.ebsClientLock.Unlock()
+	if mp.ebsClient != nil {
+		return mp.ebsClient, nil
+	}
+	if mp.ebsConfig == nil {
+		return nil, errors.NewErrorf("mp(%v) ebs is not configured in cluster", mp.config.PartitionId)
+	}
+	if ebsClient, err = blobstore.NewEbsClient(*mp.ebsConfig); err != nil {
+		return
+	}
+	if ebsClient == nil {
+		return nil, errors.NewErrorf("mp(%v) ebsClient is nil", mp.config.PartitionId)
+	}
+	log.LogInfof("action[getEbsClient] mp(%v) vol(%v) create ebs client", mp.config.PartitionId, mp.config.VolName)
+	mp.ebsClient = ebsClient
+	return
 }
 
 func (mp *metaPartition) IsForbidden() bool {
@@ -721,22 +755,24 @@ func (mp *metaPartition) onStart(isCreate bool) (err error) {
 	}
 
 	mp.vol.volDeleteLockTime = volumeInfo.DeleteLockTime
+	mp.setCaseInsensitive(volumeInfo.CaseInsensitive)
 
 	go mp.runVersionOp()
 
 	mp.volType = volumeInfo.VolType
 	var ebsClient *blobstore.BlobStoreClient
-	if clusterInfo.EbsAddr != "" && proto.IsCold(mp.volType) {
-		ebsClient, err = blobstore.NewEbsClient(
-			access.Config{
-				ConnMode: access.NoLimitConnMode,
-				Consul: access.ConsulConfig{
-					Address: clusterInfo.EbsAddr,
-				},
-				MaxSizePutOnce: int64(volumeInfo.ObjBlockSize),
-				Logger:         &access.Logger{Filename: path.Join(log.LogDir, "ebs.log")},
+	if clusterInfo.EbsAddr != "" {
+		mp.ebsConfig = &access.Config{
+			ConnMode: access.NoLimitConnMode,
+			Consul: access.ConsulConfig{
+				Address: clusterInfo.EbsAddr,
 			},
-		)
+			MaxSizePutOnce: int64(volumeInfo.ObjBlockSize),
+			Logger:         &access.Logger{Filename: path.Join(log.LogDir, "ebs.log")},
+		}
+	}
+	if clusterInfo.EbsAddr != "" && proto.IsCold(mp.volType) {
+		ebsClient, err = blobstore.NewEbsClient(*mp.ebsConfig)
 
 		if err != nil {
 			log.LogErrorf("action[onStart] err[%v]", err)
@@ -874,6 +910,7 @@ func NewMetaPartition(conf *MetaPartitionConfig, manager *metadataManager) MetaP
 		vol:           NewVol(),
 		manager:       manager,
 		uniqChecker:   newUniqChecker(),
+		auditChain:    newAuditChain(),
 		verSeq:        conf.VerSeq,
 		multiVersionList: &proto.VolVersionInfoList{
 			TemporaryVerMap: make(map[uint64]*proto.VolVersionInfo),
@@ -1002,6 +1039,7 @@ const (
 	CRC_COUNT_TX_STUFF   int = 7
 	CRC_COUNT_UINQ_STUFF int = 8
 	CRC_COUNT_MULTI_VER  int = 9
+	CRC_COUNT_AUDIT      int = 10
 )
 
 func (mp *metaPartition) LoadSnapshot(snapshotPath string) (err error) {
@@ -1018,7 +1056,7 @@ func (mp *metaPartition) LoadSnapshot(snapshotPath string) (err error) {
 	}
 
 	crc_count := len(crcs)
-	if crc_count != CRC_COUNT_BASIC && crc_count != CRC_COUNT_TX_STUFF && crc_count != CRC_COUNT_UINQ_STUFF && crc_count != CRC_COUNT_MULTI_VER {
+	if crc_count != CRC_COUNT_BASIC && crc_count != CRC_COUNT_TX_STUFF && crc_count != CRC_COUNT_UINQ_STUFF && crc_count != CRC_COUNT_MULTI_VER && crc_count != CRC_COUNT_AUDIT {
 		log.LogErrorf("action[LoadSnapshot] crc array length %d not match", len(crcs))
 		return ErrSnapshotCrcMismatch
 	}
@@ -1037,7 +1075,7 @@ func (mp *metaPartition) LoadSnapshot(snapshotPath string) (err error) {
 		loadFuncs = append(loadFuncs, mp.loadUniqChecker)
 	}
 
-	if crc_count == CRC_COUNT_MULTI_VER {
+	if crc_count >= CRC_COUNT_MULTI_VER {
 		if err = mp.loadMultiVer(snapshotPath, crcs[CRC_COUNT_MULTI_VER-1]); err != nil {
 			return
 		}
@@ -1045,6 +1083,12 @@ func (mp *metaPartition) LoadSnapshot(snapshotPath string) (err error) {
 		mp.storeMultiVersion(snapshotPath, &storeMsg{multiVerList: mp.multiVersionList.VerList})
 	}
 
+	if crc_count >= CRC_COUNT_AUDIT {
+		if err = mp.loadAuditChain(snapshotPath, crcs[CRC_COUNT_AUDIT-1]); err != nil {
+			return
+		}
+	}
+
 	errs := make([]error, len(loadFuncs))
 	var wg sync.WaitGroup
 	wg.Add(len(loadFuncs))
@@ -1155,6 +1199,7 @@ func (mp *metaPartition) store(sm *storeMsg) (err error) {
 		mp.storeTxRbDentry,
 		mp.storeUniqChecker,
 		mp.storeMultiVersion,
+		mp.storeAuditChain,
 	}
 	for _, storeFunc := range storeFuncs {
 		var crc uint32
@@ -1699,6 +1744,7 @@ func (mp *metaPartition) storeSnapshotFiles() (err error) {
 		txRbDentryTree: NewBtree(),
 		uniqId:         mp.GetUniqId(),
 		uniqChecker:    newUniqChecker(),
+		auditChain:     newAuditChain(),
 		multiVerList:   mp.multiVersionList.VerList,
 	}
 
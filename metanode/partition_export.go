@@ -0,0 +1,113 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"io"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+func exportInode(ino *Inode) *proto.MetaExportInode {
+	ino.RLock()
+	defer ino.RUnlock()
+	record := &proto.MetaExportInode{
+		Inode:      ino.Inode,
+		Type:       ino.Type,
+		Uid:        ino.Uid,
+		Gid:        ino.Gid,
+		Size:       ino.Size,
+		Generation: ino.Generation,
+		CreateTime: ino.CreateTime,
+		AccessTime: ino.AccessTime,
+		ModifyTime: ino.ModifyTime,
+		LinkTarget: ino.LinkTarget,
+		NLink:      ino.NLink,
+	}
+	if ino.Extents != nil {
+		record.Extents = ino.Extents.CopyExtents()
+	}
+	if ino.ObjExtents != nil {
+		record.ObjExtents = ino.ObjExtents.CopyExtents()
+	}
+	return record
+}
+
+// ExportMeta writes the namespace of the partition at the snapshot version verSeq in the export
+// format, or the current version if verSeq is 0, the deleted inodes and dentries are not exported.
+// The xattrs are always of the current version. The trees are cloned one after another, freeze
+// the partition to export a consistent view of the current version under writes.
+func (mp *metaPartition) ExportMeta(w io.Writer, verSeq uint64) (err error) {
+	inodeTree := mp.inodeTree.GetTree()
+	dentryTree := mp.dentryTree.GetTree()
+	extendTree := mp.extendTree.GetTree()
+
+	writer, err := proto.NewMetaExportWriter(w, &proto.MetaExportHeader{
+		VolName:     mp.config.VolName,
+		PartitionID: mp.config.PartitionId,
+		Start:       mp.config.Start,
+		End:         mp.config.End,
+		ExportTime:  time.Now().Unix(),
+	})
+	if err != nil {
+		return
+	}
+
+	inodeTree.Ascend(func(item BtreeItem) bool {
+		ino, _ := item.(*Inode).getInoByVer(verSeq, false)
+		if ino == nil || ino.ShouldDelete() {
+			return true
+		}
+		err = writer.WriteInode(exportInode(ino))
+		return err == nil
+	})
+	if err != nil {
+		return
+	}
+	dentryTree.Ascend(func(item BtreeItem) bool {
+		den, _ := item.(*Dentry).getDentryFromVerList(verSeq, false)
+		if den == nil || den.isDeleted() {
+			return true
+		}
+		err = writer.WriteDentry(&proto.MetaExportDentry{
+			ParentId: den.ParentId,
+			Name:     den.Name,
+			Inode:    den.Inode,
+			Type:     den.Type,
+		})
+		return err == nil
+	})
+	if err != nil {
+		return
+	}
+	extendTree.Ascend(func(item BtreeItem) bool {
+		extend := item.(*Extend)
+		attrs := make(map[string][]byte)
+		extend.Range(func(key, value []byte) bool {
+			attrs[string(key)] = value
+			return true
+		})
+		if len(attrs) == 0 {
+			return true
+		}
+		err = writer.WriteXAttr(&proto.MetaExportXAttr{Inode: extend.inode, Attrs: attrs})
+		return err == nil
+	})
+	if err != nil {
+		return
+	}
+	return writer.Close()
+}
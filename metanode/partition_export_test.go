@@ -0,0 +1,105 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+func testExportMeta(t *testing.T, verSeq uint64) (inodes map[uint64]*proto.MetaExportInode,
+	dentries []*proto.MetaExportDentry, xattrs []*proto.MetaExportXAttr,
+) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, mp.ExportMeta(buf, verSeq))
+
+	inodes = make(map[uint64]*proto.MetaExportInode)
+	reader := proto.NewMetaExportReader(buf)
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return
+		}
+		require.NoError(t, err)
+		switch record.Type {
+		case proto.MetaExportRecordHeader:
+			require.Equal(t, mp.config.PartitionId, record.Header.PartitionID)
+		case proto.MetaExportRecordInode:
+			inodes[record.Inode.Inode] = record.Inode
+		case proto.MetaExportRecordDentry:
+			dentries = append(dentries, record.Dentry)
+		case proto.MetaExportRecordXAttr:
+			xattrs = append(xattrs, record.XAttr)
+		}
+	}
+}
+
+func TestMetaPartition_ExportMeta(t *testing.T) {
+	initMp(t)
+	dir := testCreateInode(t, DirModeType)
+	file := testCreateInode(t, FileModeType)
+	file.Extents = NewSortedExtentsFromEks([]proto.ExtentKey{{FileOffset: 0, PartitionId: 1, ExtentId: 2, Size: 100}})
+	file.Size = 100
+	deleted := testCreateInode(t, FileModeType)
+	deleted.SetDeleteMark()
+	testCreateDentry(t, 1, dir.Inode, "dir", DirModeType)
+	testCreateDentry(t, dir.Inode, file.Inode, "file", FileModeType)
+	extend := NewExtend(file.Inode)
+	extend.Put([]byte("user.k"), []byte("v"), 0)
+	require.NoError(t, mp.fsmSetXAttr(extend))
+
+	inodes, dentries, xattrs := testExportMeta(t, 0)
+	require.Contains(t, inodes, dir.Inode)
+	require.NotContains(t, inodes, deleted.Inode)
+	require.Equal(t, uint64(100), inodes[file.Inode].Size)
+	require.Equal(t, file.Extents.CopyExtents(), inodes[file.Inode].Extents)
+	require.Len(t, dentries, 2)
+	require.Equal(t, "file", dentries[1].Name)
+	require.Equal(t, file.Inode, dentries[1].Inode)
+	require.Len(t, xattrs, 1)
+	require.Equal(t, []byte("v"), xattrs[0].Attrs["user.k"])
+}
+
+func TestMetaPartition_ExportMetaOfVersion(t *testing.T) {
+	initMp(t)
+	initVer()
+	file := testCreateInode(t, FileModeType)
+	testCreateDentry(t, 1, file.Inode, "file", FileModeType)
+	testAppendExt(t, 0, 0, file.Inode)
+	snapshot := testCreateVer()
+	testCreateVer()
+
+	// the file is truncated and a new file is created after the snapshot
+	mp.fsmExtentsTruncate(&Inode{Inode: file.Inode, Size: 500, ModifyTime: time.Now().Unix()})
+	newFile := testCreateInode(t, FileModeType)
+	testCreateDentry(t, 1, newFile.Inode, "newFile", FileModeType)
+
+	inodes, dentries, _ := testExportMeta(t, snapshot)
+	require.Equal(t, uint64(1000), inodes[file.Inode].Size)
+	require.NotContains(t, inodes, newFile.Inode)
+	require.Len(t, dentries, 1)
+	require.Equal(t, "file", dentries[0].Name)
+
+	inodes, dentries, _ = testExportMeta(t, 0)
+	require.Equal(t, uint64(500), inodes[file.Inode].Size)
+	require.Contains(t, inodes, newFile.Inode)
+	require.Len(t, dentries, 2)
+}
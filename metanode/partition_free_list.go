@@ -81,12 +81,14 @@ func (mp *metaPartition) UpdateVolumeView(dataView *proto.DataPartitionsView, vo
 				Hosts:       view.DataPartitions[i].Hosts,
 				ReplicaNum:  view.DataPartitions[i].ReplicaNum,
 				IsDiscard:   view.DataPartitions[i].IsDiscard,
+				IsShared:    view.DataPartitions[i].IsShared,
 			}
 		}
 		return newView
 	}
 	mp.vol.UpdatePartitions(convert(dataView))
 	mp.vol.volDeleteLockTime = volumeView.DeleteLockTime
+	mp.setCaseInsensitive(volumeView.CaseInsensitive)
 }
 
 func (mp *metaPartition) updateVolView(convert func(view *proto.DataPartitionsView) *DataPartitionsView) (err error) {
@@ -107,6 +109,7 @@ func (mp *metaPartition) updateVolView(convert func(view *proto.DataPartitionsVi
 		return
 	}
 	mp.vol.volDeleteLockTime = volView.DeleteLockTime
+	mp.setCaseInsensitive(volView.CaseInsensitive)
 	return nil
 }
 
@@ -128,6 +131,7 @@ func (mp *metaPartition) updateVolWorker() {
 				Hosts:       view.DataPartitions[i].Hosts,
 				ReplicaNum:  view.DataPartitions[i].ReplicaNum,
 				IsDiscard:   view.DataPartitions[i].IsDiscard,
+				IsShared:    view.DataPartitions[i].IsShared,
 			}
 		}
 		return newView
@@ -346,6 +350,15 @@ func (mp *metaPartition) deleteMarkedInodes(inoSlice []uint64) {
 			mp.freeList.Push(inode.Inode)
 		}
 		allInodes = shouldCommit
+	} else if objInodes, otherInodes := splitInodesWithObjExtents(allInodes); len(objInodes) > 0 {
+		// only the inodes of cold storage class objects of hot volume wait for ebs
+		shouldCommit, shouldRePushToFreeList = mp.doBatchDeleteObjExtentsInEBS(objInodes)
+		log.LogInfof("[deleteMarkedInodes] metaPartition(%v) deleteObjInodeCnt(%d) shouldRePush(%d)",
+			mp.config.PartitionId, len(shouldCommit), len(shouldRePushToFreeList))
+		for _, inode := range shouldRePushToFreeList {
+			mp.freeList.Push(inode.Inode)
+		}
+		allInodes = append(otherInodes, shouldCommit...)
 	}
 	log.LogInfof("[deleteMarkedInodes] metaPartition(%v) deleteExtentsByPartition(%v) allInodes(%v)",
 		mp.config.PartitionId, deleteExtentsByPartition, allInodes)
@@ -433,6 +446,10 @@ func (mp *metaPartition) doDeleteMarkedInodes(ext *proto.ExtentKey) (err error)
 			ext.PartitionId)
 		return
 	}
+	if dp.IsShared {
+		log.LogInfof("[doDeleteMarkedInodes] dp(%v) is shared with the clone source, skip ext(%s)", dp.PartitionID, ext.String())
+		return
+	}
 	log.LogDebugf("action[doDeleteMarkedInodes] dp(%v) status (%v)", dp.PartitionID, dp.Status)
 
 	// delete the data node
@@ -509,6 +526,12 @@ func (mp *metaPartition) doBatchDeleteExtentsByPartition(partitionID uint64, ext
 			partitionID)
 		return
 	}
+	// the extents are still referred to by the snapshot of the clone source
+	if dp.IsShared {
+		log.LogInfof("[doBatchDeleteExtentsByPartition] vol(%v) mp(%v) dp(%d) is shared with the clone source, skip extents count(%v)",
+			mp.config.VolName, mp.config.PartitionId, partitionID, len(exts))
+		return
+	}
 
 	for _, ext := range exts {
 		if ext.PartitionId != partitionID {
@@ -610,12 +633,36 @@ func (mp *metaPartition) doBatchDeleteObjExtentsInEBS(allInodes []*Inode) (shoul
 	return
 }
 
+// splitInodesWithObjExtents splits the inodes with obj extents from the others.
+func splitInodesWithObjExtents(inodes []*Inode) (objInodes, otherInodes []*Inode) {
+	otherInodes = make([]*Inode, 0, len(inodes))
+	for _, inode := range inodes {
+		inode.RLock()
+		hasObjExtents := inode.ObjExtents != nil && inode.ObjExtents.Len() > 0
+		inode.RUnlock()
+		if hasObjExtents {
+			objInodes = append(objInodes, inode)
+		} else {
+			otherInodes = append(otherInodes, inode)
+		}
+	}
+	return
+}
+
 func (mp *metaPartition) deleteObjExtents(oeks []proto.ObjExtentKey) (err error) {
 	total := len(oeks)
+	if total == 0 {
+		return
+	}
+	ebsClient, err := mp.getEbsClient()
+	if err != nil {
+		log.LogErrorf("[deleteObjExtents] vol(%v) mp(%v) get ebs client fail, err(%s)", mp.config.VolName, mp.config.PartitionId, err.Error())
+		return
+	}
 
 	for i := 0; i < total; i += maxDelCntOnce {
 		max := util.Min(i+maxDelCntOnce, total)
-		err = mp.ebsClient.Delete(oeks[i:max])
+		err = ebsClient.Delete(oeks[i:max])
 		if err != nil {
 			log.LogErrorf("[deleteObjExtents] vol(%v) mp(%v) delete ebs eks fail, cnt(%d), err(%s)", mp.config.VolName, mp.config.PartitionId, max-i, err.Error())
 			return err
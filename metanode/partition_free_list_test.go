@@ -99,3 +99,25 @@ func TestPersistInodesFreeList(t *testing.T) {
 	}
 	require.Greater(t, cnt, 1)
 }
+
+func TestSplitInodesWithObjExtents(t *testing.T) {
+	hot := NewInode(1, 0)
+	cold := NewInode(2, 0)
+	require.NoError(t, cold.ObjExtents.Append(proto.ObjExtentKey{FileOffset: 0, Size: 100}))
+	empty := NewInode(3, 0)
+	empty.ObjExtents = nil
+
+	objInodes, otherInodes := splitInodesWithObjExtents([]*Inode{hot, cold, empty})
+	require.Equal(t, []*Inode{cold}, objInodes)
+	require.Equal(t, []*Inode{hot, empty}, otherInodes)
+
+	// the hot volume without ebs configured fails to delete obj extents only
+	mp := newPartitionForFreeList(&MetaPartitionConfig{PartitionId: 10003, VolName: VolNameForTest}, nil)
+	_, err := mp.getEbsClient()
+	require.Error(t, err)
+	require.NoError(t, mp.deleteObjExtents(nil))
+	require.Error(t, mp.deleteObjExtents(cold.ObjExtents.CopyExtents()))
+	shouldCommit, shouldRePush := mp.doBatchDeleteObjExtentsInEBS(objInodes)
+	require.Empty(t, shouldCommit)
+	require.Equal(t, []*Inode{cold}, shouldRePush)
+}
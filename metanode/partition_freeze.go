@@ -0,0 +1,169 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	defaultFreezeTimeout = 30 * time.Second
+	maxFreezeTimeout     = 10 * time.Minute
+)
+
+var (
+	ErrPartitionFrozen      = errors.New("meta partition is already frozen")
+	ErrPartitionThawedEarly = errors.New("meta partition is thawed before in-flight mutations finished")
+)
+
+// partitionFreezer quiesces the mutations of a meta partition, new mutations
+// are queued until thawed, so that a consistent snapshot can be captured outside.
+// The zero value is ready to use.
+type partitionFreezer struct {
+	sync.Mutex
+	inflight int
+	gen      uint64
+	thawC    chan struct{} // not nil while frozen, closed on thaw
+	drainC   chan struct{} // closed when in-flight mutations finished while frozen
+	timer    *time.Timer
+	frozenAt time.Time
+}
+
+// enter blocks while frozen, the caller must call exit after the mutation is done.
+func (f *partitionFreezer) enter() {
+	for {
+		f.Lock()
+		thawC := f.thawC
+		if thawC == nil {
+			f.inflight++
+			f.Unlock()
+			return
+		}
+		f.Unlock()
+		<-thawC
+	}
+}
+
+func (f *partitionFreezer) exit() {
+	f.Lock()
+	f.inflight--
+	if f.inflight == 0 && f.drainC != nil {
+		close(f.drainC)
+		f.drainC = nil
+	}
+	f.Unlock()
+}
+
+// freeze stops new mutations and waits for the in-flight ones,
+// the freezer is thawed automatically after timeout.
+func (f *partitionFreezer) freeze(timeout time.Duration, onTimeout func()) error {
+	f.Lock()
+	if f.thawC != nil {
+		f.Unlock()
+		return ErrPartitionFrozen
+	}
+	f.gen++
+	gen := f.gen
+	thawC := make(chan struct{})
+	f.thawC = thawC
+	f.frozenAt = time.Now()
+	f.timer = time.AfterFunc(timeout, func() {
+		if f.thawGen(gen) && onTimeout != nil {
+			onTimeout()
+		}
+	})
+	drainC := make(chan struct{})
+	if f.inflight == 0 {
+		close(drainC)
+	} else {
+		f.drainC = drainC
+	}
+	f.Unlock()
+
+	select {
+	case <-drainC:
+		return nil
+	case <-thawC:
+		return ErrPartitionThawedEarly
+	}
+}
+
+// thaw resumes the queued mutations, returns false if not frozen.
+func (f *partitionFreezer) thaw() bool {
+	f.Lock()
+	gen := f.gen
+	f.Unlock()
+	return f.thawGen(gen)
+}
+
+func (f *partitionFreezer) thawGen(gen uint64) bool {
+	f.Lock()
+	defer f.Unlock()
+	if f.thawC == nil || f.gen != gen {
+		return false
+	}
+	f.timer.Stop()
+	close(f.thawC)
+	f.thawC = nil
+	f.drainC = nil
+	f.timer = nil
+	return true
+}
+
+// frozenSince returns the time when frozen, or zero time if not frozen.
+func (f *partitionFreezer) frozenSince() time.Time {
+	f.Lock()
+	defer f.Unlock()
+	if f.thawC == nil {
+		return time.Time{}
+	}
+	return f.frozenAt
+}
+
+// Freeze quiesces the mutations of the partition for at most timeout,
+// returns the apply id after all in-flight mutations are applied.
+func (mp *metaPartition) Freeze(timeout time.Duration) (applyID uint64, err error) {
+	if timeout <= 0 {
+		timeout = defaultFreezeTimeout
+	}
+	if timeout > maxFreezeTimeout {
+		timeout = maxFreezeTimeout
+	}
+	if err = mp.freezer.freeze(timeout, func() {
+		log.LogWarnf("[Freeze] partition(%v) thawed automatically after %v", mp.config.PartitionId, timeout)
+	}); err != nil {
+		return
+	}
+	applyID = mp.getApplyID()
+	log.LogInfof("[Freeze] partition(%v) frozen at applyID(%v) timeout(%v)", mp.config.PartitionId, applyID, timeout)
+	return
+}
+
+// Thaw resumes the mutations of the partition, returns false if not frozen.
+func (mp *metaPartition) Thaw() bool {
+	ok := mp.freezer.thaw()
+	if ok {
+		log.LogInfof("[Thaw] partition(%v) thawed", mp.config.PartitionId)
+	}
+	return ok
+}
+
+func (mp *metaPartition) IsFrozen() bool {
+	return !mp.freezer.frozenSince().IsZero()
+}
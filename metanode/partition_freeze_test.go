@@ -0,0 +1,65 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionFreezer(t *testing.T) {
+	var f partitionFreezer
+
+	// freeze waits for in-flight mutations
+	f.enter()
+	frozen := make(chan error)
+	go func() { frozen <- f.freeze(time.Minute, nil) }()
+	select {
+	case <-frozen:
+		t.Fatal("frozen before in-flight mutation finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+	f.exit()
+	require.NoError(t, <-frozen)
+	require.False(t, f.frozenSince().IsZero())
+	require.ErrorIs(t, f.freeze(time.Minute, nil), ErrPartitionFrozen)
+
+	// new mutations are queued until thawed
+	var done int32
+	entered := make(chan struct{})
+	go func() {
+		f.enter()
+		atomic.StoreInt32(&done, 1)
+		f.exit()
+		close(entered)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, int32(0), atomic.LoadInt32(&done))
+	require.True(t, f.thaw())
+	<-entered
+	require.False(t, f.thaw())
+	require.True(t, f.frozenSince().IsZero())
+
+	// thawed automatically after timeout
+	timeout := make(chan struct{})
+	require.NoError(t, f.freeze(10*time.Millisecond, func() { close(timeout) }))
+	<-timeout
+	require.True(t, f.frozenSince().IsZero())
+	f.enter()
+	f.exit()
+}
@@ -150,6 +150,7 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 		if err != nil {
 			return
 		}
+		mp.auditSetAttr(index, req)
 		err = mp.fsmSetAttr(req)
 	case opFSMCreateDentry:
 		den := &Dentry{}
@@ -169,6 +170,7 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 		if err = den.Unmarshal(msg.V); err != nil {
 			return
 		}
+		den.Name = mp.resolveDentryName(den.ParentId, den.Name)
 
 		status := mp.dentryInTx(den.ParentId, den.Name)
 		if status != proto.OpOk {
@@ -188,6 +190,7 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 		if err = den.Unmarshal(msg.V); err != nil {
 			return
 		}
+		den.Name = mp.resolveDentryName(den.ParentId, den.Name)
 
 		status := mp.dentryInTx(den.ParentId, den.Name)
 		if status != proto.OpOk {
@@ -208,6 +211,12 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 			return
 		}
 		resp = mp.fsmAppendExtents(ino)
+	case opFSMExtentsAddIfSize:
+		ino := NewInode(0, 0)
+		if err = ino.Unmarshal(msg.V); err != nil {
+			return
+		}
+		resp = mp.fsmAppendExtentsIfSize(ino)
 	case opFSMExtentsAddWithCheck:
 		ino := NewInode(0, 0)
 		if err = ino.Unmarshal(msg.V); err != nil {
@@ -253,6 +262,7 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 		uidRebuild := mp.acucumRebuildStart()
 		uniqId := mp.GetUniqId()
 		uniqChecker := mp.uniqChecker.clone()
+		auditChain := mp.auditChain.clone()
 		msg := &storeMsg{
 			command:        opFSMStoreTick,
 			applyIndex:     index,
@@ -268,6 +278,7 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 			uidRebuild:     uidRebuild,
 			uniqId:         uniqId,
 			uniqChecker:    uniqChecker,
+			auditChain:     auditChain,
 			multiVerList:   mp.GetAllVerList(),
 		}
 		log.LogDebugf("opFSMStoreTick: quotaRebuild [%v] uidRebuild [%v]", quotaRebuild, uidRebuild)
@@ -433,12 +444,14 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 		if err = json.Unmarshal(msg.V, req); err != nil {
 			return
 		}
+		mp.auditQuota(index, auditOpSetQuota, req.QuotaId, req.Inodes)
 		resp = mp.fsmSetInodeQuotaBatch(req)
 	case opFSMDeleteInodeQuotaBatch:
 		req := &proto.BatchDeleteMetaserverQuotaReuqest{}
 		if err = json.Unmarshal(msg.V, req); err != nil {
 			return
 		}
+		mp.auditQuota(index, auditOpDeleteQuota, req.QuotaId, req.Inodes)
 		resp = mp.fsmDeleteInodeQuotaBatch(req)
 	case opFSMUniqID:
 		resp = mp.fsmUniqID(msg.V)
@@ -629,6 +642,7 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 		txRbInodeTree  = NewBtree()
 		txRbDentryTree = NewBtree()
 		uniqChecker    = newUniqChecker()
+		auditChain     = newAuditChain()
 		verList        []*proto.VolVersionInfo
 	)
 
@@ -677,6 +691,7 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 			mp.txProcessor.txManager.txIdAlloc.setTransactionID(txID)
 			mp.inodeTree = inodeTree
 			mp.dentryTree = dentryTree
+			mp.dentryFold.rebuild(dentryTree)
 			mp.extendTree = extendTree
 			mp.multipartTree = multipartTree
 			mp.config.Cursor = cursor
@@ -684,6 +699,7 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 			mp.txProcessor.txResource.txRbInodeTree = txRbInodeTree
 			mp.txProcessor.txResource.txRbDentryTree = txRbDentryTree
 			mp.uniqChecker = uniqChecker
+			mp.auditChain = auditChain
 			mp.multiVersionList.VerList = make([]*proto.VolVersionInfo, len(verList))
 			copy(mp.multiVersionList.VerList, verList)
 			mp.verSeq = mp.multiVersionList.GetLastVer()
@@ -703,6 +719,7 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 				txRbDentryTree: mp.txProcessor.txResource.txRbDentryTree.GetTree(),
 				uniqId:         mp.GetUniqId(),
 				uniqChecker:    uniqChecker.clone(),
+				auditChain:     auditChain.clone(),
 				multiVerList:   mp.GetVerList(),
 			}
 			select {
@@ -851,6 +868,12 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 				return
 			}
 			log.LogDebugf("ApplySnapshot: write snap uniqChecker")
+		case opFSMAuditChainSnap:
+			if err = auditChain.UnMarshal(snap.V); err != nil {
+				log.LogErrorf("ApplySnapshot: apply snap auditChain fail: partitionID(%v) err(%v)", mp.config.PartitionId, err)
+				return
+			}
+			log.LogDebugf("ApplySnapshot: apply snap auditChain")
 
 		default:
 			if leaderSnapFormatVer != math.MaxUint32 && leaderSnapFormatVer > mp.manager.metaNode.raftSyncSnapFormatVersion {
@@ -931,8 +954,10 @@ func (mp *metaPartition) submit(op uint32, data []byte) (resp interface{}, err e
 		return
 	}
 
-	// submit to the raft store
+	// submit to the raft store, queued while the partition is frozen
+	mp.freezer.enter()
 	resp, err = mp.raftPartition.Submit(cmd)
+	mp.freezer.exit()
 	log.LogDebugf("submit. op [%v] done", op)
 	return
 }
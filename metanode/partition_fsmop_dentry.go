@@ -91,7 +91,15 @@ func (mp *metaPartition) fsmCreateDentry(dentry *Dentry,
 			status = proto.OpArgMismatchErr
 			return
 		}
+		if status = mp.checkDentryIgnoreCase(dentry); status != proto.OpOk {
+			return
+		}
 	}
+	defer func() {
+		if status == proto.OpOk {
+			mp.dentryFold.add(dentry.ParentId, dentry.Name)
+		}
+	}()
 
 	if item, ok := mp.dentryTree.ReplaceOrInsert(dentry, false); !ok {
 		// do not allow directories and files to overwrite each
@@ -182,8 +190,7 @@ func (mp *metaPartition) fsmTxDeleteDentry(txDentry *TxDentry) (resp *DentryResp
 	}
 
 	tmpDen := txDentry.Dentry
-	txDI := proto.NewTxDentryInfo("", tmpDen.ParentId, tmpDen.Name, 0)
-	txDenInfo, ok := txDentry.TxInfo.TxDentryInfos[txDI.GetKey()]
+	txDenInfo, ok := mp.getTxDentryInfoIgnoreCase(txDentry.TxInfo, tmpDen.ParentId, tmpDen.Name)
 	if !ok {
 		resp.Status = proto.OpTxDentryInfoNotExistErr
 		return
@@ -214,6 +221,7 @@ func (mp *metaPartition) fsmTxDeleteDentry(txDentry *TxDentry) (resp *DentryResp
 	}
 
 	mp.dentryTree.Delete(tmpDen)
+	mp.dentryFold.remove(tmpDen.ParentId, tmpDen.Name)
 	// parent link count not change
 	resp.Msg = item.(*Dentry)
 	return
@@ -230,6 +238,7 @@ func (mp *metaPartition) fsmDeleteDentry(denParm *Dentry, checkInode bool) (resp
 		doMore   = true
 		clean    bool
 	)
+	defer mp.removeDentryFold(denParm.ParentId, denParm.Name)
 	if checkInode {
 		log.LogDebugf("action[fsmDeleteDentry] mp[%v] delete param %v", mp.config.PartitionId, denParm)
 		item = mp.dentryTree.Execute(func(tree *btree.BTree) interface{} {
@@ -305,6 +314,7 @@ func (mp *metaPartition) fsmDeleteDentry(denParm *Dentry, checkInode bool) (resp
 func (mp *metaPartition) fsmBatchDeleteDentry(db DentryBatch) []*DentryResponse {
 	result := make([]*DentryResponse, 0, len(db))
 	for _, dentry := range db {
+		dentry.Name = mp.resolveDentryName(dentry.ParentId, dentry.Name)
 		status := mp.dentryInTx(dentry.ParentId, dentry.Name)
 		if status != proto.OpOk {
 			result = append(result, &DentryResponse{Status: status})
@@ -328,8 +338,7 @@ func (mp *metaPartition) fsmTxUpdateDentry(txUpDateDentry *TxUpdateDentry) (resp
 	newDen := txUpDateDentry.NewDentry
 	oldDen := txUpDateDentry.OldDentry
 
-	txDI := proto.NewTxDentryInfo("", oldDen.ParentId, oldDen.Name, 0)
-	txDenInfo, ok := txUpDateDentry.TxInfo.TxDentryInfos[txDI.GetKey()]
+	txDenInfo, ok := mp.getTxDentryInfoIgnoreCase(txUpDateDentry.TxInfo, oldDen.ParentId, oldDen.Name)
 	if !ok {
 		resp.Status = proto.OpTxDentryInfoNotExistErr
 		return
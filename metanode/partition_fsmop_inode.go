@@ -445,6 +445,24 @@ func (mp *metaPartition) fsmAppendExtents(ino *Inode) (status uint8) {
 	return
 }
 
+// fsmAppendExtentsIfSize appends the extents only if the size of the inode is the size of ino.
+func (mp *metaPartition) fsmAppendExtentsIfSize(ino *Inode) (status uint8) {
+	item := mp.inodeTree.Get(ino)
+	if item == nil {
+		return proto.OpNotExistErr
+	}
+	ino2 := item.(*Inode)
+	ino2.RLock()
+	size := ino2.Size
+	ino2.RUnlock()
+	if size != ino.Size {
+		log.LogWarnf("fsmAppendExtentsIfSize mpId[%v].inode[%v] size(%v) expected(%v)",
+			mp.config.PartitionId, ino.Inode, size, ino.Size)
+		return proto.OpArgMismatchErr
+	}
+	return mp.fsmAppendExtents(ino)
+}
+
 func (mp *metaPartition) fsmAppendExtentsWithCheck(ino *Inode, isSplit bool) (status uint8) {
 	var (
 		delExtents       []proto.ExtentKey
@@ -149,6 +149,7 @@ type MetaItemIterator struct {
 	txRbInodeTree     *BTree
 	txRbDentryTree    *BTree
 	uniqChecker       *uniqChecker
+	auditChain        *auditChain
 	verList           []*proto.VolVersionInfo
 
 	filenames []string
@@ -204,6 +205,7 @@ func newMetaItemIterator(mp *metaPartition) (si *MetaItemIterator, err error) {
 	si.txRbInodeTree = mp.txProcessor.txResource.txRbInodeTree.GetTree()
 	si.txRbDentryTree = mp.txProcessor.txResource.txRbDentryTree.GetTree()
 	si.uniqChecker = mp.uniqChecker.clone()
+	si.auditChain = mp.auditChain.clone()
 	si.verList = mp.GetAllVerList()
 	mp.nonIdempotent.Unlock()
 
@@ -351,6 +353,13 @@ func newMetaItemIterator(mp *metaPartition) (si *MetaItemIterator, err error) {
 					return
 				}
 			}
+
+			if si.auditChain.len() > 0 {
+				produceItem(si.auditChain)
+				if checkClose() {
+					return
+				}
+			}
 		}
 
 		// process extent del files
@@ -484,6 +493,14 @@ func (si *MetaItemIterator) Next() (data []byte, err error) {
 			return
 		}
 		snap = NewMetaItem(opFSMUniqCheckerSnap, nil, raw)
+	case *auditChain:
+		var raw []byte
+		if raw, _, err = typedItem.Marshal(); err != nil {
+			si.err = err
+			si.Close()
+			return
+		}
+		snap = NewMetaItem(opFSMAuditChainSnap, nil, raw)
 	default:
 		panic(fmt.Sprintf("unknown item type: %v", reflect.TypeOf(item).Name()))
 	}
@@ -80,6 +80,9 @@ func (mp *metaPartition) TxCreateDentry(req *proto.TxCreateDentryRequest, p *Pac
 	}
 
 	p.ResultCode = status.(uint8)
+	if p.ResultCode == proto.OpOk {
+		mp.inheritXAttrs(req.ParentID, req.Inode, req.Mode)
+	}
 	return
 }
 
@@ -129,6 +132,9 @@ func (mp *metaPartition) CreateDentry(req *CreateDentryReq, p *Packet, remoteAdd
 		return
 	}
 	p.ResultCode = resp.(uint8)
+	if p.ResultCode == proto.OpOk {
+		mp.inheritXAttrs(req.ParentID, req.Inode, req.Mode)
+	}
 	return
 }
 
@@ -186,6 +192,9 @@ func (mp *metaPartition) QuotaCreateDentry(req *proto.QuotaCreateDentryRequest,
 		return
 	}
 	p.ResultCode = resp.(uint8)
+	if p.ResultCode == proto.OpOk {
+		mp.inheritXAttrs(req.ParentID, req.Inode, req.Mode)
+	}
 	return
 }
 
@@ -214,6 +223,9 @@ func (mp *metaPartition) TxDeleteDentry(req *proto.TxDeleteDentryRequest, p *Pac
 	}()
 
 	dentry, status := mp.getDentry(den)
+	if status == proto.OpNotExistErr && mp.vol.isCaseInsensitive() {
+		dentry, status = mp.getDentryIgnoreCase(req.ParentID, req.Name, 0)
+	}
 	if status != proto.OpOk {
 		if mp.txDentryInRb(req.ParentID, req.Name, req.TxInfo.TxID) {
 			p.ResultCode = proto.OpOk
@@ -424,6 +436,9 @@ func (mp *metaPartition) TxUpdateDentry(req *proto.TxUpdateDentryRequest, p *Pac
 		Inode:    req.Inode,
 	}
 	oldDentry, status := mp.getDentry(newDentry)
+	if status == proto.OpNotExistErr && mp.vol.isCaseInsensitive() {
+		oldDentry, status = mp.getDentryIgnoreCase(req.ParentID, req.Name, 0)
+	}
 	if status != proto.OpOk {
 		if mp.txDentryInRb(req.ParentID, req.Name, req.TxInfo.TxID) {
 			p.ResultCode = proto.OpOk
@@ -552,6 +567,9 @@ func (mp *metaPartition) Lookup(req *LookupReq, p *Packet) (err error) {
 		denList = mp.getDentryList(dentry)
 	}
 	dentry, status := mp.getDentry(dentry)
+	if status == proto.OpNotExistErr && mp.vol.isCaseInsensitive() {
+		dentry, status = mp.getDentryIgnoreCase(req.ParentID, req.Name, req.VerSeq)
+	}
 
 	var reply []byte
 	if status == proto.OpOk || req.VerAll {
@@ -533,12 +533,18 @@ func (mp *metaPartition) BatchExtentAppend(req *proto.AppendExtentKeysRequest, p
 	for _, extent := range extents {
 		ino.Extents.Append(extent)
 	}
+	var op uint32 = opFSMExtentsAdd
+	if req.CheckSize {
+		// the size is checked by the fsm, so that the appends at the same size are serialized
+		ino.Size = req.Size
+		op = opFSMExtentsAddIfSize
+	}
 	val, err := ino.Marshal()
 	if err != nil {
 		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
 		return
 	}
-	resp, err := mp.submit(opFSMExtentsAdd, val)
+	resp, err := mp.submit(op, val)
 	if err != nil {
 		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
 		return
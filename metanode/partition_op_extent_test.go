@@ -0,0 +1,49 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFsmAppendExtentsIfSize(t *testing.T) {
+	partition := newPartition(&MetaPartitionConfig{PartitionId: 10002, VolName: VolNameForTest, PartitionType: proto.VolumeTypeHot}, nil)
+	ino := NewInode(1, FileModeType)
+	partition.fsmCreateInode(ino)
+
+	appendAt := func(size uint64, ek proto.ExtentKey) uint8 {
+		param := NewInode(ino.Inode, 0)
+		param.Size = size
+		param.Extents.Append(ek)
+		return partition.fsmAppendExtentsIfSize(param)
+	}
+	require.Equal(t, proto.OpOk, appendAt(0, proto.ExtentKey{FileOffset: 0, PartitionId: 1, ExtentId: 1, Size: 100}))
+	require.EqualValues(t, 100, ino.Size)
+
+	// the append at the same position from the other objectnode
+	require.Equal(t, proto.OpArgMismatchErr, appendAt(0, proto.ExtentKey{FileOffset: 0, PartitionId: 1, ExtentId: 2, Size: 100}))
+	require.EqualValues(t, 100, ino.Size)
+	eks := ino.Extents.CopyExtents()
+	require.Len(t, eks, 1)
+	require.EqualValues(t, 1, eks[0].ExtentId)
+
+	require.Equal(t, proto.OpOk, appendAt(100, proto.ExtentKey{FileOffset: 100, PartitionId: 1, ExtentId: 3, Size: 50}))
+	require.EqualValues(t, 150, ino.Size)
+
+	require.Equal(t, proto.OpNotExistErr, partition.fsmAppendExtentsIfSize(NewInode(2, 0)))
+}
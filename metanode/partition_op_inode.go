@@ -592,6 +592,11 @@ func (mp *metaPartition) TxCreateInodeLink(req *proto.TxLinkInodeRequest, p *Pac
 		p.PacketErrorWithBody(inoResp.Status, []byte(err.Error()))
 		return
 	}
+	if status, checkErr := mp.checkLinkInode(req.VolName, inoResp.Msg, false); status != proto.OpOk {
+		err = checkErr
+		p.PacketErrorWithBody(status, []byte(err.Error()))
+		return
+	}
 
 	ti := &TxInode{
 		Inode:  inoResp.Msg,
@@ -619,6 +624,7 @@ func (mp *metaPartition) TxCreateInodeLink(req *proto.TxLinkInodeRequest, p *Pac
 		}
 		if replyInfo(resp.Info, retMsg.Msg, make(map[uint32]*proto.MetaQuotaInfo)) {
 			status = proto.OpOk
+			mp.getLinkLimit().observe(mp.GetVolName(), req.Inode, resp.Info.Nlink)
 			reply, err = json.Marshal(resp)
 			if err != nil {
 				status = proto.OpErr
@@ -638,6 +644,16 @@ func (mp *metaPartition) CreateInodeLink(req *LinkInodeReq, p *Packet, remoteAdd
 			auditlog.LogInodeOp(remoteAddr, mp.GetVolName(), p.GetOpMsg(), req.GetFullPath(), err, time.Since(start).Milliseconds(), req.Inode, 0)
 		}()
 	}
+	var target *Inode
+	if item := mp.inodeTree.Get(NewInode(req.Inode, 0)); item != nil {
+		target = item.(*Inode)
+	}
+	if status, checkErr := mp.checkLinkInode(req.VolName, target, req.IsRename); status != proto.OpOk {
+		err = checkErr
+		p.PacketErrorWithBody(status, []byte(err.Error()))
+		return
+	}
+
 	var r interface{}
 	var val []byte
 	if req.UniqID > 0 {
@@ -668,6 +684,9 @@ func (mp *metaPartition) CreateInodeLink(req *LinkInodeReq, p *Packet, remoteAdd
 		}
 		if replyInfo(resp.Info, retMsg.Msg, make(map[uint32]*proto.MetaQuotaInfo)) {
 			status = proto.OpOk
+			if !req.IsRename {
+				mp.getLinkLimit().observe(mp.GetVolName(), req.Inode, resp.Info.Nlink)
+			}
 			reply, err = json.Marshal(resp)
 			if err != nil {
 				status = proto.OpErr
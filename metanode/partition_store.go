@@ -52,6 +52,7 @@ const (
 	uniqIDFile              = "uniqID"
 	uniqCheckerFile         = "uniqChecker"
 	verdataFile             = "multiVer"
+	auditChainFile          = "auditChain"
 	StaleMetadataSuffix     = ".old"
 	StaleMetadataTimeFormat = "20060102150405.000000000"
 )
@@ -732,6 +733,26 @@ func (mp *metaPartition) loadUniqChecker(rootDir string, crc uint32) (err error)
 	return
 }
 
+func (mp *metaPartition) loadAuditChain(rootDir string, crc uint32) (err error) {
+	filename := path.Join(rootDir, auditChainFile)
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		err = errors.NewErrorf("[loadAuditChain] OpenFile: %v", err.Error())
+		return
+	}
+	if res := crc32.ChecksumIEEE(data); res != crc {
+		log.LogErrorf("[loadAuditChain]: check crc mismatch, expected[%d], actual[%d]", crc, res)
+		return ErrSnapshotCrcMismatch
+	}
+	if err = mp.auditChain.UnMarshal(data); err != nil {
+		err = errors.NewErrorf("[loadAuditChain] Unmarshal: %v", err.Error())
+		return
+	}
+	log.LogInfof("loadAuditChain: load complete: partitionID(%v) volume(%v) entries(%v)",
+		mp.config.PartitionId, mp.config.VolName, mp.auditChain.len())
+	return
+}
+
 func (mp *metaPartition) loadMultiVer(rootDir string, crc uint32) (err error) {
 	filename := path.Join(rootDir, verdataFile)
 	if _, err = os.Stat(filename); err != nil {
@@ -1317,6 +1338,31 @@ func (mp *metaPartition) storeUniqID(rootDir string, sm *storeMsg) (err error) {
 	return mp.doStoreUniqID(rootDir, sm.uniqId)
 }
 
+func (mp *metaPartition) storeAuditChain(rootDir string, sm *storeMsg) (crc uint32, err error) {
+	chain := sm.auditChain
+	if chain == nil {
+		chain = newAuditChain()
+	}
+	var data []byte
+	if data, crc, err = chain.Marshal(); err != nil {
+		return
+	}
+	fp, err := os.OpenFile(path.Join(rootDir, auditChainFile), os.O_RDWR|os.O_TRUNC|os.O_APPEND|os.O_CREATE, 0o755)
+	if err != nil {
+		return
+	}
+	defer func() {
+		err = fp.Sync()
+		fp.Close()
+	}()
+	if _, err = fp.Write(data); err != nil {
+		return
+	}
+	log.LogInfof("storeAuditChain: store complete: partitionID(%v) volume(%v) crc(%v)",
+		mp.config.PartitionId, mp.config.VolName, crc)
+	return
+}
+
 func (mp *metaPartition) storeUniqChecker(rootDir string, sm *storeMsg) (crc uint32, err error) {
 	filename := path.Join(rootDir, uniqCheckerFile)
 	fp, err := os.OpenFile(filename, os.O_RDWR|os.O_TRUNC|os.O_APPEND|os.
@@ -41,6 +41,7 @@ type storeMsg struct {
 	uidRebuild     bool
 	uniqId         uint64
 	uniqChecker    *uniqChecker
+	auditChain     *auditChain
 	multiVerList   []*proto.VolVersionInfo
 }
 
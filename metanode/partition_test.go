@@ -17,6 +17,7 @@ package metanode
 import (
 	"os"
 	"path"
+	"sync"
 	"testing"
 	"time"
 
@@ -136,3 +137,118 @@ func TestMetaPartition_LoadSnapshot(t *testing.T) {
 	err = partition.LoadSnapshot(snapshotPath)
 	require.Equal(t, ErrSnapshotCrcMismatch, err)
 }
+
+func TestMetaPartition_GetDentryIgnoreCase(t *testing.T) {
+	initMp(t)
+	dir := testCreateInode(t, DirModeType)
+	file := testCreateInode(t, FileModeType)
+	testCreateDentry(t, dir.Inode, file.Inode, "ReadMe.TXT", FileModeType)
+
+	_, status := mp.getDentryIgnoreCase(dir.Inode, "readme.txt", 0)
+	require.Equal(t, proto.OpNotExistErr, status)
+	// the index is built from the existing dentries on enabled
+	mp.setCaseInsensitive(true)
+	require.True(t, mp.vol.isCaseInsensitive())
+
+	_, status = mp.getDentry(&Dentry{ParentId: dir.Inode, Name: "readme.txt"})
+	require.Equal(t, proto.OpNotExistErr, status)
+
+	den, status := mp.getDentryIgnoreCase(dir.Inode, "readme.txt", 0)
+	require.Equal(t, proto.OpOk, status)
+	require.Equal(t, "ReadMe.TXT", den.Name)
+	require.Equal(t, file.Inode, den.Inode)
+
+	_, status = mp.getDentryIgnoreCase(dir.Inode, "readme", 0)
+	require.Equal(t, proto.OpNotExistErr, status)
+	_, status = mp.getDentryIgnoreCase(file.Inode, "readme.txt", 0)
+	require.Equal(t, proto.OpNotExistErr, status)
+
+	// another inode with the same name ignoring case is rejected in fsm
+	other := testCreateInode(t, FileModeType)
+	status = mp.fsmCreateDentry(&Dentry{ParentId: dir.Inode, Name: "README.txt", Inode: other.Inode, Type: FileModeType}, false)
+	require.Equal(t, proto.OpExistErr, status)
+	require.False(t, mp.dentryTree.Has(&Dentry{ParentId: dir.Inode, Name: "README.txt"}))
+}
+
+func TestMetaPartition_SetCaseInsensitiveConcurrent(t *testing.T) {
+	initMp(t)
+	dir := testCreateInode(t, DirModeType)
+	file := testCreateInode(t, FileModeType)
+	testCreateDentry(t, dir.Inode, file.Inode, "ReadMe.TXT", FileModeType)
+
+	// the volume view is updated while looking up dentries
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for ii := 0; ii < 100; ii++ {
+			mp.setCaseInsensitive(true)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for ii := 0; ii < 100; ii++ {
+			if !mp.vol.isCaseInsensitive() {
+				continue
+			}
+			// the index is built before enabled
+			_, status := mp.getDentryIgnoreCase(dir.Inode, "readme.txt", 0)
+			require.Equal(t, proto.OpOk, status)
+		}
+	}()
+	wg.Wait()
+	require.True(t, mp.vol.isCaseInsensitive())
+}
+
+func TestMetaPartition_RenameDeleteIgnoreCase(t *testing.T) {
+	initMp(t)
+	mp.setCaseInsensitive(true)
+	dir := testCreateInode(t, DirModeType)
+	file := testCreateInode(t, FileModeType)
+	testCreateDentry(t, dir.Inode, file.Inode, "ReadMe.TXT", FileModeType)
+
+	// rename to another case: create the new name of the same inode, then delete the old one
+	testCreateDentry(t, dir.Inode, file.Inode, "readme.txt", FileModeType)
+	resp := mp.fsmDeleteDentry(&Dentry{ParentId: dir.Inode, Name: "ReadMe.TXT"}, false)
+	require.Equal(t, proto.OpOk, resp.Status)
+	den, status := mp.getDentryIgnoreCase(dir.Inode, "README.TXT", 0)
+	require.Equal(t, proto.OpOk, status)
+	require.Equal(t, "readme.txt", den.Name)
+
+	// update the dentry by the name of another case
+	other := testCreateInode(t, FileModeType)
+	update := &Dentry{ParentId: dir.Inode, Name: mp.resolveDentryName(dir.Inode, "README.TXT"), Inode: other.Inode}
+	require.Equal(t, "readme.txt", update.Name)
+	resp = mp.fsmUpdateDentry(update)
+	require.Equal(t, proto.OpOk, resp.Status)
+	den, _ = mp.getDentryIgnoreCase(dir.Inode, "README.TXT", 0)
+	require.Equal(t, other.Inode, den.Inode)
+
+	// delete the dentry by the name of another case
+	name := mp.resolveDentryName(dir.Inode, "Readme.Txt")
+	require.Equal(t, "readme.txt", name)
+	resp = mp.fsmDeleteDentry(&Dentry{ParentId: dir.Inode, Name: name}, false)
+	require.Equal(t, proto.OpOk, resp.Status)
+	_, status = mp.getDentryIgnoreCase(dir.Inode, "readme.txt", 0)
+	require.Equal(t, proto.OpNotExistErr, status)
+	require.Equal(t, 0, len(mp.dentryFold.names))
+	require.Equal(t, "Readme.Txt", mp.resolveDentryName(dir.Inode, "Readme.Txt"))
+
+	// the index is rebuilt from the tree of the applied snapshot
+	tree := NewBtree()
+	tree.ReplaceOrInsert(&Dentry{ParentId: dir.Inode, Name: "Snap", Inode: file.Inode}, true)
+	mp.dentryTree = tree
+	mp.dentryFold.rebuild(tree)
+	require.Equal(t, []string{"Snap"}, mp.dentryFold.get(dir.Inode, "SNAP"))
+
+	// the dentry info of transaction is matched ignoring case
+	txInfo := proto.NewTransactionInfo(0, proto.TxTypeRename)
+	txInfo.TxDentryInfos = map[string]*proto.TxDentryInfo{}
+	txDenInfo := proto.NewTxDentryInfo("", dir.Inode, "SNAP", 0)
+	txInfo.TxDentryInfos[txDenInfo.GetKey()] = txDenInfo
+	got, ok := mp.getTxDentryInfoIgnoreCase(txInfo, dir.Inode, "Snap")
+	require.True(t, ok)
+	require.Equal(t, txDenInfo, got)
+	_, ok = mp.getTxDentryInfoIgnoreCase(txInfo, file.Inode, "Snap")
+	require.False(t, ok)
+}
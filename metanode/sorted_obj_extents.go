@@ -119,6 +119,12 @@ func (se *SortedObjExtents) CopyExtents() []proto.ObjExtentKey {
 	return se.doCopyExtents()
 }
 
+func (se *SortedObjExtents) Len() int {
+	se.RLock()
+	defer se.RUnlock()
+	return len(se.eks)
+}
+
 // Returns the file size
 func (se *SortedObjExtents) Size() uint64 {
 	se.RLock()
@@ -0,0 +1,208 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/util/exporter"
+)
+
+const MetricVolQosRejected = "vol_qos_rejected"
+
+var (
+	ErrVolQosQueueFull    = errors.New("too many queued requests of volume")
+	ErrVolQosQueueTimeout = errors.New("queued request of volume timeout")
+)
+
+// VolQosLimit limits the requests of one volume on the meta node, 0 means unlimited.
+type VolQosLimit struct {
+	MaxConcurrency int   `json:"maxConcurrency"` // max requests handled concurrently
+	MaxQueue       int   `json:"maxQueue"`       // max requests waiting for concurrency
+	QueueTimeoutMs int64 `json:"queueTimeoutMs"` // max time of a request waiting in queue
+}
+
+// VolQosConfig is the default limit of every volume, and the limits of specified volumes,
+// which replace the default limit.
+type VolQosConfig struct {
+	VolQosLimit
+	Vols map[string]VolQosLimit `json:"vols"`
+}
+
+func parseVolQosConfig(raw interface{}) (conf VolQosConfig, err error) {
+	if raw == nil {
+		return
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(data, &conf); err != nil {
+		return
+	}
+	if err = conf.VolQosLimit.validate(); err != nil {
+		return
+	}
+	for name, limit := range conf.Vols {
+		if err = limit.validate(); err != nil {
+			return conf, fmt.Errorf("vol(%v) %v", name, err)
+		}
+	}
+	return
+}
+
+func (l VolQosLimit) validate() error {
+	if l.MaxConcurrency < 0 || l.MaxQueue < 0 || l.QueueTimeoutMs < 0 {
+		return fmt.Errorf("invalid vol qos limit %+v", l)
+	}
+	return nil
+}
+
+type volLimiter struct {
+	limit  VolQosLimit
+	tokens chan struct{} // nil if concurrency is unlimited
+	queued int32
+}
+
+func newVolLimiter(limit VolQosLimit) *volLimiter {
+	l := &volLimiter{limit: limit}
+	if limit.MaxConcurrency > 0 {
+		l.tokens = make(chan struct{}, limit.MaxConcurrency)
+	}
+	return l
+}
+
+func (l *volLimiter) acquire() (release func(), err error) {
+	if l.tokens == nil {
+		return func() {}, nil
+	}
+	release = func() { <-l.tokens }
+	select {
+	case l.tokens <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	if queued := atomic.AddInt32(&l.queued, 1); l.limit.MaxQueue > 0 && int(queued) > l.limit.MaxQueue {
+		atomic.AddInt32(&l.queued, -1)
+		return nil, ErrVolQosQueueFull
+	}
+	defer atomic.AddInt32(&l.queued, -1)
+
+	if l.limit.QueueTimeoutMs <= 0 {
+		l.tokens <- struct{}{}
+		return release, nil
+	}
+	timer := time.NewTimer(time.Duration(l.limit.QueueTimeoutMs) * time.Millisecond)
+	defer timer.Stop()
+	select {
+	case l.tokens <- struct{}{}:
+		return release, nil
+	case <-timer.C:
+		return nil, ErrVolQosQueueTimeout
+	}
+}
+
+// volQos is the admission control of requests by volume, so that a runaway volume
+// cannot push the other volumes on the same meta node into timeout.
+type volQos struct {
+	sync.RWMutex
+	conf     VolQosConfig
+	limiters map[string]*volLimiter
+	rejected *exporter.Counter
+}
+
+func newVolQos(conf VolQosConfig) *volQos {
+	return &volQos{
+		conf:     conf,
+		limiters: make(map[string]*volLimiter),
+		rejected: exporter.NewCounter(MetricVolQosRejected),
+	}
+}
+
+func (q *volQos) getLimiter(vol string) *volLimiter {
+	q.RLock()
+	l, ok := q.limiters[vol]
+	q.RUnlock()
+	if ok {
+		return l
+	}
+
+	q.Lock()
+	defer q.Unlock()
+	if l, ok = q.limiters[vol]; ok {
+		return l
+	}
+	limit, ok := q.conf.Vols[vol]
+	if !ok {
+		limit = q.conf.VolQosLimit
+	}
+	l = newVolLimiter(limit)
+	q.limiters[vol] = l
+	return l
+}
+
+// acquire admits a request of the volume, release must be called after the request is done.
+func (q *volQos) acquire(vol string) (release func(), err error) {
+	if q == nil {
+		return func() {}, nil
+	}
+	if release, err = q.getLimiter(vol).acquire(); err != nil {
+		q.rejected.AddWithLabels(1, map[string]string{exporter.Vol: vol})
+	}
+	return
+}
+
+func (q *volQos) getConfig() VolQosConfig {
+	q.RLock()
+	defer q.RUnlock()
+	conf := VolQosConfig{VolQosLimit: q.conf.VolQosLimit, Vols: make(map[string]VolQosLimit, len(q.conf.Vols))}
+	for name, limit := range q.conf.Vols {
+		conf.Vols[name] = limit
+	}
+	return conf
+}
+
+// setLimit sets the limit of the volume, or the default limit if vol is empty.
+// The requests admitted before are released to the old limiter.
+func (q *volQos) setLimit(vol string, limit VolQosLimit) error {
+	if err := limit.validate(); err != nil {
+		return err
+	}
+	q.Lock()
+	defer q.Unlock()
+	if vol == "" {
+		q.conf.VolQosLimit = limit
+		for name := range q.limiters {
+			if _, ok := q.conf.Vols[name]; !ok {
+				delete(q.limiters, name)
+			}
+		}
+		return nil
+	}
+	vols := make(map[string]VolQosLimit, len(q.conf.Vols)+1)
+	for name, l := range q.conf.Vols {
+		vols[name] = l
+	}
+	vols[vol] = limit
+	q.conf.Vols = vols
+	delete(q.limiters, vol)
+	return nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVolQosConfig(t *testing.T) {
+	conf, err := parseVolQosConfig(map[string]interface{}{
+		"maxConcurrency": 10,
+		"maxQueue":       20,
+		"vols":           map[string]interface{}{"vol1": map[string]interface{}{"maxConcurrency": 1, "queueTimeoutMs": 100}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, VolQosLimit{MaxConcurrency: 10, MaxQueue: 20}, conf.VolQosLimit)
+	require.Equal(t, VolQosLimit{MaxConcurrency: 1, QueueTimeoutMs: 100}, conf.Vols["vol1"])
+
+	_, err = parseVolQosConfig(map[string]interface{}{"vols": map[string]interface{}{"vol1": map[string]interface{}{"maxQueue": -1}}})
+	require.Error(t, err)
+
+	conf, err = parseVolQosConfig(nil)
+	require.NoError(t, err)
+	require.Equal(t, VolQosLimit{}, conf.VolQosLimit)
+}
+
+func TestVolQos(t *testing.T) {
+	q := newVolQos(VolQosConfig{
+		VolQosLimit: VolQosLimit{MaxConcurrency: 2, MaxQueue: 1, QueueTimeoutMs: 50},
+		Vols: map[string]VolQosLimit{
+			"unlimited": {},
+			"queued":    {MaxConcurrency: 1, MaxQueue: 1},
+		},
+	})
+
+	release1, err := q.acquire("vol1")
+	require.NoError(t, err)
+	release2, err := q.acquire("vol1")
+	require.NoError(t, err)
+
+	// other volumes are not affected
+	for i := 0; i < 10; i++ {
+		_, err = q.acquire("unlimited")
+		require.NoError(t, err)
+	}
+	release, err := q.acquire("vol2")
+	require.NoError(t, err)
+	release()
+
+	// the queued request times out
+	start := time.Now()
+	_, err = q.acquire("vol1")
+	require.ErrorIs(t, err, ErrVolQosQueueTimeout)
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	release1()
+	release2()
+
+	// the queue is full
+	release, err = q.acquire("queued")
+	require.NoError(t, err)
+	done := make(chan error)
+	go func() {
+		release, err := q.acquire("queued")
+		if err == nil {
+			release()
+		}
+		done <- err
+	}()
+	require.Eventually(t, func() bool {
+		_, err := q.acquire("queued")
+		return err == ErrVolQosQueueFull
+	}, time.Second, time.Millisecond)
+	release()
+	require.NoError(t, <-done)
+
+	// update the limit
+	require.NoError(t, q.setLimit("vol1", VolQosLimit{MaxConcurrency: 1}))
+	require.Error(t, q.setLimit("", VolQosLimit{MaxConcurrency: -1}))
+	release1, err = q.acquire("vol1")
+	require.NoError(t, err)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		release1()
+	}()
+	release, err = q.acquire("vol1")
+	require.NoError(t, err)
+	release()
+	require.Equal(t, VolQosLimit{MaxConcurrency: 1}, q.getConfig().Vols["vol1"])
+
+	var nilQos *volQos
+	release, err = nilQos.acquire("vol1")
+	require.NoError(t, err)
+	release()
+}
@@ -0,0 +1,217 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// the inodes created no earlier than it are the new children, the dentries created for the
+// inodes renamed or linked from elsewhere do not inherit the xattrs of the directory
+const xattrInheritFreshSec = 30
+
+// inheritedXAttrs returns the xattrs inherited by the new child of the directory of the extend,
+// the new subdirectory inherits the defaults and the policy too if recursive.
+func inheritedXAttrs(parent *Extend, isDir bool) (attrs map[string]string) {
+	if parent == nil {
+		return nil
+	}
+	policy, hasPolicy := parent.Get([]byte(proto.XAttrInheritPolicy))
+	recursive := isDir && !(hasPolicy && string(policy) == proto.XAttrInheritChildren)
+	parent.Range(func(key, value []byte) bool {
+		inherited, ok := proto.InheritedXAttrKey(string(key))
+		if !ok {
+			return true
+		}
+		if attrs == nil {
+			attrs = make(map[string]string)
+		}
+		attrs[inherited] = string(value)
+		if recursive {
+			attrs[string(key)] = string(value)
+		}
+		return true
+	})
+	if recursive && hasPolicy && attrs != nil {
+		attrs[proto.XAttrInheritPolicy] = string(policy)
+	}
+	return
+}
+
+// inheritXAttrs sets the xattrs inherited from the directory on the child whose dentry is just
+// created, in this partition or in the one of the child. It is best effort, the dentry is
+// created anyway and the failures are only logged.
+func (mp *metaPartition) inheritXAttrs(parentID, ino uint64, mode uint32) {
+	item := mp.extendTree.Get(NewExtend(parentID))
+	if item == nil {
+		return
+	}
+	attrs := inheritedXAttrs(item.(*Extend), proto.IsDir(mode))
+	if len(attrs) == 0 {
+		return
+	}
+
+	req := &proto.InheritXAttrRequest{
+		VolName: mp.config.VolName,
+		Inode:   ino,
+		Attrs:   attrs,
+	}
+	var err error
+	if ino >= mp.config.Start && ino <= mp.config.End {
+		req.PartitionId = mp.config.PartitionId
+		err = mp.inheritXAttr(req)
+	} else if mp.manager != nil {
+		err = mp.manager.xattrInherit.send(mp.manager.connPool, req)
+	}
+	if err != nil {
+		log.LogWarnf("action[inheritXAttrs] mp(%v) parent(%v) ino(%v) attrs(%v) err(%v)",
+			mp.config.PartitionId, parentID, ino, attrs, err)
+	}
+}
+
+func (mp *metaPartition) InheritXAttr(req *proto.InheritXAttrRequest, p *Packet) (err error) {
+	if err = mp.inheritXAttr(req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	p.PacketOkReply()
+	return
+}
+
+// inheritXAttr sets the inherited xattrs the inode has none of, only if the inode is just created.
+func (mp *metaPartition) inheritXAttr(req *proto.InheritXAttrRequest) (err error) {
+	item := mp.inodeTree.Get(NewInode(req.Inode, 0))
+	if item == nil {
+		return fmt.Errorf("inode %v not exists", req.Inode)
+	}
+	if time.Now().Unix()-item.(*Inode).CreateTime > xattrInheritFreshSec {
+		return
+	}
+
+	var existing *Extend
+	if item := mp.extendTree.Get(NewExtend(req.Inode)); item != nil {
+		existing = item.(*Extend)
+	}
+	extend := NewExtend(req.Inode)
+	for key, val := range req.Attrs {
+		if existing != nil {
+			if _, ok := existing.Get([]byte(key)); ok {
+				continue
+			}
+		}
+		extend.Put([]byte(key), []byte(val), mp.verSeq)
+	}
+	if len(extend.dataMap) == 0 {
+		return
+	}
+	_, err = mp.putExtend(opFSMSetXAttr, extend)
+	return
+}
+
+// xattrInheritRouter routes the inherited xattrs to the meta partitions of the new children by
+// the meta partitions of the volumes cached, which are refreshed from the master on failure.
+type xattrInheritRouter struct {
+	sync.RWMutex
+	views map[string][]*proto.MetaPartitionView
+}
+
+func newXAttrInheritRouter() *xattrInheritRouter {
+	return &xattrInheritRouter{views: make(map[string][]*proto.MetaPartitionView)}
+}
+
+func (r *xattrInheritRouter) partition(volName string, ino uint64, refresh bool) (*proto.MetaPartitionView, error) {
+	r.RLock()
+	views, ok := r.views[volName]
+	r.RUnlock()
+	if !ok || refresh {
+		var err error
+		if views, err = masterClient.ClientAPI().GetMetaPartitions(volName); err != nil {
+			return nil, err
+		}
+		r.Lock()
+		r.views[volName] = views
+		r.Unlock()
+	}
+	for _, view := range views {
+		if ino >= view.Start && ino <= view.End {
+			return view, nil
+		}
+	}
+	return nil, fmt.Errorf("no meta partition of inode %v", ino)
+}
+
+// send sends the request to the meta partition of the inode, the partitions are refreshed and
+// the request is sent again if the partition is not found or failed.
+func (r *xattrInheritRouter) send(connPool *util.ConnectPool, req *proto.InheritXAttrRequest) (err error) {
+	for _, refresh := range []bool{false, true} {
+		var view *proto.MetaPartitionView
+		if view, err = r.partition(req.VolName, req.Inode, refresh); err != nil {
+			continue
+		}
+		req.PartitionId = view.PartitionID
+		if err = sendInheritXAttr(connPool, view, req); err == nil {
+			return
+		}
+	}
+	return
+}
+
+// sendInheritXAttr sends the request to the leader of the partition, or to a member which proxies
+// it to the leader if the leader is unknown.
+func sendInheritXAttr(connPool *util.ConnectPool, view *proto.MetaPartitionView, req *proto.InheritXAttrRequest) (err error) {
+	addr := view.LeaderAddr
+	if addr == "" {
+		if len(view.Members) == 0 {
+			return fmt.Errorf("no member of meta partition %v", view.PartitionID)
+		}
+		addr = view.Members[0]
+	}
+
+	p := proto.NewPacketReqID()
+	p.Opcode = proto.OpMetaInheritXAttr
+	p.PartitionID = view.PartitionID
+	if err = p.MarshalData(req); err != nil {
+		return
+	}
+	reqID := p.ReqID
+
+	var conn *net.TCPConn
+	if conn, err = connPool.GetConnect(addr); err != nil {
+		return
+	}
+	defer func() {
+		connPool.PutConnect(conn, err != nil)
+	}()
+	if err = p.WriteToConn(conn); err != nil {
+		return
+	}
+	if err = p.ReadFromConn(conn, proto.ReadDeadlineTime); err != nil {
+		return
+	}
+	if p.ReqID != reqID || p.Opcode != proto.OpMetaInheritXAttr {
+		return fmt.Errorf("send and received packet mismatch: req(%v) resp(%v_%v)", reqID, p.ReqID, p.Opcode)
+	}
+	if p.ResultCode != proto.OpOk {
+		return fmt.Errorf("%v: %v", p.GetResultMsg(), string(p.Data))
+	}
+	return
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+func TestInheritedXAttrs(t *testing.T) {
+	require.Nil(t, inheritedXAttrs(nil, true))
+
+	parent := NewExtend(1)
+	parent.Put([]byte("user.owner"), []byte("alice"), 0)
+	require.Nil(t, inheritedXAttrs(parent, false))
+
+	parent.Put([]byte(proto.XAttrDefaultPrefix+"project"), []byte("7"), 0)
+	parent.Put([]byte(proto.XAttrDefaultPrefix), []byte("invalid"), 0)
+	require.Equal(t, map[string]string{"user.project": "7"}, inheritedXAttrs(parent, false))
+	require.Equal(t, map[string]string{
+		"user.project":                       "7",
+		proto.XAttrDefaultPrefix + "project": "7",
+	}, inheritedXAttrs(parent, true))
+
+	parent.Put([]byte(proto.XAttrInheritPolicy), []byte(proto.XAttrInheritRecursive), 0)
+	require.Equal(t, map[string]string{
+		"user.project":                       "7",
+		proto.XAttrDefaultPrefix + "project": "7",
+		proto.XAttrInheritPolicy:             proto.XAttrInheritRecursive,
+	}, inheritedXAttrs(parent, true))
+
+	parent.Put([]byte(proto.XAttrInheritPolicy), []byte(proto.XAttrInheritChildren), 0)
+	require.Equal(t, map[string]string{"user.project": "7"}, inheritedXAttrs(parent, true))
+}
+
+func TestCreateDentryInheritXAttrs(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mp := mockPartitionRaftForQuotaTest(mockCtrl)
+	mp.config.Start = 1
+	mp.config.End = 100
+
+	getXAttrs := func(ino uint64) map[string]string {
+		attrs := make(map[string]string)
+		if item := mp.extendTree.Get(NewExtend(ino)); item != nil {
+			item.(*Extend).Range(func(key, value []byte) bool {
+				attrs[string(key)] = string(value)
+				return true
+			})
+		}
+		return attrs
+	}
+	createDentry := func(parentID, ino uint64, mode uint32, name string) {
+		p := &Packet{}
+		req := &CreateDentryReq{ParentID: parentID, Inode: ino, Mode: mode, Name: name}
+		require.NoError(t, mp.CreateDentry(req, p, "127.0.0.1"))
+		require.Equal(t, proto.OpOk, p.ResultCode)
+	}
+
+	for _, ino := range []*Inode{NewInode(1, DirModeType), NewInode(2, DirModeType), NewInode(3, 0), NewInode(4, 0), NewInode(5, 0)} {
+		mp.inodeTree.ReplaceOrInsert(ino, true)
+	}
+	mp.fsmSetXAttr(&Extend{inode: 1, dataMap: map[string][]byte{
+		proto.XAttrDefaultPrefix + "project": []byte("7"),
+	}})
+
+	// the subdirectory inherits the defaults, and its children inherit them in turn
+	createDentry(1, 2, DirModeType, "dir")
+	require.Equal(t, map[string]string{
+		"user.project":                       "7",
+		proto.XAttrDefaultPrefix + "project": "7",
+	}, getXAttrs(2))
+	createDentry(2, 3, 0, "file")
+	require.Equal(t, map[string]string{"user.project": "7"}, getXAttrs(3))
+
+	// the xattrs of the child are not overridden
+	mp.fsmSetXAttr(&Extend{inode: 4, dataMap: map[string][]byte{"user.project": []byte("8")}})
+	createDentry(1, 4, 0, "file")
+	require.Equal(t, map[string]string{"user.project": "8"}, getXAttrs(4))
+
+	// the inode renamed or linked from elsewhere inherits nothing
+	mp.inodeTree.CopyGet(NewInode(5, 0)).(*Inode).CreateTime = time.Now().Unix() - xattrInheritFreshSec - 1
+	createDentry(1, 5, 0, "old")
+	require.Empty(t, getXAttrs(5))
+}
@@ -63,6 +63,7 @@ var (
 		// bucket write
 		proto.OSSPutObjectAction:               PermissionWrite,
 		proto.OSSPostObjectAction:              PermissionWrite,
+		proto.OSSAppendObjectAction:            PermissionWrite,
 		proto.OSSCopyObjectAction:              PermissionWrite,
 		proto.OSSCreateMultipartUploadAction:   PermissionWrite,
 		proto.OSSUploadPartAction:              PermissionWrite,
@@ -29,6 +29,7 @@ const (
 	ContextKeyRequestAction = "ctx_request_action"
 	ContextKeyStatusCode    = "status_code"
 	ContextKeyErrorMessage  = "error_message"
+	ContextKeyErrorCode     = "error_code"
 	ContextKeyBucket        = "bucket"
 	ContextKeyObject        = "object"
 	ContextKeyUid           = "uid"
@@ -72,3 +73,11 @@ func SetResponseErrorMessage(r *http.Request, message string) {
 func getResponseErrorMessage(r *http.Request) string {
 	return mux.Vars(r)[ContextKeyErrorMessage]
 }
+
+func SetResponseErrorCode(r *http.Request, code string) {
+	mux.Vars(r)[ContextKeyErrorCode] = code
+}
+
+func getResponseErrorCode(r *http.Request) string {
+	return mux.Vars(r)[ContextKeyErrorCode]
+}
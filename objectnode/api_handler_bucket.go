@@ -22,6 +22,8 @@ import (
 	"io"
 	"net/http"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/cubefs/cubefs/proto"
@@ -123,6 +125,9 @@ func (o *ObjectNode) createBucketHandler(w http.ResponseWriter, r *http.Request)
 	if err = o.mc.AdminAPI().CreateDefaultVolume(bucket, userInfo.UserID); err != nil {
 		log.LogErrorf("createBucketHandler: create bucket fail: requestID(%v) volume(%v) accessKey(%v) err(%v)",
 			GetRequestID(r), bucket, param.AccessKey(), err)
+		if strings.Contains(err.Error(), proto.ErrUserCapacityQuotaExceeded.Error()) {
+			errorCode = DiskQuotaExceeded
+		}
 		return
 	}
 
@@ -233,35 +238,58 @@ func (o *ObjectNode) listBucketsHandler(w http.ResponseWriter, r *http.Request)
 	}
 	defer rateLimit.ReleaseLimitResource(userInfo.UserID, param.apiName)
 
+	// get options
+	prefix := r.URL.Query().Get(ParamPrefix)
+	contToken := r.URL.Query().Get(ParamContToken)
+	region := r.URL.Query().Get(ParamBucketRegion)
+	maxBuckets := r.URL.Query().Get(ParamMaxBuckets)
+
+	// list all buckets if max-buckets is not specified
+	maxBucketsInt := 0
+	if maxBuckets != "" {
+		if maxBucketsInt, err = strconv.Atoi(maxBuckets); err != nil || maxBucketsInt <= 0 || maxBucketsInt > MaxBuckets {
+			log.LogErrorf("listBucketsHandler: invalid max buckets: requestID(%v) maxBuckets(%v) err(%v)",
+				GetRequestID(r), maxBuckets, err)
+			err = nil
+			errorCode = InvalidArgument
+			return
+		}
+	}
+
 	type bucket struct {
 		XMLName      xml.Name `xml:"Bucket"`
 		CreationDate string   `xml:"CreationDate"`
 		Name         string   `xml:"Name"`
+		BucketRegion string   `xml:"BucketRegion"`
 	}
 
 	type listBucketsOutput struct {
-		XMLName xml.Name `xml:"ListAllMyBucketsResult"`
-		Owner   Owner    `xml:"Owner"`
-		Buckets []bucket `xml:"Buckets>Bucket"`
+		XMLName           xml.Name `xml:"ListAllMyBucketsResult"`
+		Owner             Owner    `xml:"Owner"`
+		Buckets           []bucket `xml:"Buckets>Bucket"`
+		Prefix            string   `xml:"Prefix,omitempty"`
+		ContinuationToken string   `xml:"ContinuationToken,omitempty"`
 	}
 
 	var output listBucketsOutput
-	authVos := userInfo.Policy.AuthorizedVols
-	ownVols := userInfo.Policy.OwnVols
-	for vol := range authVos {
-		ownVols = append(ownVols, vol)
-	}
-	for _, ownVol := range ownVols {
-		var vol *Volume
-		if vol, err = o.getVol(ownVol); err != nil {
-			log.LogErrorf("listBucketsHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
-				GetRequestID(r), ownVol, err)
-			continue
+	output.Prefix = prefix
+	if region == "" || region == o.region {
+		var names []string
+		names, output.ContinuationToken = pageBucketNames(userBucketNames(userInfo), prefix, contToken, maxBucketsInt)
+		for _, name := range names {
+			var vol *Volume
+			if vol, err = o.getVol(name); err != nil {
+				log.LogErrorf("listBucketsHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+					GetRequestID(r), name, err)
+				continue
+			}
+			output.Buckets = append(output.Buckets, bucket{
+				Name:         name,
+				CreationDate: formatTimeISO(vol.CreateTime()),
+				BucketRegion: o.region,
+			})
 		}
-		output.Buckets = append(output.Buckets, bucket{
-			Name:         ownVol,
-			CreationDate: formatTimeISO(vol.CreateTime()),
-		})
+		err = nil
 	}
 	output.Owner = Owner{DisplayName: userInfo.UserID, Id: userInfo.UserID}
 
@@ -275,6 +303,42 @@ func (o *ObjectNode) listBucketsHandler(w http.ResponseWriter, r *http.Request)
 	writeSuccessResponseXML(w, response)
 }
 
+// userBucketNames returns the deduplicated names of buckets owned by or authorized to the user.
+func userBucketNames(userInfo *proto.UserInfo) []string {
+	names := make([]string, 0, len(userInfo.Policy.OwnVols)+len(userInfo.Policy.AuthorizedVols))
+	seen := make(map[string]struct{}, cap(names))
+	for _, vol := range userInfo.Policy.OwnVols {
+		if _, ok := seen[vol]; !ok {
+			seen[vol] = struct{}{}
+			names = append(names, vol)
+		}
+	}
+	for vol := range userInfo.Policy.AuthorizedVols {
+		if _, ok := seen[vol]; !ok {
+			seen[vol] = struct{}{}
+			names = append(names, vol)
+		}
+	}
+	return names
+}
+
+// pageBucketNames sorts the bucket names and returns one page with the prefix after the token,
+// next is the token of the next page which is empty if there are no more buckets.
+func pageBucketNames(names []string, prefix, token string, maxBuckets int) (page []string, next string) {
+	sort.Strings(names)
+	for _, name := range names {
+		if !strings.HasPrefix(name, prefix) || (token != "" && name <= token) {
+			continue
+		}
+		if maxBuckets > 0 && len(page) == maxBuckets {
+			next = page[len(page)-1]
+			return
+		}
+		page = append(page, name)
+	}
+	return
+}
+
 // Get bucket location
 // API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketLocation.html
 func (o *ObjectNode) getBucketLocationHandler(w http.ResponseWriter, r *http.Request) {
@@ -585,6 +649,132 @@ func (o *ObjectNode) getObjectLockConfigurationHandler(w http.ResponseWriter, r
 	writeSuccessResponseXML(w, data)
 }
 
+// Put Bucket Encryption
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketEncryption.html
+func (o *ObjectNode) putBucketEncryptionHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("putBucketEncryptionHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+	var body []byte
+	if body, err = io.ReadAll(io.LimitReader(r.Body, MaxEncryptionConfigSize+1)); err != nil {
+		log.LogErrorf("putBucketEncryptionHandler: read request body fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	if len(body) > MaxEncryptionConfigSize {
+		errorCode = EntityTooLarge
+		return
+	}
+	var config *ServerSideEncryptionConfiguration
+	if config, errorCode = parseEncryptionConfig(body); errorCode != nil {
+		log.LogErrorf("putBucketEncryptionHandler: parse encryption config fail: requestID(%v) volume(%v) config(%v) err(%v)",
+			GetRequestID(r), vol.Name(), string(body), errorCode)
+		return
+	}
+	if body, err = json.Marshal(config); err != nil {
+		log.LogErrorf("putBucketEncryptionHandler: json.Marshal encryption config fail: requestID(%v) volume(%v) config(%v) err(%v)",
+			GetRequestID(r), vol.Name(), config, err)
+		return
+	}
+	if err = storeBucketEncryption(body, vol); err != nil {
+		log.LogErrorf("putBucketEncryptionHandler: store encryption config fail: requestID(%v) volume(%v) config(%v) err(%v)",
+			GetRequestID(r), vol.Name(), string(body), err)
+		return
+	}
+	vol.metaLoader.storeEncryption(config)
+}
+
+// Get Bucket Encryption
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketEncryption.html
+func (o *ObjectNode) getBucketEncryptionHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getBucketEncryptionHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+
+	var config *ServerSideEncryptionConfiguration
+	if config, err = vol.metaLoader.loadEncryption(); err != nil {
+		log.LogErrorf("getBucketEncryptionHandler: load encryption fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	if config == nil || len(config.Rules) == 0 {
+		errorCode = NoSuchEncryptionConfiguration
+		return
+	}
+	var data []byte
+	if data, err = MarshalXMLEntity(config); err != nil {
+		log.LogErrorf("getBucketEncryptionHandler: xml marshal fail: requestID(%v) volume(%v) config(%+v) err(%v)",
+			GetRequestID(r), vol.Name(), config, err)
+		return
+	}
+
+	writeSuccessResponseXML(w, data)
+}
+
+// Delete Bucket Encryption
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketEncryption.html
+func (o *ObjectNode) deleteBucketEncryptionHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("deleteBucketEncryptionHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+	if err = deleteBucketEncryption(vol); err != nil {
+		log.LogErrorf("deleteBucketEncryptionHandler: delete bucket encryption fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	vol.metaLoader.storeEncryption(nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (o *ObjectNode) getUserInfoByAccessKeyV2(accessKey string) (userInfo *proto.UserInfo, err error) {
 	userInfo, err = o.userStore.LoadUser(accessKey)
 	if err == proto.ErrUserNotExists || err == proto.ErrAccessKeyNotExists || err == proto.ErrParamError {
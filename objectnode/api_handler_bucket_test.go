@@ -0,0 +1,52 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+func TestUserBucketNames(t *testing.T) {
+	userInfo := &proto.UserInfo{Policy: &proto.UserPolicy{
+		OwnVols:        []string{"b1", "b2"},
+		AuthorizedVols: map[string][]string{"b2": {}, "b3": {}},
+	}}
+	names := userBucketNames(userInfo)
+	require.ElementsMatch(t, []string{"b1", "b2", "b3"}, names)
+	require.Equal(t, []string{"b1", "b2"}, userInfo.Policy.OwnVols)
+}
+
+func TestPageBucketNames(t *testing.T) {
+	names := []string{"b4", "a1", "b2", "b1", "b3"}
+
+	page, next := pageBucketNames(names, "", "", 0)
+	require.Equal(t, []string{"a1", "b1", "b2", "b3", "b4"}, page)
+	require.Equal(t, "", next)
+
+	page, next = pageBucketNames(names, "b", "", 2)
+	require.Equal(t, []string{"b1", "b2"}, page)
+	require.Equal(t, "b2", next)
+	page, next = pageBucketNames(names, "b", next, 2)
+	require.Equal(t, []string{"b3", "b4"}, page)
+	require.Equal(t, "", next)
+
+	page, next = pageBucketNames(names, "c", "", 2)
+	require.Empty(t, page)
+	require.Equal(t, "", next)
+}
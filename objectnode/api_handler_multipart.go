@@ -116,6 +116,17 @@ func (o *ObjectNode) createMultipleUploadHandler(w http.ResponseWriter, r *http.
 			GetRequestID(r), acl, err)
 		return
 	}
+	// Check server side encryption, the default encryption of bucket is applied if not specified
+	var encryption *ServerSideEncryptionConfiguration
+	if encryption, err = vol.metaLoader.loadEncryption(); err != nil {
+		log.LogErrorf("createMultipleUploadHandler: load volume encryption: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+	var sse *ServerSideEncryption
+	if sse, errorCode = parseSSE(r.Header, encryption); errorCode != nil {
+		return
+	}
 	opt := &PutFileOption{
 		MIMEType:     contentType,
 		Disposition:  contentDisposition,
@@ -124,6 +135,7 @@ func (o *ObjectNode) createMultipleUploadHandler(w http.ResponseWriter, r *http.
 		CacheControl: cacheControl,
 		Expires:      expires,
 		ACL:          acl,
+		SSE:          sse,
 	}
 
 	var uploadID string
@@ -145,6 +157,7 @@ func (o *ObjectNode) createMultipleUploadHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	setSSEHeaders(w, sse)
 	writeSuccessResponseXML(w, response)
 }
 
@@ -361,6 +374,10 @@ func (o *ObjectNode) uploadPartCopyHandler(w http.ResponseWriter, r *http.Reques
 	if errorCode != nil {
 		return
 	}
+	if srcFileInfo.Archived() {
+		errorCode = InvalidObjectState
+		return
+	}
 
 	// step4: extract range params
 	copyRange := r.Header.Get(XAmzCopySourceRange)
@@ -382,7 +399,7 @@ func (o *ObjectNode) uploadPartCopyHandler(w http.ResponseWriter, r *http.Reques
 	}
 	reader, writer := io.Pipe()
 	go func() {
-		err = srcVol.readFile(srcFileInfo.Inode, size, srcObject, writer, fb, cl)
+		err = srcVol.readFile(srcFileInfo.Inode, size, srcObject, srcFileInfo.StorageClass, writer, fb, cl)
 		if err != nil {
 			log.LogErrorf("uploadPartCopyHandler: read srcObj err(%v): requestId(%v) srcVol(%v) path(%v)",
 				err, GetRequestID(r), srcBucket, srcObject)
@@ -786,6 +803,7 @@ func (o *ObjectNode) completeMultipartUploadHandler(w http.ResponseWriter, r *ht
 			GetRequestID(r), completeResult, ierr)
 	}
 
+	setSSEHeaders(w, parseSSEFromXAttrs(multipartInfo.Extend))
 	writeSuccessResponseXML(w, response)
 }
 
@@ -15,6 +15,7 @@
 package objectnode
 
 import (
+	"crypto/md5"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/xml"
@@ -82,8 +83,8 @@ func (o *ObjectNode) getObjectHandler(w http.ResponseWriter, r *http.Request) {
 		isRangeRead bool
 		rangeLower  uint64
 		rangeUpper  uint64
+		isPartRead  bool
 		partSize    uint64
-		partCount   uint64
 	)
 	rangeOpt := strings.TrimSpace(r.Header.Get(Range))
 	if len(rangeOpt) > 0 {
@@ -140,18 +141,10 @@ func (o *ObjectNode) getObjectHandler(w http.ResponseWriter, r *http.Request) {
 		isRangeRead = true
 	}
 
-	responseCacheControl := r.URL.Query().Get(ParamResponseCacheControl)
-	if len(responseCacheControl) > 0 && !ValidateCacheControl(responseCacheControl) {
-		errorCode = InvalidCacheArgument
-		return
-	}
-	responseExpires := r.URL.Query().Get(ParamResponseExpires)
-	if len(responseExpires) > 0 && !ValidateCacheExpires(responseExpires) {
-		errorCode = InvalidCacheArgument
+	overrides, errorCode := parseResponseOverrides(r, param.AccessKey())
+	if errorCode != nil {
 		return
 	}
-	responseContentType := r.URL.Query().Get(ParamResponseContentType)
-	responseContentDisposition := r.URL.Query().Get(ParamResponseContentDisposition)
 
 	// get object meta
 	start := time.Now()
@@ -171,6 +164,12 @@ func (o *ObjectNode) getObjectHandler(w http.ResponseWriter, r *http.Request) {
 	if errorCode != nil {
 		return
 	}
+	if fileInfo.Archived() {
+		log.LogWarnf("getObjectHandler: object is archived: requestId(%v) volume(%v) path(%v) storageClass(%v)",
+			GetRequestID(r), vol.Name(), param.Object(), fileInfo.StorageClass)
+		errorCode = InvalidObjectState
+		return
+	}
 
 	// validate and fix range
 	if isRangeRead && rangeUpper > uint64(fileInfo.Size)-1 {
@@ -196,59 +195,36 @@ func (o *ObjectNode) getObjectHandler(w http.ResponseWriter, r *http.Request) {
 	// set response header for GetObject
 	w.Header().Set(AcceptRanges, ValueAcceptRanges)
 	w.Header().Set(LastModified, formatTimeRFC1123(fileInfo.ModifyTime))
-	if len(responseContentType) > 0 {
-		w.Header().Set(ContentType, responseContentType)
-	} else if len(fileInfo.MIMEType) > 0 {
-		w.Header().Set(ContentType, fileInfo.MIMEType)
-	} else {
-		w.Header().Set(ContentType, ValueContentTypeStream)
-	}
-	if len(responseContentDisposition) > 0 {
-		w.Header().Set(ContentDisposition, responseContentDisposition)
-	} else if len(fileInfo.Disposition) > 0 {
-		w.Header().Set(ContentDisposition, fileInfo.Disposition)
-	}
-	if len(responseCacheControl) > 0 {
-		w.Header().Set(CacheControl, responseCacheControl)
-	} else if len(fileInfo.CacheControl) > 0 {
-		w.Header().Set(CacheControl, fileInfo.CacheControl)
-	}
-	if len(responseExpires) > 0 {
-		w.Header().Set(Expires, responseExpires)
-	} else if len(fileInfo.Expires) > 0 {
-		w.Header().Set(Expires, fileInfo.Expires)
-	}
+	setObjectResponseHeaders(w, fileInfo, overrides)
 	if len(fileInfo.RetainUntilDate) > 0 {
 		w.Header().Set(XAmzObjectLockMode, ComplianceMode)
 		w.Header().Set(XAmzObjectLockRetainUntilDate, fileInfo.RetainUntilDate)
 	}
+	setStorageClassHeader(w, fileInfo)
+	setObjectTypeHeaders(w, fileInfo)
+	setSSEHeaders(w, fileInfo.SSE)
 
 	// check request is whether contain param : partNumber
-	partNumber := r.URL.Query().Get(ParamPartNumber)
-	if len(partNumber) > 0 && fileInfo.Size >= MinParallelDownloadFileSize {
-		partNumberInt, err := strconv.ParseUint(partNumber, 10, 64)
-		if err != nil {
-			log.LogErrorf("getObjectHandler: parse partNumber(%v) fail: requestID(%v) volume(%v) path(%v) err(%v)",
-				partNumber, GetRequestID(r), param.Bucket(), param.Object(), err)
-			errorCode = InvalidArgument
+	if partNumber := r.URL.Query().Get(ParamPartNumber); len(partNumber) > 0 {
+		var partNumberInt uint64
+		if partNumberInt, errorCode = parsePartNumber(partNumber, isRangeRead); errorCode != nil {
+			log.LogErrorf("getObjectHandler: invalid partNumber(%v): requestID(%v) volume(%v) path(%v) rangeOpt(%v)",
+				partNumber, GetRequestID(r), param.Bucket(), param.Object(), rangeOpt)
 			return
 		}
-		partSize, partCount, rangeLower, rangeUpper = parsePartInfo(partNumberInt, uint64(fileInfo.Size))
-		log.LogDebugf("getObjectHandler: partNumber(%v) fileSize(%v) parsed: partSize(%d) partCount(%d) rangeLower(%d) rangeUpper(%d)",
-			partNumberInt, fileInfo.Size, partSize, partCount, rangeLower, rangeUpper)
-		if partNumberInt > partCount {
+		parts := objectParts(fileInfo, xattr)
+		if partNumberInt > uint64(len(parts)) {
 			log.LogErrorf("getObjectHandler: partNumber(%d) > partCount(%d): requestID(%v) volume(%v) path(%v)",
-				partNumberInt, partCount, GetRequestID(r), param.Bucket(), param.Object())
-			errorCode = NoSuchKey
+				partNumberInt, len(parts), GetRequestID(r), param.Bucket(), param.Object())
+			errorCode = PartNumberNotSatisfiable
 			return
 		}
-		// Header : Accept-Range, Content-Length, Content-Range, ETag, x-amz-mp-parts-count
-		w.Header().Set(ContentLength, strconv.Itoa(int(partSize)))
-		w.Header().Set(ContentRange, fmt.Sprintf("bytes %d-%d/%d", rangeLower, rangeUpper, fileInfo.Size))
-		w.Header().Set(XAmzMpPartsCount, strconv.Itoa(int(partCount)))
-		if len(fileInfo.ETag) > 0 && !strings.Contains(fileInfo.ETag, "-") {
-			w.Header()[ETag] = []string{fmt.Sprintf("%s-%d", fileInfo.ETag, partCount)}
-		}
+		isPartRead = true
+		partSize = parts[partNumberInt-1]
+		rangeLower, rangeUpper = parts.Range(partNumberInt)
+		log.LogDebugf("getObjectHandler: partNumber(%v) fileSize(%v) parsed: partSize(%d) partCount(%d) rangeLower(%d) rangeUpper(%d)",
+			partNumberInt, fileInfo.Size, partSize, len(parts), rangeLower, rangeUpper)
+		setPartResponseHeaders(w, fileInfo, parts, partSize, rangeLower, rangeUpper)
 	} else {
 		w.Header().Set(ContentLength, strconv.FormatUint(contentLength, 10))
 		if len(fileInfo.ETag) > 0 {
@@ -275,9 +251,11 @@ func (o *ObjectNode) getObjectHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		return
 	}
-	if isRangeRead || len(partNumber) > 0 {
+	if isRangeRead || isPartRead && partSize > 0 {
 		size = rangeUpper - rangeLower + 1
 		w.WriteHeader(http.StatusPartialContent)
+	} else if isPartRead {
+		size = 0
 	}
 
 	// Flow Control
@@ -290,7 +268,7 @@ func (o *ObjectNode) getObjectHandler(w http.ResponseWriter, r *http.Request) {
 
 	// read file
 	start = time.Now()
-	err = vol.readFile(fileInfo.Inode, fileSize, param.Object(), writer, offset, size)
+	err = vol.readFileCached(fileInfo.Inode, fileInfo.Generation, fileSize, param.Object(), fileInfo.StorageClass, writer, offset, size)
 	span.AppendTrackLog("file.r", start, err)
 	if err != nil {
 		log.LogErrorf("getObjectHandler: read file fail: requestID(%v) volume(%v) path(%v) offset(%v) size(%v) err(%v)",
@@ -389,6 +367,11 @@ func (o *ObjectNode) headObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	overrides, errorCode := parseResponseOverrides(r, param.AccessKey())
+	if errorCode != nil {
+		return
+	}
+
 	// QPS and Concurrency Limit
 	rateLimit := o.AcquireRateLimiter()
 	if err = rateLimit.AcquireLimitResource(vol.owner, param.apiName); err != nil {
@@ -398,7 +381,7 @@ func (o *ObjectNode) headObjectHandler(w http.ResponseWriter, r *http.Request) {
 
 	// get object meta
 	start := time.Now()
-	fileInfo, _, err := vol.ObjectMeta(param.Object())
+	fileInfo, xattr, err := vol.ObjectMeta(param.Object())
 	span.AppendTrackLog("meta.r", start, err)
 	if err != nil {
 		log.LogErrorf("headObjectHandler: get file meta fail: requestId(%v) volume(%v) path(%v) err(%v)",
@@ -472,47 +455,34 @@ func (o *ObjectNode) headObjectHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set(AcceptRanges, ValueAcceptRanges)
 	w.Header().Set(LastModified, formatTimeRFC1123(fileInfo.ModifyTime))
 	w.Header().Set(ContentMD5, EmptyContentMD5String)
-	if len(fileInfo.MIMEType) > 0 {
-		w.Header().Set(ContentType, fileInfo.MIMEType)
-	} else {
-		w.Header().Set(ContentType, ValueContentTypeStream)
-	}
-	if len(fileInfo.Disposition) > 0 {
-		w.Header().Set(ContentDisposition, fileInfo.Disposition)
-	}
-	if len(fileInfo.CacheControl) > 0 {
-		w.Header().Set(CacheControl, fileInfo.CacheControl)
-	}
-	if len(fileInfo.Expires) > 0 {
-		w.Header().Set(Expires, fileInfo.Expires)
-	}
+	setObjectResponseHeaders(w, fileInfo, overrides)
 	if len(fileInfo.RetainUntilDate) > 0 {
 		w.Header().Set(XAmzObjectLockMode, ComplianceMode)
 		w.Header().Set(XAmzObjectLockRetainUntilDate, fileInfo.RetainUntilDate)
 	}
+	setStorageClassHeader(w, fileInfo)
+	setObjectTypeHeaders(w, fileInfo)
+	setSSEHeaders(w, fileInfo.SSE)
 
 	// check request is whether contain param : partNumber
 	partNumber := r.URL.Query().Get(ParamPartNumber)
-	if len(partNumber) > 0 && fileInfo.Size >= MinParallelDownloadFileSize {
-		partNumberInt, err := strconv.ParseUint(partNumber, 10, 64)
-		if err != nil {
-			log.LogErrorf("headObjectHandler: parse param partNumber(%s) fail: requestID(%v) err(%v)", partNumber, GetRequestID(r), err)
-			errorCode = InvalidArgument
+	if len(partNumber) > 0 {
+		partNumberInt, errCode := parsePartNumber(partNumber, false)
+		if errCode != nil {
+			log.LogErrorf("headObjectHandler: invalid param partNumber(%s): requestID(%v)", partNumber, GetRequestID(r))
+			errorCode = errCode
 			return
 		}
-		partSize, partCount, rangeLower, rangeUpper := parsePartInfo(partNumberInt, uint64(fileInfo.Size))
-		log.LogDebugf("headObjectHandler: parsed partSize(%d), partCount(%d), rangeLower(%d), rangeUpper(%d)", partSize, partCount, rangeLower, rangeUpper)
-		if partNumberInt > partCount {
-			log.LogErrorf("headObjectHandler: param partNumber(%d) is more then partCount(%d): requestID(%v)", partNumberInt, partCount, GetRequestID(r))
-			errorCode = NoSuchKey
+		parts := objectParts(fileInfo, xattr)
+		if partNumberInt > uint64(len(parts)) {
+			log.LogErrorf("headObjectHandler: param partNumber(%d) is more then partCount(%d): requestID(%v)", partNumberInt, len(parts), GetRequestID(r))
+			errorCode = PartNumberNotSatisfiable
 			return
 		}
-		w.Header().Set(ContentLength, strconv.Itoa(int(partSize)))
-		w.Header().Set(ContentRange, fmt.Sprintf("bytes %d-%d/%d", rangeLower, rangeUpper, fileInfo.Size))
-		w.Header().Set(XAmzMpPartsCount, strconv.Itoa(int(partCount)))
-		if len(fileInfo.ETag) > 0 && !strings.Contains(fileInfo.ETag, "-") {
-			w.Header()[ETag] = []string{fmt.Sprintf("%s-%d", fileInfo.ETag, partCount)}
-		}
+		partSize := parts[partNumberInt-1]
+		rangeLower, rangeUpper := parts.Range(partNumberInt)
+		log.LogDebugf("headObjectHandler: parsed partSize(%d), partCount(%d), rangeLower(%d), rangeUpper(%d)", partSize, len(parts), rangeLower, rangeUpper)
+		setPartResponseHeaders(w, fileInfo, parts, partSize, rangeLower, rangeUpper)
 	} else {
 		w.Header().Set(ContentLength, strconv.Itoa(int(fileInfo.Size)))
 		if len(fileInfo.ETag) > 0 {
@@ -758,6 +728,13 @@ func (o *ObjectNode) copyObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var storageClass string
+	if raw := r.Header.Get(XAmzStorageClass); raw != "" {
+		if storageClass, errorCode = vol.parseStorageClass(raw); errorCode != nil {
+			return
+		}
+	}
+
 	// metadata directive, direct object node use source file metadata or recreate metadata for target file
 	metadataDirective := r.Header.Get(XAmzMetadataDirective)
 	// metadata directive default value is COPY
@@ -820,6 +797,10 @@ func (o *ObjectNode) copyObjectHandler(w http.ResponseWriter, r *http.Request) {
 		errorCode = EntityTooLarge
 		return
 	}
+	if fileInfo.Archived() {
+		errorCode = InvalidObjectState
+		return
+	}
 
 	// get header
 	copyMatch := r.Header.Get(XAmzCopySourceIfMatch)
@@ -875,6 +856,18 @@ func (o *ObjectNode) copyObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the encryption of target is specified by the request or the bucket of target
+	var encryption *ServerSideEncryptionConfiguration
+	if encryption, err = vol.metaLoader.loadEncryption(); err != nil {
+		log.LogErrorf("copyObjectHandler: load volume encryption: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+	var sse *ServerSideEncryption
+	if sse, errorCode = parseSSE(r.Header, encryption); errorCode != nil {
+		return
+	}
+
 	// parse user-defined metadata
 	metadata := ParseUserDefinedMetadata(r.Header)
 
@@ -887,6 +880,8 @@ func (o *ObjectNode) copyObjectHandler(w http.ResponseWriter, r *http.Request) {
 		Expires:      expires,
 		ACL:          acl,
 		ObjectLock:   objetLock,
+		StorageClass: storageClass,
+		SSE:          sse,
 	}
 	start = time.Now()
 	fsFileInfo, err := vol.CopyFile(sourceVol, sourceObject, param.Object(), metadataDirective, opt)
@@ -919,6 +914,7 @@ func (o *ObjectNode) copyObjectHandler(w http.ResponseWriter, r *http.Request) {
 			GetRequestID(r), copyResult, ierr)
 	}
 
+	setSSEHeaders(w, sse)
 	writeSuccessResponseXML(w, response)
 }
 
@@ -1026,7 +1022,7 @@ func (o *ObjectNode) getBucketV1Handler(w http.ResponseWriter, r *http.Request)
 			LastModified: formatTimeISO(file.ModifyTime),
 			ETag:         wrapUnescapedQuot(file.ETag),
 			Size:         int(file.Size),
-			StorageClass: StorageClassStandard,
+			StorageClass: file.StorageClass,
 			Owner:        bucketOwner,
 		}
 		contents = append(contents, content)
@@ -1192,7 +1188,7 @@ func (o *ObjectNode) getBucketV2Handler(w http.ResponseWriter, r *http.Request)
 				LastModified: formatTimeISO(file.ModifyTime),
 				ETag:         wrapUnescapedQuot(file.ETag),
 				Size:         int(file.Size),
-				StorageClass: StorageClassStandard,
+				StorageClass: file.StorageClass,
 				Owner:        bucketOwner,
 			}
 			contents = append(contents, content)
@@ -1262,17 +1258,28 @@ func (o *ObjectNode) putObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get request MD5, if request MD5 is not empty, compute and verify it.
+	// Get request MD5, if request MD5 is not empty, compute and verify it before the object is applied.
 	requestMD5 := r.Header.Get(ContentMD5)
 	if requestMD5 != "" {
 		decoded, err := base64.StdEncoding.DecodeString(requestMD5)
-		if err != nil {
+		if err != nil || len(decoded) != md5.Size {
 			errorCode = InvalidDigest
 			return
 		}
 		requestMD5 = hex.EncodeToString(decoded)
 	}
 
+	// Checking precondition: If-None-Match, only * is supported to create the object if absent
+	// Reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutObject.html#API_PutObject_RequestSyntax
+	createOnly := false
+	if noneMatch := r.Header.Get(IfNoneMatch); noneMatch != "" {
+		if strings.TrimSpace(noneMatch) != "*" {
+			errorCode = UnsupportedOperation
+			return
+		}
+		createOnly = true
+	}
+
 	// ObjectLock  Config
 	objetLock, err := vol.metaLoader.loadObjectLock()
 	if err != nil {
@@ -1351,6 +1358,22 @@ func (o *ObjectNode) putObjectHandler(w http.ResponseWriter, r *http.Request) {
 		errorCode = InvalidCacheArgument
 		return
 	}
+	// Get request header : x-amz-storage-class
+	storageClass, errorCode := vol.parseStorageClass(r.Header.Get(XAmzStorageClass))
+	if errorCode != nil {
+		return
+	}
+	// Get request header : x-amz-server-side-encryption, the default encryption of bucket is applied if not specified
+	var encryption *ServerSideEncryptionConfiguration
+	if encryption, err = vol.metaLoader.loadEncryption(); err != nil {
+		log.LogErrorf("putObjectHandler: load volume encryption: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+	sse, errorCode := parseSSE(r.Header, encryption)
+	if errorCode != nil {
+		return
+	}
 	// Checking user-defined metadata
 	metadata := ParseUserDefinedMetadata(r.Header)
 	// Audit file write
@@ -1375,6 +1398,10 @@ func (o *ObjectNode) putObjectHandler(w http.ResponseWriter, r *http.Request) {
 		Expires:      expires,
 		ACL:          acl,
 		ObjectLock:   objetLock,
+		StorageClass: storageClass,
+		SSE:          sse,
+		ContentMD5:   requestMD5,
+		CreateOnly:   createOnly,
 	}
 	start := time.Now()
 	fsFileInfo, err := vol.PutObject(param.Object(), reader, opt)
@@ -1386,16 +1413,166 @@ func (o *ObjectNode) putObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// check content MD5
-	if requestMD5 != "" && requestMD5 != fsFileInfo.ETag {
-		log.LogErrorf("putObjectHandler: MD5 validate fail: requestID(%v) volume(%v) path(%v) requestMD5(%v) serverMD5(%v)",
-			GetRequestID(r), vol.Name(), param.Object(), requestMD5, fsFileInfo.ETag)
-		errorCode = BadDigest
+	// set response header
+	w.Header()[ETag] = []string{wrapUnescapedQuot(fsFileInfo.ETag)}
+	setSSEHeaders(w, sse)
+}
+
+// Append object
+// API reference: https://www.alibabacloud.com/help/en/oss/developer-reference/appendobject
+func (o *ObjectNode) appendObjectHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+
+	span := trace.SpanFromContextSafe(r.Context())
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	if param.Object() == "" {
+		errorCode = InvalidKey
+		return
+	}
+	if len(param.Object()) > MaxKeyLength {
+		errorCode = KeyTooLong
+		return
+	}
+	position, errorCode := parseAppendPosition(r.URL.Query().Get(ParamPosition))
+	if errorCode != nil {
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("appendObjectHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+
+	// the appendable object is modified after created, which is against the retention
+	objetLock, err := vol.metaLoader.loadObjectLock()
+	if err != nil {
+		log.LogErrorf("appendObjectHandler: load volume objetLock: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+	if objetLock != nil && objetLock.ToRetention() != nil {
+		errorCode = UnsupportedOperation
+		return
+	}
+
+	// QPS and Concurrency Limit
+	rateLimit := o.AcquireRateLimiter()
+	if err = rateLimit.AcquireLimitResource(vol.owner, param.apiName); err != nil {
+		return
+	}
+	defer rateLimit.ReleaseLimitResource(vol.owner, param.apiName)
+
+	var userInfo *proto.UserInfo
+	if userInfo, err = o.getUserInfoByAccessKeyV2(param.AccessKey()); err != nil {
+		log.LogErrorf("appendObjectHandler: get user info fail: requestID(%v) volume(%v) accessKey(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), param.AccessKey(), err)
+		return
+	}
+
+	// Check ACL
+	acl, err := ParseACL(r, userInfo.UserID, false, vol.GetOwner() != userInfo.UserID)
+	if err != nil {
+		log.LogErrorf("appendObjectHandler: parse acl fail: requestID(%v) volume(%v) path(%v) acl(%+v) err(%v)",
+			GetRequestID(r), vol.Name(), param.Object(), acl, err)
+		return
+	}
+
+	// Verify ContentLength
+	length := GetContentLength(r)
+	if length > SinglePutLimit {
+		errorCode = EntityTooLarge
+		return
+	}
+	if length < 0 {
+		errorCode = MissingContentLength
+		return
+	}
+
+	// The following headers apply to the creation of the object by the first append only
+	contentType := r.Header.Get(ContentType)
+	contentDisposition := r.Header.Get(ContentDisposition)
+	cacheControl := r.Header.Get(CacheControl)
+	if len(cacheControl) > 0 && !ValidateCacheControl(cacheControl) {
+		errorCode = InvalidCacheArgument
+		return
+	}
+	expires := r.Header.Get(Expires)
+	if len(expires) > 0 && !ValidateCacheExpires(expires) {
+		errorCode = InvalidCacheArgument
+		return
+	}
+	// the appendable object is written in place, which is not supported by blobstore
+	storageClass, errorCode := vol.parseStorageClass(r.Header.Get(XAmzStorageClass))
+	if errorCode != nil {
+		return
+	}
+	if vol.storedInEbs(storageClass) {
+		errorCode = UnsupportedOperation
+		return
+	}
+	var encryption *ServerSideEncryptionConfiguration
+	if encryption, err = vol.metaLoader.loadEncryption(); err != nil {
+		log.LogErrorf("appendObjectHandler: load volume encryption: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+	sse, errorCode := parseSSE(r.Header, encryption)
+	if errorCode != nil {
+		return
+	}
+	metadata := ParseUserDefinedMetadata(r.Header)
+	// Audit file write
+	log.LogInfof("Audit: append object: requestID(%v) remote(%v) volume(%v) path(%v) position(%v)",
+		GetRequestID(r), getRequestIP(r), vol.Name(), param.Object(), position)
+
+	// Flow Control
+	var reader io.Reader
+	if length > DefaultFlowLimitSize {
+		reader = rateLimit.GetReader(vol.owner, param.apiName, r.Body)
+	} else {
+		reader = r.Body
+	}
+
+	opt := &PutFileOption{
+		MIMEType:     contentType,
+		Disposition:  contentDisposition,
+		Metadata:     metadata,
+		CacheControl: cacheControl,
+		Expires:      expires,
+		ACL:          acl,
+		StorageClass: storageClass,
+		SSE:          sse,
+	}
+	start := time.Now()
+	fsFileInfo, next, err := vol.AppendObject(param.Object(), reader, position, opt)
+	span.AppendTrackLog("file.w", start, err)
+	if err == PositionNotEqualToLength {
+		// the client appends again from the length of the object
+		w.Header().Set(XOssNextAppendPosition, strconv.FormatUint(next, 10))
+		return
+	}
+	if err != nil {
+		log.LogErrorf("appendObjectHandler: append object fail: requestId(%v) volume(%v) path(%v) position(%v) remote(%v) err(%v)",
+			GetRequestID(r), vol.Name(), param.Object(), position, getRequestIP(r), err)
+		err = handlePutObjectErr(err)
 		return
 	}
 
 	// set response header
 	w.Header()[ETag] = []string{wrapUnescapedQuot(fsFileInfo.ETag)}
+	setObjectTypeHeaders(w, fsFileInfo)
 }
 
 // Post object
@@ -1593,6 +1770,24 @@ func (o *ObjectNode) postObjectHandler(w http.ResponseWriter, r *http.Request) {
 		reader = f
 	}
 
+	// the encryption specified by the form takes precedence over the default encryption of bucket
+	var encryption *ServerSideEncryptionConfiguration
+	if encryption, err = vol.metaLoader.loadEncryption(); err != nil {
+		log.LogErrorf("postObjectHandler: load volume encryption: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+	sseHeader := make(http.Header)
+	for _, key := range []string{XAmzServerSideEncryption, XAmzServerSideEncryptionKMSKeyID, XAmzServerSideEncryptionBucketKeyEnabled} {
+		if value := formReq.MultipartFormValue(key); value != "" {
+			sseHeader.Set(key, value)
+		}
+	}
+	var sse *ServerSideEncryption
+	if sse, errorCode = parseSSE(sseHeader, encryption); errorCode != nil {
+		return
+	}
+
 	// put object
 	putOpt := &PutFileOption{
 		MIMEType:     contentType,
@@ -1603,6 +1798,7 @@ func (o *ObjectNode) postObjectHandler(w http.ResponseWriter, r *http.Request) {
 		Expires:      expires,
 		ACL:          aclInfo,
 		ObjectLock:   objetLock,
+		SSE:          sse,
 	}
 	start := time.Now()
 	fsFileInfo, err := vol.PutObject(key, reader, putOpt)
@@ -1625,6 +1821,7 @@ func (o *ObjectNode) postObjectHandler(w http.ResponseWriter, r *http.Request) {
 	// set response header
 	etag := wrapUnescapedQuot(fsFileInfo.ETag)
 	w.Header()[ETag] = []string{etag}
+	setSSEHeaders(w, sse)
 
 	// return response depending on success_action_xxx parameter
 	if successRedirectURL != nil {
@@ -2233,31 +2430,121 @@ func (o *ObjectNode) getObjectRetentionHandler(w http.ResponseWriter, r *http.Re
 	writeSuccessResponseXML(w, b)
 }
 
-func parsePartInfo(partNumber uint64, fileSize uint64) (uint64, uint64, uint64, uint64) {
-	var partSize uint64
-	var partCount uint64
-	var rangeLower uint64
-	var rangeUpper uint64
-	// partSize, partCount, rangeLower, rangeUpper
-	partSizeConst := ParallelDownloadPartSize
-	partCount = fileSize / uint64(partSizeConst)
-	lastSize := fileSize % uint64(partSizeConst)
-	if lastSize > 0 {
-		partCount += 1
-	}
-
-	rangeLower = uint64(partSizeConst) * (partNumber - 1)
-	if lastSize > 0 && partNumber == partCount {
-		partSize = lastSize
-		rangeUpper = fileSize - 1
+// Restore object
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_RestoreObject.html
+func (o *ObjectNode) restoreObjectHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+
+	span := trace.SpanFromContextSafe(r.Context())
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	if param.Object() == "" {
+		errorCode = InvalidKey
+		return
+	}
+
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("restoreObjectHandler: load volume fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		return
+	}
+
+	// QPS and Concurrency Limit
+	rateLimit := o.AcquireRateLimiter()
+	if err = rateLimit.AcquireLimitResource(vol.owner, param.apiName); err != nil {
+		return
+	}
+	defer rateLimit.ReleaseLimitResource(vol.owner, param.apiName)
+
+	_, errorCode = VerifyContentLength(r, BodyLimit)
+	if errorCode != nil {
+		return
+	}
+	var requestBody []byte
+	if requestBody, err = io.ReadAll(r.Body); err != nil {
+		log.LogErrorf("restoreObjectHandler: read request body data fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InvalidArgument
+		return
+	}
+	restore := new(RestoreRequest)
+	if err = xml.Unmarshal(requestBody, restore); err != nil {
+		log.LogWarnf("restoreObjectHandler: decode request body fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = MalformedXML
+		return
+	}
+	if err = restore.Validate(); err != nil {
+		log.LogErrorf("restoreObjectHandler: restore request validate fail: requestID(%v) restore(%v) err(%v)",
+			GetRequestID(r), restore, err)
+		errorCode = InvalidArgument
+		return
+	}
+
+	start := time.Now()
+	fileInfo, _, err := vol.ObjectMeta(param.Object())
+	span.AppendTrackLog("meta.r", start, err)
+	if err != nil {
+		log.LogErrorf("restoreObjectHandler: get file meta fail: requestId(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), vol.Name(), param.Object(), err)
+		if err == syscall.ENOENT {
+			errorCode = NoSuchKey
+		}
+		return
+	}
+	if !isColdStorageClass(fileInfo.StorageClass) {
+		errorCode = InvalidObjectState
+		return
+	}
+
+	// the expiry of the restored copy is extended if it's already restored
+	restored := !fileInfo.Archived()
+	expiry := time.Now().Add(time.Duration(restore.Days) * 24 * time.Hour)
+	start = time.Now()
+	err = vol.SetXAttr(param.Object(), XAttrKeyOSSRestore, []byte(formatRestoreExpiry(expiry)), false)
+	span.AppendTrackLog("xattr.w", start, err)
+	if err != nil {
+		log.LogErrorf("restoreObjectHandler: set restore xattr fail: requestID(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), vol.Name(), param.Object(), err)
+		if err == syscall.ENOENT {
+			errorCode = NoSuchKey
+		}
+		return
+	}
+	log.LogInfof("Audit: restore object: requestID(%v) volume(%v) path(%v) days(%v) restored(%v)",
+		GetRequestID(r), vol.Name(), param.Object(), restore.Days, restored)
+
+	if restored {
+		w.WriteHeader(http.StatusOK)
 	} else {
-		partSize = uint64(partSizeConst)
-		rangeUpper = (partSize * partNumber) - 1
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// setPartResponseHeaders sets the headers of the part of GetObject and HeadObject with partNumber.
+func setPartResponseHeaders(w http.ResponseWriter, fileInfo *FSFileInfo, parts ObjectParts, partSize, rangeLower, rangeUpper uint64) {
+	w.Header().Set(ContentLength, strconv.FormatUint(partSize, 10))
+	if partSize > 0 {
+		w.Header().Set(ContentRange, fmt.Sprintf("bytes %d-%d/%d", rangeLower, rangeUpper, fileInfo.Size))
 	}
-	if partNumber > partCount {
-		return 0, 0, 0, 0
+	w.Header().Set(XAmzMpPartsCount, strconv.Itoa(len(parts)))
+	if len(fileInfo.ETag) > 0 && !strings.Contains(fileInfo.ETag, "-") && len(parts) > 1 {
+		// the object is split into parts by the object node
+		w.Header()[ETag] = []string{wrapUnescapedQuot(fmt.Sprintf("%s-%d", fileInfo.ETag, len(parts)))}
+	} else if len(fileInfo.ETag) > 0 {
+		w.Header()[ETag] = []string{wrapUnescapedQuot(fileInfo.ETag)}
 	}
-	return partSize, partCount, rangeLower, rangeUpper
 }
 
 func GetContentLength(r *http.Request) int64 {
@@ -0,0 +1,186 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/util/exporter"
+)
+
+const (
+	MetricS3Requests          = "s3_requests"
+	MetricS3Errors            = "s3_errors"
+	MetricS3Latency           = "s3_latency"
+	MetricS3TrafficBytes      = "s3_traffic_bytes"
+	MetricS3TopAccessKeyBytes = "s3_top_access_key_bytes"
+	MetricS3TopAccessKeyReqs  = "s3_top_access_key_requests"
+
+	defaultAPIMetricsTopN        = 10
+	defaultAPIMetricsIntervalSec = 60
+	// the access keys beyond it in an interval are not ranked, to bound the memory
+	maxRankedAccessKeys = 100000
+)
+
+// APIMetricsConfig is the configuration of the top-N access keys by traffic, which are exported
+// as gauges at the end of every interval. The per-API metrics are always exported.
+type APIMetricsConfig struct {
+	TopN        int `json:"topN"`
+	IntervalSec int `json:"intervalSec"`
+}
+
+type accessKeyStat struct {
+	bytes    int64
+	requests int64
+}
+
+type apiMetrics struct {
+	conf APIMetricsConfig
+
+	mu   sync.Mutex
+	keys map[string]*accessKeyStat
+
+	topBytes    *exporter.GaugeVec
+	topRequests *exporter.GaugeVec
+	stopC       chan struct{}
+	stopOnce    sync.Once
+}
+
+func newAPIMetrics(conf APIMetricsConfig) *apiMetrics {
+	if conf.TopN <= 0 {
+		conf.TopN = defaultAPIMetricsTopN
+	}
+	if conf.IntervalSec <= 0 {
+		conf.IntervalSec = defaultAPIMetricsIntervalSec
+	}
+	return &apiMetrics{
+		conf:        conf,
+		keys:        make(map[string]*accessKeyStat),
+		topBytes:    exporter.NewGaugeVec(MetricS3TopAccessKeyBytes, "", []string{"accessKey"}),
+		topRequests: exporter.NewGaugeVec(MetricS3TopAccessKeyReqs, "", []string{"accessKey"}),
+		stopC:       make(chan struct{}),
+	}
+}
+
+func statusClass(statusCode int) string {
+	switch {
+	case statusCode >= 500:
+		return "5xx"
+	case statusCode >= 400:
+		return "4xx"
+	case statusCode >= 300:
+		return "3xx"
+	default:
+		return "2xx"
+	}
+}
+
+// record exports the metrics of a finished request, the error code is empty if it succeeds,
+// and the latency is measured since the time point is created.
+func (m *apiMetrics) record(api, accessKey string, statusCode int, errorCode string, in, out int64, tp *exporter.TimePoint) {
+	code := strconv.Itoa(statusCode)
+	exporter.NewCounter(MetricS3Requests).AddWithLabels(1, map[string]string{"api": api, "code": code})
+	if statusCode >= 400 {
+		exporter.NewCounter(MetricS3Errors).AddWithLabels(1, map[string]string{
+			"api": api, "class": statusClass(statusCode), "code": code, "errorCode": errorCode,
+		})
+	}
+	tp.SetWithLabels(map[string]string{"api": api})
+	if in > 0 {
+		exporter.NewCounter(MetricS3TrafficBytes).AddWithLabels(in, map[string]string{"api": api, "direction": "in"})
+	}
+	if out > 0 {
+		exporter.NewCounter(MetricS3TrafficBytes).AddWithLabels(out, map[string]string{"api": api, "direction": "out"})
+	}
+
+	if accessKey == "" {
+		return
+	}
+	m.mu.Lock()
+	stat, ok := m.keys[accessKey]
+	if !ok && len(m.keys) < maxRankedAccessKeys {
+		stat = new(accessKeyStat)
+		m.keys[accessKey] = stat
+	}
+	if stat != nil {
+		stat.bytes += in + out
+		stat.requests++
+	}
+	m.mu.Unlock()
+}
+
+type rankedAccessKey struct {
+	accessKey string
+	accessKeyStat
+}
+
+// rank returns the top-N access keys by traffic in the interval, and starts a new interval.
+func (m *apiMetrics) rank() []rankedAccessKey {
+	m.mu.Lock()
+	keys := m.keys
+	m.keys = make(map[string]*accessKeyStat)
+	m.mu.Unlock()
+
+	ranked := make([]rankedAccessKey, 0, len(keys))
+	for ak, stat := range keys {
+		ranked = append(ranked, rankedAccessKey{accessKey: ak, accessKeyStat: *stat})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].bytes != ranked[j].bytes {
+			return ranked[i].bytes > ranked[j].bytes
+		}
+		return ranked[i].requests > ranked[j].requests
+	})
+	if len(ranked) > m.conf.TopN {
+		ranked = ranked[:m.conf.TopN]
+	}
+	return ranked
+}
+
+func (m *apiMetrics) exportTopAccessKeys() {
+	ranked := m.rank()
+	if m.topBytes == nil || m.topRequests == nil {
+		return
+	}
+	// the access keys out of the top-N are removed
+	m.topBytes.Reset()
+	m.topRequests.Reset()
+	for _, key := range ranked {
+		m.topBytes.SetWithLabelValues(float64(key.bytes), key.accessKey)
+		m.topRequests.SetWithLabelValues(float64(key.requests), key.accessKey)
+	}
+}
+
+func (m *apiMetrics) start() {
+	go func() {
+		ticker := time.NewTicker(time.Duration(m.conf.IntervalSec) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stopC:
+				return
+			case <-ticker.C:
+				m.exportTopAccessKeys()
+			}
+		}
+	}()
+}
+
+func (m *apiMetrics) stop() {
+	m.stopOnce.Do(func() { close(m.stopC) })
+}
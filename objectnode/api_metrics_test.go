@@ -0,0 +1,64 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/util/exporter"
+)
+
+func TestAPIMetricsStatusClass(t *testing.T) {
+	require.Equal(t, "2xx", statusClass(200))
+	require.Equal(t, "3xx", statusClass(304))
+	require.Equal(t, "4xx", statusClass(404))
+	require.Equal(t, "5xx", statusClass(503))
+}
+
+func TestAPIMetricsTopAccessKeys(t *testing.T) {
+	m := newAPIMetrics(APIMetricsConfig{TopN: 2})
+	require.Equal(t, defaultAPIMetricsIntervalSec, m.conf.IntervalSec)
+
+	record := func(ak string, in, out int64) {
+		m.record("PutObject", ak, 200, "", in, out, exporter.NewTP(MetricS3Latency))
+	}
+	record("ak1", 100, 0)
+	record("ak2", 0, 300)
+	record("ak3", 10, 10)
+	record("ak1", 100, 50)
+	record("", 1000, 1000)
+
+	ranked := m.rank()
+	require.Len(t, ranked, 2)
+	require.Equal(t, "ak2", ranked[0].accessKey)
+	require.EqualValues(t, 300, ranked[0].bytes)
+	require.Equal(t, "ak1", ranked[1].accessKey)
+	require.EqualValues(t, 250, ranked[1].bytes)
+	require.EqualValues(t, 2, ranked[1].requests)
+
+	// a new interval starts after ranking
+	require.Len(t, m.rank(), 0)
+
+	for i := 0; i < maxRankedAccessKeys+10; i++ {
+		m.keys[fmt.Sprintf("ak%d", i)] = new(accessKeyStat)
+	}
+	record("new", 1, 1)
+	require.NotContains(t, m.keys, "new")
+	m.stop()
+	m.stop()
+}
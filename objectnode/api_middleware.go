@@ -125,6 +125,7 @@ func (o *ObjectNode) traceMiddleware(next http.Handler) http.Handler {
 
 		startTime := time.Now()
 		metric := exporter.NewTPCnt(fmt.Sprintf("action_%v", action.Name()))
+		latency := exporter.NewTP(MetricS3Latency)
 		defer func() {
 			metric.Set(err)
 		}()
@@ -148,6 +149,11 @@ func (o *ObjectNode) traceMiddleware(next http.Handler) http.Handler {
 			exporter.NewTPCnt(fmt.Sprintf("failed_%v", statusCode)).Set(nil)
 			exporter.Warning(generateWarnDetail(r, getResponseErrorMessage(r)))
 		}
+		if o.apiMetrics != nil {
+			stater := w.(*ResponseStater)
+			o.apiMetrics.record(action.Name(), ParseRequestParam(r).AccessKey(), stater.StatusCode,
+				getResponseErrorCode(r), r.ContentLength, stater.Written, latency)
+		}
 
 		log.LogInfof("traceMiddleware: end with action(%v) requestID(%v) host(%v) method(%v) url(%v) "+
 			"reqHeader(%v) remote(%v) respHeader(%v) statusCode(%v) errorMsg(%v) cost(%v)",
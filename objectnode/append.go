@@ -0,0 +1,264 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// the shards of the locks of the appends, the objects of the same hash share a lock
+const appendLockShards = 64
+
+// appendLocks serializes the appends of the same object within the objectnode, so that the
+// appends doomed to conflict do not write their data. The position is checked by the metanode
+// atomically with appending the data, which fences the appends from the other objectnodes.
+type appendLocks [appendLockShards]sync.Mutex
+
+func (l *appendLocks) get(path string) *sync.Mutex {
+	// fnv-1a without the allocation of hash.Hash
+	h := uint32(2166136261)
+	for i := 0; i < len(path); i++ {
+		h ^= uint32(path[i])
+		h *= 16777619
+	}
+	return &l[h%appendLockShards]
+}
+
+// parseAppendPosition parses the required position of AppendObject.
+func parseAppendPosition(raw string) (uint64, *ErrorCode) {
+	if raw == "" {
+		return 0, InvalidArgument
+	}
+	position, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, InvalidArgument
+	}
+	return position, nil
+}
+
+// setObjectTypeHeaders reports the appendable object and the position of the next append.
+func setObjectTypeHeaders(w http.ResponseWriter, info *FSFileInfo) {
+	if !info.Appendable {
+		return
+	}
+	w.Header().Set(XOssObjectType, ObjectTypeAppendable)
+	w.Header().Set(XOssNextAppendPosition, strconv.FormatInt(info.Size, 10))
+}
+
+// AppendObject appends the data of the reader to the appendable object at the position, which
+// must be the length of the object, and returns the position of the next append. The appendable
+// object is created by the append at position 0 if the key does not exist, the options apply to
+// the creation only. The normal objects are never appended.
+func (v *Volume) AppendObject(path string, reader io.Reader, position uint64, opt *PutFileOption) (fsInfo *FSFileInfo, next uint64, err error) {
+	defer func() {
+		// Audit behavior
+		log.LogInfof("Audit: AppendObject: volume(%v) path(%v) position(%v) next(%v) err(%v)",
+			v.name, path, position, next, err)
+	}()
+	if strings.HasSuffix(path, pathSep) || (opt != nil && opt.MIMEType == ValueContentTypeDirectory) {
+		err = syscall.EINVAL
+		return
+	}
+
+	mu := v.appendLocks.get(path)
+	mu.Lock()
+	defer mu.Unlock()
+
+	var (
+		ino  uint64
+		name string
+		mode os.FileMode
+	)
+	if _, ino, name, mode, err = v.recursiveLookupTarget(path, true); err == syscall.ENOENT {
+		if position != 0 {
+			err = PositionNotEqualToLength
+			return
+		}
+		return v.createAppendable(path, reader, opt)
+	}
+	if err != nil {
+		log.LogErrorf("AppendObject: lookup path fail: volume(%v) path(%v) err(%v)", v.name, path, err)
+		return
+	}
+	if mode.IsDir() {
+		err = syscall.EINVAL
+		return
+	}
+
+	var xattr *proto.XAttrInfo
+	if xattr, err = v.mw.XAttrGet_ll(ino, XAttrKeyOSSAppendable); err != nil {
+		log.LogErrorf("AppendObject: get xattr fail: volume(%v) path(%v) inode(%v) err(%v)",
+			v.name, path, ino, err)
+		return
+	}
+	var info *proto.InodeInfo
+	if info, err = v.mw.InodeGet_ll(ino); err != nil {
+		log.LogErrorf("AppendObject: inode get fail: volume(%v) path(%v) inode(%v) err(%v)",
+			v.name, path, ino, err)
+		return
+	}
+	next = info.Size
+	if len(xattr.Get(XAttrKeyOSSAppendable)) == 0 {
+		log.LogWarnf("AppendObject: the object is not appendable: volume(%v) path(%v) name(%v) inode(%v)",
+			v.name, path, name, ino)
+		err = ObjectNotAppendable
+		return
+	}
+	if position != info.Size {
+		err = PositionNotEqualToLength
+		return
+	}
+
+	if err = v.appendData(path, ino, position, reader); err == syscall.EINVAL {
+		// appended by the other objectnode meanwhile
+		err = PositionNotEqualToLength
+		if info, getErr := v.mw.InodeGet_ll(ino); getErr == nil {
+			next = info.Size
+		}
+		return
+	}
+	if err != nil {
+		return
+	}
+	if info, err = v.mw.InodeGet_ll(ino); err != nil {
+		log.LogErrorf("AppendObject: get final inode fail: volume(%v) path(%v) inode(%v) err(%v)",
+			v.name, path, ino, err)
+		return
+	}
+	next = info.Size
+
+	// the md5 of the whole object is unknown, so the etag is regenerated as the multipart object
+	var etagValue ETagValue
+	if etagValue, err = v.updateETag(ino, int64(info.Size), info.ModifyTime); err != nil {
+		log.LogErrorf("AppendObject: update etag fail: volume(%v) path(%v) inode(%v) err(%v)",
+			v.name, path, ino, err)
+		return
+	}
+	deleteAttrCache(ino, v.name)
+
+	fsInfo = &FSFileInfo{
+		Path:       path,
+		Size:       int64(info.Size),
+		Mode:       os.FileMode(info.Mode),
+		CreateTime: info.CreateTime,
+		ModifyTime: info.ModifyTime,
+		ETag:       etagValue.ETag(),
+		Inode:      info.Inode,
+		Appendable: true,
+	}
+	return
+}
+
+// appendData writes the data into a temporary inode, then moves its extents to the end of the
+// inode if the size of the inode is still the position, EINVAL is returned otherwise.
+func (v *Volume) appendData(path string, ino, position uint64, reader io.Reader) (err error) {
+	var tempInfo *proto.InodeInfo
+	if tempInfo, err = v.mw.InodeCreate_ll(0, DefaultFileMode, 0, 0, nil, make([]uint64, 0), path); err != nil {
+		log.LogErrorf("AppendObject: meta create temp inode fail: volume(%v) path(%v) err(%v)", v.name, path, err)
+		return
+	}
+	tempIno := tempInfo.Inode
+	defer func() {
+		if err != nil {
+			// the data is not referenced by the object, release it with the temporary inode
+			_, _ = v.mw.InodeUnlink_ll(tempIno, path)
+			_ = v.mw.Evict(tempIno, path)
+			return
+		}
+		// the data is referenced by the object, delete the temporary inode only
+		if deleteErr := v.mw.InodeDelete_ll(tempIno, path); deleteErr != nil {
+			log.LogWarnf("AppendObject: delete temp inode fail: volume(%v) path(%v) inode(%v) err(%v)",
+				v.name, path, tempIno, deleteErr)
+		}
+	}()
+
+	if err = v.ec.OpenStream(tempIno); err != nil {
+		log.LogErrorf("AppendObject: open stream fail: volume(%v) path(%v) inode(%v) err(%v)",
+			v.name, path, tempIno, err)
+		return
+	}
+	defer func() {
+		if closeErr := v.ec.CloseStream(tempIno); closeErr != nil {
+			log.LogErrorf("AppendObject: close stream fail: volume(%v) inode(%v) err(%v)",
+				v.name, tempIno, closeErr)
+		}
+	}()
+	if _, err = v.streamWriteAt(tempIno, 0, reader, nil); err != nil {
+		log.LogErrorf("AppendObject: stream write fail: volume(%v) path(%v) inode(%v) err(%v)",
+			v.name, path, tempIno, err)
+		return
+	}
+	if err = v.ec.Flush(tempIno); err != nil {
+		log.LogErrorf("AppendObject: data flush inode fail: volume(%v) path(%v) inode(%v) err(%v)",
+			v.name, path, tempIno, err)
+		return
+	}
+
+	var eks []proto.ExtentKey
+	if _, _, eks, err = v.mw.GetExtents(tempIno); err != nil {
+		log.LogErrorf("AppendObject: meta get extents fail: volume(%v) path(%v) inode(%v) err(%v)",
+			v.name, path, tempIno, err)
+		return
+	}
+	if len(eks) == 0 {
+		// nothing to append, but the position is checked still
+		var info *proto.InodeInfo
+		if info, err = v.mw.InodeGet_ll(ino); err == nil && info.Size != position {
+			err = syscall.EINVAL
+		}
+		return
+	}
+	for i := range eks {
+		eks[i].FileOffset += position
+	}
+	if err = v.mw.AppendExtentKeysIfSize(ino, position, eks); err != nil && err != syscall.EINVAL {
+		log.LogErrorf("AppendObject: meta append extent keys fail: volume(%v) path(%v) inode(%v) position(%v) err(%v)",
+			v.name, path, ino, position, err)
+	}
+	return
+}
+
+// createAppendable creates the appendable object by the first append. The object created by
+// the other objectnode meanwhile is not overwritten, and the append fails with conflict.
+func (v *Volume) createAppendable(path string, reader io.Reader, opt *PutFileOption) (fsInfo *FSFileInfo, next uint64, err error) {
+	var createOpt PutFileOption
+	if opt != nil {
+		createOpt = *opt
+	}
+	createOpt.CreateOnly = true
+	createOpt.Appendable = true
+	if fsInfo, err = v.PutObject(path, reader, &createOpt); err == PreconditionFailed {
+		err = PositionNotEqualToLength
+		if info, _, metaErr := v.ObjectMeta(path); metaErr == nil {
+			next = uint64(info.Size)
+		}
+		return nil, next, err
+	}
+	if err != nil {
+		return
+	}
+	fsInfo.Appendable = true
+	next = uint64(fsInfo.Size)
+	return
+}
@@ -0,0 +1,70 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAppendPosition(t *testing.T) {
+	for raw, expected := range map[string]uint64{"0": 0, "1024": 1024} {
+		position, errorCode := parseAppendPosition(raw)
+		require.Nil(t, errorCode)
+		require.Equal(t, expected, position)
+	}
+	for _, raw := range []string{"", "-1", "abc", "1.5"} {
+		_, errorCode := parseAppendPosition(raw)
+		require.Equal(t, InvalidArgument, errorCode)
+	}
+}
+
+func TestSetObjectTypeHeaders(t *testing.T) {
+	w := httptest.NewRecorder()
+	setObjectTypeHeaders(w, &FSFileInfo{Size: 100})
+	require.Empty(t, w.Header().Get(XOssObjectType))
+	require.Empty(t, w.Header().Get(XOssNextAppendPosition))
+
+	w = httptest.NewRecorder()
+	setObjectTypeHeaders(w, &FSFileInfo{Size: 100, Appendable: true})
+	require.Equal(t, ObjectTypeAppendable, w.Header().Get(XOssObjectType))
+	require.Equal(t, "100", w.Header().Get(XOssNextAppendPosition))
+}
+
+func TestAppendLocks(t *testing.T) {
+	var locks appendLocks
+	require.Same(t, locks.get("dir/log"), locks.get("dir/log"))
+	shards := make(map[*sync.Mutex]bool, appendLockShards)
+	for i := range locks {
+		shards[&locks[i]] = false
+	}
+	for i := 0; i < 1024; i++ {
+		mu := locks.get(fmt.Sprintf("log-%d", i))
+		_, ok := shards[mu]
+		require.True(t, ok)
+		shards[mu] = true
+	}
+	used := 0
+	for _, ok := range shards {
+		if ok {
+			used++
+		}
+	}
+	require.Greater(t, used, appendLockShards/2)
+}
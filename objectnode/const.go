@@ -33,6 +33,7 @@ const (
 	ContentLength      = "Content-Length"
 	ContentRange       = "Content-Range"
 	ContentDisposition = "Content-Disposition"
+	ContentLanguage    = "Content-Language"
 	Authorization      = "Authorization"
 	AcceptRanges       = "Accept-Ranges"
 	Range              = "Range"
@@ -74,6 +75,7 @@ const (
 	XAmzMetadataDirective           = "x-amz-metadata-directive"
 	XAmzBucketRegion                = "x-amz-bucket-region"
 	XAmzStorageClass                = "x-amz-storage-class"
+	XAmzRestore                     = "x-amz-restore"
 	XAmzTaggingCount                = "x-amz-tagging-count"
 	XAmzContentSha256               = "X-Amz-Content-Sha256"
 	XAmzCredential                  = "X-Amz-Credential" // #nosec G101
@@ -86,7 +88,14 @@ const (
 	XAmzObjectLockMode              = "X-Amz-Object-Lock-Mode"
 	XAmzObjectLockRetainUntilDate   = "X-Amz-Object-Lock-Retain-Until-Date"
 
+	XAmzServerSideEncryption                 = "x-amz-server-side-encryption"
+	XAmzServerSideEncryptionKMSKeyID         = "x-amz-server-side-encryption-aws-kms-key-id"
+	XAmzServerSideEncryptionBucketKeyEnabled = "x-amz-server-side-encryption-bucket-key-enabled"
+
 	HeaderNameXAmzDecodedContentLength = "x-amz-decoded-content-length"
+
+	XOssNextAppendPosition = "x-oss-next-append-position"
+	XOssObjectType         = "x-oss-object-type"
 )
 
 const (
@@ -107,6 +116,7 @@ const (
 const (
 	ParamUploadId   = "uploadId"
 	ParamPartNumber = "partNumber"
+	ParamPosition   = "position"
 	ParamKeyMarker  = "key-marker"
 	ParamMarker     = "marker"
 	ParamPrefix     = "prefix"
@@ -116,6 +126,9 @@ const (
 	ParamStartAfter = "start-after"
 	ParamKey        = "key"
 
+	ParamMaxBuckets   = "max-buckets"
+	ParamBucketRegion = "bucket-region"
+
 	ParamMaxParts       = "max-parts"
 	ParamUploadIdMarker = "upload-id-marker"
 	ParamPartNoMarker   = "part-number-marker"
@@ -126,11 +139,14 @@ const (
 	ParamResponseCacheControl       = "response-cache-control"
 	ParamResponseContentType        = "response-content-type"
 	ParamResponseContentDisposition = "response-content-disposition"
+	ParamResponseContentLanguage    = "response-content-language"
+	ParamResponseContentEncoding    = "response-content-encoding"
 	ParamResponseExpires            = "response-expires"
 )
 
 const (
 	MaxKeys        = 1000
+	MaxBuckets     = 10000
 	MaxParts       = 1000
 	MaxUploads     = 1000
 	SinglePutLimit = 5 * 1 << 30 // 5G
@@ -139,6 +155,13 @@ const (
 
 const (
 	StorageClassStandard = "STANDARD"
+	StorageClassCold     = "COLD"
+	StorageClassGlacier  = "GLACIER"
+)
+
+const (
+	ObjectTypeNormal     = "Normal"
+	ObjectTypeAppendable = "Appendable"
 )
 
 // XAttr keys for ObjectNode compatible feature
@@ -154,6 +177,14 @@ const (
 	XAttrKeyOSSLock         = "oss:lock"
 	XAttrKeyOSSCacheControl = "oss:cache"
 	XAttrKeyOSSExpires      = "oss:expires"
+	XAttrKeyOSSStorageClass = "oss:storage-class"
+	XAttrKeyOSSRestore      = "oss:restore"
+	XAttrKeyOSSEncryption   = "oss:encryption"
+	XAttrKeyOSSSSE          = "oss:sse"
+	XAttrKeyOSSSSEKMSKeyID  = "oss:sse-kms-key-id"
+	XAttrKeyOSSSSEBucketKey = "oss:sse-bucket-key"
+	XAttrKeyOSSParts        = "oss:parts"
+	XAttrKeyOSSAppendable   = "oss:appendable"
 
 	// Deprecated
 	XAttrKeyOSSETagDeprecated = "oss:tag"
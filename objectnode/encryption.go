@@ -0,0 +1,191 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// https://docs.aws.amazon.com/AmazonS3/latest/userguide/bucket-encryption.html
+//
+// The objects of volume are shared with the posix clients, so the object node doesn't transform
+// the data of objects. The server side encryption of objects is recorded in the extended attributes
+// of inode, and returned to the clients, the data is protected at rest by the storage of cluster.
+
+const (
+	SSEAlgorithmAES256 = "AES256"
+	SSEAlgorithmKMS    = "aws:kms"
+
+	MaxEncryptionConfigSize = 1 << 12 // 4KB
+)
+
+type ServerSideEncryptionConfiguration struct {
+	XMLNS   string                      `xml:"xmlns,attr,omitempty" json:"-"`
+	XMLName xml.Name                    `xml:"ServerSideEncryptionConfiguration" json:"-"`
+	Rules   []*ServerSideEncryptionRule `xml:"Rule" json:"rules"`
+}
+
+type ServerSideEncryptionRule struct {
+	ApplyServerSideEncryptionByDefault *ServerSideEncryptionByDefault `xml:"ApplyServerSideEncryptionByDefault,omitempty" json:"apply_sse_by_default,omitempty"`
+	BucketKeyEnabled                   bool                           `xml:"BucketKeyEnabled,omitempty" json:"bucket_key_enabled,omitempty"`
+}
+
+type ServerSideEncryptionByDefault struct {
+	SSEAlgorithm   string `xml:"SSEAlgorithm" json:"sse_algorithm"`
+	KMSMasterKeyID string `xml:"KMSMasterKeyID,omitempty" json:"kms_master_key_id,omitempty"`
+}
+
+func (c *ServerSideEncryptionConfiguration) validate() *ErrorCode {
+	if len(c.Rules) != 1 || c.Rules[0] == nil || c.Rules[0].ApplyServerSideEncryptionByDefault == nil {
+		return MalformedXML
+	}
+	byDefault := c.Rules[0].ApplyServerSideEncryptionByDefault
+	switch byDefault.SSEAlgorithm {
+	case SSEAlgorithmAES256:
+		if byDefault.KMSMasterKeyID != "" {
+			return InvalidEncryptionKeyID
+		}
+	case SSEAlgorithmKMS:
+	default:
+		return InvalidEncryptionAlgorithm
+	}
+	return nil
+}
+
+func parseEncryptionConfig(data []byte) (config *ServerSideEncryptionConfiguration, errorCode *ErrorCode) {
+	config = &ServerSideEncryptionConfiguration{}
+	if err := xml.Unmarshal(data, config); err != nil {
+		return nil, MalformedXML
+	}
+	if errorCode = config.validate(); errorCode != nil {
+		return nil, errorCode
+	}
+	return config, nil
+}
+
+// defaultSSE returns the server side encryption applied to the new objects of bucket
+func (c *ServerSideEncryptionConfiguration) defaultSSE() *ServerSideEncryption {
+	if c == nil || len(c.Rules) == 0 || c.Rules[0].ApplyServerSideEncryptionByDefault == nil {
+		return nil
+	}
+	rule := c.Rules[0]
+	sse := &ServerSideEncryption{
+		Algorithm: rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm,
+		KMSKeyID:  rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID,
+	}
+	// the bucket key only takes effect for SSE-KMS
+	sse.BucketKeyEnabled = sse.Algorithm == SSEAlgorithmKMS && rule.BucketKeyEnabled
+	return sse
+}
+
+func storeBucketEncryption(bytes []byte, vol *Volume) (err error) {
+	return vol.store.Put(vol.name, bucketRootPath, XAttrKeyOSSEncryption, bytes)
+}
+
+func deleteBucketEncryption(vol *Volume) (err error) {
+	return vol.store.Delete(vol.name, bucketRootPath, XAttrKeyOSSEncryption)
+}
+
+// ServerSideEncryption is the server side encryption of an object.
+type ServerSideEncryption struct {
+	Algorithm        string
+	KMSKeyID         string
+	BucketKeyEnabled bool
+}
+
+// parseSSE parses the server side encryption of a new object, the headers of request take
+// precedence over the default encryption of bucket.
+func parseSSE(header http.Header, config *ServerSideEncryptionConfiguration) (sse *ServerSideEncryption, errorCode *ErrorCode) {
+	algorithm := header.Get(XAmzServerSideEncryption)
+	if algorithm == "" {
+		if header.Get(XAmzServerSideEncryptionKMSKeyID) != "" {
+			return nil, InvalidEncryptionKeyID
+		}
+		sse = config.defaultSSE()
+	} else {
+		sse = &ServerSideEncryption{Algorithm: algorithm, KMSKeyID: header.Get(XAmzServerSideEncryptionKMSKeyID)}
+		switch sse.Algorithm {
+		case SSEAlgorithmAES256:
+			if sse.KMSKeyID != "" {
+				return nil, InvalidEncryptionKeyID
+			}
+		case SSEAlgorithmKMS:
+			if def := config.defaultSSE(); def != nil && def.Algorithm == SSEAlgorithmKMS {
+				if sse.KMSKeyID == "" {
+					sse.KMSKeyID = def.KMSKeyID
+				}
+				sse.BucketKeyEnabled = def.BucketKeyEnabled
+			}
+		default:
+			return nil, InvalidEncryptionAlgorithm
+		}
+	}
+	if raw := header.Get(XAmzServerSideEncryptionBucketKeyEnabled); raw != "" {
+		enabled, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, InvalidArgument
+		}
+		if sse != nil {
+			sse.BucketKeyEnabled = enabled && sse.Algorithm == SSEAlgorithmKMS
+		}
+	}
+	return sse, nil
+}
+
+func (sse *ServerSideEncryption) setXAttrs(xattrs map[string]string) {
+	if sse == nil {
+		return
+	}
+	xattrs[XAttrKeyOSSSSE] = sse.Algorithm
+	if sse.KMSKeyID != "" {
+		xattrs[XAttrKeyOSSSSEKMSKeyID] = sse.KMSKeyID
+	}
+	if sse.BucketKeyEnabled {
+		xattrs[XAttrKeyOSSSSEBucketKey] = strconv.FormatBool(true)
+	}
+}
+
+func isSSEXAttrKey(key string) bool {
+	return strings.HasPrefix(key, XAttrKeyOSSSSE)
+}
+
+func parseSSEFromXAttrs(xattrs map[string]string) *ServerSideEncryption {
+	algorithm := xattrs[XAttrKeyOSSSSE]
+	if algorithm == "" {
+		return nil
+	}
+	bucketKey, _ := strconv.ParseBool(xattrs[XAttrKeyOSSSSEBucketKey])
+	return &ServerSideEncryption{
+		Algorithm:        algorithm,
+		KMSKeyID:         xattrs[XAttrKeyOSSSSEKMSKeyID],
+		BucketKeyEnabled: bucketKey,
+	}
+}
+
+func setSSEHeaders(w http.ResponseWriter, sse *ServerSideEncryption) {
+	if sse == nil {
+		return
+	}
+	w.Header().Set(XAmzServerSideEncryption, sse.Algorithm)
+	if sse.KMSKeyID != "" {
+		w.Header().Set(XAmzServerSideEncryptionKMSKeyID, sse.KMSKeyID)
+	}
+	if sse.BucketKeyEnabled {
+		w.Header().Set(XAmzServerSideEncryptionBucketKeyEnabled, strconv.FormatBool(true))
+	}
+}
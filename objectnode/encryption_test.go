@@ -0,0 +1,121 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEncryptionConfig(t *testing.T) {
+	config, errorCode := parseEncryptionConfig([]byte(`<ServerSideEncryptionConfiguration>
+<Rule><ApplyServerSideEncryptionByDefault><SSEAlgorithm>aws:kms</SSEAlgorithm><KMSMasterKeyID>key1</KMSMasterKeyID>
+</ApplyServerSideEncryptionByDefault><BucketKeyEnabled>true</BucketKeyEnabled></Rule>
+</ServerSideEncryptionConfiguration>`))
+	require.Nil(t, errorCode)
+	require.Equal(t, &ServerSideEncryption{Algorithm: SSEAlgorithmKMS, KMSKeyID: "key1", BucketKeyEnabled: true}, config.defaultSSE())
+
+	config, errorCode = parseEncryptionConfig([]byte(`<ServerSideEncryptionConfiguration>
+<Rule><ApplyServerSideEncryptionByDefault><SSEAlgorithm>AES256</SSEAlgorithm></ApplyServerSideEncryptionByDefault>
+<BucketKeyEnabled>true</BucketKeyEnabled></Rule></ServerSideEncryptionConfiguration>`))
+	require.Nil(t, errorCode)
+	require.Equal(t, &ServerSideEncryption{Algorithm: SSEAlgorithmAES256}, config.defaultSSE())
+
+	for _, raw := range []string{
+		`<ServerSideEncryptionConfiguration>`,
+		`<ServerSideEncryptionConfiguration></ServerSideEncryptionConfiguration>`,
+		`<ServerSideEncryptionConfiguration><Rule><BucketKeyEnabled>true</BucketKeyEnabled></Rule></ServerSideEncryptionConfiguration>`,
+	} {
+		_, errorCode = parseEncryptionConfig([]byte(raw))
+		require.Equal(t, MalformedXML, errorCode)
+	}
+	_, errorCode = parseEncryptionConfig([]byte(`<ServerSideEncryptionConfiguration><Rule><ApplyServerSideEncryptionByDefault>
+<SSEAlgorithm>DES</SSEAlgorithm></ApplyServerSideEncryptionByDefault></Rule></ServerSideEncryptionConfiguration>`))
+	require.Equal(t, InvalidEncryptionAlgorithm, errorCode)
+	_, errorCode = parseEncryptionConfig([]byte(`<ServerSideEncryptionConfiguration><Rule><ApplyServerSideEncryptionByDefault>
+<SSEAlgorithm>AES256</SSEAlgorithm><KMSMasterKeyID>key1</KMSMasterKeyID></ApplyServerSideEncryptionByDefault></Rule></ServerSideEncryptionConfiguration>`))
+	require.Equal(t, InvalidEncryptionKeyID, errorCode)
+
+	var nilConfig *ServerSideEncryptionConfiguration
+	require.Nil(t, nilConfig.defaultSSE())
+}
+
+func TestParseSSE(t *testing.T) {
+	kms := &ServerSideEncryptionConfiguration{Rules: []*ServerSideEncryptionRule{{
+		ApplyServerSideEncryptionByDefault: &ServerSideEncryptionByDefault{SSEAlgorithm: SSEAlgorithmKMS, KMSMasterKeyID: "key1"},
+		BucketKeyEnabled:                   true,
+	}}}
+	header := func(kv ...string) http.Header {
+		h := make(http.Header)
+		for i := 0; i < len(kv); i += 2 {
+			h.Set(kv[i], kv[i+1])
+		}
+		return h
+	}
+
+	sse, errorCode := parseSSE(header(), nil)
+	require.Nil(t, errorCode)
+	require.Nil(t, sse)
+
+	// bucket default
+	sse, errorCode = parseSSE(header(), kms)
+	require.Nil(t, errorCode)
+	require.Equal(t, &ServerSideEncryption{Algorithm: SSEAlgorithmKMS, KMSKeyID: "key1", BucketKeyEnabled: true}, sse)
+
+	// the request overrides the bucket default
+	sse, errorCode = parseSSE(header(XAmzServerSideEncryption, SSEAlgorithmAES256), kms)
+	require.Nil(t, errorCode)
+	require.Equal(t, &ServerSideEncryption{Algorithm: SSEAlgorithmAES256}, sse)
+	sse, errorCode = parseSSE(header(XAmzServerSideEncryption, SSEAlgorithmKMS, XAmzServerSideEncryptionKMSKeyID, "key2"), kms)
+	require.Nil(t, errorCode)
+	require.Equal(t, &ServerSideEncryption{Algorithm: SSEAlgorithmKMS, KMSKeyID: "key2", BucketKeyEnabled: true}, sse)
+	sse, errorCode = parseSSE(header(XAmzServerSideEncryptionBucketKeyEnabled, "false"), kms)
+	require.Nil(t, errorCode)
+	require.False(t, sse.BucketKeyEnabled)
+
+	_, errorCode = parseSSE(header(XAmzServerSideEncryption, "DES"), kms)
+	require.Equal(t, InvalidEncryptionAlgorithm, errorCode)
+	_, errorCode = parseSSE(header(XAmzServerSideEncryption, SSEAlgorithmAES256, XAmzServerSideEncryptionKMSKeyID, "key2"), kms)
+	require.Equal(t, InvalidEncryptionKeyID, errorCode)
+	_, errorCode = parseSSE(header(XAmzServerSideEncryptionKMSKeyID, "key2"), nil)
+	require.Equal(t, InvalidEncryptionKeyID, errorCode)
+	_, errorCode = parseSSE(header(XAmzServerSideEncryptionBucketKeyEnabled, "yes"), kms)
+	require.Equal(t, InvalidArgument, errorCode)
+}
+
+func TestSSEXAttrs(t *testing.T) {
+	xattrs := map[string]string{XAttrKeyOSSETag: "etag"}
+	var nilSSE *ServerSideEncryption
+	nilSSE.setXAttrs(xattrs)
+	require.Len(t, xattrs, 1)
+	require.Nil(t, parseSSEFromXAttrs(xattrs))
+
+	sse := &ServerSideEncryption{Algorithm: SSEAlgorithmKMS, KMSKeyID: "key1", BucketKeyEnabled: true}
+	sse.setXAttrs(xattrs)
+	require.Len(t, xattrs, 4)
+	require.Equal(t, sse, parseSSEFromXAttrs(xattrs))
+	for key := range xattrs {
+		require.Equal(t, key != XAttrKeyOSSETag, isSSEXAttrKey(key))
+	}
+
+	w := httptest.NewRecorder()
+	setSSEHeaders(w, sse)
+	require.Equal(t, SSEAlgorithmKMS, w.Header().Get(XAmzServerSideEncryption))
+	require.Equal(t, "key1", w.Header().Get(XAmzServerSideEncryptionKMSKeyID))
+	require.Equal(t, "true", w.Header().Get(XAmzServerSideEncryptionBucketKeyEnabled))
+}
@@ -28,12 +28,17 @@ type FSFileInfo struct {
 	CreateTime      time.Time
 	ETag            string
 	Inode           uint64
+	Generation      uint64 // generation of the inode, bumped by every write
 	MIMEType        string
 	Disposition     string
 	CacheControl    string
 	Expires         string
 	Metadata        map[string]string `graphql:"-"` // User-defined metadata
 	RetainUntilDate string
+	StorageClass    string
+	RestoreExpiry   time.Time // expiry of the restored copy of cold object
+	SSE             *ServerSideEncryption
+	Appendable      bool // the object is created by AppendObject and not overwritten since
 }
 
 type Prefixes []string
@@ -92,6 +92,14 @@ type PutFileOption struct {
 	CacheControl string
 	Expires      string
 	ObjectLock   *ObjectLockConfig
+	StorageClass string
+	SSE          *ServerSideEncryption
+	// the hex md5 of the content, the object is not applied if the content is not matched
+	ContentMD5 string
+	// create the object only if the key does not exist, the object is never overwritten
+	CreateOnly bool
+	// mark the object appendable, the data can be appended by AppendObject
+	Appendable bool
 }
 
 type ListFilesV1Option struct {
@@ -130,8 +138,8 @@ type ListFilesV2Result struct {
 // A high-level approach that exposes the semantics of object storage to the outside world.
 // Volume escapes high-level object storage semantics to low-level POSIX semantics.
 type Volume struct {
-	mw         *meta.MetaWrapper
-	ec         *stream.ExtentClient
+	mw         MetaWrapper
+	ec         ExtentClient
 	store      Store // Storage for ACP management
 	name       string
 	owner      string
@@ -148,6 +156,8 @@ type Volume struct {
 	closeCh   chan struct{}
 
 	onAsyncTaskError AsyncTaskErrorFunc
+
+	appendLocks appendLocks
 }
 
 func (v *Volume) GetOwner() string {
@@ -198,6 +208,12 @@ func (v *Volume) loadOSSMeta() {
 		return
 	}
 	v.metaLoader.storeObjectLock(objectlock)
+
+	var encryption *ServerSideEncryptionConfiguration
+	if encryption, err = v.loadBucketEncryption(); err != nil {
+		return
+	}
+	v.metaLoader.storeEncryption(encryption)
 	v.metaLoader.setSynced()
 }
 
@@ -277,6 +293,21 @@ func (v *Volume) loadObjectLock() (configuration *ObjectLockConfig, err error) {
 	return configuration, nil
 }
 
+func (v *Volume) loadBucketEncryption() (configuration *ServerSideEncryptionConfiguration, err error) {
+	var raw []byte
+	if raw, err = v.store.Get(v.name, bucketRootPath, XAttrKeyOSSEncryption); err != nil {
+		return
+	}
+	if len(raw) == 0 {
+		return
+	}
+	configuration = &ServerSideEncryptionConfiguration{}
+	if err = json.Unmarshal(raw, configuration); err != nil {
+		return
+	}
+	return configuration, nil
+}
+
 func (v *Volume) getInodeFromPath(path string) (inode uint64, err error) {
 	if path == "/" {
 		return volumeRootInode, nil
@@ -670,6 +701,12 @@ func (v *Volume) PutObject(path string, reader io.Reader, opt *PutFileOption) (f
 		err = syscall.EINVAL
 		return
 	}
+	if err == nil && opt != nil && opt.CreateOnly {
+		log.LogWarnf("PutObject: the object exists for create only: volume(%v) path(%v) inode(%v)",
+			v.name, path, oldInode)
+		err = PreconditionFailed
+		return
+	}
 
 	// check whether existing object is protected by object lock
 	if oldInode != 0 && opt != nil && opt.ObjectLock != nil {
@@ -712,7 +749,11 @@ func (v *Volume) PutObject(path string, reader io.Reader, opt *PutFileOption) (f
 		}
 	}()
 
-	if proto.IsCold(v.volType) {
+	var storageClass string
+	if opt != nil {
+		storageClass = opt.StorageClass
+	}
+	if v.storedInEbs(storageClass) {
 		if _, err = v.ebsWrite(invisibleTempDataInode.Inode, reader, md5Hash); err != nil {
 			log.LogErrorf("PutObject: ebs write fail: volume(%v) path(%v) inode(%v) err(%v)",
 				v.name, path, invisibleTempDataInode.Inode, err)
@@ -744,6 +785,13 @@ func (v *Volume) PutObject(path string, reader io.Reader, opt *PutFileOption) (f
 		PartNum: 0,
 		TS:      finalInode.ModifyTime,
 	}
+	// the temp inode is released, so the object of mismatched content is never visible
+	if opt != nil && opt.ContentMD5 != "" && opt.ContentMD5 != etagValue.Value {
+		log.LogErrorf("PutObject: content md5 mismatch: volume(%v) path(%v) inode(%v) requestMD5(%v) serverMD5(%v)",
+			v.name, path, invisibleTempDataInode.Inode, opt.ContentMD5, etagValue.Value)
+		err = BadDigest
+		return
+	}
 
 	attr := &AttrItem{
 		XAttrInfo: proto.XAttrInfo{
@@ -774,6 +822,15 @@ func (v *Volume) PutObject(path string, reader io.Reader, opt *PutFileOption) (f
 	if opt != nil && opt.ObjectLock != nil && opt.ObjectLock.ToRetention() != nil {
 		attr.XAttrs[XAttrKeyOSSLock] = formatRetentionDateStr(finalInode.ModifyTime, opt.ObjectLock.ToRetention())
 	}
+	if storageClass != "" && storageClass != StorageClassStandard {
+		attr.XAttrs[XAttrKeyOSSStorageClass] = storageClass
+	}
+	if opt != nil {
+		opt.SSE.setXAttrs(attr.XAttrs)
+	}
+	if opt != nil && opt.Appendable {
+		attr.XAttrs[XAttrKeyOSSAppendable] = "true"
+	}
 
 	// If user-defined metadata have been specified, use extend attributes for storage.
 	if opt != nil && len(opt.Metadata) > 0 {
@@ -803,8 +860,14 @@ func (v *Volume) PutObject(path string, reader io.Reader, opt *PutFileOption) (f
 		Inode:      finalInode.Inode,
 	}
 
-	// apply new inode to dentry
-	err = v.applyInodeToDEntry(parentId, lastPathItem.Name, invisibleTempDataInode.Inode, false, fixedPath)
+	// apply new inode to dentry, the dentry created by the other writer fails the create only
+	if opt != nil && opt.CreateOnly {
+		if err = v.applyInodeToNewDentry(parentId, lastPathItem.Name, invisibleTempDataInode.Inode, fixedPath); err == syscall.EEXIST {
+			err = PreconditionFailed
+		}
+	} else {
+		err = v.applyInodeToDEntry(parentId, lastPathItem.Name, invisibleTempDataInode.Inode, false, fixedPath)
+	}
 	if err != nil {
 		log.LogErrorf("PutObject: apply new inode to dentry fail: parentID(%v) name(%v) inode(%v) err(%v)",
 			parentId, lastPathItem.Name, invisibleTempDataInode.Inode, err)
@@ -963,8 +1026,12 @@ func (v *Volume) InitMultipart(path string, opt *PutFileOption) (multipartID str
 	if opt != nil && opt.ACL != nil {
 		extend[XAttrKeyOSSACL] = opt.ACL.Encode()
 	}
+	// The server side encryption is applied to the object when the multipart is completed.
+	if opt != nil {
+		opt.SSE.setXAttrs(extend)
+	}
 
-	if v.mw.EnableQuota {
+	if v.mw.QuotaEnabled() {
 		var parentId uint64
 		if parentId, err = v.recursiveMakeDirectory(path); err != nil {
 			log.LogErrorf("InitMultipart: recursive make dir fail: volume(%v) path(%v) multipartID(%v) err(%v)",
@@ -1274,6 +1341,11 @@ func (v *Volume) CompleteMultipart(path, multipartID string, multipartInfo *prot
 		},
 	}
 	attrs[XAttrKeyOSSETag] = etagValue.Encode()
+	objectParts := make(ObjectParts, 0, len(parts))
+	for _, part := range parts {
+		objectParts = append(objectParts, part.Size)
+	}
+	attrs[XAttrKeyOSSParts] = objectParts.Encode()
 	// set user modified system metadata, self defined metadata and tag
 	extend := multipartInfo.Extend
 	if len(extend) > 0 {
@@ -1353,10 +1425,15 @@ func (v *Volume) ebsWrite(inode uint64, reader io.Reader, h hash.Hash) (size uin
 }
 
 func (v *Volume) streamWrite(inode uint64, reader io.Reader, h hash.Hash) (size uint64, err error) {
+	return v.streamWriteAt(inode, 0, reader, h)
+}
+
+// streamWriteAt writes the data of the reader to the inode from the offset.
+func (v *Volume) streamWriteAt(inode uint64, offset int, reader io.Reader, h hash.Hash) (size uint64, err error) {
 	var (
-		buf                   = make([]byte, 2*util.BlockSize)
-		readN, writeN, offset int
-		hashBuf               = make([]byte, 2*util.BlockSize)
+		buf           = make([]byte, 2*util.BlockSize)
+		readN, writeN int
+		hashBuf       = make([]byte, 2*util.BlockSize)
 	)
 	for {
 		readN, err = reader.Read(buf)
@@ -1365,7 +1442,7 @@ func (v *Volume) streamWrite(inode uint64, reader io.Reader, h hash.Hash) (size
 		}
 		if readN > 0 {
 			checkFunc := func() error {
-				if !v.mw.EnableQuota {
+				if !v.mw.QuotaEnabled() {
 					return nil
 				}
 
@@ -1530,7 +1607,7 @@ func (v *Volume) loadUserDefinedMetadata(inode uint64) (metadata map[string]stri
 	return
 }
 
-func (v *Volume) readFile(inode, inodeSize uint64, path string, writer io.Writer, offset, size uint64) (err error) {
+func (v *Volume) readFile(inode, inodeSize uint64, path, storageClass string, writer io.Writer, offset, size uint64) (err error) {
 	if err = v.ec.OpenStream(inode); err != nil {
 		log.LogErrorf("readFile: data open stream fail, Inode(%v) err(%v)", inode, err)
 		return err
@@ -1541,10 +1618,10 @@ func (v *Volume) readFile(inode, inodeSize uint64, path string, writer io.Writer
 		}
 	}()
 
-	if proto.IsHot(v.volType) {
-		return v.read(inode, inodeSize, path, writer, offset, size)
-	} else {
+	if v.storedInEbs(storageClass) {
 		return v.readEbs(inode, inodeSize, path, writer, offset, size)
+	} else {
+		return v.read(inode, inodeSize, path, writer, offset, size)
 	}
 }
 
@@ -1657,7 +1734,12 @@ func (v *Volume) ReadFile(path string, writer io.Writer, offset, size uint64) er
 		return err
 	}
 
-	return v.readFile(ino, inoInfo.Size, path, writer, offset, size)
+	var storageClass string
+	if xattr, err := v.mw.XAttrGet_ll(ino, XAttrKeyOSSStorageClass); err == nil {
+		storageClass = string(xattr.Get(XAttrKeyOSSStorageClass))
+	}
+
+	return v.readFile(ino, inoInfo.Size, path, storageClass, writer, offset, size)
 }
 
 func (v *Volume) ObjectMeta(path string) (info *FSFileInfo, xattr *proto.XAttrInfo, err error) {
@@ -1693,6 +1775,9 @@ func (v *Volume) ObjectMeta(path string) (info *FSFileInfo, xattr *proto.XAttrIn
 		disposition  string
 		cacheControl string
 		expires      string
+		storageClass = StorageClassStandard
+		restore      time.Time
+		appendable   bool
 	)
 
 	if objMetaCache != nil {
@@ -1738,6 +1823,11 @@ func (v *Volume) ObjectMeta(path string) (info *FSFileInfo, xattr *proto.XAttrIn
 		disposition = string(xattr.Get(XAttrKeyOSSDISPOSITION))
 		cacheControl = string(xattr.Get(XAttrKeyOSSCacheControl))
 		expires = string(xattr.Get(XAttrKeyOSSExpires))
+		if class := string(xattr.Get(XAttrKeyOSSStorageClass)); class != "" {
+			storageClass = class
+		}
+		restore = parseRestoreExpiry(string(xattr.Get(XAttrKeyOSSRestore)))
+		appendable = len(xattr.Get(XAttrKeyOSSAppendable)) > 0
 		rawETag := string(xattr.Get(XAttrKeyOSSETag))
 		if len(rawETag) == 0 {
 			rawETag = string(xattr.Get(XAttrKeyOSSETagDeprecated))
@@ -1779,12 +1869,17 @@ func (v *Volume) ObjectMeta(path string) (info *FSFileInfo, xattr *proto.XAttrIn
 		ModifyTime:      inoInfo.ModifyTime,
 		ETag:            etagValue.ETag(),
 		Inode:           inoInfo.Inode,
+		Generation:      inoInfo.Generation,
 		MIMEType:        mimeType,
 		Disposition:     disposition,
 		CacheControl:    cacheControl,
 		Expires:         expires,
 		Metadata:        metadata,
 		RetainUntilDate: retainUntilDate,
+		StorageClass:    storageClass,
+		RestoreExpiry:   restore,
+		SSE:             parseSSEFromXAttrs(xattr.XAttrs),
+		Appendable:      appendable,
 	}
 	return
 }
@@ -2413,7 +2508,7 @@ func (v *Volume) supplyListFileInfo(fileInfos []*FSFileInfo) (err error) {
 	}
 
 	// Get MD5 information in batches, then update to fileInfos
-	keys := []string{XAttrKeyOSSETag, XAttrKeyOSSETagDeprecated}
+	keys := []string{XAttrKeyOSSETag, XAttrKeyOSSETagDeprecated, XAttrKeyOSSStorageClass}
 	xattrs, err := v.mw.BatchGetXAttr(inodes, keys)
 	if err != nil {
 		log.LogErrorf("supplyListFileInfo: batch get xattr fail, inodes(%v), err(%v)", inodes, err)
@@ -2423,6 +2518,7 @@ func (v *Volume) supplyListFileInfo(fileInfos []*FSFileInfo) (err error) {
 		return xattrs[i].Inode < xattrs[j].Inode
 	})
 	for _, fileInfo := range fileInfos {
+		fileInfo.StorageClass = StorageClassStandard
 		if fileInfo.Mode.IsDir() {
 			fileInfo.ETag = DirectoryETagValue().ETag()
 			continue
@@ -2433,6 +2529,9 @@ func (v *Volume) supplyListFileInfo(fileInfos []*FSFileInfo) (err error) {
 		var etagValue ETagValue
 		if i >= 0 && i < len(xattrs) && xattrs[i].Inode == fileInfo.Inode {
 			xattr := xattrs[i]
+			if class := string(xattr.Get(XAttrKeyOSSStorageClass)); class != "" {
+				fileInfo.StorageClass = class
+			}
 			rawETag := string(xattr.Get(XAttrKeyOSSETag))
 			if len(rawETag) == 0 {
 				rawETag = string(xattr.Get(XAttrKeyOSSETagDeprecated))
@@ -2614,10 +2713,22 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 		}
 	}()
 
+	// the storage class of target is STANDARD if not specified
+	sourceClass, targetClass := StorageClassStandard, StorageClassStandard
+	if xattr, err := sv.mw.XAttrGet_ll(sInode, XAttrKeyOSSStorageClass); err == nil && len(xattr.Get(XAttrKeyOSSStorageClass)) > 0 {
+		sourceClass = string(xattr.Get(XAttrKeyOSSStorageClass))
+	}
+	classChanged := false
+	if opt != nil && opt.StorageClass != "" {
+		targetClass = opt.StorageClass
+		classChanged = targetClass != sourceClass
+	}
+
 	var xattr *proto.XAttrInfo
 	// if source path is same with target path, just reset file metadata
 	// source path is same with target path, and metadata directive is not 'REPLACE', objectNode does nothing
-	if targetPath == sourcePath && v.name == sv.name {
+	// the data is rewritten if the storage class is changed
+	if targetPath == sourcePath && v.name == sv.name && !classChanged {
 		if metaDirective != MetadataDirectiveReplace {
 			log.LogInfof("CopyFile: targetPath(%v) is equal with sourcePath(%v),but metaDirective(%v) is not REPLACE",
 				targetPath, sourcePath, metaDirective)
@@ -2655,6 +2766,9 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 			if opt != nil && opt.ObjectLock != nil && opt.ObjectLock.ToRetention() != nil {
 				attr.XAttrs[XAttrKeyOSSLock] = formatRetentionDateStr(time.Now(), opt.ObjectLock.ToRetention())
 			}
+			if opt != nil {
+				opt.SSE.setXAttrs(attr.XAttrs)
+			}
 			// If user-defined metadata have been specified, use extend attributes for storage.
 			if opt != nil && len(opt.Metadata) > 0 {
 				for name, value := range opt.Metadata {
@@ -2793,11 +2907,13 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 	var ebsReader *blobstore.Reader
 	var tctx context.Context
 	var ebsWriter *blobstore.Writer
-	if proto.IsCold(sv.volType) {
+	sourceInEbs := sv.storedInEbs(sourceClass)
+	targetInEbs := v.storedInEbs(targetClass)
+	if sourceInEbs {
 		sctx = context.Background()
-		ebsReader = v.getEbsReader(sInode)
+		ebsReader = sv.getEbsReader(sInode)
 	}
-	if proto.IsCold(v.volType) {
+	if targetInEbs {
 		tctx = context.Background()
 		ebsWriter = v.getEbsWriter(tInodeInfo.Inode)
 	}
@@ -2811,7 +2927,7 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 			readSize = rest
 		}
 		buf = buf[:readSize]
-		if proto.IsCold(sv.volType) {
+		if sourceInEbs {
 			readN, err = ebsReader.Read(sctx, buf, readOffset, readSize)
 		} else {
 			readN, err = sv.ec.Read(sInode, buf, readOffset, readSize)
@@ -2820,7 +2936,7 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 			return
 		}
 		if readN > 0 {
-			if proto.IsCold(v.volType) {
+			if targetInEbs {
 				writeN, err = ebsWriter.WriteWithoutPool(tctx, writeOffset, buf[:readN])
 			} else {
 				writeN, err = v.ec.Write(tInodeInfo.Inode, writeOffset, buf[:readN], 0, nil)
@@ -2842,7 +2958,7 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 		}
 	}
 	// flush
-	if proto.IsCold(v.volType) {
+	if targetInEbs {
 		err = ebsWriter.FlushWithoutPool(tInodeInfo.Inode, tctx)
 	} else {
 		v.ec.Flush(tInodeInfo.Inode)
@@ -2874,6 +2990,9 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 		},
 	}
 	targetAttr.XAttrs[XAttrKeyOSSETag] = etagValue.Encode()
+	if targetClass != StorageClassStandard {
+		targetAttr.XAttrs[XAttrKeyOSSStorageClass] = targetClass
+	}
 
 	// copy source file metadata to write target file metadata
 	if metaDirective != MetadataDirectiveReplace {
@@ -2882,7 +3001,10 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 			return
 		}
 		for key, val := range xattr.XAttrs {
-			if key == XAttrKeyOSSETag {
+			// the target is a new single part object which is not appendable, and its encryption is
+			// specified by the request or the bucket of target
+			if key == XAttrKeyOSSETag || key == XAttrKeyOSSParts || key == XAttrKeyOSSStorageClass || key == XAttrKeyOSSRestore ||
+				key == XAttrKeyOSSAppendable || isSSEXAttrKey(key) {
 				continue
 			}
 			targetAttr.XAttrs[key] = val
@@ -2893,6 +3015,9 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 		if opt != nil && opt.ObjectLock != nil && opt.ObjectLock.ToRetention() != nil {
 			targetAttr.XAttrs[XAttrKeyOSSLock] = formatRetentionDateStr(tInodeInfo.ModifyTime, opt.ObjectLock.ToRetention())
 		}
+		if opt != nil {
+			opt.SSE.setXAttrs(targetAttr.XAttrs)
+		}
 		if err = v.mw.BatchSetXAttr_ll(tInodeInfo.Inode, targetAttr.XAttrs); err != nil {
 			log.LogErrorf("CopyFile: set target xattr fail: volume(%v) target path(%v) inode(%v) xattr (%v)err(%v)",
 				v.name, targetPath, tInodeInfo.Inode, xattr, err)
@@ -2922,6 +3047,9 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 		if opt != nil && opt.ObjectLock != nil && opt.ObjectLock.ToRetention() != nil {
 			targetAttr.XAttrs[XAttrKeyOSSLock] = formatRetentionDateStr(tInodeInfo.ModifyTime, opt.ObjectLock.ToRetention())
 		}
+		if opt != nil {
+			opt.SSE.setXAttrs(targetAttr.XAttrs)
+		}
 
 		// If user-defined metadata have been specified, use extend attributes for storage.
 		if opt != nil && len(opt.Metadata) > 0 {
@@ -3072,14 +3200,16 @@ func NewVolume(config *VolumeConfig) (*Volume, error) {
 }
 
 func (v *Volume) getEbsWriter(ino uint64) (writer *blobstore.Writer) {
+	mw, _ := v.mw.(*meta.MetaWrapper)
+	ec, _ := v.ec.(*stream.ExtentClient)
 	clientConf := blobstore.ClientConfig{
 		VolName:         v.name,
 		VolType:         v.volType,
 		Ino:             ino,
-		BlockSize:       v.ebsBlockSize,
+		BlockSize:       v.getEbsBlockSize(),
 		Bc:              blockCache,
-		Mw:              v.mw,
-		Ec:              v.ec,
+		Mw:              mw,
+		Ec:              ec,
 		Ebsc:            ebsClient,
 		EnableBcache:    enableBlockcache,
 		WConcurrency:    writeThreads,
@@ -3096,14 +3226,16 @@ func (v *Volume) getEbsWriter(ino uint64) (writer *blobstore.Writer) {
 }
 
 func (v *Volume) getEbsReader(ino uint64) (reader *blobstore.Reader) {
+	mw, _ := v.mw.(*meta.MetaWrapper)
+	ec, _ := v.ec.(*stream.ExtentClient)
 	clientConf := blobstore.ClientConfig{
 		VolName:         v.name,
 		VolType:         v.volType,
 		Ino:             ino,
-		BlockSize:       v.ebsBlockSize,
+		BlockSize:       v.getEbsBlockSize(),
 		Bc:              blockCache,
-		Mw:              v.mw,
-		Ec:              v.ec,
+		Mw:              mw,
+		Ec:              ec,
 		Ebsc:            ebsClient,
 		EnableBcache:    enableBlockcache,
 		WConcurrency:    writeThreads,
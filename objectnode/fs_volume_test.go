@@ -13,3 +13,283 @@
 // permissions and limitations under the License.
 
 package objectnode
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+type memDentry struct {
+	inode uint64
+	mode  uint32
+}
+
+// memMeta is the meta of the volume in memory, only the methods of putting objects are implemented.
+type memMeta struct {
+	MetaWrapper
+
+	sync.Mutex
+	nextInode       uint64
+	inodes          map[uint64]*proto.InodeInfo
+	xattrs          map[uint64]map[string]string
+	dentries        map[uint64]map[string]memDentry
+	beforeDentryNew func() // called before creating the dentry, to inject the concurrent writer
+}
+
+func newMemMeta() *memMeta {
+	m := &memMeta{
+		nextInode: rootIno,
+		inodes:    make(map[uint64]*proto.InodeInfo),
+		xattrs:    make(map[uint64]map[string]string),
+		dentries:  make(map[uint64]map[string]memDentry),
+	}
+	m.inodes[rootIno] = &proto.InodeInfo{Inode: rootIno, Mode: uint32(DefaultDirMode), Nlink: 2}
+	return m
+}
+
+func (m *memMeta) QuotaEnabled() bool { return false }
+
+func (m *memMeta) newInode(mode uint32) *proto.InodeInfo {
+	m.nextInode++
+	info := &proto.InodeInfo{Inode: m.nextInode, Mode: mode, Nlink: 1}
+	m.inodes[info.Inode] = info
+	return info
+}
+
+func (m *memMeta) Lookup_ll(parentID uint64, name string) (inode uint64, mode uint32, err error) {
+	m.Lock()
+	defer m.Unlock()
+	d, ok := m.dentries[parentID][name]
+	if !ok {
+		return 0, 0, syscall.ENOENT
+	}
+	return d.inode, d.mode, nil
+}
+
+func (m *memMeta) createDentry(parentID uint64, name string, inode uint64, mode uint32) error {
+	if _, ok := m.dentries[parentID][name]; ok {
+		return syscall.EEXIST
+	}
+	if m.dentries[parentID] == nil {
+		m.dentries[parentID] = make(map[string]memDentry)
+	}
+	m.dentries[parentID][name] = memDentry{inode: inode, mode: mode}
+	return nil
+}
+
+func (m *memMeta) Create_ll(parentID uint64, name string, mode, uid, gid uint32, target []byte, fullPath string, ignoreExist bool) (*proto.InodeInfo, error) {
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.dentries[parentID][name]; ok {
+		return nil, syscall.EEXIST
+	}
+	info := m.newInode(mode)
+	return info, m.createDentry(parentID, name, info.Inode, mode)
+}
+
+func (m *memMeta) DentryCreate_ll(parentID uint64, name string, inode uint64, mode uint32, fullPath string) error {
+	if m.beforeDentryNew != nil {
+		m.beforeDentryNew()
+	}
+	m.Lock()
+	defer m.Unlock()
+	return m.createDentry(parentID, name, inode, mode)
+}
+
+func (m *memMeta) DentryUpdate_ll(parentID uint64, name string, inode uint64, fullPath string) (oldInode uint64, err error) {
+	m.Lock()
+	defer m.Unlock()
+	d, ok := m.dentries[parentID][name]
+	if !ok {
+		return 0, syscall.ENOENT
+	}
+	m.dentries[parentID][name] = memDentry{inode: inode, mode: d.mode}
+	return d.inode, nil
+}
+
+func (m *memMeta) InodeCreate_ll(parentID uint64, mode, uid, gid uint32, target []byte, quotaIds []uint64, fullPath string) (*proto.InodeInfo, error) {
+	m.Lock()
+	defer m.Unlock()
+	info := *m.newInode(mode)
+	return &info, nil
+}
+
+func (m *memMeta) InodeGet_ll(inode uint64) (*proto.InodeInfo, error) {
+	m.Lock()
+	defer m.Unlock()
+	info, ok := m.inodes[inode]
+	if !ok {
+		return nil, syscall.ENOENT
+	}
+	copied := *info
+	return &copied, nil
+}
+
+func (m *memMeta) InodeUnlink_ll(inode uint64, fullPath string) (*proto.InodeInfo, error) {
+	m.Lock()
+	defer m.Unlock()
+	info, ok := m.inodes[inode]
+	if !ok {
+		return nil, syscall.ENOENT
+	}
+	info.Nlink--
+	copied := *info
+	return &copied, nil
+}
+
+func (m *memMeta) Evict(inode uint64, fullPath string) error {
+	m.Lock()
+	defer m.Unlock()
+	if info, ok := m.inodes[inode]; ok && info.Nlink == 0 {
+		delete(m.inodes, inode)
+		delete(m.xattrs, inode)
+	}
+	return nil
+}
+
+func (m *memMeta) BatchSetXAttr_ll(inode uint64, attrs map[string]string) error {
+	m.Lock()
+	defer m.Unlock()
+	if m.xattrs[inode] == nil {
+		m.xattrs[inode] = make(map[string]string)
+	}
+	for k, v := range attrs {
+		m.xattrs[inode][k] = v
+	}
+	return nil
+}
+
+func (m *memMeta) inodeCount() int {
+	m.Lock()
+	defer m.Unlock()
+	return len(m.inodes)
+}
+
+// memData is the data of the volume in memory.
+type memData struct {
+	ExtentClient
+
+	meta *memMeta
+	sync.Mutex
+	data map[uint64][]byte
+}
+
+func newMemData(meta *memMeta) *memData {
+	return &memData{meta: meta, data: make(map[uint64][]byte)}
+}
+
+func (d *memData) OpenStream(inode uint64) error  { return nil }
+func (d *memData) CloseStream(inode uint64) error { return nil }
+func (d *memData) Flush(inode uint64) error       { return nil }
+
+func (d *memData) Write(inode uint64, offset int, data []byte, flags int, checkFunc func() error) (int, error) {
+	d.Lock()
+	buf := d.data[inode]
+	if end := offset + len(data); end > len(buf) {
+		buf = append(buf, make([]byte, end-len(buf))...)
+	}
+	copy(buf[offset:], data)
+	d.data[inode] = buf
+	d.Unlock()
+
+	d.meta.Lock()
+	if info, ok := d.meta.inodes[inode]; ok && uint64(len(buf)) > info.Size {
+		info.Size = uint64(len(buf))
+	}
+	d.meta.Unlock()
+	return len(data), nil
+}
+
+func newVolumeForPutTest() (*Volume, *memMeta, *memData) {
+	mw := newMemMeta()
+	ec := newMemData(mw)
+	return &Volume{name: "test", mw: mw, ec: ec, volType: proto.VolumeTypeHot}, mw, ec
+}
+
+func contentMD5(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// requireObject requires the content and etag of the object of path.
+func requireObject(t *testing.T, v *Volume, path string, content []byte) {
+	mw, ec := v.mw.(*memMeta), v.ec.(*memData)
+	parentID, err := v.recursiveMakeDirectory(path)
+	require.NoError(t, err)
+	pathItems := NewPathIterator(path).ToSlice()
+	inode, _, err := mw.Lookup_ll(parentID, pathItems[len(pathItems)-1].Name)
+	require.NoError(t, err)
+	require.Equal(t, content, ec.data[inode])
+	etag := ParseETagValue(mw.xattrs[inode][XAttrKeyOSSETag])
+	require.Equal(t, contentMD5(content), etag.Value)
+}
+
+func TestPutObjectCreateOnly(t *testing.T) {
+	v, mw, _ := newVolumeForPutTest()
+	path := "dir/object"
+	content := []byte("the first object")
+	_, err := v.PutObject(path, bytes.NewReader(content), &PutFileOption{CreateOnly: true})
+	require.NoError(t, err)
+	requireObject(t, v, path, content)
+	inodes := mw.inodeCount()
+
+	// the object exists
+	_, err = v.PutObject(path, bytes.NewReader([]byte("the second object")), &PutFileOption{CreateOnly: true})
+	require.Equal(t, PreconditionFailed, err)
+	require.Equal(t, http.StatusPreconditionFailed, PreconditionFailed.StatusCode)
+	requireObject(t, v, path, content)
+	require.Equal(t, inodes, mw.inodeCount())
+
+	// the object is created by the other writer after lookup
+	racePath := "dir/race"
+	raceContent := []byte("the object of the other writer")
+	mw.beforeDentryNew = func() {
+		mw.beforeDentryNew = nil
+		_, err := v.PutObject(racePath, bytes.NewReader(raceContent), nil)
+		require.NoError(t, err)
+	}
+	_, err = v.PutObject(racePath, bytes.NewReader([]byte("the object lost the race")), &PutFileOption{CreateOnly: true})
+	require.Equal(t, PreconditionFailed, err)
+	requireObject(t, v, racePath, raceContent)
+	require.Equal(t, inodes+1, mw.inodeCount())
+
+	// the object is overwritten without create only
+	overwritten := []byte("the object overwritten")
+	_, err = v.PutObject(path, bytes.NewReader(overwritten), nil)
+	require.NoError(t, err)
+	requireObject(t, v, path, overwritten)
+	require.Equal(t, inodes+1, mw.inodeCount())
+}
+
+func TestPutObjectBadDigest(t *testing.T) {
+	v, mw, _ := newVolumeForPutTest()
+	path := "dir/object"
+	content := []byte("the object")
+	_, err := v.PutObject(path, bytes.NewReader(content), &PutFileOption{ContentMD5: contentMD5([]byte("another object"))})
+	require.Equal(t, BadDigest, err)
+	// no partial object and the temp inode is released, only the root and the dir are left
+	parentID, err := v.recursiveMakeDirectory(path)
+	require.NoError(t, err)
+	_, _, err = mw.Lookup_ll(parentID, "object")
+	require.Equal(t, syscall.ENOENT, err)
+	require.Equal(t, 2, mw.inodeCount())
+
+	fsInfo, err := v.PutObject(path, bytes.NewReader(content), &PutFileOption{ContentMD5: contentMD5(content)})
+	require.NoError(t, err)
+	require.Equal(t, contentMD5(content), fsInfo.ETag)
+	requireObject(t, v, path, content)
+
+	// the existing object is not overwritten by the mismatched content
+	_, err = v.PutObject(path, bytes.NewReader([]byte("the object changed")), &PutFileOption{ContentMD5: contentMD5(content)})
+	require.Equal(t, BadDigest, err)
+	requireObject(t, v, path, content)
+	require.Equal(t, 3, mw.inodeCount())
+}
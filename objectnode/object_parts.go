@@ -0,0 +1,125 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+// ObjectParts is the sizes of the parts of an object, which are read by the partNumber of GetObject
+// and HeadObject. The parts of the completed multipart uploads are stored in the xattr encoded by
+// run length, e.g. "8388608*99,1048576" for 99 parts of 8MB and a part of 1MB.
+type ObjectParts []uint64
+
+func (parts ObjectParts) Encode() string {
+	sb := strings.Builder{}
+	for i := 0; i < len(parts); {
+		j := i + 1
+		for j < len(parts) && parts[j] == parts[i] {
+			j++
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatUint(parts[i], 10))
+		if j-i > 1 {
+			sb.WriteByte('*')
+			sb.WriteString(strconv.Itoa(j - i))
+		}
+		i = j
+	}
+	return sb.String()
+}
+
+func ParseObjectParts(raw string) (parts ObjectParts, err error) {
+	for _, item := range strings.Split(raw, ",") {
+		var size uint64
+		count := 1
+		fields := strings.SplitN(item, "*", 2)
+		if size, err = strconv.ParseUint(fields[0], 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid part size %v", item)
+		}
+		if len(fields) > 1 {
+			if count, err = strconv.Atoi(fields[1]); err != nil || count <= 0 || len(parts)+count > MaxPartNumberValid {
+				return nil, fmt.Errorf("invalid part count %v", item)
+			}
+		}
+		for i := 0; i < count; i++ {
+			parts = append(parts, size)
+		}
+	}
+	if len(parts) > MaxPartNumberValid {
+		return nil, fmt.Errorf("too many parts %v", len(parts))
+	}
+	return parts, nil
+}
+
+func (parts ObjectParts) Size() (size uint64) {
+	for _, part := range parts {
+		size += part
+	}
+	return
+}
+
+// Range returns the first and the last byte of the part, partNumber starts from 1.
+func (parts ObjectParts) Range(partNumber uint64) (lower, upper uint64) {
+	for _, part := range parts[:partNumber-1] {
+		lower += part
+	}
+	upper = lower
+	if size := parts[partNumber-1]; size > 0 {
+		upper = lower + size - 1
+	}
+	return
+}
+
+// objectParts returns the parts of the object. They are the uploaded parts of the completed multipart
+// objects, the other objects are split into parts of ParallelDownloadPartSize if they are large enough,
+// or they are a single part.
+func objectParts(fileInfo *FSFileInfo, xattr *proto.XAttrInfo) ObjectParts {
+	size := uint64(fileInfo.Size)
+	if xattr != nil {
+		if raw := string(xattr.Get(XAttrKeyOSSParts)); raw != "" {
+			// the parts are stale if the file has been modified by other interfaces
+			if parts, err := ParseObjectParts(raw); err == nil && parts.Size() == size {
+				return parts
+			}
+		}
+	}
+	if size < MinParallelDownloadFileSize {
+		return ObjectParts{size}
+	}
+	parts := make(ObjectParts, 0, (size+ParallelDownloadPartSize-1)/ParallelDownloadPartSize)
+	for ; size > ParallelDownloadPartSize; size -= ParallelDownloadPartSize {
+		parts = append(parts, ParallelDownloadPartSize)
+	}
+	return append(parts, size)
+}
+
+// parsePartNumber parses the partNumber of GetObject and HeadObject, which can't be used with range.
+func parsePartNumber(raw string, isRangeRead bool) (uint64, *ErrorCode) {
+	if isRangeRead {
+		return 0, PartNumberWithRange
+	}
+	partNumber, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || partNumber < uint64(MinPartNumberValid) || partNumber > uint64(MaxPartNumberValid) {
+		return 0, InvalidPartNumber
+	}
+	return partNumber, nil
+}
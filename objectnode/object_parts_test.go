@@ -0,0 +1,83 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectPartsEncode(t *testing.T) {
+	for _, c := range []struct {
+		parts ObjectParts
+		raw   string
+	}{
+		{ObjectParts{100}, "100"},
+		{ObjectParts{0}, "0"},
+		{ObjectParts{8 << 20, 8 << 20, 8 << 20, 1 << 20}, "8388608*3,1048576"},
+		{ObjectParts{5 << 20, 6 << 20, 6 << 20, 5 << 20}, "5242880,6291456*2,5242880"},
+	} {
+		require.Equal(t, c.raw, c.parts.Encode())
+		parts, err := ParseObjectParts(c.raw)
+		require.NoError(t, err)
+		require.Equal(t, c.parts, parts)
+	}
+
+	for _, raw := range []string{"", "a", "100,", "100*0", "100*a", "100*10001", "1*5000,1*5001"} {
+		_, err := ParseObjectParts(raw)
+		require.Error(t, err, raw)
+	}
+}
+
+func TestObjectPartsRange(t *testing.T) {
+	parts := ObjectParts{10, 10, 5}
+	require.Equal(t, uint64(25), parts.Size())
+	for i, expected := range [][2]uint64{{0, 9}, {10, 19}, {20, 24}} {
+		lower, upper := parts.Range(uint64(i + 1))
+		require.Equal(t, expected, [2]uint64{lower, upper})
+	}
+	lower, upper := ObjectParts{0}.Range(1)
+	require.Equal(t, [2]uint64{0, 0}, [2]uint64{lower, upper})
+}
+
+func TestObjectParts(t *testing.T) {
+	xattr := &proto.XAttrInfo{XAttrs: map[string]string{XAttrKeyOSSParts: "30*2,5"}}
+	require.Equal(t, ObjectParts{30, 30, 5}, objectParts(&FSFileInfo{Size: 65}, xattr))
+
+	// the stale parts are ignored
+	require.Equal(t, ObjectParts{66}, objectParts(&FSFileInfo{Size: 66}, xattr))
+	require.Equal(t, ObjectParts{0}, objectParts(&FSFileInfo{Size: 0}, nil))
+
+	size := int64(2*ParallelDownloadPartSize + 1)
+	require.Equal(t, ObjectParts{ParallelDownloadPartSize, ParallelDownloadPartSize, 1},
+		objectParts(&FSFileInfo{Size: size}, nil))
+	require.Equal(t, ObjectParts{ParallelDownloadPartSize, ParallelDownloadPartSize},
+		objectParts(&FSFileInfo{Size: size - 1}, nil))
+}
+
+func TestParsePartNumber(t *testing.T) {
+	partNumber, errCode := parsePartNumber("3", false)
+	require.Nil(t, errCode)
+	require.Equal(t, uint64(3), partNumber)
+
+	for _, raw := range []string{"0", "-1", "a", "10001"} {
+		_, errCode = parsePartNumber(raw, false)
+		require.Equal(t, InvalidPartNumber, errCode, raw)
+	}
+	_, errCode = parsePartNumber("1", true)
+	require.Equal(t, PartNumberWithRange, errCode)
+}
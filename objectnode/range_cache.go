@@ -0,0 +1,349 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cubefs/cubefs/util/log"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultRangeCacheMemoryMB    = 1024
+	defaultRangeCacheBlockKB     = 1024
+	defaultRangeCacheMaxRangeKB  = 1024
+	defaultRangeCacheMinObjectMB = 64
+
+	rangeCacheBlockFileSuffix = ".blk"
+)
+
+// RangeCacheConfig is the configuration of the range cache, which caches small ranged reads
+// of large objects by aligned blocks in memory, and optionally on local disk.
+type RangeCacheConfig struct {
+	MemoryMB    int64  `json:"memoryMB"`
+	DiskPath    string `json:"diskPath"`
+	DiskMB      int64  `json:"diskMB"`
+	BlockKB     int64  `json:"blockKB"`
+	MaxRangeKB  int64  `json:"maxRangeKB"`
+	MinObjectMB int64  `json:"minObjectMB"`
+}
+
+type rangeBlockKey struct {
+	vol   string
+	inode uint64
+	gen   uint64 // inode generation, bumped by every write, the block is invalid if object overwritten
+	size  uint64 // object size, the block is invalid if object changed
+	index uint64
+}
+
+func (k rangeBlockKey) fileName() string {
+	return fmt.Sprintf("%s_%d_%d_%d_%d%s", k.vol, k.inode, k.gen, k.size, k.index, rangeCacheBlockFileSuffix)
+}
+
+type rangeCacheItem struct {
+	key  rangeBlockKey
+	data []byte // nil in disk tier
+	size int64
+}
+
+// rangeLRU evicts the least recently used items when the total size exceeds capacity.
+type rangeLRU struct {
+	sync.Mutex
+	capacity int64
+	used     int64
+	items    map[rangeBlockKey]*list.Element
+	lru      *list.List
+	onEvict  func(item *rangeCacheItem)
+}
+
+func newRangeLRU(capacity int64, onEvict func(item *rangeCacheItem)) *rangeLRU {
+	return &rangeLRU{
+		capacity: capacity,
+		items:    make(map[rangeBlockKey]*list.Element),
+		lru:      list.New(),
+		onEvict:  onEvict,
+	}
+}
+
+func (c *rangeLRU) get(key rangeBlockKey) (*rangeCacheItem, bool) {
+	c.Lock()
+	defer c.Unlock()
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*rangeCacheItem), true
+}
+
+func (c *rangeLRU) put(item *rangeCacheItem) {
+	c.Lock()
+	defer c.Unlock()
+	if elem, ok := c.items[item.key]; ok {
+		c.removeElement(elem)
+	}
+	c.items[item.key] = c.lru.PushFront(item)
+	c.used += item.size
+	for c.used > c.capacity && c.lru.Len() > 0 {
+		evicted := c.removeElement(c.lru.Back())
+		if c.onEvict != nil {
+			c.onEvict(evicted)
+		}
+	}
+}
+
+func (c *rangeLRU) remove(key rangeBlockKey) {
+	c.Lock()
+	defer c.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+func (c *rangeLRU) removeElement(elem *list.Element) *rangeCacheItem {
+	item := c.lru.Remove(elem).(*rangeCacheItem)
+	delete(c.items, item.key)
+	c.used -= item.size
+	return item
+}
+
+// RangeCache caches aligned blocks of large objects for small ranged reads.
+// Missing adjacent blocks of one read are read from backend together, and
+// concurrent reads of the same blocks are coalesced into one backend read.
+type RangeCache struct {
+	blockSize uint64
+	maxRange  uint64
+	minObject uint64
+	diskPath  string
+	mem       *rangeLRU
+	disk      *rangeLRU // nil if disk cache is disabled
+	group     singleflight.Group
+
+	hit  uint64
+	miss uint64
+}
+
+func NewRangeCache(conf RangeCacheConfig) (*RangeCache, error) {
+	if conf.MemoryMB <= 0 {
+		conf.MemoryMB = defaultRangeCacheMemoryMB
+	}
+	if conf.BlockKB <= 0 {
+		conf.BlockKB = defaultRangeCacheBlockKB
+	}
+	if conf.MaxRangeKB <= 0 {
+		conf.MaxRangeKB = defaultRangeCacheMaxRangeKB
+	}
+	if conf.MinObjectMB <= 0 {
+		conf.MinObjectMB = defaultRangeCacheMinObjectMB
+	}
+	rc := &RangeCache{
+		blockSize: uint64(conf.BlockKB) << 10,
+		maxRange:  uint64(conf.MaxRangeKB) << 10,
+		minObject: uint64(conf.MinObjectMB) << 20,
+		diskPath:  conf.DiskPath,
+		mem:       newRangeLRU(conf.MemoryMB<<20, nil),
+	}
+	if conf.DiskPath != "" && conf.DiskMB > 0 {
+		if err := rc.initDisk(); err != nil {
+			return nil, err
+		}
+		rc.disk = newRangeLRU(conf.DiskMB<<20, func(item *rangeCacheItem) {
+			os.Remove(path.Join(rc.diskPath, item.key.fileName()))
+		})
+	}
+	return rc, nil
+}
+
+// initDisk removes blocks cached before restart, whose sizes are not accounted.
+func (rc *RangeCache) initDisk() error {
+	if err := os.MkdirAll(rc.diskPath, 0o755); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(rc.diskPath)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), rangeCacheBlockFileSuffix) {
+			os.Remove(path.Join(rc.diskPath, entry.Name()))
+		}
+	}
+	return nil
+}
+
+// Cacheable returns true if the ranged read of the object should read through the cache.
+func (rc *RangeCache) Cacheable(objectSize, size uint64) bool {
+	return objectSize >= rc.minObject && size > 0 && size <= rc.maxRange
+}
+
+// Read writes the range [offset, offset+size) of the object to the writer,
+// readFn reads the missing blocks from backend. The blocks are keyed by the
+// generation of the inode, so the ones cached before an in-place write are never hit.
+func (rc *RangeCache) Read(vol string, inode, gen, objectSize uint64, writer io.Writer, offset, size uint64,
+	readFn func(writer io.Writer, offset, size uint64) error,
+) error {
+	upper := offset + size
+	if upper > objectSize {
+		upper = objectSize
+	}
+	if offset >= upper {
+		return nil
+	}
+	first, last := offset/rc.blockSize, (upper-1)/rc.blockSize
+	blocks := make([][]byte, last-first+1)
+	for i := range blocks {
+		blocks[i] = rc.getBlock(rangeBlockKey{vol: vol, inode: inode, gen: gen, size: objectSize, index: first + uint64(i)})
+	}
+
+	// read missing adjacent blocks together
+	for i := 0; i < len(blocks); {
+		if blocks[i] != nil {
+			atomic.AddUint64(&rc.hit, 1)
+			i++
+			continue
+		}
+		j := i
+		for j+1 < len(blocks) && blocks[j+1] == nil {
+			j++
+		}
+		atomic.AddUint64(&rc.miss, uint64(j-i+1))
+		loaded, err := rc.loadBlocks(vol, inode, gen, objectSize, first+uint64(i), first+uint64(j), readFn)
+		if err != nil {
+			return err
+		}
+		copy(blocks[i:j+1], loaded)
+		i = j + 1
+	}
+
+	for i, block := range blocks {
+		blockStart := (first + uint64(i)) * rc.blockSize
+		start, end := uint64(0), uint64(len(block))
+		if offset > blockStart {
+			start = offset - blockStart
+		}
+		if upper < blockStart+end {
+			end = upper - blockStart
+		}
+		if start >= end {
+			return fmt.Errorf("range cache: short block(%v) of inode(%v) size(%v)", first+uint64(i), inode, len(block))
+		}
+		if _, err := writer.Write(block[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rc *RangeCache) getBlock(key rangeBlockKey) []byte {
+	if item, ok := rc.mem.get(key); ok {
+		return item.data
+	}
+	if rc.disk == nil {
+		return nil
+	}
+	if _, ok := rc.disk.get(key); !ok {
+		return nil
+	}
+	data, err := os.ReadFile(path.Join(rc.diskPath, key.fileName()))
+	if err != nil {
+		log.LogWarnf("range cache: read block(%v) from disk fail: err(%v)", key.fileName(), err)
+		rc.disk.remove(key)
+		return nil
+	}
+	rc.mem.put(&rangeCacheItem{key: key, data: data, size: int64(len(data))})
+	return data
+}
+
+func (rc *RangeCache) putBlock(key rangeBlockKey, data []byte) {
+	rc.mem.put(&rangeCacheItem{key: key, data: data, size: int64(len(data))})
+	if rc.disk == nil {
+		return
+	}
+	name := path.Join(rc.diskPath, key.fileName())
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		log.LogWarnf("range cache: write block(%v) to disk fail: err(%v)", key.fileName(), err)
+		os.Remove(tmp)
+		return
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		os.Remove(tmp)
+		return
+	}
+	rc.disk.put(&rangeCacheItem{key: key, size: int64(len(data))})
+}
+
+// loadBlocks reads blocks [first, last] from backend, concurrent loads of the same blocks are coalesced.
+func (rc *RangeCache) loadBlocks(vol string, inode, gen, objectSize, first, last uint64,
+	readFn func(writer io.Writer, offset, size uint64) error,
+) ([][]byte, error) {
+	flightKey := fmt.Sprintf("%s/%d/%d/%d/%d-%d", vol, inode, gen, objectSize, first, last)
+	val, err, _ := rc.group.Do(flightKey, func() (interface{}, error) {
+		offset := first * rc.blockSize
+		upper := (last + 1) * rc.blockSize
+		if upper > objectSize {
+			upper = objectSize
+		}
+		buffer := bytes.NewBuffer(make([]byte, 0, upper-offset))
+		if err := readFn(buffer, offset, upper-offset); err != nil {
+			return nil, err
+		}
+		data := buffer.Bytes()
+		if uint64(len(data)) != upper-offset {
+			return nil, fmt.Errorf("range cache: read inode(%v) offset(%v) size(%v) but got(%v)",
+				inode, offset, upper-offset, len(data))
+		}
+		blocks := make([][]byte, 0, last-first+1)
+		for index := first; index <= last; index++ {
+			start := (index - first) * rc.blockSize
+			end := start + rc.blockSize
+			if end > uint64(len(data)) {
+				end = uint64(len(data))
+			}
+			block := data[start:end:end]
+			rc.putBlock(rangeBlockKey{vol: vol, inode: inode, gen: gen, size: objectSize, index: index}, block)
+			blocks = append(blocks, block)
+		}
+		return blocks, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return val.([][]byte), nil
+}
+
+// Stat returns the hit and miss count of blocks.
+func (rc *RangeCache) Stat() (hit, miss uint64) {
+	return atomic.LoadUint64(&rc.hit), atomic.LoadUint64(&rc.miss)
+}
+
+// readFileCached reads the ranged data of the file through the range cache if cacheable.
+func (v *Volume) readFileCached(inode, gen, inodeSize uint64, path, storageClass string, writer io.Writer, offset, size uint64) error {
+	if rangeCache == nil || !rangeCache.Cacheable(inodeSize, size) {
+		return v.readFile(inode, inodeSize, path, storageClass, writer, offset, size)
+	}
+	return rangeCache.Read(v.name, inode, gen, inodeSize, writer, offset, size, func(w io.Writer, off, n uint64) error {
+		return v.readFile(inode, inodeSize, path, storageClass, w, off, n)
+	})
+}
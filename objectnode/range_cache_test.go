@@ -0,0 +1,162 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type rangeCacheBackend struct {
+	data  []byte
+	reads int32
+}
+
+func (b *rangeCacheBackend) read(writer io.Writer, offset, size uint64) error {
+	atomic.AddInt32(&b.reads, 1)
+	_, err := writer.Write(b.data[offset : offset+size])
+	return err
+}
+
+func newRangeCacheBackend(size int) *rangeCacheBackend {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return &rangeCacheBackend{data: data}
+}
+
+func TestRangeCacheRead(t *testing.T) {
+	rc, err := NewRangeCache(RangeCacheConfig{MemoryMB: 1, BlockKB: 4, MaxRangeKB: 16, MinObjectMB: 1})
+	require.NoError(t, err)
+	backend := newRangeCacheBackend(1<<20 + 100)
+	size := uint64(len(backend.data))
+
+	require.True(t, rc.Cacheable(size, 16<<10))
+	require.False(t, rc.Cacheable(size, 16<<10+1))
+	require.False(t, rc.Cacheable(1<<20-1, 1))
+
+	read := func(offset, n uint64) []byte {
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, rc.Read("vol", 1, 1, size, buf, offset, n, backend.read))
+		return buf.Bytes()
+	}
+
+	// adjacent missing blocks are read once
+	require.Equal(t, backend.data[1000:9000], read(1000, 8000))
+	require.Equal(t, int32(1), backend.reads)
+	require.Equal(t, backend.data[4096:8192], read(4096, 4096))
+	require.Equal(t, int32(1), backend.reads)
+
+	// only the missing block is read
+	require.Equal(t, backend.data[8000:13000], read(8000, 5000))
+	require.Equal(t, int32(2), backend.reads)
+	hit, miss := rc.Stat()
+	require.Equal(t, uint64(3), hit)
+	require.Equal(t, uint64(4), miss)
+
+	// tail block of object
+	require.Equal(t, backend.data[size-50:], read(size-50, 1000))
+	require.Equal(t, backend.data[size-100:], read(size-100, 100))
+	require.Equal(t, int32(3), backend.reads)
+
+	// object changed
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, rc.Read("vol", 1, 1, size-1, buf, 0, 100, backend.read))
+	require.Equal(t, int32(4), backend.reads)
+
+	// object overwritten in place with the same size
+	buf.Reset()
+	require.NoError(t, rc.Read("vol", 1, 2, size, buf, 0, 100, backend.read))
+	require.Equal(t, int32(5), backend.reads)
+	buf.Reset()
+	require.NoError(t, rc.Read("vol", 1, 2, size, buf, 0, 100, backend.read))
+	require.Equal(t, int32(5), backend.reads)
+}
+
+func TestRangeCacheCoalesce(t *testing.T) {
+	rc, err := NewRangeCache(RangeCacheConfig{MemoryMB: 1, BlockKB: 4, MinObjectMB: 1})
+	require.NoError(t, err)
+	backend := newRangeCacheBackend(1 << 20)
+	size := uint64(len(backend.data))
+
+	start := make(chan struct{})
+	slowRead := func(writer io.Writer, offset, n uint64) error {
+		<-start
+		return backend.read(writer, offset, n)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := bytes.NewBuffer(nil)
+			require.NoError(t, rc.Read("vol", 1, 1, size, buf, 100, 5000, slowRead))
+			require.Equal(t, backend.data[100:5100], buf.Bytes())
+		}()
+	}
+	close(start)
+	wg.Wait()
+	require.LessOrEqual(t, atomic.LoadInt32(&backend.reads), int32(8))
+	require.GreaterOrEqual(t, atomic.LoadInt32(&backend.reads), int32(1))
+}
+
+func TestRangeCacheDisk(t *testing.T) {
+	dir, err := os.MkdirTemp("", "range_cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, os.WriteFile(dir+"/stale"+rangeCacheBlockFileSuffix, []byte("x"), 0o644))
+
+	conf := RangeCacheConfig{MemoryMB: 1, DiskPath: dir, DiskMB: 1, BlockKB: 512, MaxRangeKB: 1024, MinObjectMB: 1}
+	rc, err := NewRangeCache(conf)
+	require.NoError(t, err)
+	_, err = os.Stat(dir + "/stale" + rangeCacheBlockFileSuffix)
+	require.True(t, os.IsNotExist(err))
+
+	backend := newRangeCacheBackend(4 << 20)
+	size := uint64(len(backend.data))
+	read := func(offset, n uint64) []byte {
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, rc.Read("vol", 1, 1, size, buf, offset, n, backend.read))
+		return buf.Bytes()
+	}
+
+	// block 0 and 1 fill memory and disk
+	require.Equal(t, backend.data[:1<<20], read(0, 1<<20))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(entries))
+
+	// block 2 evicts block 0 from memory and disk
+	require.Equal(t, backend.data[1<<20:1<<20+10], read(1<<20, 10))
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(entries))
+	require.Equal(t, int32(2), backend.reads)
+
+	// block 1 dropped from memory still hits on disk
+	rc.mem.remove(rangeBlockKey{vol: "vol", inode: 1, gen: 1, size: size, index: 1})
+	require.Equal(t, backend.data[600<<10:601<<10], read(600<<10, 1<<10))
+	require.Equal(t, int32(2), backend.reads)
+
+	require.Equal(t, backend.data[:10], read(0, 10))
+	require.Equal(t, int32(3), backend.reads)
+}
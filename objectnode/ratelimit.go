@@ -38,6 +38,7 @@ var putApi = map[string]string{
 	strings.ToLower(PUT_OBJECT):       PUT,
 	strings.ToLower(COPY_OBJECT):      PUT,
 	strings.ToLower(POST_OBJECT):      PUT,
+	strings.ToLower(APPEND_OBJECT):    PUT,
 	strings.ToLower(UPLOAD_PART):      PUT,
 	strings.ToLower(UPLOAD_PART_COPY): PUT,
 }
@@ -0,0 +1,89 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"net/http"
+)
+
+// responseOverrides are the response headers of GetObject and HeadObject overridden by the
+// request params, so that download links can force file names and types without mutating
+// the object metadata.
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObject.html#API_GetObject_RequestSyntax
+type responseOverrides struct {
+	CacheControl       string
+	ContentDisposition string
+	ContentEncoding    string
+	ContentLanguage    string
+	ContentType        string
+	Expires            string
+}
+
+func (o *responseOverrides) isEmpty() bool {
+	return *o == responseOverrides{}
+}
+
+// parseResponseOverrides parses the response-* params of the request, which are only allowed
+// in signed requests, such as presigned GETs.
+func parseResponseOverrides(r *http.Request, accessKey string) (overrides *responseOverrides, errorCode *ErrorCode) {
+	query := r.URL.Query()
+	overrides = &responseOverrides{
+		CacheControl:       query.Get(ParamResponseCacheControl),
+		ContentDisposition: query.Get(ParamResponseContentDisposition),
+		ContentEncoding:    query.Get(ParamResponseContentEncoding),
+		ContentLanguage:    query.Get(ParamResponseContentLanguage),
+		ContentType:        query.Get(ParamResponseContentType),
+		Expires:            query.Get(ParamResponseExpires),
+	}
+	if overrides.isEmpty() {
+		return
+	}
+	if isAnonymous(accessKey) {
+		return nil, AnonymousResponseOverride
+	}
+	if len(overrides.CacheControl) > 0 && !ValidateCacheControl(overrides.CacheControl) {
+		return nil, InvalidCacheArgument
+	}
+	if len(overrides.Expires) > 0 && !ValidateCacheExpires(overrides.Expires) {
+		return nil, InvalidCacheArgument
+	}
+	return
+}
+
+// setObjectResponseHeaders sets the content headers of the object, the overridden values take
+// precedence over the metadata of the object.
+func setObjectResponseHeaders(w http.ResponseWriter, fileInfo *FSFileInfo, overrides *responseOverrides) {
+	pick := func(override, value string) string {
+		if len(override) > 0 {
+			return override
+		}
+		return value
+	}
+	set := func(key, value string) {
+		if len(value) > 0 {
+			w.Header().Set(key, value)
+		}
+	}
+	if overrides == nil {
+		overrides = &responseOverrides{}
+	}
+
+	set(ContentType, pick(overrides.ContentType, pick(fileInfo.MIMEType, ValueContentTypeStream)))
+	set(ContentDisposition, pick(overrides.ContentDisposition, fileInfo.Disposition))
+	set(CacheControl, pick(overrides.CacheControl, fileInfo.CacheControl))
+	set(Expires, pick(overrides.Expires, fileInfo.Expires))
+	set(ContentLanguage, overrides.ContentLanguage)
+	set(ContentEncoding, overrides.ContentEncoding)
+}
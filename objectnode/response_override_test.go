@@ -0,0 +1,60 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseOverrides(t *testing.T) {
+	info := &FSFileInfo{MIMEType: "text/plain", Disposition: "inline", CacheControl: "no-cache"}
+
+	r := httptest.NewRequest("GET", "/bucket/key", nil)
+	overrides, errorCode := parseResponseOverrides(r, "")
+	require.Nil(t, errorCode)
+	w := httptest.NewRecorder()
+	setObjectResponseHeaders(w, info, overrides)
+	require.Equal(t, "text/plain", w.Header().Get(ContentType))
+	require.Equal(t, "inline", w.Header().Get(ContentDisposition))
+	require.Equal(t, "no-cache", w.Header().Get(CacheControl))
+	require.Empty(t, w.Header().Get(ContentLanguage))
+
+	w = httptest.NewRecorder()
+	setObjectResponseHeaders(w, &FSFileInfo{}, nil)
+	require.Equal(t, ValueContentTypeStream, w.Header().Get(ContentType))
+	require.Empty(t, w.Header().Get(ContentDisposition))
+
+	r = httptest.NewRequest("GET", "/bucket/key?response-content-type=application/json"+
+		"&response-content-disposition=attachment%3B%20filename%3Ddata.json&response-cache-control=no-store"+
+		"&response-content-language=en&response-content-encoding=gzip", nil)
+	_, errorCode = parseResponseOverrides(r, "")
+	require.Equal(t, AnonymousResponseOverride, errorCode)
+	overrides, errorCode = parseResponseOverrides(r, "ak")
+	require.Nil(t, errorCode)
+	w = httptest.NewRecorder()
+	setObjectResponseHeaders(w, info, overrides)
+	require.Equal(t, "application/json", w.Header().Get(ContentType))
+	require.Equal(t, "attachment; filename=data.json", w.Header().Get(ContentDisposition))
+	require.Equal(t, "no-store", w.Header().Get(CacheControl))
+	require.Equal(t, "en", w.Header().Get(ContentLanguage))
+	require.Equal(t, "gzip", w.Header().Get(ContentEncoding))
+
+	r = httptest.NewRequest("GET", "/bucket/key?response-cache-control=invalid", nil)
+	_, errorCode = parseResponseOverrides(r, "ak")
+	require.Equal(t, InvalidCacheArgument, errorCode)
+}
@@ -53,6 +53,8 @@ var (
 	CopySourceSizeTooLarge              = &ErrorCode{ErrorCode: "InvalidRequest", ErrorMessage: "The specified copy source is larger than the maximum allowable size for a copy source: 5368709120", StatusCode: http.StatusBadRequest}
 	InvalidPartOrder                    = &ErrorCode{ErrorCode: "InvalidPartOrder", ErrorMessage: "The list of parts was not in ascending order. Parts list must be specified in order by part number.", StatusCode: http.StatusBadRequest}
 	InvalidPartNumber                   = &ErrorCode{ErrorCode: "InvalidPartNumber", ErrorMessage: "The specified partNumber must be greater than 0.", StatusCode: http.StatusBadRequest}
+	PartNumberNotSatisfiable            = &ErrorCode{ErrorCode: "InvalidPartNumber", ErrorMessage: "The requested partnumber is not satisfiable.", StatusCode: http.StatusRequestedRangeNotSatisfiable}
+	PartNumberWithRange                 = &ErrorCode{ErrorCode: "InvalidRequest", ErrorMessage: "Cannot specify both Range header and partNumber query parameter.", StatusCode: http.StatusBadRequest}
 	InvalidPart                         = &ErrorCode{ErrorCode: "InvalidPart", ErrorMessage: "One or more of the specified parts could not be found. The part might not have been uploaded, or the specified entity tag might not have matched the part's entity tag.", StatusCode: http.StatusBadRequest}
 	InvalidCacheArgument                = &ErrorCode{ErrorCode: "InvalidCacheArgument", ErrorMessage: "Invalid Cache-Control or Expires Argument", StatusCode: http.StatusBadRequest}
 	TooManyTags                         = &ErrorCode{ErrorCode: "TooManyTags", ErrorMessage: "The number of tags exceeds the limit of 10 tags.", StatusCode: http.StatusBadRequest}
@@ -90,6 +92,14 @@ var (
 	ObjectLockConfigurationNotFound     = &ErrorCode{"ObjectLockConfigurationNotFoundError", "Object Lock configuration does not exist for this bucket", http.StatusNotFound}
 	TooManyRequests                     = &ErrorCode{"TooManyRequests", "too many requests, please retry later", http.StatusTooManyRequests}
 	MalformedPOSTRequest                = &ErrorCode{ErrorCode: "MalformedPOSTRequest", ErrorMessage: "The body of your POST request is not well-formed multipart/form-data.", StatusCode: http.StatusBadRequest}
+	InvalidStorageClass                 = &ErrorCode{ErrorCode: "InvalidStorageClass", ErrorMessage: "The storage class you specified is not valid.", StatusCode: http.StatusBadRequest}
+	InvalidObjectState                  = &ErrorCode{ErrorCode: "InvalidObjectState", ErrorMessage: "The operation is not valid for the object's storage class.", StatusCode: http.StatusForbidden}
+	NoSuchEncryptionConfiguration       = &ErrorCode{ErrorCode: "ServerSideEncryptionConfigurationNotFoundError", ErrorMessage: "The server side encryption configuration was not found.", StatusCode: http.StatusNotFound}
+	InvalidEncryptionAlgorithm          = &ErrorCode{ErrorCode: "InvalidEncryptionAlgorithmError", ErrorMessage: "The encryption request you specified is not valid. Supported value: AES256, aws:kms.", StatusCode: http.StatusBadRequest}
+	InvalidEncryptionKeyID              = &ErrorCode{ErrorCode: "InvalidArgument", ErrorMessage: "The KMS key ID is only allowed with aws:kms server side encryption.", StatusCode: http.StatusBadRequest}
+	PositionNotEqualToLength            = &ErrorCode{ErrorCode: "PositionNotEqualToLength", ErrorMessage: "Position is not equal to file length.", StatusCode: http.StatusConflict}
+	ObjectNotAppendable                 = &ErrorCode{ErrorCode: "ObjectNotAppendable", ErrorMessage: "The object is not appendable.", StatusCode: http.StatusConflict}
+	AnonymousResponseOverride           = &ErrorCode{ErrorCode: "InvalidRequest", ErrorMessage: "Request specific response headers cannot be used for anonymous GET requests.", StatusCode: http.StatusBadRequest}
 )
 
 type ErrorCode struct {
@@ -119,6 +129,7 @@ func (ec *ErrorCode) ServeResponse(w http.ResponseWriter, r *http.Request) {
 	// traceMiddleWare send exception request to prometheus via status code
 	SetResponseStatusCode(r, strconv.Itoa(ec.StatusCode))
 	SetResponseErrorMessage(r, ec.ErrorMessage)
+	SetResponseErrorCode(r, ec.ErrorCode)
 
 	errorResponse := ErrorResponse{
 		Code:      ec.ErrorCode,
@@ -81,6 +81,7 @@ const (
 	HEAD_OBJECT                = "HeadObject"                 // api:  HEAD /<ObjectName> , host=<bucket>.domain
 	OPTIONS_OBJECT             = "OptionsObject"              // api:  OPTIONS /<ObjectName>, host=<bucket>.domain
 	POST_OBJECT                = "PostObject"                 // api:  Post /  , host=<bucket>.domain
+	APPEND_OBJECT              = "AppendObject"               // api:  Post /<objname>?append&position=<Position>, host=<bucket>.domain
 	PUT_OBJECT                 = "PutObject"                  // api:  Put  /<objname>,  host=<bucket>.domain
 	COPY_OBJECT                = "CopyObject"                 // api:  Put /<destObjname>  ,host=<destbucket>.domain,  header["x-amz-copy-source"]
 	PUT_OBJECT_ACL             = "PutObjectAcl"               // api:  Put /<ObjectName>?acl  , host=<bucket>.domain
@@ -154,6 +154,32 @@ const (
 
 	// s3 QoS config refresh interval
 	s3QoSRefreshIntervalSec = "s3QoSRefreshIntervalSec"
+
+	// Map type configuration item, used to cache small ranged reads of large objects by aligned blocks
+	// in memory and optionally on local disk. For detailed parameters, see the RangeCacheConfig structure.
+	// Example:
+	//		{
+	//			"rangeCache": {
+	//				"memoryMB": 1024,
+	//				"diskPath": "/cfs/objectnode/rangecache",
+	//				"diskMB": 102400,
+	//				"blockKB": 1024,
+	//				"maxRangeKB": 1024,
+	//				"minObjectMB": 64
+	//			}
+	//		}
+	configRangeCache = "rangeCache"
+
+	// Map type configuration item, used to export the top-N access keys by traffic in every interval.
+	// For detailed parameters, see the APIMetricsConfig structure.
+	// Example:
+	//		{
+	//			"apiMetrics": {
+	//				"topN": 10,
+	//				"intervalSec": 60
+	//			}
+	//		}
+	configAPIMetrics = "apiMetrics"
 )
 
 // Default of configuration value
@@ -174,6 +200,7 @@ var (
 	regexpListen     = regexp.MustCompile(`^(\d)+$`)
 	objMetaCache     *ObjMetaCache
 	blockCache       *bcache.BcacheClient
+	rangeCache       *RangeCache
 	ebsClient        *blobstore.BlobStoreClient
 	writeThreads     = 4
 	readThreads      = 4
@@ -205,6 +232,7 @@ type ObjectNode struct {
 	rateLimit               RateLimiter
 	limitMutex              sync.RWMutex
 	disableCreateBucketByS3 bool
+	apiMetrics              *apiMetrics
 }
 
 func (o *ObjectNode) Start(cfg *config.Config) (err error) {
@@ -322,6 +350,31 @@ func (o *ObjectNode) loadConfig(cfg *config.Config) (err error) {
 		blockCache = bcache.NewBcacheClient()
 	}
 
+	// parse range cache
+	if rawRangeCache := cfg.GetValue(configRangeCache); rawRangeCache != nil {
+		var conf RangeCacheConfig
+		if err = ParseJSONEntity(rawRangeCache, &conf); err != nil {
+			err = fmt.Errorf("invalid %v configuration: %v", configRangeCache, err)
+			return
+		}
+		if rangeCache, err = NewRangeCache(conf); err != nil {
+			err = fmt.Errorf("init range cache fail: %v", err)
+			return
+		}
+		log.LogInfof("loadConfig: setup config: %v(%v)", configRangeCache, rawRangeCache)
+	}
+
+	// parse api metrics
+	var metricsConf APIMetricsConfig
+	if rawAPIMetrics := cfg.GetValue(configAPIMetrics); rawAPIMetrics != nil {
+		if err = ParseJSONEntity(rawAPIMetrics, &metricsConf); err != nil {
+			err = fmt.Errorf("invalid %v configuration: %v", configAPIMetrics, err)
+			return
+		}
+		log.LogInfof("loadConfig: setup config: %v(%v)", configAPIMetrics, rawAPIMetrics)
+	}
+	o.apiMetrics = newAPIMetrics(metricsConf)
+
 	return
 }
 
@@ -416,6 +469,9 @@ func handleStart(s common.Server, cfg *config.Config) (err error) {
 		o.limitMutex.Unlock()
 	}
 
+	o.apiMetrics.start()
+	o.closes = append(o.closes, o.apiMetrics.stop)
+
 	// start rest api
 	if err = o.startMuxRestAPI(); err != nil {
 		log.LogInfof("handleStart: start rest api fail: err(%v)", err)
@@ -0,0 +1,122 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+// The data of objects with cold storage class is written to blobstore directly, even if
+// the volume is hot, and the objects must be restored before they can be read.
+const (
+	MaxRestoreDays = 365
+
+	defaultEbsBlockSize = 8 * 1024 * 1024
+)
+
+type RestoreRequest struct {
+	XMLName xml.Name `xml:"RestoreRequest"`
+	Days    int      `xml:"Days"`
+}
+
+func (r *RestoreRequest) Validate() error {
+	if r.Days <= 0 || r.Days > MaxRestoreDays {
+		return fmt.Errorf("restore days must be in range [1, %v]", MaxRestoreDays)
+	}
+	return nil
+}
+
+func isColdStorageClass(class string) bool {
+	return class == StorageClassCold || class == StorageClassGlacier
+}
+
+// parseStorageClass parses the storage class of a new object in the volume, STANDARD is returned
+// if not specified. The cold storage class is invalid if the volume is hot and the object node has
+// no blobstore access configured.
+func (v *Volume) parseStorageClass(raw string) (class string, errorCode *ErrorCode) {
+	class = strings.ToUpper(strings.TrimSpace(raw))
+	switch class {
+	case "":
+		return StorageClassStandard, nil
+	case StorageClassStandard:
+		return class, nil
+	case StorageClassCold, StorageClassGlacier:
+		if proto.IsHot(v.volType) && ebsClient == nil {
+			return "", InvalidStorageClass
+		}
+		return class, nil
+	default:
+		return "", InvalidStorageClass
+	}
+}
+
+// storedInEbs returns whether the data of the object with the storage class is stored in blobstore.
+func (v *Volume) storedInEbs(class string) bool {
+	return proto.IsCold(v.volType) || isColdStorageClass(class)
+}
+
+func (v *Volume) getEbsBlockSize() int {
+	if v.ebsBlockSize > 0 {
+		return v.ebsBlockSize
+	}
+	return defaultEbsBlockSize
+}
+
+// Archived returns whether the object has cold storage class and is not restored or the
+// restored copy has expired.
+func (info *FSFileInfo) Archived() bool {
+	return isColdStorageClass(info.StorageClass) && !time.Now().Before(info.RestoreExpiry)
+}
+
+func parseRestoreExpiry(raw string) time.Time {
+	if raw == "" {
+		return time.Time{}
+	}
+	sec, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+func formatRestoreExpiry(expiry time.Time) string {
+	return strconv.FormatInt(expiry.Unix(), 10)
+}
+
+// restoreHeader returns the value of x-amz-restore header of the restored copy. The restoration
+// is completed by the restore request itself because the data is readable in blobstore, so there
+// is no ongoing restoration.
+func restoreHeader(expiry time.Time) string {
+	return fmt.Sprintf("ongoing-request=\"false\", expiry-date=\"%s\"", formatTimeRFC1123(expiry))
+}
+
+// setStorageClassHeader sets the storage class of the object, and the restore state of the
+// cold object only if it's restored and not expired, like never restored.
+func setStorageClassHeader(w http.ResponseWriter, info *FSFileInfo) {
+	if info.StorageClass == "" || info.StorageClass == StorageClassStandard {
+		return
+	}
+	w.Header().Set(XAmzStorageClass, info.StorageClass)
+	if isColdStorageClass(info.StorageClass) && !info.Archived() {
+		w.Header().Set(XAmzRestore, restoreHeader(info.RestoreExpiry))
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/xml"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+func TestParseStorageClass(t *testing.T) {
+	hot := &Volume{volType: proto.VolumeTypeHot}
+	cold := &Volume{volType: proto.VolumeTypeCold}
+
+	tests := []struct {
+		vol       *Volume
+		raw       string
+		class     string
+		errorCode *ErrorCode
+	}{
+		{hot, "", StorageClassStandard, nil},
+		{hot, "standard", StorageClassStandard, nil},
+		{hot, "COLD", "", InvalidStorageClass}, // no blobstore access
+		{hot, "REDUCED_REDUNDANCY", "", InvalidStorageClass},
+		{cold, "", StorageClassStandard, nil},
+		{cold, "cold", StorageClassCold, nil},
+		{cold, "GLACIER", StorageClassGlacier, nil},
+	}
+	for _, tt := range tests {
+		class, errorCode := tt.vol.parseStorageClass(tt.raw)
+		require.Equal(t, tt.class, class, tt.raw)
+		require.Equal(t, tt.errorCode, errorCode, tt.raw)
+	}
+
+	require.False(t, hot.storedInEbs(StorageClassStandard))
+	require.True(t, hot.storedInEbs(StorageClassGlacier))
+	require.True(t, cold.storedInEbs(StorageClassStandard))
+	require.Equal(t, defaultEbsBlockSize, hot.getEbsBlockSize())
+}
+
+func TestStorageClassRestore(t *testing.T) {
+	info := &FSFileInfo{StorageClass: StorageClassStandard}
+	require.False(t, info.Archived())
+
+	info.StorageClass = StorageClassCold
+	require.True(t, info.Archived())
+
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	info.RestoreExpiry = parseRestoreExpiry(formatRestoreExpiry(expiry))
+	require.True(t, expiry.Equal(info.RestoreExpiry))
+	require.False(t, info.Archived())
+	info.RestoreExpiry = time.Now().Add(-time.Hour)
+	require.True(t, info.Archived())
+	require.True(t, parseRestoreExpiry("invalid").IsZero())
+
+	w := httptest.NewRecorder()
+	info.RestoreExpiry = expiry
+	setStorageClassHeader(w, info)
+	require.Equal(t, StorageClassCold, w.Header().Get(XAmzStorageClass))
+	require.Equal(t, restoreHeader(expiry), w.Header().Get(XAmzRestore))
+
+	// the expired or never restored copy has no restore state
+	w = httptest.NewRecorder()
+	info.RestoreExpiry = time.Now().Add(-time.Hour)
+	setStorageClassHeader(w, info)
+	require.Equal(t, StorageClassCold, w.Header().Get(XAmzStorageClass))
+	require.Empty(t, w.Header().Get(XAmzRestore))
+	w = httptest.NewRecorder()
+	setStorageClassHeader(w, &FSFileInfo{StorageClass: StorageClassGlacier})
+	require.Empty(t, w.Header().Get(XAmzRestore))
+
+	w = httptest.NewRecorder()
+	setStorageClassHeader(w, &FSFileInfo{StorageClass: StorageClassStandard})
+	require.Empty(t, w.Header().Get(XAmzStorageClass))
+
+	restore := new(RestoreRequest)
+	require.NoError(t, xml.Unmarshal([]byte(`<RestoreRequest><Days>2</Days></RestoreRequest>`), restore))
+	require.Equal(t, 2, restore.Days)
+	require.NoError(t, restore.Validate())
+	restore.Days = 0
+	require.Error(t, restore.Validate())
+	restore.Days = MaxRestoreDays + 1
+	require.Error(t, restore.Validate())
+}
@@ -0,0 +1,84 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/data/stream"
+	"github.com/cubefs/cubefs/sdk/meta"
+)
+
+// MetaWrapper is the meta client of the volume, implemented by *meta.MetaWrapper.
+type MetaWrapper interface {
+	Owner() string
+	OSSSecure() (accessKey, secretKey string)
+	QuotaEnabled() bool
+	IsQuotaLimitedById(inodeId uint64, size bool, files bool) bool
+	Close() error
+
+	Lookup_ll(parentID uint64, name string) (inode uint64, mode uint32, err error)
+	Create_ll(parentID uint64, name string, mode, uid, gid uint32, target []byte, fullPath string, ignoreExist bool) (*proto.InodeInfo, error)
+	Delete_ll(parentID uint64, name string, isDir bool, fullPath string) (*proto.InodeInfo, error)
+	DeleteWithCond_ll(parentID, cond uint64, name string, isDir bool, fullPath string) (*proto.InodeInfo, error)
+	ReadDir_ll(parentID uint64) ([]proto.Dentry, error)
+	ReadDirLimit_ll(parentID uint64, from string, limit uint64) ([]proto.Dentry, error)
+	DentryCreate_ll(parentID uint64, name string, inode uint64, mode uint32, fullPath string) error
+	DentryUpdate_ll(parentID uint64, name string, inode uint64, fullPath string) (oldInode uint64, err error)
+
+	InodeCreate_ll(parentID uint64, mode, uid, gid uint32, target []byte, quotaIds []uint64, fullPath string) (*proto.InodeInfo, error)
+	InodeGet_ll(inode uint64) (*proto.InodeInfo, error)
+	BatchInodeGet(inodes []uint64) []*proto.InodeInfo
+	InodeLink_ll(inode uint64, fullPath string) (*proto.InodeInfo, error)
+	InodeUnlink_ll(inode uint64, fullPath string) (*proto.InodeInfo, error)
+	InodeDelete_ll(inode uint64, fullPath string) error
+	Evict(inode uint64, fullPath string) error
+
+	GetExtents(inode uint64) (gen uint64, size uint64, extents []proto.ExtentKey, err error)
+	GetObjExtents(inode uint64) (gen uint64, size uint64, extents []proto.ExtentKey, objExtents []proto.ObjExtentKey, err error)
+	AppendExtentKeys(inode uint64, eks []proto.ExtentKey) error
+	AppendExtentKeysIfSize(inode, size uint64, eks []proto.ExtentKey) error
+	AppendObjExtentKeys(inode uint64, eks []proto.ObjExtentKey) error
+
+	XAttrSet_ll(inode uint64, name, value []byte) error
+	BatchSetXAttr_ll(inode uint64, attrs map[string]string) error
+	XAttrGet_ll(inode uint64, name string) (*proto.XAttrInfo, error)
+	XAttrGetAll_ll(inode uint64) (*proto.XAttrInfo, error)
+	BatchGetXAttr(inodes []uint64, keys []string) ([]*proto.XAttrInfo, error)
+	XAttrsList_ll(inode uint64) ([]string, error)
+	XAttrDel_ll(inode uint64, name string) error
+
+	InitMultipart_ll(path string, extend map[string]string) (multipartId string, err error)
+	GetMultipart_ll(path, multipartId string) (info *proto.MultipartInfo, err error)
+	AddMultipartPart_ll(path, multipartId string, partId uint16, size uint64, md5 string, inodeInfo *proto.InodeInfo) (oldInode uint64, updated bool, err error)
+	RemoveMultipart_ll(path, multipartID string) (err error)
+	ListMultipart_ll(prefix, delimiter, keyMarker string, multipartIdMarker string, maxUploads uint64) (sessionResponse []*proto.MultipartInfo, err error)
+}
+
+// ExtentClient is the data client of the volume, implemented by *stream.ExtentClient.
+type ExtentClient interface {
+	OpenStream(inode uint64) error
+	CloseStream(inode uint64) error
+	EvictStream(inode uint64) error
+	Read(inode uint64, data []byte, offset int, size int) (read int, err error)
+	Write(inode uint64, offset int, data []byte, flags int, checkFunc func() error) (write int, err error)
+	Flush(inode uint64) error
+	UidIsLimited(uid uint32) bool
+	Close() error
+}
+
+var (
+	_ MetaWrapper  = (*meta.MetaWrapper)(nil)
+	_ ExtentClient = (*stream.ExtentClient)(nil)
+)
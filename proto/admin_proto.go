@@ -56,7 +56,10 @@ const (
 	AdminVolExpand                            = "/vol/expand"
 	AdminVolForbidden                         = "/vol/forbidden"
 	AdminVolEnableAuditLog                    = "/vol/auditlog"
+	AdminVolAuditTrail                        = "/vol/auditTrail"
 	AdminVolSetDpRepairBlockSize              = "/vol/setDpRepairBlockSize"
+	AdminRenameVol                            = "/vol/rename"
+	AdminCloneVol                             = "/vol/clone"
 	AdminCreateVol                            = "/admin/createVol"
 	AdminGetVol                               = "/admin/getVol"
 	AdminClusterFreeze                        = "/cluster/freeze"
@@ -79,6 +82,8 @@ const (
 	AdminUpdateDomainDataUseRatio             = "/admin/updateDomainDataRatio"
 	AdminUpdateZoneExcludeRatio               = "/admin/updateZoneExcludeRatio"
 	AdminSetNodeRdOnly                        = "/admin/setNodeRdOnly"
+	AdminSetNodeAllocWeight                   = "/admin/setNodeAllocWeight"
+	AdminSetNodeLabels                        = "/admin/setNodeLabels"
 	AdminSetDpRdOnly                          = "/admin/setDpRdOnly"
 	AdminSetConfig                            = "/admin/setConfig"
 	AdminGetConfig                            = "/admin/getConfig"
@@ -109,6 +114,22 @@ const (
 	AdminUpdateDecommissionDiskLimit = "/admin/updateDecommissionDiskLimit"
 	AdminEnableAutoDecommissionDisk  = "/admin/enableAutoDecommissionDisk"
 	AdminQueryAutoDecommissionDisk   = "/admin/queryAutoDecommissionDisk"
+
+	AdminGetTopView = "/admin/top"
+
+	AdminSimulatePlacement = "/admin/simulatePlacement"
+
+	AdminSetClientUpgrade = "/admin/setClientUpgrade"
+	AdminGetClientUpgrade = "/admin/getClientUpgrade"
+
+	AdminStartConfigRollout    = "/admin/startConfigRollout"
+	AdminGetConfigRollout      = "/admin/getConfigRollout"
+	AdminPromoteConfigRollout  = "/admin/promoteConfigRollout"
+	AdminRollbackConfigRollout = "/admin/rollbackConfigRollout"
+
+	AdminSetFeatureFlag    = "/admin/setFeatureFlag"
+	AdminGetFeatureFlags   = "/admin/getFeatureFlags"
+	AdminVolSetFeatureFlag = "/vol/setFeatureFlag"
 	// graphql master api
 	AdminClusterAPI               = "/api/cluster"
 	AdminUserAPI                  = "/api/user"
@@ -130,6 +151,7 @@ const (
 	ClientMetaPartition      = "/metaPartition/get"
 	ClientVolStat            = "/client/volStat"
 	ClientMetaPartitions     = "/client/metaPartitions"
+	ClientVolFeatureFlags    = "/client/featureFlags"
 
 	// qos api
 	QosGetStatus           = "/qos/getStatus"
@@ -233,6 +255,8 @@ const (
 	UserGetAKInfo       = "/user/akInfo"
 	UserTransferVol     = "/user/transferVol"
 	UserList            = "/user/list"
+	UserSetQuota        = "/user/setQuota"
+	UserGetUsage        = "/user/usage"
 	UsersOfVol          = "/vol/users"
 	// graphql api for header
 	HeadAuthorized  = "Authorization"
@@ -273,6 +297,9 @@ var GApiInfo map[string]string = map[string]string{
 	"adminupdatevol":                     AdminUpdateVol,
 	"adminvolshrink":                     AdminVolShrink,
 	"adminvolexpand":                     AdminVolExpand,
+	"adminvolaudittrail":                 AdminVolAuditTrail,
+	"adminrenamevol":                     AdminRenameVol,
+	"adminclonevol":                      AdminCloneVol,
 	"admincreatevol":                     AdminCreateVol,
 	"admingetvol":                        AdminGetVol,
 	"adminclusterfreeze":                 AdminClusterFreeze,
@@ -293,10 +320,23 @@ var GApiInfo map[string]string = map[string]string{
 	"adminupdatedomaindatauseratio":      AdminUpdateDomainDataUseRatio,
 	"adminupdatezoneexcluderatio":        AdminUpdateZoneExcludeRatio,
 	"adminsetnoderdonly":                 AdminSetNodeRdOnly,
+	"adminsetnodeallocweight":            AdminSetNodeAllocWeight,
+	"adminsetnodelabels":                 AdminSetNodeLabels,
 	"adminsetdprdonly":                   AdminSetDpRdOnly,
 	"admindatapartitionchangeleader":     AdminDataPartitionChangeLeader,
 	"adminsetdpdiscard":                  AdminSetDpDiscard,
 	"admingetdiscarddp":                  AdminGetDiscardDp,
+	"admingettopview":                    AdminGetTopView,
+	"adminsimulateplacement":             AdminSimulatePlacement,
+	"adminsetclientupgrade":              AdminSetClientUpgrade,
+	"admingetclientupgrade":              AdminGetClientUpgrade,
+	"adminstartconfigrollout":            AdminStartConfigRollout,
+	"admingetconfigrollout":              AdminGetConfigRollout,
+	"adminpromoteconfigrollout":          AdminPromoteConfigRollout,
+	"adminrollbackconfigrollout":         AdminRollbackConfigRollout,
+	"adminsetfeatureflag":                AdminSetFeatureFlag,
+	"admingetfeatureflags":               AdminGetFeatureFlags,
+	"adminvolsetfeatureflag":             AdminVolSetFeatureFlag,
 
 	// "adminclusterapi":                 AdminClusterAPI,
 	// "adminuserapi":                    AdminUserAPI,
@@ -311,6 +351,7 @@ var GApiInfo map[string]string = map[string]string{
 	"clientvol":              ClientVol,
 	"clientmetapartition":    ClientMetaPartition,
 	"clientvolstat":          ClientVolStat,
+	"clientvolfeatureflags":  ClientVolFeatureFlags,
 	"clientmetapartitions":   ClientMetaPartitions,
 	"qosgetstatus":           QosGetStatus,
 	"qosgetclientslimitinfo": QosGetClientsLimitInfo,
@@ -359,6 +400,8 @@ var GApiInfo map[string]string = map[string]string{
 	"usergetakinfo":                   UserGetAKInfo,
 	"usertransfervol":                 UserTransferVol,
 	"userlist":                        UserList,
+	"usersetquota":                    UserSetQuota,
+	"usergetusage":                    UserGetUsage,
 	"usersofvol":                      UsersOfVol,
 }
 
@@ -537,6 +580,7 @@ type CreateDataPartitionRequest struct {
 	DecommissionedDisks []string
 	IsMultiVer          bool
 	VerSeq              uint64
+	WriteEpoch          uint64
 }
 
 // CreateDataPartitionResponse defines the response to the request of creating a data partition.
@@ -713,6 +757,8 @@ type HeartBeatRequest struct {
 	DisableAuditVols     []string
 	DecommissionDisks    []string // NOTE: for datanode
 	VolDpRepairBlockSize map[string]uint64
+	DpWriteEpochs        map[uint64]uint64 // NOTE: for datanode, write epochs of the partitions on the node
+	VolFsyncPolicy       map[string]string // NOTE: for datanode, fsync policies of the vols not of the default
 }
 
 // DataPartitionReport defines the partition report.
@@ -729,6 +775,186 @@ type DataPartitionReport struct {
 	DecommissionRepairProgress float64
 	LocalPeers                 []Peer
 	TriggerDiskError           bool
+	OpStat                     PartitionOpStat
+}
+
+// ClientUpgradeInfo defines the client version advertised by master,
+// clients with auto upgrade enabled download and switch to it.
+type ClientUpgradeInfo struct {
+	Version   string // empty means auto upgrade is disabled
+	URL       string // download url of the client binary
+	Checksum  string // hex encoded sha256 of the binary
+	Signature string // base64 encoded ed25519 signature of the sha256 digest
+}
+
+// PartitionOpStat defines the io statistics of a partition replica collected since the last heartbeat.
+type PartitionOpStat struct {
+	ReadOps      uint64
+	WriteOps     uint64
+	ReadBytes    uint64
+	WriteBytes   uint64
+	AvgLatencyUs uint64
+	Interval     int64 // seconds
+}
+
+// Qps returns the read and write operations per second.
+func (s *PartitionOpStat) Qps() uint64 {
+	if s.Interval <= 0 {
+		return 0
+	}
+	return (s.ReadOps + s.WriteOps) / uint64(s.Interval)
+}
+
+// Bandwidth returns the read and write bytes per second.
+func (s *PartitionOpStat) Bandwidth() uint64 {
+	if s.Interval <= 0 {
+		return 0
+	}
+	return (s.ReadBytes + s.WriteBytes) / uint64(s.Interval)
+}
+
+const (
+	TopSortByQps       = "qps"
+	TopSortByBandwidth = "bw"
+	TopSortByLatency   = "latency"
+)
+
+// TopItem defines the load of a volume, a data partition or a client.
+type TopItem struct {
+	Name         string
+	VolName      string
+	Qps          uint64
+	Bandwidth    uint64 // bytes per second
+	AvgLatencyUs uint64
+}
+
+// TopView defines the hottest volumes, data partitions and clients of the cluster.
+type TopView struct {
+	SortBy     string
+	Volumes    []*TopItem
+	Partitions []*TopItem
+	Clients    []*TopItem
+	ReportTime int64
+}
+
+// PlacementSimulation defines the simulated effect on the partitions of removing nodes or zones
+// and adding nodes, the cluster is not changed.
+type PlacementSimulation struct {
+	RemovedDataNodes []string
+	RemovedMetaNodes []string
+	AddedDataNodes   int
+	AddedMetaNodes   int
+	AddedZone        string
+	DataPartitions   *PlacementImpact
+	MetaPartitions   *PlacementImpact
+}
+
+// PlacementImpact defines the simulated state of the data or meta partitions. The replicas on
+// the removed nodes and the inactive nodes are lost.
+type PlacementImpact struct {
+	Total           int
+	Degraded        int // partitions losing some replicas but keeping the quorum
+	BelowQuorum     int // partitions losing the quorum, which are unavailable
+	AllReplicasLost int // partitions losing all replicas, which are part of BelowQuorum
+	LostReplicas    int
+	// lost replicas which can't be placed on the remaining and added nodes of the zones of volume
+	UnplaceableReplicas int
+	// used size of the lost replicas and available space of the remaining and added nodes, only for data partitions
+	RequiredSpace  uint64
+	AvailableSpace uint64
+	// the first partitions below quorum
+	BelowQuorumPartitions []*PlacementPartition
+}
+
+// PlacementPartition defines the simulated replicas of a partition.
+type PlacementPartition struct {
+	PartitionID uint64
+	VolName     string
+	ReplicaNum  uint8
+	AliveHosts  []string
+	LostHosts   []string
+}
+
+// the status of the config rollouts
+const (
+	ConfigRolloutCanary     = "canary"
+	ConfigRolloutPromoted   = "promoted"
+	ConfigRolloutRolledBack = "rolledBack"
+)
+
+// ConfigRollout defines the progressive rollout of a cluster setting carried by the heartbeats.
+// The new value is sent to the canary nodes first, and promoted to all the nodes of the type
+// after the observation if the heartbeat error rate of the canary nodes doesn't regress over the
+// other nodes, otherwise it is rolled back.
+type ConfigRollout struct {
+	ID              uint64
+	Key             string
+	Value           string
+	OldValue        string
+	NodeType        string
+	CanaryNodes     []string
+	Status          string
+	ObserveSec      int64
+	MaxErrRateDelta float64
+	// the samples of the nodes every heartbeat, a failed heartbeat or an inactive node is an error
+	CanarySamples  int
+	CanaryErrors   int
+	ControlSamples int
+	ControlErrors  int
+	StartTime      int64
+	EndTime        int64
+	Reason         string
+}
+
+// ConfigRolloutView defines the rollout in progress and the recent ones.
+type ConfigRolloutView struct {
+	Current *ConfigRollout
+	History []*ConfigRollout
+}
+
+// the features enabled incrementally by the feature flags of master
+const (
+	FeatureAtomicRename = "atomic-rename"
+	FeatureFollowerRead = "follower-read"
+	FeatureInlineData   = "inline-data"
+)
+
+// FeatureFlagNames is the registry of the feature flags known by master.
+var FeatureFlagNames = []string{FeatureAtomicRename, FeatureFollowerRead, FeatureInlineData}
+
+// the modes of the feature flags, the flag never set is off
+const (
+	FeatureFlagOff   = "off"   // disabled for all the volumes, whatever they opt in
+	FeatureFlagOptIn = "optIn" // enabled for the volumes opted in only
+	FeatureFlagOn    = "on"    // enabled for all the volumes
+)
+
+func IsFeatureFlagName(name string) bool {
+	for _, n := range FeatureFlagNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FeatureFlag defines the mode of a feature flag in the cluster and the volumes opted in.
+type FeatureFlag struct {
+	Name    string
+	Mode    string
+	Volumes []string
+}
+
+// VolFeatureFlags defines the feature flags the volume opts in, and the features enabled for it
+// by the modes of the flags, which are queried by the components serving the volume.
+type VolFeatureFlags struct {
+	VolName string
+	OptIn   []string
+	Enabled map[string]bool
+}
+
+func (f *VolFeatureFlags) IsEnabled(name string) bool {
+	return f != nil && f.Enabled[name]
 }
 
 type DataNodeQosResponse struct {
@@ -911,6 +1137,8 @@ type DataPartitionResponse struct {
 	IsRecover     bool
 	PartitionTTL  int64
 	IsDiscard     bool
+	WriteEpoch    uint64 // bumped by master whenever the hosts change, to fence the stale writes
+	IsShared      bool   // the partition of the clone source, which is read only and never deleted by the clone
 }
 
 // DataPartitionsView defines the view of a data partition
@@ -1086,6 +1314,17 @@ type UidSimpleInfo struct {
 	Limited bool
 }
 
+// VolAuditRecord defines a namespace-changing admin operation of a volume recorded by master
+type VolAuditRecord struct {
+	Time      int64  `json:"time"` // unix nano
+	Vol       string `json:"vol"`
+	Op        string `json:"op"`
+	Principal string `json:"principal"` // authenticated client id or user id
+	Client    string `json:"client"`    // remote address of the request
+	Detail    string `json:"detail"`
+	Result    string `json:"result"`
+}
+
 // SimpleVolView defines the simple view of a volume
 type SimpleVolView struct {
 	ID                      uint64
@@ -1113,6 +1352,7 @@ type SimpleVolView struct {
 	EnableToken             bool
 	EnablePosixAcl          bool
 	EnableQuota             bool
+	CaseInsensitive         bool
 	EnableTransactionV1     string
 	EnableTransaction       string
 	TxTimeout               int64
@@ -1146,6 +1386,18 @@ type SimpleVolView struct {
 	DeleteExecTime         time.Time
 	DpRepairBlockSize      uint64
 	EnableAutoDpMetaRepair bool
+
+	// names of the volume before renamed, which are still resolved to it
+	PreviousNames []string
+	// the volume which is cloned from, and the snapshot version of it which the clone shares data with
+	CloneSource string
+	CloneVerSeq uint64
+	// partitions are only allocated on the nodes with all of the affinity labels
+	// and none of the anti-affinity labels
+	AffinityLabels     []string
+	AntiAffinityLabels []string
+	// when the extents of the volume are fsynced on the data nodes
+	FsyncPolicy string
 }
 
 type NodeSetInfo struct {
@@ -1275,6 +1527,19 @@ func IsHot(typ int) bool {
 	return typ == VolumeTypeHot
 }
 
+// the fsync policies of the extents of the volume, from the most durable to the fastest
+const (
+	FsyncPolicyAlways   = "always"   // every write is fsynced before replied
+	FsyncPolicyInterval = "interval" // the sync writes are fsynced, the others by the periodic flush
+	FsyncPolicyNever    = "never"    // never fsynced but on close, left to the writeback of the os
+
+	DefaultFsyncPolicy = FsyncPolicyInterval
+)
+
+func IsValidFsyncPolicy(policy string) bool {
+	return policy == FsyncPolicyAlways || policy == FsyncPolicyInterval || policy == FsyncPolicyNever
+}
+
 const (
 	NoCache = 0
 	RCache  = 1
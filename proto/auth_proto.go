@@ -200,12 +200,14 @@ const (
 	MsgMasterFetchVolViewReq MsgType = MsgMasterAPIAccessReq + 0x10000
 
 	// Master API cluster management
-	MsgMasterClusterFreezeReq    MsgType = MsgMasterAPIAccessReq + 0x20100
-	MsgMasterAddRaftNodeReq      MsgType = MsgMasterAPIAccessReq + 0x20200
-	MsgMasterRemoveRaftNodeReq   MsgType = MsgMasterAPIAccessReq + 0x20300
-	MsgMasterSetNodeInfoReq      MsgType = MsgMasterAPIAccessReq + 0x20400
-	MsgMasterSetNodeRdOnlyReq    MsgType = MsgMasterAPIAccessReq + 0x20500
-	MsgMasterAutoDecommissionReq MsgType = MsgMasterAPIAccessReq + 0x20600
+	MsgMasterClusterFreezeReq      MsgType = MsgMasterAPIAccessReq + 0x20100
+	MsgMasterAddRaftNodeReq        MsgType = MsgMasterAPIAccessReq + 0x20200
+	MsgMasterRemoveRaftNodeReq     MsgType = MsgMasterAPIAccessReq + 0x20300
+	MsgMasterSetNodeInfoReq        MsgType = MsgMasterAPIAccessReq + 0x20400
+	MsgMasterSetNodeRdOnlyReq      MsgType = MsgMasterAPIAccessReq + 0x20500
+	MsgMasterAutoDecommissionReq   MsgType = MsgMasterAPIAccessReq + 0x20600
+	MsgMasterSetNodeAllocWeightReq MsgType = MsgMasterAPIAccessReq + 0x20700
+	MsgMasterSetNodeLabelsReq      MsgType = MsgMasterAPIAccessReq + 0x20800
 
 	// Master API volume management
 	MsgMasterCreateVolReq MsgType = MsgMasterAPIAccessReq + 0x30100
@@ -263,6 +265,7 @@ const (
 	MsgMasterUserRemovePolicyReq    MsgType = MsgMasterAPIAccessReq + 0x80500
 	MsgMasterUserDeleteVolPolicyReq MsgType = MsgMasterAPIAccessReq + 0x80600
 	MsgMasterUserTransferVolReq     MsgType = MsgMasterAPIAccessReq + 0x80700
+	MsgMasterUserSetQuotaReq        MsgType = MsgMasterAPIAccessReq + 0x80800
 
 	// Master API zone management
 	MsgMasterUpdateZoneReq MsgType = MsgMasterAPIAccessReq + 0x90100
@@ -288,12 +291,14 @@ var MsgType2ResourceMap = map[MsgType]string{
 	MsgMasterFetchVolViewReq: "master:getvol",
 
 	// Master API cluster management
-	MsgMasterClusterFreezeReq:    "master:clusterfreeze",
-	MsgMasterAddRaftNodeReq:      "master:addraftnode",
-	MsgMasterRemoveRaftNodeReq:   "master:removeraftnode",
-	MsgMasterSetNodeInfoReq:      "master:setnodeinfo",
-	MsgMasterSetNodeRdOnlyReq:    "master:sernoderdonly",
-	MsgMasterAutoDecommissionReq: "master:autodecommission",
+	MsgMasterClusterFreezeReq:      "master:clusterfreeze",
+	MsgMasterAddRaftNodeReq:        "master:addraftnode",
+	MsgMasterRemoveRaftNodeReq:     "master:removeraftnode",
+	MsgMasterSetNodeInfoReq:        "master:setnodeinfo",
+	MsgMasterSetNodeRdOnlyReq:      "master:sernoderdonly",
+	MsgMasterAutoDecommissionReq:   "master:autodecommission",
+	MsgMasterSetNodeAllocWeightReq: "master:setnodeallocweight",
+	MsgMasterSetNodeLabelsReq:      "master:setnodelabels",
 
 	// Master API volume management
 	MsgMasterCreateVolReq: "master:createvol",
@@ -351,6 +356,7 @@ var MsgType2ResourceMap = map[MsgType]string{
 	MsgMasterUserRemovePolicyReq:    "master:userremotepolicy",
 	MsgMasterUserDeleteVolPolicyReq: "master:userdeletevolpolicy",
 	MsgMasterUserTransferVolReq:     "master:usertransfervol",
+	MsgMasterUserSetQuotaReq:        "master:usersetquota",
 
 	// Master API zone management
 	MsgMasterUpdateZoneReq: "master:updatezone",
@@ -106,6 +106,7 @@ var (
 	ErrPerformingDecommission                  = errors.New("is performing decommission")
 	ErrWaitForAutoAddReplica                   = errors.New("wait for auto add replica")
 	ErrBufferSizeExceedMaximum                 = errors.New("buffer size exceeds maximum")
+	ErrUserCapacityQuotaExceeded               = errors.New("capacity quota of user exceeded")
 )
 
 // http response error code and error message definitions
@@ -175,6 +176,7 @@ const (
 	ErrCodeZoneNumError
 	ErrCodeVersionOpError
 	ErrCodeNodeSetNotExists
+	ErrCodeUserCapacityQuotaExceeded
 )
 
 // Err2CodeMap error map to code
@@ -240,6 +242,7 @@ var Err2CodeMap = map[error]int32{
 	ErrZoneNum:                         ErrCodeZoneNumError,
 	ErrCodeVersionOp:                   ErrCodeVersionOpError,
 	ErrNodeSetNotExists:                ErrCodeNodeSetNotExists,
+	ErrUserCapacityQuotaExceeded:       ErrCodeUserCapacityQuotaExceeded,
 }
 
 func ParseErrorCode(code int32) error {
@@ -314,6 +317,7 @@ var code2ErrMap = map[int32]error{
 	ErrCodeNodeSetNotExists:                ErrNodeSetNotExists,
 	ErrCodeVolNotDelete:                    ErrVolNotDelete,
 	ErrCodeVolHasDeleted:                   ErrVolHasDeleted,
+	ErrCodeUserCapacityQuotaExceeded:       ErrUserCapacityQuotaExceeded,
 }
 
 type GeneralResp struct {
@@ -28,6 +28,26 @@ const (
 	QuotaKey   = "qa"
 )
 
+// The xattrs of the key prefix on a directory are the defaults of its new children, which get
+// them in the user namespace without the prefix, such as user.cubefs.default.owner inherited as
+// user.owner. The policy of the directory decides whether the new subdirectories inherit the
+// defaults and the policy too, they do by default.
+const (
+	XAttrDefaultPrefix      = "user.cubefs.default."
+	XAttrInheritPolicy      = "user.cubefs.inherit"
+	XAttrInheritRecursive   = "recursive"
+	XAttrInheritChildren    = "children"
+	xattrInheritedNamespace = "user."
+)
+
+// InheritedXAttrKey returns the key inherited by the children of the default key of a directory.
+func InheritedXAttrKey(key string) (string, bool) {
+	if !strings.HasPrefix(key, XAttrDefaultPrefix) || len(key) == len(XAttrDefaultPrefix) {
+		return "", false
+	}
+	return xattrInheritedNamespace + key[len(XAttrDefaultPrefix):], true
+}
+
 const (
 	FlagsSyncWrite int = 1 << iota
 	FlagsAppend
@@ -778,6 +798,9 @@ type AppendExtentKeysRequest struct {
 	PartitionId uint64      `json:"pid"`
 	Inode       uint64      `json:"ino"`
 	Extents     []ExtentKey `json:"eks"`
+	// the extents are appended only if the size of the inode is Size, e.g. for the appends of objects
+	CheckSize bool   `json:"cks,omitempty"`
+	Size      uint64 `json:"size,omitempty"`
 }
 
 type SetXAttrRequest struct {
@@ -795,6 +818,15 @@ type BatchSetXAttrRequest struct {
 	Attrs       map[string]string `json:"attrs"`
 }
 
+// InheritXAttrRequest is sent by the meta partition of the parent directory to the one of the
+// new child, the xattrs are set only if the inode is just created and has none of them.
+type InheritXAttrRequest struct {
+	VolName     string            `json:"vol"`
+	PartitionId uint64            `json:"pid"`
+	Inode       uint64            `json:"ino"`
+	Attrs       map[string]string `json:"attrs"`
+}
+
 type GetAllXAttrRequest struct {
 	VolName     string `json:"vol"`
 	PartitionId uint64 `json:"pid"`
@@ -0,0 +1,192 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// The metadata of a meta partition is exported as a stream of json lines: a header, the inodes,
+// the dentries, the xattrs and a trailer with the counts of records. The export of a volume is
+// the concatenation of the exports of its meta partitions, a truncated stream has no trailer.
+const MetaExportVersion = 1
+
+const (
+	MetaExportRecordHeader  = "header"
+	MetaExportRecordInode   = "inode"
+	MetaExportRecordDentry  = "dentry"
+	MetaExportRecordXAttr   = "xattr"
+	MetaExportRecordTrailer = "trailer"
+
+	// max size of a record line, an inode with a huge number of extent keys is the largest one
+	MaxMetaExportRecordSize = 256 << 20
+)
+
+type MetaExportRecord struct {
+	Type    string             `json:"type"`
+	Header  *MetaExportHeader  `json:"header,omitempty"`
+	Inode   *MetaExportInode   `json:"inode,omitempty"`
+	Dentry  *MetaExportDentry  `json:"dentry,omitempty"`
+	XAttr   *MetaExportXAttr   `json:"xattr,omitempty"`
+	Trailer *MetaExportTrailer `json:"trailer,omitempty"`
+}
+
+type MetaExportHeader struct {
+	Version     int    `json:"version"`
+	VolName     string `json:"vol"`
+	PartitionID uint64 `json:"pid"`
+	Start       uint64 `json:"start"`
+	End         uint64 `json:"end"`
+	ExportTime  int64  `json:"time"`
+}
+
+type MetaExportInode struct {
+	Inode      uint64         `json:"ino"`
+	Type       uint32         `json:"type"`
+	Uid        uint32         `json:"uid"`
+	Gid        uint32         `json:"gid"`
+	Size       uint64         `json:"size"`
+	Generation uint64         `json:"gen"`
+	CreateTime int64          `json:"ctime"`
+	AccessTime int64          `json:"atime"`
+	ModifyTime int64          `json:"mtime"`
+	LinkTarget []byte         `json:"target,omitempty"`
+	NLink      uint32         `json:"nlink"`
+	Extents    []ExtentKey    `json:"eks,omitempty"`
+	ObjExtents []ObjExtentKey `json:"objEks,omitempty"`
+}
+
+type MetaExportDentry struct {
+	ParentId uint64 `json:"pino"`
+	Name     string `json:"name"`
+	Inode    uint64 `json:"ino"`
+	Type     uint32 `json:"type"`
+}
+
+type MetaExportXAttr struct {
+	Inode uint64            `json:"ino"`
+	Attrs map[string][]byte `json:"attrs"`
+}
+
+type MetaExportTrailer struct {
+	Inodes   uint64 `json:"inodes"`
+	Dentries uint64 `json:"dentries"`
+	XAttrs   uint64 `json:"xattrs"`
+}
+
+// MetaExportWriter writes the records of a meta partition and counts them for the trailer.
+type MetaExportWriter struct {
+	w       io.Writer
+	enc     *json.Encoder
+	trailer MetaExportTrailer
+}
+
+func NewMetaExportWriter(w io.Writer, header *MetaExportHeader) (mw *MetaExportWriter, err error) {
+	mw = &MetaExportWriter{w: w, enc: json.NewEncoder(w)}
+	header.Version = MetaExportVersion
+	if err = mw.enc.Encode(&MetaExportRecord{Type: MetaExportRecordHeader, Header: header}); err != nil {
+		return nil, err
+	}
+	return mw, nil
+}
+
+func (mw *MetaExportWriter) WriteInode(inode *MetaExportInode) error {
+	mw.trailer.Inodes++
+	return mw.enc.Encode(&MetaExportRecord{Type: MetaExportRecordInode, Inode: inode})
+}
+
+func (mw *MetaExportWriter) WriteDentry(dentry *MetaExportDentry) error {
+	mw.trailer.Dentries++
+	return mw.enc.Encode(&MetaExportRecord{Type: MetaExportRecordDentry, Dentry: dentry})
+}
+
+func (mw *MetaExportWriter) WriteXAttr(xattr *MetaExportXAttr) error {
+	mw.trailer.XAttrs++
+	return mw.enc.Encode(&MetaExportRecord{Type: MetaExportRecordXAttr, XAttr: xattr})
+}
+
+// Close writes the trailer, the export is complete only if the trailer is written.
+func (mw *MetaExportWriter) Close() error {
+	return mw.enc.Encode(&MetaExportRecord{Type: MetaExportRecordTrailer, Trailer: &mw.trailer})
+}
+
+// MetaExportReader reads the records of the exports of meta partitions, and checks the version
+// and the completeness of every export.
+type MetaExportReader struct {
+	scanner *bufio.Scanner
+	header  *MetaExportHeader
+	count   MetaExportTrailer
+}
+
+func NewMetaExportReader(r io.Reader) *MetaExportReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), MaxMetaExportRecordSize)
+	return &MetaExportReader{scanner: scanner}
+}
+
+// Next returns the next record, io.EOF is returned at the end of a complete stream.
+func (mr *MetaExportReader) Next() (record *MetaExportRecord, err error) {
+	if !mr.scanner.Scan() {
+		if err = mr.scanner.Err(); err != nil {
+			return nil, err
+		}
+		if mr.header != nil {
+			return nil, fmt.Errorf("export of meta partition %v is truncated", mr.header.PartitionID)
+		}
+		return nil, io.EOF
+	}
+	record = &MetaExportRecord{}
+	if err = json.Unmarshal(mr.scanner.Bytes(), record); err != nil {
+		return nil, fmt.Errorf("invalid export record: %v", err)
+	}
+
+	if record.Type == MetaExportRecordHeader {
+		if mr.header != nil {
+			return nil, fmt.Errorf("export of meta partition %v is truncated", mr.header.PartitionID)
+		}
+		if record.Header == nil {
+			return nil, fmt.Errorf("invalid export header")
+		}
+		if record.Header.Version > MetaExportVersion {
+			return nil, fmt.Errorf("export version %v is newer than %v", record.Header.Version, MetaExportVersion)
+		}
+		mr.header = record.Header
+		mr.count = MetaExportTrailer{}
+		return record, nil
+	}
+	if mr.header == nil {
+		return nil, fmt.Errorf("export record %v without header", record.Type)
+	}
+	switch {
+	case record.Type == MetaExportRecordInode && record.Inode != nil:
+		mr.count.Inodes++
+	case record.Type == MetaExportRecordDentry && record.Dentry != nil:
+		mr.count.Dentries++
+	case record.Type == MetaExportRecordXAttr && record.XAttr != nil:
+		mr.count.XAttrs++
+	case record.Type == MetaExportRecordTrailer && record.Trailer != nil:
+		if *record.Trailer != mr.count {
+			return nil, fmt.Errorf("export of meta partition %v is incomplete, expected %+v, read %+v",
+				mr.header.PartitionID, *record.Trailer, mr.count)
+		}
+		mr.header = nil
+	default:
+		// records of newer minor versions are skipped by the older readers
+	}
+	return record, nil
+}
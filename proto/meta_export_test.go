@@ -0,0 +1,87 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeMetaExport(t *testing.T, buf *bytes.Buffer, pid uint64, closed bool) {
+	w, err := NewMetaExportWriter(buf, &MetaExportHeader{VolName: "vol", PartitionID: pid})
+	require.NoError(t, err)
+	require.NoError(t, w.WriteInode(&MetaExportInode{Inode: 1, Extents: []ExtentKey{{PartitionId: 1, ExtentId: 2, Size: 10}}}))
+	require.NoError(t, w.WriteDentry(&MetaExportDentry{ParentId: 1, Name: "a", Inode: 2}))
+	require.NoError(t, w.WriteXAttr(&MetaExportXAttr{Inode: 2, Attrs: map[string][]byte{"k": []byte("v")}}))
+	if closed {
+		require.NoError(t, w.Close())
+	}
+}
+
+func readMetaExport(buf *bytes.Buffer) (records []*MetaExportRecord, err error) {
+	r := NewMetaExportReader(buf)
+	for {
+		var record *MetaExportRecord
+		if record, err = r.Next(); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return
+		}
+		records = append(records, record)
+	}
+}
+
+func TestMetaExport(t *testing.T) {
+	buf := new(bytes.Buffer)
+	writeMetaExport(t, buf, 1, true)
+	writeMetaExport(t, buf, 2, true)
+	records, err := readMetaExport(buf)
+	require.NoError(t, err)
+	require.Len(t, records, 10)
+	require.Equal(t, MetaExportVersion, records[0].Header.Version)
+	require.Equal(t, uint64(2), records[5].Header.PartitionID)
+	require.Equal(t, uint32(10), records[1].Inode.Extents[0].Size)
+	require.Equal(t, []byte("v"), records[3].XAttr.Attrs["k"])
+	require.Equal(t, MetaExportTrailer{Inodes: 1, Dentries: 1, XAttrs: 1}, *records[4].Trailer)
+
+	// truncated in the middle and at the end
+	buf.Reset()
+	writeMetaExport(t, buf, 1, false)
+	writeMetaExport(t, buf, 2, true)
+	_, err = readMetaExport(buf)
+	require.Error(t, err)
+	buf.Reset()
+	writeMetaExport(t, buf, 1, false)
+	_, err = readMetaExport(buf)
+	require.Error(t, err)
+
+	// records lost
+	buf.Reset()
+	writeMetaExport(t, buf, 1, true)
+	lines := strings.SplitAfter(buf.String(), "\n")
+	_, err = readMetaExport(bytes.NewBufferString(lines[0] + lines[1] + lines[4]))
+	require.Error(t, err)
+
+	// newer version
+	buf.Reset()
+	buf.WriteString(`{"type":"header","header":{"version":2}}` + "\n")
+	_, err = readMetaExport(buf)
+	require.Error(t, err)
+}
@@ -45,6 +45,8 @@ type MetaNodeInfo struct {
 	NodeSetID                 uint64
 	PersistenceMetaPartitions []uint64
 	RdOnly                    bool
+	AllocWeight               float64
+	Labels                    []string
 	CanAllowPartition         bool
 	MaxMpCntLimit             uint32
 	CpuUtil                   float64 `json:"cpuUtil"`
@@ -71,6 +73,8 @@ type DataNodeInfo struct {
 	PersistenceDataPartitions []uint64
 	BadDisks                  []string
 	RdOnly                    bool
+	AllocWeight               float64
+	Labels                    []string
 	CanAllocPartition         bool
 	MaxDpCntLimit             uint32             `json:"maxDpCntLimit"`
 	CpuUtil                   float64            `json:"cpuUtil"`
@@ -76,6 +76,30 @@ const (
 	SnapshotReadVerSeq
 
 	DisableMountSubtype
+
+	// auto upgrade
+	AutoUpgrade
+	AutoUpgradePubKey
+
+	NegativeDentryTTL
+
+	// bandwidth limit
+	MaxUploadMBps
+	MaxDownloadMBps
+
+	MetaDegradeProbeInterval
+
+	StatAheadWindow
+
+	SessionStateFile
+
+	// retry policy of the requests to the meta and data partitions
+	RetryMaxAttempts
+	RetryBaseIntervalMs
+	RetryMaxIntervalMs
+	RetryJitterPercent
+	RetryErrorClasses
+
 	MaxMountOption
 )
 
@@ -170,6 +194,19 @@ func InitMountOptions(opts []MountOption) {
 	opts[FileSystemName] = MountOption{"fileSystemName", "The explicit name of the filesystem", "", ""}
 	opts[SnapshotReadVerSeq] = MountOption{"snapshotReadSeq", "Snapshot read seq", "", int64(0)} // default false
 	opts[DisableMountSubtype] = MountOption{"disableMountSubtype", "Disable Mount Subtype", "", false}
+	opts[AutoUpgrade] = MountOption{"autoUpgrade", "Upgrade to the client version advertised by master without unmounting", "", false}
+	opts[AutoUpgradePubKey] = MountOption{"autoUpgradePubKey", "Base64 encoded ed25519 public key to verify the upgraded client", "", ""}
+	opts[NegativeDentryTTL] = MountOption{"negativeDentryTTL", "Negative Dentry Cache Expiration Time, disabled if 0", "", int64(0)}
+	opts[MaxUploadMBps] = MountOption{"maxUploadMBps", "Upload bandwidth limit of the mount in MB/s, unlimited if 0", "", int64(0)}
+	opts[MaxDownloadMBps] = MountOption{"maxDownloadMBps", "Download bandwidth limit of the mount in MB/s, unlimited if 0", "", int64(0)}
+	opts[StatAheadWindow] = MountOption{"statAheadWindow", "Number of dentries whose attributes are prefetched ahead of lookups in the order of readdir, disabled if 0", "", int64(0)}
+	opts[SessionStateFile] = MountOption{"sessionStateFile", "Local file journaling the open nodes and handles to resume the mount after the client crashed, disabled if empty", "", ""}
+	opts[MetaDegradeProbeInterval] = MountOption{"metaDegradeProbeInterval", "Probe interval in seconds of the unavailable meta partitions degraded to read-only, disabled if 0", "", int64(0)}
+	opts[RetryMaxAttempts] = MountOption{"retryMaxAttempts", "Max attempts of the requests to the meta and data partitions, the default if 0", "", int64(0)}
+	opts[RetryBaseIntervalMs] = MountOption{"retryBaseIntervalMs", "Base backoff interval in milliseconds between the retries, the default if 0", "", int64(0)}
+	opts[RetryMaxIntervalMs] = MountOption{"retryMaxIntervalMs", "Max backoff interval in milliseconds between the retries, the default if 0", "", int64(0)}
+	opts[RetryJitterPercent] = MountOption{"retryJitterPercent", "Percent of the backoff interval shortened randomly, the default if 0", "", int64(0)}
+	opts[RetryErrorClasses] = MountOption{"retryErrorClasses", "Comma separated classes of the errors retried among network, timeout and again, the default if empty", "", ""}
 
 	for i := 0; i < MaxMountOption; i++ {
 		flag.StringVar(&opts[i].cmdlineValue, opts[i].keyword, "", opts[i].description)
@@ -335,4 +372,24 @@ type MountOptions struct {
 	VerReadSeq uint64
 	// disable mount subtype
 	DisableMountSubtype bool
+
+	AutoUpgrade       bool
+	AutoUpgradePubKey string
+
+	NegativeDentryTTL int64
+
+	MaxUploadMBps   int64
+	MaxDownloadMBps int64
+
+	MetaDegradeProbeInterval int64
+
+	StatAheadWindow int64
+
+	SessionStateFile string
+
+	RetryMaxAttempts    int64
+	RetryBaseIntervalMs int64
+	RetryMaxIntervalMs  int64
+	RetryJitterPercent  int64
+	RetryErrorClasses   string
 }
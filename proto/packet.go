@@ -71,6 +71,7 @@ const (
 	OpGetMaxExtentIDAndPartitionSize uint8 = 0x16
 	OpSnapshotExtentRepairRead       uint8 = 0x17
 	OpSnapshotExtentRepairRsp        uint8 = 0x18
+	OpReplCompressNegotiate          uint8 = 0x19
 
 	// Operations: Client -> MetaNode.
 	OpMetaCreateInode   uint8 = 0x20
@@ -233,6 +234,7 @@ const (
 
 	OpMetaBatchSetXAttr uint8 = 0xD2
 	OpMetaGetAllXAttr   uint8 = 0xD3
+	OpMetaInheritXAttr  uint8 = 0xD4
 
 	// transaction error
 
@@ -261,6 +263,10 @@ const (
 	OpLimitedIoErr       uint8 = 0xB1
 	OpStoreClosed        uint8 = 0xB2
 	OpReachMaxExtentsErr uint8 = 0xB3
+
+	// hard link
+	OpTooManyLinksErr uint8 = 0xB9
+	OpCrossVolumeErr  uint8 = 0xBA
 )
 
 const (
@@ -531,6 +537,8 @@ func (p *Packet) GetOpMsg() (m string) {
 		m = "OpSnapshotExtentRepairRead"
 	case OpGetMaxExtentIDAndPartitionSize:
 		m = "OpGetMaxExtentIDAndPartitionSize"
+	case OpReplCompressNegotiate:
+		m = "OpReplCompressNegotiate"
 	case OpBroadcastMinAppliedID:
 		m = "OpBroadcastMinAppliedID"
 	case OpRemoveDataPartitionRaftMember:
@@ -555,6 +563,8 @@ func (p *Packet) GetOpMsg() (m string) {
 		m = "OpMetaBatchObjExtentsAdd"
 	case OpMetaSetXAttr:
 		m = "OpMetaSetXAttr"
+	case OpMetaInheritXAttr:
+		m = "OpMetaInheritXAttr"
 	case OpMetaGetXAttr:
 		m = "OpMetaGetXAttr"
 	case OpMetaRemoveXAttr:
@@ -716,6 +726,10 @@ func (p *Packet) GetResultMsg() (m string) {
 		return "OpStoreClosed"
 	case OpReachMaxExtentsErr:
 		return "OpReachMaxExtentsErr"
+	case OpTooManyLinksErr:
+		return "OpTooManyLinksErr"
+	case OpCrossVolumeErr:
+		return "OpCrossVolumeErr"
 	default:
 		return fmt.Sprintf("Unknown ResultCode(%v)", p.ResultCode)
 	}
@@ -869,6 +883,15 @@ func (p *Packet) WriteToConn(c net.Conn) (err error) {
 		headSize = util.PacketHeaderVerSize
 	}
 	// log.LogDebugf("packet opcode %v header size %v extentype %v conn %v", p.Opcode, headSize, p.ExtentType, c)
+	if _, ok := c.(*CompressConn); ok {
+		if data := p.compressData(); data != nil {
+			orgData, orgSize, orgExtentType := p.Data, p.Size, p.ExtentType
+			p.Data, p.Size, p.ExtentType = data, uint32(len(data)), p.ExtentType|CompressedDataFlag
+			defer func() {
+				p.Data, p.Size, p.ExtentType = orgData, orgSize, orgExtentType
+			}()
+		}
+	}
 	header, err := Buffers.Get(headSize)
 	if err != nil {
 		header = make([]byte, headSize)
@@ -998,7 +1021,7 @@ func (p *Packet) ReadFromConnWithVer(c net.Conn, timeoutSec int) (err error) {
 	if n != int(size) {
 		return syscall.EBADMSG
 	}
-	return nil
+	return p.DecompressData()
 }
 
 // ReadFromConn reads the data from the given connection.
@@ -1043,7 +1066,7 @@ func (p *Packet) ReadFromConn(c net.Conn, timeoutSec int) (err error) {
 	if n != int(size) {
 		return syscall.EBADMSG
 	}
-	return nil
+	return p.DecompressData()
 }
 
 // PacketOkReply sets the result code as OpOk, and sets the body as empty.
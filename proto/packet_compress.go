@@ -0,0 +1,101 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/cubefs/cubefs/util"
+	"github.com/pierrec/lz4"
+)
+
+// The data of packets between datanodes in different zones may be compressed with lz4. The sender
+// negotiates the compression with OpReplCompressNegotiate on a connection first, and then sets
+// CompressedDataFlag in the extent type of the packets of which the data is compressed. The
+// compressed data is the size of the original data in 4 bytes followed by a lz4 block, the crc
+// of the packet is always the crc of the original data.
+const (
+	CompressedDataFlag = 0x20
+
+	ReplCompressCodecLz4 = "lz4"
+
+	// the data smaller than it is not compressed
+	MinCompressDataSize = 4 * util.KB
+	// max size of the original data of a compressed packet
+	MaxDecompressDataSize = 16 * util.MB
+)
+
+// ReplCompressNegotiation is the data of the negotiation request and its reply.
+type ReplCompressNegotiation struct {
+	Codec string `json:"codec"`
+	Zone  string `json:"zone"`
+}
+
+// CompressConn is a connection on which the peer has agreed to receive compressed data,
+// the data of the packets written to it are compressed if it is compressible.
+type CompressConn struct {
+	net.Conn
+}
+
+func NewCompressConn(c net.Conn) *CompressConn {
+	return &CompressConn{Conn: c}
+}
+
+// compressData returns the compressed data, or nil if the data is not worth compressing.
+func (p *Packet) compressData() []byte {
+	if p.ExtentType&CompressedDataFlag != 0 || p.Size < MinCompressDataSize || int(p.Size) > len(p.Data) ||
+		(p.ResultCode != OpOk && p.ResultCode != OpInitResultCode) {
+		return nil
+	}
+	// the compressed data must save 1/8 of the original data at least
+	size := int(p.Size)
+	buf := make([]byte, 4+size-size/8)
+	n, err := lz4.CompressBlock(p.Data[:size], buf[4:], nil)
+	if err != nil || n == 0 {
+		return nil
+	}
+	binary.BigEndian.PutUint32(buf[:4], p.Size)
+	return buf[:4+n]
+}
+
+// DecompressData replaces the compressed data of the packet with the original data.
+func (p *Packet) DecompressData() (err error) {
+	if p.ExtentType&CompressedDataFlag == 0 {
+		return nil
+	}
+	if p.Size < 4 || int(p.Size) > len(p.Data) {
+		return fmt.Errorf("invalid compressed data size %v", p.Size)
+	}
+	size := binary.BigEndian.Uint32(p.Data[:4])
+	if size > MaxDecompressDataSize {
+		return fmt.Errorf("invalid decompressed data size %v", size)
+	}
+	var data []byte
+	if p.IsWriteOperation() && size == util.BlockSize {
+		data, _ = Buffers.Get(int(size))
+	} else {
+		data = make([]byte, size)
+	}
+	n, err := lz4.UncompressBlock(p.Data[4:p.Size], data[:size])
+	if err != nil || n != int(size) {
+		return fmt.Errorf("decompress data of %v bytes to %v bytes failed: %v", p.Size, size, err)
+	}
+	p.Data = data
+	p.Size = size
+	p.ExtentType &^= CompressedDataFlag
+	return nil
+}
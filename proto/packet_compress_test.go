@@ -0,0 +1,125 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"bytes"
+	"hash/crc32"
+	"io"
+	"math/rand"
+	"net"
+	"testing"
+
+	"github.com/cubefs/cubefs/util"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	if Buffers == nil {
+		InitBufferPool(0)
+	}
+}
+
+func newCompressTestPacket(data []byte) *Packet {
+	p := NewPacket()
+	p.Opcode = OpWrite
+	p.ExtentType = NormalExtentType
+	p.ReqID = GenerateRequestID()
+	p.Size = uint32(len(data))
+	p.Data = data
+	p.CRC = crc32.ChecksumIEEE(data)
+	return p
+}
+
+// sendCompressTestPacket writes the packet to a compressed connection, and returns the packet read
+// from the other side and the size of the data on the wire.
+func sendCompressTestPacket(t *testing.T, p *Packet) (reply *Packet, wireSize uint32) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- p.WriteToConn(NewCompressConn(client))
+	}()
+	header := make([]byte, util.PacketHeaderSize)
+	_, err := io.ReadFull(server, header)
+	require.NoError(t, err)
+	wire := NewPacket()
+	require.NoError(t, wire.UnmarshalHeader(header))
+	wireSize = wire.Size
+
+	// read the whole packet again through a connection replaying the header
+	reply = NewPacket()
+	require.NoError(t, reply.ReadFromConn(&replayConn{Conn: server, buf: bytes.NewBuffer(header)}, ReadDeadlineTime))
+	require.NoError(t, <-done)
+	return
+}
+
+type replayConn struct {
+	net.Conn
+	buf *bytes.Buffer
+}
+
+func (c *replayConn) Read(b []byte) (int, error) {
+	if c.buf.Len() > 0 {
+		return c.buf.Read(b)
+	}
+	return c.Conn.Read(b)
+}
+
+func TestPacketCompress(t *testing.T) {
+	data := bytes.Repeat([]byte("cubefs replication "), util.BlockSize/16)[:util.BlockSize]
+	p := newCompressTestPacket(data)
+	reply, wireSize := sendCompressTestPacket(t, p)
+	require.Less(t, wireSize, uint32(len(data)/8))
+	require.Equal(t, data, reply.Data[:reply.Size])
+	require.Equal(t, p.CRC, crc32.ChecksumIEEE(reply.Data[:reply.Size]))
+	require.Equal(t, uint8(NormalExtentType), reply.ExtentType)
+
+	// the packet is not changed by the compression
+	require.Equal(t, uint32(len(data)), p.Size)
+	require.Equal(t, uint8(NormalExtentType), p.ExtentType)
+	require.Equal(t, data, p.Data)
+}
+
+func TestPacketCompressSkipped(t *testing.T) {
+	random := make([]byte, 64*util.KB)
+	rand.Read(random)
+	small := bytes.Repeat([]byte("a"), MinCompressDataSize-1)
+	errData := bytes.Repeat([]byte("b"), 64*util.KB)
+	for _, p := range []*Packet{newCompressTestPacket(random), newCompressTestPacket(small), newCompressTestPacket(errData)} {
+		if bytes.Equal(p.Data, errData) {
+			p.ResultCode = OpErr
+		}
+		reply, wireSize := sendCompressTestPacket(t, p)
+		require.Equal(t, p.Size, wireSize)
+		require.Equal(t, p.Data, reply.Data[:reply.Size])
+	}
+}
+
+func TestPacketDecompressInvalid(t *testing.T) {
+	p := newCompressTestPacket([]byte{0, 0, 0})
+	p.ExtentType |= CompressedDataFlag
+	require.Error(t, p.DecompressData())
+
+	p = newCompressTestPacket([]byte{0xff, 0, 0, 0, 1})
+	p.ExtentType |= CompressedDataFlag
+	require.Error(t, p.DecompressData())
+
+	p = newCompressTestPacket([]byte{0, 0, 0x10, 0, 1, 2, 3})
+	p.ExtentType |= CompressedDataFlag
+	require.Error(t, p.DecompressData())
+}
@@ -52,6 +52,7 @@ const (
 	OSSGetObjectAction     Action = OSSActionPrefix + "GetObject"
 	OSSPutObjectAction     Action = OSSActionPrefix + "PutObject"
 	OSSPostObjectAction    Action = OSSActionPrefix + "PostObject"
+	OSSAppendObjectAction  Action = OSSActionPrefix + "AppendObject"
 	OSSCopyObjectAction    Action = OSSActionPrefix + "CopyObject"
 	OSSListObjectsAction   Action = OSSActionPrefix + "ListObjects"
 	OSSDeleteObjectAction  Action = OSSActionPrefix + "DeleteObject"
@@ -147,7 +148,7 @@ const (
 	OSSDeleteBucketWebsiteAction Action = OSSActionPrefix + "DeleteBucketWebsite" // unsupported
 
 	// Object restore actions
-	OSSRestoreObjectAction Action = OSSActionPrefix + "RestoreObject" // unsupported
+	OSSRestoreObjectAction Action = OSSActionPrefix + "RestoreObject"
 
 	// Public access block actions
 	OSSGetPublicAccessBlockAction    Action = OSSActionPrefix + "GetPublicAccessBlock"   // unsupported
@@ -182,6 +183,7 @@ var AllActions = []Action{
 	OSSGetObjectAction,
 	OSSPutObjectAction,
 	OSSPostObjectAction,
+	OSSAppendObjectAction,
 	OSSCopyObjectAction,
 	OSSListObjectsAction,
 	OSSDeleteObjectAction,
@@ -423,6 +425,7 @@ var builtinPermissionActionsMap = map[Permission]Actions{
 		// Object storage interface actions
 		OSSGetObjectAction,
 		OSSPutObjectAction,
+		OSSAppendObjectAction,
 		OSSCopyObjectAction,
 		OSSListObjectsAction,
 		OSSDeleteObjectAction,
@@ -449,6 +452,7 @@ var builtinPermissionActionsMap = map[Permission]Actions{
 		OSSGetObjectRetentionAction,
 		OSSPutObjectRetentionAction,
 		OSSGetBucketEncryptionAction,
+		OSSRestoreObjectAction,
 
 		// POSIX file system interface actions
 		POSIXReadAction,
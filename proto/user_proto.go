@@ -98,15 +98,16 @@ type AKUser struct {
 }
 
 type UserInfo struct {
-	UserID      string       `json:"user_id" graphql:"user_id"`
-	AccessKey   string       `json:"access_key" graphql:"access_key"`
-	SecretKey   string       `json:"secret_key" graphql:"secret_key"`
-	Policy      *UserPolicy  `json:"policy" graphql:"policy"`
-	UserType    UserType     `json:"user_type" graphql:"user_type"`
-	CreateTime  string       `json:"create_time" graphql:"create_time"`
-	Description string       `json:"description" graphql:"description"`
-	Mu          sync.RWMutex `json:"-" graphql:"-"`
-	EMPTY       bool         // graphql need ???
+	UserID        string       `json:"user_id" graphql:"user_id"`
+	AccessKey     string       `json:"access_key" graphql:"access_key"`
+	SecretKey     string       `json:"secret_key" graphql:"secret_key"`
+	Policy        *UserPolicy  `json:"policy" graphql:"policy"`
+	UserType      UserType     `json:"user_type" graphql:"user_type"`
+	CreateTime    string       `json:"create_time" graphql:"create_time"`
+	Description   string       `json:"description" graphql:"description"`
+	CapacityQuota uint64       `json:"capacity_quota" graphql:"capacity_quota"` // GB shared by the own volumes and buckets, 0 means unlimited
+	Mu            sync.RWMutex `json:"-" graphql:"-"`
+	EMPTY         bool         // graphql need ???
 }
 
 func (i *UserInfo) String() string {
@@ -247,6 +248,21 @@ func (policy *UserPolicy) RemoveAuthorizedVol(volume string) {
 	delete(policy.AuthorizedVols, volume)
 }
 
+// RenameVol moves the ownership and authorized actions of the volume to the new name.
+func (policy *UserPolicy) RenameVol(volume, newVolume string) {
+	policy.mu.Lock()
+	defer policy.mu.Unlock()
+	for i, ownVol := range policy.OwnVols {
+		if ownVol == volume {
+			policy.OwnVols[i] = newVolume
+		}
+	}
+	if actions, ok := policy.AuthorizedVols[volume]; ok {
+		delete(policy.AuthorizedVols, volume)
+		policy.AuthorizedVols[newVolume] = actions
+	}
+}
+
 func (policy *UserPolicy) SetPerm(volume string, perm Permission) {
 	policy.mu.Lock()
 	defer policy.mu.Unlock()
@@ -375,3 +391,25 @@ type UserUpdateParam struct {
 	Password    string   `json:"password"`
 	Description string   `json:"description"`
 }
+
+type UserQuotaParam struct {
+	UserID        string `json:"user_id"`
+	CapacityQuota uint64 `json:"capacity_quota"`
+}
+
+// UserVolUsage is the usage of a volume owned by the user, a volume is a bucket of the
+// object storage either.
+type UserVolUsage struct {
+	Name     string `json:"name"`
+	Capacity uint64 `json:"capacity"`  // GB
+	UsedSize uint64 `json:"used_size"` // bytes
+}
+
+// UserUsage is the total consumption of the user across the volumes and buckets.
+type UserUsage struct {
+	UserID        string          `json:"user_id"`
+	CapacityQuota uint64          `json:"capacity_quota"` // GB, 0 means unlimited
+	UsedSize      uint64          `json:"used_size"`      // bytes
+	OverQuota     bool            `json:"over_quota"`
+	Vols          []*UserVolUsage `json:"vols"`
+}
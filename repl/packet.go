@@ -15,9 +15,11 @@
 package repl
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net"
+	"strconv"
 	"strings"
 	"time"
 
@@ -79,6 +81,8 @@ type (
 		TpObject        *exporter.TimePointCount
 		NeedReply       bool
 		OrgBuffer       []byte
+		WriteEpoch      uint64 // write epoch of the partition known by the client
+		HasWriteEpoch   bool   // the old clients do not carry the write epoch, their writes are not fenced
 
 		// used locally
 		shallDegrade bool
@@ -177,6 +181,10 @@ func copyPacket(src *Packet, dst *FollowerPacket) {
 	dst.ExtentOffset = src.ExtentOffset
 	dst.ReqID = src.ReqID
 	dst.Data = src.OrgBuffer
+	if src.HasWriteEpoch {
+		dst.Arg = []byte(strconv.FormatUint(src.WriteEpoch, 10))
+		dst.ArgLen = uint32(len(dst.Arg))
+	}
 }
 
 func (p *Packet) BeforeTp(clusterID string) (ok bool) {
@@ -202,6 +210,13 @@ func (p *Packet) resolveFollowersAddr() (err error) {
 	str := string(p.Arg[:int(p.ArgLen)])
 	followerAddrs := strings.SplitN(str, proto.AddrSplit, -1)
 	followerNum := uint8(len(followerAddrs) - 1)
+	// the arg of a write is "addr1/addr2/epoch" from the client and "epoch" from the leader,
+	// the epoch is empty if sent by an old client
+	if p.IsNormalWriteOperation() || p.IsCreateExtentOperation() {
+		if epoch, err := strconv.ParseUint(followerAddrs[followerNum], 10, 64); err == nil {
+			p.WriteEpoch, p.HasWriteEpoch = epoch, true
+		}
+	}
 	p.followersAddrs = make([]string, followerNum)
 	p.followerPackets = make([]*FollowerPacket, followerNum)
 	p.OrgBuffer = p.Data
@@ -357,6 +372,28 @@ func NewPacketToNotifyExtentRepair(partitionID uint64) (p *Packet) {
 	return
 }
 
+// NewPacketToBatchDeleteExtent returns a new packet to mark delete the normal extents, which is sent
+// to the leader of hosts and forwarded to the followers like the deletion of the meta partitions.
+func NewPacketToBatchDeleteExtent(partitionID uint64, exts []*proto.DelExtentParam, hosts []string) (p *Packet, err error) {
+	p = new(Packet)
+	p.Opcode = proto.OpBatchDeleteExtent
+	p.PartitionID = partitionID
+	p.Magic = proto.ProtoMagic
+	p.ExtentType = proto.NormalExtentType
+	p.ReqID = proto.GenerateRequestID()
+	if p.Data, err = json.Marshal(exts); err != nil {
+		return
+	}
+	p.Size = uint32(len(p.Data))
+	p.RemainingFollowers = uint8(len(hosts) - 1)
+	if len(hosts) == 1 {
+		p.RemainingFollowers = 127
+	}
+	p.Arg = []byte(strings.Join(hosts[1:], proto.AddrSplit) + proto.AddrSplit)
+	p.ArgLen = uint32(len(p.Arg))
+	return
+}
+
 func (p *Packet) SetResultCode(code uint8) {
 	p.ResultCode = code
 }
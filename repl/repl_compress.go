@@ -0,0 +1,147 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package repl
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// the peers which have failed the negotiation are not negotiated again in the period,
+// they are old versions which close the connection on the unknown opcode.
+const compressUnsupportedPeriod = 10 * time.Minute
+
+var (
+	compressEnabled bool
+	compressZone    string
+
+	compressUnsupported sync.Map // addr -> time.Time
+)
+
+// SetCompression enables the compression of the data of the packets to and from the datanodes
+// in the other zones, it should be set at startup.
+func SetCompression(enable bool, zone string) {
+	compressEnabled = enable
+	compressZone = zone
+}
+
+func localCompressNegotiation() *proto.ReplCompressNegotiation {
+	nego := &proto.ReplCompressNegotiation{Zone: compressZone}
+	if compressEnabled {
+		nego.Codec = proto.ReplCompressCodecLz4
+	}
+	return nego
+}
+
+func shouldCompress(local, remote *proto.ReplCompressNegotiation) bool {
+	return local.Codec != "" && local.Codec == remote.Codec && local.Zone != remote.Zone
+}
+
+// NegotiateCompression negotiates the compression with the datanode of addr on the connection,
+// the peer compresses the data of the replies on it if ok. The connection is closed by the peers
+// of old versions, so it must not be used any more if err is not nil.
+func NegotiateCompression(conn net.Conn, addr string) (ok bool, err error) {
+	if !compressEnabled {
+		return
+	}
+	if t, has := compressUnsupported.Load(addr); has {
+		if time.Since(t.(time.Time)) < compressUnsupportedPeriod {
+			return
+		}
+		compressUnsupported.Delete(addr)
+	}
+
+	local := localCompressNegotiation()
+	data, _ := json.Marshal(local)
+	request := NewPacket()
+	request.Opcode = proto.OpReplCompressNegotiate
+	request.ReqID = proto.GenerateRequestID()
+	request.Size = uint32(len(data))
+	request.Data = data
+	request.CRC = crc32.ChecksumIEEE(data)
+	if err = request.WriteToConn(conn); err != nil {
+		return
+	}
+	reply := NewPacket()
+	if err = reply.ReadFromConnWithVer(conn, proto.ReadDeadlineTime); err != nil {
+		return
+	}
+	if reply.ResultCode != proto.OpOk || reply.ReqID != request.ReqID {
+		compressUnsupported.Store(addr, time.Now())
+		err = fmt.Errorf("negotiate compression with %v failed: %v", addr, reply.GetResultMsg())
+		log.LogWarnf("action[NegotiateCompression] %v", err)
+		return
+	}
+	remote := &proto.ReplCompressNegotiation{}
+	if err = json.Unmarshal(reply.Data[:reply.Size], remote); err != nil {
+		return
+	}
+	ok = shouldCompress(local, remote)
+	log.LogDebugf("action[NegotiateCompression] addr(%v) local(%v) remote(%v) compress(%v)", addr, local, remote, ok)
+	return
+}
+
+// negotiateCompression replies the negotiation of the peer, and compresses the data of
+// the replies to the peer if both sides agree.
+func (rp *ReplProtocol) negotiateCompression(request *Packet) {
+	remote := &proto.ReplCompressNegotiation{}
+	if err := json.Unmarshal(request.Data[:request.Size], remote); err != nil {
+		request.PackErrorBody(ActionPreparePkt, err.Error())
+		return
+	}
+	local := localCompressNegotiation()
+	if shouldCompress(local, remote) {
+		atomic.StoreInt32(&rp.compress, 1)
+	} else {
+		atomic.StoreInt32(&rp.compress, 0)
+	}
+	data, _ := json.Marshal(local)
+	request.PacketOkWithBody(data)
+	log.LogDebugf("action[negotiateCompression] remote(%v) local(%v) compress(%v)",
+		rp.sourceConn.RemoteAddr(), local, atomic.LoadInt32(&rp.compress) == 1)
+}
+
+// operatorConn is the connection of the operators to write the replies.
+func (rp *ReplProtocol) operatorConn() net.Conn {
+	if atomic.LoadInt32(&rp.compress) == 1 {
+		return proto.NewCompressConn(rp.sourceConn)
+	}
+	return rp.sourceConn
+}
+
+// getFollowerConn gets a connection to the follower, on which the data is compressed
+// if the follower agrees.
+func getFollowerConn(addr string) (conn net.Conn, err error) {
+	if conn, err = gConnPool.GetConnect(addr); err != nil {
+		return
+	}
+	ok, err := NegotiateCompression(conn, addr)
+	if err != nil {
+		conn.Close()
+		return gConnPool.GetConnect(addr)
+	}
+	if ok {
+		conn = proto.NewCompressConn(conn)
+	}
+	return
+}
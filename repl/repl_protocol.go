@@ -59,8 +59,9 @@ type ReplProtocol struct {
 	getSmuxConn func(addr string) (c net.Conn, err error)
 	putSmuxConn func(conn net.Conn, force bool)
 
-	isError int32
-	replId  int64
+	isError  int32
+	replId   int64
+	compress int32 // compress the data of the replies if 1
 }
 
 type FollowerTransport struct {
@@ -287,6 +288,12 @@ func (rp *ReplProtocol) readPkgAndPrepare() (err error) {
 	// log.LogDebugf("action[readPkgAndPrepare] packet(%v) op %v from remote(%v) conn(%v) ",
 	//	request.GetUniqueLogId(), request.Opcode, rp.sourceConn.RemoteAddr().String(), rp.sourceConn)
 
+	// the peer waits for the reply of the negotiation before sending the other packets
+	if request.Opcode == proto.OpReplCompressNegotiate {
+		rp.negotiateCompression(request)
+		err = rp.putResponse(request)
+		return
+	}
 	if err = request.resolveFollowersAddr(); err != nil {
 		err = rp.putResponse(request)
 		return
@@ -329,7 +336,7 @@ func (rp *ReplProtocol) OperatorAndForwardPktGoRoutine() {
 		select {
 		case request := <-rp.toBeProcessedCh:
 			if !request.IsForwardPacket() {
-				rp.operatorFunc(request, rp.sourceConn)
+				rp.operatorFunc(request, rp.operatorConn())
 				rp.putResponse(request)
 			} else {
 				index, err := rp.sendRequestToAllFollowers(request)
@@ -338,7 +345,7 @@ func (rp *ReplProtocol) OperatorAndForwardPktGoRoutine() {
 					rp.putResponse(request)
 				} else {
 					rp.pushPacketToList(request)
-					rp.operatorFunc(request, rp.sourceConn)
+					rp.operatorFunc(request, rp.operatorConn())
 					rp.putAck()
 				}
 			}
@@ -495,7 +502,7 @@ func (rp *ReplProtocol) allocateFollowersConns(p *Packet, index int) (transport
 			}
 
 		} else {
-			conn, err = gConnPool.GetConnect(addr)
+			conn, err = getFollowerConn(addr)
 			if err != nil {
 				return
 			}
@@ -27,6 +27,7 @@ import (
 	"github.com/cubefs/cubefs/util"
 	"github.com/cubefs/cubefs/util/exporter"
 	"github.com/cubefs/cubefs/util/log"
+	"github.com/cubefs/cubefs/util/retry"
 	"github.com/cubefs/cubefs/util/stat"
 	"github.com/google/uuid"
 )
@@ -36,6 +37,17 @@ const (
 	RetrySleepInterval = 100 * time.Millisecond
 )
 
+// RetryPolicy is the policy of the retries of the requests to blobstore, the errors of the
+// access are all retried as before.
+var RetryPolicy = retry.Policy{
+	MaxAttempts:  MaxRetryTimes,
+	BaseInterval: RetrySleepInterval,
+	MaxInterval:  4 * RetrySleepInterval,
+	Multiplier:   2,
+	Jitter:       0.5,
+	Retryable:    retry.ClassAll,
+}
+
 type BlobStoreClient struct {
 	client access.API
 }
@@ -88,14 +100,12 @@ func (ebs *BlobStoreClient) Read(ctx context.Context, volName string, buf []byte
 			body.Close()
 		}
 	}()
-	for i := 0; i < MaxRetryTimes; i++ {
-		body, err = ebs.client.Get(ctx, &access.GetArgs{Location: loc, Offset: offset, ReadSize: size})
-		if err == nil {
-			break
+	err = RetryPolicy.Do(retry.ClassNetwork, func(attempt int) (err error) {
+		if body, err = ebs.client.Get(ctx, &access.GetArgs{Location: loc, Offset: offset, ReadSize: size}); err != nil {
+			log.LogWarnf("TRACE Ebs Read,oek(%v), err(%v), requestId(%v),retryTimes(%v)", oek, err, requestId, attempt-1)
 		}
-		log.LogWarnf("TRACE Ebs Read,oek(%v), err(%v), requestId(%v),retryTimes(%v)", oek, err, requestId, i)
-		time.Sleep(RetrySleepInterval)
-	}
+		return
+	})
 	if err != nil {
 		log.LogErrorf("TRACE Ebs Read,oek(%v), err(%v), requestId(%v)", oek, err, requestId)
 		return 0, err
@@ -126,17 +136,16 @@ func (ebs *BlobStoreClient) Write(ctx context.Context, volName string, data []by
 		metric.SetWithLabels(err, map[string]string{exporter.Vol: volName})
 	}()
 
-	for i := 0; i < MaxRetryTimes; i++ {
+	err = RetryPolicy.Do(retry.ClassNetwork, func(attempt int) (err error) {
 		location, _, err = ebs.client.Put(ctx, &access.PutArgs{
 			Size: int64(size),
 			Body: bytes.NewReader(data),
 		})
-		if err == nil {
-			break
+		if err != nil {
+			log.LogWarnf("TRACE Ebs write, err(%v), requestId(%v),retryTimes(%v)", err, requestId, attempt-1)
 		}
-		log.LogWarnf("TRACE Ebs write, err(%v), requestId(%v),retryTimes(%v)", err, requestId, i)
-		time.Sleep(RetrySleepInterval)
-	}
+		return
+	})
 	if err != nil {
 		log.LogErrorf("TRACE Ebs write,err(%v),requestId(%v)", err.Error(), requestId)
 		return location, err
@@ -20,6 +20,7 @@ import (
 	"io"
 	"os"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -93,6 +94,7 @@ type Reader struct {
 	valid           bool
 	inflightL2cache sync.Map
 	limitManager    *manager.LimitManager
+	noCacheFill     int32 // the reads don't fill the caches if 1
 }
 
 type ClientConfig struct {
@@ -359,7 +361,7 @@ func (reader *Reader) readSliceRange(ctx context.Context, rs *rwSlice) (err erro
 	reader.err <- nil
 
 	// cache full block
-	if !reader.needCacheL1() && !reader.needCacheL2() || reader.ec.IsPreloadMode() {
+	if !reader.needCacheL1() && !reader.needCacheL2() || reader.ec.IsPreloadMode() || atomic.LoadInt32(&reader.noCacheFill) == 1 {
 		log.LogDebugf("TRACE blobStore readSliceRange exit without cache. read counter=%v", read)
 		return nil
 	}
@@ -409,6 +411,34 @@ func (reader *Reader) asyncCache(ctx context.Context, cacheKey string, objExtent
 	log.LogDebugf("TRACE blobStore asyncCache(L1) Exit. cacheKey=%v", cacheKey)
 }
 
+// SetCacheFill sets whether the reads fill the caches with the whole blocks, the reads of the
+// streaming workloads bypass them so that the hot data is not evicted.
+func (reader *Reader) SetCacheFill(fill bool) {
+	if reader == nil {
+		return
+	}
+	if fill {
+		atomic.StoreInt32(&reader.noCacheFill, 0)
+	} else {
+		atomic.StoreInt32(&reader.noCacheFill, 1)
+	}
+}
+
+// EvictCache evicts the blocks of the file from the local block cache asynchronously.
+func (reader *Reader) EvictCache() {
+	if reader == nil || !reader.enableBcache {
+		return
+	}
+	reader.Lock()
+	objExtentKeys := reader.objExtentKeys
+	reader.Unlock()
+	go func() {
+		for _, oek := range objExtentKeys {
+			reader.bc.Evict(util.GenerateKey(reader.volName, reader.ino, oek.FileOffset))
+		}
+	}()
+}
+
 func (reader *Reader) needCacheL2() bool {
 	if reader.cacheAction > proto.NoCache && reader.fileLength < uint64(reader.cacheThreshold) || reader.fileCache {
 		return true
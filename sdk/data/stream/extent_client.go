@@ -31,6 +31,7 @@ import (
 	"github.com/cubefs/cubefs/util"
 	"github.com/cubefs/cubefs/util/errors"
 	"github.com/cubefs/cubefs/util/log"
+	"github.com/cubefs/cubefs/util/retry"
 	"github.com/cubefs/cubefs/util/stat"
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -85,6 +86,17 @@ const (
 	fastStreamerEvictNum = 10000
 )
 
+// MountRetryPolicy is the policy of the retries to init the data wrapper of the mount, the
+// clients restarted together do not retry together.
+var MountRetryPolicy = retry.Policy{
+	MaxAttempts:  MaxMountRetryLimit + 1,
+	BaseInterval: MountRetryInterval,
+	MaxInterval:  MountRetryInterval * MaxMountRetryLimit,
+	Multiplier:   1.5,
+	Jitter:       0.5,
+	Retryable:    retry.ClassAll,
+}
+
 var (
 	// global object pools for memory optimization
 	openRequestPool    *sync.Pool
@@ -126,6 +138,8 @@ type ExtentConfig struct {
 	Preload           bool
 	ReadRate          int64
 	WriteRate         int64
+	MaxUploadMBps     int64
+	MaxDownloadMBps   int64
 	BcacheEnable      bool
 	BcacheDir         string
 	MaxStreamerLimit  int64
@@ -141,6 +155,8 @@ type ExtentConfig struct {
 
 	DisableMetaCache             bool
 	MinWriteAbleDataPartitionCnt int
+	// policy of the retries of the requests to the data partitions, DefaultSendRetryPolicy if nil
+	RetryPolicy *retry.Policy
 }
 
 type MultiVerMgr struct {
@@ -158,6 +174,8 @@ type ExtentClient struct {
 	maxStreamerLimit   int
 	readLimiter        *rate.Limiter
 	writeLimiter       *rate.Limiter
+	uploadLimiter      *rate.Limiter // bytes per second
+	downloadLimiter    *rate.Limiter // bytes per second
 	disableMetaCache   bool
 	volumeType         int
 	volumeName         string
@@ -254,24 +272,22 @@ func NewExtentClient(config *ExtentConfig) (client *ExtentClient, err error) {
 	client = new(ExtentClient)
 	client.LimitManager = manager.NewLimitManager(client)
 	client.LimitManager.WrapperUpdate = client.UploadFlowInfo
-	limit := 0
-retry:
-
-	client.dataWrapper, err = wrapper.NewDataPartitionWrapper(client, config.Volume, config.Masters, config.Preload, config.MinWriteAbleDataPartitionCnt, config.VerReadSeq)
-	if err != nil {
-		log.LogErrorf("NewExtentClient: new data partition wrapper failed: volume(%v) mayRetry(%v) err(%v)",
-			config.Volume, limit, err)
+	for attempts := 1; ; attempts++ {
+		client.dataWrapper, err = wrapper.NewDataPartitionWrapper(client, config.Volume, config.Masters, config.Preload, config.MinWriteAbleDataPartitionCnt, config.VerReadSeq)
+		if err == nil {
+			break
+		}
+		log.LogErrorf("NewExtentClient: new data partition wrapper failed: volume(%v) attempts(%v) err(%v)",
+			config.Volume, attempts, err)
 		if strings.Contains(err.Error(), proto.ErrVolNotExists.Error()) {
 			return nil, proto.ErrVolNotExists
 		}
-		if limit >= MaxMountRetryLimit {
+		if MountRetryPolicy.Exhausted(attempts) {
 			return nil, errors.Trace(err, "Init data wrapper failed!")
-		} else {
-			limit++
-			time.Sleep(MountRetryInterval * time.Duration(limit))
-			goto retry
 		}
+		MountRetryPolicy.Sleep(attempts)
 	}
+	client.dataWrapper.SetRetryPolicy(config.RetryPolicy)
 
 	client.streamers = make(map[uint64]*Streamer)
 	client.multiVerMgr = &MultiVerMgr{verList: &proto.VolVersionInfoList{}}
@@ -308,6 +324,8 @@ retry:
 	}
 	client.readLimiter = rate.NewLimiter(readLimit, defaultReadLimitBurst)
 	client.writeLimiter = rate.NewLimiter(writeLimit, defaultWriteLimitBurst)
+	client.uploadLimiter = newBandwidthLimiter(config.MaxUploadMBps)
+	client.downloadLimiter = newBandwidthLimiter(config.MaxDownloadMBps)
 
 	if config.MaxStreamerLimit <= 0 {
 		client.disableMetaCache = true
@@ -437,6 +455,41 @@ func (client *ExtentClient) OpenStreamWithCache(inode uint64, needBCache bool) e
 	return s.IssueOpenRequest()
 }
 
+// SetCacheFill sets whether the reads of the opened inode fill the block cache with the whole
+// extents, the reads of the streaming workloads bypass it so that the hot data is not evicted.
+func (client *ExtentClient) SetCacheFill(inode uint64, fill bool) {
+	client.streamerLock.Lock()
+	s, ok := client.streamers[inode]
+	client.streamerLock.Unlock()
+	if !ok {
+		return
+	}
+	if fill {
+		atomic.StoreInt32(&s.noCacheFill, 0)
+	} else {
+		atomic.StoreInt32(&s.noCacheFill, 1)
+	}
+}
+
+// EvictCache evicts the extents of the opened inode from the block cache asynchronously.
+func (client *ExtentClient) EvictCache(inode uint64) {
+	if !client.bcacheEnable || client.evictBcache == nil {
+		return
+	}
+	client.streamerLock.Lock()
+	s, ok := client.streamers[inode]
+	client.streamerLock.Unlock()
+	if !ok {
+		return
+	}
+	extents := s.extents.List()
+	go func() {
+		for _, ek := range extents {
+			client.evictBcache(util.GenerateRepVolKey(client.volumeName, inode, ek.PartitionId, ek.ExtentId, ek.FileOffset))
+		}
+	}()
+}
+
 // Release request shall grab the lock until request is sent to the request channel
 func (client *ExtentClient) CloseStream(inode uint64) error {
 	client.streamerLock.Lock()
@@ -718,7 +771,8 @@ func (client *ExtentClient) GetStreamer(inode uint64) *Streamer {
 }
 
 func (client *ExtentClient) GetRate() string {
-	return fmt.Sprintf("read: %v\nwrite: %v\n", getRate(client.readLimiter), getRate(client.writeLimiter))
+	return fmt.Sprintf("read: %v\nwrite: %v\nupload: %v\ndownload: %v\n", getRate(client.readLimiter), getRate(client.writeLimiter),
+		getBandwidth(client.uploadLimiter), getBandwidth(client.downloadLimiter))
 }
 
 func (client *ExtentClient) shouldBcache() bool {
@@ -741,6 +795,14 @@ func (client *ExtentClient) SetWriteRate(val int) string {
 	return setRate(client.writeLimiter, val)
 }
 
+func (client *ExtentClient) SetUploadBandwidth(mbps int) string {
+	return setBandwidth(client.uploadLimiter, mbps)
+}
+
+func (client *ExtentClient) SetDownloadBandwidth(mbps int) string {
+	return setBandwidth(client.downloadLimiter, mbps)
+}
+
 func setRate(lim *rate.Limiter, val int) string {
 	if val > 0 {
 		lim.SetLimit(rate.Limit(val))
@@ -750,6 +812,49 @@ func setRate(lim *rate.Limiter, val int) string {
 	return "unlimited"
 }
 
+// newBandwidthLimiter returns a token bucket of bytes, which allows a burst of one second.
+func newBandwidthLimiter(mbps int64) *rate.Limiter {
+	lim := rate.NewLimiter(rate.Inf, 0)
+	setBandwidth(lim, int(mbps))
+	return lim
+}
+
+func getBandwidth(lim *rate.Limiter) string {
+	if lim.Limit() == rate.Inf {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%vMB/s", int(lim.Limit())/util.MB)
+}
+
+func setBandwidth(lim *rate.Limiter, mbps int) string {
+	if mbps > 0 {
+		lim.SetLimit(rate.Limit(mbps * util.MB))
+		lim.SetBurst(mbps * util.MB)
+		return fmt.Sprintf("%vMB/s", mbps)
+	}
+	lim.SetLimit(rate.Inf)
+	return "unlimited"
+}
+
+// waitBandwidth waits until n bytes are allowed by the bandwidth limiter,
+// the bytes larger than the burst are waited in several rounds.
+func waitBandwidth(ctx context.Context, lim *rate.Limiter, n int) {
+	for n > 0 {
+		if lim.Limit() == rate.Inf {
+			return
+		}
+		wait := n
+		if burst := lim.Burst(); wait > burst {
+			wait = burst
+		}
+		if err := lim.WaitN(ctx, wait); err != nil {
+			log.LogWarnf("waitBandwidth: wait(%v) err(%v)", wait, err)
+			return
+		}
+		n -= wait
+	}
+}
+
 func (client *ExtentClient) Close() error {
 	// release streamers
 	var inodes []uint64
@@ -26,6 +26,7 @@ import (
 	"github.com/cubefs/cubefs/util"
 	"github.com/cubefs/cubefs/util/errors"
 	"github.com/cubefs/cubefs/util/log"
+	"github.com/cubefs/cubefs/util/retry"
 )
 
 var (
@@ -39,6 +40,16 @@ const (
 	StreamSendSleepInterval = 100 * time.Millisecond
 )
 
+// DefaultSendRetryPolicy is the policy of the retries of the requests to the data partitions.
+var DefaultSendRetryPolicy = retry.Policy{
+	MaxAttempts:  StreamSendMaxRetry,
+	BaseInterval: StreamSendSleepInterval,
+	MaxInterval:  2 * StreamSendSleepInterval,
+	Multiplier:   1.5,
+	Jitter:       0.5,
+	Retryable:    retry.ClassAll,
+}
+
 type GetReplyFunc func(conn *net.TCPConn) (err error, again bool)
 
 // StreamConn defines the struct of the stream connection.
@@ -106,15 +117,41 @@ func (sc *StreamConn) String() string {
 // Send send the given packet over the network through the stream connection until success
 // or the maximum number of retries is reached.
 func (sc *StreamConn) Send(retry *bool, req *Packet, getReply GetReplyFunc) (err error) {
-	for i := 0; i < StreamSendMaxRetry; i++ {
+	policy := sc.retryPolicy()
+	attempts := 1
+	for ; ; attempts++ {
 		err = sc.sendToDataPartition(req, retry, getReply)
 		if err == nil || err == proto.ErrCodeVersionOp || !*retry || err == TryOtherAddrError || strings.Contains(err.Error(), "OpForbidErr") {
 			return
 		}
+		if !policy.ShouldRetry(classifySendError(err)) {
+			log.LogWarnf("StreamConn Send: not retry err(%v)", err)
+			return
+		}
+		if policy.Exhausted(attempts) {
+			break
+		}
 		log.LogWarnf("StreamConn Send: err(%v)", err)
-		time.Sleep(StreamSendSleepInterval)
+		policy.Sleep(attempts)
 	}
-	return errors.New(fmt.Sprintf("StreamConn Send: retried %v times and still failed, sc(%v) reqPacket(%v)", StreamSendMaxRetry, sc, req))
+	return errors.New(fmt.Sprintf("StreamConn Send: retried %v times and still failed, sc(%v) reqPacket(%v)", attempts, sc, req))
+}
+
+// retryPolicy returns the policy of the retries of the mount, or the default.
+func (sc *StreamConn) retryPolicy() *retry.Policy {
+	if sc.dp.ClientWrapper != nil {
+		if policy := sc.dp.ClientWrapper.RetryPolicy(); policy != nil {
+			return policy
+		}
+	}
+	return &DefaultSendRetryPolicy
+}
+
+func classifySendError(err error) retry.Class {
+	if err == LimitedIoError {
+		return retry.ClassAgain
+	}
+	return retry.Classify(err, retry.ClassNetwork)
 }
 
 func (sc *StreamConn) sendToDataPartition(req *Packet, retry *bool, getReply GetReplyFunc) (err error) {
@@ -161,7 +198,8 @@ func (sc *StreamConn) sendToDataPartition(req *Packet, retry *bool, getReply Get
 }
 
 func (sc *StreamConn) sendToConn(conn *net.TCPConn, req *Packet, getReply GetReplyFunc) (err error) {
-	for i := 0; i < StreamSendMaxRetry; i++ {
+	policy := sc.retryPolicy()
+	for i := 1; ; i++ {
 		log.LogDebugf("sendToConn: send to addr(%v), reqPacket(%v)", sc.currAddr, req)
 		err = req.WriteToConn(conn)
 		if err != nil {
@@ -182,9 +220,13 @@ func (sc *StreamConn) sendToConn(conn *net.TCPConn, req *Packet, getReply GetRep
 		if err == LimitedIoError {
 			i -= 1
 		}
+		if !policy.ShouldRetry(retry.ClassAgain) || policy.Exhausted(i) {
+			log.LogWarnf("sendToConn: getReply error and not RETRY, sc(%v) attempts(%v) err(%v)", sc, i, err)
+			break
+		}
 
 		log.LogWarnf("sendToConn: getReply error and will RETRY, sc(%v) err(%v)", sc, err)
-		time.Sleep(StreamSendSleepInterval)
+		policy.Sleep(i)
 	}
 
 	log.LogDebugf("sendToConn exit: send to addr(%v) reqPacket(%v) err(%v)", sc.currAddr, req, err)
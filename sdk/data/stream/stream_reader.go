@@ -47,6 +47,7 @@ type Streamer struct {
 	dirty                bool             // whether current open handler is in the dirty list
 	isOpen               bool
 	needBCache           bool
+	noCacheFill          int32 // the reads don't fill the block cache if 1
 	request              chan interface{} // request channel, write/flush/close
 	done                 chan struct{}    // stream writer is being closed
 	writeLock            sync.Mutex
@@ -134,6 +135,7 @@ func (s *Streamer) read(data []byte, offset int, size int) (total int, err error
 	log.LogDebugf("action[streamer.read] offset %v size %v", offset, size)
 	ctx := context.Background()
 	s.client.readLimiter.Wait(ctx)
+	waitBandwidth(ctx, s.client.downloadLimiter, size)
 	s.client.LimitManager.ReadAlloc(ctx, size)
 	requests = s.extents.PrepareReadRequests(offset, size, data)
 	for _, req := range requests {
@@ -215,7 +217,7 @@ func (s *Streamer) read(data []byte, offset int, size int) (total int, err error
 				break
 			}
 
-			if s.client.bcacheEnable && s.needBCache && filesize <= bcache.MaxFileSize {
+			if s.client.bcacheEnable && s.needBCache && filesize <= bcache.MaxFileSize && s.cacheFill() {
 				// limit big block cache
 				if s.exceedBlockSize(req.ExtentKey.Size) && atomic.LoadInt32(&s.client.inflightL1BigBlock) > 10 {
 					// do nothing
@@ -249,6 +251,10 @@ func (s *Streamer) read(data []byte, offset int, size int) (total int, err error
 	return
 }
 
+func (s *Streamer) cacheFill() bool {
+	return atomic.LoadInt32(&s.noCacheFill) == 0
+}
+
 func (s *Streamer) asyncBlockCache() {
 	if !s.needBCache || !s.isOpen {
 		return
@@ -307,6 +307,7 @@ func (s *Streamer) write(data []byte, offset, size, flags int, checkFunc func()
 	if flags&proto.FlagsSyncWrite != 0 {
 		direct = true
 	}
+	waitBandwidth(context.Background(), s.client.uploadLimiter, size)
 begin:
 	if flags&proto.FlagsAppend != 0 {
 		filesize, _ := s.extents.Size()
@@ -341,6 +342,14 @@ begin:
 
 	for _, req := range requests {
 		var writeSize int
+		if req.ExtentKey != nil && s.isSharedExtent(req.ExtentKey) {
+			// the extents shared with the clone source are never modified, the data is written to a new extent
+			log.LogDebugf("action[streamer.write] ino %v extent key (%v) is shared, write by append", s.inode, req.ExtentKey)
+			req.ExtentKey = nil
+			if s.client.bcacheEnable {
+				go s.client.evictBcache(util.GenerateKey(s.client.volumeName, s.inode, uint64(req.FileOffset)))
+			}
+		}
 		if req.ExtentKey != nil {
 			if s.client.bcacheEnable {
 				cacheKey := util.GenerateRepVolKey(s.client.volumeName, s.inode, req.ExtentKey.PartitionId, req.ExtentKey.ExtentId, uint64(req.FileOffset))
@@ -405,6 +414,11 @@ begin:
 	return
 }
 
+func (s *Streamer) isSharedExtent(ek *proto.ExtentKey) bool {
+	dp, err := s.client.dataWrapper.GetDataPartition(ek.PartitionId)
+	return err == nil && dp.IsShared
+}
+
 func (s *Streamer) doOverWriteByAppend(req *ExtentRequest, direct bool) (total int, extKey *proto.ExtentKey, err error, status int32) {
 	// the extent key needs to be updated because when preparing the requests,
 	// the obtained extent key could be a local key which can be inconsistent with the remote key.
@@ -17,6 +17,7 @@ package wrapper
 import (
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -141,9 +142,10 @@ func (dp *DataPartition) CheckAllHostsIsAvail(exclude map[string]struct{}) {
 	}
 }
 
-// GetAllAddrs returns the addresses of all the replicas of the data partition.
+// GetAllAddrs returns the addresses of all the replicas of the data partition, followed by the
+// write epoch, so that the datanodes can reject the writes by the stale hosts.
 func (dp *DataPartition) GetAllAddrs() string {
-	return strings.Join(dp.Hosts[1:], proto.AddrSplit) + proto.AddrSplit
+	return strings.Join(dp.Hosts[1:], proto.AddrSplit) + proto.AddrSplit + strconv.FormatUint(dp.WriteEpoch, 10)
 }
 
 func isExcluded(dp *DataPartition, exclude map[string]struct{}) bool {
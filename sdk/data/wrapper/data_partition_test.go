@@ -74,3 +74,15 @@ func TestKmin(t *testing.T) {
 	}
 	fmt.Println()
 }
+
+func TestGetAllAddrs(t *testing.T) {
+	dp := new(DataPartition)
+	dp.Hosts = []string{"a", "b", "c"}
+	if addrs := dp.GetAllAddrs(); addrs != "b/c/0" {
+		t.Fatalf("unexpected addrs %v", addrs)
+	}
+	dp.WriteEpoch = 5
+	if addrs := dp.GetAllAddrs(); addrs != "b/c/5" {
+		t.Fatalf("unexpected addrs %v", addrs)
+	}
+}
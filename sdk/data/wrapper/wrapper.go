@@ -29,6 +29,7 @@ import (
 	"github.com/cubefs/cubefs/util/errors"
 	"github.com/cubefs/cubefs/util/iputil"
 	"github.com/cubefs/cubefs/util/log"
+	"github.com/cubefs/cubefs/util/retry"
 	"github.com/cubefs/cubefs/util/ump"
 )
 
@@ -82,6 +83,8 @@ type Wrapper struct {
 	verConfReadSeq              uint64
 	verReadSeq                  uint64
 	SimpleClient                SimpleClientInfo
+
+	retryPolicy *retry.Policy
 }
 
 // NewDataPartitionWrapper returns a new data partition wrapper.
@@ -501,6 +504,8 @@ func (w *Wrapper) replaceOrInsertPartition(dp *DataPartition) {
 		old.Hosts = dp.Hosts
 		old.IsDiscard = dp.IsDiscard
 		old.NearHosts = dp.Hosts
+		old.WriteEpoch = dp.WriteEpoch
+		old.IsShared = dp.IsShared
 
 		dp.Metrics = old.Metrics
 	} else {
@@ -611,6 +616,19 @@ func (w *Wrapper) NearRead() bool {
 	return w.nearRead
 }
 
+func (w *Wrapper) SetRetryPolicy(policy *retry.Policy) {
+	w.retryPolicy = policy
+	if policy != nil {
+		log.LogInfof("SetRetryPolicy: set retry policy to %v", policy)
+	}
+}
+
+// RetryPolicy returns the policy of the retries of the requests to the data partitions,
+// nil if the default of the client is used.
+func (w *Wrapper) RetryPolicy() *retry.Policy {
+	return w.retryPolicy
+}
+
 // Sort hosts by distance form local
 func (w *Wrapper) sortHostsByDistance(srcHosts []string) []string {
 	hosts := make([]string, len(srcHosts))
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cubefs/cubefs/proto"
@@ -252,7 +253,7 @@ func (api *AdminAPI) DeleteVolumeWithAuthNode(volName, authKey, clientIDKey stri
 }
 
 func (api *AdminAPI) UnDeleteVolume(volName, authKey string, status bool) (err error) {
-	request := newRequest(get, proto.AdminDeleteVol)
+	request := newRequest(get, proto.AdminDeleteVol).Header(api.h)
 	request.addParam("name", volName)
 	request.addParam("authKey", authKey)
 	request.addParam("delete", strconv.FormatBool(false))
@@ -292,6 +293,9 @@ func (api *AdminAPI) UpdateVolume(
 	request.addParam("enableQuota", strconv.FormatBool(vv.EnableQuota))
 	request.addParam("deleteLockTime", strconv.FormatInt(vv.DeleteLockTime, 10))
 	request.addParam("autoDpMetaRepair", strconv.FormatBool(vv.EnableAutoDpMetaRepair))
+	request.addParam("affinityLabels", strings.Join(vv.AffinityLabels, ","))
+	request.addParam("antiAffinityLabels", strings.Join(vv.AntiAffinityLabels, ","))
+	request.addParam("fsyncPolicy", vv.FsyncPolicy)
 	request.addParam("clientIDKey", clientIDKey)
 	if txMask != "" {
 		request.addParam("enableTxMask", txMask)
@@ -313,6 +317,30 @@ func (api *AdminAPI) UpdateVolume(
 	return
 }
 
+func (api *AdminAPI) RenameVolume(volName, newName, authKey string) (err error) {
+	request := newRequest(get, proto.AdminRenameVol).Header(api.h)
+	request.addParam("name", volName)
+	request.addParam("newName", newName)
+	request.addParam("authKey", authKey)
+	_, err = api.mc.serveRequest(request)
+	return
+}
+
+// CloneVolume creates the volume newName with the configuration of the volume and a snapshot of it,
+// the metadata of the snapshot is left to be copied into the new volume.
+func (api *AdminAPI) CloneVolume(volName, newName, owner, authKey string) (vv *proto.SimpleVolView, err error) {
+	request := newRequest(get, proto.AdminCloneVol).Header(api.h)
+	request.addParam("name", volName)
+	request.addParam("newName", newName)
+	request.addParam("authKey", authKey)
+	if owner != "" {
+		request.addParam("owner", owner)
+	}
+	vv = &proto.SimpleVolView{}
+	err = api.mc.requestWith(vv, request)
+	return
+}
+
 func (api *AdminAPI) PutDataPartitions(volName string, dpsView []byte) (err error) {
 	return api.mc.request(newRequest(post, proto.AdminPutDataPartitions).
 		Header(api.h).addParam("name", volName).Body(dpsView))
@@ -342,7 +370,7 @@ func (api *AdminAPI) CreateVolName(volName, owner string, capacity uint64, delet
 	mpCount, dpCount, replicaNum, dpSize, volType int, followerRead bool, zoneName, cacheRuleKey string, ebsBlkSize,
 	cacheCapacity, cacheAction, cacheThreshold, cacheTTL, cacheHighWater, cacheLowWater, cacheLRUInterval int,
 	dpReadOnlyWhenVolFull bool, txMask string, txTimeout uint32, txConflictRetryNum int64, txConflictRetryInterval int64, optEnableQuota string,
-	clientIDKey string,
+	clientIDKey string, caseInsensitive bool, affinityLabels, antiAffinityLabels []string, fsyncPolicy string,
 ) (err error) {
 	request := newRequest(get, proto.AdminCreateVol).Header(api.h)
 	request.addParam("name", volName)
@@ -371,6 +399,16 @@ func (api *AdminAPI) CreateVolName(volName, owner string, capacity uint64, delet
 	request.addParam("dpReadOnlyWhenVolFull", strconv.FormatBool(dpReadOnlyWhenVolFull))
 	request.addParam("enableQuota", optEnableQuota)
 	request.addParam("clientIDKey", clientIDKey)
+	request.addParam("caseInsensitive", strconv.FormatBool(caseInsensitive))
+	if len(affinityLabels) > 0 {
+		request.addParam("affinityLabels", strings.Join(affinityLabels, ","))
+	}
+	if len(antiAffinityLabels) > 0 {
+		request.addParam("antiAffinityLabels", strings.Join(antiAffinityLabels, ","))
+	}
+	if fsyncPolicy != "" {
+		request.addParam("fsyncPolicy", fsyncPolicy)
+	}
 	if txMask != "" {
 		request.addParam("enableTxMask", txMask)
 	}
@@ -683,6 +721,117 @@ func (api *AdminAPI) GetDiscardDataPartition() (discardDpInfos *proto.DiscardDat
 	return
 }
 
+func (api *AdminAPI) GetTopView(sortBy string, limit int) (view *proto.TopView, err error) {
+	view = &proto.TopView{}
+	err = api.mc.requestWith(view, newRequest(get, proto.AdminGetTopView).Header(api.h).
+		addParam("sortBy", sortBy).
+		addParam("limit", strconv.Itoa(limit)))
+	return
+}
+
+// SimulatePlacement returns the simulated effect on the partitions of removing the nodes and zones
+// and adding nodes in the zone, an empty zone means the added nodes fit any zone.
+func (api *AdminAPI) SimulatePlacement(removeNodes, removeZones []string, addDataNodes, addMetaNodes int,
+	zoneName string,
+) (result *proto.PlacementSimulation, err error) {
+	result = &proto.PlacementSimulation{}
+	err = api.mc.requestWith(result, newRequest(get, proto.AdminSimulatePlacement).Header(api.h).
+		addParam("removeNodes", strings.Join(removeNodes, ",")).
+		addParam("removeZones", strings.Join(removeZones, ",")).
+		addParam("addDataNodes", strconv.Itoa(addDataNodes)).
+		addParam("addMetaNodes", strconv.Itoa(addMetaNodes)).
+		addParam("zoneName", zoneName))
+	return
+}
+
+// StartConfigRollout starts to roll out the value of the cluster setting to the canary nodes, which are
+// the nodes of the addresses if any, or the nodes of the label if any, otherwise the percent of the nodes.
+func (api *AdminAPI) StartConfigRollout(key, value string, canaryNodes []string, canaryLabel string, canaryPercent int,
+	observeSec int64, maxErrRateDelta float64,
+) (rollout *proto.ConfigRollout, err error) {
+	rollout = &proto.ConfigRollout{}
+	err = api.mc.requestWith(rollout, newRequest(post, proto.AdminStartConfigRollout).Header(api.h).
+		addParam("key", key).
+		addParam("value", value).
+		addParam("canaryNodes", strings.Join(canaryNodes, ",")).
+		addParam("canaryLabel", canaryLabel).
+		addParam("canaryPercent", strconv.Itoa(canaryPercent)).
+		addParam("observeSec", strconv.FormatInt(observeSec, 10)).
+		addParam("maxErrRateDelta", strconv.FormatFloat(maxErrRateDelta, 'f', -1, 64)))
+	return
+}
+
+// GetConfigRollout returns the rollout in progress if any and the recent rollouts finished.
+func (api *AdminAPI) GetConfigRollout() (view *proto.ConfigRolloutView, err error) {
+	view = &proto.ConfigRolloutView{}
+	err = api.mc.requestWith(view, newRequest(get, proto.AdminGetConfigRollout).Header(api.h))
+	return
+}
+
+func (api *AdminAPI) PromoteConfigRollout() (rollout *proto.ConfigRollout, err error) {
+	rollout = &proto.ConfigRollout{}
+	err = api.mc.requestWith(rollout, newRequest(post, proto.AdminPromoteConfigRollout).Header(api.h))
+	return
+}
+
+func (api *AdminAPI) RollbackConfigRollout() (rollout *proto.ConfigRollout, err error) {
+	rollout = &proto.ConfigRollout{}
+	err = api.mc.requestWith(rollout, newRequest(post, proto.AdminRollbackConfigRollout).Header(api.h))
+	return
+}
+
+// SetFeatureFlag sets the mode of the feature flag, which is off, optIn for the volumes opted in only,
+// or on for all the volumes.
+func (api *AdminAPI) SetFeatureFlag(feature, mode string) (err error) {
+	err = api.mc.request(newRequest(post, proto.AdminSetFeatureFlag).Header(api.h).
+		addParam("feature", feature).
+		addParam("mode", mode))
+	return
+}
+
+func (api *AdminAPI) GetFeatureFlags() (flags []*proto.FeatureFlag, err error) {
+	flags = make([]*proto.FeatureFlag, 0)
+	err = api.mc.requestWith(&flags, newRequest(get, proto.AdminGetFeatureFlags).Header(api.h))
+	return
+}
+
+// SetVolFeatureFlag opts the volume in or out of the feature flag.
+func (api *AdminAPI) SetVolFeatureFlag(volName, feature string, enable bool) (flags *proto.VolFeatureFlags, err error) {
+	flags = &proto.VolFeatureFlags{}
+	err = api.mc.requestWith(flags, newRequest(post, proto.AdminVolSetFeatureFlag).Header(api.h).
+		addParam("name", volName).
+		addParam("feature", feature).
+		addParam("enable", strconv.FormatBool(enable)))
+	return
+}
+
+// GetVolAuditTrail returns the audit records of the admin operations on the volume in
+// [startTime, endTime] of unix seconds, endTime of 0 means no upper bound.
+func (api *AdminAPI) GetVolAuditTrail(volName string, startTime, endTime int64, limit int) (records []*proto.VolAuditRecord, err error) {
+	records = make([]*proto.VolAuditRecord, 0)
+	err = api.mc.requestWith(&records, newRequest(get, proto.AdminVolAuditTrail).Header(api.h).
+		addParam("name", volName).
+		addParamAny("startTime", startTime).
+		addParamAny("endTime", endTime).
+		addParam("limit", strconv.Itoa(limit)))
+	return
+}
+
+func (api *AdminAPI) SetClientUpgrade(info *proto.ClientUpgradeInfo) (err error) {
+	err = api.mc.request(newRequest(post, proto.AdminSetClientUpgrade).Header(api.h).
+		addParam("version", info.Version).
+		addParam("url", info.URL).
+		addParam("checksum", info.Checksum).
+		addParam("signature", info.Signature))
+	return
+}
+
+func (api *AdminAPI) GetClientUpgrade() (info *proto.ClientUpgradeInfo, err error) {
+	info = &proto.ClientUpgradeInfo{}
+	err = api.mc.requestWith(info, newRequest(get, proto.AdminGetClientUpgrade).Header(api.h))
+	return
+}
+
 func (api *AdminAPI) SetDataPartitionDiscard(partitionId uint64, discard bool, force bool) (err error) {
 	request := newRequest(post, proto.AdminSetDpDiscard).
 		Header(api.h).
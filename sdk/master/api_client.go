@@ -87,6 +87,14 @@ func (api *ClientAPI) GetVolumeStat(volName string) (info *proto.VolStatInfo, er
 	return
 }
 
+// GetVolFeatureFlags returns the feature flags the volume opts in and the features enabled for it.
+func (api *ClientAPI) GetVolFeatureFlags(volName string) (flags *proto.VolFeatureFlags, err error) {
+	flags = &proto.VolFeatureFlags{}
+	err = api.mc.requestWith(flags, newRequest(get, proto.ClientVolFeatureFlags).
+		Header(api.h).addParam("name", volName))
+	return
+}
+
 func (api *ClientAPI) GetMetaPartition(partitionID uint64) (partition *proto.MetaPartitionInfo, err error) {
 	partition = &proto.MetaPartitionInfo{}
 	err = api.mc.requestWith(partition, newRequest(get, proto.ClientMetaPartition).
@@ -16,6 +16,7 @@ package master
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/cubefs/cubefs/proto"
 )
@@ -118,6 +119,48 @@ func (api *NodeAPI) DataNodeDecommission(nodeAddr string, count int, clientIDKey
 	return
 }
 
+// SetDataNodeAllocWeight sets the weight of partition allocation of the data node, 0 means to reset it.
+func (api *NodeAPI) SetDataNodeAllocWeight(nodeAddr string, weight float64) (err error) {
+	return api.setNodeAllocWeight(nodeAddr, "2", weight)
+}
+
+// SetMetaNodeAllocWeight sets the weight of partition allocation of the meta node, 0 means to reset it.
+func (api *NodeAPI) SetMetaNodeAllocWeight(nodeAddr string, weight float64) (err error) {
+	return api.setNodeAllocWeight(nodeAddr, "1", weight)
+}
+
+func (api *NodeAPI) setNodeAllocWeight(nodeAddr string, nodeType string, weight float64) (err error) {
+	request := newRequest(post, proto.AdminSetNodeAllocWeight).Header(api.h)
+	request.addParam("addr", nodeAddr)
+	request.addParam("nodeType", nodeType)
+	request.addParam("allocWeight", strconv.FormatFloat(weight, 'f', -1, 64))
+	if _, err = api.mc.serveRequest(request); err != nil {
+		return
+	}
+	return
+}
+
+// SetDataNodeLabels sets the labels of the data node, empty labels means to clear them.
+func (api *NodeAPI) SetDataNodeLabels(nodeAddr string, labels []string) (err error) {
+	return api.setNodeLabels(nodeAddr, "2", labels)
+}
+
+// SetMetaNodeLabels sets the labels of the meta node, empty labels means to clear them.
+func (api *NodeAPI) SetMetaNodeLabels(nodeAddr string, labels []string) (err error) {
+	return api.setNodeLabels(nodeAddr, "1", labels)
+}
+
+func (api *NodeAPI) setNodeLabels(nodeAddr string, nodeType string, labels []string) (err error) {
+	request := newRequest(post, proto.AdminSetNodeLabels).Header(api.h)
+	request.addParam("addr", nodeAddr)
+	request.addParam("nodeType", nodeType)
+	request.addParam("labels", strings.Join(labels, ","))
+	if _, err = api.mc.serveRequest(request); err != nil {
+		return
+	}
+	return
+}
+
 func (api *NodeAPI) MetaNodeDecommission(nodeAddr string, count int, clientIDKey string) (err error) {
 	request := newRequest(get, proto.DecommissionMetaNode).Header(api.h).NoTimeout()
 	request.addParam("addr", nodeAddr)
@@ -127,3 +127,16 @@ func (api *UserAPI) ListUsersOfVol(vol string) (users []string, err error) {
 	err = api.mc.requestWith(&users, newRequest(get, proto.UsersOfVol).Header(api.h).addParam("name", vol))
 	return
 }
+
+func (api *UserAPI) SetQuota(param *proto.UserQuotaParam, clientIDKey string) (userInfo *proto.UserInfo, err error) {
+	userInfo = &proto.UserInfo{}
+	err = api.mc.requestWith(userInfo, newRequest(post, proto.UserSetQuota).
+		Header(api.h).Body(param).addParam("clientIDKey", clientIDKey))
+	return
+}
+
+func (api *UserAPI) GetUsage(userID string) (usage *proto.UserUsage, err error) {
+	usage = &proto.UserUsage{}
+	err = api.mc.requestWith(usage, newRequest(get, proto.UserGetUsage).Header(api.h).addParam("user", userID))
+	return
+}
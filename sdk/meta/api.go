@@ -100,6 +100,9 @@ func (mw *MetaWrapper) Statfs() (total, used, inodeCount uint64) {
 }
 
 func (mw *MetaWrapper) Create_ll(parentID uint64, name string, mode, uid, gid uint32, target []byte, fullPath string, ignoreExist bool) (*proto.InodeInfo, error) {
+	if err := mw.checkWritable(parentID); err != nil {
+		return nil, err
+	}
 	// if mw.EnableTransaction {
 	var txMask proto.TxOpMask
 	if proto.IsRegular(mode) {
@@ -629,6 +632,9 @@ func (mw *MetaWrapper) shouldNotMoveToTrash(parentMP *MetaPartition, parentIno u
  * and the caller should make sure InodeInfo is valid before using it.
  */
 func (mw *MetaWrapper) Delete_ll(parentID uint64, name string, isDir bool, fullPath string) (*proto.InodeInfo, error) {
+	if err := mw.checkWritable(parentID); err != nil {
+		return nil, err
+	}
 	if mw.enableTx(proto.TxOpMaskRemove) {
 		return mw.txDelete_ll(parentID, name, isDir, fullPath)
 	} else {
@@ -637,6 +643,9 @@ func (mw *MetaWrapper) Delete_ll(parentID uint64, name string, isDir bool, fullP
 }
 
 func (mw *MetaWrapper) DeleteWithCond_ll(parentID, cond uint64, name string, isDir bool, fullPath string) (*proto.InodeInfo, error) {
+	if err := mw.checkWritable(parentID); err != nil {
+		return nil, err
+	}
 	return mw.deletewithcond_ll(parentID, cond, name, isDir, fullPath)
 }
 
@@ -1063,6 +1072,12 @@ func (mw *MetaWrapper) deletewithcond_ll(parentID, cond uint64, name string, isD
 }
 
 func (mw *MetaWrapper) Rename_ll(srcParentID uint64, srcName string, dstParentID uint64, dstName string, srcFullPath string, dstFullPath string, overwritten bool) (err error) {
+	if err = mw.checkWritable(srcParentID); err != nil {
+		return
+	}
+	if err = mw.checkWritable(dstParentID); err != nil {
+		return
+	}
 	if mw.enableTx(proto.TxOpMaskRename) {
 		return mw.txRename_ll(srcParentID, srcName, dstParentID, dstName, srcFullPath, dstFullPath, overwritten)
 	} else {
@@ -1299,7 +1314,7 @@ func (mw *MetaWrapper) rename_ll(srcParentID uint64, srcName string, dstParentID
 		return syscall.ENOENT
 	}
 
-	status, _, err = mw.ilink(srcMP, inode, srcFullPath)
+	status, _, err = mw.ilinkRename(srcMP, inode, srcFullPath)
 	if err != nil || status != statusOK {
 		log.LogErrorf("Rename_ll:ilink srcParentID %v srcFullPath(%v) failed %v ", srcParentID, srcFullPath, err)
 		return statusToErrno(status)
@@ -1487,6 +1502,9 @@ func (mw *MetaWrapper) ReadDirLimit_ll(parentID uint64, from string, limit uint6
 }
 
 func (mw *MetaWrapper) DentryCreate_ll(parentID uint64, name string, inode uint64, mode uint32, fullPath string) error {
+	if err := mw.checkWritable(parentID); err != nil {
+		return err
+	}
 	parentMP := mw.getPartitionByInode(parentID)
 	if parentMP == nil {
 		return syscall.ENOENT
@@ -1500,6 +1518,9 @@ func (mw *MetaWrapper) DentryCreate_ll(parentID uint64, name string, inode uint6
 }
 
 func (mw *MetaWrapper) DentryUpdate_ll(parentID uint64, name string, inode uint64, fullPath string) (oldInode uint64, err error) {
+	if err = mw.checkWritable(parentID); err != nil {
+		return
+	}
 	parentMP := mw.getPartitionByInode(parentID)
 	if parentMP == nil {
 		err = syscall.ENOENT
@@ -1547,6 +1568,9 @@ func (mw *MetaWrapper) SplitExtentKey(parentInode, inode uint64, ek proto.Extent
 
 // Used as a callback by stream sdk
 func (mw *MetaWrapper) AppendExtentKey(parentInode, inode uint64, ek proto.ExtentKey, discard []proto.ExtentKey) (int, error) {
+	if err := mw.checkWritable(inode); err != nil {
+		return statusError, err
+	}
 	mp := mw.getPartitionByInode(inode)
 	if mp == nil {
 		return statusError, syscall.ENOENT
@@ -1579,12 +1603,15 @@ func (mw *MetaWrapper) AppendExtentKey(parentInode, inode uint64, ek proto.Exten
 
 // AppendExtentKeys append multiple extent key into specified inode with single request.
 func (mw *MetaWrapper) AppendExtentKeys(inode uint64, eks []proto.ExtentKey) error {
+	if err := mw.checkWritable(inode); err != nil {
+		return err
+	}
 	mp := mw.getPartitionByInode(inode)
 	if mp == nil {
 		return syscall.ENOENT
 	}
 
-	status, err := mw.appendExtentKeys(mp, inode, eks)
+	status, err := mw.appendExtentKeys(mp, &proto.AppendExtentKeysRequest{Inode: inode, Extents: eks})
 	if err != nil || status != statusOK {
 		log.LogErrorf("AppendExtentKeys: inode(%v) extentKeys(%v) err(%v) status(%v)", inode, eks, err, status)
 		return statusToErrno(status)
@@ -1593,6 +1620,27 @@ func (mw *MetaWrapper) AppendExtentKeys(inode uint64, eks []proto.ExtentKey) err
 	return nil
 }
 
+// AppendExtentKeysIfSize appends multiple extent keys into the specified inode only if its size
+// is the size, EINVAL is returned otherwise.
+func (mw *MetaWrapper) AppendExtentKeysIfSize(inode, size uint64, eks []proto.ExtentKey) error {
+	if err := mw.checkWritable(inode); err != nil {
+		return err
+	}
+	mp := mw.getPartitionByInode(inode)
+	if mp == nil {
+		return syscall.ENOENT
+	}
+
+	req := &proto.AppendExtentKeysRequest{Inode: inode, Extents: eks, CheckSize: true, Size: size}
+	status, err := mw.appendExtentKeys(mp, req)
+	if err != nil || status != statusOK {
+		log.LogErrorf("AppendExtentKeysIfSize: inode(%v) size(%v) extentKeys(%v) err(%v) status(%v)", inode, size, eks, err, status)
+		return statusToErrno(status)
+	}
+	log.LogDebugf("AppendExtentKeysIfSize: ino(%v) size(%v) extentKeys(%v)", inode, size, eks)
+	return nil
+}
+
 // AppendObjExtentKeys append multiple obj extent key into specified inode with single request.
 func (mw *MetaWrapper) AppendObjExtentKeys(inode uint64, eks []proto.ObjExtentKey) error {
 	mp := mw.getPartitionByInode(inode)
@@ -1650,6 +1698,9 @@ func (mw *MetaWrapper) GetObjExtents(inode uint64) (gen uint64, size uint64, ext
 }
 
 func (mw *MetaWrapper) Truncate(inode, size uint64, fullPath string) error {
+	if err := mw.checkWritable(inode); err != nil {
+		return err
+	}
 	mp := mw.getPartitionByInode(inode)
 	if mp == nil {
 		log.LogErrorf("Truncate: No inode partition, ino(%v)", inode)
@@ -1664,6 +1715,9 @@ func (mw *MetaWrapper) Truncate(inode, size uint64, fullPath string) error {
 }
 
 func (mw *MetaWrapper) Link(parentID uint64, name string, ino uint64, fullPath string) (*proto.InodeInfo, error) {
+	if err := mw.checkWritable(parentID); err != nil {
+		return nil, err
+	}
 	// if mw.EnableTransaction {
 	if mw.EnableTransaction&proto.TxOpMaskLink > 0 {
 		return mw.txLink(parentID, name, ino, fullPath)
@@ -1835,6 +1889,9 @@ func (mw *MetaWrapper) Evict(inode uint64, fullPath string) error {
 }
 
 func (mw *MetaWrapper) Setattr(inode uint64, valid, mode, uid, gid uint32, atime, mtime int64) error {
+	if err := mw.checkWritable(inode); err != nil {
+		return err
+	}
 	mp := mw.getPartitionByInode(inode)
 	if mp == nil {
 		log.LogErrorf("Setattr: No such partition, ino(%v)", inode)
@@ -2160,6 +2217,9 @@ func (mw *MetaWrapper) ListMultipart_ll(prefix, delimiter, keyMarker string, mul
 
 func (mw *MetaWrapper) XAttrSet_ll(inode uint64, name, value []byte) error {
 	var err error
+	if err = mw.checkWritable(inode); err != nil {
+		return err
+	}
 	mp := mw.getPartitionByInode(inode)
 	if mp == nil {
 		log.LogErrorf("XAttrSet_ll: no such partition, inode(%v)", inode)
@@ -2177,6 +2237,9 @@ func (mw *MetaWrapper) XAttrSet_ll(inode uint64, name, value []byte) error {
 
 func (mw *MetaWrapper) BatchSetXAttr_ll(inode uint64, attrs map[string]string) error {
 	var err error
+	if err = mw.checkWritable(inode); err != nil {
+		return err
+	}
 	mp := mw.getPartitionByInode(inode)
 	if mp == nil {
 		log.LogErrorf("XAttrSet_ll: no such partition, inode(%v)", inode)
@@ -2242,6 +2305,9 @@ func (mw *MetaWrapper) XAttrGet_ll(inode uint64, name string) (*proto.XAttrInfo,
 // XAttrDel_ll is a low-level meta api that deletes specified xattr.
 func (mw *MetaWrapper) XAttrDel_ll(inode uint64, name string) error {
 	var err error
+	if err = mw.checkWritable(inode); err != nil {
+		return err
+	}
 	mp := mw.getPartitionByInode(inode)
 	if mp == nil {
 		log.LogErrorf("XAttrDel_ll: no such partition, inode(%v)", inode)
@@ -2719,6 +2785,14 @@ func (mw *MetaWrapper) QueryTrashDisableByClient() bool {
 	return mw.disableTrashByClient
 }
 
+// RestoreFromTrash restores the deleted file or directory in trash to its original path.
+func (mw *MetaWrapper) RestoreFromTrash(trashPath string) (string, error) {
+	if mw.disableTrash || mw.trashPolicy == nil {
+		return "", errors.New("trash is not enabled")
+	}
+	return mw.trashPolicy.Restore(trashPath)
+}
+
 func (mw *MetaWrapper) LockDir(ino uint64, lease uint64, lockId int64) (retLockId int64, err error) {
 	mp := mw.getPartitionByInode(ino)
 	if mp == nil {
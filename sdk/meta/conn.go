@@ -23,13 +23,26 @@ import (
 	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/util/errors"
 	"github.com/cubefs/cubefs/util/log"
+	"github.com/cubefs/cubefs/util/retry"
 )
 
 const (
-	SendRetryLimit    = 200 // times
 	SendRetryInterval = 100 // ms
+	MinSendTimeLimit  = 20  // s
 )
 
+// DefaultSendRetryPolicy is the policy of the retries of the requests to the meta partitions,
+// the attempts are unlimited and the retries are bounded by the send timeout of the mount, so
+// that the leader elections lasting no longer than it are ridden out.
+var DefaultSendRetryPolicy = retry.Policy{
+	MaxAttempts:  0,
+	BaseInterval: SendRetryInterval * time.Millisecond,
+	MaxInterval:  time.Second,
+	Multiplier:   1.5,
+	Jitter:       0.5,
+	Retryable:    retry.ClassAll,
+}
+
 type MetaConn struct {
 	conn *net.TCPConn
 	id   uint64 // PartitionID
@@ -65,21 +78,19 @@ func (mw *MetaWrapper) sendToMetaPartition(mp *MetaPartition, req *proto.Packet)
 		start   time.Time
 		lastSeq uint64
 	)
-	var sendTimeLimit int
-	if mw.metaSendTimeout < 20 {
-		sendTimeLimit = 20 * 1000 // ms
-	} else {
-		sendTimeLimit = int(mw.metaSendTimeout) * 1000 // ms
-	}
-
-	delta := (sendTimeLimit*2/SendRetryLimit - SendRetryInterval*2) / SendRetryLimit // ms
-	log.LogDebugf("mw.metaSendTimeout: %v s, sendTimeLimit: %v ms, delta: %v ms, req %v", mw.metaSendTimeout, sendTimeLimit, delta, req)
+	sendTimeLimit := mw.sendTimeLimit()
+	policy := mw.sendRetryPolicy()
+	log.LogDebugf("mw.metaSendTimeout: %v s, sendTimeLimit: %v, policy: %v, req %v", mw.metaSendTimeout, sendTimeLimit, policy, req)
 
 	req.ExtentType |= proto.MultiVersionFlag
 
 	errs := make(map[int]error, len(mp.Members))
 	var j int
 
+	if dp := mw.getDegradedPartition(mp.PartitionID); dp != nil {
+		return mw.sendToDegradedPartition(mp, dp, req)
+	}
+
 	addr = mp.LeaderAddr
 	if addr == "" {
 		err = errors.New(fmt.Sprintf("sendToMetaPartition: failed due to empty leader addr and goto retry, req(%v) mp(%v)", req, mp))
@@ -116,12 +127,15 @@ sendWithList:
 	mw.putConn(mc, err)
 retry:
 	start = time.Now()
-	for i := 0; i <= SendRetryLimit; i++ {
+	for i := 1; ; i++ {
+		// the classes of the errors of the members in this round
+		var class retry.Class
 		for j, addr = range mp.Members {
 			mc, err = mw.getConn(mp.PartitionID, addr)
 			errs[j] = err
 			if err != nil {
 				log.LogWarnf("sendToMetaPartition: getConn failed and continue to retry, req(%v) mp(%v) addr(%v) err(%v)", req, mp, addr, err)
+				class |= retry.Classify(err, retry.ClassNetwork)
 				continue
 			}
 			resp, err = mc.send(req, lastSeq)
@@ -131,18 +145,24 @@ retry:
 			}
 			if err == nil {
 				errs[j] = errors.New(fmt.Sprintf("request should retry[%v]", resp.GetResultMsg()))
+				class |= retry.ClassAgain
 			} else {
 				errs[j] = err
+				class |= retry.Classify(err, retry.ClassNetwork)
 			}
 			log.LogWarnf("sendToMetaPartition: retry failed req(%v) mp(%v) mc(%v) errs(%v) resp(%v)", req, mp, mc, errs, resp)
 		}
-		if time.Since(start) > time.Duration(sendTimeLimit)*time.Millisecond {
+		if (class != 0 && !policy.ShouldRetry(class)) || policy.Exhausted(i) {
+			log.LogWarnf("sendToMetaPartition: not retry req(%v) mp(%v) errors(%v) attempts(%v)", req, mp, class, i)
+			break
+		}
+		if time.Since(start) > sendTimeLimit {
 			log.LogWarnf("sendToMetaPartition: retry timeout req(%v) mp(%v) time(%v)", req, mp, time.Since(start))
 			break
 		}
-		sendRetryInterval := time.Duration(SendRetryInterval+i*delta) * time.Millisecond
+		sendRetryInterval := policy.Interval(i)
 		log.LogWarnf("sendToMetaPartition: req(%v) mp(%v) retry in (%v), retry_iteration (%v), retry_totalTime (%v)", req, mp,
-			sendRetryInterval, i+1, time.Since(start))
+			sendRetryInterval, i, time.Since(start))
 		time.Sleep(sendRetryInterval)
 	}
 
@@ -152,11 +172,28 @@ out:
 		mw.checkVerFromMeta(resp)
 	}
 	if err != nil || resp == nil {
-		return nil, errors.New(fmt.Sprintf("sendToMetaPartition failed: req(%v) mp(%v) errs(%v) resp(%v)", req, mp, errs, resp))
+		err = errors.New(fmt.Sprintf("sendToMetaPartition failed: req(%v) mp(%v) errs(%v) resp(%v)", req, mp, errs, resp))
+		mw.degradePartition(mp, err)
+		return nil, err
 	}
 	return resp, nil
 }
 
+// sendTimeLimit returns the most time of retrying a request to the meta partition.
+func (mw *MetaWrapper) sendTimeLimit() time.Duration {
+	if mw.metaSendTimeout < MinSendTimeLimit {
+		return MinSendTimeLimit * time.Second
+	}
+	return time.Duration(mw.metaSendTimeout) * time.Second
+}
+
+func (mw *MetaWrapper) sendRetryPolicy() *retry.Policy {
+	if mw.retryPolicy != nil {
+		return mw.retryPolicy
+	}
+	return &DefaultSendRetryPolicy
+}
+
 func (mc *MetaConn) send(req *proto.Packet, verSeq uint64) (resp *proto.Packet, err error) {
 	req.ExtentType |= proto.MultiVersionFlag
 	req.VerSeq = verSeq
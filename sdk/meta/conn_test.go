@@ -0,0 +1,53 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// leastRetryWait returns the least total wait of the retries before the policy is exhausted or
+// the wait reaches limit, the jitter shortens each interval by Jitter at most.
+func leastRetryWait(mw *MetaWrapper, limit time.Duration) (wait time.Duration) {
+	policy := *mw.sendRetryPolicy()
+	jitter := policy.Jitter
+	policy.Jitter = 0
+	for attempts := 1; !policy.Exhausted(attempts) && wait < limit; attempts++ {
+		wait += time.Duration(float64(policy.Interval(attempts)) * (1 - jitter))
+	}
+	return
+}
+
+func TestDefaultSendRetryPolicyCoversSendTimeout(t *testing.T) {
+	for _, timeout := range []int64{0, 20, 600, 3600} {
+		mw := &MetaWrapper{metaSendTimeout: timeout}
+		limit := mw.sendTimeLimit()
+		if timeout < MinSendTimeLimit {
+			assert.Equal(t, MinSendTimeLimit*time.Second, limit)
+		} else {
+			assert.Equal(t, time.Duration(timeout)*time.Second, limit)
+		}
+		assert.GreaterOrEqual(t, int64(leastRetryWait(mw, limit)), int64(limit), "send timeout %v", timeout)
+	}
+
+	// the policy limited by attempts gives up before the send timeout
+	policy := DefaultSendRetryPolicy
+	policy.MaxAttempts = 201
+	mw := &MetaWrapper{metaSendTimeout: 600, retryPolicy: &policy}
+	assert.Less(t, int64(leastRetryWait(mw, mw.sendTimeLimit())), int64(mw.sendTimeLimit()))
+}
@@ -0,0 +1,159 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"fmt"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// A meta partition is degraded if a request to it failed after retrying for metaSendTimeout,
+// which usually means the raft quorum of the partition is lost. The inodes and dentries of a
+// degraded partition are read-only, writes fail fast with EROFS instead of hanging, and reads
+// are sent to the members only once, which may be served by the followers of the follower
+// read volumes. A write is let through every probe interval to detect the recovery.
+
+var ErrMetaPartitionDegraded = errors.New("meta partition is degraded")
+
+type degradedPartition struct {
+	since     time.Time
+	lastProbe int64 // unix nano
+}
+
+// tryProbe returns true if the caller is the one to probe the partition in the interval
+func (dp *degradedPartition) tryProbe(interval time.Duration) bool {
+	last := atomic.LoadInt64(&dp.lastProbe)
+	now := time.Now().UnixNano()
+	if now-last < int64(interval) {
+		return false
+	}
+	return atomic.CompareAndSwapInt64(&dp.lastProbe, last, now)
+}
+
+func isMetaReadOp(opcode uint8) bool {
+	switch opcode {
+	case proto.OpMetaLookup, proto.OpMetaReadDir, proto.OpMetaReadDirLimit, proto.OpMetaReadDirOnly,
+		proto.OpMetaInodeGet, proto.OpMetaBatchInodeGet, proto.OpMetaExtentsList, proto.OpMetaObjExtentsList,
+		proto.OpMetaGetXAttr, proto.OpMetaGetAllXAttr, proto.OpMetaListXAttr, proto.OpMetaBatchGetXAttr,
+		proto.OpMetaGetInodeQuota, proto.OpMetaTxGet:
+		return true
+	}
+	return false
+}
+
+func (mw *MetaWrapper) getDegradedPartition(partitionID uint64) *degradedPartition {
+	if mw.degradeProbeInterval <= 0 {
+		return nil
+	}
+	v, ok := mw.degradedPartitions.Load(partitionID)
+	if !ok {
+		return nil
+	}
+	return v.(*degradedPartition)
+}
+
+func (mw *MetaWrapper) degradePartition(mp *MetaPartition, err error) {
+	if mw.degradeProbeInterval <= 0 {
+		return
+	}
+	now := time.Now()
+	dp := &degradedPartition{since: now, lastProbe: now.UnixNano()}
+	if _, loaded := mw.degradedPartitions.LoadOrStore(mp.PartitionID, dp); loaded {
+		return
+	}
+	log.LogErrorf("degradePartition: vol(%v) mp(%v) is unavailable and degraded to read-only, err(%v)",
+		mw.volname, mp, err)
+	// the members of the partition may be changed by the master
+	select {
+	case mw.forceUpdate <- struct{}{}:
+	default:
+	}
+}
+
+func (mw *MetaWrapper) recoverPartition(mp *MetaPartition, dp *degradedPartition) {
+	if _, loaded := mw.degradedPartitions.LoadAndDelete(mp.PartitionID); loaded {
+		log.LogWarnf("recoverPartition: vol(%v) mp(%v) is available again after degraded for %v",
+			mw.volname, mp, time.Since(dp.since))
+	}
+}
+
+// checkWritable returns EROFS if the partition of the inode is degraded and it's not the time to probe
+func (mw *MetaWrapper) checkWritable(ino uint64) error {
+	mp := mw.getPartitionByInode(ino)
+	if mp == nil {
+		return nil
+	}
+	dp := mw.getDegradedPartition(mp.PartitionID)
+	if dp == nil || dp.tryProbe(mw.degradeProbeInterval) {
+		return nil
+	}
+	log.LogWarnf("checkWritable: vol(%v) ino(%v) mp(%v) is degraded since %v, read-only",
+		mw.volname, ino, mp.PartitionID, dp.since.Format(time.RFC3339))
+	return syscall.EROFS
+}
+
+// IsDegraded returns true if the partition of the inode is degraded
+func (mw *MetaWrapper) IsDegraded(ino uint64) bool {
+	mp := mw.getPartitionByInode(ino)
+	return mp != nil && mw.getDegradedPartition(mp.PartitionID) != nil
+}
+
+// sendToDegradedPartition sends the request to the members of the partition only once without
+// waiting for the leader, the partition is recovered if a write succeeds.
+func (mw *MetaWrapper) sendToDegradedPartition(mp *MetaPartition, dp *degradedPartition, req *proto.Packet) (*proto.Packet, error) {
+	var lastSeq uint64
+	if mw.Client != nil {
+		lastSeq = mw.Client.GetLatestVer()
+	}
+	addrs := mp.Members
+	if mp.LeaderAddr != "" {
+		addrs = append([]string{mp.LeaderAddr}, mp.Members...)
+	}
+	errs := make(map[string]error, len(addrs))
+	for _, addr := range addrs {
+		if _, ok := errs[addr]; ok {
+			continue
+		}
+		mc, err := mw.getConn(mp.PartitionID, addr)
+		if err != nil {
+			errs[addr] = err
+			continue
+		}
+		resp, err := mc.send(req, lastSeq)
+		mw.putConn(mc, err)
+		if err == nil && !resp.ShouldRetry() {
+			if !isMetaReadOp(req.Opcode) {
+				mw.recoverPartition(mp, dp)
+			}
+			if mw.Client != nil {
+				mw.checkVerFromMeta(resp)
+			}
+			return resp, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("request should retry[%v]", resp.GetResultMsg())
+		}
+		errs[addr] = err
+	}
+	log.LogWarnf("sendToDegradedPartition: req(%v) mp(%v) degraded since %v failed, errs(%v)",
+		req, mp, dp.since.Format(time.RFC3339), errs)
+	return nil, errors.NewErrorf("%v: req(%v) mp(%v) errs(%v)", ErrMetaPartitionDegraded, req, mp, errs)
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/btree"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDegradeTestWrapper(interval time.Duration) *MetaWrapper {
+	mw := &MetaWrapper{
+		partitions:           make(map[uint64]*MetaPartition),
+		ranges:               btree.New(32),
+		forceUpdate:          make(chan struct{}, 1),
+		degradeProbeInterval: interval,
+	}
+	mw.addPartition(&MetaPartition{PartitionID: 1, Start: 0, End: 99})
+	mw.addPartition(&MetaPartition{PartitionID: 2, Start: 100, End: 199})
+	return mw
+}
+
+func TestDegradePartition(t *testing.T) {
+	mw := newDegradeTestWrapper(time.Hour)
+	mp := mw.getPartitionByID(1)
+
+	mw.degradePartition(mp, errors.New("quorum lost"))
+	dp := mw.getDegradedPartition(1)
+	assert.NotNil(t, dp)
+	assert.Nil(t, mw.getDegradedPartition(2))
+	assert.True(t, mw.IsDegraded(1))
+	assert.False(t, mw.IsDegraded(100))
+	assert.Len(t, mw.forceUpdate, 1)
+
+	// writes are rejected until the probe interval elapses
+	assert.Equal(t, syscall.EROFS, mw.checkWritable(1))
+	assert.Nil(t, mw.checkWritable(100))
+	dp.lastProbe = time.Now().Add(-2 * time.Hour).UnixNano()
+	assert.Nil(t, mw.checkWritable(1))
+	assert.Equal(t, syscall.EROFS, mw.checkWritable(1))
+
+	rw := mw.getRWPartitions()
+	assert.Len(t, rw, 1)
+	assert.Equal(t, uint64(2), rw[0].PartitionID)
+
+	mw.recoverPartition(mp, dp)
+	assert.False(t, mw.IsDegraded(1))
+	assert.Nil(t, mw.checkWritable(1))
+	assert.Len(t, mw.getRWPartitions(), 2)
+}
+
+func TestDegradePartitionDisabled(t *testing.T) {
+	mw := newDegradeTestWrapper(0)
+	mw.degradePartition(mw.getPartitionByID(1), errors.New("quorum lost"))
+	assert.False(t, mw.IsDegraded(1))
+	assert.Nil(t, mw.checkWritable(1))
+	assert.Len(t, mw.getRWPartitions(), 2)
+}
+
+func TestIsMetaReadOp(t *testing.T) {
+	assert.True(t, isMetaReadOp(proto.OpMetaLookup))
+	assert.True(t, isMetaReadOp(proto.OpMetaInodeGet))
+	assert.False(t, isMetaReadOp(proto.OpMetaCreateDentry))
+	assert.False(t, isMetaReadOp(proto.OpMetaExtentsAdd))
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/cubefs/cubefs/util/btree"
 	"github.com/cubefs/cubefs/util/errors"
 	"github.com/cubefs/cubefs/util/log"
+	"github.com/cubefs/cubefs/util/retry"
 )
 
 const (
@@ -58,6 +59,8 @@ const (
 	statusTxTimeout
 	statusUploadPartConflict
 	statusNotEmpty
+	statusTooManyLinks
+	statusCrossVolume
 )
 
 const (
@@ -73,6 +76,17 @@ const (
 	MaxQuotaCache                        = 10000
 )
 
+// MountRetryPolicy is the policy of the retries to init the meta wrapper of the mount, the
+// clients restarted together do not retry together.
+var MountRetryPolicy = retry.Policy{
+	MaxAttempts:  MaxMountRetryLimit,
+	BaseInterval: MountRetryInterval,
+	MaxInterval:  MountRetryInterval * MaxMountRetryLimit,
+	Multiplier:   1.5,
+	Jitter:       0.5,
+	Retryable:    retry.ClassAll,
+}
+
 type AsyncTaskErrorFunc func(err error)
 
 func (f AsyncTaskErrorFunc) OnError(err error) {
@@ -97,6 +111,10 @@ type MetaConfig struct {
 	SubDir                     string
 	TrashTraverseLimit         int
 	TrashRebuildGoroutineLimit int
+	// probe interval of the degraded meta partitions, degradation is disabled if 0
+	MetaDegradeProbeInterval time.Duration
+	// policy of the retries of the requests to the meta partitions, DefaultSendRetryPolicy if nil
+	RetryPolicy *retry.Policy
 
 	VerReadSeq uint64
 }
@@ -182,6 +200,12 @@ type MetaWrapper struct {
 	VerReadSeq uint64
 	LastVerSeq uint64
 	Client     wrapper.SimpleClientInfo
+
+	// degraded meta partitions indexed by ID
+	degradedPartitions   sync.Map
+	degradeProbeInterval time.Duration
+
+	retryPolicy *retry.Policy
 }
 
 type uniqidRange struct {
@@ -229,6 +253,8 @@ func NewMetaWrapper(config *MetaConfig) (*MetaWrapper, error) {
 	mw.mc = masterSDK.NewMasterClient(config.Masters, false)
 	mw.onAsyncTaskError = config.OnAsyncTaskError
 	mw.metaSendTimeout = config.MetaSendTimeout
+	mw.degradeProbeInterval = config.MetaDegradeProbeInterval
+	mw.retryPolicy = config.RetryPolicy
 	mw.conns = util.NewConnectPool()
 	mw.partitions = make(map[uint64]*MetaPartition)
 	mw.ranges = btree.New(32)
@@ -243,31 +269,27 @@ func NewMetaWrapper(config *MetaConfig) (*MetaWrapper, error) {
 	mw.VerReadSeq = config.VerReadSeq
 	mw.dirCache = make(map[uint64]dirInfoCache)
 	mw.subDir = config.SubDir
-	limit := MaxMountRetryLimit
-
-	for limit > 0 {
+	for attempts := 1; ; attempts++ {
 		err = mw.initMetaWrapper()
 		// When initializing the volume, if the master explicitly responds that the specified
 		// volume does not exist, it will not retry.
 		if err != nil {
 			if strings.Contains(err.Error(), "auth key do not match") {
-				limit = 0
 				break
 			}
-			log.LogErrorf("NewMetaWrapper: init meta wrapper failed: volume(%v) err(%v)", mw.volname, err)
+			log.LogErrorf("NewMetaWrapper: init meta wrapper failed: volume(%v) attempts(%v) err(%v)",
+				mw.volname, attempts, err)
 		}
 		if err == proto.ErrVolNotExists {
 			return nil, err
 		}
-		if err != nil {
-			limit--
-			time.Sleep(MountRetryInterval * time.Duration(limit))
-			continue
+		if err == nil || MountRetryPolicy.Exhausted(attempts) {
+			break
 		}
-		break
+		MountRetryPolicy.Sleep(attempts)
 	}
 	mw.enableTrash()
-	if limit <= 0 && err != nil {
+	if err != nil {
 		return nil, err
 	}
 
@@ -325,6 +347,10 @@ func (mw *MetaWrapper) OSSSecure() (accessKey, secretKey string) {
 	return mw.ossSecure.AccessKey, mw.ossSecure.SecretKey
 }
 
+func (mw *MetaWrapper) QuotaEnabled() bool {
+	return mw.EnableQuota
+}
+
 func (mw *MetaWrapper) VolCreateTime() int64 {
 	return mw.volCreateTime
 }
@@ -380,6 +406,10 @@ func parseStatus(result uint8) (status int) {
 		status = statusUploadPartConflict
 	case proto.OpForbidErr:
 		status = statusForbid
+	case proto.OpTooManyLinksErr:
+		status = statusTooManyLinks
+	case proto.OpCrossVolumeErr:
+		status = statusCrossVolume
 	default:
 		status = statusError
 	}
@@ -431,6 +461,10 @@ func statusToErrno(status int) error {
 		return syscall.EEXIST
 	case statusForbid:
 		return syscall.EPERM
+	case statusTooManyLinks:
+		return syscall.EMLINK
+	case statusCrossVolume:
+		return syscall.EXDEV
 	default:
 	}
 	return syscall.EIO
@@ -1490,10 +1490,15 @@ func (mw *MetaWrapper) txIlink(tx *Transaction, mp *MetaPartition, inode uint64,
 }
 
 func (mw *MetaWrapper) ilink(mp *MetaPartition, inode uint64, fullPath string) (status int, info *proto.InodeInfo, err error) {
-	return mw.ilinkWork(mp, inode, proto.OpMetaLinkInode, fullPath)
+	return mw.ilinkWork(mp, inode, proto.OpMetaLinkInode, fullPath, false)
 }
 
-func (mw *MetaWrapper) ilinkWork(mp *MetaPartition, inode uint64, op uint8, fullPath string) (status int, info *proto.InodeInfo, err error) {
+// ilinkRename links the inode to be renamed, which is not limited by the max link count of meta node
+func (mw *MetaWrapper) ilinkRename(mp *MetaPartition, inode uint64, fullPath string) (status int, info *proto.InodeInfo, err error) {
+	return mw.ilinkWork(mp, inode, proto.OpMetaLinkInode, fullPath, true)
+}
+
+func (mw *MetaWrapper) ilinkWork(mp *MetaPartition, inode uint64, op uint8, fullPath string, isRename bool) (status int, info *proto.InodeInfo, err error) {
 	bgTime := stat.BeginStat()
 	defer func() {
 		stat.EndStat("ilink", err, bgTime, 1)
@@ -1511,6 +1516,7 @@ func (mw *MetaWrapper) ilinkWork(mp *MetaPartition, inode uint64, op uint8, full
 		PartitionID: mp.PartitionID,
 		Inode:       inode,
 		UniqID:      uniqID,
+		IsRename:    isRename,
 	}
 	req.FullPaths = []string{fullPath}
 
@@ -1907,18 +1913,14 @@ func (mw *MetaWrapper) removeMultipart(mp *MetaPartition, path, multipartId stri
 	return statusOK, nil
 }
 
-func (mw *MetaWrapper) appendExtentKeys(mp *MetaPartition, inode uint64, extents []proto.ExtentKey) (status int, err error) {
+func (mw *MetaWrapper) appendExtentKeys(mp *MetaPartition, req *proto.AppendExtentKeysRequest) (status int, err error) {
 	bgTime := stat.BeginStat()
 	defer func() {
 		stat.EndStat("appendExtentKeys", err, bgTime, 1)
 	}()
 
-	req := &proto.AppendExtentKeysRequest{
-		VolName:     mw.volname,
-		PartitionId: mp.PartitionID,
-		Inode:       inode,
-		Extents:     extents,
-	}
+	req.VolName = mw.volname
+	req.PartitionId = mp.PartitionID
 
 	packet := proto.NewPacketReqID()
 	packet.Opcode = proto.OpMetaBatchExtentsAdd
@@ -117,5 +117,18 @@ func (mw *MetaWrapper) getRWPartitions() []*MetaPartition {
 			rwPartitions = append(rwPartitions, mp)
 		}
 	}
-	return rwPartitions
+	if mw.degradeProbeInterval <= 0 {
+		return rwPartitions
+	}
+	// skip the degraded partitions unless all of them are degraded
+	available := make([]*MetaPartition, 0, len(rwPartitions))
+	for _, mp := range rwPartitions {
+		if mw.getDegradedPartition(mp.PartitionID) == nil {
+			available = append(available, mp)
+		}
+	}
+	if len(available) == 0 {
+		return rwPartitions
+	}
+	return available
 }
@@ -38,6 +38,7 @@ const (
 const (
 	DisableTrash = "/trash/disable"
 	QueryTrash   = "/trash/query"
+	RestoreTrash = "/trash/restore"
 )
 
 type Trash struct {
@@ -249,6 +250,16 @@ func (trash *Trash) MoveToTrash(parentPathAbsolute string, parentIno uint64, fil
 	return nil
 }
 
+// Restore moves the deleted file or directory back to its original location of the mount point,
+// see TrashRestorer.Restore.
+func (trash *Trash) Restore(trashPath string) (originalPath string, err error) {
+	restorer := &TrashRestorer{meta: trash.mw, mountPath: trash.mountPath, trashRoot: trash.trashRoot}
+	if originalPath, err = restorer.Restore(trashPath); err == nil {
+		trash.subDirCache.Delete(path.Join(trash.trashRoot, path.Clean(strings.TrimPrefix(trashPath, "/"))))
+	}
+	return
+}
+
 func transferLongFileName(filePath string) (newName, oldName string) {
 	oldName = path.Base(filePath)
 	parentPath := path.Dir(filePath)
@@ -922,9 +933,13 @@ func (trash *Trash) rebuildDir(dirName, trashCurrent string, ino uint64, fileIno
 }
 
 func (trash *Trash) recoverPosixPathName(fileName string, fileIno uint64) string {
+	return recoverPosixPathName(trash.mw, fileName, fileIno)
+}
+
+func recoverPosixPathName(mw trashMetaAPI, fileName string, fileIno uint64) string {
 	if strings.HasPrefix(fileName, LongNamePrefix) {
 		log.LogDebugf("action[recoverPosixPathName] %v is long ino %v", fileName, fileIno)
-		info, err := trash.mw.InodeGet_ll(fileIno)
+		info, err := mw.InodeGet_ll(fileIno)
 		if err != nil {
 			log.LogWarnf("action[recoverPosixPathName]:InodeGet_ll for %v[%v] failed:%v",
 				fileName, fileIno, err.Error())
@@ -933,7 +948,7 @@ func (trash *Trash) recoverPosixPathName(fileName string, fileIno uint64) string
 			return strings.Split(fileName, ParentDirPrefix)[0]
 		} else {
 			log.LogDebugf("action[recoverPosixPathName]:XAttrGet_ll for %v", fileName)
-			attrInfo, err := trash.mw.XAttrGet_ll(info.Inode, OriginalName)
+			attrInfo, err := mw.XAttrGet_ll(info.Inode, OriginalName)
 			if err != nil {
 				log.LogWarnf("action[recoverPosixPathName]:XAttrGet_ll for %v[%v] failed:%v",
 					fileName, fileIno, err.Error())
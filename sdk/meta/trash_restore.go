@@ -0,0 +1,133 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"os"
+	"path"
+	"strings"
+	"syscall"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// trashMetaAPI is the meta operations to restore files from the trash, implemented by MetaWrapper.
+type trashMetaAPI interface {
+	LookupPath(subdir string) (uint64, error)
+	Lookup_ll(parentID uint64, name string) (inode uint64, mode uint32, err error)
+	InodeGet_ll(inode uint64) (*proto.InodeInfo, error)
+	XAttrGet_ll(inode uint64, name string) (*proto.XAttrInfo, error)
+	Create_ll(parentID uint64, name string, mode, uid, gid uint32, target []byte, fullPath string, ignoreExist bool) (*proto.InodeInfo, error)
+	Rename_ll(srcParentID uint64, srcName string, dstParentID uint64, dstName string, srcFullPath string, dstFullPath string, overwritten bool) (err error)
+}
+
+// TrashRestorer restores the deleted files or directories in the trash of the mount path,
+// it's used by the client and cfs-cli, and doesn't run the delete workers of the trash.
+type TrashRestorer struct {
+	meta      trashMetaAPI
+	mountPath string
+	trashRoot string
+}
+
+func NewTrashRestorer(mw *MetaWrapper, subDir string) *TrashRestorer {
+	if subDir == "" {
+		subDir = "/"
+	}
+	return &TrashRestorer{meta: mw, mountPath: subDir, trashRoot: path.Join(subDir, TrashPrefix)}
+}
+
+// Restore moves the deleted file or directory back to its original location of the mount point,
+// trashPath is relative to the trash root, such as Current/a/b/file or Expired_xxx/a/b/file.
+// The missing parent directories are created with the mode of the closest existing one, and the
+// restore fails with EEXIST if the original path is reused. The original path is returned.
+func (r *TrashRestorer) Restore(trashPath string) (originalPath string, err error) {
+	trashPath = path.Clean(strings.TrimPrefix(trashPath, "/"))
+	subs := strings.SplitN(trashPath, "/", 2)
+	if len(subs) != 2 || (subs[0] != CurrentName && !strings.HasPrefix(subs[0], ExpiredPrefix)) {
+		log.LogWarnf("action[Restore] invalid trash path %v", trashPath)
+		return "", syscall.EINVAL
+	}
+	srcPath := path.Join(r.trashRoot, trashPath)
+	srcInfo, err := r.lookupPath(srcPath)
+	if err != nil {
+		log.LogWarnf("action[Restore] lookup %v failed: %v", srcPath, err.Error())
+		return "", syscall.ENOENT
+	}
+	originalPath = subs[1]
+	if baseName := path.Base(originalPath); strings.Contains(baseName, ParentDirPrefix) ||
+		strings.HasPrefix(baseName, LongNamePrefix) {
+		// the parent dirs of the file is not rebuilt yet
+		originalPath = path.Join(path.Dir(originalPath), recoverPosixPathName(r.meta, baseName, srcInfo.Inode))
+	}
+	dstPath := path.Join(r.mountPath, originalPath)
+	if _, err = r.meta.LookupPath(dstPath); err == nil {
+		log.LogWarnf("action[Restore] original path %v of %v is reused", dstPath, srcPath)
+		return originalPath, syscall.EEXIST
+	}
+
+	dstParentInfo, err := r.createOriginalParentPath(path.Dir(originalPath))
+	if err != nil {
+		log.LogWarnf("action[Restore] create parent of %v failed: %v", dstPath, err.Error())
+		return originalPath, err
+	}
+	srcParentInfo, err := r.lookupPath(path.Dir(srcPath))
+	if err != nil {
+		log.LogWarnf("action[Restore] lookup parent of %v failed: %v", srcPath, err.Error())
+		return originalPath, syscall.ENOENT
+	}
+	if err = r.meta.Rename_ll(srcParentInfo.Inode, path.Base(srcPath), dstParentInfo.Inode, path.Base(dstPath),
+		srcPath, dstPath, false); err != nil {
+		log.LogWarnf("action[Restore] rename %v to %v failed: %v", srcPath, dstPath, err.Error())
+		return originalPath, err
+	}
+	log.LogInfof("action[Restore] restore %v to %v success", srcPath, dstPath)
+	return originalPath, nil
+}
+
+func (r *TrashRestorer) lookupPath(fullPath string) (*proto.InodeInfo, error) {
+	ino, err := r.meta.LookupPath(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	return r.meta.InodeGet_ll(ino)
+}
+
+// createOriginalParentPath creates the parent dirs of the restored file under the mount point if
+// they are deleted too, and returns the inode info of the direct parent.
+func (r *TrashRestorer) createOriginalParentPath(parentPath string) (info *proto.InodeInfo, err error) {
+	cur := path.Clean(r.mountPath)
+	if info, err = r.lookupPath(cur); err != nil {
+		return
+	}
+	if parentPath == "." {
+		return
+	}
+	for _, sub := range strings.Split(parentPath, "/") {
+		cur = path.Join(cur, sub)
+		if child, _, lookupErr := r.meta.Lookup_ll(info.Inode, sub); lookupErr == nil {
+			if info, err = r.meta.InodeGet_ll(child); err != nil {
+				return
+			}
+			continue
+		}
+		mode := info.Mode&0o777 | uint32(os.ModeDir)
+		if info, err = r.meta.Create_ll(info.Inode, sub, mode, info.Uid, info.Gid, nil, cur, true); err != nil {
+			return
+		}
+		log.LogDebugf("action[createOriginalParentPath] create %v success", cur)
+	}
+	return
+}
@@ -0,0 +1,190 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"os"
+	"path"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTrashMeta is an in-memory namespace for the restore of trash.
+type fakeTrashMeta struct {
+	inodes   map[uint64]*proto.InodeInfo
+	dentries map[uint64]map[string]uint64
+	nextIno  uint64
+}
+
+func newFakeTrashMeta() *fakeTrashMeta {
+	m := &fakeTrashMeta{
+		inodes:   make(map[uint64]*proto.InodeInfo),
+		dentries: make(map[uint64]map[string]uint64),
+		nextIno:  proto.RootIno,
+	}
+	m.inodes[proto.RootIno] = &proto.InodeInfo{Inode: proto.RootIno, Mode: uint32(os.ModeDir) | 0o755}
+	m.dentries[proto.RootIno] = make(map[string]uint64)
+	return m
+}
+
+func (m *fakeTrashMeta) mkdirAll(t *testing.T, fullPath string, mode uint32) uint64 {
+	ino := uint64(proto.RootIno)
+	for _, sub := range strings.Split(strings.Trim(fullPath, "/"), "/") {
+		child, ok := m.dentries[ino][sub]
+		if !ok {
+			info, err := m.Create_ll(ino, sub, uint32(os.ModeDir)|mode, 0, 0, nil, "", false)
+			require.NoError(t, err)
+			child = info.Inode
+		}
+		ino = child
+	}
+	return ino
+}
+
+func (m *fakeTrashMeta) createFile(t *testing.T, fullPath string) uint64 {
+	parent := m.mkdirAll(t, path.Dir(fullPath), 0o755)
+	info, err := m.Create_ll(parent, path.Base(fullPath), 0o644, 0, 0, nil, fullPath, false)
+	require.NoError(t, err)
+	return info.Inode
+}
+
+func (m *fakeTrashMeta) LookupPath(subdir string) (uint64, error) {
+	ino := uint64(proto.RootIno)
+	if subdir = strings.Trim(subdir, "/"); subdir == "" {
+		return ino, nil
+	}
+	for _, sub := range strings.Split(subdir, "/") {
+		child, ok := m.dentries[ino][sub]
+		if !ok {
+			return 0, syscall.ENOENT
+		}
+		ino = child
+	}
+	return ino, nil
+}
+
+func (m *fakeTrashMeta) Lookup_ll(parentID uint64, name string) (uint64, uint32, error) {
+	child, ok := m.dentries[parentID][name]
+	if !ok {
+		return 0, 0, syscall.ENOENT
+	}
+	return child, m.inodes[child].Mode, nil
+}
+
+func (m *fakeTrashMeta) InodeGet_ll(inode uint64) (*proto.InodeInfo, error) {
+	info, ok := m.inodes[inode]
+	if !ok {
+		return nil, syscall.ENOENT
+	}
+	return info, nil
+}
+
+func (m *fakeTrashMeta) XAttrGet_ll(inode uint64, name string) (*proto.XAttrInfo, error) {
+	return nil, syscall.ENODATA
+}
+
+func (m *fakeTrashMeta) Create_ll(parentID uint64, name string, mode, uid, gid uint32, target []byte,
+	fullPath string, ignoreExist bool,
+) (*proto.InodeInfo, error) {
+	if child, ok := m.dentries[parentID][name]; ok {
+		if ignoreExist {
+			return m.inodes[child], nil
+		}
+		return nil, syscall.EEXIST
+	}
+	m.nextIno++
+	info := &proto.InodeInfo{Inode: m.nextIno, Mode: mode, Uid: uid, Gid: gid}
+	m.inodes[info.Inode] = info
+	m.dentries[parentID][name] = info.Inode
+	if proto.IsDir(mode) {
+		m.dentries[info.Inode] = make(map[string]uint64)
+	}
+	return info, nil
+}
+
+func (m *fakeTrashMeta) Rename_ll(srcParentID uint64, srcName string, dstParentID uint64, dstName string,
+	srcFullPath string, dstFullPath string, overwritten bool,
+) error {
+	child, ok := m.dentries[srcParentID][srcName]
+	if !ok {
+		return syscall.ENOENT
+	}
+	if _, ok = m.dentries[dstParentID][dstName]; ok && !overwritten {
+		return syscall.EEXIST
+	}
+	delete(m.dentries[srcParentID], srcName)
+	m.dentries[dstParentID][dstName] = child
+	return nil
+}
+
+func TestTrashRestore(t *testing.T) {
+	m := newFakeTrashMeta()
+	r := &TrashRestorer{meta: m, mountPath: "/", trashRoot: path.Join("/", TrashPrefix)}
+
+	// the parent exists
+	m.mkdirAll(t, "/a/b", 0o755)
+	ino := m.createFile(t, "/.Trash/Current/a/b/file")
+	originalPath, err := r.Restore("Current/a/b/file")
+	require.NoError(t, err)
+	require.Equal(t, "a/b/file", originalPath)
+	restored, err := m.LookupPath("/a/b/file")
+	require.NoError(t, err)
+	require.Equal(t, ino, restored)
+	_, err = m.LookupPath("/.Trash/Current/a/b/file")
+	require.Equal(t, syscall.ENOENT, err)
+
+	// the original path is taken by another file
+	ino = m.createFile(t, "/.Trash/Current/a/b/file")
+	_, err = r.Restore("/Current/a/b/file")
+	require.Equal(t, syscall.EEXIST, err)
+	inTrash, err := m.LookupPath("/.Trash/Current/a/b/file")
+	require.NoError(t, err)
+	require.Equal(t, ino, inTrash)
+	require.NotEqual(t, ino, restored)
+
+	// the parent is deleted too, and is created with the mode of the closest existing dir
+	m.mkdirAll(t, "/x", 0o700)
+	ino = m.createFile(t, "/.Trash/Expired_2024-01-01-000000/x/y/z/file")
+	originalPath, err = r.Restore("Expired_2024-01-01-000000/x/y/z/file")
+	require.NoError(t, err)
+	require.Equal(t, "x/y/z/file", originalPath)
+	restored, err = m.LookupPath("/x/y/z/file")
+	require.NoError(t, err)
+	require.Equal(t, ino, restored)
+	parent, err := m.LookupPath("/x/y")
+	require.NoError(t, err)
+	require.Equal(t, uint32(os.ModeDir)|0o700, m.inodes[parent].Mode)
+
+	// the parent dirs of the file in trash are not rebuilt yet
+	ino = m.createFile(t, "/.Trash/Current/"+ParentDirPrefix+"c"+ParentDirPrefix+"file")
+	originalPath, err = r.Restore("Current/" + ParentDirPrefix + "c" + ParentDirPrefix + "file")
+	require.NoError(t, err)
+	require.Equal(t, "c/file", originalPath)
+	restored, err = m.LookupPath("/c/file")
+	require.NoError(t, err)
+	require.Equal(t, ino, restored)
+
+	// invalid or missing trash path
+	_, err = r.Restore("a/b/file")
+	require.Equal(t, syscall.EINVAL, err)
+	_, err = r.Restore("Current")
+	require.Equal(t, syscall.EINVAL, err)
+	_, err = r.Restore("Current/not/exist")
+	require.Equal(t, syscall.ENOENT, err)
+}
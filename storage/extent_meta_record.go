@@ -0,0 +1,111 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// The extent metadata file keeps the base extent id at offset 0 and the extent id
+// preallocated on the verify file at offset 8, which are read by all versions.
+// Both of them are also written to the two record slots after them in turn,
+//
+//	+-----------+---------+------------------+---------------------+-----------+
+//	| magic(4)  | seq(8)  | baseExtentID(8)  | preAllocExtentID(8) | crc32(4)  |
+//	+-----------+---------+------------------+---------------------+-----------+
+//
+// so a torn write is detected by the checksum, and the valid record with the largest
+// sequence is loaded. A larger extent id is always safe, so the larger one of the
+// record and the legacy field is used, e.g. the legacy field is written by older versions.
+const (
+	extentMetaRecordMagic  uint32 = 0x4558544d // "EXTM"
+	extentMetaRecordOffset        = 16
+	extentMetaRecordSize          = 32
+	extentMetaRecordSlots         = 2
+)
+
+type extentMetaRecord struct {
+	seq              uint64
+	baseExtentID     uint64
+	preAllocExtentID uint64
+}
+
+func encodeExtentMetaRecord(rec extentMetaRecord) []byte {
+	data := make([]byte, extentMetaRecordSize)
+	binary.BigEndian.PutUint32(data[0:4], extentMetaRecordMagic)
+	binary.BigEndian.PutUint64(data[4:12], rec.seq)
+	binary.BigEndian.PutUint64(data[12:20], rec.baseExtentID)
+	binary.BigEndian.PutUint64(data[20:28], rec.preAllocExtentID)
+	binary.BigEndian.PutUint32(data[28:32], crc32.ChecksumIEEE(data[:28]))
+	return data
+}
+
+func decodeExtentMetaRecord(data []byte) (rec extentMetaRecord, ok bool) {
+	if len(data) < extentMetaRecordSize ||
+		binary.BigEndian.Uint32(data[0:4]) != extentMetaRecordMagic ||
+		binary.BigEndian.Uint32(data[28:32]) != crc32.ChecksumIEEE(data[:28]) {
+		return
+	}
+	rec.seq = binary.BigEndian.Uint64(data[4:12])
+	rec.baseExtentID = binary.BigEndian.Uint64(data[12:20])
+	rec.preAllocExtentID = binary.BigEndian.Uint64(data[20:28])
+	return rec, true
+}
+
+// loadExtentMetaRecord loads the valid record with the largest sequence, the records
+// don't exist if the metadata file is written by older versions only.
+func (s *ExtentStore) loadExtentMetaRecord() (rec extentMetaRecord, ok bool) {
+	s.extentMetaLock.Lock()
+	defer s.extentMetaLock.Unlock()
+	data := make([]byte, extentMetaRecordSize)
+	for slot := 0; slot < extentMetaRecordSlots; slot++ {
+		if _, err := s.metadataFp.ReadAt(data, int64(extentMetaRecordOffset+slot*extentMetaRecordSize)); err != nil {
+			continue
+		}
+		r, valid := decodeExtentMetaRecord(data)
+		if !valid {
+			if binary.BigEndian.Uint32(data[0:4]) == extentMetaRecordMagic {
+				log.LogWarnf("[loadExtentMetaRecord] dp(%v) torn extent meta record in slot(%v)", s.partitionID, slot)
+			}
+			continue
+		}
+		if !ok || r.seq > rec.seq {
+			rec, ok = r, true
+		}
+	}
+	if ok && rec.seq > s.extentMetaRecord.seq {
+		s.extentMetaRecord = rec
+	}
+	return
+}
+
+// persistExtentMetaRecord writes the record to the slot of the next sequence,
+// the other slot keeps the previous record if it's torn.
+func (s *ExtentStore) persistExtentMetaRecord(update func(rec *extentMetaRecord)) (err error) {
+	s.extentMetaLock.Lock()
+	defer s.extentMetaLock.Unlock()
+	rec := s.extentMetaRecord
+	rec.seq++
+	update(&rec)
+	slot := int64(rec.seq % extentMetaRecordSlots)
+	if _, err = s.metadataFp.WriteAt(encodeExtentMetaRecord(rec), extentMetaRecordOffset+slot*extentMetaRecordSize); err != nil {
+		return
+	}
+	s.extentMetaRecord = rec
+	return
+}
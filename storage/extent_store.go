@@ -150,6 +150,9 @@ type ExtentStore struct {
 	stopMutex                         sync.RWMutex
 	stopC                             chan interface{}
 	ApplyId                           uint64
+	fsyncPolicy                       atomic.Value // string, the fsync policy of the volume
+	extentMetaLock                    sync.Mutex
+	extentMetaRecord                  extentMetaRecord // the last record of extent metadata
 }
 
 func MkdirAll(name string) (err error) {
@@ -240,6 +243,9 @@ func NewExtentStore(dataDir string, partitionID uint64, storeSize, dpType int, i
 		return
 	}
 	s.hasAllocSpaceExtentIDOnVerfiyFile = s.GetPreAllocSpaceExtentIDOnVerifyFile()
+	// the records of extent metadata don't exist if upgraded from older versions
+	s.extentMetaRecord.baseExtentID = atomic.LoadUint64(&s.baseExtentID)
+	s.extentMetaRecord.preAllocExtentID = s.hasAllocSpaceExtentIDOnVerfiyFile
 	s.storeSize = storeSize
 	s.closed = 0
 	err = s.initTinyExtent()
@@ -316,6 +322,10 @@ func (s *ExtentStore) startFlushCache() {
 		case <-s.stopC:
 			return
 		default:
+			if s.FsyncPolicy() == proto.FsyncPolicyNever {
+				log.LogDebugf("[startFlushCache] store(%v) skip flush, fsync policy never", s.dataPath)
+				break
+			}
 			log.LogInfof("[startFlushCache] flush extent cache")
 			s.cache.CopyAndFlush(30 * time.Second)
 		}
@@ -674,6 +684,11 @@ func (s *ExtentStore) Write(param *WriteParam) (status uint8, err error) {
 	}
 	// update access time
 	atomic.StoreInt64(&ei.AccessTime, time.Now().Unix())
+	if isSync := s.isSyncWrite(param.IsSync); isSync != param.IsSync {
+		p := *param
+		p.IsSync = isSync
+		param = &p
+	}
 	log.LogDebugf("action[Write] dp %v write param(%v)", s.partitionID, param)
 	if err = s.checkOffsetAndSize(param); err != nil {
 		log.LogInfof("action[Write] path %v err %v", e.filePath, err)
@@ -690,6 +705,34 @@ func (s *ExtentStore) Write(param *WriteParam) (status uint8, err error) {
 	return status, nil
 }
 
+// SetFsyncPolicy sets the fsync policy of the volume, the default one if empty.
+func (s *ExtentStore) SetFsyncPolicy(policy string) {
+	if policy == "" {
+		policy = proto.DefaultFsyncPolicy
+	}
+	s.fsyncPolicy.Store(policy)
+}
+
+func (s *ExtentStore) FsyncPolicy() string {
+	if policy, ok := s.fsyncPolicy.Load().(string); ok {
+		return policy
+	}
+	return proto.DefaultFsyncPolicy
+}
+
+// isSyncWrite returns whether the write is fsynced before replied by the fsync policy, the
+// write not fsynced is flushed by the periodic flush unless the policy is never.
+func (s *ExtentStore) isSyncWrite(isSync bool) bool {
+	switch s.FsyncPolicy() {
+	case proto.FsyncPolicyAlways:
+		return true
+	case proto.FsyncPolicyNever:
+		return false
+	default:
+		return isSync
+	}
+}
+
 func (s *ExtentStore) checkOffsetAndSize(param *WriteParam) error {
 	if IsTinyExtent(param.ExtentID) {
 		return nil
@@ -294,3 +294,68 @@ func TestExtentStores(t *testing.T) {
 		ExtentStoreTest(t, ty)
 	}
 }
+
+func TestExtentStoreFsyncPolicy(t *testing.T) {
+	path, clean, err := getTestPathExtentStore()
+	require.NoError(t, err)
+	defer clean()
+	s, err := storage.NewExtentStore(path, 0, 1*util.GB, proto.PartitionTypeNormal, true)
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.EqualValues(t, proto.DefaultFsyncPolicy, s.FsyncPolicy())
+	for _, policy := range []string{proto.FsyncPolicyAlways, proto.FsyncPolicyNever, proto.FsyncPolicyInterval} {
+		s.SetFsyncPolicy(policy)
+		require.EqualValues(t, policy, s.FsyncPolicy())
+		id, err := s.NextExtentID()
+		require.NoError(t, err)
+		require.NoError(t, s.Create(id))
+		extentStoreNormalRwTest(t, s, id)
+	}
+	s.SetFsyncPolicy("")
+	require.EqualValues(t, proto.DefaultFsyncPolicy, s.FsyncPolicy())
+}
+
+func TestExtentStoreMetaRecord(t *testing.T) {
+	path, clean, err := getTestPathExtentStore()
+	require.NoError(t, err)
+	defer clean()
+	s, err := storage.NewExtentStore(path, 0, 1*util.GB, proto.PartitionTypeNormal, true)
+	require.NoError(t, err)
+	var last uint64
+	for i := 0; i < 3; i++ {
+		last, err = s.NextExtentID()
+		require.NoError(t, err)
+	}
+	s.Close()
+
+	metaFile := filepath.Join(path, storage.ExtBaseExtentIDFileName)
+	reopen := func() uint64 {
+		s, err := storage.NewExtentStore(path, 0, 1*util.GB, proto.PartitionTypeNormal, false)
+		require.NoError(t, err)
+		defer s.Close()
+		id, err := s.GetPersistenceBaseExtentID()
+		require.NoError(t, err)
+		return id
+	}
+	require.Equal(t, last, reopen())
+
+	// the torn base extent id is recovered from the record
+	data, err := os.ReadFile(metaFile)
+	require.NoError(t, err)
+	copy(data[0:8], make([]byte, 8))
+	require.NoError(t, os.WriteFile(metaFile, data, 0o666))
+	require.Equal(t, last, reopen())
+
+	// the torn record rolls back to the previous record in the other slot
+	data, err = os.ReadFile(metaFile)
+	require.NoError(t, err)
+	copy(data[0:8], make([]byte, 8))
+	for off := 16; off < 80; off += 32 {
+		if data[off+19] == byte(last) {
+			data[off+19] ^= 0xff
+		}
+	}
+	require.NoError(t, os.WriteFile(metaFile, data, 0o666))
+	require.Equal(t, last-1, reopen())
+}
@@ -138,6 +138,13 @@ func (s *ExtentStore) DeleteBlockCrc(extentID uint64) (err error) {
 }
 
 func (s *ExtentStore) PersistenceBaseExtentID(extentID uint64) (err error) {
+	if err = s.persistExtentMetaRecord(func(rec *extentMetaRecord) {
+		if extentID > rec.baseExtentID {
+			rec.baseExtentID = extentID
+		}
+	}); err != nil {
+		return
+	}
 	value := make([]byte, 8)
 	binary.BigEndian.PutUint64(value, extentID)
 	_, err = s.metadataFp.WriteAt(value, BaseExtentIDOffset)
@@ -151,6 +158,11 @@ func (s *ExtentStore) GetPreAllocSpaceExtentIDOnVerifyFile() (extentID uint64) {
 		return
 	}
 	extentID = binary.BigEndian.Uint64(value)
+	if rec, ok := s.loadExtentMetaRecord(); ok && rec.preAllocExtentID > extentID {
+		log.LogWarnf("GetPreAllocSpaceExtentIDOnVerifyFile. partitionID %v load %v from record, torn %v",
+			s.partitionID, rec.preAllocExtentID, extentID)
+		extentID = rec.preAllocExtentID
+	}
 	return
 }
 
@@ -197,6 +209,13 @@ func (s *ExtentStore) PreAllocSpaceOnVerfiyFile(currExtentID uint64) {
 			}
 		}
 
+		if err = s.persistExtentMetaRecord(func(rec *extentMetaRecord) {
+			if uint64(endAllocSpaceExtentID) > rec.preAllocExtentID {
+				rec.preAllocExtentID = uint64(endAllocSpaceExtentID)
+			}
+		}); err != nil {
+			return
+		}
 		data := make([]byte, 8)
 		binary.BigEndian.PutUint64(data, uint64(endAllocSpaceExtentID))
 		if _, err = s.metadataFp.WriteAt(data, 8); err != nil {
@@ -217,6 +236,11 @@ func (s *ExtentStore) GetPersistenceBaseExtentID() (extentID uint64, err error)
 		return
 	}
 	extentID = binary.BigEndian.Uint64(data)
+	if rec, ok := s.loadExtentMetaRecord(); ok && rec.baseExtentID > extentID {
+		log.LogWarnf("GetPersistenceBaseExtentID. partitionID %v load %v from record, torn %v",
+			s.partitionID, rec.baseExtentID, extentID)
+		extentID = rec.baseExtentID
+	}
 	return
 }
 
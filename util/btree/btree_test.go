@@ -905,3 +905,93 @@ func TestBtree_NodeMerge(t *testing.T) {
 		return true
 	})
 }
+
+// checkNodes verifies the invariants of the subtree and returns its depth.
+func checkNodes(t *testing.T, n *node, minItems, maxItems int, isRoot bool) int {
+	if !isRoot && (len(n.items) < minItems || len(n.items) > maxItems) {
+		t.Fatalf("node has %d items, expect [%d, %d]", len(n.items), minItems, maxItems)
+	}
+	if len(n.children) == 0 {
+		return 1
+	}
+	if len(n.children) != len(n.items)+1 {
+		t.Fatalf("node has %d items and %d children", len(n.items), len(n.children))
+	}
+	depth := 0
+	for i, child := range n.children {
+		d := checkNodes(t, child, minItems, maxItems, false)
+		if i > 0 && d != depth {
+			t.Fatalf("leaves at depth %d and %d", depth, d)
+		}
+		depth = d
+	}
+	return depth + 1
+}
+
+func TestCompact(t *testing.T) {
+	tree := New(4)
+	for _, v := range perm(20000) {
+		tree.ReplaceOrInsert(v)
+	}
+	for _, v := range perm(20000) {
+		if int(v.(Int))%4 != 0 {
+			tree.Delete(v)
+		}
+	}
+	snap := tree.Clone()
+	want := all(tree)
+
+	var pivot Item
+	freed, rounds := 0, 0
+	for {
+		next, n := tree.Compact(pivot, 64)
+		freed += n
+		rounds++
+		checkNodes(t, tree.root, tree.minItems(), tree.maxItems(), true)
+		if next == nil {
+			break
+		}
+		pivot = next
+	}
+	if rounds < 2 || freed <= 0 {
+		t.Fatalf("compacted in %d rounds, freed %d bytes", rounds, freed)
+	}
+	assert.Equal(t, want, all(tree))
+	assert.Equal(t, len(want), tree.Len())
+	assert.Equal(t, want, all(snap))
+
+	// the dense tree is not compacted again
+	next, n := tree.Compact(nil, 1<<20)
+	assert.Nil(t, next)
+	assert.Equal(t, 0, n)
+
+	for _, v := range perm(20000) {
+		tree.ReplaceOrInsert(v)
+	}
+	assert.Equal(t, rang(20000), all(tree))
+	checkNodes(t, tree.root, tree.minItems(), tree.maxItems(), true)
+}
+
+func TestCompactCopyOnWrite(t *testing.T) {
+	tree := New(4)
+	for index := 0; index < nodeTestCount*4; index++ {
+		tree.ReplaceOrInsert(&inode{ID: uint64(index), Nlink: 2})
+	}
+	for index := 0; index < nodeTestCount*4; index++ {
+		if index%4 != 0 {
+			tree.Delete(&inode{ID: uint64(index)})
+		}
+	}
+	snap := tree.Clone()
+	tree.Compact(nil, 1<<20)
+	for index := 0; index < nodeTestCount*4; index += 4 {
+		tree.CopyGet(&inode{ID: uint64(index)}).(*inode).Nlink++
+	}
+	snap.Ascend(func(i Item) bool {
+		return assert.Equal(t, 2, i.(*inode).Nlink)
+	})
+	tree.Ascend(func(i Item) bool {
+		return assert.Equal(t, 3, i.(*inode).Nlink)
+	})
+	assert.Equal(t, nodeTestCount, tree.Len())
+}
@@ -0,0 +1,165 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package btree
+
+import "unsafe"
+
+var (
+	nodeSize  = int(unsafe.Sizeof(node{}))
+	itemSize  = int(unsafe.Sizeof(Item(nil)))
+	childSize = int(unsafe.Sizeof((*node)(nil)))
+)
+
+// size returns the bytes of the node and its slices, excluding the items themselves.
+func (n *node) size() int {
+	return nodeSize + cap(n.items)*itemSize + cap(n.children)*childSize
+}
+
+// isLastInternal reports whether the children of the node are leaves.
+func (n *node) isLastInternal() bool {
+	return len(n.children) > 0 && len(n.children[0].children) == 0
+}
+
+// packedLeaves returns the number of leaves to hold the items of the subtree rooted at the
+// last internal node densely, and whether it frees at least a quarter of the leaves.
+func (n *node) packedLeaves(maxItems, minItems int, isRoot bool) (int, bool) {
+	total := len(n.items)
+	for _, child := range n.children {
+		total += len(child.items)
+	}
+	// k leaves hold total-(k-1) items, at most maxItems each
+	k := (total + 1 + maxItems) / (maxItems + 1)
+	if k < 2 {
+		k = 2
+	}
+	if !isRoot && k < minItems+1 {
+		k = minItems + 1
+	}
+	freed := len(n.children) - k
+	return k, freed > 0 && freed*4 >= len(n.children)
+}
+
+// collectSparse collects the first item of the sparse subtrees after pivot, until the budget of
+// leaves is used up. It returns the separator after the visited subtrees to resume from, and
+// whether the budget is used up.
+func (n *node) collectSparse(pivot Item, budget *int, maxItems, minItems int, isRoot bool, firsts *[]Item) (Item, bool) {
+	if n.isLastInternal() {
+		*budget -= len(n.children)
+		if _, sparse := n.packedLeaves(maxItems, minItems, isRoot); sparse {
+			*firsts = append(*firsts, n.children[0].items[0])
+		}
+		return nil, *budget <= 0
+	}
+	start := 0
+	if pivot != nil {
+		i, found := n.items.find(pivot)
+		if start = i; found {
+			start, pivot = i+1, nil
+		}
+	}
+	for i := start; i < len(n.children); i++ {
+		var next Item
+		var stop bool
+		if i == start {
+			next, stop = n.children[i].collectSparse(pivot, budget, maxItems, minItems, false, firsts)
+		} else {
+			next, stop = n.children[i].collectSparse(nil, budget, maxItems, minItems, false, firsts)
+		}
+		if !stop {
+			continue
+		}
+		if next == nil && i < len(n.items) {
+			next = n.items[i]
+		}
+		return next, true
+	}
+	return nil, false
+}
+
+// pack rebuilds the leaves of the last internal node into the given number of dense leaves,
+// and returns the bytes of the nodes freed. The items of the leaves shared with a clone are
+// copied, as the copy-on-write does.
+func (n *node) pack(k int) (freed int) {
+	all := make(items, 0, len(n.items)+len(n.children)*cap(n.children[0].items))
+	freed += cap(n.items)*itemSize + cap(n.children)*childSize
+	for i, child := range n.children {
+		if child.cow == n.cow {
+			all = append(all, child.items...)
+		} else {
+			all = append(all, child.items.copy()...)
+		}
+		if i < len(n.items) {
+			all = append(all, n.items[i])
+		}
+		freed += child.size()
+	}
+
+	leafItems := len(all) - (k - 1)
+	base, extra := leafItems/k, leafItems%k
+	seps, leaves := make(items, 0, k-1), make(children, 0, k)
+	pos := 0
+	for i := 0; i < k; i++ {
+		cnt := base
+		if i < extra {
+			cnt++
+		}
+		leaf := n.cow.newNode()
+		leaf.items = make(items, cnt)
+		pos += copy(leaf.items, all[pos:pos+cnt])
+		leaves = append(leaves, leaf)
+		freed -= leaf.size()
+		if i < k-1 {
+			seps = append(seps, all[pos])
+			pos++
+		}
+	}
+	freed -= cap(seps)*itemSize + cap(leaves)*childSize
+
+	for _, child := range n.children {
+		n.cow.freeNode(child)
+	}
+	n.items, n.children = seps, leaves
+	return
+}
+
+// Compact rebuilds the sparse subtrees after pivot into dense nodes, visiting at most limit
+// leaves. A subtree is sparse if its items fit in three quarters of its leaves after the
+// churn of inserts and deletes. It returns the item to resume the next compaction from, nil
+// if the whole tree is visited, and the estimated bytes of the nodes freed.
+//
+// Compact is a write operation, the nodes shared with a clone are copied on write, and the
+// memory of them is freed after the clone is released.
+func (t *BTree) Compact(pivot Item, limit int) (next Item, freed int) {
+	if t.root == nil || len(t.root.children) == 0 {
+		return nil, 0
+	}
+	var firsts []Item
+	next, _ = t.root.collectSparse(pivot, &limit, t.maxItems(), t.minItems(), true, &firsts)
+	if len(firsts) == 0 {
+		return
+	}
+	t.root = t.root.mutableFor(t.cow)
+	for _, first := range firsts {
+		n, isRoot := t.root, true
+		for !n.isLastInternal() {
+			i, _ := n.items.find(first)
+			n, isRoot = n.mutableChild(i), false
+		}
+		if k, sparse := n.packedLeaves(t.maxItems(), t.minItems(), isRoot); sparse {
+			freed += n.pack(k)
+		}
+	}
+	return
+}
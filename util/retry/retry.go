@@ -0,0 +1,208 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Class is the class of the errors retried, the policy retries the errors of its classes.
+type Class uint8
+
+const (
+	ClassNetwork Class = 1 << iota // the connections refused, reset or broken
+	ClassTimeout                   // the requests timed out
+	ClassAgain                     // the servers ask to retry later, e.g. busy, limited or no leader
+
+	ClassAll = ClassNetwork | ClassTimeout | ClassAgain
+)
+
+var classNames = []struct {
+	class Class
+	name  string
+}{
+	{ClassNetwork, "network"},
+	{ClassTimeout, "timeout"},
+	{ClassAgain, "again"},
+}
+
+func (c Class) String() string {
+	names := make([]string, 0, len(classNames))
+	for _, cn := range classNames {
+		if c&cn.class != 0 {
+			names = append(names, cn.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// ParseClass parses the comma separated names of the classes, e.g. "network,timeout,again".
+func ParseClass(s string) (c Class, err error) {
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, cn := range classNames {
+			if strings.EqualFold(name, cn.name) {
+				c |= cn.class
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown retryable error class %q", name)
+		}
+	}
+	return c, nil
+}
+
+// Classify returns the class of the error by its type, or def if the type is unknown. The
+// errors traced by util/errors are classified by their messages.
+func Classify(err error, def Class) Class {
+	if err == nil {
+		return 0
+	}
+	var netErr net.Error
+	switch {
+	// EAGAIN is a timeout of net.Error too
+	case errors.Is(err, syscall.EAGAIN), errors.Is(err, syscall.EBUSY):
+		return ClassAgain
+	case errors.Is(err, os.ErrDeadlineExceeded), errors.Is(err, syscall.ETIMEDOUT),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return ClassTimeout
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.As(err, &netErr):
+		return ClassNetwork
+	}
+	if msg := err.Error(); strings.Contains(msg, "i/o timeout") {
+		return ClassTimeout
+	} else if strings.Contains(msg, "connection refused") || strings.Contains(msg, "connection reset") ||
+		strings.Contains(msg, "broken pipe") {
+		return ClassNetwork
+	}
+	return def
+}
+
+// Policy is the policy of the retries of the client requests. The interval after the n-th
+// failed attempt grows exponentially from BaseInterval to MaxInterval, and is randomized by
+// Jitter, so that the clients failed together do not retry together and storm the servers.
+type Policy struct {
+	MaxAttempts  int           // the most attempts including the first, unlimited if 0
+	BaseInterval time.Duration // the interval after the first failed attempt
+	MaxInterval  time.Duration // the most interval, unlimited if 0
+	Multiplier   float64       // the growth of the interval, constant if no more than 1
+	Jitter       float64       // the fraction of the interval randomized in [0, 1]
+	Retryable    Class         // the classes of the errors retried
+}
+
+func (p *Policy) String() string {
+	return fmt.Sprintf("attempts(%v) interval(%v-%v) multiplier(%v) jitter(%v) retryable(%v)",
+		p.MaxAttempts, p.BaseInterval, p.MaxInterval, p.Multiplier, p.Jitter, p.Retryable)
+}
+
+func (p *Policy) Validate() error {
+	if p.MaxAttempts < 0 {
+		return fmt.Errorf("invalid max attempts %v", p.MaxAttempts)
+	}
+	if p.BaseInterval < 0 || p.MaxInterval < 0 || (p.MaxInterval > 0 && p.MaxInterval < p.BaseInterval) {
+		return fmt.Errorf("invalid intervals %v-%v", p.BaseInterval, p.MaxInterval)
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return fmt.Errorf("invalid jitter %v", p.Jitter)
+	}
+	return nil
+}
+
+// Exhausted returns whether no more attempt is allowed after the attempts.
+func (p *Policy) Exhausted(attempts int) bool {
+	return p.MaxAttempts > 0 && attempts >= p.MaxAttempts
+}
+
+// ShouldRetry returns whether the error of the class is retried.
+func (p *Policy) ShouldRetry(class Class) bool {
+	return p.Retryable&class != 0
+}
+
+// Interval returns the interval after the failed attempts, the jitter shortens it by a random
+// fraction no more than Jitter.
+func (p *Policy) Interval(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	interval := float64(p.BaseInterval)
+	if p.Multiplier > 1 {
+		interval *= math.Pow(p.Multiplier, float64(attempts-1))
+	}
+	if p.MaxInterval > 0 && interval > float64(p.MaxInterval) {
+		interval = float64(p.MaxInterval)
+	}
+	if p.Jitter > 0 {
+		interval -= interval * p.Jitter * rand.Float64()
+	}
+	return time.Duration(interval)
+}
+
+// Sleep sleeps the interval after the failed attempts.
+func (p *Policy) Sleep(attempts int) {
+	time.Sleep(p.Interval(attempts))
+}
+
+// Do calls op until it succeeds, its error is not retried, or the attempts are exhausted. The
+// class of the error is classified by its type, def if unknown.
+func (p *Policy) Do(def Class, op func(attempt int) error) (err error) {
+	for attempt := 1; ; attempt++ {
+		if err = op(attempt); err == nil || !p.ShouldRetry(Classify(err, def)) || p.Exhausted(attempt) {
+			return
+		}
+		p.Sleep(attempt)
+	}
+}
+
+// Override returns the policy overridden by the non-zero fields of o.
+func (p Policy) Override(o Policy) Policy {
+	if o.MaxAttempts > 0 {
+		p.MaxAttempts = o.MaxAttempts
+	}
+	if o.BaseInterval > 0 {
+		p.BaseInterval = o.BaseInterval
+	}
+	if o.MaxInterval > 0 {
+		p.MaxInterval = o.MaxInterval
+	}
+	if o.Multiplier > 0 {
+		p.Multiplier = o.Multiplier
+	}
+	if o.Jitter > 0 {
+		p.Jitter = o.Jitter
+	}
+	if o.Retryable != 0 {
+		p.Retryable = o.Retryable
+	}
+	if p.MaxInterval > 0 && p.MaxInterval < p.BaseInterval {
+		p.MaxInterval = p.BaseInterval
+	}
+	return p
+}
@@ -0,0 +1,136 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cerrors "github.com/cubefs/cubefs/util/errors"
+)
+
+func TestClass(t *testing.T) {
+	c, err := ParseClass(" network, Timeout,again ")
+	require.NoError(t, err)
+	require.Equal(t, ClassAll, c)
+	require.Equal(t, "network,timeout,again", c.String())
+
+	c, err = ParseClass("timeout")
+	require.NoError(t, err)
+	require.Equal(t, ClassTimeout, c)
+	c, err = ParseClass("")
+	require.NoError(t, err)
+	require.Equal(t, Class(0), c)
+	_, err = ParseClass("network,unknown")
+	require.Error(t, err)
+}
+
+func TestClassify(t *testing.T) {
+	require.Equal(t, Class(0), Classify(nil, ClassAll))
+	require.Equal(t, ClassTimeout, Classify(&net.OpError{Op: "read", Err: timeoutError{}}, ClassAgain))
+	require.Equal(t, ClassTimeout, Classify(cerrors.Trace(errors.New("read tcp: i/o timeout"), "send"), ClassAgain))
+	require.Equal(t, ClassNetwork, Classify(fmt.Errorf("dial: %w", syscall.ECONNREFUSED), ClassAgain))
+	require.Equal(t, ClassNetwork, Classify(io.EOF, ClassAgain))
+	require.Equal(t, ClassNetwork, Classify(errors.New("write: broken pipe"), ClassAgain))
+	require.Equal(t, ClassAgain, Classify(syscall.EAGAIN, ClassNetwork))
+	require.Equal(t, ClassAgain, Classify(errors.New("unknown"), ClassAgain))
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestPolicyInterval(t *testing.T) {
+	p := &Policy{BaseInterval: 100 * time.Millisecond, MaxInterval: time.Second, Multiplier: 2}
+	require.NoError(t, p.Validate())
+	for attempts, expected := range map[int]time.Duration{
+		0: 100 * time.Millisecond, 1: 100 * time.Millisecond, 2: 200 * time.Millisecond,
+		4: 800 * time.Millisecond, 5: time.Second, 100: time.Second,
+	} {
+		require.Equal(t, expected, p.Interval(attempts))
+	}
+
+	p.Multiplier = 1
+	require.Equal(t, 100*time.Millisecond, p.Interval(10))
+
+	p.Jitter = 0.5
+	p.Multiplier = 2
+	for i := 0; i < 100; i++ {
+		interval := p.Interval(3)
+		require.True(t, interval > 200*time.Millisecond && interval <= 400*time.Millisecond, interval)
+	}
+
+	for _, invalid := range []Policy{
+		{MaxAttempts: -1},
+		{BaseInterval: time.Second, MaxInterval: time.Millisecond},
+		{Jitter: 1.5},
+	} {
+		require.Error(t, invalid.Validate())
+	}
+}
+
+func TestPolicyDo(t *testing.T) {
+	p := &Policy{MaxAttempts: 3, BaseInterval: time.Millisecond, Retryable: ClassNetwork | ClassTimeout}
+	attempts := 0
+	err := p.Do(ClassNetwork, func(attempt int) error {
+		attempts++
+		require.Equal(t, attempts, attempt)
+		return io.EOF
+	})
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, 3, attempts)
+
+	attempts = 0
+	err = p.Do(ClassAgain, func(int) error {
+		attempts++
+		return errors.New("busy")
+	})
+	require.Error(t, err)
+	require.Equal(t, 1, attempts)
+
+	attempts = 0
+	require.NoError(t, p.Do(ClassNetwork, func(int) error {
+		if attempts++; attempts < 2 {
+			return io.EOF
+		}
+		return nil
+	}))
+	require.Equal(t, 2, attempts)
+
+	require.False(t, (&Policy{}).Exhausted(1000))
+}
+
+func TestPolicyOverride(t *testing.T) {
+	def := Policy{MaxAttempts: 200, BaseInterval: 100 * time.Millisecond, MaxInterval: time.Second, Multiplier: 1.5, Jitter: 0.5, Retryable: ClassAll}
+	require.Equal(t, def, def.Override(Policy{}))
+
+	p := def.Override(Policy{MaxAttempts: 10, BaseInterval: 2 * time.Second, Jitter: 0.2, Retryable: ClassTimeout})
+	require.Equal(t, 10, p.MaxAttempts)
+	require.Equal(t, 2*time.Second, p.BaseInterval)
+	require.Equal(t, 2*time.Second, p.MaxInterval)
+	require.Equal(t, 1.5, p.Multiplier)
+	require.Equal(t, 0.2, p.Jitter)
+	require.Equal(t, ClassTimeout, p.Retryable)
+	require.NoError(t, p.Validate())
+}